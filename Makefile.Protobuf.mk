@@ -22,7 +22,6 @@ PATCHED_OTEL_PROTO_DIR = proto-gen/.patched-otel-proto
 
 PROTO_INCLUDES := \
 	-Iidl/proto/api_v2 \
-	-Iidl/proto/api_v3 \
 	-Imodel/proto/metrics \
 	-I/usr/include/github.com/gogo/protobuf
 
@@ -84,6 +83,7 @@ proto: proto-model \
 	proto-hotrod \
 	proto-zipkin \
 	proto-openmetrics \
+	proto-throttling \
 	proto-api-v3
 
 .PHONY: proto-model
@@ -95,7 +95,14 @@ proto-model:
 proto-api-v2:
 	$(call proto_compile, proto-gen/api_v2, idl/proto/api_v2/query.proto)
 	$(call proto_compile, proto-gen/api_v2, idl/proto/api_v2/collector.proto)
-	$(call proto_compile, proto-gen/api_v2, idl/proto/api_v2/sampling.proto)
+	@# sampling.proto lives in model/proto/sampling because it extends the idl with the span kind of the
+	@# per-operation strategies; idl/proto/api_v2 is not included so that its sampling.proto does not shadow it.
+	$(call print_caption, "Processing model/proto/sampling/sampling.proto --> proto-gen/api_v2")
+	$(PROTOC) \
+		-Imodel/proto/sampling \
+		-I/usr/include/github.com/gogo/protobuf \
+		--gogo_out=plugins=grpc,$(PROTO_GOGO_MAPPINGS):$(PWD)/proto-gen/api_v2 \
+		model/proto/sampling/sampling.proto
 
 .PHONY: proto-openmetrics
 proto-openmetrics:
@@ -104,6 +111,10 @@ proto-openmetrics:
 	@# TODO why is this file included in model/proto/metrics/ in the first place?
 	rm proto-gen/api_v2/metrics/otelmetric.pb.go
 
+.PHONY: proto-throttling
+proto-throttling:
+	$(call proto_compile, proto-gen/api_v2/throttling, model/proto/throttling/throttling.proto, -Imodel/proto/throttling)
+
 .PHONY: proto-storage-v1
 proto-storage-v1:
 	$(call proto_compile, proto-gen/storage_v1, plugin/storage/grpc/proto/storage.proto, -Iplugin/storage/grpc/proto)
@@ -126,10 +137,12 @@ proto-zipkin:
 # only to fields in a struct, so we use regex search/replace to swap it.
 # Note that the .pb.go types must be generated into the same internal package $(API_V3_PATH)
 # where a manually defined traces.go file is located.
+# The query_service.proto source lives next to the generated code because it extends
+# the upstream IDL with the continuation token pagination fields.
 API_V3_PATH=cmd/query/app/internal/api_v3
 .PHONY: proto-api-v3
 proto-api-v3:
-	$(call proto_compile, $(API_V3_PATH), idl/proto/api_v3/query_service.proto, -Iidl/opentelemetry-proto)
+	$(call proto_compile, $(API_V3_PATH), $(API_V3_PATH)/query_service.proto, -I$(API_V3_PATH) -Iidl/opentelemetry-proto)
 	@echo "🏗️  replace TracesData with internal custom type"
 	$(SED) -i 's/v1.TracesData/TracesData/g' $(API_V3_PATH)/query_service.pb.go
 	@echo "🏗️  remove OTEL import because we're not using any other OTLP types"
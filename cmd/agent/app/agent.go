@@ -27,15 +27,18 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/agent/app/processors"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 )
 
 // Agent is a composition of all services / components
 type Agent struct {
 	processors []processors.Processor
 	httpServer *http.Server
-	httpAddr   atomic.Value // string, set once agent starts listening
-	logger     *zap.Logger
-	exitWG     sync.WaitGroup
+	// httpNetwork is the Go TCP network of the HTTP server, "tcp" if empty
+	httpNetwork string
+	httpAddr    atomic.Value // string, set once agent starts listening
+	logger      *zap.Logger
+	exitWG      sync.WaitGroup
 }
 
 // NewAgent creates the new Agent.
@@ -62,7 +65,7 @@ func (a *Agent) GetHTTPRouter() *mux.Router {
 // It returns an error when it's immediately apparent on startup, but
 // any errors happening after starting the servers are only logged.
 func (a *Agent) Run() error {
-	listener, err := net.Listen("tcp", a.httpServer.Addr)
+	listener, err := netutils.Listen(a.httpNetwork, a.httpServer.Addr, 0)
 	if err != nil {
 		return err
 	}
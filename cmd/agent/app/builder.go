@@ -33,6 +33,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/agent/app/servers/thriftudp"
 	"github.com/jaegertracing/jaeger/internal/safeexpvar"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/ports"
 	agentThrift "github.com/jaegertracing/jaeger/thrift-gen/agent"
 )
@@ -73,6 +74,9 @@ type CollectorProxy interface {
 type Builder struct {
 	Processors []ProcessorConfiguration `yaml:"processors"`
 	HTTPServer HTTPServerConfiguration  `yaml:"httpServer"`
+	// OTLPUnixSocketPath is the path of the Unix domain socket receiving OTLP traces over gRPC,
+	// for the clients which cannot reach the agent over the network. Disabled when empty.
+	OTLPUnixSocketPath string `yaml:"otlpUnixSocketPath"`
 
 	reporters []reporter.Reporter
 }
@@ -91,11 +95,15 @@ type ServerConfiguration struct {
 	MaxPacketSize    int    `yaml:"maxPacketSize"`
 	SocketBufferSize int    `yaml:"socketBufferSize"`
 	HostPort         string `yaml:"hostPort" validate:"nonzero"`
+	// IPFamily is the IP family of the server: dual (the default), udp4 or udp6
+	IPFamily string `yaml:"ipFamily"`
 }
 
 // HTTPServerConfiguration holds config for a server providing sampling strategies and baggage restrictions to clients
 type HTTPServerConfiguration struct {
 	HostPort string `yaml:"hostPort" validate:"nonzero"`
+	// IPFamily is the IP family of the server: dual (the default), tcp4 or tcp6
+	IPFamily string `yaml:"ipFamily"`
 }
 
 // WithReporter adds auxiliary reporters.
@@ -111,10 +119,16 @@ func (b *Builder) CreateAgent(primaryProxy CollectorProxy, logger *zap.Logger, m
 	if err != nil {
 		return nil, fmt.Errorf("cannot create processors: %w", err)
 	}
+	httpNetwork, err := netutils.TCPNetwork(b.HTTPServer.IPFamily)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the HTTP server: %w", err)
+	}
 	server := b.HTTPServer.getHTTPServer(primaryProxy.GetManager(), mFactory, logger)
 	b.publishOpts()
 
-	return NewAgent(processors, server, logger), nil
+	agent := NewAgent(processors, server, logger)
+	agent.httpNetwork = httpNetwork
+	return agent, nil
 }
 
 func (b *Builder) getReporter(primaryProxy CollectorProxy) reporter.Reporter {
@@ -162,6 +176,13 @@ func (b *Builder) getProcessors(rep reporter.Reporter, mFactory metrics.Factory,
 		}
 		retMe[idx] = processor
 	}
+	if b.OTLPUnixSocketPath != "" {
+		processor, err := processors.NewOTLPProcessor(b.OTLPUnixSocketPath, rep, mFactory, logger)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create OTLP Processor: %w", err)
+		}
+		retMe = append(retMe, processor)
+	}
 	return retMe, nil
 }
 
@@ -208,7 +229,11 @@ func (c *ServerConfiguration) getUDPServer(mFactory metrics.Factory) (servers.Se
 	if c.HostPort == "" {
 		return nil, fmt.Errorf("no host:port provided for udp server: %+v", *c)
 	}
-	transport, err := thriftudp.NewTUDPServerTransport(c.HostPort)
+	network, err := netutils.UDPNetwork(c.IPFamily)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := thriftudp.NewTUDPServerTransportWithNetwork(network, c.HostPort)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create UDPServerTransport: %w", err)
 	}
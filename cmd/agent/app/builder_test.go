@@ -21,6 +21,7 @@ import (
 	"expvar"
 	"flag"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -132,18 +133,32 @@ func TestBuilderWithExtraReporter(t *testing.T) {
 	assert.NotNil(t, agent)
 }
 
+func TestBuilderWithOTLPUnixSocket(t *testing.T) {
+	cfg := &Builder{OTLPUnixSocketPath: filepath.Join(t.TempDir(), "otlp.sock")}
+	agent, err := cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	require.Len(t, agent.processors, 1)
+	agent.processors[0].Stop()
+
+	cfg.OTLPUnixSocketPath = filepath.Join(t.TempDir(), "missing", "otlp.sock")
+	_, err = cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.ErrorContains(t, err, "cannot create OTLP Processor")
+}
+
 func TestBuilderWithProcessorErrors(t *testing.T) {
 	testCases := []struct {
 		model       Model
 		protocol    Protocol
 		hostPort    string
+		ipFamily    string
 		err         string
 		errContains string
 	}{
 		{protocol: Protocol("bad"), err: "cannot find protocol factory for protocol bad"},
 		{protocol: compactProtocol, model: Model("bad"), err: "cannot find agent processor for data model bad"},
-		{protocol: compactProtocol, model: jaegerModel, err: "no host:port provided for udp server: {QueueSize:1000 MaxPacketSize:65000 SocketBufferSize:0 HostPort:}"},
+		{protocol: compactProtocol, model: jaegerModel, err: "no host:port provided for udp server: {QueueSize:1000 MaxPacketSize:65000 SocketBufferSize:0 HostPort: IPFamily:}"},
 		{protocol: compactProtocol, model: zipkinModel, hostPort: "bad-host-port", errContains: "bad-host-port"},
+		{protocol: compactProtocol, model: jaegerModel, hostPort: ":0", ipFamily: "tcp4", err: `invalid IP family "tcp4", must be dual, udp4 or udp6`},
 	}
 	for _, tc := range testCases {
 		testCase := tc // capture loop var
@@ -154,6 +169,7 @@ func TestBuilderWithProcessorErrors(t *testing.T) {
 					Protocol: testCase.protocol,
 					Server: ServerConfiguration{
 						HostPort: testCase.hostPort,
+						IPFamily: testCase.ipFamily,
 					},
 				},
 			},
@@ -168,6 +184,19 @@ func TestBuilderWithProcessorErrors(t *testing.T) {
 	}
 }
 
+func TestBuilderWithHTTPServerIPFamily(t *testing.T) {
+	cfg := &Builder{HTTPServer: HTTPServerConfiguration{HostPort: ":0", IPFamily: "tcp4"}}
+	agent, err := cfg.CreateAgent(&fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	require.NoError(t, agent.Run())
+	defer agent.Stop()
+	assert.True(t, strings.HasPrefix(agent.HTTPAddr(), "0.0.0.0:"), agent.HTTPAddr())
+
+	cfg = &Builder{HTTPServer: HTTPServerConfiguration{IPFamily: "udp4"}}
+	_, err = cfg.CreateAgent(&fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.ErrorContains(t, err, `cannot create the HTTP server: invalid IP family "udp4"`)
+}
+
 func TestMultipleCollectorProxies(t *testing.T) {
 	b := Builder{}
 	ra := fakeCollectorProxy{}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -31,9 +32,12 @@ const (
 	suffixServerMaxPacketSize    = "server-max-packet-size"
 	suffixServerSocketBufferSize = "server-socket-buffer-size"
 	suffixServerHostPort         = "server-host-port"
+	suffixServerIPFamily         = "server-ip-family"
 
 	processorPrefixFmt = "processor.%s-%s."
 	httpServerHostPort = "http-server.host-port"
+	httpServerIPFamily = "http-server.ip-family"
+	otlpUnixSocketPath = "processor.otlp.unix-socket-path"
 )
 
 var defaultProcessors = []struct {
@@ -52,6 +56,14 @@ func AddFlags(flags *flag.FlagSet) {
 		httpServerHostPort,
 		defaultHTTPServerHostPort,
 		"host:port of the http server (e.g. for /sampling point and /baggageRestrictions endpoint)")
+	flags.String(
+		httpServerIPFamily,
+		netutils.NetworkDual,
+		"IP family of the http server: dual accepts both IPv4 and IPv6 when the host is empty or a host name, tcp4 only IPv4 and tcp6 only IPv6")
+	flags.String(
+		otlpUnixSocketPath,
+		"",
+		"(experimental) path of the Unix domain socket receiving OTLP traces over gRPC, e.g. for sidecars which cannot listen on TCP ports; disabled when empty")
 
 	for _, p := range defaultProcessors {
 		prefix := fmt.Sprintf(processorPrefixFmt, p.model, p.protocol)
@@ -60,6 +72,7 @@ func AddFlags(flags *flag.FlagSet) {
 		flags.Int(prefix+suffixServerMaxPacketSize, defaultMaxPacketSize, "max packet size for the UDP server")
 		flags.Int(prefix+suffixServerSocketBufferSize, 0, "socket buffer size for UDP packets in bytes")
 		flags.String(prefix+suffixServerHostPort, ":"+strconv.Itoa(p.port), "host:port for the UDP server")
+		flags.String(prefix+suffixServerIPFamily, netutils.NetworkDual, "IP family of the UDP server: dual accepts both IPv4 and IPv6 when the host is empty or a host name, udp4 only IPv4 and udp6 only IPv6")
 	}
 }
 
@@ -73,10 +86,13 @@ func (b *Builder) InitFromViper(v *viper.Viper) *Builder {
 		p.Server.MaxPacketSize = v.GetInt(prefix + suffixServerMaxPacketSize)
 		p.Server.SocketBufferSize = v.GetInt(prefix + suffixServerSocketBufferSize)
 		p.Server.HostPort = portNumToHostPort(v.GetString(prefix + suffixServerHostPort))
+		p.Server.IPFamily = v.GetString(prefix + suffixServerIPFamily)
 		b.Processors = append(b.Processors, *p)
 	}
 
 	b.HTTPServer.HostPort = portNumToHostPort(v.GetString(httpServerHostPort))
+	b.HTTPServer.IPFamily = v.GetString(httpServerIPFamily)
+	b.OTLPUnixSocketPath = v.GetString(otlpUnixSocketPath)
 	return b
 }
 
@@ -36,18 +36,25 @@ func TestBindFlags(t *testing.T) {
 
 	err := command.ParseFlags([]string{
 		"--http-server.host-port=:8080",
+		"--http-server.ip-family=tcp6",
+		"--processor.jaeger-binary.server-ip-family=udp4",
 		"--processor.jaeger-binary.server-host-port=:1111",
 		"--processor.jaeger-binary.server-max-packet-size=4242",
 		"--processor.jaeger-binary.server-queue-size=42",
 		"--processor.jaeger-binary.workers=42",
+		"--processor.otlp.unix-socket-path=/var/run/jaeger/otlp.sock",
 	})
 	require.NoError(t, err)
 
 	b.InitFromViper(v)
 	assert.Len(t, b.Processors, 3)
 	assert.Equal(t, ":8080", b.HTTPServer.HostPort)
+	assert.Equal(t, "tcp6", b.HTTPServer.IPFamily)
+	assert.Equal(t, "udp4", b.Processors[2].Server.IPFamily)
+	assert.Equal(t, "dual", b.Processors[0].Server.IPFamily)
 	assert.Equal(t, ":1111", b.Processors[2].Server.HostPort)
 	assert.Equal(t, 4242, b.Processors[2].Server.MaxPacketSize)
 	assert.Equal(t, 42, b.Processors[2].Server.QueueSize)
 	assert.Equal(t, 42, b.Processors[2].Workers)
+	assert.Equal(t, "/var/run/jaeger/otlp.sock", b.OTLPUnixSocketPath)
 }
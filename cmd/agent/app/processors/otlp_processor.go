@@ -0,0 +1,99 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
+	jConverter "github.com/jaegertracing/jaeger/model/converter/thrift/jaeger"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	otlp2jaeger "github.com/jaegertracing/jaeger/pkg/otlptranslator"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+)
+
+// OTLPProcessor is a gRPC server receiving OTLP traces on a Unix domain socket,
+// which hands them over to the reporter as Jaeger batches.
+type OTLPProcessor struct {
+	ptraceotlp.UnimplementedGRPCServer
+
+	server   *grpc.Server
+	listener net.Listener
+	reporter reporter.Reporter
+	logger   *zap.Logger
+	metrics  struct {
+		// Number of spans received over OTLP
+		Spans metrics.Counter `metric:"otlp.unix.spans"`
+
+		// Number of OTLP requests which could not be translated or reported
+		ExportErrors metrics.Counter `metric:"otlp.unix.export-errors"`
+	}
+}
+
+// NewOTLPProcessor creates an OTLPProcessor listening on the Unix domain socket at socketPath.
+// A socket left behind at the path by a previous run is removed.
+func NewOTLPProcessor(socketPath string, rep reporter.Reporter, mFactory metrics.Factory, logger *zap.Logger) (*OTLPProcessor, error) {
+	if info, err := os.Lstat(socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("cannot remove stale socket %s: %w", socketPath, err)
+		}
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on socket %s: %w", socketPath, err)
+	}
+	p := &OTLPProcessor{
+		server:   grpc.NewServer(),
+		listener: listener,
+		reporter: rep,
+		logger:   logger,
+	}
+	metrics.Init(&p.metrics, mFactory, nil)
+	ptraceotlp.RegisterGRPCServer(p.server, p)
+	return p, nil
+}
+
+// Serve starts serving traffic
+func (p *OTLPProcessor) Serve() {
+	p.logger.Info("Starting OTLP server on Unix domain socket", zap.String("socket", p.listener.Addr().String()))
+	if err := p.server.Serve(p.listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		p.logger.Error("OTLP server failure", zap.Error(err))
+	}
+}
+
+// Stop waits for the pending requests and stops the server, which removes the socket.
+func (p *OTLPProcessor) Stop() {
+	p.server.GracefulStop()
+}
+
+// Export implements ptraceotlp.GRPCServer.
+func (p *OTLPProcessor) Export(ctx context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	batches, err := otlp2jaeger.ProtoFromTraces(req.Traces())
+	if err != nil {
+		p.metrics.ExportErrors.Inc(1)
+		return ptraceotlp.NewExportResponse(), status.Errorf(codes.InvalidArgument, "cannot translate OTLP traces: %v", err)
+	}
+	for _, batch := range batches {
+		p.metrics.Spans.Inc(int64(len(batch.Spans)))
+		err := p.reporter.EmitBatch(ctx, &jaeger.Batch{
+			Process: jConverter.FromDomainProcess(batch.Process),
+			Spans:   jConverter.FromDomain(batch.Spans),
+		})
+		if err != nil {
+			p.metrics.ExportErrors.Inc(1)
+			return ptraceotlp.NewExportResponse(), status.Errorf(codes.Unavailable, "cannot report spans: %v", err)
+		}
+	}
+	return ptraceotlp.NewExportResponse(), nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package processors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+// batchReporter records the batches it reports.
+type batchReporter struct {
+	mu      sync.Mutex
+	batches []*jaeger.Batch
+	err     error
+}
+
+func (*batchReporter) EmitZipkinBatch(context.Context, []*zipkincore.Span) error {
+	return nil
+}
+
+func (r *batchReporter) EmitBatch(_ context.Context, batch *jaeger.Batch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, batch)
+	return r.err
+}
+
+func otlpTraces() ptrace.Traces {
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("service.name", "frontend")
+	span := resourceSpans.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("operation")
+	span.SetTraceID([16]byte{1})
+	span.SetSpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 2})
+	return traces
+}
+
+func startOTLPProcessor(t *testing.T, rep *batchReporter, mFactory *metricstest.Factory) (ptraceotlp.GRPCClient, string) {
+	socketPath := filepath.Join(t.TempDir(), "otlp.sock")
+	processor, err := NewOTLPProcessor(socketPath, rep, mFactory, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	go processor.Serve()
+	t.Cleanup(processor.Stop)
+
+	conn, err := grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return ptraceotlp.NewGRPCClient(conn), socketPath
+}
+
+func TestOTLPProcessor(t *testing.T) {
+	rep := &batchReporter{}
+	mFactory := metricstest.NewFactory(0)
+	client, _ := startOTLPProcessor(t, rep, mFactory)
+
+	_, err := client.Export(context.Background(), ptraceotlp.NewExportRequestFromTraces(otlpTraces()))
+	require.NoError(t, err)
+
+	require.Len(t, rep.batches, 1)
+	assert.Equal(t, "frontend", rep.batches[0].Process.ServiceName)
+	require.Len(t, rep.batches[0].Spans, 1)
+	assert.Equal(t, "operation", rep.batches[0].Spans[0].OperationName)
+	assert.Equal(t, int64(2), rep.batches[0].Spans[0].SpanId)
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "otlp.unix.spans", Value: 1})
+}
+
+func TestOTLPProcessorReportError(t *testing.T) {
+	rep := &batchReporter{err: errors.New("collector unavailable")}
+	mFactory := metricstest.NewFactory(0)
+	client, _ := startOTLPProcessor(t, rep, mFactory)
+
+	_, err := client.Export(context.Background(), ptraceotlp.NewExportRequestFromTraces(otlpTraces()))
+	require.ErrorContains(t, err, "collector unavailable")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "otlp.unix.export-errors", Value: 1})
+}
+
+func TestOTLPProcessorStop(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "otlp.sock")
+	processor, err := NewOTLPProcessor(socketPath, &batchReporter{}, metricstest.NewFactory(0), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		processor.Serve()
+		close(done)
+	}()
+	processor.Stop()
+	<-done
+	_, err = os.Stat(socketPath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestOTLPProcessorStaleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "otlp.sock")
+	// a listener closed without unlinking leaves the socket file behind, as after a crash
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	processor, err := NewOTLPProcessor(socketPath, &batchReporter{}, metricstest.NewFactory(0), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	processor.Stop()
+}
+
+func TestOTLPProcessorListenError(t *testing.T) {
+	_, err := NewOTLPProcessor(filepath.Join(t.TempDir(), "missing", "otlp.sock"), &batchReporter{}, metricstest.NewFactory(0), zaptest.NewLogger(t))
+	require.ErrorContains(t, err, "cannot listen on socket")
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
 	"go.uber.org/zap"
@@ -33,6 +34,8 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/discovery"
 	"github.com/jaegertracing/jaeger/pkg/discovery/grpcresolver"
+	"github.com/jaegertracing/jaeger/pkg/discovery/outlierdetection"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/netutils"
 )
@@ -45,10 +48,24 @@ type ConnBuilder struct {
 	MaxRetry uint
 	TLS      tlscfg.Options
 
+	// Compression is the name of the compressor of the messages sent to the collectors.
+	Compression string
+
 	DiscoveryMinPeers int
 	Notifier          discovery.Notifier
 	Discoverer        discovery.Discoverer
 
+	// DiscoverySRV is the DNS SRV name of the collectors, looked up every DiscoveryRefreshInterval,
+	// used unless a Notifier and a Discoverer are set.
+	DiscoverySRV             string
+	DiscoveryRefreshInterval time.Duration
+
+	// OutlierDetection ejects the collectors failing the requests, it is disabled if its
+	// FailureRateThreshold is zero and the requests are sent round robin.
+	OutlierDetection outlierdetection.Config
+	// HealthCheck excludes the collectors reported not serving by the gRPC health checks.
+	HealthCheck bool
+
 	AdditionalDialOptions []grpc.DialOption
 }
 
@@ -75,6 +92,12 @@ func (b *ConnBuilder) CreateConnection(ctx context.Context, logger *zap.Logger,
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
+	if b.Notifier == nil && b.Discoverer == nil && b.DiscoverySRV != "" {
+		logger.Info("Discovering the collectors with DNS SRV records", zap.String("name", b.DiscoverySRV))
+		srvDiscoverer := discovery.NewSRVDiscoverer(b.DiscoverySRV, logger)
+		go srvDiscoverer.Watch(ctx, b.DiscoveryRefreshInterval)
+		b.Notifier, b.Discoverer = srvDiscoverer, srvDiscoverer
+	}
 	if b.Notifier != nil && b.Discoverer != nil {
 		logger.Info("Using external discovery service with roundrobin load balancer")
 		grpcResolver := grpcresolver.New(b.Notifier, b.Discoverer, logger, b.DiscoveryMinPeers)
@@ -98,8 +121,16 @@ func (b *ConnBuilder) CreateConnection(ctx context.Context, logger *zap.Logger,
 			dialTarget = b.CollectorHostPorts[0]
 		}
 	}
-	dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(grpcresolver.GRPCServiceConfig))
+	serviceConfig, err := b.serviceConfig()
+	if err != nil {
+		return nil, err
+	}
+	dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(serviceConfig))
 	dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(retry.UnaryClientInterceptor(retry.WithMax(b.MaxRetry))))
+	dialOptions = append(dialOptions, grpccompression.DialOption(b.Compression))
+	dialOptions = append(dialOptions, grpc.WithStatsHandler(grpccompression.NewStatsHandler(
+		mFactory.Namespace(metrics.NSOptions{Tags: map[string]string{"protocol": "grpc"}}),
+	)))
 	dialOptions = append(dialOptions, b.AdditionalDialOptions...)
 
 	conn, err := grpc.NewClient(dialTarget, dialOptions...)
@@ -136,3 +167,21 @@ func (b *ConnBuilder) CreateConnection(ctx context.Context, logger *zap.Logger,
 
 	return conn, nil
 }
+
+func (b *ConnBuilder) serviceConfig() (string, error) {
+	healthCheckService := ""
+	if b.HealthCheck {
+		healthCheckService = "jaeger.api_v2.CollectorService"
+	}
+	if b.OutlierDetection.FailureRateThreshold == 0 {
+		if healthCheckService == "" {
+			return grpcresolver.GRPCServiceConfig, nil
+		}
+		return fmt.Sprintf(`{"loadBalancingPolicy":"round_robin","healthCheckConfig":{"serviceName":%q}}`, healthCheckService), nil
+	}
+	serviceConfig, err := outlierdetection.ServiceConfig(b.OutlierDetection, healthCheckService)
+	if err != nil {
+		return "", fmt.Errorf("invalid outlier detection options: %w", err)
+	}
+	return serviceConfig, nil
+}
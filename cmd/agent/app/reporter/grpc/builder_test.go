@@ -17,6 +17,7 @@ import (
 	"context"
 	"net"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -24,12 +25,15 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/discovery"
+	"github.com/jaegertracing/jaeger/pkg/discovery/outlierdetection"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
@@ -397,3 +401,90 @@ func TestBuilderWithAdditionalDialOptions(t *testing.T) {
 	err = r.Invoke(context.Background(), "test", map[string]string{}, map[string]string{}, []grpc.CallOption{}...)
 	require.Error(t, err, "should error because no server is running")
 }
+
+func TestBuilderWithDNSSRV(t *testing.T) {
+	cb := ConnBuilder{
+		DiscoverySRV:             "_grpc._tcp.jaeger-collector.invalid",
+		DiscoveryRefreshInterval: time.Hour,
+		DiscoveryMinPeers:        3,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn, err := cb.CreateConnection(ctx, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.True(t, strings.HasSuffix(conn.Target(), "///round_robin"))
+	assert.IsType(t, &discovery.SRVDiscoverer{}, cb.Notifier)
+	assert.IsType(t, &discovery.SRVDiscoverer{}, cb.Discoverer)
+}
+
+func TestBuilderServiceConfig(t *testing.T) {
+	cb := ConnBuilder{}
+	serviceConfig, err := cb.serviceConfig()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"loadBalancingPolicy":"round_robin"}`, serviceConfig)
+
+	cb.HealthCheck = true
+	serviceConfig, err = cb.serviceConfig()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"loadBalancingPolicy":"round_robin","healthCheckConfig":{"serviceName":"jaeger.api_v2.CollectorService"}}`, serviceConfig)
+
+	cb.OutlierDetection = outlierdetection.Config{FailureRateThreshold: 0.5}
+	_, err = cb.serviceConfig()
+	require.ErrorContains(t, err, "invalid outlier detection options")
+	cb.CollectorHostPorts = []string{"127.0.0.1:14268"}
+	_, err = cb.CreateConnection(context.Background(), zap.NewNop(), metrics.NullFactory)
+	require.ErrorContains(t, err, "invalid outlier detection options")
+}
+
+type failingSpanHandler struct {
+	requests atomic.Int64
+}
+
+func (h *failingSpanHandler) PostSpans(context.Context, *api_v2.PostSpansRequest) (*api_v2.PostSpansResponse, error) {
+	h.requests.Add(1)
+	return nil, status.Error(codes.Unavailable, "overloaded")
+}
+
+func TestBuilderWithOutlierDetection(t *testing.T) {
+	healthyHandler := &mockSpanHandler{}
+	_, healthyAddr := initializeGRPCTestServer(t, func(s *grpc.Server) {
+		api_v2.RegisterCollectorServiceServer(s, healthyHandler)
+	})
+	failingHandler := &failingSpanHandler{}
+	_, failingAddr := initializeGRPCTestServer(t, func(s *grpc.Server) {
+		api_v2.RegisterCollectorServiceServer(s, failingHandler)
+	})
+
+	cb := ConnBuilder{
+		CollectorHostPorts: []string{healthyAddr.String(), failingAddr.String()},
+		OutlierDetection: outlierdetection.Config{
+			FailureRateThreshold: 0.5,
+			MinimumRequests:      2,
+			Interval:             10 * time.Millisecond,
+			EjectionTime:         time.Minute,
+			MaxEjectionPercent:   50,
+		},
+		// the test servers have no health service, the collectors are then considered serving
+		HealthCheck: true,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn, err := cb.CreateConnection(ctx, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := api_v2.NewCollectorServiceClient(conn)
+
+	// the failing collector is ejected, all the spans are then sent to the healthy collector
+	assert.Eventually(t, func() bool {
+		failures := failingHandler.requests.Load()
+		for i := 0; i < 10; i++ {
+			client.PostSpans(context.Background(), &api_v2.PostSpansRequest{})
+		}
+		return failingHandler.requests.Load() == failures
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		_, err := client.PostSpans(context.Background(), &api_v2.PostSpansRequest{})
+		require.NoError(t, err)
+	}
+}
@@ -18,10 +18,13 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/discovery/outlierdetection"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 )
 
 const (
@@ -30,6 +33,17 @@ const (
 	retryFlag         = gRPCPrefix + ".retry.max"
 	defaultMaxRetry   = 3
 	discoveryMinPeers = gRPCPrefix + ".discovery.min-peers"
+	compression       = gRPCPrefix + ".compression"
+	discoverySRV      = gRPCPrefix + ".discovery.dns-srv"
+	discoveryRefresh  = gRPCPrefix + ".discovery.refresh-interval"
+	healthCheck       = gRPCPrefix + ".health-check"
+
+	outlierDetectionPrefix  = gRPCPrefix + ".outlier-detection"
+	outlierFailureRate      = outlierDetectionPrefix + ".failure-rate"
+	outlierMinRequests      = outlierDetectionPrefix + ".min-requests"
+	outlierInterval         = outlierDetectionPrefix + ".interval"
+	outlierEjectionTime     = outlierDetectionPrefix + ".ejection-time"
+	outlierMaxEjectionRatio = outlierDetectionPrefix + ".max-ejection-percent"
 )
 
 var tlsFlagsConfig = tlscfg.ClientFlagsConfig{
@@ -41,6 +55,15 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.Uint(retryFlag, defaultMaxRetry, "Sets the maximum number of retries for a call")
 	flags.Int(discoveryMinPeers, 3, "Max number of collectors to which the agent will try to connect at any given time")
 	flags.String(collectorHostPort, "", "Comma-separated string representing host:port of a static list of collectors to connect to directly")
+	flags.String(compression, grpccompression.None, "The compression of the spans sent to the collectors: "+strings.Join(grpccompression.Compressors, ", "))
+	flags.String(discoverySRV, "", "The DNS SRV name of the collectors, e.g. _grpc._tcp.jaeger-collector.observability.svc.cluster.local, used instead of the static list of collectors")
+	flags.Duration(discoveryRefresh, 30*time.Second, "How often the DNS SRV records of the collectors are looked up")
+	flags.Bool(healthCheck, true, "Whether the collectors reported not serving by the gRPC health checks are excluded")
+	flags.Float64(outlierFailureRate, 0.5, "The ratio of failed requests over which a collector is ejected, 0 disables the outlier detection")
+	flags.Int(outlierMinRequests, 10, "The number of requests sent to a collector during an interval under which it is not ejected")
+	flags.Duration(outlierInterval, 10*time.Second, "How often the failure rates of the collectors are evaluated")
+	flags.Duration(outlierEjectionTime, 30*time.Second, "How long a collector is ejected, multiplied by the number of its consecutive ejections")
+	flags.Int(outlierMaxEjectionRatio, 50, "The maximum percentage of the collectors ejected at the same time")
 	tlsFlagsConfig.AddFlags(flags)
 }
 
@@ -57,5 +80,25 @@ func (b *ConnBuilder) InitFromViper(v *viper.Viper) (*ConnBuilder, error) {
 	}
 	b.TLS = tls
 	b.DiscoveryMinPeers = v.GetInt(discoveryMinPeers)
+	b.Compression = v.GetString(compression)
+	b.DiscoverySRV = v.GetString(discoverySRV)
+	b.DiscoveryRefreshInterval = v.GetDuration(discoveryRefresh)
+	b.HealthCheck = v.GetBool(healthCheck)
+	b.OutlierDetection = outlierdetection.Config{
+		FailureRateThreshold: v.GetFloat64(outlierFailureRate),
+		MinimumRequests:      v.GetInt(outlierMinRequests),
+		Interval:             v.GetDuration(outlierInterval),
+		EjectionTime:         v.GetDuration(outlierEjectionTime),
+		MaxEjectionPercent:   v.GetInt(outlierMaxEjectionRatio),
+	}
+	if err := grpccompression.Validate(b.Compression); err != nil {
+		return b, err
+	}
+	if b.DiscoverySRV != "" && b.DiscoveryRefreshInterval <= 0 {
+		return b, fmt.Errorf("the refresh interval of the DNS SRV records must be positive, got %v", b.DiscoveryRefreshInterval)
+	}
+	if _, err := b.serviceConfig(); err != nil {
+		return b, err
+	}
 	return b, nil
 }
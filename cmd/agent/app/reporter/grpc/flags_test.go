@@ -17,6 +17,7 @@ package grpc
 import (
 	"flag"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -24,8 +25,23 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/discovery/outlierdetection"
 )
 
+// withDefaults sets the defaults of the discovery and load balancing flags.
+func withDefaults(b *ConnBuilder) *ConnBuilder {
+	b.DiscoveryRefreshInterval = 30 * time.Second
+	b.HealthCheck = true
+	b.OutlierDetection = outlierdetection.Config{
+		FailureRateThreshold: 0.5,
+		MinimumRequests:      10,
+		Interval:             10 * time.Second,
+		EjectionTime:         30 * time.Second,
+		MaxEjectionPercent:   50,
+	}
+	return b
+}
+
 func TestBindFlags(t *testing.T) {
 	tests := []struct {
 		cOpts    []string
@@ -33,15 +49,34 @@ func TestBindFlags(t *testing.T) {
 	}{
 		{
 			cOpts:    []string{"--reporter.grpc.host-port=localhost:1111", "--reporter.grpc.retry.max=15"},
-			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: 15, DiscoveryMinPeers: 3},
+			expected: withDefaults(&ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: 15, DiscoveryMinPeers: 3, Compression: "none"}),
 		},
 		{
 			cOpts:    []string{"--reporter.grpc.host-port=localhost:1111,localhost:2222"},
-			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3},
+			expected: withDefaults(&ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3, Compression: "none"}),
 		},
 		{
 			cOpts:    []string{"--reporter.grpc.host-port=localhost:1111,localhost:2222", "--reporter.grpc.discovery.min-peers=5"},
-			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 5},
+			expected: withDefaults(&ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 5, Compression: "none"}),
+		},
+		{
+			cOpts:    []string{"--reporter.grpc.host-port=localhost:1111", "--reporter.grpc.compression=zstd"},
+			expected: withDefaults(&ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3, Compression: "zstd"}),
+		},
+		{
+			cOpts: []string{
+				"--reporter.grpc.discovery.dns-srv=_grpc._tcp.jaeger-collector",
+				"--reporter.grpc.discovery.refresh-interval=1m",
+				"--reporter.grpc.health-check=false",
+				"--reporter.grpc.outlier-detection.failure-rate=0",
+			},
+			expected: &ConnBuilder{
+				MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3, Compression: "none",
+				DiscoverySRV: "_grpc._tcp.jaeger-collector", DiscoveryRefreshInterval: time.Minute,
+				OutlierDetection: outlierdetection.Config{
+					MinimumRequests: 10, Interval: 10 * time.Second, EjectionTime: 30 * time.Second, MaxEjectionPercent: 50,
+				},
+			},
 		},
 	}
 	for _, test := range tests {
@@ -60,6 +95,16 @@ func TestBindFlags(t *testing.T) {
 	}
 }
 
+func TestBindCompressionFlagFailure(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--reporter.grpc.compression=brotli",
+	})
+	require.NoError(t, err)
+	_, err = new(ConnBuilder).InitFromViper(v)
+	require.ErrorContains(t, err, `unsupported gRPC compression "brotli"`)
+}
+
 func TestBindTLSFlagFailure(t *testing.T) {
 	v, command := config.Viperize(AddFlags)
 	err := command.ParseFlags([]string{
@@ -71,3 +116,25 @@ func TestBindTLSFlagFailure(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to process TLS options")
 }
+
+func TestBindLoadBalancingFlagsFailure(t *testing.T) {
+	tests := []struct {
+		cOpts []string
+		err   string
+	}{
+		{
+			cOpts: []string{"--reporter.grpc.discovery.dns-srv=_grpc._tcp.jaeger-collector", "--reporter.grpc.discovery.refresh-interval=0s"},
+			err:   "the refresh interval of the DNS SRV records must be positive, got 0s",
+		},
+		{
+			cOpts: []string{"--reporter.grpc.outlier-detection.failure-rate=1.5"},
+			err:   "invalid outlier detection options: the failure rate threshold must be in (0, 1], got 1.5",
+		},
+	}
+	for _, test := range tests {
+		v, command := config.Viperize(AddFlags)
+		require.NoError(t, command.ParseFlags(test.cOpts))
+		_, err := new(ConnBuilder).InitFromViper(v)
+		require.EqualError(t, err, test.err)
+	}
+}
@@ -79,11 +79,17 @@ func createClient(destAddr, locAddr *net.UDPAddr) (*TUDPTransport, error) {
 //
 //	trans, err := thriftudp.NewTUDPClientTransport("localhost:9001")
 func NewTUDPServerTransport(hostPort string) (*TUDPTransport, error) {
-	addr, err := net.ResolveUDPAddr("udp", hostPort)
+	return NewTUDPServerTransportWithNetwork("udp", hostPort)
+}
+
+// NewTUDPServerTransportWithNetwork is like NewTUDPServerTransport, listening with the Go UDP
+// network, e.g. udp4 or udp6 to only receive the packets of one IP family.
+func NewTUDPServerTransportWithNetwork(network, hostPort string) (*TUDPTransport, error) {
+	addr, err := net.ResolveUDPAddr(network, hostPort)
 	if err != nil {
 		return nil, thrift.NewTTransportException(thrift.NOT_OPEN, err.Error())
 	}
-	conn, err := net.ListenUDP(addr.Network(), addr)
+	conn, err := net.ListenUDP(network, addr)
 	if err != nil {
 		return nil, thrift.NewTTransportException(thrift.NOT_OPEN, err.Error())
 	}
@@ -79,6 +79,16 @@ func TestNewTUDPServerTransport(t *testing.T) {
 	require.False(t, trans.IsOpen())
 }
 
+func TestNewTUDPServerTransportWithNetwork(t *testing.T) {
+	trans, err := NewTUDPServerTransportWithNetwork("udp4", ":0")
+	require.NoError(t, err)
+	assert.Contains(t, trans.Addr().String(), "0.0.0.0:")
+	require.NoError(t, trans.Close())
+
+	_, err = NewTUDPServerTransportWithNetwork("udp4", "[::1]:0")
+	require.Error(t, err)
+}
+
 func TestSetSocketBufferSize(t *testing.T) {
 	trans, err := NewTUDPServerTransport(localListenAddr.String())
 	require.NoError(t, err)
@@ -55,10 +55,12 @@ func main() {
 				return err
 			}
 			logger := svc.Logger // shortcut
+			telset, err := svc.TelemetrySetting("jaeger-agent", nil)
+			if err != nil {
+				logger.Fatal("Failed to create telemetry", zap.Error(err))
+			}
 
-			mFactory := svc.MetricsFactory.
-				Namespace(metrics.NSOptions{Name: "jaeger"}).
-				Namespace(metrics.NSOptions{Name: "agent"})
+			mFactory := telset.Metrics.Namespace(metrics.NSOptions{Name: "agent"})
 			version.NewInfoMetrics(mFactory)
 
 			rOpts := new(reporter.Options).InitFromViper(v, logger)
@@ -96,6 +98,9 @@ func main() {
 			svc.RunAndThen(func() {
 				agent.Stop()
 				cp.Close()
+				if err := telset.Close(context.Background()); err != nil {
+					logger.Error("Failed to close telemetry", zap.Error(err))
+				}
 			})
 			return nil
 		},
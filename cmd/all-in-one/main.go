@@ -43,16 +43,19 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
 	"github.com/jaegertracing/jaeger/pkg/version"
 	metricsPlugin "github.com/jaegertracing/jaeger/plugin/metrics"
 	ss "github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/stored"
 	"github.com/jaegertracing/jaeger/plugin/storage"
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
 	metricsstoreMetrics "github.com/jaegertracing/jaeger/storage/metricsstore/metrics"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
-	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
 
 // all-in-one/main is a standalone full-stack jaeger backend, backed by a memory store
@@ -94,21 +97,28 @@ by default uses only in-memory database.`,
 				return err
 			}
 			logger := svc.Logger // shortcut
-			baseFactory := svc.MetricsFactory.Namespace(metrics.NSOptions{Name: "jaeger"})
-			version.NewInfoMetrics(baseFactory)
-			agentMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "agent"})
-			collectorMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "collector"})
-			queryMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "query"})
-
 			tracer, err := jtracer.New("jaeger-all-in-one")
 			if err != nil {
 				logger.Fatal("Failed to initialize tracer", zap.Error(err))
 			}
+			// the collector is not traced, since the tracer exports its spans to it
+			svc.Admin.Handle("/debug/trace", jtracer.NewCaptureHandler(tracer.Capture, logger))
+			telset, err := svc.TelemetrySetting("jaeger-all-in-one", tracer)
+			if err != nil {
+				logger.Fatal("Failed to create telemetry", zap.Error(err))
+			}
+			baseFactory := telset.Metrics
+			version.NewInfoMetrics(baseFactory)
+			agentMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "agent"})
+			collectorMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "collector"})
+			queryMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "query"})
 
 			storageFactory.InitFromViper(v, logger)
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			svc.HC().AddProbe("storage", storageFactory.CheckHealth)
+			svc.Admin.Handle("/storage/capabilities", storageFactory.CapabilitiesHandler())
 
 			spanReader, err := storageFactory.CreateSpanReader()
 			if err != nil {
@@ -118,6 +128,7 @@ by default uses only in-memory database.`,
 			if err != nil {
 				logger.Fatal("Failed to create span writer", zap.Error(err))
 			}
+			spanWriter = svc.Admin.Status().InstrumentSpanWriter(spanWriter)
 			dependencyReader, err := storageFactory.CreateDependencyReader()
 			if err != nil {
 				logger.Fatal("Failed to create dependency reader", zap.Error(err))
@@ -141,6 +152,14 @@ by default uses only in-memory database.`,
 			if err != nil {
 				logger.Fatal("Failed to create sampling strategy provider", zap.Error(err))
 			}
+			// the strategies set with the query service API take precedence
+			var storedStrategies io.Closer
+			if storedOpts := new(stored.Options).InitFromViper(v); storedOpts.Enabled() {
+				if store := collectorApp.CreateSamplingStrategyStore(storageFactory, logger); store != nil {
+					samplingProvider = stored.NewProvider(samplingProvider, store, *storedOpts, logger, collectorMetricsFactory)
+					storedStrategies = samplingProvider
+				}
+			}
 
 			aOpts := new(agentApp.Builder).InitFromViper(v)
 			repOpts := new(agentRep.Options).InitFromViper(v, logger)
@@ -158,6 +177,18 @@ by default uses only in-memory database.`,
 			}
 
 			tm := tenancy.NewManager(&cOpts.GRPC.Tenancy)
+			var metadataStore metadatastore.Store
+			if cOpts.ServiceMetadataFromProcessTags {
+				metadataStore = collectorApp.CreateMetadataStore(storageFactory, logger)
+			}
+			// the live tail streams the traces processed by the collector
+			if qOpts.LiveTail.Enabled {
+				qOpts.TraceBroadcaster = tracestream.NewBroadcaster()
+			}
+			var recentTraces *tracestream.RecentTraces
+			if rtOpts := new(tracestream.RecentTracesOptions).InitFromViper(v); rtOpts.MaxTraces > 0 {
+				recentTraces = tracestream.NewRecentTraces(rtOpts.MaxTraces)
+			}
 
 			// collector
 			c := collectorApp.New(&collectorApp.CollectorParams{
@@ -169,10 +200,17 @@ by default uses only in-memory database.`,
 				SamplingAggregator: samplingAggregator,
 				HealthCheck:        svc.HC(),
 				TenancyMgr:         tm,
+				MeterProvider:      telset.MeterProvider,
+				MetadataStore:      metadataStore,
+				TraceBroadcaster:   qOpts.TraceBroadcaster,
+				RecentTraces:       recentTraces,
 			})
 			if err := c.Start(cOpts); err != nil {
 				log.Fatal(err)
 			}
+			svc.Admin.Status().Register("queue", func() any {
+				return c.QueueStatus()
+			})
 
 			// agent
 			// if the agent reporter grpc host:port was not explicitly set then use whatever the collector is listening on
@@ -195,25 +233,29 @@ by default uses only in-memory database.`,
 			agent := startAgent(cp, aOpts, logger, agentMetricsFactory)
 
 			// query
+			queryServiceOptions := qOpts.BuildQueryServiceOptions(storageFactory, logger)
+			queryServiceOptions.RecentTraces = recentTraces
 			querySrv := startQuery(
-				svc, qOpts, qOpts.BuildQueryServiceOptions(storageFactory, logger),
+				svc, qOpts, queryServiceOptions,
 				spanReader, dependencyReader, metricsQueryService,
-				queryMetricsFactory, tm, tracer,
+				tm, tracer,
 			)
 
 			svc.RunAndThen(func() {
 				agent.Stop()
 				_ = cp.Close()
 				_ = c.Close()
-				_ = querySrv.Close()
-				if closer, ok := spanWriter.(io.Closer); ok {
-					if err := closer.Close(); err != nil {
-						logger.Error("Failed to close span writer", zap.Error(err))
-					}
+				if storedStrategies != nil {
+					_ = storedStrategies.Close()
 				}
+				_ = querySrv.Close()
+				// the storage factory closes the span writers it created
 				if err := storageFactory.Close(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
+				if err := telset.Close(context.Background()); err != nil {
+					logger.Error("Failed to close telemetry", zap.Error(err))
+				}
 				if err := tracer.Close(context.Background()); err != nil {
 					logger.Error("Error shutting down tracer provider", zap.Error(err))
 				}
@@ -227,6 +269,7 @@ by default uses only in-memory database.`,
 	command.AddCommand(docs.Command(v))
 	command.AddCommand(status.Command(v, ports.CollectorAdminHTTP))
 	command.AddCommand(printconfig.Command(v))
+	command.AddCommand(telemetery.Command())
 
 	config.AddFlags(
 		v,
@@ -238,7 +281,9 @@ by default uses only in-memory database.`,
 		agentGrpcRep.AddFlags,
 		collectorFlags.AddFlags,
 		queryApp.AddFlags,
+		tracestream.AddFlags,
 		samplingStrategyFactory.AddFlags,
+		stored.AddFlags,
 		metricsReaderFactory.AddFlags,
 	)
 
@@ -273,11 +318,9 @@ func startQuery(
 	spanReader spanstore.Reader,
 	depReader dependencystore.Reader,
 	metricsQueryService querysvc.MetricsQueryService,
-	metricsFactory metrics.Factory,
 	tm *tenancy.Manager,
 	jt *jtracer.JTracer,
 ) *queryApp.Server {
-	spanReader = storageMetrics.NewReadMetricsDecorator(spanReader, metricsFactory)
 	qs := querysvc.NewQueryService(spanReader, depReader, *queryOpts)
 	server, err := queryApp.NewServer(svc.Logger, svc.HC(), qs, metricsQueryService, qOpts, tm, jt)
 	if err != nil {
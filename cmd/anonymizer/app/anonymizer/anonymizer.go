@@ -54,6 +54,7 @@ type mapping struct {
 // Jaeger UI format, to make it easy to visualize traces.
 //
 // The mapping from original to obfuscated strings is stored in a file and can be reused between runs.
+// With an HMAC key the pseudonyms are derived from the key alone, so the mapping file is not used.
 type Anonymizer struct {
 	mappingFile string
 	logger      *zap.Logger
@@ -62,6 +63,8 @@ type Anonymizer struct {
 	options     Options
 	cancel      context.CancelFunc
 	wg          sync.WaitGroup
+	// pseudonymizer is only set when Options.HMACKey is configured.
+	pseudonymizer *pseudonymizer
 }
 
 // Options represents the various options with which the anonymizer can be configured.
@@ -70,6 +73,10 @@ type Options struct {
 	HashCustomTags   bool `yaml:"hash_custom_tags" name:"hash_custom_tags"`
 	HashLogs         bool `yaml:"hash_logs" name:"hash_logs"`
 	HashProcess      bool `yaml:"hash_process" name:"hash_process"`
+	// HMACKey enables the structural-preserving mode, when set. Service names,
+	// operation names and tag values are replaced with deterministic pseudonyms
+	// derived from this key, and tag keys and value types are preserved.
+	HMACKey string `yaml:"hmac_key" name:"hmac_key"`
 }
 
 // New creates new Anonymizer. The mappingFile stores the mapping from original to
@@ -86,6 +93,11 @@ func New(mappingFile string, options Options, logger *zap.Logger) *Anonymizer {
 		options: options,
 		cancel:  cancel,
 	}
+	if options.HMACKey != "" {
+		a.pseudonymizer = newPseudonymizer(options.HMACKey)
+		// the mapping of a previous run may have been made without the key or with another one
+		return a
+	}
 	if _, err := os.Stat(filepath.Clean(mappingFile)); err == nil {
 		dat, err := os.ReadFile(filepath.Clean(mappingFile))
 		if err != nil {
@@ -119,8 +131,11 @@ func (a *Anonymizer) Stop() {
 
 // SaveMapping writes the mapping from original to obfuscated strings to a file.
 // It is called by the anonymizer itself periodically, and should be called at
-// the end of the extraction run.
+// the end of the extraction run. It does nothing with an HMAC key.
 func (a *Anonymizer) SaveMapping() {
+	if a.pseudonymizer != nil {
+		return
+	}
 	a.lock.Lock()
 	defer a.lock.Unlock()
 	dat, err := json.Marshal(a.mapping)
@@ -145,6 +160,9 @@ func (a *Anonymizer) mapOperationName(service, operation string) string {
 }
 
 func (a *Anonymizer) mapString(v string, m map[string]string) string {
+	if a.pseudonymizer != nil {
+		return a.pseudonymizer.pseudonym(v)
+	}
 	a.lock.Lock()
 	defer a.lock.Unlock()
 	if s, ok := m[v]; ok {
@@ -155,6 +173,13 @@ func (a *Anonymizer) mapString(v string, m map[string]string) string {
 	return s
 }
 
+func (a *Anonymizer) hashTags(tags []model.KeyValue) []model.KeyValue {
+	if a.pseudonymizer != nil {
+		return a.pseudonymizer.pseudonymizeTags(tags)
+	}
+	return hashTags(tags)
+}
+
 func hash(value string) string {
 	h := fnv.New64()
 	_, _ = h.Write([]byte(value))
@@ -169,11 +194,11 @@ func (a *Anonymizer) AnonymizeSpan(span *model.Span) *uimodel.Span {
 	outputTags := filterStandardTags(span.Tags)
 	// when true, the allowedTags are hashed and when false they are preserved as it is
 	if a.options.HashStandardTags {
-		outputTags = hashTags(outputTags)
+		outputTags = a.hashTags(outputTags)
 	}
 	// when true, all tags other than allowedTags are hashed, when false they are dropped
 	if a.options.HashCustomTags {
-		customTags := hashTags(filterCustomTags(span.Tags))
+		customTags := a.hashTags(filterCustomTags(span.Tags))
 		outputTags = append(outputTags, customTags...)
 	}
 	span.Tags = outputTags
@@ -181,7 +206,7 @@ func (a *Anonymizer) AnonymizeSpan(span *model.Span) *uimodel.Span {
 	// when true, logs are hashed, when false, they are dropped
 	if a.options.HashLogs {
 		for _, log := range span.Logs {
-			log.Fields = hashTags(log.Fields)
+			log.Fields = a.hashTags(log.Fields)
 		}
 	} else {
 		span.Logs = nil
@@ -191,7 +216,7 @@ func (a *Anonymizer) AnonymizeSpan(span *model.Span) *uimodel.Span {
 
 	// when true, process tags are hashed, when false they are dropped
 	if a.options.HashProcess {
-		span.Process.Tags = hashTags(span.Process.Tags)
+		span.Process.Tags = a.hashTags(span.Process.Tags)
 	} else {
 		span.Process.Tags = nil
 	}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package anonymizer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"regexp"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// pseudonymizer replaces values with deterministic pseudonyms derived from
+// a keyed HMAC-SHA256, so that the same input always produces the same output
+// for a given key, and outputs of different runs can be joined together.
+// IP addresses and UUIDs are mapped to values of the same format.
+type pseudonymizer struct {
+	key []byte
+}
+
+func newPseudonymizer(key string) *pseudonymizer {
+	return &pseudonymizer{key: []byte(key)}
+}
+
+func (p *pseudonymizer) sum(value string) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	_, _ = mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// pseudonym returns the pseudonym of the value.
+func (p *pseudonymizer) pseudonym(value string) string {
+	sum := p.sum(value)
+	if addr, err := netip.ParseAddr(value); err == nil {
+		if addr.Is4() {
+			return netip.AddrFrom4([4]byte(sum[:4])).String()
+		}
+		return netip.AddrFrom16([16]byte(sum[:16])).String()
+	}
+	if uuidRegex.MatchString(value) {
+		b := sum[:16]
+		b[6] = (b[6] & 0x0f) | 0x40 // version 4
+		b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	}
+	return hex.EncodeToString(sum[:8])
+}
+
+// pseudonymizeTags replaces the values of the tags with their pseudonyms,
+// while preserving the keys and the value types. String, binary and integer values
+// are replaced, boolean and floating point values are kept as is.
+func (p *pseudonymizer) pseudonymizeTags(tags []model.KeyValue) []model.KeyValue {
+	out := make([]model.KeyValue, 0, len(tags))
+	for _, tag := range tags {
+		switch tag.VType {
+		case model.StringType:
+			tag = model.String(tag.Key, p.pseudonym(tag.VStr))
+		case model.BinaryType:
+			tag = model.Binary(tag.Key, p.sum(string(tag.VBinary)))
+		case model.Int64Type:
+			n := binary.BigEndian.Uint64(p.sum(tag.AsString())) >> 1
+			tag = model.Int64(tag.Key, int64(n))
+		}
+		out = append(out, tag)
+	}
+	return out
+}
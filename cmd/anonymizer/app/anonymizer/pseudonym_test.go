@@ -0,0 +1,124 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package anonymizer
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestPseudonymizer_Deterministic(t *testing.T) {
+	p1 := newPseudonymizer("secret")
+	p2 := newPseudonymizer("secret")
+	p3 := newPseudonymizer("other-secret")
+
+	assert.Equal(t, p1.pseudonym("foobar"), p2.pseudonym("foobar"))
+	assert.NotEqual(t, p1.pseudonym("foobar"), p3.pseudonym("foobar"))
+	assert.NotEqual(t, p1.pseudonym("foobar"), p1.pseudonym("foobaz"))
+	assert.Len(t, p1.pseudonym("foobar"), 16)
+}
+
+func TestPseudonymizer_FormatPreserving(t *testing.T) {
+	p := newPseudonymizer("secret")
+
+	ipv4 := p.pseudonym("10.0.0.1")
+	addr, err := netip.ParseAddr(ipv4)
+	require.NoError(t, err)
+	assert.True(t, addr.Is4())
+	assert.NotEqual(t, "10.0.0.1", ipv4)
+
+	ipv6 := p.pseudonym("2001:db8::1")
+	addr, err = netip.ParseAddr(ipv6)
+	require.NoError(t, err)
+	assert.True(t, addr.Is6())
+
+	uuid := p.pseudonym("123e4567-e89b-12d3-a456-426614174000")
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, uuid)
+	assert.NotEqual(t, "123e4567-e89b-12d3-a456-426614174000", uuid)
+}
+
+func TestPseudonymizer_Tags(t *testing.T) {
+	p := newPseudonymizer("secret")
+	tags := []model.KeyValue{
+		model.String("peer.ipv4", "10.0.0.1"),
+		model.Int64("user.id", 12345),
+		model.Bool("error", true),
+		model.Float64("ratio", 0.5),
+		model.Binary("payload", []byte("data")),
+	}
+
+	out := p.pseudonymizeTags(tags)
+	require.Len(t, out, len(tags))
+	for i := range tags {
+		assert.Equal(t, tags[i].Key, out[i].Key)
+		assert.Equal(t, tags[i].VType, out[i].VType)
+	}
+	assert.Equal(t, p.pseudonym("10.0.0.1"), out[0].VStr)
+	assert.NotEqual(t, int64(12345), out[1].VInt64)
+	assert.GreaterOrEqual(t, out[1].VInt64, int64(0))
+	assert.Equal(t, tags[2], out[2])
+	assert.Equal(t, tags[3], out[3])
+	assert.NotEqual(t, tags[4].VBinary, out[4].VBinary)
+	assert.Equal(t, out, p.pseudonymizeTags(tags))
+}
+
+func TestAnonymizer_AnonymizeSpan_HMAC(t *testing.T) {
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(1),
+		OperationName: "GET /users",
+		Tags: []model.KeyValue{
+			model.String("http.method", "GET"),
+			model.String("user.email", "john@example.com"),
+		},
+		Process: &model.Process{
+			ServiceName: "frontend",
+			Tags:        []model.KeyValue{model.String("ip", "192.168.0.1")},
+		},
+	}
+	anonymizer := &Anonymizer{
+		mapping: mapping{
+			Services:   make(map[string]string),
+			Operations: make(map[string]string),
+		},
+		options: Options{
+			HashCustomTags: true,
+			HashProcess:    true,
+			HMACKey:        "secret",
+		},
+		pseudonymizer: newPseudonymizer("secret"),
+	}
+	p := newPseudonymizer("secret")
+
+	_ = anonymizer.AnonymizeSpan(span)
+	assert.Equal(t, p.pseudonym("frontend"), span.Process.ServiceName)
+	assert.Equal(t, p.pseudonym("[frontend]:GET /users"), span.OperationName)
+	assert.Equal(t, []model.KeyValue{
+		model.String("http.method", "GET"),
+		model.String("user.email", p.pseudonym("john@example.com")),
+	}, span.Tags)
+	assert.Equal(t, []model.KeyValue{model.String("ip", p.pseudonym("192.168.0.1"))}, span.Process.Tags)
+}
+
+func TestNew_HMACIgnoresMappingFile(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(`{"services": {"frontend": "hashed_frontend"}}`), PermUserRW))
+
+	anonymizer := New(mappingFile, Options{HMACKey: "secret"}, zap.NewNop())
+	defer anonymizer.Stop()
+	assert.Equal(t, newPseudonymizer("secret").pseudonym("frontend"), anonymizer.mapServiceName("frontend"))
+
+	anonymizer.SaveMapping()
+	dat, err := os.ReadFile(mappingFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"services": {"frontend": "hashed_frontend"}}`, string(dat))
+}
@@ -15,9 +15,20 @@
 package app
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
+const (
+	// SourceQuery reads traces from jaeger-query gRPC API.
+	SourceQuery = "query"
+	// SourceStorage reads traces directly from the configured storage backend.
+	SourceStorage = "storage"
+)
+
 // Options represent configurable parameters for jaeger-anonymizer
 type Options struct {
 	QueryGRPCHostPort string
@@ -28,6 +39,13 @@ type Options struct {
 	HashCustomTags    bool
 	HashLogs          bool
 	HashProcess       bool
+	HMACKeyFile       string
+	Source            string
+	ServiceNames      []string
+	OperationName     string
+	StartTime         string
+	EndTime           string
+	MaxTraces         int
 }
 
 const (
@@ -39,6 +57,15 @@ const (
 	hashLogsFlag          = "hash-logs"
 	hashProcessFlag       = "hash-process"
 	maxSpansCount         = "max-spans-count"
+	hmacKeyFileFlag       = "hmac-key-file"
+	sourceFlag            = "source"
+	serviceNameFlag       = "service-name"
+	operationNameFlag     = "operation-name"
+	startTimeFlag         = "start-time"
+	endTimeFlag           = "end-time"
+	maxTracesFlag         = "max-traces"
+
+	defaultLookback = time.Hour
 )
 
 // AddFlags adds flags for anonymizer main program
@@ -57,7 +84,8 @@ func (o *Options) AddFlags(command *cobra.Command) {
 		&o.TraceID,
 		traceIDFlag,
 		"",
-		"The trace-id of trace to anonymize")
+		"The trace-id of trace to anonymize. Mandatory when reading from jaeger-query, "+
+			"when reading from storage and not set, traces are searched using service and time range filters")
 	command.Flags().BoolVar(
 		&o.HashStandardTags,
 		hashStandardTagsFlag,
@@ -78,12 +106,103 @@ func (o *Options) AddFlags(command *cobra.Command) {
 		hashProcessFlag,
 		false,
 		"Whether to hash process")
+	command.Flags().StringVar(
+		&o.HMACKeyFile,
+		hmacKeyFileFlag,
+		"",
+		"Path to a file with a secret key. When set, service names, operation names and tag values are replaced "+
+			"with deterministic keyed pseudonyms (preserving the format of IPs and UUIDs) instead of plain hashes, "+
+			"so that traces anonymized with the same key remain joinable across files, without a mapping file")
 	command.Flags().IntVar(
 		&o.MaxSpansCount,
 		maxSpansCount,
 		-1,
 		"The maximum number of spans to anonymize")
+	command.Flags().StringVar(
+		&o.Source,
+		sourceFlag,
+		SourceQuery,
+		fmt.Sprintf("Where to read traces from: %q (jaeger-query gRPC API) or %q (storage backend configured via SPAN_STORAGE_TYPE and the storage flags, listed by --help with --%s=%s)", SourceQuery, SourceStorage, sourceFlag, SourceStorage))
+	command.Flags().StringSliceVar(
+		&o.ServiceNames,
+		serviceNameFlag,
+		nil,
+		"Comma-separated list of services to search traces for when reading from storage. All services are searched if empty")
+	command.Flags().StringVar(
+		&o.OperationName,
+		operationNameFlag,
+		"",
+		"The operation name to search traces for when reading from storage")
+	command.Flags().StringVar(
+		&o.StartTime,
+		startTimeFlag,
+		"",
+		"The start of the time range (RFC3339) to search traces in when reading from storage. Defaults to one hour before end-time")
+	command.Flags().StringVar(
+		&o.EndTime,
+		endTimeFlag,
+		"",
+		"The end of the time range (RFC3339) to search traces in when reading from storage. Defaults to now")
+	command.Flags().IntVar(
+		&o.MaxTraces,
+		maxTracesFlag,
+		100,
+		"The maximum number of traces to load per service when reading from storage")
+}
+
+// SourceFromArgs returns the source of the traces given by the --source flag of the command line
+// arguments, before they are parsed, so that the storage flags are only added when reading from storage.
+func SourceFromArgs(args []string) string {
+	flag := "--" + sourceFlag
+	for i, arg := range args {
+		if i == 0 {
+			continue // skip the app name
+		}
+		if arg == flag && i < len(args)-1 {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			return arg[len(flag)+1:]
+		}
+	}
+	return SourceQuery
+}
+
+// Validate checks that the combination of options is valid.
+func (o *Options) Validate() error {
+	switch o.Source {
+	case SourceQuery:
+		if o.TraceID == "" {
+			return fmt.Errorf("--%s is required when reading from %s", traceIDFlag, SourceQuery)
+		}
+	case SourceStorage:
+	default:
+		return fmt.Errorf("unknown --%s %q, must be %q or %q", sourceFlag, o.Source, SourceQuery, SourceStorage)
+	}
+	_, _, err := o.TimeRange()
+	return err
+}
 
-	// mark traceid flag as mandatory
-	command.MarkFlagRequired(traceIDFlag)
+// TimeRange returns the time range to search traces in when reading from storage.
+func (o *Options) TimeRange() (time.Time, time.Time, error) {
+	end := time.Now()
+	if o.EndTime != "" {
+		t, err := time.Parse(time.RFC3339, o.EndTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse --%s: %w", endTimeFlag, err)
+		}
+		end = t
+	}
+	start := end.Add(-defaultLookback)
+	if o.StartTime != "" {
+		t, err := time.Parse(time.RFC3339, o.StartTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse --%s: %w", startTimeFlag, err)
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--%s must be before --%s", startTimeFlag, endTimeFlag)
+	}
+	return start, end, nil
 }
@@ -16,9 +16,11 @@ package app
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 )
@@ -35,6 +37,9 @@ func TestOptionsWithDefaultFlags(t *testing.T) {
 	assert.False(t, o.HashLogs)
 	assert.False(t, o.HashProcess)
 	assert.Equal(t, -1, o.MaxSpansCount)
+	assert.Equal(t, SourceQuery, o.Source)
+	assert.Empty(t, o.ServiceNames)
+	assert.Equal(t, 100, o.MaxTraces)
 }
 
 func TestOptionsWithFlags(t *testing.T) {
@@ -51,6 +56,7 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--hash-logs",
 		"--hash-process",
 		"--max-spans-count=100",
+		"--hmac-key-file=/etc/anonymizer/key",
 	})
 
 	assert.Equal(t, "192.168.1.10:16686", o.QueryGRPCHostPort)
@@ -61,6 +67,99 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.True(t, o.HashLogs)
 	assert.True(t, o.HashProcess)
 	assert.Equal(t, 100, o.MaxSpansCount)
+	assert.Equal(t, "/etc/anonymizer/key", o.HMACKeyFile)
+}
+
+func TestOptionsWithStorageFlags(t *testing.T) {
+	o := Options{}
+	c := cobra.Command{}
+
+	o.AddFlags(&c)
+	c.ParseFlags([]string{
+		"--source=storage",
+		"--service-name=svc1,svc2",
+		"--operation-name=op",
+		"--start-time=2024-01-01T00:00:00Z",
+		"--end-time=2024-01-02T00:00:00Z",
+		"--max-traces=5",
+	})
+
+	assert.Equal(t, SourceStorage, o.Source)
+	assert.Equal(t, []string{"svc1", "svc2"}, o.ServiceNames)
+	assert.Equal(t, "op", o.OperationName)
+	assert.Equal(t, 5, o.MaxTraces)
+	require.NoError(t, o.Validate())
+
+	start, end, err := o.TimeRange()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), start.UTC())
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), end.UTC())
+}
+
+func TestSourceFromArgs(t *testing.T) {
+	assert.Equal(t, SourceQuery, SourceFromArgs([]string{"jaeger-anonymizer", "--trace-id=1"}))
+	assert.Equal(t, SourceStorage, SourceFromArgs([]string{"jaeger-anonymizer", "--source=storage"}))
+	assert.Equal(t, SourceStorage, SourceFromArgs([]string{"jaeger-anonymizer", "--max-traces=5", "--source", "storage"}))
+	assert.Equal(t, SourceQuery, SourceFromArgs([]string{"jaeger-anonymizer", "--source"}))
+}
+
+func TestOptionsTimeRangeDefaults(t *testing.T) {
+	o := Options{EndTime: "2024-01-02T00:00:00Z"}
+	start, end, err := o.TimeRange()
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, end.Sub(start))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{
+			name:    "query requires trace id",
+			options: Options{Source: SourceQuery},
+			err:     "--trace-id is required",
+		},
+		{
+			name:    "storage without trace id",
+			options: Options{Source: SourceStorage},
+		},
+		{
+			name:    "unknown source",
+			options: Options{Source: "foo"},
+			err:     `unknown --source "foo"`,
+		},
+		{
+			name:    "invalid start time",
+			options: Options{Source: SourceStorage, StartTime: "yesterday"},
+			err:     "cannot parse --start-time",
+		},
+		{
+			name:    "invalid end time",
+			options: Options{Source: SourceStorage, EndTime: "today"},
+			err:     "cannot parse --end-time",
+		},
+		{
+			name: "start after end",
+			options: Options{
+				Source:    SourceStorage,
+				StartTime: "2024-01-02T00:00:00Z",
+				EndTime:   "2024-01-01T00:00:00Z",
+			},
+			err: "--start-time must be before --end-time",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.err)
+			}
+		})
+	}
 }
 
 func TestMain(m *testing.M) {
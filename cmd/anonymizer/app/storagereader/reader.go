@@ -0,0 +1,97 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package storagereader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// Reader loads traces directly from a span storage backend,
+// bypassing jaeger-query.
+type Reader struct {
+	spanReader spanstore.Reader
+}
+
+// Params describes which traces to load from storage.
+type Params struct {
+	// ServiceNames to search in. If empty, all services known to the storage are searched.
+	ServiceNames []string
+	// OperationName optionally restricts the search to a single operation.
+	OperationName string
+	StartTimeMin  time.Time
+	StartTimeMax  time.Time
+	// NumTraces is the maximum number of traces loaded per service.
+	NumTraces int
+}
+
+// New creates a Reader
+func New(spanReader spanstore.Reader) *Reader {
+	return &Reader{
+		spanReader: spanReader,
+	}
+}
+
+// QueryTrace loads a trace and returns all spans inside it
+func (r *Reader) QueryTrace(ctx context.Context, traceID string) ([]model.Span, error) {
+	mTraceID, err := model.TraceIDFromString(traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the provided trace id: %w", err)
+	}
+
+	trace, err := r.spanReader.GetTrace(ctx, mTraceID)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := make([]model.Span, 0, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spans = append(spans, *span)
+	}
+	return spans, nil
+}
+
+// QueryTraces loads all traces matching the parameters
+func (r *Reader) QueryTraces(ctx context.Context, params Params) ([]*model.Trace, error) {
+	services := params.ServiceNames
+	if len(services) == 0 {
+		var err error
+		services, err = r.spanReader.GetServices(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get services: %w", err)
+		}
+	}
+
+	var traces []*model.Trace
+	seen := make(map[model.TraceID]bool)
+	for _, service := range services {
+		found, err := r.spanReader.FindTraces(ctx, &spanstore.TraceQueryParameters{
+			ServiceName:   service,
+			OperationName: params.OperationName,
+			StartTimeMin:  params.StartTimeMin,
+			StartTimeMax:  params.StartTimeMax,
+			NumTraces:     params.NumTraces,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find traces for service %s: %w", service, err)
+		}
+		for _, trace := range found {
+			if len(trace.Spans) == 0 {
+				continue
+			}
+			// the same trace can be returned for several services
+			traceID := trace.Spans[0].TraceID
+			if seen[traceID] {
+				continue
+			}
+			seen[traceID] = true
+			traces = append(traces, trace)
+		}
+	}
+	return traces, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package storagereader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var (
+	mockTraceID = model.NewTraceID(0, 123456)
+	mockTrace   = &model.Trace{
+		Spans: []*model.Span{
+			{TraceID: mockTraceID, SpanID: model.NewSpanID(1), Process: &model.Process{}},
+			{TraceID: mockTraceID, SpanID: model.NewSpanID(2), Process: &model.Process{}},
+		},
+	}
+	otherTrace = &model.Trace{
+		Spans: []*model.Span{
+			{TraceID: model.NewTraceID(0, 42), SpanID: model.NewSpanID(3), Process: &model.Process{}},
+		},
+	}
+)
+
+func TestQueryTrace(t *testing.T) {
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(mockTrace, nil).Once()
+	r := New(spanReader)
+
+	spans, err := r.QueryTrace(context.Background(), mockTraceID.String())
+	require.NoError(t, err)
+	assert.Len(t, spans, 2)
+	assert.Equal(t, model.NewSpanID(2), spans[1].SpanID)
+}
+
+func TestQueryTraceInvalidTraceID(t *testing.T) {
+	r := New(&spanstoremocks.Reader{})
+	_, err := r.QueryTrace(context.Background(), "xyz")
+	require.ErrorContains(t, err, "failed to convert the provided trace id")
+}
+
+func TestQueryTraceNotFound(t *testing.T) {
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+	r := New(spanReader)
+
+	_, err := r.QueryTrace(context.Background(), mockTraceID.String())
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+func TestQueryTraces(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	end := time.Now()
+	matchParams := func(service string) any {
+		return mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+			return q.ServiceName == service && q.OperationName == "op" &&
+				q.StartTimeMin.Equal(start) && q.StartTimeMax.Equal(end) && q.NumTraces == 10
+		})
+	}
+
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return([]string{"svc1", "svc2"}, nil).Once()
+	spanReader.On("FindTraces", mock.Anything, matchParams("svc1")).Return([]*model.Trace{mockTrace}, nil).Once()
+	spanReader.On("FindTraces", mock.Anything, matchParams("svc2")).
+		Return([]*model.Trace{mockTrace, otherTrace, {}}, nil).Once()
+	r := New(spanReader)
+
+	traces, err := r.QueryTraces(context.Background(), Params{
+		OperationName: "op",
+		StartTimeMin:  start,
+		StartTimeMax:  end,
+		NumTraces:     10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{mockTrace, otherTrace}, traces)
+	spanReader.AssertExpectations(t)
+}
+
+func TestQueryTracesWithServices(t *testing.T) {
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{otherTrace}, nil).Once()
+	r := New(spanReader)
+
+	traces, err := r.QueryTraces(context.Background(), Params{ServiceNames: []string{"svc"}})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{otherTrace}, traces)
+	spanReader.AssertNotCalled(t, "GetServices", mock.Anything)
+}
+
+func TestQueryTracesErrors(t *testing.T) {
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return(nil, errors.New("services error")).Once()
+	_, err := New(spanReader).QueryTraces(context.Background(), Params{})
+	require.ErrorContains(t, err, "failed to get services: services error")
+
+	spanReader = &spanstoremocks.Reader{}
+	spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("find error")).Once()
+	_, err = New(spanReader).QueryTraces(context.Background(), Params{ServiceNames: []string{"svc"}})
+	require.ErrorContains(t, err, "failed to find traces for service svc: find error")
+}
@@ -85,6 +85,7 @@ func New(config Config, logger *zap.Logger) (*Writer, error) {
 		HashCustomTags:   config.AnonymizerOpts.HashCustomTags,
 		HashLogs:         config.AnonymizerOpts.HashLogs,
 		HashProcess:      config.AnonymizerOpts.HashProcess,
+		HMACKey:          config.AnonymizerOpts.HMACKey,
 	}
 
 	return &Writer{
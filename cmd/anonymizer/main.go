@@ -15,32 +15,79 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/anonymizer/app"
 	"github.com/jaegertracing/jaeger/cmd/anonymizer/app/anonymizer"
 	"github.com/jaegertracing/jaeger/cmd/anonymizer/app/query"
+	"github.com/jaegertracing/jaeger/cmd/anonymizer/app/storagereader"
 	"github.com/jaegertracing/jaeger/cmd/anonymizer/app/uiconv"
 	"github.com/jaegertracing/jaeger/cmd/anonymizer/app/writer"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage"
 )
 
 var logger, _ = zap.NewDevelopment()
 
 func main() {
 	options := app.Options{}
+	v := viper.New()
+
+	// the storage factory is only needed, and its flags only added, when reading from storage
+	var storageFactory *storage.Factory
+	var flagInits []func(*flag.FlagSet)
+	if app.SourceFromArgs(os.Args) == app.SourceStorage {
+		var err error
+		storageFactory, err = storage.NewFactory(storage.FactoryConfigFromEnvAndCLI(os.Args, os.Stderr))
+		if err != nil {
+			log.Fatalf("Cannot initialize storage factory: %v", err)
+		}
+		flagInits = append(flagInits, storageFactory.AddFlags)
+	}
 
 	command := &cobra.Command{
 		Use:   "jaeger-anonymizer",
 		Short: "Jaeger anonymizer hashes fields of a trace for easy sharing",
-		Long:  `Jaeger anonymizer queries Jaeger query for a trace, anonymizes fields, and store in file`,
+		Long:  `Jaeger anonymizer queries Jaeger query or the storage backend for traces, anonymizes fields, and store in file`,
 		Run: func(_ *cobra.Command, _ /* args */ []string) {
+			if err := options.Validate(); err != nil {
+				logger.Fatal("invalid options", zap.Error(err))
+			}
+
+			hmacKey, err := readHMACKey(options.HMACKeyFile)
+			if err != nil {
+				logger.Fatal("error while reading HMAC key", zap.Error(err))
+			}
+
+			var spans []model.Span
+			traceIDs := []string{options.TraceID}
+			if options.Source == app.SourceStorage {
+				spans, traceIDs = readFromStorage(v, storageFactory, &options)
+			} else {
+				spans = readFromQuery(&options)
+			}
+
 			prefix := options.OutputDir + "/" + options.TraceID
+			if options.TraceID == "" {
+				start, end, _ := options.TimeRange()
+				prefix = fmt.Sprintf("%s/traces-%s-%s", options.OutputDir,
+					start.UTC().Format(timeRangeFormat), end.UTC().Format(timeRangeFormat))
+			}
 			conf := writer.Config{
 				MaxSpansCount:  options.MaxSpansCount,
 				CapturedFile:   prefix + ".original.json",
@@ -51,6 +98,7 @@ func main() {
 					HashCustomTags:   options.HashCustomTags,
 					HashLogs:         options.HashLogs,
 					HashProcess:      options.HashProcess,
+					HMACKey:          hmacKey,
 				},
 			}
 
@@ -59,19 +107,6 @@ func main() {
 				logger.Fatal("error while creating writer object", zap.Error(err))
 			}
 
-			query, err := query.New(options.QueryGRPCHostPort)
-			if err != nil {
-				logger.Fatal("error while creating query object", zap.Error(err))
-			}
-
-			spans, err := query.QueryTrace(options.TraceID)
-			if err != nil {
-				logger.Fatal("error while querying for trace", zap.Error(err))
-			}
-			if err := query.Close(); err != nil {
-				logger.Error("Failed to close grpc client connection", zap.Error(err))
-			}
-
 			for _, span := range spans {
 				if err := w.WriteSpan(&span); err != nil {
 					if errors.Is(err, writer.ErrMaxSpansCountReached) {
@@ -83,19 +118,30 @@ func main() {
 			}
 			w.Close()
 
-			uiCfg := uiconv.Config{
-				CapturedFile: conf.AnonymizedFile,
-				UIFile:       prefix + ".anonymized-ui-trace.json",
-				TraceID:      options.TraceID,
-			}
-			if err := uiconv.Extract(uiCfg, logger); err != nil {
-				logger.Fatal("error while extracing UI trace", zap.Error(err))
+			for _, traceID := range traceIDs {
+				uiFile := prefix + ".anonymized-ui-trace.json"
+				if options.TraceID == "" {
+					uiFile = prefix + "." + traceID + ".anonymized-ui-trace.json"
+				}
+				uiCfg := uiconv.Config{
+					CapturedFile: conf.AnonymizedFile,
+					UIFile:       uiFile,
+					TraceID:      traceID,
+				}
+				if err := uiconv.Extract(uiCfg, logger); err != nil {
+					logger.Fatal("error while extracing UI trace", zap.Error(err))
+				}
+				logger.Sugar().Infof("Wrote UI-compatible anonymized file to %s", uiCfg.UIFile)
 			}
-			logger.Sugar().Infof("Wrote UI-compatible anonymized file to %s", uiCfg.UIFile)
 		},
 	}
 
 	options.AddFlags(command)
+	config.AddFlags(
+		v,
+		command,
+		flagInits...,
+	)
 
 	command.AddCommand(version.Command())
 
@@ -104,3 +150,94 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+const timeRangeFormat = "20060102T150405Z"
+
+func readHMACKey(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("HMAC key file %s is empty", path)
+	}
+	return key, nil
+}
+
+func readFromQuery(options *app.Options) []model.Span {
+	query, err := query.New(options.QueryGRPCHostPort)
+	if err != nil {
+		logger.Fatal("error while creating query object", zap.Error(err))
+	}
+
+	spans, err := query.QueryTrace(options.TraceID)
+	if err != nil {
+		logger.Fatal("error while querying for trace", zap.Error(err))
+	}
+	if err := query.Close(); err != nil {
+		logger.Error("Failed to close grpc client connection", zap.Error(err))
+	}
+	return spans
+}
+
+// readFromStorage loads either the requested trace or all traces matching
+// the search options from the storage backend, and returns their spans
+// together with the IDs of the loaded traces.
+func readFromStorage(v *viper.Viper, storageFactory *storage.Factory, options *app.Options) ([]model.Span, []string) {
+	storageFactory.InitFromViper(v, logger)
+	if err := storageFactory.Initialize(metrics.NullFactory, logger); err != nil {
+		logger.Fatal("Failed to init storage factory", zap.Error(err))
+	}
+	defer func() {
+		if err := storageFactory.Close(); err != nil {
+			logger.Error("Failed to close storage factory", zap.Error(err))
+		}
+	}()
+	spanReader, err := storageFactory.CreateSpanReader()
+	if err != nil {
+		logger.Fatal("Failed to create span reader", zap.Error(err))
+	}
+	reader := storagereader.New(spanReader)
+	ctx := context.Background()
+
+	if options.TraceID != "" {
+		spans, err := reader.QueryTrace(ctx, options.TraceID)
+		if err != nil {
+			logger.Fatal("error while reading trace from storage", zap.Error(err))
+		}
+		return spans, []string{options.TraceID}
+	}
+
+	start, end, _ := options.TimeRange()
+	logger.Info("Searching traces in storage",
+		zap.Strings("services", options.ServiceNames),
+		zap.String("operation", options.OperationName),
+		zap.String("start", start.Format(time.RFC3339)),
+		zap.String("end", end.Format(time.RFC3339)),
+	)
+	traces, err := reader.QueryTraces(ctx, storagereader.Params{
+		ServiceNames:  options.ServiceNames,
+		OperationName: options.OperationName,
+		StartTimeMin:  start,
+		StartTimeMax:  end,
+		NumTraces:     options.MaxTraces,
+	})
+	if err != nil {
+		logger.Fatal("error while searching traces in storage", zap.Error(err))
+	}
+
+	var spans []model.Span
+	traceIDs := make([]string, 0, len(traces))
+	for _, trace := range traces {
+		traceIDs = append(traceIDs, trace.Spans[0].TraceID.String())
+		for _, span := range trace.Spans {
+			spans = append(spans, *span)
+		}
+	}
+	logger.Info("Loaded traces from storage", zap.Int("traces", len(traceIDs)), zap.Int("spans", len(spans)))
+	return spans, traceIDs
+}
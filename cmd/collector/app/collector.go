@@ -19,22 +19,39 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/geoip"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/k8smetadata"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/rules"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/server"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/throttler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/udpreceiver"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wasm"
 	"github.com/jaegertracing/jaeger/internal/safeexpvar"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/http3server"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/throttling"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -56,15 +73,33 @@ type Collector struct {
 	spanProcessor      processor.SpanProcessor
 	spanHandlers       *SpanHandlers
 	tenancyMgr         *tenancy.Manager
+	tracer             *jtracer.JTracer
+	meterProvider      metric.MeterProvider
+	metadataStore      metadatastore.Store
+	traceBroadcaster   *tracestream.Broadcaster
+	recentTraces       *tracestream.RecentTraces
 
 	// state, read only
+	wal                        *wal.Writer
+	wasmProcessor              *wasm.Processor
+	spanRules                  *rules.Engine
+	k8sMetadata                *k8smetadata.Enricher
+	geoIP                      *geoip.Processor
+	throttler                  throttling.ThrottlingServiceServer
 	hServer                    *http.Server
 	grpcServer                 *grpc.Server
 	otlpReceiver               receiver.Traces
+	otlpHTTP3Server            *http3server.Server
 	zipkinReceiver             receiver.Traces
+	udpReceivers               *udpreceiver.Receivers
 	tlsGRPCCertWatcherCloser   io.Closer
 	tlsHTTPCertWatcherCloser   io.Closer
 	tlsZipkinCertWatcherCloser io.Closer
+
+	tlsOTLPHTTP3CertWatcherCloser io.Closer
+
+	reloadMu   sync.Mutex
+	reloadable reloadableOptions
 }
 
 // CollectorParams to construct a new Jaeger Collector.
@@ -77,30 +112,92 @@ type CollectorParams struct {
 	SamplingAggregator samplingstrategy.Aggregator
 	HealthCheck        *healthcheck.HealthCheck
 	TenancyMgr         *tenancy.Manager
+	// Tracer, when set, traces the requests received by the gRPC and HTTP servers.
+	Tracer *jtracer.JTracer
+	// MeterProvider, when set, records the OTEL metrics of the OTLP and Zipkin receivers.
+	MeterProvider metric.MeterProvider
+	// MetadataStore, when set, records the service metadata reported in the process tags
+	// if enabled by CollectorOptions.ServiceMetadataFromProcessTags.
+	MetadataStore metadatastore.Store
+	// TraceBroadcaster, when set, receives the trace IDs of the processed spans, for the live
+	// tail of the query service running in the same process.
+	TraceBroadcaster *tracestream.Broadcaster
+	// RecentTraces, when set, buffers the processed spans, for the recent traces API of the
+	// query service running in the same process.
+	RecentTraces *tracestream.RecentTraces
 }
 
 // New constructs a new collector component, ready to be started
 func New(params *CollectorParams) *Collector {
+	samplingProvider := params.SamplingProvider
+	if samplingProvider != nil && params.MetricsFactory != nil {
+		samplingProvider = samplingstrategy.NewInstrumentedProvider(samplingProvider, params.MetricsFactory)
+	}
 	return &Collector{
 		serviceName:        params.ServiceName,
 		logger:             params.Logger,
 		metricsFactory:     params.MetricsFactory,
 		spanWriter:         params.SpanWriter,
-		samplingProvider:   params.SamplingProvider,
+		samplingProvider:   samplingProvider,
 		samplingAggregator: params.SamplingAggregator,
 		hCheck:             params.HealthCheck,
 		tenancyMgr:         params.TenancyMgr,
+		tracer:             params.Tracer,
+		meterProvider:      params.MeterProvider,
+		metadataStore:      params.MetadataStore,
+		traceBroadcaster:   params.TraceBroadcaster,
+		recentTraces:       params.RecentTraces,
 	}
 }
 
 // Start the component and underlying dependencies
 func (c *Collector) Start(options *flags.CollectorOptions) error {
+	if options.WAL.Dir != "" {
+		walWriter, err := wal.NewWriter(options.WAL, c.metricsFactory, c.logger)
+		if err != nil {
+			return fmt.Errorf("could not create the write-ahead log: %w", err)
+		}
+		c.wal = walWriter
+	}
+	if len(options.WASMModules) > 0 {
+		wasmProcessor, err := wasm.NewProcessor(context.Background(), options.WASMModules, options.WASMTimeout, c.metricsFactory, c.logger)
+		if err != nil {
+			return fmt.Errorf("could not load the WebAssembly modules: %w", err)
+		}
+		c.wasmProcessor = wasmProcessor
+	}
+	if options.SpanRulesFile != "" {
+		spanRules, err := rules.NewEngine(options.SpanRulesFile, c.metricsFactory, c.logger)
+		if err != nil {
+			return fmt.Errorf("could not load the span rules: %w", err)
+		}
+		c.spanRules = spanRules
+	}
+	if options.K8sMetadata.Enabled {
+		k8sMetadata, err := k8smetadata.NewEnricher(options.K8sMetadata, c.metricsFactory, c.logger)
+		if err != nil {
+			return fmt.Errorf("could not start the Kubernetes metadata enrichment: %w", err)
+		}
+		c.k8sMetadata = k8sMetadata
+	}
+	if options.GeoIP.Enabled() {
+		geoIP, err := geoip.NewProcessor(options.GeoIP, c.metricsFactory, c.logger)
+		if err != nil {
+			return fmt.Errorf("could not load the GeoIP databases: %w", err)
+		}
+		c.geoIP = geoIP
+	}
 	handlerBuilder := &SpanHandlerBuilder{
 		SpanWriter:     c.spanWriter,
 		CollectorOpts:  options,
 		Logger:         c.logger,
 		MetricsFactory: c.metricsFactory,
 		TenancyMgr:     c.tenancyMgr,
+		WAL:            c.wal,
+		WASMProcessor:  c.wasmProcessor,
+		SpanRules:      c.spanRules,
+		K8sMetadata:    c.k8sMetadata,
+		GeoIP:          c.geoIP,
 	}
 
 	var additionalProcessors []ProcessSpan
@@ -109,12 +206,62 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 			c.samplingAggregator.HandleRootSpan(span, c.logger)
 		})
 	}
+	if c.metadataStore != nil && options.ServiceMetadataFromProcessTags {
+		additionalProcessors = append(additionalProcessors, newServiceMetadataRecorder(c.metadataStore, c.logger).recordSpan)
+	}
+	if c.traceBroadcaster != nil {
+		additionalProcessors = append(additionalProcessors, func(span *model.Span, tenant string) {
+			c.traceBroadcaster.Publish(tracestream.Event{TraceID: span.TraceID, Tenant: tenant})
+		})
+	}
+	if c.recentTraces != nil {
+		additionalProcessors = append(additionalProcessors, c.recentTraces.Add)
+	}
 
 	c.spanProcessor = handlerBuilder.BuildSpanProcessor(additionalProcessors...)
 	c.spanHandlers = handlerBuilder.BuildHandlers(c.spanProcessor)
 
+	if options.Throttling.Enabled() {
+		c.throttler = throttler.NewThrottler(options.Throttling, c.metricsFactory)
+	}
+
+	var envoyALSHandler *handler.EnvoyALSHandler
+	if options.EnvoyALSEnabled {
+		envoyALSHandler = handler.NewEnvoyALSHandler(c.logger, c.spanProcessor, c.tenancyMgr, c.metricsFactory)
+	}
+
+	var jsonSpanHandler *handler.JSONSpanHandler
+	if options.JSONSpans.Enabled {
+		mapping := jsonspans.DefaultMapping()
+		if options.JSONSpans.MappingFile != "" {
+			var err error
+			if mapping, err = jsonspans.LoadMapping(options.JSONSpans.MappingFile); err != nil {
+				return fmt.Errorf("could not load the JSON span mapping: %w", err)
+			}
+		}
+		converter, err := jsonspans.NewConverter(mapping)
+		if err != nil {
+			return fmt.Errorf("could not load the JSON span mapping: %w", err)
+		}
+		jsonSpanHandler = handler.NewJSONSpanHandler(converter, c.spanProcessor, c.tenancyMgr)
+	}
+
+	var tracerProvider trace.TracerProvider
+	telset := telemetery.NoopSettings()
+	telset.Logger = c.logger
+	telset.Metrics = c.metricsFactory
+	if c.tracer != nil {
+		tracerProvider = c.tracer.OTEL
+		telset.TracerProvider = tracerProvider
+	}
+	if c.meterProvider != nil {
+		telset.MeterProvider = c.meterProvider
+	}
+
 	grpcServer, err := server.StartGRPCServer(&server.GRPCServerParams{
 		HostPort:                options.GRPC.HostPort,
+		Network:                 options.GRPC.Network,
+		SocketPermissions:       options.GRPC.SocketPermissions,
 		Handler:                 c.spanHandlers.GRPCHandler,
 		TLSConfig:               options.GRPC.TLS,
 		SamplingProvider:        c.samplingProvider,
@@ -122,6 +269,10 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 		MaxReceiveMessageLength: options.GRPC.MaxReceiveMessageLength,
 		MaxConnectionAge:        options.GRPC.MaxConnectionAge,
 		MaxConnectionAgeGrace:   options.GRPC.MaxConnectionAgeGrace,
+		MetricsFactory:          c.metricsFactory,
+		TracerProvider:          tracerProvider,
+		Throttler:               c.throttler,
+		EnvoyALSHandler:         envoyALSHandler,
 	})
 	if err != nil {
 		return fmt.Errorf("could not start gRPC server: %w", err)
@@ -129,13 +280,17 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 	c.grpcServer = grpcServer
 
 	httpServer, err := server.StartHTTPServer(&server.HTTPServerParams{
-		HostPort:         options.HTTP.HostPort,
-		Handler:          c.spanHandlers.JaegerBatchesHandler,
-		TLSConfig:        options.HTTP.TLS,
-		HealthCheck:      c.hCheck,
-		MetricsFactory:   c.metricsFactory,
-		SamplingProvider: c.samplingProvider,
-		Logger:           c.logger,
+		HostPort:          options.HTTP.HostPort,
+		Network:           options.HTTP.Network,
+		SocketPermissions: options.HTTP.SocketPermissions,
+		Handler:           c.spanHandlers.JaegerBatchesHandler,
+		TLSConfig:         options.HTTP.TLS,
+		HealthCheck:       c.hCheck,
+		MetricsFactory:    c.metricsFactory,
+		SamplingProvider:  c.samplingProvider,
+		Logger:            c.logger,
+		TracerProvider:    tracerProvider,
+		JSONSpanHandler:   jsonSpanHandler,
 	})
 	if err != nil {
 		return fmt.Errorf("could not start HTTP server: %w", err)
@@ -149,7 +304,7 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 	if options.Zipkin.HTTPHostPort == "" {
 		c.logger.Info("Not listening for Zipkin HTTP traffic, port not configured")
 	} else {
-		zipkinReceiver, err := handler.StartZipkinReceiver(options, c.logger, c.spanProcessor, c.tenancyMgr)
+		zipkinReceiver, err := handler.StartZipkinReceiver(options, telset, c.spanProcessor, c.tenancyMgr)
 		if err != nil {
 			return fmt.Errorf("could not start Zipkin receiver: %w", err)
 		}
@@ -157,13 +312,31 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 	}
 
 	if options.OTLP.Enabled {
-		otlpReceiver, err := handler.StartOTLPReceiver(options, c.logger, c.spanProcessor, c.tenancyMgr)
+		otlpReceiver, err := handler.StartOTLPReceiver(options, telset, c.spanProcessor, c.tenancyMgr)
 		if err != nil {
 			return fmt.Errorf("could not start OTLP receiver: %w", err)
 		}
 		c.otlpReceiver = otlpReceiver
+		if options.OTLP.HTTP.HTTP3HostPort != "" {
+			otlpHTTP3Server, err := handler.StartOTLPHTTP3Server(options, c.logger, c.spanProcessor, c.tenancyMgr)
+			if err != nil {
+				return fmt.Errorf("could not start OTLP HTTP/3 server: %w", err)
+			}
+			c.otlpHTTP3Server = otlpHTTP3Server
+			c.tlsOTLPHTTP3CertWatcherCloser = &options.OTLP.HTTP.TLS
+		}
+	}
+
+	if options.UDP.Enabled() {
+		agentHandler := handler.NewAgentHandler(c.spanHandlers.JaegerBatchesHandler, c.spanHandlers.ZipkinSpansHandler)
+		udpReceivers, err := udpreceiver.StartReceivers(options.UDP, agentHandler, c.metricsFactory, c.logger)
+		if err != nil {
+			return fmt.Errorf("could not start the UDP receivers: %w", err)
+		}
+		c.udpReceivers = udpReceivers
 	}
 
+	c.reloadable = newReloadableOptions(options)
 	c.publishOpts(options)
 
 	return nil
@@ -208,8 +381,54 @@ func (c *Collector) Close() error {
 		defer cancel()
 	}
 
-	if err := c.spanProcessor.Close(); err != nil {
-		c.logger.Error("failed to close span processor.", zap.Error(err))
+	// Stop the HTTP/3 listener of the OTLP receiver
+	if c.otlpHTTP3Server != nil {
+		if err := c.otlpHTTP3Server.Close(); err != nil {
+			c.logger.Error("failed to stop the OTLP HTTP/3 server", zap.Error(err))
+		}
+	}
+
+	// Stop the UDP receivers of the legacy clients
+	if c.udpReceivers != nil {
+		c.udpReceivers.Close()
+	}
+
+	// the span processor is not created if one of the components created before it fails to start
+	if c.spanProcessor != nil {
+		if err := c.spanProcessor.Close(); err != nil {
+			c.logger.Error("failed to close span processor.", zap.Error(err))
+		}
+	}
+
+	// the span processor records the spans failing to be written until it is closed
+	if c.wal != nil {
+		if err := c.wal.Close(); err != nil {
+			c.logger.Error("failed to close the write-ahead log.", zap.Error(err))
+		}
+	}
+
+	if c.wasmProcessor != nil {
+		if err := c.wasmProcessor.Close(); err != nil {
+			c.logger.Error("failed to close the WebAssembly modules.", zap.Error(err))
+		}
+	}
+
+	if c.spanRules != nil {
+		if err := c.spanRules.Close(); err != nil {
+			c.logger.Error("failed to close the span rules.", zap.Error(err))
+		}
+	}
+
+	if c.k8sMetadata != nil {
+		if err := c.k8sMetadata.Close(); err != nil {
+			c.logger.Error("failed to close the Kubernetes metadata enrichment.", zap.Error(err))
+		}
+	}
+
+	if c.geoIP != nil {
+		if err := c.geoIP.Close(); err != nil {
+			c.logger.Error("failed to close the GeoIP databases.", zap.Error(err))
+		}
 	}
 
 	// aggregator does not exist for all strategy stores. only Close() if exists.
@@ -229,6 +448,9 @@ func (c *Collector) Close() error {
 	if c.tlsZipkinCertWatcherCloser != nil {
 		_ = c.tlsZipkinCertWatcherCloser.Close()
 	}
+	if c.tlsOTLPHTTP3CertWatcherCloser != nil {
+		_ = c.tlsOTLPHTTP3CertWatcherCloser.Close()
+	}
 
 	return nil
 }
@@ -237,3 +459,8 @@ func (c *Collector) Close() error {
 func (c *Collector) SpanHandlers() *SpanHandlers {
 	return c.spanHandlers
 }
+
+// QueueStatus returns the status of the span queue of the started Collector.
+func (c *Collector) QueueStatus() QueueStatus {
+	return c.spanProcessor.(*spanProcessor).queueStatus()
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"expvar"
 	"io"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -27,11 +29,17 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/k8smetadata"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/throttler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/udpreceiver"
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
@@ -91,6 +99,80 @@ func TestNewCollector(t *testing.T) {
 	collectorOpts := optionsForEphemeralPorts()
 	require.NoError(t, c.Start(collectorOpts))
 	assert.NotNil(t, c.SpanHandlers())
+	assert.Equal(t, QueueStatus{Workers: flags.DefaultNumWorkers}, c.QueueStatus())
+	require.NoError(t, c.Close())
+}
+
+func TestCollector_Throttling(t *testing.T) {
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   metrics.NullFactory,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+	})
+
+	collectorOpts := optionsForEphemeralPorts()
+	collectorOpts.Throttling = throttler.Options{CreditsPerSecond: 1, MaxBalance: 1, MaxOperations: 1, MaxServices: 1}
+	require.NoError(t, c.Start(collectorOpts))
+	assert.NotNil(t, c.throttler)
+	require.NoError(t, c.Close())
+}
+
+func TestCollector_UDPReceivers(t *testing.T) {
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   metrics.NullFactory,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+	})
+
+	collectorOpts := optionsForEphemeralPorts()
+	collectorOpts.UDP.JaegerCompact = udpreceiver.ServerOptions{HostPort: "127.0.0.1:0", Workers: 1, QueueSize: 10, MaxPacketSize: 65000}
+	require.NoError(t, c.Start(collectorOpts))
+	assert.NotNil(t, c.udpReceivers)
+	require.NoError(t, c.Close())
+}
+
+func TestCollector_EnvoyALS(t *testing.T) {
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   metrics.NullFactory,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+	})
+
+	collectorOpts := optionsForEphemeralPorts()
+	collectorOpts.EnvoyALSEnabled = true
+	require.NoError(t, c.Start(collectorOpts))
+	assert.Contains(t, c.grpcServer.GetServiceInfo(), "envoy.service.accesslog.v3.AccessLogService")
+	require.NoError(t, c.Close())
+}
+
+func TestCollector_JSONSpans(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(`{"service": "process.name"}`), 0o600))
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   metrics.NullFactory,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+	})
+
+	collectorOpts := optionsForEphemeralPorts()
+	collectorOpts.JSONSpans = jsonspans.Options{Enabled: true, MappingFile: mappingFile}
+	require.NoError(t, c.Start(collectorOpts))
 	require.NoError(t, c.Close())
 }
 
@@ -142,6 +224,34 @@ func TestCollector_StartErrors(t *testing.T) {
 	options = optionsForEphemeralPorts()
 	options.OTLP.HTTP.HostPort = ":-1"
 	run("OTLP/HTTP", options, "could not start OTLP receiver")
+
+	options = optionsForEphemeralPorts()
+	options.WAL.Dir = t.TempDir()
+	run("WAL", options, "could not create the write-ahead log")
+
+	options = optionsForEphemeralPorts()
+	options.WASMModules = []string{filepath.Join(t.TempDir(), "missing.wasm")}
+	run("WASM", options, "could not load the WebAssembly modules")
+
+	options = optionsForEphemeralPorts()
+	options.SpanRulesFile = filepath.Join(t.TempDir(), "missing.json")
+	run("span rules", options, "could not load the span rules")
+
+	options = optionsForEphemeralPorts()
+	options.K8sMetadata = k8smetadata.Options{Enabled: true, Kubeconfig: filepath.Join(t.TempDir(), "missing")}
+	run("Kubernetes metadata", options, "could not start the Kubernetes metadata enrichment")
+
+	options = optionsForEphemeralPorts()
+	options.GeoIP.CountryDatabase = filepath.Join(t.TempDir(), "missing.mmdb")
+	run("GeoIP", options, "could not load the GeoIP databases")
+
+	options = optionsForEphemeralPorts()
+	options.UDP.JaegerBinary = udpreceiver.ServerOptions{HostPort: ":-1", Workers: 1, QueueSize: 10, MaxPacketSize: 65000}
+	run("UDP", options, "could not start the UDP receivers")
+
+	options = optionsForEphemeralPorts()
+	options.JSONSpans = jsonspans.Options{Enabled: true, MappingFile: filepath.Join(t.TempDir(), "missing.json")}
+	run("JSON spans mapping file", options, "could not load the JSON span mapping")
 }
 
 type mockSamplingProvider struct{}
@@ -243,3 +353,79 @@ func TestAggregator(t *testing.T) {
 	assert.EqualValues(t, 1, agg.callCount.Load(), "aggregator was used")
 	assert.EqualValues(t, 1, agg.closeCount.Load(), "aggregator close was called")
 }
+
+func TestCollectorTraceBroadcaster(t *testing.T) {
+	baseMetrics := metricstest.NewFactory(time.Hour)
+	defer baseMetrics.Backend.Stop()
+	broadcaster := tracestream.NewBroadcaster()
+	subscription := broadcaster.Subscribe(10)
+	defer subscription.Close()
+
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   baseMetrics,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+		TraceBroadcaster: broadcaster,
+	})
+	collectorOpts := optionsForEphemeralPorts()
+	collectorOpts.NumWorkers = 1
+	collectorOpts.QueueSize = 10
+	require.NoError(t, c.Start(collectorOpts))
+	defer c.Close()
+
+	spans := []*model.Span{{
+		TraceID:       model.NewTraceID(0, 42),
+		OperationName: "y",
+		Process:       &model.Process{ServiceName: "x"},
+	}}
+	_, err := c.spanProcessor.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, Tenant: "acme"})
+	require.NoError(t, err)
+	select {
+	case event := <-subscription.Events():
+		assert.Equal(t, tracestream.Event{TraceID: model.NewTraceID(0, 42), Tenant: "acme"}, event)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the trace ID of the span")
+	}
+}
+
+func TestCollectorRecentTraces(t *testing.T) {
+	baseMetrics := metricstest.NewFactory(time.Hour)
+	defer baseMetrics.Backend.Stop()
+	recentTraces := tracestream.NewRecentTraces(10)
+
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   baseMetrics,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+		RecentTraces:     recentTraces,
+	})
+	collectorOpts := optionsForEphemeralPorts()
+	collectorOpts.NumWorkers = 1
+	collectorOpts.QueueSize = 10
+	require.NoError(t, c.Start(collectorOpts))
+	defer c.Close()
+
+	spans := []*model.Span{{
+		TraceID:       model.NewTraceID(0, 42),
+		OperationName: "y",
+		Process:       &model.Process{ServiceName: "x"},
+	}}
+	_, err := c.spanProcessor.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, Tenant: "acme"})
+	require.NoError(t, err)
+	keepAll := func(*model.Trace) bool { return true }
+	require.Eventually(t, func() bool {
+		traces, err := recentTraces.Get("acme", 0, keepAll)
+		return err == nil && len(traces) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	traces, err := recentTraces.Get("acme", 0, keepAll)
+	require.NoError(t, err)
+	assert.Equal(t, model.NewTraceID(0, 42), traces[0].Spans[0].TraceID)
+}
@@ -18,26 +18,77 @@ package flags
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/geoip"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/k8smetadata"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/throttler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/udpreceiver"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
+	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/config/corscfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
 const (
-	flagDynQueueSizeMemory     = "collector.queue-size-memory"
-	flagNumWorkers             = "collector.num-workers"
-	flagQueueSize              = "collector.queue-size"
-	flagCollectorTags          = "collector.tags"
-	flagSpanSizeMetricsEnabled = "collector.enable-span-size-metrics"
+	flagDynQueueSizeMemory      = "collector.queue-size-memory"
+	flagNumWorkers              = "collector.num-workers"
+	flagQueueSize               = "collector.queue-size"
+	flagCollectorTags           = "collector.tags"
+	flagSpanSizeMetricsEnabled  = "collector.enable-span-size-metrics"
+	flagBatchSize               = "collector.batch.size"
+	flagBatchFlushInterval      = "collector.batch.flush-interval"
+	flagMaxTags                 = "collector.span-limits.max-tags"
+	flagMaxTagValueLength       = "collector.span-limits.max-tag-value-length"
+	flagMaxLogs                 = "collector.span-limits.max-logs"
+	flagMaxProcessTags          = "collector.span-limits.max-process-tags"
+	flagDedupeCacheSize         = "collector.dedupe.cache-size"
+	flagServiceMetadataTags     = "collector.service-metadata.from-process-tags"
+	flagNormalizeErrorStatus    = "collector.normalize-error-status"
+	flagAutoscalingEnabled      = "collector.workers-autoscaling.enabled"
+	flagAutoscalingMinWorkers   = "collector.workers-autoscaling.min"
+	flagAutoscalingMaxWorkers   = "collector.workers-autoscaling.max"
+	flagAutoscalingLatency      = "collector.workers-autoscaling.target-latency"
+	flagAutoscalingInterval     = "collector.workers-autoscaling.interval"
+	flagWALDir                  = "collector.wal.dir"
+	flagWALMaxSegmentSize       = "collector.wal.max-segment-size-mib"
+	flagWALMaxSize              = "collector.wal.max-size-mib"
+	flagWALSegmentCloseInterval = "collector.wal.segment-close-interval"
+	flagWASMModules             = "collector.wasm.modules"
+	flagWASMTimeout             = "collector.wasm.timeout"
+	flagSpanRulesFile           = "collector.span-rules.file"
+	flagK8sMetadataEnabled      = "collector.k8s-metadata.enabled"
+	flagK8sMetadataKubeconfig   = "collector.k8s-metadata.kubeconfig"
+	flagK8sMetadataNamespace    = "collector.k8s-metadata.namespace"
+	flagK8sMetadataLabels       = "collector.k8s-metadata.labels"
+	flagK8sMetadataSyncTimeout  = "collector.k8s-metadata.sync-timeout"
+	flagGeoIPCountryDatabase    = "collector.geoip.country-database"
+	flagGeoIPASNDatabase        = "collector.geoip.asn-database"
+	flagGeoIPTags               = "collector.geoip.ip-tags"
+	flagThrottlingCredits       = "collector.throttling.credits-per-second"
+	flagThrottlingMaxBalance    = "collector.throttling.max-balance"
+	flagThrottlingMaxOperations = "collector.throttling.max-operations"
+	flagThrottlingMaxServices   = "collector.throttling.max-services"
+	flagEnvoyALSEnabled         = "collector.envoy-als.enabled"
+	flagJSONSpansEnabled        = "collector.json-spans.enabled"
+	flagJSONSpansMappingFile    = "collector.json-spans.mapping-file"
 
 	flagSuffixHostPort = "host-port"
+	flagSuffixIPFamily = "ip-family"
+
+	flagSuffixSocketPermissions = "socket-permissions"
+
+	flagSuffixHTTP3HostPort = "http3-host-port"
 
 	flagSuffixHTTPReadTimeout       = "read-timeout"
 	flagSuffixHTTPReadHeaderTimeout = "read-header-timeout"
@@ -50,12 +101,27 @@ const (
 	flagCollectorOTLPEnabled = "collector.otlp.enabled"
 
 	flagZipkinHTTPHostPort     = "collector.zipkin.host-port"
+	flagZipkinIPFamily         = "collector.zipkin.ip-family"
 	flagZipkinKeepAliveEnabled = "collector.zipkin.keep-alive"
 
+	// the UDP receivers are named after the processors of jaeger-agent
+	flagPrefixUDP                 = "collector.udp."
+	udpJaegerCompact              = "jaeger-compact"
+	udpJaegerBinary               = "jaeger-binary"
+	udpZipkinCompact              = "zipkin-compact"
+	flagSuffixUDPWorkers          = "workers"
+	flagSuffixUDPQueueSize        = "queue-size"
+	flagSuffixUDPMaxPacketSize    = "max-packet-size"
+	flagSuffixUDPSocketBufferSize = "socket-buffer-size"
+
 	// DefaultNumWorkers is the default number of workers consuming from the processor queue
 	DefaultNumWorkers = 50
 	// DefaultQueueSize is the size of the processor's queue
 	DefaultQueueSize = 2000
+	// DefaultBatchSize is the number of spans written at once to the storage backends supporting it
+	DefaultBatchSize = 100
+	// DefaultBatchFlushInterval is how long spans wait for a batch to be full before being written
+	DefaultBatchFlushInterval = 100 * time.Millisecond
 	// DefaultGRPCMaxReceiveMessageLength is the default max receivable message size for the gRPC Collector
 	DefaultGRPCMaxReceiveMessageLength = 4 * 1024 * 1024
 )
@@ -130,6 +196,8 @@ type CollectorOptions struct {
 	Zipkin struct {
 		// HTTPHostPort is the host:port address that the Zipkin collector service listens in on for http requests
 		HTTPHostPort string
+		// HTTPNetwork is the Go TCP network of the Zipkin HTTP server, e.g. tcp4 or tcp6, "tcp" if empty
+		HTTPNetwork string
 		// TLS configures secure transport for Zipkin endpoint to collect spans
 		TLS tlscfg.Options
 		// CORS allows CORS requests , sets the values for Allowed Headers and Allowed Origins.
@@ -141,6 +209,79 @@ type CollectorOptions struct {
 	CollectorTags map[string]string
 	// SpanSizeMetricsEnabled determines whether to enable metrics based on processed span size
 	SpanSizeMetricsEnabled bool
+	// BatchSize is the number of spans written at once to the storage backends supporting it
+	BatchSize int
+	// BatchFlushInterval is how long spans wait for a batch to be full before being written
+	BatchFlushInterval time.Duration
+	// WorkersAutoscaling adapts the number of workers of the span processor to the write latency of the
+	// storage, all backends together, and the queue length, NumWorkers being the initial number of workers
+	// when it is enabled
+	WorkersAutoscaling WorkersAutoscaling
+	// SpanLimits bounds the size of the spans, the data over the limits is truncated
+	SpanLimits SpanLimits
+	// DedupeCacheSize is the number of recent spans remembered to drop their exact duplicates, 0 disabling it
+	DedupeCacheSize int
+	// ServiceMetadataFromProcessTags records the metadata of the services reported in the process tags
+	ServiceMetadataFromProcessTags bool
+	// NormalizeErrorStatus normalizes the error signaling of the spans of the different SDKs into the error and status.code tags
+	NormalizeErrorStatus bool
+	// WAL configures the write-ahead log of the spans which failed to be written to the storage
+	WAL wal.Options
+	// WASMModules are the paths of the WebAssembly modules processing the spans, in order
+	WASMModules []string
+	// WASMTimeout bounds the time a WebAssembly module takes to process a span, 0 disabling it
+	WASMTimeout time.Duration
+	// SpanRulesFile is the path of the JSON file of the rules dropping, keeping or modifying the spans
+	SpanRulesFile string
+	// K8sMetadata configures the enrichment of the spans with the metadata of their Kubernetes pods
+	K8sMetadata k8smetadata.Options
+	// GeoIP configures the enrichment of the spans with the geolocation of their client IPs
+	GeoIP geoip.Options
+	// Throttling configures the credits granted to the SDKs for the traces forced by their clients
+	Throttling throttler.Options
+	// UDP configures the receivers of the spans sent in Thrift over UDP by the legacy clients
+	UDP udpreceiver.Options
+	// EnvoyALSEnabled enables the Envoy gRPC access log service creating spans from the access logs
+	EnvoyALSEnabled bool
+	// JSONSpans configures the HTTP endpoint accepting the spans in the JSON schema of the jsonspans package
+	JSONSpans jsonspans.Options
+}
+
+// WorkersAutoscaling defines the bounds and the target of the autoscaling of the workers.
+type WorkersAutoscaling struct {
+	// Enabled enables the autoscaling of the workers
+	Enabled bool
+	// MinWorkers is the minimum number of workers
+	MinWorkers int
+	// MaxWorkers is the maximum number of workers
+	MaxWorkers int
+	// TargetLatency is the average write latency of the storage, all backends together,
+	// over which the number of workers is decreased
+	TargetLatency time.Duration
+	// Interval is how often the number of workers is adapted
+	Interval time.Duration
+}
+
+// SpanLimits defines the size limits of the spans, 0 meaning no limit.
+type SpanLimits struct {
+	// MaxTags is the maximum number of tags of a span
+	MaxTags int
+	// MaxTagValueLength is the maximum length in bytes of the string and binary values of the tags and log fields
+	MaxTagValueLength int
+	// MaxLogs is the maximum number of logs of a span
+	MaxLogs int
+	// MaxProcessTags is the maximum number of tags of the process of a span
+	MaxProcessTags int
+}
+
+func (a WorkersAutoscaling) validate() error {
+	if a.MinWorkers <= 0 || a.MaxWorkers < a.MinWorkers {
+		return fmt.Errorf("invalid workers autoscaling bounds [%d, %d], the minimum must be positive and not greater than the maximum", a.MinWorkers, a.MaxWorkers)
+	}
+	if a.TargetLatency <= 0 || a.Interval <= 0 {
+		return fmt.Errorf("invalid workers autoscaling target latency %v or interval %v, they must be positive", a.TargetLatency, a.Interval)
+	}
+	return nil
 }
 
 type serverFlagsConfig struct {
@@ -152,6 +293,12 @@ type serverFlagsConfig struct {
 type HTTPOptions struct {
 	// HostPort is the host:port address that the server listens on
 	HostPort string
+	// Network is the Go TCP network of the server, e.g. tcp4 or tcp6, "tcp" if empty
+	Network string
+	// SocketPermissions are the permissions of the Unix domain socket of a unix:// HostPort, if not zero
+	SocketPermissions os.FileMode
+	// HTTP3HostPort is the UDP host:port address of the HTTP/3 listener of the server, disabled if empty
+	HTTP3HostPort string
 	// TLS configures secure transport for HTTP endpoint
 	TLS tlscfg.Options
 	// ReadTimeout sets the respective parameter of http.Server
@@ -168,6 +315,10 @@ type HTTPOptions struct {
 type GRPCOptions struct {
 	// HostPort is the host:port address that the collector service listens in on for gRPC requests
 	HostPort string
+	// Network is the Go TCP network of the server, e.g. tcp4 or tcp6, "tcp" if empty
+	Network string
+	// SocketPermissions are the permissions of the Unix domain socket of a unix:// HostPort, if not zero
+	SocketPermissions os.FileMode
 	// TLS configures secure transport for gRPC endpoint to collect spans
 	TLS tlscfg.Options
 	// MaxReceiveMessageLength is the maximum message size receivable by the gRPC Collector.
@@ -189,25 +340,107 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.Uint(flagDynQueueSizeMemory, 0, "(experimental) The max memory size in MiB to use for the dynamic queue.")
 	flags.String(flagCollectorTags, "", "One or more tags to be added to the Process tags of all spans passing through this collector. Ex: key1=value1,key2=${envVar:defaultValue}")
 	flags.Bool(flagSpanSizeMetricsEnabled, false, "Enables metrics based on processed span size, which are more expensive to calculate.")
+	flags.Int(flagBatchSize, DefaultBatchSize, "The number of spans written at once to the storage backends supporting batch writes, e.g. Elasticsearch; 1 writes spans one by one.")
+	flags.Duration(flagBatchFlushInterval, DefaultBatchFlushInterval, "How long spans wait for a batch to be full before being written to the storage backends supporting batch writes.")
+	flags.Bool(flagAutoscalingEnabled, false, "Adapts the number of workers of the span processor, shared by all the storage backends, to the storage: it is halved when the average write latency of the span writer exceeds the target latency, and increased when spans queue up otherwise; --collector.num-workers is the initial number of workers.")
+	flags.Int(flagAutoscalingMinWorkers, 1, "The minimum number of workers when the workers autoscaling is enabled.")
+	flags.Int(flagAutoscalingMaxWorkers, 4*DefaultNumWorkers, "The maximum number of workers when the workers autoscaling is enabled.")
+	flags.Duration(flagAutoscalingLatency, 500*time.Millisecond, "The average write latency of the storage, all backends together, over which the number of workers is decreased, when the workers autoscaling is enabled.")
+	flags.Duration(flagAutoscalingInterval, 5*time.Second, "How often the number of workers is adapted, when the workers autoscaling is enabled.")
+	flags.Int(flagMaxTags, 0, "The maximum number of tags of a span, the tags over the limit are dropped; 0 means no limit.")
+	flags.Int(flagMaxTagValueLength, 0, "The maximum length in bytes of the string and binary values of the span tags and log fields, longer values are truncated; 0 means no limit.")
+	flags.Int(flagMaxLogs, 0, "The maximum number of logs of a span, the logs over the limit are dropped; 0 means no limit.")
+	flags.Int(flagMaxProcessTags, 0, "The maximum number of tags of the process of a span, the tags over the limit are dropped; 0 means no limit.")
+	flags.Int(flagDedupeCacheSize, 0, "The number of recently received spans remembered to drop their exact duplicates, e.g. sent again by retrying clients; 0 disables the deduplication.")
+	flags.Bool(flagServiceMetadataTags, false, "Records the metadata of the services reported in the process tags service.description, service.team, service.repository and service.oncall, if supported by the span storage; the metadata set with the query service API takes precedence.")
+	flags.Bool(flagNormalizeErrorStatus, false, "Normalizes the error signaling of the spans (otel.status_code, error tags, HTTP 5xx and gRPC status codes) into a boolean error=true tag and a status.code tag (ERROR, OK or UNSET) before storing them, so that the searches of the errors find the spans of all the SDKs.")
+	flags.String(flagWALDir, "", "The directory of the write-ahead log recording the spans which failed to be written to the storage, to be re-submitted with jaeger-wal-replay once the storage recovers; empty disables the write-ahead log.")
+	flags.Int(flagWALMaxSegmentSize, 64, "The size in MiB at which a segment of the write-ahead log is closed and a new one started.")
+	flags.Int(flagWALMaxSize, 1024, "The maximum size in MiB of the write-ahead log segments not replayed yet, the failed spans over the limit are dropped; 0 means no limit.")
+	flags.Duration(flagWALSegmentCloseInterval, time.Minute, "How often the segment of the write-ahead log being written is closed, so that its spans can be replayed without restarting the collector.")
+	flags.Var(&config.StringSlice{}, flagWASMModules, "The path of a WebAssembly module processing the spans before they are written to the storage, which can modify or drop them. Can be specified multiple times, the modules are applied in order.")
+	flags.Duration(flagWASMTimeout, 100*time.Millisecond, "The maximum time a WebAssembly module takes to process a span, after which the module is interrupted and the span kept as it was; 0 disables the timeout.")
+	flags.Bool(flagK8sMetadataEnabled, false, "Adds the Kubernetes metadata (namespace, pod, deployment, node and labels) of the pods reporting the spans to their process tags, looking up the pods by IP; the collector needs the permissions to list and watch the pods.")
+	flags.String(flagK8sMetadataKubeconfig, "", "The path of the kubeconfig file to connect to the Kubernetes API, the service account of the collector pod being used if empty.")
+	flags.String(flagK8sMetadataNamespace, "", "The namespace of the pods watched to enrich the spans, all the namespaces if empty.")
+	flags.Var(&config.StringSlice{}, flagK8sMetadataLabels, "A pod label added to the process tags as k8s.pod.labels.<label>. Can be specified multiple times; all the labels are added if none is specified.")
+	flags.Duration(flagK8sMetadataSyncTimeout, time.Minute, "How long the collector waits for the pods to be listed when starting.")
+	flags.String(flagGeoIPCountryDatabase, "", "The path of a MaxMind country or city database (e.g. GeoLite2-City.mmdb) used to add the geo.continent.code, geo.country.iso_code and geo.city.name tags to the spans with a client IP tag, reloaded when it changes.")
+	flags.String(flagGeoIPASNDatabase, "", "The path of a MaxMind ASN database (e.g. GeoLite2-ASN.mmdb) used to add the as.number and as.organization.name tags to the spans with a client IP tag, reloaded when it changes.")
+	flags.String(flagGeoIPTags, strings.Join(geoip.DefaultIPTags, ","), "The comma-separated span tags holding the client IP looked up in the GeoIP databases, the first one found being used.")
+	flags.Float64(flagThrottlingCredits, 0, "The rate at which the credits of an operation of a service accrue, each credit allowing an SDK asking for credits with the gRPC throttling API to sample one trace forced by its client, e.g. with the debug flag; 0 disables the throttling API.")
+	flags.Float64(flagThrottlingMaxBalance, 10, "The maximum number of credits of an operation of a service, shared by the instances of the service.")
+	flags.Int(flagThrottlingMaxOperations, 1000, "The maximum number of operations of a service granted credits, the other operations being granted none.")
+	flags.Int(flagThrottlingMaxServices, 10000, "The maximum number of services granted credits, the other services being granted none.")
+	flags.Bool(flagEnvoyALSEnabled, false, "Serves the Envoy gRPC access log service (envoy.service.accesslog.v3.AccessLogService) on the gRPC port, creating a span for every HTTP request logged by the proxies, e.g. of the services of a service mesh which are not instrumented.")
+	flags.Bool(flagJSONSpansEnabled, false, "Serves the endpoint /api/json/spans on the HTTP port, accepting the spans in a simple JSON schema, e.g. for the profilers and eBPF agents which implement neither OTLP nor Thrift")
+	flags.String(flagJSONSpansMappingFile, "", "The path of a JSON file replacing the paths of the fields of the JSON span schema, to accept the spans of an existing schema")
+	flags.String(flagSpanRulesFile, "", "The path of a JSON file of rules dropping, keeping or modifying the spans matching expressions before they are written to the storage, reloaded when it changes; empty disables the span rules.")
 
 	addHTTPFlags(flags, httpServerFlagsCfg, ports.PortToHostPort(ports.CollectorHTTP))
+	addSocketPermissionsFlag(flags, httpServerFlagsCfg, "HTTP", "collector-http.sock")
 	addGRPCFlags(flags, grpcServerFlagsCfg, ports.PortToHostPort(ports.CollectorGRPC))
+	addSocketPermissionsFlag(flags, grpcServerFlagsCfg, "gRPC", "collector-grpc.sock")
 
 	flags.Bool(flagCollectorOTLPEnabled, true, "Enables OpenTelemetry OTLP receiver on dedicated HTTP and gRPC ports")
 	addHTTPFlags(flags, otlpServerFlagsCfg.HTTP, "")
+	flags.String(otlpServerFlagsCfg.HTTP.prefix+"."+flagSuffixHTTP3HostPort, "", "The UDP host:port (e.g. :4318) of the HTTP/3 (QUIC) listener of the OTLP HTTP receiver, e.g. for the mobile SDKs on lossy networks; it requires TLS (disabled by default)")
 	corsOTLPFlags.AddFlags(flags)
 	addGRPCFlags(flags, otlpServerFlagsCfg.GRPC, "")
 
 	flags.String(flagZipkinHTTPHostPort, "", "The host:port (e.g. 127.0.0.1:9411 or :9411) of the collector's Zipkin server (disabled by default)")
+	addIPFamilyFlag(flags, flagZipkinIPFamily, "tcp", "the collector's Zipkin server")
 	flags.Bool(flagZipkinKeepAliveEnabled, true, "KeepAlive configures allow Keep-Alive for Zipkin HTTP server (enabled by default)")
 	tlsZipkinFlagsConfig.AddFlags(flags)
 	corsZipkinFlags.AddFlags(flags)
 
+	addUDPFlags(flags, udpJaegerCompact)
+	addUDPFlags(flags, udpJaegerBinary)
+	addUDPFlags(flags, udpZipkinCompact)
+
 	tenancy.AddFlags(flags)
 }
 
+func addUDPFlags(flags *flag.FlagSet, name string) {
+	prefix := flagPrefixUDP + name + "."
+	flags.String(prefix+flagSuffixHostPort, "", "The host:port (e.g. :6831) of the UDP receiver of the "+name+" Thrift spans of the legacy clients, as sent to jaeger-agent (disabled by default)")
+	addIPFamilyFlag(flags, prefix+flagSuffixIPFamily, "udp", "the "+name+" UDP receiver")
+	flags.Int(prefix+flagSuffixUDPWorkers, 10, "The number of workers decoding the packets of the "+name+" UDP receiver")
+	flags.Int(prefix+flagSuffixUDPQueueSize, 1000, "The number of packets of the "+name+" UDP receiver waiting for a worker, the new packets being dropped when it is full")
+	flags.Int(prefix+flagSuffixUDPMaxPacketSize, 65000, "The maximum size in bytes of the packets of the "+name+" UDP receiver")
+	flags.Int(prefix+flagSuffixUDPSocketBufferSize, 0, "The size in bytes of the socket receive buffer of the "+name+" UDP receiver, 0 keeping the system default")
+}
+
+func initUDPFromViper(v *viper.Viper, name string) (udpreceiver.ServerOptions, error) {
+	prefix := flagPrefixUDP + name + "."
+	network, err := netutils.UDPNetwork(v.GetString(prefix + flagSuffixIPFamily))
+	if err != nil {
+		return udpreceiver.ServerOptions{}, fmt.Errorf("failed to parse the %s UDP receiver options: %w", name, err)
+	}
+	return udpreceiver.ServerOptions{
+		HostPort:         ports.FormatHostPort(v.GetString(prefix + flagSuffixHostPort)),
+		Network:          network,
+		Workers:          v.GetInt(prefix + flagSuffixUDPWorkers),
+		QueueSize:        v.GetInt(prefix + flagSuffixUDPQueueSize),
+		MaxPacketSize:    v.GetInt(prefix + flagSuffixUDPMaxPacketSize),
+		SocketBufferSize: v.GetInt(prefix + flagSuffixUDPSocketBufferSize),
+	}, nil
+}
+
+// addIPFamilyFlag adds the flag of the IP family of a listener of the base network, tcp or udp.
+func addIPFamilyFlag(flags *flag.FlagSet, name string, base string, listener string) {
+	flags.String(name, netutils.NetworkDual, fmt.Sprintf("The IP family of %s: %s accepts both IPv4 and IPv6 when the host is empty or a host name, %s4 only IPv4 and %s6 only IPv6", listener, netutils.NetworkDual, base, base))
+}
+
+// addSocketPermissionsFlag adds the flag of the permissions of the Unix domain socket of a server
+// listening on one, which only the collector's HTTP and gRPC servers support.
+func addSocketPermissionsFlag(flags *flag.FlagSet, cfg serverFlagsConfig, server string, socket string) {
+	flags.String(cfg.prefix+"."+flagSuffixSocketPermissions, "", fmt.Sprintf("The octal permissions (e.g. 0660) of the Unix domain socket of the collector's %s server, if its host-port is the path of one (e.g. unix:///var/run/jaeger/%s); the umask applies if empty", server, socket))
+}
+
 func addHTTPFlags(flags *flag.FlagSet, cfg serverFlagsConfig, defaultHostPort string) {
 	flags.String(cfg.prefix+"."+flagSuffixHostPort, defaultHostPort, "The host:port (e.g. 127.0.0.1:12345 or :12345) of the collector's HTTP server")
+	addIPFamilyFlag(flags, cfg.prefix+"."+flagSuffixIPFamily, "tcp", "the collector's HTTP server")
 	flags.Duration(cfg.prefix+"."+flagSuffixHTTPIdleTimeout, 0, "See https://pkg.go.dev/net/http#Server")
 	flags.Duration(cfg.prefix+"."+flagSuffixHTTPReadTimeout, 0, "See https://pkg.go.dev/net/http#Server")
 	flags.Duration(cfg.prefix+"."+flagSuffixHTTPReadHeaderTimeout, 2*time.Second, "See https://pkg.go.dev/net/http#Server")
@@ -219,6 +452,7 @@ func addGRPCFlags(flags *flag.FlagSet, cfg serverFlagsConfig, defaultHostPort st
 		cfg.prefix+"."+flagSuffixHostPort,
 		defaultHostPort,
 		"The host:port (e.g. 127.0.0.1:12345 or :12345) of the collector's gRPC server")
+	addIPFamilyFlag(flags, cfg.prefix+"."+flagSuffixIPFamily, "tcp", "the collector's gRPC server")
 	flags.Int(
 		cfg.prefix+"."+flagSuffixGRPCMaxReceiveMessageLength,
 		DefaultGRPCMaxReceiveMessageLength,
@@ -236,6 +470,14 @@ func addGRPCFlags(flags *flag.FlagSet, cfg serverFlagsConfig, defaultHostPort st
 
 func (opts *HTTPOptions) initFromViper(v *viper.Viper, _ *zap.Logger, cfg serverFlagsConfig) error {
 	opts.HostPort = ports.FormatHostPort(v.GetString(cfg.prefix + "." + flagSuffixHostPort))
+	network, err := netutils.TCPNetwork(v.GetString(cfg.prefix + "." + flagSuffixIPFamily))
+	if err != nil {
+		return err
+	}
+	opts.Network = network
+	if opts.SocketPermissions, err = netutils.ParseSocketPermissions(v.GetString(cfg.prefix + "." + flagSuffixSocketPermissions)); err != nil {
+		return err
+	}
 	opts.IdleTimeout = v.GetDuration(cfg.prefix + "." + flagSuffixHTTPIdleTimeout)
 	opts.ReadTimeout = v.GetDuration(cfg.prefix + "." + flagSuffixHTTPReadTimeout)
 	opts.ReadHeaderTimeout = v.GetDuration(cfg.prefix + "." + flagSuffixHTTPReadHeaderTimeout)
@@ -244,11 +486,23 @@ func (opts *HTTPOptions) initFromViper(v *viper.Viper, _ *zap.Logger, cfg server
 		return fmt.Errorf("failed to parse HTTP TLS options: %w", err)
 	}
 	opts.TLS = tlsOpts
+	opts.HTTP3HostPort = ports.FormatHostPort(v.GetString(cfg.prefix + "." + flagSuffixHTTP3HostPort))
+	if opts.HTTP3HostPort != "" && !opts.TLS.Enabled {
+		return fmt.Errorf("the HTTP/3 listener requires TLS, see --%s.tls.enabled", cfg.prefix)
+	}
 	return nil
 }
 
 func (opts *GRPCOptions) initFromViper(v *viper.Viper, _ *zap.Logger, cfg serverFlagsConfig) error {
 	opts.HostPort = ports.FormatHostPort(v.GetString(cfg.prefix + "." + flagSuffixHostPort))
+	network, err := netutils.TCPNetwork(v.GetString(cfg.prefix + "." + flagSuffixIPFamily))
+	if err != nil {
+		return err
+	}
+	opts.Network = network
+	if opts.SocketPermissions, err = netutils.ParseSocketPermissions(v.GetString(cfg.prefix + "." + flagSuffixSocketPermissions)); err != nil {
+		return err
+	}
 	opts.MaxReceiveMessageLength = v.GetInt(cfg.prefix + "." + flagSuffixGRPCMaxReceiveMessageLength)
 	opts.MaxConnectionAge = v.GetDuration(cfg.prefix + "." + flagSuffixGRPCMaxConnectionAge)
 	opts.MaxConnectionAgeGrace = v.GetDuration(cfg.prefix + "." + flagSuffixGRPCMaxConnectionAgeGrace)
@@ -269,6 +523,61 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper, logger *zap.Logger)
 	cOpts.QueueSize = v.GetInt(flagQueueSize)
 	cOpts.DynQueueSizeMemory = v.GetUint(flagDynQueueSizeMemory) * 1024 * 1024 // we receive in MiB and store in bytes
 	cOpts.SpanSizeMetricsEnabled = v.GetBool(flagSpanSizeMetricsEnabled)
+	cOpts.BatchSize = v.GetInt(flagBatchSize)
+	cOpts.BatchFlushInterval = v.GetDuration(flagBatchFlushInterval)
+	cOpts.WorkersAutoscaling = WorkersAutoscaling{
+		Enabled:       v.GetBool(flagAutoscalingEnabled),
+		MinWorkers:    v.GetInt(flagAutoscalingMinWorkers),
+		MaxWorkers:    v.GetInt(flagAutoscalingMaxWorkers),
+		TargetLatency: v.GetDuration(flagAutoscalingLatency),
+		Interval:      v.GetDuration(flagAutoscalingInterval),
+	}
+	if cOpts.WorkersAutoscaling.Enabled {
+		if err := cOpts.WorkersAutoscaling.validate(); err != nil {
+			return cOpts, err
+		}
+	}
+	cOpts.SpanLimits = SpanLimits{
+		MaxTags:           v.GetInt(flagMaxTags),
+		MaxTagValueLength: v.GetInt(flagMaxTagValueLength),
+		MaxLogs:           v.GetInt(flagMaxLogs),
+		MaxProcessTags:    v.GetInt(flagMaxProcessTags),
+	}
+	cOpts.DedupeCacheSize = v.GetInt(flagDedupeCacheSize)
+	cOpts.ServiceMetadataFromProcessTags = v.GetBool(flagServiceMetadataTags)
+	cOpts.NormalizeErrorStatus = v.GetBool(flagNormalizeErrorStatus)
+	cOpts.WAL = wal.Options{
+		Dir:                  v.GetString(flagWALDir),
+		MaxSegmentSize:       v.GetInt64(flagWALMaxSegmentSize) * 1024 * 1024, // we receive in MiB and store in bytes
+		MaxSize:              v.GetInt64(flagWALMaxSize) * 1024 * 1024,
+		SegmentCloseInterval: v.GetDuration(flagWALSegmentCloseInterval),
+	}
+	cOpts.WASMModules = v.GetStringSlice(flagWASMModules)
+	cOpts.WASMTimeout = v.GetDuration(flagWASMTimeout)
+	cOpts.SpanRulesFile = v.GetString(flagSpanRulesFile)
+	cOpts.GeoIP = geoip.Options{
+		CountryDatabase: v.GetString(flagGeoIPCountryDatabase),
+		ASNDatabase:     v.GetString(flagGeoIPASNDatabase),
+		IPTags:          strings.Split(v.GetString(flagGeoIPTags), ","),
+	}
+	cOpts.Throttling = throttler.Options{
+		CreditsPerSecond: v.GetFloat64(flagThrottlingCredits),
+		MaxBalance:       v.GetFloat64(flagThrottlingMaxBalance),
+		MaxOperations:    v.GetInt(flagThrottlingMaxOperations),
+		MaxServices:      v.GetInt(flagThrottlingMaxServices),
+	}
+	cOpts.EnvoyALSEnabled = v.GetBool(flagEnvoyALSEnabled)
+	cOpts.JSONSpans = jsonspans.Options{
+		Enabled:     v.GetBool(flagJSONSpansEnabled),
+		MappingFile: v.GetString(flagJSONSpansMappingFile),
+	}
+	cOpts.K8sMetadata = k8smetadata.Options{
+		Enabled:     v.GetBool(flagK8sMetadataEnabled),
+		Kubeconfig:  v.GetString(flagK8sMetadataKubeconfig),
+		Namespace:   v.GetString(flagK8sMetadataNamespace),
+		Labels:      v.GetStringSlice(flagK8sMetadataLabels),
+		SyncTimeout: v.GetDuration(flagK8sMetadataSyncTimeout),
+	}
 
 	if err := cOpts.HTTP.initFromViper(v, logger, httpServerFlagsCfg); err != nil {
 		return cOpts, fmt.Errorf("failed to parse HTTP server options: %w", err)
@@ -289,6 +598,11 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper, logger *zap.Logger)
 
 	cOpts.Zipkin.KeepAlive = v.GetBool(flagZipkinKeepAliveEnabled)
 	cOpts.Zipkin.HTTPHostPort = ports.FormatHostPort(v.GetString(flagZipkinHTTPHostPort))
+	zipkinNetwork, err := netutils.TCPNetwork(v.GetString(flagZipkinIPFamily))
+	if err != nil {
+		return cOpts, fmt.Errorf("failed to parse Zipkin server options: %w", err)
+	}
+	cOpts.Zipkin.HTTPNetwork = zipkinNetwork
 	tlsZipkin, err := tlsZipkinFlagsConfig.InitFromViper(v)
 	if err != nil {
 		return cOpts, fmt.Errorf("failed to parse Zipkin TLS options: %w", err)
@@ -296,5 +610,15 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper, logger *zap.Logger)
 	cOpts.Zipkin.TLS = tlsZipkin
 	cOpts.Zipkin.CORS = corsZipkinFlags.InitFromViper(v)
 
+	for name, udpOpts := range map[string]*udpreceiver.ServerOptions{
+		udpJaegerCompact: &cOpts.UDP.JaegerCompact,
+		udpJaegerBinary:  &cOpts.UDP.JaegerBinary,
+		udpZipkinCompact: &cOpts.UDP.ZipkinCompact,
+	} {
+		if *udpOpts, err = initUDPFromViper(v, name); err != nil {
+			return cOpts, err
+		}
+	}
+
 	return cOpts, nil
 }
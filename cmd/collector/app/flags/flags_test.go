@@ -15,6 +15,7 @@
 package flags
 
 import (
+	"os"
 	"testing"
 	"time"
 
@@ -22,6 +23,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/geoip"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/k8smetadata"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/throttler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/udpreceiver"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 )
@@ -190,6 +197,350 @@ func TestCollectorOptionsWithFlags_CheckZipkinKeepAlive(t *testing.T) {
 	assert.False(t, c.Zipkin.KeepAlive)
 }
 
+func TestCollectorOptionsWithFlags_CheckBatch(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.batch.size=500",
+		"--collector.batch.flush-interval=1s",
+	})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, 500, c.BatchSize)
+	assert.Equal(t, time.Second, c.BatchFlushInterval)
+}
+
+func TestCollectorOptionsWithFlags_CheckSpanLimits(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.span-limits.max-tags=128",
+		"--collector.span-limits.max-tag-value-length=4096",
+		"--collector.span-limits.max-logs=256",
+		"--collector.span-limits.max-process-tags=64",
+	})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, SpanLimits{
+		MaxTags:           128,
+		MaxTagValueLength: 4096,
+		MaxLogs:           256,
+		MaxProcessTags:    64,
+	}, c.SpanLimits)
+}
+
+func TestCollectorOptionsWithFlags_CheckDedupe(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--collector.dedupe.cache-size=10000"})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, 10000, c.DedupeCacheSize)
+}
+
+func TestCollectorOptionsWithFlags_CheckServiceMetadata(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--collector.service-metadata.from-process-tags=true"})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.True(t, c.ServiceMetadataFromProcessTags)
+}
+
+func TestCollectorOptionsWithFlags_CheckNormalizeErrorStatus(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--collector.normalize-error-status=true"})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.True(t, c.NormalizeErrorStatus)
+}
+
+func TestCollectorOptionsWithFlags_CheckWorkersAutoscaling(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.workers-autoscaling.enabled=true",
+		"--collector.workers-autoscaling.min=5",
+		"--collector.workers-autoscaling.max=100",
+		"--collector.workers-autoscaling.target-latency=1s",
+		"--collector.workers-autoscaling.interval=10s",
+	})
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, WorkersAutoscaling{
+		Enabled:       true,
+		MinWorkers:    5,
+		MaxWorkers:    100,
+		TargetLatency: time.Second,
+		Interval:      10 * time.Second,
+	}, c.WorkersAutoscaling)
+}
+
+func TestCollectorOptionsWithFlags_CheckWorkersAutoscalingErrors(t *testing.T) {
+	tests := []struct {
+		flags []string
+		err   string
+	}{
+		{flags: []string{"--collector.workers-autoscaling.min=0"}, err: "invalid workers autoscaling bounds [0, 200]"},
+		{flags: []string{"--collector.workers-autoscaling.max=0"}, err: "invalid workers autoscaling bounds [1, 0]"},
+		{flags: []string{"--collector.workers-autoscaling.interval=0"}, err: "invalid workers autoscaling target latency 500ms or interval 0s"},
+	}
+	for _, test := range tests {
+		t.Run(test.err, func(t *testing.T) {
+			c := &CollectorOptions{}
+			v, command := config.Viperize(AddFlags)
+			command.ParseFlags(append(test.flags, "--collector.workers-autoscaling.enabled=true"))
+			_, err := c.InitFromViper(v, zap.NewNop())
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+	// the bounds are only checked when the autoscaling is enabled
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--collector.workers-autoscaling.min=0"})
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+}
+
+func TestCollectorOptionsWithFlags_CheckWAL(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.wal.dir=/var/lib/jaeger/wal",
+		"--collector.wal.max-segment-size-mib=8",
+		"--collector.wal.max-size-mib=0",
+		"--collector.wal.segment-close-interval=30s",
+	})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, wal.Options{
+		Dir:                  "/var/lib/jaeger/wal",
+		MaxSegmentSize:       8 * 1024 * 1024,
+		MaxSize:              0,
+		SegmentCloseInterval: 30 * time.Second,
+	}, c.WAL)
+}
+
+func TestCollectorOptionsWithFlags_CheckWASMModules(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.wasm.modules=/etc/jaeger/enrich.wasm",
+		"--collector.wasm.modules=/etc/jaeger/filter.wasm",
+		"--collector.wasm.timeout=250ms",
+	})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, []string{"/etc/jaeger/enrich.wasm", "/etc/jaeger/filter.wasm"}, c.WASMModules)
+	assert.Equal(t, 250*time.Millisecond, c.WASMTimeout)
+}
+
+func TestCollectorOptionsWithFlags_CheckSpanRulesFile(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.span-rules.file=/etc/jaeger/span-rules.json",
+	})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, "/etc/jaeger/span-rules.json", c.SpanRulesFile)
+}
+
+func TestCollectorOptionsWithFlags_CheckK8sMetadata(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.k8s-metadata.enabled=true",
+		"--collector.k8s-metadata.kubeconfig=/etc/jaeger/kubeconfig",
+		"--collector.k8s-metadata.namespace=shop",
+		"--collector.k8s-metadata.labels=app",
+		"--collector.k8s-metadata.labels=team",
+		"--collector.k8s-metadata.sync-timeout=30s",
+	})
+	c.InitFromViper(v, zap.NewNop())
+
+	assert.Equal(t, k8smetadata.Options{
+		Enabled:     true,
+		Kubeconfig:  "/etc/jaeger/kubeconfig",
+		Namespace:   "shop",
+		Labels:      []string{"app", "team"},
+		SyncTimeout: 30 * time.Second,
+	}, c.K8sMetadata)
+}
+
+func TestCollectorOptionsWithFlags_CheckGeoIP(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, geoip.Options{IPTags: []string{"http.client_ip", "net.peer.ip"}}, c.GeoIP)
+
+	command.ParseFlags([]string{
+		"--collector.geoip.country-database=/var/lib/GeoIP/GeoLite2-City.mmdb",
+		"--collector.geoip.asn-database=/var/lib/GeoIP/GeoLite2-ASN.mmdb",
+		"--collector.geoip.ip-tags=client.address,http.client_ip",
+	})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, geoip.Options{
+		CountryDatabase: "/var/lib/GeoIP/GeoLite2-City.mmdb",
+		ASNDatabase:     "/var/lib/GeoIP/GeoLite2-ASN.mmdb",
+		IPTags:          []string{"client.address", "http.client_ip"},
+	}, c.GeoIP)
+}
+
+func TestCollectorOptionsWithFlags_CheckThrottling(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, throttler.Options{MaxBalance: 10, MaxOperations: 1000, MaxServices: 10000}, c.Throttling)
+	assert.False(t, c.Throttling.Enabled())
+
+	command.ParseFlags([]string{
+		"--collector.throttling.credits-per-second=0.5",
+		"--collector.throttling.max-balance=2",
+		"--collector.throttling.max-operations=100",
+		"--collector.throttling.max-services=50",
+	})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, throttler.Options{CreditsPerSecond: 0.5, MaxBalance: 2, MaxOperations: 100, MaxServices: 50}, c.Throttling)
+}
+
+func TestCollectorOptionsWithFlags_CheckUDP(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	c.InitFromViper(v, zap.NewNop())
+	defaults := udpreceiver.ServerOptions{Network: "udp", Workers: 10, QueueSize: 1000, MaxPacketSize: 65000}
+	assert.Equal(t, udpreceiver.Options{JaegerCompact: defaults, JaegerBinary: defaults, ZipkinCompact: defaults}, c.UDP)
+	assert.False(t, c.UDP.Enabled())
+
+	command.ParseFlags([]string{
+		"--collector.udp.jaeger-compact.host-port=6831",
+		"--collector.udp.jaeger-compact.ip-family=udp6",
+		"--collector.udp.jaeger-compact.workers=20",
+		"--collector.udp.jaeger-compact.queue-size=5000",
+		"--collector.udp.jaeger-compact.max-packet-size=9000",
+		"--collector.udp.jaeger-compact.socket-buffer-size=4194304",
+		"--collector.udp.zipkin-compact.host-port=:5775",
+	})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, udpreceiver.ServerOptions{HostPort: ":6831", Network: "udp6", Workers: 20, QueueSize: 5000, MaxPacketSize: 9000, SocketBufferSize: 4194304}, c.UDP.JaegerCompact)
+	assert.Equal(t, defaults, c.UDP.JaegerBinary)
+	assert.Equal(t, ":5775", c.UDP.ZipkinCompact.HostPort)
+	assert.True(t, c.UDP.Enabled())
+}
+
+func TestCollectorOptionsWithFlags_CheckIPFamily(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", c.HTTP.Network)
+	assert.Equal(t, "tcp", c.GRPC.Network)
+	assert.Equal(t, "tcp", c.OTLP.GRPC.Network)
+	assert.Equal(t, "tcp", c.Zipkin.HTTPNetwork)
+
+	command.ParseFlags([]string{
+		"--collector.http-server.ip-family=tcp6",
+		"--collector.grpc-server.ip-family=tcp4",
+		"--collector.otlp.grpc.ip-family=tcp6",
+		"--collector.otlp.http.ip-family=tcp4",
+		"--collector.zipkin.ip-family=tcp6",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "tcp6", c.HTTP.Network)
+	assert.Equal(t, "tcp4", c.GRPC.Network)
+	assert.Equal(t, "tcp6", c.OTLP.GRPC.Network)
+	assert.Equal(t, "tcp4", c.OTLP.HTTP.Network)
+	assert.Equal(t, "tcp6", c.Zipkin.HTTPNetwork)
+}
+
+func TestCollectorOptionsWithFlags_CheckIPFamilyErrors(t *testing.T) {
+	for _, flag := range []string{
+		"--collector.http-server.ip-family=udp4",
+		"--collector.grpc-server.ip-family=ipv6",
+		"--collector.zipkin.ip-family=tcp5",
+		"--collector.udp.jaeger-binary.ip-family=tcp4",
+	} {
+		c := &CollectorOptions{}
+		v, command := config.Viperize(AddFlags)
+		require.NoError(t, command.ParseFlags([]string{flag}))
+		_, err := c.InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid IP family", flag)
+	}
+}
+
+func TestCollectorOptionsWithFlags_CheckSocketPermissions(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.http-server.host-port=unix:///var/run/jaeger/collector-http.sock",
+		"--collector.http-server.socket-permissions=0660",
+		"--collector.grpc-server.socket-permissions=600",
+	})
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///var/run/jaeger/collector-http.sock", c.HTTP.HostPort)
+	assert.Equal(t, os.FileMode(0o660), c.HTTP.SocketPermissions)
+	assert.Equal(t, os.FileMode(0o600), c.GRPC.SocketPermissions)
+	assert.Equal(t, os.FileMode(0), c.OTLP.GRPC.SocketPermissions)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--collector.grpc-server.socket-permissions=0999"}))
+	_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `invalid socket permissions "0999"`)
+}
+
+func TestCollectorOptionsWithFlags_CheckHTTP3(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.otlp.http.http3-host-port=4318",
+		"--collector.otlp.http.tls.enabled=true",
+	})
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, ":4318", c.OTLP.HTTP.HTTP3HostPort)
+	assert.Empty(t, c.HTTP.HTTP3HostPort)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--collector.otlp.http.http3-host-port=:4318"}))
+	_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+	require.EqualError(t, err, "failed to parse OTLP/HTTP server options: the HTTP/3 listener requires TLS, see --collector.otlp.http.tls.enabled")
+}
+
+func TestCollectorOptionsWithFlags_CheckEnvoyALS(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	c.InitFromViper(v, zap.NewNop())
+	assert.False(t, c.EnvoyALSEnabled)
+
+	command.ParseFlags([]string{"--collector.envoy-als.enabled=true"})
+	c.InitFromViper(v, zap.NewNop())
+	assert.True(t, c.EnvoyALSEnabled)
+}
+
+func TestCollectorOptionsWithFlags_CheckJSONSpans(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, jsonspans.Options{}, c.JSONSpans)
+
+	command.ParseFlags([]string{
+		"--collector.json-spans.enabled=true",
+		"--collector.json-spans.mapping-file=/etc/jaeger/mapping.json",
+	})
+	c.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, jsonspans.Options{Enabled: true, MappingFile: "/etc/jaeger/mapping.json"}, c.JSONSpans)
+}
+
 func TestMain(m *testing.M) {
 	testutils.VerifyGoLeaks(m)
 }
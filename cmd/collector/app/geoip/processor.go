@@ -0,0 +1,270 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package geoip adds the geolocation and the autonomous system of the client IPs recorded in
+// the span tags, looked up in local MaxMind databases, e.g. GeoLite2-Country and GeoLite2-ASN.
+//
+// The first of the IP tags found in a span is looked up, and the tags found in the databases
+// are added to the span: geo.continent.code, geo.country.iso_code and geo.city.name from a
+// country or city database, as.number and as.organization.name from an ASN database. The
+// databases are reloaded when their files change, e.g. when they are updated by geoipupdate.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/oschwald/maxminddb-golang"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/fswatcher"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_geoip_spans_total",
+			Type: telemetery.Counter,
+			Help: "Spans with a client IP tag enriched with its geolocation or autonomous system, or whose IP was not found in the GeoIP databases",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"enriched", "unresolved"}},
+			},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_geoip_reloads_total",
+			Type: telemetery.Counter,
+			Help: "Reloads of the GeoIP databases, the previous database is kept when it fails",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"ok", "err"}},
+			},
+		},
+	)
+}
+
+const (
+	tagContinentCode  = "geo.continent.code"
+	tagCountryISOCode = "geo.country.iso_code"
+	tagCityName       = "geo.city.name"
+	tagASNumber       = "as.number"
+	tagASOrganization = "as.organization.name"
+)
+
+// DefaultIPTags are the span tags holding the IP of the client in the OpenTelemetry semantic conventions.
+var DefaultIPTags = []string{"http.client_ip", "net.peer.ip"}
+
+// Options configures the GeoIP enrichment of the spans.
+type Options struct {
+	// CountryDatabase is the path of a MaxMind country or city database
+	CountryDatabase string
+	// ASNDatabase is the path of a MaxMind ASN database
+	ASNDatabase string
+	// IPTags are the span tags looked up, in order
+	IPTags []string
+}
+
+// Enabled returns whether a database is configured.
+func (o Options) Enabled() bool {
+	return o.CountryDatabase != "" || o.ASNDatabase != ""
+}
+
+type processorMetrics struct {
+	// Enriched counts the spans whose client IP was found in a database
+	Enriched metrics.Counter `metric:"geoip.spans" tags:"result=enriched"`
+	// Unresolved counts the spans whose client IP was not found in the databases
+	Unresolved metrics.Counter `metric:"geoip.spans" tags:"result=unresolved"`
+	// Reloads counts the reloads of the databases
+	Reloads metrics.Counter `metric:"geoip.reloads" tags:"result=ok"`
+	// ReloadFailures counts the reloads of the databases which failed, leaving the previous database in place
+	ReloadFailures metrics.Counter `metric:"geoip.reloads" tags:"result=err"`
+}
+
+type countryRecord struct {
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type asnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Processor adds the geolocation and the autonomous system of the client IPs to the spans.
+type Processor struct {
+	ipTags  []string
+	country *database
+	asn     *database
+	logger  *zap.Logger
+	metrics processorMetrics
+}
+
+// NewProcessor loads the databases of options and watches their files to reload them.
+func NewProcessor(options Options, metricsFactory metrics.Factory, logger *zap.Logger) (*Processor, error) {
+	p := &Processor{
+		ipTags: options.IPTags,
+		logger: logger,
+	}
+	if len(p.ipTags) == 0 {
+		p.ipTags = DefaultIPTags
+	}
+	metrics.MustInit(&p.metrics, metricsFactory, nil)
+	if options.CountryDatabase != "" {
+		country, err := p.openDatabase(options.CountryDatabase, "Country", "City")
+		if err != nil {
+			return nil, err
+		}
+		p.country = country
+	}
+	if options.ASNDatabase != "" {
+		asn, err := p.openDatabase(options.ASNDatabase, "ASN")
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.asn = asn
+	}
+	return p, nil
+}
+
+// Process adds the tags found in the databases for the first IP tag of the span, keeping
+// the tags already set. It never drops the span.
+func (p *Processor) Process(span *model.Span) *model.Span {
+	ip := p.clientIP(span)
+	if ip == nil {
+		return span
+	}
+	var tags []model.KeyValue
+	if p.country != nil {
+		var record countryRecord
+		if p.country.lookup(ip, &record, p.logger) {
+			tags = appendString(tags, tagContinentCode, record.Continent.Code)
+			tags = appendString(tags, tagCountryISOCode, record.Country.ISOCode)
+			tags = appendString(tags, tagCityName, record.City.Names["en"])
+		}
+	}
+	if p.asn != nil {
+		var record asnRecord
+		if p.asn.lookup(ip, &record, p.logger) && record.Number != 0 {
+			tags = append(tags, model.Int64(tagASNumber, int64(record.Number)))
+			tags = appendString(tags, tagASOrganization, record.Organization)
+		}
+	}
+	if len(tags) == 0 {
+		p.metrics.Unresolved.Inc(1)
+		return span
+	}
+	p.metrics.Enriched.Inc(1)
+	for _, tag := range tags {
+		if _, ok := model.KeyValues(span.Tags).FindByKey(tag.Key); !ok {
+			span.Tags = append(span.Tags, tag)
+		}
+	}
+	return span
+}
+
+func (p *Processor) clientIP(span *model.Span) net.IP {
+	for _, key := range p.ipTags {
+		if tag, ok := model.KeyValues(span.Tags).FindByKey(key); ok {
+			if ip := net.ParseIP(tag.AsString()); ip != nil {
+				return ip
+			}
+		}
+	}
+	return nil
+}
+
+func appendString(tags []model.KeyValue, key string, value string) []model.KeyValue {
+	if value == "" {
+		return tags
+	}
+	return append(tags, model.String(key, value))
+}
+
+// Close stops watching the databases.
+func (p *Processor) Close() error {
+	var errs []error
+	for _, db := range []*database{p.country, p.asn} {
+		if db != nil {
+			errs = append(errs, db.watcher.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// database is a MaxMind database, replaced when its file changes.
+type database struct {
+	path    string
+	types   []string
+	reader  atomic.Pointer[maxminddb.Reader]
+	watcher *fswatcher.FSWatcher
+}
+
+func (p *Processor) openDatabase(path string, types ...string) (*database, error) {
+	db := &database{path: path, types: types}
+	reader, err := db.load()
+	if err != nil {
+		return nil, err
+	}
+	db.reader.Store(reader)
+	watcher, err := fswatcher.New([]string{path}, func() { p.reload(db) }, p.logger)
+	if err != nil {
+		return nil, err
+	}
+	db.watcher = watcher
+	return db, nil
+}
+
+// load reads the database in memory, so that it can be replaced while it is being read.
+func (db *database) load() (*maxminddb.Reader, error) {
+	data, err := os.ReadFile(filepath.Clean(db.path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the GeoIP database: %w", err)
+	}
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the GeoIP database %s: %w", db.path, err)
+	}
+	for _, t := range db.types {
+		if strings.Contains(reader.Metadata.DatabaseType, t) {
+			return reader, nil
+		}
+	}
+	return nil, fmt.Errorf("the GeoIP database %s is a %s database, expected a %s database",
+		db.path, reader.Metadata.DatabaseType, strings.Join(db.types, " or "))
+}
+
+func (p *Processor) reload(db *database) {
+	reader, err := db.load()
+	if err != nil {
+		p.metrics.ReloadFailures.Inc(1)
+		p.logger.Error("Failed to reload the GeoIP database, keeping the previous database", zap.Error(err))
+		return
+	}
+	db.reader.Store(reader)
+	p.metrics.Reloads.Inc(1)
+	p.logger.Info("Reloaded the GeoIP database", zap.String("path", db.path), zap.String("type", reader.Metadata.DatabaseType))
+}
+
+// lookup decodes the record of ip into record and returns whether it was found.
+func (db *database) lookup(ip net.IP, record any, logger *zap.Logger) bool {
+	_, found, err := db.reader.Load().LookupNetwork(ip, record)
+	if err != nil {
+		logger.Debug("Failed to look up the IP in the GeoIP database", zap.Stringer("ip", ip), zap.Error(err))
+		return false
+	}
+	return found
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+var (
+	londonRecord = mmdbtype.Map{
+		"continent": mmdbtype.Map{"code": mmdbtype.String("EU")},
+		"country":   mmdbtype.Map{"iso_code": mmdbtype.String("GB")},
+		"city":      mmdbtype.Map{"names": mmdbtype.Map{"en": mmdbtype.String("London")}},
+	}
+	telstraRecord = mmdbtype.Map{
+		"autonomous_system_number":       mmdbtype.Uint32(1221),
+		"autonomous_system_organization": mmdbtype.String("Telstra Pty Ltd"),
+	}
+)
+
+// writeDatabase writes a MaxMind database of the records by network at path, replacing the
+// file at once so that the watcher never reads it partially written.
+func writeDatabase(t *testing.T, path string, databaseType string, records map[string]mmdbtype.Map) {
+	tree, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: databaseType})
+	require.NoError(t, err)
+	for cidr, record := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		require.NoError(t, tree.Insert(network, record))
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	require.NoError(t, err)
+	_, err = tree.WriteTo(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func newTestProcessor(t *testing.T, ipTags []string, metricsFactory metrics.Factory) (*Processor, Options) {
+	dir := t.TempDir()
+	options := Options{
+		CountryDatabase: filepath.Join(dir, "GeoLite2-City.mmdb"),
+		ASNDatabase:     filepath.Join(dir, "GeoLite2-ASN.mmdb"),
+		IPTags:          ipTags,
+	}
+	writeDatabase(t, options.CountryDatabase, "GeoLite2-City", map[string]mmdbtype.Map{"81.2.69.0/24": londonRecord})
+	writeDatabase(t, options.ASNDatabase, "GeoLite2-ASN", map[string]mmdbtype.Map{
+		"81.2.69.0/24": telstraRecord,
+		"1.128.0.0/11": telstraRecord,
+	})
+	p, err := NewProcessor(options, metricsFactory, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+	return p, options
+}
+
+func londonTags(tags ...model.KeyValue) []model.KeyValue {
+	return append(tags,
+		model.String("geo.continent.code", "EU"),
+		model.String("geo.country.iso_code", "GB"),
+		model.String("geo.city.name", "London"),
+		model.Int64("as.number", 1221),
+		model.String("as.organization.name", "Telstra Pty Ltd"),
+	)
+}
+
+func TestProcessor(t *testing.T) {
+	p, _ := newTestProcessor(t, nil, metrics.NullFactory)
+	tests := []struct {
+		name     string
+		tags     []model.KeyValue
+		expected []model.KeyValue
+	}{
+		{
+			name: "no IP tag",
+			tags: []model.KeyValue{model.String("http.method", "GET")},
+		},
+		{
+			name:     "http.client_ip",
+			tags:     []model.KeyValue{model.String("http.client_ip", "81.2.69.142")},
+			expected: londonTags(model.String("http.client_ip", "81.2.69.142")),
+		},
+		{
+			name:     "net.peer.ip",
+			tags:     []model.KeyValue{model.String("net.peer.ip", "81.2.69.142")},
+			expected: londonTags(model.String("net.peer.ip", "81.2.69.142")),
+		},
+		{
+			name: "first IP tag",
+			tags: []model.KeyValue{model.String("net.peer.ip", "81.2.69.142"), model.String("http.client_ip", "1.128.0.1")},
+			expected: []model.KeyValue{
+				model.String("net.peer.ip", "81.2.69.142"),
+				model.String("http.client_ip", "1.128.0.1"),
+				model.Int64("as.number", 1221),
+				model.String("as.organization.name", "Telstra Pty Ltd"),
+			},
+		},
+		{
+			name: "invalid IP",
+			tags: []model.KeyValue{model.String("http.client_ip", "unknown")},
+		},
+		{
+			name: "not found",
+			tags: []model.KeyValue{model.String("http.client_ip", "8.8.8.8")},
+		},
+		{
+			name: "existing tags kept",
+			tags: []model.KeyValue{model.String("http.client_ip", "81.2.69.142"), model.String("geo.country.iso_code", "FR")},
+			expected: append(
+				[]model.KeyValue{model.String("http.client_ip", "81.2.69.142"), model.String("geo.country.iso_code", "FR")},
+				model.String("geo.continent.code", "EU"),
+				model.String("geo.city.name", "London"),
+				model.Int64("as.number", 1221),
+				model.String("as.organization.name", "Telstra Pty Ltd"),
+			),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			span := &model.Span{Tags: test.tags}
+			assert.Same(t, span, p.Process(span))
+			if test.expected == nil {
+				test.expected = test.tags
+			}
+			assert.Equal(t, test.expected, span.Tags)
+		})
+	}
+}
+
+func TestProcessorIPTags(t *testing.T) {
+	p, _ := newTestProcessor(t, []string{"client.address"}, metrics.NullFactory)
+	span := &model.Span{Tags: []model.KeyValue{model.String("http.client_ip", "81.2.69.142")}}
+	p.Process(span)
+	assert.Len(t, span.Tags, 1)
+
+	span = &model.Span{Tags: []model.KeyValue{model.String("client.address", "81.2.69.142")}}
+	p.Process(span)
+	assert.Equal(t, londonTags(model.String("client.address", "81.2.69.142")), span.Tags)
+}
+
+func TestProcessorReload(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	p, options := newTestProcessor(t, nil, mb)
+	process := func() []model.KeyValue {
+		span := &model.Span{Tags: []model.KeyValue{model.String("http.client_ip", "1.128.0.1")}}
+		return p.Process(span).Tags
+	}
+	require.Len(t, process(), 3)
+
+	writeDatabase(t, options.CountryDatabase, "GeoLite2-City", map[string]mmdbtype.Map{"1.128.0.0/11": londonRecord})
+	assert.Eventually(t, func() bool {
+		return len(process()) == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the previous database is kept when the new one is invalid
+	writeDatabase(t, options.CountryDatabase, "GeoLite2-ASN", nil)
+	assert.Eventually(t, func() bool {
+		counters, _ := mb.Snapshot()
+		return counters["geoip.reloads|result=err"] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, process(), 6)
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "geoip.reloads", Tags: map[string]string{"result": "ok"}, Value: 1})
+}
+
+func TestProcessorMetrics(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	p, _ := newTestProcessor(t, nil, mb)
+	p.Process(&model.Span{Tags: []model.KeyValue{model.String("http.client_ip", "81.2.69.142")}})
+	p.Process(&model.Span{Tags: []model.KeyValue{model.String("http.client_ip", "8.8.8.8")}})
+	p.Process(&model.Span{})
+
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "geoip.spans", Tags: map[string]string{"result": "enriched"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "geoip.spans", Tags: map[string]string{"result": "unresolved"}, Value: 1},
+	)
+}
+
+func TestNewProcessorErrors(t *testing.T) {
+	dir := t.TempDir()
+	country := filepath.Join(dir, "GeoLite2-Country.mmdb")
+	writeDatabase(t, country, "GeoLite2-Country", nil)
+	asn := filepath.Join(dir, "GeoLite2-ASN.mmdb")
+	writeDatabase(t, asn, "GeoLite2-ASN", nil)
+	invalid := filepath.Join(dir, "invalid.mmdb")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a database"), 0o600))
+
+	tests := []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{
+			name:    "missing file",
+			options: Options{CountryDatabase: filepath.Join(dir, "missing.mmdb")},
+			err:     "failed to read the GeoIP database",
+		},
+		{
+			name:    "invalid database",
+			options: Options{ASNDatabase: invalid},
+			err:     "failed to open the GeoIP database " + invalid,
+		},
+		{
+			name:    "wrong database type",
+			options: Options{CountryDatabase: asn},
+			err:     "is a GeoLite2-ASN database, expected a Country or City database",
+		},
+		{
+			name:    "invalid ASN database after the country database",
+			options: Options{CountryDatabase: country, ASNDatabase: invalid},
+			err:     "failed to open the GeoIP database " + invalid,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewProcessor(test.options, metrics.NullFactory, zap.NewNop())
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestOptionsEnabled(t *testing.T) {
+	assert.False(t, Options{}.Enabled())
+	assert.True(t, Options{CountryDatabase: "country.mmdb"}.Enabled())
+	assert.True(t, Options{ASNDatabase: "asn.mmdb"}.Enabled())
+}
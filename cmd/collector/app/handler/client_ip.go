@@ -0,0 +1,37 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"net"
+
+	"go.opentelemetry.io/collector/client"
+	"google.golang.org/grpc/peer"
+)
+
+// clientIP returns the IP of the client which sent the request of ctx, or "" if unknown.
+// The OTLP and Zipkin receivers record the client in the collector client info, the
+// gRPC server in the peer.
+func clientIP(ctx context.Context) string {
+	if addr := client.FromContext(ctx).Addr; addr != nil {
+		return addrIP(addr.String())
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return addrIP(p.Addr.String())
+	}
+	return ""
+}
+
+// addrIP returns the IP of the host:port address, or "" if it has no IP.
+func addrIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if net.ParseIP(host) == nil {
+		return ""
+	}
+	return host
+}
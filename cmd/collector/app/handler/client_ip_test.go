@@ -0,0 +1,40 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/client"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientIP(t *testing.T) {
+	tcpAddr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4317}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{name: "unknown", ctx: context.Background()},
+		{name: "client info", ctx: client.NewContext(context.Background(), client.Info{Addr: tcpAddr}), expected: "10.0.0.1"},
+		{name: "peer", ctx: peer.NewContext(context.Background(), &peer.Peer{Addr: tcpAddr}), expected: "10.0.0.1"},
+		{name: "unix socket", ctx: peer.NewContext(context.Background(), &peer.Peer{Addr: &net.UnixAddr{Name: "/tmp/jaeger.sock"}})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, clientIP(test.ctx))
+		})
+	}
+}
+
+func TestAddrIP(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", addrIP("10.0.0.1:14268"))
+	assert.Equal(t, "fd00::1", addrIP("[fd00::1]:14268"))
+	assert.Equal(t, "10.0.0.1", addrIP("10.0.0.1"))
+	assert.Equal(t, "", addrIP("localhost:14268"))
+}
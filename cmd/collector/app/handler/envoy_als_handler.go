@@ -0,0 +1,314 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"io"
+	"strings"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	accesslogdatav3 "github.com/envoyproxy/go-control-plane/envoy/data/accesslog/v3"
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/service/accesslog/v3"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_envoy_als_entries_total",
+			Type: telemetery.Counter,
+			Help: "Envoy access log entries received with the gRPC access log service, the HTTP entries being converted to spans and the TCP entries ignored",
+			Labels: []telemetery.Label{
+				{Name: "type", Values: []string{"http", "tcp"}},
+			},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_envoy_als_rejected_spans_total",
+			Type: telemetery.Counter,
+			Help: "Spans converted from Envoy access log entries which the collector failed to process, e.g. because its queue was full",
+		},
+	)
+}
+
+const envoyUnknownOperation = "unknown"
+
+type envoyALSMetrics struct {
+	// HTTPEntries counts the HTTP access log entries converted to spans
+	HTTPEntries metrics.Counter `metric:"envoy_als.entries" tags:"type=http"`
+	// TCPEntries counts the TCP access log entries, which are ignored
+	TCPEntries metrics.Counter `metric:"envoy_als.entries" tags:"type=tcp"`
+	// RejectedSpans counts the converted spans which failed to be processed
+	RejectedSpans metrics.Counter `metric:"envoy_als.rejected_spans"`
+}
+
+// EnvoyALSHandler implements the Envoy gRPC access log service, synthesizing a span for every
+// HTTP request proxied by Envoy, so that the service mesh topology includes the services which
+// are not instrumented.
+//
+// The spans of a request join the trace of its traceparent header if Envoy logs it, e.g. with
+// additional_request_headers_to_log, or the trace identified by the hash of its x-request-id,
+// which Envoy propagates along the requests, so that the spans of the proxies on the path
+// of a request are in the same trace.
+type EnvoyALSHandler struct {
+	accesslogv3.UnimplementedAccessLogServiceServer
+
+	logger        *zap.Logger
+	batchConsumer batchConsumer
+	metrics       envoyALSMetrics
+}
+
+// NewEnvoyALSHandler creates an EnvoyALSHandler passing the spans to spanProcessor.
+func NewEnvoyALSHandler(logger *zap.Logger, spanProcessor processor.SpanProcessor, tenancyMgr *tenancy.Manager, metricsFactory metrics.Factory) *EnvoyALSHandler {
+	h := &EnvoyALSHandler{
+		logger: logger,
+		batchConsumer: newBatchConsumer(logger,
+			spanProcessor,
+			processor.GRPCTransport,
+			processor.EnvoyALSSpanFormat,
+			tenancyMgr),
+	}
+	metrics.MustInit(&h.metrics, metricsFactory, nil)
+	return h
+}
+
+// StreamAccessLogs implements accesslogv3.AccessLogServiceServer.
+func (h *EnvoyALSHandler) StreamAccessLogs(stream accesslogv3.AccessLogService_StreamAccessLogsServer) error {
+	// the identifier of the Envoy node is only sent with the first message of the stream
+	var process *model.Process
+	for {
+		message, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&accesslogv3.StreamAccessLogsResponse{})
+		}
+		if err != nil {
+			return err
+		}
+		if identifier := message.GetIdentifier(); identifier != nil {
+			process = envoyProcess(identifier)
+		}
+		if process == nil {
+			process = envoyProcess(nil)
+		}
+		h.metrics.TCPEntries.Inc(int64(len(message.GetTcpLogs().GetLogEntry())))
+		entries := message.GetHttpLogs().GetLogEntry()
+		if len(entries) == 0 {
+			continue
+		}
+		h.metrics.HTTPEntries.Inc(int64(len(entries)))
+		spans := make([]*model.Span, 0, len(entries))
+		for _, entry := range entries {
+			spans = append(spans, envoyEntryToSpan(entry, process))
+		}
+		// the logs are not sent again by Envoy if the stream fails, so the spans are dropped
+		// rather than the stream closed when the collector is busy
+		if err := h.batchConsumer.consume(stream.Context(), &model.Batch{Spans: spans, Process: process}); err != nil {
+			h.metrics.RejectedSpans.Inc(int64(len(spans)))
+			h.logger.Debug("Failed to process the spans of the Envoy access logs", zap.Error(err))
+		}
+	}
+}
+
+// envoyProcess returns the process of the spans of an Envoy node, named after its cluster,
+// e.g. set with --service-cluster.
+func envoyProcess(identifier *accesslogv3.StreamAccessLogsMessage_Identifier) *model.Process {
+	node := identifier.GetNode()
+	serviceName := node.GetCluster()
+	if serviceName == "" {
+		serviceName = node.GetId()
+	}
+	if serviceName == "" {
+		serviceName = "envoy"
+	}
+	var tags []model.KeyValue
+	if id := node.GetId(); id != "" {
+		tags = append(tags, model.String("node_id", id))
+	}
+	if zone := node.GetLocality().GetZone(); zone != "" {
+		tags = append(tags, model.String("zone", zone))
+	}
+	if logName := identifier.GetLogName(); logName != "" {
+		tags = append(tags, model.String("envoy.log_name", logName))
+	}
+	return model.NewProcess(serviceName, tags)
+}
+
+// envoyEntryToSpan converts an HTTP access log entry to a span with the tags of the spans of
+// the Envoy tracers.
+func envoyEntryToSpan(entry *accesslogdatav3.HTTPAccessLogEntry, process *model.Process) *model.Span {
+	common := entry.GetCommonProperties()
+	request := entry.GetRequest()
+	response := entry.GetResponse()
+
+	span := &model.Span{
+		OperationName: envoyOperationName(entry),
+		StartTime:     common.GetStartTime().AsTime(),
+		Duration:      envoyDuration(common),
+		Flags:         model.Flags(0),
+		Process:       process,
+	}
+	span.Flags.SetSampled()
+	requestID := request.GetRequestId()
+	parent := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(request.GetRequestHeaders()))
+	if sc := trace.SpanContextFromContext(parent); sc.IsValid() {
+		traceID, spanID := sc.TraceID(), sc.SpanID()
+		span.TraceID = model.NewTraceID(binary.BigEndian.Uint64(traceID[:8]), binary.BigEndian.Uint64(traceID[8:]))
+		span.References = []model.SpanRef{model.NewChildOfRef(span.TraceID, model.NewSpanID(binary.BigEndian.Uint64(spanID[:])))}
+	} else if requestID != "" {
+		h := fnv.New128a()
+		h.Write([]byte(requestID))
+		sum := h.Sum(nil)
+		span.TraceID = model.NewTraceID(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]))
+	} else {
+		span.TraceID = model.NewRandomTraceID()
+	}
+	span.SpanID = envoySpanID(span.TraceID, process, entry)
+
+	kind := "server"
+	if strings.HasPrefix(common.GetUpstreamCluster(), "outbound|") {
+		// the upstream clusters of the sidecars of Istio are named after the direction of the traffic
+		kind = "client"
+	}
+	method := corev3.RequestMethod_name[int32(request.GetRequestMethod())]
+	statusCode := response.GetResponseCode().GetValue()
+	span.Tags = append(span.Tags,
+		model.String("span.kind", kind),
+		model.String("component", "proxy"),
+		model.String("http.method", method),
+		model.String("http.url", request.GetScheme()+"://"+request.GetAuthority()+request.GetPath()),
+		model.String("http.protocol", envoyProtocol(entry.GetProtocolVersion())),
+		model.Int64("http.status_code", int64(statusCode)),
+		model.String("response_flags", envoyResponseFlags(common.GetResponseFlags())),
+	)
+	if requestID != "" {
+		span.Tags = append(span.Tags, model.String("guid:x-request-id", requestID))
+	}
+	if cluster := common.GetUpstreamCluster(); cluster != "" {
+		span.Tags = append(span.Tags, model.String("upstream_cluster", cluster))
+	}
+	if userAgent := request.GetUserAgent(); userAgent != "" {
+		span.Tags = append(span.Tags, model.String("user_agent", userAgent))
+	}
+	if peer := envoySocketAddress(common.GetDownstreamRemoteAddress()); peer != "" {
+		span.Tags = append(span.Tags, model.String("peer.address", peer))
+	}
+	// like the Envoy tracers, the requests without a response or with a 5xx response are errors
+	if statusCode == 0 || statusCode >= 500 {
+		span.Tags = append(span.Tags, model.Bool("error", true))
+	}
+	return span
+}
+
+func envoyOperationName(entry *accesslogdatav3.HTTPAccessLogEntry) string {
+	if route := entry.GetCommonProperties().GetRouteName(); route != "" {
+		return route
+	}
+	path := entry.GetRequest().GetPath()
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return envoyUnknownOperation
+	}
+	return corev3.RequestMethod_name[int32(entry.GetRequest().GetRequestMethod())] + " " + path
+}
+
+func envoyDuration(common *accesslogdatav3.AccessLogCommon) time.Duration {
+	if d := common.GetDuration(); d != nil {
+		return d.AsDuration()
+	}
+	return common.GetTimeToLastDownstreamTxByte().AsDuration()
+}
+
+// envoySpanID derives the span ID from the request, so that the span of an access log sent
+// twice is deduplicated.
+func envoySpanID(traceID model.TraceID, process *model.Process, entry *accesslogdatav3.HTTPAccessLogEntry) model.SpanID {
+	h := fnv.New64a()
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], traceID.High)
+	binary.BigEndian.PutUint64(buf[8:], traceID.Low)
+	h.Write(buf[:])
+	h.Write([]byte(process.ServiceName))
+	h.Write([]byte(entry.GetCommonProperties().GetUpstreamCluster()))
+	h.Write([]byte(entry.GetCommonProperties().GetStreamId()))
+	binary.BigEndian.PutUint64(buf[:8], uint64(entry.GetCommonProperties().GetStartTime().AsTime().UnixNano()))
+	h.Write(buf[:8])
+	return model.NewSpanID(h.Sum64())
+}
+
+func envoySocketAddress(address *corev3.Address) string {
+	return address.GetSocketAddress().GetAddress()
+}
+
+func envoyProtocol(version accesslogdatav3.HTTPAccessLogEntry_HTTPVersion) string {
+	switch version {
+	case accesslogdatav3.HTTPAccessLogEntry_HTTP10:
+		return "HTTP/1.0"
+	case accesslogdatav3.HTTPAccessLogEntry_HTTP11:
+		return "HTTP/1.1"
+	case accesslogdatav3.HTTPAccessLogEntry_HTTP2:
+		return "HTTP/2"
+	case accesslogdatav3.HTTPAccessLogEntry_HTTP3:
+		return "HTTP/3"
+	default:
+		return "-"
+	}
+}
+
+// envoyResponseFlags returns the short names of the response flags, as in the Envoy access logs.
+func envoyResponseFlags(flags *accesslogdatav3.ResponseFlags) string {
+	var names []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{flags.GetFailedLocalHealthcheck(), "LH"},
+		{flags.GetNoHealthyUpstream(), "UH"},
+		{flags.GetUpstreamRequestTimeout(), "UT"},
+		{flags.GetLocalReset(), "LR"},
+		{flags.GetUpstreamRemoteReset(), "UR"},
+		{flags.GetUpstreamConnectionFailure(), "UF"},
+		{flags.GetUpstreamConnectionTermination(), "UC"},
+		{flags.GetUpstreamOverflow(), "UO"},
+		{flags.GetNoRouteFound(), "NR"},
+		{flags.GetDelayInjected(), "DI"},
+		{flags.GetFaultInjected(), "FI"},
+		{flags.GetRateLimited(), "RL"},
+		{flags.GetUnauthorizedDetails() != nil, "UAEX"},
+		{flags.GetRateLimitServiceError(), "RLSE"},
+		{flags.GetDownstreamConnectionTermination(), "DC"},
+		{flags.GetUpstreamRetryLimitExceeded(), "URX"},
+		{flags.GetStreamIdleTimeout(), "SI"},
+		{flags.GetInvalidEnvoyRequestHeaders(), "IH"},
+		{flags.GetDownstreamProtocolError(), "DPE"},
+		{flags.GetUpstreamMaxStreamDurationReached(), "UMSDR"},
+		{flags.GetResponseFromCacheFilter(), "RFCF"},
+		{flags.GetNoFilterConfigFound(), "NFCF"},
+		{flags.GetDurationTimeout(), "DT"},
+		{flags.GetUpstreamProtocolError(), "UPE"},
+		{flags.GetNoClusterFound(), "NC"},
+		{flags.GetOverloadManager(), "OM"},
+		{flags.GetDnsResolutionFailure(), "DF"},
+	} {
+		if flag.set {
+			names = append(names, flag.name)
+		}
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	accesslogdatav3 "github.com/envoyproxy/go-control-plane/envoy/data/accesslog/v3"
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/service/accesslog/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+var envoyStartTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func envoyHTTPEntry() *accesslogdatav3.HTTPAccessLogEntry {
+	return &accesslogdatav3.HTTPAccessLogEntry{
+		CommonProperties: &accesslogdatav3.AccessLogCommon{
+			StartTime:       timestamppb.New(envoyStartTime),
+			Duration:        durationpb.New(15 * time.Millisecond),
+			UpstreamCluster: "outbound|8080||reviews.default.svc.cluster.local",
+			StreamId:        "stream-1",
+			DownstreamRemoteAddress: &corev3.Address{Address: &corev3.Address_SocketAddress{
+				SocketAddress: &corev3.SocketAddress{Address: "10.0.0.7"},
+			}},
+		},
+		ProtocolVersion: accesslogdatav3.HTTPAccessLogEntry_HTTP11,
+		Request: &accesslogdatav3.HTTPRequestProperties{
+			RequestMethod: corev3.RequestMethod_GET,
+			Scheme:        "http",
+			Authority:     "reviews:8080",
+			Path:          "/reviews/1?lang=en",
+			UserAgent:     "curl/8.0",
+			RequestId:     "c2a5e0b7-6f1d-4b5e-9d4f-1d2b3c4d5e6f",
+		},
+		Response: &accesslogdatav3.HTTPResponseProperties{
+			ResponseCode: wrapperspb.UInt32(200),
+		},
+	}
+}
+
+func TestEnvoyEntryToSpan(t *testing.T) {
+	process := model.NewProcess("productpage", nil)
+	span := envoyEntryToSpan(envoyHTTPEntry(), process)
+
+	assert.Equal(t, "GET /reviews/1", span.OperationName)
+	assert.Equal(t, envoyStartTime, span.StartTime)
+	assert.Equal(t, 15*time.Millisecond, span.Duration)
+	assert.True(t, span.Flags.IsSampled())
+	assert.Same(t, process, span.Process)
+	assert.Empty(t, span.References)
+	assert.Equal(t, model.KeyValues{
+		model.String("span.kind", "client"),
+		model.String("component", "proxy"),
+		model.String("http.method", "GET"),
+		model.String("http.url", "http://reviews:8080/reviews/1?lang=en"),
+		model.String("http.protocol", "HTTP/1.1"),
+		model.Int64("http.status_code", 200),
+		model.String("response_flags", "-"),
+		model.String("guid:x-request-id", "c2a5e0b7-6f1d-4b5e-9d4f-1d2b3c4d5e6f"),
+		model.String("upstream_cluster", "outbound|8080||reviews.default.svc.cluster.local"),
+		model.String("user_agent", "curl/8.0"),
+		model.String("peer.address", "10.0.0.7"),
+	}, model.KeyValues(span.Tags))
+
+	// the proxies on the path of a request join the same trace with different spans
+	inbound := envoyHTTPEntry()
+	inbound.CommonProperties.UpstreamCluster = "inbound|8080||"
+	other := envoyEntryToSpan(inbound, model.NewProcess("reviews", nil))
+	assert.Equal(t, span.TraceID, other.TraceID)
+	assert.NotEqual(t, span.SpanID, other.SpanID)
+	kind, _ := model.KeyValues(other.Tags).FindByKey("span.kind")
+	assert.Equal(t, "server", kind.VStr)
+
+	// the same access log has the same span
+	assert.Equal(t, span.SpanID, envoyEntryToSpan(envoyHTTPEntry(), process).SpanID)
+}
+
+func TestEnvoyEntryToSpanTraceparent(t *testing.T) {
+	entry := envoyHTTPEntry()
+	entry.Request.RequestHeaders = map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	span := envoyEntryToSpan(entry, model.NewProcess("productpage", nil))
+
+	traceID := model.NewTraceID(0x4bf92f3577b34da6, 0xa3ce929d0e0e4736)
+	assert.Equal(t, traceID, span.TraceID)
+	assert.Equal(t, []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(0x00f067aa0ba902b7))}, span.References)
+}
+
+func TestEnvoyEntryToSpanWithoutRequestID(t *testing.T) {
+	entry := &accesslogdatav3.HTTPAccessLogEntry{
+		CommonProperties: &accesslogdatav3.AccessLogCommon{
+			StartTime:                  timestamppb.New(envoyStartTime),
+			TimeToLastDownstreamTxByte: durationpb.New(time.Second),
+			RouteName:                  "default",
+			ResponseFlags: &accesslogdatav3.ResponseFlags{
+				NoHealthyUpstream:   true,
+				UpstreamOverflow:    true,
+				UnauthorizedDetails: &accesslogdatav3.ResponseFlags_Unauthorized{},
+			},
+		},
+		Request: &accesslogdatav3.HTTPRequestProperties{RequestMethod: corev3.RequestMethod_POST},
+		Response: &accesslogdatav3.HTTPResponseProperties{
+			ResponseCode: wrapperspb.UInt32(503),
+		},
+	}
+	span := envoyEntryToSpan(entry, envoyProcess(nil))
+
+	assert.Equal(t, "default", span.OperationName)
+	assert.Equal(t, time.Second, span.Duration)
+	assert.NotEqual(t, model.TraceID{}, span.TraceID)
+	tags := model.KeyValues(span.Tags)
+	flags, _ := tags.FindByKey("response_flags")
+	assert.Equal(t, "UH,UO,UAEX", flags.VStr)
+	protocol, _ := tags.FindByKey("http.protocol")
+	assert.Equal(t, "-", protocol.VStr)
+	errorTag, ok := tags.FindByKey("error")
+	require.True(t, ok)
+	assert.True(t, errorTag.Bool())
+	_, ok = tags.FindByKey("guid:x-request-id")
+	assert.False(t, ok)
+}
+
+func TestEnvoyOperationName(t *testing.T) {
+	assert.Equal(t, envoyUnknownOperation, envoyOperationName(&accesslogdatav3.HTTPAccessLogEntry{}))
+	assert.Equal(t, "PUT /ratings", envoyOperationName(&accesslogdatav3.HTTPAccessLogEntry{
+		Request: &accesslogdatav3.HTTPRequestProperties{RequestMethod: corev3.RequestMethod_PUT, Path: "/ratings"},
+	}))
+}
+
+func TestEnvoyProcess(t *testing.T) {
+	assert.Equal(t, model.NewProcess("envoy", nil), envoyProcess(nil))
+	assert.Equal(t, model.NewProcess("sidecar~10.0.0.7", []model.KeyValue{
+		model.String("node_id", "sidecar~10.0.0.7"),
+	}), envoyProcess(&accesslogv3.StreamAccessLogsMessage_Identifier{
+		Node: &corev3.Node{Id: "sidecar~10.0.0.7"},
+	}))
+	assert.Equal(t, model.NewProcess("productpage.default", []model.KeyValue{
+		model.String("node_id", "sidecar~10.0.0.7"),
+		model.String("zone", "us-east-1a"),
+		model.String("envoy.log_name", "als"),
+	}), envoyProcess(&accesslogv3.StreamAccessLogsMessage_Identifier{
+		Node: &corev3.Node{
+			Id:       "sidecar~10.0.0.7",
+			Cluster:  "productpage.default",
+			Locality: &corev3.Locality{Zone: "us-east-1a"},
+		},
+		LogName: "als",
+	}))
+}
+
+func TestEnvoyALSHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedError error
+		rejected      int64
+	}{
+		{name: "processed"},
+		{name: "rejected", expectedError: errors.New("queue is full"), rejected: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mb := metricstest.NewFactory(0)
+			defer mb.Backend.Stop()
+			spanProcessor := &mockSpanProcessor{expectedError: test.expectedError}
+			server, addr := initializeGRPCTestServer(t, func(s *grpc.Server) {
+				accesslogv3.RegisterAccessLogServiceServer(s, NewEnvoyALSHandler(zap.NewNop(), spanProcessor, &tenancy.Manager{}, mb))
+			})
+			defer server.Stop()
+			conn, err := grpc.NewClient(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			stream, err := accesslogv3.NewAccessLogServiceClient(conn).StreamAccessLogs(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, stream.Send(&accesslogv3.StreamAccessLogsMessage{
+				Identifier: &accesslogv3.StreamAccessLogsMessage_Identifier{
+					Node: &corev3.Node{Cluster: "productpage"},
+				},
+				LogEntries: &accesslogv3.StreamAccessLogsMessage_HttpLogs{
+					HttpLogs: &accesslogv3.StreamAccessLogsMessage_HTTPAccessLogEntries{
+						LogEntry: []*accesslogdatav3.HTTPAccessLogEntry{envoyHTTPEntry()},
+					},
+				},
+			}))
+			require.NoError(t, stream.Send(&accesslogv3.StreamAccessLogsMessage{
+				LogEntries: &accesslogv3.StreamAccessLogsMessage_TcpLogs{
+					TcpLogs: &accesslogv3.StreamAccessLogsMessage_TCPAccessLogEntries{
+						LogEntry: []*accesslogdatav3.TCPAccessLogEntry{{}},
+					},
+				},
+			}))
+			require.NoError(t, stream.Send(&accesslogv3.StreamAccessLogsMessage{
+				LogEntries: &accesslogv3.StreamAccessLogsMessage_HttpLogs{
+					HttpLogs: &accesslogv3.StreamAccessLogsMessage_HTTPAccessLogEntries{
+						LogEntry: []*accesslogdatav3.HTTPAccessLogEntry{envoyHTTPEntry()},
+					},
+				},
+			}))
+			_, err = stream.CloseAndRecv()
+			require.NoError(t, err)
+
+			spans := spanProcessor.getSpans()
+			require.Len(t, spans, 2)
+			for _, span := range spans {
+				assert.Equal(t, "productpage", span.Process.ServiceName)
+			}
+			assert.Equal(t, processor.GRPCTransport, spanProcessor.getTransport())
+			assert.Equal(t, processor.EnvoyALSSpanFormat, spanProcessor.getSpanFormat())
+			mb.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "envoy_als.entries", Tags: map[string]string{"type": "http"}, Value: 2},
+				metricstest.ExpectedMetric{Name: "envoy_als.entries", Tags: map[string]string{"type": "tcp"}, Value: 1},
+				metricstest.ExpectedMetric{Name: "envoy_als.rejected_spans", Value: int(test.rejected)},
+			)
+		})
+	}
+}
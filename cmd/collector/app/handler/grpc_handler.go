@@ -90,6 +90,7 @@ func (c *batchConsumer) consume(ctx context.Context, batch *model.Batch) error {
 		InboundTransport: c.spanOptions.InboundTransport,
 		SpanFormat:       c.spanOptions.SpanFormat,
 		Tenant:           tenant,
+		ClientIP:         clientIP(ctx),
 	})
 	if err != nil {
 		if errors.Is(err, processor.ErrBusy) {
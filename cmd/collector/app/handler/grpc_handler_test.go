@@ -42,6 +42,7 @@ type mockSpanProcessor struct {
 	tenants       map[string]bool
 	transport     processor.InboundTransport
 	spanFormat    processor.SpanFormat
+	clientIP      string
 }
 
 func (p *mockSpanProcessor) ProcessSpans(spans []*model.Span, opts processor.SpansOptions) ([]bool, error) {
@@ -55,6 +56,7 @@ func (p *mockSpanProcessor) ProcessSpans(spans []*model.Span, opts processor.Spa
 	p.tenants[opts.Tenant] = true
 	p.transport = opts.InboundTransport
 	p.spanFormat = opts.SpanFormat
+	p.clientIP = opts.ClientIP
 	return oks, p.expectedError
 }
 
@@ -82,6 +84,12 @@ func (p *mockSpanProcessor) getSpanFormat() processor.SpanFormat {
 	return p.spanFormat
 }
 
+func (p *mockSpanProcessor) getClientIP() string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.clientIP
+}
+
 func (p *mockSpanProcessor) reset() {
 	p.mux.Lock()
 	defer p.mux.Unlock()
@@ -89,6 +97,7 @@ func (p *mockSpanProcessor) reset() {
 	p.tenants = nil
 	p.transport = ""
 	p.spanFormat = ""
+	p.clientIP = ""
 }
 
 func (*mockSpanProcessor) Close() error {
@@ -144,6 +153,7 @@ func TestPostSpans(t *testing.T) {
 		got := processor.getSpans()
 		require.Equal(t, len(test.batch.GetSpans()), len(got))
 		assert.Equal(t, test.expected, got)
+		assert.Equal(t, "127.0.0.1", processor.getClientIP())
 		processor.reset()
 	}
 }
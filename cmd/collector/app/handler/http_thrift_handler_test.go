@@ -39,15 +39,17 @@ import (
 var httpClient = &http.Client{Timeout: 2 * time.Second}
 
 type mockJaegerHandler struct {
-	err     error
-	mux     sync.Mutex
-	batches []*jaeger.Batch
+	err      error
+	mux      sync.Mutex
+	batches  []*jaeger.Batch
+	clientIP string
 }
 
-func (p *mockJaegerHandler) SubmitBatches(batches []*jaeger.Batch, _ SubmitBatchOptions) ([]*jaeger.BatchSubmitResponse, error) {
+func (p *mockJaegerHandler) SubmitBatches(batches []*jaeger.Batch, options SubmitBatchOptions) ([]*jaeger.BatchSubmitResponse, error) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 	p.batches = append(p.batches, batches...)
+	p.clientIP = options.ClientIP
 	return nil, p.err
 }
 
@@ -84,6 +86,7 @@ func TestThriftFormat(t *testing.T) {
 	require.NoError(t, err)
 	assert.EqualValues(t, http.StatusAccepted, statusCode)
 	assert.EqualValues(t, "", resBodyStr)
+	assert.Equal(t, "127.0.0.1", handler.jaegerBatchesHandler.(*mockJaegerHandler).clientIP)
 
 	statusCode, resBodyStr, err = postBytes("application/x-thrift; charset=utf-8", server.URL+`/api/traces`, someBytes)
 	require.NoError(t, err)
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// JSONSpanHandler handles the HTTP calls posting spans in the JSON schema of the jsonspans package.
+type JSONSpanHandler struct {
+	converter     *jsonspans.Converter
+	spanProcessor processor.SpanProcessor
+	tenancyMgr    *tenancy.Manager
+}
+
+// NewJSONSpanHandler returns a new JSONSpanHandler
+func NewJSONSpanHandler(converter *jsonspans.Converter, spanProcessor processor.SpanProcessor, tenancyMgr *tenancy.Manager) *JSONSpanHandler {
+	return &JSONSpanHandler{
+		converter:     converter,
+		spanProcessor: spanProcessor,
+		tenancyMgr:    tenancyMgr,
+	}
+}
+
+// RegisterRoutes registers routes for this handler on the given router
+func (h *JSONSpanHandler) RegisterRoutes(router *mux.Router) {
+	router.Handle("/api/json/spans", tenancy.ExtractTenantHTTPHandler(h.tenancyMgr, http.HandlerFunc(h.SaveSpans))).Methods(http.MethodPost)
+}
+
+// SaveSpans submits the spans provided in the request body to the span processor
+func (h *JSONSpanHandler) SaveSpans(w http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, fmt.Sprintf(UnableToReadBodyErrFormat, err), http.StatusInternalServerError)
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot parse content type: %v", err), http.StatusBadRequest)
+		return
+	}
+	if contentType != "application/json" {
+		http.Error(w, fmt.Sprintf("Unsupported content type: %v", html.EscapeString(contentType)), http.StatusBadRequest)
+		return
+	}
+
+	spans, err := h.converter.Convert(bodyBytes)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(UnableToReadBodyErrFormat, html.EscapeString(err.Error())), http.StatusBadRequest)
+		return
+	}
+	_, err = h.spanProcessor.ProcessSpans(spans, processor.SpansOptions{
+		SpanFormat:       processor.JSONSpanFormat,
+		InboundTransport: processor.HTTPTransport,
+		Tenant:           tenancy.GetTenant(r.Context()),
+		ClientIP:         addrIP(r.RemoteAddr),
+	})
+	if errors.Is(err, processor.ErrBusy) {
+		http.Error(w, fmt.Sprintf("Cannot submit the spans: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot submit the spans: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const jsonSpans = `{"spans": [{"trace_id": "1", "span_id": "2", "service": "api", "name": "read", "start_time": 1}]}`
+
+func initializeJSONSpanTestServer(t *testing.T, spanProcessor processor.SpanProcessor, tenancyMgr *tenancy.Manager) *httptest.Server {
+	converter, err := jsonspans.NewConverter(jsonspans.DefaultMapping())
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	NewJSONSpanHandler(converter, spanProcessor, tenancyMgr).RegisterRoutes(r)
+	return httptest.NewServer(r)
+}
+
+func TestJSONSpanHandler(t *testing.T) {
+	spanProcessor := &mockSpanProcessor{}
+	server := initializeJSONSpanTestServer(t, spanProcessor, &tenancy.Manager{})
+	defer server.Close()
+
+	statusCode, resBodyStr, err := postBytes("application/json; charset=utf-8", server.URL+"/api/json/spans", []byte(jsonSpans))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, statusCode)
+	assert.Empty(t, resBodyStr)
+	spans := spanProcessor.getSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "read", spans[0].OperationName)
+	assert.Equal(t, "api", spans[0].Process.ServiceName)
+	assert.Equal(t, processor.HTTPTransport, spanProcessor.getTransport())
+	assert.Equal(t, processor.JSONSpanFormat, spanProcessor.getSpanFormat())
+	assert.Equal(t, "127.0.0.1", spanProcessor.getClientIP())
+}
+
+func TestJSONSpanHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		expectedError error
+		statusCode    int
+		response      string
+	}{
+		{
+			name:        "bad content type",
+			contentType: "application/json; =",
+			body:        jsonSpans,
+			statusCode:  http.StatusBadRequest,
+			response:    "Cannot parse content type: mime: invalid media parameter\n",
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "application/x-thrift",
+			body:        jsonSpans,
+			statusCode:  http.StatusBadRequest,
+			response:    "Unsupported content type: application/x-thrift\n",
+		},
+		{
+			name:        "invalid spans",
+			contentType: "application/json",
+			body:        `[{}]`,
+			statusCode:  http.StatusBadRequest,
+			response:    "Unable to process request body: invalid span 0: missing field &#34;trace_id&#34;\n",
+		},
+		{
+			name:          "busy",
+			contentType:   "application/json",
+			body:          jsonSpans,
+			expectedError: processor.ErrBusy,
+			statusCode:    http.StatusServiceUnavailable,
+			response:      "Cannot submit the spans: server busy\n",
+		},
+		{
+			name:          "processing error",
+			contentType:   "application/json",
+			body:          jsonSpans,
+			expectedError: assert.AnError,
+			statusCode:    http.StatusInternalServerError,
+			response:      "Cannot submit the spans: " + assert.AnError.Error() + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := initializeJSONSpanTestServer(t, &mockSpanProcessor{expectedError: test.expectedError}, &tenancy.Manager{})
+			defer server.Close()
+
+			statusCode, resBodyStr, err := postBytes(test.contentType, server.URL+"/api/json/spans", []byte(test.body))
+			require.NoError(t, err)
+			assert.Equal(t, test.statusCode, statusCode)
+			assert.Equal(t, test.response, resBodyStr)
+		})
+	}
+}
+
+func TestJSONSpanHandlerTenancy(t *testing.T) {
+	spanProcessor := &mockSpanProcessor{}
+	tenancyMgr := tenancy.NewManager(&tenancy.Options{Enabled: true, Header: "x-tenant", Tenants: []string{"acme"}})
+	server := initializeJSONSpanTestServer(t, spanProcessor, tenancyMgr)
+	defer server.Close()
+
+	statusCode, resBodyStr, err := postBytes("application/json", server.URL+"/api/json/spans", []byte(jsonSpans))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+	assert.Equal(t, "missing tenant header", resBodyStr)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/json/spans", strings.NewReader(jsonSpans))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-tenant", "acme")
+	res, err := httpClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	assert.Equal(t, map[string]bool{"acme": true}, spanProcessor.getTenants())
+}
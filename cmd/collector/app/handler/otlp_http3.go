@@ -0,0 +1,152 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/http3server"
+	otlp2jaeger "github.com/jaegertracing/jaeger/pkg/otlptranslator"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const (
+	otlpTracesPath      = "/v1/traces"
+	otlpProtobufContent = "application/x-protobuf"
+	otlpJSONContent     = "application/json"
+)
+
+// StartOTLPHTTP3Server starts the HTTP/3 (QUIC) listener of the OTLP HTTP receiver on the UDP
+// port of --collector.otlp.http.http3-host-port, serving the /v1/traces endpoint of OTLP/HTTP
+// with the TLS configuration of the receiver. The OTLP receiver of OpenTelemetry only serves
+// HTTP/1 and HTTP/2, so the listener has its own handler of the export requests.
+func StartOTLPHTTP3Server(options *flags.CollectorOptions, logger *zap.Logger, spanProcessor processor.SpanProcessor, tm *tenancy.Manager) (*http3server.Server, error) {
+	opts := &options.OTLP.HTTP
+	if !opts.TLS.Enabled {
+		return nil, errors.New("the OTLP HTTP/3 server requires TLS")
+	}
+	tlsCfg, err := opts.TLS.Config(logger)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(otlpTracesPath, tenancy.ExtractTenantHTTPHandler(tm, &OTLPHTTPHandler{
+		logger:        logger,
+		spanProcessor: spanProcessor,
+	}))
+	server, err := http3server.Listen(opts.Network, opts.HTTP3HostPort, tlsCfg, mux)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on the OTLP HTTP/3 port: %w", err)
+	}
+	logger.Info("Starting OTLP HTTP/3 server", zap.Stringer("addr", server.Addr()))
+	go func() {
+		if err := server.Serve(); err != nil {
+			logger.Error("Could not serve OTLP HTTP/3", zap.Error(err))
+		}
+	}()
+	return server, nil
+}
+
+// OTLPHTTPHandler handles the OTLP/HTTP export requests of the traces, encoded in protobuf or JSON.
+type OTLPHTTPHandler struct {
+	logger        *zap.Logger
+	spanProcessor processor.SpanProcessor
+}
+
+func (h *OTLPHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != otlpProtobufContent && contentType != otlpJSONContent) {
+		http.Error(w, fmt.Sprintf("Unsupported content type: %v", html.EscapeString(r.Header.Get("Content-Type"))), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := readOTLPBody(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(UnableToReadBodyErrFormat, err), http.StatusBadRequest)
+		return
+	}
+
+	request := ptraceotlp.NewExportRequest()
+	if contentType == otlpJSONContent {
+		err = request.UnmarshalJSON(body)
+	} else {
+		err = request.UnmarshalProto(body)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf(UnableToReadBodyErrFormat, html.EscapeString(err.Error())), http.StatusBadRequest)
+		return
+	}
+	batches, err := otlp2jaeger.ProtoFromTraces(request.Traces())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot convert the spans: %v", html.EscapeString(err.Error())), http.StatusBadRequest)
+		return
+	}
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span.GetProcess() == nil {
+				span.Process = batch.Process
+			}
+		}
+		_, err = h.spanProcessor.ProcessSpans(batch.Spans, processor.SpansOptions{
+			SpanFormat:       processor.OTLPSpanFormat,
+			InboundTransport: processor.HTTPTransport,
+			Tenant:           tenancy.GetTenant(r.Context()),
+			ClientIP:         addrIP(r.RemoteAddr),
+		})
+		if errors.Is(err, processor.ErrBusy) {
+			http.Error(w, fmt.Sprintf("Cannot submit the spans: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			h.logger.Error("cannot process spans", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Cannot submit the spans: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var response []byte
+	if contentType == otlpJSONContent {
+		response, err = ptraceotlp.NewExportResponse().MarshalJSON()
+	} else {
+		response, err = ptraceotlp.NewExportResponse().MarshalProto()
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot encode the response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func readOTLPBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	switch r.Header.Get("Content-Encoding") {
+	case "":
+		return io.ReadAll(r.Body)
+	case "gzip":
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", r.Header.Get("Content-Encoding"))
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+func otlpRequestBody(t *testing.T, contentType string) []byte {
+	request := ptraceotlp.NewExportRequestFromTraces(makeTracesOneSpan())
+	var body []byte
+	var err error
+	if contentType == otlpJSONContent {
+		body, err = request.MarshalJSON()
+	} else {
+		body, err = request.MarshalProto()
+	}
+	require.NoError(t, err)
+	return body
+}
+
+func TestOTLPHTTPHandler(t *testing.T) {
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write(otlpRequestBody(t, otlpProtobufContent))
+	require.NoError(t, writer.Close())
+
+	tests := []struct {
+		name            string
+		contentType     string
+		contentEncoding string
+		body            []byte
+	}{
+		{name: "protobuf", contentType: otlpProtobufContent, body: otlpRequestBody(t, otlpProtobufContent)},
+		{name: "json", contentType: otlpJSONContent, body: otlpRequestBody(t, otlpJSONContent)},
+		{name: "gzip", contentType: otlpProtobufContent, contentEncoding: "gzip", body: gzipped.Bytes()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spanProcessor := &mockSpanProcessor{}
+			request := httptest.NewRequest(http.MethodPost, otlpTracesPath, bytes.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			request.Header.Set("Content-Encoding", test.contentEncoding)
+			recorder := httptest.NewRecorder()
+			(&OTLPHTTPHandler{logger: zap.NewNop(), spanProcessor: spanProcessor}).ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.contentType, recorder.Header().Get("Content-Type"))
+			spans := spanProcessor.getSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "test", spans[0].OperationName)
+			assert.NotNil(t, spans[0].Process)
+			assert.Equal(t, processor.HTTPTransport, spanProcessor.getTransport())
+			assert.Equal(t, processor.OTLPSpanFormat, spanProcessor.getSpanFormat())
+		})
+	}
+}
+
+func TestOTLPHTTPHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		contentType     string
+		contentEncoding string
+		body            []byte
+		expectedError   error
+		statusCode      int
+	}{
+		{name: "method", method: http.MethodGet, contentType: otlpProtobufContent, statusCode: http.StatusMethodNotAllowed},
+		{name: "content type", contentType: "application/x-thrift", statusCode: http.StatusUnsupportedMediaType},
+		{name: "content encoding", contentType: otlpProtobufContent, contentEncoding: "br", statusCode: http.StatusBadRequest},
+		{name: "bad gzip", contentType: otlpProtobufContent, contentEncoding: "gzip", body: []byte("oops"), statusCode: http.StatusBadRequest},
+		{name: "bad body", contentType: otlpJSONContent, body: []byte("{"), statusCode: http.StatusBadRequest},
+		{
+			name:          "busy",
+			contentType:   otlpProtobufContent,
+			body:          otlpRequestBody(t, otlpProtobufContent),
+			expectedError: processor.ErrBusy,
+			statusCode:    http.StatusServiceUnavailable,
+		},
+		{
+			name:          "processor error",
+			contentType:   otlpProtobufContent,
+			body:          otlpRequestBody(t, otlpProtobufContent),
+			expectedError: errors.New("oops"),
+			statusCode:    http.StatusInternalServerError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			request := httptest.NewRequest(method, otlpTracesPath, bytes.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			request.Header.Set("Content-Encoding", test.contentEncoding)
+			recorder := httptest.NewRecorder()
+			handler := &OTLPHTTPHandler{logger: zap.NewNop(), spanProcessor: &mockSpanProcessor{expectedError: test.expectedError}}
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, test.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestStartOTLPHTTP3Server(t *testing.T) {
+	const testCertKeyLocation = "../../../../pkg/config/tlscfg/testdata"
+	options := &flags.CollectorOptions{}
+	options.OTLP.HTTP.HTTP3HostPort = "127.0.0.1:0"
+	_, err := StartOTLPHTTP3Server(options, zap.NewNop(), &mockSpanProcessor{}, &tenancy.Manager{})
+	require.EqualError(t, err, "the OTLP HTTP/3 server requires TLS")
+
+	options.OTLP.HTTP.TLS = tlscfg.Options{
+		Enabled:  true,
+		CertPath: testCertKeyLocation + "/example-server-cert.pem",
+		KeyPath:  testCertKeyLocation + "/example-server-key.pem",
+	}
+	defer options.OTLP.HTTP.TLS.Close()
+	spanProcessor := &mockSpanProcessor{}
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true, Header: "x-tenant"})
+	server, err := StartOTLPHTTP3Server(options, zap.NewNop(), spanProcessor, tm)
+	require.NoError(t, err)
+	defer server.Close()
+
+	ca, err := os.ReadFile(testCertKeyLocation + "/example-CA-cert.pem")
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(ca))
+	roundTripper := &http3.RoundTripper{TLSClientConfig: &tls.Config{RootCAs: pool, ServerName: "example.com", MinVersion: tls.VersionTLS13}}
+	defer roundTripper.Close()
+	request, err := http.NewRequest(http.MethodPost, "https://"+server.Addr().String()+otlpTracesPath, bytes.NewReader(otlpRequestBody(t, otlpProtobufContent)))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", otlpProtobufContent)
+	request.Header.Set("x-tenant", "acme")
+	response, err := (&http.Client{Transport: roundTripper}).Do(request)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	require.Len(t, spanProcessor.getSpans(), 1)
+	assert.True(t, spanProcessor.tenants["acme"])
+}
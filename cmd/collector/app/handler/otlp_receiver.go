@@ -18,35 +18,36 @@ import (
 	"context"
 	"fmt"
 
-	otlp2jaeger "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
-	noopmetric "go.opentelemetry.io/otel/metric/noop"
-	nooptrace "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
+	otlp2jaeger "github.com/jaegertracing/jaeger/pkg/otlptranslator"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 )
 
 var _ component.Host = (*otelHost)(nil) // API check
 
 // StartOTLPReceiver starts OpenTelemetry OTLP receiver listening on gRPC and HTTP ports.
-func StartOTLPReceiver(options *flags.CollectorOptions, logger *zap.Logger, spanProcessor processor.SpanProcessor, tm *tenancy.Manager) (receiver.Traces, error) {
+func StartOTLPReceiver(options *flags.CollectorOptions, telset telemetery.Setting, spanProcessor processor.SpanProcessor, tm *tenancy.Manager) (receiver.Traces, error) {
 	otlpFactory := otlpreceiver.NewFactory()
 	return startOTLPReceiver(
 		options,
-		logger,
+		telset,
 		spanProcessor,
 		tm,
 		otlpFactory,
@@ -60,7 +61,7 @@ func StartOTLPReceiver(options *flags.CollectorOptions, logger *zap.Logger, span
 // function allows to mock those constructors.
 func startOTLPReceiver(
 	options *flags.CollectorOptions,
-	logger *zap.Logger,
+	telset telemetery.Setting,
 	spanProcessor processor.SpanProcessor,
 	tm *tenancy.Manager,
 	// from here: params that can be mocked in tests
@@ -72,18 +73,15 @@ func startOTLPReceiver(
 	otlpReceiverConfig := otlpFactory.CreateDefaultConfig().(*otlpreceiver.Config)
 	applyGRPCSettings(otlpReceiverConfig.GRPC, &options.OTLP.GRPC)
 	applyHTTPSettings(otlpReceiverConfig.HTTP.ServerConfig, &options.OTLP.HTTP)
+	logger := telset.Logger
 	statusReporter := func(ev *component.StatusEvent) {
 		// TODO this could be wired into changing healthcheck.HealthCheck
 		logger.Info("OTLP receiver status change", zap.Stringer("status", ev.Status()))
 	}
 	otlpReceiverSettings := receiver.Settings{
-		TelemetrySettings: component.TelemetrySettings{
-			Logger:         logger,
-			TracerProvider: nooptrace.NewTracerProvider(),
-			MeterProvider:  noopmetric.NewMeterProvider(), // TODO wire this with jaegerlib metrics?
-			ReportStatus:   statusReporter,
-		},
+		TelemetrySettings: telset.ToOtelComponent(),
 	}
+	otlpReceiverSettings.ReportStatus = statusReporter
 
 	otlpConsumer := newConsumerDelegate(logger, spanProcessor, tm)
 	// the following two constructors never return errors given non-nil arguments, so we ignore errors
@@ -110,6 +108,9 @@ func applyGRPCSettings(cfg *configgrpc.ServerConfig, opts *flags.GRPCOptions) {
 	if opts.HostPort != "" {
 		cfg.NetAddr.Endpoint = opts.HostPort
 	}
+	if opts.Network != "" {
+		cfg.NetAddr.Transport = confignet.TransportType(opts.Network)
+	}
 	if opts.TLS.Enabled {
 		cfg.TLSSetting = applyTLSSettings(&opts.TLS)
 	}
@@ -130,6 +131,9 @@ func applyHTTPSettings(cfg *confighttp.ServerConfig, opts *flags.HTTPOptions) {
 	if opts.HostPort != "" {
 		cfg.Endpoint = opts.HostPort
 	}
+	// the HTTP servers of the receivers always listen with the tcp network,
+	// the IP family is applied to the wildcard host
+	cfg.Endpoint = netutils.WildcardHostPort(opts.Network, cfg.Endpoint)
 	if opts.TLS.Enabled {
 		cfg.TLSSetting = applyTLSSettings(&opts.TLS)
 	}
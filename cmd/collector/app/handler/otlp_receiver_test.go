@@ -32,6 +32,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config/corscfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 )
@@ -49,9 +50,10 @@ func optionsWithPorts(port string) *flags.CollectorOptions {
 
 func TestStartOtlpReceiver(t *testing.T) {
 	spanProcessor := &mockSpanProcessor{}
-	logger, _ := testutils.NewLogger()
+	telset := telemetery.NoopSettings()
+	telset.Logger, _ = testutils.NewLogger()
 	tm := &tenancy.Manager{}
-	rec, err := StartOTLPReceiver(optionsWithPorts(":0"), logger, spanProcessor, tm)
+	rec, err := StartOTLPReceiver(optionsWithPorts(":0"), telset, spanProcessor, tm)
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, rec.Shutdown(context.Background()))
@@ -99,10 +101,11 @@ func TestConsumerDelegate(t *testing.T) {
 
 func TestStartOtlpReceiver_Error(t *testing.T) {
 	spanProcessor := &mockSpanProcessor{}
-	logger, _ := testutils.NewLogger()
+	telset := telemetery.NoopSettings()
+	telset.Logger, _ = testutils.NewLogger()
 	opts := optionsWithPorts(":-1")
 	tm := &tenancy.Manager{}
-	_, err := StartOTLPReceiver(opts, logger, spanProcessor, tm)
+	_, err := StartOTLPReceiver(opts, telset, spanProcessor, tm)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not start the OTLP receiver")
 
@@ -110,7 +113,7 @@ func TestStartOtlpReceiver_Error(t *testing.T) {
 		return nil, errors.New("mock error")
 	}
 	f := otlpreceiver.NewFactory()
-	_, err = startOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, f, newTraces, f.CreateTracesReceiver)
+	_, err = startOTLPReceiver(opts, telset, spanProcessor, &tenancy.Manager{}, f, newTraces, f.CreateTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create the OTLP consumer")
 
@@ -119,7 +122,7 @@ func TestStartOtlpReceiver_Error(t *testing.T) {
 	) (receiver.Traces, error) {
 		return nil, errors.New("mock error")
 	}
-	_, err = startOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, f, consumer.NewTraces, createTracesReceiver)
+	_, err = startOTLPReceiver(opts, telset, spanProcessor, &tenancy.Manager{}, f, consumer.NewTraces, createTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create the OTLP receiver")
 }
@@ -230,3 +233,14 @@ func TestApplyOTLPHTTPServerSettings(t *testing.T) {
 	assert.Equal(t, []string{"Content-Type", "Accept", "X-Requested-With"}, out.CORS.AllowedHeaders)
 	assert.Equal(t, []string{"http://example.domain.com", "http://*.domain.com"}, out.CORS.AllowedOrigins)
 }
+
+func TestApplyOTLPServerSettingsIPFamily(t *testing.T) {
+	otlpFactory := otlpreceiver.NewFactory()
+	otlpReceiverConfig := otlpFactory.CreateDefaultConfig().(*otlpreceiver.Config)
+
+	applyGRPCSettings(otlpReceiverConfig.GRPC, &flags.GRPCOptions{HostPort: ":4317", Network: "tcp6"})
+	assert.EqualValues(t, "tcp6", otlpReceiverConfig.GRPC.NetAddr.Transport)
+
+	applyHTTPSettings(otlpReceiverConfig.HTTP.ServerConfig, &flags.HTTPOptions{HostPort: ":4318", Network: "tcp4"})
+	assert.Equal(t, "0.0.0.0:4318", otlpReceiverConfig.HTTP.Endpoint)
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/thrift-gen/agent"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+type agentHandler struct {
+	jaegerBatchesHandler JaegerBatchesHandler
+	zipkinSpansHandler   ZipkinSpansHandler
+}
+
+// NewAgentHandler returns a handler of the Agent Thrift service, to which the legacy clients
+// send their spans over UDP, submitting the spans to the Jaeger and Zipkin handlers.
+func NewAgentHandler(jaegerBatchesHandler JaegerBatchesHandler, zipkinSpansHandler ZipkinSpansHandler) agent.Agent {
+	return &agentHandler{
+		jaegerBatchesHandler: jaegerBatchesHandler,
+		zipkinSpansHandler:   zipkinSpansHandler,
+	}
+}
+
+// EmitBatch implements agent.Agent.
+func (h *agentHandler) EmitBatch(_ context.Context, batch *jaeger.Batch) error {
+	_, err := h.jaegerBatchesHandler.SubmitBatches([]*jaeger.Batch{batch}, SubmitBatchOptions{InboundTransport: processor.UDPTransport})
+	return err
+}
+
+// EmitZipkinBatch implements agent.Agent.
+func (h *agentHandler) EmitZipkinBatch(_ context.Context, spans []*zipkincore.Span) error {
+	_, err := h.zipkinSpansHandler.SubmitZipkinBatch(spans, SubmitBatchOptions{InboundTransport: processor.UDPTransport})
+	return err
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	zipkinsanitizer "github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer/zipkin"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+type recordingProcessor struct {
+	shouldIErrorProcessor
+	options []processor.SpansOptions
+}
+
+func (p *recordingProcessor) ProcessSpans(mSpans []*model.Span, options processor.SpansOptions) ([]bool, error) {
+	p.options = append(p.options, options)
+	return p.shouldIErrorProcessor.ProcessSpans(mSpans, options)
+}
+
+func TestAgentHandler(t *testing.T) {
+	p := &recordingProcessor{}
+	h := NewAgentHandler(
+		NewJaegerSpanHandler(zap.NewNop(), p),
+		NewZipkinSpanHandler(zap.NewNop(), p, zipkinsanitizer.NewChainedSanitizer(zipkinsanitizer.NewStandardSanitizers()...)),
+	)
+	require.NoError(t, h.EmitBatch(context.Background(), &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "someServiceName"},
+		Spans:   []*jaeger.Span{{SpanId: 21345}},
+	}))
+	require.NoError(t, h.EmitZipkinBatch(context.Background(), []*zipkincore.Span{{ID: 12345}}))
+	assert.Equal(t, []processor.SpansOptions{
+		{InboundTransport: processor.UDPTransport, SpanFormat: processor.JaegerSpanFormat},
+		{InboundTransport: processor.UDPTransport, SpanFormat: processor.ZipkinSpanFormat},
+	}, p.options)
+
+	p.shouldError = true
+	require.Equal(t, errTestError, h.EmitBatch(context.Background(), &jaeger.Batch{Process: &jaeger.Process{}}))
+	require.Equal(t, errTestError, h.EmitZipkinBatch(context.Background(), []*zipkincore.Span{{ID: 12345}}))
+}
@@ -30,6 +30,8 @@ import (
 // SubmitBatchOptions are passed to Submit methods of the handlers.
 type SubmitBatchOptions struct {
 	InboundTransport processor.InboundTransport
+	// ClientIP is the IP of the client which sent the batches, empty if unknown
+	ClientIP string
 }
 
 // ZipkinSpansHandler consumes and handles zipkin spans
@@ -68,6 +70,7 @@ func (jbh *jaegerBatchesHandler) SubmitBatches(batches []*jaeger.Batch, options
 		oks, err := jbh.modelProcessor.ProcessSpans(mSpans, processor.SpansOptions{
 			InboundTransport: options.InboundTransport,
 			SpanFormat:       processor.JaegerSpanFormat,
+			ClientIP:         options.ClientIP,
 		})
 		if err != nil {
 			jbh.logger.Error("Collector failed to process span batch", zap.Error(err))
@@ -119,6 +122,7 @@ func (h *zipkinSpanHandler) SubmitZipkinBatch(spans []*zipkincore.Span, options
 	bools, err := h.modelProcessor.ProcessSpans(mSpans, processor.SpansOptions{
 		InboundTransport: options.InboundTransport,
 		SpanFormat:       processor.ZipkinSpanFormat,
+		ClientIP:         options.ClientIP,
 	})
 	if err != nil {
 		h.logger.Error("Collector failed to process Zipkin span batch", zap.Error(err))
@@ -11,26 +11,24 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
-	noopmetric "go.opentelemetry.io/otel/metric/noop"
-	nooptrace "go.opentelemetry.io/otel/trace/noop"
-	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 )
 
 // StartZipkinReceiver starts Zipkin receiver from OTEL Collector.
 func StartZipkinReceiver(
 	options *flags.CollectorOptions,
-	logger *zap.Logger,
+	telset telemetery.Setting,
 	spanProcessor processor.SpanProcessor,
 	tm *tenancy.Manager,
 ) (receiver.Traces, error) {
 	zipkinFactory := zipkinreceiver.NewFactory()
 	return startZipkinReceiver(
 		options,
-		logger,
+		telset,
 		spanProcessor,
 		tm,
 		zipkinFactory,
@@ -44,7 +42,7 @@ func StartZipkinReceiver(
 // function allows to mock those constructors.
 func startZipkinReceiver(
 	options *flags.CollectorOptions,
-	logger *zap.Logger,
+	telset telemetery.Setting,
 	spanProcessor processor.SpanProcessor,
 	tm *tenancy.Manager,
 	// from here: params that can be mocked in tests
@@ -56,17 +54,15 @@ func startZipkinReceiver(
 	receiverConfig := zipkinFactory.CreateDefaultConfig().(*zipkinreceiver.Config)
 	applyHTTPSettings(&receiverConfig.ServerConfig, &flags.HTTPOptions{
 		HostPort: options.Zipkin.HTTPHostPort,
+		Network:  options.Zipkin.HTTPNetwork,
 		TLS:      options.Zipkin.TLS,
 		CORS:     options.HTTP.CORS,
 		// TODO keepAlive not supported?
 	})
 	receiverSettings := receiver.Settings{
-		TelemetrySettings: component.TelemetrySettings{
-			Logger:         logger,
-			TracerProvider: nooptrace.NewTracerProvider(),
-			MeterProvider:  noopmetric.NewMeterProvider(), // TODO wire this with jaegerlib metrics?
-		},
+		TelemetrySettings: telset.ToOtelComponent(),
 	}
+	logger := telset.Logger
 
 	consumerAdapter := newConsumerDelegate(logger, spanProcessor, tm)
 	// reset Zipkin spanFormat
@@ -25,6 +25,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
 	zipkinthrift "github.com/jaegertracing/jaeger/model/converter/thrift/zipkin"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	zipkin_proto3 "github.com/jaegertracing/jaeger/proto-gen/zipkin"
@@ -33,13 +34,14 @@ import (
 
 func TestZipkinReceiver(t *testing.T) {
 	spanProcessor := &mockSpanProcessor{}
-	logger, _ := testutils.NewLogger()
+	telset := telemetery.NoopSettings()
+	telset.Logger, _ = testutils.NewLogger()
 	tm := &tenancy.Manager{}
 
 	opts := &flags.CollectorOptions{}
 	opts.Zipkin.HTTPHostPort = ":11911"
 
-	rec, err := StartZipkinReceiver(opts, logger, spanProcessor, tm)
+	rec, err := StartZipkinReceiver(opts, telset, spanProcessor, tm)
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, rec.Shutdown(context.Background()))
@@ -134,13 +136,14 @@ func TestZipkinReceiver(t *testing.T) {
 
 func TestStartZipkinReceiver_Error(t *testing.T) {
 	spanProcessor := &mockSpanProcessor{}
-	logger, _ := testutils.NewLogger()
+	telset := telemetery.NoopSettings()
+	telset.Logger, _ = testutils.NewLogger()
 	tm := &tenancy.Manager{}
 
 	opts := &flags.CollectorOptions{}
 	opts.Zipkin.HTTPHostPort = ":-1"
 
-	_, err := StartZipkinReceiver(opts, logger, spanProcessor, tm)
+	_, err := StartZipkinReceiver(opts, telset, spanProcessor, tm)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not start Zipkin receiver")
 
@@ -148,7 +151,7 @@ func TestStartZipkinReceiver_Error(t *testing.T) {
 		return nil, errors.New("mock error")
 	}
 	f := zipkinreceiver.NewFactory()
-	_, err = startZipkinReceiver(opts, logger, spanProcessor, tm, f, newTraces, f.CreateTracesReceiver)
+	_, err = startZipkinReceiver(opts, telset, spanProcessor, tm, f, newTraces, f.CreateTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create Zipkin consumer")
 
@@ -157,7 +160,7 @@ func TestStartZipkinReceiver_Error(t *testing.T) {
 	) (receiver.Traces, error) {
 		return nil, errors.New("mock error")
 	}
-	_, err = startZipkinReceiver(opts, logger, spanProcessor, tm, f, consumer.NewTraces, createTracesReceiver)
+	_, err = startZipkinReceiver(opts, telset, spanProcessor, tm, f, consumer.NewTraces, createTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create Zipkin receiver")
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/ports"
@@ -170,7 +171,8 @@ func TestSpanCollectorZipkinTLS(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
 			spanProcessor := &mockSpanProcessor{}
-			logger, _ := testutils.NewLogger()
+			telset := telemetery.NoopSettings()
+			telset.Logger, _ = testutils.NewLogger()
 			tm := &tenancy.Manager{}
 
 			opts := &flags.CollectorOptions{}
@@ -178,7 +180,7 @@ func TestSpanCollectorZipkinTLS(t *testing.T) {
 			opts.Zipkin.TLS = test.serverTLS
 			defer test.serverTLS.Close()
 
-			server, err := StartZipkinReceiver(opts, logger, spanProcessor, tm)
+			server, err := StartZipkinReceiver(opts, telset, spanProcessor, tm)
 			if test.expectServerFail {
 				require.Error(t, err)
 				return
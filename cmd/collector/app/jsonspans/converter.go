@@ -0,0 +1,457 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonspans converts the spans submitted in a simple JSON schema, e.g. by profilers
+// or eBPF agents which do not implement OTLP or Thrift, to Jaeger spans.
+//
+// The spans are posted to the collector HTTP endpoint /api/json/spans, in the body:
+//
+//	{
+//	  "spans": [
+//	    {
+//	      "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+//	      "span_id": "00f067aa0ba902b7",
+//	      "parent_span_id": "53995c3f42cd8ad8",
+//	      "service": "checkout",
+//	      "name": "SELECT orders",
+//	      "start_time": 1714557600000000000,
+//	      "duration": 1500000,
+//	      "kind": "client",
+//	      "error": false,
+//	      "attributes": {"db.system": "postgresql", "db.rows": 3},
+//	      "resource": {"host.name": "node-1", "process.pid": 4242}
+//	    }
+//	  ]
+//	}
+//
+// The trace_id, span_id, service and name are required. The IDs are hexadecimal strings or
+// unsigned integers. The start_time and duration are numbers in time_unit, nanoseconds by
+// default, or the start_time an RFC 3339 string; the duration may be replaced by an end_time.
+// The attributes become the tags of the span and the resource the tags of its process, the
+// nested values being encoded in JSON. The body may also be the array of the spans.
+//
+// Agents with an existing schema may keep it with a mapping file replacing the paths of the
+// fields, the nested fields being separated by dots, e.g.
+//
+//	{"trace_id": "ctx.trace", "service": "process.comm", "duration": "latency", "time_unit": "us"}
+//
+// while the omitted fields keep the paths of the schema.
+package jsonspans
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Options configures the ingestion of the spans in the JSON schema.
+type Options struct {
+	// Enabled serves the HTTP endpoint accepting the spans
+	Enabled bool
+	// MappingFile is the path of the JSON file replacing the paths of the fields of the schema
+	MappingFile string
+}
+
+// Mapping is the path of the fields of the spans in the submitted JSON, the nested fields being
+// separated by dots.
+type Mapping struct {
+	// Spans is the path of the array of the spans in the body, which is the array itself if empty
+	Spans        string `json:"spans"`
+	TraceID      string `json:"trace_id"`
+	SpanID       string `json:"span_id"`
+	ParentSpanID string `json:"parent_span_id"`
+	Service      string `json:"service"`
+	Operation    string `json:"name"`
+	StartTime    string `json:"start_time"`
+	Duration     string `json:"duration"`
+	EndTime      string `json:"end_time"`
+	Kind         string `json:"kind"`
+	Error        string `json:"error"`
+	Tags         string `json:"attributes"`
+	ProcessTags  string `json:"resource"`
+	// TimeUnit is the unit of the numeric times and durations: ns, us, ms or s
+	TimeUnit string `json:"time_unit"`
+}
+
+// DefaultMapping returns the mapping of the documented schema.
+func DefaultMapping() Mapping {
+	return Mapping{
+		Spans:        "spans",
+		TraceID:      "trace_id",
+		SpanID:       "span_id",
+		ParentSpanID: "parent_span_id",
+		Service:      "service",
+		Operation:    "name",
+		StartTime:    "start_time",
+		Duration:     "duration",
+		EndTime:      "end_time",
+		Kind:         "kind",
+		Error:        "error",
+		Tags:         "attributes",
+		ProcessTags:  "resource",
+		TimeUnit:     "ns",
+	}
+}
+
+// LoadMapping returns the default mapping with the paths of the mapping file.
+func LoadMapping(path string) (Mapping, error) {
+	mapping := DefaultMapping()
+	bytes, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return mapping, fmt.Errorf("failed to read the mapping file: %w", err)
+	}
+	if err := json.Unmarshal(bytes, &mapping); err != nil {
+		return mapping, fmt.Errorf("failed to parse the mapping file: %w", err)
+	}
+	return mapping, nil
+}
+
+var timeUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// Converter converts the submitted JSON to spans according to a mapping.
+type Converter struct {
+	timeUnit     time.Duration
+	spans        []string
+	traceID      []string
+	spanID       []string
+	parentSpanID []string
+	service      []string
+	operation    []string
+	startTime    []string
+	duration     []string
+	endTime      []string
+	kind         []string
+	error        []string
+	tags         []string
+	processTags  []string
+}
+
+// NewConverter creates a Converter for the mapping.
+func NewConverter(mapping Mapping) (*Converter, error) {
+	timeUnit, ok := timeUnits[mapping.TimeUnit]
+	if !ok {
+		return nil, fmt.Errorf("invalid time unit %q, expected ns, us, ms or s", mapping.TimeUnit)
+	}
+	for field, path := range map[string]string{
+		"trace_id":   mapping.TraceID,
+		"span_id":    mapping.SpanID,
+		"service":    mapping.Service,
+		"name":       mapping.Operation,
+		"start_time": mapping.StartTime,
+	} {
+		if path == "" {
+			return nil, fmt.Errorf("the path of the required field %s is empty", field)
+		}
+	}
+	return &Converter{
+		timeUnit:     timeUnit,
+		spans:        splitPath(mapping.Spans),
+		traceID:      splitPath(mapping.TraceID),
+		spanID:       splitPath(mapping.SpanID),
+		parentSpanID: splitPath(mapping.ParentSpanID),
+		service:      splitPath(mapping.Service),
+		operation:    splitPath(mapping.Operation),
+		startTime:    splitPath(mapping.StartTime),
+		duration:     splitPath(mapping.Duration),
+		endTime:      splitPath(mapping.EndTime),
+		kind:         splitPath(mapping.Kind),
+		error:        splitPath(mapping.Error),
+		tags:         splitPath(mapping.Tags),
+		processTags:  splitPath(mapping.ProcessTags),
+	}, nil
+}
+
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// Convert returns the spans of the body.
+func (c *Converter) Convert(body []byte) ([]*model.Span, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var root any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse the spans: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("failed to parse the spans: unexpected data after the JSON value")
+	}
+	items, ok := root.([]any)
+	if !ok {
+		value, found := lookup(root, c.spans)
+		if items, ok = value.([]any); !found || !ok {
+			return nil, fmt.Errorf("the body is neither an array of spans nor has an array of spans at %q", strings.Join(c.spans, "."))
+		}
+	}
+	spans := make([]*model.Span, 0, len(items))
+	for i, item := range items {
+		span, err := c.convertSpan(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid span %d: %w", i, err)
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+func (c *Converter) convertSpan(item any) (*model.Span, error) {
+	if _, ok := item.(map[string]any); !ok {
+		return nil, errors.New("the span is not an object")
+	}
+	traceID, err := c.traceIDField(item)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := c.spanIDField(item, c.spanID, true)
+	if err != nil {
+		return nil, err
+	}
+	parentSpanID, err := c.spanIDField(item, c.parentSpanID, false)
+	if err != nil {
+		return nil, err
+	}
+	service, err := c.stringField(item, c.service, true)
+	if err != nil {
+		return nil, err
+	}
+	operation, err := c.stringField(item, c.operation, true)
+	if err != nil {
+		return nil, err
+	}
+	startTime, err := c.timeField(item, c.startTime, true)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := c.durationField(item, startTime)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := c.tagsField(item, c.tags)
+	if err != nil {
+		return nil, err
+	}
+	processTags, err := c.tagsField(item, c.processTags)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := c.stringField(item, c.kind, false)
+	if err != nil {
+		return nil, err
+	}
+	if kind != "" {
+		tags = append(tags, model.String("span.kind", strings.ToLower(kind)))
+	}
+	if value, found := lookup(item, c.error); found {
+		isError, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("the field %q is not a boolean", strings.Join(c.error, "."))
+		}
+		if isError {
+			tags = append(tags, model.Bool("error", true))
+		}
+	}
+
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: operation,
+		StartTime:     startTime,
+		Duration:      duration,
+		Tags:          tags,
+		Process:       model.NewProcess(service, processTags),
+	}
+	if parentSpanID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, parentSpanID)}
+	}
+	return span, nil
+}
+
+// lookup returns the value at path in value, and whether it is present.
+func lookup(value any, path []string) (any, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+func (*Converter) stringField(item any, path []string, required bool) (string, error) {
+	value, found := lookup(item, path)
+	if !found {
+		if required {
+			return "", fmt.Errorf("missing field %q", strings.Join(path, "."))
+		}
+		return "", nil
+	}
+	s, ok := value.(string)
+	if !ok || (required && s == "") {
+		return "", fmt.Errorf("the field %q is not a non-empty string", strings.Join(path, "."))
+	}
+	return s, nil
+}
+
+func (c *Converter) traceIDField(item any) (model.TraceID, error) {
+	value, found := lookup(item, c.traceID)
+	if !found {
+		return model.TraceID{}, fmt.Errorf("missing field %q", strings.Join(c.traceID, "."))
+	}
+	var traceID model.TraceID
+	var err error
+	switch v := value.(type) {
+	case string:
+		traceID, err = model.TraceIDFromString(v)
+	case json.Number:
+		var low uint64
+		low, err = strconv.ParseUint(v.String(), 10, 64)
+		traceID = model.NewTraceID(0, low)
+	default:
+		err = errors.New("not a string or a number")
+	}
+	if err == nil && traceID == (model.TraceID{}) {
+		err = errors.New("the trace ID is zero")
+	}
+	if err != nil {
+		return model.TraceID{}, fmt.Errorf("invalid trace ID in %q: %w", strings.Join(c.traceID, "."), err)
+	}
+	return traceID, nil
+}
+
+func (*Converter) spanIDField(item any, path []string, required bool) (model.SpanID, error) {
+	value, found := lookup(item, path)
+	if !found {
+		if required {
+			return 0, fmt.Errorf("missing field %q", strings.Join(path, "."))
+		}
+		return 0, nil
+	}
+	var spanID model.SpanID
+	var err error
+	switch v := value.(type) {
+	case string:
+		if v == "" && !required {
+			return 0, nil
+		}
+		spanID, err = model.SpanIDFromString(v)
+	case json.Number:
+		var id uint64
+		id, err = strconv.ParseUint(v.String(), 10, 64)
+		spanID = model.NewSpanID(id)
+	default:
+		err = errors.New("not a string or a number")
+	}
+	if err == nil && required && spanID == 0 {
+		err = errors.New("the span ID is zero")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid span ID in %q: %w", strings.Join(path, "."), err)
+	}
+	return spanID, nil
+}
+
+func (c *Converter) timeField(item any, path []string, required bool) (time.Time, error) {
+	value, found := lookup(item, path)
+	if !found {
+		if required {
+			return time.Time{}, fmt.Errorf("missing field %q", strings.Join(path, "."))
+		}
+		return time.Time{}, nil
+	}
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time in %q: %w", strings.Join(path, "."), err)
+		}
+		return t, nil
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time in %q: %w", strings.Join(path, "."), err)
+		}
+		return time.Unix(0, n*int64(c.timeUnit)), nil
+	default:
+		return time.Time{}, fmt.Errorf("the field %q is not a number or a string", strings.Join(path, "."))
+	}
+}
+
+func (c *Converter) durationField(item any, startTime time.Time) (time.Duration, error) {
+	if value, found := lookup(item, c.duration); found {
+		n, ok := value.(json.Number)
+		if !ok {
+			return 0, fmt.Errorf("the field %q is not a number", strings.Join(c.duration, "."))
+		}
+		d, err := n.Float64()
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid duration in %q: %s", strings.Join(c.duration, "."), n)
+		}
+		return time.Duration(d * float64(c.timeUnit)), nil
+	}
+	endTime, err := c.timeField(item, c.endTime, false)
+	if err != nil || endTime.IsZero() {
+		return 0, err
+	}
+	if endTime.Before(startTime) {
+		return 0, fmt.Errorf("the field %q is before the start time", strings.Join(c.endTime, "."))
+	}
+	return endTime.Sub(startTime), nil
+}
+
+func (*Converter) tagsField(item any, path []string) ([]model.KeyValue, error) {
+	value, found := lookup(item, path)
+	if !found {
+		return nil, nil
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the field %q is not an object", strings.Join(path, "."))
+	}
+	tags := make([]model.KeyValue, 0, len(object))
+	for key, value := range object {
+		tags = append(tags, tag(key, value))
+	}
+	model.KeyValues(tags).Sort()
+	return tags, nil
+}
+
+func tag(key string, value any) model.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return model.String(key, v)
+	case bool:
+		return model.Bool(key, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return model.Int64(key, i)
+		}
+		f, _ := v.Float64()
+		return model.Float64(key, f)
+	default:
+		js, _ := json.Marshal(v)
+		return model.String(key, string(js))
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonspans
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const documentedSpans = `{
+  "spans": [
+    {
+      "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+      "span_id": "00f067aa0ba902b7",
+      "parent_span_id": "53995c3f42cd8ad8",
+      "service": "checkout",
+      "name": "SELECT orders",
+      "start_time": 1714557600000000000,
+      "duration": 1500000,
+      "kind": "CLIENT",
+      "error": true,
+      "attributes": {"db.system": "postgresql", "db.rows": 3, "db.ratio": 0.5, "db.cached": false, "db.args": ["a", 1]},
+      "resource": {"host.name": "node-1", "process.pid": 4242}
+    }
+  ]
+}`
+
+func newDefaultConverter(t *testing.T) *Converter {
+	converter, err := NewConverter(DefaultMapping())
+	require.NoError(t, err)
+	return converter
+}
+
+func TestConvertDocumentedSchema(t *testing.T) {
+	spans, err := newDefaultConverter(t).Convert([]byte(documentedSpans))
+	require.NoError(t, err)
+
+	traceID := model.NewTraceID(0x4bf92f3577b34da6, 0xa3ce929d0e0e4736)
+	assert.Equal(t, []*model.Span{{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(0x00f067aa0ba902b7),
+		OperationName: "SELECT orders",
+		References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(0x53995c3f42cd8ad8))},
+		StartTime:     time.Unix(0, 1714557600000000000),
+		Duration:      1500 * time.Microsecond,
+		Tags: []model.KeyValue{
+			model.String("db.args", `["a",1]`),
+			model.Bool("db.cached", false),
+			model.Float64("db.ratio", 0.5),
+			model.Int64("db.rows", 3),
+			model.String("db.system", "postgresql"),
+			model.String("span.kind", "client"),
+			model.Bool("error", true),
+		},
+		Process: model.NewProcess("checkout", []model.KeyValue{
+			model.String("host.name", "node-1"),
+			model.Int64("process.pid", 4242),
+		}),
+	}}, spans)
+}
+
+func TestConvertArray(t *testing.T) {
+	spans, err := newDefaultConverter(t).Convert([]byte(`[
+		{"trace_id": 42, "span_id": 7, "parent_span_id": "", "service": "api", "name": "read",
+		 "start_time": "2024-05-01T10:00:00.5Z", "end_time": "2024-05-01T10:00:01Z"},
+		{"trace_id": "2a", "span_id": "8", "service": "api", "name": "write", "start_time": 0}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, spans, 2)
+	assert.Equal(t, model.NewTraceID(0, 42), spans[0].TraceID)
+	assert.Equal(t, model.NewSpanID(7), spans[0].SpanID)
+	assert.Empty(t, spans[0].References)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC), spans[0].StartTime)
+	assert.Equal(t, 500*time.Millisecond, spans[0].Duration)
+	assert.Empty(t, spans[0].Tags)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID)
+	assert.Equal(t, time.Duration(0), spans[1].Duration)
+}
+
+func TestConvertMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"spans": "events",
+		"trace_id": "ctx.trace",
+		"span_id": "ctx.span",
+		"parent_span_id": "ctx.parent",
+		"service": "process.comm",
+		"name": "fn",
+		"start_time": "ts",
+		"duration": "latency",
+		"attributes": "labels",
+		"time_unit": "us"
+	}`), 0o600))
+	mapping, err := LoadMapping(path)
+	require.NoError(t, err)
+	assert.Equal(t, "resource", mapping.ProcessTags)
+	converter, err := NewConverter(mapping)
+	require.NoError(t, err)
+
+	spans, err := converter.Convert([]byte(`{"events": [{
+		"ctx": {"trace": "1", "span": "2", "parent": "3"},
+		"process": {"comm": "nginx"},
+		"fn": "tcp_sendmsg",
+		"ts": 1714557600000000,
+		"latency": 12.5,
+		"labels": {"bytes": 512}
+	}]}`))
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, model.NewTraceID(0, 1), span.TraceID)
+	assert.Equal(t, model.NewSpanID(2), span.SpanID)
+	assert.Equal(t, model.NewSpanID(3), span.ParentSpanID())
+	assert.Equal(t, "nginx", span.Process.ServiceName)
+	assert.Equal(t, "tcp_sendmsg", span.OperationName)
+	assert.Equal(t, time.Unix(0, 1714557600000000000), span.StartTime)
+	assert.Equal(t, 12500*time.Nanosecond, span.Duration)
+	assert.Equal(t, []model.KeyValue{model.Int64("bytes", 512)}, span.Tags)
+}
+
+func TestLoadMappingErrors(t *testing.T) {
+	_, err := LoadMapping(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "failed to read the mapping file")
+
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"trace_id": 1}`), 0o600))
+	_, err = LoadMapping(path)
+	require.ErrorContains(t, err, "failed to parse the mapping file")
+}
+
+func TestNewConverterErrors(t *testing.T) {
+	mapping := DefaultMapping()
+	mapping.TimeUnit = "h"
+	_, err := NewConverter(mapping)
+	require.EqualError(t, err, `invalid time unit "h", expected ns, us, ms or s`)
+
+	mapping = DefaultMapping()
+	mapping.Service = ""
+	_, err = NewConverter(mapping)
+	require.EqualError(t, err, "the path of the required field service is empty")
+}
+
+func TestConvertErrors(t *testing.T) {
+	const valid = `"trace_id": "1", "span_id": "2", "service": "api", "name": "read", "start_time": 1`
+	tests := []struct {
+		body string
+		err  string
+	}{
+		{body: `{`, err: "failed to parse the spans: unexpected EOF"},
+		{body: `[] []`, err: "failed to parse the spans: unexpected data after the JSON value"},
+		{body: `{"events": []}`, err: `the body is neither an array of spans nor has an array of spans at "spans"`},
+		{body: `[1]`, err: "invalid span 0: the span is not an object"},
+		{body: `[{"span_id": "2"}]`, err: `invalid span 0: missing field "trace_id"`},
+		{body: `[{"trace_id": "0", "span_id": "2"}]`, err: `invalid span 0: invalid trace ID in "trace_id": the trace ID is zero`},
+		{body: `[{"trace_id": "xyz"}]`, err: `invalid span 0: invalid trace ID in "trace_id"`},
+		{body: `[{"trace_id": true}]`, err: `invalid span 0: invalid trace ID in "trace_id": not a string or a number`},
+		{body: `[{"trace_id": "1"}]`, err: `invalid span 0: missing field "span_id"`},
+		{body: `[{"trace_id": "1", "span_id": 0}]`, err: `invalid span 0: invalid span ID in "span_id": the span ID is zero`},
+		{body: `[{"trace_id": "1", "span_id": -1}]`, err: `invalid span 0: invalid span ID in "span_id"`},
+		{body: `[{"trace_id": "1", "span_id": "2", "parent_span_id": {}}]`, err: `invalid span 0: invalid span ID in "parent_span_id": not a string or a number`},
+		{body: `[{"trace_id": "1", "span_id": "2"}]`, err: `invalid span 0: missing field "service"`},
+		{body: `[{"trace_id": "1", "span_id": "2", "service": ""}]`, err: `invalid span 0: the field "service" is not a non-empty string`},
+		{body: `[{"trace_id": "1", "span_id": "2", "service": "api", "name": 1}]`, err: `invalid span 0: the field "name" is not a non-empty string`},
+		{body: `[{"trace_id": "1", "span_id": "2", "service": "api", "name": "read"}]`, err: `invalid span 0: missing field "start_time"`},
+		{body: `[{"trace_id": "1", "span_id": "2", "service": "api", "name": "read", "start_time": "yesterday"}]`, err: `invalid span 0: invalid time in "start_time"`},
+		{body: `[{"trace_id": "1", "span_id": "2", "service": "api", "name": "read", "start_time": 1.5}]`, err: `invalid span 0: invalid time in "start_time"`},
+		{body: `[{"trace_id": "1", "span_id": "2", "service": "api", "name": "read", "start_time": true}]`, err: `invalid span 0: the field "start_time" is not a number or a string`},
+		{body: `[{` + valid + `, "duration": "1s"}]`, err: `invalid span 0: the field "duration" is not a number`},
+		{body: `[{` + valid + `, "duration": -1}]`, err: `invalid span 0: invalid duration in "duration": -1`},
+		{body: `[{` + valid + `, "end_time": 0}]`, err: `invalid span 0: the field "end_time" is before the start time`},
+		{body: `[{` + valid + `, "attributes": []}]`, err: `invalid span 0: the field "attributes" is not an object`},
+		{body: `[{` + valid + `, "resource": "node-1"}]`, err: `invalid span 0: the field "resource" is not an object`},
+		{body: `[{` + valid + `, "kind": 1}]`, err: `invalid span 0: the field "kind" is not a non-empty string`},
+		{body: `[{` + valid + `, "error": "yes"}]`, err: `invalid span 0: the field "error" is not a boolean`},
+		{body: `[{` + valid + `}, {}]`, err: `invalid span 1: missing field "trace_id"`},
+	}
+	converter := newDefaultConverter(t)
+	for _, test := range tests {
+		t.Run(test.body, func(t *testing.T) {
+			_, err := converter.Convert([]byte(test.body))
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
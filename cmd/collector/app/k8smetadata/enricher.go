@@ -0,0 +1,334 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package k8smetadata adds the Kubernetes metadata of the pods reporting the spans to their
+// process tags, so that the traces can be searched by namespace, deployment, node or pod
+// labels without changing the instrumentation of the services.
+//
+// The pod of a span is found by its IP: the k8s.pod.ip process tag set by the OpenTelemetry
+// SDKs, else the ip process tag set by the Jaeger clients, else the address of the client
+// which sent the spans to the collector. The pods are watched with an informer, so that the
+// lookups do not call the Kubernetes API.
+package k8smetadata
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_k8s_metadata_spans_total",
+			Type: telemetery.Counter,
+			Help: "Spans whose process tags were enriched with the metadata of their Kubernetes pod, or whose pod was not found",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"enriched", "unresolved"}},
+			},
+		},
+	)
+}
+
+// The tags follow the OpenTelemetry semantic conventions, like the k8sattributes processor.
+const (
+	tagPodIP          = "k8s.pod.ip"
+	tagClientIP       = "ip"
+	tagNamespace      = "k8s.namespace.name"
+	tagPodName        = "k8s.pod.name"
+	tagDeployment     = "k8s.deployment.name"
+	tagNode           = "k8s.node.name"
+	tagPodLabelPrefix = "k8s.pod.labels."
+
+	// podTemplateHashLabel is the label set by the deployments on their replica sets and pods
+	podTemplateHashLabel = "pod-template-hash"
+
+	podIPIndex = "podIP"
+)
+
+// Options configures the enrichment of the spans with the Kubernetes metadata.
+type Options struct {
+	// Enabled enables the enrichment
+	Enabled bool
+	// Kubeconfig is the path of the kubeconfig file, the in-cluster configuration being used if empty
+	Kubeconfig string
+	// Namespace restricts the watched pods to a namespace, all the namespaces being watched if empty
+	Namespace string
+	// Labels are the pod labels added as tags, all of them if empty
+	Labels []string
+	// SyncTimeout bounds the time waiting for the pods to be listed when starting
+	SyncTimeout time.Duration
+}
+
+type enricherMetrics struct {
+	// Enriched counts the spans enriched with the metadata of their pod
+	Enriched metrics.Counter `metric:"k8s-metadata.spans" tags:"result=enriched"`
+	// Unresolved counts the spans whose pod was not found
+	Unresolved metrics.Counter `metric:"k8s-metadata.spans" tags:"result=unresolved"`
+}
+
+// Enricher adds the metadata of the pods reporting the spans to their process tags.
+type Enricher struct {
+	labels    map[string]struct{}
+	factory   informers.SharedInformerFactory
+	indexer   cache.Indexer
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	logger    *zap.Logger
+	metrics   enricherMetrics
+}
+
+// NewEnricher connects to the Kubernetes API, watches the pods and waits for them to be listed.
+func NewEnricher(options Options, metricsFactory metrics.Factory, logger *zap.Logger) (*Enricher, error) {
+	client, err := newClient(options.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	// the Kubernetes client logs the failures to watch the pods with klog
+	klog.SetLogger(zapr.NewLogger(logger))
+	return newEnricher(client, options, metricsFactory, logger)
+}
+
+// newClient returns a client of the Kubernetes API configured by the kubeconfig file at path,
+// or by the service account of the pod if path is empty.
+func newClient(path string) (kubernetes.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the Kubernetes configuration: %w", err)
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+func newEnricher(client kubernetes.Interface, options Options, metricsFactory metrics.Factory, logger *zap.Logger) (*Enricher, error) {
+	e := &Enricher{
+		factory: informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(options.Namespace)),
+		stopCh:  make(chan struct{}),
+		logger:  logger,
+	}
+	metrics.MustInit(&e.metrics, metricsFactory, nil)
+	if len(options.Labels) > 0 {
+		e.labels = make(map[string]struct{}, len(options.Labels))
+		for _, label := range options.Labels {
+			e.labels[label] = struct{}{}
+		}
+	}
+
+	informer := e.factory.Core().V1().Pods().Informer()
+	if err := informer.SetTransform(e.stripPod); err != nil {
+		return nil, err
+	}
+	if err := informer.AddIndexers(cache.Indexers{podIPIndex: indexPodIPs}); err != nil {
+		return nil, err
+	}
+	e.indexer = informer.GetIndexer()
+	e.factory.Start(e.stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.SyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		_ = e.Close()
+		return nil, fmt.Errorf("failed to list the pods within %v, check the permissions of the collector to list and watch the pods", options.SyncTimeout)
+	}
+	logger.Info("Watching the Kubernetes pods to enrich the spans", zap.Int("pods", len(e.indexer.ListKeys())))
+	return e, nil
+}
+
+// stripPod keeps the fields of the pods used to enrich the spans, to save memory.
+func (e *Enricher) stripPod(obj any) (any, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		// e.g. cache.DeletedFinalStateUnknown
+		return obj, nil
+	}
+	stripped := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              pod.Name,
+			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
+			CreationTimestamp: pod.CreationTimestamp,
+			OwnerReferences:   pod.OwnerReferences,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:    pod.Spec.NodeName,
+			HostNetwork: pod.Spec.HostNetwork,
+		},
+		Status: corev1.PodStatus{
+			Phase:  pod.Status.Phase,
+			PodIP:  pod.Status.PodIP,
+			PodIPs: pod.Status.PodIPs,
+		},
+	}
+	stripped.Labels = make(map[string]string, len(pod.Labels))
+	for k, v := range pod.Labels {
+		// the pod template hash is needed to find the deployment of the pod
+		if e.addsLabel(k) || k == podTemplateHashLabel {
+			stripped.Labels[k] = v
+		}
+	}
+	return stripped, nil
+}
+
+// addsLabel returns whether the pod label is added as a tag.
+func (e *Enricher) addsLabel(label string) bool {
+	if e.labels == nil {
+		return true
+	}
+	_, ok := e.labels[label]
+	return ok
+}
+
+// indexPodIPs indexes the pods by IP. The pods on the host network share the IP of their node,
+// so they cannot be told apart by IP.
+func indexPodIPs(obj any) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.HostNetwork {
+		return nil, nil
+	}
+	ips := make([]string, 0, len(pod.Status.PodIPs)+1)
+	if pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	for _, ip := range pod.Status.PodIPs {
+		if ip.IP != pod.Status.PodIP {
+			ips = append(ips, ip.IP)
+		}
+	}
+	return ips, nil
+}
+
+// Enrich adds the metadata of the pods to the process tags of the spans received from the
+// client at clientIP. The tags already set by the services are kept.
+func (e *Enricher) Enrich(spans []*model.Span, clientIP string) {
+	// the spans of a batch usually share their process
+	enriched := make(map[*model.Process]bool)
+	for _, span := range spans {
+		if span.Process == nil {
+			continue
+		}
+		ok, seen := enriched[span.Process]
+		if !seen {
+			ok = e.enrichProcess(span.Process, clientIP)
+			enriched[span.Process] = ok
+		}
+		if ok {
+			e.metrics.Enriched.Inc(1)
+		} else {
+			e.metrics.Unresolved.Inc(1)
+		}
+	}
+}
+
+func (e *Enricher) enrichProcess(process *model.Process, clientIP string) bool {
+	pod := e.findPod(podIP(process, clientIP))
+	if pod == nil {
+		return false
+	}
+	tags := []model.KeyValue{
+		model.String(tagNamespace, pod.Namespace),
+		model.String(tagPodName, pod.Name),
+	}
+	if deployment := deploymentName(pod); deployment != "" {
+		tags = append(tags, model.String(tagDeployment, deployment))
+	}
+	if pod.Spec.NodeName != "" {
+		tags = append(tags, model.String(tagNode, pod.Spec.NodeName))
+	}
+	labels := make([]model.KeyValue, 0, len(pod.Labels))
+	for k, v := range pod.Labels {
+		if e.addsLabel(k) {
+			labels = append(labels, model.String(tagPodLabelPrefix+k, v))
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Key < labels[j].Key })
+	process.Tags = appendMissingTags(process.Tags, append(tags, labels...))
+	return true
+}
+
+// podIP returns the IP of the pod reporting the process.
+func podIP(process *model.Process, clientIP string) string {
+	for _, key := range []string{tagPodIP, tagClientIP} {
+		if tag, ok := model.KeyValues(process.Tags).FindByKey(key); ok {
+			return tag.AsString()
+		}
+	}
+	return clientIP
+}
+
+// findPod returns the pod with the ip, preferring the running pods over the completed ones,
+// which keep their IP until they are deleted.
+func (e *Enricher) findPod(ip string) *corev1.Pod {
+	if net.ParseIP(ip) == nil {
+		return nil
+	}
+	objs, err := e.indexer.ByIndex(podIPIndex, ip)
+	if err != nil {
+		e.logger.Debug("Failed to look up the pod", zap.String("ip", ip), zap.Error(err))
+		return nil
+	}
+	var found *corev1.Pod
+	for _, obj := range objs {
+		pod := obj.(*corev1.Pod)
+		if found == nil || completed(found) && !completed(pod) ||
+			completed(found) == completed(pod) && found.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			found = pod
+		}
+	}
+	return found
+}
+
+func completed(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// deploymentName returns the name of the deployment of the pod, which is the name of the replica
+// set owning the pod without the hash of the pod template, or "" if the pod has no deployment.
+func deploymentName(pod *corev1.Pod) string {
+	hash := pod.Labels[podTemplateHashLabel]
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "ReplicaSet" && hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return ""
+}
+
+func appendMissingTags(tags []model.KeyValue, added []model.KeyValue) []model.KeyValue {
+	existing := make(map[string]struct{}, len(tags))
+	for i := range tags {
+		existing[tags[i].Key] = struct{}{}
+	}
+	for _, tag := range added {
+		if _, ok := existing[tag.Key]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Close stops watching the pods.
+func (e *Enricher) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.stopCh)
+		e.factory.Shutdown()
+	})
+	return nil
+}
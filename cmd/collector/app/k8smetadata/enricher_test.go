@@ -0,0 +1,197 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package k8smetadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+type podOption func(pod *corev1.Pod)
+
+func newPod(name string, ip string, options ...podOption) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "shop",
+			Labels:    map[string]string{"app": "checkout", "pod-template-hash": "7d4b9c"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "checkout-7d4b9c"},
+			},
+			CreationTimestamp: metav1.NewTime(time.Unix(1000, 0)),
+		},
+		Spec: corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase:  corev1.PodRunning,
+			PodIP:  ip,
+			PodIPs: []corev1.PodIP{{IP: ip}},
+		},
+	}
+	for _, option := range options {
+		option(pod)
+	}
+	return pod
+}
+
+func newTestEnricher(t *testing.T, options Options, metricsFactory metrics.Factory, pods ...runtime.Object) (*Enricher, *fake.Clientset) {
+	client := fake.NewSimpleClientset(pods...)
+	options.SyncTimeout = 5 * time.Second
+	e, err := newEnricher(client, options, metricsFactory, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, e.Close()) })
+	return e, client
+}
+
+func checkoutTags(extra ...model.KeyValue) []model.KeyValue {
+	return append(extra,
+		model.String("k8s.namespace.name", "shop"),
+		model.String("k8s.pod.name", "checkout-1"),
+		model.String("k8s.deployment.name", "checkout"),
+		model.String("k8s.node.name", "node-1"),
+		model.String("k8s.pod.labels.app", "checkout"),
+		model.String("k8s.pod.labels.pod-template-hash", "7d4b9c"),
+	)
+}
+
+func TestEnricher(t *testing.T) {
+	e, _ := newTestEnricher(t, Options{}, metrics.NullFactory,
+		newPod("checkout-1", "10.0.0.1"),
+		newPod("completed", "10.0.0.1", func(pod *corev1.Pod) {
+			pod.Status.Phase = corev1.PodSucceeded
+			pod.CreationTimestamp = metav1.NewTime(time.Unix(2000, 0))
+		}),
+		newPod("host-network", "10.0.0.2", func(pod *corev1.Pod) { pod.Spec.HostNetwork = true }),
+	)
+	tests := []struct {
+		name     string
+		tags     []model.KeyValue
+		clientIP string
+		expected []model.KeyValue
+	}{
+		{
+			name:     "client IP",
+			clientIP: "10.0.0.1",
+			expected: checkoutTags(),
+		},
+		{
+			name:     "ip tag",
+			tags:     []model.KeyValue{model.String("ip", "10.0.0.1")},
+			clientIP: "10.0.0.9",
+			expected: checkoutTags(model.String("ip", "10.0.0.1")),
+		},
+		{
+			name:     "k8s.pod.ip tag",
+			tags:     []model.KeyValue{model.String("ip", "10.0.0.9"), model.String("k8s.pod.ip", "10.0.0.1")},
+			expected: checkoutTags(model.String("ip", "10.0.0.9"), model.String("k8s.pod.ip", "10.0.0.1")),
+		},
+		{
+			name:     "existing tags kept",
+			tags:     []model.KeyValue{model.String("k8s.namespace.name", "custom")},
+			clientIP: "10.0.0.1",
+			expected: append([]model.KeyValue{model.String("k8s.namespace.name", "custom")}, checkoutTags()[1:]...),
+		},
+		{
+			name:     "host network",
+			clientIP: "10.0.0.2",
+		},
+		{
+			name:     "unknown IP",
+			clientIP: "10.0.0.3",
+		},
+		{
+			name: "no IP",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			process := model.NewProcess("checkout", test.tags)
+			e.Enrich([]*model.Span{{Process: process}, {Process: process}, {}}, test.clientIP)
+			if test.expected == nil {
+				test.expected = test.tags
+			}
+			assert.Equal(t, test.expected, process.Tags)
+		})
+	}
+}
+
+func TestEnricherLabels(t *testing.T) {
+	e, _ := newTestEnricher(t, Options{Labels: []string{"team"}}, metrics.NullFactory,
+		newPod("checkout-1", "10.0.0.1", func(pod *corev1.Pod) { pod.Labels["team"] = "payments" }),
+		newPod("standalone", "10.0.0.2", func(pod *corev1.Pod) {
+			pod.Labels = nil
+			pod.OwnerReferences = nil
+			pod.Spec.NodeName = ""
+		}),
+	)
+	process := model.NewProcess("checkout", nil)
+	e.Enrich([]*model.Span{{Process: process}}, "10.0.0.1")
+	assert.Equal(t, []model.KeyValue{
+		model.String("k8s.namespace.name", "shop"),
+		model.String("k8s.pod.name", "checkout-1"),
+		model.String("k8s.deployment.name", "checkout"),
+		model.String("k8s.node.name", "node-1"),
+		model.String("k8s.pod.labels.team", "payments"),
+	}, process.Tags)
+
+	process = model.NewProcess("standalone", nil)
+	e.Enrich([]*model.Span{{Process: process}}, "10.0.0.2")
+	assert.Equal(t, []model.KeyValue{
+		model.String("k8s.namespace.name", "shop"),
+		model.String("k8s.pod.name", "standalone"),
+	}, process.Tags)
+}
+
+func TestEnricherWatchesPods(t *testing.T) {
+	e, client := newTestEnricher(t, Options{}, metrics.NullFactory)
+	_, err := client.CoreV1().Pods("shop").Create(context.Background(), newPod("checkout-1", "10.0.0.1"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		process := model.NewProcess("checkout", nil)
+		e.Enrich([]*model.Span{{Process: process}}, "10.0.0.1")
+		return len(process.Tags) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestEnricherMetrics(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	e, _ := newTestEnricher(t, Options{}, mb, newPod("checkout-1", "10.0.0.1"))
+	process := model.NewProcess("checkout", nil)
+	e.Enrich([]*model.Span{{Process: process}, {Process: process}}, "10.0.0.1")
+	e.Enrich([]*model.Span{{Process: model.NewProcess("unknown", nil)}}, "10.0.0.2")
+
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "k8s-metadata.spans", Tags: map[string]string{"result": "enriched"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "k8s-metadata.spans", Tags: map[string]string{"result": "unresolved"}, Value: 1},
+	)
+}
+
+func TestNewEnricherSyncTimeout(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("pods is forbidden")
+	})
+	_, err := newEnricher(client, Options{SyncTimeout: 100 * time.Millisecond}, metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "failed to list the pods within 100ms")
+}
+
+func TestNewEnricherInvalidKubeconfig(t *testing.T) {
+	_, err := NewEnricher(Options{Kubeconfig: "/does/not/exist"}, metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "failed to load the Kubernetes configuration")
+}
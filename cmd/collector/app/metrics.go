@@ -122,10 +122,12 @@ type SpanCounts struct {
 // NewSpanProcessorMetrics returns a SpanProcessorMetrics
 func NewSpanProcessorMetrics(serviceMetrics metrics.Factory, hostMetrics metrics.Factory, otherFormatTypes []processor.SpanFormat) *SpanProcessorMetrics {
 	spanCounts := SpanCountsByFormat{
-		processor.ZipkinSpanFormat:  newCountsByTransport(serviceMetrics, processor.ZipkinSpanFormat),
-		processor.JaegerSpanFormat:  newCountsByTransport(serviceMetrics, processor.JaegerSpanFormat),
-		processor.ProtoSpanFormat:   newCountsByTransport(serviceMetrics, processor.ProtoSpanFormat),
-		processor.UnknownSpanFormat: newCountsByTransport(serviceMetrics, processor.UnknownSpanFormat),
+		processor.ZipkinSpanFormat:   newCountsByTransport(serviceMetrics, processor.ZipkinSpanFormat),
+		processor.JaegerSpanFormat:   newCountsByTransport(serviceMetrics, processor.JaegerSpanFormat),
+		processor.ProtoSpanFormat:    newCountsByTransport(serviceMetrics, processor.ProtoSpanFormat),
+		processor.EnvoyALSSpanFormat: newCountsByTransport(serviceMetrics, processor.EnvoyALSSpanFormat),
+		processor.JSONSpanFormat:     newCountsByTransport(serviceMetrics, processor.JSONSpanFormat),
+		processor.UnknownSpanFormat:  newCountsByTransport(serviceMetrics, processor.UnknownSpanFormat),
 	}
 	for _, otherFormatType := range otherFormatTypes {
 		spanCounts[otherFormatType] = newCountsByTransport(serviceMetrics, otherFormatType)
@@ -210,6 +212,7 @@ func newCountsByTransport(factory metrics.Factory, format processor.SpanFormat)
 	return SpanCountsByTransport{
 		processor.HTTPTransport:    newCounts(factory, processor.HTTPTransport),
 		processor.GRPCTransport:    newCounts(factory, processor.GRPCTransport),
+		processor.UDPTransport:     newCounts(factory, processor.UDPTransport),
 		processor.UnknownTransport: newCounts(factory, processor.UnknownTransport),
 	}
 }
@@ -28,6 +28,12 @@ type ProcessSpans func(spans []*model.Span, tenant string)
 // FilterSpan decides whether to allow or disallow a span
 type FilterSpan func(span *model.Span) bool
 
+// EnrichSpans adds data to a batch of Domain Model Spans received from the client at clientIP
+type EnrichSpans func(spans []*model.Span, clientIP string)
+
+// TransformSpan returns the span to save in place of a span, or nil to drop it
+type TransformSpan func(span *model.Span) *model.Span
+
 // ChainedProcessSpan chains spanProcessors as a single ProcessSpan call
 func ChainedProcessSpan(spanProcessors ...ProcessSpan) ProcessSpan {
 	return func(span *model.Span, tenant string) {
@@ -36,3 +42,15 @@ func ChainedProcessSpan(spanProcessors ...ProcessSpan) ProcessSpan {
 		}
 	}
 }
+
+// ChainedTransformSpan chains transformers as a single TransformSpan call, stopping at the first one dropping the span
+func ChainedTransformSpan(transformers ...TransformSpan) TransformSpan {
+	return func(span *model.Span) *model.Span {
+		for _, transform := range transformers {
+			if span = transform(span); span == nil {
+				return nil
+			}
+		}
+		return span
+	}
+}
@@ -33,3 +33,21 @@ func TestChainedProcessSpan(t *testing.T) {
 	assert.True(t, happened1)
 	assert.True(t, happened2)
 }
+
+func TestChainedTransformSpan(t *testing.T) {
+	calls := 0
+	rename := func(span *model.Span) *model.Span {
+		calls++
+		return &model.Span{OperationName: span.OperationName + "-renamed"}
+	}
+	drop := func(*model.Span) *model.Span {
+		calls++
+		return nil
+	}
+	assert.Equal(t, &model.Span{OperationName: "op-renamed-renamed"}, ChainedTransformSpan(rename, rename)(&model.Span{OperationName: "op"}))
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	assert.Nil(t, ChainedTransformSpan(drop, rename)(&model.Span{}))
+	assert.Equal(t, 1, calls)
+}
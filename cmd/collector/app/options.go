@@ -16,6 +16,8 @@
 package app
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
@@ -33,6 +35,8 @@ type options struct {
 	sanitizer              sanitizer.SanitizeSpan
 	preSave                ProcessSpan
 	spanFilter             FilterSpan
+	transformSpan          TransformSpan
+	enrichSpans            EnrichSpans
 	numWorkers             int
 	blockingSubmit         bool
 	queueSize              int
@@ -43,6 +47,12 @@ type options struct {
 	collectorTags          map[string]string
 	spanSizeMetricsEnabled bool
 	onDroppedSpan          func(span *model.Span)
+	onFailedSpan           func(span *model.Span, tenant string)
+	batchSize              int
+	batchFlushInterval     time.Duration
+	workersAutoscaling     flags.WorkersAutoscaling
+	spanLimits             flags.SpanLimits
+	dedupeCacheSize        int
 }
 
 // Option is a function that sets some option on StorageBuilder.
@@ -102,6 +112,22 @@ func (options) SpanFilter(spanFilter FilterSpan) Option {
 	}
 }
 
+// TransformSpan creates an Option that initializes the transformSpan function, called by the
+// workers after the sanitizers. The spans it drops are not saved.
+func (options) TransformSpan(transformSpan TransformSpan) Option {
+	return func(b *options) {
+		b.transformSpan = transformSpan
+	}
+}
+
+// EnrichSpans creates an Option that initializes the enrichSpans function, called on the
+// received batches after the span limits are applied and before the collector tags are added.
+func (options) EnrichSpans(enrichSpans EnrichSpans) Option {
+	return func(b *options) {
+		b.enrichSpans = enrichSpans
+	}
+}
+
 // NumWorkers creates an Option that initializes the number of queue consumers AKA workers
 func (options) NumWorkers(numWorkers int) Option {
 	return func(b *options) {
@@ -172,6 +198,49 @@ func (options) OnDroppedSpan(onDroppedSpan func(span *model.Span)) Option {
 	}
 }
 
+// OnFailedSpan creates an Option that initializes the onFailedSpan function, called with the spans
+// which failed to be written to the storage
+func (options) OnFailedSpan(onFailedSpan func(span *model.Span, tenant string)) Option {
+	return func(b *options) {
+		b.onFailedSpan = onFailedSpan
+	}
+}
+
+// WorkersAutoscaling creates an Option that initializes the autoscaling of the number of workers of the span processor
+func (options) WorkersAutoscaling(workersAutoscaling flags.WorkersAutoscaling) Option {
+	return func(b *options) {
+		b.workersAutoscaling = workersAutoscaling
+	}
+}
+
+// BatchSize creates an Option that initializes the number of spans written at once to a spanstore.BatchWriter
+func (options) BatchSize(batchSize int) Option {
+	return func(b *options) {
+		b.batchSize = batchSize
+	}
+}
+
+// BatchFlushInterval creates an Option that initializes how long spans wait for a batch to be full
+func (options) BatchFlushInterval(batchFlushInterval time.Duration) Option {
+	return func(b *options) {
+		b.batchFlushInterval = batchFlushInterval
+	}
+}
+
+// SpanLimits creates an Option that initializes the size limits of the spans
+func (options) SpanLimits(spanLimits flags.SpanLimits) Option {
+	return func(b *options) {
+		b.spanLimits = spanLimits
+	}
+}
+
+// DedupeCacheSize creates an Option that initializes the number of recent spans remembered to drop their duplicates
+func (options) DedupeCacheSize(dedupeCacheSize int) Option {
+	return func(b *options) {
+		b.dedupeCacheSize = dedupeCacheSize
+	}
+}
+
 func (options) apply(opts ...Option) options {
 	ret := options{}
 	for _, opt := range opts {
@@ -198,8 +267,17 @@ func (options) apply(opts ...Option) options {
 	if ret.spanFilter == nil {
 		ret.spanFilter = func(_ *model.Span) bool { return true }
 	}
+	if ret.transformSpan == nil {
+		ret.transformSpan = func(span *model.Span) *model.Span { return span }
+	}
+	if ret.enrichSpans == nil {
+		ret.enrichSpans = func(_ []*model.Span, _ /* clientIP */ string) {}
+	}
 	if ret.numWorkers == 0 {
 		ret.numWorkers = flags.DefaultNumWorkers
 	}
+	if ret.batchFlushInterval == 0 {
+		ret.batchFlushInterval = flags.DefaultBatchFlushInterval
+	}
 	return ret
 }
@@ -40,6 +40,8 @@ func TestAllOptionSet(t *testing.T) {
 		Options.NumWorkers(5),
 		Options.PreProcessSpans(func(_ []*model.Span, _ /* tenant */ string) {}),
 		Options.Sanitizer(func(span *model.Span) *model.Span { return span }),
+		Options.TransformSpan(func(_ *model.Span) *model.Span { return nil }),
+		Options.EnrichSpans(func(_ []*model.Span, _ /* clientIP */ string) {}),
 		Options.QueueSize(10),
 		Options.DynQueueSizeWarmup(1000),
 		Options.DynQueueSizeMemory(1024),
@@ -47,6 +49,10 @@ func TestAllOptionSet(t *testing.T) {
 		Options.CollectorTags(map[string]string{"extra": "tags"}),
 		Options.SpanSizeMetricsEnabled(true),
 		Options.OnDroppedSpan(func(_ *model.Span) {}),
+		Options.OnFailedSpan(func(_ *model.Span, _ /* tenant */ string) {}),
+		Options.WorkersAutoscaling(flags.WorkersAutoscaling{Enabled: true, MaxWorkers: 10}),
+		Options.SpanLimits(flags.SpanLimits{MaxTags: 10}),
+		Options.DedupeCacheSize(100),
 	)
 	assert.EqualValues(t, 5, opts.numWorkers)
 	assert.EqualValues(t, 10, opts.queueSize)
@@ -55,6 +61,12 @@ func TestAllOptionSet(t *testing.T) {
 	assert.EqualValues(t, 1024, opts.dynQueueSizeMemory)
 	assert.True(t, opts.spanSizeMetricsEnabled)
 	assert.NotNil(t, opts.onDroppedSpan)
+	assert.NotNil(t, opts.onFailedSpan)
+	assert.Equal(t, flags.WorkersAutoscaling{Enabled: true, MaxWorkers: 10}, opts.workersAutoscaling)
+	assert.Equal(t, flags.SpanLimits{MaxTags: 10}, opts.spanLimits)
+	assert.Equal(t, 100, opts.dedupeCacheSize)
+	assert.Nil(t, opts.transformSpan(&model.Span{}))
+	assert.NotNil(t, opts.enrichSpans)
 }
 
 func TestNoOptionsSet(t *testing.T) {
@@ -66,10 +78,13 @@ func TestNoOptionsSet(t *testing.T) {
 	assert.False(t, opts.blockingSubmit)
 	assert.NotPanics(t, func() { opts.preProcessSpans(nil, "") })
 	assert.NotPanics(t, func() { opts.preSave(nil, "") })
+	assert.NotPanics(t, func() { opts.enrichSpans(nil, "") })
 	assert.True(t, opts.spanFilter(nil))
 	span := model.Span{}
 	assert.EqualValues(t, &span, opts.sanitizer(&span))
+	assert.EqualValues(t, &span, opts.transformSpan(&span))
 	assert.EqualValues(t, 0, opts.dynQueueSizeWarmup)
 	assert.False(t, opts.spanSizeMetricsEnabled)
 	assert.Nil(t, opts.onDroppedSpan)
+	assert.Nil(t, opts.onFailedSpan)
 }
@@ -29,6 +29,8 @@ type SpansOptions struct {
 	SpanFormat       SpanFormat
 	InboundTransport InboundTransport
 	Tenant           string
+	// ClientIP is the IP of the client which sent the spans, empty if unknown
+	ClientIP string
 }
 
 // SpanProcessor handles model spans
@@ -46,6 +48,8 @@ const (
 	GRPCTransport InboundTransport = "grpc"
 	// HTTPTransport indicates spans received over HTTP.
 	HTTPTransport InboundTransport = "http"
+	// UDPTransport indicates spans received in Thrift over UDP, like by jaeger-agent.
+	UDPTransport InboundTransport = "udp"
 	// UnknownTransport is the fallback/catch-all category.
 	UnknownTransport InboundTransport = "unknown"
 )
@@ -62,6 +66,10 @@ const (
 	ProtoSpanFormat SpanFormat = "proto"
 	// OTLPSpanFormat is for OpenTelemetry OTLP format.
 	OTLPSpanFormat SpanFormat = "otlp"
+	// EnvoyALSSpanFormat is for the spans converted from the Envoy access logs.
+	EnvoyALSSpanFormat SpanFormat = "envoy-als"
+	// JSONSpanFormat is for the spans in the JSON schema of the jsonspans package.
+	JSONSpanFormat SpanFormat = "json"
 	// UnknownSpanFormat is the fallback/catch-all category.
 	UnknownSpanFormat SpanFormat = "unknown"
 )
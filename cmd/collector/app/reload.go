@@ -0,0 +1,66 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+)
+
+// reloadableOptions are the collector options which can be changed without restarting.
+type reloadableOptions struct {
+	queueSize  int
+	numWorkers int
+	tenants    []string
+}
+
+func newReloadableOptions(options *flags.CollectorOptions) reloadableOptions {
+	return reloadableOptions{
+		queueSize:  options.QueueSize,
+		numWorkers: options.NumWorkers,
+		tenants:    options.GRPC.Tenancy.Tenants,
+	}
+}
+
+// Reload applies the options which can be changed without restarting the collector,
+// i.e. the queue size, the number of workers and the list of the valid tenants,
+// and logs the changes. The other options are ignored.
+func (c *Collector) Reload(options *flags.CollectorOptions) error {
+	if options.QueueSize <= 0 || options.NumWorkers <= 0 {
+		return fmt.Errorf("invalid queue size %d or number of workers %d, they must be positive", options.QueueSize, options.NumWorkers)
+	}
+	next := newReloadableOptions(options)
+
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	previous := c.reloadable
+	if next.queueSize != previous.queueSize && options.DynQueueSizeMemory > 0 {
+		c.logger.Warn("Ignoring the reloaded queue size, the queue is sized dynamically", zap.Int("queue-size", next.queueSize))
+		next.queueSize = previous.queueSize
+	}
+	if next.queueSize != previous.queueSize || next.numWorkers != previous.numWorkers {
+		c.spanProcessor.(*spanProcessor).resize(next.queueSize, next.numWorkers)
+		logReloaded(c.logger, "queue-size", previous.queueSize, next.queueSize)
+		logReloaded(c.logger, "num-workers", previous.numWorkers, next.numWorkers)
+	}
+	if !slices.Equal(next.tenants, previous.tenants) {
+		c.tenancyMgr.SetTenants(next.tenants)
+		c.logger.Info("Reloaded collector option", zap.String("option", "tenants"),
+			zap.Strings("previous", previous.tenants), zap.Strings("value", next.tenants))
+	}
+	c.reloadable = next
+	c.publishOpts(options)
+	return nil
+}
+
+func logReloaded(logger *zap.Logger, option string, previous, value int) {
+	if previous != value {
+		logger.Info("Reloaded collector option", zap.String("option", option),
+			zap.Int("previous", previous), zap.Int("value", value))
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+func TestCollectorReload(t *testing.T) {
+	baseMetrics := metricstest.NewFactory(time.Hour)
+	defer baseMetrics.Backend.Stop()
+	core, logs := observer.New(zap.InfoLevel)
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true, Tenants: []string{"acme"}})
+
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.New(core),
+		MetricsFactory:   baseMetrics,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       tm,
+	})
+	options := optionsForEphemeralPorts()
+	options.QueueSize = 100
+	options.NumWorkers = 2
+	options.GRPC.Tenancy = tenancy.Options{Enabled: true, Tenants: []string{"acme"}}
+	require.NoError(t, c.Start(options))
+	defer c.Close()
+
+	reloaded := optionsForEphemeralPorts()
+	reloaded.QueueSize = 200
+	reloaded.NumWorkers = 4
+	reloaded.GRPC.Tenancy = tenancy.Options{Enabled: true, Tenants: []string{"acme", "country-store"}}
+	require.NoError(t, c.Reload(reloaded))
+
+	sp := c.spanProcessor.(*spanProcessor)
+	assert.Equal(t, 200, sp.queue.Capacity())
+	assert.Equal(t, 4, sp.numWorkers)
+	assert.True(t, tm.Valid("country-store"))
+	assert.Equal(t, 3, logs.FilterMessage("Reloaded collector option").Len())
+
+	// reloading the same options changes nothing
+	require.NoError(t, c.Reload(reloaded))
+	assert.Equal(t, 3, logs.FilterMessage("Reloaded collector option").Len())
+
+	reloaded.NumWorkers = 0
+	require.ErrorContains(t, c.Reload(reloaded), "must be positive")
+	assert.Equal(t, 4, sp.numWorkers)
+}
+
+func TestCollectorReloadDynamicQueueSize(t *testing.T) {
+	baseMetrics := metricstest.NewFactory(time.Hour)
+	defer baseMetrics.Backend.Stop()
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   baseMetrics,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+	})
+	options := optionsForEphemeralPorts()
+	options.QueueSize = 100
+	options.NumWorkers = 2
+	options.DynQueueSizeMemory = 1024 * 1024
+	require.NoError(t, c.Start(options))
+	defer c.Close()
+
+	reloaded := *options
+	reloaded.QueueSize = 200
+	require.NoError(t, c.Reload(&reloaded))
+	assert.Equal(t, 100, c.spanProcessor.(*spanProcessor).queue.Capacity())
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rules drops, keeps or modifies the spans received by the collector according
+// to rules whose conditions are expressions evaluated on the spans.
+//
+// The rules are loaded from a JSON file, reloaded when it changes:
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "name": "keep-errors",
+//	      "when": "span.tags['error'] == true",
+//	      "action": "keep"
+//	    },
+//	    {
+//	      "name": "drop-health-checks",
+//	      "when": "span.duration < duration('2ms') && span.tags['http.status_code'] == 200",
+//	      "action": "drop",
+//	      "ratio": 0.9
+//	    },
+//	    {
+//	      "name": "payments-team",
+//	      "when": "span.service == 'payments'",
+//	      "action": "modify",
+//	      "set_tags": {"team": "payments"}
+//	    }
+//	  ]
+//	}
+//
+// The conditions use the expr language (https://expr-lang.org) on the span fields trace_id,
+// span_id, service, operation, duration, tags and process_tags. The rules are evaluated in
+// order: the first matching keep or drop rule decides the fate of the span, and the matching
+// modify rules set the tags of the span for the rules after them. A drop rule with a ratio
+// drops this fraction of the matching spans, chosen by trace ID so that the spans of a
+// trace are all dropped or all kept.
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/fswatcher"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_span_rules_hits_total",
+			Type: telemetery.Counter,
+			Help: "Spans matching each of the span rules",
+			Labels: []telemetery.Label{
+				{Name: "rule", Values: []string{"<name of the rule>"}},
+			},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_span_rules_dropped_spans_total",
+			Type: telemetery.Counter,
+			Help: "Spans dropped by the span rules",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_span_rules_errors_total",
+			Type: telemetery.Counter,
+			Help: "Failed evaluations of the conditions of the span rules, the rule is skipped",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_span_rules_reloads_total",
+			Type: telemetery.Counter,
+			Help: "Reloads of the span rules file, the previous rules are kept when it fails",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"ok", "err"}},
+			},
+		},
+	)
+}
+
+const (
+	actionKeep   = "keep"
+	actionDrop   = "drop"
+	actionModify = "modify"
+)
+
+type engineMetrics struct {
+	// Dropped counts the spans dropped by the rules
+	Dropped metrics.Counter `metric:"span-rules.dropped-spans"`
+	// Errors counts the failed evaluations of the conditions
+	Errors metrics.Counter `metric:"span-rules.errors"`
+	// Reloads counts the reloads of the rules file
+	Reloads metrics.Counter `metric:"span-rules.reloads" tags:"result=ok"`
+	// ReloadFailures counts the reloads of the rules file which failed, leaving the previous rules in place
+	ReloadFailures metrics.Counter `metric:"span-rules.reloads" tags:"result=err"`
+}
+
+type config struct {
+	Rules []ruleConfig `json:"rules"`
+}
+
+type ruleConfig struct {
+	Name   string `json:"name"`
+	When   string `json:"when"`
+	Action string `json:"action"`
+	// Ratio is the fraction of the matching spans dropped by a drop rule, all of them if omitted
+	Ratio *float64 `json:"ratio"`
+	// SetTags are the tags set by a modify rule
+	SetTags map[string]string `json:"set_tags"`
+}
+
+type rule struct {
+	name   string
+	when   *vm.Program
+	action string
+	ratio  float64
+	tags   []model.KeyValue
+	hits   metrics.Counter
+}
+
+// env is the environment of the conditions.
+type env struct {
+	Span spanEnv `expr:"span"`
+}
+
+type spanEnv struct {
+	TraceID     string         `expr:"trace_id"`
+	SpanID      string         `expr:"span_id"`
+	Service     string         `expr:"service"`
+	Operation   string         `expr:"operation"`
+	Duration    time.Duration  `expr:"duration"`
+	Tags        map[string]any `expr:"tags"`
+	ProcessTags map[string]any `expr:"process_tags"`
+}
+
+// Engine applies the rules of a file to the spans.
+type Engine struct {
+	path           string
+	logger         *zap.Logger
+	metricsFactory metrics.Factory
+	metrics        engineMetrics
+	rules          atomic.Pointer[[]*rule]
+	watcher        *fswatcher.FSWatcher
+}
+
+// NewEngine loads the rules of the file at path and watches it to reload them when it changes.
+func NewEngine(path string, metricsFactory metrics.Factory, logger *zap.Logger) (*Engine, error) {
+	e := &Engine{
+		path:           path,
+		logger:         logger,
+		metricsFactory: metricsFactory,
+	}
+	metrics.MustInit(&e.metrics, metricsFactory, nil)
+	rules, err := e.load()
+	if err != nil {
+		return nil, err
+	}
+	e.rules.Store(&rules)
+	watcher, err := fswatcher.New([]string{path}, e.reload, logger)
+	if err != nil {
+		return nil, err
+	}
+	e.watcher = watcher
+	return e, nil
+}
+
+func (e *Engine) load() ([]*rule, error) {
+	data, err := os.ReadFile(filepath.Clean(e.path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the span rules file: %w", err)
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the span rules file: %w", err)
+	}
+	rules := make([]*rule, 0, len(cfg.Rules))
+	names := make(map[string]struct{}, len(cfg.Rules))
+	for _, rc := range cfg.Rules {
+		if _, ok := names[rc.Name]; ok {
+			return nil, fmt.Errorf("duplicate span rule name %q", rc.Name)
+		}
+		names[rc.Name] = struct{}{}
+		r, err := e.compile(rc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid span rule %q: %w", rc.Name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (e *Engine) compile(rc ruleConfig) (*rule, error) {
+	if rc.Name == "" {
+		return nil, errors.New("the name is required")
+	}
+	when, err := expr.Compile(rc.When, expr.Env(env{}), expr.AsBool())
+	if err != nil {
+		return nil, err
+	}
+	r := &rule{
+		name:   rc.Name,
+		when:   when,
+		action: rc.Action,
+		ratio:  1,
+		hits:   e.metricsFactory.Counter(metrics.Options{Name: "span-rules.hits", Tags: map[string]string{"rule": rc.Name}}),
+	}
+	switch rc.Action {
+	case actionKeep:
+	case actionDrop:
+		if rc.Ratio != nil {
+			if *rc.Ratio < 0 || *rc.Ratio > 1 {
+				return nil, fmt.Errorf("the ratio %v must be between 0 and 1", *rc.Ratio)
+			}
+			r.ratio = *rc.Ratio
+		}
+	case actionModify:
+		if len(rc.SetTags) == 0 {
+			return nil, errors.New("a modify rule requires set_tags")
+		}
+		for k, v := range rc.SetTags {
+			r.tags = append(r.tags, model.String(k, v))
+		}
+		sort.Slice(r.tags, func(i, j int) bool { return r.tags[i].Key < r.tags[j].Key })
+	default:
+		return nil, fmt.Errorf("unknown action %q, it must be keep, drop or modify", rc.Action)
+	}
+	return r, nil
+}
+
+func (e *Engine) reload() {
+	rules, err := e.load()
+	if err != nil {
+		e.metrics.ReloadFailures.Inc(1)
+		e.logger.Error("Failed to reload the span rules, keeping the previous rules", zap.Error(err))
+		return
+	}
+	e.rules.Store(&rules)
+	e.metrics.Reloads.Inc(1)
+	e.logger.Info("Reloaded the span rules", zap.Int("rules", len(rules)))
+}
+
+// Apply returns the span modified by the rules, or nil if a rule dropped it.
+func (e *Engine) Apply(span *model.Span) *model.Span {
+	rules := *e.rules.Load()
+	if len(rules) == 0 {
+		return span
+	}
+	env := newEnv(span)
+	for _, r := range rules {
+		matched, err := expr.Run(r.when, env)
+		if err != nil {
+			e.metrics.Errors.Inc(1)
+			e.logger.Debug("Failed to evaluate the span rule", zap.String("rule", r.name), zap.Error(err))
+			continue
+		}
+		if !matched.(bool) {
+			continue
+		}
+		r.hits.Inc(1)
+		switch r.action {
+		case actionKeep:
+			return span
+		case actionDrop:
+			if r.ratio < 1 && !drawTrace(span.TraceID, r.ratio) {
+				return span
+			}
+			e.metrics.Dropped.Inc(1)
+			return nil
+		case actionModify:
+			for _, tag := range r.tags {
+				setTag(span, tag)
+				env.Span.Tags[tag.Key] = tag.Value()
+			}
+		}
+	}
+	return span
+}
+
+// Close stops watching the rules file.
+func (e *Engine) Close() error {
+	return e.watcher.Close()
+}
+
+func newEnv(span *model.Span) env {
+	e := env{
+		Span: spanEnv{
+			TraceID:   span.TraceID.String(),
+			SpanID:    span.SpanID.String(),
+			Operation: span.OperationName,
+			Duration:  span.Duration,
+			Tags:      tagsMap(span.Tags),
+		},
+	}
+	if span.Process != nil {
+		e.Span.Service = span.Process.ServiceName
+		e.Span.ProcessTags = tagsMap(span.Process.Tags)
+	}
+	return e
+}
+
+func tagsMap(tags []model.KeyValue) map[string]any {
+	m := make(map[string]any, len(tags))
+	for i := range tags {
+		m[tags[i].Key] = tags[i].Value()
+	}
+	return m
+}
+
+func setTag(span *model.Span, tag model.KeyValue) {
+	for i := range span.Tags {
+		if span.Tags[i].Key == tag.Key {
+			span.Tags[i] = tag
+			return
+		}
+	}
+	span.Tags = append(span.Tags, tag)
+}
+
+// drawTrace returns whether the trace is in the fraction ratio of the traces. The trace ID is
+// hashed, so that the draw is independent of the probabilistic sampling decided by the trace ID.
+func drawTrace(traceID model.TraceID, ratio float64) bool {
+	return float64(mix(traceID.High^mix(traceID.Low))) < ratio*math.MaxUint64
+}
+
+// mix is the finalizer of SplitMix64, spreading any change of x to all the bits of the result.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
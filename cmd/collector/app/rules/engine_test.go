@@ -0,0 +1,197 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const testRules = `{
+  "rules": [
+    {
+      "name": "keep-errors",
+      "when": "span.tags['error'] == true",
+      "action": "keep"
+    },
+    {
+      "name": "payments-team",
+      "when": "span.service == 'payments'",
+      "action": "modify",
+      "set_tags": {"team": "payments", "tier": "1"}
+    },
+    {
+      "name": "drop-health-checks",
+      "when": "span.duration < duration('2ms') && span.tags['http.status_code'] == 200",
+      "action": "drop"
+    },
+    {
+      "name": "drop-team-debug",
+      "when": "span.tags['team'] == 'payments' && span.operation == 'debug'",
+      "action": "drop"
+    }
+  ]
+}`
+
+// writeRules replaces the rules file at once, so that the watcher never reads it partially written.
+func writeRules(t *testing.T, path string, rules string) {
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(rules), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func newTestEngine(t *testing.T, rules string, metricsFactory metrics.Factory) (*Engine, string) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	writeRules(t, path, rules)
+	e, err := NewEngine(path, metricsFactory, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, e.Close()) })
+	return e, path
+}
+
+func testSpan(service string, operation string, duration time.Duration, tags ...model.KeyValue) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(1, 2),
+		OperationName: operation,
+		Duration:      duration,
+		Tags:          tags,
+		Process:       model.NewProcess(service, nil),
+	}
+}
+
+func TestEngine(t *testing.T) {
+	e, _ := newTestEngine(t, testRules, metrics.NullFactory)
+	tests := []struct {
+		name     string
+		span     *model.Span
+		expected *model.Span
+	}{
+		{
+			name:     "no matching rule",
+			span:     testSpan("orders", "get", time.Second),
+			expected: testSpan("orders", "get", time.Second),
+		},
+		{
+			name: "dropped",
+			span: testSpan("orders", "health", time.Millisecond, model.Int64("http.status_code", 200)),
+		},
+		{
+			name:     "kept before the drop rule",
+			span:     testSpan("orders", "health", time.Millisecond, model.Int64("http.status_code", 200), model.Bool("error", true)),
+			expected: testSpan("orders", "health", time.Millisecond, model.Int64("http.status_code", 200), model.Bool("error", true)),
+		},
+		{
+			name:     "modified",
+			span:     testSpan("payments", "charge", time.Second, model.String("tier", "2")),
+			expected: testSpan("payments", "charge", time.Second, model.String("tier", "1"), model.String("team", "payments")),
+		},
+		{
+			name: "dropped on a modified tag",
+			span: testSpan("payments", "debug", time.Second),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, e.Apply(test.span))
+		})
+	}
+}
+
+func TestEngineDropRatio(t *testing.T) {
+	e, _ := newTestEngine(t, `{"rules": [{"name": "half", "when": "true", "action": "drop", "ratio": 0.5}]}`, metrics.NullFactory)
+	dropped := 0
+	for i := uint64(0); i < 1000; i++ {
+		span := &model.Span{TraceID: model.NewTraceID(0, i)}
+		result := e.Apply(span)
+		// the spans of a trace are all dropped or all kept
+		assert.Equal(t, result, e.Apply(&model.Span{TraceID: model.NewTraceID(0, i)}))
+		if result == nil {
+			dropped++
+		}
+	}
+	assert.InDelta(t, 500, dropped, 100)
+}
+
+func TestEngineMetrics(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	e, _ := newTestEngine(t, testRules, mb)
+	e.Apply(testSpan("orders", "health", time.Millisecond, model.Int64("http.status_code", 200)))
+	e.Apply(testSpan("payments", "charge", time.Second))
+	// the condition fails on a missing tag
+	e, _ = newTestEngine(t, `{"rules": [{"name": "slow", "when": "span.tags['size'] > 10", "action": "drop"}]}`, mb)
+	e.Apply(testSpan("orders", "get", time.Second))
+
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "span-rules.hits", Tags: map[string]string{"rule": "drop-health-checks"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "span-rules.hits", Tags: map[string]string{"rule": "payments-team"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "span-rules.hits", Tags: map[string]string{"rule": "keep-errors"}, Value: 0},
+		metricstest.ExpectedMetric{Name: "span-rules.dropped-spans", Value: 1},
+		metricstest.ExpectedMetric{Name: "span-rules.errors", Value: 1},
+	)
+}
+
+func TestEngineReload(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	e, path := newTestEngine(t, testRules, mb)
+	span := func() *model.Span { return testSpan("orders", "get", time.Second) }
+	require.NotNil(t, e.Apply(span()))
+
+	writeRules(t, path, `{"rules": [{"name": "drop-orders", "when": "span.service == 'orders'", "action": "drop"}]}`)
+	assert.Eventually(t, func() bool {
+		return e.Apply(span()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the previous rules are kept when the file is invalid
+	writeRules(t, path, `{"rules": [{"name": "invalid", "when": "span.unknown", "action": "drop"}]}`)
+	assert.Eventually(t, func() bool {
+		counters, _ := mb.Snapshot()
+		return counters["span-rules.reloads|result=err"] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, e.Apply(span()))
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "span-rules.reloads", Tags: map[string]string{"result": "ok"}, Value: 1})
+}
+
+func TestNewEngineErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{name: "invalid JSON", rules: `{`, err: "failed to parse the span rules file"},
+		{name: "missing name", rules: `{"rules": [{"when": "true", "action": "keep"}]}`, err: "the name is required"},
+		{
+			name:  "duplicate name",
+			rules: `{"rules": [{"name": "a", "when": "true", "action": "keep"}, {"name": "a", "when": "true", "action": "drop"}]}`,
+			err:   `duplicate span rule name "a"`,
+		},
+		{name: "invalid condition", rules: `{"rules": [{"name": "a", "when": "span.unknown", "action": "keep"}]}`, err: `invalid span rule "a"`},
+		{name: "condition not boolean", rules: `{"rules": [{"name": "a", "when": "span.service", "action": "keep"}]}`, err: "expected bool"},
+		{name: "unknown action", rules: `{"rules": [{"name": "a", "when": "true", "action": "sample"}]}`, err: `unknown action "sample"`},
+		{name: "invalid ratio", rules: `{"rules": [{"name": "a", "when": "true", "action": "drop", "ratio": 2}]}`, err: "the ratio 2 must be between 0 and 1"},
+		{name: "modify without tags", rules: `{"rules": [{"name": "a", "when": "true", "action": "modify"}]}`, err: "a modify rule requires set_tags"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.json")
+			writeRules(t, path, test.rules)
+			_, err := NewEngine(path, metrics.NullFactory, zap.NewNop())
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+
+	_, err := NewEngine(filepath.Join(t.TempDir(), "missing.json"), metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "failed to read the span rules file")
+}
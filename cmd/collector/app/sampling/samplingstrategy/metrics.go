@@ -0,0 +1,112 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstrategy
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/normalizer"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+const (
+	// maxServiceNames bounds the cardinality of the svc label, like the span metrics of the collector.
+	maxServiceNames = 4000
+	otherServices   = "other-services"
+
+	strategyTypeProbabilistic = "probabilistic"
+	strategyTypeRateLimiting  = "ratelimiting"
+	strategyTypePerOperation  = "per_operation"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_sampling_strategies_served_total",
+			Type: telemetery.Counter,
+			Help: "Sampling strategies served to the SDKs, by service and strategy type",
+			Labels: []telemetery.Label{
+				{Name: "svc"},
+				{Name: "type", Values: []string{strategyTypeProbabilistic, strategyTypeRateLimiting, strategyTypePerOperation}},
+			},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_sampling_strategy_errors_total",
+			Type: telemetery.Counter,
+			Help: "Sampling strategy requests which failed",
+		},
+	)
+}
+
+type instrumentedProvider struct {
+	Provider
+	factory metrics.Factory
+	errors  metrics.Counter
+
+	lock     sync.Mutex
+	services map[string]struct{}
+	served   map[servedKey]metrics.Counter
+}
+
+type servedKey struct {
+	service      string
+	strategyType string
+}
+
+// NewInstrumentedProvider wraps a Provider counting the strategies it serves per service and strategy type.
+func NewInstrumentedProvider(provider Provider, metricsFactory metrics.Factory) Provider {
+	return &instrumentedProvider{
+		Provider: provider,
+		factory:  metricsFactory,
+		errors:   metricsFactory.Counter(metrics.Options{Name: "sampling.strategy-errors"}),
+		services: make(map[string]struct{}),
+		served:   make(map[servedKey]metrics.Counter),
+	}
+}
+
+// GetSamplingStrategy implements Provider.
+func (p *instrumentedProvider) GetSamplingStrategy(ctx context.Context, serviceName string) (*api_v2.SamplingStrategyResponse, error) {
+	strategy, err := p.Provider.GetSamplingStrategy(ctx, serviceName)
+	if err != nil {
+		p.errors.Inc(1)
+		return strategy, err
+	}
+	p.servedCounter(normalizer.ServiceName(serviceName), strategyType(strategy)).Inc(1)
+	return strategy, nil
+}
+
+func (p *instrumentedProvider) servedCounter(service string, strategyType string) metrics.Counter {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.services[service]; !ok {
+		if len(p.services) >= maxServiceNames {
+			service = otherServices
+		}
+		p.services[service] = struct{}{}
+	}
+	key := servedKey{service: service, strategyType: strategyType}
+	counter, ok := p.served[key]
+	if !ok {
+		counter = p.factory.Counter(metrics.Options{
+			Name: "sampling.strategies-served",
+			Tags: map[string]string{"svc": service, "type": strategyType},
+		})
+		p.served[key] = counter
+	}
+	return counter
+}
+
+func strategyType(strategy *api_v2.SamplingStrategyResponse) string {
+	switch {
+	case strategy.GetOperationSampling() != nil:
+		return strategyTypePerOperation
+	case strategy.GetStrategyType() == api_v2.SamplingStrategyType_RATE_LIMITING:
+		return strategyTypeRateLimiting
+	default:
+		return strategyTypeProbabilistic
+	}
+}
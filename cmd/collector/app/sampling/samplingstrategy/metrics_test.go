@@ -0,0 +1,104 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstrategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jprom "github.com/jaegertracing/jaeger/internal/metrics/prometheus"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+type fakeProvider map[string]*api_v2.SamplingStrategyResponse
+
+func (p fakeProvider) GetSamplingStrategy(_ context.Context, serviceName string) (*api_v2.SamplingStrategyResponse, error) {
+	if strategy, ok := p[serviceName]; ok {
+		return strategy, nil
+	}
+	return nil, errors.New("no strategy")
+}
+
+func (fakeProvider) Close() error {
+	return nil
+}
+
+var testStrategies = fakeProvider{
+	"frontend": {StrategyType: api_v2.SamplingStrategyType_PROBABILISTIC},
+	"redis":    {StrategyType: api_v2.SamplingStrategyType_RATE_LIMITING},
+	"driver": {
+		StrategyType:      api_v2.SamplingStrategyType_PROBABILISTIC,
+		OperationSampling: &api_v2.PerOperationSamplingStrategies{},
+	},
+}
+
+func TestInstrumentedProvider(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	provider := NewInstrumentedProvider(testStrategies, metricsFactory)
+
+	for _, service := range []string{"frontend", "frontend", "redis", "driver", "unknown"} {
+		provider.GetSamplingStrategy(context.Background(), service)
+	}
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "sampling.strategies-served", Tags: map[string]string{"svc": "frontend", "type": "probabilistic"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "sampling.strategies-served", Tags: map[string]string{"svc": "redis", "type": "ratelimiting"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "sampling.strategies-served", Tags: map[string]string{"svc": "driver", "type": "per_operation"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "sampling.strategy-errors", Value: 1},
+	)
+	require.NoError(t, provider.Close())
+}
+
+func TestInstrumentedProviderMaxServices(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	strategies := make(fakeProvider)
+	for i := 0; i <= maxServiceNames; i++ {
+		strategies[fmt.Sprintf("service-%d", i)] = &api_v2.SamplingStrategyResponse{}
+	}
+	provider := NewInstrumentedProvider(strategies, metricsFactory)
+
+	for i := 0; i <= maxServiceNames; i++ {
+		provider.GetSamplingStrategy(context.Background(), fmt.Sprintf("service-%d", i))
+	}
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "sampling.strategies-served", Tags: map[string]string{"svc": "service-0", "type": "probabilistic"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "sampling.strategies-served", Tags: map[string]string{"svc": otherServices, "type": "probabilistic"}, Value: 1},
+	)
+}
+
+// TestInstrumentedProviderSchema checks that the metrics exposed on /metrics are documented.
+func TestInstrumentedProviderSchema(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metricsFactory := jprom.New(jprom.WithRegisterer(registry)).
+		Namespace(metrics.NSOptions{Name: "jaeger"}).
+		Namespace(metrics.NSOptions{Name: "collector"})
+	provider := NewInstrumentedProvider(testStrategies, metricsFactory)
+	provider.GetSamplingStrategy(context.Background(), "frontend")
+	provider.GetSamplingStrategy(context.Background(), "unknown")
+
+	documented := make(map[string]telemetery.Metric)
+	for _, metric := range telemetery.DefaultRegistry().Metrics() {
+		documented[metric.Name] = metric
+	}
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 2)
+	for _, family := range families {
+		metric, ok := documented[family.GetName()]
+		if assert.True(t, ok, "metric %s is not documented", family.GetName()) {
+			assert.True(t, strings.EqualFold(string(metric.Type), family.GetType().String()), "type of %s", family.GetName())
+		}
+	}
+}
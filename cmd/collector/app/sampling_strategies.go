@@ -0,0 +1,27 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+// CreateSamplingStrategyStore creates the store of the sampling strategies set with the query
+// service API of the storage factory, or returns nil if the storage does not support it.
+func CreateSamplingStrategyStore(storageFactory storage.Factory, logger *zap.Logger) samplingstore.StrategyStore {
+	strategyFactory, ok := storageFactory.(storage.SamplingStrategyStoreFactory)
+	if !ok {
+		logger.Info("Sampling strategies storage not supported by the factory")
+		return nil
+	}
+	store, err := strategyFactory.CreateSamplingStrategyStore()
+	if err != nil {
+		logger.Info("Sampling strategies storage not created", zap.String("reason", err.Error()))
+		return nil
+	}
+	return store
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/mocks"
+)
+
+func TestCreateSamplingStrategyStore(t *testing.T) {
+	assert.Nil(t, CreateSamplingStrategyStore(&mocks.Factory{}, zap.NewNop()))
+
+	factory := memory.NewFactory()
+	require.NoError(t, factory.Initialize(nil, zap.NewNop()))
+	assert.NotNil(t, CreateSamplingStrategyStore(factory, zap.NewNop()))
+}
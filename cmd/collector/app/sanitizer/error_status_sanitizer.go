@@ -0,0 +1,19 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sanitizer
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+)
+
+// NewErrorStatusSanitizer returns a function that normalizes the error signaling of the spans
+// before they are stored, like the error-status adjuster of the query service, so that the spans
+// of all the SDKs are found by the searches of the error=true tag.
+func NewErrorStatusSanitizer() SanitizeSpan {
+	return func(span *model.Span) *model.Span {
+		adjuster.NormalizeErrorStatus(span)
+		return span
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sanitizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+)
+
+func TestErrorStatusSanitizer(t *testing.T) {
+	span := NewErrorStatusSanitizer()(&model.Span{
+		Tags: model.KeyValues{model.String("error", "true"), model.Int64("http.status_code", 500)},
+	})
+	assert.Equal(t, model.KeyValues{
+		model.Int64("http.status_code", 500),
+		model.Bool(adjuster.ErrorTag, true),
+		model.String(adjuster.StatusCodeTag, adjuster.StatusCodeError),
+	}, model.KeyValues(span.Tags))
+}
@@ -27,6 +27,7 @@ type SanitizeSpan func(span *model.Span) *model.Span
 func NewStandardSanitizers() []SanitizeSpan {
 	return []SanitizeSpan{
 		NewEmptyServiceNameSanitizer(),
+		NewTraceStateSanitizer(),
 	}
 }
 
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sanitizer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// traceStateTag is the tag of the W3C tracestate of the spans received with OTLP
+	traceStateTag = "w3c.tracestate"
+	// traceStateOTelKey is the key of the OpenTelemetry entry of the tracestate
+	traceStateOTelKey = "ot"
+
+	// thresholdMaxDigits is the number of hexadecimal digits of the 56 bits sampling thresholds
+	thresholdMaxDigits = 14
+	// pValueZeroProbability is the p-value of the spans which would have a zero sampling probability
+	pValueZeroProbability = 63
+)
+
+// NewTraceStateSanitizer returns a function that records the sampling probability found in the
+// OpenTelemetry entry of the W3C tracestate of the spans, e.g. ot=th:c for 25% or ot=p:2 in the
+// former specification, as the sampling.adjusted_count tag of the spans. The threshold takes
+// precedence over the p-value, and the spans sampled at 100% or already having the tag are
+// left unchanged.
+func NewTraceStateSanitizer() SanitizeSpan {
+	return sanitizeTraceState
+}
+
+func sanitizeTraceState(span *model.Span) *model.Span {
+	tag, ok := model.KeyValues(span.Tags).FindByKey(traceStateTag)
+	if !ok {
+		return span
+	}
+	if _, ok := model.KeyValues(span.Tags).FindByKey(model.AdjustedCountTag); ok {
+		return span
+	}
+	count, ok := adjustedCount(tag.AsString())
+	if !ok || count == 1 {
+		return span
+	}
+	span.Tags = append(span.Tags, model.Float64(model.AdjustedCountTag, count))
+	return span
+}
+
+// adjustedCount returns the adjusted count of the sampling of the tracestate, if it has one.
+func adjustedCount(traceState string) (float64, bool) {
+	otel, ok := traceStateEntry(traceState, traceStateOTelKey)
+	if !ok {
+		return 0, false
+	}
+	var pValue string
+	for _, field := range strings.Split(otel, ";") {
+		key, value, _ := strings.Cut(field, ":")
+		switch key {
+		case "th":
+			return thresholdAdjustedCount(value)
+		case "p":
+			pValue = value
+		}
+	}
+	if pValue == "" {
+		return 0, false
+	}
+	return pValueAdjustedCount(pValue)
+}
+
+// traceStateEntry returns the value of the entry of the tracestate with the key.
+func traceStateEntry(traceState string, key string) (string, bool) {
+	for _, entry := range strings.Split(traceState, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// thresholdAdjustedCount returns the adjusted count of the rejection threshold th, whose
+// hexadecimal digits are the most significant of a 56 bits number.
+func thresholdAdjustedCount(th string) (float64, bool) {
+	if th == "" || len(th) > thresholdMaxDigits {
+		return 0, false
+	}
+	threshold, err := strconv.ParseUint(th, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	threshold <<= 4 * (thresholdMaxDigits - len(th))
+	const maxThreshold = 1 << 56
+	return maxThreshold / float64(maxThreshold-threshold), true
+}
+
+// pValueAdjustedCount returns the adjusted count of the p-value p, the sampling probability
+// being 2^-p.
+func pValueAdjustedCount(p string) (float64, bool) {
+	pValue, err := strconv.Atoi(p)
+	if err != nil || pValue < 0 || pValue > pValueZeroProbability {
+		return 0, false
+	}
+	if pValue == pValueZeroProbability {
+		return 0, true
+	}
+	return math.Exp2(float64(pValue)), true
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sanitizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceStateSanitizer(t *testing.T) {
+	tests := []struct {
+		traceState string
+		expected   float64
+	}{
+		{traceState: "ot=th:8", expected: 2},
+		{traceState: "ot=th:c", expected: 4},
+		{traceState: "ot=th:fd70a3d70a3d71", expected: 100},
+		{traceState: "vendor=a:b , ot=rv:abcdef01234567;th:e ,other=c", expected: 8},
+		{traceState: "ot=p:3", expected: 8},
+		{traceState: "ot=p:63", expected: 0},
+		{traceState: "ot=p:1;th:c", expected: 4},
+		{traceState: "ot=th:0", expected: 1},
+		{traceState: "ot=p:0", expected: 1},
+		{traceState: "ot=rv:abcdef01234567", expected: 1},
+		{traceState: "ot=th:xyz", expected: 1},
+		{traceState: "ot=th:", expected: 1},
+		{traceState: "ot=th:fffffffffffffff", expected: 1},
+		{traceState: "ot=p:64", expected: 1},
+		{traceState: "ot=p:x", expected: 1},
+		{traceState: "vendor=th:8", expected: 1},
+	}
+	sanitizer := NewTraceStateSanitizer()
+	for _, test := range tests {
+		t.Run(test.traceState, func(t *testing.T) {
+			span := sanitizer(&model.Span{Tags: model.KeyValues{model.String("w3c.tracestate", test.traceState)}})
+			assert.InDelta(t, test.expected, span.GetAdjustedCount(), 1e-9)
+			if test.expected == 1 {
+				assert.Len(t, span.Tags, 1)
+			}
+		})
+	}
+}
+
+func TestTraceStateSanitizerKeepsAdjustedCount(t *testing.T) {
+	span := NewTraceStateSanitizer()(&model.Span{Tags: model.KeyValues{
+		model.String("w3c.tracestate", "ot=th:8"),
+		model.Int64("sampling.adjusted_count", 10),
+	}})
+	assert.Len(t, span.Tags, 2)
+	assert.Equal(t, float64(10), span.GetAdjustedCount())
+
+	span = NewTraceStateSanitizer()(&model.Span{})
+	assert.Empty(t, span.Tags)
+}
@@ -17,8 +17,12 @@ package server
 import (
 	"fmt"
 	"net"
+	"os"
 	"time"
 
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/service/accesslog/v3"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -31,13 +35,21 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/throttling"
 )
 
 // GRPCServerParams to construct a new Jaeger Collector gRPC Server
 type GRPCServerParams struct {
-	TLSConfig               tlscfg.Options
-	HostPort                string
+	TLSConfig tlscfg.Options
+	HostPort  string
+	// Network is the Go TCP network of the listener, e.g. tcp4 or tcp6, "tcp" if empty.
+	Network string
+	// SocketPermissions are the permissions of the Unix domain socket of a unix:// HostPort, if not zero.
+	SocketPermissions       os.FileMode
 	Handler                 *handler.GRPCHandler
 	SamplingProvider        samplingstrategy.Provider
 	Logger                  *zap.Logger
@@ -45,6 +57,14 @@ type GRPCServerParams struct {
 	MaxReceiveMessageLength int
 	MaxConnectionAge        time.Duration
 	MaxConnectionAgeGrace   time.Duration
+	// MetricsFactory, when set, receives the size of the messages before and after compression.
+	MetricsFactory metrics.Factory
+	// TracerProvider, when set, traces the requests received by the server.
+	TracerProvider trace.TracerProvider
+	// Throttler, when set, grants credits to the SDKs for the traces forced by their clients.
+	Throttler throttling.ThrottlingServiceServer
+	// EnvoyALSHandler, when set, converts the access logs streamed by Envoy to spans.
+	EnvoyALSHandler accesslogv3.AccessLogServiceServer
 
 	// Set by the server to indicate the actual host:port of the server.
 	HostPortActual string
@@ -63,6 +83,13 @@ func StartGRPCServer(params *GRPCServerParams) (*grpc.Server, error) {
 		MaxConnectionAgeGrace: params.MaxConnectionAgeGrace,
 	}))
 
+	if params.MetricsFactory != nil {
+		grpcOpts = append(grpcOpts, grpc.StatsHandler(grpccompression.NewStatsHandler(params.MetricsFactory)))
+	}
+	if params.TracerProvider != nil {
+		grpcOpts = append(grpcOpts, grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(params.TracerProvider))))
+	}
+
 	if params.TLSConfig.Enabled {
 		// user requested a server with TLS, setup creds
 		tlsCfg, err := params.TLSConfig.Config(params.Logger)
@@ -77,7 +104,7 @@ func StartGRPCServer(params *GRPCServerParams) (*grpc.Server, error) {
 	server = grpc.NewServer(grpcOpts...)
 	reflection.Register(server)
 
-	listener, err := net.Listen("tcp", params.HostPort)
+	listener, err := netutils.Listen(params.Network, params.HostPort, params.SocketPermissions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen on gRPC port: %w", err)
 	}
@@ -98,6 +125,14 @@ func serveGRPC(server *grpc.Server, listener net.Listener, params *GRPCServerPar
 
 	healthServer.SetServingStatus("jaeger.api_v2.CollectorService", grpc_health_v1.HealthCheckResponse_SERVING)
 	healthServer.SetServingStatus("jaeger.api_v2.SamplingManager", grpc_health_v1.HealthCheckResponse_SERVING)
+	if params.Throttler != nil {
+		throttling.RegisterThrottlingServiceServer(server, params.Throttler)
+		healthServer.SetServingStatus("jaeger.api_v2.throttling.ThrottlingService", grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+	if params.EnvoyALSHandler != nil {
+		accesslogv3.RegisterAccessLogServiceServer(server, params.EnvoyALSHandler)
+		healthServer.SetServingStatus("envoy.service.accesslog.v3.AccessLogService", grpc_health_v1.HealthCheckResponse_SERVING)
+	}
 
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
 
@@ -16,11 +16,16 @@ package server
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/service/accesslog/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -29,10 +34,16 @@ import (
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/throttler"
 	"github.com/jaegertracing/jaeger/internal/grpctest"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/throttling"
 )
 
 // test wrong port number
@@ -96,6 +107,159 @@ func TestSpanCollector(t *testing.T) {
 	require.NotNil(t, response)
 }
 
+func TestSpanCollectorUnixSocket(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	socket := filepath.Join(t.TempDir(), "collector-grpc.sock")
+	params := &GRPCServerParams{
+		HostPort:          "unix://" + socket,
+		SocketPermissions: 0o600,
+		Handler:           handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider:  &mockSamplingProvider{},
+		Logger:            logger,
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+	assert.Equal(t, socket, params.HostPortActual)
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	conn, err := grpc.NewClient(
+		"unix://"+socket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	c := api_v2.NewCollectorServiceClient(conn)
+	response, err := c.PostSpans(context.Background(), &api_v2.PostSpansRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, response)
+}
+
+func TestThrottlingService(t *testing.T) {
+	logger := zap.NewNop()
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider: &mockSamplingProvider{},
+		Logger:           logger,
+		Throttler: throttler.NewThrottler(throttler.Options{
+			CreditsPerSecond: 1,
+			MaxBalance:       5,
+			MaxOperations:    10,
+			MaxServices:      10,
+		}, metrics.NullFactory),
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(
+		params.HostPortActual,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	c := throttling.NewThrottlingServiceClient(conn)
+	response, err := c.GetCredits(context.Background(), &throttling.GetCreditsRequest{
+		ServiceName: "frontend",
+		Operations:  []string{"GET /"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 5}}, response.Balances)
+}
+
+func TestEnvoyALSService(t *testing.T) {
+	logger := zap.NewNop()
+	spanProcessor := &mockSpanProcessor{}
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(logger, spanProcessor, &tenancy.Manager{}),
+		SamplingProvider: &mockSamplingProvider{},
+		Logger:           logger,
+		EnvoyALSHandler:  handler.NewEnvoyALSHandler(logger, spanProcessor, &tenancy.Manager{}, metrics.NullFactory),
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(
+		params.HostPortActual,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	stream, err := accesslogv3.NewAccessLogServiceClient(conn).StreamAccessLogs(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&accesslogv3.StreamAccessLogsMessage{}))
+	_, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+}
+
+func TestSpanCollectorCompression(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	metricsFactory := metricstest.NewFactory(0)
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider: &mockSamplingProvider{},
+		Logger:           logger,
+		MetricsFactory:   metricsFactory,
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(
+		params.HostPortActual,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpccompression.DialOption(grpccompression.Zstd))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	batch := model.Batch{Process: &model.Process{ServiceName: "service"}}
+	for i := 0; i < 100; i++ {
+		batch.Spans = append(batch.Spans, &model.Span{OperationName: "operation"})
+	}
+	c := api_v2.NewCollectorServiceClient(conn)
+	_, err = c.PostSpans(context.Background(), &api_v2.PostSpansRequest{Batch: batch})
+	require.NoError(t, err)
+
+	counters, _ := metricsFactory.Snapshot()
+	uncompressed := counters["grpc.payload_bytes|direction=received|encoding=uncompressed"]
+	compressed := counters["grpc.payload_bytes|direction=received|encoding=compressed"]
+	assert.Positive(t, compressed)
+	assert.Less(t, compressed, uncompressed)
+}
+
+func TestSpanCollectorTracing(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	recorder := tracetest.NewSpanRecorder()
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider: &mockSamplingProvider{},
+		Logger:           logger,
+		TracerProvider:   sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(params.HostPortActual, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	c := api_v2.NewCollectorServiceClient(conn)
+	_, err = c.PostSpans(context.Background(), &api_v2.PostSpansRequest{})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, "jaeger.api_v2.CollectorService/PostSpans", recorder.Ended()[0].Name())
+}
+
 func TestCollectorStartWithTLS(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	params := &GRPCServerParams{
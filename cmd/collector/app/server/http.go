@@ -17,9 +17,12 @@ package server
 import (
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -30,18 +33,27 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/httpmetrics"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
 )
 
 // HTTPServerParams to construct a new Jaeger Collector HTTP Server
 type HTTPServerParams struct {
-	TLSConfig        tlscfg.Options
-	HostPort         string
-	Handler          handler.JaegerBatchesHandler
-	SamplingProvider samplingstrategy.Provider
-	MetricsFactory   metrics.Factory
-	HealthCheck      *healthcheck.HealthCheck
-	Logger           *zap.Logger
+	TLSConfig tlscfg.Options
+	HostPort  string
+	// Network is the Go TCP network of the listener, e.g. tcp4 or tcp6, "tcp" if empty.
+	Network string
+	// SocketPermissions are the permissions of the Unix domain socket of a unix:// HostPort, if not zero.
+	SocketPermissions os.FileMode
+	Handler           handler.JaegerBatchesHandler
+	SamplingProvider  samplingstrategy.Provider
+	MetricsFactory    metrics.Factory
+	HealthCheck       *healthcheck.HealthCheck
+	Logger            *zap.Logger
+	// TracerProvider, when set, traces the requests received by the server.
+	TracerProvider trace.TracerProvider
+	// JSONSpanHandler, when set, accepts the spans in the JSON schema of the jsonspans package.
+	JSONSpanHandler *handler.JSONSpanHandler
 
 	// ReadTimeout sets the respective parameter of http.Server
 	ReadTimeout time.Duration
@@ -71,7 +83,7 @@ func StartHTTPServer(params *HTTPServerParams) (*http.Server, error) {
 		server.TLSConfig = tlsCfg
 	}
 
-	listener, err := net.Listen("tcp", params.HostPort)
+	listener, err := netutils.Listen(params.Network, params.HostPort, params.SocketPermissions)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +97,9 @@ func serveHTTP(server *http.Server, listener net.Listener, params *HTTPServerPar
 	r := mux.NewRouter()
 	apiHandler := handler.NewAPIHandler(params.Handler)
 	apiHandler.RegisterRoutes(r)
+	if params.JSONSpanHandler != nil {
+		params.JSONSpanHandler.RegisterRoutes(r)
+	}
 
 	cfgHandler := clientcfgHandler.NewHTTPHandler(clientcfgHandler.HTTPHandlerParams{
 		ConfigManager: &clientcfgHandler.ConfigManager{
@@ -97,8 +112,12 @@ func serveHTTP(server *http.Server, listener net.Listener, params *HTTPServerPar
 	})
 	cfgHandler.RegisterRoutes(r)
 
+	var h http.Handler = r
+	if params.TracerProvider != nil {
+		h = otelhttp.NewHandler(r, "collector-http", otelhttp.WithTracerProvider(params.TracerProvider))
+	}
 	recoveryHandler := recoveryhandler.NewRecoveryHandler(params.Logger, true)
-	server.Handler = httpmetrics.Wrap(recoveryHandler(r), params.MetricsFactory, params.Logger)
+	server.Handler = httpmetrics.Wrap(recoveryHandler(h), params.MetricsFactory, params.Logger)
 	go func() {
 		var err error
 		if params.TLSConfig.Enabled {
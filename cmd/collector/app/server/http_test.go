@@ -15,11 +15,15 @@
 package server
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,9 +32,11 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/jsonspans"
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -47,6 +53,50 @@ func TestFailToListenHTTP(t *testing.T) {
 	require.EqualError(t, err, "listen tcp: address -1: invalid port")
 }
 
+func TestHTTPServerIPFamily(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	server, err := StartHTTPServer(&HTTPServerParams{
+		HostPort: "[::1]:0",
+		Network:  "tcp4",
+		Logger:   logger,
+	})
+	assert.Nil(t, server)
+	require.ErrorContains(t, err, "listen tcp4: address ::1: no suitable address found")
+}
+
+func TestHTTPServerUnixSocket(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	socket := filepath.Join(t.TempDir(), "collector-http.sock")
+	mFact := metricstest.NewFactory(time.Hour)
+	defer mFact.Backend.Stop()
+	server, err := StartHTTPServer(&HTTPServerParams{
+		HostPort:          "unix://" + socket,
+		SocketPermissions: 0o660,
+		Handler:           handler.NewJaegerSpanHandler(logger, &mockSpanProcessor{}),
+		SamplingProvider:  &mockSamplingProvider{},
+		MetricsFactory:    mFact,
+		HealthCheck:       healthcheck.New(),
+		Logger:            logger,
+	})
+	require.NoError(t, err)
+	defer server.Close()
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	response, err := client.Post("http://localhost/api/traces", "application/x-thrift", nil)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
 func TestCreateTLSHTTPServerError(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	tlsCfg := tlscfg.Options{
@@ -90,6 +140,32 @@ func TestSpanCollectorHTTP(t *testing.T) {
 	defer server.Close()
 }
 
+func TestJSONSpansHTTP(t *testing.T) {
+	mFact := metricstest.NewFactory(time.Hour)
+	defer mFact.Backend.Stop()
+	logger := zap.NewNop()
+	converter, err := jsonspans.NewConverter(jsonspans.DefaultMapping())
+	require.NoError(t, err)
+	params := &HTTPServerParams{
+		Handler:          handler.NewJaegerSpanHandler(logger, &mockSpanProcessor{}),
+		SamplingProvider: &mockSamplingProvider{},
+		MetricsFactory:   mFact,
+		HealthCheck:      healthcheck.New(),
+		Logger:           logger,
+		JSONSpanHandler:  handler.NewJSONSpanHandler(converter, &mockSpanProcessor{}, &tenancy.Manager{}),
+	}
+
+	server := httptest.NewServer(nil)
+	defer server.Close()
+
+	serveHTTP(server.Config, server.Listener, params)
+
+	response, err := http.Post(server.URL+"/api/json/spans", "application/json", strings.NewReader("[]"))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+}
+
 func TestSpanCollectorHTTPS(t *testing.T) {
 	testCases := []struct {
 		name              string
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+)
+
+// The process tags reporting the metadata of a service.
+const (
+	ServiceDescriptionTagKey = "service.description"
+	ServiceTeamTagKey        = "service.team"
+	ServiceRepositoryTagKey  = "service.repository"
+	ServiceOnCallTagKey      = "service.oncall"
+)
+
+// CreateMetadataStore creates the service metadata store of the storage factory,
+// or returns nil if the storage does not support it.
+func CreateMetadataStore(storageFactory storage.Factory, logger *zap.Logger) metadatastore.Store {
+	metadataFactory, ok := storageFactory.(storage.MetadataStoreFactory)
+	if !ok {
+		logger.Info("Service metadata storage not supported by the factory")
+		return nil
+	}
+	store, err := metadataFactory.CreateMetadataStore()
+	if err != nil {
+		logger.Info("Service metadata storage not created", zap.String("reason", err.Error()))
+		return nil
+	}
+	return store
+}
+
+// serviceMetadataRecorder records the metadata of the services reported in the process tags
+// of their spans. The process tags only fill the fields missing in the stored metadata, so that
+// the metadata set with the query service API takes precedence.
+type serviceMetadataRecorder struct {
+	store  metadatastore.Store
+	logger *zap.Logger
+
+	mux sync.Mutex
+	// recorded holds the metadata last recorded from the tags per tenant and service,
+	// to only access the storage when it changes.
+	recorded map[string]metadatastore.ServiceMetadata
+}
+
+func newServiceMetadataRecorder(store metadatastore.Store, logger *zap.Logger) *serviceMetadataRecorder {
+	return &serviceMetadataRecorder{
+		store:    store,
+		logger:   logger,
+		recorded: make(map[string]metadatastore.ServiceMetadata),
+	}
+}
+
+func (r *serviceMetadataRecorder) recordSpan(span *model.Span, tenant string) {
+	fromTags, ok := processTagsMetadata(span.Process)
+	if !ok {
+		return
+	}
+	key := tenant + "|" + fromTags.ServiceName
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if recorded, ok := r.recorded[key]; ok && recorded == *fromTags {
+		return
+	}
+	ctx := tenancy.WithTenant(context.Background(), tenant)
+	stored, err := r.store.GetServiceMetadata(ctx, fromTags.ServiceName)
+	if errors.Is(err, metadatastore.ErrServiceMetadataNotFound) {
+		stored, err = &metadatastore.ServiceMetadata{ServiceName: fromTags.ServiceName}, nil
+	}
+	if err == nil && stored.Merge(fromTags) {
+		err = r.store.WriteServiceMetadata(ctx, stored)
+	}
+	if err != nil {
+		// not marked as recorded to retry with the next span
+		r.logger.Warn("Failed to record the service metadata", zap.String("service", fromTags.ServiceName), zap.Error(err))
+		return
+	}
+	r.recorded[key] = *fromTags
+}
+
+// processTagsMetadata returns the service metadata reported in the process tags, if any.
+func processTagsMetadata(process *model.Process) (*metadatastore.ServiceMetadata, bool) {
+	if process == nil || process.ServiceName == "" {
+		return nil, false
+	}
+	metadata := &metadatastore.ServiceMetadata{ServiceName: process.ServiceName}
+	found := false
+	for _, tag := range process.Tags {
+		var field *string
+		switch tag.Key {
+		case ServiceDescriptionTagKey:
+			field = &metadata.Description
+		case ServiceTeamTagKey:
+			field = &metadata.Team
+		case ServiceRepositoryTagKey:
+			field = &metadata.RepositoryURL
+		case ServiceOnCallTagKey:
+			field = &metadata.OnCallURL
+		default:
+			continue
+		}
+		*field = tag.AsString()
+		found = true
+	}
+	return metadata, found
+}
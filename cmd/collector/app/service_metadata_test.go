@@ -0,0 +1,93 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+	"github.com/jaegertracing/jaeger/storage/mocks"
+)
+
+// countingMetadataStore counts the reads of the store, failing them with err if set.
+type countingMetadataStore struct {
+	metadatastore.Store
+	reads int
+	err   error
+}
+
+func (s *countingMetadataStore) GetServiceMetadata(ctx context.Context, service string) (*metadatastore.ServiceMetadata, error) {
+	s.reads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.Store.GetServiceMetadata(ctx, service)
+}
+
+func metadataSpan(service string, tags ...model.KeyValue) *model.Span {
+	return &model.Span{Process: &model.Process{ServiceName: service, Tags: tags}}
+}
+
+func TestServiceMetadataRecorder(t *testing.T) {
+	store := &countingMetadataStore{Store: memory.NewMetadataStore()}
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	require.NoError(t, store.WriteServiceMetadata(ctx, &metadatastore.ServiceMetadata{ServiceName: "frontend", Team: "web"}))
+	r := newServiceMetadataRecorder(store, zap.NewNop())
+
+	span := metadataSpan("frontend",
+		model.String(ServiceTeamTagKey, "other"),
+		model.String(ServiceRepositoryTagKey, "https://git/frontend"),
+		model.String(ServiceOnCallTagKey, "https://oncall/web"),
+		model.String(ServiceDescriptionTagKey, "The web frontend"),
+		model.String("hostname", "host1"))
+	r.recordSpan(span, "acme")
+	r.recordSpan(span, "acme")
+	assert.Equal(t, 1, store.reads, "the storage is only read when the tags change")
+
+	metadata, err := store.Store.GetServiceMetadata(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, &metadatastore.ServiceMetadata{
+		ServiceName:   "frontend",
+		Description:   "The web frontend",
+		Team:          "web", // set with the API
+		RepositoryURL: "https://git/frontend",
+		OnCallURL:     "https://oncall/web",
+	}, metadata)
+
+	r.recordSpan(metadataSpan("billing", model.String(ServiceTeamTagKey, "payments")), "")
+	metadata, err = store.Store.GetServiceMetadata(context.Background(), "billing")
+	require.NoError(t, err)
+	assert.Equal(t, &metadatastore.ServiceMetadata{ServiceName: "billing", Team: "payments"}, metadata)
+
+	r.recordSpan(metadataSpan("no-tags", model.String("hostname", "host1")), "")
+	r.recordSpan(&model.Span{}, "")
+	_, err = store.Store.GetServiceMetadata(context.Background(), "no-tags")
+	require.ErrorIs(t, err, metadatastore.ErrServiceMetadataNotFound)
+}
+
+func TestServiceMetadataRecorderStorageError(t *testing.T) {
+	store := &countingMetadataStore{Store: memory.NewMetadataStore(), err: errors.New("storage error")}
+	r := newServiceMetadataRecorder(store, zap.NewNop())
+	span := metadataSpan("frontend", model.String(ServiceTeamTagKey, "web"))
+	r.recordSpan(span, "")
+	r.recordSpan(span, "")
+	assert.Equal(t, 2, store.reads, "the recording is retried")
+}
+
+func TestCreateMetadataStore(t *testing.T) {
+	assert.Nil(t, CreateMetadataStore(&mocks.Factory{}, zap.NewNop()))
+
+	factory := memory.NewFactory()
+	require.NoError(t, factory.Initialize(nil, zap.NewNop()))
+	assert.NotNil(t, CreateMetadataStore(factory, zap.NewNop()))
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// spanBatcher accumulates the spans of each tenant and writes them at once with a
+// spanstore.BatchWriter, when the batch is full or on flush. On a spanstore.BatchWriteError,
+// only the spans that it reports are failed.
+type spanBatcher struct {
+	writer  spanstore.BatchWriter
+	size    int
+	metrics *SpanProcessorMetrics
+	// onFailed is called with the spans of the batches which failed to be written, if not nil
+	onFailed func(span *model.Span, tenant string)
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	batches map[string][]*model.Span
+}
+
+func newSpanBatcher(
+	writer spanstore.BatchWriter,
+	size int,
+	metrics *SpanProcessorMetrics,
+	onFailed func(span *model.Span, tenant string),
+	logger *zap.Logger,
+) *spanBatcher {
+	return &spanBatcher{
+		writer:   writer,
+		size:     size,
+		metrics:  metrics,
+		onFailed: onFailed,
+		logger:   logger,
+		batches:  make(map[string][]*model.Span),
+	}
+}
+
+// add adds the span to the batch of the tenant, writing the batch if it is full.
+func (b *spanBatcher) add(span *model.Span, tenant string) {
+	b.mu.Lock()
+	batch := append(b.batches[tenant], span)
+	if len(batch) < b.size {
+		b.batches[tenant] = batch
+		b.mu.Unlock()
+		return
+	}
+	delete(b.batches, tenant)
+	b.mu.Unlock()
+	b.write(batch, tenant)
+}
+
+// flush writes the batches of all the tenants, even if they are not full.
+func (b *spanBatcher) flush() {
+	b.mu.Lock()
+	batches := b.batches
+	b.batches = make(map[string][]*model.Span)
+	b.mu.Unlock()
+	for tenant, batch := range batches {
+		b.write(batch, tenant)
+	}
+}
+
+func (b *spanBatcher) write(batch []*model.Span, tenant string) {
+	startTime := time.Now()
+	// as in saveSpan, the write is not bound to the inbound Context
+	ctx := tenancy.WithTenant(context.Background(), tenant)
+	var failed []*model.Span
+	if err := b.writer.WriteBatch(ctx, batch); err != nil {
+		failed = batch
+		var batchErr *spanstore.BatchWriteError
+		if errors.As(err, &batchErr) {
+			failed = batchErr.Failed
+		}
+		b.logger.Error("Failed to save spans", zap.Int("spans", len(failed)), zap.Error(err))
+	} else {
+		b.logger.Debug("Spans written to the storage by the collector", zap.Int("spans", len(batch)))
+	}
+	isFailed := make(map[*model.Span]bool, len(failed))
+	for _, span := range failed {
+		isFailed[span] = true
+		b.metrics.SavedErrBySvc.ReportServiceNameForSpan(span)
+		if b.onFailed != nil {
+			b.onFailed(span, tenant)
+		}
+	}
+	for _, span := range batch {
+		if !isFailed[span] {
+			b.metrics.SavedOkBySvc.ReportServiceNameForSpan(span)
+		}
+	}
+	b.metrics.SaveLatency.Record(time.Since(startTime))
+}
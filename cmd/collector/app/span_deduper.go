@@ -0,0 +1,65 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/cache"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(telemetery.Metric{
+		Name: "jaeger_collector_spans_duplicates_dropped_total",
+		Type: telemetery.Counter,
+		Help: "Exact duplicates of recently received spans dropped by the collector, e.g. because of client retries",
+	})
+}
+
+// spanDeduper detects the exact duplicates of the spans recently received, remembering
+// the trace ID, span ID and hash of the spans in an LRU cache. Spans with the same IDs
+// but different contents, like the client and server sides of a Zipkin span, are not
+// duplicates.
+//
+// The spans are only remembered once queued, so that the retries of the spans dropped
+// by a full queue are not dropped as duplicates. The duplicates received concurrently,
+// before the first one is queued, are kept.
+type spanDeduper struct {
+	seen              cache.Cache
+	duplicatesDropped metrics.Counter
+}
+
+func newSpanDeduper(cacheSize int, metricsFactory metrics.Factory) *spanDeduper {
+	return &spanDeduper{
+		seen:              cache.NewLRU(cacheSize),
+		duplicatesDropped: metricsFactory.Counter(metrics.Options{Name: "spans.duplicates-dropped"}),
+	}
+}
+
+// spanDedupeKey returns the key of the span in the cache, or false if it cannot be hashed,
+// in which case the span is kept.
+func spanDedupeKey(span *model.Span) (string, bool) {
+	hash, err := model.HashCode(span)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%s:%x", span.TraceID, span.SpanID, hash), true
+}
+
+// isDuplicate checks whether the span of the key was already queued.
+func (d *spanDeduper) isDuplicate(key string) bool {
+	if d.seen.Get(key) != nil {
+		d.duplicatesDropped.Inc(1)
+		return true
+	}
+	return false
+}
+
+// markSeen remembers the span of the key once it is queued.
+func (d *spanDeduper) markSeen(key string) {
+	d.seen.Put(key, struct{}{})
+}
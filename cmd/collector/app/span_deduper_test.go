@@ -0,0 +1,118 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanDeduper(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	deduper := newSpanDeduper(2, mb)
+
+	newSpan := func(spanID uint64, kind string) *model.Span {
+		return &model.Span{
+			TraceID: model.NewTraceID(0, 1),
+			SpanID:  model.NewSpanID(spanID),
+			Process: model.NewProcess("svc", nil),
+			Tags:    []model.KeyValue{model.String("span.kind", kind)},
+		}
+	}
+	isDuplicate := func(span *model.Span) bool {
+		key, ok := spanDedupeKey(span)
+		require.True(t, ok)
+		if deduper.isDuplicate(key) {
+			return true
+		}
+		deduper.markSeen(key)
+		return false
+	}
+	assert.False(t, isDuplicate(newSpan(1, "client")))
+	assert.True(t, isDuplicate(newSpan(1, "client")))
+	// same IDs, different contents
+	assert.False(t, isDuplicate(newSpan(1, "server")))
+	// evicts the client span from the cache
+	assert.False(t, isDuplicate(newSpan(2, "client")))
+	assert.False(t, isDuplicate(newSpan(1, "client")))
+
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "spans.duplicates-dropped", Value: 1})
+}
+
+func TestSpanProcessorDedupe(t *testing.T) {
+	w := &fakeSpanWriter{}
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	p := NewSpanProcessor(w, nil,
+		Options.ServiceMetrics(mb),
+		Options.QueueSize(10),
+		Options.DedupeCacheSize(100),
+	)
+
+	newSpan := func() *model.Span {
+		return &model.Span{
+			TraceID: model.NewTraceID(0, 1),
+			SpanID:  model.NewSpanID(1),
+			Process: model.NewProcess("svc", nil),
+		}
+	}
+	for i := 0; i < 2; i++ {
+		ok, err := p.ProcessSpans([]*model.Span{newSpan()}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true}, ok)
+	}
+	require.NoError(t, p.Close())
+
+	assert.Len(t, w.spans, 1)
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "spans.duplicates-dropped", Value: 1})
+}
+
+func TestSpanProcessorDedupeRetryAfterFullQueue(t *testing.T) {
+	w := &blockingWriter{}
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	p := NewSpanProcessor(w, nil,
+		Options.ServiceMetrics(mb),
+		Options.NumWorkers(1),
+		Options.QueueSize(1),
+		Options.DedupeCacheSize(100),
+	)
+
+	newSpan := func(spanID uint64) *model.Span {
+		return &model.Span{
+			TraceID: model.NewTraceID(0, 1),
+			SpanID:  model.NewSpanID(spanID),
+			Process: model.NewProcess("svc", nil),
+		}
+	}
+	process := func(spans ...*model.Span) []bool {
+		ok, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+		require.NoError(t, err)
+		return ok
+	}
+
+	// the first span blocks the worker, the second one fills the queue
+	w.Lock()
+	assert.Equal(t, []bool{true}, process(newSpan(1)))
+	assert.Eventually(t, func() bool { return w.inWriteSpan.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []bool{true, false}, process(newSpan(2), newSpan(3)))
+	w.Unlock()
+
+	// the retry of the span dropped by the full queue is not a duplicate
+	assert.Eventually(t, func() bool {
+		return process(newSpan(3))[0]
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []bool{true}, process(newSpan(3)))
+	require.NoError(t, p.Close())
+
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "spans.duplicates-dropped", Value: 1})
+}
@@ -21,9 +21,15 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/geoip"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/k8smetadata"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/rules"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer"
 	zs "github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer/zipkin"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wasm"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -37,6 +43,16 @@ type SpanHandlerBuilder struct {
 	Logger         *zap.Logger
 	MetricsFactory metrics.Factory
 	TenancyMgr     *tenancy.Manager
+	// WAL, when set, records the spans which failed to be written to the storage
+	WAL *wal.Writer
+	// WASMProcessor, when set, runs the spans through WebAssembly modules before they are saved
+	WASMProcessor *wasm.Processor
+	// SpanRules, when set, drops, keeps or modifies the spans before they are run through the WebAssembly modules
+	SpanRules *rules.Engine
+	// K8sMetadata, when set, adds the Kubernetes metadata of the pods reporting the spans to their process tags
+	K8sMetadata *k8smetadata.Enricher
+	// GeoIP, when set, adds the geolocation of the client IPs to the spans before the span rules are applied
+	GeoIP *geoip.Processor
 }
 
 // SpanHandlers holds instances to the span handlers built by the SpanHandlerBuilder
@@ -52,9 +68,7 @@ func (b *SpanHandlerBuilder) BuildSpanProcessor(additional ...ProcessSpan) proce
 	svcMetrics := b.metricsFactory()
 	hostMetrics := svcMetrics.Namespace(metrics.NSOptions{Tags: map[string]string{"host": hostname}})
 
-	return NewSpanProcessor(
-		b.SpanWriter,
-		additional,
+	opts := []Option{
 		Options.ServiceMetrics(svcMetrics),
 		Options.HostMetrics(hostMetrics),
 		Options.Logger(b.logger()),
@@ -65,7 +79,40 @@ func (b *SpanHandlerBuilder) BuildSpanProcessor(additional ...ProcessSpan) proce
 		Options.DynQueueSizeWarmup(uint(b.CollectorOpts.QueueSize)), // same as queue size for now
 		Options.DynQueueSizeMemory(b.CollectorOpts.DynQueueSizeMemory),
 		Options.SpanSizeMetricsEnabled(b.CollectorOpts.SpanSizeMetricsEnabled),
-	)
+		Options.BatchSize(b.CollectorOpts.BatchSize),
+		Options.BatchFlushInterval(b.CollectorOpts.BatchFlushInterval),
+		Options.WorkersAutoscaling(b.CollectorOpts.WorkersAutoscaling),
+		Options.SpanLimits(b.CollectorOpts.SpanLimits),
+		Options.DedupeCacheSize(b.CollectorOpts.DedupeCacheSize),
+	}
+	if b.WAL != nil {
+		logger := b.logger()
+		opts = append(opts, Options.OnFailedSpan(func(span *model.Span, tenant string) {
+			if err := b.WAL.Write(span, tenant); err != nil {
+				logger.Error("Failed to write the span to the WAL", zap.Error(err))
+			}
+		}))
+	}
+	if b.CollectorOpts.NormalizeErrorStatus {
+		opts = append(opts, Options.Sanitizer(sanitizer.NewErrorStatusSanitizer()))
+	}
+	if b.K8sMetadata != nil {
+		opts = append(opts, Options.EnrichSpans(b.K8sMetadata.Enrich))
+	}
+	var transformers []TransformSpan
+	if b.GeoIP != nil {
+		transformers = append(transformers, b.GeoIP.Process)
+	}
+	if b.SpanRules != nil {
+		transformers = append(transformers, b.SpanRules.Apply)
+	}
+	if b.WASMProcessor != nil {
+		transformers = append(transformers, b.WASMProcessor.Process)
+	}
+	if len(transformers) > 0 {
+		opts = append(opts, Options.TransformSpan(ChainedTransformSpan(transformers...)))
+	}
+	return NewSpanProcessor(b.SpanWriter, additional, opts...)
 }
 
 // BuildHandlers builds span handlers (Zipkin, Jaeger)
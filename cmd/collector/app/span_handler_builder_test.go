@@ -16,6 +16,9 @@
 package app
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,7 +26,11 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/rules"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
 	cmdFlags "github.com/jaegertracing/jaeger/cmd/internal/flags"
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -64,6 +71,101 @@ func TestNewSpanHandlerBuilder(t *testing.T) {
 	require.NoError(t, spanProcessor.Close())
 }
 
+func TestSpanHandlerBuilderWAL(t *testing.T) {
+	v, command := config.Viperize(cmdFlags.AddFlags, flags.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--collector.batch.size=1"}))
+	cOpts, err := new(flags.CollectorOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	cOpts.WAL.Dir = t.TempDir()
+
+	walWriter, err := wal.NewWriter(cOpts.WAL, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	builder := &SpanHandlerBuilder{
+		SpanWriter:    &fakeSpanWriter{err: errors.New("storage unavailable")},
+		CollectorOpts: cOpts,
+		TenancyMgr:    &tenancy.Manager{},
+		WAL:           walWriter,
+	}
+	spanProcessor := builder.BuildSpanProcessor()
+	_, err = spanProcessor.ProcessSpans([]*model.Span{{OperationName: "op", Process: &model.Process{ServiceName: "x"}}},
+		processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, Tenant: "acme"})
+	require.NoError(t, err)
+	require.NoError(t, spanProcessor.Close())
+	require.NoError(t, walWriter.Close())
+
+	segments, err := wal.Segments(cOpts.WAL.Dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	var operations []string
+	require.NoError(t, wal.ReadSegment(segments[0], func(span *model.Span, tenant string) error {
+		operations = append(operations, span.OperationName+"@"+tenant)
+		return nil
+	}))
+	assert.Equal(t, []string{"op@acme"}, operations)
+}
+
+func TestSpanHandlerBuilderSpanRules(t *testing.T) {
+	v, command := config.Viperize(cmdFlags.AddFlags, flags.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--collector.batch.size=1"}))
+	cOpts, err := new(flags.CollectorOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"name": "drop-debug", "when": "span.operation == 'debug'", "action": "drop"}]}`), 0o600))
+	spanRules, err := rules.NewEngine(path, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	defer spanRules.Close()
+	spanWriter := &fakeSpanWriter{}
+	builder := &SpanHandlerBuilder{
+		SpanWriter:    spanWriter,
+		CollectorOpts: cOpts,
+		TenancyMgr:    &tenancy.Manager{},
+		SpanRules:     spanRules,
+	}
+	spanProcessor := builder.BuildSpanProcessor()
+	_, err = spanProcessor.ProcessSpans([]*model.Span{
+		{OperationName: "debug", Process: &model.Process{ServiceName: "x"}},
+		{OperationName: "op", Process: &model.Process{ServiceName: "x"}},
+	}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	require.NoError(t, spanProcessor.Close())
+
+	require.Len(t, spanWriter.spans, 1)
+	assert.Equal(t, "op", spanWriter.spans[0].OperationName)
+}
+
 func TestDefaultSpanFilter(t *testing.T) {
 	assert.True(t, defaultSpanFilter(nil))
 }
+
+func TestSpanHandlerBuilderNormalizeErrorStatus(t *testing.T) {
+	v, command := config.Viperize(cmdFlags.AddFlags, flags.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--collector.batch.size=1", "--collector.normalize-error-status=true"}))
+	cOpts, err := new(flags.CollectorOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+
+	spanWriter := &fakeSpanWriter{}
+	builder := &SpanHandlerBuilder{
+		SpanWriter:    spanWriter,
+		CollectorOpts: cOpts,
+		TenancyMgr:    &tenancy.Manager{},
+	}
+	spanProcessor := builder.BuildSpanProcessor()
+	_, err = spanProcessor.ProcessSpans([]*model.Span{
+		{
+			OperationName: "op",
+			Process:       &model.Process{ServiceName: "x"},
+			Tags:          model.KeyValues{model.String("otel.status_code", "ERROR")},
+		},
+	}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	require.NoError(t, spanProcessor.Close())
+
+	require.Len(t, spanWriter.spans, 1)
+	errorTag, ok := model.KeyValues(spanWriter.spans[0].Tags).FindByKey("error")
+	require.True(t, ok)
+	assert.True(t, errorTag.Bool())
+	statusTag, ok := model.KeyValues(spanWriter.spans[0].Tags).FindByKey("status.code")
+	require.True(t, ok)
+	assert.Equal(t, "ERROR", statusTag.AsString())
+}
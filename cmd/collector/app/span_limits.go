@@ -0,0 +1,147 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(telemetery.Metric{
+		Name: "jaeger_collector_spans_truncated_total",
+		Type: telemetery.Counter,
+		Help: "Tags and logs dropped, and tag values truncated, by the span limits",
+		Labels: []telemetery.Label{
+			{Name: "limit", Values: []string{"max_tags", "max_tag_value_length", "max_logs", "max_process_tags"}},
+		},
+	})
+}
+
+type spanLimitsMetrics struct {
+	// TagsDropped counts the span tags dropped over the max tags limit
+	TagsDropped metrics.Counter `metric:"spans.truncated" tags:"limit=max_tags"`
+	// ValuesTruncated counts the tag and log field values truncated to the max length
+	ValuesTruncated metrics.Counter `metric:"spans.truncated" tags:"limit=max_tag_value_length"`
+	// LogsDropped counts the span logs dropped over the max logs limit
+	LogsDropped metrics.Counter `metric:"spans.truncated" tags:"limit=max_logs"`
+	// ProcessTagsDropped counts the process tags dropped over the max process tags limit
+	ProcessTagsDropped metrics.Counter `metric:"spans.truncated" tags:"limit=max_process_tags"`
+}
+
+// spanLimiter truncates the data of the spans over the limits, adding a warning to the span
+// so that the truncation is visible in the UI.
+type spanLimiter struct {
+	limits  flags.SpanLimits
+	metrics spanLimitsMetrics
+}
+
+func newSpanLimiter(limits flags.SpanLimits, metricsFactory metrics.Factory) *spanLimiter {
+	l := &spanLimiter{limits: limits}
+	metrics.MustInit(&l.metrics, metricsFactory, nil)
+	return l
+}
+
+func (l *spanLimiter) enabled() bool {
+	return l.limits != flags.SpanLimits{}
+}
+
+// limitSpans applies the limits to a batch of spans. It must be called before the spans
+// are queued, because spans of the batch may share the same Process.
+func (l *spanLimiter) limitSpans(spans []*model.Span) {
+	// the number of tags dropped from each process, to warn all the spans sharing it
+	processes := make(map[*model.Process]int)
+	for _, span := range spans {
+		l.limitSpan(span)
+		if span.Process == nil {
+			continue
+		}
+		dropped, ok := processes[span.Process]
+		if !ok {
+			dropped = l.limitProcess(span.Process)
+			processes[span.Process] = dropped
+		}
+		if dropped > 0 {
+			span.Warnings = append(span.Warnings,
+				fmt.Sprintf("%d process tags dropped by the collector over the limit of %d", dropped, l.limits.MaxProcessTags))
+		}
+	}
+}
+
+func (l *spanLimiter) limitSpan(span *model.Span) {
+	if limit := l.limits.MaxTags; limit > 0 && len(span.Tags) > limit {
+		dropped := len(span.Tags) - limit
+		span.Tags = span.Tags[:limit]
+		l.metrics.TagsDropped.Inc(int64(dropped))
+		span.Warnings = append(span.Warnings,
+			fmt.Sprintf("%d tags dropped by the collector over the limit of %d", dropped, limit))
+	}
+	if limit := l.limits.MaxLogs; limit > 0 && len(span.Logs) > limit {
+		dropped := len(span.Logs) - limit
+		span.Logs = span.Logs[:limit]
+		l.metrics.LogsDropped.Inc(int64(dropped))
+		span.Warnings = append(span.Warnings,
+			fmt.Sprintf("%d logs dropped by the collector over the limit of %d", dropped, limit))
+	}
+	truncated := l.truncateValues(span.Tags)
+	for _, log := range span.Logs {
+		truncated += l.truncateValues(log.Fields)
+	}
+	if truncated > 0 {
+		span.Warnings = append(span.Warnings,
+			fmt.Sprintf("%d tag values truncated by the collector to %d bytes", truncated, l.limits.MaxTagValueLength))
+	}
+}
+
+// limitProcess applies the limits to the process tags and returns the number of tags dropped.
+func (l *spanLimiter) limitProcess(process *model.Process) int {
+	var dropped int
+	if limit := l.limits.MaxProcessTags; limit > 0 && len(process.Tags) > limit {
+		dropped = len(process.Tags) - limit
+		process.Tags = process.Tags[:limit]
+		l.metrics.ProcessTagsDropped.Inc(int64(dropped))
+	}
+	l.truncateValues(process.Tags)
+	return dropped
+}
+
+// truncateValues truncates the string and binary values longer than the max length
+// and returns the number of values truncated.
+func (l *spanLimiter) truncateValues(tags []model.KeyValue) int {
+	limit := l.limits.MaxTagValueLength
+	if limit <= 0 {
+		return 0
+	}
+	var truncated int
+	for i := range tags {
+		tag := &tags[i]
+		switch tag.VType {
+		case model.StringType:
+			if len(tag.VStr) > limit {
+				tag.VStr = truncateString(tag.VStr, limit)
+				truncated++
+			}
+		case model.BinaryType:
+			if len(tag.VBinary) > limit {
+				tag.VBinary = tag.VBinary[:limit]
+				truncated++
+			}
+		}
+	}
+	l.metrics.ValuesTruncated.Inc(int64(truncated))
+	return truncated
+}
+
+// truncateString cuts the string to at most limit bytes without splitting a UTF-8 character.
+func truncateString(s string, limit int) string {
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func TestSpanLimiterDisabled(t *testing.T) {
+	assert.False(t, newSpanLimiter(flags.SpanLimits{}, metrics.NullFactory).enabled())
+	assert.True(t, newSpanLimiter(flags.SpanLimits{MaxLogs: 1}, metrics.NullFactory).enabled())
+}
+
+func TestSpanLimiterLimitSpans(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	limiter := newSpanLimiter(flags.SpanLimits{
+		MaxTags:           2,
+		MaxTagValueLength: 4,
+		MaxLogs:           1,
+		MaxProcessTags:    1,
+	}, mb)
+
+	process := model.NewProcess("svc", []model.KeyValue{
+		model.String("hostname", "localhost"),
+		model.String("ip", "127.0.0.1"),
+	})
+	spans := []*model.Span{
+		{
+			Process: process,
+			Tags: []model.KeyValue{
+				model.String("a", "abcdef"),
+				model.Binary("b", []byte{1, 2, 3, 4, 5}),
+				model.String("c", "c"),
+			},
+			Logs: []model.Log{
+				{Fields: []model.KeyValue{model.String("event", "éééé")}},
+				{Fields: []model.KeyValue{model.String("event", "dropped")}},
+			},
+		},
+		{Process: process, Tags: []model.KeyValue{model.Int64("d", 123456789)}},
+	}
+	limiter.limitSpans(spans)
+
+	assert.Equal(t, []model.KeyValue{
+		model.String("a", "abcd"),
+		model.Binary("b", []byte{1, 2, 3, 4}),
+	}, spans[0].Tags)
+	assert.Equal(t, []model.Log{
+		{Fields: []model.KeyValue{model.String("event", "éé")}},
+	}, spans[0].Logs)
+	assert.Equal(t, []model.KeyValue{model.String("hostname", "loca")}, process.Tags)
+	assert.Equal(t, []string{
+		"1 tags dropped by the collector over the limit of 2",
+		"1 logs dropped by the collector over the limit of 1",
+		"3 tag values truncated by the collector to 4 bytes",
+		"1 process tags dropped by the collector over the limit of 1",
+	}, spans[0].Warnings)
+	assert.Equal(t, []model.KeyValue{model.Int64("d", 123456789)}, spans[1].Tags)
+	assert.Equal(t, []string{
+		"1 process tags dropped by the collector over the limit of 1",
+	}, spans[1].Warnings)
+
+	// the process shared by the spans is counted once
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "spans.truncated|limit=max_tags", Value: 1},
+		metricstest.ExpectedMetric{Name: "spans.truncated|limit=max_tag_value_length", Value: 4},
+		metricstest.ExpectedMetric{Name: "spans.truncated|limit=max_logs", Value: 1},
+		metricstest.ExpectedMetric{Name: "spans.truncated|limit=max_process_tags", Value: 1},
+	)
+}
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "ab", truncateString("abc", 2))
+	assert.Equal(t, "é", truncateString("éé", 3))
+	assert.Equal(t, "", truncateString("é", 1))
+}
+
+func TestSpanProcessorSpanLimits(t *testing.T) {
+	w := &fakeSpanWriter{}
+	p := NewSpanProcessor(w, nil,
+		Options.CollectorTags(map[string]string{"collector": "tag"}),
+		Options.QueueSize(10),
+		Options.SpanLimits(flags.SpanLimits{MaxTags: 1, MaxProcessTags: 1}),
+	)
+	defer func() { require.NoError(t, p.Close()) }()
+
+	span := &model.Span{
+		Process: model.NewProcess("svc", []model.KeyValue{
+			model.String("a", "a"),
+			model.String("b", "b"),
+		}),
+		Tags: []model.KeyValue{model.String("c", "c"), model.String("d", "d")},
+	}
+	_, err := p.ProcessSpans([]*model.Span{span}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		w.spansLock.Lock()
+		defer w.spansLock.Unlock()
+		return len(w.spans) == 1
+	}, time.Second, time.Millisecond)
+
+	w.spansLock.Lock()
+	defer w.spansLock.Unlock()
+	// neither the collector tags nor the format tag count against the limits
+	assert.Equal(t, []model.KeyValue{model.String("a", "a"), model.String("collector", "tag")}, w.spans[0].Process.Tags)
+	assert.Equal(t, []model.KeyValue{
+		model.String("c", "c"),
+		model.String("internal.span.format", string(processor.JaegerSpanFormat)),
+	}, w.spans[0].Tags)
+	assert.Len(t, w.spans[0].Warnings, 2)
+}
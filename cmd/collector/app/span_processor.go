@@ -46,9 +46,17 @@ type spanProcessor struct {
 	preProcessSpans    ProcessSpans
 	filterSpan         FilterSpan             // filter is called before the sanitizer but after preProcessSpans
 	sanitizer          sanitizer.SanitizeSpan // sanitizer is called before processSpan
+	transformSpan      TransformSpan          // transformSpan is called after the sanitizer
+	enrichSpans        EnrichSpans            // enrichSpans is called before the collector tags are added
+	limiter            *spanLimiter           // limiter is nil when the spans have no size limits
+	deduper            *spanDeduper           // deduper is nil when the deduplication is disabled
+	autoscaler         *workersAutoscaler     // autoscaler is nil when the number of workers is static
 	processSpan        ProcessSpan
 	logger             *zap.Logger
 	spanWriter         spanstore.Writer
+	onFailedSpan       func(span *model.Span, tenant string)
+	batcher            *spanBatcher // batches the spans written when spanWriter is a spanstore.BatchWriter
+	batchFlushInterval time.Duration
 	reportBusy         bool
 	numWorkers         int
 	collectorTags      map[string]string
@@ -56,6 +64,7 @@ type spanProcessor struct {
 	dynQueueSizeMemory uint
 	bytesProcessed     atomic.Uint64
 	spansProcessed     atomic.Uint64
+	spansDropped       *atomic.Uint64
 	stopCh             chan struct{}
 }
 
@@ -80,10 +89,18 @@ func NewSpanProcessor(
 
 	sp.background(1*time.Second, sp.updateGauges)
 
+	if sp.batcher != nil {
+		sp.background(sp.batchFlushInterval, sp.batcher.flush)
+	}
+
 	if sp.dynQueueSizeMemory > 0 {
 		sp.background(1*time.Minute, sp.updateQueueSize)
 	}
 
+	if sp.autoscaler != nil {
+		sp.background(sp.autoscaler.options.Interval, sp.autoscaleWorkers)
+	}
+
 	return sp
 }
 
@@ -93,8 +110,10 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 		options.serviceMetrics,
 		options.hostMetrics,
 		options.extraFormatTypes)
+	spansDropped := new(atomic.Uint64)
 	droppedItemHandler := func(item any) {
 		handlerMetrics.SpansDropped.Inc(1)
+		spansDropped.Add(1)
 		if options.onDroppedSpan != nil {
 			options.onDroppedSpan(item.(*queueItem).span)
 		}
@@ -113,13 +132,33 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 		preProcessSpans:    options.preProcessSpans,
 		filterSpan:         options.spanFilter,
 		sanitizer:          sanitizer.NewChainedSanitizer(sanitizers...),
+		transformSpan:      options.transformSpan,
+		enrichSpans:        options.enrichSpans,
 		reportBusy:         options.reportBusy,
 		numWorkers:         options.numWorkers,
 		spanWriter:         spanWriter,
 		collectorTags:      options.collectorTags,
+		spansDropped:       spansDropped,
 		stopCh:             make(chan struct{}),
 		dynQueueSizeMemory: options.dynQueueSizeMemory,
 		dynQueueSizeWarmup: options.dynQueueSizeWarmup,
+		batchFlushInterval: options.batchFlushInterval,
+		onFailedSpan:       options.onFailedSpan,
+	}
+	if limiter := newSpanLimiter(options.spanLimits, options.serviceMetrics); limiter.enabled() {
+		sp.limiter = limiter
+	}
+	if options.dedupeCacheSize > 0 {
+		sp.deduper = newSpanDeduper(options.dedupeCacheSize, options.serviceMetrics)
+	}
+	if options.workersAutoscaling.Enabled {
+		sp.autoscaler = newWorkersAutoscaler(options.workersAutoscaling, options.hostMetrics, options.logger)
+		sp.numWorkers = sp.autoscaler.bound(sp.numWorkers)
+		// the write latencies are recorded by saveSpan and the batcher
+		handlerMetrics.SaveLatency = sp.autoscaler.observe(handlerMetrics.SaveLatency)
+	}
+	if batchWriter, ok := spanWriter.(spanstore.BatchWriter); ok && options.batchSize > 1 {
+		sp.batcher = newSpanBatcher(batchWriter, options.batchSize, handlerMetrics, options.onFailedSpan, options.logger)
 	}
 
 	processSpanFuncs := []ProcessSpan{options.preSave, sp.saveSpan}
@@ -142,6 +181,9 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 func (sp *spanProcessor) Close() error {
 	close(sp.stopCh)
 	sp.queue.Stop()
+	if sp.batcher != nil {
+		sp.batcher.flush()
+	}
 
 	return nil
 }
@@ -152,6 +194,10 @@ func (sp *spanProcessor) saveSpan(span *model.Span, tenant string) {
 		sp.metrics.SavedErrBySvc.ReportServiceNameForSpan(span)
 		return
 	}
+	if sp.batcher != nil {
+		sp.batcher.add(span, tenant)
+		return
+	}
 
 	startTime := time.Now()
 	// Since we save spans asynchronously from receiving them, we cannot reuse
@@ -161,6 +207,9 @@ func (sp *spanProcessor) saveSpan(span *model.Span, tenant string) {
 	if err := sp.spanWriter.WriteSpan(ctx, span); err != nil {
 		sp.logger.Error("Failed to save span", zap.Error(err))
 		sp.metrics.SavedErrBySvc.ReportServiceNameForSpan(span)
+		if sp.onFailedSpan != nil {
+			sp.onFailedSpan(span, tenant)
+		}
 	} else {
 		sp.logger.Debug("Span written to the storage by the collector",
 			zap.Stringer("trace-id", span.TraceID), zap.Stringer("span-id", span.SpanID))
@@ -179,6 +228,12 @@ func (sp *spanProcessor) ProcessSpans(mSpans []*model.Span, options processor.Sp
 	sp.metrics.BatchSize.Update(int64(len(mSpans)))
 	retMe := make([]bool, len(mSpans))
 
+	// The limits are applied before the collector tags are added, so that they are never dropped.
+	if sp.limiter != nil {
+		sp.limiter.limitSpans(mSpans)
+	}
+	sp.enrichSpans(mSpans, options.ClientIP)
+
 	// Note: this is not the ideal place to do this because collector tags are added to Process.Tags,
 	// and Process can be shared between different spans in the batch, but we no longer know that,
 	// the relation is lost upstream and it's impossible in Go to dedupe pointers. But at least here
@@ -199,7 +254,9 @@ func (sp *spanProcessor) ProcessSpans(mSpans []*model.Span, options processor.Sp
 }
 
 func (sp *spanProcessor) processItemFromQueue(item *queueItem) {
-	sp.processSpan(sp.sanitizer(item.span), item.tenant)
+	if span := sp.transformSpan(sp.sanitizer(item.span)); span != nil {
+		sp.processSpan(span, item.tenant)
+	}
 	sp.metrics.InQueueLatency.Record(time.Since(item.queuedTime))
 }
 
@@ -230,6 +287,15 @@ func (sp *spanProcessor) enqueueSpan(span *model.Span, originalFormat processor.
 	spanCounts := sp.metrics.GetCountsForFormat(originalFormat, transport)
 	spanCounts.ReceivedBySvc.ReportServiceNameForSpan(span)
 
+	// the key is computed before the span is modified
+	dedupeKey, dedupe := "", false
+	if sp.deduper != nil {
+		dedupeKey, dedupe = spanDedupeKey(span)
+		if dedupe && sp.deduper.isDuplicate(dedupeKey) {
+			return true // as in "not dropped", the span was already received
+		}
+	}
+
 	if !sp.filterSpan(span) {
 		spanCounts.RejectedBySvc.ReportServiceNameForSpan(span)
 		return true // as in "not dropped", because it's actively rejected
@@ -243,7 +309,13 @@ func (sp *spanProcessor) enqueueSpan(span *model.Span, originalFormat processor.
 		span:       span,
 		tenant:     tenant,
 	}
-	return sp.queue.Produce(item)
+	if !sp.queue.Produce(item) {
+		return false
+	}
+	if dedupe {
+		sp.deduper.markSeen(dedupeKey)
+	}
+	return true
 }
 
 func (sp *spanProcessor) background(reportPeriod time.Duration, callback func()) {
@@ -304,8 +376,55 @@ func (sp *spanProcessor) updateQueueSize() {
 	}
 }
 
+// resize changes the size of the queue and the number of workers consuming from it.
+// The queue size is left to the dynamic queue sizing when it is enabled, and the number
+// of workers is kept within the bounds of the workers autoscaling.
+func (sp *spanProcessor) resize(queueSize int, numWorkers int) {
+	sp.queueResizeMu.Lock()
+	defer sp.queueResizeMu.Unlock()
+	if sp.dynQueueSizeMemory > 0 {
+		queueSize = sp.queue.Capacity()
+	}
+	if sp.autoscaler != nil {
+		numWorkers = sp.autoscaler.bound(numWorkers)
+	}
+	sp.queue.ResizeWithWorkers(queueSize, numWorkers)
+	sp.numWorkers = numWorkers
+}
+
+// autoscaleWorkers applies the number of workers decided by the autoscaler.
+func (sp *spanProcessor) autoscaleWorkers() {
+	sp.queueResizeMu.Lock()
+	defer sp.queueResizeMu.Unlock()
+	workers := sp.autoscaler.nextWorkers(sp.numWorkers, sp.queue.Size(), sp.queue.Capacity())
+	if workers != sp.numWorkers {
+		sp.queue.ResizeWithWorkers(sp.queue.Capacity(), workers)
+		sp.numWorkers = workers
+	}
+}
+
 func (sp *spanProcessor) updateGauges() {
 	sp.metrics.SpansBytes.Update(int64(sp.bytesProcessed.Load()))
 	sp.metrics.QueueLength.Update(int64(sp.queue.Size()))
 	sp.metrics.QueueCapacity.Update(int64(sp.queue.Capacity()))
 }
+
+// QueueStatus is the status of the queue of the spans waiting to be written to the storage.
+type QueueStatus struct {
+	Length       int    `json:"length"`
+	Capacity     int    `json:"capacity"`
+	Workers      int    `json:"workers"`
+	SpansDropped uint64 `json:"spans_dropped"`
+}
+
+func (sp *spanProcessor) queueStatus() QueueStatus {
+	sp.queueResizeMu.Lock()
+	numWorkers := sp.numWorkers
+	sp.queueResizeMu.Unlock()
+	return QueueStatus{
+		Length:       sp.queue.Size(),
+		Capacity:     sp.queue.Capacity(),
+		Workers:      numWorkers,
+		SpansDropped: sp.spansDropped.Load(),
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	zc "github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
 )
@@ -716,4 +717,228 @@ func TestSpanProcessorWithOnDroppedSpanOption(t *testing.T) {
 	}, opts)
 	require.EqualError(t, err, processor.ErrBusy.Error())
 	assert.Equal(t, []string{"op3"}, droppedOperations)
+	assert.Equal(t, QueueStatus{Length: 1, Capacity: 1, Workers: 1, SpansDropped: 1}, p.queueStatus())
+}
+
+type fakeBatchWriter struct {
+	fakeSpanWriter
+	batches [][]*model.Span
+}
+
+func (w *fakeBatchWriter) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	w.spansLock.Lock()
+	w.batches = append(w.batches, spans)
+	w.spansLock.Unlock()
+	for _, span := range spans {
+		w.WriteSpan(ctx, span)
+	}
+	return w.err
+}
+
+func (w *fakeBatchWriter) batchSizes() []int {
+	w.spansLock.Lock()
+	defer w.spansLock.Unlock()
+	var sizes []int
+	for _, batch := range w.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestSpanProcessorBatchWriter(t *testing.T) {
+	w := &fakeBatchWriter{}
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	p := NewSpanProcessor(w,
+		nil,
+		Options.ServiceMetrics(mb.Namespace(metrics.NSOptions{Name: "service"})),
+		Options.NumWorkers(1),
+		Options.QueueSize(10),
+		Options.BatchSize(2),
+		Options.BatchFlushInterval(time.Hour),
+	).(*spanProcessor)
+
+	spans := []*model.Span{
+		{Process: &model.Process{ServiceName: "x"}},
+		{Process: &model.Process{ServiceName: "x"}},
+		{Process: &model.Process{ServiceName: "x"}},
+	}
+	_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, Tenant: "acme"})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(w.batchSizes()) == 1
+	}, time.Second, time.Millisecond)
+
+	// the incomplete batch is written on close
+	require.NoError(t, p.Close())
+	assert.Equal(t, []int{2, 1}, w.batchSizes())
+	assert.Equal(t, map[string]bool{"acme": true}, w.tenants)
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "service.spans.saved-by-svc|debug=false|result=ok|svc=x", Value: 3,
+	})
+}
+
+func TestSpanProcessorBatchWriterFlushInterval(t *testing.T) {
+	w := &fakeBatchWriter{}
+	w.err = fmt.Errorf("some-error")
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	p := NewSpanProcessor(w,
+		nil,
+		Options.ServiceMetrics(mb.Namespace(metrics.NSOptions{Name: "service"})),
+		Options.QueueSize(10),
+		Options.BatchSize(10),
+		Options.BatchFlushInterval(time.Millisecond),
+	).(*spanProcessor)
+	defer p.Close()
+
+	_, err := p.ProcessSpans([]*model.Span{{Process: &model.Process{ServiceName: "x"}}},
+		processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(w.batchSizes()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		counters, _ := mb.Snapshot()
+		return counters["service.spans.saved-by-svc|debug=false|result=err|svc=x"] == 1
+	}, time.Second, time.Millisecond)
+}
+
+// partialBatchWriter fails to write the spans of the operation "fail" of the batches.
+type partialBatchWriter struct {
+	fakeBatchWriter
+}
+
+func (w *partialBatchWriter) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	w.fakeBatchWriter.WriteBatch(ctx, spans)
+	var failed []*model.Span
+	for _, span := range spans {
+		if span.OperationName == "fail" {
+			failed = append(failed, span)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &spanstore.BatchWriteError{Failed: failed, Err: fmt.Errorf("some-error")}
+}
+
+func TestSpanProcessorBatchWriterPartialFailure(t *testing.T) {
+	w := &partialBatchWriter{}
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	var failedLock sync.Mutex
+	var failed []string
+	p := NewSpanProcessor(w,
+		nil,
+		Options.ServiceMetrics(mb.Namespace(metrics.NSOptions{Name: "service"})),
+		Options.QueueSize(10),
+		Options.BatchSize(3),
+		Options.BatchFlushInterval(time.Hour),
+		Options.OnFailedSpan(func(span *model.Span, _ string) {
+			failedLock.Lock()
+			defer failedLock.Unlock()
+			failed = append(failed, span.OperationName)
+		}),
+	).(*spanProcessor)
+
+	_, err := p.ProcessSpans([]*model.Span{
+		{OperationName: "ok", Process: &model.Process{ServiceName: "x"}},
+		{OperationName: "fail", Process: &model.Process{ServiceName: "x"}},
+		{OperationName: "ok", Process: &model.Process{ServiceName: "x"}},
+	}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+	assert.Equal(t, []int{3}, w.batchSizes())
+	// only the spans reported by the error are failed
+	assert.Equal(t, []string{"fail"}, failed)
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "service.spans.saved-by-svc|debug=false|result=ok|svc=x", Value: 2},
+		metricstest.ExpectedMetric{Name: "service.spans.saved-by-svc|debug=false|result=err|svc=x", Value: 1},
+	)
+}
+
+func TestSpanProcessorOnFailedSpan(t *testing.T) {
+	testCases := []struct {
+		name       string
+		spanWriter spanstore.Writer
+	}{
+		{name: "span writer", spanWriter: &fakeSpanWriter{err: fmt.Errorf("some-error")}},
+		{name: "batch writer", spanWriter: &fakeBatchWriter{fakeSpanWriter: fakeSpanWriter{err: fmt.Errorf("some-error")}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var failedLock sync.Mutex
+			var failed []string
+			p := NewSpanProcessor(tc.spanWriter,
+				nil,
+				Options.QueueSize(10),
+				Options.BatchSize(2),
+				Options.BatchFlushInterval(time.Hour),
+				Options.OnFailedSpan(func(span *model.Span, tenant string) {
+					failedLock.Lock()
+					defer failedLock.Unlock()
+					failed = append(failed, span.OperationName+"@"+tenant)
+				}),
+			).(*spanProcessor)
+
+			_, err := p.ProcessSpans([]*model.Span{
+				{OperationName: "op1", Process: &model.Process{ServiceName: "x"}},
+				{OperationName: "op2", Process: &model.Process{ServiceName: "x"}},
+			}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, Tenant: "acme"})
+			require.NoError(t, err)
+			require.NoError(t, p.Close())
+			assert.ElementsMatch(t, []string{"op1@acme", "op2@acme"}, failed)
+		})
+	}
+}
+
+func TestSpanProcessorBatchSizeOne(t *testing.T) {
+	w := &fakeBatchWriter{}
+	p := NewSpanProcessor(w, nil, Options.QueueSize(1), Options.BatchSize(1)).(*spanProcessor)
+	assert.Nil(t, p.batcher)
+	_, err := p.ProcessSpans([]*model.Span{{}}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+	assert.Empty(t, w.batchSizes())
+	assert.Len(t, w.spans, 1)
+}
+
+func TestSpanProcessorEnrichSpans(t *testing.T) {
+	w := &fakeSpanWriter{}
+	enrich := func(spans []*model.Span, clientIP string) {
+		for _, span := range spans {
+			span.Process.Tags = append(span.Process.Tags, model.String("client", clientIP))
+		}
+	}
+	p := NewSpanProcessor(w, nil, Options.QueueSize(1), Options.EnrichSpans(enrich),
+		Options.CollectorTags(map[string]string{"collector": "c1"}))
+	_, err := p.ProcessSpans([]*model.Span{{OperationName: "op", Process: &model.Process{ServiceName: "x"}}},
+		processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, ClientIP: "10.0.0.1"})
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+
+	require.Len(t, w.spans, 1)
+	assert.Equal(t, []model.KeyValue{model.String("client", "10.0.0.1"), model.String("collector", "c1")}, w.spans[0].Process.Tags)
+}
+
+func TestSpanProcessorTransformSpan(t *testing.T) {
+	w := &fakeSpanWriter{}
+	transform := func(span *model.Span) *model.Span {
+		if span.OperationName == "drop" {
+			return nil
+		}
+		return &model.Span{OperationName: "transformed-" + span.OperationName, Process: span.Process}
+	}
+	p := NewSpanProcessor(w, nil, Options.QueueSize(2), Options.TransformSpan(transform))
+	res, err := p.ProcessSpans([]*model.Span{
+		{OperationName: "keep", Process: &model.Process{ServiceName: "x"}},
+		{OperationName: "drop", Process: &model.Process{ServiceName: "x"}},
+	}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, res)
+	require.NoError(t, p.Close())
+
+	require.Len(t, w.spans, 1)
+	assert.Equal(t, "transformed-keep", w.spans[0].OperationName)
 }
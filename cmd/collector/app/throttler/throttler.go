@@ -0,0 +1,149 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package throttler grants credits to the SDKs for the traces forced by their clients, e.g. with
+// the debug flag, as the throttler of the agent used to, so that they cannot overwhelm the pipeline.
+//
+// The credits of each operation of a service accrue at CreditsPerSecond up to MaxBalance, and are
+// withdrawn by the instances of the service asking for them, each credit allowing an SDK to sample
+// one forced trace. The credits are granted by each collector independently, so the rate of the
+// forced traces of an operation is up to CreditsPerSecond times the number of collectors.
+package throttler
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/throttling"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_throttling_requests_total",
+			Type: telemetery.Counter,
+			Help: "Requests of credits for the traces forced by the clients of the SDKs",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_throttling_credits_total",
+			Type: telemetery.Counter,
+			Help: "Credits granted to the SDKs, each allowing to sample one trace forced by a client",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_throttling_untracked_operations_total",
+			Type: telemetery.Counter,
+			Help: "Operations granted no credits because their service, or the collector, already tracks the maximum number of operations",
+		},
+	)
+}
+
+// Options configures the credits granted to the SDKs.
+type Options struct {
+	// CreditsPerSecond is the rate at which the credits of an operation of a service accrue
+	CreditsPerSecond float64
+	// MaxBalance is the maximum number of credits of an operation
+	MaxBalance float64
+	// MaxOperations is the maximum number of operations tracked for a service
+	MaxOperations int
+	// MaxServices is the maximum number of services tracked
+	MaxServices int
+}
+
+// Enabled returns whether credits are granted.
+func (o Options) Enabled() bool {
+	return o.CreditsPerSecond > 0
+}
+
+type throttlerMetrics struct {
+	// Requests counts the requests of credits
+	Requests metrics.Counter `metric:"throttling.requests"`
+	// Credits counts the credits granted
+	Credits metrics.Counter `metric:"throttling.credits"`
+	// UntrackedOperations counts the operations granted no credits because of MaxOperations or MaxServices
+	UntrackedOperations metrics.Counter `metric:"throttling.untracked_operations"`
+}
+
+type balance struct {
+	credits  float64
+	lastTick time.Time
+}
+
+// Throttler implements the throttling.ThrottlingServiceServer gRPC API.
+type Throttler struct {
+	options Options
+	metrics throttlerMetrics
+	now     func() time.Time
+
+	mu       sync.Mutex
+	services map[string]map[string]*balance
+}
+
+var _ throttling.ThrottlingServiceServer = (*Throttler)(nil)
+
+// NewThrottler creates a Throttler granting credits according to options.
+func NewThrottler(options Options, metricsFactory metrics.Factory) *Throttler {
+	t := &Throttler{
+		options:  options,
+		now:      time.Now,
+		services: make(map[string]map[string]*balance),
+	}
+	metrics.MustInit(&t.metrics, metricsFactory, nil)
+	return t
+}
+
+// GetCredits withdraws the whole credits accrued by the operations of the request, which start with MaxBalance.
+func (t *Throttler) GetCredits(_ context.Context, request *throttling.GetCreditsRequest) (*throttling.GetCreditsResponse, error) {
+	if request.ServiceName == "" {
+		return nil, status.Error(codes.InvalidArgument, "the service name is required")
+	}
+	t.metrics.Requests.Inc(1)
+	response := &throttling.GetCreditsResponse{
+		Balances: make([]*throttling.OperationBalance, 0, len(request.Operations)),
+	}
+	now := t.now()
+	var granted, untracked int64
+
+	t.mu.Lock()
+	operations := t.operations(request.ServiceName)
+	for _, operation := range request.Operations {
+		b, ok := operations[operation]
+		if !ok && operations != nil && len(operations) < t.options.MaxOperations {
+			b = &balance{credits: t.options.MaxBalance, lastTick: now}
+			operations[operation] = b
+		}
+		if b == nil {
+			untracked++
+			response.Balances = append(response.Balances, &throttling.OperationBalance{Operation: operation})
+			continue
+		}
+		b.credits = math.Min(t.options.MaxBalance, b.credits+now.Sub(b.lastTick).Seconds()*t.options.CreditsPerSecond)
+		b.lastTick = now
+		// the fraction of a credit is kept, the SDKs spending whole credits
+		credits := math.Floor(b.credits)
+		b.credits -= credits
+		granted += int64(credits)
+		response.Balances = append(response.Balances, &throttling.OperationBalance{Operation: operation, Balance: credits})
+	}
+	t.mu.Unlock()
+
+	t.metrics.Credits.Inc(granted)
+	t.metrics.UntrackedOperations.Inc(untracked)
+	return response, nil
+}
+
+// operations returns the balances of the operations of the service, or nil if MaxServices are already tracked.
+func (t *Throttler) operations(service string) map[string]*balance {
+	operations, ok := t.services[service]
+	if !ok && len(t.services) < t.options.MaxServices {
+		operations = make(map[string]*balance)
+		t.services[service] = operations
+	}
+	return operations
+}
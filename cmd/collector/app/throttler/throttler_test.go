@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package throttler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/throttling"
+)
+
+func getCredits(t *testing.T, throttler *Throttler, service string, operations ...string) []*throttling.OperationBalance {
+	response, err := throttler.GetCredits(context.Background(), &throttling.GetCreditsRequest{
+		ServiceName: service,
+		Operations:  operations,
+	})
+	require.NoError(t, err)
+	return response.Balances
+}
+
+func TestOptionsEnabled(t *testing.T) {
+	assert.False(t, Options{}.Enabled())
+	assert.True(t, Options{CreditsPerSecond: 1}.Enabled())
+}
+
+func TestGetCredits(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	throttler := NewThrottler(Options{CreditsPerSecond: 0.5, MaxBalance: 2, MaxOperations: 10, MaxServices: 10}, mb)
+	now := time.Now()
+	throttler.now = func() time.Time { return now }
+
+	// the operations start with the maximum balance
+	assert.Equal(t, []*throttling.OperationBalance{
+		{Operation: "GET /", Balance: 2},
+		{Operation: "POST /", Balance: 2},
+	}, getCredits(t, throttler, "frontend", "GET /", "POST /"))
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 0}}, getCredits(t, throttler, "frontend", "GET /"))
+
+	// the fraction of a credit is kept for the next request
+	now = now.Add(3 * time.Second)
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 1}}, getCredits(t, throttler, "frontend", "GET /"))
+	now = now.Add(time.Second)
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 1}}, getCredits(t, throttler, "frontend", "GET /"))
+
+	// the credits do not accrue beyond the maximum balance
+	now = now.Add(time.Minute)
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 2}}, getCredits(t, throttler, "frontend", "GET /"))
+
+	// the services have their own credits
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 2}}, getCredits(t, throttler, "billing", "GET /"))
+
+	counters, _ := mb.Snapshot()
+	assert.Equal(t, int64(6), counters["throttling.requests"])
+	assert.Equal(t, int64(10), counters["throttling.credits"])
+}
+
+func TestGetCreditsUntracked(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	throttler := NewThrottler(Options{CreditsPerSecond: 1, MaxBalance: 1, MaxOperations: 1, MaxServices: 1}, mb)
+
+	assert.Equal(t, []*throttling.OperationBalance{
+		{Operation: "GET /", Balance: 1},
+		{Operation: "POST /", Balance: 0},
+	}, getCredits(t, throttler, "frontend", "GET /", "POST /"))
+	assert.Equal(t, []*throttling.OperationBalance{{Operation: "GET /", Balance: 0}}, getCredits(t, throttler, "billing", "GET /"))
+
+	counters, _ := mb.Snapshot()
+	assert.Equal(t, int64(2), counters["throttling.untracked_operations"])
+}
+
+func TestGetCreditsWithoutService(t *testing.T) {
+	throttler := NewThrottler(Options{CreditsPerSecond: 1, MaxBalance: 1, MaxOperations: 1, MaxServices: 1}, metrics.NullFactory)
+	_, err := throttler.GetCredits(context.Background(), &throttling.GetCreditsRequest{Operations: []string{"GET /"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
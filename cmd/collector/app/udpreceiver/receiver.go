@@ -0,0 +1,158 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package udpreceiver receives the spans sent in Thrift over UDP by the legacy clients, like
+// jaeger-agent, so that the agents can be decommissioned without changing the clients, which
+// are pointed at the collectors instead.
+package udpreceiver
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/processors"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/servers"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/servers/thriftudp"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+	agentThrift "github.com/jaegertracing/jaeger/thrift-gen/agent"
+)
+
+func init() {
+	labels := []telemetery.Label{
+		{Name: "model", Values: []string{"jaeger", "zipkin"}},
+		{Name: "protocol", Values: []string{"compact", "binary"}},
+	}
+	telemetery.Register(
+		telemetery.Metric{
+			Name:   "jaeger_collector_thrift_udp_server_packets_processed_total",
+			Type:   telemetery.Counter,
+			Help:   "UDP packets of Thrift spans received by the collector",
+			Labels: labels,
+		},
+		telemetery.Metric{
+			Name:   "jaeger_collector_thrift_udp_server_packets_dropped_total",
+			Type:   telemetery.Counter,
+			Help:   "UDP packets of Thrift spans dropped because the queue of the receiver was full",
+			Labels: labels,
+		},
+		telemetery.Metric{
+			Name:   "jaeger_collector_thrift_udp_t_processor_handler_errors_total",
+			Type:   telemetery.Counter,
+			Help:   "UDP packets of Thrift spans which failed to be decoded or processed",
+			Labels: labels,
+		},
+	)
+}
+
+// ServerOptions configures a UDP receiver.
+type ServerOptions struct {
+	// HostPort is the address of the receiver, which is disabled if it is empty
+	HostPort string
+	// Network is the Go UDP network of the receiver, e.g. udp4 or udp6, "udp" if empty
+	Network string
+	// Workers is the number of workers decoding the packets
+	Workers int
+	// QueueSize is the number of packets waiting for a worker before new packets are dropped
+	QueueSize int
+	// MaxPacketSize is the maximum size of the packets
+	MaxPacketSize int
+	// SocketBufferSize is the size of the receive buffer of the socket, 0 keeping the system default
+	SocketBufferSize int
+}
+
+// Options configures the UDP receivers of the models and the Thrift protocols of the agent.
+type Options struct {
+	JaegerCompact ServerOptions
+	JaegerBinary  ServerOptions
+	ZipkinCompact ServerOptions
+}
+
+// Enabled returns whether one of the receivers is enabled.
+func (o Options) Enabled() bool {
+	return o.JaegerCompact.HostPort != "" || o.JaegerBinary.HostPort != "" || o.ZipkinCompact.HostPort != ""
+}
+
+type receiver struct {
+	processor *processors.ThriftProcessor
+	addr      net.Addr
+}
+
+// Receivers are the started UDP receivers.
+type Receivers struct {
+	receivers []receiver
+}
+
+// StartReceivers starts the enabled receivers, passing the batches of spans to handler.
+func StartReceivers(options Options, handler agentThrift.Agent, metricsFactory metrics.Factory, logger *zap.Logger) (*Receivers, error) {
+	endpoints := []struct {
+		model    string
+		protocol string
+		factory  thrift.TProtocolFactory
+		options  ServerOptions
+	}{
+		{model: "jaeger", protocol: "compact", factory: thrift.NewTCompactProtocolFactoryConf(&thrift.TConfiguration{}), options: options.JaegerCompact},
+		{model: "jaeger", protocol: "binary", factory: thrift.NewTBinaryProtocolFactoryConf(&thrift.TConfiguration{}), options: options.JaegerBinary},
+		{model: "zipkin", protocol: "compact", factory: thrift.NewTCompactProtocolFactoryConf(&thrift.TConfiguration{}), options: options.ZipkinCompact},
+	}
+	r := &Receivers{}
+	agentProcessor := agentThrift.NewAgentProcessor(handler)
+	for _, endpoint := range endpoints {
+		if endpoint.options.HostPort == "" {
+			continue
+		}
+		mFactory := metricsFactory.Namespace(metrics.NSOptions{Tags: map[string]string{
+			"model":    endpoint.model,
+			"protocol": endpoint.protocol,
+		}})
+		rcv, err := startReceiver(endpoint.options, endpoint.factory, agentProcessor, mFactory, logger)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("cannot start the UDP receiver of %s spans in Thrift %s: %w", endpoint.model, endpoint.protocol, err)
+		}
+		logger.Info("Listening for Thrift spans over UDP",
+			zap.String("model", endpoint.model), zap.String("protocol", endpoint.protocol), zap.Stringer("addr", rcv.addr))
+		r.receivers = append(r.receivers, rcv)
+	}
+	return r, nil
+}
+
+func startReceiver(options ServerOptions, factory thrift.TProtocolFactory, handler processors.AgentProcessor, mFactory metrics.Factory, logger *zap.Logger) (receiver, error) {
+	network := options.Network
+	if network == "" {
+		network = "udp"
+	}
+	transport, err := thriftudp.NewTUDPServerTransportWithNetwork(network, options.HostPort)
+	if err != nil {
+		return receiver{}, fmt.Errorf("cannot create UDPServerTransport: %w", err)
+	}
+	if options.SocketBufferSize != 0 {
+		if err := transport.SetSocketBufferSize(options.SocketBufferSize); err != nil {
+			transport.Close()
+			return receiver{}, fmt.Errorf("cannot set UDP socket buffer size: %w", err)
+		}
+	}
+	server, err := servers.NewTBufferedServer(transport, options.QueueSize, options.MaxPacketSize, mFactory)
+	if err != nil {
+		transport.Close()
+		return receiver{}, err
+	}
+	processor, err := processors.NewThriftProcessor(server, options.Workers, mFactory, factory, handler, logger)
+	if err != nil {
+		transport.Close()
+		return receiver{}, err
+	}
+	go processor.Serve()
+	return receiver{processor: processor, addr: transport.Addr()}, nil
+}
+
+// Close stops the receivers, once the packets they received are processed.
+func (r *Receivers) Close() {
+	for _, rcv := range r.receivers {
+		rcv.processor.Stop()
+	}
+	r.receivers = nil
+}
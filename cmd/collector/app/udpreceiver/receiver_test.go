@@ -0,0 +1,108 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package udpreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/testutils"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+type fakeHandler struct {
+	mu            sync.Mutex
+	batches       []*jaeger.Batch
+	zipkinBatches [][]*zipkincore.Span
+}
+
+func (h *fakeHandler) EmitBatch(_ context.Context, batch *jaeger.Batch) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.batches = append(h.batches, batch)
+	return nil
+}
+
+func (h *fakeHandler) EmitZipkinBatch(_ context.Context, spans []*zipkincore.Span) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.zipkinBatches = append(h.zipkinBatches, spans)
+	return nil
+}
+
+func (h *fakeHandler) received() (int, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.batches), len(h.zipkinBatches)
+}
+
+var testServerOptions = ServerOptions{HostPort: "127.0.0.1:0", Workers: 1, QueueSize: 10, MaxPacketSize: 65000}
+
+func TestOptionsEnabled(t *testing.T) {
+	assert.False(t, Options{}.Enabled())
+	assert.True(t, Options{JaegerBinary: testServerOptions}.Enabled())
+}
+
+func TestReceivers(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	handler := &fakeHandler{}
+	r, err := StartReceivers(Options{
+		JaegerCompact: testServerOptions,
+		JaegerBinary:  testServerOptions,
+		ZipkinCompact: testServerOptions,
+	}, handler, mb, zap.NewNop())
+	require.NoError(t, err)
+	defer r.Close()
+	require.Len(t, r.receivers, 3)
+
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "legacy-client"},
+		Spans:   []*jaeger.Span{{TraceIdLow: 1, SpanId: 2, OperationName: "op"}},
+	}
+	compact, compactCloser, err := testutils.NewJaegerThriftUDPClient(r.receivers[0].addr.String(), thrift.NewTCompactProtocolFactoryConf(&thrift.TConfiguration{}))
+	require.NoError(t, err)
+	defer compactCloser.Close()
+	binary, binaryCloser, err := testutils.NewJaegerThriftUDPClient(r.receivers[1].addr.String(), thrift.NewTBinaryProtocolFactoryConf(&thrift.TConfiguration{}))
+	require.NoError(t, err)
+	defer binaryCloser.Close()
+	zipkin, zipkinCloser, err := testutils.NewZipkinThriftUDPClient(r.receivers[2].addr.String())
+	require.NoError(t, err)
+	defer zipkinCloser.Close()
+
+	require.NoError(t, compact.EmitBatch(context.Background(), batch))
+	require.NoError(t, binary.EmitBatch(context.Background(), batch))
+	require.NoError(t, zipkin.EmitZipkinBatch(context.Background(), []*zipkincore.Span{{ID: 3, Name: "op"}}))
+	assert.Eventually(t, func() bool {
+		batches, zipkinBatches := handler.received()
+		return batches == 2 && zipkinBatches == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "legacy-client", handler.batches[0].Process.ServiceName)
+
+	counters, _ := mb.Snapshot()
+	assert.Equal(t, int64(1), counters["thrift.udp.server.packets.processed|model=jaeger|protocol=binary"])
+}
+
+func TestReceiversErrors(t *testing.T) {
+	_, err := StartReceivers(Options{
+		JaegerCompact: testServerOptions,
+		JaegerBinary:  ServerOptions{HostPort: ":-1", Workers: 1},
+	}, &fakeHandler{}, metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "cannot start the UDP receiver of jaeger spans in Thrift binary: cannot create UDPServerTransport")
+
+	_, err = StartReceivers(Options{
+		ZipkinCompact: ServerOptions{HostPort: "127.0.0.1:0", QueueSize: 10, MaxPacketSize: 65000},
+	}, &fakeHandler{}, metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "number of processors must be greater than 0")
+}
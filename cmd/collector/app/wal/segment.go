@@ -0,0 +1,160 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// SegmentSuffix is the suffix of the closed segments, ready to be replayed.
+	SegmentSuffix = ".wal"
+	// activeSuffix is the suffix of the segment being written.
+	activeSuffix = SegmentSuffix + ".active"
+
+	// headerSize is the size of the header of a record: the length and the CRC-32C of its payload.
+	headerSize = 8
+	// maxRecordSize bounds the memory allocated to read a record whose length is corrupted.
+	maxRecordSize = 256 << 20
+)
+
+// ErrCorruptSegment is returned when a segment holds a truncated record or a record not matching its checksum.
+var ErrCorruptSegment = errors.New("corrupt WAL segment")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// encodeRecord returns the record of a span: the header, then the payload holding the
+// length of the tenant as an uvarint, the tenant and the span serialized with protobuf.
+func encodeRecord(span *model.Span, tenant string) ([]byte, error) {
+	payloadSize := binary.MaxVarintLen64 + len(tenant) + span.Size()
+	record := make([]byte, headerSize, headerSize+payloadSize)
+	record = binary.AppendUvarint(record, uint64(len(tenant)))
+	record = append(record, tenant...)
+	spanBytes, err := span.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the span: %w", err)
+	}
+	record = append(record, spanBytes...)
+	payload := record[headerSize:]
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+	return record, nil
+}
+
+// decodePayload returns the span and the tenant of the payload of a record.
+func decodePayload(payload []byte) (*model.Span, string, error) {
+	tenantLength, n := binary.Uvarint(payload)
+	if n <= 0 || tenantLength > uint64(len(payload)-n) {
+		return nil, "", fmt.Errorf("%w: invalid tenant length", ErrCorruptSegment)
+	}
+	tenant := string(payload[n : n+int(tenantLength)])
+	span := &model.Span{}
+	if err := span.Unmarshal(payload[n+int(tenantLength):]); err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrCorruptSegment, err)
+	}
+	return span, tenant, nil
+}
+
+// readRecord returns the payload of the next record, or io.EOF at the end of the segment.
+func readRecord(r io.Reader) ([]byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: truncated record header", ErrCorruptSegment)
+		}
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return nil, fmt.Errorf("%w: record of %d bytes", ErrCorruptSegment, length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("%w: truncated record", ErrCorruptSegment)
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorruptSegment)
+	}
+	return payload, nil
+}
+
+// Segments returns the paths of the closed segments of the directory, oldest first.
+func Segments(dir string) ([]string, error) {
+	return segmentsWithSuffix(dir, SegmentSuffix)
+}
+
+func segmentsWithSuffix(dir, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the WAL segments: %w", err)
+	}
+	var segments []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+			segments = append(segments, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// the names of the segments are their zero-padded sequence numbers
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// ReadSegment calls fn with the spans of the segment and their tenant, in the order they were written.
+// It stops at the first error returned by fn.
+func ReadSegment(path string, fn func(span *model.Span, tenant string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		payload, err := readRecord(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		span, tenant, err := decodePayload(payload)
+		if err != nil {
+			return err
+		}
+		if err := fn(span, tenant); err != nil {
+			return err
+		}
+	}
+}
+
+// validLength returns the length of the prefix of the segment made of complete records.
+func validLength(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var length int64
+	for {
+		payload, err := readRecord(r)
+		if errors.Is(err, io.EOF) || errors.Is(err, ErrCorruptSegment) {
+			return length, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		length += int64(headerSize + len(payload))
+	}
+}
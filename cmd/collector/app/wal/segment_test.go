@@ -0,0 +1,98 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func writeSegment(t *testing.T, path string, spans ...*model.Span) []byte {
+	var data []byte
+	for _, span := range spans {
+		record, err := encodeRecord(span, "tenant")
+		require.NoError(t, err)
+		data = append(data, record...)
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return data
+}
+
+func TestReadSegment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1"+SegmentSuffix)
+	writeSegment(t, path, newSpan(1), newSpan(2))
+
+	var spans []*model.Span
+	require.NoError(t, ReadSegment(path, func(span *model.Span, tenant string) error {
+		assert.Equal(t, "tenant", tenant)
+		spans = append(spans, span)
+		return nil
+	}))
+	assert.Equal(t, []*model.Span{newSpan(1), newSpan(2)}, spans)
+
+	stop := errors.New("stop")
+	count := 0
+	err := ReadSegment(path, func(*model.Span, string) error {
+		count++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, count)
+
+	require.Error(t, ReadSegment(filepath.Join(t.TempDir(), "missing"+SegmentSuffix), nil))
+}
+
+func TestReadSegmentCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "1"+SegmentSuffix)
+	data := writeSegment(t, path, newSpan(1))
+	noop := func(*model.Span, string) error { return nil }
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated header", data: data[:headerSize-1]},
+		{name: "truncated payload", data: data[:len(data)-1]},
+		{name: "checksum mismatch", data: append(append([]byte{}, data[:len(data)-1]...), data[len(data)-1]+1)},
+		{name: "record too large", data: []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, tc.data, 0o600))
+			require.ErrorIs(t, ReadSegment(path, noop), ErrCorruptSegment)
+			length, err := validLength(path)
+			require.NoError(t, err)
+			assert.Zero(t, length)
+		})
+	}
+}
+
+func TestDecodePayloadCorrupt(t *testing.T) {
+	_, _, err := decodePayload([]byte{10, 'a'})
+	require.ErrorIs(t, err, ErrCorruptSegment)
+	_, _, err = decodePayload([]byte{0, 0xff})
+	require.ErrorIs(t, err, ErrCorruptSegment)
+}
+
+func TestSegments(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2" + SegmentSuffix, "1" + SegmentSuffix, "3" + activeSuffix, "other"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "4"+SegmentSuffix), 0o750))
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "1"+SegmentSuffix), filepath.Join(dir, "2"+SegmentSuffix)}, segments)
+
+	_, err = Segments(filepath.Join(dir, "missing"))
+	require.ErrorContains(t, err, "failed to list the WAL segments")
+}
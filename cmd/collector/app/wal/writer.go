@@ -0,0 +1,288 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_wal_spans_total",
+			Type: telemetery.Counter,
+			Help: "Spans which failed to be written to the storage, recorded or dropped by the write-ahead log",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"recorded", "dropped"}},
+			},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_wal_size_bytes",
+			Type: telemetery.Gauge,
+			Help: "Size of the segments of the write-ahead log not replayed yet",
+		},
+	)
+}
+
+var (
+	// ErrFull is returned when recording a span would exceed the maximum size of the WAL.
+	ErrFull = errors.New("the WAL is full")
+	// ErrClosed is returned when recording a span after the WAL is closed.
+	ErrClosed = errors.New("the WAL is closed")
+)
+
+// Options configures the write-ahead log.
+type Options struct {
+	// Dir is the directory of the segments, the WAL is disabled if empty.
+	Dir string
+	// MaxSegmentSize is the size in bytes at which a segment is closed and a new one started.
+	MaxSegmentSize int64
+	// MaxSize is the maximum size in bytes of the segments not replayed yet, 0 means no limit.
+	MaxSize int64
+	// SegmentCloseInterval is how often the segment being written is closed, so that its spans
+	// can be replayed without restarting the collector.
+	SegmentCloseInterval time.Duration
+}
+
+type writerMetrics struct {
+	// SpansRecorded counts the spans appended to the WAL
+	SpansRecorded metrics.Counter `metric:"wal.spans" tags:"result=recorded"`
+	// SpansDropped counts the spans not appended to the WAL, because it is full or cannot be written
+	SpansDropped metrics.Counter `metric:"wal.spans" tags:"result=dropped"`
+	// Size is the size of the segments on disk
+	Size metrics.Gauge `metric:"wal.size_bytes"`
+}
+
+// Writer appends the spans which failed to be written to the storage to the segments of the WAL.
+// The segments are closed once they reach the maximum size, periodically and when the writer is
+// closed; only the closed segments are replayed.
+type Writer struct {
+	options Options
+	logger  *zap.Logger
+	metrics writerMetrics
+	timeNow func() time.Time
+
+	mu sync.Mutex
+	// active is the segment being written, nil until a span is recorded
+	active      *os.File
+	activeSize  int64
+	size        int64
+	lastSegment int64
+	closed      bool
+
+	stopCh chan struct{}
+	done   sync.WaitGroup
+}
+
+// NewWriter creates a Writer of the segments in options.Dir. The segments left active by a collector
+// which did not shut down cleanly are closed, without their last record if it is incomplete.
+func NewWriter(options Options, metricsFactory metrics.Factory, logger *zap.Logger) (*Writer, error) {
+	if options.MaxSegmentSize <= 0 {
+		return nil, errors.New("the maximum segment size of the WAL must be positive")
+	}
+	if err := os.MkdirAll(options.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the WAL directory: %w", err)
+	}
+	w := &Writer{
+		options: options,
+		logger:  logger,
+		timeNow: time.Now,
+		stopCh:  make(chan struct{}),
+	}
+	metrics.MustInit(&w.metrics, metricsFactory, nil)
+	if err := w.recover(); err != nil {
+		return nil, err
+	}
+	if err := w.updateSize(); err != nil {
+		return nil, err
+	}
+	if options.SegmentCloseInterval > 0 {
+		w.done.Add(1)
+		go w.closeSegmentsPeriodically()
+	}
+	return w, nil
+}
+
+// recover closes the segments left active, truncating their incomplete last record.
+func (w *Writer) recover() error {
+	segments, err := segmentsWithSuffix(w.options.Dir, activeSuffix)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		length, err := validLength(segment)
+		if err != nil {
+			return fmt.Errorf("failed to recover the WAL segment %s: %w", segment, err)
+		}
+		if length == 0 {
+			if err := os.Remove(segment); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Truncate(segment, length); err != nil {
+			return fmt.Errorf("failed to recover the WAL segment %s: %w", segment, err)
+		}
+		if err := os.Rename(segment, strings.TrimSuffix(segment, activeSuffix)+SegmentSuffix); err != nil {
+			return fmt.Errorf("failed to recover the WAL segment %s: %w", segment, err)
+		}
+		w.logger.Info("Recovered a WAL segment left active", zap.String("segment", segment))
+	}
+	return nil
+}
+
+// Write appends the span and its tenant to the active segment.
+func (w *Writer) Write(span *model.Span, tenant string) error {
+	record, err := encodeRecord(span, tenant)
+	if err != nil {
+		w.metrics.SpansDropped.Inc(1)
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.write(record); err != nil {
+		w.metrics.SpansDropped.Inc(1)
+		return err
+	}
+	w.metrics.SpansRecorded.Inc(1)
+	return nil
+}
+
+func (w *Writer) write(record []byte) error {
+	if w.closed {
+		return ErrClosed
+	}
+	size := int64(len(record))
+	if w.options.MaxSize > 0 && w.size+size > w.options.MaxSize {
+		// the segments replayed since the last check may have been removed
+		if err := w.updateSize(); err != nil {
+			return err
+		}
+		if w.size+size > w.options.MaxSize {
+			return ErrFull
+		}
+	}
+	if w.active != nil && w.activeSize+size > w.options.MaxSegmentSize {
+		if err := w.closeActive(); err != nil {
+			return err
+		}
+	}
+	if w.active == nil {
+		if err := w.openActive(); err != nil {
+			return err
+		}
+	}
+	n, err := w.active.Write(record)
+	w.activeSize += int64(n)
+	w.size += int64(n)
+	w.metrics.Size.Update(w.size)
+	if err != nil {
+		return fmt.Errorf("failed to write to the WAL segment: %w", err)
+	}
+	return nil
+}
+
+// openActive creates a new active segment, named after its creation time so that the
+// segments are sorted in the order they were written.
+func (w *Writer) openActive() error {
+	sequence := w.timeNow().UnixNano()
+	if sequence <= w.lastSegment {
+		sequence = w.lastSegment + 1
+	}
+	f, err := os.OpenFile(filepath.Join(w.options.Dir, fmt.Sprintf("%020d%s", sequence, activeSuffix)),
+		os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create a WAL segment: %w", err)
+	}
+	w.active, w.activeSize, w.lastSegment = f, 0, sequence
+	return nil
+}
+
+// closeActive syncs and closes the active segment, then renames it to make it replayable.
+func (w *Writer) closeActive() error {
+	if w.active == nil {
+		return nil
+	}
+	active := w.active
+	w.active, w.activeSize = nil, 0
+	err := errors.Join(active.Sync(), active.Close())
+	if err == nil {
+		err = os.Rename(active.Name(), strings.TrimSuffix(active.Name(), activeSuffix)+SegmentSuffix)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close the WAL segment %s: %w", active.Name(), err)
+	}
+	return nil
+}
+
+// updateSize reads the size of the segments on disk, some of them being removed once replayed.
+func (w *Writer) updateSize() error {
+	segments, err := Segments(w.options.Dir)
+	if err != nil {
+		return err
+	}
+	size := w.activeSize
+	for _, segment := range segments {
+		info, err := os.Stat(segment)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		size += info.Size()
+	}
+	w.size = size
+	w.metrics.Size.Update(size)
+	return nil
+}
+
+func (w *Writer) closeSegmentsPeriodically() {
+	defer w.done.Done()
+	ticker := time.NewTicker(w.options.SegmentCloseInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			err := w.closeActive()
+			if err == nil {
+				err = w.updateSize()
+			}
+			w.mu.Unlock()
+			if err != nil {
+				w.logger.Error("Failed to close the active WAL segment", zap.Error(err))
+			}
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// Close closes the active segment. The spans recorded after Close are dropped.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.closeActive()
+	w.mu.Unlock()
+	close(w.stopCh)
+	w.done.Wait()
+	return err
+}
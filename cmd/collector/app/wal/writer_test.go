@@ -0,0 +1,168 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func newSpan(id uint64) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, id),
+		SpanID:        model.NewSpanID(id),
+		OperationName: "operation",
+		Process:       &model.Process{ServiceName: "service"},
+	}
+}
+
+type recordedSpan struct {
+	id     uint64
+	tenant string
+}
+
+// readAll returns the spans of the closed segments of the directory.
+func readAll(t *testing.T, dir string) []recordedSpan {
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	var spans []recordedSpan
+	for _, segment := range segments {
+		require.NoError(t, ReadSegment(segment, func(span *model.Span, tenant string) error {
+			spans = append(spans, recordedSpan{id: uint64(span.SpanID), tenant: tenant})
+			return nil
+		}))
+	}
+	return spans
+}
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	w, err := NewWriter(Options{Dir: dir, MaxSegmentSize: 1 << 20}, metricsFactory, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(newSpan(1), ""))
+	require.NoError(t, w.Write(newSpan(2), "acme"))
+	assert.Empty(t, readAll(t, dir), "the active segment is not replayable")
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, []recordedSpan{{id: 1}, {id: 2, tenant: "acme"}}, readAll(t, dir))
+	require.ErrorIs(t, w.Write(newSpan(3), ""), ErrClosed)
+	require.NoError(t, w.Close())
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "wal.spans", Tags: map[string]string{"result": "recorded"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "wal.spans", Tags: map[string]string{"result": "dropped"}, Value: 1},
+	)
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	info, err := os.Stat(segments[0])
+	require.NoError(t, err)
+	metricsFactory.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "wal.size_bytes", Value: int(info.Size())})
+}
+
+func TestWriterRotatesSegments(t *testing.T) {
+	dir := t.TempDir()
+	record, err := encodeRecord(newSpan(1), "")
+	require.NoError(t, err)
+	w, err := NewWriter(Options{Dir: dir, MaxSegmentSize: int64(2 * len(record))}, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	// the segments are named after the time they are created
+	w.timeNow = func() time.Time { return time.Unix(0, 1) }
+	for i := uint64(1); i <= 5; i++ {
+		require.NoError(t, w.Write(newSpan(i), ""))
+	}
+	require.NoError(t, w.Close())
+
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	assert.Len(t, segments, 3)
+	assert.Equal(t, []recordedSpan{{id: 1}, {id: 2}, {id: 3}, {id: 4}, {id: 5}}, readAll(t, dir))
+}
+
+func TestWriterMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	record, err := encodeRecord(newSpan(1), "")
+	require.NoError(t, err)
+	w, err := NewWriter(Options{
+		Dir:            dir,
+		MaxSegmentSize: int64(len(record)),
+		MaxSize:        int64(2 * len(record)),
+	}, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.NoError(t, w.Write(newSpan(1), ""))
+	require.NoError(t, w.Write(newSpan(2), ""))
+	require.ErrorIs(t, w.Write(newSpan(3), ""), ErrFull)
+
+	// the space of the replayed segments is available again
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	require.NoError(t, os.Remove(segments[0]))
+	require.NoError(t, w.Write(newSpan(3), ""))
+}
+
+func TestWriterClosesSegmentsPeriodically(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Options{
+		Dir:                  dir,
+		MaxSegmentSize:       1 << 20,
+		SegmentCloseInterval: time.Millisecond,
+	}, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.NoError(t, w.Write(newSpan(1), ""))
+	assert.Eventually(t, func() bool {
+		return len(readAll(t, dir)) == 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestWriterRecoversActiveSegments(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Options{Dir: dir, MaxSegmentSize: 1 << 20}, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, w.Write(newSpan(1), ""))
+	require.NoError(t, w.Write(newSpan(2), ""))
+	require.NoError(t, w.active.Sync())
+	active := w.active.Name()
+	// simulates a crash in the middle of the write of a record
+	info, err := os.Stat(active)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(active, info.Size()-1))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000001"+activeSuffix), nil, 0o600))
+	w.active.Close()
+
+	w, err = NewWriter(Options{Dir: dir, MaxSegmentSize: 1 << 20}, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []recordedSpan{{id: 1}}, readAll(t, dir))
+	leftActive, err := filepath.Glob(filepath.Join(dir, "*"+activeSuffix))
+	require.NoError(t, err)
+	assert.Empty(t, leftActive)
+}
+
+func TestNewWriterErrors(t *testing.T) {
+	_, err := NewWriter(Options{Dir: t.TempDir()}, metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "maximum segment size")
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = NewWriter(Options{Dir: filepath.Join(file, "wal"), MaxSegmentSize: 1}, metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "failed to create the WAL directory")
+}
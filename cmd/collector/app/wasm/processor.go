@@ -0,0 +1,266 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasm runs the spans received by the collector through user-provided WebAssembly
+// modules, which can enrich, rewrite or drop them before they are written to the storage.
+//
+// A module must export its memory as "memory" and the functions:
+//
+//	allocate(size i32) i32
+//	process(ptr i32, len i32) i64
+//
+// For each span, the collector calls allocate to reserve size bytes in the memory of the
+// module, writes the span encoded in the Jaeger protobuf model (model.proto) at the returned
+// pointer and calls process with the pointer and the length of the encoded span. process
+// returns 0 to drop the span, otherwise the pointer in the upper 32 bits and the length in
+// the lower 32 bits of the encoded span to write in place of the span. The memory returned
+// by allocate and process only needs to remain valid until the next call to allocate.
+//
+// A call to process longer than the timeout of the processor is aborted and the span kept as
+// it was before the module, e.g. if the module loops.
+//
+// The modules can import the WASI preview 1 functions (wasi_snapshot_preview1), e.g. to log
+// to the standard output; they are not given access to the file system. A module exporting
+// the "_initialize" function, as WASI reactors do, has it called once instantiated.
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_wasm_spans_total",
+			Type: telemetery.Counter,
+			Help: "Spans run through the WebAssembly span processors, processed, dropped or failing to be processed",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"processed", "dropped", "failed"}},
+			},
+		},
+	)
+}
+
+type processorMetrics struct {
+	// Processed counts the spans returned by all the modules
+	Processed metrics.Counter `metric:"wasm.spans" tags:"result=processed"`
+	// Dropped counts the spans dropped by a module
+	Dropped metrics.Counter `metric:"wasm.spans" tags:"result=dropped"`
+	// Failed counts the spans which a module failed to process, they are kept as they were before the module
+	Failed metrics.Counter `metric:"wasm.spans" tags:"result=failed"`
+}
+
+// Processor runs the spans through the WebAssembly modules, in order.
+type Processor struct {
+	runtime wazero.Runtime
+	modules []*module
+	timeout time.Duration
+	logger  *zap.Logger
+	metrics processorMetrics
+}
+
+// NewProcessor compiles the WebAssembly modules at paths and checks that they implement the ABI.
+// Each call of a module to process a span is aborted after the timeout, unless it is not positive.
+func NewProcessor(ctx context.Context, paths []string, timeout time.Duration, metricsFactory metrics.Factory, logger *zap.Logger) (*Processor, error) {
+	p := &Processor{
+		// the functions are interrupted when the context of their call is done
+		runtime: wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true)),
+		timeout: timeout,
+		logger:  logger,
+	}
+	metrics.MustInit(&p.metrics, metricsFactory, nil)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, p.runtime); err != nil {
+		_ = p.Close()
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+	for _, path := range paths {
+		m, err := p.compile(ctx, path)
+		if err != nil {
+			_ = p.Close()
+			return nil, fmt.Errorf("failed to load WebAssembly module %s: %w", path, err)
+		}
+		p.modules = append(p.modules, m)
+	}
+	return p, nil
+}
+
+func (p *Processor) compile(ctx context.Context, path string) (*module, error) {
+	code, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := p.runtime.CompileModule(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	m := &module{
+		name:     filepath.Base(path),
+		runtime:  p.runtime,
+		compiled: compiled,
+		// the modules are anonymous, so that they can be instantiated once per concurrent call
+		config: wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"),
+	}
+	// instantiating the module checks its exports
+	i, err := m.instantiate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.release(i)
+	return m, nil
+}
+
+// Process returns the span processed by the modules, or nil if a module dropped it.
+// The span is passed unchanged to the next module when a module fails to process it.
+func (p *Processor) Process(span *model.Span) *model.Span {
+	for _, m := range p.modules {
+		processed, err := p.process(m, span)
+		if err != nil {
+			p.metrics.Failed.Inc(1)
+			p.logger.Error("WebAssembly module failed to process the span",
+				zap.String("module", m.name), zap.Stringer("trace-id", span.TraceID), zap.Error(err))
+			continue
+		}
+		if processed == nil {
+			p.metrics.Dropped.Inc(1)
+			return nil
+		}
+		span = processed
+	}
+	p.metrics.Processed.Inc(1)
+	return span
+}
+
+// process runs the span through the module, within the timeout.
+func (p *Processor) process(m *module, span *model.Span) (*model.Span, error) {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return m.process(ctx, span)
+}
+
+// Close releases the modules.
+func (p *Processor) Close() error {
+	return p.runtime.Close(context.Background())
+}
+
+// module is a compiled WebAssembly module, with the instances not processing a span.
+type module struct {
+	name     string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	config   wazero.ModuleConfig
+
+	mu   sync.Mutex
+	idle []*instance
+}
+
+// instance is an instance of a module; its functions cannot be called concurrently.
+type instance struct {
+	module   api.Module
+	memory   api.Memory
+	allocate api.Function
+	process  api.Function
+}
+
+func (m *module) instantiate(ctx context.Context) (*instance, error) {
+	mod, err := m.runtime.InstantiateModule(ctx, m.compiled, m.config)
+	if err != nil {
+		return nil, err
+	}
+	i := &instance{
+		module:   mod,
+		memory:   mod.ExportedMemory("memory"),
+		allocate: mod.ExportedFunction("allocate"),
+		process:  mod.ExportedFunction("process"),
+	}
+	if i.memory == nil || i.allocate == nil || i.process == nil {
+		_ = mod.Close(ctx)
+		return nil, errors.New("the module must export memory, allocate and process")
+	}
+	return i, nil
+}
+
+func (m *module) acquire(ctx context.Context) (*instance, error) {
+	m.mu.Lock()
+	if n := len(m.idle); n > 0 {
+		i := m.idle[n-1]
+		m.idle = m.idle[:n-1]
+		m.mu.Unlock()
+		return i, nil
+	}
+	m.mu.Unlock()
+	return m.instantiate(ctx)
+}
+
+func (m *module) release(i *instance) {
+	m.mu.Lock()
+	m.idle = append(m.idle, i)
+	m.mu.Unlock()
+}
+
+// process returns the span processed by the module, or nil if the module dropped it.
+func (m *module) process(ctx context.Context, span *model.Span) (*model.Span, error) {
+	data, err := span.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	i, err := m.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	processed, err := i.call(ctx, data)
+	if err != nil {
+		// the state of the instance is unknown after a trap
+		_ = i.module.Close(ctx)
+		return nil, err
+	}
+	m.release(i)
+	return processed, nil
+}
+
+func (i *instance) call(ctx context.Context, data []byte) (*model.Span, error) {
+	results, err := i.allocate.Call(ctx, uint64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("allocate failed: %w", err)
+	}
+	ptr := uint32(results[0])
+	if !i.memory.Write(ptr, data) {
+		return nil, fmt.Errorf("allocate returned %d, out of the memory for %d bytes", ptr, len(data))
+	}
+	results, err = i.process.Call(ctx, uint64(ptr), uint64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("process failed: %w", err)
+	}
+	if results[0] == 0 {
+		return nil, nil
+	}
+	ptr, size := uint32(results[0]>>32), uint32(results[0])
+	processed, ok := i.memory.Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("process returned %d bytes at %d, out of the memory", size, ptr)
+	}
+	span := &model.Span{}
+	// Unmarshal copies the bytes, which may be overwritten by the next call
+	if err := span.Unmarshal(processed); err != nil {
+		return nil, fmt.Errorf("process returned an invalid span: %w", err)
+	}
+	return span, nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+var (
+	// process returns the span it is given
+	identityProcess = []byte{
+		0x20, 0x00, 0xad, 0x42, 0x20, 0x86, // i64.extend_i32_u(ptr) << 32
+		0x20, 0x01, 0xad, 0x84, // | i64.extend_i32_u(len)
+	}
+	// process drops the span
+	dropProcess = []byte{0x42, 0x00}
+	// process traps
+	trapProcess = []byte{0x00}
+	// process loops forever
+	loopProcess = []byte{
+		0x03, 0x40, 0x0c, 0x00, 0x0b, // loop br 0 end
+		0x42, 0x00,
+	}
+	// process returns a span out of the memory
+	outOfMemoryProcess = append([]byte{0x42}, sleb128(0xffff<<32|100)...)
+)
+
+// writeModule assembles a WebAssembly module with the body of its process function and
+// the data at the offset 0 of its memory, and writes it to a file in dir.
+func writeModule(t *testing.T, dir string, name string, process []byte, data []byte) string {
+	section := func(id byte, content ...byte) []byte {
+		return append(append([]byte{id}, uleb128(uint64(len(content)))...), content...)
+	}
+	body := func(instructions ...byte) []byte {
+		instructions = append(append([]byte{0x00}, instructions...), 0x0b) // no locals, end
+		return append(uleb128(uint64(len(instructions))), instructions...)
+	}
+	name8 := func(name string) []byte {
+		return append([]byte{byte(len(name))}, name...)
+	}
+
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	code = append(code, section(0x01, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e)...)
+	code = append(code, section(0x03, 0x02, 0x00, 0x01)...)
+	code = append(code, section(0x05, 0x01, 0x00, 0x01)...) // one page
+	var exports []byte
+	exports = append(exports, 0x03)
+	exports = append(append(exports, name8("memory")...), 0x02, 0x00)
+	exports = append(append(exports, name8("allocate")...), 0x00, 0x00)
+	exports = append(append(exports, name8("process")...), 0x00, 0x01)
+	code = append(code, section(0x07, exports...)...)
+	functions := []byte{0x02}
+	functions = append(functions, body(0x41, 0x80, 0x08)...) // allocate returns 1024
+	functions = append(functions, body(process...)...)
+	code = append(code, section(0x0a, functions...)...)
+	if len(data) > 0 {
+		segment := append([]byte{0x01, 0x00, 0x41, 0x00, 0x0b}, uleb128(uint64(len(data)))...)
+		code = append(code, section(0x0b, append(segment, data...)...)...)
+	}
+	return writeFile(t, dir, name, code)
+}
+
+func writeFile(t *testing.T, dir string, name string, code []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, code, 0o600))
+	return path
+}
+
+func uleb128(v uint64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func sleb128(v int64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+// replaceModule writes a module returning span in place of any span.
+func replaceModule(t *testing.T, dir string, span *model.Span) string {
+	data, err := span.Marshal()
+	require.NoError(t, err)
+	return writeModule(t, dir, "replace.wasm", append([]byte{0x42}, sleb128(int64(len(data)))...), data)
+}
+
+func testSpan() *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(1, 2),
+		SpanID:        model.NewSpanID(3),
+		OperationName: "op",
+		Tags:          []model.KeyValue{model.String("k", "v")},
+		Process:       model.NewProcess("service", nil),
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	dir := t.TempDir()
+	enriched := testSpan()
+	enriched.OperationName = "enriched"
+	identity := writeModule(t, dir, "identity.wasm", identityProcess, nil)
+	drop := writeModule(t, dir, "drop.wasm", dropProcess, nil)
+	trap := writeModule(t, dir, "trap.wasm", trapProcess, nil)
+	outOfMemory := writeModule(t, dir, "out-of-memory.wasm", outOfMemoryProcess, nil)
+	replace := replaceModule(t, dir, enriched)
+
+	tests := []struct {
+		name     string
+		modules  []string
+		expected *model.Span
+	}{
+		{name: "no modules", expected: testSpan()},
+		{name: "identity", modules: []string{identity}, expected: testSpan()},
+		{name: "drop", modules: []string{drop}},
+		{name: "replace", modules: []string{replace, identity}, expected: enriched},
+		{name: "drop before replace", modules: []string{drop, replace}},
+		{name: "trap", modules: []string{trap, identity}, expected: testSpan()},
+		{name: "out of memory", modules: []string{outOfMemory}, expected: testSpan()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := NewProcessor(context.Background(), test.modules, time.Second, metrics.NullFactory, zap.NewNop())
+			require.NoError(t, err)
+			defer p.Close()
+			// the second call uses the instances released by the first one
+			for i := 0; i < 2; i++ {
+				assert.Equal(t, test.expected, p.Process(testSpan()))
+			}
+		})
+	}
+}
+
+func TestProcessorConcurrency(t *testing.T) {
+	path := writeModule(t, t.TempDir(), "identity.wasm", identityProcess, nil)
+	p, err := NewProcessor(context.Background(), []string{path}, time.Second, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.Equal(t, testSpan(), p.Process(testSpan()))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProcessorTimeout(t *testing.T) {
+	dir := t.TempDir()
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	p, err := NewProcessor(context.Background(), []string{
+		writeModule(t, dir, "loop.wasm", loopProcess, nil),
+		writeModule(t, dir, "identity.wasm", identityProcess, nil),
+	}, 50*time.Millisecond, mb, zap.NewNop())
+	require.NoError(t, err)
+	defer p.Close()
+
+	// the looping module is interrupted each time, the span is kept as it was
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		assert.Equal(t, testSpan(), p.Process(testSpan()))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	}
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "wasm.spans", Tags: map[string]string{"result": "processed"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "wasm.spans", Tags: map[string]string{"result": "failed"}, Value: 2},
+	)
+}
+
+func TestProcessorMetrics(t *testing.T) {
+	dir := t.TempDir()
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	identity, err := NewProcessor(context.Background(), []string{
+		writeModule(t, dir, "trap.wasm", trapProcess, nil),
+		writeModule(t, dir, "identity.wasm", identityProcess, nil),
+	}, time.Second, mb, zap.NewNop())
+	require.NoError(t, err)
+	defer identity.Close()
+	drop, err := NewProcessor(context.Background(), []string{writeModule(t, dir, "drop.wasm", dropProcess, nil)}, time.Second, mb, zap.NewNop())
+	require.NoError(t, err)
+	defer drop.Close()
+
+	identity.Process(testSpan())
+	drop.Process(testSpan())
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "wasm.spans", Tags: map[string]string{"result": "processed"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "wasm.spans", Tags: map[string]string{"result": "dropped"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "wasm.spans", Tags: map[string]string{"result": "failed"}, Value: 1},
+	)
+}
+
+func TestNewProcessorErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		path string
+		err  string
+	}{
+		{
+			name: "missing file",
+			path: filepath.Join(dir, "missing.wasm"),
+			err:  "no such file",
+		},
+		{
+			name: "invalid module",
+			path: writeFile(t, dir, "invalid.wasm", []byte("not a module")),
+			err:  "invalid magic number",
+		},
+		{
+			name: "missing exports",
+			path: writeFile(t, dir, "empty.wasm", []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}),
+			err:  "the module must export memory, allocate and process",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewProcessor(context.Background(), []string{test.path}, time.Second, metrics.NullFactory, zap.NewNop())
+			require.ErrorContains(t, err, "failed to load WebAssembly module "+test.path)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_workers_autoscaling_total",
+			Type: telemetery.Counter,
+			Help: "Decisions of the workers autoscaling, changing or keeping the number of workers of the span processor queue",
+			Labels: []telemetery.Label{
+				{Name: "decision", Values: []string{"increase", "decrease", "hold"}},
+			},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_queue_workers",
+			Type: telemetery.Gauge,
+			Help: "Number of workers of the span processor queue, set by the workers autoscaling",
+		},
+	)
+}
+
+const (
+	// workersIncrease is the number of workers added when the spans queue up.
+	workersIncrease = 2
+	// workersDecreaseFactor is the factor applied to the number of workers when the storage is slow.
+	workersDecreaseFactor = 0.5
+	// backlogRatio is the fraction of the queue capacity over which the spans are considered to queue up.
+	backlogRatio = 0.1
+)
+
+type workersAutoscalerMetrics struct {
+	// Increased counts the intervals at the end of which workers were added
+	Increased metrics.Counter `metric:"workers-autoscaling" tags:"decision=increase"`
+	// Decreased counts the intervals at the end of which workers were removed
+	Decreased metrics.Counter `metric:"workers-autoscaling" tags:"decision=decrease"`
+	// Held counts the intervals at the end of which the number of workers was kept
+	Held metrics.Counter `metric:"workers-autoscaling" tags:"decision=hold"`
+	// Workers is the number of workers
+	Workers metrics.Gauge `metric:"queue-workers"`
+}
+
+// workersAutoscaler adapts the number of workers with an additive increase/multiplicative
+// decrease (AIMD) controller: the workers are halved when the average write latency of the
+// storage over the last interval exceeds the target latency, so that a struggling storage
+// is not overloaded further, and a few workers are added when the spans queue up otherwise.
+// It scales the workers of the single queue of the span processor, on the latency of the
+// span writer as a whole: with several storage backends, the slowest one drives the workers
+// writing to all of them.
+type workersAutoscaler struct {
+	options flags.WorkersAutoscaling
+	metrics workersAutoscalerMetrics
+	logger  *zap.Logger
+
+	// the writes since the last decision
+	latencySum atomic.Int64
+	writes     atomic.Int64
+}
+
+func newWorkersAutoscaler(options flags.WorkersAutoscaling, metricsFactory metrics.Factory, logger *zap.Logger) *workersAutoscaler {
+	a := &workersAutoscaler{
+		options: options,
+		logger:  logger,
+	}
+	metrics.MustInit(&a.metrics, metricsFactory, nil)
+	return a
+}
+
+// observe returns a timer recording the write latencies to the timer and to the autoscaler.
+func (a *workersAutoscaler) observe(timer metrics.Timer) metrics.Timer {
+	return &latencyObserver{Timer: timer, autoscaler: a}
+}
+
+// bound returns the number of workers within the bounds of the autoscaling.
+func (a *workersAutoscaler) bound(workers int) int {
+	return max(a.options.MinWorkers, min(a.options.MaxWorkers, workers))
+}
+
+// nextWorkers returns the number of workers for the next interval.
+func (a *workersAutoscaler) nextWorkers(workers int, queueLength int, queueCapacity int) int {
+	latencySum, writes := a.latencySum.Swap(0), a.writes.Swap(0)
+	var latency time.Duration
+	if writes > 0 {
+		latency = time.Duration(latencySum / writes)
+	}
+	next := workers
+	switch {
+	case latency > a.options.TargetLatency:
+		next = int(float64(workers) * workersDecreaseFactor)
+	case float64(queueLength) > float64(queueCapacity)*backlogRatio:
+		next = workers + workersIncrease
+	}
+	next = a.bound(next)
+	switch {
+	case next > workers:
+		a.metrics.Increased.Inc(1)
+	case next < workers:
+		a.metrics.Decreased.Inc(1)
+	default:
+		a.metrics.Held.Inc(1)
+	}
+	a.metrics.Workers.Update(int64(next))
+	if next != workers {
+		a.logger.Info("Autoscaling the workers",
+			zap.Int("previous", workers), zap.Int("workers", next),
+			zap.Duration("average-write-latency", latency), zap.Int("queue-length", queueLength))
+	}
+	return next
+}
+
+// latencyObserver is a metrics.Timer recording the write latencies for the workersAutoscaler.
+type latencyObserver struct {
+	metrics.Timer
+	autoscaler *workersAutoscaler
+}
+
+func (o *latencyObserver) Record(latency time.Duration) {
+	o.Timer.Record(latency)
+	o.autoscaler.latencySum.Add(int64(latency))
+	o.autoscaler.writes.Add(1)
+}
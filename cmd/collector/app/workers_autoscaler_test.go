@@ -0,0 +1,138 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+var testWorkersAutoscaling = flags.WorkersAutoscaling{
+	Enabled:       true,
+	MinWorkers:    2,
+	MaxWorkers:    11,
+	TargetLatency: 100 * time.Millisecond,
+	Interval:      time.Hour,
+}
+
+func TestWorkersAutoscaler(t *testing.T) {
+	tests := []struct {
+		name      string
+		workers   int
+		latencies []time.Duration
+		queued    int
+		expected  int
+	}{
+		{
+			name:      "slow storage",
+			workers:   10,
+			latencies: []time.Duration{50 * time.Millisecond, 250 * time.Millisecond},
+			queued:    90,
+			expected:  5,
+		},
+		{
+			name:      "slow storage at the minimum",
+			workers:   3,
+			latencies: []time.Duration{time.Second},
+			expected:  2,
+		},
+		{
+			name:      "spans queuing up",
+			workers:   5,
+			latencies: []time.Duration{50 * time.Millisecond},
+			queued:    11,
+			expected:  7,
+		},
+		{
+			name:     "spans queuing up without writes",
+			workers:  5,
+			queued:   11,
+			expected: 7,
+		},
+		{
+			name:     "spans queuing up at the maximum",
+			workers:  10,
+			queued:   11,
+			expected: 11,
+		},
+		{
+			name:      "idle",
+			workers:   5,
+			latencies: []time.Duration{50 * time.Millisecond},
+			queued:    10,
+			expected:  5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := newWorkersAutoscaler(testWorkersAutoscaling, metrics.NullFactory, zap.NewNop())
+			timer := a.observe(metrics.NullTimer)
+			for _, latency := range test.latencies {
+				timer.Record(latency)
+			}
+			assert.Equal(t, test.expected, a.nextWorkers(test.workers, test.queued, 100))
+			// the latencies are reset at each decision
+			assert.Equal(t, test.expected, a.nextWorkers(test.expected, 0, 100))
+		})
+	}
+}
+
+func TestWorkersAutoscalerMetrics(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	a := newWorkersAutoscaler(testWorkersAutoscaling, mb, zap.NewNop())
+	a.observe(metrics.NullTimer).Record(time.Second)
+	a.nextWorkers(10, 0, 100)
+	a.nextWorkers(5, 50, 100)
+	a.nextWorkers(7, 0, 100)
+
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "workers-autoscaling|decision=decrease", Value: 1},
+		metricstest.ExpectedMetric{Name: "workers-autoscaling|decision=increase", Value: 1},
+		metricstest.ExpectedMetric{Name: "workers-autoscaling|decision=hold", Value: 1},
+	)
+	mb.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "queue-workers", Value: 7})
+}
+
+func TestSpanProcessorWorkersAutoscaling(t *testing.T) {
+	w := &blockingWriter{}
+	p := NewSpanProcessor(w,
+		nil,
+		Options.NumWorkers(50),
+		Options.QueueSize(10),
+		Options.WorkersAutoscaling(testWorkersAutoscaling),
+	).(*spanProcessor)
+	defer p.Close()
+	// the initial number of workers is bounded
+	assert.Equal(t, 11, p.queueStatus().Workers)
+
+	p.metrics.SaveLatency.Record(time.Second)
+	p.autoscaleWorkers()
+	assert.Equal(t, 5, p.queueStatus().Workers)
+
+	// a blocked storage makes all the workers busy and the spans queue up
+	w.Lock()
+	spans := make([]*model.Span, 7)
+	for i := range spans {
+		spans[i] = &model.Span{}
+	}
+	_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return p.queue.Size() == 2
+	}, time.Second, time.Millisecond)
+	p.autoscaleWorkers()
+	assert.Equal(t, 7, p.queueStatus().Workers)
+	w.Unlock()
+}
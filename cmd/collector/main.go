@@ -16,6 +16,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -34,12 +35,16 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/internal/printconfig"
 	"github.com/jaegertracing/jaeger/cmd/internal/status"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/version"
 	ss "github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/stored"
 	"github.com/jaegertracing/jaeger/plugin/storage"
 	"github.com/jaegertracing/jaeger/ports"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
 )
 
 const serviceName = "jaeger-collector"
@@ -70,7 +75,17 @@ func main() {
 				return err
 			}
 			logger := svc.Logger // shortcut
-			baseFactory := svc.MetricsFactory.Namespace(metrics.NSOptions{Name: "jaeger"})
+			// the collector traces itself only while a capture is requested on the admin port
+			tracer, err := jtracer.NewCaptureOnly(serviceName)
+			if err != nil {
+				logger.Fatal("Failed to create tracer", zap.Error(err))
+			}
+			svc.Admin.Handle("/debug/trace", jtracer.NewCaptureHandler(tracer.Capture, logger))
+			telset, err := svc.TelemetrySetting(serviceName, tracer)
+			if err != nil {
+				logger.Fatal("Failed to create telemetry", zap.Error(err))
+			}
+			baseFactory := telset.Metrics
 			metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "collector"})
 			version.NewInfoMetrics(metricsFactory)
 
@@ -78,10 +93,13 @@ func main() {
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			svc.HC().AddProbe("storage", storageFactory.CheckHealth)
+			svc.Admin.Handle("/storage/capabilities", storageFactory.CapabilitiesHandler())
 			spanWriter, err := storageFactory.CreateSpanWriter()
 			if err != nil {
 				logger.Fatal("Failed to create span writer", zap.Error(err))
 			}
+			spanWriter = svc.Admin.Status().InstrumentSpanWriter(spanWriter)
 
 			ssFactory, err := storageFactory.CreateSamplingStoreFactory()
 			if err != nil {
@@ -96,11 +114,23 @@ func main() {
 			if err != nil {
 				logger.Fatal("Failed to create sampling strategy provider", zap.Error(err))
 			}
+			// the strategies set with the query service API take precedence
+			var storedStrategies io.Closer
+			if storedOpts := new(stored.Options).InitFromViper(v); storedOpts.Enabled() {
+				if store := app.CreateSamplingStrategyStore(storageFactory, logger); store != nil {
+					samplingProvider = stored.NewProvider(samplingProvider, store, *storedOpts, logger, metricsFactory)
+					storedStrategies = samplingProvider
+				}
+			}
 			collectorOpts, err := new(flags.CollectorOptions).InitFromViper(v, logger)
 			if err != nil {
 				logger.Fatal("Failed to initialize collector", zap.Error(err))
 			}
 			tm := tenancy.NewManager(&collectorOpts.GRPC.Tenancy)
+			var metadataStore metadatastore.Store
+			if collectorOpts.ServiceMetadataFromProcessTags {
+				metadataStore = app.CreateMetadataStore(storageFactory, logger)
+			}
 
 			collector := app.New(&app.CollectorParams{
 				ServiceName:        serviceName,
@@ -111,28 +141,53 @@ func main() {
 				SamplingAggregator: samplingAggregator,
 				HealthCheck:        svc.HC(),
 				TenancyMgr:         tm,
+				Tracer:             tracer,
+				MeterProvider:      telset.MeterProvider,
+				MetadataStore:      metadataStore,
 			})
 			// Start all Collector services
 			if err := collector.Start(collectorOpts); err != nil {
 				logger.Fatal("Failed to start collector", zap.Error(err))
 			}
+			svc.Admin.Status().Register("queue", func() any {
+				return collector.QueueStatus()
+			})
+			reloader, err := cmdFlags.NewConfigReloader(v, func() error {
+				opts, err := new(flags.CollectorOptions).InitFromViper(v, logger)
+				if err != nil {
+					return err
+				}
+				return collector.Reload(opts)
+			}, metricsFactory, logger)
+			if err != nil {
+				logger.Fatal("Failed to watch the config file", zap.Error(err))
+			}
 			// Wait for shutdown
 			svc.RunAndThen(func() {
+				if err := reloader.Close(); err != nil {
+					logger.Error("Failed to stop watching the config file", zap.Error(err))
+				}
 				if err := collector.Close(); err != nil {
 					logger.Error("failed to cleanly close the collector", zap.Error(err))
 				}
-				if closer, ok := spanWriter.(io.Closer); ok {
-					err := closer.Close()
-					if err != nil {
-						logger.Error("failed to close span writer", zap.Error(err))
+				if storedStrategies != nil {
+					if err := storedStrategies.Close(); err != nil {
+						logger.Error("Failed to close the stored sampling strategies", zap.Error(err))
 					}
 				}
+				// the storage factory closes the span writers it created
 				if err := storageFactory.Close(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
 				if err := samplingStrategyFactory.Close(); err != nil {
 					logger.Error("Failed to close sampling strategy store factory", zap.Error(err))
 				}
+				if err := telset.Close(context.Background()); err != nil {
+					logger.Error("Failed to close telemetry", zap.Error(err))
+				}
+				if err := tracer.Close(context.Background()); err != nil {
+					logger.Error("Failed to close tracer", zap.Error(err))
+				}
 			})
 			return nil
 		},
@@ -143,6 +198,7 @@ func main() {
 	command.AddCommand(docs.Command(v))
 	command.AddCommand(status.Command(v, ports.CollectorAdminHTTP))
 	command.AddCommand(printconfig.Command(v))
+	command.AddCommand(telemetery.Command())
 
 	config.AddFlags(
 		v,
@@ -151,6 +207,7 @@ func main() {
 		flags.AddFlags,
 		storageFactory.AddPipelineFlags,
 		samplingStrategyFactory.AddFlags,
+		stored.AddFlags,
 	)
 
 	if err := command.Execute(); err != nil {
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// Stats summarizes a backfill.
+type Stats struct {
+	Windows int64
+	Traces  int64
+	Links   int64
+}
+
+// Backfiller recomputes the dependency links of the traces of a time range from a span storage
+// and writes them to a dependency storage, without the Spark dependencies job.
+//
+// The time range is processed in windows that are checkpointed once their links are written,
+// so that a restarted backfill resumes after the last written window. The links of a window
+// are computed from the traces whose first span started in it, and written at its start.
+type Backfiller struct {
+	reader     spanstore.Reader
+	writer     dependencystore.Writer
+	checkpoint *Checkpoint
+	options    Options
+	logger     *zap.Logger
+
+	stats Stats
+}
+
+// NewBackfiller creates a Backfiller.
+func NewBackfiller(reader spanstore.Reader, writer dependencystore.Writer, checkpoint *Checkpoint, options Options, logger *zap.Logger) *Backfiller {
+	return &Backfiller{
+		reader:     reader,
+		writer:     writer,
+		checkpoint: checkpoint,
+		options:    options,
+		logger:     logger,
+	}
+}
+
+// Run backfills the dependencies, stopping at the first error.
+func (b *Backfiller) Run(ctx context.Context) (Stats, error) {
+	start, end, err := b.options.TimeRange()
+	if err != nil {
+		return Stats{}, err
+	}
+	services, err := b.reader.GetServices(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("cannot get services: %w", err)
+	}
+	for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(b.options.Window) {
+		windowEnd := windowStart.Add(b.options.Window)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		if b.checkpoint.Written(windowEnd) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return b.stats, err
+		}
+		if err := b.backfillWindow(ctx, services, windowStart, windowEnd); err != nil {
+			return b.stats, err
+		}
+		if err := b.checkpoint.Save(windowEnd); err != nil {
+			return b.stats, err
+		}
+		b.stats.Windows++
+		b.logger.Info("Backfilled dependencies", zap.Time("start", windowStart), zap.Time("end", windowEnd))
+	}
+	return b.stats, nil
+}
+
+func (b *Backfiller) backfillWindow(ctx context.Context, services []string, start, end time.Time) error {
+	aggregator := dependencystore.NewLatencyAggregator(nil)
+	seen := make(map[model.TraceID]struct{})
+	for _, service := range services {
+		traces, err := b.reader.FindTraces(ctx, &spanstore.TraceQueryParameters{
+			ServiceName:  service,
+			StartTimeMin: start,
+			StartTimeMax: end,
+			NumTraces:    b.options.MaxTraces,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot find traces of service %s: %w", service, err)
+		}
+		if len(traces) >= b.options.MaxTraces {
+			b.logger.Warn("The number of traces in the window reached the maximum, some dependencies may not be counted; use a smaller window",
+				zap.String("service", service), zap.Time("start", start), zap.Time("end", end), zap.Int("max-traces", b.options.MaxTraces))
+		}
+		for _, trace := range traces {
+			if len(trace.Spans) == 0 {
+				continue
+			}
+			traceID := trace.Spans[0].TraceID
+			if _, ok := seen[traceID]; ok {
+				continue
+			}
+			seen[traceID] = struct{}{}
+			// a trace overlapping several windows is only counted in the window of its first span
+			if first := firstSpanStart(trace); first.Before(start) || !first.Before(end) {
+				continue
+			}
+			aggregator.AddTrace(trace)
+			b.stats.Traces++
+		}
+	}
+	latencies := aggregator.Latencies()
+	if len(latencies) == 0 {
+		return nil
+	}
+	if err := b.write(start, latencies); err != nil {
+		return fmt.Errorf("cannot write the dependencies of the window starting at %s: %w", start.Format(time.RFC3339), err)
+	}
+	b.stats.Links += int64(len(latencies))
+	return nil
+}
+
+// write writes the links with the latencies of their calls if the dependency storage can store them.
+func (b *Backfiller) write(ts time.Time, latencies []dependencystore.DependencyLatency) error {
+	if writer, ok := b.writer.(dependencystore.LatencyWriter); ok {
+		err := writer.WriteDependencyLatencies(ts, latencies)
+		if !errors.Is(err, dependencystore.ErrDependencyLatenciesNotSupported) {
+			return err
+		}
+	}
+	links := make([]model.DependencyLink, len(latencies))
+	for i, latency := range latencies {
+		links[i] = model.DependencyLink{
+			Parent:    latency.Parent,
+			Child:     latency.Child,
+			CallCount: latency.CallCount,
+			Source:    model.JaegerDependencyLinkSource,
+		}
+	}
+	return b.writer.WriteDependencies(ts, links)
+}
+
+func firstSpanStart(trace *model.Trace) time.Time {
+	var first time.Time
+	for i, span := range trace.Spans {
+		if i == 0 || span.StartTime.Before(first) {
+			first = span.StartTime
+		}
+	}
+	return first
+}
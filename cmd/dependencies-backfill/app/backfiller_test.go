@@ -0,0 +1,267 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var backfillStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// linkWriter records the written dependency links.
+type linkWriter struct {
+	links map[time.Time][]model.DependencyLink
+	err   error
+}
+
+func (w *linkWriter) WriteDependencies(ts time.Time, links []model.DependencyLink) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.links == nil {
+		w.links = make(map[time.Time][]model.DependencyLink)
+	}
+	w.links[ts] = append(w.links[ts], links...)
+	return nil
+}
+
+// latencyWriter records the written dependency latencies, if supported.
+type latencyWriter struct {
+	linkWriter
+	unsupported bool
+	latencies   map[time.Time][]dependencystore.DependencyLatency
+}
+
+func (w *latencyWriter) WriteDependencyLatencies(ts time.Time, latencies []dependencystore.DependencyLatency) error {
+	if w.unsupported {
+		return dependencystore.ErrDependencyLatenciesNotSupported
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if w.latencies == nil {
+		w.latencies = make(map[time.Time][]dependencystore.DependencyLatency)
+	}
+	w.latencies[ts] = append(w.latencies[ts], latencies...)
+	return nil
+}
+
+// failingReader fails to search the traces.
+type failingReader struct {
+	spanstore.Reader
+	servicesErr error
+	findErr     error
+}
+
+func (r failingReader) GetServices(context.Context) ([]string, error) {
+	return []string{"frontend"}, r.servicesErr
+}
+
+func (r failingReader) FindTraces(context.Context, *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	return nil, r.findErr
+}
+
+func backfillSpan(traceID, spanID, parentID uint64, service string, start time.Duration) *model.Span {
+	span := &model.Span{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: "op",
+		StartTime:     backfillStart.Add(start),
+		Duration:      time.Millisecond,
+		Process:       &model.Process{ServiceName: service},
+	}
+	if parentID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(span.TraceID, model.NewSpanID(parentID))}
+	}
+	return span
+}
+
+func newSpanReader(t *testing.T, spans ...*model.Span) *memory.Store {
+	store := memory.NewStore()
+	for _, span := range spans {
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	return store
+}
+
+func backfillOptions() Options {
+	return Options{
+		StartTime: backfillStart.Format(time.RFC3339),
+		EndTime:   backfillStart.Add(3 * time.Hour).Format(time.RFC3339),
+		Window:    time.Hour,
+		MaxTraces: 10,
+	}
+}
+
+func TestBackfiller(t *testing.T) {
+	reader := newSpanReader(t,
+		backfillSpan(1, 1, 0, "frontend", 10*time.Minute),
+		backfillSpan(1, 2, 1, "backend", 11*time.Minute),
+		backfillSpan(1, 3, 2, "db", 12*time.Minute),
+		// the trace overlapping two windows is counted in the window of its first span
+		backfillSpan(2, 4, 0, "frontend", 59*time.Minute),
+		backfillSpan(2, 5, 4, "backend", 61*time.Minute),
+		backfillSpan(3, 6, 0, "frontend", 2*time.Hour),
+		backfillSpan(3, 7, 6, "backend", 2*time.Hour),
+		// outside of the time range
+		backfillSpan(4, 8, 0, "frontend", 4*time.Hour),
+		backfillSpan(4, 9, 8, "backend", 4*time.Hour),
+	)
+	writer := &linkWriter{}
+	checkpoint, err := LoadCheckpoint("")
+	require.NoError(t, err)
+
+	stats, err := NewBackfiller(reader, writer, checkpoint, backfillOptions(), zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Windows: 3, Traces: 3, Links: 3}, stats)
+	assert.Equal(t, map[time.Time][]model.DependencyLink{
+		backfillStart: {
+			{Parent: "backend", Child: "db", CallCount: 1, Source: model.JaegerDependencyLinkSource},
+			{Parent: "frontend", Child: "backend", CallCount: 2, Source: model.JaegerDependencyLinkSource},
+		},
+		backfillStart.Add(2 * time.Hour): {
+			{Parent: "frontend", Child: "backend", CallCount: 1, Source: model.JaegerDependencyLinkSource},
+		},
+	}, writer.links)
+	assert.True(t, checkpoint.Written(backfillStart.Add(3*time.Hour)))
+}
+
+func TestBackfillerLatencies(t *testing.T) {
+	spans := []*model.Span{
+		backfillSpan(1, 1, 0, "frontend", 10*time.Minute),
+		backfillSpan(1, 2, 1, "backend", 11*time.Minute),
+	}
+	opts := backfillOptions()
+	opts.EndTime = backfillStart.Add(time.Hour).Format(time.RFC3339)
+	checkpoint, err := LoadCheckpoint("")
+	require.NoError(t, err)
+
+	writer := &latencyWriter{}
+	_, err = NewBackfiller(newSpanReader(t, spans...), writer, checkpoint, opts, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, writer.links)
+	require.Len(t, writer.latencies[backfillStart], 1)
+	latency := writer.latencies[backfillStart][0]
+	assert.Equal(t, "frontend", latency.Parent)
+	assert.Equal(t, "backend", latency.Child)
+	assert.Equal(t, int64(1), latency.Latency.Count)
+	assert.Equal(t, time.Millisecond, latency.Latency.P50)
+
+	// the links are written without latencies when the dependency storage cannot store them
+	checkpoint, err = LoadCheckpoint("")
+	require.NoError(t, err)
+	writer = &latencyWriter{unsupported: true}
+	_, err = NewBackfiller(newSpanReader(t, spans...), writer, checkpoint, opts, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, writer.latencies)
+	assert.Equal(t, []model.DependencyLink{
+		{Parent: "frontend", Child: "backend", CallCount: 1, Source: model.JaegerDependencyLinkSource},
+	}, writer.links[backfillStart])
+}
+
+func TestBackfillerResumes(t *testing.T) {
+	reader := newSpanReader(t,
+		backfillSpan(1, 1, 0, "frontend", 10*time.Minute),
+		backfillSpan(1, 2, 1, "backend", 11*time.Minute),
+		backfillSpan(2, 3, 0, "frontend", 2*time.Hour),
+		backfillSpan(2, 4, 3, "backend", 2*time.Hour),
+	)
+	checkpoint, err := LoadCheckpoint("")
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.Save(backfillStart.Add(time.Hour)))
+	writer := &linkWriter{}
+
+	stats, err := NewBackfiller(reader, writer, checkpoint, backfillOptions(), zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Windows: 2, Traces: 1, Links: 1}, stats)
+	assert.NotContains(t, writer.links, backfillStart)
+	assert.Contains(t, writer.links, backfillStart.Add(2*time.Hour))
+}
+
+func TestBackfillerErrors(t *testing.T) {
+	spans := []*model.Span{
+		backfillSpan(1, 1, 0, "frontend", 10*time.Minute),
+		backfillSpan(1, 2, 1, "backend", 11*time.Minute),
+	}
+	tests := []struct {
+		name   string
+		reader spanstore.Reader
+		writer dependencystore.Writer
+		err    string
+	}{
+		{
+			name:   "services error",
+			reader: failingReader{servicesErr: errors.New("storage unavailable")},
+			writer: &linkWriter{},
+			err:    "cannot get services: storage unavailable",
+		},
+		{
+			name:   "search error",
+			reader: failingReader{findErr: errors.New("storage unavailable")},
+			writer: &linkWriter{},
+			err:    "cannot find traces of service frontend: storage unavailable",
+		},
+		{
+			name:   "write error",
+			reader: newSpanReader(t, spans...),
+			writer: &linkWriter{err: errors.New("dependency storage unavailable")},
+			err:    "cannot write the dependencies of the window starting at 2024-01-01T00:00:00Z: dependency storage unavailable",
+		},
+		{
+			name:   "latency write error",
+			reader: newSpanReader(t, spans...),
+			writer: &latencyWriter{linkWriter: linkWriter{err: errors.New("dependency storage unavailable")}},
+			err:    "cannot write the dependencies of the window starting at 2024-01-01T00:00:00Z: dependency storage unavailable",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkpoint, err := LoadCheckpoint("")
+			require.NoError(t, err)
+			_, err = NewBackfiller(test.reader, test.writer, checkpoint, backfillOptions(), zap.NewNop()).Run(context.Background())
+			require.EqualError(t, err, test.err)
+			// the failed window is not checkpointed
+			assert.False(t, checkpoint.Written(backfillStart.Add(time.Hour)))
+		})
+	}
+}
+
+func TestBackfillerCanceled(t *testing.T) {
+	checkpoint, err := LoadCheckpoint("")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewBackfiller(memory.NewStore(), &linkWriter{}, checkpoint, backfillOptions(), zap.NewNop()).Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBackfillerTruncatedWindow(t *testing.T) {
+	reader := newSpanReader(t,
+		backfillSpan(1, 1, 0, "frontend", 10*time.Minute),
+		backfillSpan(2, 2, 0, "frontend", 20*time.Minute),
+	)
+	opts := backfillOptions()
+	opts.MaxTraces = 1
+	checkpoint, err := LoadCheckpoint("")
+	require.NoError(t, err)
+	core, logs := observer.New(zap.WarnLevel)
+
+	stats, err := NewBackfiller(reader, &linkWriter{}, checkpoint, opts, zap.New(core)).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Traces)
+	assert.Equal(t, 1, logs.FilterMessageSnippet("reached the maximum").Len())
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint records the end of the last window whose dependencies were written.
+// When created without a file, the checkpoint is only kept in memory.
+type Checkpoint struct {
+	path string
+	End  time.Time `json:"end"`
+}
+
+// LoadCheckpoint reads the checkpoint from the file, if it exists.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint file: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Written returns whether the dependencies of the window ending at end were written.
+func (c *Checkpoint) Written(end time.Time) bool {
+	return !c.End.IsZero() && !end.After(c.End)
+}
+
+// Save records that the dependencies of the window ending at end were written.
+func (c *Checkpoint) Save(end time.Time) error {
+	c.End = end
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	// the file is replaced atomically so that a crash does not lose the previous checkpoint
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("cannot write checkpoint file: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
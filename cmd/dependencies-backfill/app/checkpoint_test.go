@@ -0,0 +1,54 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	end := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	c, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.False(t, c.Written(end))
+	require.NoError(t, c.Save(end))
+
+	// the checkpoint is restored from the file
+	c, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.True(t, c.Written(end.Add(-time.Hour)))
+	assert.True(t, c.Written(end))
+	assert.False(t, c.Written(end.Add(time.Hour)))
+}
+
+func TestCheckpointInMemory(t *testing.T) {
+	c, err := LoadCheckpoint("")
+	require.NoError(t, err)
+	end := time.Now()
+	require.NoError(t, c.Save(end))
+	assert.True(t, c.Written(end))
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+	_, err := LoadCheckpoint(invalid)
+	require.ErrorContains(t, err, "cannot parse checkpoint file")
+
+	_, err = LoadCheckpoint(dir)
+	require.ErrorContains(t, err, "cannot read checkpoint file")
+
+	c, err := LoadCheckpoint(filepath.Join(dir, "missing", "checkpoint.json"))
+	require.NoError(t, err)
+	require.ErrorContains(t, c.Save(time.Now()), "cannot write checkpoint file")
+}
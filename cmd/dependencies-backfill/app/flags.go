@@ -0,0 +1,97 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Options represent configurable parameters for jaeger-dependencies-backfill
+type Options struct {
+	StartTime      string
+	EndTime        string
+	Window         time.Duration
+	MaxTraces      int
+	CheckpointFile string
+}
+
+const (
+	startTimeFlag      = "start-time"
+	endTimeFlag        = "end-time"
+	windowFlag         = "window"
+	maxTracesFlag      = "max-traces"
+	checkpointFileFlag = "checkpoint-file"
+
+	defaultLookback = 24 * time.Hour
+)
+
+// AddFlags adds flags for dependencies-backfill main program
+func (o *Options) AddFlags(command *cobra.Command) {
+	command.Flags().StringVar(
+		&o.StartTime,
+		startTimeFlag,
+		"",
+		"The start of the time range (RFC3339) of the traces the dependencies are computed from. Defaults to one day before end-time")
+	command.Flags().StringVar(
+		&o.EndTime,
+		endTimeFlag,
+		"",
+		"The end of the time range (RFC3339) of the traces the dependencies are computed from. Defaults to now")
+	command.Flags().DurationVar(
+		&o.Window,
+		windowFlag,
+		time.Hour,
+		"The time range is processed in windows of this duration; the dependencies of each window are written "+
+			"at the start of the window and checkpointed once written")
+	command.Flags().IntVar(
+		&o.MaxTraces,
+		maxTracesFlag,
+		1000,
+		"The maximum number of traces searched per service and window. A warning is logged when a window reaches it, "+
+			"in which case a smaller window should be used")
+	command.Flags().StringVar(
+		&o.CheckpointFile,
+		checkpointFileFlag,
+		"",
+		"Path to a file recording the end of the last written window. When set, a restarted backfill resumes after it")
+}
+
+// Validate checks that the combination of options is valid.
+func (o *Options) Validate() error {
+	if o.Window <= 0 {
+		return fmt.Errorf("--%s must be positive", windowFlag)
+	}
+	if o.MaxTraces <= 0 {
+		return fmt.Errorf("--%s must be positive", maxTracesFlag)
+	}
+	_, _, err := o.TimeRange()
+	return err
+}
+
+// TimeRange returns the time range of the traces the dependencies are computed from.
+func (o *Options) TimeRange() (time.Time, time.Time, error) {
+	end := time.Now()
+	if o.EndTime != "" {
+		t, err := time.Parse(time.RFC3339, o.EndTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse --%s: %w", endTimeFlag, err)
+		}
+		end = t
+	}
+	start := end.Add(-defaultLookback)
+	if o.StartTime != "" {
+		t, err := time.Parse(time.RFC3339, o.StartTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse --%s: %w", startTimeFlag, err)
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--%s must be before --%s", startTimeFlag, endTimeFlag)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsWithDefaultFlags(t *testing.T) {
+	o := Options{}
+	c := cobra.Command{}
+	o.AddFlags(&c)
+
+	assert.Equal(t, time.Hour, o.Window)
+	assert.Equal(t, 1000, o.MaxTraces)
+	assert.Empty(t, o.CheckpointFile)
+	require.NoError(t, o.Validate())
+
+	start, end, err := o.TimeRange()
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, end.Sub(start))
+}
+
+func TestOptionsWithFlags(t *testing.T) {
+	o := Options{}
+	c := cobra.Command{}
+	o.AddFlags(&c)
+	require.NoError(t, c.ParseFlags([]string{
+		"--start-time=2024-01-01T00:00:00Z",
+		"--end-time=2024-01-02T00:00:00Z",
+		"--window=10m",
+		"--max-traces=5",
+		"--checkpoint-file=/tmp/backfill.json",
+	}))
+
+	assert.Equal(t, 10*time.Minute, o.Window)
+	assert.Equal(t, 5, o.MaxTraces)
+	assert.Equal(t, "/tmp/backfill.json", o.CheckpointFile)
+	require.NoError(t, o.Validate())
+
+	start, end, err := o.TimeRange()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), start.UTC())
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), end.UTC())
+}
+
+func TestOptionsValidate(t *testing.T) {
+	valid := Options{Window: time.Hour, MaxTraces: 1}
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+		err    string
+	}{
+		{
+			name:   "invalid window",
+			modify: func(o *Options) { o.Window = 0 },
+			err:    "--window must be positive",
+		},
+		{
+			name:   "invalid max traces",
+			modify: func(o *Options) { o.MaxTraces = 0 },
+			err:    "--max-traces must be positive",
+		},
+		{
+			name:   "invalid start time",
+			modify: func(o *Options) { o.StartTime = "yesterday" },
+			err:    "cannot parse --start-time",
+		},
+		{
+			name:   "invalid end time",
+			modify: func(o *Options) { o.EndTime = "today" },
+			err:    "cannot parse --end-time",
+		},
+		{
+			name: "start after end",
+			modify: func(o *Options) {
+				o.StartTime = "2024-01-02T00:00:00Z"
+				o.EndTime = "2024-01-01T00:00:00Z"
+			},
+			err: "--start-time must be before --end-time",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := valid
+			test.modify(&o)
+			require.ErrorContains(t, o.Validate(), test.err)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/dependencies-backfill/app"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+)
+
+var logger, _ = zap.NewDevelopment()
+
+func main() {
+	options := app.Options{}
+	v := viper.New()
+
+	storageFactory, err := storage.NewFactory(storage.FactoryConfigFromEnvAndCLI(os.Args, os.Stderr))
+	if err != nil {
+		log.Fatalf("Cannot initialize storage factory: %v", err)
+	}
+
+	command := &cobra.Command{
+		Use:   "jaeger-dependencies-backfill",
+		Short: "Jaeger dependencies backfill computes the dependency links of stored traces",
+		Long: `Jaeger dependencies backfill computes the dependency links of the traces of a time range, read from the storage
+backend configured via SPAN_STORAGE_TYPE, and writes them to the storage backend configured via DEPENDENCY_STORAGE_TYPE,
+e.g. after enabling the dependencies late, without running the Spark dependencies job.`,
+		Run: func(_ *cobra.Command, _ /* args */ []string) {
+			if err := options.Validate(); err != nil {
+				logger.Fatal("invalid options", zap.Error(err))
+			}
+			if err := backfill(v, storageFactory, &options); err != nil {
+				logger.Fatal("Backfill failed, restart it with the same checkpoint file to resume it", zap.Error(err))
+			}
+		},
+	}
+
+	options.AddFlags(command)
+	config.AddFlags(
+		v,
+		command,
+		storageFactory.AddFlags,
+	)
+
+	command.AddCommand(version.Command())
+
+	if err := command.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func backfill(v *viper.Viper, storageFactory *storage.Factory, options *app.Options) error {
+	checkpoint, err := app.LoadCheckpoint(options.CheckpointFile)
+	if err != nil {
+		return err
+	}
+
+	storageFactory.InitFromViper(v, logger)
+	if err := storageFactory.Initialize(metrics.NullFactory, logger); err != nil {
+		return fmt.Errorf("failed to init storage factory: %w", err)
+	}
+	defer func() {
+		if err := storageFactory.Close(); err != nil {
+			logger.Error("Failed to close storage factory", zap.Error(err))
+		}
+	}()
+
+	reader, err := storageFactory.CreateSpanReader()
+	if err != nil {
+		return fmt.Errorf("failed to create span reader: %w", err)
+	}
+	dependencyReader, err := storageFactory.CreateDependencyReader()
+	if err != nil {
+		return fmt.Errorf("failed to create dependency reader: %w", err)
+	}
+	writer, ok := dependencyReader.(dependencystore.Writer)
+	if !ok {
+		return errors.New("the dependency storage does not support writing dependencies")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stats, err := app.NewBackfiller(reader, writer, checkpoint, *options, logger).Run(ctx)
+	logger.Info("Backfilled dependencies", zap.Int64("windows", stats.Windows), zap.Int64("traces", stats.Traces), zap.Int64("links", stats.Links))
+	return err
+}
@@ -28,6 +28,8 @@ const (
 	indexDateSeparator = "index-date-separator"
 	username           = "es.username"
 	password           = "es.password"
+	dryRun             = "dry-run"
+	maxTotalSizeGB     = "max-total-size-gb"
 )
 
 // Config holds configuration for index cleaner binary.
@@ -40,6 +42,8 @@ type Config struct {
 	Username                 string
 	Password                 string
 	TLSEnabled               bool
+	DryRun                   bool
+	MaxTotalSizeGB           int
 }
 
 // AddFlags adds flags for TLS to the FlagSet.
@@ -51,6 +55,8 @@ func (*Config) AddFlags(flags *flag.FlagSet) {
 	flags.String(indexDateSeparator, "-", "Index date separator")
 	flags.String(username, "", "The username required by storage")
 	flags.String(password, "", "The password required by storage")
+	flags.Bool(dryRun, false, "Only report the indices that would be deleted and the estimated space reclaimed, without deleting them")
+	flags.Int(maxTotalSizeGB, 0, "If greater than zero, the newest indices of each family (span, service, dependencies, sampling) are kept as long as their cumulative size (including replicas) stays under this many GB, older indices are deleted. Applied in addition to NUM_OF_DAYS, which becomes optional")
 }
 
 // InitFromViper initializes config from viper.Viper.
@@ -66,4 +72,6 @@ func (c *Config) InitFromViper(v *viper.Viper) {
 	c.IndexDateSeparator = v.GetString(indexDateSeparator)
 	c.Username = v.GetString(username)
 	c.Password = v.GetString(password)
+	c.DryRun = v.GetBool(dryRun)
+	c.MaxTotalSizeGB = v.GetInt(maxTotalSizeGB)
 }
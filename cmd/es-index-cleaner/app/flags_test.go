@@ -41,6 +41,8 @@ func TestBindFlags(t *testing.T) {
 		"--index-date-separator=@",
 		"--es.username=admin",
 		"--es.password=admin",
+		"--dry-run=true",
+		"--max-total-size-gb=50",
 	})
 	require.NoError(t, err)
 
@@ -52,4 +54,6 @@ func TestBindFlags(t *testing.T) {
 	assert.Equal(t, "@", c.IndexDateSeparator)
 	assert.Equal(t, "admin", c.Username)
 	assert.Equal(t, "admin", c.Password)
+	assert.True(t, c.DryRun)
+	assert.Equal(t, 50, c.MaxTotalSizeGB)
 }
@@ -17,10 +17,10 @@ package app
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/jaegertracing/jaeger/pkg/es/client"
-	"github.com/jaegertracing/jaeger/pkg/es/filter"
 )
 
 // IndexFilter holds configuration for index filtering.
@@ -35,12 +35,65 @@ type IndexFilter struct {
 	Rollover bool
 	// Indices created before this date will be deleted.
 	DeleteBeforeThisDate time.Time
+	// If positive, the newest indices of each family (span, service, dependencies, sampling
+	// or span archive) are kept as long as their cumulative store size does not exceed this
+	// number of bytes, older indices are deleted.
+	MaxTotalSizeBytes int64
 }
 
 // Filter filters indices.
+// An index is selected for deletion if it was created before DeleteBeforeThisDate
+// or if it does not fit into the MaxTotalSizeBytes budget of its family.
 func (i *IndexFilter) Filter(indices []client.Index) []client.Index {
 	indices = i.filter(indices)
-	return filter.ByDate(indices, i.DeleteBeforeThisDate)
+	overBudget := i.exceedingSizeBudget(indices)
+
+	var filtered []client.Index
+	for _, in := range indices {
+		if i.isWriteIndex(in) {
+			continue
+		}
+		if in.CreationTime.Before(i.DeleteBeforeThisDate) || overBudget[in.Index] {
+			filtered = append(filtered, in)
+		}
+	}
+	return filtered
+}
+
+// exceedingSizeBudget walks indices from the newest to the oldest and returns the names
+// of indices which do not fit into the size budget of their family, so that the large span
+// indices do not evict the small service or dependencies indices. Indices in write aliases
+// are accounted for in the budget even though they cannot be removed.
+func (i *IndexFilter) exceedingSizeBudget(indices []client.Index) map[string]bool {
+	if i.MaxTotalSizeBytes <= 0 {
+		return nil
+	}
+	family := regexp.MustCompile(fmt.Sprintf("^%sjaeger-(span-archive|span|service|dependencies|sampling)-", i.IndexPrefix))
+	sorted := make([]client.Index, len(indices))
+	copy(sorted, indices)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].CreationTime.After(sorted[b].CreationTime)
+	})
+
+	exceeding := map[string]bool{}
+	totals := map[string]int64{}
+	for _, in := range sorted {
+		name := family.FindStringSubmatch(in.Index)[1]
+		totals[name] += in.StoreSize
+		if totals[name] > i.MaxTotalSizeBytes {
+			exceeding[in.Index] = true
+		}
+	}
+	return exceeding
+}
+
+// isWriteIndex returns true if the index is in a write alias and therefore cannot be removed.
+func (i *IndexFilter) isWriteIndex(in client.Index) bool {
+	return in.Aliases[i.IndexPrefix+"jaeger-span-write"] ||
+		in.Aliases[i.IndexPrefix+"jaeger-service-write"] ||
+		in.Aliases[i.IndexPrefix+"jaeger-span-archive-write"] ||
+		in.Aliases[i.IndexPrefix+"jaeger-dependencies-write"] ||
+		in.Aliases[i.IndexPrefix+"jaeger-sampling-write"]
 }
 
 func (i *IndexFilter) filter(indices []client.Index) []client.Index {
@@ -58,14 +111,6 @@ func (i *IndexFilter) filter(indices []client.Index) []client.Index {
 	var filtered []client.Index
 	for _, in := range indices {
 		if reg.MatchString(in.Index) {
-			// index in write alias cannot be removed
-			if in.Aliases[i.IndexPrefix+"jaeger-span-write"] ||
-				in.Aliases[i.IndexPrefix+"jaeger-service-write"] ||
-				in.Aliases[i.IndexPrefix+"jaeger-span-archive-write"] ||
-				in.Aliases[i.IndexPrefix+"jaeger-dependencies-write"] ||
-				in.Aliases[i.IndexPrefix+"jaeger-sampling-write"] {
-				continue
-			}
 			filtered = append(filtered, in)
 		}
 	}
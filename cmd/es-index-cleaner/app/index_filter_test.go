@@ -372,3 +372,91 @@ func testIndexFilter(t *testing.T, prefix string) {
 		})
 	}
 }
+
+func TestIndexFilter_maxTotalSize(t *testing.T) {
+	indices := []client.Index{
+		{
+			Index:        "jaeger-span-000001",
+			CreationTime: time.Date(2020, time.August, 0o4, 15, 0, 0, 0, time.UTC),
+			Aliases:      map[string]bool{"jaeger-span-read": true},
+			StoreSize:    30,
+		},
+		{
+			Index:        "jaeger-span-000002",
+			CreationTime: time.Date(2020, time.August, 0o5, 15, 0, 0, 0, time.UTC),
+			Aliases:      map[string]bool{"jaeger-span-read": true},
+			StoreSize:    30,
+		},
+		{
+			Index:        "jaeger-span-000003",
+			CreationTime: time.Date(2020, time.August, 0o6, 15, 0, 0, 0, time.UTC),
+			Aliases:      map[string]bool{"jaeger-span-read": true, "jaeger-span-write": true},
+			StoreSize:    50,
+		},
+		{
+			Index:        "jaeger-service-000001",
+			CreationTime: time.Date(2020, time.August, 0o6, 14, 0, 0, 0, time.UTC),
+			Aliases:      map[string]bool{"jaeger-service-read": true, "jaeger-service-write": true},
+			StoreSize:    10,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filter   *IndexFilter
+		expected []string
+	}{
+		{
+			name: "size budget disabled",
+			filter: &IndexFilter{
+				Rollover:             true,
+				DeleteBeforeThisDate: time.Date(2020, time.August, 0o1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "write indices count against the budget",
+			filter: &IndexFilter{
+				Rollover:             true,
+				DeleteBeforeThisDate: time.Date(2020, time.August, 0o1, 0, 0, 0, 0, time.UTC),
+				MaxTotalSizeBytes:    90,
+			},
+			expected: []string{"jaeger-span-000001"},
+		},
+		{
+			name: "write indices are never deleted",
+			filter: &IndexFilter{
+				Rollover:             true,
+				DeleteBeforeThisDate: time.Date(2020, time.August, 0o1, 0, 0, 0, 0, time.UTC),
+				MaxTotalSizeBytes:    10,
+			},
+			expected: []string{"jaeger-span-000001", "jaeger-span-000002"},
+		},
+		{
+			name: "the budget applies to each index family",
+			filter: &IndexFilter{
+				Rollover:             true,
+				DeleteBeforeThisDate: time.Date(2020, time.August, 0o1, 0, 0, 0, 0, time.UTC),
+				MaxTotalSizeBytes:    85,
+			},
+			expected: []string{"jaeger-span-000001"},
+		},
+		{
+			name: "union with date based deletion",
+			filter: &IndexFilter{
+				Rollover:             true,
+				DeleteBeforeThisDate: time.Date(2020, time.August, 0o5, 0, 0, 0, 0, time.UTC),
+				MaxTotalSizeBytes:    1000,
+			},
+			expected: []string{"jaeger-span-000001"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var names []string
+			for _, in := range test.filter.Filter(indices) {
+				names = append(names, in.Index)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
@@ -32,6 +32,8 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/es/client"
 )
 
+const bytesInGB = 1 << 30
+
 func main() {
 	logger, _ := zap.NewProduction()
 	v := viper.New()
@@ -39,19 +41,24 @@ func main() {
 	tlsFlags := tlscfg.ClientFlagsConfig{Prefix: "es"}
 
 	command := &cobra.Command{
-		Use:   "jaeger-es-index-cleaner NUM_OF_DAYS http://HOSTNAME:PORT",
+		Use:   "jaeger-es-index-cleaner [NUM_OF_DAYS] http://HOSTNAME:PORT",
 		Short: "Jaeger es-index-cleaner removes Jaeger indices",
-		Long:  "Jaeger es-index-cleaner removes Jaeger indices",
+		Long:  "Jaeger es-index-cleaner removes Jaeger indices, NUM_OF_DAYS being optional with --max-total-size-gb",
 		RunE: func(_ *cobra.Command, args []string) error {
-			if len(args) != 2 {
+			cfg.InitFromViper(v)
+			// without NUM_OF_DAYS the indices are only deleted by the size budget
+			numOfDays := -1
+			switch {
+			case len(args) == 2:
+				days, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("could not parse NUM_OF_DAYS argument: %w", err)
+				}
+				numOfDays, args = days, args[1:]
+			case len(args) != 1 || cfg.MaxTotalSizeGB <= 0:
 				return fmt.Errorf("wrong number of arguments")
 			}
-			numOfDays, err := strconv.Atoi(args[0])
-			if err != nil {
-				return fmt.Errorf("could not parse NUM_OF_DAYS argument: %w", err)
-			}
 
-			cfg.InitFromViper(v)
 			tlsOpts, err := tlsFlags.InitFromViper(v)
 			if err != nil {
 				return err
@@ -71,7 +78,7 @@ func main() {
 			}
 			i := client.IndicesClient{
 				Client: client.Client{
-					Endpoint:  args[1],
+					Endpoint:  args[0],
 					Client:    c,
 					BasicAuth: basicAuth(cfg.Username, cfg.Password),
 				},
@@ -82,11 +89,23 @@ func main() {
 			if err != nil {
 				return err
 			}
+			if cfg.DryRun || cfg.MaxTotalSizeGB > 0 {
+				sizes, err := i.GetIndicesStoreSize(cfg.IndexPrefix)
+				if err != nil {
+					return err
+				}
+				for k := range indices {
+					indices[k].StoreSize = sizes[indices[k].Index]
+				}
+			}
 
-			year, month, day := time.Now().UTC().Date()
-			tomorrowMidnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
-			deleteIndicesBefore := tomorrowMidnight.Add(-time.Hour * 24 * time.Duration(numOfDays))
-			logger.Info("Indices before this date will be deleted", zap.String("date", deleteIndicesBefore.Format(time.RFC3339)))
+			var deleteIndicesBefore time.Time
+			if numOfDays >= 0 {
+				year, month, day := time.Now().UTC().Date()
+				tomorrowMidnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+				deleteIndicesBefore = tomorrowMidnight.Add(-time.Hour * 24 * time.Duration(numOfDays))
+				logger.Info("Indices before this date will be deleted", zap.String("date", deleteIndicesBefore.Format(time.RFC3339)))
+			}
 
 			filter := &app.IndexFilter{
 				IndexPrefix:          cfg.IndexPrefix,
@@ -94,6 +113,10 @@ func main() {
 				Archive:              cfg.Archive,
 				Rollover:             cfg.Rollover,
 				DeleteBeforeThisDate: deleteIndicesBefore,
+				MaxTotalSizeBytes:    int64(cfg.MaxTotalSizeGB) * bytesInGB,
+			}
+			if cfg.MaxTotalSizeGB > 0 {
+				logger.Info("Indices not fitting into the size budget of their family, starting from the newest, will be deleted", zap.Int("max_total_size_gb", cfg.MaxTotalSizeGB))
 			}
 			logger.Info("Queried indices", zap.Any("indices", indices))
 			indices = filter.Filter(indices)
@@ -102,6 +125,20 @@ func main() {
 				logger.Info("No indices to delete")
 				return nil
 			}
+			if cfg.DryRun {
+				var reclaimed int64
+				names := make([]string, 0, len(indices))
+				for _, in := range indices {
+					reclaimed += in.StoreSize
+					names = append(names, in.Index)
+				}
+				logger.Info("Dry run, indices would be deleted",
+					zap.Strings("indices", names),
+					zap.Int64("estimated_reclaimed_bytes", reclaimed),
+					zap.String("estimated_reclaimed", fmt.Sprintf("%.2f GB", float64(reclaimed)/bytesInGB)),
+				)
+				return nil
+			}
 			logger.Info("Deleting indices", zap.Any("indices", indices))
 			return i.DeleteIndices(indices)
 		},
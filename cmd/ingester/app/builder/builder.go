@@ -15,9 +15,15 @@
 package builder
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"cloud.google.com/go/pubsub"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kinesis"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/ingester/app"
@@ -30,7 +36,7 @@ import (
 )
 
 // CreateConsumer creates a new span consumer for the ingester
-func CreateConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanWriter spanstore.Writer, options app.Options) (*consumer.Consumer, error) {
+func CreateConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanWriter spanstore.Writer, options app.Options) (consumer.SpanConsumer, error) {
 	var unmarshaller kafka.Unmarshaller
 	switch options.Encoding {
 	case kafka.EncodingJSON:
@@ -50,6 +56,20 @@ func CreateConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanWrit
 	}
 	spanProcessor := processor.NewSpanProcessor(spParams)
 
+	switch options.Source {
+	case app.SourceKafka:
+		return createKafkaConsumer(logger, metricsFactory, spanProcessor, options)
+	case app.SourceKinesis:
+		return createKinesisConsumer(logger, metricsFactory, spanProcessor, options)
+	case app.SourcePubSub:
+		return createPubSubConsumer(logger, metricsFactory, spanProcessor, options)
+	default:
+		return nil, fmt.Errorf(`source '%s' not recognised, use one of ("%s")`,
+			options.Source, strings.Join(app.AllSources, "\", \""))
+	}
+}
+
+func createKafkaConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanProcessor processor.SpanProcessor, options app.Options) (consumer.SpanConsumer, error) {
 	consumerConfig := kafkaConsumer.Configuration{
 		Brokers:              options.Brokers,
 		Topic:                options.Topic,
@@ -79,11 +99,84 @@ func CreateConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanWrit
 	}
 
 	consumerParams := consumer.Params{
-		InternalConsumer:      saramaConsumer,
-		ProcessorFactory:      *processorFactory,
-		MetricsFactory:        metricsFactory,
-		Logger:                logger,
-		DeadlockCheckInterval: options.DeadlockInterval,
+		InternalConsumer:        saramaConsumer,
+		ProcessorFactory:        *processorFactory,
+		MetricsFactory:          metricsFactory,
+		Logger:                  logger,
+		DeadlockCheckInterval:   options.DeadlockInterval,
+		MaxWriteLatency:         options.Throttling.MaxWriteLatency,
+		MaxWriteErrorRate:       options.Throttling.MaxWriteErrorRate,
+		ThrottlingCheckInterval: options.Throttling.CheckInterval,
 	}
 	return consumer.New(consumerParams)
 }
+
+func createKinesisConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanProcessor processor.SpanProcessor, options app.Options) (consumer.SpanConsumer, error) {
+	awsConfig := aws.NewConfig()
+	if options.Kinesis.Region != "" {
+		awsConfig = awsConfig.WithRegion(options.Kinesis.Region)
+	}
+	if options.Kinesis.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(options.Kinesis.Endpoint)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create AWS session: %w", err)
+	}
+	checkpointer, err := consumer.NewFileCheckpointer(options.Kinesis.CheckpointDir)
+	if err != nil {
+		return nil, err
+	}
+
+	processorFactory, err := consumer.NewProcessorFactory(consumer.ProcessorFactoryParams{
+		Parallelism:   options.Parallelism,
+		BaseProcessor: spanProcessor,
+		Logger:        logger,
+		Factory:       metricsFactory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return consumer.NewKinesisConsumer(consumer.KinesisParams{
+		ProcessorFactory: *processorFactory,
+		MetricsFactory:   metricsFactory,
+		Logger:           logger,
+		Client:           kinesis.New(sess),
+		Checkpointer:     checkpointer,
+		StreamName:       options.Kinesis.Stream,
+		InitialPosition:  options.Kinesis.InitialPosition,
+		PollInterval:     options.Kinesis.PollInterval,
+		MaxRecords:       options.Kinesis.MaxRecords,
+	})
+}
+
+func createPubSubConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanProcessor processor.SpanProcessor, options app.Options) (consumer.SpanConsumer, error) {
+	processorFactory, err := consumer.NewProcessorFactory(consumer.ProcessorFactoryParams{
+		Parallelism:   options.Parallelism,
+		BaseProcessor: spanProcessor,
+		Logger:        logger,
+		Factory:       metricsFactory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := pubsub.NewClient(context.Background(), options.PubSub.Project)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create Pub/Sub client: %w", err)
+	}
+	pubsubConsumer, err := consumer.NewPubSubConsumer(consumer.PubSubParams{
+		ProcessorFactory:       *processorFactory,
+		MetricsFactory:         metricsFactory,
+		Logger:                 logger,
+		Client:                 client,
+		SubscriptionID:         options.PubSub.Subscription,
+		MaxOutstandingMessages: options.PubSub.MaxOutstandingMessages,
+	})
+	if err != nil {
+		return nil, errors.Join(err, client.Close())
+	}
+	return pubsubConsumer, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpointer persists the sequence number of the last processed record of each Kinesis shard.
+type Checkpointer interface {
+	// Checkpoint returns the checkpointed sequence number of the shard, or an empty string if there is none.
+	Checkpoint(shardID string) (string, error)
+	// SetCheckpoint records the sequence number of the last processed record of the shard.
+	SetCheckpoint(shardID string, sequenceNumber string) error
+}
+
+type fileCheckpointer struct {
+	directory string
+}
+
+// NewFileCheckpointer creates a Checkpointer that stores the checkpoint of each shard in a file of the given directory.
+func NewFileCheckpointer(directory string) (Checkpointer, error) {
+	if err := os.MkdirAll(directory, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create checkpoint directory: %w", err)
+	}
+	return &fileCheckpointer{directory: directory}, nil
+}
+
+func (c *fileCheckpointer) Checkpoint(shardID string) (string, error) {
+	data, err := os.ReadFile(c.path(shardID))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *fileCheckpointer) SetCheckpoint(shardID string, sequenceNumber string) error {
+	// write to a temporary file first so that a crash never leaves a partial checkpoint
+	tmp := c.path(shardID) + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sequenceNumber), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path(shardID))
+}
+
+func (c *fileCheckpointer) path(shardID string) string {
+	return filepath.Join(c.directory, filepath.Base(shardID))
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCheckpointer(t *testing.T) {
+	c, err := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoints"))
+	require.NoError(t, err)
+
+	checkpoint, err := c.Checkpoint("shardId-000000000000")
+	require.NoError(t, err)
+	assert.Empty(t, checkpoint)
+
+	require.NoError(t, c.SetCheckpoint("shardId-000000000000", "4959"))
+	require.NoError(t, c.SetCheckpoint("shardId-000000000000", "4960"))
+	checkpoint, err = c.Checkpoint("shardId-000000000000")
+	require.NoError(t, err)
+	assert.Equal(t, "4960", checkpoint)
+
+	checkpoint, err = c.Checkpoint("shardId-000000000001")
+	require.NoError(t, err)
+	assert.Empty(t, checkpoint)
+}
+
+func TestFileCheckpointerErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err := NewFileCheckpointer(file)
+	require.ErrorContains(t, err, "cannot create checkpoint directory")
+
+	dir := t.TempDir()
+	c, err := NewFileCheckpointer(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "shard"), 0o750))
+	_, err = c.Checkpoint("shard")
+	require.Error(t, err)
+	require.Error(t, c.SetCheckpoint("shard", "1"))
+}
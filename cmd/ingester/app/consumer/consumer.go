@@ -15,7 +15,11 @@
 package consumer
 
 import (
+	"fmt"
+	"io"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -27,6 +31,34 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 )
 
+// SpanConsumer consumes spans from a message queue until it is closed
+type SpanConsumer interface {
+	Start()
+	io.Closer
+}
+
+// StatusReporter is implemented by the SpanConsumers reporting how far behind they are.
+type StatusReporter interface {
+	Status() Status
+}
+
+// Status is the progress of a consumer on the partitions it holds.
+type Status struct {
+	Partitions []PartitionStatus `json:"partitions"`
+	// TotalLag is the number of messages left to consume on all the partitions.
+	TotalLag int64 `json:"total_lag"`
+	// Throttled is whether the partitions are paused because the storage is unhealthy.
+	Throttled bool `json:"throttled"`
+}
+
+// PartitionStatus is the progress of a consumer on a partition.
+type PartitionStatus struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+	Lag       int64  `json:"lag"`
+}
+
 // Params are the parameters of a Consumer
 type Params struct {
 	ProcessorFactory      ProcessorFactory
@@ -34,6 +66,12 @@ type Params struct {
 	Logger                *zap.Logger
 	InternalConsumer      consumer.Consumer
 	DeadlockCheckInterval time.Duration
+	// MaxWriteLatency is the average latency of the span writes over which the partitions are paused, 0 disabling it
+	MaxWriteLatency time.Duration
+	// MaxWriteErrorRate is the error rate of the span writes over which the partitions are paused, 0 disabling it
+	MaxWriteErrorRate float64
+	// ThrottlingCheckInterval is how often the span writes are checked to pause or resume the partitions
+	ThrottlingCheckInterval time.Duration
 }
 
 // Consumer uses sarama to consume and handle messages from kafka
@@ -46,6 +84,13 @@ type Consumer struct {
 
 	deadlockDetector deadlockDetector
 
+	// throttler is nil when the partitions are never paused
+	throttler               *throttler
+	throttlingCheckInterval time.Duration
+	stopThrottling          chan struct{}
+	// probes counts the probes, to probe the storage with the partitions in turn
+	probes int
+
 	partitionIDToState  map[int32]*consumerState
 	partitionMapLock    sync.Mutex
 	partitionsHeld      int64
@@ -56,25 +101,42 @@ type Consumer struct {
 
 type consumerState struct {
 	partitionConsumer sc.PartitionConsumer
+	offset            atomic.Int64
+	lag               atomic.Int64
+	closed            atomic.Bool
 }
 
 // New is a constructor for a Consumer
 func New(params Params) (*Consumer, error) {
 	deadlockDetector := newDeadlockDetector(params.MetricsFactory, params.Logger, params.DeadlockCheckInterval)
-	return &Consumer{
-		metricsFactory:      params.MetricsFactory,
-		logger:              params.Logger,
-		internalConsumer:    params.InternalConsumer,
-		processorFactory:    params.ProcessorFactory,
-		deadlockDetector:    deadlockDetector,
-		partitionIDToState:  make(map[int32]*consumerState),
-		partitionsHeldGauge: partitionsHeldGauge(params.MetricsFactory),
-	}, nil
+	c := &Consumer{
+		metricsFactory:          params.MetricsFactory,
+		logger:                  params.Logger,
+		internalConsumer:        params.InternalConsumer,
+		processorFactory:        params.ProcessorFactory,
+		deadlockDetector:        deadlockDetector,
+		throttlingCheckInterval: params.ThrottlingCheckInterval,
+		stopThrottling:          make(chan struct{}),
+		partitionIDToState:      make(map[int32]*consumerState),
+		partitionsHeldGauge:     partitionsHeldGauge(params.MetricsFactory),
+	}
+	if params.MaxWriteLatency > 0 || params.MaxWriteErrorRate > 0 {
+		if params.ThrottlingCheckInterval <= 0 {
+			return nil, fmt.Errorf("invalid throttling check interval %v, it must be positive", params.ThrottlingCheckInterval)
+		}
+		c.throttler = newThrottler(params.MaxWriteLatency, params.MaxWriteErrorRate, params.MetricsFactory, params.Logger)
+		c.processorFactory.baseProcessor = c.throttler.observe(c.processorFactory.baseProcessor)
+	}
+	return c, nil
 }
 
 // Start begins consuming messages in a go routine
 func (c *Consumer) Start() {
 	c.deadlockDetector.start()
+	if c.throttler != nil {
+		c.doneWg.Add(1)
+		go c.throttle()
+	}
 	c.doneWg.Add(1)
 	go func() {
 		defer c.doneWg.Done()
@@ -82,6 +144,10 @@ func (c *Consumer) Start() {
 		for pc := range c.internalConsumer.Partitions() {
 			c.partitionMapLock.Lock()
 			c.partitionIDToState[pc.Partition()] = &consumerState{partitionConsumer: pc}
+			// the partitions acquired while the storage is unhealthy start paused
+			if c.throttler != nil && c.throttler.paused.Load() {
+				pc.Pause()
+			}
 			c.partitionMapLock.Unlock()
 			c.partitionMetrics(pc.Topic(), pc.Partition()).startCounter.Inc(1)
 
@@ -100,6 +166,7 @@ func (c *Consumer) Close() error {
 
 	c.logger.Debug("Closing deadlock detector")
 	c.deadlockDetector.close()
+	close(c.stopThrottling)
 
 	c.logger.Debug("Waiting for messages and errors to be handled")
 	c.doneWg.Wait()
@@ -107,6 +174,94 @@ func (c *Consumer) Close() error {
 	return err
 }
 
+// throttle pauses, probes and resumes the partitions as decided by the throttler, until the Consumer is closed.
+func (c *Consumer) throttle() {
+	defer c.doneWg.Done()
+	ticker := time.NewTicker(c.throttlingCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			state, changed := c.throttler.update()
+			if !changed {
+				continue
+			}
+			if state == probing {
+				c.probe()
+			} else {
+				c.setPaused(state == paused)
+			}
+		case <-c.stopThrottling:
+			return
+		}
+	}
+}
+
+// setPaused pauses or resumes the fetching of the messages of the partitions held.
+func (c *Consumer) setPaused(paused bool) {
+	c.partitionMapLock.Lock()
+	defer c.partitionMapLock.Unlock()
+	for _, state := range c.partitionIDToState {
+		if state.closed.Load() {
+			continue
+		}
+		if paused {
+			state.partitionConsumer.Pause()
+		} else {
+			state.partitionConsumer.Resume()
+		}
+	}
+}
+
+// probe resumes a single partition held, the next one at each probe, and pauses the others.
+func (c *Consumer) probe() {
+	c.partitionMapLock.Lock()
+	defer c.partitionMapLock.Unlock()
+	var partitions []int32
+	for partition, state := range c.partitionIDToState {
+		if !state.closed.Load() {
+			partitions = append(partitions, partition)
+		}
+	}
+	if len(partitions) == 0 {
+		return
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	probed := partitions[c.probes%len(partitions)]
+	c.probes++
+	for _, partition := range partitions {
+		if partition == probed {
+			c.partitionIDToState[partition].partitionConsumer.Resume()
+		} else {
+			c.partitionIDToState[partition].partitionConsumer.Pause()
+		}
+	}
+}
+
+// Status implements StatusReporter, with the partitions currently held.
+func (c *Consumer) Status() Status {
+	c.partitionMapLock.Lock()
+	defer c.partitionMapLock.Unlock()
+	status := Status{Partitions: []PartitionStatus{}, Throttled: c.throttler != nil && c.throttler.paused.Load()}
+	for partition, state := range c.partitionIDToState {
+		if state.closed.Load() {
+			continue
+		}
+		lag := state.lag.Load()
+		status.Partitions = append(status.Partitions, PartitionStatus{
+			Topic:     state.partitionConsumer.Topic(),
+			Partition: partition,
+			Offset:    state.offset.Load(),
+			Lag:       lag,
+		})
+		status.TotalLag += lag
+	}
+	sort.Slice(status.Partitions, func(i, j int) bool {
+		return status.Partitions[i].Partition < status.Partitions[j].Partition
+	})
+	return status
+}
+
 // handleMessages handles incoming Kafka messages on a channel
 func (c *Consumer) handleMessages(pc sc.PartitionConsumer) {
 	c.logger.Info("Starting message handler", zap.Int32("partition", pc.Partition()))
@@ -124,6 +279,10 @@ func (c *Consumer) handleMessages(pc sc.PartitionConsumer) {
 	}()
 
 	msgMetrics := c.newMsgMetrics(pc.Topic(), pc.Partition())
+	c.partitionMapLock.Lock()
+	state := c.partitionIDToState[pc.Partition()]
+	c.partitionMapLock.Unlock()
+	defer state.closed.Store(true)
 
 	var msgProcessor processor.SpanProcessor
 
@@ -139,8 +298,11 @@ func (c *Consumer) handleMessages(pc sc.PartitionConsumer) {
 			}
 			c.logger.Debug("Got msg", zap.Any("msg", msg))
 			msgMetrics.counter.Inc(1)
+			lag := pc.HighWaterMarkOffset() - msg.Offset - 1
 			msgMetrics.offsetGauge.Update(msg.Offset)
-			msgMetrics.lagGauge.Update(pc.HighWaterMarkOffset() - msg.Offset - 1)
+			msgMetrics.lagGauge.Update(lag)
+			state.offset.Store(msg.Offset)
+			state.lag.Store(lag)
 			deadlockDetector.incrementMsgCount()
 
 			if msgProcessor == nil {
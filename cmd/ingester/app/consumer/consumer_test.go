@@ -165,7 +165,12 @@ func TestSaramaConsumerWrapper_start_Messages(t *testing.T) {
 	// Ensure that the partition consumer was updated in the map
 	assert.Equal(t, saramaPartitionConsumer.HighWaterMarkOffset(),
 		undertest.partitionIDToState[partition].partitionConsumer.HighWaterMarkOffset())
+	assert.Equal(t, Status{
+		Partitions: []PartitionStatus{{Topic: topic, Partition: partition, Offset: msgOffset, Lag: 1}},
+		TotalLag:   1,
+	}, undertest.Status())
 	undertest.Close()
+	assert.Empty(t, undertest.Status().Partitions)
 
 	localFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
 		Name:  "sarama-consumer.partitions-held",
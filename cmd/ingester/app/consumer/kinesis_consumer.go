@@ -0,0 +1,303 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const (
+	kinesisConsumerNamespace = "kinesis-consumer"
+	// shardSyncInterval is how often the stream is checked for new shards, e.g. after resharding.
+	shardSyncInterval = time.Minute
+)
+
+var errStreamNameRequired = errors.New("kinesis stream name is required")
+
+// KinesisParams are the parameters of a KinesisConsumer
+type KinesisParams struct {
+	ProcessorFactory ProcessorFactory
+	MetricsFactory   metrics.Factory
+	Logger           *zap.Logger
+	Client           kinesisiface.KinesisAPI
+	Checkpointer     Checkpointer
+	StreamName       string
+	// InitialPosition is the shard iterator type used for shards without a checkpoint,
+	// kinesis.ShardIteratorTypeLatest or kinesis.ShardIteratorTypeTrimHorizon.
+	InitialPosition string
+	PollInterval    time.Duration
+	MaxRecords      int64
+}
+
+// KinesisConsumer consumes spans from the shards of an AWS Kinesis stream
+type KinesisConsumer struct {
+	metricsFactory   metrics.Factory
+	logger           *zap.Logger
+	client           kinesisiface.KinesisAPI
+	checkpointer     Checkpointer
+	processorFactory ProcessorFactory
+	streamName       string
+	initialPosition  string
+	pollInterval     time.Duration
+	maxRecords       int64
+
+	// shards maps the IDs of the consumed shards to the partitions reported in metrics and logs.
+	// It is only accessed by the shard discovery loop.
+	shards map[string]int32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	doneWg sync.WaitGroup
+}
+
+type kinesisMetrics struct {
+	counter    metrics.Counter
+	errCounter metrics.Counter
+	lagGauge   metrics.Gauge
+}
+
+type kinesisMessage struct {
+	record    *kinesis.Record
+	stream    string
+	partition int32
+	offset    int64
+}
+
+func (m kinesisMessage) Key() []byte {
+	return []byte(aws.StringValue(m.record.PartitionKey))
+}
+
+func (m kinesisMessage) Value() []byte {
+	return m.record.Data
+}
+
+func (m kinesisMessage) Topic() string {
+	return m.stream
+}
+
+func (m kinesisMessage) Partition() int32 {
+	return m.partition
+}
+
+func (m kinesisMessage) Offset() int64 {
+	return m.offset
+}
+
+// NewKinesisConsumer is a constructor for a KinesisConsumer
+func NewKinesisConsumer(params KinesisParams) (*KinesisConsumer, error) {
+	if params.StreamName == "" {
+		return nil, errStreamNameRequired
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &KinesisConsumer{
+		metricsFactory:   params.MetricsFactory,
+		logger:           params.Logger,
+		client:           params.Client,
+		checkpointer:     params.Checkpointer,
+		processorFactory: params.ProcessorFactory,
+		streamName:       params.StreamName,
+		initialPosition:  params.InitialPosition,
+		pollInterval:     params.PollInterval,
+		maxRecords:       params.MaxRecords,
+		shards:           make(map[string]int32),
+		ctx:              ctx,
+		cancel:           cancel,
+	}, nil
+}
+
+// Start begins consuming the shards of the stream in go routines
+func (c *KinesisConsumer) Start() {
+	c.doneWg.Add(1)
+	go func() {
+		defer c.doneWg.Done()
+		c.logger.Info("Starting Kinesis consumer", zap.String("stream", c.streamName))
+		ticker := time.NewTicker(shardSyncInterval)
+		defer ticker.Stop()
+		for {
+			c.syncShards()
+			select {
+			case <-ticker.C:
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops consuming and waits for the received records to be handled
+func (c *KinesisConsumer) Close() error {
+	c.logger.Info("Closing Kinesis consumer")
+	c.cancel()
+	c.doneWg.Wait()
+	return nil
+}
+
+// syncShards starts consuming the shards of the stream that are not consumed yet.
+func (c *KinesisConsumer) syncShards() {
+	input := &kinesis.ListShardsInput{StreamName: aws.String(c.streamName)}
+	for {
+		out, err := c.client.ListShardsWithContext(c.ctx, input)
+		if err != nil {
+			if c.ctx.Err() == nil {
+				c.logger.Error("Failed to list Kinesis shards", zap.String("stream", c.streamName), zap.Error(err))
+			}
+			return
+		}
+		for _, shard := range out.Shards {
+			shardID := aws.StringValue(shard.ShardId)
+			if _, ok := c.shards[shardID]; ok {
+				continue
+			}
+			partition := int32(len(c.shards))
+			c.shards[shardID] = partition
+			c.doneWg.Add(1)
+			go c.consumeShard(shardID, partition)
+		}
+		if out.NextToken == nil {
+			return
+		}
+		// StreamName must not be set together with NextToken
+		input = &kinesis.ListShardsInput{NextToken: out.NextToken}
+	}
+}
+
+// consumeShard reads the records of a shard until the shard is closed or the consumer is closed.
+func (c *KinesisConsumer) consumeShard(shardID string, partition int32) {
+	defer c.doneWg.Done()
+	c.logger.Info("Starting shard consumer", zap.String("shard", shardID), zap.Int32("partition", partition))
+	msgMetrics := c.newMetrics(shardID)
+
+	pending := &pendingMessages[string]{}
+	markOffset := func(offset int64) {
+		released := pending.release(offset)
+		if len(released) == 0 {
+			return
+		}
+		if err := c.checkpointer.SetCheckpoint(shardID, released[len(released)-1]); err != nil {
+			c.logger.Error("Failed to checkpoint Kinesis shard", zap.String("shard", shardID), zap.Error(err))
+		}
+	}
+	msgProcessor := c.processorFactory.newWithMarker(c.streamName, partition, pending.minOffset(), markOffset)
+	defer msgProcessor.Close()
+
+	var iterator *string
+	// lastSequenceNumber is used to resume reading if the shard iterator expires
+	var lastSequenceNumber string
+	for {
+		if iterator == nil {
+			var err error
+			if iterator, err = c.shardIterator(shardID, lastSequenceNumber); err != nil {
+				if c.ctx.Err() != nil {
+					return
+				}
+				msgMetrics.errCounter.Inc(1)
+				c.logger.Error("Failed to get Kinesis shard iterator", zap.String("shard", shardID), zap.Error(err))
+				if !c.wait() {
+					return
+				}
+				continue
+			}
+		}
+		out, err := c.client.GetRecordsWithContext(c.ctx, &kinesis.GetRecordsInput{
+			ShardIterator: iterator,
+			Limit:         aws.Int64(c.maxRecords),
+		})
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+			msgMetrics.errCounter.Inc(1)
+			c.logger.Error("Error consuming from Kinesis", zap.String("shard", shardID), zap.Error(err))
+			// get a new iterator in case the current one expired
+			iterator = nil
+			if !c.wait() {
+				return
+			}
+			continue
+		}
+		for _, record := range out.Records {
+			lastSequenceNumber = aws.StringValue(record.SequenceNumber)
+			msgMetrics.counter.Inc(1)
+			msg := kinesisMessage{
+				record:    record,
+				stream:    c.streamName,
+				partition: partition,
+				offset:    pending.add(lastSequenceNumber),
+			}
+			if err := msgProcessor.Process(msg); err != nil {
+				c.logger.Error("Failed to process a Kinesis record", zap.Error(err), zap.String("shard", shardID), zap.String("sequence-number", lastSequenceNumber))
+			}
+		}
+		msgMetrics.lagGauge.Update(aws.Int64Value(out.MillisBehindLatest))
+		if out.NextShardIterator == nil {
+			c.logger.Info("Kinesis shard is closed", zap.String("shard", shardID))
+			return
+		}
+		iterator = out.NextShardIterator
+		if !c.wait() {
+			return
+		}
+	}
+}
+
+// shardIterator returns an iterator positioned after the given sequence number,
+// after the checkpoint of the shard, or at the initial position, in that order of preference.
+func (c *KinesisConsumer) shardIterator(shardID string, after string) (*string, error) {
+	if after == "" {
+		checkpoint, err := c.checkpointer.Checkpoint(shardID)
+		if err != nil {
+			return nil, err
+		}
+		after = checkpoint
+	}
+	input := &kinesis.GetShardIteratorInput{
+		StreamName:        aws.String(c.streamName),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: aws.String(c.initialPosition),
+	}
+	if after != "" {
+		input.ShardIteratorType = aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber)
+		input.StartingSequenceNumber = aws.String(after)
+	}
+	out, err := c.client.GetShardIteratorWithContext(c.ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return out.ShardIterator, nil
+}
+
+// wait waits for the poll interval, it returns false if the consumer was closed in the meantime.
+func (c *KinesisConsumer) wait() bool {
+	select {
+	case <-time.After(c.pollInterval):
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+func (c *KinesisConsumer) newMetrics(shardID string) kinesisMetrics {
+	f := c.metricsFactory.Namespace(metrics.NSOptions{
+		Name: kinesisConsumerNamespace,
+		Tags: map[string]string{
+			"stream": c.streamName,
+			"shard":  shardID,
+		},
+	})
+	return kinesisMetrics{
+		counter:    f.Counter(metrics.Options{Name: "messages"}),
+		errCounter: f.Counter(metrics.Options{Name: "errors"}),
+		lagGauge:   f.Gauge(metrics.Options{Name: "millis-behind-latest"}),
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const testStream = "jaeger-spans"
+
+// recordingProcessor records the values of the processed messages.
+type recordingProcessor struct {
+	sync.Mutex
+	values []string
+}
+
+func (p *recordingProcessor) Process(msg processor.Message) error {
+	p.Lock()
+	defer p.Unlock()
+	p.values = append(p.values, string(msg.Value()))
+	return nil
+}
+
+func (*recordingProcessor) Close() error {
+	return nil
+}
+
+func (p *recordingProcessor) processed() []string {
+	p.Lock()
+	defer p.Unlock()
+	return append([]string(nil), p.values...)
+}
+
+type memoryCheckpointer struct {
+	sync.Mutex
+	checkpoints map[string]string
+}
+
+func (c *memoryCheckpointer) Checkpoint(shardID string) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.checkpoints[shardID], nil
+}
+
+func (c *memoryCheckpointer) SetCheckpoint(shardID string, sequenceNumber string) error {
+	c.Lock()
+	defer c.Unlock()
+	c.checkpoints[shardID] = sequenceNumber
+	return nil
+}
+
+// fakeKinesis serves the records of each shard once, and records the requested shard iterators.
+type fakeKinesis struct {
+	kinesisiface.KinesisAPI
+
+	sync.Mutex
+	records        map[string][]*kinesis.Record
+	getRecordsErrs int
+	iterators      []kinesis.GetShardIteratorInput
+}
+
+func (k *fakeKinesis) ListShardsWithContext(_ aws.Context, input *kinesis.ListShardsInput, _ ...request.Option) (*kinesis.ListShardsOutput, error) {
+	if input.NextToken == nil {
+		return &kinesis.ListShardsOutput{
+			Shards:    []*kinesis.Shard{{ShardId: aws.String("shard-0")}},
+			NextToken: aws.String("next"),
+		}, nil
+	}
+	return &kinesis.ListShardsOutput{Shards: []*kinesis.Shard{{ShardId: aws.String("shard-1")}}}, nil
+}
+
+func (k *fakeKinesis) GetShardIteratorWithContext(_ aws.Context, input *kinesis.GetShardIteratorInput, _ ...request.Option) (*kinesis.GetShardIteratorOutput, error) {
+	k.Lock()
+	defer k.Unlock()
+	k.iterators = append(k.iterators, *input)
+	return &kinesis.GetShardIteratorOutput{ShardIterator: input.ShardId}, nil
+}
+
+func (k *fakeKinesis) GetRecordsWithContext(_ aws.Context, input *kinesis.GetRecordsInput, _ ...request.Option) (*kinesis.GetRecordsOutput, error) {
+	k.Lock()
+	defer k.Unlock()
+	if k.getRecordsErrs > 0 {
+		k.getRecordsErrs--
+		return nil, errors.New("expired iterator")
+	}
+	shardID := aws.StringValue(input.ShardIterator)
+	records := k.records[shardID]
+	k.records[shardID] = nil
+	return &kinesis.GetRecordsOutput{
+		Records:            records,
+		NextShardIterator:  input.ShardIterator,
+		MillisBehindLatest: aws.Int64(0),
+	}, nil
+}
+
+func (k *fakeKinesis) shardIterators() []kinesis.GetShardIteratorInput {
+	k.Lock()
+	defer k.Unlock()
+	return append([]kinesis.GetShardIteratorInput(nil), k.iterators...)
+}
+
+func record(sequenceNumber, value string) *kinesis.Record {
+	return &kinesis.Record{
+		SequenceNumber: aws.String(sequenceNumber),
+		PartitionKey:   aws.String("key"),
+		Data:           []byte(value),
+	}
+}
+
+func newKinesisConsumer(t *testing.T, client kinesisiface.KinesisAPI, checkpointer Checkpointer, sp processor.SpanProcessor, metricsFactory metrics.Factory) *KinesisConsumer {
+	c, err := NewKinesisConsumer(KinesisParams{
+		ProcessorFactory: ProcessorFactory{
+			metricsFactory: metricsFactory,
+			logger:         zap.NewNop(),
+			baseProcessor:  sp,
+			parallelism:    1,
+		},
+		MetricsFactory:  metricsFactory,
+		Logger:          zap.NewNop(),
+		Client:          client,
+		Checkpointer:    checkpointer,
+		StreamName:      testStream,
+		InitialPosition: kinesis.ShardIteratorTypeTrimHorizon,
+		PollInterval:    time.Millisecond,
+		MaxRecords:      100,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestNewKinesisConsumerWithoutStream(t *testing.T) {
+	_, err := NewKinesisConsumer(KinesisParams{})
+	require.ErrorIs(t, err, errStreamNameRequired)
+}
+
+func TestKinesisConsumer(t *testing.T) {
+	client := &fakeKinesis{
+		records: map[string][]*kinesis.Record{
+			"shard-0": {record("1", "a"), record("2", "b")},
+			"shard-1": {record("10", "c")},
+		},
+		getRecordsErrs: 1,
+	}
+	checkpointer := &memoryCheckpointer{checkpoints: map[string]string{"shard-1": "9"}}
+	sp := &recordingProcessor{}
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+
+	c := newKinesisConsumer(t, client, checkpointer, sp, metricsFactory)
+	c.Start()
+	assert.Eventually(t, func() bool {
+		checkpoint0, _ := checkpointer.Checkpoint("shard-0")
+		checkpoint1, _ := checkpointer.Checkpoint("shard-1")
+		return checkpoint0 == "2" && checkpoint1 == "10"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, sp.processed())
+	iterators := client.shardIterators()
+	assert.Contains(t, iterators, kinesis.GetShardIteratorInput{
+		StreamName:        aws.String(testStream),
+		ShardId:           aws.String("shard-0"),
+		ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon),
+	})
+	assert.Contains(t, iterators, kinesis.GetShardIteratorInput{
+		StreamName:             aws.String(testStream),
+		ShardId:                aws.String("shard-1"),
+		ShardIteratorType:      aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber),
+		StartingSequenceNumber: aws.String("9"),
+	})
+	// the consumer whose read failed got a new iterator
+	assert.Len(t, iterators, 3)
+
+	_, gauges := metricsFactory.Snapshot()
+	assert.Contains(t, gauges, "kinesis-consumer.millis-behind-latest|shard=shard-0|stream="+testStream)
+}
+
+type closedShardKinesis struct {
+	fakeKinesis
+}
+
+func (*closedShardKinesis) GetRecordsWithContext(aws.Context, *kinesis.GetRecordsInput, ...request.Option) (*kinesis.GetRecordsOutput, error) {
+	return &kinesis.GetRecordsOutput{}, nil
+}
+
+func TestKinesisConsumerClosedShard(t *testing.T) {
+	client := &closedShardKinesis{}
+	c := newKinesisConsumer(t, client, &memoryCheckpointer{checkpoints: map[string]string{}}, &recordingProcessor{}, metrics.NullFactory)
+	c.Start()
+	assert.Eventually(t, func() bool {
+		return len(client.shardIterators()) == 2
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, c.Close())
+}
+
+type failingKinesis struct {
+	kinesisiface.KinesisAPI
+	listed chan struct{}
+}
+
+func (k *failingKinesis) ListShardsWithContext(ctx aws.Context, _ *kinesis.ListShardsInput, _ ...request.Option) (*kinesis.ListShardsOutput, error) {
+	close(k.listed)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestKinesisConsumerCloseWhileListingShards(t *testing.T) {
+	client := &failingKinesis{listed: make(chan struct{})}
+	c := newKinesisConsumer(t, client, &memoryCheckpointer{}, &recordingProcessor{}, metrics.NullFactory)
+	c.Start()
+	<-client.listed
+	require.NoError(t, c.Close())
+}
+
+func TestKinesisMessage(t *testing.T) {
+	msg := kinesisMessage{record: record("1", "value"), stream: testStream, partition: 3, offset: 7}
+	assert.Equal(t, []byte("key"), msg.Key())
+	assert.Equal(t, []byte("value"), msg.Value())
+	assert.Equal(t, testStream, msg.Topic())
+	assert.Equal(t, int32(3), msg.Partition())
+	assert.Equal(t, int64(7), msg.Offset())
+}
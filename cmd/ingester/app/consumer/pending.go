@@ -0,0 +1,49 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"sync"
+)
+
+// pendingMessages assigns sequential offsets to messages from sources without numeric
+// offsets, so that they can be checkpointed by the offset manager like Kafka messages.
+// It keeps the value needed to checkpoint each message until its offset is released.
+type pendingMessages[T any] struct {
+	mutex sync.Mutex
+	// first is the offset of values[0]
+	first  int64
+	values []T
+}
+
+// minOffset is the offset preceding the first one assigned by add.
+func (*pendingMessages[T]) minOffset() int64 {
+	return -1
+}
+
+// add records the value and returns the offset assigned to it.
+func (p *pendingMessages[T]) add(value T) int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.values = append(p.values, value)
+	return p.first + int64(len(p.values)) - 1
+}
+
+// release removes and returns the values with offsets up to and including the given one, in order.
+func (p *pendingMessages[T]) release(offset int64) []T {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	n := offset - p.first + 1
+	if n <= 0 {
+		return nil
+	}
+	if n > int64(len(p.values)) {
+		n = int64(len(p.values))
+	}
+	released := make([]T, n)
+	copy(released, p.values[:n])
+	p.values = p.values[n:]
+	p.first += n
+	return released
+}
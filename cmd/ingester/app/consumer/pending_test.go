@@ -0,0 +1,26 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingMessages(t *testing.T) {
+	p := &pendingMessages[string]{}
+	assert.Equal(t, int64(-1), p.minOffset())
+	assert.Equal(t, int64(0), p.add("a"))
+	assert.Equal(t, int64(1), p.add("b"))
+	assert.Equal(t, int64(2), p.add("c"))
+
+	assert.Nil(t, p.release(-1))
+	assert.Equal(t, []string{"a", "b"}, p.release(1))
+	assert.Nil(t, p.release(1))
+	assert.Equal(t, int64(3), p.add("d"))
+	assert.Equal(t, []string{"c", "d"}, p.release(10))
+	assert.Empty(t, p.release(10))
+	assert.Equal(t, int64(4), p.add("e"))
+}
@@ -64,7 +64,11 @@ func (c *ProcessorFactory) new(topic string, partition int32, minOffset int64) p
 	markOffset := func(offset int64) {
 		c.consumer.MarkPartitionOffset(topic, partition, offset, "")
 	}
+	return c.newWithMarker(topic, partition, minOffset, markOffset)
+}
 
+// newWithMarker creates the processors for a partition, checkpointing processed offsets with markOffset.
+func (c *ProcessorFactory) newWithMarker(topic string, partition int32, minOffset int64, markOffset offset.MarkOffset) processor.SpanProcessor {
 	om := offset.NewManager(minOffset, markOffset, topic, partition, c.metricsFactory)
 
 	retryProcessor := decorator.NewRetryingProcessor(c.metricsFactory, c.baseProcessor, c.retryOptions...)
@@ -75,6 +79,13 @@ func (c *ProcessorFactory) new(topic string, partition int32, minOffset int64) p
 	return newStartedProcessor(pp, om)
 }
 
+// newRetrying creates a processor processing the messages synchronously, with retries, for the sources
+// acknowledging every message when its Process call returns instead of checkpointing offsets.
+func (c *ProcessorFactory) newRetrying() processor.SpanProcessor {
+	retryProcessor := decorator.NewRetryingProcessor(c.metricsFactory, c.baseProcessor, c.retryOptions...)
+	return processor.NewDecoratedProcessor(c.metricsFactory, retryProcessor)
+}
+
 type service interface {
 	Start()
 	io.Closer
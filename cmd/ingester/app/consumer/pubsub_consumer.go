@@ -0,0 +1,115 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const pubsubConsumerNamespace = "pubsub-consumer"
+
+var errSubscriptionRequired = errors.New("pubsub subscription is required")
+
+// PubSubParams are the parameters of a PubSubConsumer
+type PubSubParams struct {
+	ProcessorFactory       ProcessorFactory
+	MetricsFactory         metrics.Factory
+	Logger                 *zap.Logger
+	Client                 *pubsub.Client
+	SubscriptionID         string
+	MaxOutstandingMessages int
+}
+
+// PubSubConsumer consumes spans from a Google Cloud Pub/Sub subscription.
+// Every message is acknowledged when it is processed, or negatively acknowledged to be redelivered
+// if its processing fails, independently of the other messages.
+type PubSubConsumer struct {
+	metricsFactory   metrics.Factory
+	logger           *zap.Logger
+	client           *pubsub.Client
+	subscription     *pubsub.Subscription
+	processorFactory ProcessorFactory
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	doneWg sync.WaitGroup
+}
+
+type pubsubMessage struct {
+	*pubsub.Message
+}
+
+func (m pubsubMessage) Value() []byte {
+	return m.Message.Data
+}
+
+// NewPubSubConsumer is a constructor for a PubSubConsumer
+func NewPubSubConsumer(params PubSubParams) (*PubSubConsumer, error) {
+	if params.SubscriptionID == "" {
+		return nil, errSubscriptionRequired
+	}
+	subscription := params.Client.Subscription(params.SubscriptionID)
+	if params.MaxOutstandingMessages > 0 {
+		subscription.ReceiveSettings.MaxOutstandingMessages = params.MaxOutstandingMessages
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PubSubConsumer{
+		metricsFactory:   params.MetricsFactory,
+		logger:           params.Logger,
+		client:           params.Client,
+		subscription:     subscription,
+		processorFactory: params.ProcessorFactory,
+		ctx:              ctx,
+		cancel:           cancel,
+	}, nil
+}
+
+// Start begins receiving messages in a go routine
+func (c *PubSubConsumer) Start() {
+	c.doneWg.Add(1)
+	go func() {
+		defer c.doneWg.Done()
+		subscriptionID := c.subscription.ID()
+		c.logger.Info("Starting Pub/Sub consumer", zap.String("subscription", subscriptionID))
+		f := c.metricsFactory.Namespace(metrics.NSOptions{
+			Name: pubsubConsumerNamespace,
+			Tags: map[string]string{"subscription": subscriptionID},
+		})
+		msgCounter := f.Counter(metrics.Options{Name: "messages"})
+		errCounter := f.Counter(metrics.Options{Name: "errors"})
+
+		// Receive calls the callback concurrently, up to the maximum of outstanding messages,
+		// so the messages are processed synchronously instead of by the parallel processor.
+		msgProcessor := c.processorFactory.newRetrying()
+
+		err := c.subscription.Receive(c.ctx, func(_ context.Context, msg *pubsub.Message) {
+			msgCounter.Inc(1)
+			if err := msgProcessor.Process(pubsubMessage{Message: msg}); err != nil {
+				c.logger.Error("Failed to process a Pub/Sub message", zap.Error(err), zap.String("id", msg.ID))
+				msg.Nack()
+				return
+			}
+			msg.Ack()
+		})
+		if err != nil {
+			errCounter.Inc(1)
+			c.logger.Error("Error consuming from Pub/Sub", zap.String("subscription", subscriptionID), zap.Error(err))
+		}
+	}()
+}
+
+// Close stops receiving messages, waits for the received messages to be handled and closes the client
+func (c *PubSubConsumer) Close() error {
+	c.logger.Info("Closing Pub/Sub consumer")
+	c.cancel()
+	c.doneWg.Wait()
+	return c.client.Close()
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor/decorator"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const (
+	testProject      = "jaeger"
+	testTopic        = "spans"
+	testSubscription = "jaeger-spans"
+)
+
+func newPubSubClient(t *testing.T, srv *pstest.Server) *pubsub.Client {
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	// the connection is closed with the client
+	client, err := pubsub.NewClient(context.Background(), testProject, option.WithGRPCConn(conn))
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewPubSubConsumerWithoutSubscription(t *testing.T) {
+	_, err := NewPubSubConsumer(PubSubParams{})
+	require.ErrorIs(t, err, errSubscriptionRequired)
+}
+
+func TestPubSubConsumer(t *testing.T) {
+	srv := pstest.NewServer()
+	defer srv.Close()
+	client := newPubSubClient(t, srv)
+
+	ctx := context.Background()
+	topic, err := client.CreateTopic(ctx, testTopic)
+	require.NoError(t, err)
+	defer topic.Stop()
+	_, err = client.CreateSubscription(ctx, testSubscription, pubsub.SubscriptionConfig{Topic: topic})
+	require.NoError(t, err)
+	for _, value := range []string{"a", "b"} {
+		srv.Publish("projects/"+testProject+"/topics/"+testTopic, []byte(value), nil)
+	}
+
+	sp := &recordingProcessor{}
+	c, err := NewPubSubConsumer(PubSubParams{
+		ProcessorFactory: ProcessorFactory{
+			metricsFactory: metrics.NullFactory,
+			logger:         zap.NewNop(),
+			baseProcessor:  sp,
+			parallelism:    1,
+		},
+		MetricsFactory:         metrics.NullFactory,
+		Logger:                 zap.NewNop(),
+		Client:                 client,
+		SubscriptionID:         testSubscription,
+		MaxOutstandingMessages: 10,
+	})
+	require.NoError(t, err)
+	c.Start()
+	assert.Eventually(t, func() bool {
+		msgs := srv.Messages()
+		for _, msg := range msgs {
+			if msg.Acks == 0 {
+				return false
+			}
+		}
+		return len(msgs) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+	assert.ElementsMatch(t, []string{"a", "b"}, sp.processed())
+}
+
+// failingProcessor fails to process the messages of a value.
+type failingProcessor struct {
+	recordingProcessor
+	value string
+}
+
+func (p *failingProcessor) Process(msg processor.Message) error {
+	if string(msg.Value()) == p.value {
+		return errors.New("processing failed")
+	}
+	return p.recordingProcessor.Process(msg)
+}
+
+func TestPubSubConsumerFailedMessage(t *testing.T) {
+	srv := pstest.NewServer()
+	defer srv.Close()
+	client := newPubSubClient(t, srv)
+
+	ctx := context.Background()
+	topic, err := client.CreateTopic(ctx, testTopic)
+	require.NoError(t, err)
+	defer topic.Stop()
+	_, err = client.CreateSubscription(ctx, testSubscription, pubsub.SubscriptionConfig{Topic: topic})
+	require.NoError(t, err)
+	ids := map[string]string{}
+	for _, value := range []string{"a", "bad", "b", "c"} {
+		ids[value] = srv.Publish("projects/"+testProject+"/topics/"+testTopic, []byte(value), nil)
+	}
+
+	sp := &failingProcessor{value: "bad"}
+	c, err := NewPubSubConsumer(PubSubParams{
+		ProcessorFactory: ProcessorFactory{
+			metricsFactory: metrics.NullFactory,
+			logger:         zap.NewNop(),
+			baseProcessor:  sp,
+			parallelism:    1,
+			retryOptions:   []decorator.RetryOption{decorator.MaxAttempts(0), decorator.PropagateError(true)},
+		},
+		MetricsFactory:         metrics.NullFactory,
+		Logger:                 zap.NewNop(),
+		Client:                 client,
+		SubscriptionID:         testSubscription,
+		MaxOutstandingMessages: 10,
+	})
+	require.NoError(t, err)
+	c.Start()
+	// the messages received after the failed one are acknowledged, the failed one being redelivered
+	assert.Eventually(t, func() bool {
+		for _, value := range []string{"a", "b", "c"} {
+			if srv.Message(ids[value]).Acks == 0 {
+				return false
+			}
+		}
+		return srv.Message(ids["bad"]).Deliveries > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+	assert.Zero(t, srv.Message(ids["bad"]).Acks)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, sp.processed())
+}
+
+func TestPubSubMessage(t *testing.T) {
+	msg := pubsubMessage{Message: &pubsub.Message{Data: []byte("value")}}
+	assert.Equal(t, []byte("value"), msg.Value())
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// probeIntervals is the number of check intervals without writes after which the paused
+// consumption probes the storage with a single partition.
+const probeIntervals = 3
+
+// throttling is the consumption of the partitions decided by the throttler.
+type throttling int
+
+const (
+	// resumed consumes all the partitions.
+	resumed throttling = iota
+	// paused consumes no partition.
+	paused
+	// probing consumes a single partition, whose writes tell whether the storage is healthy again.
+	probing
+)
+
+// throttler decides to pause the consumption of the partitions while the storage is unhealthy,
+// i.e. while the average latency or the error rate of the span writes over the last check interval
+// exceed their thresholds, so that Kafka keeps the messages the storage cannot absorb. The partitions
+// are resumed after an interval of healthy writes, including the retries of the messages received
+// before the pause. An interval without writes tells nothing about the storage and keeps the state,
+// except that once the paused partitions have had no writes for probeIntervals, a single partition
+// is consumed to probe the storage, the next one whenever the probed one has no writes either.
+type throttler struct {
+	maxLatency   time.Duration
+	maxErrorRate float64
+	logger       *zap.Logger
+
+	pauses      metrics.Counter
+	resumes     metrics.Counter
+	probes      metrics.Counter
+	pausedGauge metrics.Gauge
+
+	// the writes since the last update
+	latencySum atomic.Int64
+	writes     atomic.Int64
+	errors     atomic.Int64
+
+	// state and idleIntervals are only accessed by update
+	state         throttling
+	idleIntervals int
+	// paused is whether the partitions are not all consumed, for the other goroutines
+	paused atomic.Bool
+}
+
+func newThrottler(maxLatency time.Duration, maxErrorRate float64, metricsFactory metrics.Factory, logger *zap.Logger) *throttler {
+	f := metricsFactory.Namespace(metrics.NSOptions{Name: consumerNamespace, Tags: nil})
+	return &throttler{
+		maxLatency:   maxLatency,
+		maxErrorRate: maxErrorRate,
+		logger:       logger,
+		pauses:       f.Counter(metrics.Options{Name: "throttling", Tags: map[string]string{"action": "pause"}}),
+		resumes:      f.Counter(metrics.Options{Name: "throttling", Tags: map[string]string{"action": "resume"}}),
+		probes:       f.Counter(metrics.Options{Name: "throttling", Tags: map[string]string{"action": "probe"}}),
+		pausedGauge:  f.Gauge(metrics.Options{Name: "throttled", Tags: nil}),
+	}
+}
+
+// observe returns a processor recording the latency and the errors of the processor.
+func (t *throttler) observe(p processor.SpanProcessor) processor.SpanProcessor {
+	return &observedProcessor{SpanProcessor: p, throttler: t}
+}
+
+// update returns the consumption of the partitions according to the writes since the last update,
+// and whether it must be applied, i.e. it changed or the probe moves to the next partition.
+func (t *throttler) update() (throttling, bool) {
+	latencySum, writes, errors := t.latencySum.Swap(0), t.writes.Swap(0), t.errors.Swap(0)
+	if writes == 0 {
+		if t.state == resumed {
+			return t.state, false
+		}
+		t.idleIntervals++
+		if t.idleIntervals < probeIntervals {
+			return t.state, false
+		}
+		t.idleIntervals = 0
+		t.state = probing
+		t.probes.Inc(1)
+		t.logger.Info("Probing the storage with a single partition, the paused partitions have no writes")
+		return probing, true
+	}
+	t.idleIntervals = 0
+	latency := time.Duration(latencySum / writes)
+	errorRate := float64(errors) / float64(writes)
+	unhealthy := (t.maxLatency > 0 && latency > t.maxLatency) || (t.maxErrorRate > 0 && errorRate > t.maxErrorRate)
+	switch {
+	case unhealthy && t.state != paused:
+		t.state = paused
+		t.paused.Store(true)
+		t.pauses.Inc(1)
+		t.pausedGauge.Update(1)
+		t.logger.Warn("Pausing the consumption of the partitions, the storage is unhealthy",
+			zap.Duration("average-write-latency", latency), zap.Float64("write-error-rate", errorRate))
+		return paused, true
+	case !unhealthy && t.state != resumed:
+		t.state = resumed
+		t.paused.Store(false)
+		t.resumes.Inc(1)
+		t.pausedGauge.Update(0)
+		t.logger.Info("Resuming the consumption of the partitions, the storage is healthy again")
+		return resumed, true
+	default:
+		return t.state, false
+	}
+}
+
+// observedProcessor records the latency and the errors of a processor for the throttler.
+type observedProcessor struct {
+	processor.SpanProcessor
+	throttler *throttler
+}
+
+func (p *observedProcessor) Process(message processor.Message) error {
+	start := time.Now()
+	err := p.SpanProcessor.Process(message)
+	p.throttler.latencySum.Add(int64(time.Since(start)))
+	p.throttler.writes.Add(1)
+	if err != nil {
+		p.throttler.errors.Add(1)
+	}
+	return err
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	smocks "github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// writingProcessor takes latency to process a message and fails if err is set.
+type writingProcessor struct {
+	latency time.Duration
+	err     error
+}
+
+func (p *writingProcessor) Process(processor.Message) error {
+	time.Sleep(p.latency)
+	return p.err
+}
+
+func (*writingProcessor) Close() error {
+	return nil
+}
+
+func TestThrottler(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxLatency   time.Duration
+		maxErrorRate float64
+		processor    *writingProcessor
+		paused       bool
+	}{
+		{
+			name:       "healthy",
+			maxLatency: time.Second,
+			processor:  &writingProcessor{},
+		},
+		{
+			name:       "slow writes",
+			maxLatency: time.Millisecond,
+			processor:  &writingProcessor{latency: 5 * time.Millisecond},
+			paused:     true,
+		},
+		{
+			name:         "failed writes",
+			maxErrorRate: 0.5,
+			processor:    &writingProcessor{err: errors.New("storage unavailable")},
+			paused:       true,
+		},
+		{
+			name:       "failed writes without error rate threshold",
+			maxLatency: time.Second,
+			processor:  &writingProcessor{err: errors.New("storage unavailable")},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th := newThrottler(test.maxLatency, test.maxErrorRate, metrics.NullFactory, zap.NewNop())
+			p := th.observe(test.processor)
+			for i := 0; i < 2; i++ {
+				p.Process(saramaMessageWrapper{&sarama.ConsumerMessage{}})
+			}
+			state, changed := th.update()
+			assert.Equal(t, test.paused, state == paused)
+			assert.Equal(t, test.paused, changed)
+			assert.Equal(t, test.paused, th.paused.Load())
+			// an interval without writes keeps the state
+			state, changed = th.update()
+			assert.Equal(t, test.paused, state == paused)
+			assert.False(t, changed)
+		})
+	}
+}
+
+func TestThrottlerProbes(t *testing.T) {
+	wp := &writingProcessor{err: errors.New("storage unavailable")}
+	th := newThrottler(0, 0.5, metrics.NullFactory, zap.NewNop())
+	p := th.observe(wp)
+	p.Process(saramaMessageWrapper{&sarama.ConsumerMessage{}})
+	state, changed := th.update()
+	require.Equal(t, paused, state)
+	require.True(t, changed)
+
+	for i := 1; i < probeIntervals; i++ {
+		state, changed = th.update()
+		assert.Equal(t, paused, state)
+		assert.False(t, changed)
+	}
+	state, changed = th.update()
+	assert.Equal(t, probing, state)
+	assert.True(t, changed)
+	assert.True(t, th.paused.Load())
+
+	// the probe moves to the next partition while the probed one has no writes
+	for i := 1; i < probeIntervals; i++ {
+		state, changed = th.update()
+		assert.Equal(t, probing, state)
+		assert.False(t, changed)
+	}
+	state, changed = th.update()
+	assert.Equal(t, probing, state)
+	assert.True(t, changed)
+
+	// the probe fails
+	p.Process(saramaMessageWrapper{&sarama.ConsumerMessage{}})
+	state, changed = th.update()
+	assert.Equal(t, paused, state)
+	assert.True(t, changed)
+
+	for i := 0; i < probeIntervals; i++ {
+		state, _ = th.update()
+	}
+	require.Equal(t, probing, state)
+
+	// the probe succeeds
+	wp.err = nil
+	p.Process(saramaMessageWrapper{&sarama.ConsumerMessage{}})
+	state, changed = th.update()
+	assert.Equal(t, resumed, state)
+	assert.True(t, changed)
+	assert.False(t, th.paused.Load())
+}
+
+func TestThrottlerMetrics(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	th := newThrottler(0, 0.1, mb, zap.NewNop())
+	wp := &writingProcessor{err: errors.New("storage unavailable")}
+	p := th.observe(wp)
+	p.Process(saramaMessageWrapper{&sarama.ConsumerMessage{}})
+	th.update()
+	mb.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "sarama-consumer.throttled", Value: 1})
+	for i := 0; i < probeIntervals; i++ {
+		th.update()
+	}
+	wp.err = nil
+	p.Process(saramaMessageWrapper{&sarama.ConsumerMessage{}})
+	th.update()
+
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "sarama-consumer.throttling", Tags: map[string]string{"action": "pause"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "sarama-consumer.throttling", Tags: map[string]string{"action": "probe"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "sarama-consumer.throttling", Tags: map[string]string{"action": "resume"}, Value: 1},
+	)
+	mb.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "sarama-consumer.throttled", Value: 0})
+}
+
+func TestConsumerThrottling(t *testing.T) {
+	saramaConsumer := smocks.NewConsumer(t, &sarama.Config{})
+	mc := saramaConsumer.ExpectConsumePartition(topic, partition, msgOffset)
+	mc.ExpectMessagesDrainedOnClose()
+	saramaPartitionConsumer, err := saramaConsumer.ConsumePartition(topic, partition, msgOffset)
+	require.NoError(t, err)
+	undertest := newConsumer(t, metrics.NullFactory, topic, &writingProcessor{latency: 5 * time.Millisecond},
+		newSaramaClusterConsumer(saramaPartitionConsumer, mc))
+	undertest.throttler = newThrottler(time.Millisecond, 0, metrics.NullFactory, zap.NewNop())
+	undertest.processorFactory.baseProcessor = undertest.throttler.observe(undertest.processorFactory.baseProcessor)
+	undertest.throttlingCheckInterval = time.Hour
+
+	undertest.Start()
+	mc.YieldMessage(&sarama.ConsumerMessage{})
+	assert.Eventually(t, func() bool {
+		return undertest.throttler.writes.Load() > 0
+	}, 5*time.Second, time.Millisecond)
+
+	state, changed := undertest.throttler.update()
+	require.Equal(t, paused, state)
+	require.True(t, changed)
+	undertest.setPaused(true)
+	assert.True(t, mc.IsPaused())
+	assert.True(t, undertest.Status().Throttled)
+
+	undertest.setPaused(false)
+	assert.False(t, mc.IsPaused())
+	require.NoError(t, undertest.Close())
+}
+
+func TestConsumerThrottlingProbesWithoutTraffic(t *testing.T) {
+	saramaConsumer := smocks.NewConsumer(t, &sarama.Config{})
+	mc := saramaConsumer.ExpectConsumePartition(topic, partition, msgOffset)
+	mc.ExpectMessagesDrainedOnClose()
+	saramaPartitionConsumer, err := saramaConsumer.ConsumePartition(topic, partition, msgOffset)
+	require.NoError(t, err)
+	undertest := newConsumer(t, metrics.NullFactory, topic, &writingProcessor{latency: 5 * time.Millisecond},
+		newSaramaClusterConsumer(saramaPartitionConsumer, mc))
+	undertest.throttler = newThrottler(time.Millisecond, 0, metrics.NullFactory, zap.NewNop())
+	undertest.processorFactory.baseProcessor = undertest.throttler.observe(undertest.processorFactory.baseProcessor)
+	undertest.throttlingCheckInterval = 10 * time.Millisecond
+
+	undertest.Start()
+	// the single slow write pauses the partition, then no message is received
+	mc.YieldMessage(&sarama.ConsumerMessage{})
+	assert.Eventually(t, undertest.throttler.paused.Load, 5*time.Second, time.Millisecond)
+
+	// the partition is resumed to probe the storage while the consumer is still throttled
+	assert.Eventually(t, func() bool {
+		return !mc.IsPaused() && undertest.Status().Throttled
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, undertest.Close())
+}
+
+func TestNewConsumerThrottlingErrors(t *testing.T) {
+	_, err := New(Params{MetricsFactory: metrics.NullFactory, MaxWriteLatency: time.Second})
+	require.ErrorContains(t, err, "invalid throttling check interval")
+
+	c, err := New(Params{
+		MetricsFactory:          metrics.NullFactory,
+		Logger:                  zap.NewNop(),
+		MaxWriteErrorRate:       0.5,
+		ThrottlingCheckInterval: time.Second,
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, c.throttler)
+}
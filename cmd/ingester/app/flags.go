@@ -33,6 +33,12 @@ const (
 	ConfigPrefix = "ingester"
 	// KafkaConsumerConfigPrefix is a prefix for the Kafka flags
 	KafkaConsumerConfigPrefix = "kafka.consumer"
+	// KinesisConsumerConfigPrefix is a prefix for the Kinesis flags
+	KinesisConsumerConfigPrefix = "kinesis.consumer"
+	// PubSubConsumerConfigPrefix is a prefix for the Pub/Sub flags
+	PubSubConsumerConfigPrefix = "pubsub.consumer"
+	// SuffixSource is a suffix for the source flag
+	SuffixSource = ".source"
 	// SuffixBrokers is a suffix for the brokers flag
 	SuffixBrokers = ".brokers"
 	// SuffixTopic is a suffix for the topic flag
@@ -51,10 +57,42 @@ const (
 	SuffixEncoding = ".encoding"
 	// SuffixDeadlockInterval is a suffix for deadlock detecor flag
 	SuffixDeadlockInterval = ".deadlockInterval"
+	// SuffixMaxWriteLatency is a suffix for the throttling max write latency flag
+	SuffixMaxWriteLatency = ".throttling.max-write-latency"
+	// SuffixMaxWriteErrorRate is a suffix for the throttling max write error rate flag
+	SuffixMaxWriteErrorRate = ".throttling.max-write-error-rate"
+	// SuffixThrottlingCheckInterval is a suffix for the throttling check interval flag
+	SuffixThrottlingCheckInterval = ".throttling.check-interval"
 	// SuffixParallelism is a suffix for the parallelism flag
 	SuffixParallelism = ".parallelism"
 	// SuffixHTTPPort is a suffix for the HTTP port
 	SuffixHTTPPort = ".http-port"
+	// SuffixStream is a suffix for the Kinesis stream flag
+	SuffixStream = ".stream"
+	// SuffixRegion is a suffix for the Kinesis region flag
+	SuffixRegion = ".region"
+	// SuffixEndpoint is a suffix for the Kinesis endpoint flag
+	SuffixEndpoint = ".endpoint"
+	// SuffixInitialPosition is a suffix for the Kinesis initial position flag
+	SuffixInitialPosition = ".initial-position"
+	// SuffixPollInterval is a suffix for the Kinesis poll interval flag
+	SuffixPollInterval = ".poll-interval"
+	// SuffixMaxRecords is a suffix for the Kinesis max records flag
+	SuffixMaxRecords = ".max-records"
+	// SuffixCheckpointDir is a suffix for the Kinesis checkpoint directory flag
+	SuffixCheckpointDir = ".checkpoint-dir"
+	// SuffixProject is a suffix for the Pub/Sub project flag
+	SuffixProject = ".project"
+	// SuffixSubscription is a suffix for the Pub/Sub subscription flag
+	SuffixSubscription = ".subscription"
+	// SuffixMaxOutstandingMessages is a suffix for the Pub/Sub max outstanding messages flag
+	SuffixMaxOutstandingMessages = ".max-outstanding-messages"
+	// SourceKafka consumes spans from Kafka
+	SourceKafka = "kafka"
+	// SourceKinesis consumes spans from AWS Kinesis
+	SourceKinesis = "kinesis"
+	// SourcePubSub consumes spans from Google Cloud Pub/Sub
+	SourcePubSub = "pubsub"
 	// DefaultBroker is the default kafka broker
 	DefaultBroker = "127.0.0.1:9092"
 	// DefaultTopic is the default kafka topic
@@ -69,20 +107,75 @@ const (
 	DefaultEncoding = kafka.EncodingProto
 	// DefaultDeadlockInterval is the default deadlock interval
 	DefaultDeadlockInterval = time.Duration(0)
+	// DefaultThrottlingCheckInterval is the default interval between the checks of the span writes
+	DefaultThrottlingCheckInterval = 5 * time.Second
 	// DefaultFetchMaxMessageBytes is the default for kafka.consumer.fetch-max-message-bytes flag
 	DefaultFetchMaxMessageBytes = 1024 * 1024 // 1MB
+	// DefaultSource is the default source of spans
+	DefaultSource = SourceKafka
+	// DefaultStream is the default Kinesis stream
+	DefaultStream = "jaeger-spans"
+	// DefaultInitialPosition is the default position of Kinesis shards without a checkpoint
+	DefaultInitialPosition = "LATEST"
+	// DefaultPollInterval is the default interval between reads of a Kinesis shard
+	DefaultPollInterval = time.Second
+	// DefaultMaxRecords is the default maximum number of records read from a Kinesis shard at once
+	DefaultMaxRecords = 1000
+	// DefaultCheckpointDir is the default directory storing the Kinesis checkpoints
+	DefaultCheckpointDir = "/tmp/jaeger-ingester/checkpoints"
+	// DefaultSubscription is the default Pub/Sub subscription
+	DefaultSubscription = "jaeger-spans"
+	// DefaultMaxOutstandingMessages is the default maximum number of unacknowledged Pub/Sub messages
+	DefaultMaxOutstandingMessages = 1000
 )
 
+// AllSources lists the supported sources of spans
+var AllSources = []string{SourceKafka, SourceKinesis, SourcePubSub}
+
 // Options stores the configuration options for the Ingester
 type Options struct {
 	kafkaConsumer.Configuration `mapstructure:",squash"`
-	Parallelism                 int           `mapstructure:"parallelism"`
-	Encoding                    string        `mapstructure:"encoding"`
-	DeadlockInterval            time.Duration `mapstructure:"deadlock_interval"`
+	Source                      string            `mapstructure:"source"`
+	Kinesis                     KinesisOptions    `mapstructure:"kinesis"`
+	PubSub                      PubSubOptions     `mapstructure:"pubsub"`
+	Parallelism                 int               `mapstructure:"parallelism"`
+	Encoding                    string            `mapstructure:"encoding"`
+	DeadlockInterval            time.Duration     `mapstructure:"deadlock_interval"`
+	Throttling                  ThrottlingOptions `mapstructure:"throttling"`
+}
+
+// ThrottlingOptions stores the thresholds of the span writes over which the Kafka partitions are paused
+type ThrottlingOptions struct {
+	MaxWriteLatency   time.Duration `mapstructure:"max_write_latency"`
+	MaxWriteErrorRate float64       `mapstructure:"max_write_error_rate"`
+	CheckInterval     time.Duration `mapstructure:"check_interval"`
+}
+
+// KinesisOptions stores the configuration options for consuming spans from AWS Kinesis
+type KinesisOptions struct {
+	Stream          string        `mapstructure:"stream"`
+	Region          string        `mapstructure:"region"`
+	Endpoint        string        `mapstructure:"endpoint"`
+	InitialPosition string        `mapstructure:"initial_position"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	MaxRecords      int64         `mapstructure:"max_records"`
+	CheckpointDir   string        `mapstructure:"checkpoint_dir"`
+}
+
+// PubSubOptions stores the configuration options for consuming spans from Google Cloud Pub/Sub
+type PubSubOptions struct {
+	Project                string `mapstructure:"project"`
+	Subscription           string `mapstructure:"subscription"`
+	MaxOutstandingMessages int    `mapstructure:"max_outstanding_messages"`
 }
 
 // AddFlags adds flags for Builder
 func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		ConfigPrefix+SuffixSource,
+		DefaultSource,
+		fmt.Sprintf(`The source of spans ("%s"), spans are decoded using the %s flag for all sources`,
+			strings.Join(AllSources, "\", \""), KafkaConsumerConfigPrefix+SuffixEncoding))
 	flagSet.String(
 		ConfigPrefix+SuffixParallelism,
 		strconv.Itoa(DefaultParallelism),
@@ -91,6 +184,18 @@ func AddFlags(flagSet *flag.FlagSet) {
 		ConfigPrefix+SuffixDeadlockInterval,
 		DefaultDeadlockInterval,
 		"Interval to check for deadlocks. If no messages gets processed in given time, ingester app will exit. Value of 0 disables deadlock check.")
+	flagSet.Duration(
+		ConfigPrefix+SuffixMaxWriteLatency,
+		0,
+		"The average latency of the span writes over which the consumption of the Kafka partitions is paused until the storage recovers. Value of 0 disables it.")
+	flagSet.Float64(
+		ConfigPrefix+SuffixMaxWriteErrorRate,
+		0,
+		"The ratio of failed span writes, between 0 and 1, over which the consumption of the Kafka partitions is paused until the storage recovers. Value of 0 disables it.")
+	flagSet.Duration(
+		ConfigPrefix+SuffixThrottlingCheckInterval,
+		DefaultThrottlingCheckInterval,
+		"Interval to check the latency and the error rate of the span writes, to pause or resume the consumption of the Kafka partitions; after 3 intervals paused without writes, a single partition is consumed to probe the storage.")
 
 	// Authentication flags
 	flagSet.String(
@@ -127,6 +232,48 @@ func AddFlags(flagSet *flag.FlagSet) {
 		"The maximum number of message bytes to fetch from the broker in a single request. So you must be sure this is at least as large as your largest message.")
 
 	auth.AddFlags(KafkaConsumerConfigPrefix, flagSet)
+
+	flagSet.String(
+		KinesisConsumerConfigPrefix+SuffixStream,
+		DefaultStream,
+		"The name of the Kinesis stream to consume from")
+	flagSet.String(
+		KinesisConsumerConfigPrefix+SuffixRegion,
+		"",
+		"The AWS region of the Kinesis stream, the default region of the AWS SDK is used if empty")
+	flagSet.String(
+		KinesisConsumerConfigPrefix+SuffixEndpoint,
+		"",
+		"Custom Kinesis endpoint, e.g. for a local emulator")
+	flagSet.String(
+		KinesisConsumerConfigPrefix+SuffixInitialPosition,
+		DefaultInitialPosition,
+		`The position to start consuming shards without a checkpoint from ("LATEST", "TRIM_HORIZON")`)
+	flagSet.Duration(
+		KinesisConsumerConfigPrefix+SuffixPollInterval,
+		DefaultPollInterval,
+		"The interval between reads of each Kinesis shard")
+	flagSet.Int64(
+		KinesisConsumerConfigPrefix+SuffixMaxRecords,
+		DefaultMaxRecords,
+		"The maximum number of records to read from a Kinesis shard at once")
+	flagSet.String(
+		KinesisConsumerConfigPrefix+SuffixCheckpointDir,
+		DefaultCheckpointDir,
+		"The directory where the sequence number of the last processed record of each Kinesis shard is stored")
+
+	flagSet.String(
+		PubSubConsumerConfigPrefix+SuffixProject,
+		"",
+		"The Google Cloud project of the Pub/Sub subscription")
+	flagSet.String(
+		PubSubConsumerConfigPrefix+SuffixSubscription,
+		DefaultSubscription,
+		"The name of the Pub/Sub subscription to consume from")
+	flagSet.Int(
+		PubSubConsumerConfigPrefix+SuffixMaxOutstandingMessages,
+		DefaultMaxOutstandingMessages,
+		"The maximum number of received Pub/Sub messages that are not acknowledged yet")
 }
 
 // InitFromViper initializes Builder with properties from viper
@@ -140,11 +287,27 @@ func (o *Options) InitFromViper(v *viper.Viper) {
 	o.RackID = v.GetString(KafkaConsumerConfigPrefix + SuffixRackID)
 	o.FetchMaxMessageBytes = v.GetInt32(KafkaConsumerConfigPrefix + SuffixFetchMaxMessageBytes)
 
+	o.Source = v.GetString(ConfigPrefix + SuffixSource)
 	o.Parallelism = v.GetInt(ConfigPrefix + SuffixParallelism)
 	o.DeadlockInterval = v.GetDuration(ConfigPrefix + SuffixDeadlockInterval)
+	o.Throttling.MaxWriteLatency = v.GetDuration(ConfigPrefix + SuffixMaxWriteLatency)
+	o.Throttling.MaxWriteErrorRate = v.GetFloat64(ConfigPrefix + SuffixMaxWriteErrorRate)
+	o.Throttling.CheckInterval = v.GetDuration(ConfigPrefix + SuffixThrottlingCheckInterval)
 	authenticationOptions := auth.AuthenticationConfig{}
 	authenticationOptions.InitFromViper(KafkaConsumerConfigPrefix, v)
 	o.AuthenticationConfig = authenticationOptions
+
+	o.Kinesis.Stream = v.GetString(KinesisConsumerConfigPrefix + SuffixStream)
+	o.Kinesis.Region = v.GetString(KinesisConsumerConfigPrefix + SuffixRegion)
+	o.Kinesis.Endpoint = v.GetString(KinesisConsumerConfigPrefix + SuffixEndpoint)
+	o.Kinesis.InitialPosition = v.GetString(KinesisConsumerConfigPrefix + SuffixInitialPosition)
+	o.Kinesis.PollInterval = v.GetDuration(KinesisConsumerConfigPrefix + SuffixPollInterval)
+	o.Kinesis.MaxRecords = v.GetInt64(KinesisConsumerConfigPrefix + SuffixMaxRecords)
+	o.Kinesis.CheckpointDir = v.GetString(KinesisConsumerConfigPrefix + SuffixCheckpointDir)
+
+	o.PubSub.Project = v.GetString(PubSubConsumerConfigPrefix + SuffixProject)
+	o.PubSub.Subscription = v.GetString(PubSubConsumerConfigPrefix + SuffixSubscription)
+	o.PubSub.MaxOutstandingMessages = v.GetInt(PubSubConsumerConfigPrefix + SuffixMaxOutstandingMessages)
 }
 
 // stripWhiteSpace removes all whitespace characters from a string
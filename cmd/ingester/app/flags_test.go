@@ -43,6 +43,9 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--kafka.consumer.protocol-version=1.0.0",
 		"--ingester.parallelism=5",
 		"--ingester.deadlockInterval=2m",
+		"--ingester.throttling.max-write-latency=1s",
+		"--ingester.throttling.max-write-error-rate=0.5",
+		"--ingester.throttling.check-interval=10s",
 	})
 	o.InitFromViper(v)
 
@@ -55,6 +58,11 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, "1.0.0", o.ProtocolVersion)
 	assert.Equal(t, 5, o.Parallelism)
 	assert.Equal(t, 2*time.Minute, o.DeadlockInterval)
+	assert.Equal(t, ThrottlingOptions{
+		MaxWriteLatency:   time.Second,
+		MaxWriteErrorRate: 0.5,
+		CheckInterval:     10 * time.Second,
+	}, o.Throttling)
 	assert.Equal(t, kafka.EncodingJSON, o.Encoding)
 }
 
@@ -113,6 +121,55 @@ func TestFlagDefaults(t *testing.T) {
 	assert.Equal(t, int32(DefaultFetchMaxMessageBytes), o.FetchMaxMessageBytes)
 	assert.Equal(t, DefaultEncoding, o.Encoding)
 	assert.Equal(t, DefaultDeadlockInterval, o.DeadlockInterval)
+	assert.Equal(t, ThrottlingOptions{CheckInterval: DefaultThrottlingCheckInterval}, o.Throttling)
+	assert.Equal(t, DefaultSource, o.Source)
+	assert.Equal(t, KinesisOptions{
+		Stream:          DefaultStream,
+		InitialPosition: DefaultInitialPosition,
+		PollInterval:    DefaultPollInterval,
+		MaxRecords:      DefaultMaxRecords,
+		CheckpointDir:   DefaultCheckpointDir,
+	}, o.Kinesis)
+	assert.Equal(t, PubSubOptions{
+		Subscription:           DefaultSubscription,
+		MaxOutstandingMessages: DefaultMaxOutstandingMessages,
+	}, o.PubSub)
+}
+
+func TestSourceFlags(t *testing.T) {
+	o := &Options{}
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--ingester.source=kinesis",
+		"--kinesis.consumer.stream=stream1",
+		"--kinesis.consumer.region=eu-west-1",
+		"--kinesis.consumer.endpoint=http://localhost:4566",
+		"--kinesis.consumer.initial-position=TRIM_HORIZON",
+		"--kinesis.consumer.poll-interval=200ms",
+		"--kinesis.consumer.max-records=500",
+		"--kinesis.consumer.checkpoint-dir=/var/lib/jaeger",
+		"--pubsub.consumer.project=project1",
+		"--pubsub.consumer.subscription=subscription1",
+		"--pubsub.consumer.max-outstanding-messages=10",
+	})
+	require.NoError(t, err)
+	o.InitFromViper(v)
+
+	assert.Equal(t, SourceKinesis, o.Source)
+	assert.Equal(t, KinesisOptions{
+		Stream:          "stream1",
+		Region:          "eu-west-1",
+		Endpoint:        "http://localhost:4566",
+		InitialPosition: "TRIM_HORIZON",
+		PollInterval:    200 * time.Millisecond,
+		MaxRecords:      500,
+		CheckpointDir:   "/var/lib/jaeger",
+	}, o.Kinesis)
+	assert.Equal(t, PubSubOptions{
+		Project:                "project1",
+		Subscription:           "subscription1",
+		MaxOutstandingMessages: 10,
+	}, o.PubSub)
 }
 
 func TestMain(m *testing.M) {
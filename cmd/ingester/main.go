@@ -15,8 +15,8 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -27,6 +27,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/ingester/app"
 	"github.com/jaegertracing/jaeger/cmd/ingester/app/builder"
+	ingesterConsumer "github.com/jaegertracing/jaeger/cmd/ingester/app/consumer"
 	"github.com/jaegertracing/jaeger/cmd/internal/docs"
 	"github.com/jaegertracing/jaeger/cmd/internal/env"
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
@@ -57,7 +58,11 @@ func main() {
 				return err
 			}
 			logger := svc.Logger // shortcut
-			baseFactory := svc.MetricsFactory.Namespace(metrics.NSOptions{Name: "jaeger"})
+			telset, err := svc.TelemetrySetting("jaeger-ingester", nil)
+			if err != nil {
+				logger.Fatal("Failed to create telemetry", zap.Error(err))
+			}
+			baseFactory := telset.Metrics
 			metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "ingester"})
 			version.NewInfoMetrics(metricsFactory)
 
@@ -65,10 +70,12 @@ func main() {
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			svc.HC().AddProbe("storage", storageFactory.CheckHealth)
 			spanWriter, err := storageFactory.CreateSpanWriter()
 			if err != nil {
 				logger.Fatal("Failed to create span writer", zap.Error(err))
 			}
+			spanWriter = svc.Admin.Status().InstrumentSpanWriter(spanWriter)
 
 			options := app.Options{}
 			options.InitFromViper(v)
@@ -76,6 +83,11 @@ func main() {
 			if err != nil {
 				logger.Fatal("Unable to create consumer", zap.Error(err))
 			}
+			if reporter, ok := consumer.(ingesterConsumer.StatusReporter); ok {
+				svc.Admin.Status().Register("consumer", func() any {
+					return reporter.Status()
+				})
+			}
 			consumer.Start()
 
 			svc.RunAndThen(func() {
@@ -85,15 +97,13 @@ func main() {
 				if err = consumer.Close(); err != nil {
 					logger.Error("Failed to close consumer", zap.Error(err))
 				}
-				if closer, ok := spanWriter.(io.Closer); ok {
-					err := closer.Close()
-					if err != nil {
-						logger.Error("Failed to close span writer", zap.Error(err))
-					}
-				}
+				// the storage factory closes the span writers it created
 				if err := storageFactory.Close(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
+				if err := telset.Close(context.Background()); err != nil {
+					logger.Error("Failed to close telemetry", zap.Error(err))
+				}
 			})
 			return nil
 		},
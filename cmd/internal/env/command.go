@@ -47,8 +47,9 @@ when Jaeger is deployed in the collector+ingester configuration.
 `
 
 	samplingTypeDescription = `The method [%s] used for determining the sampling rates served
-to clients configured with remote sampling enabled. "file" uses a periodically reloaded file and
-"adaptive" dynamically adjusts sampling rates based on current traffic.
+to clients configured with remote sampling enabled. "file" uses a periodically reloaded file,
+"adaptive" dynamically adjusts sampling rates based on current traffic and "configmap" watches
+a Kubernetes ConfigMap holding the strategies.
 `
 
 	samplingStorageTypeDescription = `The type of backend [%s] used for adaptive sampling storage
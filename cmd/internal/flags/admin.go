@@ -32,12 +32,19 @@ import (
 
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
+	"github.com/jaegertracing/jaeger/pkg/selfmonitor"
 	"github.com/jaegertracing/jaeger/pkg/version"
 )
 
 const (
 	adminHTTPHostPort = "admin.http.host-port"
+	adminHTTPIPFamily = "admin.http.ip-family"
+
+	// how often the dependencies registered as health check probes are checked
+	healthProbeInterval = 10 * time.Second
+	healthProbeTimeout  = 5 * time.Second
 )
 
 var tlsAdminHTTPFlagsConfig = tlscfg.ServerFlagsConfig{
@@ -48,7 +55,9 @@ var tlsAdminHTTPFlagsConfig = tlscfg.ServerFlagsConfig{
 type AdminServer struct {
 	logger               *zap.Logger
 	adminHostPort        string
+	adminNetwork         string
 	hc                   *healthcheck.HealthCheck
+	status               *selfmonitor.Registry
 	mux                  *http.ServeMux
 	server               *http.Server
 	tlsCfg               *tls.Config
@@ -61,6 +70,7 @@ func NewAdminServer(hostPort string) *AdminServer {
 		adminHostPort: hostPort,
 		logger:        zap.NewNop(),
 		hc:            healthcheck.New(),
+		status:        selfmonitor.NewRegistry(),
 		mux:           http.NewServeMux(),
 	}
 }
@@ -70,6 +80,11 @@ func (s *AdminServer) HC() *healthcheck.HealthCheck {
 	return s.hc
 }
 
+// Status returns the registry of the sources of the /status/snapshot endpoint.
+func (s *AdminServer) Status() *selfmonitor.Registry {
+	return s.status
+}
+
 // setLogger initializes logger.
 func (s *AdminServer) setLogger(logger *zap.Logger) {
 	s.logger = logger
@@ -79,6 +94,7 @@ func (s *AdminServer) setLogger(logger *zap.Logger) {
 // AddFlags registers CLI flags.
 func (s *AdminServer) AddFlags(flagSet *flag.FlagSet) {
 	flagSet.String(adminHTTPHostPort, s.adminHostPort, fmt.Sprintf("The host:port (e.g. 127.0.0.1%s or %s) for the admin server, including health check, /metrics, etc.", s.adminHostPort, s.adminHostPort))
+	flagSet.String(adminHTTPIPFamily, netutils.NetworkDual, "The IP family of the admin server: dual accepts both IPv4 and IPv6 when the host is empty or a host name, tcp4 only IPv4 and tcp6 only IPv6")
 	tlsAdminHTTPFlagsConfig.AddFlags(flagSet)
 }
 
@@ -87,7 +103,11 @@ func (s *AdminServer) initFromViper(v *viper.Viper, logger *zap.Logger) error {
 	s.setLogger(logger)
 
 	s.adminHostPort = v.GetString(adminHTTPHostPort)
-	var tlsAdminHTTP tlscfg.Options
+	network, err := netutils.TCPNetwork(v.GetString(adminHTTPIPFamily))
+	if err != nil {
+		return fmt.Errorf("failed to parse admin server options: %w", err)
+	}
+	s.adminNetwork = network
 	tlsAdminHTTP, err := tlsAdminHTTPFlagsConfig.InitFromViper(v)
 	if err != nil {
 		return fmt.Errorf("failed to parse admin server TLS options: %w", err)
@@ -112,7 +132,7 @@ func (s *AdminServer) Handle(path string, handler http.Handler) {
 
 // Serve starts HTTP server.
 func (s *AdminServer) Serve() error {
-	l, err := net.Listen("tcp", s.adminHostPort)
+	l, err := netutils.Listen(s.adminNetwork, s.adminHostPort, 0)
 	if err != nil {
 		s.logger.Error("Admin server failed to listen", zap.Error(err))
 		return err
@@ -129,6 +149,11 @@ func (s *AdminServer) Serve() error {
 func (s *AdminServer) serveWithListener(l net.Listener) {
 	s.logger.Info("Mounting health check on admin server", zap.String("route", "/"))
 	s.mux.Handle("/", s.hc.Handler())
+	s.logger.Info("Mounting liveness and readiness checks on admin server", zap.String("route", "/status/"))
+	s.mux.Handle("/status/live", s.hc.LivenessHandler())
+	s.mux.Handle("/status/ready", s.hc.ReadinessHandler())
+	s.mux.Handle("/status/snapshot", s.status.Handler())
+	s.hc.StartProbing(healthProbeInterval, healthProbeTimeout)
 	version.RegisterHandler(s.mux, s.logger)
 	s.registerPprofHandlers()
 	recoveryHandler := recoveryhandler.NewRecoveryHandler(s.logger, true)
@@ -170,6 +195,7 @@ func (s *AdminServer) registerPprofHandlers() {
 
 // Close stops the HTTP server
 func (s *AdminServer) Close() error {
+	s.hc.StopProbing()
 	return errors.Join(
 		s.tlsCertWatcherCloser.Close(),
 		s.server.Shutdown(context.Background()),
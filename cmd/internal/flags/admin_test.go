@@ -15,7 +15,10 @@
 package flags
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -60,12 +63,87 @@ func TestAdminServerHandlesPortZero(t *testing.T) {
 	assert.Positive(t, port)
 }
 
+func TestAdminServerIPFamily(t *testing.T) {
+	adminServer := NewAdminServer(":0")
+	v, command := config.Viperize(adminServer.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--admin.http.ip-family=tcp4"}))
+	zapCore, logs := observer.New(zap.InfoLevel)
+	require.NoError(t, adminServer.initFromViper(v, zap.New(zapCore)))
+
+	require.NoError(t, adminServer.Serve())
+	defer adminServer.Close()
+
+	message := logs.FilterMessage("Admin server started")
+	require.Equal(t, 1, message.Len())
+	assert.True(t, strings.HasPrefix(message.All()[0].ContextMap()["http.host-port"].(string), "0.0.0.0:"))
+}
+
+func TestAdminServerInvalidIPFamily(t *testing.T) {
+	adminServer := NewAdminServer(":0")
+	v, command := config.Viperize(adminServer.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--admin.http.ip-family=ipv4"}))
+	err := adminServer.initFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `failed to parse admin server options: invalid IP family "ipv4"`)
+}
+
 func TestAdminHealthCheck(t *testing.T) {
 	adminServer := NewAdminServer(":0")
 	status := adminServer.HC().Get()
 	assert.Equal(t, healthcheck.Unavailable, status)
 }
 
+func TestAdminReadiness(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	adminServer := NewAdminServer(":0")
+	v, command := config.Viperize(adminServer.AddFlags)
+	command.ParseFlags([]string{})
+	require.NoError(t, adminServer.initFromViper(v, zap.NewNop()))
+	adminServer.HC().AddProbe("storage", func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	adminServer.HC().Ready()
+
+	adminServer.serveWithListener(l)
+	defer adminServer.Close()
+
+	getStatus := func(path string) int {
+		resp, err := http.Get("http://" + l.Addr().String() + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, getStatus("/status/live"))
+	waitForEqual(t, http.StatusServiceUnavailable, func() any { return getStatus("/status/ready") })
+}
+
+func TestAdminStatusSnapshot(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	adminServer := NewAdminServer(":0")
+	v, command := config.Viperize(adminServer.AddFlags)
+	command.ParseFlags([]string{})
+	require.NoError(t, adminServer.initFromViper(v, zap.NewNop()))
+	adminServer.Status().Register("queue", func() any {
+		return map[string]int{"length": 3}
+	})
+
+	adminServer.serveWithListener(l)
+	defer adminServer.Close()
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/status/snapshot")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var snapshot struct {
+		Components map[string]map[string]int `json:"components"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+	assert.Equal(t, map[string]map[string]int{"queue": {"length": 3}}, snapshot.Components)
+}
+
 func TestAdminFailToServe(t *testing.T) {
 	l, err := net.Listen("tcp", ":0")
 	require.NoError(t, err)
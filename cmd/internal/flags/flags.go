@@ -16,9 +16,13 @@
 package flags
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -31,23 +35,93 @@ const (
 	logLevel        = "log-level"
 	logEncoding     = "log-encoding" // json or console
 	configFile      = "config-file"
+	configStrict    = "config-file-strict"
 )
 
+// envVarRefRegex matches the ${VAR}, ${env:VAR} and ${env:VAR:-default} references to environment variables.
+var envVarRefRegex = regexp.MustCompile(`\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
 // AddConfigFileFlag adds flags for ExternalConfFlags
 func AddConfigFileFlag(flagSet *flag.FlagSet) {
-	flagSet.String(configFile, "", "Configuration file in JSON, TOML, YAML, HCL, or Java properties formats (default none). See spf13/viper for precedence.")
+	flagSet.String(configFile, "", "Configuration file in JSON, TOML, YAML, HCL, or Java properties formats (default none). "+
+		"The keys are the names of the flags, nested at each dot, e.g. collector: {grpc-server: {host-port: ':14250'}}, "+
+		"so that a single file can configure several binaries. References to environment variables like ${env:VAR} are expanded. "+
+		"The file does not use the layout of the configuration of the jaeger v2 binary (receivers, processors, exporters and extensions). "+
+		"See spf13/viper for precedence.")
+	flagSet.Bool(configStrict, false, "Rejects the config file if it has keys which are not flags of the binary, instead of logging them as warnings. "+
+		"The keys in the namespaces the binary has no flag in, e.g. query in the file of jaeger-collector, belong to the other binaries and are always accepted.")
 }
 
-// TryLoadConfigFile initializes viper with config file specified as flag
-func TryLoadConfigFile(v *viper.Viper) error {
+// TryLoadConfigFile initializes viper with config file specified as flag.
+// It returns the keys of the file which are not flags, except in the namespaces
+// (the part of the keys before the first dot) the binary has no flag in, which
+// belong to the other binaries sharing the file. They are rejected instead if
+// the config-file-strict flag is set.
+func TryLoadConfigFile(v *viper.Viper) (unknownKeys []string, err error) {
 	if file := v.GetString(configFile); file != "" {
-		v.SetConfigFile(file)
-		err := v.ReadInConfig()
+		unknownKeys, err = loadConfigFile(v, file, v.GetBool(configStrict))
 		if err != nil {
-			return fmt.Errorf("cannot load config file %s: %w", file, err)
+			return nil, fmt.Errorf("cannot load config file %s: %w", file, err)
+		}
+	}
+	return unknownKeys, nil
+}
+
+func loadConfigFile(v *viper.Viper, file string, strict bool) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	content = expandEnvVarRefs(content)
+	configType := strings.TrimPrefix(filepath.Ext(file), ".")
+
+	fileConfig := viper.New()
+	fileConfig.SetConfigType(configType)
+	if err := fileConfig.ReadConfig(bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	unknownKeys := unknownConfigKeys(v.AllKeys(), fileConfig.AllKeys())
+	if strict && len(unknownKeys) > 0 {
+		return nil, fmt.Errorf("unknown configuration keys: %s", strings.Join(unknownKeys, ", "))
+	}
+
+	v.SetConfigType(configType)
+	return unknownKeys, v.ReadConfig(bytes.NewReader(content))
+}
+
+// expandEnvVarRefs replaces the references to environment variables by their values,
+// or by their default values when they are not set.
+func expandEnvVarRefs(content []byte) []byte {
+	return envVarRefRegex.ReplaceAllFunc(content, func(ref []byte) []byte {
+		match := envVarRefRegex.FindSubmatch(ref)
+		if value, ok := os.LookupEnv(string(match[1])); ok {
+			return []byte(value)
+		}
+		return match[2]
+	})
+}
+
+// unknownConfigKeys returns the sorted keys of the file which are not flags, in the namespaces of the flags.
+func unknownConfigKeys(flagKeys []string, fileKeys []string) []string {
+	known := make(map[string]bool, len(flagKeys))
+	namespaces := make(map[string]bool)
+	for _, key := range flagKeys {
+		known[key] = true
+		if namespace, _, found := strings.Cut(key, "."); found {
+			namespaces[namespace] = true
+		}
+	}
+	var unknown []string
+	for _, key := range fileKeys {
+		if known[key] {
+			continue
+		}
+		if namespace, _, found := strings.Cut(key, "."); !found || namespaces[namespace] {
+			unknown = append(unknown, key)
 		}
 	}
-	return nil
+	sort.Strings(unknown)
+	return unknown
 }
 
 // ParseJaegerTags parses the Jaeger tags string into a map.
@@ -16,10 +16,17 @@
 package flags
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
 )
 
 func TestParseJaegerTags(t *testing.T) {
@@ -56,3 +63,65 @@ func TestParseJaegerTagsPanic(t *testing.T) {
 		},
 	)
 }
+
+func collectorFlags(flagSet *flag.FlagSet) {
+	AddConfigFileFlag(flagSet)
+	AddLoggingFlags(flagSet)
+	flagSet.String("collector.grpc-server.host-port", ":14250", "")
+	flagSet.Int("collector.queue-size", 2000, "")
+}
+
+func loadConfigFileContent(t *testing.T, content string, args ...string) (*viper.Viper, []string, error) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	v, command := config.Viperize(collectorFlags)
+	require.NoError(t, command.ParseFlags(append([]string{"--config-file=" + file}, args...)))
+	unknownKeys, err := TryLoadConfigFile(v)
+	return v, unknownKeys, err
+}
+
+func TestTryLoadConfigFile(t *testing.T) {
+	t.Setenv("COLLECTOR_PORT", "4317")
+	v, unknownKeys, err := loadConfigFileContent(t, `
+log-level: debug
+collector:
+  grpc-server:
+    host-port: :${env:COLLECTOR_PORT}
+  queue-size: ${QUEUE_SIZE:-100}
+# the namespaces of the other binaries are not validated
+query:
+  base-path: /jaeger
+`)
+	require.NoError(t, err)
+	assert.Empty(t, unknownKeys)
+	assert.Equal(t, "debug", v.GetString("log-level"))
+	assert.Equal(t, ":4317", v.GetString("collector.grpc-server.host-port"))
+	assert.Equal(t, 100, v.GetInt("collector.queue-size"))
+}
+
+func TestTryLoadConfigFileFlagPrecedence(t *testing.T) {
+	v, _, err := loadConfigFileContent(t, "collector: {queue-size: 100}", "--collector.queue-size=10")
+	require.NoError(t, err)
+	assert.Equal(t, 10, v.GetInt("collector.queue-size"))
+}
+
+func TestTryLoadConfigFileUnknownKeys(t *testing.T) {
+	content := "log-levl: debug\ncollector: {queue-sise: 100, queue-size: 10}"
+	v, unknownKeys, err := loadConfigFileContent(t, content)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"collector.queue-sise", "log-levl"}, unknownKeys)
+	assert.Equal(t, 10, v.GetInt("collector.queue-size"))
+
+	_, _, err = loadConfigFileContent(t, content, "--config-file-strict")
+	require.ErrorContains(t, err, "unknown configuration keys: collector.queue-sise, log-levl")
+}
+
+func TestTryLoadConfigFileErrors(t *testing.T) {
+	_, _, err := loadConfigFileContent(t, "collector: [")
+	require.ErrorContains(t, err, "cannot load config file")
+
+	v, _ := config.Viperize(collectorFlags)
+	v.Set(configFile, filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = TryLoadConfigFile(v)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package flags
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/fswatcher"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// ConfigReloader reloads the config file on SIGHUP or when the file changes,
+// and calls back the binary to apply the options it is able to change without restarting.
+type ConfigReloader struct {
+	v        *viper.Viper
+	onReload func() error
+	logger   *zap.Logger
+	watcher  *fswatcher.FSWatcher
+	signals  chan os.Signal
+	done     chan struct{}
+	mu       sync.Mutex
+	metrics  struct {
+		// Number of reloads applied
+		Reloads metrics.Counter `metric:"config.reloads" tags:"result=ok"`
+
+		// Number of reloads which failed, leaving the previous options in place
+		Failures metrics.Counter `metric:"config.reloads" tags:"result=err"`
+	}
+}
+
+// NewConfigReloader creates a ConfigReloader reloading the config file into v.
+// onReload reads the options from v and applies them.
+func NewConfigReloader(v *viper.Viper, onReload func() error, mFactory metrics.Factory, logger *zap.Logger) (*ConfigReloader, error) {
+	r := &ConfigReloader{
+		v:        v,
+		onReload: onReload,
+		logger:   logger,
+		signals:  make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+	metrics.Init(&r.metrics, mFactory, nil)
+
+	if file := v.GetString(configFile); file != "" {
+		watcher, err := fswatcher.New([]string{file}, r.Reload, logger)
+		if err != nil {
+			return nil, err
+		}
+		r.watcher = watcher
+	}
+	signal.Notify(r.signals, syscall.SIGHUP)
+	go r.handleSignals()
+	return r, nil
+}
+
+func (r *ConfigReloader) handleSignals() {
+	for {
+		select {
+		case <-r.signals:
+			r.Reload()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Reload reloads the config file and applies the options.
+func (r *ConfigReloader) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger.Info("Reloading the configuration")
+	unknownKeys, err := TryLoadConfigFile(r.v)
+	if err != nil {
+		r.metrics.Failures.Inc(1)
+		r.logger.Error("Failed to reload the configuration", zap.Error(err))
+		return
+	}
+	if len(unknownKeys) > 0 {
+		r.logger.Warn("Ignoring the unknown keys of the config file", zap.Strings("keys", unknownKeys))
+	}
+	if err := r.onReload(); err != nil {
+		r.metrics.Failures.Inc(1)
+		r.logger.Error("Failed to apply the reloaded configuration", zap.Error(err))
+		return
+	}
+	r.metrics.Reloads.Inc(1)
+}
+
+// Close stops reloading the configuration.
+func (r *ConfigReloader) Close() error {
+	signal.Stop(r.signals)
+	close(r.done)
+	if r.watcher != nil {
+		return r.watcher.Close()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package flags
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func newTestConfigReloader(t *testing.T, content string, onReload func() error) (*ConfigReloader, string, *metricstest.Factory) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	v, command := config.Viperize(collectorFlags)
+	require.NoError(t, command.ParseFlags([]string{"--config-file=" + file}))
+	_, err := TryLoadConfigFile(v)
+	require.NoError(t, err)
+
+	mFactory := metricstest.NewFactory(0)
+	r, err := NewConfigReloader(v, onReload, mFactory, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+	return r, file, mFactory
+}
+
+func TestConfigReloaderFileChange(t *testing.T) {
+	queueSizes := make(chan int, 10)
+	var r *ConfigReloader
+	r, file, mFactory := newTestConfigReloader(t, "collector: {queue-size: 100}", func() error {
+		queueSizes <- r.v.GetInt("collector.queue-size")
+		return nil
+	})
+
+	// replace the file at once, as it must not be read while partially written
+	tmp := file + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte("collector: {queue-size: 200}"), 0o600))
+	require.NoError(t, os.Rename(tmp, file))
+	select {
+	case queueSize := <-queueSizes:
+		assert.Equal(t, 200, queueSize)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration was not reloaded")
+	}
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "config.reloads", Tags: map[string]string{"result": "ok"}, Value: 1})
+}
+
+func TestConfigReloaderSignal(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	newTestConfigReloader(t, "collector: {queue-size: 100}", func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration was not reloaded")
+	}
+}
+
+func TestConfigReloaderFailures(t *testing.T) {
+	r, file, mFactory := newTestConfigReloader(t, "collector: {queue-size: 100}", func() error {
+		return errors.New("invalid queue size")
+	})
+	r.Reload()
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "config.reloads", Tags: map[string]string{"result": "err"}, Value: 1})
+
+	// the previous configuration stays in place when the file is invalid
+	r.watcher.Close()
+	require.NoError(t, os.WriteFile(file, []byte("collector: ["), 0o600))
+	r.Reload()
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "config.reloads", Tags: map[string]string{"result": "err"}, Value: 2})
+	assert.Equal(t, 100, r.v.GetInt("collector.queue-size"))
+}
+
+func TestConfigReloaderWithoutFile(t *testing.T) {
+	v, _ := config.Viperize(collectorFlags)
+	r, err := NewConfigReloader(v, func() error { return nil }, metricstest.NewFactory(0), zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, r.watcher)
+	require.NoError(t, r.Close())
+}
@@ -15,6 +15,7 @@
 package flags
 
 import (
+	"context"
 	"expvar"
 	"flag"
 	"fmt"
@@ -29,7 +30,9 @@ import (
 
 	"github.com/jaegertracing/jaeger/internal/metrics/metricsbuilder"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -50,7 +53,8 @@ type Service struct {
 	// MetricsFactory is the root factory without a namespace.
 	MetricsFactory metrics.Factory
 
-	signalsChannel chan os.Signal
+	telemetryOptions telemetery.Options
+	signalsChannel   chan os.Signal
 }
 
 // NewService creates a new Service.
@@ -73,12 +77,14 @@ func (s *Service) AddFlags(flagSet *flag.FlagSet) {
 		AddFlags(flagSet)
 	}
 	metricsbuilder.AddFlags(flagSet)
+	telemetery.AddFlags(flagSet)
 	s.Admin.AddFlags(flagSet)
 }
 
 // Start bootstraps the service and starts the admin server.
 func (s *Service) Start(v *viper.Viper) error {
-	if err := TryLoadConfigFile(v); err != nil {
+	unknownKeys, err := TryLoadConfigFile(v)
+	if err != nil {
 		return fmt.Errorf("cannot load config file: %w", err)
 	}
 
@@ -90,6 +96,9 @@ func (s *Service) Start(v *viper.Viper) error {
 		return fmt.Errorf("cannot create logger: %w", err)
 	}
 	s.Logger = logger
+	if len(unknownKeys) > 0 {
+		logger.Warn("Ignoring the unknown keys of the config file", zap.Strings("keys", unknownKeys))
+	}
 	grpclog.SetLoggerV2(zapgrpc.NewLogger(
 		logger.WithOptions(
 			zap.AddCallerSkip(5), // ensure the actual caller:lineNo is shown
@@ -101,6 +110,9 @@ func (s *Service) Start(v *viper.Viper) error {
 		return fmt.Errorf("cannot create metrics factory: %w", err)
 	}
 	s.MetricsFactory = metricsFactory
+	if _, err := s.telemetryOptions.InitFromViper(v); err != nil {
+		return fmt.Errorf("cannot initialize telemetry: %w", err)
+	}
 
 	if err = s.Admin.initFromViper(v, s.Logger); err != nil {
 		return fmt.Errorf("cannot initialize admin server: %w", err)
@@ -109,6 +121,7 @@ func (s *Service) Start(v *viper.Viper) error {
 		route := metricsBuilder.HTTPRoute
 		s.Logger.Info("Mounting metrics handler on admin server", zap.String("route", route))
 		s.Admin.Handle(route, h)
+		s.Admin.Handle(route+"/schema", telemetery.DefaultRegistry().Handler())
 	}
 
 	// Mount expvar routes on different backends
@@ -124,6 +137,20 @@ func (s *Service) Start(v *viper.Viper) error {
 	return nil
 }
 
+// TelemetrySetting creates the telemetry of the component, to be closed on shutdown.
+// Its metrics are in the jaeger namespace and its spans are the ones of the tracer, if not nil.
+func (s *Service) TelemetrySetting(serviceName string, tracer *jtracer.JTracer) (telemetery.Setting, error) {
+	params := telemetery.Params{
+		ServiceName: serviceName,
+		Logger:      s.Logger,
+		Metrics:     s.MetricsFactory.Namespace(metrics.NSOptions{Name: "jaeger"}),
+	}
+	if tracer != nil {
+		params.TracerProvider = tracer.OTEL
+	}
+	return telemetery.New(context.Background(), params, s.telemetryOptions)
+}
+
 // HC returns the reference to HeathCheck.
 func (s *Service) HC() *healthcheck.HealthCheck {
 	return s.Admin.HC()
@@ -14,6 +14,7 @@
 package flags
 
 import (
+	"context"
 	"flag"
 	"os"
 	"reflect"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
 )
 
 func TestAddFlags(*testing.T) {
@@ -57,6 +59,11 @@ func TestStartErrors(t *testing.T) {
 			flags:  []string{"--metrics-backend=invalid-metrics-backend"},
 			expErr: "cannot create metrics factory",
 		},
+		{
+			name:   "bad telemetry exporter",
+			flags:  []string{"--telemetry.metrics.exporter=invalid-exporter"},
+			expErr: "cannot initialize telemetry",
+		},
 		{
 			name:   "bad admin TLS",
 			flags:  []string{"--admin.http.tls.enabled=true", "--admin.http.tls.cert=invalid-cert"},
@@ -85,6 +92,10 @@ func TestStartErrors(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
+			telset, err := s.TelemetrySetting("test-service", jtracer.NoOp())
+			require.NoError(t, err)
+			assert.Equal(t, s.Logger, telset.Logger)
+			require.NoError(t, telset.Close(context.Background()))
 
 			var stopped atomic.Bool
 			shutdown := func() {
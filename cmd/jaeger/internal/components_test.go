@@ -41,6 +41,10 @@ func TestComponents(t *testing.T) {
 
 	_, jaegerReceiverFactoryExists := factories.Receivers[component.MustNewType("jaeger")]
 	assert.True(t, jaegerReceiverFactoryExists)
+
+	for _, name := range []string{"attributes", "filter", "transform"} {
+		assert.Contains(t, factories.Processors, component.MustNewType(name))
+	}
 }
 
 func TestGetOtelcolFactories(t *testing.T) {
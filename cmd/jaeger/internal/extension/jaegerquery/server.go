@@ -15,6 +15,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/jaeger/internal/extension/jaegerstorage"
 	queryApp "github.com/jaegertracing/jaeger/cmd/query/app"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -64,10 +65,18 @@ func (s *server) Start(_ context.Context, host component.Host) error {
 		return fmt.Errorf("cannot create dependencies reader: %w", err)
 	}
 
-	var opts querysvc.QueryServiceOptions
+	capabilities := f.Capabilities()
+	opts := querysvc.QueryServiceOptions{StorageCapabilities: &capabilities}
 	if err := s.addArchiveStorage(&opts, host); err != nil {
 		return err
 	}
+	if len(s.config.Adjusters) > 0 {
+		adjusters, err := querysvc.NewAdjusters(s.config.Adjusters, querysvc.AdjusterConfig{MaxClockSkewAdjust: s.config.MaxClockSkewAdjust})
+		if err != nil {
+			return fmt.Errorf("cannot create the adjusters: %w", err)
+		}
+		opts.Adjuster = adjuster.Sequence(adjusters...)
+	}
 	qs := querysvc.NewQueryService(spanReader, depReader, opts)
 	metricsQueryService, _ := disabled.NewMetricsReader()
 	tm := tenancy.NewManager(&s.config.Tenancy)
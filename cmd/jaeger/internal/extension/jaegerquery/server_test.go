@@ -16,6 +16,7 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	"github.com/jaegertracing/jaeger/cmd/jaeger/internal/extension/jaegerstorage"
+	queryApp "github.com/jaegertracing/jaeger/cmd/query/app"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
@@ -51,6 +52,10 @@ func (ff fakeFactory) CreateSpanWriter() (spanstore.Writer, error) {
 	return &spanstoremocks.Writer{}, nil
 }
 
+func (fakeFactory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{}
+}
+
 func (ff fakeFactory) Initialize(metrics.Factory, *zap.Logger) error {
 	if ff.name == "need-initialize-error" {
 		return fmt.Errorf("test-error")
@@ -155,6 +160,25 @@ func TestServerStart(t *testing.T) {
 			},
 			expectedErr: "cannot find archive storage factory",
 		},
+		{
+			name: "adjusters",
+			config: &Config{
+				QueryOptionsBase: queryApp.QueryOptionsBase{
+					Adjusters: []string{"span-id-deduper", "critical-path"},
+				},
+				TraceStoragePrimary: "jaeger_storage",
+			},
+		},
+		{
+			name: "adjusters error",
+			config: &Config{
+				QueryOptionsBase: queryApp.QueryOptionsBase{
+					Adjusters: []string{"unknown"},
+				},
+				TraceStoragePrimary: "jaeger_storage",
+			},
+			expectedErr: "cannot create the adjusters",
+		},
 	}
 
 	for _, tt := range tests {
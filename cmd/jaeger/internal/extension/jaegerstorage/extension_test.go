@@ -71,6 +71,10 @@ func (errorFactory) CreateDependencyReader() (dependencystore.Reader, error) {
 	panic("not implemented")
 }
 
+func (errorFactory) Capabilities() storage.Capabilities {
+	panic("not implemented")
+}
+
 func (e errorFactory) Close() error {
 	return e.closeErr
 }
@@ -8,9 +8,9 @@ import (
 	"errors"
 	"fmt"
 
-	otlp2jaeger "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	otlp2jaeger "github.com/jaegertracing/jaeger/pkg/otlptranslator"
 	spanstore_v1 "github.com/jaegertracing/jaeger/storage/spanstore"
 	"github.com/jaegertracing/jaeger/storage_v2/spanstore"
 )
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoints records, per service, the end of the last migrated window.
+// When created without a file, the checkpoints are only kept in memory.
+type Checkpoints struct {
+	path string
+
+	mu       sync.Mutex
+	services map[string]time.Time
+}
+
+// LoadCheckpoints reads the checkpoints from the file, if it exists.
+func LoadCheckpoints(path string) (*Checkpoints, error) {
+	c := &Checkpoints{path: path, services: make(map[string]time.Time)}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint file: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.services); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Migrated returns whether the window of the service ending at end was migrated.
+func (c *Checkpoints) Migrated(service string, end time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last, ok := c.services[service]
+	return ok && !end.After(last)
+}
+
+// Save records that the window of the service ending at end was migrated.
+func (c *Checkpoints) Save(service string, end time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.services[service] = end
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.services)
+	if err != nil {
+		return err
+	}
+	// the file is replaced atomically so that a crash does not lose the previous checkpoints
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("cannot write checkpoint file: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	end := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	c, err := LoadCheckpoints(path)
+	require.NoError(t, err)
+	assert.False(t, c.Migrated("svc", end))
+	require.NoError(t, c.Save("svc", end))
+
+	// the checkpoints are restored from the file
+	c, err = LoadCheckpoints(path)
+	require.NoError(t, err)
+	assert.True(t, c.Migrated("svc", end.Add(-time.Hour)))
+	assert.True(t, c.Migrated("svc", end))
+	assert.False(t, c.Migrated("svc", end.Add(time.Hour)))
+	assert.False(t, c.Migrated("other", end))
+}
+
+func TestCheckpointsInMemory(t *testing.T) {
+	c, err := LoadCheckpoints("")
+	require.NoError(t, err)
+	end := time.Now()
+	require.NoError(t, c.Save("svc", end))
+	assert.True(t, c.Migrated("svc", end))
+}
+
+func TestCheckpointsErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+	_, err := LoadCheckpoints(invalid)
+	require.ErrorContains(t, err, "cannot parse checkpoint file")
+
+	_, err = LoadCheckpoints(dir)
+	require.ErrorContains(t, err, "cannot read checkpoint file")
+
+	c, err := LoadCheckpoints(filepath.Join(dir, "missing", "checkpoints.json"))
+	require.NoError(t, err)
+	require.ErrorContains(t, c.Save("svc", time.Now()), "cannot write checkpoint file")
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Options represent configurable parameters for jaeger-migrate
+type Options struct {
+	ServiceNames   []string
+	StartTime      string
+	EndTime        string
+	Window         time.Duration
+	MaxTraces      int
+	Workers        int
+	CheckpointFile string
+}
+
+const (
+	serviceNameFlag    = "service-name"
+	startTimeFlag      = "start-time"
+	endTimeFlag        = "end-time"
+	windowFlag         = "window"
+	maxTracesFlag      = "max-traces"
+	workersFlag        = "workers"
+	checkpointFileFlag = "checkpoint-file"
+
+	defaultLookback = 24 * time.Hour
+)
+
+// AddFlags adds flags for migrate main program
+func (o *Options) AddFlags(command *cobra.Command) {
+	command.Flags().StringSliceVar(
+		&o.ServiceNames,
+		serviceNameFlag,
+		nil,
+		"Comma-separated list of services to migrate the traces of. All services of the source storage are migrated if empty")
+	command.Flags().StringVar(
+		&o.StartTime,
+		startTimeFlag,
+		"",
+		"The start of the time range (RFC3339) of the migrated traces. Defaults to one day before end-time")
+	command.Flags().StringVar(
+		&o.EndTime,
+		endTimeFlag,
+		"",
+		"The end of the time range (RFC3339) of the migrated traces. Defaults to now")
+	command.Flags().DurationVar(
+		&o.Window,
+		windowFlag,
+		time.Hour,
+		"The time range is migrated in windows of this duration; each window is searched separately and checkpointed once migrated")
+	command.Flags().IntVar(
+		&o.MaxTraces,
+		maxTracesFlag,
+		1000,
+		"The maximum number of traces searched per service and window. The windows with more traces are split "+
+			"in halves, down to a millisecond, until the traces of each part are found")
+	command.Flags().IntVar(
+		&o.Workers,
+		workersFlag,
+		4,
+		"The number of traces migrated concurrently")
+	command.Flags().StringVar(
+		&o.CheckpointFile,
+		checkpointFileFlag,
+		"",
+		"Path to a file recording the migrated windows. When set, a restarted migration skips the windows already migrated")
+}
+
+// Validate checks that the combination of options is valid.
+func (o *Options) Validate() error {
+	if o.Window <= 0 {
+		return fmt.Errorf("--%s must be positive", windowFlag)
+	}
+	if o.MaxTraces <= 0 {
+		return fmt.Errorf("--%s must be positive", maxTracesFlag)
+	}
+	if o.Workers <= 0 {
+		return fmt.Errorf("--%s must be positive", workersFlag)
+	}
+	_, _, err := o.TimeRange()
+	return err
+}
+
+// TimeRange returns the time range of the migrated traces.
+func (o *Options) TimeRange() (time.Time, time.Time, error) {
+	end := time.Now()
+	if o.EndTime != "" {
+		t, err := time.Parse(time.RFC3339, o.EndTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse --%s: %w", endTimeFlag, err)
+		}
+		end = t
+	}
+	start := end.Add(-defaultLookback)
+	if o.StartTime != "" {
+		t, err := time.Parse(time.RFC3339, o.StartTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse --%s: %w", startTimeFlag, err)
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--%s must be before --%s", startTimeFlag, endTimeFlag)
+	}
+	return start, end, nil
+}
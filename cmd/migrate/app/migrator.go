@@ -0,0 +1,216 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// minWindow is the shortest window a window with too many traces is split into,
+	// the precision of the start times of the spans in the storage backends.
+	minWindow = time.Millisecond
+	// migratedCacheSize is the number of traces remembered as migrated by each of the
+	// two generations of the cache, bounding its memory.
+	migratedCacheSize = 100_000
+)
+
+// Stats summarizes a migration.
+type Stats struct {
+	Traces int64
+	Spans  int64
+}
+
+// Migrator copies the traces of a time range from a source span storage to a target one.
+//
+// The time range is migrated service by service, in windows that are checkpointed
+// once all their traces are written, so that a restarted migration resumes after the
+// last migrated window. The traces of an interrupted window are written again.
+// The spans of each trace are written at once when the writer is a spanstore.BatchWriter,
+// whose writes complete before WriteBatch returns, unlike WriteSpan of the writers buffering
+// the spans, e.g. in the bulk processor of Elasticsearch, so that no window is checkpointed
+// before its traces are stored.
+// The windows with more than the maximum number of traces are split in halves until
+// all their traces are found.
+type Migrator struct {
+	reader      spanstore.Reader
+	writer      spanstore.Writer
+	checkpoints *Checkpoints
+	options     Options
+	logger      *zap.Logger
+
+	traces atomic.Int64
+	spans  atomic.Int64
+
+	// migrated and previous hold the traces recently written during this run, which are
+	// found again when searching for the other services of the trace. The traces are only
+	// remembered for two generations of migratedCacheSize traces, the older ones being
+	// written again if they are found again.
+	mu       sync.Mutex
+	migrated map[model.TraceID]struct{}
+	previous map[model.TraceID]struct{}
+}
+
+// NewMigrator creates a Migrator.
+func NewMigrator(reader spanstore.Reader, writer spanstore.Writer, checkpoints *Checkpoints, options Options, logger *zap.Logger) *Migrator {
+	return &Migrator{
+		reader:      reader,
+		writer:      writer,
+		checkpoints: checkpoints,
+		options:     options,
+		logger:      logger,
+		migrated:    make(map[model.TraceID]struct{}),
+	}
+}
+
+// Run migrates the traces, stopping at the first error.
+func (m *Migrator) Run(ctx context.Context) (Stats, error) {
+	start, end, err := m.options.TimeRange()
+	if err != nil {
+		return Stats{}, err
+	}
+	services := m.options.ServiceNames
+	if len(services) == 0 {
+		services, err = m.reader.GetServices(ctx)
+		if err != nil {
+			return Stats{}, fmt.Errorf("cannot get services: %w", err)
+		}
+	}
+	for _, service := range services {
+		for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(m.options.Window) {
+			windowEnd := windowStart.Add(m.options.Window)
+			if windowEnd.After(end) {
+				windowEnd = end
+			}
+			if m.checkpoints.Migrated(service, windowEnd) {
+				continue
+			}
+			if err := m.migrateWindow(ctx, service, windowStart, windowEnd); err != nil {
+				return m.stats(), err
+			}
+			if err := m.checkpoints.Save(service, windowEnd); err != nil {
+				return m.stats(), err
+			}
+		}
+		m.logger.Info("Migrated service", zap.String("service", service))
+	}
+	return m.stats(), nil
+}
+
+func (m *Migrator) stats() Stats {
+	return Stats{Traces: m.traces.Load(), Spans: m.spans.Load()}
+}
+
+func (m *Migrator) migrateWindow(ctx context.Context, service string, start, end time.Time) error {
+	// one more trace than the maximum is searched to tell the windows exceeding it
+	traceIDs, err := m.reader.FindTraceIDs(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:  service,
+		StartTimeMin: start,
+		StartTimeMax: end,
+		NumTraces:    m.options.MaxTraces + 1,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot find traces of service %s: %w", service, err)
+	}
+	if len(traceIDs) > m.options.MaxTraces {
+		if end.Sub(start) <= minWindow {
+			return fmt.Errorf("the traces of service %s from %s to %s exceed the maximum of %d traces, see --%s",
+				service, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano), m.options.MaxTraces, maxTracesFlag)
+		}
+		m.logger.Debug("Splitting the window exceeding the maximum number of traces",
+			zap.String("service", service), zap.Time("start", start), zap.Time("end", end), zap.Int("max-traces", m.options.MaxTraces))
+		middle := start.Add(end.Sub(start) / 2)
+		if err := m.migrateWindow(ctx, service, start, middle); err != nil {
+			return err
+		}
+		return m.migrateWindow(ctx, service, middle, end)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan model.TraceID)
+	errs := make([]error, m.options.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < m.options.Workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for traceID := range jobs {
+				if err := m.migrateTrace(ctx, traceID); err != nil {
+					errs[i] = err
+					// stops the other workers
+					cancel()
+					return
+				}
+			}
+		}(i)
+	}
+	for _, traceID := range traceIDs {
+		if !m.startMigration(traceID) {
+			continue
+		}
+		select {
+		case jobs <- traceID:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// startMigration returns false when the trace was already migrated during this run.
+func (m *Migrator) startMigration(traceID model.TraceID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.migrated[traceID]; ok {
+		return false
+	}
+	if _, ok := m.previous[traceID]; ok {
+		return false
+	}
+	if len(m.migrated) >= migratedCacheSize {
+		m.previous, m.migrated = m.migrated, make(map[model.TraceID]struct{})
+	}
+	m.migrated[traceID] = struct{}{}
+	return true
+}
+
+func (m *Migrator) migrateTrace(ctx context.Context, traceID model.TraceID) error {
+	trace, err := m.reader.GetTrace(ctx, traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		// the trace expired since it was found
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read trace %s: %w", traceID, err)
+	}
+	if batchWriter, ok := m.writer.(spanstore.BatchWriter); ok {
+		if err := batchWriter.WriteBatch(ctx, trace.Spans); err != nil {
+			return fmt.Errorf("cannot write the spans of trace %s: %w", traceID, err)
+		}
+	} else {
+		for _, span := range trace.Spans {
+			if err := m.writer.WriteSpan(ctx, span); err != nil {
+				return fmt.Errorf("cannot write span %s of trace %s: %w", span.SpanID, traceID, err)
+			}
+		}
+	}
+	m.traces.Add(1)
+	m.spans.Add(int64(len(trace.Spans)))
+	return nil
+}
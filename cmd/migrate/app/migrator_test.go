@@ -0,0 +1,276 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var migrationStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// sourceReader searches the spans of the traces by service and start time.
+type sourceReader struct {
+	spanstore.Reader
+
+	mu       sync.Mutex
+	traces   map[model.TraceID]*model.Trace
+	searches int
+	findErr  error
+	getErr   error
+}
+
+func newSourceReader(spans ...*model.Span) *sourceReader {
+	r := &sourceReader{traces: make(map[model.TraceID]*model.Trace)}
+	for _, span := range spans {
+		trace, ok := r.traces[span.TraceID]
+		if !ok {
+			trace = &model.Trace{}
+			r.traces[span.TraceID] = trace
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	return r
+}
+
+func (r *sourceReader) GetServices(context.Context) ([]string, error) {
+	return []string{"frontend", "backend"}, nil
+}
+
+func (r *sourceReader) FindTraceIDs(_ context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches++
+	if r.findErr != nil {
+		return nil, r.findErr
+	}
+	var traceIDs []model.TraceID
+	for traceID, trace := range r.traces {
+		for _, span := range trace.Spans {
+			if span.Process.ServiceName == query.ServiceName &&
+				!span.StartTime.Before(query.StartTimeMin) && span.StartTime.Before(query.StartTimeMax) {
+				traceIDs = append(traceIDs, traceID)
+				break
+			}
+		}
+	}
+	if len(traceIDs) > query.NumTraces {
+		traceIDs = traceIDs[:query.NumTraces]
+	}
+	return traceIDs, nil
+}
+
+func (r *sourceReader) GetTrace(_ context.Context, traceID model.TraceID) (*model.Trace, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	trace, ok := r.traces[traceID]
+	if !ok {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return trace, nil
+}
+
+func migrationSpan(traceID, spanID uint64, service string, start time.Duration) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: "op",
+		StartTime:     migrationStart.Add(start),
+		Process:       &model.Process{ServiceName: service},
+	}
+}
+
+func migrationOptions() Options {
+	return Options{
+		StartTime: migrationStart.Format(time.RFC3339),
+		EndTime:   migrationStart.Add(3 * time.Hour).Format(time.RFC3339),
+		Window:    time.Hour,
+		MaxTraces: 10,
+		Workers:   2,
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	reader := newSourceReader(
+		migrationSpan(1, 1, "frontend", 10*time.Minute),
+		migrationSpan(1, 2, "backend", 11*time.Minute),
+		migrationSpan(2, 3, "backend", 2*time.Hour),
+		// outside of the migrated time range
+		migrationSpan(3, 4, "backend", 4*time.Hour),
+	)
+	target := memory.NewStore()
+	checkpoints, err := LoadCheckpoints("")
+	require.NoError(t, err)
+
+	stats, err := NewMigrator(reader, target, checkpoints, migrationOptions(), zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	// the trace of both services is only migrated once
+	assert.Equal(t, Stats{Traces: 2, Spans: 3}, stats)
+	assert.Equal(t, 6, reader.searches)
+
+	trace, err := target.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+	_, err = target.GetTrace(context.Background(), model.NewTraceID(0, 3))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	assert.True(t, checkpoints.Migrated("frontend", migrationStart.Add(3*time.Hour)))
+	assert.True(t, checkpoints.Migrated("backend", migrationStart.Add(3*time.Hour)))
+}
+
+func TestMigratorResumes(t *testing.T) {
+	reader := newSourceReader(
+		migrationSpan(1, 1, "backend", 10*time.Minute),
+		migrationSpan(2, 2, "backend", 2*time.Hour),
+	)
+	checkpoints, err := LoadCheckpoints("")
+	require.NoError(t, err)
+	require.NoError(t, checkpoints.Save("backend", migrationStart.Add(time.Hour)))
+	opts := migrationOptions()
+	opts.ServiceNames = []string{"backend"}
+
+	stats, err := NewMigrator(reader, memory.NewStore(), checkpoints, opts, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Traces: 1, Spans: 1}, stats)
+	assert.Equal(t, 2, reader.searches)
+}
+
+// failingWriter fails to write the spans.
+type failingWriter struct{}
+
+func (failingWriter) WriteSpan(context.Context, *model.Span) error {
+	return errors.New("target unavailable")
+}
+
+// bufferingWriter buffers the spans written one by one like the Elasticsearch writer,
+// and fails to write the batches.
+type bufferingWriter struct{}
+
+func (bufferingWriter) WriteSpan(context.Context, *model.Span) error {
+	return nil
+}
+
+func (bufferingWriter) WriteBatch(context.Context, []*model.Span) error {
+	return errors.New("bulk request failed")
+}
+
+func TestMigratorErrors(t *testing.T) {
+	spans := []*model.Span{migrationSpan(1, 1, "backend", 10*time.Minute), migrationSpan(2, 2, "backend", 20*time.Minute)}
+	opts := migrationOptions()
+	opts.ServiceNames = []string{"backend"}
+
+	tests := []struct {
+		name   string
+		modify func(r *sourceReader)
+		writer spanstore.Writer
+		err    string
+	}{
+		{
+			name:   "search error",
+			modify: func(r *sourceReader) { r.findErr = errors.New("source unavailable") },
+			writer: memory.NewStore(),
+			err:    "cannot find traces of service backend: source unavailable",
+		},
+		{
+			name:   "read error",
+			modify: func(r *sourceReader) { r.getErr = errors.New("source unavailable") },
+			writer: memory.NewStore(),
+			err:    "cannot read trace",
+		},
+		{
+			name:   "write error",
+			modify: func(*sourceReader) {},
+			writer: failingWriter{},
+			err:    "target unavailable",
+		},
+		{
+			name:   "batch write error",
+			modify: func(*sourceReader) {},
+			writer: bufferingWriter{},
+			err:    "cannot write the spans of trace",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := newSourceReader(spans...)
+			test.modify(reader)
+			checkpoints, err := LoadCheckpoints("")
+			require.NoError(t, err)
+			_, err = NewMigrator(reader, test.writer, checkpoints, opts, zap.NewNop()).Run(context.Background())
+			require.ErrorContains(t, err, test.err)
+			// the failed window is not checkpointed
+			assert.False(t, checkpoints.Migrated("backend", migrationStart.Add(time.Hour)))
+		})
+	}
+}
+
+func TestMigratorCanceled(t *testing.T) {
+	reader := newSourceReader(migrationSpan(1, 1, "backend", 10*time.Minute))
+	checkpoints, err := LoadCheckpoints("")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewMigrator(reader, memory.NewStore(), checkpoints, migrationOptions(), zap.NewNop()).Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMigratorSplitWindow(t *testing.T) {
+	reader := newSourceReader(
+		migrationSpan(1, 1, "backend", 10*time.Minute),
+		migrationSpan(2, 2, "backend", 20*time.Minute),
+		migrationSpan(3, 3, "backend", 2*time.Hour),
+	)
+	checkpoints, err := LoadCheckpoints("")
+	require.NoError(t, err)
+	opts := migrationOptions()
+	opts.ServiceNames = []string{"backend"}
+	opts.MaxTraces = 1
+
+	stats, err := NewMigrator(reader, memory.NewStore(), checkpoints, opts, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Traces)
+	// the first hour is split into [0, 30m), [0, 15m), [15m, 30m) and [30m, 1h)
+	assert.Equal(t, 7, reader.searches)
+	assert.True(t, checkpoints.Migrated("backend", migrationStart.Add(3*time.Hour)))
+}
+
+func TestMigratorWindowExceedsMaxTraces(t *testing.T) {
+	reader := newSourceReader(
+		migrationSpan(1, 1, "backend", 10*time.Minute),
+		migrationSpan(2, 2, "backend", 10*time.Minute),
+	)
+	checkpoints, err := LoadCheckpoints("")
+	require.NoError(t, err)
+	opts := migrationOptions()
+	opts.ServiceNames = []string{"backend"}
+	opts.MaxTraces = 1
+
+	_, err = NewMigrator(reader, memory.NewStore(), checkpoints, opts, zap.NewNop()).Run(context.Background())
+	require.ErrorContains(t, err, "exceed the maximum of 1 traces")
+	assert.False(t, checkpoints.Migrated("backend", migrationStart.Add(time.Hour)))
+}
+
+func TestMigratorStartMigration(t *testing.T) {
+	m := NewMigrator(nil, nil, nil, migrationOptions(), zap.NewNop())
+	for i := 0; i < migratedCacheSize; i++ {
+		assert.True(t, m.startMigration(model.NewTraceID(0, uint64(i))))
+	}
+	assert.False(t, m.startMigration(model.NewTraceID(0, 0)))
+	// a new generation is started, the traces of the previous one still being remembered
+	assert.True(t, m.startMigration(model.NewTraceID(0, migratedCacheSize)))
+	assert.False(t, m.startMigration(model.NewTraceID(0, 1)))
+	assert.Len(t, m.migrated, 1)
+	assert.Len(t, m.previous, migratedCacheSize)
+}
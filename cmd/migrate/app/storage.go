@@ -0,0 +1,60 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/plugin/storage"
+)
+
+const (
+	// TargetStorageTypeEnvVar is the name of the env var that defines the type of backend the traces are migrated to.
+	TargetStorageTypeEnvVar = "TARGET_STORAGE_TYPE"
+
+	// TargetFlagsPrefix is the prefix of the flags of the target storage, e.g. --target.es.server-urls,
+	// which allows the source and the target to be backends of the same type.
+	TargetFlagsPrefix = "target."
+)
+
+// TargetFactoryConfig returns the configuration of the storage factory the traces are migrated to.
+func TargetFactoryConfig() (storage.FactoryConfig, error) {
+	storageType := os.Getenv(TargetStorageTypeEnvVar)
+	if storageType == "" {
+		return storage.FactoryConfig{}, fmt.Errorf("the %s environment variable must define the storage type to migrate traces to", TargetStorageTypeEnvVar)
+	}
+	types := strings.Split(storageType, ",")
+	return storage.FactoryConfig{
+		SpanWriterTypes:         types,
+		SpanReaderType:          types[0],
+		DependenciesStorageType: types[0],
+	}, nil
+}
+
+// AddPrefixedFlags returns a function adding the flags added by addFlags with the given prefix.
+func AddPrefixedFlags(prefix string, addFlags func(*flag.FlagSet)) func(*flag.FlagSet) {
+	return func(flagSet *flag.FlagSet) {
+		prefixed := flag.NewFlagSet(prefix, flag.ContinueOnError)
+		addFlags(prefixed)
+		prefixed.VisitAll(func(f *flag.Flag) {
+			flagSet.Var(f.Value, prefix+f.Name, f.Usage)
+		})
+	}
+}
+
+// PrefixedViper returns a viper holding the values of the keys with the given prefix, without the prefix.
+func PrefixedViper(v *viper.Viper, prefix string) *viper.Viper {
+	prefixed := viper.New()
+	for _, key := range v.AllKeys() {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			prefixed.Set(name, v.Get(key))
+		}
+	}
+	return prefixed
+}
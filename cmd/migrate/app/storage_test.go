@@ -0,0 +1,46 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+)
+
+func TestTargetFactoryConfig(t *testing.T) {
+	t.Setenv(TargetStorageTypeEnvVar, "")
+	_, err := TargetFactoryConfig()
+	require.ErrorContains(t, err, TargetStorageTypeEnvVar)
+
+	t.Setenv(TargetStorageTypeEnvVar, "elasticsearch,kafka")
+	cfg, err := TargetFactoryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, storage.FactoryConfig{
+		SpanWriterTypes:         []string{"elasticsearch", "kafka"},
+		SpanReaderType:          "elasticsearch",
+		DependenciesStorageType: "elasticsearch",
+	}, cfg)
+}
+
+func TestPrefixedFlags(t *testing.T) {
+	addFlags := func(flagSet *flag.FlagSet) {
+		flagSet.String("es.server-urls", "http://127.0.0.1:9200", "")
+	}
+	v, command := config.Viperize(addFlags, AddPrefixedFlags(TargetFlagsPrefix, addFlags))
+	require.NoError(t, command.ParseFlags([]string{
+		"--es.server-urls=http://source:9200",
+		"--target.es.server-urls=http://target:9200",
+	}))
+
+	assert.Equal(t, "http://source:9200", v.GetString("es.server-urls"))
+	target := PrefixedViper(v, TargetFlagsPrefix)
+	assert.Equal(t, "http://target:9200", target.GetString("es.server-urls"))
+	assert.Equal(t, []string{"es.server-urls"}, target.AllKeys())
+}
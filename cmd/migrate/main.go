@@ -0,0 +1,113 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/migrate/app"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+)
+
+var logger, _ = zap.NewDevelopment()
+
+func main() {
+	options := app.Options{}
+	v := viper.New()
+
+	sourceFactory, err := storage.NewFactory(storage.FactoryConfigFromEnvAndCLI(os.Args, os.Stderr))
+	if err != nil {
+		log.Fatalf("Cannot initialize source storage factory: %v", err)
+	}
+	targetConfig, err := app.TargetFactoryConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	targetFactory, err := storage.NewFactory(targetConfig)
+	if err != nil {
+		log.Fatalf("Cannot initialize target storage factory: %v", err)
+	}
+
+	command := &cobra.Command{
+		Use:   "jaeger-migrate",
+		Short: "Jaeger migrate copies traces from one storage backend to another",
+		Long: `Jaeger migrate copies the traces of a time range from the storage backend configured via SPAN_STORAGE_TYPE
+and the storage flags to the storage backend configured via TARGET_STORAGE_TYPE and the storage flags prefixed with "target.".`,
+		Run: func(_ *cobra.Command, _ /* args */ []string) {
+			if err := options.Validate(); err != nil {
+				logger.Fatal("invalid options", zap.Error(err))
+			}
+			if err := migrate(v, sourceFactory, targetFactory, &options); err != nil {
+				logger.Fatal("Migration failed, restart it with the same checkpoint file to resume it", zap.Error(err))
+			}
+		},
+	}
+
+	options.AddFlags(command)
+	config.AddFlags(
+		v,
+		command,
+		sourceFactory.AddFlags,
+		app.AddPrefixedFlags(app.TargetFlagsPrefix, targetFactory.AddFlags),
+	)
+
+	command.AddCommand(version.Command())
+
+	if err := command.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func migrate(v *viper.Viper, sourceFactory, targetFactory *storage.Factory, options *app.Options) error {
+	checkpoints, err := app.LoadCheckpoints(options.CheckpointFile)
+	if err != nil {
+		return err
+	}
+
+	sourceFactory.InitFromViper(v, logger)
+	if err := sourceFactory.Initialize(metrics.NullFactory, logger); err != nil {
+		return fmt.Errorf("failed to init source storage factory: %w", err)
+	}
+	defer closeFactory(sourceFactory, "source")
+	targetFactory.InitFromViper(app.PrefixedViper(v, app.TargetFlagsPrefix), logger)
+	if err := targetFactory.Initialize(metrics.NullFactory, logger); err != nil {
+		return fmt.Errorf("failed to init target storage factory: %w", err)
+	}
+	// closing the target flushes the spans buffered by its writer
+	defer closeFactory(targetFactory, "target")
+
+	reader, err := sourceFactory.CreateSpanReader()
+	if err != nil {
+		return fmt.Errorf("failed to create span reader: %w", err)
+	}
+	writer, err := targetFactory.CreateSpanWriter()
+	if err != nil {
+		return fmt.Errorf("failed to create span writer: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stats, err := app.NewMigrator(reader, writer, checkpoints, *options, logger).Run(ctx)
+	logger.Info("Migrated traces", zap.Int64("traces", stats.Traces), zap.Int64("spans", stats.Spans))
+	return err
+}
+
+func closeFactory(factory *storage.Factory, name string) {
+	if err := factory.Close(); err != nil {
+		logger.Error("Failed to close "+name+" storage factory", zap.Error(err))
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// ErrRuleNotFound is returned when the tenant has no rule of the name.
+var ErrRuleNotFound = errors.New("alerting rule not found")
+
+// RuleState is the result of the evaluations of a rule.
+type RuleState struct {
+	State State `json:"state"`
+	// Since is when the rule entered its state, zero if it was never evaluated.
+	Since time.Time `json:"since,omitempty"`
+	// Value is the value of the signal at the last evaluation, nil if there was no data.
+	Value          *float64  `json:"value,omitempty"`
+	LastEvaluation time.Time `json:"lastEvaluation,omitempty"`
+	LastError      string    `json:"lastError,omitempty"`
+}
+
+// RuleStatus is a rule with its state.
+type RuleStatus struct {
+	Rule
+	Status RuleState `json:"status"`
+}
+
+type ruleEntry struct {
+	tenant string
+	rule   Rule
+	status RuleState
+}
+
+// stateFile is the content of the file persisting the rules and their states.
+type stateFile struct {
+	Rules []persistedRule `json:"rules"`
+}
+
+type persistedRule struct {
+	Tenant string    `json:"tenant,omitempty"`
+	Rule   Rule      `json:"rule"`
+	Status RuleState `json:"status"`
+}
+
+// Manager stores the alerting rules of the tenants, periodically evaluates them and sends
+// their notifications when they start or stop firing.
+type Manager struct {
+	options Options
+	source  source
+	sender  *sender
+	logger  *zap.Logger
+	timeNow func() time.Time
+
+	// lock guards rules and the writes of the state file.
+	lock  sync.Mutex
+	rules map[string]map[string]*ruleEntry
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	done      sync.WaitGroup
+}
+
+// NewManager creates a Manager computing the signals with the query service or the metrics query
+// service, depending on the source of the options, and loading the rules of the state file if it exists.
+func NewManager(options Options, querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, logger *zap.Logger) (*Manager, error) {
+	if options.Interval <= 0 {
+		return nil, errors.New("the interval of the alerting rules evaluation must be positive")
+	}
+	var src source
+	switch options.Source {
+	case SourceSpans:
+		src = &spanSource{querySvc: querySvc}
+	case SourceMetrics:
+		src = &metricSource{reader: metricsQuerySvc}
+	default:
+		return nil, fmt.Errorf("unknown alerting source %q, must be %s or %s", options.Source, SourceSpans, SourceMetrics)
+	}
+	m := newManager(options, src, logger)
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func newManager(options Options, src source, logger *zap.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		options: options,
+		source:  src,
+		sender:  &sender{client: &http.Client{Timeout: options.NotificationTimeout}},
+		logger:  logger,
+		timeNow: time.Now,
+		rules:   make(map[string]map[string]*ruleEntry),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// load reads the rules and their states from the state file, if any.
+func (m *Manager) load() error {
+	if m.options.StateFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.options.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the alerting state file: %w", err)
+	}
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse the alerting state file: %w", err)
+	}
+	for i := range file.Rules {
+		persisted := &file.Rules[i]
+		if err := persisted.Rule.init(); err != nil {
+			return fmt.Errorf("invalid rule #%d of the alerting state file: %w", i, err)
+		}
+		m.tenantRules(persisted.Tenant)[persisted.Rule.Name] = &ruleEntry{
+			tenant: persisted.Tenant,
+			rule:   persisted.Rule,
+			status: persisted.Status,
+		}
+	}
+	return nil
+}
+
+// save writes the rules and their states to the state file, replacing it atomically.
+// It must be called with the lock held.
+func (m *Manager) save() error {
+	if m.options.StateFile == "" {
+		return nil
+	}
+	file := stateFile{Rules: []persistedRule{}}
+	for _, rules := range m.rules {
+		for _, entry := range rules {
+			file.Rules = append(file.Rules, persistedRule{Tenant: entry.tenant, Rule: entry.rule, Status: entry.status})
+		}
+	}
+	sort.Slice(file.Rules, func(i, j int) bool {
+		if file.Rules[i].Tenant != file.Rules[j].Tenant {
+			return file.Rules[i].Tenant < file.Rules[j].Tenant
+		}
+		return file.Rules[i].Rule.Name < file.Rules[j].Rule.Name
+	})
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(m.options.StateFile), filepath.Base(m.options.StateFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write the alerting state file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), m.options.StateFile)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the alerting state file: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) tenantRules(tenant string) map[string]*ruleEntry {
+	rules, ok := m.rules[tenant]
+	if !ok {
+		rules = make(map[string]*ruleEntry)
+		m.rules[tenant] = rules
+	}
+	return rules
+}
+
+func (e *ruleEntry) toStatus() *RuleStatus {
+	return &RuleStatus{Rule: e.rule, Status: e.status}
+}
+
+// Rules returns the rules of the tenant of the context, ordered by name.
+func (m *Manager) Rules(ctx context.Context) []*RuleStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	rules := m.rules[tenancy.GetTenant(ctx)]
+	found := make([]*RuleStatus, 0, len(rules))
+	for _, entry := range rules {
+		found = append(found, entry.toStatus())
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// GetRule returns a rule of the tenant of the context, or ErrRuleNotFound.
+func (m *Manager) GetRule(ctx context.Context, name string) (*RuleStatus, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	entry, ok := m.rules[tenancy.GetTenant(ctx)][name]
+	if !ok {
+		return nil, ErrRuleNotFound
+	}
+	return entry.toStatus(), nil
+}
+
+// PutRule creates or replaces a rule of the tenant of the context. The state of a replaced
+// rule is kept, so that its notifications are resolved if it stops firing.
+func (m *Manager) PutRule(ctx context.Context, rule Rule) (*RuleStatus, error) {
+	if err := rule.init(); err != nil {
+		return nil, err
+	}
+	if err := m.source.validate(&rule); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	tenant := tenancy.GetTenant(ctx)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	rules := m.tenantRules(tenant)
+	entry := &ruleEntry{tenant: tenant, rule: rule, status: RuleState{State: StateOK}}
+	if previous, ok := rules[rule.Name]; ok {
+		entry.status = previous.status
+	}
+	rules[rule.Name] = entry
+	return entry.toStatus(), m.save()
+}
+
+// DeleteRule deletes a rule of the tenant of the context, returning ErrRuleNotFound if there is none.
+// No notification is sent for a deleted rule that was firing.
+func (m *Manager) DeleteRule(ctx context.Context, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	rules := m.rules[tenancy.GetTenant(ctx)]
+	if _, ok := rules[name]; !ok {
+		return ErrRuleNotFound
+	}
+	delete(rules, name)
+	return m.save()
+}
+
+// Start evaluates the rules periodically until Close is called.
+func (m *Manager) Start() {
+	m.done.Add(1)
+	go func() {
+		defer m.done.Done()
+		ticker := time.NewTicker(m.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.evaluate(m.ctx)
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the evaluations and waits for the current one to finish.
+func (m *Manager) Close() error {
+	m.closeOnce.Do(m.cancel)
+	m.done.Wait()
+	return nil
+}
+
+// evaluate evaluates all the rules of all the tenants, then persists their states.
+func (m *Manager) evaluate(ctx context.Context) {
+	m.lock.Lock()
+	var entries []*ruleEntry
+	for _, rules := range m.rules {
+		for _, entry := range rules {
+			entries = append(entries, entry)
+		}
+	}
+	m.lock.Unlock()
+
+	for _, entry := range entries {
+		m.evaluateRule(ctx, entry)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err := m.save(); err != nil {
+		m.logger.Error("Failed to persist the states of the alerting rules", zap.Error(err))
+	}
+}
+
+// evaluateRule computes the signal of the rule, updates its state and sends its notifications
+// if it starts or stops firing. The rules without data keep their state.
+func (m *Manager) evaluateRule(ctx context.Context, entry *ruleEntry) {
+	now := m.timeNow()
+	rule := &entry.rule
+	value, ok, err := m.source.evaluate(tenancy.WithTenant(ctx, entry.tenant), rule, now)
+
+	m.lock.Lock()
+	if m.rules[entry.tenant][rule.Name] != entry {
+		// the rule was replaced or deleted during its evaluation
+		m.lock.Unlock()
+		return
+	}
+	status := &entry.status
+	status.LastEvaluation = now
+	status.LastError = ""
+	status.Value = nil
+	if err != nil {
+		status.LastError = err.Error()
+		m.lock.Unlock()
+		m.logger.Error("Failed to evaluate an alerting rule", zap.String("tenant", entry.tenant), zap.String("rule", rule.Name), zap.Error(err))
+		return
+	}
+	if !ok {
+		m.lock.Unlock()
+		return
+	}
+	status.Value = &value
+	state := StateOK
+	if rule.fires(value) {
+		state = StateFiring
+	}
+	changed := state != status.State
+	if changed || status.Since.IsZero() {
+		status.State = state
+		status.Since = now
+	}
+	notification := &Notification{
+		Status:      statusFiring,
+		Tenant:      entry.tenant,
+		Rule:        rule.Name,
+		Service:     rule.Service,
+		Operation:   rule.Operation,
+		Signal:      rule.Signal,
+		Operator:    rule.Operator,
+		Threshold:   rule.Threshold,
+		Value:       value,
+		Window:      rule.window.String(),
+		StartsAt:    status.Since,
+		EvaluatedAt: now,
+	}
+	m.lock.Unlock()
+
+	if !changed {
+		return
+	}
+	if state == StateOK {
+		notification.Status = statusResolved
+	}
+	m.logger.Info("Alerting rule changed state", zap.String("tenant", entry.tenant), zap.String("rule", rule.Name),
+		zap.String("status", notification.Status), zap.Float64("value", value))
+	if err := m.sender.notifyAll(ctx, rule.Notifiers, notification); err != nil {
+		m.logger.Error("Failed to send the notifications of an alerting rule", zap.String("tenant", entry.tenant), zap.String("rule", rule.Name), zap.Error(err))
+	}
+}
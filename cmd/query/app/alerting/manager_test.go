@@ -0,0 +1,278 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+type fakeValue struct {
+	value float64
+	ok    bool
+	err   error
+}
+
+// fakeSource returns the values set for the rules, and records the tenants they were evaluated for.
+type fakeSource struct {
+	sync.Mutex
+	values  map[string]fakeValue
+	tenants map[string]string
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{values: make(map[string]fakeValue), tenants: make(map[string]string)}
+}
+
+func (s *fakeSource) set(rule string, value fakeValue) {
+	s.Lock()
+	defer s.Unlock()
+	s.values[rule] = value
+}
+
+func (*fakeSource) validate(rule *Rule) error {
+	if rule.Quantile == 0.9 {
+		return errors.New("unsupported quantile")
+	}
+	return nil
+}
+
+func (s *fakeSource) evaluate(ctx context.Context, rule *Rule, _ time.Time) (float64, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.tenants[rule.Name] = tenancy.GetTenant(ctx)
+	v := s.values[rule.Name]
+	return v.value, v.ok, v.err
+}
+
+func newTestManager(t *testing.T, options Options) (*Manager, *fakeSource) {
+	if options.Interval == 0 {
+		options.Interval = time.Minute
+	}
+	src := newFakeSource()
+	m := newManager(options, src, zap.NewNop())
+	m.timeNow = func() time.Time { return testNow }
+	t.Cleanup(func() {
+		require.NoError(t, m.Close())
+	})
+	return m, src
+}
+
+func TestNewManager(t *testing.T) {
+	querySvc := newTestQueryService(t)
+	m, err := NewManager(Options{Interval: time.Minute, Source: SourceSpans}, querySvc, &fakeMetricsReader{}, zap.NewNop())
+	require.NoError(t, err)
+	assert.IsType(t, &spanSource{}, m.source)
+	m, err = NewManager(Options{Interval: time.Minute, Source: SourceMetrics}, querySvc, &fakeMetricsReader{}, zap.NewNop())
+	require.NoError(t, err)
+	assert.IsType(t, &metricSource{}, m.source)
+	// the state file is created by the first change
+	m, err = NewManager(Options{Interval: time.Minute, Source: SourceSpans, StateFile: filepath.Join(t.TempDir(), "missing.json")}, querySvc, nil, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, m.Rules(context.Background()))
+}
+
+func TestNewManagerErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+		return filename
+	}
+	tests := []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{name: "interval", options: Options{Source: SourceSpans}, err: "the interval of the alerting rules evaluation must be positive"},
+		{name: "source", options: Options{Interval: time.Minute, Source: "logs"}, err: `unknown alerting source "logs"`},
+		{name: "unreadable state file", options: Options{Interval: time.Minute, Source: SourceSpans, StateFile: dir}, err: "failed to read the alerting state file"},
+		{name: "malformed state file", options: Options{Interval: time.Minute, Source: SourceSpans, StateFile: writeFile("malformed.json", "{")}, err: "failed to parse the alerting state file"},
+		{
+			name:    "invalid rule",
+			options: Options{Interval: time.Minute, Source: SourceSpans, StateFile: writeFile("invalid.json", `{"rules": [{"rule": {"name": "errors"}}]}`)},
+			err:     "invalid rule #0 of the alerting state file",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewManager(test.options, nil, nil, zap.NewNop())
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestManagerRules(t *testing.T) {
+	m, _ := newTestManager(t, Options{})
+	ctx := context.Background()
+	acme := tenancy.WithTenant(ctx, "acme")
+
+	status, err := m.PutRule(ctx, Rule{Name: "latency", Service: "frontend", Signal: SignalLatency, Operator: ">", Threshold: 500})
+	require.NoError(t, err)
+	assert.Equal(t, 0.95, status.Quantile)
+	assert.Equal(t, StateOK, status.Status.State)
+	_, err = m.PutRule(ctx, Rule{Name: "errors", Service: "frontend", Signal: SignalErrorRate, Operator: ">", Threshold: 0.05})
+	require.NoError(t, err)
+	_, err = m.PutRule(acme, Rule{Name: "errors", Service: "billing", Signal: SignalErrorRate, Operator: ">", Threshold: 0.1})
+	require.NoError(t, err)
+
+	rules := m.Rules(ctx)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "errors", rules[0].Name)
+	assert.Equal(t, "latency", rules[1].Name)
+	rule, err := m.GetRule(acme, "errors")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", rule.Service)
+	_, err = m.GetRule(acme, "latency")
+	require.ErrorIs(t, err, ErrRuleNotFound)
+
+	_, err = m.PutRule(ctx, Rule{Name: "errors", Service: "frontend"})
+	require.ErrorIs(t, err, ErrInvalidRule)
+	_, err = m.PutRule(ctx, Rule{Name: "latency", Service: "frontend", Signal: SignalLatency, Quantile: 0.9, Operator: ">"})
+	require.ErrorIs(t, err, ErrInvalidRule)
+	require.ErrorContains(t, err, "unsupported quantile")
+
+	require.NoError(t, m.DeleteRule(ctx, "errors"))
+	require.ErrorIs(t, m.DeleteRule(ctx, "errors"), ErrRuleNotFound)
+	assert.Len(t, m.Rules(ctx), 1)
+	assert.Len(t, m.Rules(acme), 1)
+}
+
+func TestManagerEvaluate(t *testing.T) {
+	server, received := newReceiver(t)
+	m, src := newTestManager(t, Options{})
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	_, err := m.PutRule(acme, Rule{
+		Name:      "errors",
+		Service:   "frontend",
+		Signal:    SignalErrorRate,
+		Operator:  ">",
+		Threshold: 0.05,
+		Notifiers: []Notifier{{Type: NotifierWebhook, URL: server.URL + "/webhook"}},
+	})
+	require.NoError(t, err)
+	status := func() RuleState {
+		rule, err := m.GetRule(acme, "errors")
+		require.NoError(t, err)
+		return rule.Status
+	}
+
+	src.set("errors", fakeValue{value: 0.01, ok: true})
+	m.evaluate(context.Background())
+	assert.Equal(t, "acme", src.tenants["errors"])
+	assert.Equal(t, StateOK, status().State)
+	assert.Equal(t, testNow, status().Since)
+	assert.Equal(t, 0.01, *status().Value)
+	assert.Empty(t, received.get("/webhook"))
+
+	start := testNow.Add(time.Minute)
+	m.timeNow = func() time.Time { return start }
+	src.set("errors", fakeValue{value: 0.25, ok: true})
+	m.evaluate(context.Background())
+	assert.Equal(t, StateFiring, status().State)
+	assert.Equal(t, start, status().Since)
+	notifications := received.get("/webhook")
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "firing", notifications[0]["status"])
+	assert.Equal(t, "acme", notifications[0]["tenant"])
+	assert.Equal(t, 0.25, notifications[0]["value"])
+
+	// the rules keep firing without notifications, and keep their state without data or on errors
+	m.timeNow = func() time.Time { return start.Add(time.Minute) }
+	m.evaluate(context.Background())
+	src.set("errors", fakeValue{})
+	m.evaluate(context.Background())
+	assert.Equal(t, StateFiring, status().State)
+	assert.Nil(t, status().Value)
+	src.set("errors", fakeValue{err: errors.New("storage error")})
+	m.evaluate(context.Background())
+	assert.Equal(t, StateFiring, status().State)
+	assert.Equal(t, "storage error", status().LastError)
+	assert.Equal(t, start, status().Since)
+	assert.Len(t, received.get("/webhook"), 1)
+
+	src.set("errors", fakeValue{value: 0, ok: true})
+	m.evaluate(context.Background())
+	assert.Equal(t, StateOK, status().State)
+	assert.Empty(t, status().LastError)
+	notifications = received.get("/webhook")
+	require.Len(t, notifications, 2)
+	assert.Equal(t, "resolved", notifications[1]["status"])
+}
+
+func TestManagerEvaluateNotificationError(t *testing.T) {
+	server, received := newReceiver(t)
+	m, src := newTestManager(t, Options{})
+	_, err := m.PutRule(context.Background(), Rule{
+		Name:      "calls",
+		Service:   "frontend",
+		Signal:    SignalCallRate,
+		Operator:  "<",
+		Threshold: 1,
+		Notifiers: []Notifier{{Type: NotifierWebhook, URL: server.URL + "/fail"}},
+	})
+	require.NoError(t, err)
+	src.set("calls", fakeValue{value: 0, ok: true})
+	m.evaluate(context.Background())
+	// the state changes even if the notification fails
+	rule, err := m.GetRule(context.Background(), "calls")
+	require.NoError(t, err)
+	assert.Equal(t, StateFiring, rule.Status.State)
+	assert.Len(t, received.get("/fail"), 1)
+}
+
+func TestManagerStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "alerting.json")
+	m, src := newTestManager(t, Options{StateFile: stateFile})
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	_, err := m.PutRule(acme, Rule{Name: "errors", Service: "frontend", Signal: SignalErrorRate, Operator: ">", Threshold: 0.05, Window: "10m"})
+	require.NoError(t, err)
+	_, err = m.PutRule(context.Background(), Rule{Name: "calls", Service: "frontend", Signal: SignalCallRate, Operator: "<", Threshold: 1})
+	require.NoError(t, err)
+	src.set("errors", fakeValue{value: 0.25, ok: true})
+	m.evaluate(context.Background())
+
+	reloaded, err := NewManager(Options{Interval: time.Minute, Source: SourceSpans, StateFile: stateFile}, nil, nil, zap.NewNop())
+	require.NoError(t, err)
+	rule, err := reloaded.GetRule(acme, "errors")
+	require.NoError(t, err)
+	assert.Equal(t, StateFiring, rule.Status.State)
+	assert.Equal(t, testNow, rule.Status.Since)
+	assert.Equal(t, 10*time.Minute, rule.window)
+	assert.Len(t, reloaded.Rules(context.Background()), 1)
+
+	require.NoError(t, m.DeleteRule(acme, "errors"))
+	reloaded, err = NewManager(Options{Interval: time.Minute, Source: SourceSpans, StateFile: stateFile}, nil, nil, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.Rules(acme))
+
+	// the failure to persist a change is returned
+	m.options.StateFile = filepath.Join(t.TempDir(), "missing", "alerting.json")
+	_, err = m.PutRule(context.Background(), Rule{Name: "calls", Service: "frontend", Signal: SignalCallRate, Operator: "<", Threshold: 2})
+	require.ErrorContains(t, err, "failed to write the alerting state file")
+}
+
+func TestManagerStart(t *testing.T) {
+	m, src := newTestManager(t, Options{Interval: time.Millisecond})
+	_, err := m.PutRule(context.Background(), Rule{Name: "calls", Service: "frontend", Signal: SignalCallRate, Operator: "<", Threshold: 1})
+	require.NoError(t, err)
+	src.set("calls", fakeValue{value: 0, ok: true})
+	m.Start()
+	assert.Eventually(t, func() bool {
+		rule, err := m.GetRule(context.Background(), "calls")
+		return err == nil && rule.Status.State == StateFiring
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, m.Close())
+}
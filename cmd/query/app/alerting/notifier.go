@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// State is the state of a rule.
+type State string
+
+const (
+	// StateOK is the state of the rules whose signal does not cross the threshold, or was never evaluated.
+	StateOK State = "ok"
+	// StateFiring is the state of the rules whose signal crossed the threshold at their last evaluation.
+	StateFiring State = "firing"
+)
+
+// Notification is sent when a rule starts or stops firing, it is the body of the webhook notifiers.
+type Notification struct {
+	// Status is "firing" or "resolved".
+	Status      string    `json:"status"`
+	Tenant      string    `json:"tenant,omitempty"`
+	Rule        string    `json:"rule"`
+	Service     string    `json:"service"`
+	Operation   string    `json:"operation,omitempty"`
+	Signal      Signal    `json:"signal"`
+	Operator    string    `json:"operator"`
+	Threshold   float64   `json:"threshold"`
+	Value       float64   `json:"value"`
+	Window      string    `json:"window"`
+	StartsAt    time.Time `json:"startsAt"`
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+}
+
+const (
+	statusFiring   = "firing"
+	statusResolved = "resolved"
+)
+
+func (n *Notification) summary() string {
+	target := n.Service
+	if n.Operation != "" {
+		target += " " + n.Operation
+	}
+	if n.Status == statusResolved {
+		return fmt.Sprintf("[RESOLVED] %s: the %s of %s is %g", n.Rule, n.Signal, target, n.Value)
+	}
+	return fmt.Sprintf("[FIRING] %s: the %s of %s is %g %s %g over %s", n.Rule, n.Signal, target, n.Value, n.Operator, n.Threshold, n.Window)
+}
+
+// sender posts the notifications to the notifiers of the rules.
+type sender struct {
+	client *http.Client
+}
+
+func (s *sender) send(ctx context.Context, notifier Notifier, notification *Notification) error {
+	var url string
+	var payload any
+	switch notifier.Type {
+	case NotifierWebhook:
+		url, payload = notifier.URL, notification
+	case NotifierSlack:
+		url, payload = notifier.URL, map[string]string{"text": notification.summary()}
+	default:
+		url = notifier.URL
+		if url == "" {
+			url = defaultPagerDutyURL
+		}
+		payload = pagerDutyEvent(notifier.RoutingKey, notification)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain the body to reuse the connection
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the %s notifier returned the status %d", notifier.Type, resp.StatusCode)
+	}
+	return nil
+}
+
+// pagerDutyEvent returns the event of the PagerDuty Events API v2 triggering or resolving the
+// incident of the rule, deduplicated by the tenant and name of the rule.
+func pagerDutyEvent(routingKey string, notification *Notification) map[string]any {
+	event := map[string]any{
+		"routing_key": routingKey,
+		"dedup_key":   "jaeger/" + notification.Tenant + "/" + notification.Rule,
+	}
+	if notification.Status == statusResolved {
+		event["event_action"] = "resolve"
+		return event
+	}
+	event["event_action"] = "trigger"
+	event["payload"] = map[string]any{
+		"summary":        notification.summary(),
+		"source":         notification.Service,
+		"severity":       "error",
+		"timestamp":      notification.StartsAt.Format(time.RFC3339),
+		"custom_details": notification,
+	}
+	return event
+}
+
+// notifyAll sends the notification to all the notifiers, returning their errors.
+func (s *sender) notifyAll(ctx context.Context, notifiers []Notifier, notification *Notification) error {
+	var errs []error
+	for _, notifier := range notifiers {
+		if err := s.send(ctx, notifier, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedRequests struct {
+	sync.Mutex
+	bodies map[string][]map[string]any
+}
+
+// newReceiver records the JSON bodies posted to each path, responding 500 to /fail.
+func newReceiver(t *testing.T) (*httptest.Server, *receivedRequests) {
+	received := &receivedRequests{bodies: make(map[string][]map[string]any)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(data, &body))
+		received.Lock()
+		received.bodies[r.URL.Path] = append(received.bodies[r.URL.Path], body)
+		received.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func (r *receivedRequests) get(path string) []map[string]any {
+	r.Lock()
+	defer r.Unlock()
+	return r.bodies[path]
+}
+
+func testNotification() *Notification {
+	return &Notification{
+		Status:      statusFiring,
+		Tenant:      "acme",
+		Rule:        "checkout-errors",
+		Service:     "frontend",
+		Operation:   "checkout",
+		Signal:      SignalErrorRate,
+		Operator:    ">",
+		Threshold:   0.05,
+		Value:       0.25,
+		Window:      "5m0s",
+		StartsAt:    testNow,
+		EvaluatedAt: testNow,
+	}
+}
+
+func TestSender(t *testing.T) {
+	server, received := newReceiver(t)
+	s := &sender{client: server.Client()}
+	notification := testNotification()
+	err := s.notifyAll(context.Background(), []Notifier{
+		{Type: NotifierWebhook, URL: server.URL + "/webhook"},
+		{Type: NotifierSlack, URL: server.URL + "/slack"},
+		{Type: NotifierPagerDuty, URL: server.URL + "/pagerduty", RoutingKey: "key"},
+	}, notification)
+	require.NoError(t, err)
+
+	webhook := received.get("/webhook")
+	require.Len(t, webhook, 1)
+	assert.Equal(t, "firing", webhook[0]["status"])
+	assert.Equal(t, "checkout-errors", webhook[0]["rule"])
+	assert.Equal(t, 0.25, webhook[0]["value"])
+	assert.Equal(t, "2024-05-01T12:00:00Z", webhook[0]["startsAt"])
+
+	slack := received.get("/slack")
+	require.Len(t, slack, 1)
+	assert.Equal(t, "[FIRING] checkout-errors: the error_rate of frontend checkout is 0.25 > 0.05 over 5m0s", slack[0]["text"])
+
+	pagerDuty := received.get("/pagerduty")
+	require.Len(t, pagerDuty, 1)
+	assert.Equal(t, "key", pagerDuty[0]["routing_key"])
+	assert.Equal(t, "trigger", pagerDuty[0]["event_action"])
+	assert.Equal(t, "jaeger/acme/checkout-errors", pagerDuty[0]["dedup_key"])
+	payload := pagerDuty[0]["payload"].(map[string]any)
+	assert.Equal(t, "frontend", payload["source"])
+	assert.Equal(t, "error", payload["severity"])
+
+	notification.Status = statusResolved
+	notification.Operation = ""
+	require.NoError(t, s.notifyAll(context.Background(), []Notifier{
+		{Type: NotifierSlack, URL: server.URL + "/slack"},
+		{Type: NotifierPagerDuty, URL: server.URL + "/pagerduty", RoutingKey: "key"},
+	}, notification))
+	assert.Equal(t, "[RESOLVED] checkout-errors: the error_rate of frontend is 0.25", received.get("/slack")[1]["text"])
+	resolve := received.get("/pagerduty")[1]
+	assert.Equal(t, "resolve", resolve["event_action"])
+	assert.Equal(t, "jaeger/acme/checkout-errors", resolve["dedup_key"])
+	assert.NotContains(t, resolve, "payload")
+}
+
+func TestSenderErrors(t *testing.T) {
+	server, received := newReceiver(t)
+	s := &sender{client: server.Client()}
+	err := s.notifyAll(context.Background(), []Notifier{
+		{Type: NotifierWebhook, URL: server.URL + "/fail"},
+		{Type: NotifierWebhook, URL: "http://localhost:0/unreachable"},
+		{Type: NotifierWebhook, URL: server.URL + "/webhook"},
+	}, testNotification())
+	require.ErrorContains(t, err, "the webhook notifier returned the status 500")
+	require.ErrorContains(t, err, "unreachable")
+	// the notifiers after a failed one are notified
+	assert.Len(t, received.get("/webhook"), 1)
+}
+
+func TestPagerDutyDefaultURL(t *testing.T) {
+	var url string
+	s := &sender{client: &http.Client{Transport: roundTripper(func(r *http.Request) (*http.Response, error) {
+		url = r.URL.String()
+		return &http.Response{StatusCode: http.StatusAccepted, Body: http.NoBody}, nil
+	})}}
+	require.NoError(t, s.send(context.Background(), Notifier{Type: NotifierPagerDuty, RoutingKey: "key"}, testNotification()))
+	assert.Equal(t, defaultPagerDutyURL, url)
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
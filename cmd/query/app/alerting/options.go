@@ -0,0 +1,58 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPrefix              = "query.alerting"
+	flagEnabled             = flagPrefix + ".enabled"
+	flagInterval            = flagPrefix + ".interval"
+	flagSource              = flagPrefix + ".source"
+	flagStateFile           = flagPrefix + ".state-file"
+	flagNotificationTimeout = flagPrefix + ".notification-timeout"
+
+	defaultInterval            = time.Minute
+	defaultSource              = SourceSpans
+	defaultNotificationTimeout = 10 * time.Second
+)
+
+// Options holds configuration for the alerting rules.
+type Options struct {
+	// Enabled evaluates the alerting rules in the query service and registers their API.
+	Enabled bool
+	// Interval is the time between two evaluations of the rules.
+	Interval time.Duration
+	// Source is where the signals are computed from, SourceSpans or SourceMetrics.
+	Source string
+	// StateFile is the path to the JSON file persisting the rules and their states, they are
+	// only kept in memory if empty.
+	StateFile string
+	// NotificationTimeout bounds the duration of the requests sending the notifications.
+	NotificationTimeout time.Duration
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Periodically evaluate the alerting rules on the error rate, call rate and latency of the services, managed via /api/alerting/rules, and notify webhooks, Slack or PagerDuty")
+	flagSet.Duration(flagInterval, defaultInterval, "The interval between two evaluations of the alerting rules")
+	flagSet.String(flagSource, defaultSource, "Where the signals of the alerting rules are computed from: spans, from the span storage, or metrics, from the metrics storage of the Service Performance Monitoring")
+	flagSet.String(flagStateFile, "", "The path to the JSON file persisting the alerting rules and their states; they are only kept in memory if empty")
+	flagSet.Duration(flagNotificationTimeout, defaultNotificationTimeout, "The timeout of the requests sending the notifications of the alerting rules")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.Interval = v.GetDuration(flagInterval)
+	o.Source = v.GetString(flagSource)
+	o.StateFile = v.GetString(flagStateFile)
+	o.NotificationTimeout = v.GetDuration(flagNotificationTimeout)
+	return o
+}
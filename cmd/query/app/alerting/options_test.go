@@ -0,0 +1,44 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.alerting.enabled=true",
+		"--query.alerting.interval=30s",
+		"--query.alerting.source=metrics",
+		"--query.alerting.state-file=/var/lib/jaeger/alerting.json",
+		"--query.alerting.notification-timeout=5s",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Enabled:             true,
+		Interval:            30 * time.Second,
+		Source:              SourceMetrics,
+		StateFile:           "/var/lib/jaeger/alerting.json",
+		NotificationTimeout: 5 * time.Second,
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Equal(t, defaultInterval, opts.Interval)
+	assert.Equal(t, SourceSpans, opts.Source)
+	assert.Empty(t, opts.StateFile)
+	assert.Equal(t, defaultNotificationTimeout, opts.NotificationTimeout)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// ErrInvalidRule is wrapped by the errors of the validation of the rules.
+var ErrInvalidRule = errors.New("invalid alerting rule")
+
+// ruleName restricts the names of the rules, which are part of their URLs.
+var ruleName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Signal is a value computed from the spans of a service, or of one of its operations, over a window.
+type Signal string
+
+const (
+	// SignalErrorRate is the ratio of the spans with an error, between 0 and 1.
+	SignalErrorRate Signal = "error_rate"
+	// SignalCallRate is the number of spans per second.
+	SignalCallRate Signal = "call_rate"
+	// SignalLatency is a quantile of the durations of the spans, in milliseconds.
+	SignalLatency Signal = "latency"
+)
+
+// NotifierType is the destination of the notifications of a rule.
+type NotifierType string
+
+const (
+	// NotifierWebhook posts a Notification as JSON to a URL.
+	NotifierWebhook NotifierType = "webhook"
+	// NotifierSlack posts a message to a Slack incoming webhook.
+	NotifierSlack NotifierType = "slack"
+	// NotifierPagerDuty triggers and resolves an incident with the PagerDuty Events API v2.
+	NotifierPagerDuty NotifierType = "pagerduty"
+)
+
+const (
+	defaultWindow          = 5 * time.Minute
+	defaultLatencyQuantile = 0.95
+	defaultPagerDutyURL    = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// Notifier configures a destination of the notifications of a rule.
+type Notifier struct {
+	Type NotifierType `json:"type"`
+	// URL is the URL of the webhook, or overrides the URL of the PagerDuty Events API.
+	URL string `json:"url,omitempty"`
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string `json:"routingKey,omitempty"`
+}
+
+// Rule fires when a signal of a service, optionally restricted to one of its operations, crosses a threshold,
+// e.g. the error rate of the operation checkout of the service frontend above 0.05 over 5m.
+type Rule struct {
+	Name      string `json:"name"`
+	Service   string `json:"service"`
+	Operation string `json:"operation,omitempty"`
+	Signal    Signal `json:"signal"`
+	// Quantile is the quantile of the latency signal, 0.95 if zero.
+	Quantile float64 `json:"quantile,omitempty"`
+	// Operator is ">" or "<", the rule fires when the inequality "signal operator threshold" holds.
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	// Window is the time range the signal is computed over, ending when the rule is evaluated, 5m if empty.
+	Window    string     `json:"window,omitempty"`
+	Notifiers []Notifier `json:"notifiers,omitempty"`
+
+	window time.Duration
+}
+
+// init validates the rule, sets its defaults and parses its window.
+func (r *Rule) init() error {
+	if err := r.validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if !ruleName.MatchString(r.Name) {
+		return fmt.Errorf("the name %q must only contain letters, digits, '_', '.' and '-'", r.Name)
+	}
+	if r.Service == "" {
+		return errors.New("the service is required")
+	}
+	switch r.Signal {
+	case SignalErrorRate, SignalCallRate:
+		if r.Quantile != 0 {
+			return fmt.Errorf("the quantile only applies to the %s signal", SignalLatency)
+		}
+	case SignalLatency:
+		if r.Quantile == 0 {
+			r.Quantile = defaultLatencyQuantile
+		}
+		if r.Quantile < 0 || r.Quantile > 1 {
+			return fmt.Errorf("the quantile %v must be between 0 and 1", r.Quantile)
+		}
+	default:
+		return fmt.Errorf("unknown signal %q, must be one of %s, %s or %s", r.Signal, SignalErrorRate, SignalCallRate, SignalLatency)
+	}
+	if r.Operator != ">" && r.Operator != "<" {
+		return fmt.Errorf("unknown operator %q, must be > or <", r.Operator)
+	}
+	r.window = defaultWindow
+	if r.Window != "" {
+		window, err := time.ParseDuration(r.Window)
+		if err != nil {
+			return fmt.Errorf("invalid window: %w", err)
+		}
+		if window <= 0 {
+			return errors.New("the window must be positive")
+		}
+		r.window = window
+	}
+	for i, n := range r.Notifiers {
+		if err := n.validate(); err != nil {
+			return fmt.Errorf("invalid notifier #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (n Notifier) validate() error {
+	switch n.Type {
+	case NotifierWebhook, NotifierSlack:
+		if n.URL == "" {
+			return fmt.Errorf("the URL of the %s notifier is required", n.Type)
+		}
+	case NotifierPagerDuty:
+		if n.RoutingKey == "" {
+			return errors.New("the routing key of the pagerduty notifier is required")
+		}
+	default:
+		return fmt.Errorf("unknown notifier type %q, must be one of %s, %s or %s", n.Type, NotifierWebhook, NotifierSlack, NotifierPagerDuty)
+	}
+	if n.URL != "" {
+		if u, err := url.Parse(n.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("the URL %q must be an absolute http or https URL", n.URL)
+		}
+	}
+	return nil
+}
+
+// fires checks the value of the signal against the threshold.
+func (r *Rule) fires(value float64) bool {
+	if r.Operator == ">" {
+		return value > r.Threshold
+	}
+	return value < r.Threshold
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleInit(t *testing.T) {
+	rule := Rule{Name: "checkout-latency", Service: "frontend", Signal: SignalLatency, Operator: ">", Threshold: 500}
+	require.NoError(t, rule.init())
+	assert.Equal(t, defaultLatencyQuantile, rule.Quantile)
+	assert.Equal(t, defaultWindow, rule.window)
+
+	rule = Rule{
+		Name:      "checkout-errors",
+		Service:   "frontend",
+		Operation: "checkout",
+		Signal:    SignalErrorRate,
+		Operator:  ">",
+		Threshold: 0.05,
+		Window:    "10m",
+		Notifiers: []Notifier{
+			{Type: NotifierWebhook, URL: "https://hooks.example.com/jaeger"},
+			{Type: NotifierSlack, URL: "https://hooks.slack.com/services/T0/B0/X"},
+			{Type: NotifierPagerDuty, RoutingKey: "key"},
+		},
+	}
+	require.NoError(t, rule.init())
+	assert.Equal(t, 10*time.Minute, rule.window)
+}
+
+func TestRuleInitErrors(t *testing.T) {
+	valid := func() Rule {
+		return Rule{Name: "errors", Service: "frontend", Signal: SignalErrorRate, Operator: ">", Threshold: 0.05}
+	}
+	tests := []struct {
+		name   string
+		modify func(*Rule)
+		err    string
+	}{
+		{name: "name", modify: func(r *Rule) { r.Name = "a b" }, err: "must only contain"},
+		{name: "service", modify: func(r *Rule) { r.Service = "" }, err: "the service is required"},
+		{name: "signal", modify: func(r *Rule) { r.Signal = "throughput" }, err: "unknown signal"},
+		{name: "quantile of error rate", modify: func(r *Rule) { r.Quantile = 0.9 }, err: "the quantile only applies"},
+		{name: "quantile", modify: func(r *Rule) { r.Signal, r.Quantile = SignalLatency, 2 }, err: "must be between 0 and 1"},
+		{name: "operator", modify: func(r *Rule) { r.Operator = ">=" }, err: "unknown operator"},
+		{name: "window", modify: func(r *Rule) { r.Window = "soon" }, err: "invalid window"},
+		{name: "negative window", modify: func(r *Rule) { r.Window = "-1m" }, err: "the window must be positive"},
+		{name: "notifier type", modify: func(r *Rule) { r.Notifiers = []Notifier{{Type: "email"}} }, err: "unknown notifier type"},
+		{name: "webhook URL", modify: func(r *Rule) { r.Notifiers = []Notifier{{Type: NotifierWebhook}} }, err: "the URL of the webhook notifier is required"},
+		{name: "routing key", modify: func(r *Rule) { r.Notifiers = []Notifier{{Type: NotifierPagerDuty}} }, err: "the routing key"},
+		{name: "URL scheme", modify: func(r *Rule) { r.Notifiers = []Notifier{{Type: NotifierSlack, URL: "ftp://slack"}} }, err: "absolute http or https URL"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := valid()
+			test.modify(&rule)
+			err := rule.init()
+			require.ErrorIs(t, err, ErrInvalidRule)
+			assert.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestRuleFires(t *testing.T) {
+	above := Rule{Operator: ">", Threshold: 0.05}
+	assert.True(t, above.fires(0.1))
+	assert.False(t, above.fires(0.05))
+	below := Rule{Operator: "<", Threshold: 1}
+	assert.True(t, below.fires(0.5))
+	assert.False(t, below.fires(1))
+}
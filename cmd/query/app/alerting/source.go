@@ -0,0 +1,141 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// SourceSpans computes the signals from the spans of the span storage.
+	SourceSpans = "spans"
+	// SourceMetrics reads the signals from the metrics storage of the Service Performance Monitoring.
+	SourceMetrics = "metrics"
+
+	operationLabel = "operation"
+)
+
+// errorTags selects the spans counted as errors.
+var errorTags = map[string]string{"error": "true"}
+
+// source computes the value of the signal of a rule over the window ending at end.
+// It returns false if there is no data to compute it from, e.g. no spans for the error rate.
+type source interface {
+	// validate checks that the source can compute the signal of the rule.
+	validate(rule *Rule) error
+	evaluate(ctx context.Context, rule *Rule, end time.Time) (float64, bool, error)
+}
+
+// spanSource computes the signals from the latency distributions of the spans.
+type spanSource struct {
+	querySvc *querysvc.QueryService
+}
+
+func (*spanSource) validate(rule *Rule) error {
+	if rule.Signal == SignalLatency && rule.Quantile != 0.5 && rule.Quantile != 0.95 && rule.Quantile != 0.99 {
+		return fmt.Errorf("the %s source only computes the 0.5, 0.95 and 0.99 latency quantiles", SourceSpans)
+	}
+	return nil
+}
+
+func (s *spanSource) evaluate(ctx context.Context, rule *Rule, end time.Time) (float64, bool, error) {
+	query := &spanstore.LatencyQueryParameters{
+		ServiceName:   rule.Service,
+		OperationName: rule.Operation,
+		StartTimeMin:  end.Add(-rule.window),
+		StartTimeMax:  end,
+	}
+	dist, err := s.querySvc.GetLatencyDistribution(ctx, query)
+	if err != nil {
+		return 0, false, err
+	}
+	switch rule.Signal {
+	case SignalCallRate:
+		return float64(dist.Count) / rule.window.Seconds(), true, nil
+	case SignalErrorRate:
+		if dist.Count == 0 {
+			return 0, false, nil
+		}
+		query.Tags = errorTags
+		errorDist, err := s.querySvc.GetLatencyDistribution(ctx, query)
+		if err != nil {
+			return 0, false, err
+		}
+		return float64(errorDist.Count) / float64(dist.Count), true, nil
+	default:
+		if dist.Count == 0 {
+			return 0, false, nil
+		}
+		// the quantile is checked by validate
+		latency := dist.P95
+		switch rule.Quantile {
+		case 0.5:
+			latency = dist.P50
+		case 0.99:
+			latency = dist.P99
+		}
+		return float64(latency) / float64(time.Millisecond), true, nil
+	}
+}
+
+// metricSource reads the signals from the metrics storage, with a single step covering the window.
+type metricSource struct {
+	reader metricsstore.Reader
+}
+
+func (*metricSource) validate(*Rule) error {
+	return nil
+}
+
+func (s *metricSource) evaluate(ctx context.Context, rule *Rule, end time.Time) (float64, bool, error) {
+	window := rule.window
+	params := metricsstore.BaseQueryParameters{
+		ServiceNames:     []string{rule.Service},
+		GroupByOperation: rule.Operation != "",
+		EndTime:          &end,
+		Lookback:         &window,
+		Step:             &window,
+		RatePer:          &window,
+	}
+	var family *metrics.MetricFamily
+	var err error
+	switch rule.Signal {
+	case SignalCallRate:
+		family, err = s.reader.GetCallRates(ctx, &metricsstore.CallRateQueryParameters{BaseQueryParameters: params})
+	case SignalErrorRate:
+		family, err = s.reader.GetErrorRates(ctx, &metricsstore.ErrorRateQueryParameters{BaseQueryParameters: params})
+	default:
+		family, err = s.reader.GetLatencies(ctx, &metricsstore.LatenciesQueryParameters{BaseQueryParameters: params, Quantile: rule.Quantile})
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	for _, metric := range family.GetMetrics() {
+		if rule.Operation != "" && labelValue(metric, operationLabel) != rule.Operation {
+			continue
+		}
+		points := metric.GetMetricPoints()
+		if len(points) == 0 {
+			continue
+		}
+		return points[len(points)-1].GetGaugeValue().GetDoubleValue(), true, nil
+	}
+	return 0, false, nil
+}
+
+func labelValue(metric *metrics.Metric, name string) string {
+	for _, label := range metric.GetLabels() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
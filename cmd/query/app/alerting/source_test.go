@@ -0,0 +1,161 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestQueryService stores four checkout spans of frontend, one of them an error, and one
+// span of another operation lasting 50ms.
+func newTestQueryService(t *testing.T) *querysvc.QueryService {
+	store := memory.NewStore()
+	frontend := model.NewProcess("frontend", nil)
+	for i := 1; i <= 5; i++ {
+		span := &model.Span{
+			TraceID:       model.NewTraceID(0, uint64(i)),
+			SpanID:        model.SpanID(i),
+			OperationName: "checkout",
+			StartTime:     testNow.Add(-time.Duration(i) * time.Second),
+			Duration:      time.Duration(i) * 100 * time.Millisecond,
+			Process:       frontend,
+		}
+		if i == 1 {
+			span.Tags = []model.KeyValue{model.Bool("error", true)}
+		}
+		if i == 5 {
+			span.OperationName = "health"
+			span.Duration = 50 * time.Millisecond
+		}
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	return querysvc.NewQueryService(store, store, querysvc.QueryServiceOptions{})
+}
+
+func initRule(t *testing.T, rule Rule) *Rule {
+	rule.Name = "test"
+	if rule.Operator == "" {
+		rule.Operator = ">"
+	}
+	require.NoError(t, rule.init())
+	return &rule
+}
+
+func TestSpanSource(t *testing.T) {
+	src := &spanSource{querySvc: newTestQueryService(t)}
+	tests := []struct {
+		name  string
+		rule  Rule
+		value float64
+		ok    bool
+	}{
+		{name: "error rate", rule: Rule{Service: "frontend", Operation: "checkout", Signal: SignalErrorRate}, value: 0.25, ok: true},
+		{name: "error rate of the service", rule: Rule{Service: "frontend", Signal: SignalErrorRate}, value: 0.2, ok: true},
+		{name: "call rate", rule: Rule{Service: "frontend", Signal: SignalCallRate, Window: "10s"}, value: 0.5, ok: true},
+		{name: "latency", rule: Rule{Service: "frontend", Operation: "health", Signal: SignalLatency, Quantile: 0.99}, value: 50, ok: true},
+		{name: "latency median", rule: Rule{Service: "frontend", Operation: "health", Signal: SignalLatency, Quantile: 0.5}, value: 50, ok: true},
+		{name: "no calls", rule: Rule{Service: "backend", Signal: SignalCallRate}, value: 0, ok: true},
+		{name: "no error rate", rule: Rule{Service: "backend", Signal: SignalErrorRate}},
+		{name: "no latency", rule: Rule{Service: "backend", Signal: SignalLatency}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := initRule(t, test.rule)
+			require.NoError(t, src.validate(rule))
+			value, ok, err := src.evaluate(context.Background(), rule, testNow)
+			require.NoError(t, err)
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.value, value, 1e-9)
+		})
+	}
+	err := src.validate(initRule(t, Rule{Service: "frontend", Signal: SignalLatency, Quantile: 0.9}))
+	require.ErrorContains(t, err, "only computes the 0.5, 0.95 and 0.99 latency quantiles")
+}
+
+type fakeMetricsReader struct {
+	metricsstore.Reader
+	family *metrics.MetricFamily
+	err    error
+
+	signal string
+	params metricsstore.BaseQueryParameters
+}
+
+func (r *fakeMetricsReader) GetLatencies(_ context.Context, params *metricsstore.LatenciesQueryParameters) (*metrics.MetricFamily, error) {
+	r.signal, r.params = "latencies", params.BaseQueryParameters
+	return r.family, r.err
+}
+
+func (r *fakeMetricsReader) GetCallRates(_ context.Context, params *metricsstore.CallRateQueryParameters) (*metrics.MetricFamily, error) {
+	r.signal, r.params = "calls", params.BaseQueryParameters
+	return r.family, r.err
+}
+
+func (r *fakeMetricsReader) GetErrorRates(_ context.Context, params *metricsstore.ErrorRateQueryParameters) (*metrics.MetricFamily, error) {
+	r.signal, r.params = "errors", params.BaseQueryParameters
+	return r.family, r.err
+}
+
+func gauge(operation string, values ...float64) *metrics.Metric {
+	metric := &metrics.Metric{
+		Labels: []*metrics.Label{{Name: "service_name", Value: "frontend"}, {Name: operationLabel, Value: operation}},
+	}
+	for _, value := range values {
+		metric.MetricPoints = append(metric.MetricPoints, &metrics.MetricPoint{
+			Value: &metrics.MetricPoint_GaugeValue{GaugeValue: &metrics.GaugeValue{Value: &metrics.GaugeValue_DoubleValue{DoubleValue: value}}},
+		})
+	}
+	return metric
+}
+
+func TestMetricSource(t *testing.T) {
+	reader := &fakeMetricsReader{family: &metrics.MetricFamily{Metrics: []*metrics.Metric{
+		gauge("health"),
+		gauge("other", 0.5),
+		gauge("checkout", 0.1, 0.2),
+	}}}
+	src := &metricSource{reader: reader}
+	rule := initRule(t, Rule{Service: "frontend", Operation: "checkout", Signal: SignalErrorRate})
+	require.NoError(t, src.validate(rule))
+	value, ok, err := src.evaluate(context.Background(), rule, testNow)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 0.2, value)
+	assert.Equal(t, "errors", reader.signal)
+	assert.Equal(t, []string{"frontend"}, reader.params.ServiceNames)
+	assert.True(t, reader.params.GroupByOperation)
+	assert.Equal(t, testNow, *reader.params.EndTime)
+	assert.Equal(t, defaultWindow, *reader.params.Lookback)
+	assert.Equal(t, defaultWindow, *reader.params.Step)
+
+	_, _, err = src.evaluate(context.Background(), initRule(t, Rule{Service: "frontend", Signal: SignalCallRate}), testNow)
+	require.NoError(t, err)
+	assert.Equal(t, "calls", reader.signal)
+	assert.False(t, reader.params.GroupByOperation)
+	_, _, err = src.evaluate(context.Background(), initRule(t, Rule{Service: "frontend", Signal: SignalLatency, Quantile: 0.9}), testNow)
+	require.NoError(t, err)
+	assert.Equal(t, "latencies", reader.signal)
+
+	_, ok, err = src.evaluate(context.Background(), initRule(t, Rule{Service: "frontend", Operation: "health", Signal: SignalErrorRate}), testNow)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	reader.err = errors.New("metrics error")
+	_, _, err = src.evaluate(context.Background(), rule, testNow)
+	require.ErrorContains(t, err, "metrics error")
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// allowedHostsHandler rejects the requests whose Host header, or X-Forwarded-Host header of a
+// trusted proxy, is not one of the allowed hosts, e.g. to protect the UI from DNS rebinding.
+// The hosts are case insensitive and may start with a "*." wildcard matching their subdomains.
+func allowedHostsHandler(h http.Handler, allowedHosts []string) http.Handler {
+	if len(allowedHosts) == 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAllowedHost(allowedHosts, r.Host) {
+			http.Error(w, "host not allowed", http.StatusMisdirectedRequest)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func isAllowedHost(allowedHosts []string, hostPort string) bool {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = hostPort
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
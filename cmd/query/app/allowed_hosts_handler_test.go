@@ -0,0 +1,45 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedHostsHandler(t *testing.T) {
+	handler := allowedHostsHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), []string{"jaeger.example.com", "*.tracing.example.com", "::1"})
+	tests := map[string]int{
+		"jaeger.example.com":          http.StatusOK,
+		"JAEGER.example.com.:16686":   http.StatusOK,
+		"ui.tracing.example.com":      http.StatusOK,
+		"[::1]:16686":                 http.StatusOK,
+		"tracing.example.com":         http.StatusMisdirectedRequest,
+		"evil.com":                    http.StatusMisdirectedRequest,
+		"jaeger.example.com.evil.com": http.StatusMisdirectedRequest,
+		"":                            http.StatusMisdirectedRequest,
+	}
+	for host, statusCode := range tests {
+		t.Run(host, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Host = host
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestAllowedHostsHandlerDisabled(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Host = "evil.com"
+	recorder := httptest.NewRecorder()
+	allowedHostsHandler(http.NotFoundHandler(), nil).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
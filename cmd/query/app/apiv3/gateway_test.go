@@ -51,8 +51,10 @@ var regenerateSnapshots = os.Getenv("REGENERATE_SNAPSHOTS") == "true"
 
 type testGateway struct {
 	reader *spanstoremocks.Reader
-	url    string
-	router *mux.Router
+	// pageReader is the pagination of the reader, if supported
+	pageReader *spanstoremocks.PaginatedReader
+	url        string
+	router     *mux.Router
 	// used to set a tenancy header when executing requests
 	setupRequest func(*http.Request)
 }
@@ -16,10 +16,12 @@ package apiv3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gogo/protobuf/types"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
@@ -28,6 +30,10 @@ import (
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
+// NextPageTokenTrailer is the gRPC trailer of FindTraces responses that contains
+// the continuation token of the next page, since traces are streamed as TracesData.
+const NextPageTokenTrailer = "next-page-token"
+
 // Handler implements api_v3.QueryServiceServer
 type Handler struct {
 	QueryService *querysvc.QueryService
@@ -71,6 +77,7 @@ func (h *Handler) FindTraces(request *api_v3.FindTracesRequest, stream api_v3.Qu
 		OperationName: query.GetOperationName(),
 		Tags:          query.GetAttributes(),
 		NumTraces:     int(query.GetNumTraces()),
+		PageToken:     query.GetPageToken(),
 	}
 	if query.GetStartTimeMin() != nil {
 		startTimeMin, err := types.TimestampFromProto(query.GetStartTimeMin())
@@ -101,11 +108,20 @@ func (h *Handler) FindTraces(request *api_v3.FindTracesRequest, stream api_v3.Qu
 		queryParams.DurationMax = durationMax
 	}
 
-	traces, err := h.QueryService.FindTraces(stream.Context(), queryParams)
+	page, err := h.QueryService.FindTracesPage(stream.Context(), queryParams)
+	if errors.Is(err, spanstore.ErrInvalidPageToken) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, querysvc.ErrQueryCostExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
 	if err != nil {
 		return err
 	}
-	for _, t := range traces {
+	if page.NextPageToken != "" {
+		stream.SetTrailer(metadata.Pairs(NextPageTokenTrailer, page.NextPageToken))
+	}
+	for _, t := range page.Traces {
 		td, err := modelToOTLP(t.GetSpans())
 		if err != nil {
 			return err
@@ -117,13 +133,18 @@ func (h *Handler) FindTraces(request *api_v3.FindTracesRequest, stream api_v3.Qu
 }
 
 // GetServices implements api_v3.QueryServiceServer's GetServices
-func (h *Handler) GetServices(ctx context.Context, _ *api_v3.GetServicesRequest) (*api_v3.GetServicesResponse, error) {
+func (h *Handler) GetServices(ctx context.Context, request *api_v3.GetServicesRequest) (*api_v3.GetServicesResponse, error) {
 	services, err := h.QueryService.GetServices(ctx)
 	if err != nil {
 		return nil, err
 	}
+	services, nextPageToken, err := paginateServices(services, request.GetPageSize(), request.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	return &api_v3.GetServicesResponse{
-		Services: services,
+		Services:      services,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
@@ -136,6 +157,10 @@ func (h *Handler) GetOperations(ctx context.Context, request *api_v3.GetOperatio
 	if err != nil {
 		return nil, err
 	}
+	operations, nextPageToken, err := paginateOperations(operations, request.GetPageSize(), request.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	apiOperations := make([]*api_v3.Operation, len(operations))
 	for i := range operations {
 		apiOperations[i] = &api_v3.Operation{
@@ -144,6 +169,7 @@ func (h *Handler) GetOperations(ctx context.Context, request *api_v3.GetOperatio
 		}
 	}
 	return &api_v3.GetOperationsResponse{
-		Operations: apiOperations,
+		Operations:    apiOperations,
+		NextPageToken: nextPageToken,
 	}, nil
 }
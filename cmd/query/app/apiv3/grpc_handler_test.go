@@ -17,6 +17,7 @@ package apiv3
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"testing"
 
@@ -25,12 +26,15 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	_ "github.com/jaegertracing/jaeger/pkg/gogocodec" // force gogo codec registration
+	"github.com/jaegertracing/jaeger/pkg/otlptranslator"
 	dependencyStoreMocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
@@ -56,19 +60,40 @@ func newGrpcServer(t *testing.T, handler *Handler) (*grpc.Server, net.Addr) {
 }
 
 type testServerClient struct {
-	server  *grpc.Server
-	address net.Addr
-	reader  *spanstoremocks.Reader
-	client  api_v3.QueryServiceClient
+	server     *grpc.Server
+	address    net.Addr
+	reader     *spanstoremocks.Reader
+	pageReader *spanstoremocks.PaginatedReader
+	client     api_v3.QueryServiceClient
+}
+
+// paginatedReader is a mocked span reader supporting the pagination of the traces.
+type paginatedReader struct {
+	*spanstoremocks.Reader
+	*spanstoremocks.PaginatedReader
 }
 
 func newTestServerClient(t *testing.T) *testServerClient {
 	tsc := &testServerClient{
 		reader: &spanstoremocks.Reader{},
 	}
+	tsc.connect(t, tsc.reader)
+	return tsc
+}
+
+// newPaginatedTestServerClient creates a testServerClient whose span reader supports the pagination.
+func newPaginatedTestServerClient(t *testing.T) *testServerClient {
+	tsc := &testServerClient{
+		reader:     &spanstoremocks.Reader{},
+		pageReader: &spanstoremocks.PaginatedReader{},
+	}
+	tsc.connect(t, &paginatedReader{Reader: tsc.reader, PaginatedReader: tsc.pageReader})
+	return tsc
+}
 
+func (tsc *testServerClient) connect(t *testing.T, reader spanstore.Reader) {
 	q := querysvc.NewQueryService(
-		tsc.reader,
+		reader,
 		&dependencyStoreMocks.Reader{},
 		querysvc.QueryServiceOptions{},
 	)
@@ -84,8 +109,6 @@ func newTestServerClient(t *testing.T) *testServerClient {
 	require.NoError(t, err)
 	t.Cleanup(func() { conn.Close() })
 	tsc.client = api_v3.NewQueryServiceClient(conn)
-
-	return tsc
 }
 
 func TestGetTrace(t *testing.T) {
@@ -113,6 +136,63 @@ func TestGetTrace(t *testing.T) {
 		td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
 }
 
+func TestGetTraceSpanLinks(t *testing.T) {
+	tsc := newTestServerClient(t)
+	tsc.reader.On("GetTrace", matchContext, matchTraceID).Return(
+		&model.Trace{
+			Spans: []*model.Span{
+				{
+					TraceID:       model.NewTraceID(0, 1),
+					SpanID:        model.NewSpanID(1),
+					OperationName: "foobar",
+					References:    []model.SpanRef{model.NewFollowsFromRef(model.NewTraceID(0, 2), model.NewSpanID(2))},
+					Tags: []model.KeyValue{model.String(otlptranslator.LinksTagKey,
+						`[{"traceId":"00000000000000000000000000000002","spanId":"0000000000000002","attributes":[{"key":"k","value":{"intValue":"1"}}]}]`)},
+				},
+			},
+		}, nil).Once()
+
+	getTraceStream, err := tsc.client.GetTrace(context.Background(),
+		&api_v3.GetTraceRequest{
+			TraceId: "156",
+		},
+	)
+	require.NoError(t, err)
+	recv, err := getTraceStream.Recv()
+	require.NoError(t, err)
+	span := recv.ToTraces().ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Empty(t, span.Attributes().AsRaw())
+	require.Equal(t, 1, span.Links().Len())
+	assert.Equal(t, "00000000000000000000000000000002", span.Links().At(0).TraceID().String())
+	assert.Equal(t, map[string]any{"k": int64(1)}, span.Links().At(0).Attributes().AsRaw())
+}
+
+func TestGetTrace128BitID(t *testing.T) {
+	traceID := model.NewTraceID(1, 42)
+	tsc := newTestServerClient(t)
+	tsc.reader.On("GetTrace", matchContext, traceID).Return(
+		&model.Trace{
+			Spans: []*model.Span{
+				{
+					TraceID:       traceID,
+					SpanID:        model.NewSpanID(1),
+					OperationName: "foobar",
+				},
+			},
+		}, nil).Once()
+
+	getTraceStream, err := tsc.client.GetTrace(context.Background(),
+		&api_v3.GetTraceRequest{
+			TraceId: traceID.HexString(),
+		},
+	)
+	require.NoError(t, err)
+	recv, err := getTraceStream.Recv()
+	require.NoError(t, err)
+	span := recv.ToTraces().ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, "0000000000000001000000000000002a", span.TraceID().String())
+}
+
 func TestGetTraceStorageError(t *testing.T) {
 	tsc := newTestServerClient(t)
 	tsc.reader.On("GetTrace", matchContext, matchTraceID).Return(
@@ -176,6 +256,50 @@ func TestFindTraces(t *testing.T) {
 	require.EqualValues(t, 1, td.SpanCount())
 }
 
+func TestFindTracesPagination(t *testing.T) {
+	tsc := newPaginatedTestServerClient(t)
+	nextPageToken := spanstore.EncodePageToken(2)
+	tsc.pageReader.On("FindTracesPage", matchContext, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.PageToken == "page-token"
+	})).Return(&spanstore.TracesPage{
+		Traces:        []*model.Trace{{Spans: []*model.Span{{OperationName: "name"}}}},
+		NextPageToken: nextPageToken,
+	}, nil).Once()
+
+	responseStream, err := tsc.client.FindTraces(context.Background(), &api_v3.FindTracesRequest{
+		Query: &api_v3.TraceQueryParameters{
+			StartTimeMin: &types.Timestamp{},
+			StartTimeMax: &types.Timestamp{},
+			NumTraces:    2,
+			PageToken:    "page-token",
+		},
+	})
+	require.NoError(t, err)
+	_, err = responseStream.Recv()
+	require.NoError(t, err)
+	_, err = responseStream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []string{nextPageToken}, responseStream.Trailer().Get(NextPageTokenTrailer))
+}
+
+func TestFindTracesInvalidPageToken(t *testing.T) {
+	tsc := newPaginatedTestServerClient(t)
+	tsc.pageReader.On("FindTracesPage", matchContext, mock.AnythingOfType("*spanstore.TraceQueryParameters")).Return(
+		nil, spanstore.ErrInvalidPageToken).Once()
+
+	responseStream, err := tsc.client.FindTraces(context.Background(), &api_v3.FindTracesRequest{
+		Query: &api_v3.TraceQueryParameters{
+			StartTimeMin: &types.Timestamp{},
+			StartTimeMax: &types.Timestamp{},
+			PageToken:    "invalid",
+		},
+	})
+	require.NoError(t, err)
+	_, err = responseStream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestFindTracesQueryNil(t *testing.T) {
 	tsc := newTestServerClient(t)
 	responseStream, err := tsc.client.FindTraces(context.Background(), &api_v3.FindTracesRequest{})
@@ -228,6 +352,35 @@ func TestGetServices(t *testing.T) {
 	assert.Equal(t, []string{"foo"}, response.GetServices())
 }
 
+func TestGetServicesPagination(t *testing.T) {
+	tsc := newTestServerClient(t)
+	tsc.reader.On("GetServices", matchContext).Return(
+		[]string{"foo", "baz", "bar"}, nil).Twice()
+
+	response, err := tsc.client.GetServices(context.Background(), &api_v3.GetServicesRequest{PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar", "baz"}, response.GetServices())
+	require.NotEmpty(t, response.GetNextPageToken())
+
+	response, err = tsc.client.GetServices(context.Background(), &api_v3.GetServicesRequest{
+		PageSize:  2,
+		PageToken: response.GetNextPageToken(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, response.GetServices())
+	assert.Empty(t, response.GetNextPageToken())
+}
+
+func TestGetServicesInvalidPageToken(t *testing.T) {
+	tsc := newTestServerClient(t)
+	tsc.reader.On("GetServices", matchContext).Return(
+		[]string{"foo"}, nil).Once()
+
+	_, err := tsc.client.GetServices(context.Background(), &api_v3.GetServicesRequest{PageToken: "invalid"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestGetServicesStorageError(t *testing.T) {
 	tsc := newTestServerClient(t)
 	tsc.reader.On("GetServices", matchContext).Return(
@@ -257,6 +410,37 @@ func TestGetOperations(t *testing.T) {
 	}, response.GetOperations())
 }
 
+func TestGetOperationsPagination(t *testing.T) {
+	tsc := newTestServerClient(t)
+	tsc.reader.On("GetOperations", matchContext, mock.AnythingOfType("spanstore.OperationQueryParameters")).Return(
+		[]spanstore.Operation{
+			{Name: "get_users", SpanKind: "server"},
+			{Name: "get_users", SpanKind: "client"},
+			{Name: "add_user", SpanKind: "server"},
+		}, nil).Once()
+
+	response, err := tsc.client.GetOperations(context.Background(), &api_v3.GetOperationsRequest{
+		Service:  "foo",
+		PageSize: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*api_v3.Operation{
+		{Name: "add_user", SpanKind: "server"},
+		{Name: "get_users", SpanKind: "client"},
+	}, response.GetOperations())
+	assert.Equal(t, spanstore.EncodePageToken(2), response.GetNextPageToken())
+}
+
+func TestGetOperationsInvalidPageToken(t *testing.T) {
+	tsc := newTestServerClient(t)
+	tsc.reader.On("GetOperations", matchContext, mock.AnythingOfType("spanstore.OperationQueryParameters")).Return(
+		[]spanstore.Operation{{Name: "get_users"}}, nil).Once()
+
+	_, err := tsc.client.GetOperations(context.Background(), &api_v3.GetOperationsRequest{PageToken: "invalid"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestGetOperationsStorageError(t *testing.T) {
 	tsc := newTestServerClient(t)
 	tsc.reader.On("GetOperations", matchContext, mock.AnythingOfType("spanstore.OperationQueryParameters")).Return(
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gogo/protobuf/jsonpb"
@@ -36,6 +37,20 @@ const (
 	paramNumTraces     = "query.num_traces"
 	paramDurationMin   = "query.duration_min"
 	paramDurationMax   = "query.duration_max"
+	paramQueryToken    = "query.page_token"
+	paramPageSize      = "page_size" // get services and operations
+	paramPageToken     = "page_token"
+	paramFormat        = "format" // encoding of the traces
+
+	// formatOTLPJSON returns the traces as plain OTLP TracesData JSON, without the grpc-gateway wrapper.
+	formatOTLPJSON = "otlp_json"
+	// formatOTLPProto returns the traces as OTLP TracesData protobuf, also selected by the Accept header.
+	formatOTLPProto = "otlp_proto"
+
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/x-protobuf"
+	// headerNextPageToken holds the token of the next page of traces in the plain OTLP formats.
+	headerNextPageToken = "X-Next-Page-Token"
 
 	routeGetTrace      = "/api/v3/traces/{" + paramTraceID + "}"
 	routeFindTraces    = "/api/v3/traces"
@@ -88,6 +103,12 @@ func (h *HTTPGateway) tryHandleError(w http.ResponseWriter, err error, statusCod
 	if errors.Is(err, spanstore.ErrTraceNotFound) {
 		statusCode = http.StatusNotFound
 	}
+	if errors.Is(err, querysvc.ErrServiceNotAllowed) {
+		statusCode = http.StatusForbidden
+	}
+	if errors.Is(err, querysvc.ErrQueryCostExceeded) {
+		statusCode = http.StatusUnprocessableEntity
+	}
 	if statusCode == http.StatusInternalServerError {
 		h.Logger.Error("HTTP handler, Internal Server Error", zap.Error(err))
 	}
@@ -110,13 +131,31 @@ func (h *HTTPGateway) tryParamError(w http.ResponseWriter, err error, paramName
 	return h.tryHandleError(w, fmt.Errorf("malformed parameter %s: %w", paramName, err), http.StatusBadRequest)
 }
 
-func (h *HTTPGateway) returnSpans(spans []*model.Span, w http.ResponseWriter) {
+// parseFormat returns the encoding of the traces requested with the format parameter or the Accept header,
+// empty for the default grpc-gateway JSON.
+func parseFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get(paramFormat); format {
+	case "":
+		if strings.Contains(r.Header.Get("Accept"), contentTypeProtobuf) {
+			return formatOTLPProto, nil
+		}
+		return "", nil
+	case formatOTLPJSON, formatOTLPProto:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expecting %s or %s", format, formatOTLPJSON, formatOTLPProto)
+	}
+}
+
+func (h *HTTPGateway) returnSpans(spans []*model.Span, nextPageToken string, format string, w http.ResponseWriter) {
 	// modelToOTLP does not easily return an error, so allow mocking it
-	h.returnSpansTestable(spans, w, modelToOTLP)
+	h.returnSpansTestable(spans, nextPageToken, format, w, modelToOTLP)
 }
 
 func (h *HTTPGateway) returnSpansTestable(
 	spans []*model.Span,
+	nextPageToken string,
+	format string,
 	w http.ResponseWriter,
 	modelToOTLP func(_ []*model.Span) (ptrace.Traces, error),
 ) {
@@ -124,11 +163,30 @@ func (h *HTTPGateway) returnSpansTestable(
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
-	tracesData := api_v3.TracesData(td)
-	response := &api_v3.GRPCGatewayWrapper{
-		Result: &tracesData,
+	var body []byte
+	switch format {
+	case formatOTLPJSON:
+		body, err = new(ptrace.JSONMarshaler).MarshalTraces(td)
+		w.Header().Set("Content-Type", contentTypeJSON)
+	case formatOTLPProto:
+		body, err = new(ptrace.ProtoMarshaler).MarshalTraces(td)
+		w.Header().Set("Content-Type", contentTypeProtobuf)
+	default:
+		tracesData := api_v3.TracesData(td)
+		response := &api_v3.GRPCGatewayWrapper{
+			Result:        &tracesData,
+			NextPageToken: nextPageToken,
+		}
+		h.marshalResponse(response, w)
+		return
+	}
+	if h.tryHandleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	if nextPageToken != "" {
+		w.Header().Set(headerNextPageToken, nextPageToken)
 	}
-	h.marshalResponse(response, w)
+	_, _ = w.Write(body)
 }
 
 func (*HTTPGateway) marshalResponse(response proto.Message, w http.ResponseWriter) {
@@ -136,6 +194,10 @@ func (*HTTPGateway) marshalResponse(response proto.Message, w http.ResponseWrite
 }
 
 func (h *HTTPGateway) getTrace(w http.ResponseWriter, r *http.Request) {
+	format, err := parseFormat(r)
+	if h.tryParamError(w, err, paramFormat) {
+		return
+	}
 	vars := mux.Vars(r)
 	traceIDVar := vars[paramTraceID]
 	traceID, err := model.TraceIDFromString(traceIDVar)
@@ -146,25 +208,33 @@ func (h *HTTPGateway) getTrace(w http.ResponseWriter, r *http.Request) {
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
-	h.returnSpans(trace.Spans, w)
+	h.returnSpans(trace.Spans, "", format, w)
 }
 
 func (h *HTTPGateway) findTraces(w http.ResponseWriter, r *http.Request) {
+	format, err := parseFormat(r)
+	if h.tryParamError(w, err, paramFormat) {
+		return
+	}
 	queryParams, shouldReturn := h.parseFindTracesQuery(r.URL.Query(), w)
 	if shouldReturn {
 		return
 	}
 
-	traces, err := h.QueryService.FindTraces(r.Context(), queryParams)
+	page, err := h.QueryService.FindTracesPage(r.Context(), queryParams)
+	if errors.Is(err, spanstore.ErrInvalidPageToken) {
+		h.tryParamError(w, err, paramQueryToken)
+		return
+	}
 	// TODO how do we distinguish internal error from bad parameters for FindTrace?
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
 	var spans []*model.Span
-	for _, trace := range traces {
+	for _, trace := range page.Traces {
 		spans = append(spans, trace.Spans...)
 	}
-	h.returnSpans(spans, w)
+	h.returnSpans(spans, page.NextPageToken, format, w)
 }
 
 func (h *HTTPGateway) parseFindTracesQuery(q url.Values, w http.ResponseWriter) (*spanstore.TraceQueryParameters, bool) {
@@ -172,6 +242,7 @@ func (h *HTTPGateway) parseFindTracesQuery(q url.Values, w http.ResponseWriter)
 		ServiceName:   q.Get(paramServiceName),
 		OperationName: q.Get(paramOperationName),
 		Tags:          nil, // most curiously not supported by grpc-gateway
+		PageToken:     q.Get(paramQueryToken),
 	}
 
 	timeMin := q.Get(paramTimeMin)
@@ -217,13 +288,35 @@ func (h *HTTPGateway) parseFindTracesQuery(q url.Values, w http.ResponseWriter)
 	return queryParams, false
 }
 
+func (h *HTTPGateway) parsePageSize(q url.Values, w http.ResponseWriter) (int32, bool) {
+	n := q.Get(paramPageSize)
+	if n == "" {
+		return 0, false
+	}
+	pageSize, err := strconv.ParseInt(n, 10, 32)
+	if h.tryParamError(w, err, paramPageSize) {
+		return 0, true
+	}
+	return int32(pageSize), false
+}
+
 func (h *HTTPGateway) getServices(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pageSize, shouldReturn := h.parsePageSize(query, w)
+	if shouldReturn {
+		return
+	}
 	services, err := h.QueryService.GetServices(r.Context())
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
+	services, nextPageToken, err := paginateServices(services, pageSize, query.Get(paramPageToken))
+	if h.tryParamError(w, err, paramPageToken) {
+		return
+	}
 	h.marshalResponse(&api_v3.GetServicesResponse{
-		Services: services,
+		Services:      services,
+		NextPageToken: nextPageToken,
 	}, w)
 }
 
@@ -233,10 +326,18 @@ func (h *HTTPGateway) getOperations(w http.ResponseWriter, r *http.Request) {
 		ServiceName: query.Get("service"),
 		SpanKind:    query.Get("span_kind"),
 	}
+	pageSize, shouldReturn := h.parsePageSize(query, w)
+	if shouldReturn {
+		return
+	}
 	operations, err := h.QueryService.GetOperations(r.Context(), queryParams)
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
+	operations, nextPageToken, err := paginateOperations(operations, pageSize, query.Get(paramPageToken))
+	if h.tryParamError(w, err, paramPageToken) {
+		return
+	}
 	apiOperations := make([]*api_v3.Operation, len(operations))
 	for i := range operations {
 		apiOperations[i] = &api_v3.Operation{
@@ -244,5 +345,8 @@ func (h *HTTPGateway) getOperations(w http.ResponseWriter, r *http.Request) {
 			SpanKind: operations[i].SpanKind,
 		}
 	}
-	h.marshalResponse(&api_v3.GetOperationsResponse{Operations: apiOperations}, w)
+	h.marshalResponse(&api_v3.GetOperationsResponse{
+		Operations:    apiOperations,
+		NextPageToken: nextPageToken,
+	}, w)
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
@@ -36,8 +37,22 @@ func setupHTTPGatewayNoServer(
 	gw := &testGateway{
 		reader: &spanstoremocks.Reader{},
 	}
+	gw.route(gw.reader, basePath, tenancyOptions)
+	return gw
+}
 
-	q := querysvc.NewQueryService(gw.reader,
+// setupPaginatedHTTPGatewayNoServer creates a testGateway whose span reader supports the pagination.
+func setupPaginatedHTTPGatewayNoServer() *testGateway {
+	gw := &testGateway{
+		reader:     &spanstoremocks.Reader{},
+		pageReader: &spanstoremocks.PaginatedReader{},
+	}
+	gw.route(&paginatedReader{Reader: gw.reader, PaginatedReader: gw.pageReader}, "", tenancy.Options{})
+	return gw
+}
+
+func (gw *testGateway) route(reader spanstore.Reader, basePath string, tenancyOptions tenancy.Options) {
+	q := querysvc.NewQueryService(reader,
 		&dependencyStoreMocks.Reader{},
 		querysvc.QueryServiceOptions{},
 	)
@@ -54,7 +69,6 @@ func setupHTTPGatewayNoServer(
 		gw.router = gw.router.PathPrefix(basePath).Subrouter()
 	}
 	hgw.RegisterRoutes(gw.router)
-	return gw
 }
 
 func setupHTTPGateway(
@@ -116,7 +130,7 @@ func TestHTTPGatewayOTLPError(t *testing.T) {
 		Logger: zap.NewNop(),
 	}
 	const simErr = "simulated error"
-	gw.returnSpansTestable(nil, w,
+	gw.returnSpansTestable(nil, "", "", w,
 		func(_ []*model.Span) (ptrace.Traces, error) {
 			return ptrace.Traces{}, fmt.Errorf(simErr)
 		},
@@ -254,6 +268,169 @@ func TestHTTPGatewayFindTracesErrors(t *testing.T) {
 	})
 }
 
+func TestHTTPGatewayFindTracesPagination(t *testing.T) {
+	q, qp := mockFindQueries()
+	q.Set(paramQueryToken, "page-token")
+	qp.PageToken = "page-token"
+	nextPageToken := spanstore.EncodePageToken(20)
+
+	gw := setupPaginatedHTTPGatewayNoServer()
+	gw.pageReader.
+		On("FindTracesPage", matchContext, qp).
+		Return(&spanstore.TracesPage{
+			Traces:        []*model.Trace{{Spans: []*model.Span{{OperationName: "name"}}}},
+			NextPageToken: nextPageToken,
+		}, nil).Once()
+
+	r, err := http.NewRequest(http.MethodGet, "/api/v3/traces?"+q.Encode(), nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	gw.router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"nextPageToken":"`+nextPageToken+`"`)
+
+	gw.pageReader.
+		On("FindTracesPage", matchContext, mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, spanstore.ErrInvalidPageToken).Once()
+	w = httptest.NewRecorder()
+	gw.router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), paramQueryToken)
+}
+
+func TestHTTPGatewayOTLPFormats(t *testing.T) {
+	traceID := model.NewTraceID(150, 160)
+	trace := &model.Trace{Spans: []*model.Span{{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(180),
+		OperationName: "foobar",
+		Process:       &model.Process{ServiceName: "foo"},
+	}}}
+	testCases := []struct {
+		name        string
+		query       string
+		accept      string
+		contentType string
+		unmarshaler ptrace.Unmarshaler
+	}{
+		{
+			name:        "json",
+			query:       "?format=" + formatOTLPJSON,
+			contentType: contentTypeJSON,
+			unmarshaler: new(ptrace.JSONUnmarshaler),
+		},
+		{
+			name:        "protobuf",
+			query:       "?format=" + formatOTLPProto,
+			contentType: contentTypeProtobuf,
+			unmarshaler: new(ptrace.ProtoUnmarshaler),
+		},
+		{
+			name:        "protobuf accept header",
+			accept:      contentTypeProtobuf,
+			contentType: contentTypeProtobuf,
+			unmarshaler: new(ptrace.ProtoUnmarshaler),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gw := setupHTTPGatewayNoServer(t, "", tenancy.Options{})
+			gw.reader.On("GetTrace", matchContext, matchTraceID).Return(trace, nil).Once()
+
+			r, err := http.NewRequest(http.MethodGet, "/api/v3/traces/123"+tc.query, nil)
+			require.NoError(t, err)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+			gw.router.ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+
+			td, err := tc.unmarshaler.UnmarshalTraces(w.Body.Bytes())
+			require.NoError(t, err)
+			require.Equal(t, 1, td.SpanCount())
+			span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+			assert.Equal(t, "foobar", span.Name())
+		})
+	}
+
+	t.Run("next page token", func(t *testing.T) {
+		q, qp := mockFindQueries()
+		q.Set(paramFormat, formatOTLPJSON)
+		nextPageToken := spanstore.EncodePageToken(20)
+		gw := setupPaginatedHTTPGatewayNoServer()
+		gw.pageReader.
+			On("FindTracesPage", matchContext, qp).
+			Return(&spanstore.TracesPage{Traces: []*model.Trace{trace}, NextPageToken: nextPageToken}, nil).Once()
+
+		r, err := http.NewRequest(http.MethodGet, "/api/v3/traces?"+q.Encode(), nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, nextPageToken, w.Header().Get(headerNextPageToken))
+		assert.Contains(t, w.Body.String(), `"resourceSpans"`)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		gw := setupHTTPGatewayNoServer(t, "", tenancy.Options{})
+		for _, path := range []string{"/api/v3/traces/123?format=xml", "/api/v3/traces?format=xml"} {
+			r, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			gw.router.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+			assert.Contains(t, w.Body.String(), "malformed parameter format", path)
+		}
+	})
+}
+
+func TestHTTPGatewayGetServicesPagination(t *testing.T) {
+	gw := setupHTTPGatewayNoServer(t, "", tenancy.Options{})
+	gw.reader.
+		On("GetServices", matchContext).
+		Return([]string{"foo", "baz", "bar"}, nil)
+
+	r, err := http.NewRequest(http.MethodGet, "/api/v3/services?page_size=2", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	gw.router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"services":["bar","baz"],"nextPageToken":"`+spanstore.EncodePageToken(2)+`"}`,
+		w.Body.String())
+
+	for _, query := range []string{"page_size=NaN", "page_token=invalid"} {
+		r, err := http.NewRequest(http.MethodGet, "/api/v3/services?"+query, nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestHTTPGatewayGetOperationsPagination(t *testing.T) {
+	gw := setupHTTPGatewayNoServer(t, "", tenancy.Options{})
+	qp := spanstore.OperationQueryParameters{ServiceName: "foo"}
+	gw.reader.
+		On("GetOperations", matchContext, qp).
+		Return([]spanstore.Operation{{Name: "op2"}, {Name: "op1"}}, nil)
+
+	r, err := http.NewRequest(http.MethodGet, "/api/v3/operations?service=foo&page_size=1&page_token="+spanstore.EncodePageToken(1), nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	gw.router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"operations":[{"name":"op2"}]}`, w.Body.String())
+
+	for _, query := range []string{"page_size=NaN", "page_token=invalid"} {
+		r, err := http.NewRequest(http.MethodGet, "/api/v3/operations?service=foo&"+query, nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
 func TestHTTPGatewayGetServicesErrors(t *testing.T) {
 	gw := setupHTTPGatewayNoServer(t, "", tenancy.Options{})
 
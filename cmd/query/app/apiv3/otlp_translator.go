@@ -15,10 +15,10 @@
 package apiv3
 
 import (
-	model2otel "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/jaegertracing/jaeger/model"
+	model2otel "github.com/jaegertracing/jaeger/pkg/otlptranslator"
 )
 
 func modelToOTLP(spans []*model.Span) (ptrace.Traces, error) {
@@ -0,0 +1,45 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apiv3
+
+import (
+	"sort"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// paginateServices returns a page of services. Services are sorted by name
+// when paginating, so that pages are stable across requests.
+func paginateServices(services []string, pageSize int32, pageToken string) ([]string, string, error) {
+	if pageSize <= 0 && pageToken == "" {
+		return services, "", nil
+	}
+	offset, err := spanstore.DecodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Strings(services)
+	start, end, nextPageToken := spanstore.Paginate(len(services), offset, int(pageSize))
+	return services[start:end], nextPageToken, nil
+}
+
+// paginateOperations returns a page of operations. Operations are sorted by
+// name and span kind when paginating, so that pages are stable across requests.
+func paginateOperations(operations []spanstore.Operation, pageSize int32, pageToken string) ([]spanstore.Operation, string, error) {
+	if pageSize <= 0 && pageToken == "" {
+		return operations, "", nil
+	}
+	offset, err := spanstore.DecodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].SpanKind < operations[j].SpanKind
+	})
+	start, end, nextPageToken := spanstore.Paginate(len(operations), offset, int(pageSize))
+	return operations[start:end], nextPageToken, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+// Event is the audit record of a request to the query API.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Protocol is "http" or "grpc".
+	Protocol string `json:"protocol"`
+	// Endpoint is the route template of an HTTP request or the full gRPC method.
+	Endpoint string `json:"endpoint"`
+	// Subject is the "sub" claim of the verified JWT of the request, empty if the
+	// request has no verified claims.
+	Subject    string   `json:"subject,omitempty"`
+	Tenant     string   `json:"tenant,omitempty"`
+	RemoteAddr string   `json:"remoteAddr,omitempty"`
+	TraceIDs   []string `json:"traceIDs,omitempty"`
+	Services   []string `json:"services,omitempty"`
+	// Status is the HTTP status code or the gRPC status code of the response.
+	Status string `json:"status"`
+}
+
+// Exporter writes audit events to their destination.
+type Exporter interface {
+	io.Closer
+	Export(event Event)
+}
+
+// Logger records an audit event for each request to the query API.
+type Logger struct {
+	exporter      Exporter
+	tenancyHeader string
+	timeNow       func() time.Time
+}
+
+// NewLogger creates a Logger exporting the events as configured by the options.
+// The tenant of the requests is read from the tenancyHeader, if not empty.
+func NewLogger(options Options, tenancyHeader string, logger *zap.Logger) (*Logger, error) {
+	var exporter Exporter
+	switch options.Exporter {
+	case FileExporter:
+		if options.FilePath == "" {
+			return nil, errors.New("the audit log file path is required with the file exporter")
+		}
+		var err error
+		exporter, err = newFileExporter(options.FilePath, int64(options.FileMaxSizeMB)<<20, options.FileMaxBackups, logger)
+		if err != nil {
+			return nil, err
+		}
+	case OTLPExporter:
+		exporter = newOTLPExporter(options.OTLPEndpoint, logger)
+	default:
+		return nil, fmt.Errorf("unknown audit log exporter %q, expected %q or %q", options.Exporter, FileExporter, OTLPExporter)
+	}
+	return newLogger(exporter, tenancyHeader), nil
+}
+
+func newLogger(exporter Exporter, tenancyHeader string) *Logger {
+	return &Logger{
+		exporter:      exporter,
+		tenancyHeader: tenancyHeader,
+		timeNow:       time.Now,
+	}
+}
+
+// Close flushes the pending events and closes the exporter.
+func (l *Logger) Close() error {
+	return l.exporter.Close()
+}
+
+// subjectFromContext returns the subject of the verified claims of the request, the
+// claims being read by the query server before the request is recorded.
+func subjectFromContext(ctx context.Context) string {
+	subject, _ := querysvc.ClaimsFromContext(ctx)["sub"].(string)
+	return subject
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+var testTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+type fakeExporter struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (e *fakeExporter) Export(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *fakeExporter) Close() error {
+	return nil
+}
+
+func (e *fakeExporter) Events() []Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Event(nil), e.events...)
+}
+
+func newTestLogger(tenancyHeader string) (*Logger, *fakeExporter) {
+	exporter := &fakeExporter{}
+	l := newLogger(exporter, tenancyHeader)
+	l.timeNow = func() time.Time { return testTime }
+	return l, exporter
+}
+
+// testJWT creates an unsigned token with the given payload, which the audit log must not
+// take the subject from.
+func testJWT(payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(payload)) + "."
+}
+
+func TestSubjectFromContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		subject string
+	}{
+		{name: "verified claims", ctx: querysvc.ContextWithClaims(context.Background(), map[string]any{"sub": "alice"}), subject: "alice"},
+		{name: "no subject", ctx: querysvc.ContextWithClaims(context.Background(), map[string]any{"exp": 1})},
+		{name: "no claims", ctx: context.Background()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.subject, subjectFromContext(test.ctx))
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	l, err := NewLogger(Options{
+		Exporter:      FileExporter,
+		FilePath:      filepath.Join(t.TempDir(), "audit.log"),
+		FileMaxSizeMB: 1,
+	}, "x-tenant", zap.NewNop())
+	require.NoError(t, err)
+	assert.IsType(t, &fileExporter{}, l.exporter)
+	assert.Equal(t, "x-tenant", l.tenancyHeader)
+	require.NoError(t, l.Close())
+
+	l, err = NewLogger(Options{Exporter: OTLPExporter, OTLPEndpoint: "http://localhost:4318/v1/logs"}, "", zap.NewNop())
+	require.NoError(t, err)
+	assert.IsType(t, &otlpExporter{}, l.exporter)
+	require.NoError(t, l.Close())
+}
+
+func TestNewLoggerErrors(t *testing.T) {
+	_, err := NewLogger(Options{Exporter: FileExporter}, "", zap.NewNop())
+	require.ErrorContains(t, err, "file path is required")
+
+	_, err = NewLogger(Options{Exporter: FileExporter, FilePath: t.TempDir()}, "", zap.NewNop())
+	require.ErrorContains(t, err, "failed to open the audit log file")
+
+	_, err = NewLogger(Options{Exporter: "kafka"}, "", zap.NewNop())
+	require.ErrorContains(t, err, `unknown audit log exporter "kafka"`)
+}
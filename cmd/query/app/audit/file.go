@@ -0,0 +1,125 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// fileExporter writes the events as JSON lines, rotating the file to path.1, path.2, ...
+// once it reaches maxSize bytes.
+type fileExporter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	logger     *zap.Logger
+
+	mu     sync.Mutex
+	file   *os.File // nil after a failed rotation, until the file is opened again
+	size   int64
+	closed bool
+}
+
+func newFileExporter(path string, maxSize int64, maxBackups int, logger *zap.Logger) (*fileExporter, error) {
+	e := &fileExporter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		logger:     logger,
+	}
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *fileExporter) open() error {
+	file, err := os.OpenFile(e.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open the audit log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open the audit log file: %w", err)
+	}
+	e.file, e.size = file, info.Size()
+	return nil
+}
+
+// Export implements Exporter.
+func (e *fileExporter) Export(event Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		e.logger.Error("Failed to marshal the audit event", zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	if e.file == nil {
+		if err := e.open(); err != nil {
+			e.logger.Error("Failed to write the audit event", zap.Error(err))
+			return
+		}
+	}
+	if e.size > 0 && e.size+int64(len(line)) > e.maxSize {
+		if err := e.rotate(); err != nil {
+			e.logger.Error("Failed to rotate the audit log file", zap.Error(err))
+			if e.file == nil {
+				return
+			}
+		}
+	}
+	n, err := e.file.Write(line)
+	e.size += int64(n)
+	if err != nil {
+		e.logger.Error("Failed to write the audit event", zap.Error(err))
+	}
+}
+
+func (e *fileExporter) rotate() error {
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	e.file = nil
+	if e.maxBackups > 0 {
+		os.Remove(e.backupPath(e.maxBackups))
+		for i := e.maxBackups - 1; i > 0; i-- {
+			os.Rename(e.backupPath(i), e.backupPath(i+1))
+		}
+		if err := os.Rename(e.path, e.backupPath(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(e.path); err != nil {
+		return err
+	}
+	return e.open()
+}
+
+func (e *fileExporter) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", e.path, i)
+}
+
+// Close implements io.Closer.
+func (e *fileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func readEvents(t *testing.T, path string) []Event {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	e, err := newFileExporter(path, 1<<20, 1, zap.NewNop())
+	require.NoError(t, err)
+	event := Event{
+		Time:     testTime,
+		Protocol: "http",
+		Endpoint: "GET /api/traces/{traceID}",
+		Subject:  "alice",
+		TraceIDs: []string{"1f2e"},
+		Status:   "200",
+	}
+	e.Export(event)
+	e.Export(event)
+	require.NoError(t, e.Close())
+	assert.Equal(t, []Event{event, event}, readEvents(t, path))
+
+	// the events exported after Close are dropped
+	e.Export(event)
+	assert.Len(t, readEvents(t, path), 2)
+
+	// the file is appended to when reopened
+	e, err = newFileExporter(path, 1<<20, 1, zap.NewNop())
+	require.NoError(t, err)
+	e.Export(event)
+	require.NoError(t, e.Close())
+	assert.Len(t, readEvents(t, path), 3)
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	event := Event{Time: testTime, Protocol: "http", Endpoint: "GET /api/services", Status: "200"}
+	line, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	// each file holds two events
+	e, err := newFileExporter(path, int64(2*(len(line)+1)), 2, zap.NewNop())
+	require.NoError(t, err)
+	for i := 0; i < 7; i++ {
+		e.Export(event)
+	}
+	require.NoError(t, e.Close())
+
+	assert.Len(t, readEvents(t, path), 1)
+	assert.Len(t, readEvents(t, path+".1"), 2)
+	assert.Len(t, readEvents(t, path+".2"), 2)
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestFileExporterRotationWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	e, err := newFileExporter(path, 1, 0, zap.NewNop())
+	require.NoError(t, err)
+	e.Export(Event{Endpoint: "GET /api/services"})
+	e.Export(Event{Endpoint: "GET /api/operations"})
+	require.NoError(t, e.Close())
+
+	events := readEvents(t, path)
+	require.Len(t, events, 1)
+	assert.Equal(t, "GET /api/operations", events[0].Endpoint)
+	assert.NoFileExists(t, path+".1")
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+)
+
+// NewUnaryInterceptor creates a gRPC interceptor recording the unary calls.
+func (l *Logger) NewUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		event := l.newGRPCEvent(ctx, info.FullMethod)
+		event.TraceIDs, event.Services = requestSubjects(req)
+		resp, err := handler(ctx, req)
+		event.Status = status.Code(err).String()
+		l.exporter.Export(event)
+		return resp, err
+	}
+}
+
+// NewStreamInterceptor creates a gRPC interceptor recording the streaming calls,
+// with the trace IDs and services of the request received from the client.
+func (l *Logger) NewStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		stream := &recordingServerStream{
+			ServerStream: ss,
+			event:        l.newGRPCEvent(ss.Context(), info.FullMethod),
+		}
+		err := handler(srv, stream)
+		stream.mu.Lock()
+		event := stream.event
+		stream.mu.Unlock()
+		event.Status = status.Code(err).String()
+		l.exporter.Export(event)
+		return err
+	}
+}
+
+type recordingServerStream struct {
+	grpc.ServerStream
+	mu    sync.Mutex
+	event Event
+}
+
+func (s *recordingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	traceIDs, services := requestSubjects(m)
+	s.mu.Lock()
+	s.event.TraceIDs = append(s.event.TraceIDs, traceIDs...)
+	s.event.Services = append(s.event.Services, services...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (l *Logger) newGRPCEvent(ctx context.Context, method string) Event {
+	event := Event{
+		Time:     l.timeNow(),
+		Protocol: "grpc",
+		Endpoint: method,
+		Subject:  subjectFromContext(ctx),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if l.tenancyHeader != "" {
+			if values := md.Get(l.tenancyHeader); len(values) > 0 {
+				event.Tenant = values[0]
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		event.RemoteAddr = p.Addr.String()
+	}
+	return event
+}
+
+// requestSubjects returns the trace IDs and the services a request of the query APIs is about.
+func requestSubjects(req any) (traceIDs []string, services []string) {
+	switch r := req.(type) {
+	case *api_v2.GetTraceRequest:
+		traceIDs = []string{r.TraceID.String()}
+	case *api_v2.ArchiveTraceRequest:
+		traceIDs = []string{r.TraceID.String()}
+	case *api_v2.FindTracesRequest:
+		services = nonEmpty(r.GetQuery().GetServiceName())
+	case *api_v2.GetOperationsRequest:
+		services = nonEmpty(r.GetService())
+	case *api_v3.GetTraceRequest:
+		traceIDs = nonEmpty(r.GetTraceId())
+	case *api_v3.FindTracesRequest:
+		services = nonEmpty(r.GetQuery().GetServiceName())
+	case *api_v3.GetOperationsRequest:
+		services = nonEmpty(r.GetService())
+	case *metrics.GetLatenciesRequest:
+		services = r.GetBaseRequest().GetServiceNames()
+	case *metrics.GetCallRatesRequest:
+		services = r.GetBaseRequest().GetServiceNames()
+	case *metrics.GetErrorRatesRequest:
+		services = r.GetBaseRequest().GetServiceNames()
+	}
+	return traceIDs, services
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
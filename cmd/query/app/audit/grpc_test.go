@@ -0,0 +1,126 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+)
+
+// newIncomingContext creates the context of a call whose verified claims are alice's, the
+// subject of its unverified token being ignored.
+func newIncomingContext() context.Context {
+	ctx := querysvc.ContextWithClaims(context.Background(), map[string]any{"sub": "alice"})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+		"authorization", "Bearer "+testJWT(`{"sub":"mallory"}`),
+		"x-tenant", "acme",
+	))
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	l, exporter := newTestLogger("x-tenant")
+	interceptor := l.NewUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/jaeger.api_v2.QueryService/GetOperations"}
+	_, err := interceptor(newIncomingContext(), &api_v2.GetOperationsRequest{Service: "frontend"}, info,
+		func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.Unavailable, "storage is down")
+		})
+	require.Error(t, err)
+
+	require.Len(t, exporter.Events(), 1)
+	assert.Equal(t, Event{
+		Time:       testTime,
+		Protocol:   "grpc",
+		Endpoint:   "/jaeger.api_v2.QueryService/GetOperations",
+		Subject:    "alice",
+		Tenant:     "acme",
+		RemoteAddr: "10.0.0.1:1234",
+		Services:   []string{"frontend"},
+		Status:     "Unavailable",
+	}, exporter.Events()[0])
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req any
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	*(m.(*api_v2.GetTraceRequest)) = *(s.req.(*api_v2.GetTraceRequest))
+	return nil
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	l, exporter := newTestLogger("")
+	interceptor := l.NewStreamInterceptor()
+	stream := &fakeServerStream{
+		ctx: newIncomingContext(),
+		req: &api_v2.GetTraceRequest{TraceID: model.NewTraceID(0, 0x1f2e)},
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/jaeger.api_v2.QueryService/GetTrace"}
+	err := interceptor(nil, stream, info, func(_ any, ss grpc.ServerStream) error {
+		return ss.RecvMsg(&api_v2.GetTraceRequest{})
+	})
+	require.NoError(t, err)
+
+	require.Len(t, exporter.Events(), 1)
+	assert.Equal(t, Event{
+		Time:       testTime,
+		Protocol:   "grpc",
+		Endpoint:   "/jaeger.api_v2.QueryService/GetTrace",
+		Subject:    "alice",
+		RemoteAddr: "10.0.0.1:1234",
+		TraceIDs:   []string{"0000000000001f2e"},
+		Status:     "OK",
+	}, exporter.Events()[0])
+}
+
+func TestRequestSubjects(t *testing.T) {
+	traceID := model.NewTraceID(0, 0x1f2e)
+	baseRequest := &metrics.MetricsQueryBaseRequest{ServiceNames: []string{"frontend", "driver"}}
+	tests := []struct {
+		req      any
+		traceIDs []string
+		services []string
+	}{
+		{req: &api_v2.GetTraceRequest{TraceID: traceID}, traceIDs: []string{"0000000000001f2e"}},
+		{req: &api_v2.ArchiveTraceRequest{TraceID: traceID}, traceIDs: []string{"0000000000001f2e"}},
+		{req: &api_v2.FindTracesRequest{Query: &api_v2.TraceQueryParameters{ServiceName: "frontend"}}, services: []string{"frontend"}},
+		{req: &api_v2.FindTracesRequest{}},
+		{req: &api_v2.GetOperationsRequest{Service: "frontend"}, services: []string{"frontend"}},
+		{req: &api_v2.GetServicesRequest{}},
+		{req: &api_v3.GetTraceRequest{TraceId: "1f2e"}, traceIDs: []string{"1f2e"}},
+		{req: &api_v3.FindTracesRequest{Query: &api_v3.TraceQueryParameters{ServiceName: "frontend"}}, services: []string{"frontend"}},
+		{req: &api_v3.GetOperationsRequest{Service: "frontend"}, services: []string{"frontend"}},
+		{req: &metrics.GetLatenciesRequest{BaseRequest: baseRequest}, services: []string{"frontend", "driver"}},
+		{req: &metrics.GetCallRatesRequest{BaseRequest: baseRequest}, services: []string{"frontend", "driver"}},
+		{req: &metrics.GetErrorRatesRequest{BaseRequest: baseRequest}, services: []string{"frontend", "driver"}},
+	}
+	for _, test := range tests {
+		traceIDs, services := requestSubjects(test.req)
+		assert.Equal(t, test.traceIDs, traceIDs, "%T", test.req)
+		assert.Equal(t, test.services, services, "%T", test.req)
+	}
+}
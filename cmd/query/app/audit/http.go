@@ -0,0 +1,97 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const apiRouteMarker = "/api/"
+
+// the route variables and the query parameters of the HTTP APIs naming trace IDs and services
+var (
+	traceIDVars   = []string{"traceID", "otherTraceID", "trace_id"}
+	traceIDParams = []string{"traceID"}
+	serviceVars   = []string{"service"}
+	serviceParams = []string{"service", "query.service_name"}
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets the handlers take over the connection, e.g. to upgrade it to WebSocket.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Middleware records the requests to the API routes of a mux.Router, skipping the
+// routes of the static assets of the UI.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.Contains(template, apiRouteMarker) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		event := Event{
+			Time:       l.timeNow(),
+			Protocol:   "http",
+			Endpoint:   r.Method + " " + template,
+			Subject:    subjectFromContext(r.Context()),
+			RemoteAddr: r.RemoteAddr,
+		}
+		if l.tenancyHeader != "" {
+			event.Tenant = r.Header.Get(l.tenancyHeader)
+		}
+		vars, query := mux.Vars(r), r.URL.Query()
+		event.TraceIDs = valuesOf(vars, query, traceIDVars, traceIDParams)
+		event.Services = valuesOf(vars, query, serviceVars, serviceParams)
+
+		next.ServeHTTP(recorder, r)
+		event.Status = strconv.Itoa(recorder.status)
+		l.exporter.Export(event)
+	})
+}
+
+func valuesOf(vars map[string]string, query map[string][]string, varNames []string, paramNames []string) []string {
+	var values []string
+	for _, name := range varNames {
+		if value := vars[name]; value != "" {
+			// the router matches the encoded path
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				value = unescaped
+			}
+			values = append(values, value)
+		}
+	}
+	for _, name := range paramNames {
+		values = append(values, query[name]...)
+	}
+	return values
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+func newTestRouter(l *Logger) *mux.Router {
+	ok := func(http.ResponseWriter, *http.Request) {}
+	r := mux.NewRouter().UseEncodedPath()
+	r.Use(l.Middleware)
+	r.HandleFunc("/api/traces/{traceID}", ok)
+	r.HandleFunc("/api/traces", ok)
+	r.HandleFunc("/api/services/{service}/operations", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	r.HandleFunc("/api/v3/traces/{trace_id}", ok)
+	r.PathPrefix("/static/").HandlerFunc(ok)
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		target   string
+		endpoint string
+		traceIDs []string
+		services []string
+		status   string
+	}{
+		{
+			target:   "/api/traces/1f2e",
+			endpoint: "GET /api/traces/{traceID}",
+			traceIDs: []string{"1f2e"},
+			status:   "200",
+		},
+		{
+			target:   "/api/traces?service=frontend&traceID=1&traceID=2",
+			endpoint: "GET /api/traces",
+			traceIDs: []string{"1", "2"},
+			services: []string{"frontend"},
+			status:   "200",
+		},
+		{
+			target:   "/api/services/my%2Fservice/operations",
+			endpoint: "GET /api/services/{service}/operations",
+			services: []string{"my/service"},
+			status:   "500",
+		},
+		{
+			target:   "/api/v3/traces/1f2e",
+			endpoint: "GET /api/v3/traces/{trace_id}",
+			traceIDs: []string{"1f2e"},
+			status:   "200",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			l, exporter := newTestLogger("x-tenant")
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			// the subject is taken from the verified claims, not from the token
+			req = req.WithContext(querysvc.ContextWithClaims(req.Context(), map[string]any{"sub": "alice"}))
+			req.Header.Set("Authorization", "Bearer "+testJWT(`{"sub":"mallory"}`))
+			req.Header.Set("x-tenant", "acme")
+			newTestRouter(l).ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Len(t, exporter.Events(), 1)
+			assert.Equal(t, Event{
+				Time:       testTime,
+				Protocol:   "http",
+				Endpoint:   test.endpoint,
+				Subject:    "alice",
+				Tenant:     "acme",
+				RemoteAddr: req.RemoteAddr,
+				TraceIDs:   test.traceIDs,
+				Services:   test.services,
+				Status:     test.status,
+			}, exporter.Events()[0])
+		})
+	}
+}
+
+func TestMiddlewareSkipsStaticAssets(t *testing.T) {
+	l, exporter := newTestLogger("")
+	router := newTestRouter(l)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/static/index.js", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	assert.Empty(t, exporter.Events())
+}
+
+func TestMiddlewareWithoutTenancy(t *testing.T) {
+	l, exporter := newTestLogger("")
+	req := httptest.NewRequest(http.MethodGet, "/api/traces", nil)
+	req.Header.Set("x-tenant", "acme")
+	req.Header.Set("Authorization", "Bearer "+testJWT(`{"sub":"mallory"}`))
+	newTestRouter(l).ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, exporter.Events(), 1)
+	assert.Empty(t, exporter.Events()[0].Tenant)
+	assert.Empty(t, exporter.Events()[0].Subject)
+}
+
+func TestMiddlewareHijack(t *testing.T) {
+	l, exporter := newTestLogger("")
+	r := mux.NewRouter()
+	r.Use(l.Middleware)
+	r.HandleFunc("/api/live-tail", func(w http.ResponseWriter, _ *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if assert.NoError(t, err) {
+			conn.Close()
+		}
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/live-tail")
+	if err == nil {
+		resp.Body.Close()
+	}
+	require.Eventually(t, func() bool { return len(exporter.Events()) == 1 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, "101", exporter.Events()[0].Status)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// FileExporter writes the audit events as JSON lines to a rotated file.
+	FileExporter = "file"
+	// OTLPExporter sends the audit events as OTLP logs over HTTP.
+	OTLPExporter = "otlp"
+
+	flagPrefix         = "query.audit"
+	flagEnabled        = flagPrefix + ".enabled"
+	flagExporter       = flagPrefix + ".exporter"
+	flagFilePath       = flagPrefix + ".file.path"
+	flagFileMaxSize    = flagPrefix + ".file.max-size-mb"
+	flagFileMaxBackups = flagPrefix + ".file.max-backups"
+	flagOTLPEndpoint   = flagPrefix + ".otlp.endpoint"
+
+	defaultFileMaxSize    = 100
+	defaultFileMaxBackups = 10
+	defaultOTLPEndpoint   = "http://localhost:4318/v1/logs"
+)
+
+// Options holds configuration for the audit log of the query API.
+type Options struct {
+	// Enabled records an audit event for each request to the query API.
+	Enabled bool
+	// Exporter is the destination of the audit events, FileExporter or OTLPExporter.
+	Exporter string
+	// FilePath is the file the FileExporter writes to.
+	FilePath string
+	// FileMaxSizeMB is the size in megabytes at which the FileExporter rotates the file.
+	FileMaxSizeMB int
+	// FileMaxBackups is the number of rotated files kept by the FileExporter.
+	FileMaxBackups int
+	// OTLPEndpoint is the URL of the OTLP/HTTP logs endpoint the OTLPExporter sends to.
+	OTLPEndpoint string
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Record who queried which traces and services in an audit log")
+	flagSet.String(flagExporter, FileExporter, "The destination of the audit log, either 'file' for JSON lines or 'otlp' for OTLP logs over HTTP")
+	flagSet.String(flagFilePath, "", "The path of the audit log file")
+	flagSet.Int(flagFileMaxSize, defaultFileMaxSize, "The size in megabytes at which the audit log file is rotated")
+	flagSet.Int(flagFileMaxBackups, defaultFileMaxBackups, "The number of rotated audit log files to keep")
+	flagSet.String(flagOTLPEndpoint, defaultOTLPEndpoint, "The URL of the OTLP/HTTP logs endpoint receiving the audit log")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.Exporter = v.GetString(flagExporter)
+	o.FilePath = v.GetString(flagFilePath)
+	o.FileMaxSizeMB = v.GetInt(flagFileMaxSize)
+	o.FileMaxBackups = v.GetInt(flagFileMaxBackups)
+	o.OTLPEndpoint = v.GetString(flagOTLPEndpoint)
+	return o
+}
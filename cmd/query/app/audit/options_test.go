@@ -0,0 +1,46 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.audit.enabled=true",
+		"--query.audit.exporter=otlp",
+		"--query.audit.file.path=/var/log/jaeger/audit.log",
+		"--query.audit.file.max-size-mb=10",
+		"--query.audit.file.max-backups=3",
+		"--query.audit.otlp.endpoint=http://collector:4318/v1/logs",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Enabled:        true,
+		Exporter:       OTLPExporter,
+		FilePath:       "/var/log/jaeger/audit.log",
+		FileMaxSizeMB:  10,
+		FileMaxBackups: 3,
+		OTLPEndpoint:   "http://collector:4318/v1/logs",
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Equal(t, FileExporter, opts.Exporter)
+	assert.Empty(t, opts.FilePath)
+	assert.Equal(t, defaultFileMaxSize, opts.FileMaxSizeMB)
+	assert.Equal(t, defaultFileMaxBackups, opts.FileMaxBackups)
+	assert.Equal(t, defaultOTLPEndpoint, opts.OTLPEndpoint)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.uber.org/zap"
+)
+
+const (
+	otlpQueueSize    = 1000
+	otlpMaxBatchSize = 100
+	otlpTimeout      = 10 * time.Second
+	otlpScopeName    = "github.com/jaegertracing/jaeger/cmd/query/app/audit"
+	otlpServiceName  = "jaeger-query"
+)
+
+// otlpExporter sends the events in batches to an OTLP/HTTP logs endpoint, from a
+// bounded queue so that a slow endpoint does not slow down the queries.
+type otlpExporter struct {
+	endpoint string
+	client   *http.Client
+	logger   *zap.Logger
+
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+func newOTLPExporter(endpoint string, logger *zap.Logger) *otlpExporter {
+	e := &otlpExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: otlpTimeout},
+		logger:   logger,
+		events:   make(chan Event, otlpQueueSize),
+		done:     make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// Export implements Exporter.
+func (e *otlpExporter) Export(event Event) {
+	select {
+	case e.events <- event:
+	default:
+		e.logger.Warn("The audit log queue is full, dropping the event", zap.String("endpoint", event.Endpoint))
+	}
+}
+
+func (e *otlpExporter) run() {
+	defer e.wg.Done()
+	for {
+		select {
+		case event := <-e.events:
+			e.send(e.batch(event))
+		case <-e.done:
+			// flush the events exported before Close
+			for len(e.events) > 0 {
+				e.send(e.batch(<-e.events))
+			}
+			return
+		}
+	}
+}
+
+// batch returns the event with the ones already queued after it.
+func (e *otlpExporter) batch(event Event) []Event {
+	events := []Event{event}
+	for len(events) < otlpMaxBatchSize {
+		select {
+		case event := <-e.events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+	return events
+}
+
+func (e *otlpExporter) send(events []Event) {
+	body, err := plogotlp.NewExportRequestFromLogs(toLogs(events)).MarshalProto()
+	if err != nil {
+		e.logger.Error("Failed to marshal the audit events", zap.Error(err))
+		return
+	}
+	if err := e.post(body); err != nil {
+		e.logger.Error("Failed to send the audit events", zap.Int("events", len(events)), zap.Error(err))
+	}
+}
+
+func (e *otlpExporter) post(body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return nil
+}
+
+func toLogs(events []Event) plog.Logs {
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.Resource().Attributes().PutStr("service.name", otlpServiceName)
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	scopeLogs.Scope().SetName(otlpScopeName)
+	for _, event := range events {
+		record := scopeLogs.LogRecords().AppendEmpty()
+		record.SetTimestamp(pcommon.NewTimestampFromTime(event.Time))
+		record.Body().SetStr(event.Endpoint)
+		attrs := record.Attributes()
+		attrs.PutStr("audit.protocol", event.Protocol)
+		attrs.PutStr("audit.endpoint", event.Endpoint)
+		attrs.PutStr("audit.status", event.Status)
+		putStrIfNotEmpty(attrs, "audit.subject", event.Subject)
+		putStrIfNotEmpty(attrs, "audit.tenant", event.Tenant)
+		putStrIfNotEmpty(attrs, "audit.remote_addr", event.RemoteAddr)
+		putStrSliceIfNotEmpty(attrs, "audit.trace_ids", event.TraceIDs)
+		putStrSliceIfNotEmpty(attrs, "audit.services", event.Services)
+	}
+	return logs
+}
+
+func putStrIfNotEmpty(attrs pcommon.Map, key string, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
+
+func putStrSliceIfNotEmpty(attrs pcommon.Map, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	slice := attrs.PutEmptySlice(key)
+	for _, value := range values {
+		slice.AppendEmpty().SetStr(value)
+	}
+}
+
+// Close implements io.Closer.
+func (e *otlpExporter) Close() error {
+	e.once.Do(func() {
+		close(e.done)
+	})
+	e.wg.Wait()
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeLogsReceiver struct {
+	*httptest.Server
+	mu      sync.Mutex
+	records []plog.LogRecord
+	status  int
+}
+
+func newFakeLogsReceiver(t *testing.T, status int) *fakeLogsReceiver {
+	r := &fakeLogsReceiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		exportRequest := plogotlp.NewExportRequest()
+		assert.NoError(t, exportRequest.UnmarshalProto(body))
+		resourceLogs := exportRequest.Logs().ResourceLogs()
+		r.mu.Lock()
+		for i := 0; i < resourceLogs.Len(); i++ {
+			serviceName, _ := resourceLogs.At(i).Resource().Attributes().Get("service.name")
+			assert.Equal(t, "jaeger-query", serviceName.Str())
+			scopeLogs := resourceLogs.At(i).ScopeLogs()
+			for j := 0; j < scopeLogs.Len(); j++ {
+				records := scopeLogs.At(j).LogRecords()
+				for k := 0; k < records.Len(); k++ {
+					r.records = append(r.records, records.At(k))
+				}
+			}
+		}
+		r.mu.Unlock()
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeLogsReceiver) Records() []plog.LogRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]plog.LogRecord(nil), r.records...)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	receiver := newFakeLogsReceiver(t, http.StatusOK)
+	e := newOTLPExporter(receiver.URL+"/v1/logs", zap.NewNop())
+	e.Export(Event{
+		Time:       testTime,
+		Protocol:   "grpc",
+		Endpoint:   "/jaeger.api_v2.QueryService/GetTrace",
+		Subject:    "alice",
+		Tenant:     "acme",
+		RemoteAddr: "10.0.0.1:1234",
+		TraceIDs:   []string{"1f2e"},
+		Status:     "OK",
+	})
+	e.Export(Event{Time: testTime, Protocol: "http", Endpoint: "GET /api/services", Status: "200"})
+	// Close flushes the queued events
+	require.NoError(t, e.Close())
+
+	records := receiver.Records()
+	require.Len(t, records, 2)
+	record := records[0]
+	assert.Equal(t, testTime, record.Timestamp().AsTime())
+	assert.Equal(t, "/jaeger.api_v2.QueryService/GetTrace", record.Body().Str())
+	assert.Equal(t, map[string]any{
+		"audit.protocol":    "grpc",
+		"audit.endpoint":    "/jaeger.api_v2.QueryService/GetTrace",
+		"audit.status":      "OK",
+		"audit.subject":     "alice",
+		"audit.tenant":      "acme",
+		"audit.remote_addr": "10.0.0.1:1234",
+		"audit.trace_ids":   []any{"1f2e"},
+	}, record.Attributes().AsRaw())
+	assert.Equal(t, map[string]any{
+		"audit.protocol": "http",
+		"audit.endpoint": "GET /api/services",
+		"audit.status":   "200",
+	}, records[1].Attributes().AsRaw())
+}
+
+func TestOTLPExporterErrors(t *testing.T) {
+	receiver := newFakeLogsReceiver(t, http.StatusServiceUnavailable)
+	zapCore, logs := observer.New(zap.ErrorLevel)
+	e := newOTLPExporter(receiver.URL+"/v1/logs", zap.New(zapCore))
+	e.Export(Event{Endpoint: "GET /api/services"})
+	require.NoError(t, e.Close())
+	require.Equal(t, 1, logs.FilterMessage("Failed to send the audit events").Len())
+	assert.Contains(t, logs.All()[0].ContextMap()["error"], "503")
+}
+
+func TestOTLPExporterQueueFull(t *testing.T) {
+	zapCore, logs := observer.New(zap.WarnLevel)
+	e := &otlpExporter{
+		logger: zap.New(zapCore),
+		events: make(chan Event, 1),
+	}
+	e.Export(Event{Endpoint: "GET /api/services"})
+	e.Export(Event{Endpoint: "GET /api/services"})
+	assert.Equal(t, 1, logs.FilterMessage("The audit log queue is full, dropping the event").Len())
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
+)
+
+const forwardedAccessTokenHeader = "X-Forwarded-Access-Token"
+
+// claimsReader reads the claims of the callers from their JWT bearer tokens, used by the query service
+// to authorize the services and to scope the saved searches. The requests without a verified JWT have
+// no claims and are only allowed the services of the rules not requiring any.
+type claimsReader struct {
+	// verifier checks the signatures of the tokens, no token being verified if nil.
+	verifier *bearertoken.ClaimsVerifier
+	// trustForwardedToken accepts the X-Forwarded-Access-Token header of the requests of the
+	// trusted proxies as is, the proxies having verified the token.
+	trustForwardedToken bool
+}
+
+func newClaimsReader(opts *QueryOptions) (*claimsReader, error) {
+	reader := &claimsReader{trustForwardedToken: opts.TrustForwardedToken}
+	if opts.JWT.Enabled() {
+		verifier, err := bearertoken.NewClaimsVerifier(opts.JWT, nil)
+		if err != nil {
+			return nil, err
+		}
+		reader.verifier = verifier
+	}
+	return reader, nil
+}
+
+// contextWithClaims attaches to the context the claims of the JWT if its signature is verified.
+func (c *claimsReader) contextWithClaims(ctx context.Context, authorization string) context.Context {
+	if c.verifier == nil || authorization == "" {
+		return ctx
+	}
+	claims, err := c.verifier.VerifyClaims(authorization)
+	if err != nil {
+		return ctx
+	}
+	return querysvc.ContextWithClaims(ctx, claims)
+}
+
+func (c *claimsReader) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if forwarded := r.Header.Get(forwardedAccessTokenHeader); forwarded != "" && c.trustForwardedToken && fromTrustedProxy(ctx) {
+			if claims, err := bearertoken.ParseClaims(forwarded); err == nil {
+				h.ServeHTTP(w, r.WithContext(querysvc.ContextWithClaims(ctx, claims)))
+				return
+			}
+		}
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" {
+			authorization = r.Header.Get(forwardedAccessTokenHeader)
+		}
+		h.ServeHTTP(w, r.WithContext(c.contextWithClaims(ctx, authorization)))
+	})
+}
+
+func (c *claimsReader) fromMetadata(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		return c.contextWithClaims(ctx, values[0])
+	}
+	return ctx
+}
+
+type claimsServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *claimsServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (c *claimsReader) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(c.fromMetadata(ctx), req)
+	}
+}
+
+func (c *claimsReader) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &claimsServerStream{
+			ServerStream: ss,
+			ctx:          c.fromMetadata(ss.Context()),
+		})
+	}
+}
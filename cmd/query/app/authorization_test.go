@@ -0,0 +1,288 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var testSigningKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+// testToken returns a JWT of the claims signed by the testSigningKey.
+func testToken(claims map[string]any) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims(claims)).SignedString(testSigningKey)
+	return token
+}
+
+// unsignedToken returns a JWT of the claims without signature, as a client could forge.
+func unsignedToken(claims map[string]any) string {
+	payload, _ := json.Marshal(claims)
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+// testJWTOptions returns the options verifying the tokens of testToken.
+func testJWTOptions(t *testing.T) bearertoken.VerifierOptions {
+	der, err := x509.MarshalPKIXPublicKey(&testSigningKey.PublicKey)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return bearertoken.VerifierOptions{KeyFile: keyFile}
+}
+
+func TestAuthorizationRulesFlag(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`{"rules": [{"claim": "sub", "value": "alice", "services": ["*"]}]}`), 0o600))
+
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.authorization.rules-file=" + rulesFile}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, []querysvc.AuthorizationRule{{Claim: "sub", Value: "alice", Services: []string{"*"}}}, qOpts.AuthorizationRules)
+	assert.NotNil(t, qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), zap.NewNop()).Authorizer)
+
+	require.NoError(t, command.ParseFlags([]string{"--query.authorization.rules-file=" + filepath.Join(t.TempDir(), "missing.json")}))
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "failed to read the authorization rules")
+}
+
+func TestJWTFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--query.authorization.jwt.jwks-url=https://idp/jwks",
+		"--query.authorization.jwt.issuer=https://idp",
+		"--query.authorization.jwt.audience=jaeger",
+		"--query.authorization.trust-forwarded-token=true",
+		"--query.http-server.trusted-proxies=10.0.0.0/8",
+	}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, bearertoken.VerifierOptions{JWKSURL: "https://idp/jwks", Issuer: "https://idp", Audience: "jaeger"}, qOpts.JWT)
+	assert.True(t, qOpts.TrustForwardedToken)
+
+	for expectedErr, flags := range map[string][]string{
+		"--query.authorization.jwt.jwks-url and --query.authorization.jwt.key-file are mutually exclusive": {
+			"--query.authorization.jwt.jwks-url=https://idp/jwks", "--query.authorization.jwt.key-file=key.pem",
+		},
+		"--query.authorization.trust-forwarded-token requires the trusted proxies, see --query.http-server.trusted-proxies": {
+			"--query.authorization.trust-forwarded-token=true",
+		},
+	} {
+		v, command := config.Viperize(AddFlags)
+		require.NoError(t, command.ParseFlags(flags))
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.EqualError(t, err, expectedErr)
+	}
+}
+
+func TestClaimsReader(t *testing.T) {
+	// the claims of alice are the only ones allowed by the authorizer
+	authorizer := querysvc.NewServiceAuthorizer([]querysvc.AuthorizationRule{{Claim: "sub", Value: "alice", Services: []string{"*"}}})
+	var allowed bool
+	next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		allowed = authorizer.IsAllowed(r.Context(), "frontend")
+	})
+	verifying, err := newClaimsReader(&QueryOptions{JWT: testJWTOptions(t)})
+	require.NoError(t, err)
+	trusting, err := newClaimsReader(&QueryOptions{JWT: testJWTOptions(t), TrustForwardedToken: true})
+	require.NoError(t, err)
+	alice := map[string]any{"sub": "alice"}
+	tests := []struct {
+		name         string
+		reader       *claimsReader
+		headers      map[string]string
+		trustedProxy bool
+		allowed      bool
+	}{
+		{name: "signed", reader: verifying, headers: map[string]string{"Authorization": "Bearer " + testToken(alice)}, allowed: true},
+		{name: "signed forwarded", reader: verifying, headers: map[string]string{forwardedAccessTokenHeader: testToken(alice)}, allowed: true},
+		{name: "unsigned", reader: verifying, headers: map[string]string{"Authorization": "Bearer " + unsignedToken(alice)}},
+		{name: "no verifier", reader: &claimsReader{}, headers: map[string]string{"Authorization": "Bearer " + testToken(alice)}},
+		{
+			name:    "unsigned forwarded not trusted",
+			reader:  verifying,
+			headers: map[string]string{forwardedAccessTokenHeader: unsignedToken(alice)},
+		},
+		{
+			name:         "unsigned forwarded by a trusted proxy",
+			reader:       trusting,
+			headers:      map[string]string{forwardedAccessTokenHeader: unsignedToken(alice)},
+			trustedProxy: true,
+			allowed:      true,
+		},
+		{
+			name:    "unsigned forwarded by another client",
+			reader:  trusting,
+			headers: map[string]string{forwardedAccessTokenHeader: unsignedToken(alice)},
+		},
+		{
+			name:         "the forwarded token of a trusted proxy takes precedence",
+			reader:       trusting,
+			headers:      map[string]string{"Authorization": "Bearer " + testToken(map[string]any{"sub": "bob"}), forwardedAccessTokenHeader: unsignedToken(alice)},
+			trustedProxy: true,
+			allowed:      true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allowed = false
+			req := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+			for header, value := range test.headers {
+				req.Header.Set(header, value)
+			}
+			if test.trustedProxy {
+				req = req.WithContext(context.WithValue(req.Context(), trustedProxyContextKey{}, true))
+			}
+			test.reader.handler(next).ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, test.allowed, allowed)
+		})
+	}
+}
+
+func TestServerAuthorization(t *testing.T) {
+	rules := []querysvc.AuthorizationRule{{Claim: "sub", Value: "alice", Services: []string{"payment-*"}}}
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return([]string{"inventory", "payment-api"}, nil)
+	querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{
+		Authorizer: querysvc.NewServiceAuthorizer(rules),
+	})
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc, nil,
+		&QueryOptions{
+			GRPCHostPort:       "127.0.0.1:0",
+			HTTPHostPort:       "127.0.0.1:0",
+			AuthorizationRules: rules,
+			JWT:                testJWTOptions(t),
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+	token := testToken(map[string]any{"sub": "alice"})
+	forged := unsignedToken(map[string]any{"sub": "alice"})
+
+	httpGet := func(path, token string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", server.httpConn.Addr().String(), path), nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body structuredResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, fmt.Sprint(body.Data)
+	}
+	code, services := httpGet("/api/services", token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "[payment-api]", services)
+	code, _ = httpGet("/api/services/inventory/operations", token)
+	assert.Equal(t, http.StatusForbidden, code)
+	// the claims of the tokens not signed by the issuer are ignored
+	code, services = httpGet("/api/services", forged)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "[]", services)
+
+	conn, err := grpc.NewClient(server.grpcConn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := api_v2.NewQueryServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	resp, err := client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payment-api"}, resp.Services)
+	_, err = client.GetOperations(ctx, &api_v2.GetOperationsRequest{Service: "inventory"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+forged)
+	resp, err = client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Services)
+	_, err = client.GetOperations(ctx, &api_v2.GetOperationsRequest{Service: "payment-api"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServerSavedSearchesOwner(t *testing.T) {
+	querySvc := querysvc.NewQueryService(&spanstoremocks.Reader{}, &depsmocks.Reader{}, querysvc.QueryServiceOptions{
+		SavedSearchStore: memory.NewSavedSearchStore(),
+	})
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			JWT:          testJWTOptions(t),
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	httpDo := func(method, path, user, body string) (int, savedSearch) {
+		req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", server.httpConn.Addr().String(), path), strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+testToken(map[string]any{"sub": user}))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var response struct {
+			Data savedSearch `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response.Data
+	}
+	code, search := httpDo(http.MethodPut, "/api/saved-searches/slow", "alice", `{"service": "frontend"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "alice", search.Owner)
+	code, _ = httpDo(http.MethodGet, "/api/saved-searches/slow", "alice", "")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = httpDo(http.MethodGet, "/api/saved-searches/slow", "bob", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = httpDo(http.MethodGet, "/api/saved-searches/slow?owner=alice", "bob", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	httpDo(http.MethodPut, "/api/saved-searches/slow", "alice", `{"service": "frontend", "shared": true}`)
+	code, search = httpDo(http.MethodGet, "/api/saved-searches/slow?owner=alice", "bob", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "frontend", search.Service)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressionEncoders are the encoders of the content encodings supported by the responses.
+var compressionEncoders = map[string]func(io.Writer) io.WriteCloser{
+	"br": func(w io.Writer) io.WriteCloser {
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	},
+	"gzip": func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	},
+	"deflate": func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression) // never fails with a valid level
+		return fw
+	},
+}
+
+// validateCompressionEncodings validates the content encodings of the responses.
+func validateCompressionEncodings(encodings []string) error {
+	for _, encoding := range encodings {
+		if _, ok := compressionEncoders[encoding]; !ok {
+			return fmt.Errorf("unsupported compression %q, must be br, gzip or deflate", encoding)
+		}
+	}
+	return nil
+}
+
+// compressionHandler compresses the responses with the first of the encodings, in the order of
+// preference of the server, which the client accepts with the highest quality. It replaces
+// handlers.CompressHandler, which only supports gzip and deflate.
+func compressionHandler(h http.Handler, encodings []string) http.Handler {
+	if len(encodings) == 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+		// the websockets must not be compressed by the handler
+		if encoding == "" || r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressResponseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+		}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the encoding accepted by the Accept-Encoding header with the highest
+// quality, ties going to the order of the encodings, or an empty string if none is accepted.
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if coding != "" {
+			qualities[strings.ToLower(coding)] = quality
+		}
+	}
+	best, bestQuality := "", 0.0
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// compressResponseWriter compresses the body of the response, unless it is already encoded or
+// has no body.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	writer      io.WriteCloser
+	wroteHeader bool
+}
+
+func (w *compressResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	if code >= 100 && code < 200 {
+		// the informational responses may precede the final one
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	if header.Get("Content-Encoding") == "" && code != http.StatusNoContent && code != http.StatusNotModified {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.writer = compressionEncoders[w.encoding](w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			// sniff the content type of the uncompressed body, as net/http would
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.writer.Write(b)
+}
+
+// Flush flushes the compressed data, e.g. of the streamed responses of the API gateway.
+func (w *compressResponseWriter) Flush() {
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressResponseWriter) close() {
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
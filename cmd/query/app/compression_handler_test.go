@@ -0,0 +1,141 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compressedBody = `{"data": ["frontend", "backend"]}`
+
+func TestCompressionHandler(t *testing.T) {
+	handler := compressionHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "33")
+		w.Write([]byte(compressedBody))
+	}), []string{"br", "gzip", "deflate"})
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"br": func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"gzip": func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil },
+		"":        func(r io.Reader) (io.Reader, error) { return r, nil },
+	}
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+	}{
+		{acceptEncoding: "gzip, deflate, br", encoding: "br"},
+		{acceptEncoding: "gzip", encoding: "gzip"},
+		{acceptEncoding: "br;q=0.5, deflate", encoding: "deflate"},
+		{acceptEncoding: "br;q=0, GZIP;q=0.8", encoding: "gzip"},
+		{acceptEncoding: "*", encoding: "br"},
+		{acceptEncoding: "identity", encoding: ""},
+		{acceptEncoding: "br;q=oops", encoding: ""},
+		{acceptEncoding: "", encoding: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.acceptEncoding, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+			request.Header.Set("Accept-Encoding", test.acceptEncoding)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.encoding, recorder.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+			if test.encoding != "" {
+				assert.Empty(t, recorder.Header().Get("Content-Length"))
+			}
+			assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"))
+			reader, err := decoders[test.encoding](recorder.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, compressedBody, string(body))
+		})
+	}
+}
+
+func TestCompressionHandlerUncompressed(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		upgrade string
+	}{
+		{
+			name: "already encoded",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Encoding", "gzip")
+				w.Write([]byte("gzipped"))
+			},
+		},
+		{
+			name: "no content",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name:    "websocket",
+			handler: func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte("upgraded")) },
+			upgrade: "websocket",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept-Encoding", "br")
+			request.Header.Set("Upgrade", test.upgrade)
+			recorder := httptest.NewRecorder()
+			compressionHandler(test.handler, []string{"br"}).ServeHTTP(recorder, request)
+			assert.NotEqual(t, "br", recorder.Header().Get("Content-Encoding"))
+			assert.NotContains(t, recorder.Body.String(), "\x00")
+		})
+	}
+}
+
+func TestCompressionHandlerFlush(t *testing.T) {
+	server := httptest.NewServer(compressionHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(compressedBody))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(compressedBody))
+	}), []string{"gzip"}))
+	defer server.Close()
+
+	// the transport decompresses the gzip responses it asked for
+	response, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.True(t, response.Uncompressed)
+	assert.Equal(t, strings.Repeat(compressedBody, 2), string(body))
+}
+
+func TestCompressionHandlerDisabled(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	compressionHandler(http.NotFoundHandler(), nil).ServeHTTP(recorder, request)
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Empty(t, recorder.Header().Get("Vary"))
+}
+
+func TestValidateCompressionEncodings(t *testing.T) {
+	require.NoError(t, validateCompressionEncodings([]string{"br", "gzip", "deflate"}))
+	require.EqualError(t, validateCompressionEncodings([]string{"zstd"}), `unsupported compression "zstd", must be br, gzip or deflate`)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var (
+	// ErrTooManyExports is returned when the maximum number of concurrent exports are already running.
+	ErrTooManyExports = errors.New("too many trace exports are running, retry later")
+	// ErrMaxDurationExceeded is returned when an export is ended by its maximum duration.
+	ErrMaxDurationExceeded = errors.New("the trace export exceeded its maximum duration")
+)
+
+// Exporter reads all the traces matching a query, page by page, and writes them one by one.
+// The next page is only read once the previous one is written, so that a slow consumer
+// slows down the reads of the span storage.
+type Exporter struct {
+	querySvc *querysvc.QueryService
+	options  Options
+	// slots holds a token per running export.
+	slots chan struct{}
+}
+
+// NewExporter creates an Exporter of the traces of the query service.
+func NewExporter(options Options, querySvc *querysvc.QueryService) (*Exporter, error) {
+	if options.MaxDuration <= 0 {
+		return nil, errors.New("the maximum duration of the trace exports must be positive")
+	}
+	if options.MaxConcurrent <= 0 {
+		return nil, errors.New("the maximum number of concurrent trace exports must be positive")
+	}
+	if options.MaxTraces <= 0 || options.PageSize <= 0 {
+		return nil, errors.New("the maximum number of traces and the page size of the trace exports must be positive")
+	}
+	return &Exporter{
+		querySvc: querySvc,
+		options:  options,
+		slots:    make(chan struct{}, options.MaxConcurrent),
+	}, nil
+}
+
+// Export writes the traces matching the query, at most query.NumTraces of them or Options.MaxTraces
+// if not set, and returns the number of traces written. The pagination fields of the query are ignored.
+func (e *Exporter) Export(
+	ctx context.Context,
+	query spanstore.TraceQueryParameters,
+	write func(trace *model.Trace) error,
+) (int, error) {
+	select {
+	case e.slots <- struct{}{}:
+		defer func() { <-e.slots }()
+	default:
+		return 0, ErrTooManyExports
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.options.MaxDuration)
+	defer cancel()
+
+	remaining := e.options.MaxTraces
+	if query.NumTraces > 0 && query.NumTraces < remaining {
+		remaining = query.NumTraces
+	}
+	query.PageToken = ""
+	throttle := newThrottle(e.options.MaxTracesPerSecond)
+	written := 0
+	for remaining > 0 {
+		query.NumTraces = min(e.options.PageSize, remaining)
+		page, err := e.querySvc.FindTracesPage(ctx, &query)
+		if err != nil {
+			return written, e.contextError(ctx, err)
+		}
+		traces := page.Traces
+		for _, trace := range traces[:min(len(traces), remaining)] {
+			if err := throttle.wait(ctx); err != nil {
+				return written, e.contextError(ctx, err)
+			}
+			if err := write(trace); err != nil {
+				return written, err
+			}
+			written++
+			remaining--
+		}
+		if page.NextPageToken == "" || len(traces) == 0 {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+	return written, nil
+}
+
+// contextError replaces the errors caused by the deadline of the export with ErrMaxDurationExceeded.
+func (*Exporter) contextError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrMaxDurationExceeded
+	}
+	return err
+}
+
+// throttle spaces out the traces written so that they do not exceed a rate.
+type throttle struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newThrottle(perSecond int) *throttle {
+	t := &throttle{}
+	if perSecond > 0 {
+		t.interval = time.Second / time.Duration(perSecond)
+	}
+	return t
+}
+
+// wait blocks until the next trace may be written.
+func (t *throttle) wait(ctx context.Context) error {
+	if t.interval == 0 {
+		return nil
+	}
+	now := time.Now()
+	if delay := t.next.Sub(now); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	} else {
+		t.next = now
+	}
+	t.next = t.next.Add(t.interval)
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var testOptions = Options{
+	MaxDuration:   time.Minute,
+	MaxConcurrent: 1,
+	MaxTraces:     100,
+	PageSize:      2,
+}
+
+func newTrace(id uint64) *model.Trace {
+	return &model.Trace{Spans: []*model.Span{{TraceID: model.NewTraceID(0, id)}}}
+}
+
+// paginatedReader is a mocked span reader supporting the pagination of the traces.
+type paginatedReader struct {
+	*spanstoremocks.Reader
+	*spanstoremocks.PaginatedReader
+}
+
+// pagedReader returns a reader whose first page holds the traces 1 and 2, and the second one the trace 3.
+func pagedReader() spanstore.Reader {
+	pageReader := &spanstoremocks.PaginatedReader{}
+	nextPageToken := spanstore.EncodePageToken(2)
+	pageReader.On("FindTracesPage", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.PageToken == ""
+	})).Return(&spanstore.TracesPage{Traces: []*model.Trace{newTrace(1), newTrace(2)}, NextPageToken: nextPageToken}, nil)
+	pageReader.On("FindTracesPage", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.PageToken == nextPageToken
+	})).Return(&spanstore.TracesPage{Traces: []*model.Trace{newTrace(3)}}, nil)
+	return &paginatedReader{Reader: &spanstoremocks.Reader{}, PaginatedReader: pageReader}
+}
+
+func newTestExporter(t *testing.T, options Options, reader spanstore.Reader) *Exporter {
+	exporter, err := NewExporter(options, querysvc.NewQueryService(reader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{}))
+	require.NoError(t, err)
+	return exporter
+}
+
+func TestNewExporterErrors(t *testing.T) {
+	for _, options := range []Options{
+		{MaxConcurrent: 1, MaxTraces: 1, PageSize: 1},
+		{MaxDuration: time.Minute, MaxTraces: 1, PageSize: 1},
+		{MaxDuration: time.Minute, MaxConcurrent: 1, PageSize: 1},
+		{MaxDuration: time.Minute, MaxConcurrent: 1, MaxTraces: 1},
+	} {
+		_, err := NewExporter(options, nil)
+		require.Error(t, err, options)
+	}
+}
+
+func TestExport(t *testing.T) {
+	testCases := []struct {
+		name      string
+		numTraces int
+		maxTraces int
+		expected  []uint64
+	}{
+		{name: "all pages", expected: []uint64{1, 2, 3}},
+		{name: "query limit", numTraces: 1, expected: []uint64{1}},
+		{name: "limit within page", numTraces: 3, expected: []uint64{1, 2, 3}},
+		{name: "max traces", numTraces: 10, maxTraces: 2, expected: []uint64{1, 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := testOptions
+			if tc.maxTraces > 0 {
+				options.MaxTraces = tc.maxTraces
+			}
+			exporter := newTestExporter(t, options, pagedReader())
+			var exported []uint64
+			n, err := exporter.Export(context.Background(), spanstore.TraceQueryParameters{NumTraces: tc.numTraces}, func(trace *model.Trace) error {
+				exported = append(exported, trace.Spans[0].TraceID.Low)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), n)
+			assert.Equal(t, tc.expected, exported)
+		})
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error")).Once()
+	exporter := newTestExporter(t, testOptions, reader)
+	_, err := exporter.Export(context.Background(), spanstore.TraceQueryParameters{}, func(*model.Trace) error { return nil })
+	require.EqualError(t, err, "storage error")
+
+	exporter = newTestExporter(t, testOptions, pagedReader())
+	n, err := exporter.Export(context.Background(), spanstore.TraceQueryParameters{}, func(*model.Trace) error {
+		return errors.New("write error")
+	})
+	require.EqualError(t, err, "write error")
+	assert.Equal(t, 0, n)
+}
+
+func TestExportTooManyExports(t *testing.T) {
+	exporter := newTestExporter(t, testOptions, pagedReader())
+	_, err := exporter.Export(context.Background(), spanstore.TraceQueryParameters{NumTraces: 1}, func(*model.Trace) error {
+		_, err := exporter.Export(context.Background(), spanstore.TraceQueryParameters{}, func(*model.Trace) error { return nil })
+		return err
+	})
+	require.ErrorIs(t, err, ErrTooManyExports)
+
+	// the slot of the export is released once it ends
+	_, err = exporter.Export(context.Background(), spanstore.TraceQueryParameters{NumTraces: 1}, func(*model.Trace) error { return nil })
+	require.NoError(t, err)
+}
+
+func TestExportMaxDuration(t *testing.T) {
+	options := testOptions
+	options.MaxDuration = 50 * time.Millisecond
+	options.MaxTracesPerSecond = 1
+	exporter := newTestExporter(t, options, pagedReader())
+	n, err := exporter.Export(context.Background(), spanstore.TraceQueryParameters{}, func(*model.Trace) error { return nil })
+	require.ErrorIs(t, err, ErrMaxDurationExceeded)
+	assert.Equal(t, 1, n)
+}
+
+func TestExportCanceled(t *testing.T) {
+	options := testOptions
+	options.MaxTracesPerSecond = 1
+	exporter := newTestExporter(t, options, pagedReader())
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := exporter.Export(ctx, spanstore.TraceQueryParameters{}, func(*model.Trace) error {
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestThrottle(t *testing.T) {
+	throttle := newThrottle(200)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, throttle.wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+}
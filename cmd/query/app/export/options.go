@@ -0,0 +1,147 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+)
+
+const (
+	flagPrefix             = "query.export"
+	flagEnabled            = flagPrefix + ".enabled"
+	flagMaxDuration        = flagPrefix + ".max-duration"
+	flagMaxTracesPerSecond = flagPrefix + ".max-traces-per-second"
+	flagMaxConcurrent      = flagPrefix + ".max-concurrent"
+	flagMaxTraces          = flagPrefix + ".max-traces"
+	flagPageSize           = flagPrefix + ".page-size"
+
+	scheduleFlagPrefix   = flagPrefix + ".schedule"
+	flagScheduleEnabled  = scheduleFlagPrefix + ".enabled"
+	flagInterval         = scheduleFlagPrefix + ".interval"
+	flagDelay            = scheduleFlagPrefix + ".delay"
+	flagQueriesFile      = scheduleFlagPrefix + ".queries-file"
+	flagScheduleMax      = scheduleFlagPrefix + ".max-traces"
+	flagTracesPerFile    = scheduleFlagPrefix + ".traces-per-file"
+	flagBucket           = scheduleFlagPrefix + ".s3.bucket"
+	flagKeyPrefix        = scheduleFlagPrefix + ".s3.prefix"
+	flagRegion           = scheduleFlagPrefix + ".s3.region"
+	flagEndpoint         = scheduleFlagPrefix + ".s3.endpoint"
+	flagS3ForcePathStyle = scheduleFlagPrefix + ".s3.force-path-style"
+
+	defaultMaxDuration        = 5 * time.Minute
+	defaultMaxTracesPerSecond = 100
+	defaultMaxConcurrent      = 2
+	defaultMaxTraces          = 10000
+	defaultPageSize           = 100
+
+	defaultInterval      = time.Hour
+	defaultDelay         = 5 * time.Minute
+	defaultScheduleMax   = 100000
+	defaultTracesPerFile = 1000
+	defaultKeyPrefix     = "jaeger"
+)
+
+// Options holds configuration for the bulk export of the traces matching a query.
+type Options struct {
+	// Enabled registers the API streaming the exported traces.
+	Enabled bool
+	// MaxDuration bounds the duration of an export, the stream is ended once it elapses.
+	MaxDuration time.Duration
+	// MaxTracesPerSecond throttles the traces written by an export, unlimited if zero.
+	MaxTracesPerSecond int
+	// MaxConcurrent is the maximum number of exports running at the same time.
+	MaxConcurrent int
+	// MaxTraces is the maximum number of traces of an export, and its default limit.
+	MaxTraces int
+	// PageSize is the number of traces read from the span storage at once. The span storages
+	// that do not support pagination only export the first page.
+	PageSize int
+	// Schedule configures the periodic export of saved queries to an object storage.
+	Schedule ScheduleOptions
+}
+
+// ScheduleOptions holds configuration for the periodic export of the traces of saved queries
+// to an S3 compatible object storage, e.g. Google Cloud Storage with its interoperability endpoint.
+type ScheduleOptions struct {
+	// Enabled runs the scheduled exports in the background.
+	Enabled bool
+	// Interval is the period of the exports, each export covers the traces started during an interval.
+	Interval time.Duration
+	// Delay leaves the time to the spans of the last traces to be stored before they are exported.
+	Delay time.Duration
+	// QueriesFile is the path to the JSON file of the saved queries, see LoadSavedQueries.
+	QueriesFile string
+	// MaxTraces is the maximum number of traces exported per query and interval.
+	MaxTraces int
+	// TracesPerFile is the maximum number of traces per file written.
+	TracesPerFile int
+	// Bucket is the bucket of the object storage the files are written to.
+	Bucket string
+	// KeyPrefix is prepended to the keys of the files written.
+	KeyPrefix string
+	// Region is the region of the bucket, the default region of the AWS configuration is used if empty.
+	Region string
+	// Endpoint overrides the endpoint of S3, e.g. https://storage.googleapis.com.
+	Endpoint string
+	// ForcePathStyle puts the bucket in the path of the URLs instead of the host name.
+	ForcePathStyle bool
+}
+
+// s3Config returns the bucket the files are written to.
+func (o ScheduleOptions) s3Config() objectstore.S3Config {
+	return objectstore.S3Config{
+		Bucket:         o.Bucket,
+		Region:         o.Region,
+		Endpoint:       o.Endpoint,
+		ForcePathStyle: o.ForcePathStyle,
+	}
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Allow streaming all the traces matching a query via /api/export/traces, as newline-delimited JSON or length-prefixed OTLP protobuf")
+	flagSet.Duration(flagMaxDuration, defaultMaxDuration, "The maximum duration of a trace export, the stream is ended once it elapses")
+	flagSet.Int(flagMaxTracesPerSecond, defaultMaxTracesPerSecond, "The maximum number of traces per second written by a trace export; unlimited if 0")
+	flagSet.Int(flagMaxConcurrent, defaultMaxConcurrent, "The maximum number of trace exports running at the same time, the others are rejected")
+	flagSet.Int(flagMaxTraces, defaultMaxTraces, "The maximum number of traces of a trace export")
+	flagSet.Int(flagPageSize, defaultPageSize, "The number of traces read from the span storage at once by a trace export; the span storages without pagination only export the first page")
+	flagSet.Bool(flagScheduleEnabled, false, "Periodically export the traces of saved queries as compressed OTLP files to an S3 compatible object storage")
+	flagSet.Duration(flagInterval, defaultInterval, "The period of the scheduled exports, each export covers the traces started during one period")
+	flagSet.Duration(flagDelay, defaultDelay, "The delay after the end of a period before its traces are exported, leaving time to store their last spans")
+	flagSet.String(flagQueriesFile, "", `The path to a JSON file of the saved queries exported, of the form {"queries": [{"name": "...", "service": "...", ...}]}`)
+	flagSet.Int(flagScheduleMax, defaultScheduleMax, "The maximum number of traces exported per saved query and period")
+	flagSet.Int(flagTracesPerFile, defaultTracesPerFile, "The maximum number of traces per file written by the scheduled exports")
+	flagSet.String(flagBucket, "", "The bucket the scheduled exports are written to")
+	flagSet.String(flagKeyPrefix, defaultKeyPrefix, "The prefix of the keys of the files written by the scheduled exports")
+	flagSet.String(flagRegion, "", "The region of the bucket of the scheduled exports; the default region of the AWS configuration is used if empty")
+	flagSet.String(flagEndpoint, "", "The endpoint of the S3 compatible object storage, e.g. https://storage.googleapis.com for Google Cloud Storage; AWS S3 if empty")
+	flagSet.Bool(flagS3ForcePathStyle, false, "Put the bucket in the path of the object storage URLs instead of the host name, as required by some S3 compatible object storages")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.MaxDuration = v.GetDuration(flagMaxDuration)
+	o.MaxTracesPerSecond = v.GetInt(flagMaxTracesPerSecond)
+	o.MaxConcurrent = v.GetInt(flagMaxConcurrent)
+	o.MaxTraces = v.GetInt(flagMaxTraces)
+	o.PageSize = v.GetInt(flagPageSize)
+	o.Schedule.Enabled = v.GetBool(flagScheduleEnabled)
+	o.Schedule.Interval = v.GetDuration(flagInterval)
+	o.Schedule.Delay = v.GetDuration(flagDelay)
+	o.Schedule.QueriesFile = v.GetString(flagQueriesFile)
+	o.Schedule.MaxTraces = v.GetInt(flagScheduleMax)
+	o.Schedule.TracesPerFile = v.GetInt(flagTracesPerFile)
+	o.Schedule.Bucket = v.GetString(flagBucket)
+	o.Schedule.KeyPrefix = v.GetString(flagKeyPrefix)
+	o.Schedule.Region = v.GetString(flagRegion)
+	o.Schedule.Endpoint = v.GetString(flagEndpoint)
+	o.Schedule.ForcePathStyle = v.GetBool(flagS3ForcePathStyle)
+	return o
+}
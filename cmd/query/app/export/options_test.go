@@ -0,0 +1,77 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.export.enabled=true",
+		"--query.export.max-duration=1m",
+		"--query.export.max-traces-per-second=10",
+		"--query.export.max-concurrent=4",
+		"--query.export.max-traces=500",
+		"--query.export.page-size=50",
+		"--query.export.schedule.enabled=true",
+		"--query.export.schedule.interval=15m",
+		"--query.export.schedule.delay=1m",
+		"--query.export.schedule.queries-file=/etc/jaeger/queries.json",
+		"--query.export.schedule.max-traces=1000",
+		"--query.export.schedule.traces-per-file=100",
+		"--query.export.schedule.s3.bucket=traces",
+		"--query.export.schedule.s3.prefix=archive",
+		"--query.export.schedule.s3.region=eu-west-1",
+		"--query.export.schedule.s3.endpoint=https://storage.googleapis.com",
+		"--query.export.schedule.s3.force-path-style=true",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Enabled:            true,
+		MaxDuration:        time.Minute,
+		MaxTracesPerSecond: 10,
+		MaxConcurrent:      4,
+		MaxTraces:          500,
+		PageSize:           50,
+		Schedule: ScheduleOptions{
+			Enabled:        true,
+			Interval:       15 * time.Minute,
+			Delay:          time.Minute,
+			QueriesFile:    "/etc/jaeger/queries.json",
+			MaxTraces:      1000,
+			TracesPerFile:  100,
+			Bucket:         "traces",
+			KeyPrefix:      "archive",
+			Region:         "eu-west-1",
+			Endpoint:       "https://storage.googleapis.com",
+			ForcePathStyle: true,
+		},
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Equal(t, defaultMaxDuration, opts.MaxDuration)
+	assert.Equal(t, defaultMaxTracesPerSecond, opts.MaxTracesPerSecond)
+	assert.Equal(t, defaultMaxConcurrent, opts.MaxConcurrent)
+	assert.Equal(t, defaultMaxTraces, opts.MaxTraces)
+	assert.Equal(t, defaultPageSize, opts.PageSize)
+	assert.False(t, opts.Schedule.Enabled)
+	assert.Equal(t, defaultInterval, opts.Schedule.Interval)
+	assert.Equal(t, defaultDelay, opts.Schedule.Delay)
+	assert.Equal(t, defaultScheduleMax, opts.Schedule.MaxTraces)
+	assert.Equal(t, defaultTracesPerFile, opts.Schedule.TracesPerFile)
+	assert.Equal(t, defaultKeyPrefix, opts.Schedule.KeyPrefix)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// savedQueryName restricts the names of the saved queries, which are part of the keys of the files written.
+var savedQueryName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// SavedQuery is a trace query exported periodically by a Scheduler.
+type SavedQuery struct {
+	// Name identifies the query in the keys of the files written.
+	Name        string            `json:"name"`
+	Service     string            `json:"service"`
+	Operation   string            `json:"operation,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	MinDuration string            `json:"minDuration,omitempty"`
+	MaxDuration string            `json:"maxDuration,omitempty"`
+
+	minDuration time.Duration
+	maxDuration time.Duration
+}
+
+type savedQueriesFile struct {
+	Queries []SavedQuery `json:"queries"`
+}
+
+// LoadSavedQueries reads the queries from a JSON file of the form {"queries": [...]}.
+func LoadSavedQueries(filename string) ([]SavedQuery, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the saved queries: %w", err)
+	}
+	var file savedQueriesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse the saved queries: %w", err)
+	}
+	if len(file.Queries) == 0 {
+		return nil, errors.New("no saved queries found")
+	}
+	names := make(map[string]struct{}, len(file.Queries))
+	for i := range file.Queries {
+		q := &file.Queries[i]
+		if err := q.init(); err != nil {
+			return nil, fmt.Errorf("invalid saved query #%d: %w", i, err)
+		}
+		if _, ok := names[q.Name]; ok {
+			return nil, fmt.Errorf("duplicate saved query %q", q.Name)
+		}
+		names[q.Name] = struct{}{}
+	}
+	return file.Queries, nil
+}
+
+// init validates the query and parses its durations.
+func (q *SavedQuery) init() error {
+	if !savedQueryName.MatchString(q.Name) {
+		return fmt.Errorf("the name %q must only contain letters, digits, '_', '.' and '-'", q.Name)
+	}
+	if q.Service == "" {
+		return errors.New("the service is required")
+	}
+	var err error
+	if q.MinDuration != "" {
+		if q.minDuration, err = time.ParseDuration(q.MinDuration); err != nil {
+			return fmt.Errorf("invalid minimum duration: %w", err)
+		}
+	}
+	if q.MaxDuration != "" {
+		if q.maxDuration, err = time.ParseDuration(q.MaxDuration); err != nil {
+			return fmt.Errorf("invalid maximum duration: %w", err)
+		}
+	}
+	return nil
+}
+
+// traceQuery returns the parameters of the query of the traces started in [start, end).
+func (q *SavedQuery) traceQuery(start, end time.Time) spanstore.TraceQueryParameters {
+	return spanstore.TraceQueryParameters{
+		ServiceName:   q.Service,
+		OperationName: q.Operation,
+		Tags:          q.Tags,
+		DurationMin:   q.minDuration,
+		DurationMax:   q.maxDuration,
+		StartTimeMin:  start,
+		// the span storages match the maximum start time inclusively, at microsecond resolution
+		StartTimeMax: end.Add(-time.Microsecond),
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func writeQueriesFile(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "queries.json")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	return filename
+}
+
+func TestLoadSavedQueries(t *testing.T) {
+	filename := writeQueriesFile(t, `{"queries": [
+		{"name": "checkout-errors", "service": "checkout", "operation": "POST /orders", "tags": {"error": "true"}, "minDuration": "1s", "maxDuration": "1m"},
+		{"name": "frontend", "service": "frontend"}
+	]}`)
+	queries, err := LoadSavedQueries(filename)
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+
+	start := time.Unix(1000, 0)
+	assert.Equal(t, spanstore.TraceQueryParameters{
+		ServiceName:   "checkout",
+		OperationName: "POST /orders",
+		Tags:          map[string]string{"error": "true"},
+		DurationMin:   time.Second,
+		DurationMax:   time.Minute,
+		StartTimeMin:  start,
+		StartTimeMax:  start.Add(time.Hour - time.Microsecond),
+	}, queries[0].traceQuery(start, start.Add(time.Hour)))
+	assert.Equal(t, "frontend", queries[1].traceQuery(start, start.Add(time.Hour)).ServiceName)
+}
+
+func TestLoadSavedQueriesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "invalid JSON", content: `{`, err: "failed to parse the saved queries"},
+		{name: "no queries", content: `{"queries": []}`, err: "no saved queries found"},
+		{name: "invalid name", content: `{"queries": [{"name": "a/b", "service": "s"}]}`, err: `invalid saved query #0: the name "a/b"`},
+		{name: "no service", content: `{"queries": [{"name": "a"}]}`, err: "the service is required"},
+		{name: "invalid min duration", content: `{"queries": [{"name": "a", "service": "s", "minDuration": "x"}]}`, err: "invalid minimum duration"},
+		{name: "invalid max duration", content: `{"queries": [{"name": "a", "service": "s", "maxDuration": "x"}]}`, err: "invalid maximum duration"},
+		{name: "duplicate", content: `{"queries": [{"name": "a", "service": "s"}, {"name": "a", "service": "t"}]}`, err: `duplicate saved query "a"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadSavedQueries(writeQueriesFile(t, test.content))
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+
+	_, err := LoadSavedQueries(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "failed to read the saved queries")
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+	model2otel "github.com/jaegertracing/jaeger/pkg/otlptranslator"
+)
+
+const (
+	manifestName   = "manifest.json"
+	windowFormat   = "20060102T150405Z"
+	tracesFileType = "application/gzip"
+)
+
+// Manifest describes the files written by the export of a saved query over a time window.
+// It is written after the files, its presence marks a complete export.
+type Manifest struct {
+	Query     string         `json:"query"`
+	StartTime time.Time      `json:"startTime"`
+	EndTime   time.Time      `json:"endTime"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Traces    int            `json:"traces"`
+	Spans     int            `json:"spans"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a gzip compressed file holding a trace per line, as an OTLP TracesData JSON.
+type ManifestFile struct {
+	Key    string `json:"key"`
+	Traces int    `json:"traces"`
+	Spans  int    `json:"spans"`
+	Size   int    `json:"size"`
+}
+
+// ObjectStore writes the files of the scheduled exports, see objectstore.S3Store.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// Scheduler periodically exports the traces of saved queries to an object storage.
+// Each run exports, for every query, the traces started since the end of its last successful export.
+type Scheduler struct {
+	options  ScheduleOptions
+	queries  []SavedQuery
+	exporter *Exporter
+	store    ObjectStore
+	logger   *zap.Logger
+	timeNow  func() time.Time
+	// nextStart is the start of the window of the next export of each query,
+	// the end of the window of its last successful export.
+	nextStart map[string]time.Time
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	done      sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler of the saved queries of the options, writing to S3.
+func NewScheduler(options ScheduleOptions, querySvc *querysvc.QueryService, logger *zap.Logger) (*Scheduler, error) {
+	if options.Interval <= 0 {
+		return nil, errors.New("the interval of the scheduled exports must be positive")
+	}
+	if options.TracesPerFile <= 0 {
+		return nil, errors.New("the number of traces per file of the scheduled exports must be positive")
+	}
+	if options.Bucket == "" {
+		return nil, errors.New("the bucket of the scheduled exports must be set")
+	}
+	queries, err := LoadSavedQueries(options.QueriesFile)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := NewExporter(Options{
+		MaxDuration:   options.Interval,
+		MaxConcurrent: 1,
+		MaxTraces:     options.MaxTraces,
+		PageSize:      defaultPageSize,
+	}, querySvc)
+	if err != nil {
+		return nil, err
+	}
+	store, err := objectstore.NewS3Store(options.s3Config())
+	if err != nil {
+		return nil, err
+	}
+	return newScheduler(options, queries, exporter, store, logger), nil
+}
+
+func newScheduler(options ScheduleOptions, queries []SavedQuery, exporter *Exporter, store ObjectStore, logger *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		options:   options,
+		queries:   queries,
+		exporter:  exporter,
+		store:     store,
+		logger:    logger,
+		timeNow:   time.Now,
+		nextStart: make(map[string]time.Time),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start runs the exports periodically until Close is called.
+func (s *Scheduler) Start() {
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(s.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.run(s.ctx)
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the exports and waits for the current run to finish.
+func (s *Scheduler) Close() error {
+	s.closeOnce.Do(s.cancel)
+	s.done.Wait()
+	return nil
+}
+
+// run exports every saved query up to the current time minus the delay.
+func (s *Scheduler) run(ctx context.Context) {
+	end := s.timeNow().Add(-s.options.Delay).Truncate(time.Second)
+	for i := range s.queries {
+		q := &s.queries[i]
+		start, ok := s.nextStart[q.Name]
+		if !ok {
+			start = end.Add(-s.options.Interval)
+			s.nextStart[q.Name] = start
+		}
+		if !start.Before(end) {
+			continue
+		}
+		manifest, err := s.exportQuery(ctx, q, start, end)
+		if err != nil {
+			// the window is exported again by the next run
+			s.logger.Error("Failed to export the traces of a saved query", zap.String("query", q.Name), zap.Error(err))
+		} else {
+			s.nextStart[q.Name] = end
+			s.logger.Info("Exported the traces of a saved query", zap.String("query", q.Name),
+				zap.Int("traces", manifest.Traces), zap.Int("files", len(manifest.Files)))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// exportQuery writes the traces of the query started in [start, end), then their manifest.
+func (s *Scheduler) exportQuery(ctx context.Context, q *SavedQuery, start, end time.Time) (*Manifest, error) {
+	dir := path.Join(s.options.KeyPrefix, q.Name, start.UTC().Format(windowFormat)+"-"+end.UTC().Format(windowFormat))
+	manifest := &Manifest{
+		Query:     q.Name,
+		StartTime: start.UTC(),
+		EndTime:   end.UTC(),
+	}
+	file := &tracesFile{}
+	flush := func() error {
+		if file.traces == 0 {
+			return nil
+		}
+		body, err := file.close()
+		if err != nil {
+			return err
+		}
+		key := path.Join(dir, fmt.Sprintf("traces-%05d.otlp.jsonl.gz", len(manifest.Files)))
+		if err := s.store.PutObject(ctx, key, body, tracesFileType); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{Key: key, Traces: file.traces, Spans: file.spans, Size: len(body)})
+		manifest.Traces += file.traces
+		manifest.Spans += file.spans
+		file = &tracesFile{}
+		return nil
+	}
+	query := q.traceQuery(start, end)
+	query.NumTraces = s.options.MaxTraces
+	_, err := s.exporter.Export(ctx, query, func(trace *model.Trace) error {
+		if err := file.write(trace); err != nil {
+			return err
+		}
+		if file.traces >= s.options.TracesPerFile {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	manifest.CreatedAt = s.timeNow().UTC()
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.PutObject(ctx, path.Join(dir, manifestName), body, "application/json"); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// tracesFile compresses the traces of a file, as a line of OTLP TracesData JSON per trace.
+type tracesFile struct {
+	buf    bytes.Buffer
+	gzip   *gzip.Writer
+	traces int
+	spans  int
+}
+
+func (f *tracesFile) write(trace *model.Trace) error {
+	td, err := model2otel.ProtoToTraces([]*model.Batch{{Spans: trace.Spans}})
+	if err != nil {
+		return err
+	}
+	line, err := new(ptrace.JSONMarshaler).MarshalTraces(td)
+	if err != nil {
+		return err
+	}
+	if f.gzip == nil {
+		f.gzip = gzip.NewWriter(&f.buf)
+	}
+	if _, err := f.gzip.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	f.traces++
+	f.spans += len(trace.Spans)
+	return nil
+}
+
+// close returns the compressed content of the file.
+func (f *tracesFile) close() ([]byte, error) {
+	if err := f.gzip.Close(); err != nil {
+		return nil, err
+	}
+	return f.buf.Bytes(), nil
+}
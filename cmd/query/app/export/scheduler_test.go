@@ -0,0 +1,178 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+)
+
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	err     error
+}
+
+func (s *fakeObjectStore) PutObject(_ context.Context, key string, body []byte, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if s.objects == nil {
+		s.objects = make(map[string][]byte)
+	}
+	s.objects[key] = body
+	return nil
+}
+
+func (s *fakeObjectStore) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, ok := s.objects[key]
+	return body, ok
+}
+
+var testScheduleOptions = ScheduleOptions{
+	Interval:      time.Hour,
+	Delay:         time.Minute,
+	MaxTraces:     100,
+	TracesPerFile: 2,
+	KeyPrefix:     "jaeger",
+}
+
+func newTestScheduler(t *testing.T, store ObjectStore, now time.Time) *Scheduler {
+	spanStore := memory.NewStore()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, spanStore.WriteSpan(context.Background(), &model.Span{
+			TraceID:       model.NewTraceID(0, uint64(i)),
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			StartTime:     now.Add(-time.Duration(i) * 10 * time.Minute),
+			Process:       &model.Process{ServiceName: "frontend"},
+		}))
+	}
+	querySvc := querysvc.NewQueryService(spanStore, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	exporter, err := NewExporter(Options{MaxDuration: time.Minute, MaxConcurrent: 1, MaxTraces: 100, PageSize: 100}, querySvc)
+	require.NoError(t, err)
+	scheduler := newScheduler(testScheduleOptions, []SavedQuery{{Name: "frontend", Service: "frontend"}}, exporter, store, zap.NewNop())
+	scheduler.timeNow = func() time.Time { return now }
+	return scheduler
+}
+
+func TestSchedulerRun(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeObjectStore{}
+	scheduler := newTestScheduler(t, store, now)
+	scheduler.run(context.Background())
+
+	dir := "jaeger/frontend/20240501T105900Z-20240501T115900Z/"
+	body, ok := store.get(dir + manifestName)
+	require.True(t, ok)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(body, &manifest))
+	assert.Equal(t, "frontend", manifest.Query)
+	assert.Equal(t, now.Add(-61*time.Minute), manifest.StartTime)
+	assert.Equal(t, now.Add(-time.Minute), manifest.EndTime)
+	assert.Equal(t, 3, manifest.Traces)
+	assert.Equal(t, 3, manifest.Spans)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, dir+"traces-00000.otlp.jsonl.gz", manifest.Files[0].Key)
+	assert.Equal(t, 2, manifest.Files[0].Traces)
+	assert.Equal(t, 1, manifest.Files[1].Traces)
+
+	file, ok := store.get(manifest.Files[0].Key)
+	require.True(t, ok)
+	assert.Len(t, file, manifest.Files[0].Size)
+	reader, err := gzip.NewReader(bytes.NewReader(file))
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(reader)
+	lines := 0
+	for scanner.Scan() {
+		td, err := new(ptrace.JSONUnmarshaler).UnmarshalTraces(scanner.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, 1, td.SpanCount())
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+
+	// the next run starts at the end of the previous one
+	scheduler.timeNow = func() time.Time { return now.Add(time.Hour) }
+	scheduler.run(context.Background())
+	body, ok = store.get("jaeger/frontend/20240501T115900Z-20240501T125900Z/" + manifestName)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal(body, &manifest))
+	assert.Equal(t, 0, manifest.Traces)
+	assert.Empty(t, manifest.Files)
+}
+
+func TestSchedulerRunError(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeObjectStore{err: errors.New("storage error")}
+	scheduler := newTestScheduler(t, store, now)
+	scheduler.run(context.Background())
+	assert.Equal(t, now.Add(-61*time.Minute), scheduler.nextStart["frontend"])
+
+	// the failed window is exported again
+	store.err = nil
+	scheduler.timeNow = func() time.Time { return now.Add(time.Hour) }
+	scheduler.run(context.Background())
+	_, ok := store.get("jaeger/frontend/20240501T105900Z-20240501T125900Z/" + manifestName)
+	assert.True(t, ok)
+}
+
+func TestSchedulerStartClose(t *testing.T) {
+	store := &fakeObjectStore{}
+	scheduler := newTestScheduler(t, store, time.Now())
+	scheduler.options.Interval = time.Millisecond
+	scheduler.Start()
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.objects) > 0
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, scheduler.Close())
+	require.NoError(t, scheduler.Close())
+}
+
+func TestNewScheduler(t *testing.T) {
+	options := testScheduleOptions
+	options.Bucket = "traces"
+	options.Region = "us-east-1"
+	options.QueriesFile = writeQueriesFile(t, `{"queries": [{"name": "frontend", "service": "frontend"}]}`)
+	querySvc := querysvc.NewQueryService(memory.NewStore(), &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	scheduler, err := NewScheduler(options, querySvc, zap.NewNop())
+	require.NoError(t, err)
+	assert.Len(t, scheduler.queries, 1)
+
+	for _, invalid := range []func(o *ScheduleOptions){
+		func(o *ScheduleOptions) { o.Interval = 0 },
+		func(o *ScheduleOptions) { o.TracesPerFile = 0 },
+		func(o *ScheduleOptions) { o.Bucket = "" },
+		func(o *ScheduleOptions) { o.QueriesFile = "" },
+		func(o *ScheduleOptions) { o.MaxTraces = 0 },
+	} {
+		invalidOptions := options
+		invalid(&invalidOptions)
+		_, err := NewScheduler(invalidOptions, querySvc, zap.NewNop())
+		require.Error(t, err)
+	}
+}
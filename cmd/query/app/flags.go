@@ -23,17 +23,31 @@ import (
 	"io"
 	"net/http"
 	"net/textproto"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/alerting"
+	"github.com/jaegertracing/jaeger/cmd/query/app/audit"
+	"github.com/jaegertracing/jaeger/cmd/query/app/export"
+	"github.com/jaegertracing/jaeger/cmd/query/app/graphqlapi"
+	"github.com/jaegertracing/jaeger/cmd/query/app/livetail"
+	"github.com/jaegertracing/jaeger/cmd/query/app/logs"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
+	"github.com/jaegertracing/jaeger/cmd/query/app/sharing"
+	"github.com/jaegertracing/jaeger/cmd/query/app/slo"
 	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/storage"
 )
@@ -41,14 +55,37 @@ import (
 const (
 	queryHTTPHostPort          = "query.http-server.host-port"
 	queryGRPCHostPort          = "query.grpc-server.host-port"
+	queryHTTPIPFamily          = "query.http-server.ip-family"
+	queryGRPCIPFamily          = "query.grpc-server.ip-family"
+	queryHTTPSocketPermissions = "query.http-server.socket-permissions"
+	queryGRPCSocketPermissions = "query.grpc-server.socket-permissions"
+	queryHTTP3HostPort         = "query.http-server.http3-host-port"
+	queryTrustedProxies        = "query.http-server.trusted-proxies"
+	queryAllowedHosts          = "query.http-server.allowed-hosts"
+	queryCompression           = "query.http-server.compression"
 	queryBasePath              = "query.base-path"
 	queryStaticFiles           = "query.static-files"
+	queryStaticFilesOverlay    = "query.static-files-overlay"
 	queryLogStaticAssetsAccess = "query.log-static-assets-access"
 	queryUIConfig              = "query.ui-config"
+	queryTenantUIConfig        = "query.tenant-ui-config"
 	queryTokenPropagation      = "query.bearer-token-propagation"
 	queryAdditionalHeaders     = "query.additional-headers"
 	queryMaxClockSkewAdjust    = "query.max-clock-skew-adjustment"
+	queryAdjusters             = "query.adjusters"
 	queryEnableTracing         = "query.enable-tracing"
+	queryAuthorizationRules    = "query.authorization.rules-file"
+	queryJWTJWKSURL            = "query.authorization.jwt.jwks-url"
+	queryJWTKeyFile            = "query.authorization.jwt.key-file"
+	queryJWTIssuer             = "query.authorization.jwt.issuer"
+	queryJWTAudience           = "query.authorization.jwt.audience"
+	queryTrustForwardedToken   = "query.authorization.trust-forwarded-token"
+	queryRecordWarnings        = "query.warnings.record"
+	querySamplingAdminToken    = "query.sampling-strategies.admin-token-file"
+	queryLimitsMaxSpans        = "query.limits.max-spans"
+	queryLimitsMaxSubQueries   = "query.limits.max-sub-queries"
+	queryLimitsMaxTimeRange    = "query.limits.max-time-range"
+	queryLimitsTenantsFile     = "query.limits.tenants-file"
 )
 
 var tlsGRPCFlagsConfig = tlscfg.ServerFlagsConfig{
@@ -63,6 +100,8 @@ var tlsHTTPFlagsConfig = tlscfg.ServerFlagsConfig{
 type QueryOptionsStaticAssets struct {
 	// Path is the path for the static assets for the UI (https://github.com/uber/jaeger-ui)
 	Path string `valid:"optional" mapstructure:"path"`
+	// OverlayPath is the path for the custom static assets replacing or extending those of the UI, e.g. for branding
+	OverlayPath string `valid:"optional" mapstructure:"overlay_path"`
 	// LogAccess tells static handler to log access to static assets, useful in debugging
 	LogAccess bool `valid:"optional" mapstructure:"log_access"`
 }
@@ -76,12 +115,22 @@ type QueryOptionsBase struct {
 
 	// UIConfig is the path to a configuration file for the UI
 	UIConfig string `valid:"optional" mapstructure:"ui_config"`
+	// TenantUIConfigs are the paths to the configuration files for the UI of the tenants, by tenant
+	TenantUIConfigs map[string]string `valid:"optional" mapstructure:"tenant_ui_configs"`
 	// BearerTokenPropagation activate/deactivate bearer token propagation to storage
 	BearerTokenPropagation bool
 	// AdditionalHeaders
 	AdditionalHeaders http.Header
 	// MaxClockSkewAdjust is the maximum duration by which jaeger-query will adjust a span
 	MaxClockSkewAdjust time.Duration
+	// Adjusters are the names of the adjusters applied to the traces, in order; the standard ones if empty
+	Adjusters []string `valid:"optional" mapstructure:"adjusters"`
+	// TrustedProxies are the IP ranges or addresses of the reverse proxies whose X-Forwarded-* headers are applied
+	TrustedProxies []string `valid:"optional" mapstructure:"trusted_proxies"`
+	// AllowedHosts are the hosts the HTTP requests may be sent to, all the hosts if empty
+	AllowedHosts []string `valid:"optional" mapstructure:"allowed_hosts"`
+	// Compression are the content encodings of the HTTP responses, in the order of preference
+	Compression []string `valid:"optional" mapstructure:"compression"`
 	// Tenancy configures tenancy for query
 	Tenancy tenancy.Options
 	// EnableTracing determines whether traces will be emitted by jaeger-query.
@@ -96,32 +145,128 @@ type QueryOptions struct {
 	HTTPHostPort string
 	// GRPCHostPort is the host:port address that the query service listens in on for gRPC requests
 	GRPCHostPort string
+	// HTTPNetwork is the Go TCP network of the HTTP server, e.g. tcp4 or tcp6, "tcp" if empty;
+	// it is also the network of the gRPC server if both share the same port
+	HTTPNetwork string
+	// GRPCNetwork is the Go TCP network of the gRPC server, e.g. tcp4 or tcp6, "tcp" if empty
+	GRPCNetwork string
+	// HTTPSocketPermissions are the permissions of the Unix domain socket of a unix:// HTTPHostPort, if not zero
+	HTTPSocketPermissions os.FileMode
+	// GRPCSocketPermissions are the permissions of the Unix domain socket of a unix:// GRPCHostPort, if not zero
+	GRPCSocketPermissions os.FileMode
+	// HTTP3HostPort is the UDP host:port address of the HTTP/3 listener of the HTTP server, disabled if empty
+	HTTP3HostPort string
 	// TLSGRPC configures secure transport (Consumer to Query service GRPC API)
 	TLSGRPC tlscfg.Options
 	// TLSHTTP configures secure transport (Consumer to Query service HTTP API)
 	TLSHTTP tlscfg.Options
+	// RegressionDetection configures the background detection of latency and error rate regressions
+	RegressionDetection regression.Options
+	// Audit configures the audit log of the requests to the query APIs
+	Audit audit.Options
+	// TraceSharing configures the share tokens of single traces
+	TraceSharing sharing.Options
+	// Logs configures the correlation of the traces with the logs of a log backend
+	Logs logs.Options
+	// Export configures the bulk export of the traces matching a query
+	Export export.Options
+	// GraphQL configures the GraphQL API
+	GraphQL graphqlapi.Options
+	// Alerting configures the evaluation of the alerting rules
+	Alerting alerting.Options
+	// SLOs configures the tracking of the Service Level Objectives
+	SLOs slo.Options
+	// LiveTail configures the streaming of the new traces matching a query
+	LiveTail livetail.Options
+	// TraceBroadcaster, when set, is the source of the new traces of the live tail instead of the
+	// span storage, for the collector running in the same process
+	TraceBroadcaster *tracestream.Broadcaster
+	// AuthorizationRules restrict the services the callers may query, if not nil
+	AuthorizationRules []querysvc.AuthorizationRule
+	// JWT configures the verification of the JWT bearer tokens the claims of the callers are read from,
+	// the callers having no claims if it is not enabled
+	JWT bearertoken.VerifierOptions
+	// TrustForwardedToken accepts the claims of the X-Forwarded-Access-Token header of the requests of the
+	// TrustedProxies without verifying the token
+	TrustForwardedToken bool
+	// RecordWarnings enables the persistence of the warnings of the adjusted traces in the span storage
+	RecordWarnings bool
+	// SamplingAdminToken is the bearer token of the admins allowed to edit the sampling strategies, if not empty
+	SamplingAdminToken string
+	// CostLimits bound the cost of the queries, unbounded if zero
+	CostLimits querysvc.CostLimits
+	// TenantCostLimits override the CostLimits by tenant, if not nil
+	TenantCostLimits map[string]querysvc.CostLimits
 }
 
 // AddFlags adds flags for QueryOptions
 func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Var(&config.StringSlice{}, queryAdditionalHeaders, `Additional HTTP response headers.  Can be specified multiple times.  Format: "Key: Value"`)
-	flagSet.String(queryHTTPHostPort, ports.PortToHostPort(ports.QueryHTTP), "The host:port (e.g. 127.0.0.1:14268 or :14268) of the query's HTTP server")
-	flagSet.String(queryGRPCHostPort, ports.PortToHostPort(ports.QueryGRPC), "The host:port (e.g. 127.0.0.1:14250 or :14250) of the query's gRPC server")
+	flagSet.String(queryHTTPHostPort, ports.PortToHostPort(ports.QueryHTTP), "The host:port (e.g. 127.0.0.1:14268 or :14268) of the query's HTTP server, or the path of its Unix domain socket (e.g. unix:///var/run/jaeger/query-http.sock)")
+	flagSet.String(queryGRPCHostPort, ports.PortToHostPort(ports.QueryGRPC), "The host:port (e.g. 127.0.0.1:14250 or :14250) of the query's gRPC server, or the path of its Unix domain socket (e.g. unix:///var/run/jaeger/query-grpc.sock)")
+	flagSet.String(queryHTTPSocketPermissions, "", "The octal permissions (e.g. 0660) of the Unix domain socket of the query's HTTP server, if it listens on one; the umask applies if empty")
+	flagSet.String(queryGRPCSocketPermissions, "", "The octal permissions (e.g. 0660) of the Unix domain socket of the query's gRPC server, if it listens on one; the umask applies if empty")
+	flagSet.String(queryHTTP3HostPort, "", "The UDP host:port (e.g. :16686) of the HTTP/3 (QUIC) listener of the query's HTTP server, announced to the browsers with the Alt-Svc header; it requires --query.http.tls.enabled (disabled by default)")
+	flagSet.String(queryTrustedProxies, "", "Comma-separated list of the IP ranges (e.g. 10.0.0.0/8) or addresses of the reverse proxies in front of the query's HTTP server, whose X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host headers are applied; the headers are ignored if empty")
+	flagSet.String(queryAllowedHosts, "", "Comma-separated list of the hosts (e.g. jaeger.example.com or *.example.com) the requests to the query's HTTP server may be sent to, the other ones being rejected; all the hosts are allowed if empty")
+	flagSet.String(queryCompression, "gzip,deflate", "Comma-separated list of the content encodings of the responses of the query's HTTP server, in the order of preference, among br, gzip and deflate; the responses are not compressed if empty")
+	flagSet.String(queryHTTPIPFamily, netutils.NetworkDual, "The IP family of the query's HTTP server, and of its gRPC server if both share the same port: dual accepts both IPv4 and IPv6 when the host is empty or a host name, tcp4 only IPv4 and tcp6 only IPv6")
+	flagSet.String(queryGRPCIPFamily, netutils.NetworkDual, "The IP family of the query's gRPC server: dual accepts both IPv4 and IPv6 when the host is empty or a host name, tcp4 only IPv4 and tcp6 only IPv6")
 	flagSet.String(queryBasePath, "/", "The base path for all HTTP routes, e.g. /jaeger; useful when running behind a reverse proxy. See https://github.com/jaegertracing/jaeger/blob/main/examples/reverse-proxy/README.md")
 	flagSet.String(queryStaticFiles, "", "The directory path override for the static assets for the UI")
+	flagSet.String(queryStaticFilesOverlay, "", "The directory path of custom static assets overlaid on those of the UI, e.g. for branding; its static/custom.css stylesheet, if any, is linked by the UI")
 	flagSet.Bool(queryLogStaticAssetsAccess, false, "Log when static assets are accessed (for debugging)")
 	flagSet.String(queryUIConfig, "", "The path to the UI configuration file in JSON format")
+	flagSet.Var(&config.StringSlice{}, queryTenantUIConfig, `The path to the UI configuration file of a tenant, served to the requests with its tenancy header, requires multi-tenancy.  Can be specified multiple times.  Format: "tenant=path"`)
 	flagSet.Bool(queryTokenPropagation, false, "Allow propagation of bearer token to be used by storage plugins")
-	flagSet.Duration(queryMaxClockSkewAdjust, 0, "The maximum delta by which span timestamps may be adjusted in the UI due to clock skew; set to 0s to disable clock skew adjustments")
+	flagSet.Duration(queryMaxClockSkewAdjust, 0, "The maximum delta by which span timestamps may be adjusted in the UI due to clock skew, either inferred from the parent spans or reported by the clock.offset_ms process tag; set to 0s to disable clock skew adjustments")
+	flagSet.String(queryAdjusters, strings.Join(querysvc.StandardAdjusterNames(), ","), "Comma-separated list of the adjusters applied to the traces before returning them, in order, among "+strings.Join(querysvc.AdjusterNames(), ", ")+"; the adjusters not listed are disabled")
 	flagSet.Bool(queryEnableTracing, false, "Enables emitting jaeger-query traces")
+	flagSet.String(queryAuthorizationRules, "", "The path to a JSON file of rules allowing the callers, by JWT claim or tenant, to query the services matching globs; all the services may be queried if empty")
+	flagSet.String(queryJWTJWKSURL, "", "The URL of the JSON Web Key Set verifying the signatures of the JWT bearer tokens whose claims the authorization rules, the saved searches and the audit log use; the tokens are not verified, and their claims ignored, if neither it nor the key file is set")
+	flagSet.String(queryJWTKeyFile, "", "The path to the PEM public key or certificate verifying the signatures of the JWT bearer tokens, instead of a JWKS URL")
+	flagSet.String(queryJWTIssuer, "", "The iss claim the JWT bearer tokens must have, if not empty")
+	flagSet.String(queryJWTAudience, "", "The aud claim the JWT bearer tokens must have, if not empty")
+	flagSet.Bool(queryTrustForwardedToken, false, "Accepts without verification the claims of the JWT of the X-Forwarded-Access-Token header of the HTTP requests sent by the trusted proxies, see --"+queryTrustedProxies+", which have verified the token")
+	flagSet.Bool(queryRecordWarnings, false, "Records in the background the warnings of the traces adjusted for the UI and GraphQL APIs or scored, e.g. clock skew or missing spans, in the span storage to find them with the /api/warnings endpoint; supported by the memory and Badger storage")
+	flagSet.String(querySamplingAdminToken, "", "The path to a file holding the bearer token required by the /api/sampling-strategies endpoints, which edit the sampling strategies served by the collectors; the endpoints are disabled if empty. Only supported by the memory and sqlite storages")
+	flagSet.Int(queryLimitsMaxSpans, 0, "The maximum number of spans returned by a trace search, the larger searches failing with HTTP 422; unbounded if 0")
+	flagSet.Int(queryLimitsMaxSubQueries, 0, "The maximum number of storage queries of a trace search, estimated as one for each of its tags, log fields and tag filters, its operation and its duration range, and one for each trace read; unbounded if 0")
+	flagSet.Duration(queryLimitsMaxTimeRange, 0, "The maximum time range searched by the queries of the traces and the dependencies; unbounded if 0")
+	flagSet.String(queryLimitsTenantsFile, "", `The path to a JSON file of the query limits overridden by tenant, requires multi-tenancy. Format: {"tenants": {"<tenant>": {"maxSpans": 10000, "maxSubQueries": 100, "maxTimeRange": "24h"}}}, a negative limit being unbounded`)
 	tlsGRPCFlagsConfig.AddFlags(flagSet)
 	tlsHTTPFlagsConfig.AddFlags(flagSet)
+	regression.AddFlags(flagSet)
+	audit.AddFlags(flagSet)
+	sharing.AddFlags(flagSet)
+	logs.AddFlags(flagSet)
+	export.AddFlags(flagSet)
+	graphqlapi.AddFlags(flagSet)
+	alerting.AddFlags(flagSet)
+	livetail.AddFlags(flagSet)
+	slo.AddFlags(flagSet)
 }
 
 // InitFromViper initializes QueryOptions with properties from viper
 func (qOpts *QueryOptions) InitFromViper(v *viper.Viper, logger *zap.Logger) (*QueryOptions, error) {
 	qOpts.HTTPHostPort = v.GetString(queryHTTPHostPort)
 	qOpts.GRPCHostPort = v.GetString(queryGRPCHostPort)
+	httpNetwork, err := netutils.TCPNetwork(v.GetString(queryHTTPIPFamily))
+	if err != nil {
+		return qOpts, fmt.Errorf("failed to process the HTTP server options: %w", err)
+	}
+	qOpts.HTTPNetwork = httpNetwork
+	grpcNetwork, err := netutils.TCPNetwork(v.GetString(queryGRPCIPFamily))
+	if err != nil {
+		return qOpts, fmt.Errorf("failed to process the gRPC server options: %w", err)
+	}
+	qOpts.GRPCNetwork = grpcNetwork
+	if qOpts.HTTPSocketPermissions, err = netutils.ParseSocketPermissions(v.GetString(queryHTTPSocketPermissions)); err != nil {
+		return qOpts, fmt.Errorf("failed to process the HTTP server options: %w", err)
+	}
+	if qOpts.GRPCSocketPermissions, err = netutils.ParseSocketPermissions(v.GetString(queryGRPCSocketPermissions)); err != nil {
+		return qOpts, fmt.Errorf("failed to process the gRPC server options: %w", err)
+	}
 	tlsGrpc, err := tlsGRPCFlagsConfig.InitFromViper(v)
 	if err != nil {
 		return qOpts, fmt.Errorf("failed to process gRPC TLS options: %w", err)
@@ -132,13 +277,34 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper, logger *zap.Logger) (*Q
 		return qOpts, fmt.Errorf("failed to process HTTP TLS options: %w", err)
 	}
 	qOpts.TLSHTTP = tlsHTTP
+	qOpts.HTTP3HostPort = ports.FormatHostPort(v.GetString(queryHTTP3HostPort))
+	if qOpts.HTTP3HostPort != "" && !qOpts.TLSHTTP.Enabled {
+		return qOpts, errors.New("failed to process the HTTP server options: the HTTP/3 listener requires TLS, see --query.http.tls.enabled")
+	}
+	qOpts.TrustedProxies = splitList(v.GetString(queryTrustedProxies))
+	if _, err := parseTrustedProxies(qOpts.TrustedProxies); err != nil {
+		return qOpts, fmt.Errorf("failed to process the HTTP server options: %w", err)
+	}
+	qOpts.AllowedHosts = splitList(v.GetString(queryAllowedHosts))
+	qOpts.Compression = splitList(v.GetString(queryCompression))
+	if err := validateCompressionEncodings(qOpts.Compression); err != nil {
+		return qOpts, fmt.Errorf("failed to process the HTTP server options: %w", err)
+	}
 	qOpts.BasePath = v.GetString(queryBasePath)
 	qOpts.StaticAssets.Path = v.GetString(queryStaticFiles)
+	qOpts.StaticAssets.OverlayPath = v.GetString(queryStaticFilesOverlay)
 	qOpts.StaticAssets.LogAccess = v.GetBool(queryLogStaticAssetsAccess)
 	qOpts.UIConfig = v.GetString(queryUIConfig)
 	qOpts.BearerTokenPropagation = v.GetBool(queryTokenPropagation)
 
 	qOpts.MaxClockSkewAdjust = v.GetDuration(queryMaxClockSkewAdjust)
+	qOpts.Adjusters = nil
+	if adjusters := v.GetString(queryAdjusters); adjusters != "" {
+		qOpts.Adjusters = strings.Split(adjusters, ",")
+	}
+	if err := querysvc.ValidateAdjusterNames(qOpts.Adjusters); err != nil {
+		return qOpts, fmt.Errorf("failed to process the adjusters: %w", err)
+	}
 	stringSlice := v.GetStringSlice(queryAdditionalHeaders)
 	headers, err := stringSliceAsHeader(stringSlice)
 	if err != nil {
@@ -147,22 +313,125 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper, logger *zap.Logger) (*Q
 		qOpts.AdditionalHeaders = headers
 	}
 	qOpts.Tenancy = tenancy.InitFromViper(v)
+	tenantUIConfigs, err := parseTenantUIConfigs(v.GetStringSlice(queryTenantUIConfig))
+	if err != nil {
+		return qOpts, fmt.Errorf("failed to process the tenant UI configs: %w", err)
+	}
+	if len(tenantUIConfigs) > 0 && !qOpts.Tenancy.Enabled {
+		return qOpts, errors.New("the tenant UI configs require multi-tenancy, see --multi-tenancy.enabled")
+	}
+	qOpts.TenantUIConfigs = tenantUIConfigs
 	qOpts.EnableTracing = v.GetBool(queryEnableTracing)
+	qOpts.RegressionDetection.InitFromViper(v)
+	qOpts.Audit.InitFromViper(v)
+	qOpts.TraceSharing.InitFromViper(v)
+	qOpts.Logs.InitFromViper(v)
+	qOpts.Export.InitFromViper(v)
+	qOpts.GraphQL.InitFromViper(v)
+	qOpts.Alerting.InitFromViper(v)
+	qOpts.LiveTail.InitFromViper(v)
+	qOpts.SLOs.InitFromViper(v)
+	qOpts.RecordWarnings = v.GetBool(queryRecordWarnings)
+	if rulesFile := v.GetString(queryAuthorizationRules); rulesFile != "" {
+		rules, err := querysvc.LoadAuthorizationRules(rulesFile)
+		if err != nil {
+			return qOpts, err
+		}
+		qOpts.AuthorizationRules = rules
+	}
+	qOpts.CostLimits = querysvc.CostLimits{
+		MaxSpans:      v.GetInt(queryLimitsMaxSpans),
+		MaxSubQueries: v.GetInt(queryLimitsMaxSubQueries),
+		MaxTimeRange:  v.GetDuration(queryLimitsMaxTimeRange),
+	}
+	qOpts.TenantCostLimits = nil
+	if limitsFile := v.GetString(queryLimitsTenantsFile); limitsFile != "" {
+		if !qOpts.Tenancy.Enabled {
+			return qOpts, errors.New("the tenant query limits require multi-tenancy, see --multi-tenancy.enabled")
+		}
+		limits, err := querysvc.LoadTenantCostLimits(limitsFile)
+		if err != nil {
+			return qOpts, err
+		}
+		qOpts.TenantCostLimits = limits
+	}
+	qOpts.JWT = bearertoken.VerifierOptions{
+		JWKSURL:  v.GetString(queryJWTJWKSURL),
+		KeyFile:  v.GetString(queryJWTKeyFile),
+		Issuer:   v.GetString(queryJWTIssuer),
+		Audience: v.GetString(queryJWTAudience),
+	}
+	if qOpts.JWT.JWKSURL != "" && qOpts.JWT.KeyFile != "" {
+		return qOpts, fmt.Errorf("--%s and --%s are mutually exclusive", queryJWTJWKSURL, queryJWTKeyFile)
+	}
+	qOpts.TrustForwardedToken = v.GetBool(queryTrustForwardedToken)
+	if qOpts.TrustForwardedToken && len(qOpts.TrustedProxies) == 0 {
+		return qOpts, fmt.Errorf("--%s requires the trusted proxies, see --%s", queryTrustForwardedToken, queryTrustedProxies)
+	}
+	if tokenFile := v.GetString(querySamplingAdminToken); tokenFile != "" {
+		token, err := loadAdminToken(tokenFile)
+		if err != nil {
+			return qOpts, err
+		}
+		qOpts.SamplingAdminToken = token
+	}
 	return qOpts, nil
 }
 
 // BuildQueryServiceOptions creates a QueryServiceOptions struct with appropriate adjusters and archive config
 func (qOpts *QueryOptions) BuildQueryServiceOptions(storageFactory storage.Factory, logger *zap.Logger) *querysvc.QueryServiceOptions {
-	opts := &querysvc.QueryServiceOptions{}
+	capabilities := storageFactory.Capabilities()
+	opts := &querysvc.QueryServiceOptions{StorageCapabilities: &capabilities}
 	if !opts.InitArchiveStorage(storageFactory, logger) {
 		logger.Info("Archive storage not initialized")
 	}
 
-	opts.Adjuster = adjuster.Sequence(querysvc.StandardAdjusters(qOpts.MaxClockSkewAdjust)...)
+	if qOpts.RecordWarnings && !opts.InitWarningStorage(storageFactory, logger) {
+		logger.Info("Warnings storage not initialized")
+	}
+
+	if !opts.InitMetadataStorage(storageFactory, logger) {
+		logger.Info("Service metadata storage not initialized")
+	}
+
+	if !opts.InitSavedSearchStorage(storageFactory, logger) {
+		logger.Info("Saved searches storage not initialized")
+	}
+
+	if qOpts.SamplingAdminToken != "" && !opts.InitSamplingStrategyStorage(storageFactory, logger) {
+		logger.Info("Sampling strategies storage not initialized")
+	}
+
+	adjusters, err := querysvc.NewAdjusters(qOpts.Adjusters, querysvc.AdjusterConfig{MaxClockSkewAdjust: qOpts.MaxClockSkewAdjust})
+	if err != nil {
+		// the adjusters are validated by InitFromViper
+		logger.Error("Invalid adjusters, using the standard ones", zap.Error(err))
+		adjusters = querysvc.StandardAdjusters(qOpts.MaxClockSkewAdjust)
+	}
+	opts.Adjuster = adjuster.Sequence(adjusters...)
+	if qOpts.AuthorizationRules != nil {
+		opts.Authorizer = querysvc.NewServiceAuthorizer(qOpts.AuthorizationRules)
+	}
+	if qOpts.CostLimits != (querysvc.CostLimits{}) || qOpts.TenantCostLimits != nil {
+		opts.CostLimiter = querysvc.NewCostLimiter(qOpts.CostLimits, qOpts.TenantCostLimits)
+	}
 
 	return opts
 }
 
+// loadAdminToken reads the bearer token of the admins from a file, ignoring the surrounding blank characters.
+func loadAdminToken(filename string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the sampling strategies admin token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("the sampling strategies admin token file %s is empty", filename)
+	}
+	return token, nil
+}
+
 // stringSliceAsHeader parses a slice of strings and returns a http.Header.
 // Each string in the slice is expected to be in the format "key: value"
 func stringSliceAsHeader(slice []string) (http.Header, error) {
@@ -182,3 +451,34 @@ func stringSliceAsHeader(slice []string) (http.Header, error) {
 
 	return http.Header(header), nil
 }
+
+// parseTenantUIConfigs parses the tenant=path UI configurations of the tenants.
+func parseTenantUIConfigs(slice []string) (map[string]string, error) {
+	if len(slice) == 0 {
+		return nil, nil
+	}
+	configs := make(map[string]string, len(slice))
+	for _, element := range slice {
+		tenant, path, ok := strings.Cut(element, "=")
+		tenant, path = strings.TrimSpace(tenant), strings.TrimSpace(path)
+		if !ok || tenant == "" || path == "" {
+			return nil, fmt.Errorf("invalid tenant UI config %q, must be tenant=path", element)
+		}
+		if _, ok := configs[tenant]; ok {
+			return nil, fmt.Errorf("duplicate UI config of the tenant %q", tenant)
+		}
+		configs[tenant] = path
+	}
+	return configs, nil
+}
+
+// splitList splits a comma-separated list of a flag, ignoring the blank elements.
+func splitList(list string) []string {
+	var elements []string
+	for _, element := range strings.Split(list, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
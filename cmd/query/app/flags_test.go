@@ -17,6 +17,8 @@ package app
 
 import (
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -25,8 +27,15 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 	"github.com/jaegertracing/jaeger/ports"
+	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/mocks"
 	spanstore_mocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
@@ -35,6 +44,7 @@ func TestQueryBuilderFlags(t *testing.T) {
 	v, command := config.Viperize(AddFlags)
 	command.ParseFlags([]string{
 		"--query.static-files=/dev/null",
+		"--query.static-files-overlay=/etc/jaeger/branding",
 		"--query.log-static-assets-access=true",
 		"--query.ui-config=some.json",
 		"--query.base-path=/jaeger",
@@ -47,6 +57,7 @@ func TestQueryBuilderFlags(t *testing.T) {
 	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
 	require.NoError(t, err)
 	assert.Equal(t, "/dev/null", qOpts.StaticAssets.Path)
+	assert.Equal(t, "/etc/jaeger/branding", qOpts.StaticAssets.OverlayPath)
 	assert.True(t, qOpts.StaticAssets.LogAccess)
 	assert.Equal(t, "some.json", qOpts.UIConfig)
 	assert.Equal(t, "/jaeger", qOpts.BasePath)
@@ -96,23 +107,31 @@ func TestStringSliceAsHeader(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// newMockFactory returns a storage factory mock with the given capabilities.
+func newMockFactory(capabilities storage.Capabilities) *mocks.Factory {
+	f := &mocks.Factory{}
+	f.On("Capabilities").Return(capabilities)
+	return f
+}
+
 func TestBuildQueryServiceOptions(t *testing.T) {
 	v, _ := config.Viperize(AddFlags)
 	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
 	require.NoError(t, err)
 	assert.NotNil(t, qOpts)
 
-	qSvcOpts := qOpts.BuildQueryServiceOptions(&mocks.Factory{}, zap.NewNop())
+	qSvcOpts := qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{TraceSearch: true}), zap.NewNop())
 	assert.NotNil(t, qSvcOpts)
 	assert.NotNil(t, qSvcOpts.Adjuster)
 	assert.Nil(t, qSvcOpts.ArchiveSpanReader)
 	assert.Nil(t, qSvcOpts.ArchiveSpanWriter)
+	assert.Equal(t, &storage.Capabilities{TraceSearch: true}, qSvcOpts.StorageCapabilities)
 
 	comboFactory := struct {
 		*mocks.Factory
 		*mocks.ArchiveFactory
 	}{
-		&mocks.Factory{},
+		newMockFactory(storage.Capabilities{}),
 		&mocks.ArchiveFactory{},
 	}
 
@@ -126,6 +145,250 @@ func TestBuildQueryServiceOptions(t *testing.T) {
 	assert.NotNil(t, qSvcOpts.ArchiveSpanWriter)
 }
 
+func TestQueryOptionsIPFamily(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", qOpts.HTTPNetwork)
+	assert.Equal(t, "tcp", qOpts.GRPCNetwork)
+
+	require.NoError(t, command.ParseFlags([]string{"--query.http-server.ip-family=tcp6", "--query.grpc-server.ip-family=tcp4"}))
+	qOpts, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "tcp6", qOpts.HTTPNetwork)
+	assert.Equal(t, "tcp4", qOpts.GRPCNetwork)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.grpc-server.ip-family=ipv4"}))
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `failed to process the gRPC server options: invalid IP family "ipv4"`)
+}
+
+func TestQueryOptionsSocketPermissions(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--query.http-server.host-port=unix:///var/run/jaeger/query-http.sock",
+		"--query.http-server.socket-permissions=0660",
+	}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///var/run/jaeger/query-http.sock", qOpts.HTTPHostPort)
+	assert.Equal(t, os.FileMode(0o660), qOpts.HTTPSocketPermissions)
+	assert.Equal(t, os.FileMode(0), qOpts.GRPCSocketPermissions)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.grpc-server.socket-permissions=rw"}))
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `failed to process the gRPC server options: invalid socket permissions "rw"`)
+}
+
+func TestQueryOptionsHTTP3(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--query.http-server.http3-host-port=16686",
+		"--query.http.tls.enabled=true",
+	}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, ":16686", qOpts.HTTP3HostPort)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.http-server.http3-host-port=:16686"}))
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.EqualError(t, err, "failed to process the HTTP server options: the HTTP/3 listener requires TLS, see --query.http.tls.enabled")
+}
+
+func TestQueryOptionsReverseProxy(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, qOpts.TrustedProxies)
+	assert.Empty(t, qOpts.AllowedHosts)
+	assert.Equal(t, []string{"gzip", "deflate"}, qOpts.Compression)
+
+	require.NoError(t, command.ParseFlags([]string{
+		"--query.http-server.trusted-proxies=10.0.0.0/8, 192.168.1.1",
+		"--query.http-server.allowed-hosts=jaeger.example.com,*.example.org",
+		"--query.http-server.compression=br,gzip",
+	}))
+	qOpts, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, qOpts.TrustedProxies)
+	assert.Equal(t, []string{"jaeger.example.com", "*.example.org"}, qOpts.AllowedHosts)
+	assert.Equal(t, []string{"br", "gzip"}, qOpts.Compression)
+
+	require.NoError(t, command.ParseFlags([]string{"--query.http-server.compression="}))
+	qOpts, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, qOpts.Compression)
+
+	for flag, expectedErr := range map[string]string{
+		"--query.http-server.trusted-proxies=proxy": `invalid trusted proxy address "proxy"`,
+		"--query.http-server.compression=zstd":      `unsupported compression "zstd"`,
+	} {
+		v, command := config.Viperize(AddFlags)
+		require.NoError(t, command.ParseFlags([]string{flag}))
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, expectedErr)
+	}
+}
+
+func TestQueryOptionsTenantUIConfigs(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--query.tenant-ui-config=acme=/etc/jaeger/acme.json",
+		"--query.tenant-ui-config=globex = /etc/jaeger/globex.json",
+	}))
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.EqualError(t, err, "the tenant UI configs require multi-tenancy, see --multi-tenancy.enabled")
+
+	v.Set("multi-tenancy.enabled", true)
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"acme":   "/etc/jaeger/acme.json",
+		"globex": "/etc/jaeger/globex.json",
+	}, qOpts.TenantUIConfigs)
+
+	for expectedErr, flags := range map[string][]string{
+		`invalid tenant UI config "acme", must be tenant=path`:       {"--query.tenant-ui-config=acme"},
+		`invalid tenant UI config "=acme.json", must be tenant=path`: {"--query.tenant-ui-config==acme.json"},
+		`duplicate UI config of the tenant "acme"`:                   {"--query.tenant-ui-config=acme=a.json", "--query.tenant-ui-config=acme=b.json"},
+	} {
+		v, command := config.Viperize(AddFlags)
+		require.NoError(t, command.ParseFlags(flags))
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, expectedErr)
+	}
+}
+
+func TestBuildQueryServiceOptionsAdjusters(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.adjusters=span-id-deduper,critical-path"}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"span-id-deduper", "critical-path"}, qOpts.Adjusters)
+
+	qSvcOpts := qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), zap.NewNop())
+	trace, err := qSvcOpts.Adjuster.Adjust(&model.Trace{Spans: []*model.Span{{SpanID: 1, Process: &model.Process{}}}})
+	require.NoError(t, err)
+	_, found := model.KeyValues(trace.Spans[0].Tags).FindByKey(adjuster.CriticalPathTagKey)
+	assert.True(t, found)
+
+	// the options built programmatically fall back to the standard adjusters
+	qOpts.Adjusters = []string{"unknown"}
+	logger, logBuffer := testutils.NewLogger()
+	qSvcOpts = qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), logger)
+	assert.NotNil(t, qSvcOpts.Adjuster)
+	assert.Contains(t, logBuffer.String(), "Invalid adjusters")
+}
+
+func TestQueryBuilderAdjustersFlags(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, querysvc.StandardAdjusterNames(), qOpts.Adjusters)
+
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.adjusters="}))
+	qOpts, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, qOpts.Adjusters)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.adjusters=clock-skew,unknown"}))
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `failed to process the adjusters: unknown adjuster "unknown"`)
+}
+
+func TestBuildQueryServiceOptionsRecordWarnings(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.warnings.record=true"}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, qOpts.RecordWarnings)
+
+	qSvcOpts := qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), zap.NewNop())
+	assert.Nil(t, qSvcOpts.WarningStore)
+
+	memoryFactory := memory.NewFactory()
+	require.NoError(t, memoryFactory.Initialize(metrics.NullFactory, zap.NewNop()))
+	qSvcOpts = qOpts.BuildQueryServiceOptions(memoryFactory, zap.NewNop())
+	assert.NotNil(t, qSvcOpts.WarningStore)
+}
+
+func TestBuildQueryServiceOptionsSamplingAdminToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0o600))
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.sampling-strategies.admin-token-file=" + tokenFile}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "secret", qOpts.SamplingAdminToken)
+
+	qSvcOpts := qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), zap.NewNop())
+	assert.Nil(t, qSvcOpts.StrategyStore)
+
+	memoryFactory := memory.NewFactory()
+	require.NoError(t, memoryFactory.Initialize(metrics.NullFactory, zap.NewNop()))
+	qSvcOpts = qOpts.BuildQueryServiceOptions(memoryFactory, zap.NewNop())
+	assert.NotNil(t, qSvcOpts.StrategyStore)
+}
+
+func TestQueryOptionsSamplingAdminTokenErrors(t *testing.T) {
+	emptyFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(emptyFile, []byte(" \n"), 0o600))
+	for file, expected := range map[string]string{
+		"/does/not/exist": "failed to read the sampling strategies admin token",
+		emptyFile:         "is empty",
+	} {
+		v, command := config.Viperize(AddFlags)
+		require.NoError(t, command.ParseFlags([]string{"--query.sampling-strategies.admin-token-file=" + file}))
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, expected)
+	}
+}
+
+func TestBuildQueryServiceOptionsCostLimits(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), zap.NewNop()).CostLimiter)
+
+	limitsFile := filepath.Join(t.TempDir(), "limits.json")
+	require.NoError(t, os.WriteFile(limitsFile, []byte(`{"tenants": {"acme": {"maxSpans": 100}}}`), 0o600))
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--query.limits.max-spans=10",
+		"--query.limits.max-sub-queries=20",
+		"--query.limits.max-time-range=24h",
+		"--query.limits.tenants-file=" + limitsFile,
+	}))
+	v.Set("multi-tenancy.enabled", true)
+	qOpts, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, querysvc.CostLimits{MaxSpans: 10, MaxSubQueries: 20, MaxTimeRange: 24 * time.Hour}, qOpts.CostLimits)
+	assert.Equal(t, map[string]querysvc.CostLimits{"acme": {MaxSpans: 100}}, qOpts.TenantCostLimits)
+
+	limiter := qOpts.BuildQueryServiceOptions(newMockFactory(storage.Capabilities{}), zap.NewNop()).CostLimiter
+	require.NotNil(t, limiter)
+	assert.Equal(t, querysvc.CostLimits{MaxSpans: 100, MaxSubQueries: 20, MaxTimeRange: 24 * time.Hour}, limiter.Limits("acme"))
+}
+
+func TestQueryOptionsCostLimitsErrors(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--query.limits.tenants-file=/does/not/exist"}))
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.EqualError(t, err, "the tenant query limits require multi-tenancy, see --multi-tenancy.enabled")
+
+	v.Set("multi-tenancy.enabled", true)
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "failed to read the tenant cost limits")
+}
+
 func TestQueryOptionsPortAllocationFromFlags(t *testing.T) {
 	flagPortCases := []struct {
 		name                 string
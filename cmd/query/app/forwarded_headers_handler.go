@@ -0,0 +1,128 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	forwardedForHeader   = "X-Forwarded-For"
+	forwardedProtoHeader = "X-Forwarded-Proto"
+	forwardedHostHeader  = "X-Forwarded-Host"
+)
+
+type trustedProxyContextKey struct{}
+
+// fromTrustedProxy returns whether the request of the context was sent by a trusted proxy.
+func fromTrustedProxy(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedProxyContextKey{}).(bool)
+	return trusted
+}
+
+// parseTrustedProxies parses the IP ranges of the trusted reverse proxies, in the CIDR notation
+// or as single IP addresses.
+func parseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy range %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy address %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// forwardedHeadersHandler applies the X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host
+// headers of the requests sent by the trusted proxies to their remote address, URL scheme and
+// host, so that the audit log records the clients and the links use the external URL. The headers
+// of the other requests are removed, since the clients could forge them.
+func forwardedHeadersHandler(h http.Handler, trustedProxies []netip.Prefix) http.Handler {
+	if len(trustedProxies) == 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isTrustedProxy(trustedProxies, remoteHost(r.RemoteAddr)) {
+			r.Header.Del(forwardedForHeader)
+			r.Header.Del(forwardedProtoHeader)
+			r.Header.Del(forwardedHostHeader)
+			h.ServeHTTP(w, r)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), trustedProxyContextKey{}, true))
+		if client := forwardedClient(r.Header.Values(forwardedForHeader), trustedProxies); client != "" {
+			r.RemoteAddr = client
+		}
+		if proto := strings.ToLower(lastForwardedValue(r.Header.Get(forwardedProtoHeader))); proto == "http" || proto == "https" {
+			r.URL.Scheme = proto
+		}
+		if host := lastForwardedValue(r.Header.Get(forwardedHostHeader)); host != "" {
+			r.Host = host
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClient returns the address of the client in the X-Forwarded-For headers: the last
+// address not of a trusted proxy, every proxy appending the address of its peer.
+func forwardedClient(headers []string, trustedProxies []netip.Prefix) string {
+	var addrs []string
+	for _, header := range headers {
+		for _, addr := range strings.Split(header, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	client := ""
+	for i := len(addrs) - 1; i >= 0; i-- {
+		client = addrs[i]
+		if !isTrustedProxy(trustedProxies, client) {
+			break
+		}
+	}
+	return client
+}
+
+// lastForwardedValue returns the value appended by the last proxy to the header.
+func lastForwardedValue(header string) string {
+	values := strings.Split(header, ",")
+	return strings.TrimSpace(values[len(values)-1])
+}
+
+func isTrustedProxy(trustedProxies []netip.Prefix, host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardedHeadersHandler(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "::1"})
+	require.NoError(t, err)
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		proto          string
+		host           string
+		expectedAddr   string
+		expectedScheme string
+		expectedHost   string
+		stripped       bool
+	}{
+		{
+			name:           "trusted proxy",
+			remoteAddr:     "10.1.2.3:4567",
+			forwardedFor:   []string{"203.0.113.7"},
+			proto:          "https",
+			host:           "jaeger.example.com",
+			expectedAddr:   "203.0.113.7",
+			expectedScheme: "https",
+			expectedHost:   "jaeger.example.com",
+		},
+		{
+			name:           "chain of proxies",
+			remoteAddr:     "[::1]:4567",
+			forwardedFor:   []string{"198.51.100.1, 203.0.113.7", "10.0.0.1"},
+			proto:          "HTTP, https",
+			host:           "internal, jaeger.example.com",
+			expectedAddr:   "203.0.113.7",
+			expectedScheme: "https",
+			expectedHost:   "jaeger.example.com",
+		},
+		{
+			name:         "only trusted proxies",
+			remoteAddr:   "10.1.2.3:4567",
+			forwardedFor: []string{"10.0.0.2, 10.0.0.1"},
+			proto:        "gopher",
+			expectedAddr: "10.0.0.2",
+			expectedHost: "example.com",
+		},
+		{
+			name:         "untrusted peer",
+			remoteAddr:   "203.0.113.7:4567",
+			forwardedFor: []string{"127.0.0.1"},
+			proto:        "https",
+			host:         "jaeger.example.com",
+			expectedAddr: "203.0.113.7:4567",
+			expectedHost: "example.com",
+			stripped:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var served *http.Request
+			handler := forwardedHeadersHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				served = r
+			}), trustedProxies)
+			request := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+			request.RemoteAddr = test.remoteAddr
+			for _, forwardedFor := range test.forwardedFor {
+				request.Header.Add(forwardedForHeader, forwardedFor)
+			}
+			request.Header.Set(forwardedProtoHeader, test.proto)
+			request.Header.Set(forwardedHostHeader, test.host)
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			require.NotNil(t, served)
+			assert.Equal(t, test.expectedAddr, served.RemoteAddr)
+			assert.Equal(t, test.expectedScheme, served.URL.Scheme)
+			assert.Equal(t, test.expectedHost, served.Host)
+			if test.stripped {
+				assert.Empty(t, served.Header.Values(forwardedForHeader))
+				assert.Empty(t, served.Header.Get(forwardedProtoHeader))
+				assert.Empty(t, served.Header.Get(forwardedHostHeader))
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := parseTrustedProxies([]string{"10.1.2.3/8", "192.168.0.1", "::ffff:172.16.0.1", "fd00::/8"})
+	require.NoError(t, err)
+	require.Len(t, prefixes, 4)
+	assert.Equal(t, "10.0.0.0/8", prefixes[0].String())
+	assert.Equal(t, "192.168.0.1/32", prefixes[1].String())
+	assert.Equal(t, "172.16.0.1/32", prefixes[2].String())
+	assert.Equal(t, "fd00::/8", prefixes[3].String())
+
+	_, err = parseTrustedProxies([]string{"10.0.0.0/33"})
+	require.ErrorContains(t, err, `invalid trusted proxy range "10.0.0.0/33"`)
+	_, err = parseTrustedProxies([]string{"proxy.local"})
+	require.ErrorContains(t, err, `invalid trusted proxy address "proxy.local"`)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package graphqlapi serves the traces, services, operations and dependency links with a
+// GraphQL API, so that the dashboards fetch only the fields they need, e.g.
+//
+//	{
+//	  traces(serviceName: "frontend", durationMin: "1s", limit: 10) {
+//	    traceID
+//	    duration
+//	    rootSpan { operationName children { serviceName operationName duration } }
+//	  }
+//	}
+//
+// The queries are sent to /api/graphql, either in the query parameters query, variables and
+// operationName of a GET request, or in the JSON body of a POST request with these fields.
+// The schema is available by introspection.
+package graphqlapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const (
+	routeGraphQL = "/api/graphql"
+
+	// maxRequestSize limits the size of the bodies of the POST requests.
+	maxRequestSize = 1 << 20
+)
+
+// request is the body of the POST requests, see https://graphql.org/learn/serving-over-http/.
+type request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// Handler serves the GraphQL API.
+type Handler struct {
+	schema     graphql.Schema
+	tenancyMgr *tenancy.Manager
+	logger     *zap.Logger
+}
+
+// NewHandler creates a Handler resolving the queries with the query service.
+func NewHandler(options Options, querySvc *querysvc.QueryService, tenancyMgr *tenancy.Manager, logger *zap.Logger) (*Handler, error) {
+	builder := &schemaBuilder{
+		querySvc:  querySvc,
+		maxTraces: options.MaxTraces,
+		now:       time.Now,
+	}
+	if builder.maxTraces <= 0 {
+		builder.maxTraces = defaultMaxTraces
+	}
+	return newHandler(builder, tenancyMgr, logger)
+}
+
+func newHandler(builder *schemaBuilder, tenancyMgr *tenancy.Manager, logger *zap.Logger) (*Handler, error) {
+	schema, err := builder.build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the GraphQL schema: %w", err)
+	}
+	return &Handler{
+		schema:     schema,
+		tenancyMgr: tenancyMgr,
+		logger:     logger,
+	}, nil
+}
+
+// RegisterRoutes registers the GraphQL endpoint into the provided mux.
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	var handler http.Handler = http.HandlerFunc(h.serveGraphQL)
+	if h.tenancyMgr.Enabled {
+		handler = tenancy.ExtractTenantHTTPHandler(h.tenancyMgr, handler)
+	}
+	router.Handle(routeGraphQL, handler).Methods(http.MethodGet, http.MethodPost)
+}
+
+func (h *Handler) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	req, err := parseRequest(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+	// the errors of the queries are returned in the result, with the data of the other fields
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Error("Failed to write the GraphQL response", zap.Error(err))
+	}
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req := &request{
+			Query:         q.Get("query"),
+			OperationName: q.Get("operationName"),
+		}
+		if variables := q.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, fmt.Errorf("malformed variables: %w", err)
+			}
+		}
+		return req, nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the request: %w", err)
+	}
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType == "application/graphql" {
+		return &request{Query: string(body)}, nil
+	}
+	req := &request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, fmt.Errorf("malformed request: %w", err)
+	}
+	return req, nil
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+)
+
+var (
+	testNow     = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	testTraceID = model.NewTraceID(0, 0xabc)
+)
+
+func testTrace() []*model.Span {
+	frontend := model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "web-1")})
+	backend := model.NewProcess("backend", nil)
+	start := testNow.Add(-time.Minute)
+	return []*model.Span{
+		{
+			TraceID:       testTraceID,
+			SpanID:        1,
+			OperationName: "GET /dispatch",
+			StartTime:     start,
+			Duration:      2 * time.Second,
+			Tags:          []model.KeyValue{model.String("span.kind", "server"), model.Int64("http.status_code", 200)},
+			Logs:          []model.Log{{Timestamp: start.Add(time.Millisecond), Fields: []model.KeyValue{model.String("event", "dispatch")}}},
+			Process:       frontend,
+		},
+		{
+			TraceID:       testTraceID,
+			SpanID:        2,
+			OperationName: "GET /customer",
+			References:    []model.SpanRef{model.NewChildOfRef(testTraceID, 1)},
+			StartTime:     start.Add(10 * time.Millisecond),
+			Duration:      time.Second,
+			Tags:          []model.KeyValue{model.String("span.kind", "client")},
+			Process:       frontend,
+		},
+		{
+			TraceID:       testTraceID,
+			SpanID:        3,
+			OperationName: "/customer",
+			References:    []model.SpanRef{model.NewChildOfRef(testTraceID, 2)},
+			StartTime:     start.Add(20 * time.Millisecond),
+			Duration:      900 * time.Millisecond,
+			Tags:          []model.KeyValue{model.String("span.kind", "server"), model.Bool("error", true)},
+			Process:       backend,
+		},
+	}
+}
+
+type testServer struct {
+	server *httptest.Server
+	store  *memory.Store
+}
+
+func initializeTestServer(t *testing.T, tenancyMgr *tenancy.Manager) *testServer {
+	store := memory.NewStore()
+	for _, span := range testTrace() {
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	querySvc := querysvc.NewQueryService(store, store, querysvc.QueryServiceOptions{})
+	h, err := newHandler(&schemaBuilder{
+		querySvc:  querySvc,
+		maxTraces: 10,
+		now:       func() time.Time { return testNow },
+	}, tenancyMgr, zap.NewNop())
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	h.RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return &testServer{server: server, store: store}
+}
+
+type response struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (s *testServer) post(t *testing.T, query string, variables map[string]any) response {
+	body, err := json.Marshal(request{Query: query, Variables: variables})
+	require.NoError(t, err)
+	res, err := http.Post(s.server.URL+routeGraphQL, "application/json", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	var resp response
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	return resp
+}
+
+func toJSON(t *testing.T, value any) string {
+	js, err := json.Marshal(value)
+	require.NoError(t, err)
+	return string(js)
+}
+
+func TestTrace(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	resp := s.post(t, `query($id: String!) {
+		trace(id: $id) {
+			traceID
+			spanCount
+			startTime
+			duration
+			services
+			rootSpan {
+				spanID
+				parentSpanID
+				operationName
+				process { serviceName tags { key value } }
+				tags(keys: ["http.status_code"]) { key type value }
+				logs { timestamp fields { key value } }
+				children {
+					operationName
+					children { serviceName operationName duration parent { spanID } }
+				}
+			}
+			spans(serviceName: "backend") {
+				references { refType spanID span { operationName } }
+			}
+		}
+	}`, map[string]any{"id": testTraceID.String()})
+	require.Empty(t, resp.Errors)
+
+	start := model.TimeAsEpochMicroseconds(testNow.Add(-time.Minute))
+	assert.JSONEq(t, `{
+		"trace": {
+			"traceID": "0000000000000abc",
+			"spanCount": 3,
+			"startTime": `+toJSON(t, start)+`,
+			"duration": 2000000,
+			"services": ["backend", "frontend"],
+			"rootSpan": {
+				"spanID": "0000000000000001",
+				"parentSpanID": null,
+				"operationName": "GET /dispatch",
+				"process": {"serviceName": "frontend", "tags": [{"key": "hostname", "value": "web-1"}]},
+				"tags": [{"key": "http.status_code", "type": "INT64", "value": "200"}],
+				"logs": [{"timestamp": `+toJSON(t, start+1000)+`, "fields": [{"key": "event", "value": "dispatch"}]}],
+				"children": [{
+					"operationName": "GET /customer",
+					"children": [{"serviceName": "backend", "operationName": "/customer", "duration": 900000, "parent": {"spanID": "0000000000000002"}}]
+				}]
+			},
+			"spans": [{
+				"references": [{"refType": "CHILD_OF", "spanID": "0000000000000002", "span": {"operationName": "GET /customer"}}]
+			}]
+		}
+	}`, toJSON(t, resp.Data))
+}
+
+func TestTraceNotFound(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	resp := s.post(t, `{ trace(id: "123") { traceID } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]any{"trace": nil}, resp.Data)
+
+	resp = s.post(t, `{ trace(id: "xyz") { traceID } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "malformed trace ID")
+}
+
+func TestTraces(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	resp := s.post(t, `{
+		traces(serviceName: "backend", tags: [{key: "error", value: "true"}], durationMin: "500ms", limit: 5) {
+			traceID
+			spans(operationName: "/customer") { spanID }
+		}
+	}`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"traces": [{"traceID": "0000000000000abc", "spans": [{"spanID": "0000000000000003"}]}]}`, toJSON(t, resp.Data))
+
+	resp = s.post(t, `{ traces(serviceName: "backend", startTimeMax: "2024-05-01T11:00:00Z") { traceID } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"traces": []}`, toJSON(t, resp.Data))
+}
+
+func TestTracesErrors(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	tests := []struct {
+		args string
+		err  string
+	}{
+		{args: `startTimeMin: "yesterday"`, err: "malformed startTimeMin"},
+		{args: `startTimeMax: "today"`, err: "malformed startTimeMax"},
+		{args: `durationMin: "long"`, err: "malformed durationMin"},
+		{args: `durationMax: "short"`, err: "malformed durationMax"},
+		{args: `durationMin: "2s", durationMax: "1s"`, err: "durationMax must be greater than durationMin"},
+		{args: `limit: 11`, err: "the limit must be between 1 and 10"},
+		{args: `limit: 0`, err: "the limit must be between 1 and 10"},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			resp := s.post(t, `{ traces(serviceName: "backend", `+test.args+`) { traceID } }`, nil)
+			require.Len(t, resp.Errors, 1)
+			assert.Contains(t, resp.Errors[0].Message, test.err)
+		})
+	}
+}
+
+func TestServices(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	resp := s.post(t, `{
+		services { name operations { name spanKind } }
+		operations(serviceName: "frontend", spanKind: "client") { name }
+	}`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{
+		"services": [
+			{"name": "backend", "operations": [{"name": "/customer", "spanKind": "server"}]},
+			{"name": "frontend", "operations": [{"name": "GET /customer", "spanKind": "client"}, {"name": "GET /dispatch", "spanKind": "server"}]}
+		],
+		"operations": [{"name": "GET /customer"}]
+	}`, toJSON(t, resp.Data))
+}
+
+func TestDependencies(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	resp := s.post(t, `{ dependencies(lookback: "1h") { parent child callCount source } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"dependencies": [{"parent": "frontend", "child": "backend", "callCount": 1, "source": null}]}`, toJSON(t, resp.Data))
+
+	resp = s.post(t, `{ dependencies(endTime: "2024-05-01T10:00:00Z") { parent } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"dependencies": []}`, toJSON(t, resp.Data))
+
+	resp = s.post(t, `{ dependencies(endTime: "now") { parent } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "malformed endTime")
+
+	resp = s.post(t, `{ dependencies(lookback: "a day") { parent } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "malformed lookback")
+}
+
+func TestGetAndGraphQLContentType(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+
+	q := url.Values{}
+	q.Set("query", `query($name: String!) { operations(serviceName: $name) { name } }`)
+	q.Set("variables", `{"name": "backend"}`)
+	res, err := http.Get(s.server.URL + routeGraphQL + "?" + q.Encode())
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var resp response
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	assert.JSONEq(t, `{"operations": [{"name": "/customer"}]}`, toJSON(t, resp.Data))
+
+	res, err = http.Post(s.server.URL+routeGraphQL, "application/graphql", strings.NewReader(`{ services { name } }`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	resp = response{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	assert.JSONEq(t, `{"services": [{"name": "backend"}, {"name": "frontend"}]}`, toJSON(t, resp.Data))
+}
+
+func TestBadRequests(t *testing.T) {
+	s := initializeTestServer(t, &tenancy.Manager{})
+	tests := []struct {
+		name   string
+		do     func() (*http.Response, error)
+		status int
+		body   string
+	}{
+		{
+			name: "malformed variables",
+			do: func() (*http.Response, error) {
+				return http.Get(s.server.URL + routeGraphQL + "?query=%7B%7D&variables=%7B")
+			},
+			status: http.StatusBadRequest,
+			body:   "malformed variables",
+		},
+		{
+			name:   "missing query",
+			do:     func() (*http.Response, error) { return http.Get(s.server.URL + routeGraphQL) },
+			status: http.StatusBadRequest,
+			body:   "missing query",
+		},
+		{
+			name: "malformed body",
+			do: func() (*http.Response, error) {
+				return http.Post(s.server.URL+routeGraphQL, "application/json", strings.NewReader("{"))
+			},
+			status: http.StatusBadRequest,
+			body:   "malformed request",
+		},
+		{
+			name: "body too large",
+			do: func() (*http.Response, error) {
+				return http.Post(s.server.URL+routeGraphQL, "application/graphql", strings.NewReader(strings.Repeat(" ", maxRequestSize+1)))
+			},
+			status: http.StatusBadRequest,
+			body:   "failed to read the request",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.do()
+			require.NoError(t, err)
+			defer res.Body.Close()
+			assert.Equal(t, test.status, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), test.body)
+		})
+	}
+}
+
+func TestTenancy(t *testing.T) {
+	tenancyMgr := tenancy.NewManager(&tenancy.Options{Enabled: true, Header: "x-tenant"})
+	s := initializeTestServer(t, tenancyMgr)
+
+	res, err := http.Post(s.server.URL+routeGraphQL, "application/graphql", strings.NewReader(`{ services { name } }`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPost, s.server.URL+routeGraphQL, strings.NewReader(`{ services { name } }`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/graphql")
+	req.Header.Set("x-tenant", "acme")
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var resp response
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	// the spans were written without a tenant
+	assert.JSONEq(t, `{"services": []}`, toJSON(t, resp.Data))
+}
+
+func TestNewHandler(t *testing.T) {
+	store := memory.NewStore()
+	h, err := NewHandler(Options{}, querysvc.NewQueryService(store, store, querysvc.QueryServiceOptions{}), &tenancy.Manager{}, zap.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
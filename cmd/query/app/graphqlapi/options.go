@@ -0,0 +1,39 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graphqlapi
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPrefix    = "query.graphql"
+	flagEnabled   = flagPrefix + ".enabled"
+	flagMaxTraces = flagPrefix + ".max-traces"
+
+	defaultMaxTraces = 100
+)
+
+// Options holds configuration for the GraphQL API.
+type Options struct {
+	// Enabled registers the GraphQL endpoint /api/graphql.
+	Enabled bool
+	// MaxTraces is the maximum number of traces returned by a search.
+	MaxTraces int
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Serve the traces, services, operations and dependency links with a GraphQL API at /api/graphql, returning only the fields selected by the queries")
+	flagSet.Int(flagMaxTraces, defaultMaxTraces, "The maximum number of traces returned by a search of the GraphQL API")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.MaxTraces = v.GetInt(flagMaxTraces)
+	return o
+}
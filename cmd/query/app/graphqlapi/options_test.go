@@ -0,0 +1,31 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graphqlapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.graphql.enabled=true",
+		"--query.graphql.max-traces=50",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{Enabled: true, MaxTraces: 50}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Equal(t, defaultMaxTraces, opts.MaxTraces)
+}
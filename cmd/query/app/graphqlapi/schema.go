@@ -0,0 +1,779 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	defaultTracesLimit          = 20
+	defaultTracesLookback       = 48 * time.Hour
+	defaultDependenciesLookback = 24 * time.Hour
+)
+
+// traceNode is the source of the Trace objects, indexing the spans for the nested queries.
+type traceNode struct {
+	trace    *model.Trace
+	spans    []*spanNode
+	byID     map[model.SpanID]*spanNode
+	children map[model.SpanID][]*spanNode
+}
+
+// spanNode is the source of the Span objects.
+type spanNode struct {
+	span  *model.Span
+	trace *traceNode
+}
+
+func newTraceNode(trace *model.Trace) *traceNode {
+	t := &traceNode{
+		trace:    trace,
+		spans:    make([]*spanNode, 0, len(trace.Spans)),
+		byID:     make(map[model.SpanID]*spanNode, len(trace.Spans)),
+		children: make(map[model.SpanID][]*spanNode),
+	}
+	for _, span := range trace.Spans {
+		s := &spanNode{span: span, trace: t}
+		t.spans = append(t.spans, s)
+		t.byID[span.SpanID] = s
+	}
+	for _, s := range t.spans {
+		if parentID := s.span.ParentSpanID(); parentID != 0 {
+			t.children[parentID] = append(t.children[parentID], s)
+		}
+	}
+	return t
+}
+
+// rootSpan returns the earliest span without a parent in the trace.
+func (t *traceNode) rootSpan() *spanNode {
+	var root *spanNode
+	for _, s := range t.spans {
+		if _, ok := t.byID[s.span.ParentSpanID()]; ok {
+			continue
+		}
+		if root == nil || s.span.StartTime.Before(root.span.StartTime) {
+			root = s
+		}
+	}
+	return root
+}
+
+func (t *traceNode) bounds() (time.Time, time.Time) {
+	var start, end time.Time
+	for _, s := range t.spans {
+		if start.IsZero() || s.span.StartTime.Before(start) {
+			start = s.span.StartTime
+		}
+		if spanEnd := s.span.StartTime.Add(s.span.Duration); spanEnd.After(end) {
+			end = spanEnd
+		}
+	}
+	return start, end
+}
+
+// operationNode is the source of the Operation objects.
+type operationNode struct {
+	name     string
+	spanKind string
+}
+
+// serviceNode is the source of the Service objects.
+type serviceNode string
+
+var longType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Long",
+	Description: "A 64-bit integer, e.g. a timestamp or a duration in microseconds.",
+	Serialize: func(value any) any {
+		return value
+	},
+	ParseValue: func(value any) any {
+		switch v := value.(type) {
+		case int:
+			return int64(v)
+		case int64:
+			return v
+		case float64:
+			return int64(v)
+		default:
+			return nil
+		}
+	},
+	ParseLiteral: func(valueAST ast.Value) any {
+		if v, ok := valueAST.(*ast.IntValue); ok {
+			if i, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return i
+			}
+		}
+		return nil
+	},
+})
+
+func micros(t time.Time) int64 {
+	return int64(model.TimeAsEpochMicroseconds(t))
+}
+
+func durationMicros(d time.Duration) int64 {
+	return int64(model.DurationAsMicroseconds(d))
+}
+
+func tagsOf(keyValues []model.KeyValue) []model.KeyValue {
+	if keyValues == nil {
+		return []model.KeyValue{}
+	}
+	return keyValues
+}
+
+// schemaBuilder builds the schema resolving the queries with the query service.
+type schemaBuilder struct {
+	querySvc  *querysvc.QueryService
+	maxTraces int
+	now       func() time.Time
+}
+
+func (b *schemaBuilder) build() (graphql.Schema, error) {
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Tag",
+		Description: "A tag of a span or of a process, or a field of a log.",
+		Fields: graphql.Fields{
+			"key": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(model.KeyValue).Key, nil
+				},
+			},
+			"type": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The type of the value: string, bool, int64, float64 or binary.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					kv := p.Source.(model.KeyValue)
+					return kv.VType.String(), nil
+				},
+			},
+			"value": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The value formatted as a string.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					kv := p.Source.(model.KeyValue)
+					return kv.AsString(), nil
+				},
+			},
+		},
+	})
+	tagsArgs := graphql.FieldConfigArgument{
+		"keys": &graphql.ArgumentConfig{
+			Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+			Description: "Returns only the tags with these keys.",
+		},
+	}
+	resolveTags := func(p graphql.ResolveParams, keyValues []model.KeyValue) []model.KeyValue {
+		keys, ok := p.Args["keys"].([]any)
+		if !ok {
+			return tagsOf(keyValues)
+		}
+		wanted := make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			wanted[key.(string)] = struct{}{}
+		}
+		tags := []model.KeyValue{}
+		for _, kv := range keyValues {
+			if _, ok := wanted[kv.Key]; ok {
+				tags = append(tags, kv)
+			}
+		}
+		return tags
+	}
+
+	processType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Process",
+		Fields: graphql.Fields{
+			"serviceName": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*model.Process).ServiceName, nil
+				},
+			},
+			"tags": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+				Args: tagsArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return resolveTags(p, p.Source.(*model.Process).Tags), nil
+				},
+			},
+		},
+	})
+
+	logType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Log",
+		Fields: graphql.Fields{
+			"timestamp": &graphql.Field{
+				Type:        graphql.NewNonNull(longType),
+				Description: "The time of the log in microseconds since the epoch.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return micros(p.Source.(model.Log).Timestamp), nil
+				},
+			},
+			"fields": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return tagsOf(p.Source.(model.Log).Fields), nil
+				},
+			},
+		},
+	})
+
+	var spanType *graphql.Object
+	referenceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Reference",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"refType": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "CHILD_OF or FOLLOWS_FROM.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*referenceNode).ref.RefType.String(), nil
+					},
+				},
+				"traceID": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*referenceNode).ref.TraceID.String(), nil
+					},
+				},
+				"spanID": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*referenceNode).ref.SpanID.String(), nil
+					},
+				},
+				"span": &graphql.Field{
+					Type:        spanType,
+					Description: "The referenced span, null if it is not in the trace.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						ref := p.Source.(*referenceNode)
+						if ref.ref.TraceID != ref.trace.trace.Spans[0].TraceID {
+							return nil, nil
+						}
+						return nullableSpan(ref.trace.byID[ref.ref.SpanID]), nil
+					},
+				},
+			}
+		}),
+	})
+
+	spanType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Span",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"traceID": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*spanNode).span.TraceID.String(), nil
+					},
+				},
+				"spanID": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*spanNode).span.SpanID.String(), nil
+					},
+				},
+				"parentSpanID": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						if parentID := p.Source.(*spanNode).span.ParentSpanID(); parentID != 0 {
+							return parentID.String(), nil
+						}
+						return nil, nil
+					},
+				},
+				"operationName": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*spanNode).span.OperationName, nil
+					},
+				},
+				"serviceName": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(*spanNode).span.Process.GetServiceName(), nil
+					},
+				},
+				"startTime": &graphql.Field{
+					Type:        graphql.NewNonNull(longType),
+					Description: "The start time of the span in microseconds since the epoch.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return micros(p.Source.(*spanNode).span.StartTime), nil
+					},
+				},
+				"duration": &graphql.Field{
+					Type:        graphql.NewNonNull(longType),
+					Description: "The duration of the span in microseconds.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return durationMicros(p.Source.(*spanNode).span.Duration), nil
+					},
+				},
+				"process": &graphql.Field{
+					Type: graphql.NewNonNull(processType),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						if process := p.Source.(*spanNode).span.Process; process != nil {
+							return process, nil
+						}
+						return &model.Process{}, nil
+					},
+				},
+				"tags": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+					Args: tagsArgs,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return resolveTags(p, p.Source.(*spanNode).span.Tags), nil
+					},
+				},
+				"logs": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(logType))),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						logs := p.Source.(*spanNode).span.Logs
+						if logs == nil {
+							return []model.Log{}, nil
+						}
+						return logs, nil
+					},
+				},
+				"references": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(referenceType))),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						s := p.Source.(*spanNode)
+						refs := make([]*referenceNode, 0, len(s.span.References))
+						for _, ref := range s.span.References {
+							refs = append(refs, &referenceNode{ref: ref, trace: s.trace})
+						}
+						return refs, nil
+					},
+				},
+				"parent": &graphql.Field{
+					Type:        spanType,
+					Description: "The parent span, null for a root span or if the parent is not in the trace.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						s := p.Source.(*spanNode)
+						parentID := s.span.ParentSpanID()
+						if parentID == 0 {
+							return nil, nil
+						}
+						return nullableSpan(s.trace.byID[parentID]), nil
+					},
+				},
+				"children": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(spanType))),
+					Description: "The spans whose parent is the span.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						s := p.Source.(*spanNode)
+						children := s.trace.children[s.span.SpanID]
+						if children == nil {
+							return []*spanNode{}, nil
+						}
+						return children, nil
+					},
+				},
+				"warnings": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						warnings := p.Source.(*spanNode).span.Warnings
+						if warnings == nil {
+							return []string{}, nil
+						}
+						return warnings, nil
+					},
+				},
+			}
+		}),
+	})
+
+	traceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Trace",
+		Fields: graphql.Fields{
+			"traceID": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*traceNode).trace.Spans[0].TraceID.String(), nil
+				},
+			},
+			"spanCount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return len(p.Source.(*traceNode).spans), nil
+				},
+			},
+			"startTime": &graphql.Field{
+				Type:        graphql.NewNonNull(longType),
+				Description: "The start time of the earliest span in microseconds since the epoch.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					start, _ := p.Source.(*traceNode).bounds()
+					return micros(start), nil
+				},
+			},
+			"duration": &graphql.Field{
+				Type:        graphql.NewNonNull(longType),
+				Description: "The time from the start of the earliest span to the end of the latest span in microseconds.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					start, end := p.Source.(*traceNode).bounds()
+					return durationMicros(end.Sub(start)), nil
+				},
+			},
+			"services": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					set := make(map[string]struct{})
+					for _, s := range p.Source.(*traceNode).spans {
+						set[s.span.Process.GetServiceName()] = struct{}{}
+					}
+					services := make([]string, 0, len(set))
+					for service := range set {
+						services = append(services, service)
+					}
+					sort.Strings(services)
+					return services, nil
+				},
+			},
+			"rootSpan": &graphql.Field{
+				Type:        spanType,
+				Description: "The earliest span of the trace without a parent in the trace.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return nullableSpan(p.Source.(*traceNode).rootSpan()), nil
+				},
+			},
+			"spans": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(spanType))),
+				Args: graphql.FieldConfigArgument{
+					"serviceName":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Returns only the spans of the service."},
+					"operationName": &graphql.ArgumentConfig{Type: graphql.String, Description: "Returns only the spans of the operation."},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					service, _ := p.Args["serviceName"].(string)
+					operation, _ := p.Args["operationName"].(string)
+					spans := []*spanNode{}
+					for _, s := range p.Source.(*traceNode).spans {
+						if (service == "" || s.span.Process.GetServiceName() == service) &&
+							(operation == "" || s.span.OperationName == operation) {
+							spans = append(spans, s)
+						}
+					}
+					return spans, nil
+				},
+			},
+		},
+	})
+
+	operationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Operation",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(operationNode).name, nil
+				},
+			},
+			"spanKind": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if kind := p.Source.(operationNode).spanKind; kind != "" {
+						return kind, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+	operationsArgs := graphql.FieldConfigArgument{
+		"spanKind": &graphql.ArgumentConfig{Type: graphql.String, Description: "Returns only the operations of the spans of this kind, e.g. server."},
+	}
+
+	serviceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Service",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return string(p.Source.(serviceNode)), nil
+				},
+			},
+			"operations": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(operationType))),
+				Args: operationsArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					spanKind, _ := p.Args["spanKind"].(string)
+					return b.operations(p, string(p.Source.(serviceNode)), spanKind)
+				},
+			},
+		},
+	})
+
+	dependencyLinkType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DependencyLink",
+		Fields: graphql.Fields{
+			"parent": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(model.DependencyLink).Parent, nil
+				},
+			},
+			"child": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(model.DependencyLink).Child, nil
+				},
+			},
+			"callCount": &graphql.Field{
+				Type: graphql.NewNonNull(longType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return int64(p.Source.(model.DependencyLink).CallCount), nil
+				},
+			},
+			"source": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if source := p.Source.(model.DependencyLink).Source; source != "" {
+						return source, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	tagFilterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TagFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"key":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"trace": &graphql.Field{
+				Type:        traceType,
+				Description: "The trace with the ID, null if it is not found.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: b.trace,
+			},
+			"traces": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(traceType))),
+				Description: "The traces with a span matching the search, of the last 48h by default.",
+				Args: graphql.FieldConfigArgument{
+					"serviceName":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"operationName": &graphql.ArgumentConfig{Type: graphql.String},
+					"tags":          &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(tagFilterType))},
+					"startTimeMin":  &graphql.ArgumentConfig{Type: graphql.String, Description: "The earliest start time of the span, in RFC 3339."},
+					"startTimeMax":  &graphql.ArgumentConfig{Type: graphql.String, Description: "The latest start time of the span, in RFC 3339."},
+					"durationMin":   &graphql.ArgumentConfig{Type: graphql.String, Description: "The minimum duration of the span, e.g. 100ms."},
+					"durationMax":   &graphql.ArgumentConfig{Type: graphql.String, Description: "The maximum duration of the span, e.g. 2s."},
+					"limit":         &graphql.ArgumentConfig{Type: graphql.Int, Description: "The maximum number of traces, 20 by default."},
+				},
+				Resolve: b.traces,
+			},
+			"services": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(serviceType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					services, err := b.querySvc.GetServices(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					sort.Strings(services)
+					nodes := make([]serviceNode, 0, len(services))
+					for _, service := range services {
+						nodes = append(nodes, serviceNode(service))
+					}
+					return nodes, nil
+				},
+			},
+			"operations": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(operationType))),
+				Args: graphql.FieldConfigArgument{
+					"serviceName": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"spanKind":    operationsArgs["spanKind"],
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					spanKind, _ := p.Args["spanKind"].(string)
+					return b.operations(p, p.Args["serviceName"].(string), spanKind)
+				},
+			},
+			"dependencies": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dependencyLinkType))),
+				Description: "The links between the services, of the last 24h by default.",
+				Args: graphql.FieldConfigArgument{
+					"endTime":  &graphql.ArgumentConfig{Type: graphql.String, Description: "The end of the period, in RFC 3339, now by default."},
+					"lookback": &graphql.ArgumentConfig{Type: graphql.String, Description: "The length of the period, e.g. 1h."},
+				},
+				Resolve: b.dependencies,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// referenceNode is the source of the Reference objects.
+type referenceNode struct {
+	ref   model.SpanRef
+	trace *traceNode
+}
+
+// nullableSpan avoids returning a typed nil for the nullable span fields.
+func nullableSpan(s *spanNode) any {
+	if s == nil {
+		return nil
+	}
+	return s
+}
+
+func (b *schemaBuilder) trace(p graphql.ResolveParams) (any, error) {
+	traceID, err := model.TraceIDFromString(p.Args["id"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("malformed trace ID: %w", err)
+	}
+	trace, err := b.querySvc.GetTrace(p.Context, traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if node := b.traceNode(p.Context, trace); node != nil {
+		return node, nil
+	}
+	return nil, nil
+}
+
+// traceNode adjusts the trace as in the UI, e.g. for the clock skews, the errors of the
+// adjusters not failing the query, and returns nil if it has no spans.
+func (b *schemaBuilder) traceNode(ctx context.Context, trace *model.Trace) *traceNode {
+	if adjusted, _ := b.querySvc.Adjust(trace); adjusted != nil {
+		trace = adjusted
+	}
+	b.querySvc.RecordWarnings(ctx, trace)
+	if len(trace.Spans) == 0 {
+		return nil
+	}
+	return newTraceNode(trace)
+}
+
+func (b *schemaBuilder) traces(p graphql.ResolveParams) (any, error) {
+	query := &spanstore.TraceQueryParameters{
+		ServiceName:  p.Args["serviceName"].(string),
+		Tags:         make(map[string]string),
+		StartTimeMax: b.now(),
+	}
+	query.OperationName, _ = p.Args["operationName"].(string)
+	if tags, ok := p.Args["tags"].([]any); ok {
+		for _, tag := range tags {
+			filter := tag.(map[string]any)
+			query.Tags[filter["key"].(string)] = filter["value"].(string)
+		}
+	}
+	var err error
+	if s, ok := p.Args["startTimeMax"].(string); ok {
+		if query.StartTimeMax, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return nil, fmt.Errorf("malformed startTimeMax: %w", err)
+		}
+	}
+	query.StartTimeMin = query.StartTimeMax.Add(-defaultTracesLookback)
+	if s, ok := p.Args["startTimeMin"].(string); ok {
+		if query.StartTimeMin, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return nil, fmt.Errorf("malformed startTimeMin: %w", err)
+		}
+	}
+	if s, ok := p.Args["durationMin"].(string); ok {
+		if query.DurationMin, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("malformed durationMin: %w", err)
+		}
+	}
+	if s, ok := p.Args["durationMax"].(string); ok {
+		if query.DurationMax, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("malformed durationMax: %w", err)
+		}
+	}
+	if query.DurationMin != 0 && query.DurationMax != 0 && query.DurationMax < query.DurationMin {
+		return nil, errors.New("durationMax must be greater than durationMin")
+	}
+	limit, ok := p.Args["limit"].(int)
+	if !ok {
+		limit = min(defaultTracesLimit, b.maxTraces)
+	}
+	if limit <= 0 || limit > b.maxTraces {
+		return nil, fmt.Errorf("the limit must be between 1 and %d", b.maxTraces)
+	}
+	query.NumTraces = limit
+
+	traces, err := b.querySvc.FindTraces(p.Context, query)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*traceNode, 0, len(traces))
+	for _, trace := range traces {
+		if node := b.traceNode(p.Context, trace); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (b *schemaBuilder) operations(p graphql.ResolveParams, service, spanKind string) (any, error) {
+	operations, err := b.querySvc.GetOperations(p.Context, spanstore.OperationQueryParameters{
+		ServiceName: service,
+		SpanKind:    spanKind,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].SpanKind < operations[j].SpanKind
+	})
+	nodes := make([]operationNode, 0, len(operations))
+	for _, operation := range operations {
+		nodes = append(nodes, operationNode{name: operation.Name, spanKind: operation.SpanKind})
+	}
+	return nodes, nil
+}
+
+func (b *schemaBuilder) dependencies(p graphql.ResolveParams) (any, error) {
+	endTime := b.now()
+	lookback := defaultDependenciesLookback
+	var err error
+	if s, ok := p.Args["endTime"].(string); ok {
+		if endTime, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return nil, fmt.Errorf("malformed endTime: %w", err)
+		}
+	}
+	if s, ok := p.Args["lookback"].(string); ok {
+		if lookback, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("malformed lookback: %w", err)
+		}
+	}
+	links, err := b.querySvc.GetDependencies(p.Context, endTime, lookback)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		return []model.DependencyLink{}, nil
+	}
+	return links, nil
+}
@@ -153,6 +153,12 @@ func (g *GRPCHandler) FindTraces(r *api_v2.FindTracesRequest, stream api_v2.Quer
 		NumTraces:     int(query.SearchDepth),
 	}
 	traces, err := g.queryService.FindTraces(stream.Context(), &queryParams)
+	if errors.Is(err, querysvc.ErrServiceNotAllowed) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, querysvc.ErrQueryCostExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
 	if err != nil {
 		g.logger.Error("failed when searching for traces", zap.Error(err))
 		return status.Errorf(codes.Internal, "failed when searching for traces: %v", err)
@@ -203,6 +209,9 @@ func (g *GRPCHandler) GetOperations(
 		ServiceName: r.Service,
 		SpanKind:    r.SpanKind,
 	})
+	if errors.Is(err, querysvc.ErrServiceNotAllowed) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
 	if err != nil {
 		g.logger.Error("failed to fetch operations", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to fetch operations: %v", err)
@@ -235,6 +244,9 @@ func (g *GRPCHandler) GetDependencies(ctx context.Context, r *api_v2.GetDependen
 	}
 
 	dependencies, err := g.queryService.GetDependencies(ctx, startTime, endTime.Sub(startTime))
+	if errors.Is(err, querysvc.ErrQueryCostExceeded) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
 	if err != nil {
 		g.logger.Error("failed to fetch dependencies", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to fetch dependencies: %v", err)
@@ -1187,3 +1187,22 @@ func TestNewGRPCHandlerWithEmptyOptions(t *testing.T) {
 	assert.NotNil(t, handler.tracer)
 	assert.NotNil(t, handler.nowFn)
 }
+
+func TestGetDependenciesCostLimitGRPC(t *testing.T) {
+	disabledReader, err := disabled.NewMetricsReader()
+	require.NoError(t, err)
+	q := querysvc.NewQueryService(
+		&spanstoremocks.Reader{},
+		&depsmocks.Reader{},
+		querysvc.QueryServiceOptions{
+			CostLimiter: querysvc.NewCostLimiter(querysvc.CostLimits{MaxTimeRange: time.Hour}, nil),
+		})
+	handler := NewGRPCHandler(q, disabledReader, GRPCHandlerOptions{})
+
+	endTime := time.Now()
+	_, err = handler.GetDependencies(context.Background(), &api_v2.GetDependenciesRequest{
+		StartTime: endTime.Add(-2 * time.Hour),
+		EndTime:   endTime,
+	})
+	assertGRPCError(t, err, codes.ResourceExhausted, "the query searches a time range of 2h0m0s, above the limit of 1h0m0s")
+}
@@ -16,6 +16,9 @@
 package app
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"sort"
 	"testing"
 	"time"
@@ -23,9 +26,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	ui "github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 )
 
 func TestDeduplicateDependencies(t *testing.T) {
@@ -359,3 +366,79 @@ func TestGetDependenciesLookbackParsingFailure(t *testing.T) {
 	err := getJSON(ts.server.URL+"/api/dependencies?endTs=1476374248550&service=testing&lookback=shazbot", &response)
 	require.Error(t, err)
 }
+
+func TestGetDependencyLatencies(t *testing.T) {
+	store := memory.NewStore()
+	traceID := model.NewTraceID(0, 1)
+	startTime := time.Unix(0, 1476374248550*millisToNanosMultiplier).Add(-time.Minute)
+	for _, span := range []*model.Span{
+		{
+			TraceID:   traceID,
+			SpanID:    model.NewSpanID(1),
+			Process:   &model.Process{ServiceName: "killer"},
+			StartTime: startTime,
+			Duration:  300 * time.Millisecond,
+		},
+		{
+			TraceID:    traceID,
+			SpanID:     model.NewSpanID(2),
+			References: []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+			Process:    &model.Process{ServiceName: "queen"},
+			StartTime:  startTime,
+			Duration:   200 * time.Millisecond,
+		},
+		{
+			TraceID:    traceID,
+			SpanID:     model.NewSpanID(3),
+			References: []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(2))},
+			Process:    &model.Process{ServiceName: "bishop"},
+			StartTime:  startTime,
+			Duration:   100 * time.Millisecond,
+		},
+	} {
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	qs := querysvc.NewQueryService(store, store, querysvc.QueryServiceOptions{})
+	r := NewRouter()
+	NewAPIHandler(qs, &tenancy.Manager{}, HandlerOptions.Logger(zap.NewNop())).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	var response struct {
+		Data  []ui.DependencyLatency `json:"data"`
+		Total int                    `json:"total"`
+	}
+	err := getJSON(server.URL+"/api/dependencies/latencies?endTs=1476374248550&service=bishop", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Total)
+	require.Len(t, response.Data, 1)
+	latency := response.Data[0]
+	assert.Equal(t, "queen", latency.Parent)
+	assert.Equal(t, "bishop", latency.Child)
+	assert.Equal(t, uint64(1), latency.CallCount)
+	assert.Equal(t, int64(1), latency.Latency.Count)
+	assert.Equal(t, uint64(100000), latency.Latency.P50)
+
+	err = getJSON(server.URL+"/api/dependencies/latencies?endTs=1476374248550", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Total)
+}
+
+func TestGetDependencyLatenciesFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	for _, test := range []struct {
+		query  string
+		status int
+	}{
+		{"?endTs=shazbot", http.StatusBadRequest},
+		{"?endTs=1476374248550&lookback=shazbot", http.StatusBadRequest},
+		{"?endTs=1476374248550", http.StatusNotImplemented},
+	} {
+		resp, err := http.Get(ts.server.URL + "/api/dependencies/latencies" + test.query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, test.status, resp.StatusCode, test.query)
+	}
+}
@@ -20,7 +20,14 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/alerting"
+	"github.com/jaegertracing/jaeger/cmd/query/app/export"
+	"github.com/jaegertracing/jaeger/cmd/query/app/livetail"
+	"github.com/jaegertracing/jaeger/cmd/query/app/logs"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
+	"github.com/jaegertracing/jaeger/cmd/query/app/sharing"
+	"github.com/jaegertracing/jaeger/cmd/query/app/slo"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
 )
 
@@ -74,3 +81,60 @@ func (handlerOptions) MetricsQueryService(mqs querysvc.MetricsQueryService) Hand
 		apiHandler.metricsQueryService = mqs
 	}
 }
+
+// TraceSharing creates a HandlerOption that enables the share tokens of single traces.
+func (handlerOptions) TraceSharing(signer *sharing.Signer) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.traceSharing = signer
+	}
+}
+
+// AlertingRules creates a HandlerOption that enables the API managing the alerting rules.
+func (handlerOptions) AlertingRules(manager *alerting.Manager) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.alerting = manager
+	}
+}
+
+// LiveTail creates a HandlerOption that enables the WebSocket API streaming the new traces.
+func (handlerOptions) LiveTail(tailer *livetail.Tailer) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.liveTail = tailer
+	}
+}
+
+// SLOs creates a HandlerOption that enables the API managing the SLOs and computing their status.
+func (handlerOptions) SLOs(tracker *slo.Tracker) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.slos = tracker
+	}
+}
+
+// Regressions creates a HandlerOption that initializes the store of detected regressions.
+func (handlerOptions) Regressions(store *regression.Store) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.regressions = store
+	}
+}
+
+// TraceExporter creates a HandlerOption that enables the API streaming the traces matching a query.
+func (handlerOptions) TraceExporter(exporter *export.Exporter) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.exporter = exporter
+	}
+}
+
+// LogCorrelator creates a HandlerOption that enables the API returning the logs of the traces.
+func (handlerOptions) LogCorrelator(correlator *logs.Correlator) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.logCorrelator = correlator
+	}
+}
+
+// SamplingAdminToken creates a HandlerOption that enables the API editing the sampling strategies,
+// restricted to the requests with the bearer token.
+func (handlerOptions) SamplingAdminToken(token string) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.samplingAdminToken = token
+	}
+}
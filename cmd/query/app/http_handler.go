@@ -17,41 +17,78 @@ package app
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/alerting"
+	"github.com/jaegertracing/jaeger/cmd/query/app/export"
+	"github.com/jaegertracing/jaeger/cmd/query/app/livetail"
+	"github.com/jaegertracing/jaeger/cmd/query/app/logs"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
+	"github.com/jaegertracing/jaeger/cmd/query/app/sharing"
+	"github.com/jaegertracing/jaeger/cmd/query/app/slo"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
 	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
 	ui "github.com/jaegertracing/jaeger/model/json"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	model2otel "github.com/jaegertracing/jaeger/pkg/otlptranslator"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/plugin/metrics/disabled"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
 	"github.com/jaegertracing/jaeger/storage/metricsstore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 const (
 	traceIDParam          = "traceID"
+	otherTraceIDParam     = "otherTraceID"
 	endTsParam            = "endTs"
 	lookbackParam         = "lookback"
 	stepParam             = "step"
 	rateParam             = "ratePer"
 	quantileParam         = "quantile"
 	groupByOperationParam = "groupByOperation"
+	analysisParam         = "analysis"
+	shareTokenParam       = "token"
+	ttlParam              = "ttl"
+	formatParam           = "format"
+	savedSearchNameParam  = "name"
+	ruleNameParam         = "rule"
+	sloNameParam          = "slo"
+	ownerParam            = "owner"
+
+	criticalPathAnalysis = "critical_path"
+
+	exportFormatNDJSON    = "ndjson"
+	exportFormatOTLPProto = "otlp_proto"
+	// exportErrorTrailer reports the errors ending an export after its first trace was written.
+	exportErrorTrailer = "X-Export-Error"
 
 	defaultAPIPrefix  = "api"
 	prettyPrintIndent = "    "
@@ -85,6 +122,14 @@ func NewRouter() *mux.Router {
 type APIHandler struct {
 	queryService        *querysvc.QueryService
 	metricsQueryService querysvc.MetricsQueryService
+	regressions         *regression.Store
+	alerting            *alerting.Manager
+	slos                *slo.Tracker
+	traceSharing        *sharing.Signer
+	logCorrelator       *logs.Correlator
+	exporter            *export.Exporter
+	liveTail            *livetail.Tailer
+	samplingAdminToken  string
 	queryParser         queryParser
 	tenancyMgr          *tenancy.Manager
 	basePath            string
@@ -121,7 +166,11 @@ func NewAPIHandler(queryService *querysvc.QueryService, tm *tenancy.Manager, opt
 
 // RegisterRoutes registers routes for this handler on the given router
 func (aH *APIHandler) RegisterRoutes(router *mux.Router) {
+	// registered before the traces by ID, whose route it matches
+	aH.handleFunc(router, aH.getRecentTraces, "/traces/recent").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.getTrace, "/traces/{%s}", traceIDParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.compareTraces, "/traces/{%s}/diff/{%s}", traceIDParam, otherTraceIDParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getTraceLogs, "/traces/{%s}/logs", traceIDParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.archiveTrace, "/archive/{%s}", traceIDParam).Methods(http.MethodPost)
 	aH.handleFunc(router, aH.search, "/traces").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.getServices, "/services").Methods(http.MethodGet)
@@ -131,10 +180,48 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router) {
 	aH.handleFunc(router, aH.getOperationsLegacy, "/services/{%s}/operations", serviceParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.transformOTLP, "/transform").Methods(http.MethodPost)
 	aH.handleFunc(router, aH.dependencies, "/dependencies").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.dependencyLatencies, "/dependencies/latencies").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.durations, "/durations").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.findExemplarTraces, "/exemplars/traces").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.findCallPaths, "/call-paths").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.findQualityScores, "/quality/scores").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.exportTraces, "/export/traces").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getRegressions, "/regressions").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getWarnings, "/warnings").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.findServiceMetadata, "/service-metadata").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getServiceMetadata, "/services/{%s}/metadata", serviceParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.putServiceMetadata, "/services/{%s}/metadata", serviceParam).Methods(http.MethodPut)
+	aH.handleFunc(router, aH.deleteServiceMetadata, "/services/{%s}/metadata", serviceParam).Methods(http.MethodDelete)
+	aH.handleFunc(router, aH.findSavedSearches, "/saved-searches").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getSavedSearch, "/saved-searches/{%s}", savedSearchNameParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.putSavedSearch, "/saved-searches/{%s}", savedSearchNameParam).Methods(http.MethodPut)
+	aH.handleFunc(router, aH.deleteSavedSearch, "/saved-searches/{%s}", savedSearchNameParam).Methods(http.MethodDelete)
+	aH.handleFunc(router, aH.findAlertingRules, "/alerting/rules").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getAlertingRule, "/alerting/rules/{%s}", ruleNameParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.putAlertingRule, "/alerting/rules/{%s}", ruleNameParam).Methods(http.MethodPut)
+	aH.handleFunc(router, aH.deleteAlertingRule, "/alerting/rules/{%s}", ruleNameParam).Methods(http.MethodDelete)
+	aH.handleFunc(router, aH.findSLOs, "/slos").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getSLO, "/slos/{%s}", sloNameParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.putSLO, "/slos/{%s}", sloNameParam).Methods(http.MethodPut)
+	aH.handleFunc(router, aH.deleteSLO, "/slos/{%s}", sloNameParam).Methods(http.MethodDelete)
+	aH.handleFunc(router, aH.tailTraces, "/live-tail").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getSLOStatus, "/slos/{%s}/status", sloNameParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.latencies, "/metrics/latencies").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.calls, "/metrics/calls").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.errors, "/metrics/errors").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.minStep, "/metrics/minstep").Methods(http.MethodGet)
+	if aH.traceSharing != nil {
+		aH.handleFunc(router, aH.shareTrace, "/traces/{%s}/share", traceIDParam).Methods(http.MethodPost)
+		// the token replaces the tenancy header and the authorization of the services
+		aH.handleRoute(router, aH.verifyShareToken(http.HandlerFunc(aH.getSharedTrace)), "/shared-traces/{%s}", shareTokenParam).Methods(http.MethodGet)
+	}
+	if aH.samplingAdminToken != "" {
+		// the strategies are served to the SDKs of all the tenants
+		aH.handleRoute(router, aH.verifySamplingAdmin(aH.findSamplingStrategies), "/sampling-strategies").Methods(http.MethodGet)
+		aH.handleRoute(router, aH.verifySamplingAdmin(aH.getSamplingStrategy), "/sampling-strategies/{%s}", serviceParam).Methods(http.MethodGet)
+		aH.handleRoute(router, aH.verifySamplingAdmin(aH.putSamplingStrategy), "/sampling-strategies/{%s}", serviceParam).Methods(http.MethodPut)
+		aH.handleRoute(router, aH.verifySamplingAdmin(aH.deleteSamplingStrategy), "/sampling-strategies/{%s}", serviceParam).Methods(http.MethodDelete)
+	}
 }
 
 func (aH *APIHandler) handleFunc(
@@ -143,11 +230,20 @@ func (aH *APIHandler) handleFunc(
 	routeFmt string,
 	args ...any,
 ) *mux.Route {
-	route := aH.formatRoute(routeFmt, args...)
 	var handler http.Handler = http.HandlerFunc(f)
 	if aH.tenancyMgr.Enabled {
 		handler = tenancy.ExtractTenantHTTPHandler(aH.tenancyMgr, handler)
 	}
+	return aH.handleRoute(router, handler, routeFmt, args...)
+}
+
+func (aH *APIHandler) handleRoute(
+	router *mux.Router,
+	handler http.Handler,
+	routeFmt string,
+	args ...any,
+) *mux.Route {
+	route := aH.formatRoute(routeFmt, args...)
 	traceMiddleware := otelhttp.NewHandler(
 		otelhttp.WithRouteTag(route, traceResponseHandler(handler)),
 		route,
@@ -207,7 +303,7 @@ func (aH *APIHandler) transformOTLP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var uiErrors []structuredError
-	structuredRes := aH.tracesToResponse(traces, false, uiErrors)
+	structuredRes := aH.tracesToResponse(r.Context(), traces, false, nil, uiErrors)
 	aH.writeJSON(w, r, structuredRes)
 }
 
@@ -218,12 +314,11 @@ func (aH *APIHandler) getOperations(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	spanKind := r.FormValue(spanKindParam)
-	operations, err := aH.queryService.GetOperations(
-		r.Context(),
-		spanstore.OperationQueryParameters{ServiceName: service, SpanKind: spanKind},
-	)
-
+	query, err := aH.queryParser.parseOperationsQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	operations, err := aH.queryService.GetOperations(r.Context(), query)
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
@@ -235,8 +330,10 @@ func (aH *APIHandler) getOperations(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	structuredRes := structuredResponse{
-		Data:  data,
-		Total: len(operations),
+		Data:   data,
+		Total:  len(operations),
+		Limit:  query.Limit,
+		Offset: query.Offset,
 	}
 	aH.writeJSON(w, r, &structuredRes)
 }
@@ -246,6 +343,10 @@ func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
 	if aH.handleError(w, err, http.StatusBadRequest) {
 		return
 	}
+	analysis, err := parseAnalysis(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
 
 	var uiErrors []structuredError
 	var tracesFromStorage []*model.Trace
@@ -254,21 +355,122 @@ func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
 		if aH.handleError(w, err, http.StatusInternalServerError) {
 			return
 		}
+	} else if tQuery.traceIDPrefix != "" {
+		tracesFromStorage, err = aH.queryService.FindTracesByIDPrefix(r.Context(), &spanstore.TraceIDPrefixQueryParameters{
+			Prefix:       tQuery.traceIDPrefix,
+			StartTimeMin: tQuery.StartTimeMin,
+			StartTimeMax: tQuery.StartTimeMax,
+			NumTraces:    tQuery.NumTraces,
+		})
+		if errors.Is(err, spanstore.ErrTraceIDPrefixNotSupported) {
+			aH.handleError(w, err, http.StatusNotImplemented)
+			return
+		}
+		if aH.handleError(w, err, http.StatusInternalServerError) {
+			return
+		}
 	} else {
 		tracesFromStorage, err = aH.queryService.FindTraces(r.Context(), &tQuery.TraceQueryParameters)
+		if errors.Is(err, spanstore.ErrTagFiltersNotSupported) || errors.Is(err, spanstore.ErrLogFieldsNotSupported) {
+			aH.handleError(w, err, http.StatusNotImplemented)
+			return
+		}
 		if aH.handleError(w, err, http.StatusInternalServerError) {
 			return
 		}
 	}
 
-	structuredRes := aH.tracesToResponse(tracesFromStorage, true, uiErrors)
+	structuredRes := aH.tracesToResponse(r.Context(), tracesFromStorage, true, analysis, uiErrors)
+	aH.writeJSON(w, r, structuredRes)
+}
+
+// getRecentTraces implements the REST API /traces/recent returning the most recent traces processed
+// by the collector of the all-in-one, without querying the span storage, optionally of a service.
+func (aH *APIHandler) getRecentTraces(w http.ResponseWriter, r *http.Request) {
+	limit := defaultRecentTracesLimit
+	if limitParam := r.FormValue(limitParam); limitParam != "" {
+		limitParsed, err := strconv.Atoi(limitParam)
+		if err == nil && limitParsed < 0 {
+			err = errors.New("the limit must not be negative")
+		}
+		if aH.handleError(w, err, http.StatusBadRequest) {
+			return
+		}
+		limit = limitParsed
+	}
+	traces, err := aH.queryService.GetRecentTraces(r.Context(), r.FormValue(serviceParam), limit)
+	if errors.Is(err, querysvc.ErrRecentTracesNotConfigured) {
+		aH.handleError(w, err, http.StatusNotImplemented)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := aH.tracesToResponse(r.Context(), traces, true, nil, nil)
 	aH.writeJSON(w, r, structuredRes)
 }
 
-func (aH *APIHandler) tracesToResponse(traces []*model.Trace, adjust bool, uiErrors []structuredError) *structuredResponse {
+// liveTailUpgrader upgrades the connections of the live tail to WebSocket, only accepting the
+// requests of the pages of the same origin.
+var liveTailUpgrader = websocket.Upgrader{}
+
+// tailTraces implements the WebSocket API /live-tail streaming the new traces matching the
+// query of the search API, one JSON message per trace in the format of the search results.
+func (aH *APIHandler) tailTraces(w http.ResponseWriter, r *http.Request) {
+	if aH.liveTail == nil {
+		aH.handleError(w, errLiveTailDisabled, http.StatusNotImplemented)
+		return
+	}
+	tQuery, err := aH.queryParser.parseTraceQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	if len(tQuery.traceIDs) > 0 || tQuery.traceIDPrefix != "" {
+		aH.handleError(w, errors.New("the live tail does not support the trace IDs"), http.StatusBadRequest)
+		return
+	}
+	// the upgrader reports its errors to the client
+	conn, err := liveTailUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	// the messages of the client are discarded, reading them detects the closing of the connection
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = aH.liveTail.Tail(ctx, tQuery.TraceQueryParameters, func(trace *model.Trace) error {
+		return conn.WriteJSON(aH.tracesToResponse(ctx, []*model.Trace{trace}, true, nil, nil))
+	})
+	switch {
+	case errors.Is(err, livetail.ErrTooManySubscribers):
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()))
+	case err != nil:
+		aH.logger.Debug("the live tail was interrupted", zap.Error(err))
+	default:
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}
+}
+
+func (aH *APIHandler) tracesToResponse(
+	ctx context.Context,
+	traces []*model.Trace,
+	adjust bool,
+	analysis adjuster.Adjuster,
+	uiErrors []structuredError,
+) *structuredResponse {
 	uiTraces := make([]*ui.Trace, len(traces))
 	for i, v := range traces {
-		uiTrace, uiErr := aH.convertModelToUI(v, adjust)
+		uiTrace, uiErr := aH.convertModelToUI(ctx, v, adjust, analysis)
 		if uiErr != nil {
 			uiErrors = append(uiErrors, *uiErr)
 		}
@@ -281,8 +483,13 @@ func (aH *APIHandler) tracesToResponse(traces []*model.Trace, adjust bool, uiErr
 	}
 }
 
+// tracesByIDs reads the traces by their IDs, one storage query each.
 func (aH *APIHandler) tracesByIDs(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, []structuredError, error) {
+	if err := aH.queryService.CheckCost(ctx, querysvc.QueryCost{SubQueries: len(traceIDs)}); err != nil {
+		return nil, nil, err
+	}
 	var traceErrors []structuredError
+	var spans int
 	retMe := make([]*model.Trace, 0, len(traceIDs))
 	for _, traceID := range traceIDs {
 		if trace, err := aH.queryService.GetTrace(ctx, traceID); err != nil {
@@ -294,6 +501,11 @@ func (aH *APIHandler) tracesByIDs(ctx context.Context, traceIDs []model.TraceID)
 				TraceID: ui.TraceID(traceID.String()),
 			})
 		} else {
+			// the reads stop as soon as the spans exceed the cost limits
+			spans += len(trace.Spans)
+			if err := aH.queryService.CheckCost(ctx, querysvc.QueryCost{Spans: spans}); err != nil {
+				return nil, nil, err
+			}
 			retMe = append(retMe, trace)
 		}
 	}
@@ -319,191 +531,1329 @@ func (aH *APIHandler) dependencies(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (aH *APIHandler) latencies(w http.ResponseWriter, r *http.Request) {
-	q, err := strconv.ParseFloat(r.FormValue(quantileParam), 64)
-	if err != nil {
-		aH.handleError(w, newParseError(err, quantileParam), http.StatusBadRequest)
+// dependencyLatencies implements the REST API /dependencies/latencies returning the dependency
+// links with the distributions of the durations of their calls, optionally of a service.
+func (aH *APIHandler) dependencyLatencies(w http.ResponseWriter, r *http.Request) {
+	dqp, err := aH.queryParser.parseDependenciesQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
 		return
 	}
-	aH.metrics(w, r, func(ctx context.Context, baseParams metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error) {
-		return aH.metricsQueryService.GetLatencies(ctx, &metricsstore.LatenciesQueryParameters{
-			BaseQueryParameters: baseParams,
-			Quantile:            q,
+	service := r.FormValue(serviceParam)
+
+	latencies, err := aH.queryService.GetDependencyLatencies(r.Context(), dqp.endTs, dqp.lookback)
+	if errors.Is(err, dependencystore.ErrDependencyLatenciesNotSupported) {
+		aH.handleError(w, err, http.StatusNotImplemented)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	uiLatencies := make([]ui.DependencyLatency, 0, len(latencies))
+	for _, latency := range latencies {
+		if service != "" && latency.Parent != service && latency.Child != service {
+			continue
+		}
+		uiLatencies = append(uiLatencies, ui.DependencyLatency{
+			Parent:    latency.Parent,
+			Child:     latency.Child,
+			CallCount: latency.CallCount,
+			Latency:   toUILatencyDistribution(latency.Latency),
 		})
-	})
+	}
+	structuredRes := structuredResponse{
+		Data:  uiLatencies,
+		Total: len(uiLatencies),
+	}
+	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (aH *APIHandler) calls(w http.ResponseWriter, r *http.Request) {
-	aH.metrics(w, r, func(ctx context.Context, baseParams metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error) {
-		return aH.metricsQueryService.GetCallRates(ctx, &metricsstore.CallRateQueryParameters{
-			BaseQueryParameters: baseParams,
-		})
-	})
+func (aH *APIHandler) durations(w http.ResponseWriter, r *http.Request) {
+	query, err := aH.queryParser.parseLatencyQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	err = aH.queryService.CheckCost(r.Context(), querysvc.QueryCost{TimeRange: query.StartTimeMax.Sub(query.StartTimeMin)})
+	if aH.handleError(w, err, http.StatusUnprocessableEntity) {
+		return
+	}
+	dist, err := aH.queryService.GetLatencyDistribution(r.Context(), query)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: toUILatencyDistribution(dist),
+	}
+	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (aH *APIHandler) errors(w http.ResponseWriter, r *http.Request) {
-	aH.metrics(w, r, func(ctx context.Context, baseParams metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error) {
-		return aH.metricsQueryService.GetErrorRates(ctx, &metricsstore.ErrorRateQueryParameters{
-			BaseQueryParameters: baseParams,
-		})
-	})
+func toUILatencyDistribution(dist *spanstore.LatencyDistribution) ui.LatencyDistribution {
+	bounds := make([]uint64, len(dist.BucketBounds))
+	for i, bound := range dist.BucketBounds {
+		bounds[i] = model.DurationAsMicroseconds(bound)
+	}
+	return ui.LatencyDistribution{
+		Count:        dist.Count,
+		P50:          model.DurationAsMicroseconds(dist.P50),
+		P95:          model.DurationAsMicroseconds(dist.P95),
+		P99:          model.DurationAsMicroseconds(dist.P99),
+		BucketBounds: bounds,
+		BucketCounts: dist.BucketCounts,
+	}
 }
 
-func (aH *APIHandler) minStep(w http.ResponseWriter, r *http.Request) {
-	minStep, err := aH.metricsQueryService.GetMinStepDuration(r.Context(), &metricsstore.MinStepDurationQueryParameters{})
+// findExemplarTraces implements the REST API /exemplars/traces
+// It responds with the traces that may have produced a metric exemplar, the closest first.
+func (aH *APIHandler) findExemplarTraces(w http.ResponseWriter, r *http.Request) {
+	query, err := aH.queryParser.parseExemplarQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	candidates, err := aH.queryService.FindExemplarTraces(r.Context(), query)
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
-
+	uiCandidates := make([]ui.ExemplarCandidate, len(candidates))
+	for i, candidate := range candidates {
+		uiCandidates[i] = ui.ExemplarCandidate{
+			TraceID:       ui.TraceID(candidate.Span.TraceID.String()),
+			SpanID:        ui.SpanID(candidate.Span.SpanID.String()),
+			OperationName: candidate.Span.OperationName,
+			StartTime:     model.TimeAsEpochMicroseconds(candidate.Span.StartTime),
+			Duration:      model.DurationAsMicroseconds(candidate.Span.Duration),
+			TimeOffset:    model.DurationAsMicroseconds(candidate.TimeOffset),
+			LatencyDelta:  model.DurationAsMicroseconds(candidate.LatencyDelta),
+		}
+	}
 	structuredRes := structuredResponse{
-		Data: minStep.Milliseconds(),
+		Data:  uiCandidates,
+		Total: len(uiCandidates),
 	}
 	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (aH *APIHandler) metrics(w http.ResponseWriter, r *http.Request, getMetrics func(context.Context, metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error)) {
-	requestParams, err := aH.queryParser.parseMetricsQueryParams(r)
+// findCallPaths implements the REST API /call-paths returning the most frequent chains of calls
+// between services going through a service, with the durations of their hops.
+func (aH *APIHandler) findCallPaths(w http.ResponseWriter, r *http.Request) {
+	query, err := aH.queryParser.parseCallPathQueryParams(r)
 	if aH.handleError(w, err, http.StatusBadRequest) {
 		return
 	}
-	m, err := getMetrics(r.Context(), requestParams)
+	paths, err := aH.queryService.FindCallPaths(r.Context(), query)
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
-	aH.writeJSON(w, r, m)
-}
-
-func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool) (*ui.Trace, *structuredError) {
-	var errs []error
-	if adjust {
-		var err error
-		trace, err = aH.queryService.Adjust(trace)
-		if err != nil {
-			errs = append(errs, err)
+	uiPaths := make([]ui.CallPath, len(paths))
+	for i, path := range paths {
+		hops := make([]ui.CallHop, len(path.Hops))
+		for j, hop := range path.Hops {
+			hops[j] = ui.CallHop{
+				ServiceName:   hop.ServiceName,
+				OperationName: hop.OperationName,
+				P50:           model.DurationAsMicroseconds(hop.Latency.P50),
+				P95:           model.DurationAsMicroseconds(hop.Latency.P95),
+				P99:           model.DurationAsMicroseconds(hop.Latency.P99),
+			}
 		}
+		uiPaths[i] = ui.CallPath{Hops: hops, Count: path.Count, TraceCount: path.TraceCount}
 	}
-	uiTrace := uiconv.FromDomain(trace)
-	var uiError *structuredError
-	if err := errors.Join(errs...); err != nil {
-		uiError = &structuredError{
-			Msg:     err.Error(),
-			TraceID: uiTrace.TraceID,
-		}
+	structuredRes := structuredResponse{
+		Data:  uiPaths,
+		Total: len(uiPaths),
 	}
-	return uiTrace, uiError
+	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (*APIHandler) deduplicateDependencies(dependencies []model.DependencyLink) []ui.DependencyLink {
-	type Key struct {
-		parent string
-		child  string
+// findQualityScores implements the REST API /quality/scores returning the instrumentation quality
+// scores of a service, or of all the services, over a time range.
+func (aH *APIHandler) findQualityScores(w http.ResponseWriter, r *http.Request) {
+	query, err := aH.queryParser.parseQualityScoreQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
 	}
-	links := make(map[Key]uint64)
-
-	for _, l := range dependencies {
-		links[Key{l.Parent, l.Child}] += l.CallCount
+	scores, err := aH.queryService.FindQualityScores(r.Context(), query)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
 	}
-
-	result := make([]ui.DependencyLink, 0, len(links))
-	for k, v := range links {
-		result = append(result, ui.DependencyLink{Parent: k.parent, Child: k.child, CallCount: v})
+	uiScores := make([]ui.QualityScore, len(scores))
+	for i, score := range scores {
+		checks := make([]ui.QualityCheckResult, len(score.Checks))
+		for j, check := range score.Checks {
+			checks[j] = ui.QualityCheckResult{
+				Check:   string(check.Check),
+				Checked: check.Checked,
+				Failed:  check.Failed,
+				Score:   check.Score(),
+			}
+		}
+		uiScores[i] = ui.QualityScore{
+			ServiceName: score.ServiceName,
+			SpanCount:   score.SpanCount,
+			TraceCount:  score.TraceCount,
+			Score:       score.Score(),
+			Checks:      checks,
+		}
 	}
-
-	return result
+	structuredRes := structuredResponse{
+		Data:  uiScores,
+		Total: len(uiScores),
+	}
+	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (*APIHandler) filterDependenciesByService(
-	dependencies []model.DependencyLink,
-	service string,
-) []model.DependencyLink {
-	if len(service) == 0 {
-		return dependencies
+func (aH *APIHandler) getRegressions(w http.ResponseWriter, r *http.Request) {
+	if aH.regressions == nil {
+		aH.handleError(w, errRegressionDetectionDisabled, http.StatusNotImplemented)
+		return
 	}
-
-	var filteredDependencies []model.DependencyLink
-	for _, dependency := range dependencies {
-		if dependency.Parent == service || dependency.Child == service {
-			filteredDependencies = append(filteredDependencies, dependency)
+	query, err := aH.queryParser.parseRegressionQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	annotations := aH.regressions.Find(query)
+	allowed := annotations[:0]
+	for _, annotation := range annotations {
+		if aH.queryService.IsServiceAllowed(r.Context(), annotation.ServiceName) {
+			allowed = append(allowed, annotation)
 		}
 	}
-	return filteredDependencies
+	annotations = allowed
+	structuredRes := structuredResponse{
+		Data:  annotations,
+		Total: len(annotations),
+	}
+	aH.writeJSON(w, r, &structuredRes)
 }
 
-// Parses trace ID from URL like /traces/{trace-id}
-func (aH *APIHandler) parseTraceID(w http.ResponseWriter, r *http.Request) (model.TraceID, bool) {
-	vars := mux.Vars(r)
-	traceIDVar := vars[traceIDParam]
-	traceID, err := model.TraceIDFromString(traceIDVar)
-	if aH.handleError(w, err, http.StatusBadRequest) {
-		return traceID, false
-	}
-	return traceID, true
+// traceWarnings is the JSON representation of the recorded warnings of a trace.
+type traceWarnings struct {
+	TraceID   ui.TraceID    `json:"traceID"`
+	StartTime uint64        `json:"startTime"` // microseconds since Unix epoch
+	Services  []string      `json:"services"`
+	Warnings  []spanWarning `json:"warnings"`
 }
 
-// getTrace implements the REST API /traces/{trace-id}
-// It parses trace ID from the path, fetches the trace from QueryService,
-// formats it in the UI JSON format, and responds to the client.
-func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
-	traceID, ok := aH.parseTraceID(w, r)
-	if !ok {
+type spanWarning struct {
+	SpanID  ui.SpanID `json:"spanID"`
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+}
+
+// getWarnings implements the REST API /warnings finding the traces whose recorded
+// warnings are of the type parameter, e.g. clock-skew.
+func (aH *APIHandler) getWarnings(w http.ResponseWriter, r *http.Request) {
+	query, err := aH.queryParser.parseWarningsQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
 		return
 	}
-	trace, err := aH.queryService.GetTrace(r.Context(), traceID)
-	if errors.Is(err, spanstore.ErrTraceNotFound) {
-		aH.handleError(w, err, http.StatusNotFound)
+	found, err := aH.queryService.FindTraceWarnings(r.Context(), query)
+	if errors.Is(err, querysvc.ErrWarningStorageNotConfigured) {
+		aH.handleError(w, err, http.StatusNotImplemented)
 		return
 	}
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
+	data := make([]traceWarnings, len(found))
+	for i, trace := range found {
+		data[i] = traceWarnings{
+			TraceID:   ui.TraceID(trace.TraceID.String()),
+			StartTime: model.TimeAsEpochMicroseconds(trace.StartTime),
+			Services:  trace.Services,
+			Warnings:  make([]spanWarning, len(trace.Warnings)),
+		}
+		for j, warning := range trace.Warnings {
+			data[i].Warnings[j] = spanWarning{
+				SpanID:  ui.SpanID(warning.SpanID.String()),
+				Type:    warning.Type,
+				Message: warning.Message,
+			}
+		}
+	}
+	structuredRes := structuredResponse{
+		Data:  data,
+		Total: len(data),
+		Limit: query.NumTraces,
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
 
-	var uiErrors []structuredError
-	structuredRes := aH.tracesToResponse([]*model.Trace{trace}, shouldAdjust(r), uiErrors)
-	aH.writeJSON(w, r, structuredRes)
+// serviceMetadata is the JSON representation of the metadata of a service.
+type serviceMetadata struct {
+	ServiceName   string `json:"serviceName"`
+	Description   string `json:"description,omitempty"`
+	Team          string `json:"team,omitempty"`
+	RepositoryURL string `json:"repositoryURL,omitempty"`
+	OnCallURL     string `json:"onCallURL,omitempty"`
 }
 
-func shouldAdjust(r *http.Request) bool {
-	raw := r.FormValue("raw")
-	isRaw, _ := strconv.ParseBool(raw)
-	return !isRaw
+func toServiceMetadataJSON(metadata *metadatastore.ServiceMetadata) serviceMetadata {
+	return serviceMetadata{
+		ServiceName:   metadata.ServiceName,
+		Description:   metadata.Description,
+		Team:          metadata.Team,
+		RepositoryURL: metadata.RepositoryURL,
+		OnCallURL:     metadata.OnCallURL,
+	}
 }
 
-// archiveTrace implements the REST API POST:/archive/{trace-id}.
-// It passes the traceID to queryService.ArchiveTrace for writing.
-func (aH *APIHandler) archiveTrace(w http.ResponseWriter, r *http.Request) {
-	traceID, ok := aH.parseTraceID(w, r)
-	if !ok {
-		return
+// handleMetadataError handles the errors of the service metadata storage, returning true if there was one.
+func (aH *APIHandler) handleMetadataError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, querysvc.ErrMetadataStorageNotConfigured):
+		return aH.handleError(w, err, http.StatusNotImplemented)
+	case errors.Is(err, metadatastore.ErrServiceMetadataNotFound):
+		return aH.handleError(w, err, http.StatusNotFound)
+	default:
+		return aH.handleError(w, err, http.StatusInternalServerError)
 	}
+}
 
-	// QueryService.ArchiveTrace can now archive this traceID.
-	err := aH.queryService.ArchiveTrace(r.Context(), traceID)
-	if errors.Is(err, spanstore.ErrTraceNotFound) {
-		aH.handleError(w, err, http.StatusNotFound)
+// findServiceMetadata implements the REST API /service-metadata listing the metadata of all the services.
+func (aH *APIHandler) findServiceMetadata(w http.ResponseWriter, r *http.Request) {
+	found, err := aH.queryService.FindServiceMetadata(r.Context())
+	if aH.handleMetadataError(w, err) {
 		return
 	}
-	if aH.handleError(w, err, http.StatusInternalServerError) {
-		return
+	data := make([]serviceMetadata, len(found))
+	for i, metadata := range found {
+		data[i] = toServiceMetadataJSON(metadata)
 	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:  data,
+		Total: len(data),
+	})
+}
 
-	structuredRes := structuredResponse{
-		Data:   []string{}, // doesn't matter, just want an empty array
-		Errors: []structuredError{},
+func (aH *APIHandler) getServiceMetadata(w http.ResponseWriter, r *http.Request) {
+	// given how getServiceMetadata is bound to URL route, serviceParam cannot be empty
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	metadata, err := aH.queryService.GetServiceMetadata(r.Context(), service)
+	if aH.handleMetadataError(w, err) {
+		return
 	}
-	aH.writeJSON(w, r, &structuredRes)
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: toServiceMetadataJSON(metadata),
+	})
 }
 
-func (aH *APIHandler) handleError(w http.ResponseWriter, err error, statusCode int) bool {
-	if err == nil {
-		return false
+// putServiceMetadata creates or replaces the metadata of the service from the JSON body of the request.
+func (aH *APIHandler) putServiceMetadata(w http.ResponseWriter, r *http.Request) {
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	var body serviceMetadata
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		aH.handleError(w, fmt.Errorf("cannot parse the service metadata: %w", err), http.StatusBadRequest)
+		return
 	}
-	if errors.Is(err, disabled.ErrDisabled) {
-		statusCode = http.StatusNotImplemented
+	if body.ServiceName != "" && body.ServiceName != service {
+		aH.handleError(w, fmt.Errorf("service name %q does not match the service %q of the path", body.ServiceName, service), http.StatusBadRequest)
+		return
+	}
+	metadata := &metadatastore.ServiceMetadata{
+		ServiceName:   service,
+		Description:   body.Description,
+		Team:          body.Team,
+		RepositoryURL: body.RepositoryURL,
+		OnCallURL:     body.OnCallURL,
+	}
+	if aH.handleMetadataError(w, aH.queryService.WriteServiceMetadata(r.Context(), metadata)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: toServiceMetadataJSON(metadata),
+	})
+}
+
+func (aH *APIHandler) deleteServiceMetadata(w http.ResponseWriter, r *http.Request) {
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	if aH.handleMetadataError(w, aH.queryService.DeleteServiceMetadata(r.Context(), service)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: []string{},
+	})
+}
+
+// savedSearchName restricts the names of the saved searches, which are part of their URLs.
+var savedSearchName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// savedSearch is the JSON representation of a saved search, with the parameters of /traces.
+type savedSearch struct {
+	Name        string            `json:"name"`
+	Owner       string            `json:"owner,omitempty"`
+	Shared      bool              `json:"shared,omitempty"`
+	Service     string            `json:"service"`
+	Operation   string            `json:"operation,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	MinDuration string            `json:"minDuration,omitempty"`
+	MaxDuration string            `json:"maxDuration,omitempty"`
+	Lookback    string            `json:"lookback,omitempty"`
+	Limit       int               `json:"limit,omitempty"`
+	// UpdatedAt is ignored when the search is saved.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func formatOptionalDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func toSavedSearchJSON(search *savedsearchstore.SavedSearch) savedSearch {
+	return savedSearch{
+		Name:        search.Name,
+		Owner:       search.Owner,
+		Shared:      search.Shared,
+		Service:     search.ServiceName,
+		Operation:   search.OperationName,
+		Tags:        search.Tags,
+		MinDuration: formatOptionalDuration(search.DurationMin),
+		MaxDuration: formatOptionalDuration(search.DurationMax),
+		Lookback:    formatOptionalDuration(search.Lookback),
+		Limit:       search.Limit,
+		UpdatedAt:   search.UpdatedAt,
+	}
+}
+
+// toSavedSearch validates the search and parses its durations.
+func (s savedSearch) toSavedSearch() (*savedsearchstore.SavedSearch, error) {
+	if s.Service == "" {
+		return nil, errServiceParameterRequired
+	}
+	if s.Limit < 0 {
+		return nil, errors.New("the limit must not be negative")
+	}
+	search := &savedsearchstore.SavedSearch{
+		Name:          s.Name,
+		Shared:        s.Shared,
+		ServiceName:   s.Service,
+		OperationName: s.Operation,
+		Tags:          s.Tags,
+		Limit:         s.Limit,
+	}
+	for _, d := range []struct {
+		param string
+		value string
+		dst   *time.Duration
+	}{
+		{minDurationParam, s.MinDuration, &search.DurationMin},
+		{maxDurationParam, s.MaxDuration, &search.DurationMax},
+		{lookbackParam, s.Lookback, &search.Lookback},
+	} {
+		if d.value == "" {
+			continue
+		}
+		value, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, newParseError(err, d.param)
+		}
+		*d.dst = value
+	}
+	return search, nil
+}
+
+// handleSavedSearchError handles the errors of the saved searches storage, returning true if there was one.
+func (aH *APIHandler) handleSavedSearchError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, querysvc.ErrSavedSearchStorageNotConfigured):
+		return aH.handleError(w, err, http.StatusNotImplemented)
+	case errors.Is(err, savedsearchstore.ErrSavedSearchNotFound):
+		return aH.handleError(w, err, http.StatusNotFound)
+	default:
+		return aH.handleError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// findSavedSearches implements the REST API /saved-searches listing the searches saved by the
+// caller and the ones shared by the other users, ordered by owner and name.
+func (aH *APIHandler) findSavedSearches(w http.ResponseWriter, r *http.Request) {
+	found, err := aH.queryService.FindSavedSearches(r.Context())
+	if aH.handleSavedSearchError(w, err) {
+		return
+	}
+	data := make([]savedSearch, len(found))
+	for i, search := range found {
+		data[i] = toSavedSearchJSON(search)
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:  data,
+		Total: len(data),
+	})
+}
+
+// getSavedSearch returns a search of the caller, or a search shared by the user of the owner parameter.
+func (aH *APIHandler) getSavedSearch(w http.ResponseWriter, r *http.Request) {
+	name, _ := url.QueryUnescape(mux.Vars(r)[savedSearchNameParam])
+	search, err := aH.queryService.GetSavedSearch(r.Context(), r.FormValue(ownerParam), name)
+	if aH.handleSavedSearchError(w, err) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: toSavedSearchJSON(search),
+	})
+}
+
+// putSavedSearch creates or replaces a search of the caller from the JSON body of the request.
+func (aH *APIHandler) putSavedSearch(w http.ResponseWriter, r *http.Request) {
+	name, _ := url.QueryUnescape(mux.Vars(r)[savedSearchNameParam])
+	if !savedSearchName.MatchString(name) {
+		aH.handleError(w, fmt.Errorf("the name %q must only contain letters, digits, '_', '.' and '-'", name), http.StatusBadRequest)
+		return
+	}
+	var body savedSearch
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		aH.handleError(w, fmt.Errorf("cannot parse the saved search: %w", err), http.StatusBadRequest)
+		return
+	}
+	if body.Name != "" && body.Name != name {
+		aH.handleError(w, fmt.Errorf("name %q does not match the saved search %q of the path", body.Name, name), http.StatusBadRequest)
+		return
+	}
+	body.Name = name
+	search, err := body.toSavedSearch()
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	if aH.handleSavedSearchError(w, aH.queryService.WriteSavedSearch(r.Context(), search)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: toSavedSearchJSON(search),
+	})
+}
+
+func (aH *APIHandler) deleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	name, _ := url.QueryUnescape(mux.Vars(r)[savedSearchNameParam])
+	if aH.handleSavedSearchError(w, aH.queryService.DeleteSavedSearch(r.Context(), name)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: []string{},
+	})
+}
+
+// handleAlertingError handles the errors of the alerting rules, returning true if there was one.
+func (aH *APIHandler) handleAlertingError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, alerting.ErrInvalidRule):
+		return aH.handleError(w, err, http.StatusBadRequest)
+	case errors.Is(err, alerting.ErrRuleNotFound):
+		return aH.handleError(w, err, http.StatusNotFound)
+	default:
+		return aH.handleError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// findAlertingRules implements the REST API /alerting/rules listing the alerting rules of the
+// services the caller is allowed to query, with their states.
+func (aH *APIHandler) findAlertingRules(w http.ResponseWriter, r *http.Request) {
+	if aH.alerting == nil {
+		aH.handleError(w, errAlertingDisabled, http.StatusNotImplemented)
+		return
+	}
+	rules := aH.alerting.Rules(r.Context())
+	allowed := rules[:0]
+	for _, rule := range rules {
+		if aH.queryService.IsServiceAllowed(r.Context(), rule.Service) {
+			allowed = append(allowed, rule)
+		}
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:  allowed,
+		Total: len(allowed),
+	})
+}
+
+func (aH *APIHandler) getAlertingRule(w http.ResponseWriter, r *http.Request) {
+	if aH.alerting == nil {
+		aH.handleError(w, errAlertingDisabled, http.StatusNotImplemented)
+		return
+	}
+	name, _ := url.QueryUnescape(mux.Vars(r)[ruleNameParam])
+	rule, err := aH.alerting.GetRule(r.Context(), name)
+	if aH.handleAlertingError(w, err) {
+		return
+	}
+	if !aH.queryService.IsServiceAllowed(r.Context(), rule.Service) {
+		aH.handleError(w, fmt.Errorf("%w %q", querysvc.ErrServiceNotAllowed, rule.Service), http.StatusForbidden)
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: rule,
+	})
+}
+
+// putAlertingRule creates or replaces an alerting rule from the JSON body of the request.
+func (aH *APIHandler) putAlertingRule(w http.ResponseWriter, r *http.Request) {
+	if aH.alerting == nil {
+		aH.handleError(w, errAlertingDisabled, http.StatusNotImplemented)
+		return
+	}
+	name, _ := url.QueryUnescape(mux.Vars(r)[ruleNameParam])
+	var rule alerting.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		aH.handleError(w, fmt.Errorf("cannot parse the alerting rule: %w", err), http.StatusBadRequest)
+		return
+	}
+	if rule.Name != "" && rule.Name != name {
+		aH.handleError(w, fmt.Errorf("name %q does not match the alerting rule %q of the path", rule.Name, name), http.StatusBadRequest)
+		return
+	}
+	rule.Name = name
+	if !aH.queryService.IsServiceAllowed(r.Context(), rule.Service) {
+		aH.handleError(w, fmt.Errorf("%w %q", querysvc.ErrServiceNotAllowed, rule.Service), http.StatusForbidden)
+		return
+	}
+	status, err := aH.alerting.PutRule(r.Context(), rule)
+	if aH.handleAlertingError(w, err) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: status,
+	})
+}
+
+func (aH *APIHandler) deleteAlertingRule(w http.ResponseWriter, r *http.Request) {
+	if aH.alerting == nil {
+		aH.handleError(w, errAlertingDisabled, http.StatusNotImplemented)
+		return
+	}
+	name, _ := url.QueryUnescape(mux.Vars(r)[ruleNameParam])
+	rule, err := aH.alerting.GetRule(r.Context(), name)
+	if aH.handleAlertingError(w, err) {
+		return
+	}
+	if !aH.queryService.IsServiceAllowed(r.Context(), rule.Service) {
+		aH.handleError(w, fmt.Errorf("%w %q", querysvc.ErrServiceNotAllowed, rule.Service), http.StatusForbidden)
+		return
+	}
+	if aH.handleAlertingError(w, aH.alerting.DeleteRule(r.Context(), name)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: []string{},
+	})
+}
+
+// handleSLOError handles the errors of the SLOs, returning true if there was one.
+func (aH *APIHandler) handleSLOError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, slo.ErrInvalidObjective):
+		return aH.handleError(w, err, http.StatusBadRequest)
+	case errors.Is(err, slo.ErrObjectiveNotFound):
+		return aH.handleError(w, err, http.StatusNotFound)
+	case errors.Is(err, querysvc.ErrServiceNotAllowed):
+		return aH.handleError(w, err, http.StatusForbidden)
+	default:
+		return aH.handleError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// findSLOs implements the REST API /slos listing the SLOs of the services the caller is allowed to query.
+func (aH *APIHandler) findSLOs(w http.ResponseWriter, r *http.Request) {
+	if aH.slos == nil {
+		aH.handleError(w, errSLODisabled, http.StatusNotImplemented)
+		return
+	}
+	slos := aH.slos.Objectives(r.Context())
+	allowed := slos[:0]
+	for _, s := range slos {
+		if aH.queryService.IsServiceAllowed(r.Context(), s.Service) {
+			allowed = append(allowed, s)
+		}
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:  allowed,
+		Total: len(allowed),
+	})
+}
+
+// getAllowedSLO returns the SLO of the path if the caller is allowed to query its service,
+// otherwise it writes the error and returns false.
+func (aH *APIHandler) getAllowedSLO(w http.ResponseWriter, r *http.Request) (slo.SLO, bool) {
+	name, _ := url.QueryUnescape(mux.Vars(r)[sloNameParam])
+	objective, err := aH.slos.GetObjective(r.Context(), name)
+	if aH.handleSLOError(w, err) {
+		return objective, false
+	}
+	if !aH.queryService.IsServiceAllowed(r.Context(), objective.Service) {
+		aH.handleError(w, fmt.Errorf("%w %q", querysvc.ErrServiceNotAllowed, objective.Service), http.StatusForbidden)
+		return objective, false
+	}
+	return objective, true
+}
+
+func (aH *APIHandler) getSLO(w http.ResponseWriter, r *http.Request) {
+	if aH.slos == nil {
+		aH.handleError(w, errSLODisabled, http.StatusNotImplemented)
+		return
+	}
+	objective, ok := aH.getAllowedSLO(w, r)
+	if !ok {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: objective,
+	})
+}
+
+// putSLO creates or replaces an SLO from the JSON body of the request.
+func (aH *APIHandler) putSLO(w http.ResponseWriter, r *http.Request) {
+	if aH.slos == nil {
+		aH.handleError(w, errSLODisabled, http.StatusNotImplemented)
+		return
+	}
+	name, _ := url.QueryUnescape(mux.Vars(r)[sloNameParam])
+	var objective slo.SLO
+	if err := json.NewDecoder(r.Body).Decode(&objective); err != nil {
+		aH.handleError(w, fmt.Errorf("cannot parse the SLO: %w", err), http.StatusBadRequest)
+		return
+	}
+	if objective.Name != "" && objective.Name != name {
+		aH.handleError(w, fmt.Errorf("name %q does not match the SLO %q of the path", objective.Name, name), http.StatusBadRequest)
+		return
+	}
+	objective.Name = name
+	if !aH.queryService.IsServiceAllowed(r.Context(), objective.Service) {
+		aH.handleError(w, fmt.Errorf("%w %q", querysvc.ErrServiceNotAllowed, objective.Service), http.StatusForbidden)
+		return
+	}
+	objective, err := aH.slos.PutObjective(r.Context(), objective)
+	if aH.handleSLOError(w, err) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: objective,
+	})
+}
+
+func (aH *APIHandler) deleteSLO(w http.ResponseWriter, r *http.Request) {
+	if aH.slos == nil {
+		aH.handleError(w, errSLODisabled, http.StatusNotImplemented)
+		return
+	}
+	objective, ok := aH.getAllowedSLO(w, r)
+	if !ok {
+		return
+	}
+	if aH.handleSLOError(w, aH.slos.DeleteObjective(r.Context(), objective.Name)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: []string{},
+	})
+}
+
+// getSLOStatus implements the REST API /slos/{slo}/status computing the compliance, the error budget
+// and the burn rates of an SLO, ending now.
+func (aH *APIHandler) getSLOStatus(w http.ResponseWriter, r *http.Request) {
+	if aH.slos == nil {
+		aH.handleError(w, errSLODisabled, http.StatusNotImplemented)
+		return
+	}
+	objective, ok := aH.getAllowedSLO(w, r)
+	if !ok {
+		return
+	}
+	status, err := aH.slos.GetStatus(r.Context(), objective.Name)
+	if aH.handleSLOError(w, err) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: status,
+	})
+}
+
+// samplingStrategy is the JSON representation of the stored sampling strategy of a service,
+// the strategy having the format of the sampling endpoints of the collector.
+type samplingStrategy struct {
+	ServiceName string          `json:"serviceName"`
+	Strategy    json.RawMessage `json:"strategy"`
+}
+
+func toSamplingStrategyJSON(service string, strategy *api_v2.SamplingStrategyResponse) (samplingStrategy, error) {
+	value, err := uiconv.SamplingStrategyResponseToJSON(strategy)
+	if err != nil {
+		return samplingStrategy{}, err
+	}
+	return samplingStrategy{ServiceName: service, Strategy: json.RawMessage(value)}, nil
+}
+
+// verifySamplingAdmin only lets through the requests with the bearer token of the admins.
+func (aH *APIHandler) verifySamplingAdmin(f func(http.ResponseWriter, *http.Request)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(aH.samplingAdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			aH.handleError(w, errors.New("the sampling strategies API requires the admin bearer token"), http.StatusUnauthorized)
+			return
+		}
+		f(w, r)
+	})
+}
+
+// handleSamplingStrategyError handles the errors of the sampling strategies storage, returning true if there was one.
+func (aH *APIHandler) handleSamplingStrategyError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, querysvc.ErrSamplingStrategyStorageNotConfigured):
+		return aH.handleError(w, err, http.StatusNotImplemented)
+	case errors.Is(err, querysvc.ErrInvalidSamplingStrategy):
+		return aH.handleError(w, err, http.StatusBadRequest)
+	case errors.Is(err, samplingstore.ErrStrategyNotFound):
+		return aH.handleError(w, err, http.StatusNotFound)
+	default:
+		return aH.handleError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// findSamplingStrategies implements the REST API /sampling-strategies listing the stored
+// sampling strategies of all the services, ordered by service name.
+func (aH *APIHandler) findSamplingStrategies(w http.ResponseWriter, r *http.Request) {
+	found, err := aH.queryService.FindSamplingStrategies(r.Context())
+	if aH.handleSamplingStrategyError(w, err) {
+		return
+	}
+	data := make([]samplingStrategy, 0, len(found))
+	for service, strategy := range found {
+		s, err := toSamplingStrategyJSON(service, strategy)
+		if aH.handleError(w, err, http.StatusInternalServerError) {
+			return
+		}
+		data = append(data, s)
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].ServiceName < data[j].ServiceName })
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:  data,
+		Total: len(data),
+	})
+}
+
+func (aH *APIHandler) getSamplingStrategy(w http.ResponseWriter, r *http.Request) {
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	strategy, err := aH.queryService.GetSamplingStrategy(r.Context(), service)
+	if aH.handleSamplingStrategyError(w, err) {
+		return
+	}
+	data, err := toSamplingStrategyJSON(service, strategy)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: data,
+	})
+}
+
+// putSamplingStrategy creates or replaces the sampling strategy of the service from the JSON
+// body of the request, in the format of the sampling endpoints of the collector.
+func (aH *APIHandler) putSamplingStrategy(w http.ResponseWriter, r *http.Request) {
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	body, err := io.ReadAll(r.Body)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	strategy, err := uiconv.SamplingStrategyResponseFromJSON(body)
+	if err != nil {
+		aH.handleError(w, fmt.Errorf("cannot parse the sampling strategy: %w", err), http.StatusBadRequest)
+		return
+	}
+	if aH.handleSamplingStrategyError(w, aH.queryService.WriteSamplingStrategy(r.Context(), service, strategy)) {
+		return
+	}
+	data, err := toSamplingStrategyJSON(service, strategy)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: data,
+	})
+}
+
+func (aH *APIHandler) deleteSamplingStrategy(w http.ResponseWriter, r *http.Request) {
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	if aH.handleSamplingStrategyError(w, aH.queryService.DeleteSamplingStrategy(r.Context(), service)) {
+		return
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data: []string{},
+	})
+}
+
+func (aH *APIHandler) latencies(w http.ResponseWriter, r *http.Request) {
+	q, err := strconv.ParseFloat(r.FormValue(quantileParam), 64)
+	if err != nil {
+		aH.handleError(w, newParseError(err, quantileParam), http.StatusBadRequest)
+		return
+	}
+	aH.metrics(w, r, func(ctx context.Context, baseParams metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error) {
+		return aH.metricsQueryService.GetLatencies(ctx, &metricsstore.LatenciesQueryParameters{
+			BaseQueryParameters: baseParams,
+			Quantile:            q,
+		})
+	})
+}
+
+func (aH *APIHandler) calls(w http.ResponseWriter, r *http.Request) {
+	aH.metrics(w, r, func(ctx context.Context, baseParams metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error) {
+		return aH.metricsQueryService.GetCallRates(ctx, &metricsstore.CallRateQueryParameters{
+			BaseQueryParameters: baseParams,
+		})
+	})
+}
+
+func (aH *APIHandler) errors(w http.ResponseWriter, r *http.Request) {
+	aH.metrics(w, r, func(ctx context.Context, baseParams metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error) {
+		return aH.metricsQueryService.GetErrorRates(ctx, &metricsstore.ErrorRateQueryParameters{
+			BaseQueryParameters: baseParams,
+		})
+	})
+}
+
+func (aH *APIHandler) minStep(w http.ResponseWriter, r *http.Request) {
+	minStep, err := aH.metricsQueryService.GetMinStepDuration(r.Context(), &metricsstore.MinStepDurationQueryParameters{})
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	structuredRes := structuredResponse{
+		Data: minStep.Milliseconds(),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+func (aH *APIHandler) metrics(w http.ResponseWriter, r *http.Request, getMetrics func(context.Context, metricsstore.BaseQueryParameters) (*metrics.MetricFamily, error)) {
+	requestParams, err := aH.queryParser.parseMetricsQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	m, err := getMetrics(r.Context(), requestParams)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	aH.writeJSON(w, r, m)
+}
+
+// convertModelToUI optionally adjusts the trace and annotates it with the requested analysis
+// before converting it to the UI model.
+func (aH *APIHandler) convertModelToUI(ctx context.Context, trace *model.Trace, adjust bool, analysis adjuster.Adjuster) (*ui.Trace, *structuredError) {
+	var errs []error
+	if adjust {
+		var err error
+		trace, err = aH.queryService.Adjust(trace)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		// the warnings are recorded before the analysis, which does not annotate the spans with warnings
+		aH.queryService.RecordWarnings(ctx, trace)
+	}
+	if analysis != nil {
+		var err error
+		trace, err = analysis.Adjust(trace)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	uiTrace := uiconv.FromDomain(trace)
+	var uiError *structuredError
+	if err := errors.Join(errs...); err != nil {
+		uiError = &structuredError{
+			Msg:     err.Error(),
+			TraceID: uiTrace.TraceID,
+		}
+	}
+	return uiTrace, uiError
+}
+
+func (*APIHandler) deduplicateDependencies(dependencies []model.DependencyLink) []ui.DependencyLink {
+	type Key struct {
+		parent string
+		child  string
+	}
+	links := make(map[Key]uint64)
+
+	for _, l := range dependencies {
+		links[Key{l.Parent, l.Child}] += l.CallCount
+	}
+
+	result := make([]ui.DependencyLink, 0, len(links))
+	for k, v := range links {
+		result = append(result, ui.DependencyLink{Parent: k.parent, Child: k.child, CallCount: v})
+	}
+
+	return result
+}
+
+func (*APIHandler) filterDependenciesByService(
+	dependencies []model.DependencyLink,
+	service string,
+) []model.DependencyLink {
+	if len(service) == 0 {
+		return dependencies
+	}
+
+	var filteredDependencies []model.DependencyLink
+	for _, dependency := range dependencies {
+		if dependency.Parent == service || dependency.Child == service {
+			filteredDependencies = append(filteredDependencies, dependency)
+		}
+	}
+	return filteredDependencies
+}
+
+// Parses trace ID from URL like /traces/{trace-id}
+func (aH *APIHandler) parseTraceID(w http.ResponseWriter, r *http.Request) (model.TraceID, bool) {
+	return aH.parseTraceIDParam(w, r, traceIDParam)
+}
+
+func (aH *APIHandler) parseTraceIDParam(w http.ResponseWriter, r *http.Request, paramName string) (model.TraceID, bool) {
+	vars := mux.Vars(r)
+	traceIDVar := vars[paramName]
+	traceID, err := model.TraceIDFromString(traceIDVar)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return traceID, false
+	}
+	return traceID, true
+}
+
+// getTrace implements the REST API /traces/{trace-id}
+// It parses trace ID from the path, fetches the trace from QueryService,
+// formats it in the UI JSON format, and responds to the client.
+// With ?analysis=critical_path each span is annotated with its contribution
+// to the critical path of the trace.
+func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	aH.writeTrace(w, r, aH.queryService, traceID)
+}
+
+func (aH *APIHandler) writeTrace(w http.ResponseWriter, r *http.Request, queryService *querysvc.QueryService, traceID model.TraceID) {
+	analysis, err := parseAnalysis(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	trace, err := queryService.GetTrace(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	var uiErrors []structuredError
+	structuredRes := aH.tracesToResponse(r.Context(), []*model.Trace{trace}, shouldAdjust(r), analysis, uiErrors)
+	aH.writeJSON(w, r, structuredRes)
+}
+
+// exportTraces implements the REST API /export/traces streaming all the traces matching a query,
+// one per line as JSON, or as OTLP TracesData protobuf each prefixed with its length in 4 bytes big-endian.
+// It accepts the parameters of /traces, the limit defaults to the maximum number of traces of an export.
+func (aH *APIHandler) exportTraces(w http.ResponseWriter, r *http.Request) {
+	if aH.exporter == nil {
+		aH.handleError(w, errTraceExportDisabled, http.StatusNotImplemented)
+		return
+	}
+	tQuery, err := aH.queryParser.parseTraceQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	if r.FormValue(limitParam) == "" {
+		tQuery.NumTraces = 0
+	}
+	format := r.FormValue(formatParam)
+	var contentType string
+	var encode func(trace *model.Trace) ([]byte, error)
+	switch format {
+	case "", exportFormatNDJSON:
+		contentType = "application/x-ndjson"
+		encode = func(trace *model.Trace) ([]byte, error) {
+			uiTrace, _ := aH.convertModelToUI(r.Context(), trace, true, nil)
+			line, err := json.Marshal(uiTrace)
+			return append(line, '\n'), err
+		}
+	case exportFormatOTLPProto:
+		contentType = "application/x-protobuf"
+		encode = encodeLengthPrefixedOTLP
+	default:
+		aH.handleError(w, fmt.Errorf("unsupported '%s' parameter %s, expecting %s or %s",
+			formatParam, format, exportFormatNDJSON, exportFormatOTLPProto), http.StatusBadRequest)
+		return
+	}
+
+	// the status is sent with the first trace, so that the errors preventing the export are reported by the status
+	writeHeader := func() {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Trailer", exportErrorTrailer)
+		w.WriteHeader(http.StatusOK)
+	}
+	responseController := http.NewResponseController(w)
+	started := false
+	written, err := aH.exporter.Export(r.Context(), tQuery.TraceQueryParameters, func(trace *model.Trace) error {
+		data, err := encode(trace)
+		if err != nil {
+			return err
+		}
+		if !started {
+			writeHeader()
+			started = true
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		// the writes block until the client reads the traces flushed
+		_ = responseController.Flush()
+		return nil
+	})
+	if err == nil {
+		if !started {
+			writeHeader()
+		}
+		return
+	}
+	if started {
+		aH.logger.Error("Trace export ended by an error", zap.Int("traces", written), zap.Error(err))
+		w.Header().Set(exportErrorTrailer, err.Error())
+		return
+	}
+	if errors.Is(err, export.ErrTooManyExports) {
+		aH.handleError(w, err, http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, export.ErrMaxDurationExceeded) {
+		aH.handleError(w, err, http.StatusServiceUnavailable)
+		return
+	}
+	aH.handleError(w, err, http.StatusInternalServerError)
+}
+
+// encodeLengthPrefixedOTLP encodes the trace as OTLP TracesData protobuf prefixed with its length.
+func encodeLengthPrefixedOTLP(trace *model.Trace) ([]byte, error) {
+	td, err := model2otel.ProtoToTraces([]*model.Batch{{Spans: trace.Spans}})
+	if err != nil {
+		return nil, err
+	}
+	data, err := new(ptrace.ProtoMarshaler).MarshalTraces(td)
+	if err != nil {
+		return nil, err
+	}
+	return append(binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(data)), uint32(len(data))), data...), nil
+}
+
+// getTraceLogs implements the REST API /traces/{trace-id}/logs returning the log lines
+// correlated with the trace by the log backend.
+func (aH *APIHandler) getTraceLogs(w http.ResponseWriter, r *http.Request) {
+	if aH.logCorrelator == nil {
+		aH.handleError(w, errLogCorrelationDisabled, http.StatusNotImplemented)
+		return
+	}
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	trace, err := aH.queryService.GetTrace(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	lines, err := aH.logCorrelator.TraceLogs(r.Context(), trace)
+	if aH.handleError(w, err, http.StatusBadGateway) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data:  lines,
+		Total: len(lines),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// shareTrace implements the REST API /traces/{trace-id}/share minting a token which
+// grants read access to the trace, for the caller's own tenant and services only.
+func (aH *APIHandler) shareTrace(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	var ttl time.Duration
+	if ttlValue := r.FormValue(ttlParam); ttlValue != "" {
+		var err error
+		ttl, err = time.ParseDuration(ttlValue)
+		if err != nil {
+			aH.handleError(w, newParseError(err, ttlParam), http.StatusBadRequest)
+			return
+		}
+	}
+	_, err := aH.queryService.GetTrace(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	token, grant, err := aH.traceSharing.Sign(traceID, tenancy.GetTenant(r.Context()), ttl)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: sharedTraceLink{
+			Token:     token,
+			Path:      aH.formatRoute("/shared-traces/%s", token),
+			ExpiresAt: grant.ExpiresAt,
+		},
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// sharedTraceLink is the response of the trace sharing API.
+type sharedTraceLink struct {
+	Token string `json:"token"`
+	// Path is the route reading the shared trace, relative to the base path.
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// verifyShareToken is the middleware of the shared traces route, rejecting the requests
+// without a valid token and attaching the grant and the tenant of the token to the others.
+func (aH *APIHandler) verifyShareToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grant, err := aH.traceSharing.Verify(mux.Vars(r)[shareTokenParam])
+		if aH.handleError(w, err, http.StatusUnauthorized) {
+			return
+		}
+		ctx := sharing.ContextWithGrant(r.Context(), grant)
+		if grant.Tenant != "" {
+			ctx = tenancy.WithTenant(ctx, grant.Tenant)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// getSharedTrace implements the REST API /shared-traces/{token}, with the same response as /traces/{trace-id}.
+func (aH *APIHandler) getSharedTrace(w http.ResponseWriter, r *http.Request) {
+	grant, _ := sharing.GrantFromContext(r.Context())
+	aH.writeTrace(w, r, aH.queryService.WithoutAuthorization(), grant.TraceID)
+}
+
+// compareTraces implements the REST API /traces/{trace-id}/diff/{other-trace-id}
+// It responds with the structural difference between the two traces.
+func (aH *APIHandler) compareTraces(w http.ResponseWriter, r *http.Request) {
+	baseTraceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	otherTraceID, ok := aH.parseTraceIDParam(w, r, otherTraceIDParam)
+	if !ok {
+		return
+	}
+	diff, err := aH.queryService.CompareTraces(r.Context(), baseTraceID, otherTraceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: traceDiffToUI(diff),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+func traceDiffToUI(diff *querysvc.TraceDiff) *ui.TraceDiff {
+	uiDiff := &ui.TraceDiff{
+		BaseTraceID:  ui.TraceID(diff.BaseTraceID.String()),
+		OtherTraceID: ui.TraceID(diff.OtherTraceID.String()),
+		Spans:        make([]ui.SpanDiff, len(diff.Spans)),
+	}
+	for i, spanDiff := range diff.Spans {
+		uiSpanDiff := ui.SpanDiff{
+			Type:          string(spanDiff.Type),
+			DurationDelta: spanDiff.DurationDelta.Microseconds(),
+		}
+		if base := spanDiff.Base; base != nil {
+			uiSpanDiff.ServiceName = base.Process.GetServiceName()
+			uiSpanDiff.BaseSpanID = ui.SpanID(base.SpanID.String())
+			uiSpanDiff.BaseOperationName = base.OperationName
+			uiSpanDiff.BaseDuration = model.DurationAsMicroseconds(base.Duration)
+		}
+		if other := spanDiff.Other; other != nil {
+			uiSpanDiff.ServiceName = other.Process.GetServiceName()
+			uiSpanDiff.OtherSpanID = ui.SpanID(other.SpanID.String())
+			uiSpanDiff.OtherOperationName = other.OperationName
+			uiSpanDiff.OtherDuration = model.DurationAsMicroseconds(other.Duration)
+		}
+		uiDiff.Spans[i] = uiSpanDiff
+	}
+	return uiDiff
+}
+
+// parseAnalysis returns the adjuster computing the analysis requested with the analysis parameter, if any.
+func parseAnalysis(r *http.Request) (adjuster.Adjuster, error) {
+	switch analysis := r.FormValue(analysisParam); analysis {
+	case "":
+		return nil, nil
+	case criticalPathAnalysis:
+		return adjuster.CriticalPath(), nil
+	default:
+		return nil, fmt.Errorf("unsupported '%s' parameter: %s", analysisParam, analysis)
+	}
+}
+
+func shouldAdjust(r *http.Request) bool {
+	raw := r.FormValue("raw")
+	isRaw, _ := strconv.ParseBool(raw)
+	return !isRaw
+}
+
+// archiveTrace implements the REST API POST:/archive/{trace-id}.
+// It passes the traceID to queryService.ArchiveTrace for writing.
+func (aH *APIHandler) archiveTrace(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+
+	// QueryService.ArchiveTrace can now archive this traceID.
+	err := aH.queryService.ArchiveTrace(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	structuredRes := structuredResponse{
+		Data:   []string{}, // doesn't matter, just want an empty array
+		Errors: []structuredError{},
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+func (aH *APIHandler) handleError(w http.ResponseWriter, err error, statusCode int) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, disabled.ErrDisabled) {
+		statusCode = http.StatusNotImplemented
+	}
+	if errors.Is(err, querysvc.ErrServiceNotAllowed) {
+		statusCode = http.StatusForbidden
+	}
+	var costErr *querysvc.CostLimitError
+	if errors.As(err, &costErr) {
+		statusCode = http.StatusUnprocessableEntity
 	}
 	if statusCode == http.StatusInternalServerError {
 		aH.logger.Error("HTTP handler, Internal Server Error", zap.Error(err))
 	}
 	structuredResp := structuredResponse{
+		// the limit exceeded by the query, if any, explains the error
+		Data: costErr,
 		Errors: []structuredError{
 			{
 				Code: statusCode,
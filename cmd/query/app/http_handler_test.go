@@ -16,8 +16,10 @@
 package app
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,28 +28,41 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/alerting"
+	"github.com/jaegertracing/jaeger/cmd/query/app/export"
+	"github.com/jaegertracing/jaeger/cmd/query/app/livetail"
+	"github.com/jaegertracing/jaeger/cmd/query/app/logs"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
+	"github.com/jaegertracing/jaeger/cmd/query/app/sharing"
+	"github.com/jaegertracing/jaeger/cmd/query/app/slo"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	ui "github.com/jaegertracing/jaeger/model/json"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
 	"github.com/jaegertracing/jaeger/plugin/metrics/disabled"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
 	metricsmocks "github.com/jaegertracing/jaeger/storage/metricsstore/mocks"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -342,6 +357,59 @@ func TestGetTrace(t *testing.T) {
 	}
 }
 
+func TestGetTraceCriticalPath(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	traceID := model.NewTraceID(0, 1)
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), traceID).
+		Return(&model.Trace{Spans: []*model.Span{
+			{
+				TraceID:   traceID,
+				SpanID:    model.NewSpanID(1),
+				Process:   &model.Process{},
+				StartTime: time.Unix(10, 0),
+				Duration:  10 * time.Millisecond,
+			},
+			{
+				TraceID:    traceID,
+				SpanID:     model.NewSpanID(2),
+				References: []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+				Process:    &model.Process{},
+				StartTime:  time.Unix(10, 0).Add(2 * time.Millisecond),
+				Duration:   6 * time.Millisecond,
+			},
+		}}, nil).Once()
+
+	var response structuredTraceResponse
+	err := getJSON(ts.server.URL+`/api/traces/1?analysis=critical_path`, &response)
+	require.NoError(t, err)
+	require.Len(t, response.Traces, 1)
+	criticalPath := make(map[ui.SpanID]any)
+	for _, span := range response.Traces[0].Spans {
+		for _, tag := range span.Tags {
+			if tag.Key == adjuster.CriticalPathTagKey {
+				criticalPath[span.SpanID] = tag.Value
+			}
+		}
+	}
+	assert.Equal(t, map[ui.SpanID]any{
+		"0000000000000001": float64(4000),
+		"0000000000000002": float64(6000),
+	}, criticalPath)
+}
+
+func TestGetTraceUnsupportedAnalysis(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/1?analysis=magic`, &response)
+	require.EqualError(t, err, parsedError(400, "unsupported 'analysis' parameter: magic"))
+
+	err = getJSON(ts.server.URL+`/api/traces?service=svc&analysis=magic`, &response)
+	require.EqualError(t, err, parsedError(400, "unsupported 'analysis' parameter: magic"))
+}
+
 func TestGetTraceDBFailure(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -383,6 +451,39 @@ func TestGetTraceAdjustmentFailure(t *testing.T) {
 	assert.EqualValues(t, errAdjustment.Error(), response.Errors[0].Msg)
 }
 
+func TestGetTrace128BitID(t *testing.T) {
+	testCases := []struct {
+		param    string
+		traceID  model.TraceID
+		expected ui.TraceID
+	}{
+		{param: "0000000000000001000000000000002a", traceID: model.NewTraceID(1, 42), expected: "0000000000000001000000000000002a"},
+		{param: "8000000000000001000000000000002a", traceID: model.NewTraceID(0x8000000000000001, 42), expected: "8000000000000001000000000000002a"},
+		// the zero-padded high bits of the 64bit trace IDs are omitted in the responses
+		{param: "0000000000000000000000000000002a", traceID: model.NewTraceID(0, 42), expected: "000000000000002a"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.param, func(t *testing.T) {
+			ts := initializeTestServer()
+			defer ts.server.Close()
+			trace := &model.Trace{Spans: []*model.Span{{
+				TraceID: testCase.traceID,
+				SpanID:  model.NewSpanID(1),
+				Process: &model.Process{},
+			}}}
+			ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), testCase.traceID).
+				Return(trace, nil).Once()
+
+			var response structuredTraceResponse
+			require.NoError(t, getJSON(ts.server.URL+"/api/traces/"+testCase.param, &response))
+			assert.Empty(t, response.Errors)
+			require.Len(t, response.Traces, 1)
+			assert.Equal(t, testCase.expected, response.Traces[0].TraceID)
+			assert.Equal(t, testCase.expected, response.Traces[0].Spans[0].TraceID)
+		})
+	}
+}
+
 func TestGetTraceBadTraceID(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -392,6 +493,70 @@ func TestGetTraceBadTraceID(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestCompareTraces(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	baseTrace := &model.Trace{Spans: []*model.Span{{
+		TraceID:       model.NewTraceID(0, 1),
+		SpanID:        model.NewSpanID(1),
+		OperationName: "get",
+		Process:       &model.Process{ServiceName: "svc"},
+		Duration:      10 * time.Millisecond,
+	}}}
+	otherTrace := &model.Trace{Spans: []*model.Span{{
+		TraceID:       model.NewTraceID(0, 2),
+		SpanID:        model.NewSpanID(2),
+		OperationName: "get",
+		Process:       &model.Process{ServiceName: "svc"},
+		Duration:      15 * time.Millisecond,
+	}}}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 1)).Return(baseTrace, nil).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 2)).Return(otherTrace, nil).Once()
+
+	var response struct {
+		Data ui.TraceDiff `json:"data"`
+	}
+	err := getJSON(ts.server.URL+"/api/traces/1/diff/2", &response)
+	require.NoError(t, err)
+	assert.Equal(t, ui.TraceDiff{
+		BaseTraceID:  "0000000000000001",
+		OtherTraceID: "0000000000000002",
+		Spans: []ui.SpanDiff{{
+			Type:               "matched",
+			ServiceName:        "svc",
+			BaseSpanID:         "0000000000000001",
+			OtherSpanID:        "0000000000000002",
+			BaseOperationName:  "get",
+			OtherOperationName: "get",
+			BaseDuration:       10000,
+			OtherDuration:      15000,
+			DurationDelta:      5000,
+		}},
+	}, response.Data)
+}
+
+func TestCompareTracesFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 1)).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 3)).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/traces/1/diff/2", &response)
+	require.EqualError(t, err, parsedError(404, "cannot retrieve base trace 0000000000000001: trace not found"))
+
+	err = getJSON(ts.server.URL+"/api/traces/3/diff/2", &response)
+	require.Error(t, err)
+
+	err = getJSON(ts.server.URL+"/api/traces/chumbawumba/diff/2", &response)
+	require.Error(t, err)
+
+	err = getJSON(ts.server.URL+"/api/traces/1/diff/chumbawumba", &response)
+	require.Error(t, err)
+}
+
 func TestSearchSuccess(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -417,6 +582,90 @@ func TestSearchByTraceIDSuccess(t *testing.T) {
 	assert.Len(t, response.Data, 2)
 }
 
+func TestSearchByTraceIDPrefix(t *testing.T) {
+	store := memory.NewStore()
+	for _, traceID := range []model.TraceID{model.NewTraceID(0, 0xabcd0001), model.NewTraceID(0, 0xef000001)} {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID:   traceID,
+			SpanID:    model.NewSpanID(1),
+			StartTime: time.Now(),
+			Process:   &model.Process{ServiceName: "service"},
+		}))
+	}
+	qs := querysvc.NewQueryService(store, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	r := NewRouter()
+	NewAPIHandler(qs, &tenancy.Manager{}, HandlerOptions.Logger(zap.NewNop())).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	var response structuredTraceResponse
+	err := getJSON(server.URL+`/api/traces?traceIDPrefix=00000000ABCD`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Traces, 1)
+	assert.Equal(t, ui.TraceID("00000000abcd0001"), response.Traces[0].TraceID)
+}
+
+func TestSearchByTraceIDPrefixNotSupported(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?traceIDPrefix=abcd`, &response)
+	require.ErrorContains(t, err, "501 error from server")
+}
+
+func TestSearchByTagFiltersNotSupported(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		StorageCapabilities: &storage.Capabilities{TraceSearch: true, TagSearch: true},
+	})
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&tagFilter=http.status_code>=500`, &response)
+	require.ErrorContains(t, err, "501 error from server")
+}
+
+func TestSearchByLogFieldsNotSupported(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, spanstore.ErrLogFieldsNotSupported).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&log=event:exception`, &response)
+	require.ErrorContains(t, err, "501 error from server")
+}
+
+func TestSearchCostLimitExceeded(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		CostLimiter: querysvc.NewCostLimiter(querysvc.CostLimits{MaxSpans: 3, MaxSubQueries: 2}, nil),
+	})
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(mockTrace, nil)
+
+	resp, err := http.Get(ts.server.URL + `/api/traces?traceID=1&traceID=2&traceID=3`)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	var response struct {
+		Data   querysvc.CostLimitError `json:"data"`
+		Errors []structuredError       `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, querysvc.CostLimitError{Limit: "maxSubQueries", Max: "2", Value: "3"}, response.Data)
+	assert.Equal(t, []structuredError{{
+		Code: http.StatusUnprocessableEntity,
+		Msg:  "query cost limit exceeded: the query needs 3 storage queries, above the limit of 2",
+	}}, response.Errors)
+
+	err = getJSON(ts.server.URL+`/api/traces?traceID=1&traceID=2`, nil)
+	require.ErrorContains(t, err, "422 error from server")
+	require.ErrorContains(t, err, "the query returns 4 spans, above the limit of 3")
+	ts.spanReader.AssertNumberOfCalls(t, "GetTrace", 2)
+}
+
 func TestSearchByTraceIDSuccessWithArchive(t *testing.T) {
 	archiveReadMock := &spanstoremocks.Reader{}
 	ts := initializeTestServerWithOptions(&tenancy.Manager{}, querysvc.QueryServiceOptions{
@@ -576,6 +825,33 @@ func TestGetOperationsSuccess(t *testing.T) {
 	}
 }
 
+func TestGetOperationsPage(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On(
+		"GetOperations",
+		mock.AnythingOfType("*context.valueCtx"),
+		spanstore.OperationQueryParameters{
+			ServiceName: "trifle",
+			NamePrefix:  "GET /",
+			Offset:      10,
+			Limit:       5,
+		},
+	).Return([]spanstore.Operation{{Name: "GET /orders"}}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/operations?service=trifle&prefix=GET+%2F&offset=10&limit=5", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, 5, response.Limit)
+	assert.Equal(t, 10, response.Offset)
+
+	for _, query := range []string{"limit=abc", "offset=-1"} {
+		err = getJSON(ts.server.URL+"/api/operations?service=trifle&"+query, &response)
+		require.ErrorContains(t, err, "400 error", query)
+	}
+}
+
 func TestGetOperationsNoServiceName(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -598,6 +874,1009 @@ func TestGetOperationsStorageFailure(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGetDurationsSuccess(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "svc" && q.OperationName == "op"
+	})).Return([]*model.Trace{{
+		Spans: []*model.Span{
+			{
+				OperationName: "op",
+				Process:       &model.Process{ServiceName: "svc"},
+				StartTime:     time.Unix(0, 0).Add(2 * time.Second),
+				Duration:      5 * time.Millisecond,
+			},
+		},
+	}}, nil).Once()
+
+	var response struct {
+		Data ui.LatencyDistribution `json:"data"`
+	}
+	err := getJSON(ts.server.URL+"/api/durations?service=svc&operation=op&start=1000000&end=3000000&bucket=1ms&bucket=10ms", &response)
+	require.NoError(t, err)
+	assert.Equal(t, ui.LatencyDistribution{
+		Count:        1,
+		P50:          5000,
+		P95:          5000,
+		P99:          5000,
+		BucketBounds: []uint64{1000, 10000},
+		BucketCounts: []int64{0, 1, 0},
+	}, response.Data)
+}
+
+func TestGetDurationsFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	for _, query := range []string{
+		"",
+		"?service=svc&start=abc",
+		"?service=svc&end=abc",
+		"?service=svc&bucket=abc",
+		"?service=svc&bucket=10ms&bucket=1ms",
+		"?service=svc",
+	} {
+		var response structuredResponse
+		err := getJSON(ts.server.URL+"/api/durations"+query, &response)
+		require.Error(t, err, query)
+	}
+}
+
+func TestFindExemplarTraces(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "svc" && q.OperationName == "op" &&
+			q.StartTimeMin.Equal(time.Unix(0, 0).Add(900*time.Millisecond)) && q.StartTimeMax.Equal(time.Unix(0, 0).Add(3*time.Second))
+	})).Return([]*model.Trace{
+		{Spans: []*model.Span{{
+			TraceID:       model.NewTraceID(0, 1),
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			Process:       &model.Process{ServiceName: "svc"},
+			StartTime:     time.Unix(0, 0).Add(1900 * time.Millisecond),
+			Duration:      200 * time.Millisecond,
+		}}},
+		{Spans: []*model.Span{{
+			TraceID:       model.NewTraceID(0, 2),
+			SpanID:        model.NewSpanID(2),
+			OperationName: "op",
+			Process:       &model.Process{ServiceName: "svc"},
+			StartTime:     time.Unix(0, 0).Add(1600 * time.Millisecond),
+			Duration:      100 * time.Millisecond,
+		}}},
+	}, nil).Once()
+
+	var response struct {
+		Data  []ui.ExemplarCandidate `json:"data"`
+		Total int                    `json:"total"`
+	}
+	err := getJSON(ts.server.URL+"/api/exemplars/traces?service=svc&operation=op&timestamp=2000000&latency=100ms&window=1s", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, []ui.ExemplarCandidate{
+		{
+			TraceID:       "0000000000000002",
+			SpanID:        "0000000000000002",
+			OperationName: "op",
+			StartTime:     1600000,
+			Duration:      100000,
+			TimeOffset:    300000,
+			LatencyDelta:  0,
+		},
+		{
+			TraceID:       "0000000000000001",
+			SpanID:        "0000000000000001",
+			OperationName: "op",
+			StartTime:     1900000,
+			Duration:      200000,
+			TimeOffset:    0,
+			LatencyDelta:  100000,
+		},
+	}, response.Data)
+}
+
+func TestFindExemplarTracesFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	for _, query := range []string{
+		"",
+		"?service=svc",
+		"?service=svc&timestamp=abc",
+		"?service=svc&timestamp=1&latency=abc",
+		"?service=svc&timestamp=1&window=abc",
+		"?service=svc&timestamp=1&limit=abc",
+		"?service=svc&timestamp=1",
+	} {
+		var response structuredResponse
+		err := getJSON(ts.server.URL+"/api/exemplars/traces"+query, &response)
+		require.Error(t, err, query)
+	}
+}
+
+func TestFindCallPaths(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	traceID := model.NewTraceID(0, 1)
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "svc" && q.OperationName == "" &&
+			q.StartTimeMin.Equal(time.Unix(0, 0).Add(time.Second)) && q.StartTimeMax.Equal(time.Unix(0, 0).Add(3*time.Second))
+	})).Return([]*model.Trace{
+		{Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "op",
+				Process:       &model.Process{ServiceName: "svc"},
+				Duration:      200 * time.Millisecond,
+			},
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(2),
+				References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+				OperationName: "query",
+				Process:       &model.Process{ServiceName: "db"},
+				Duration:      100 * time.Millisecond,
+			},
+		}},
+	}, nil).Once()
+
+	var response struct {
+		Data  []ui.CallPath `json:"data"`
+		Total int           `json:"total"`
+	}
+	err := getJSON(ts.server.URL+"/api/call-paths?service=svc&start=1000000&end=3000000", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, []ui.CallPath{{
+		Hops: []ui.CallHop{
+			{ServiceName: "svc", OperationName: "op", P50: 200000, P95: 200000, P99: 200000},
+			{ServiceName: "db", OperationName: "query", P50: 100000, P95: 100000, P99: 100000},
+		},
+		Count:      1,
+		TraceCount: 1,
+	}}, response.Data)
+}
+
+func TestFindQualityScores(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetServices", mock.AnythingOfType("*context.valueCtx")).Return([]string{"svc", "idle"}, nil).Once()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "svc" &&
+			q.StartTimeMin.Equal(time.Unix(0, 0).Add(time.Second)) && q.StartTimeMax.Equal(time.Unix(0, 0).Add(3*time.Second))
+	})).Return([]*model.Trace{
+		{Spans: []*model.Span{
+			{
+				TraceID:       model.NewTraceID(0, 1),
+				SpanID:        model.NewSpanID(1),
+				OperationName: "op",
+				Process:       &model.Process{ServiceName: "svc"},
+				Tags:          model.KeyValues{model.String("http.method", "GET")},
+			},
+		}},
+	}, nil).Once()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "idle"
+	})).Return(nil, nil).Once()
+
+	var response struct {
+		Data  []ui.QualityScore `json:"data"`
+		Total int               `json:"total"`
+	}
+	err := getJSON(ts.server.URL+"/api/quality/scores?start=1000000&end=3000000", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Total, "the services without spans are left out")
+	assert.Equal(t, []ui.QualityScore{{
+		ServiceName: "svc",
+		SpanCount:   1,
+		TraceCount:  1,
+		Score:       1.0 / 3,
+		Checks: []ui.QualityCheckResult{
+			{Check: "span-kind", Checked: 1, Failed: 1, Score: 0},
+			{Check: "http-attributes", Checked: 1, Failed: 1, Score: 0},
+			{Check: "error-recording", Score: 1},
+			{Check: "clock-skew", Checked: 1, Score: 1},
+		},
+	}}, response.Data)
+}
+
+func TestFindQualityScoresFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	for _, query := range []string{
+		"?start=abc",
+		"?end=abc",
+		"?service=svc",
+	} {
+		var response structuredResponse
+		err := getJSON(ts.server.URL+"/api/quality/scores"+query, &response)
+		require.Error(t, err, query)
+	}
+}
+
+func TestFindCallPathsFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	for _, query := range []string{
+		"",
+		"?service=svc&start=abc",
+		"?service=svc&end=abc",
+		"?service=svc&limit=abc",
+		"?service=svc",
+	} {
+		var response structuredResponse
+		err := getJSON(ts.server.URL+"/api/call-paths"+query, &response)
+		require.Error(t, err, query)
+	}
+}
+
+func TestGetRegressions(t *testing.T) {
+	store := regression.NewStore(10)
+	detectedAt := time.Unix(0, 0).Add(2 * time.Second).UTC()
+	annotation := regression.Annotation{
+		ServiceName:   "svc",
+		OperationName: "op",
+		Kind:          regression.KindLatency,
+		DetectedAt:    detectedAt,
+		WindowStart:   detectedAt.Add(-time.Second),
+		WindowEnd:     detectedAt,
+		Baseline:      1000,
+		Current:       3000,
+	}
+	store.Add(annotation)
+	store.Add(regression.Annotation{ServiceName: "other", DetectedAt: detectedAt})
+	ts := initializeTestServer(HandlerOptions.Regressions(store))
+	defer ts.server.Close()
+
+	var response struct {
+		Data []regression.Annotation `json:"data"`
+	}
+	err := getJSON(ts.server.URL+"/api/regressions?service=svc&start=1000000&end=3000000", &response)
+	require.NoError(t, err)
+	assert.Equal(t, []regression.Annotation{annotation}, response.Data)
+
+	err = getJSON(ts.server.URL+"/api/regressions?start=abc", &response)
+	require.ErrorContains(t, err, "400 error")
+}
+
+func TestGetRegressionsDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/regressions", &response)
+	require.ErrorContains(t, err, "501 error")
+}
+
+func TestGetTraceLogs(t *testing.T) {
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("query"), mockTraceID.String())
+		w.Write([]byte(`{"data": {"result": [{"stream": {"service_name": "service"}, "values": [["1000000000", "card declined"]]}]}}`))
+	}))
+	defer loki.Close()
+	correlator, err := logs.NewCorrelator(logs.Options{Backend: logs.BackendLoki, Endpoint: loki.URL, Limit: 10, LokiServiceLabel: "service_name"})
+	require.NoError(t, err)
+	ts := initializeTestServer(HandlerOptions.LogCorrelator(correlator))
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mockTraceID).
+		Return(mockTrace, nil).Once()
+
+	var response struct {
+		Data  []logs.LogLine `json:"data"`
+		Total int            `json:"total"`
+	}
+	err = getJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"/logs", &response)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, []logs.LogLine{{Timestamp: time.Unix(1, 0).UTC(), Service: "service", Message: "card declined"}}, response.Data)
+}
+
+func TestGetTraceLogsErrors(t *testing.T) {
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer loki.Close()
+	correlator, err := logs.NewCorrelator(logs.Options{Backend: logs.BackendLoki, Endpoint: loki.URL, Limit: 10})
+	require.NoError(t, err)
+	ts := initializeTestServer(HandlerOptions.LogCorrelator(correlator))
+	defer ts.server.Close()
+	url := ts.server.URL + "/api/traces/" + mockTraceID.String() + "/logs"
+
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mockTraceID).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+	err = getJSON(url, &structuredResponse{})
+	require.ErrorContains(t, err, "404 error")
+
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mockTraceID).
+		Return(mockTrace, nil).Once()
+	err = getJSON(url, &structuredResponse{})
+	require.ErrorContains(t, err, "502 error")
+
+	err = getJSON(ts.server.URL+"/api/traces/xyz/logs", &structuredResponse{})
+	require.ErrorContains(t, err, "400 error")
+}
+
+func TestGetTraceLogsDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	err := getJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"/logs", &structuredResponse{})
+	require.ErrorContains(t, err, "501 error")
+}
+
+func newExportServer(t *testing.T, reader spanstore.Reader) *httptest.Server {
+	qs := querysvc.NewQueryService(reader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	exporter, err := export.NewExporter(export.Options{MaxDuration: time.Minute, MaxConcurrent: 1, MaxTraces: 100, PageSize: 10}, qs)
+	require.NoError(t, err)
+	r := NewRouter()
+	NewAPIHandler(qs, &tenancy.Manager{}, HandlerOptions.Logger(zap.NewNop()), HandlerOptions.TraceExporter(exporter)).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExportTraces(t *testing.T) {
+	store := memory.NewStore()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID:       model.NewTraceID(0, uint64(i)),
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			StartTime:     time.Now(),
+			Process:       &model.Process{ServiceName: "service"},
+		}))
+	}
+	server := newExportServer(t, store)
+
+	resp, err := http.Get(server.URL + "/api/export/traces?service=service&limit=2")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	scanner := bufio.NewScanner(resp.Body)
+	var traces []ui.Trace
+	for scanner.Scan() {
+		var trace ui.Trace
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &trace))
+		traces = append(traces, trace)
+	}
+	require.Len(t, traces, 2)
+	assert.Equal(t, "op", traces[0].Spans[0].OperationName)
+	assert.Empty(t, resp.Trailer.Get(exportErrorTrailer))
+
+	resp, err = http.Get(server.URL + "/api/export/traces?service=service&format=otlp_proto")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-protobuf", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	spans := 0
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), 4)
+		size := binary.BigEndian.Uint32(body)
+		td, err := new(ptrace.ProtoUnmarshaler).UnmarshalTraces(body[4 : 4+size])
+		require.NoError(t, err)
+		spans += td.SpanCount()
+		body = body[4+size:]
+	}
+	assert.Equal(t, 3, spans)
+
+	// no trace matches the query
+	resp, err = http.Get(server.URL + "/api/export/traces?service=other")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+}
+
+func TestExportTracesErrors(t *testing.T) {
+	pageReader := &spanstoremocks.PaginatedReader{}
+	nextPageToken := spanstore.EncodePageToken(2)
+	pageReader.On("FindTracesPage", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "paged" && q.PageToken == ""
+	})).Return(&spanstore.TracesPage{Traces: []*model.Trace{mockTrace}, NextPageToken: nextPageToken}, nil)
+	pageReader.On("FindTracesPage", mock.Anything, mock.Anything).Return(nil, errStorage)
+	server := newExportServer(t, &struct {
+		*spanstoremocks.Reader
+		*spanstoremocks.PaginatedReader
+	}{&spanstoremocks.Reader{}, pageReader})
+
+	for _, tc := range []struct {
+		query  string
+		status int
+	}{
+		{query: "", status: http.StatusBadRequest},
+		{query: "?service=service&format=xml", status: http.StatusBadRequest},
+		{query: "?service=service", status: http.StatusInternalServerError},
+	} {
+		err := getJSON(server.URL+"/api/export/traces"+tc.query, &structuredResponse{})
+		require.ErrorContains(t, err, fmt.Sprintf("%d error from server", tc.status), tc.query)
+	}
+
+	// the errors after the first trace are reported in the trailer
+	resp, err := http.Get(server.URL + "/api/export/traces?service=paged")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, errStorage.Error(), resp.Trailer.Get(exportErrorTrailer))
+}
+
+func TestExportTracesDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	err := getJSON(ts.server.URL+"/api/export/traces?service=service", &structuredResponse{})
+	require.ErrorContains(t, err, "501 error")
+}
+
+func TestGetWarnings(t *testing.T) {
+	store := memory.NewWarningStore(0)
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		WarningStore:    store,
+		WarningRecorder: querysvc.NewWarningRecorder(store, zap.NewNop()),
+	})
+	defer ts.server.Close()
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:   mockTraceID,
+				SpanID:    model.NewSpanID(1),
+				StartTime: time.Unix(0, 0).Add(2 * time.Second),
+				Process:   &model.Process{ServiceName: "svc"},
+			},
+			{
+				TraceID:    mockTraceID,
+				SpanID:     model.NewSpanID(2),
+				StartTime:  time.Unix(0, 0).Add(2 * time.Second),
+				Process:    &model.Process{ServiceName: "svc"},
+				References: []model.SpanRef{{TraceID: model.NewTraceID(0, 0)}},
+			},
+		},
+	}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mockTraceID).
+		Return(trace, nil).Once()
+	var response structuredResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/traces/"+mockTraceID.String(), &response))
+
+	var warnings struct {
+		Data []traceWarnings `json:"data"`
+	}
+	// the warnings are recorded in the background
+	require.Eventually(t, func() bool {
+		err := getJSON(ts.server.URL+"/api/warnings?type=invalid-reference&start=1000000&end=3000000", &warnings)
+		return err == nil && len(warnings.Data) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, ui.TraceID(mockTraceID.String()), warnings.Data[0].TraceID)
+	assert.Equal(t, uint64(2000000), warnings.Data[0].StartTime)
+	assert.Equal(t, []string{"svc"}, warnings.Data[0].Services)
+	require.Len(t, warnings.Data[0].Warnings, 1)
+	assert.Equal(t, ui.SpanID(model.NewSpanID(2).String()), warnings.Data[0].Warnings[0].SpanID)
+	assert.Equal(t, adjuster.WarningTypeInvalidReference, warnings.Data[0].Warnings[0].Type)
+
+	err := getJSON(ts.server.URL+"/api/warnings?type=clock-skew", &warnings)
+	require.NoError(t, err)
+	assert.Empty(t, warnings.Data)
+
+	for _, query := range []string{"type=unknown", "start=abc", "limit=abc"} {
+		err = getJSON(ts.server.URL+"/api/warnings?"+query, &warnings)
+		require.ErrorContains(t, err, "400 error", query)
+	}
+}
+
+func TestGetWarningsDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/warnings", &response)
+	require.ErrorContains(t, err, "501 error")
+}
+
+func TestServiceMetadataAPI(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{MetadataStore: memory.NewMetadataStore()})
+	defer ts.server.Close()
+	url := ts.server.URL + "/api/services/abc%2Ftrifle/metadata"
+
+	var response struct {
+		Data serviceMetadata `json:"data"`
+	}
+	err := getJSON(url, &response)
+	require.ErrorContains(t, err, "404 error")
+
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(`{"team": "web", "onCallURL": "https://oncall/web"}`))
+	require.NoError(t, err)
+	require.NoError(t, execJSON(req, map[string]string{}, &response))
+	expected := serviceMetadata{ServiceName: "abc/trifle", Team: "web", OnCallURL: "https://oncall/web"}
+	assert.Equal(t, expected, response.Data)
+
+	require.NoError(t, getJSON(url, &response))
+	assert.Equal(t, expected, response.Data)
+
+	var list struct {
+		Data []serviceMetadata `json:"data"`
+	}
+	require.NoError(t, getJSON(ts.server.URL+"/api/service-metadata", &list))
+	assert.Equal(t, []serviceMetadata{expected}, list.Data)
+
+	for _, body := range []string{`{`, `{"serviceName": "other"}`} {
+		req, err = http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		require.NoError(t, err)
+		require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "400 error", body)
+	}
+
+	req, err = http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	var deleted structuredResponse
+	require.NoError(t, execJSON(req, map[string]string{}, &deleted))
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &deleted), "404 error")
+}
+
+func TestServiceMetadataAPIDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/service-metadata", &response)
+	require.ErrorContains(t, err, "501 error")
+	err = getJSON(ts.server.URL+"/api/services/trifle/metadata", &response)
+	require.ErrorContains(t, err, "501 error")
+}
+
+func TestSamplingStrategiesAPI(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{StrategyStore: memory.NewStrategyStore()},
+		HandlerOptions.SamplingAdminToken("secret"))
+	defer ts.server.Close()
+	url := ts.server.URL + "/api/sampling-strategies/abc%2Ftrifle"
+	admin := map[string]string{"Authorization": "Bearer secret"}
+	exec := func(method string, url string, body string, headers map[string]string, out any) error {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		return execJSON(req, headers, out)
+	}
+
+	var response struct {
+		Data samplingStrategy `json:"data"`
+	}
+	require.ErrorContains(t, exec(http.MethodGet, url, "", admin, &response), "404 error")
+
+	strategy := `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.5}}`
+	require.NoError(t, exec(http.MethodPut, url, strategy, admin, &response))
+	expected := samplingStrategy{ServiceName: "abc/trifle", Strategy: json.RawMessage(strategy)}
+	assert.Equal(t, expected, response.Data)
+
+	require.NoError(t, exec(http.MethodGet, url, "", admin, &response))
+	assert.Equal(t, expected, response.Data)
+
+	var list struct {
+		Data []samplingStrategy `json:"data"`
+	}
+	require.NoError(t, exec(http.MethodPut, ts.server.URL+"/api/sampling-strategies/aaa", strategy, admin, &response))
+	require.NoError(t, exec(http.MethodGet, ts.server.URL+"/api/sampling-strategies", "", admin, &list))
+	assert.Equal(t, []samplingStrategy{{ServiceName: "aaa", Strategy: json.RawMessage(strategy)}, expected}, list.Data)
+
+	for _, body := range []string{`{`, `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":2}}`} {
+		require.ErrorContains(t, exec(http.MethodPut, url, body, admin, &response), "400 error", body)
+	}
+
+	var deleted structuredResponse
+	require.NoError(t, exec(http.MethodDelete, url, "", admin, &deleted))
+	require.ErrorContains(t, exec(http.MethodDelete, url, "", admin, &deleted), "404 error")
+}
+
+func TestSamplingStrategiesAPIRequiresAdmin(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{StrategyStore: memory.NewStrategyStore()},
+		HandlerOptions.SamplingAdminToken("secret"))
+	defer ts.server.Close()
+	for _, headers := range []map[string]string{
+		{},
+		{"Authorization": "Bearer other"},
+		{"Authorization": "secret"},
+	} {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			req, err := http.NewRequest(method, ts.server.URL+"/api/sampling-strategies/trifle", strings.NewReader(`{}`))
+			require.NoError(t, err)
+			require.ErrorContains(t, execJSON(req, headers, &structuredResponse{}), "401 error")
+		}
+	}
+}
+
+func TestSamplingStrategiesAPIDisabled(t *testing.T) {
+	// the API is not served without an admin token
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{StrategyStore: memory.NewStrategyStore()})
+	defer ts.server.Close()
+	var response structuredResponse
+	require.ErrorContains(t, getJSON(ts.server.URL+"/api/sampling-strategies", &response), "404 error")
+
+	// nor without a storage supporting it
+	ts = initializeTestServer(HandlerOptions.SamplingAdminToken("secret"))
+	defer ts.server.Close()
+	req, err := http.NewRequest(http.MethodGet, ts.server.URL+"/api/sampling-strategies", nil)
+	require.NoError(t, err)
+	require.ErrorContains(t, execJSON(req, map[string]string{"Authorization": "Bearer secret"}, &response), "501 error")
+}
+
+func TestSavedSearchesAPI(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{SavedSearchStore: memory.NewSavedSearchStore()})
+	defer ts.server.Close()
+	url := ts.server.URL + "/api/saved-searches/slow-checkout"
+
+	var response struct {
+		Data savedSearch `json:"data"`
+	}
+	err := getJSON(url, &response)
+	require.ErrorContains(t, err, "404 error")
+
+	body := `{"service": "frontend", "operation": "checkout", "tags": {"error": "true"}, "minDuration": "1.5s", "lookback": "1h", "limit": 20, "shared": true}`
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, execJSON(req, map[string]string{}, &response))
+	assert.False(t, response.Data.UpdatedAt.IsZero())
+	expected := savedSearch{
+		Name:        "slow-checkout",
+		Shared:      true,
+		Service:     "frontend",
+		Operation:   "checkout",
+		Tags:        map[string]string{"error": "true"},
+		MinDuration: "1.5s",
+		Lookback:    "1h0m0s",
+		Limit:       20,
+		UpdatedAt:   response.Data.UpdatedAt,
+	}
+	assert.Equal(t, expected, response.Data)
+
+	require.NoError(t, getJSON(url, &response))
+	assert.Equal(t, expected, response.Data)
+
+	var list struct {
+		Data []savedSearch `json:"data"`
+	}
+	require.NoError(t, getJSON(ts.server.URL+"/api/saved-searches", &list))
+	assert.Equal(t, []savedSearch{expected}, list.Data)
+
+	for _, test := range []struct {
+		url  string
+		body string
+	}{
+		{url: url, body: `{`},
+		{url: url, body: `{"name": "other", "service": "frontend"}`},
+		{url: url, body: `{"operation": "checkout"}`},
+		{url: url, body: `{"service": "frontend", "maxDuration": "forever"}`},
+		{url: url, body: `{"service": "frontend", "limit": -1}`},
+		{url: ts.server.URL + "/api/saved-searches/slow%20checkout", body: `{"service": "frontend"}`},
+	} {
+		req, err = http.NewRequest(http.MethodPut, test.url, strings.NewReader(test.body))
+		require.NoError(t, err)
+		require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "400 error", test.body)
+	}
+
+	req, err = http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	var deleted structuredResponse
+	require.NoError(t, execJSON(req, map[string]string{}, &deleted))
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &deleted), "404 error")
+}
+
+func TestSavedSearchesAPIDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/saved-searches", &response)
+	require.ErrorContains(t, err, "501 error")
+	err = getJSON(ts.server.URL+"/api/saved-searches/slow", &response)
+	require.ErrorContains(t, err, "501 error")
+}
+
+func newTestAlertingManager(t *testing.T, querySvc *querysvc.QueryService) *alerting.Manager {
+	manager, err := alerting.NewManager(alerting.Options{Interval: time.Minute, Source: alerting.SourceSpans}, querySvc, nil, zap.NewNop())
+	require.NoError(t, err)
+	return manager
+}
+
+func TestAlertingRulesAPI(t *testing.T) {
+	var manager *alerting.Manager
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{}, func(aH *APIHandler) {
+		manager = newTestAlertingManager(t, aH.queryService)
+		HandlerOptions.AlertingRules(manager)(aH)
+	})
+	defer ts.server.Close()
+	url := ts.server.URL + "/api/alerting/rules/checkout-errors"
+
+	var response struct {
+		Data alerting.RuleStatus `json:"data"`
+	}
+	err := getJSON(url, &response)
+	require.ErrorContains(t, err, "404 error")
+
+	body := `{"service": "frontend", "operation": "checkout", "signal": "error_rate", "operator": ">", "threshold": 0.05, "window": "10m"}`
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, execJSON(req, map[string]string{}, &response))
+	assert.Equal(t, "checkout-errors", response.Data.Name)
+	assert.Equal(t, alerting.SignalErrorRate, response.Data.Signal)
+	assert.Equal(t, alerting.StateOK, response.Data.Status.State)
+
+	require.NoError(t, getJSON(url, &response))
+	assert.Equal(t, "frontend", response.Data.Service)
+	assert.Equal(t, "10m", response.Data.Window)
+
+	var list struct {
+		Data []alerting.RuleStatus `json:"data"`
+	}
+	require.NoError(t, getJSON(ts.server.URL+"/api/alerting/rules", &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "checkout-errors", list.Data[0].Name)
+
+	for _, body := range []string{
+		`{`,
+		`{"name": "other", "service": "frontend", "signal": "error_rate", "operator": ">"}`,
+		`{"service": "frontend", "signal": "throughput", "operator": ">"}`,
+		`{"service": "frontend", "signal": "latency", "quantile": 0.9, "operator": ">"}`,
+	} {
+		req, err = http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		require.NoError(t, err)
+		require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "400 error", body)
+	}
+
+	req, err = http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	var deleted structuredResponse
+	require.NoError(t, execJSON(req, map[string]string{}, &deleted))
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &deleted), "404 error")
+	assert.Empty(t, manager.Rules(context.Background()))
+}
+
+func TestAlertingRulesAPIAuthorization(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		Authorizer: querysvc.NewServiceAuthorizer([]querysvc.AuthorizationRule{
+			{Claim: "sub", Value: "alice", Services: []string{"frontend"}},
+		}),
+	}, func(aH *APIHandler) {
+		manager := newTestAlertingManager(t, aH.queryService.WithoutAuthorization())
+		_, err := manager.PutRule(context.Background(), alerting.Rule{
+			Name: "billing-errors", Service: "billing", Signal: alerting.SignalErrorRate, Operator: ">", Threshold: 0.05,
+		})
+		require.NoError(t, err)
+		HandlerOptions.AlertingRules(manager)(aH)
+	})
+	defer ts.server.Close()
+
+	// the callers without access to the service of a rule can neither see nor change it
+	var list structuredResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/alerting/rules", &list))
+	assert.Equal(t, 0, list.Total)
+	var response structuredResponse
+	url := ts.server.URL + "/api/alerting/rules/billing-errors"
+	require.ErrorContains(t, getJSON(url, &response), "403 error")
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "403 error")
+	req, err = http.NewRequest(http.MethodPut, url, strings.NewReader(`{"service": "billing", "signal": "error_rate", "operator": ">"}`))
+	require.NoError(t, err)
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "403 error")
+}
+
+func TestAlertingRulesAPIDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/alerting/rules", &response)
+	require.ErrorContains(t, err, "501 error")
+	err = getJSON(ts.server.URL+"/api/alerting/rules/errors", &response)
+	require.ErrorContains(t, err, "501 error")
+}
+
+func TestSLOsAPI(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{}, func(aH *APIHandler) {
+		tracker, err := slo.NewTracker(slo.Options{Source: slo.SourceSpans}, aH.queryService, nil)
+		require.NoError(t, err)
+		HandlerOptions.SLOs(tracker)(aH)
+	})
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).Return([]*model.Trace{}, nil)
+	url := ts.server.URL + "/api/slos/checkout-latency"
+
+	var response struct {
+		Data slo.SLO `json:"data"`
+	}
+	err := getJSON(url, &response)
+	require.ErrorContains(t, err, "404 error")
+
+	body := `{"service": "frontend", "operation": "checkout", "type": "latency", "objective": 0.99, "latencyThreshold": 300, "period": "168h"}`
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, execJSON(req, map[string]string{}, &response))
+	assert.Equal(t, "checkout-latency", response.Data.Name)
+	assert.Equal(t, slo.TypeLatency, response.Data.Type)
+
+	require.NoError(t, getJSON(url, &response))
+	assert.Equal(t, "frontend", response.Data.Service)
+	assert.Equal(t, "168h", response.Data.Period)
+
+	var list struct {
+		Data []slo.SLO `json:"data"`
+	}
+	require.NoError(t, getJSON(ts.server.URL+"/api/slos", &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "checkout-latency", list.Data[0].Name)
+
+	var status struct {
+		Data slo.Status `json:"data"`
+	}
+	require.NoError(t, getJSON(url+"/status", &status))
+	assert.Equal(t, "checkout-latency", status.Data.Name)
+	assert.Equal(t, "168h0m0s", status.Data.Period)
+	// there are no spans
+	assert.Nil(t, status.Data.Compliance)
+	require.Len(t, status.Data.BurnRates, 3)
+	assert.Equal(t, "1h0m0s", status.Data.BurnRates[0].Window)
+	assert.Nil(t, status.Data.BurnRates[0].BurnRate)
+
+	for _, body := range []string{
+		`{`,
+		`{"name": "other", "service": "frontend", "type": "availability", "objective": 0.99}`,
+		`{"service": "frontend", "type": "availability", "objective": 99.9}`,
+		`{"service": "frontend", "type": "latency", "objective": 0.99}`,
+	} {
+		req, err = http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		require.NoError(t, err)
+		require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "400 error", body)
+	}
+
+	req, err = http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	var deleted structuredResponse
+	require.NoError(t, execJSON(req, map[string]string{}, &deleted))
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &deleted), "404 error")
+	require.ErrorContains(t, getJSON(url+"/status", &status), "404 error")
+}
+
+func TestSLOsAPIStatusError(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{}, func(aH *APIHandler) {
+		tracker, err := slo.NewTracker(slo.Options{Source: slo.SourceSpans}, aH.queryService, nil)
+		require.NoError(t, err)
+		_, err = tracker.PutObjective(context.Background(), slo.SLO{Name: "availability", Service: "frontend", Type: slo.TypeAvailability, Objective: 0.999})
+		require.NoError(t, err)
+		HandlerOptions.SLOs(tracker)(aH)
+	})
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).Return(nil, errStorage)
+
+	var status structuredResponse
+	require.ErrorContains(t, getJSON(ts.server.URL+"/api/slos/availability/status", &status), "500 error")
+}
+
+func TestSLOsAPIAuthorization(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		Authorizer: querysvc.NewServiceAuthorizer([]querysvc.AuthorizationRule{
+			{Claim: "sub", Value: "alice", Services: []string{"frontend"}},
+		}),
+	}, func(aH *APIHandler) {
+		tracker, err := slo.NewTracker(slo.Options{Source: slo.SourceSpans}, aH.queryService, nil)
+		require.NoError(t, err)
+		_, err = tracker.PutObjective(context.Background(), slo.SLO{Name: "billing", Service: "billing", Type: slo.TypeAvailability, Objective: 0.999})
+		require.NoError(t, err)
+		HandlerOptions.SLOs(tracker)(aH)
+	})
+	defer ts.server.Close()
+
+	// the callers without access to the service of an SLO can neither see nor change it
+	var list structuredResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/slos", &list))
+	assert.Equal(t, 0, list.Total)
+	var response structuredResponse
+	url := ts.server.URL + "/api/slos/billing"
+	require.ErrorContains(t, getJSON(url, &response), "403 error")
+	require.ErrorContains(t, getJSON(url+"/status", &response), "403 error")
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "403 error")
+	req, err = http.NewRequest(http.MethodPut, url, strings.NewReader(`{"service": "billing", "type": "availability", "objective": 0.99}`))
+	require.NoError(t, err)
+	require.ErrorContains(t, execJSON(req, map[string]string{}, &response), "403 error")
+}
+
+func TestSLOsAPIDisabled(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/slos", &response)
+	require.ErrorContains(t, err, "501 error")
+	err = getJSON(ts.server.URL+"/api/slos/availability", &response)
+	require.ErrorContains(t, err, "501 error")
+	err = getJSON(ts.server.URL+"/api/slos/availability/status", &response)
+	require.ErrorContains(t, err, "501 error")
+}
+
+func TestShareTrace(t *testing.T) {
+	signer, err := sharing.NewSigner(sharing.Options{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	ts := initializeTestServer(HandlerOptions.TraceSharing(signer))
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(mockTrace, nil)
+
+	var response struct {
+		Data sharedTraceLink `json:"data"`
+	}
+	err = postJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"/share?ttl=2h", nil, &response)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/shared-traces/"+response.Data.Token, response.Data.Path)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), response.Data.ExpiresAt, time.Minute)
+
+	var traceResponse structuredTraceResponse
+	err = getJSON(ts.server.URL+response.Data.Path, &traceResponse)
+	require.NoError(t, err)
+	require.Len(t, traceResponse.Traces, 1)
+	assert.Equal(t, ui.TraceID(mockTraceID.String()), traceResponse.Traces[0].TraceID)
+
+	err = getJSON(ts.server.URL+"/api/shared-traces/invalid", &traceResponse)
+	require.ErrorContains(t, err, "401 error")
+	err = postJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"/share?ttl=48h", nil, &response)
+	require.ErrorContains(t, err, "400 error")
+	err = postJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"/share?ttl=abc", nil, &response)
+	require.ErrorContains(t, err, "400 error")
+}
+
+func TestShareTraceAuthorization(t *testing.T) {
+	signer, err := sharing.NewSigner(sharing.Options{DefaultTTL: time.Hour, MaxTTL: time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		Authorizer: querysvc.NewServiceAuthorizer([]querysvc.AuthorizationRule{
+			{Claim: "sub", Value: "alice", Services: []string{"*"}},
+		}),
+	}, HandlerOptions.TraceSharing(signer))
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(mockTrace, nil)
+
+	// the caller without access to the services of the trace cannot share it
+	var response structuredResponse
+	err = postJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"/share", nil, &response)
+	require.ErrorContains(t, err, "404 error")
+
+	// but the recipients of a token do not need access to the services
+	token, _, err := signer.Sign(mockTraceID, "", 0)
+	require.NoError(t, err)
+	var traceResponse structuredTraceResponse
+	err = getJSON(ts.server.URL+"/api/shared-traces/"+token, &traceResponse)
+	require.NoError(t, err)
+	assert.Len(t, traceResponse.Traces, 1)
+}
+
+func TestSharedTraceTenancy(t *testing.T) {
+	signer, err := sharing.NewSigner(sharing.Options{DefaultTTL: time.Hour, MaxTTL: time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	tenancyMgr := tenancy.NewManager(&tenancy.Options{Enabled: true})
+	readStorage := &spanstoremocks.Reader{}
+	qs := querysvc.NewQueryService(readStorage, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	r := NewRouter()
+	NewAPIHandler(qs, tenancyMgr, HandlerOptions.TraceSharing(signer)).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	readStorage.On("GetTrace", mock.MatchedBy(func(ctx context.Context) bool {
+		return tenancy.GetTenant(ctx) == "acme"
+	}), mockTraceID).Return(mockTrace, nil)
+
+	token, _, err := signer.Sign(mockTraceID, "acme", 0)
+	require.NoError(t, err)
+	var traceResponse structuredTraceResponse
+	// no tenancy header, the tenant is the one of the token
+	err = getJSON(server.URL+"/api/shared-traces/"+token, &traceResponse)
+	require.NoError(t, err)
+	assert.Len(t, traceResponse.Traces, 1)
+}
+
 func TestGetOperationsLegacySuccess(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -1039,3 +2318,110 @@ func TestSearchTenancyFlowTenantHTTP(t *testing.T) {
 	assert.Empty(t, responseMegacorp.Errors)
 	assert.Nil(t, responseMegacorp.Data)
 }
+
+func TestLiveTailAPI(t *testing.T) {
+	broadcaster := tracestream.NewBroadcaster()
+	options := livetail.Options{PollInterval: 10 * time.Millisecond, MaxSubscribers: 1}
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{}, func(aH *APIHandler) {
+		HandlerOptions.LiveTail(livetail.NewTailer(options, aH.queryService, broadcaster, zap.NewNop()))(aH)
+	})
+	defer ts.server.Close()
+	trace := &model.Trace{Spans: []*model.Span{{
+		TraceID:       mockTraceID,
+		SpanID:        model.NewSpanID(1),
+		OperationName: "GET /",
+		Process:       &model.Process{ServiceName: "frontend"},
+	}}}
+	ts.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(trace, nil)
+	url := "ws" + strings.TrimPrefix(ts.server.URL, "http") + "/api/live-tail?service=frontend"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	// the trace ID is published until received, since the tail subscribes asynchronously
+	stop := make(chan struct{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				broadcaster.Publish(tracestream.Event{TraceID: mockTraceID})
+			}
+		}
+	}()
+	var response structuredTraceResponse
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&response))
+	close(stop)
+	<-published
+	require.Len(t, response.Traces, 1)
+	assert.Equal(t, ui.TraceID(mockTraceID.String()), response.Traces[0].TraceID)
+	assert.Equal(t, "GET /", response.Traces[0].Spans[0].OperationName)
+
+	// the tail of the first connection is the only one allowed
+	other, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer other.Close()
+	_, _, err = other.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err)
+}
+
+func TestLiveTailAPIErrors(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/live-tail?service=frontend", &response)
+	require.ErrorContains(t, err, "501 error")
+
+	tailer := livetail.NewTailer(livetail.Options{MaxSubscribers: 1}, nil, nil, zap.NewNop())
+	ts = initializeTestServer(HandlerOptions.LiveTail(tailer))
+	defer ts.server.Close()
+	err = getJSON(ts.server.URL+"/api/live-tail", &response)
+	require.ErrorContains(t, err, "400 error")
+	err = getJSON(ts.server.URL+"/api/live-tail?traceID=1", &response)
+	require.ErrorContains(t, err, "does not support the trace IDs")
+	err = getJSON(ts.server.URL+"/api/live-tail?service=frontend", &response)
+	require.ErrorContains(t, err, "400 error", "the request is not a WebSocket handshake")
+}
+
+func TestGetRecentTraces(t *testing.T) {
+	recentTraces := tracestream.NewRecentTraces(10)
+	for i := uint64(1); i <= 3; i++ {
+		recentTraces.Add(&model.Span{
+			TraceID:       model.NewTraceID(0, i),
+			SpanID:        model.NewSpanID(i),
+			OperationName: "op",
+			Process:       &model.Process{ServiceName: fmt.Sprintf("service-%d", i%2)},
+		}, "")
+	}
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{RecentTraces: recentTraces})
+	defer ts.server.Close()
+
+	var response structuredTraceResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/traces/recent", &response))
+	require.Len(t, response.Traces, 3)
+	assert.Equal(t, ui.TraceID("0000000000000003"), response.Traces[0].TraceID, "the most recent trace first")
+
+	response = structuredTraceResponse{}
+	require.NoError(t, getJSON(ts.server.URL+"/api/traces/recent?service=service-1&limit=1", &response))
+	require.Len(t, response.Traces, 1)
+	assert.Equal(t, ui.TraceID("0000000000000003"), response.Traces[0].TraceID)
+}
+
+func TestGetRecentTracesErrors(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/traces/recent", &response)
+	require.ErrorContains(t, err, "501 error")
+
+	ts = initializeTestServerWithHandler(querysvc.QueryServiceOptions{RecentTraces: tracestream.NewRecentTraces(10)})
+	defer ts.server.Close()
+	for _, limit := range []string{"ten", "-1"} {
+		err = getJSON(ts.server.URL+"/api/traces/recent?limit="+limit, &response)
+		require.ErrorContains(t, err, "400 error", limit)
+	}
+}
@@ -113,8 +113,11 @@ type TraceQueryParameters struct {
 	DurationMin *types.Duration `protobuf:"bytes,6,opt,name=duration_min,json=durationMin,proto3" json:"duration_min,omitempty"`
 	// Span max duration. REST API uses Golang's time format e.g. 10s.
 	DurationMax *types.Duration `protobuf:"bytes,7,opt,name=duration_max,json=durationMax,proto3" json:"duration_max,omitempty"`
-	// Maximum number of traces in the response.
-	NumTraces            int32    `protobuf:"varint,8,opt,name=num_traces,json=numTraces,proto3" json:"num_traces,omitempty"`
+	// Maximum number of traces in the response, i.e. the page size.
+	NumTraces int32 `protobuf:"varint,8,opt,name=num_traces,json=numTraces,proto3" json:"num_traces,omitempty"`
+	// Optional. Opaque continuation token returned by a previous FindTraces call
+	// with the same parameters, used to retrieve the next page of traces.
+	PageToken            string   `protobuf:"bytes,9,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -200,6 +203,13 @@ func (m *TraceQueryParameters) GetNumTraces() int32 {
 	return 0
 }
 
+func (m *TraceQueryParameters) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 // Request object to search traces.
 type FindTracesRequest struct {
 	Query                *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
@@ -241,6 +251,10 @@ func (m *FindTracesRequest) GetQuery() *TraceQueryParameters {
 
 // Request object to get service names.
 type GetServicesRequest struct {
+	// Optional. Maximum number of services in the response. If zero, all services are returned.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Optional. Opaque continuation token returned by a previous GetServices call.
+	PageToken            string   `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -270,9 +284,25 @@ func (m *GetServicesRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GetServicesRequest proto.InternalMessageInfo
 
+func (m *GetServicesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetServicesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 // Response object to get service names.
 type GetServicesResponse struct {
-	Services             []string `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	Services []string `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	// Token to retrieve the next page of services, empty if there are no more services.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -309,12 +339,24 @@ func (m *GetServicesResponse) GetServices() []string {
 	return nil
 }
 
+func (m *GetServicesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 // Request object to get operation names.
 type GetOperationsRequest struct {
 	// Required service name.
 	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
 	// Optional span kind.
-	SpanKind             string   `protobuf:"bytes,2,opt,name=span_kind,json=spanKind,proto3" json:"span_kind,omitempty"`
+	SpanKind string `protobuf:"bytes,2,opt,name=span_kind,json=spanKind,proto3" json:"span_kind,omitempty"`
+	// Optional. Maximum number of operations in the response. If zero, all operations are returned.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Optional. Opaque continuation token returned by a previous GetOperations call
+	// with the same parameters.
+	PageToken            string   `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -358,6 +400,20 @@ func (m *GetOperationsRequest) GetSpanKind() string {
 	return ""
 }
 
+func (m *GetOperationsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetOperationsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 // Operation encapsulates information about operation.
 type Operation struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -407,10 +463,12 @@ func (m *Operation) GetSpanKind() string {
 
 // Response object to get operation names.
 type GetOperationsResponse struct {
-	Operations           []*Operation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Operations []*Operation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	// Token to retrieve the next page of operations, empty if there are no more operations.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *GetOperationsResponse) Reset()         { *m = GetOperationsResponse{} }
@@ -444,6 +502,13 @@ func (m *GetOperationsResponse) GetOperations() []*Operation {
 	return nil
 }
 
+func (m *GetOperationsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 // GRPCGatewayError is the type returned when GRPC server returns an error.
 // Example: {"error":{"grpcCode":2,"httpCode":500,"message":"...","httpStatus":"text..."}}.
 type GRPCGatewayError struct {
@@ -560,10 +625,12 @@ func (m *GRPCGatewayError_GRPCGatewayErrorDetails) GetHttpStatus() string {
 // See https://github.com/grpc-ecosystem/grpc-gateway/issues/2189
 //
 type GRPCGatewayWrapper struct {
-	Result               *TracesData `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	Result *TracesData `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	// Token to retrieve the next page of traces from FindTraces, empty if there are no more traces.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *GRPCGatewayWrapper) Reset()         { *m = GRPCGatewayWrapper{} }
@@ -597,6 +664,13 @@ func (m *GRPCGatewayWrapper) GetResult() *TracesData {
 	return nil
 }
 
+func (m *GRPCGatewayWrapper) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*GetTraceRequest)(nil), "jaeger.api_v3.GetTraceRequest")
 	proto.RegisterType((*TraceQueryParameters)(nil), "jaeger.api_v3.TraceQueryParameters")
@@ -615,59 +689,63 @@ func init() {
 func init() { proto.RegisterFile("query_service.proto", fileDescriptor_5fcb6756dc1afb8d) }
 
 var fileDescriptor_5fcb6756dc1afb8d = []byte{
-	// 819 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xdd, 0x6e, 0xdc, 0x44,
-	0x14, 0xae, 0x77, 0xb3, 0xc9, 0xfa, 0x6c, 0xd2, 0x96, 0xe9, 0xa2, 0xba, 0x46, 0xa4, 0x1b, 0x17,
-	0xa4, 0xbd, 0x72, 0xc8, 0xe6, 0x82, 0x02, 0x45, 0x94, 0x36, 0x65, 0x85, 0x50, 0x4a, 0x3b, 0xa9,
-	0x0a, 0x42, 0x95, 0xac, 0x49, 0x7c, 0x30, 0xa6, 0xeb, 0xb1, 0x3b, 0x33, 0x5e, 0xb2, 0xcf, 0xc0,
-	0x0d, 0x12, 0x6f, 0xc1, 0x4b, 0xf1, 0x08, 0xbc, 0x00, 0x17, 0xc8, 0x33, 0x63, 0x77, 0xd7, 0x0b,
-	0x21, 0xb9, 0xb2, 0xcf, 0x99, 0xef, 0x3b, 0x3f, 0xdf, 0x9c, 0x33, 0x70, 0xeb, 0x4d, 0x89, 0x62,
-	0x11, 0x49, 0x14, 0xf3, 0xf4, 0x0c, 0xc3, 0x42, 0xe4, 0x2a, 0x27, 0x3b, 0x3f, 0x33, 0x4c, 0x50,
-	0x84, 0xac, 0x48, 0xa3, 0xf9, 0xa1, 0x3f, 0xce, 0x0b, 0xe4, 0x0a, 0x67, 0x98, 0xa1, 0x12, 0x8b,
-	0x7d, 0x8d, 0xd9, 0x57, 0x82, 0x9d, 0xe1, 0xfe, 0xfc, 0xc0, 0xfc, 0x18, 0xa2, 0x3f, 0x4c, 0xf2,
-	0x24, 0x37, 0xe7, 0xd5, 0x9f, 0xf5, 0xde, 0x4d, 0xf2, 0x3c, 0x99, 0xa1, 0x21, 0x9e, 0x96, 0x3f,
-	0xee, 0xab, 0x34, 0x43, 0xa9, 0x58, 0x56, 0x58, 0xc0, 0x6e, 0x1b, 0x10, 0x97, 0x82, 0xa9, 0x34,
-	0xe7, 0xe6, 0x3c, 0xf8, 0xc3, 0x81, 0x1b, 0x53, 0x54, 0x2f, 0xaa, 0x4c, 0x14, 0xdf, 0x94, 0x28,
-	0x15, 0xb9, 0x03, 0x7d, 0x9d, 0x39, 0x4a, 0x63, 0xcf, 0x19, 0x39, 0x63, 0x97, 0x6e, 0x69, 0xfb,
-	0xeb, 0x98, 0x7c, 0x01, 0x20, 0x15, 0x13, 0x2a, 0xaa, 0xf2, 0x78, 0x9d, 0x91, 0x33, 0x1e, 0x4c,
-	0xfc, 0xd0, 0xe4, 0x08, 0xeb, 0x1c, 0xe1, 0x8b, 0xba, 0x88, 0x47, 0x1b, 0xbf, 0xfd, 0x79, 0xd7,
-	0xa1, 0xae, 0xe6, 0x54, 0x5e, 0xf2, 0x19, 0xf4, 0x91, 0xc7, 0x86, 0xde, 0xbd, 0x24, 0x7d, 0x0b,
-	0x79, 0x5c, 0xf9, 0x82, 0xdf, 0x37, 0x60, 0xa8, 0x2b, 0x7d, 0x5e, 0x29, 0xfb, 0x8c, 0x09, 0x96,
-	0xa1, 0x42, 0x21, 0xc9, 0x1e, 0x6c, 0x5b, 0x99, 0x23, 0xce, 0x32, 0xb4, 0x55, 0x0f, 0xac, 0xef,
-	0x29, 0xcb, 0x90, 0x7c, 0x08, 0xd7, 0xf3, 0x02, 0x4d, 0xef, 0x06, 0xd4, 0xd1, 0xa0, 0x9d, 0xc6,
-	0xab, 0x61, 0x27, 0x00, 0x4c, 0x29, 0x91, 0x9e, 0x96, 0x0a, 0xa5, 0xd7, 0x1d, 0x75, 0xc7, 0x83,
-	0xc9, 0x61, 0xb8, 0x72, 0x69, 0xe1, 0xbf, 0x95, 0x10, 0x7e, 0xd9, 0xb0, 0x9e, 0x70, 0x25, 0x16,
-	0x74, 0x29, 0x0c, 0x79, 0x08, 0xd7, 0xdf, 0xaa, 0x16, 0x65, 0x29, 0xf7, 0x36, 0xfe, 0xaf, 0x75,
-	0xba, 0xdd, 0x68, 0x76, 0x9c, 0xf2, 0x76, 0x04, 0x76, 0xee, 0xf5, 0xae, 0x12, 0x81, 0x9d, 0x93,
-	0x07, 0xb0, 0x5d, 0x5f, 0xbd, 0xae, 0x60, 0x53, 0xf3, 0xef, 0xac, 0xf1, 0x8f, 0x2c, 0x88, 0x0e,
-	0x6a, 0x78, 0x95, 0x7f, 0x85, 0xcd, 0xce, 0xbd, 0xad, 0xcb, 0xb3, 0xd9, 0x39, 0x79, 0x1f, 0x80,
-	0x97, 0x59, 0xa4, 0x87, 0x48, 0x7a, 0xfd, 0x91, 0x33, 0xee, 0x51, 0x97, 0x97, 0x99, 0x16, 0x52,
-	0xfa, 0x9f, 0xc3, 0x8d, 0x96, 0x7a, 0xe4, 0x26, 0x74, 0x5f, 0xe3, 0xc2, 0xde, 0x63, 0xf5, 0x4b,
-	0x86, 0xd0, 0x9b, 0xb3, 0x59, 0x59, 0x5f, 0x9b, 0x31, 0x3e, 0xed, 0xdc, 0x77, 0x82, 0xa7, 0xf0,
-	0xce, 0x57, 0x29, 0x8f, 0x4d, 0xb0, 0x7a, 0x86, 0x3f, 0x81, 0x9e, 0x5e, 0x3f, 0x1d, 0x62, 0x30,
-	0xb9, 0x77, 0x89, 0x2b, 0xa4, 0x86, 0x11, 0x0c, 0x81, 0x4c, 0x51, 0x9d, 0x98, 0xd9, 0xa9, 0x03,
-	0x06, 0x07, 0x70, 0x6b, 0xc5, 0x2b, 0x8b, 0x9c, 0x4b, 0x24, 0x3e, 0xf4, 0xed, 0x94, 0x49, 0xcf,
-	0x19, 0x75, 0xc7, 0x2e, 0x6d, 0xec, 0xe0, 0x18, 0x86, 0x53, 0x54, 0xdf, 0xd6, 0xf3, 0xd5, 0xd4,
-	0xe6, 0xc1, 0x96, 0xc5, 0xd4, 0xeb, 0x65, 0x4d, 0xf2, 0x1e, 0xb8, 0xb2, 0x60, 0x3c, 0x7a, 0x9d,
-	0xf2, 0xd8, 0x36, 0xda, 0xaf, 0x1c, 0xdf, 0xa4, 0x3c, 0x0e, 0x1e, 0x80, 0xdb, 0xc4, 0x22, 0x04,
-	0x36, 0x96, 0x26, 0x5d, 0xff, 0x5f, 0xcc, 0x7e, 0x0e, 0xef, 0xb6, 0x8a, 0xb1, 0x1d, 0xdc, 0x07,
-	0x68, 0x56, 0xc0, 0xf4, 0x30, 0x98, 0x78, 0x2d, 0xb9, 0x1a, 0x1a, 0x5d, 0xc2, 0x06, 0x7f, 0x39,
-	0x70, 0x73, 0x4a, 0x9f, 0x3d, 0x9e, 0x32, 0x85, 0xbf, 0xb0, 0xc5, 0x13, 0x21, 0x72, 0x41, 0x8e,
-	0xa1, 0x87, 0xd5, 0x8f, 0x15, 0xfe, 0xe3, 0x56, 0xa4, 0x36, 0x7e, 0xcd, 0x71, 0x84, 0x8a, 0xa5,
-	0x33, 0x49, 0x4d, 0x14, 0xff, 0x57, 0x07, 0x6e, 0xff, 0x07, 0xa4, 0xd2, 0x3e, 0x11, 0xc5, 0xd9,
-	0xe3, 0x3c, 0x36, 0x3a, 0xf4, 0x68, 0x63, 0x57, 0x67, 0x3f, 0x29, 0x55, 0xe8, 0xb3, 0x8e, 0x39,
-	0xab, 0xed, 0x4a, 0xff, 0x0c, 0xa5, 0x64, 0x89, 0x79, 0x82, 0x5c, 0x5a, 0x9b, 0x64, 0x17, 0xa0,
-	0x42, 0x9d, 0x28, 0xa6, 0x4a, 0xa9, 0x97, 0xd4, 0xa5, 0x4b, 0x9e, 0xe0, 0x25, 0x90, 0xa5, 0x62,
-	0xbe, 0x13, 0xac, 0x28, 0x50, 0x90, 0x87, 0xb0, 0x29, 0x50, 0x96, 0x33, 0x65, 0x7b, 0x1e, 0x87,
-	0x2b, 0xaf, 0xba, 0xd9, 0x8e, 0xd0, 0x3c, 0xe6, 0xf3, 0x03, 0x33, 0x7b, 0xf2, 0x88, 0x29, 0x46,
-	0x2d, 0x6f, 0xf2, 0x77, 0x07, 0xb6, 0xf5, 0x34, 0xda, 0xf9, 0x22, 0xdf, 0x43, 0xbf, 0x7e, 0x95,
-	0xc9, 0x6e, 0x5b, 0xc2, 0xd5, 0xe7, 0xda, 0xbf, 0x74, 0xba, 0xe0, 0xda, 0x47, 0x0e, 0x79, 0x05,
-	0xf0, 0x76, 0x5b, 0xc8, 0xa8, 0x15, 0x7b, 0x6d, 0x91, 0xae, 0x18, 0xfd, 0x25, 0x0c, 0x96, 0xb6,
-	0x84, 0xec, 0xad, 0x97, 0xde, 0xda, 0x2b, 0x3f, 0xb8, 0x08, 0x62, 0x46, 0x34, 0xb8, 0x46, 0x5e,
-	0xc1, 0xce, 0xca, 0xf4, 0x92, 0x7b, 0xeb, 0xb4, 0xb5, 0x45, 0xf3, 0x3f, 0xb8, 0x18, 0x54, 0x47,
-	0x7f, 0xb4, 0x07, 0xb7, 0xd3, 0xdc, 0x62, 0xab, 0xce, 0x52, 0x9e, 0x58, 0xca, 0x0f, 0x9b, 0xe6,
-	0x7b, 0xba, 0xa9, 0xfb, 0x3e, 0xfc, 0x27, 0x00, 0x00, 0xff, 0xff, 0x04, 0x2f, 0x8b, 0x11, 0xd5,
-	0x07, 0x00, 0x00,
+	// 884 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x72, 0xdb, 0x44,
+	0x14, 0xae, 0xfc, 0x93, 0xd8, 0xc7, 0x49, 0x53, 0xb6, 0x61, 0xaa, 0x9a, 0x21, 0x75, 0x54, 0x60,
+	0x7c, 0xa5, 0x50, 0xe7, 0x82, 0x02, 0x65, 0x28, 0x6d, 0x8a, 0x87, 0x61, 0x5a, 0x82, 0x92, 0xe1,
+	0x6f, 0x3a, 0xa3, 0xd9, 0x44, 0x07, 0x21, 0x62, 0xad, 0xd4, 0xdd, 0x95, 0xb1, 0x7b, 0xc1, 0x3d,
+	0xc3, 0x0d, 0xcf, 0xc1, 0x25, 0x2f, 0xc4, 0x23, 0xf0, 0x02, 0x5c, 0x30, 0xfb, 0x23, 0xd5, 0x96,
+	0x21, 0x38, 0x57, 0xda, 0x73, 0xf6, 0xfb, 0xce, 0xff, 0xd1, 0xc2, 0xcd, 0x17, 0x05, 0xf2, 0x79,
+	0x28, 0x90, 0x4f, 0x93, 0x73, 0xf4, 0x73, 0x9e, 0xc9, 0x8c, 0x6c, 0xff, 0x48, 0x31, 0x46, 0xee,
+	0xd3, 0x3c, 0x09, 0xa7, 0x87, 0xfd, 0x61, 0x96, 0x23, 0x93, 0x38, 0xc1, 0x14, 0x25, 0x9f, 0x1f,
+	0x68, 0xcc, 0x81, 0xe4, 0xf4, 0x1c, 0x0f, 0xa6, 0xf7, 0xcc, 0xc1, 0x10, 0xfb, 0xbb, 0x71, 0x16,
+	0x67, 0xe6, 0x5e, 0x9d, 0xac, 0xf6, 0x4e, 0x9c, 0x65, 0xf1, 0x04, 0x0d, 0xf1, 0xac, 0xf8, 0xfe,
+	0x40, 0x26, 0x29, 0x0a, 0x49, 0xd3, 0xdc, 0x02, 0xf6, 0xea, 0x80, 0xa8, 0xe0, 0x54, 0x26, 0x19,
+	0x33, 0xf7, 0xde, 0xef, 0x0e, 0xec, 0x8c, 0x51, 0x9e, 0x2a, 0x4f, 0x01, 0xbe, 0x28, 0x50, 0x48,
+	0x72, 0x1b, 0x3a, 0xda, 0x73, 0x98, 0x44, 0xae, 0x33, 0x70, 0x86, 0xdd, 0x60, 0x53, 0xcb, 0x9f,
+	0x45, 0xe4, 0x63, 0x00, 0x21, 0x29, 0x97, 0xa1, 0xf2, 0xe3, 0x36, 0x06, 0xce, 0xb0, 0x37, 0xea,
+	0xfb, 0xc6, 0x87, 0x5f, 0xfa, 0xf0, 0x4f, 0xcb, 0x20, 0x1e, 0xb5, 0x7e, 0xfb, 0xf3, 0x8e, 0x13,
+	0x74, 0x35, 0x47, 0x69, 0xc9, 0x87, 0xd0, 0x41, 0x16, 0x19, 0x7a, 0x73, 0x4d, 0xfa, 0x26, 0xb2,
+	0x48, 0xe9, 0xbc, 0x3f, 0x5a, 0xb0, 0xab, 0x23, 0xfd, 0x52, 0x55, 0xf6, 0x98, 0x72, 0x9a, 0xa2,
+	0x44, 0x2e, 0xc8, 0x3e, 0x6c, 0xd9, 0x32, 0x87, 0x8c, 0xa6, 0x68, 0xa3, 0xee, 0x59, 0xdd, 0x33,
+	0x9a, 0x22, 0x79, 0x1b, 0xae, 0x67, 0x39, 0x9a, 0xdc, 0x0d, 0xa8, 0xa1, 0x41, 0xdb, 0x95, 0x56,
+	0xc3, 0x4e, 0x00, 0xa8, 0x94, 0x3c, 0x39, 0x2b, 0x24, 0x0a, 0xb7, 0x39, 0x68, 0x0e, 0x7b, 0xa3,
+	0x43, 0x7f, 0xa9, 0x69, 0xfe, 0xbf, 0x85, 0xe0, 0x7f, 0x52, 0xb1, 0x9e, 0x30, 0xc9, 0xe7, 0xc1,
+	0x82, 0x19, 0xf2, 0x10, 0xae, 0xbf, 0xaa, 0x5a, 0x98, 0x26, 0xcc, 0x6d, 0xfd, 0x5f, 0xea, 0xc1,
+	0x56, 0x55, 0xb3, 0xa7, 0x09, 0xab, 0x5b, 0xa0, 0x33, 0xb7, 0x7d, 0x15, 0x0b, 0x74, 0x46, 0x1e,
+	0xc0, 0x56, 0xd9, 0x7a, 0x1d, 0xc1, 0x86, 0xe6, 0xdf, 0x5e, 0xe1, 0x1f, 0x59, 0x50, 0xd0, 0x2b,
+	0xe1, 0xca, 0xff, 0x12, 0x9b, 0xce, 0xdc, 0xcd, 0xf5, 0xd9, 0x74, 0x46, 0xde, 0x04, 0x60, 0x45,
+	0x1a, 0xea, 0x21, 0x12, 0x6e, 0x67, 0xe0, 0x0c, 0xdb, 0x41, 0x97, 0x15, 0xa9, 0x2e, 0xa4, 0x50,
+	0xd7, 0x39, 0x8d, 0x31, 0x94, 0xd9, 0x05, 0x32, 0xb7, 0xab, 0xdb, 0xd2, 0x55, 0x9a, 0x53, 0xa5,
+	0xe8, 0x7f, 0x04, 0x3b, 0xb5, 0xe2, 0x92, 0x1b, 0xd0, 0xbc, 0xc0, 0xb9, 0x6d, 0xb3, 0x3a, 0x92,
+	0x5d, 0x68, 0x4f, 0xe9, 0xa4, 0x28, 0xbb, 0x6a, 0x84, 0x0f, 0x1a, 0xf7, 0x1d, 0xef, 0x19, 0xbc,
+	0xf6, 0x69, 0xc2, 0x22, 0xe3, 0xab, 0x1c, 0xf1, 0xf7, 0xa1, 0xad, 0xb7, 0x53, 0x9b, 0xe8, 0x8d,
+	0xee, 0xae, 0xd1, 0xe1, 0xc0, 0x30, 0xbc, 0x63, 0x20, 0x63, 0x94, 0x27, 0x66, 0xb4, 0x2a, 0x83,
+	0x6f, 0x80, 0x8e, 0x38, 0x14, 0xc9, 0x4b, 0x33, 0x7e, 0xed, 0xa0, 0xa3, 0x14, 0x27, 0xc9, 0x4b,
+	0xac, 0x25, 0xd8, 0xa8, 0x25, 0xe8, 0x7d, 0x0b, 0x37, 0x97, 0x2c, 0x8a, 0x3c, 0x63, 0x02, 0x49,
+	0x1f, 0x3a, 0x76, 0x80, 0x85, 0xeb, 0x0c, 0x9a, 0xc3, 0x6e, 0x50, 0xc9, 0xe4, 0x1d, 0xd8, 0x61,
+	0x38, 0x93, 0xe1, 0x8a, 0xd9, 0x6d, 0xa5, 0x3e, 0xae, 0x4c, 0xff, 0xe2, 0xc0, 0xee, 0x18, 0xe5,
+	0x17, 0xe5, 0x8c, 0x57, 0xf1, 0xba, 0xb0, 0x69, 0x8d, 0x95, 0x2b, 0x6e, 0x45, 0x95, 0x89, 0xc8,
+	0x29, 0x0b, 0x2f, 0x12, 0x16, 0x59, 0xa3, 0x1d, 0xa5, 0xf8, 0x3c, 0x61, 0xd1, 0x72, 0x9a, 0xcd,
+	0x4b, 0xd3, 0x6c, 0xd5, 0xd3, 0x7c, 0x00, 0xdd, 0x2a, 0x0e, 0x42, 0xa0, 0xb5, 0xb0, 0xa9, 0xfa,
+	0x7c, 0xa9, 0x67, 0x6f, 0x0e, 0xaf, 0xd7, 0x12, 0xb1, 0x65, 0xba, 0x0f, 0x50, 0xad, 0xb0, 0x29,
+	0x54, 0x6f, 0xe4, 0xd6, 0xfa, 0x59, 0xd1, 0x82, 0x05, 0xec, 0xda, 0x45, 0xfc, 0xcb, 0x81, 0x1b,
+	0xe3, 0xe0, 0xf8, 0xf1, 0x98, 0x4a, 0xfc, 0x89, 0xce, 0x9f, 0x70, 0x9e, 0x71, 0xf2, 0x14, 0xda,
+	0xa8, 0x0e, 0x76, 0x82, 0xde, 0xab, 0x79, 0xac, 0xe3, 0x57, 0x14, 0x47, 0x28, 0x69, 0x32, 0x11,
+	0x81, 0xb1, 0xd2, 0xff, 0xd5, 0x81, 0x5b, 0xff, 0x01, 0x51, 0x83, 0x10, 0xf3, 0xfc, 0xfc, 0x71,
+	0x16, 0x55, 0xa3, 0x55, 0xca, 0xea, 0xee, 0x07, 0x29, 0x73, 0x7d, 0xd7, 0x30, 0x77, 0xa5, 0xac,
+	0x7a, 0x9c, 0xa2, 0x10, 0x34, 0x36, 0xad, 0xea, 0x06, 0xa5, 0x48, 0xf6, 0x00, 0x14, 0xea, 0x44,
+	0x52, 0x59, 0x08, 0xdb, 0xa9, 0x05, 0x8d, 0xf7, 0x33, 0x90, 0x85, 0x60, 0xbe, 0xe6, 0x34, 0xcf,
+	0x91, 0x93, 0x87, 0xb0, 0xc1, 0x51, 0x14, 0x13, 0x69, 0x73, 0x1e, 0xfa, 0x4b, 0xaf, 0x97, 0xf9,
+	0x0b, 0xf8, 0xe6, 0xd1, 0x9a, 0xde, 0x33, 0x4b, 0x24, 0x8e, 0xa8, 0xa4, 0x81, 0xe5, 0xad, 0x5b,
+	0xf1, 0xd1, 0xdf, 0x0d, 0xd8, 0xd2, 0xeb, 0x67, 0x97, 0x82, 0x7c, 0x03, 0x9d, 0xf2, 0x95, 0x22,
+	0x7b, 0xf5, 0x52, 0x2f, 0x3f, 0x5f, 0xfd, 0xb5, 0xc3, 0xf2, 0xae, 0xbd, 0xeb, 0x90, 0xe7, 0x00,
+	0xaf, 0x7e, 0x0f, 0x64, 0x50, 0xb3, 0xbd, 0xf2, 0xe7, 0xb8, 0xa2, 0xf5, 0xaf, 0xa0, 0xb7, 0xb0,
+	0xda, 0x64, 0x7f, 0x35, 0xf4, 0xda, 0x8f, 0xa4, 0xef, 0x5d, 0x06, 0x31, 0x23, 0xef, 0x5d, 0x23,
+	0xcf, 0x61, 0x7b, 0x69, 0x1b, 0xc8, 0xdd, 0x55, 0xda, 0xca, 0xd2, 0xf7, 0xdf, 0xba, 0x1c, 0x54,
+	0x5a, 0x7f, 0xb4, 0x0f, 0xb7, 0x92, 0xcc, 0x62, 0x55, 0x66, 0x09, 0x8b, 0x2d, 0xe5, 0xbb, 0x0d,
+	0xf3, 0x3d, 0xdb, 0xd0, 0x79, 0x1f, 0xfe, 0x13, 0x00, 0x00, 0xff, 0xff, 0x7e, 0x0c, 0x62, 0xa4,
+	0xe5, 0x08, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
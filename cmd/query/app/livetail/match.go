@@ -0,0 +1,100 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package livetail
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// matches returns whether a span of the trace matches the service, operation, duration, tags,
+// tag filters, log fields and resource attributes of the query, the way the span storage finds the traces.
+// The time range of the query is ignored.
+func matches(trace *model.Trace, query *spanstore.TraceQueryParameters) bool {
+	for _, span := range trace.Spans {
+		if spanMatches(span, query) {
+			return true
+		}
+	}
+	return false
+}
+
+func spanMatches(span *model.Span, query *spanstore.TraceQueryParameters) bool {
+	process := span.Process
+	if process == nil {
+		process = &model.Process{}
+	}
+	if query.ServiceName != "" && query.ServiceName != process.ServiceName {
+		return false
+	}
+	if query.OperationName != "" && query.OperationName != span.OperationName {
+		return false
+	}
+	if query.DurationMin != 0 && span.Duration < query.DurationMin {
+		return false
+	}
+	if query.DurationMax != 0 && span.Duration > query.DurationMax {
+		return false
+	}
+	for key, value := range query.Tags {
+		if !hasKeyValue(span.Tags, key, value) && !hasKeyValue(process.Tags, key, value) && !logsHaveKeyValue(span.Logs, key, value) {
+			return false
+		}
+	}
+	for _, filter := range query.TagFilters {
+		if !filter.Matches(span.Tags) && !filter.Matches(process.Tags) && !logsMatchFilter(span.Logs, filter) {
+			return false
+		}
+	}
+	for key, value := range query.ResourceAttributes {
+		if !hasKeyValue(process.Tags, key, value) {
+			return false
+		}
+	}
+	return len(query.LogFields) == 0 || hasLogWithFields(span.Logs, query.LogFields)
+}
+
+// hasKeyValue returns whether one of the key values, which may have duplicate keys, matches.
+func hasKeyValue(kvs []model.KeyValue, key, value string) bool {
+	for _, kv := range kvs {
+		if kv.Key == key && kv.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
+
+func logsHaveKeyValue(logs []model.Log, key, value string) bool {
+	for _, log := range logs {
+		if hasKeyValue(log.Fields, key, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func logsMatchFilter(logs []model.Log, filter spanstore.TagFilter) bool {
+	for _, log := range logs {
+		if filter.Matches(log.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLogWithFields(logs []model.Log, fields map[string]string) bool {
+	for _, log := range logs {
+		found := true
+		for key, value := range fields {
+			if !hasKeyValue(log.Fields, key, value) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package livetail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func TestMatches(t *testing.T) {
+	trace := &model.Trace{Spans: []*model.Span{
+		{
+			OperationName: "GET /",
+			Duration:      time.Second,
+			Process:       &model.Process{ServiceName: "frontend", Tags: model.KeyValues{model.String("k8s.namespace.name", "shop")}},
+			Tags:          model.KeyValues{model.Int64("http.status_code", 200), model.String("http.method", "GET")},
+		},
+		{
+			OperationName: "SELECT",
+			Duration:      10 * time.Millisecond,
+			Process:       &model.Process{ServiceName: "db"},
+			Logs: []model.Log{
+				{Fields: model.KeyValues{model.String("event", "retry")}},
+				{Fields: model.KeyValues{model.String("event", "exception"), model.String("exception.type", "timeout")}},
+			},
+		},
+		{OperationName: "orphan"},
+	}}
+	testCases := []struct {
+		description string
+		query       spanstore.TraceQueryParameters
+		expected    bool
+	}{
+		{description: "empty query", expected: true},
+		{description: "service", query: spanstore.TraceQueryParameters{ServiceName: "db"}, expected: true},
+		{description: "other service", query: spanstore.TraceQueryParameters{ServiceName: "backend"}},
+		{description: "operation of another service", query: spanstore.TraceQueryParameters{ServiceName: "db", OperationName: "GET /"}},
+		{description: "min duration", query: spanstore.TraceQueryParameters{ServiceName: "db", DurationMin: time.Second}},
+		{description: "max duration", query: spanstore.TraceQueryParameters{ServiceName: "frontend", DurationMax: time.Millisecond}},
+		{description: "durations", query: spanstore.TraceQueryParameters{DurationMin: time.Millisecond, DurationMax: time.Second}, expected: true},
+		{description: "span tag", query: spanstore.TraceQueryParameters{Tags: map[string]string{"http.status_code": "200"}}, expected: true},
+		{description: "process tag", query: spanstore.TraceQueryParameters{Tags: map[string]string{"k8s.namespace.name": "shop"}}, expected: true},
+		{description: "log tag", query: spanstore.TraceQueryParameters{ServiceName: "db", Tags: map[string]string{"event": "retry"}}, expected: true},
+		{description: "tag of another span", query: spanstore.TraceQueryParameters{ServiceName: "db", Tags: map[string]string{"http.status_code": "200"}}},
+		{description: "resource attribute", query: spanstore.TraceQueryParameters{ResourceAttributes: map[string]string{"k8s.namespace.name": "shop"}}, expected: true},
+		{description: "span tag as resource attribute", query: spanstore.TraceQueryParameters{ResourceAttributes: map[string]string{"http.status_code": "200"}}},
+		{description: "log fields", query: spanstore.TraceQueryParameters{LogFields: map[string]string{"event": "exception", "exception.type": "timeout"}}, expected: true},
+		{description: "tag filter", query: spanstore.TraceQueryParameters{TagFilters: []spanstore.TagFilter{
+			{Key: "http.status_code", Operator: spanstore.TagFilterLess, Value: "400"},
+			{Key: "http.method", Operator: spanstore.TagFilterNotEqual, Value: "POST"},
+		}}, expected: true},
+		{description: "process tag filter", query: spanstore.TraceQueryParameters{TagFilters: []spanstore.TagFilter{{Key: "k8s.namespace.name", Operator: spanstore.TagFilterExists}}}, expected: true},
+		{description: "log tag filter", query: spanstore.TraceQueryParameters{ServiceName: "db", TagFilters: []spanstore.TagFilter{{Key: "exception.type", Operator: spanstore.TagFilterNotEqual, Value: "oom"}}}, expected: true},
+		{description: "unmatched tag filter", query: spanstore.TraceQueryParameters{TagFilters: []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagFilterGreaterOrEqual, Value: "500"}}}},
+		{description: "log fields of different logs", query: spanstore.TraceQueryParameters{LogFields: map[string]string{"event": "retry", "exception.type": "timeout"}}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, matches(trace, &testCase.query))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package livetail
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPrefix         = "query.live-tail"
+	flagEnabled        = flagPrefix + ".enabled"
+	flagPollInterval   = flagPrefix + ".poll-interval"
+	flagTraceDelay     = flagPrefix + ".trace-delay"
+	flagMaxSubscribers = flagPrefix + ".max-subscribers"
+
+	defaultPollInterval   = 2 * time.Second
+	defaultTraceDelay     = 5 * time.Second
+	defaultMaxSubscribers = 100
+)
+
+// Options holds configuration for the live tail of the new traces.
+type Options struct {
+	// Enabled registers the WebSocket API streaming the new traces matching a query.
+	Enabled bool
+	// PollInterval is the time between two lookups of the new traces.
+	PollInterval time.Duration
+	// TraceDelay is how long the traces are given to complete, after their first span, before
+	// they are sent.
+	TraceDelay time.Duration
+	// MaxSubscribers bounds the number of the concurrent live tails.
+	MaxSubscribers int
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Stream the new traces matching a query via the /api/live-tail WebSocket; the traces are those processed by the collector of the all-in-one, or else polled from the span storage")
+	flagSet.Duration(flagPollInterval, defaultPollInterval, "The interval between two lookups of the new traces of the live tails")
+	flagSet.Duration(flagTraceDelay, defaultTraceDelay, "How long the traces are given to complete, after their first span, before they are streamed by the live tails")
+	flagSet.Int(flagMaxSubscribers, defaultMaxSubscribers, "The maximum number of the concurrent live tails")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.PollInterval = v.GetDuration(flagPollInterval)
+	o.TraceDelay = v.GetDuration(flagTraceDelay)
+	o.MaxSubscribers = v.GetInt(flagMaxSubscribers)
+	return o
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package livetail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.live-tail.enabled=true",
+		"--query.live-tail.poll-interval=1s",
+		"--query.live-tail.trace-delay=10s",
+		"--query.live-tail.max-subscribers=5",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Enabled:        true,
+		PollInterval:   time.Second,
+		TraceDelay:     10 * time.Second,
+		MaxSubscribers: 5,
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Equal(t, defaultPollInterval, opts.PollInterval)
+	assert.Equal(t, defaultTraceDelay, opts.TraceDelay)
+	assert.Equal(t, defaultMaxSubscribers, opts.MaxSubscribers)
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package livetail
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// subscriptionBufferSize is the number of the trace IDs of the Broadcaster buffered between
+	// two lookups of the new traces.
+	subscriptionBufferSize = 4096
+	// maxPolledTraces bounds the number of the traces found in the span storage by a lookup.
+	maxPolledTraces = 1000
+	// sentRetention is how long the IDs of the sent traces are remembered, so that their late
+	// spans do not send them again.
+	sentRetention = 10 * time.Minute
+)
+
+// ErrTooManySubscribers is returned by Tail when the maximum number of live tails is reached.
+var ErrTooManySubscribers = errors.New("too many live tails, try again later")
+
+// Tailer streams the new traces matching the queries of its subscribers.
+type Tailer struct {
+	options     Options
+	querySvc    *querysvc.QueryService
+	broadcaster *tracestream.Broadcaster
+	logger      *zap.Logger
+	timeNow     func() time.Time
+	subscribers atomic.Int32
+}
+
+// NewTailer creates a Tailer finding the new traces in the trace IDs of the broadcaster of the
+// collector running in the same process, or else in the span storage if it is nil.
+func NewTailer(options Options, querySvc *querysvc.QueryService, broadcaster *tracestream.Broadcaster, logger *zap.Logger) *Tailer {
+	return &Tailer{
+		options:     options,
+		querySvc:    querySvc,
+		broadcaster: broadcaster,
+		logger:      logger,
+		timeNow:     time.Now,
+	}
+}
+
+// tail is a subscription to the new traces matching a query.
+type tail struct {
+	*Tailer
+	query spanstore.TraceQueryParameters
+	send  func(*model.Trace) error
+	sent  map[model.TraceID]time.Time
+}
+
+// Tail calls send with the new traces matching the query, once per trace, until the context is
+// done, returning nil, or send fails, returning its error. The traces are sent once they had the
+// trace delay to complete; the time range, limit and page of the query are ignored.
+func (t *Tailer) Tail(ctx context.Context, query spanstore.TraceQueryParameters, send func(*model.Trace) error) error {
+	if t.subscribers.Add(1) > int32(t.options.MaxSubscribers) {
+		t.subscribers.Add(-1)
+		return ErrTooManySubscribers
+	}
+	defer t.subscribers.Add(-1)
+
+	query.PageToken = ""
+	tl := &tail{
+		Tailer: t,
+		query:  query,
+		send:   send,
+		sent:   make(map[model.TraceID]time.Time),
+	}
+	if t.broadcaster != nil {
+		return tl.fromBroadcaster(ctx)
+	}
+	return tl.fromStorage(ctx)
+}
+
+// fromBroadcaster sends the traces of the trace IDs of the tenant published by the collector,
+// retrieved from the span storage after the trace delay.
+func (t *tail) fromBroadcaster(ctx context.Context) error {
+	subscription := t.broadcaster.Subscribe(subscriptionBufferSize)
+	defer subscription.Close()
+	tenant := tenancy.GetTenant(ctx)
+	pending := make(map[model.TraceID]time.Time)
+
+	ticker := time.NewTicker(t.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-subscription.Events():
+			if _, ok := pending[event.TraceID]; ok || event.Tenant != tenant {
+				continue
+			}
+			if _, ok := t.sent[event.TraceID]; !ok {
+				pending[event.TraceID] = t.timeNow()
+			}
+		case <-ticker.C:
+			now := t.timeNow()
+			for traceID, seen := range pending {
+				if now.Sub(seen) < t.options.TraceDelay {
+					continue
+				}
+				delete(pending, traceID)
+				trace, err := t.querySvc.GetTrace(ctx, traceID)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					if !errors.Is(err, spanstore.ErrTraceNotFound) {
+						t.logger.Warn("Failed to get the trace of the live tail", zap.Stringer("trace-id", traceID), zap.Error(err))
+					}
+					continue
+				}
+				if !matches(trace, &t.query) {
+					continue
+				}
+				if err := t.sendOnce(traceID, trace, now); err != nil {
+					return err
+				}
+			}
+			t.prune(now)
+		}
+	}
+}
+
+// fromStorage sends the traces found in the span storage with a span started since the
+// previous lookup, up to the trace delay ago.
+func (t *tail) fromStorage(ctx context.Context) error {
+	since := t.timeNow().Add(-t.options.TraceDelay)
+
+	ticker := time.NewTicker(t.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			now := t.timeNow()
+			query := t.query
+			query.StartTimeMin = since
+			query.StartTimeMax = now.Add(-t.options.TraceDelay)
+			query.NumTraces = maxPolledTraces
+			traces, err := t.querySvc.FindTraces(ctx, &query)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				t.logger.Warn("Failed to find the traces of the live tail", zap.Error(err))
+				continue
+			}
+			since = query.StartTimeMax
+			for _, trace := range traces {
+				if len(trace.Spans) == 0 {
+					continue
+				}
+				if err := t.sendOnce(trace.Spans[0].TraceID, trace, now); err != nil {
+					return err
+				}
+			}
+			t.prune(now)
+		}
+	}
+}
+
+func (t *tail) sendOnce(traceID model.TraceID, trace *model.Trace, now time.Time) error {
+	if _, ok := t.sent[traceID]; ok {
+		return nil
+	}
+	t.sent[traceID] = now
+	return t.send(trace)
+}
+
+func (t *tail) prune(now time.Time) {
+	for traceID, sent := range t.sent {
+		if now.Sub(sent) > sentRetention {
+			delete(t.sent, traceID)
+		}
+	}
+}
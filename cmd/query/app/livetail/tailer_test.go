@@ -0,0 +1,167 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package livetail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var testOptions = Options{
+	PollInterval:   10 * time.Millisecond,
+	TraceDelay:     20 * time.Millisecond,
+	MaxSubscribers: 1,
+}
+
+func writeSpan(t *testing.T, ctx context.Context, store *memory.Store, traceID uint64, service string) {
+	require.NoError(t, store.WriteSpan(ctx, &model.Span{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(traceID),
+		OperationName: "op",
+		StartTime:     time.Now(),
+		Process:       &model.Process{ServiceName: service},
+	}))
+}
+
+// startTail tails the query, returning the channel of the IDs of the sent traces and of the
+// error of Tail.
+func startTail(ctx context.Context, tailer *Tailer, query spanstore.TraceQueryParameters) (chan model.TraceID, chan error) {
+	traceIDs := make(chan model.TraceID, 10)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tailer.Tail(ctx, query, func(trace *model.Trace) error {
+			traceIDs <- trace.Spans[0].TraceID
+			return nil
+		})
+	}()
+	return traceIDs, errs
+}
+
+func receive(t *testing.T, traceIDs chan model.TraceID) model.TraceID {
+	select {
+	case traceID := <-traceIDs:
+		return traceID
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for a trace of the live tail")
+		return model.TraceID{}
+	}
+}
+
+func TestTailFromBroadcaster(t *testing.T) {
+	store := memory.NewStore()
+	querySvc := querysvc.NewQueryService(store, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	broadcaster := tracestream.NewBroadcaster()
+	tailer := NewTailer(testOptions, querySvc, broadcaster, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(tenancy.WithTenant(context.Background(), "acme"))
+	writeSpan(t, tenancy.WithTenant(context.Background(), "globex"), store, 1, "frontend")
+	writeSpan(t, ctx, store, 2, "backend")
+	writeSpan(t, ctx, store, 4, "frontend")
+	events := []tracestream.Event{
+		{TraceID: model.NewTraceID(0, 1), Tenant: "globex"},
+		{TraceID: model.NewTraceID(0, 2), Tenant: "acme"},
+		{TraceID: model.NewTraceID(0, 3), Tenant: "acme"},
+		{TraceID: model.NewTraceID(0, 4), Tenant: "acme"},
+	}
+	traceIDs, errs := startTail(ctx, tailer, spanstore.TraceQueryParameters{ServiceName: "frontend"})
+
+	// the events are published until the end of the test, since the tail subscribes asynchronously
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for ctx.Err() == nil {
+			for _, event := range events {
+				broadcaster.Publish(event)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	assert.Equal(t, model.NewTraceID(0, 4), receive(t, traceIDs))
+	time.Sleep(5 * testOptions.TraceDelay)
+	assert.Empty(t, traceIDs, "the traces are only sent once")
+
+	cancel()
+	require.NoError(t, <-errs)
+	<-published
+}
+
+func TestTailFromStorage(t *testing.T) {
+	store := memory.NewStore()
+	querySvc := querysvc.NewQueryService(store, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	tailer := NewTailer(testOptions, querySvc, nil, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	traceIDs, errs := startTail(ctx, tailer, spanstore.TraceQueryParameters{ServiceName: "frontend"})
+
+	writeSpan(t, ctx, store, 1, "backend")
+	writeSpan(t, ctx, store, 2, "frontend")
+	assert.Equal(t, model.NewTraceID(0, 2), receive(t, traceIDs))
+	time.Sleep(5 * testOptions.PollInterval)
+	assert.Empty(t, traceIDs, "the traces are only sent once")
+
+	cancel()
+	require.NoError(t, <-errs)
+}
+
+func TestTailSendError(t *testing.T) {
+	store := memory.NewStore()
+	querySvc := querysvc.NewQueryService(store, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	tailer := NewTailer(testOptions, querySvc, nil, zap.NewNop())
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tailer.Tail(context.Background(), spanstore.TraceQueryParameters{ServiceName: "frontend"}, func(*model.Trace) error {
+			return errors.New("connection closed")
+		})
+	}()
+	writeSpan(t, context.Background(), store, 1, "frontend")
+	select {
+	case err := <-errs:
+		require.EqualError(t, err, "connection closed")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for the live tail to fail")
+	}
+}
+
+func TestTailTooManySubscribers(t *testing.T) {
+	tailer := NewTailer(Options{MaxSubscribers: 0}, nil, nil, zap.NewNop())
+	err := tailer.Tail(context.Background(), spanstore.TraceQueryParameters{}, nil)
+	require.ErrorIs(t, err, ErrTooManySubscribers)
+	assert.Zero(t, tailer.subscribers.Load())
+}
+
+func TestTailStorageError(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error"))
+	querySvc := querysvc.NewQueryService(reader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	logger, logBuffer := testutils.NewLogger()
+	tailer := NewTailer(testOptions, querySvc, nil, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, errs := startTail(ctx, tailer, spanstore.TraceQueryParameters{ServiceName: "frontend"})
+	require.Eventually(t, func() bool {
+		return logBuffer.String() != ""
+	}, 5*time.Second, time.Millisecond)
+	assert.Contains(t, logBuffer.String(), "Failed to find the traces of the live tail")
+	cancel()
+	require.NoError(t, <-errs)
+}
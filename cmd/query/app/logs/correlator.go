@@ -0,0 +1,149 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// maxErrorBodySize bounds the part of the error responses of the log backend reported in the errors.
+const maxErrorBodySize = 512
+
+// LogLine is a log line correlated with a trace.
+type LogLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Service   string    `json:"service,omitempty"`
+	Message   string    `json:"message"`
+	// Attributes are the other labels or fields of the log line.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// query selects the log lines of a trace in a log backend.
+type query struct {
+	traceID   model.TraceID
+	services  []string
+	startTime time.Time
+	endTime   time.Time
+	limit     int
+}
+
+// backend fetches log lines from a log backend.
+type backend interface {
+	// fetchLogs returns the log lines matching the query, in chronological order.
+	fetchLogs(ctx context.Context, q query) ([]LogLine, error)
+}
+
+// Correlator finds the log lines of the traces in a log backend, by their trace ID,
+// in the time range of the traces and among the logs of their services.
+type Correlator struct {
+	backend     backend
+	limit       int
+	timePadding time.Duration
+}
+
+// NewCorrelator creates a Correlator querying the log backend of the options.
+func NewCorrelator(options Options) (*Correlator, error) {
+	if options.Endpoint == "" {
+		return nil, errors.New("the endpoint of the log backend must be set")
+	}
+	if options.Limit <= 0 {
+		return nil, errors.New("the limit of the log lines must be positive")
+	}
+	client := &http.Client{Timeout: options.Timeout}
+	endpoint := strings.TrimSuffix(options.Endpoint, "/")
+	var b backend
+	switch options.Backend {
+	case BackendLoki:
+		b = &lokiBackend{client: client, endpoint: endpoint, serviceLabel: options.LokiServiceLabel}
+	case BackendElasticsearch:
+		b = &esBackend{client: client, endpoint: endpoint, index: options.ESIndex, traceIDField: options.ESTraceIDField}
+	default:
+		return nil, fmt.Errorf("unsupported log backend %q, expecting %s or %s", options.Backend, BackendLoki, BackendElasticsearch)
+	}
+	return &Correlator{
+		backend:     b,
+		limit:       options.Limit,
+		timePadding: options.TimePadding,
+	}, nil
+}
+
+// TraceLogs returns the log lines of the trace, in chronological order.
+func (c *Correlator) TraceLogs(ctx context.Context, trace *model.Trace) ([]LogLine, error) {
+	if len(trace.Spans) == 0 {
+		return nil, nil
+	}
+	q := query{
+		traceID:   trace.Spans[0].TraceID,
+		startTime: trace.Spans[0].StartTime,
+		endTime:   trace.Spans[0].StartTime.Add(trace.Spans[0].Duration),
+		limit:     c.limit,
+	}
+	services := make(map[string]struct{})
+	for _, span := range trace.Spans {
+		if span.StartTime.Before(q.startTime) {
+			q.startTime = span.StartTime
+		}
+		if end := span.StartTime.Add(span.Duration); end.After(q.endTime) {
+			q.endTime = end
+		}
+		if service := span.Process.GetServiceName(); service != "" {
+			services[service] = struct{}{}
+		}
+	}
+	for service := range services {
+		q.services = append(q.services, service)
+	}
+	sort.Strings(q.services)
+	q.startTime = q.startTime.Add(-c.timePadding)
+	q.endTime = q.endTime.Add(c.timePadding)
+	return c.backend.fetchLogs(ctx, q)
+}
+
+// traceIDStrings returns the formats of the trace ID found in the logs: the 32 hex digits
+// of the OpenTelemetry SDKs, and the shorter format of the 64 bits trace IDs.
+func traceIDStrings(traceID model.TraceID) []string {
+	padded := fmt.Sprintf("%016x%016x", traceID.High, traceID.Low)
+	if short := traceID.String(); short != padded {
+		return []string{padded, short}
+	}
+	return []string{padded}
+}
+
+// doJSON sends the request to the log backend and decodes its JSON response.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query the log backend: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("the log backend responded with status %d: %s", resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode the response of the log backend: %w", err)
+	}
+	return nil
+}
+
+// sortLogLines sorts the log lines in chronological order and keeps the first ones.
+func sortLogLines(lines []LogLine, limit int) []LogLine {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Timestamp.Before(lines[j].Timestamp)
+	})
+	if len(lines) > limit {
+		lines = lines[:limit]
+	}
+	return lines
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type fakeBackend struct {
+	query query
+	lines []LogLine
+}
+
+func (f *fakeBackend) fetchLogs(_ context.Context, q query) ([]LogLine, error) {
+	f.query = q
+	return f.lines, nil
+}
+
+func TestNewCorrelator(t *testing.T) {
+	options := Options{Backend: BackendLoki, Endpoint: "http://loki:3100/", Limit: 10}
+	c, err := NewCorrelator(options)
+	require.NoError(t, err)
+	assert.Equal(t, &lokiBackend{client: c.backend.(*lokiBackend).client, endpoint: "http://loki:3100"}, c.backend)
+
+	options.Backend = BackendElasticsearch
+	c, err = NewCorrelator(options)
+	require.NoError(t, err)
+	assert.IsType(t, &esBackend{}, c.backend)
+
+	options.Backend = "splunk"
+	_, err = NewCorrelator(options)
+	require.ErrorContains(t, err, `unsupported log backend "splunk"`)
+
+	_, err = NewCorrelator(Options{Backend: BackendLoki, Limit: 10})
+	require.ErrorContains(t, err, "endpoint")
+
+	_, err = NewCorrelator(Options{Backend: BackendLoki, Endpoint: "http://loki:3100"})
+	require.ErrorContains(t, err, "limit")
+}
+
+func TestTraceLogs(t *testing.T) {
+	backend := &fakeBackend{lines: []LogLine{{Message: "charging the card"}}}
+	c := &Correlator{backend: backend, limit: 10, timePadding: time.Minute}
+	traceID := model.NewTraceID(1, 2)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trace := &model.Trace{Spans: []*model.Span{
+		{TraceID: traceID, StartTime: start.Add(time.Second), Duration: time.Second, Process: &model.Process{ServiceName: "payment-api"}},
+		{TraceID: traceID, StartTime: start, Duration: 5 * time.Second, Process: &model.Process{ServiceName: "frontend"}},
+		{TraceID: traceID, StartTime: start.Add(2 * time.Second), Duration: time.Second, Process: &model.Process{ServiceName: "frontend"}},
+	}}
+
+	lines, err := c.TraceLogs(context.Background(), trace)
+	require.NoError(t, err)
+	assert.Equal(t, backend.lines, lines)
+	assert.Equal(t, query{
+		traceID:   traceID,
+		services:  []string{"frontend", "payment-api"},
+		startTime: start.Add(-time.Minute),
+		endTime:   start.Add(5*time.Second + time.Minute),
+		limit:     10,
+	}, backend.query)
+
+	lines, err = c.TraceLogs(context.Background(), &model.Trace{})
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+}
+
+func TestTraceIDStrings(t *testing.T) {
+	assert.Equal(t, []string{"00000000000000010000000000000002"}, traceIDStrings(model.NewTraceID(1, 2)))
+	assert.Equal(t, []string{"00000000000000000000000000000002", "0000000000000002"}, traceIDStrings(model.NewTraceID(0, 2)))
+}
+
+func TestSortLogLines(t *testing.T) {
+	start := time.Now()
+	lines := []LogLine{
+		{Timestamp: start.Add(2 * time.Second), Message: "c"},
+		{Timestamp: start, Message: "a"},
+		{Timestamp: start.Add(time.Second), Message: "b"},
+	}
+	expected := []LogLine{lines[1], lines[2]}
+	assert.Equal(t, expected, sortLogLines(lines, 2))
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The fields of the log documents following the Elastic Common Schema.
+const (
+	esTimestampField = "@timestamp"
+	esMessageField   = "message"
+	esServiceField   = "service.name"
+)
+
+// esBackend fetches the log lines from an index of Elasticsearch or OpenSearch.
+type esBackend struct {
+	client       *http.Client
+	endpoint     string
+	index        string
+	traceIDField string
+}
+
+type esResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source map[string]any `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func (e *esBackend) fetchLogs(ctx context.Context, q query) ([]LogLine, error) {
+	filters := []any{
+		map[string]any{"terms": map[string]any{e.traceIDField: traceIDStrings(q.traceID)}},
+		map[string]any{"range": map[string]any{esTimestampField: map[string]any{
+			"gte": q.startTime.UTC().Format(time.RFC3339Nano),
+			"lte": q.endTime.UTC().Format(time.RFC3339Nano),
+		}}},
+	}
+	if len(q.services) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{esServiceField: q.services}})
+	}
+	body, err := json.Marshal(map[string]any{
+		"size":  q.limit,
+		"sort":  []any{map[string]any{esTimestampField: map[string]any{"order": "asc"}}},
+		"query": map[string]any{"bool": map[string]any{"filter": filters}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+"/"+url.PathEscape(e.index)+"/_search", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp esResponse
+	if err := doJSON(e.client, req, &resp); err != nil {
+		return nil, err
+	}
+	lines := make([]LogLine, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		fields := make(map[string]string)
+		flattenFields("", hit.Source, fields)
+		line := LogLine{
+			Service: fields[esServiceField],
+			Message: fields[esMessageField],
+		}
+		if ts, ok := fields[esTimestampField]; ok {
+			if line.Timestamp, err = time.Parse(time.RFC3339Nano, ts); err != nil {
+				return nil, fmt.Errorf("invalid timestamp of an Elasticsearch log document: %w", err)
+			}
+		}
+		for _, k := range []string{esTimestampField, esMessageField, esServiceField, e.traceIDField} {
+			delete(fields, k)
+		}
+		if len(fields) > 0 {
+			line.Attributes = fields
+		}
+		lines = append(lines, line)
+	}
+	return sortLogLines(lines, q.limit), nil
+}
+
+// flattenFields flattens the objects of a document into dotted field names.
+func flattenFields(prefix string, source map[string]any, fields map[string]string) {
+	for k, v := range source {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch value := v.(type) {
+		case nil:
+		case map[string]any:
+			flattenFields(k, value, fields)
+		case string:
+			fields[k] = value
+		case []any:
+			encoded, _ := json.Marshal(value)
+			fields[k] = string(encoded)
+		default:
+			fields[k] = fmt.Sprint(value)
+		}
+	}
+}
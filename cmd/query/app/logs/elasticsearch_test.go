@@ -0,0 +1,89 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestElasticsearchFetchLogs(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/logs-*/_search", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"size": 10,
+			"sort": [{"@timestamp": {"order": "asc"}}],
+			"query": {"bool": {"filter": [
+				{"terms": {"trace.id": ["00000000000000010000000000000002"]}},
+				{"range": {"@timestamp": {"gte": "2024-01-01T00:00:00Z", "lte": "2024-01-01T00:01:00Z"}}},
+				{"terms": {"service.name": ["frontend"]}}
+			]}}
+		}`, string(body))
+		w.Write([]byte(`{"hits": {"hits": [
+			{"_source": {"@timestamp": "2024-01-01T00:00:02Z", "message": "checkout failed", "service": {"name": "frontend"},
+				"trace": {"id": "00000000000000010000000000000002"}, "log": {"level": "error"}, "http.status_code": 502, "tags": ["a", "b"], "user": null}},
+			{"_source": {"@timestamp": "2024-01-01T00:00:01.5Z", "message": "checkout", "service.name": "frontend"}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	e := &esBackend{client: server.Client(), endpoint: server.URL, index: "logs-*", traceIDField: "trace.id"}
+	lines, err := e.fetchLogs(context.Background(), query{
+		traceID:   model.NewTraceID(1, 2),
+		services:  []string{"frontend"},
+		startTime: start,
+		endTime:   start.Add(time.Minute),
+		limit:     10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []LogLine{
+		{Timestamp: start.Add(1500 * time.Millisecond), Service: "frontend", Message: "checkout"},
+		{
+			Timestamp: start.Add(2 * time.Second), Service: "frontend", Message: "checkout failed",
+			Attributes: map[string]string{"log.level": "error", "http.status_code": "502", "tags": `["a","b"]`},
+		},
+	}, lines)
+}
+
+func TestElasticsearchFetchLogsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		err      string
+	}{
+		{name: "error status", status: http.StatusNotFound, response: "no such index", err: "the log backend responded with status 404: no such index"},
+		{
+			name: "invalid timestamp", status: http.StatusOK,
+			response: `{"hits": {"hits": [{"_source": {"@timestamp": "yesterday"}}]}}`,
+			err:      "invalid timestamp of an Elasticsearch log document",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			e := &esBackend{client: server.Client(), endpoint: server.URL, index: "logs-*", traceIDField: "trace.id"}
+			_, err := e.fetchLogs(context.Background(), query{limit: 10})
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
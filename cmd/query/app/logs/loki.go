@@ -0,0 +1,101 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// lokiTenantHeader is the header of the Loki tenant, set to the Jaeger tenant.
+const lokiTenantHeader = "X-Scope-OrgID"
+
+// lokiBackend fetches the log lines from the query_range API of Loki.
+type lokiBackend struct {
+	client       *http.Client
+	endpoint     string
+	serviceLabel string
+}
+
+type lokiResponse struct {
+	Data struct {
+		Result []lokiStream `json:"result"`
+	} `json:"data"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are pairs of a timestamp in nanoseconds and of a log line.
+	Values [][2]string `json:"values"`
+}
+
+func (l *lokiBackend) fetchLogs(ctx context.Context, q query) ([]LogLine, error) {
+	params := url.Values{}
+	params.Set("query", l.logQL(q))
+	params.Set("start", strconv.FormatInt(q.startTime.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(q.endTime.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(q.limit))
+	params.Set("direction", "forward")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.endpoint+"/loki/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := tenancy.GetTenant(ctx); tenant != "" {
+		req.Header.Set(lokiTenantHeader, tenant)
+	}
+	var resp lokiResponse
+	if err := doJSON(l.client, req, &resp); err != nil {
+		return nil, err
+	}
+	var lines []LogLine
+	for _, stream := range resp.Data.Result {
+		var attributes map[string]string
+		for k, v := range stream.Stream {
+			if k == l.serviceLabel {
+				continue
+			}
+			if attributes == nil {
+				attributes = make(map[string]string)
+			}
+			attributes[k] = v
+		}
+		for _, value := range stream.Values {
+			ns, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp of a Loki log line: %w", err)
+			}
+			lines = append(lines, LogLine{
+				Timestamp:  time.Unix(0, ns).UTC(),
+				Service:    stream.Stream[l.serviceLabel],
+				Message:    value[1],
+				Attributes: attributes,
+			})
+		}
+	}
+	return sortLogLines(lines, q.limit), nil
+}
+
+// logQL selects the streams of the services of the trace, and filters their lines
+// containing the trace ID.
+func (l *lokiBackend) logQL(q query) string {
+	services := ".+"
+	if len(q.services) > 0 {
+		quoted := make([]string, len(q.services))
+		for i, service := range q.services {
+			quoted[i] = regexp.QuoteMeta(service)
+		}
+		services = strings.Join(quoted, "|")
+	}
+	// the shorter format of the trace ID is contained in the longer one
+	traceIDs := traceIDStrings(q.traceID)
+	return fmt.Sprintf("{%s=~%s} |= %s", l.serviceLabel, strconv.Quote(services), strconv.Quote(traceIDs[len(traceIDs)-1]))
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+func TestLokiFetchLogs(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/query_range", r.URL.Path)
+		assert.Equal(t, `{service_name=~"frontend|payment\\.api"} |= "0000000000000002"`, r.URL.Query().Get("query"))
+		assert.Equal(t, "1704067200000000000", r.URL.Query().Get("start"))
+		assert.Equal(t, "1704067260000000000", r.URL.Query().Get("end"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		assert.Equal(t, "forward", r.URL.Query().Get("direction"))
+		assert.Equal(t, "acme", r.Header.Get(lokiTenantHeader))
+		w.Write([]byte(`{"status": "success", "data": {"resultType": "streams", "result": [
+			{"stream": {"service_name": "payment.api", "level": "error"}, "values": [["1704067201000000000", "card declined"]]},
+			{"stream": {"service_name": "frontend"}, "values": [["1704067202000000000", "checkout failed"], ["1704067200500000000", "checkout"]]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	l := &lokiBackend{client: server.Client(), endpoint: server.URL, serviceLabel: "service_name"}
+	lines, err := l.fetchLogs(tenancy.WithTenant(context.Background(), "acme"), query{
+		traceID:   model.NewTraceID(0, 2),
+		services:  []string{"frontend", "payment.api"},
+		startTime: start,
+		endTime:   start.Add(time.Minute),
+		limit:     2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []LogLine{
+		{Timestamp: start.Add(500 * time.Millisecond), Service: "frontend", Message: "checkout"},
+		{Timestamp: start.Add(time.Second), Service: "payment.api", Message: "card declined", Attributes: map[string]string{"level": "error"}},
+	}, lines)
+}
+
+func TestLokiLogQLWithoutServices(t *testing.T) {
+	l := &lokiBackend{serviceLabel: "app"}
+	assert.Equal(t, `{app=~".+"} |= "00000000000000010000000000000002"`, l.logQL(query{traceID: model.NewTraceID(1, 2)}))
+}
+
+func TestLokiFetchLogsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		err      string
+	}{
+		{name: "error status", status: http.StatusBadRequest, response: "parse error", err: "the log backend responded with status 400: parse error"},
+		{name: "invalid JSON", status: http.StatusOK, response: "{", err: "failed to decode the response of the log backend"},
+		{
+			name: "invalid timestamp", status: http.StatusOK,
+			response: `{"data": {"result": [{"stream": {}, "values": [["yesterday", "line"]]}]}}`,
+			err:      "invalid timestamp of a Loki log line",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			l := &lokiBackend{client: server.Client(), endpoint: server.URL, serviceLabel: "service_name"}
+			_, err := l.fetchLogs(context.Background(), query{limit: 10})
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// BackendLoki fetches the logs from the Loki HTTP API.
+	BackendLoki = "loki"
+	// BackendElasticsearch fetches the logs from an Elasticsearch or OpenSearch index.
+	BackendElasticsearch = "elasticsearch"
+
+	flagPrefix       = "query.logs"
+	flagBackend      = flagPrefix + ".backend"
+	flagEndpoint     = flagPrefix + ".endpoint"
+	flagLimit        = flagPrefix + ".limit"
+	flagTimeout      = flagPrefix + ".timeout"
+	flagTimePadding  = flagPrefix + ".time-padding"
+	flagLokiLabel    = flagPrefix + ".loki.service-label"
+	flagESIndex      = flagPrefix + ".elasticsearch.index"
+	flagESTraceField = flagPrefix + ".elasticsearch.trace-id-field"
+
+	defaultLimit        = 500
+	defaultTimeout      = 10 * time.Second
+	defaultTimePadding  = time.Minute
+	defaultLokiLabel    = "service_name"
+	defaultESIndex      = "logs-*"
+	defaultESTraceField = "trace.id"
+)
+
+// Options holds configuration for the correlation of the traces with the logs of a log backend.
+type Options struct {
+	// Backend is the type of the log backend, BackendLoki or BackendElasticsearch,
+	// the correlation is disabled if empty.
+	Backend string
+	// Endpoint is the base URL of the log backend, e.g. http://loki:3100.
+	Endpoint string
+	// Limit is the maximum number of log lines returned for a trace.
+	Limit int
+	// Timeout bounds the requests to the log backend.
+	Timeout time.Duration
+	// TimePadding extends the time range of the trace in which the logs are searched,
+	// for the logs written shortly before or after the spans.
+	TimePadding time.Duration
+	// LokiServiceLabel is the label of the Loki streams holding the service name.
+	LokiServiceLabel string
+	// ESIndex is the index pattern of the logs in Elasticsearch.
+	ESIndex string
+	// ESTraceIDField is the field of the Elasticsearch log documents holding the trace ID.
+	ESTraceIDField string
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(flagBackend, "", "The log backend queried for the logs of a trace via /api/traces/{traceID}/logs, either loki or elasticsearch; disabled if empty")
+	flagSet.String(flagEndpoint, "", "The base URL of the log backend, e.g. http://loki:3100 or http://elasticsearch:9200")
+	flagSet.Int(flagLimit, defaultLimit, "The maximum number of log lines returned for a trace")
+	flagSet.Duration(flagTimeout, defaultTimeout, "The timeout of the requests to the log backend")
+	flagSet.Duration(flagTimePadding, defaultTimePadding, "The duration by which the time range of a trace is extended on both sides when searching its logs")
+	flagSet.String(flagLokiLabel, defaultLokiLabel, "The label of the Loki streams holding the service name")
+	flagSet.String(flagESIndex, defaultESIndex, "The index pattern of the log documents in Elasticsearch")
+	flagSet.String(flagESTraceField, defaultESTraceField, "The field of the Elasticsearch log documents holding the trace ID")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Backend = v.GetString(flagBackend)
+	o.Endpoint = v.GetString(flagEndpoint)
+	o.Limit = v.GetInt(flagLimit)
+	o.Timeout = v.GetDuration(flagTimeout)
+	o.TimePadding = v.GetDuration(flagTimePadding)
+	o.LokiServiceLabel = v.GetString(flagLokiLabel)
+	o.ESIndex = v.GetString(flagESIndex)
+	o.ESTraceIDField = v.GetString(flagESTraceField)
+	return o
+}
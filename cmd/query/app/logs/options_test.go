@@ -0,0 +1,52 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.logs.backend=elasticsearch",
+		"--query.logs.endpoint=http://elasticsearch:9200",
+		"--query.logs.limit=50",
+		"--query.logs.timeout=3s",
+		"--query.logs.time-padding=30s",
+		"--query.logs.loki.service-label=app",
+		"--query.logs.elasticsearch.index=app-logs-*",
+		"--query.logs.elasticsearch.trace-id-field=traceId",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Backend:          BackendElasticsearch,
+		Endpoint:         "http://elasticsearch:9200",
+		Limit:            50,
+		Timeout:          3 * time.Second,
+		TimePadding:      30 * time.Second,
+		LokiServiceLabel: "app",
+		ESIndex:          "app-logs-*",
+		ESTraceIDField:   "traceId",
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.Empty(t, opts.Backend)
+	assert.Equal(t, defaultLimit, opts.Limit)
+	assert.Equal(t, defaultTimeout, opts.Timeout)
+	assert.Equal(t, defaultTimePadding, opts.TimePadding)
+	assert.Equal(t, defaultLokiLabel, opts.LokiServiceLabel)
+	assert.Equal(t, defaultESIndex, opts.ESIndex)
+	assert.Equal(t, defaultESTraceField, opts.ESTraceIDField)
+}
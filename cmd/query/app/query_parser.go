@@ -20,38 +20,78 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
 	"github.com/jaegertracing/jaeger/storage/metricsstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
 )
 
 const (
 	defaultQueryLimit = 100
-
-	operationParam   = "operation"
-	tagParam         = "tag"
-	tagsParam        = "tags"
-	startTimeParam   = "start"
-	limitParam       = "limit"
-	minDurationParam = "minDuration"
-	maxDurationParam = "maxDuration"
-	serviceParam     = "service"
-	spanKindParam    = "spanKind"
-	endTimeParam     = "end"
-	prettyPrintParam = "prettyPrint"
+	// defaultRecentTracesLimit is the number of the recent traces returned without a limit parameter.
+	defaultRecentTracesLimit = 20
+
+	operationParam     = "operation"
+	tagParam           = "tag"
+	tagsParam          = "tags"
+	tagFilterParam     = "tagFilter"
+	logParam           = "log"
+	logsParam          = "logs"
+	bucketParam        = "bucket"
+	startTimeParam     = "start"
+	limitParam         = "limit"
+	minDurationParam   = "minDuration"
+	maxDurationParam   = "maxDuration"
+	serviceParam       = "service"
+	spanKindParam      = "spanKind"
+	endTimeParam       = "end"
+	prettyPrintParam   = "prettyPrint"
+	typeParam          = "type"
+	prefixParam        = "prefix"
+	offsetParam        = "offset"
+	resourceParam      = "resource"
+	traceIDPrefixParam = "traceIDPrefix"
+	timestampParam     = "timestamp"
+	latencyParam       = "latency"
+	windowParam        = "window"
+
+	defaultExemplarWindow = 30 * time.Second
+	defaultExemplarLimit  = 10
+
+	defaultCallPathLimit = 20
 )
 
 var (
 	errMaxDurationGreaterThanMin = fmt.Errorf("'%s' should be greater than '%s'", maxDurationParam, minDurationParam)
 
+	errBucketsNotAscending = fmt.Errorf("'%s' values should be positive and ascending", bucketParam)
+
 	// errServiceParameterRequired occurs when no service name is defined.
 	errServiceParameterRequired = fmt.Errorf("parameter '%s' is required", serviceParam)
 
+	errTimestampParameterRequired = fmt.Errorf("parameter '%s' is required", timestampParam)
+
+	errRegressionDetectionDisabled = errors.New("regression detection is not enabled")
+
+	errLogCorrelationDisabled = errors.New("the correlation with a log backend is not enabled")
+
+	errTraceExportDisabled = errors.New("the export of traces is not enabled")
+
+	errAlertingDisabled = errors.New("alerting is not enabled")
+
+	errSLODisabled = errors.New("the tracking of the SLOs is not enabled")
+
+	errLiveTailDisabled = errors.New("the live tail is not enabled")
+
 	jaegerToOtelSpanKind = map[string]string{
 		"unspecified": metrics.SpanKind_SPAN_KIND_UNSPECIFIED.String(),
 		"internal":    metrics.SpanKind_SPAN_KIND_INTERNAL.String(),
@@ -71,7 +111,8 @@ type (
 
 	traceQueryParameters struct {
 		spanstore.TraceQueryParameters
-		traceIDs []model.TraceID
+		traceIDs      []model.TraceID
+		traceIDPrefix string
 	}
 
 	dependenciesQueryParameters struct {
@@ -112,7 +153,7 @@ func newDurationUnitsParser(units time.Duration) durationParser {
 // Trace query syntax:
 //
 //	query ::= param | param '&' query
-//	param ::= service | operation | limit | start | end | minDuration | maxDuration | tag | tags
+//	param ::= service | operation | limit | start | end | minDuration | maxDuration | tag | tags | tagFilter | log | logs | resource | traceIDPrefix
 //	service ::= 'service=' strValue
 //	operation ::= 'operation=' strValue
 //	limit ::= 'limit=' intValue
@@ -124,6 +165,12 @@ func newDurationUnitsParser(units time.Duration) durationParser {
 //	key := strValue
 //	keyValue := strValue ':' strValue
 //	tags :== 'tags=' jsonMap
+//	tagFilter ::= 'tagFilter=' key | 'tagFilter=' key tagFilterOperator strValue (a key alone matches the spans having the tag)
+//	tagFilterOperator ::= '!=' | '>' | '>=' | '<' | '<=' (the comparisons are numeric but '!=')
+//	log ::= 'log=' keyvalue
+//	logs :== 'logs=' jsonMap
+//	resource ::= 'resource=' keyvalue (key is one of spanstore.SearchableResourceAttributes)
+//	traceIDPrefix ::= 'traceIDPrefix=' strValue (at least spanstore.MinTraceIDPrefixLength hex digits)
 func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParameters, error) {
 	service := r.FormValue(serviceParam)
 	operation := r.FormValue(operationParam)
@@ -142,6 +189,24 @@ func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParamet
 		return nil, err
 	}
 
+	tagFilters, err := parseTagFilters(r.Form[tagFilterParam])
+	if err != nil {
+		return nil, err
+	}
+
+	var logFields map[string]string
+	if len(r.Form[logParam]) > 0 || len(r.Form[logsParam]) > 0 {
+		logFields, err = parseKeyValues(r.Form[logParam], r.Form[logsParam], logParam, logsParam)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resourceAttributes, err := parseResourceAttributes(r.Form[resourceParam])
+	if err != nil {
+		return nil, err
+	}
+
 	limitParam := r.FormValue(limitParam)
 	limit := defaultQueryLimit
 	if limitParam != "" {
@@ -172,18 +237,30 @@ func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParamet
 		traceIDs = append(traceIDs, traceID)
 	}
 
+	var traceIDPrefix string
+	if prefix := r.FormValue(traceIDPrefixParam); prefix != "" {
+		traceIDPrefix, err = spanstore.NormalizeTraceIDPrefix(prefix)
+		if err != nil {
+			return nil, newParseError(err, traceIDPrefixParam)
+		}
+	}
+
 	traceQuery := &traceQueryParameters{
 		TraceQueryParameters: spanstore.TraceQueryParameters{
-			ServiceName:   service,
-			OperationName: operation,
-			StartTimeMin:  startTime,
-			StartTimeMax:  endTime,
-			Tags:          tags,
-			NumTraces:     limit,
-			DurationMin:   minDuration,
-			DurationMax:   maxDuration,
+			ServiceName:        service,
+			OperationName:      operation,
+			StartTimeMin:       startTime,
+			StartTimeMax:       endTime,
+			Tags:               tags,
+			TagFilters:         tagFilters,
+			LogFields:          logFields,
+			NumTraces:          limit,
+			ResourceAttributes: resourceAttributes,
+			DurationMin:        minDuration,
+			DurationMax:        maxDuration,
 		},
-		traceIDs: traceIDs,
+		traceIDs:      traceIDs,
+		traceIDPrefix: traceIDPrefix,
 	}
 
 	if err := p.validateQuery(traceQuery); err != nil {
@@ -192,6 +269,252 @@ func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParamet
 	return traceQuery, nil
 }
 
+// parseLatencyQueryParams takes a request and constructs a model of latency distribution query parameters.
+//
+// Latency query syntax:
+//
+//	query ::= param | param '&' query
+//	param ::= service | operation | start | end | bucket
+//	service ::= 'service=' strValue
+//	operation ::= 'operation=' strValue
+//	start ::= 'start=' intValue in unix microseconds
+//	end ::= 'end=' intValue in unix microseconds
+//	bucket ::= 'bucket=' strValue (histogram bucket upper bound, units are "ns", "us" (or "µs"), "ms", "s", "m", "h")
+func (p *queryParser) parseLatencyQueryParams(r *http.Request) (*spanstore.LatencyQueryParameters, error) {
+	service := r.FormValue(serviceParam)
+	if service == "" {
+		return nil, errServiceParameterRequired
+	}
+	startTime, err := p.parseTime(r, startTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := p.parseTime(r, endTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	var bounds []time.Duration
+	for _, b := range r.Form[bucketParam] {
+		bound, err := time.ParseDuration(b)
+		if err != nil {
+			return nil, newParseError(err, bucketParam)
+		}
+		if bound <= 0 || (len(bounds) > 0 && bound <= bounds[len(bounds)-1]) {
+			return nil, errBucketsNotAscending
+		}
+		bounds = append(bounds, bound)
+	}
+	return &spanstore.LatencyQueryParameters{
+		ServiceName:   service,
+		OperationName: r.FormValue(operationParam),
+		StartTimeMin:  startTime,
+		StartTimeMax:  endTime,
+		BucketBounds:  bounds,
+	}, nil
+}
+
+// parseExemplarQueryParams takes a request and constructs a query of the traces of a metric exemplar.
+//
+// Query Parameters:
+//
+//	/exemplars/traces?service=myservice&operation=myop&timestamp=...&latency=250ms&window=30s&limit=10
+//
+//	service ::= 'service=' strValue
+//	operation ::= 'operation=' strValue
+//	timestamp ::= 'timestamp=' intValue in unix microseconds
+//	latency ::= 'latency=' strValue (units are "ns", "us" (or "µs"), "ms", "s", "m", "h")
+//	window ::= 'window=' strValue (units are "ns", "us" (or "µs"), "ms", "s", "m", "h")
+//	limit ::= 'limit=' intValue
+func (p *queryParser) parseExemplarQueryParams(r *http.Request) (*querysvc.ExemplarQuery, error) {
+	service := r.FormValue(serviceParam)
+	if service == "" {
+		return nil, errServiceParameterRequired
+	}
+	if r.FormValue(timestampParam) == "" {
+		return nil, errTimestampParameterRequired
+	}
+	timestamp, err := p.parseTime(r, timestampParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	latency, err := parseDuration(r, latencyParam, newDurationStringParser(), 0)
+	if err != nil {
+		return nil, err
+	}
+	window, err := parseDuration(r, windowParam, newDurationStringParser(), defaultExemplarWindow)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultExemplarLimit
+	if limitValue := r.FormValue(limitParam); limitValue != "" {
+		limitParsed, err := strconv.ParseInt(limitValue, 10, 32)
+		if err != nil {
+			return nil, newParseError(err, limitParam)
+		}
+		limit = int(limitParsed)
+	}
+	return &querysvc.ExemplarQuery{
+		ServiceName:   service,
+		OperationName: r.FormValue(operationParam),
+		Timestamp:     timestamp,
+		Latency:       latency,
+		Window:        window,
+		Limit:         limit,
+	}, nil
+}
+
+// parseCallPathQueryParams takes a request and constructs a query of the call paths through a service.
+//
+// Query Parameters:
+//
+//	/call-paths?service=myservice&operation=myop&start=...&end=...&limit=20
+//
+//	service ::= 'service=' strValue
+//	operation ::= 'operation=' strValue
+//	start ::= 'start=' intValue in unix microseconds
+//	end ::= 'end=' intValue in unix microseconds
+//	limit ::= 'limit=' intValue, all the paths are returned if 0
+func (p *queryParser) parseCallPathQueryParams(r *http.Request) (*querysvc.CallPathQuery, error) {
+	service := r.FormValue(serviceParam)
+	if service == "" {
+		return nil, errServiceParameterRequired
+	}
+	startTime, err := p.parseTime(r, startTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := p.parseTime(r, endTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultCallPathLimit
+	if limitValue := r.FormValue(limitParam); limitValue != "" {
+		limitParsed, err := strconv.ParseInt(limitValue, 10, 32)
+		if err != nil {
+			return nil, newParseError(err, limitParam)
+		}
+		limit = int(limitParsed)
+	}
+	return &querysvc.CallPathQuery{
+		ServiceName:   service,
+		OperationName: r.FormValue(operationParam),
+		StartTimeMin:  startTime,
+		StartTimeMax:  endTime,
+		Limit:         limit,
+	}, nil
+}
+
+// parseQualityScoreQueryParams takes a request and constructs a query of the instrumentation quality
+// scores of a service, or of all the services if none.
+//
+// Query Parameters:
+//
+//	/quality/scores?service=myservice&start=...&end=...
+//
+//	service ::= 'service=' strValue
+//	start ::= 'start=' intValue in unix microseconds
+//	end ::= 'end=' intValue in unix microseconds
+func (p *queryParser) parseQualityScoreQueryParams(r *http.Request) (*querysvc.QualityScoreQuery, error) {
+	startTime, err := p.parseTime(r, startTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := p.parseTime(r, endTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	return &querysvc.QualityScoreQuery{
+		ServiceName:  r.FormValue(serviceParam),
+		StartTimeMin: startTime,
+		StartTimeMax: endTime,
+	}, nil
+}
+
+// parseRegressionQueryParams takes a request and constructs a query for detected regressions.
+//
+// Query Parameters:
+//
+//	/regressions?service=myservice&operation=myop&start=...&end=...
+//
+//	service ::= 'service=' strValue
+//	operation ::= 'operation=' strValue
+//	start ::= 'start=' intValue in unix microseconds
+//	end ::= 'end=' intValue in unix microseconds
+func (p *queryParser) parseRegressionQueryParams(r *http.Request) (regression.Query, error) {
+	startTime, err := p.parseTime(r, startTimeParam, time.Microsecond)
+	if err != nil {
+		return regression.Query{}, err
+	}
+	endTime, err := p.parseTime(r, endTimeParam, time.Microsecond)
+	if err != nil {
+		return regression.Query{}, err
+	}
+	return regression.Query{
+		ServiceName:   r.FormValue(serviceParam),
+		OperationName: r.FormValue(operationParam),
+		StartTime:     startTime,
+		EndTime:       endTime,
+	}, nil
+}
+
+// parseOperationsQueryParams takes a request and constructs a query of the operations of a service,
+// optionally restricted to a name prefix and paginated with the offset and limit parameters.
+func (*queryParser) parseOperationsQueryParams(r *http.Request) (spanstore.OperationQueryParameters, error) {
+	query := spanstore.OperationQueryParameters{
+		ServiceName: r.FormValue(serviceParam),
+		SpanKind:    r.FormValue(spanKindParam),
+		NamePrefix:  r.FormValue(prefixParam),
+	}
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{name: offsetParam, value: &query.Offset},
+		{name: limitParam, value: &query.Limit},
+	} {
+		s := r.FormValue(param.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(s, 10, 31)
+		if err != nil {
+			return query, newParseError(err, param.name)
+		}
+		*param.value = int(n)
+	}
+	return query, nil
+}
+
+// parseWarningsQueryParams takes a request and constructs a query of the recorded warnings of the traces.
+func (p *queryParser) parseWarningsQueryParams(r *http.Request) (*warningstore.Query, error) {
+	warningType := r.FormValue(typeParam)
+	if warningType != "" && !slices.Contains(adjuster.WarningTypes, warningType) {
+		return nil, fmt.Errorf("unsupported warning type %q, expected one of %v", warningType, adjuster.WarningTypes)
+	}
+	startTime, err := p.parseTime(r, startTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := p.parseTime(r, endTimeParam, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultQueryLimit
+	if limitValue := r.FormValue(limitParam); limitValue != "" {
+		limitParsed, err := strconv.ParseInt(limitValue, 10, 32)
+		if err != nil {
+			return nil, newParseError(err, limitParam)
+		}
+		limit = int(limitParsed)
+	}
+	return &warningstore.Query{
+		Type:         warningType,
+		StartTimeMin: startTime,
+		StartTimeMax: endTime,
+		NumTraces:    limit,
+	}, nil
+}
+
 // parseDependenciesQueryParams takes a request and constructs a model of dependencies query parameters.
 //
 // The dependencies API does not operate on the latency space, instead its timestamps are just time range selections,
@@ -370,7 +693,7 @@ func mapSpanKindsToOpenTelemetry(spanKinds []string) ([]string, error) {
 }
 
 func (*queryParser) validateQuery(traceQuery *traceQueryParameters) error {
-	if len(traceQuery.traceIDs) == 0 && traceQuery.ServiceName == "" {
+	if len(traceQuery.traceIDs) == 0 && traceQuery.traceIDPrefix == "" && traceQuery.ServiceName == "" {
 		return errServiceParameterRequired
 	}
 	if traceQuery.DurationMin != 0 && traceQuery.DurationMax != 0 {
@@ -382,18 +705,23 @@ func (*queryParser) validateQuery(traceQuery *traceQueryParameters) error {
 }
 
 func (*queryParser) parseTags(simpleTags []string, jsonTags []string) (map[string]string, error) {
+	return parseKeyValues(simpleTags, jsonTags, tagParam, tagsParam)
+}
+
+// parseKeyValues merges key:value pairs from simpleParam and JSON maps from jsonParam.
+func parseKeyValues(simpleValues []string, jsonValues []string, simpleParam, jsonParam string) (map[string]string, error) {
 	retMe := make(map[string]string)
-	for _, tag := range simpleTags {
+	for _, tag := range simpleValues {
 		keyAndValue := strings.Split(tag, ":")
 		if l := len(keyAndValue); l <= 1 {
-			return nil, fmt.Errorf("malformed 'tag' parameter, expecting key:value, received: %s", tag)
+			return nil, fmt.Errorf("malformed '%s' parameter, expecting key:value, received: %s", simpleParam, tag)
 		}
 		retMe[keyAndValue[0]] = strings.Join(keyAndValue[1:], ":")
 	}
-	for _, tags := range jsonTags {
+	for _, tags := range jsonValues {
 		var fromJSON map[string]string
 		if err := json.Unmarshal([]byte(tags), &fromJSON); err != nil {
-			return nil, fmt.Errorf("malformed 'tags' parameter, cannot unmarshal JSON: %w", err)
+			return nil, fmt.Errorf("malformed '%s' parameter, cannot unmarshal JSON: %w", jsonParam, err)
 		}
 		for k, v := range fromJSON {
 			retMe[k] = v
@@ -402,6 +730,48 @@ func (*queryParser) parseTags(simpleTags []string, jsonTags []string) (map[strin
 	return retMe, nil
 }
 
+// parseTagFilters parses the key, operator and value expressions of the tag filters.
+func parseTagFilters(values []string) ([]spanstore.TagFilter, error) {
+	var filters []spanstore.TagFilter
+	for _, value := range values {
+		filter := spanstore.TagFilter{Key: strings.TrimSpace(value), Operator: spanstore.TagFilterExists}
+		index := len(value)
+		// the operators are listed the longest first, so that >= is not parsed as >
+		for _, operator := range spanstore.TagFilterOperators {
+			if i := strings.Index(value, string(operator)); i >= 0 && i < index {
+				index = i
+				filter = spanstore.TagFilter{
+					Key:      strings.TrimSpace(value[:i]),
+					Operator: operator,
+					Value:    strings.TrimSpace(value[i+len(operator):]),
+				}
+			}
+		}
+		if err := filter.Validate(); err != nil {
+			return nil, newParseError(err, tagFilterParam)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// parseResourceAttributes parses the key:value pairs of the searchable resource attributes.
+func parseResourceAttributes(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	attributes, err := parseKeyValues(values, nil, resourceParam, "")
+	if err != nil {
+		return nil, err
+	}
+	for key := range attributes {
+		if !slices.Contains(spanstore.SearchableResourceAttributes, key) {
+			return nil, fmt.Errorf("unsupported '%s' parameter %s, expecting one of %v", resourceParam, key, spanstore.SearchableResourceAttributes)
+		}
+	}
+	return attributes, nil
+}
+
 func newParseError(err error, paramName string) error {
 	return fmt.Errorf("unable to parse param '%s': %w", paramName, err)
 }
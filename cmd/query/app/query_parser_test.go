@@ -92,6 +92,41 @@ func TestParseTraceQuery(t *testing.T) {
 				},
 			},
 		},
+		{"x?service=service&start=0&end=0&log=event:exception&log=exception.type", `malformed 'log' parameter, expecting key:value, received: exception.type`, nil},
+		{`x?service=service&start=0&end=0&logs={"event":1}`, "malformed 'logs' parameter, cannot unmarshal JSON: json: cannot unmarshal number into Go .* string", nil},
+		// log=k:v and logs=JSON
+		{
+			`x?service=service&start=0&end=0&limit=200&log=event:exception&logs={"exception.type":"TimeoutError"}`, noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    200,
+					Tags:         make(map[string]string),
+					LogFields:    map[string]string{"event": "exception", "exception.type": "TimeoutError"},
+				},
+			},
+		},
+		// resource=k:v
+		{
+			"x?service=service&start=0&end=0&limit=200&resource=deployment.environment:prod&resource=k8s.namespace.name:checkout", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    200,
+					Tags:         make(map[string]string),
+					ResourceAttributes: map[string]string{
+						"deployment.environment": "prod",
+						"k8s.namespace.name":     "checkout",
+					},
+				},
+			},
+		},
+		{"x?service=service&start=0&end=0&resource=deployment.environment", `malformed 'resource' parameter, expecting key:value, received: deployment.environment`, nil},
+		{"x?service=service&start=0&end=0&resource=cloud.region:eu", `unsupported 'resource' parameter cloud.region, expecting one of .*`, nil},
 		{
 			"x?service=service&start=0&end=0&operation=operation&limit=200&minDuration=10s&maxDuration=20s", noErr,
 			&traceQueryParameters{
@@ -137,6 +172,40 @@ func TestParseTraceQuery(t *testing.T) {
 				},
 			},
 		},
+		// tag filters
+		{
+			"x?service=service&start=0&end=0&tagFilter=error&tagFilter=http.status_code>=500&tagFilter= http.method != GET", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    100,
+					Tags:         make(map[string]string),
+					TagFilters: []spanstore.TagFilter{
+						{Key: "error", Operator: spanstore.TagFilterExists},
+						{Key: "http.status_code", Operator: spanstore.TagFilterGreaterOrEqual, Value: "500"},
+						{Key: "http.method", Operator: spanstore.TagFilterNotEqual, Value: "GET"},
+					},
+				},
+			},
+		},
+		{"x?service=service&tagFilter=http.status_code<5xx", `unable to parse param 'tagFilter': the tag filter http.status_code < 5xx must compare with a number`, nil},
+		{"x?service=service&tagFilter=>=500", `unable to parse param 'tagFilter': the key of a tag filter cannot be empty`, nil},
+		// trace ID prefix without service
+		{
+			"x?traceIDPrefix=4BF92F", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					NumTraces:    100,
+					StartTimeMin: timeNow,
+					StartTimeMax: timeNow,
+					Tags:         make(map[string]string),
+				},
+				traceIDPrefix: "4bf92f",
+			},
+		},
+		{"x?traceIDPrefix=4bf", `unable to parse param 'traceIDPrefix': trace ID prefix must have between 4 and 32 hex digits, received: 4bf`, nil},
 		{
 			"x?traceID=100&traceID=x200", `cannot parse traceID param: strconv.ParseUint: parsing "x200": invalid syntax`,
 			&traceQueryParameters{
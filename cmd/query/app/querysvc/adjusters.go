@@ -16,21 +16,125 @@
 package querysvc
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jaegertracing/jaeger/model/adjuster"
 )
 
+// AdjusterConfig holds the parameters of the adjusters.
+type AdjusterConfig struct {
+	// MaxClockSkewAdjust is the maximum duration by which the clock-skew adjuster moves a span.
+	MaxClockSkewAdjust time.Duration
+}
+
+// AdjusterFactory creates an adjuster from the parameters of the adjusters.
+type AdjusterFactory func(config AdjusterConfig) adjuster.Adjuster
+
+// standardAdjusterNames are the names of the adjusters applied by default, in order.
+var standardAdjusterNames = []string{
+	"span-id-deduper",
+	"clock-skew",
+	"ip-tag",
+	"otel-tag",
+	"sort-log-fields",
+	"span-references",
+	"missing-parent-spans",
+	"parent-reference",
+}
+
+var (
+	adjusterFactoriesMu sync.RWMutex
+	adjusterFactories   = map[string]AdjusterFactory{
+		"span-id-deduper": func(AdjusterConfig) adjuster.Adjuster { return adjuster.SpanIDDeduper() },
+		"clock-skew": func(config AdjusterConfig) adjuster.Adjuster {
+			return adjuster.ClockSkew(config.MaxClockSkewAdjust)
+		},
+		"ip-tag":               func(AdjusterConfig) adjuster.Adjuster { return adjuster.IPTagAdjuster() },
+		"otel-tag":             func(AdjusterConfig) adjuster.Adjuster { return adjuster.OTelTagAdjuster() },
+		"sort-log-fields":      func(AdjusterConfig) adjuster.Adjuster { return adjuster.SortLogFields() },
+		"span-references":      func(AdjusterConfig) adjuster.Adjuster { return adjuster.SpanReferences() },
+		"missing-parent-spans": func(AdjusterConfig) adjuster.Adjuster { return adjuster.MissingParentSpans() },
+		"parent-reference":     func(AdjusterConfig) adjuster.Adjuster { return adjuster.ParentReference() },
+		"critical-path":        func(AdjusterConfig) adjuster.Adjuster { return adjuster.CriticalPath() },
+		"error-status":         func(AdjusterConfig) adjuster.Adjuster { return adjuster.ErrorStatus() },
+	}
+)
+
+// RegisterAdjuster registers a custom adjuster, which can then be enabled by name like the standard ones.
+// It is meant to be called from the init functions of the packages of forks or plugins, and panics
+// if an adjuster is already registered with the name.
+func RegisterAdjuster(name string, factory AdjusterFactory) {
+	adjusterFactoriesMu.Lock()
+	defer adjusterFactoriesMu.Unlock()
+	if _, ok := adjusterFactories[name]; ok {
+		panic(fmt.Sprintf("adjuster %s registered twice", name))
+	}
+	adjusterFactories[name] = factory
+}
+
+// StandardAdjusterNames returns the names of the adjusters applied by default, in order.
+func StandardAdjusterNames() []string {
+	return append([]string(nil), standardAdjusterNames...)
+}
+
+// AdjusterNames returns the names of all the registered adjusters, sorted.
+func AdjusterNames() []string {
+	adjusterFactoriesMu.RLock()
+	defer adjusterFactoriesMu.RUnlock()
+	return sortedNames()
+}
+
+// ValidateAdjusterNames checks that the adjusters are registered and listed once.
+func ValidateAdjusterNames(names []string) error {
+	adjusterFactoriesMu.RLock()
+	defer adjusterFactoriesMu.RUnlock()
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := adjusterFactories[name]; !ok {
+			return fmt.Errorf("unknown adjuster %q, the adjusters are %s", name, strings.Join(sortedNames(), ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("adjuster %q listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// sortedNames must be called with adjusterFactoriesMu held.
+func sortedNames() []string {
+	names := make([]string, 0, len(adjusterFactories))
+	for name := range adjusterFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewAdjusters creates the adjusters of the names, applied in this order, or the standard adjusters if there are none.
+func NewAdjusters(names []string, config AdjusterConfig) ([]adjuster.Adjuster, error) {
+	if len(names) == 0 {
+		names = standardAdjusterNames
+	}
+	if err := ValidateAdjusterNames(names); err != nil {
+		return nil, err
+	}
+	adjusterFactoriesMu.RLock()
+	defer adjusterFactoriesMu.RUnlock()
+	adjusters := make([]adjuster.Adjuster, len(names))
+	for i, name := range names {
+		adjusters[i] = adjusterFactories[name](config)
+	}
+	return adjusters, nil
+}
+
 // StandardAdjusters is a list of model adjusters applied by the query service
 // before returning the data to the API clients.
 func StandardAdjusters(maxClockSkewAdjust time.Duration) []adjuster.Adjuster {
-	return []adjuster.Adjuster{
-		adjuster.SpanIDDeduper(),
-		adjuster.ClockSkew(maxClockSkewAdjust),
-		adjuster.IPTagAdjuster(),
-		adjuster.OTelTagAdjuster(),
-		adjuster.SortLogFields(),
-		adjuster.SpanReferences(),
-		adjuster.ParentReference(),
-	}
+	adjusters, _ := NewAdjusters(standardAdjusterNames, AdjusterConfig{MaxClockSkewAdjust: maxClockSkewAdjust})
+	return adjusters
 }
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+)
+
+func TestNewAdjusters(t *testing.T) {
+	adjusters, err := NewAdjusters(nil, AdjusterConfig{MaxClockSkewAdjust: time.Second})
+	require.NoError(t, err)
+	assert.Len(t, adjusters, len(StandardAdjusterNames()))
+	assert.Len(t, StandardAdjusters(time.Second), len(StandardAdjusterNames()))
+
+	adjusters, err = NewAdjusters([]string{"critical-path", "span-id-deduper"}, AdjusterConfig{})
+	require.NoError(t, err)
+	require.Len(t, adjusters, 2)
+	trace, err := adjusters[0].Adjust(&model.Trace{Spans: []*model.Span{{SpanID: 1, Process: &model.Process{}}}})
+	require.NoError(t, err)
+	_, found := model.KeyValues(trace.Spans[0].Tags).FindByKey(adjuster.CriticalPathTagKey)
+	assert.True(t, found)
+
+	adjusters, err = NewAdjusters([]string{"error-status"}, AdjusterConfig{})
+	require.NoError(t, err)
+	trace, err = adjusters[0].Adjust(&model.Trace{Spans: []*model.Span{{Tags: model.KeyValues{model.String("error", "true")}}}})
+	require.NoError(t, err)
+	errorTag, found := model.KeyValues(trace.Spans[0].Tags).FindByKey(adjuster.ErrorTag)
+	require.True(t, found)
+	assert.True(t, errorTag.Bool())
+}
+
+func TestNewAdjustersErrors(t *testing.T) {
+	_, err := NewAdjusters([]string{"clock-skew", "unknown"}, AdjusterConfig{})
+	require.ErrorContains(t, err, `unknown adjuster "unknown", the adjusters are clock-skew, critical-path,`)
+
+	_, err = NewAdjusters([]string{"clock-skew", "ip-tag", "clock-skew"}, AdjusterConfig{})
+	require.EqualError(t, err, `adjuster "clock-skew" listed twice`)
+}
+
+func TestRegisterAdjuster(t *testing.T) {
+	maxClockSkewAdjust := time.Duration(0)
+	RegisterAdjuster("test-adjuster", func(config AdjusterConfig) adjuster.Adjuster {
+		maxClockSkewAdjust = config.MaxClockSkewAdjust
+		return adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			trace.Warnings = append(trace.Warnings, "adjusted")
+			return trace, nil
+		})
+	})
+	defer func() {
+		adjusterFactoriesMu.Lock()
+		delete(adjusterFactories, "test-adjuster")
+		adjusterFactoriesMu.Unlock()
+	}()
+	assert.Contains(t, AdjusterNames(), "test-adjuster")
+	assert.NotContains(t, StandardAdjusterNames(), "test-adjuster")
+
+	adjusters, err := NewAdjusters([]string{"test-adjuster"}, AdjusterConfig{MaxClockSkewAdjust: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, maxClockSkewAdjust)
+	trace, err := adjusters[0].Adjust(&model.Trace{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"adjusted"}, trace.Warnings)
+
+	assert.PanicsWithValue(t, "adjuster test-adjuster registered twice", func() {
+		RegisterAdjuster("test-adjuster", nil)
+	})
+}
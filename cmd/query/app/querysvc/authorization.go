@@ -0,0 +1,153 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// ErrServiceNotAllowed is returned when the caller queries a service it is not allowed to.
+var ErrServiceNotAllowed = errors.New("not allowed to query the service")
+
+// AuthorizationRule allows the callers it matches to query the services matching
+// one of its globs. A rule with neither a tenant nor a claim matches all the callers.
+type AuthorizationRule struct {
+	// Tenant matches the tenant of the request, see pkg/tenancy.
+	Tenant string `json:"tenant,omitempty"`
+	// Claim and Value match a claim of the JWT bearer token of the request,
+	// either a string equal to Value or an array of strings containing it.
+	Claim string `json:"claim,omitempty"`
+	Value string `json:"value,omitempty"`
+	// Services are globs with the syntax of path.Match, e.g. "payment-*".
+	Services []string `json:"services"`
+}
+
+type authorizationRulesFile struct {
+	Rules []AuthorizationRule `json:"rules"`
+}
+
+// LoadAuthorizationRules reads the rules from a JSON file of the form {"rules": [...]}.
+func LoadAuthorizationRules(filename string) ([]AuthorizationRule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the authorization rules: %w", err)
+	}
+	var file authorizationRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse the authorization rules: %w", err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, errors.New("no authorization rules found")
+	}
+	for i, rule := range file.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid authorization rule #%d: %w", i, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func (r AuthorizationRule) validate() error {
+	if (r.Claim == "") != (r.Value == "") {
+		return errors.New("claim and value must be set together")
+	}
+	if len(r.Services) == 0 {
+		return errors.New("at least one service glob is required")
+	}
+	for _, glob := range r.Services {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid service glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+func (r AuthorizationRule) matchesCaller(tenant string, claims map[string]any) bool {
+	if r.Tenant != "" && r.Tenant != tenant {
+		return false
+	}
+	if r.Claim == "" {
+		return true
+	}
+	switch value := claims[r.Claim].(type) {
+	case string:
+		return value == r.Value
+	case []any:
+		for _, v := range value {
+			if s, ok := v.(string); ok && s == r.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r AuthorizationRule) matchesService(service string) bool {
+	for _, glob := range r.Services {
+		// the globs are validated when the rules are loaded
+		if ok, _ := path.Match(glob, service); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type claimsContextKey struct{}
+
+// ContextWithClaims returns a context with the JWT claims of the caller, used to authorize the queries.
+func ContextWithClaims(ctx context.Context, claims map[string]any) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the verified JWT claims of the caller, nil if the request has none.
+func ClaimsFromContext(ctx context.Context) map[string]any {
+	claims, _ := ctx.Value(claimsContextKey{}).(map[string]any)
+	return claims
+}
+
+// ServiceAuthorizer restricts the services a caller may query, identified by the tenant
+// and the JWT claims of its request.
+type ServiceAuthorizer struct {
+	rules []AuthorizationRule
+}
+
+// NewServiceAuthorizer creates a ServiceAuthorizer denying the services not allowed by any rule.
+func NewServiceAuthorizer(rules []AuthorizationRule) *ServiceAuthorizer {
+	return &ServiceAuthorizer{rules: rules}
+}
+
+// IsAllowed checks that the caller of the context may query the service.
+func (a *ServiceAuthorizer) IsAllowed(ctx context.Context, service string) bool {
+	tenant, claims := tenancy.GetTenant(ctx), ClaimsFromContext(ctx)
+	for _, rule := range a.rules {
+		if rule.matchesCaller(tenant, claims) && rule.matchesService(service) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTraceAllowed checks that the caller may query all the services of the trace.
+func (a *ServiceAuthorizer) isTraceAllowed(ctx context.Context, trace *model.Trace) bool {
+	checked := make(map[string]bool)
+	for _, span := range trace.Spans {
+		service := span.Process.GetServiceName()
+		if checked[service] {
+			continue
+		}
+		if !a.IsAllowed(ctx, service) {
+			return false
+		}
+		checked[service] = true
+	}
+	return true
+}
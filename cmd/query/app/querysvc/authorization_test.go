@@ -0,0 +1,185 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var testAuthorizationRules = []AuthorizationRule{
+	{Claim: "groups", Value: "payments", Services: []string{"payment-*"}},
+	{Tenant: "acme", Services: []string{"acme-*"}},
+	{Services: []string{"frontend"}},
+}
+
+func withAuthorizer() testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.Authorizer = NewServiceAuthorizer(testAuthorizationRules)
+	}
+}
+
+func paymentsCaller() context.Context {
+	return ContextWithClaims(context.Background(), map[string]any{"groups": []any{"dev", "payments"}})
+}
+
+func writeRulesFile(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	return filename
+}
+
+func TestLoadAuthorizationRules(t *testing.T) {
+	filename := writeRulesFile(t, `{"rules": [
+		{"claim": "groups", "value": "payments", "services": ["payment-*"]},
+		{"tenant": "acme", "services": ["acme-*"]}
+	]}`)
+	rules, err := LoadAuthorizationRules(filename)
+	require.NoError(t, err)
+	assert.Equal(t, testAuthorizationRules[:2], rules)
+}
+
+func TestLoadAuthorizationRulesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "invalid JSON", content: `{`, err: "failed to parse the authorization rules"},
+		{name: "no rules", content: `{"rules": []}`, err: "no authorization rules found"},
+		{name: "claim without value", content: `{"rules": [{"claim": "sub", "services": ["*"]}]}`, err: "invalid authorization rule #0: claim and value must be set together"},
+		{name: "no services", content: `{"rules": [{"tenant": "acme"}]}`, err: "at least one service glob is required"},
+		{name: "invalid glob", content: `{"rules": [{"tenant": "acme", "services": ["[a"]}]}`, err: `invalid service glob "[a"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadAuthorizationRules(writeRulesFile(t, test.content))
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+
+	_, err := LoadAuthorizationRules(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "failed to read the authorization rules")
+}
+
+func TestServiceAuthorizerIsAllowed(t *testing.T) {
+	authorizer := NewServiceAuthorizer(testAuthorizationRules)
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		service string
+		allowed bool
+	}{
+		{name: "claim in array", ctx: paymentsCaller(), service: "payment-api", allowed: true},
+		{name: "string claim", ctx: ContextWithClaims(context.Background(), map[string]any{"groups": "payments"}), service: "payment-api", allowed: true},
+		{name: "other claim value", ctx: ContextWithClaims(context.Background(), map[string]any{"groups": "dev"}), service: "payment-api"},
+		{name: "no claims", ctx: context.Background(), service: "payment-api"},
+		{name: "tenant", ctx: tenancy.WithTenant(context.Background(), "acme"), service: "acme-billing", allowed: true},
+		{name: "other tenant", ctx: tenancy.WithTenant(context.Background(), "other"), service: "acme-billing"},
+		{name: "rule for all the callers", ctx: context.Background(), service: "frontend", allowed: true},
+		{name: "service not matched", ctx: paymentsCaller(), service: "inventory"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.allowed, authorizer.IsAllowed(test.ctx, test.service))
+		})
+	}
+}
+
+func traceOfServices(services ...string) *model.Trace {
+	trace := &model.Trace{}
+	for i, service := range services {
+		trace.Spans = append(trace.Spans, &model.Span{
+			TraceID: mockTraceID,
+			SpanID:  model.NewSpanID(uint64(i + 1)),
+			Process: &model.Process{ServiceName: service},
+		})
+	}
+	return trace
+}
+
+func TestGetServicesAuthorized(t *testing.T) {
+	tqs := initializeTestService(withAuthorizer())
+	tqs.spanReader.On("GetServices", mock.Anything).
+		Return([]string{"frontend", "inventory", "payment-api"}, nil).Once()
+
+	services, err := tqs.queryService.GetServices(paymentsCaller())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "payment-api"}, services)
+}
+
+func TestGetTraceAuthorized(t *testing.T) {
+	tqs := initializeTestService(withAuthorizer())
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+		Return(traceOfServices("frontend", "payment-api"), nil).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+		Return(traceOfServices("frontend", "inventory"), nil).Once()
+
+	_, err := tqs.queryService.GetTrace(paymentsCaller(), mockTraceID)
+	require.NoError(t, err)
+	_, err = tqs.queryService.GetTrace(paymentsCaller(), mockTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+func TestFindTracesAuthorized(t *testing.T) {
+	tqs := initializeTestService(withAuthorizer())
+	allowed := traceOfServices("payment-api", "frontend")
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).
+		Return([]*model.Trace{allowed, traceOfServices("payment-api", "inventory")}, nil).Once()
+
+	traces, err := tqs.queryService.FindTraces(paymentsCaller(), &spanstore.TraceQueryParameters{ServiceName: "payment-api"})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{allowed}, traces)
+
+	_, err = tqs.queryService.FindTraces(paymentsCaller(), &spanstore.TraceQueryParameters{ServiceName: "inventory"})
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+	assert.EqualError(t, err, `not allowed to query the service "inventory"`)
+}
+
+func TestGetOperationsAndLatenciesAuthorized(t *testing.T) {
+	tqs := initializeTestService(withAuthorizer())
+
+	_, err := tqs.queryService.GetOperations(paymentsCaller(), spanstore.OperationQueryParameters{ServiceName: "inventory"})
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+	_, err = tqs.queryService.GetLatencyDistribution(paymentsCaller(), &spanstore.LatencyQueryParameters{ServiceName: "inventory"})
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+}
+
+func TestGetDependenciesAuthorized(t *testing.T) {
+	tqs := initializeTestService(withAuthorizer())
+	allowed := model.DependencyLink{Parent: "frontend", Child: "payment-api", CallCount: 1}
+	tqs.depsReader.On("GetDependencies", mock.Anything, mock.Anything, mock.Anything).
+		Return([]model.DependencyLink{
+			allowed,
+			{Parent: "frontend", Child: "inventory", CallCount: 2},
+			{Parent: "inventory", Child: "payment-api", CallCount: 3},
+		}, nil).Once()
+
+	links, err := tqs.queryService.GetDependencies(paymentsCaller(), time.Now(), defaultDependencyLookbackDuration)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{allowed}, links)
+}
+
+func TestWithoutAuthorization(t *testing.T) {
+	tqs := initializeTestService(withAuthorizer())
+	tqs.spanReader.On("GetServices", mock.Anything).Return([]string{"inventory"}, nil).Once()
+
+	unrestricted := tqs.queryService.WithoutAuthorization()
+	services, err := unrestricted.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inventory"}, services)
+	assert.True(t, unrestricted.IsServiceAllowed(context.Background(), "inventory"))
+	assert.False(t, tqs.queryService.IsServiceAllowed(context.Background(), "inventory"))
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// maxCallPathTraces is the number of traces of the time range the call paths are extracted from.
+const maxCallPathTraces = 1000
+
+// CallPathQuery selects the call paths through a service, or through one of its operations,
+// the operation of the span entering the service.
+type CallPathQuery struct {
+	ServiceName   string
+	OperationName string
+	StartTimeMin  time.Time
+	StartTimeMax  time.Time
+	// Limit is the maximum number of paths returned, the most frequent ones, all of them are returned if zero.
+	Limit int
+}
+
+// CallHop is a call to a service in a CallPath.
+type CallHop struct {
+	ServiceName   string
+	OperationName string
+	// Latency is the distribution of the durations of the spans entering the service.
+	Latency *spanstore.LatencyDistribution
+}
+
+// CallPath is a chain of calls between services, from the root span of the traces to a service
+// calling no other one, e.g. frontend → checkout → payment. The consecutive spans of a service
+// are part of the same hop, which is entered by the first of them.
+type CallPath struct {
+	Hops []CallHop
+	// Count is the number of occurrences of the path, a trace containing it as many times as it makes its calls.
+	Count int64
+	// TraceCount is the number of traces containing the path.
+	TraceCount int64
+}
+
+// callPathStats aggregates the occurrences of a call path.
+type callPathStats struct {
+	hops       []*model.Span
+	durations  [][]time.Duration
+	count      int64
+	traceCount int64
+	lastTrace  int
+}
+
+// FindCallPaths extracts the call paths of the traces of the time range going through the service,
+// or through the operation if any, ordered by decreasing number of occurrences.
+func (qs QueryService) FindCallPaths(ctx context.Context, query *CallPathQuery) ([]CallPath, error) {
+	traces, err := qs.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:   query.ServiceName,
+		OperationName: query.OperationName,
+		StartTimeMin:  query.StartTimeMin,
+		StartTimeMax:  query.StartTimeMax,
+		NumTraces:     maxCallPathTraces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]*callPathStats)
+	for i, trace := range traces {
+		extractCallPaths(trace, func(hops []*model.Span) {
+			if !hasHop(hops, query.ServiceName, query.OperationName) {
+				return
+			}
+			key := callPathKey(hops)
+			s, ok := stats[key]
+			if !ok {
+				s = &callPathStats{
+					hops:      append([]*model.Span(nil), hops...),
+					durations: make([][]time.Duration, len(hops)),
+					lastTrace: -1,
+				}
+				stats[key] = s
+			}
+			for j, hop := range hops {
+				s.durations[j] = append(s.durations[j], hop.Duration)
+			}
+			s.count++
+			if s.lastTrace != i {
+				s.traceCount++
+				s.lastTrace = i
+			}
+		})
+	}
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if stats[keys[i]].count != stats[keys[j]].count {
+			return stats[keys[i]].count > stats[keys[j]].count
+		}
+		return keys[i] < keys[j]
+	})
+	if query.Limit > 0 && len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+	}
+	paths := make([]CallPath, len(keys))
+	for i, key := range keys {
+		s := stats[key]
+		hops := make([]CallHop, len(s.hops))
+		for j, hop := range s.hops {
+			hops[j] = CallHop{
+				ServiceName:   hop.Process.GetServiceName(),
+				OperationName: hop.OperationName,
+				Latency:       spanstore.ComputeLatencyDistribution(s.durations[j], nil),
+			}
+		}
+		paths[i] = CallPath{Hops: hops, Count: s.count, TraceCount: s.traceCount}
+	}
+	return paths, nil
+}
+
+// extractCallPaths calls emit with the spans entering the services of each call path of the trace.
+func extractCallPaths(trace *model.Trace, emit func(hops []*model.Span)) {
+	spans := make(map[model.SpanID]bool, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spans[span.SpanID] = true
+	}
+	children := make(map[model.SpanID][]*model.Span)
+	var roots []*model.Span
+	for _, span := range trace.Spans {
+		if parentID := span.ParentSpanID(); parentID != 0 && spans[parentID] {
+			children[parentID] = append(children[parentID], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+	// visited guards against the cycles of the traces with duplicate span IDs
+	visited := make(map[*model.Span]bool, len(trace.Spans))
+	var visit func(span *model.Span, hops []*model.Span) bool
+	visit = func(span *model.Span, hops []*model.Span) bool {
+		if visited[span] {
+			return false
+		}
+		visited[span] = true
+		entered := len(hops) == 0 || hops[len(hops)-1].Process.GetServiceName() != span.Process.GetServiceName()
+		if entered {
+			hops = append(hops, span)
+		}
+		extended := false
+		for _, child := range children[span.SpanID] {
+			if visit(child, hops) {
+				extended = true
+			}
+		}
+		if entered && !extended {
+			emit(hops)
+		}
+		return entered || extended
+	}
+	for _, root := range roots {
+		visit(root, nil)
+	}
+}
+
+func hasHop(hops []*model.Span, service, operation string) bool {
+	for _, hop := range hops {
+		if hop.Process.GetServiceName() == service && (operation == "" || hop.OperationName == operation) {
+			return true
+		}
+	}
+	return false
+}
+
+func callPathKey(hops []*model.Span) string {
+	var sb strings.Builder
+	for _, hop := range hops {
+		sb.WriteString(hop.Process.GetServiceName())
+		sb.WriteByte(0)
+		sb.WriteString(hop.OperationName)
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func newCallPathSpan(traceID, spanID, parentID uint64, service, operation string, duration time.Duration, start time.Time) *model.Span {
+	span := &model.Span{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: operation,
+		StartTime:     start,
+		Duration:      duration,
+		Process:       &model.Process{ServiceName: service},
+	}
+	if parentID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(span.TraceID, model.NewSpanID(parentID))}
+	}
+	return span
+}
+
+func hopNames(path CallPath) []string {
+	names := make([]string, len(path.Hops))
+	for i, hop := range path.Hops {
+		names[i] = hop.ServiceName + ":" + hop.OperationName
+	}
+	return names
+}
+
+func TestFindCallPaths(t *testing.T) {
+	store := memory.NewStore()
+	start := time.Now().Add(-time.Minute)
+	spans := []*model.Span{
+		// frontend → checkout → payment, and frontend → inventory
+		newCallPathSpan(1, 1, 0, "frontend", "GET", 100*time.Millisecond, start),
+		newCallPathSpan(1, 2, 1, "frontend", "render", 90*time.Millisecond, start),
+		newCallPathSpan(1, 3, 2, "checkout", "POST", 50*time.Millisecond, start),
+		newCallPathSpan(1, 4, 3, "payment", "charge", 20*time.Millisecond, start),
+		newCallPathSpan(1, 5, 1, "inventory", "reserve", 10*time.Millisecond, start),
+		// frontend → checkout → payment twice
+		newCallPathSpan(2, 1, 0, "frontend", "GET", 200*time.Millisecond, start),
+		newCallPathSpan(2, 2, 1, "checkout", "POST", 150*time.Millisecond, start),
+		newCallPathSpan(2, 3, 2, "payment", "charge", 40*time.Millisecond, start),
+		newCallPathSpan(2, 4, 2, "payment", "charge", 60*time.Millisecond, start),
+		// not through frontend
+		newCallPathSpan(3, 1, 0, "checkout", "POST", 10*time.Millisecond, start),
+	}
+	for _, span := range spans {
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	qs := NewQueryService(store, &depsmocks.Reader{}, QueryServiceOptions{})
+	query := &CallPathQuery{
+		ServiceName:  "frontend",
+		StartTimeMin: start.Add(-time.Minute),
+		StartTimeMax: start.Add(time.Minute),
+	}
+	paths, err := qs.FindCallPaths(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.Equal(t, []string{"frontend:GET", "checkout:POST", "payment:charge"}, hopNames(paths[0]))
+	assert.Equal(t, int64(3), paths[0].Count)
+	assert.Equal(t, int64(2), paths[0].TraceCount)
+	assert.Equal(t, int64(3), paths[0].Hops[2].Latency.Count)
+	assert.Equal(t, 40*time.Millisecond, paths[0].Hops[2].Latency.P50)
+	assert.Equal(t, 60*time.Millisecond, paths[0].Hops[2].Latency.P99)
+	assert.Equal(t, []string{"frontend:GET", "inventory:reserve"}, hopNames(paths[1]))
+	assert.Equal(t, int64(1), paths[1].Count)
+	assert.Equal(t, int64(1), paths[1].TraceCount)
+
+	query.Limit = 1
+	paths, err = qs.FindCallPaths(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Equal(t, int64(3), paths[0].Count)
+
+	// the paths through the operation of the span entering the service
+	paths, err = qs.FindCallPaths(context.Background(), &CallPathQuery{
+		ServiceName:   "inventory",
+		OperationName: "reserve",
+		StartTimeMin:  query.StartTimeMin,
+		StartTimeMax:  query.StartTimeMax,
+	})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Equal(t, []string{"frontend:GET", "inventory:reserve"}, hopNames(paths[0]))
+}
+
+func TestFindCallPathsError(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error")).Once()
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{})
+	_, err := qs.FindCallPaths(context.Background(), &CallPathQuery{ServiceName: "frontend"})
+	require.EqualError(t, err, "storage error")
+}
+
+func TestExtractCallPaths(t *testing.T) {
+	start := time.Now()
+	trace := &model.Trace{Spans: []*model.Span{
+		newCallPathSpan(1, 1, 0, "frontend", "GET", time.Second, start),
+		newCallPathSpan(1, 2, 1, "checkout", "POST", time.Second, start),
+		// the duplicate span ID makes a cycle
+		newCallPathSpan(1, 1, 2, "payment", "charge", time.Second, start),
+		// the parent is missing, the span is a root
+		newCallPathSpan(1, 4, 9, "batch", "run", time.Second, start),
+	}}
+	var paths [][]string
+	extractCallPaths(trace, func(hops []*model.Span) {
+		path := make([]string, len(hops))
+		for i, hop := range hops {
+			path[i] = hop.Process.GetServiceName()
+		}
+		paths = append(paths, path)
+	})
+	assert.Equal(t, [][]string{{"frontend", "checkout", "payment"}, {"batch"}}, paths)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// ErrQueryCostExceeded is returned when a query exceeds one of the cost limits of the caller.
+var ErrQueryCostExceeded = errors.New("query cost limit exceeded")
+
+// CostLimits bound the cost of the queries of a caller, a zero limit being unbounded.
+type CostLimits struct {
+	// MaxSpans is the maximum number of spans returned by a search.
+	MaxSpans int
+	// MaxSubQueries is the maximum number of storage queries of a search, see QueryCost.
+	MaxSubQueries int
+	// MaxTimeRange is the maximum time range searched by a query.
+	MaxTimeRange time.Duration
+}
+
+// QueryCost is the cost of a query checked against the CostLimits, the zero fields not being checked.
+type QueryCost struct {
+	// Spans is the number of spans returned.
+	Spans int
+	// SubQueries is the number of storage queries, estimated as the index searches of a trace search,
+	// one for each of its tags, log fields, tag filters, operation and duration range but at least one,
+	// and the reads of the traces found.
+	SubQueries int
+	// TimeRange is the time range searched.
+	TimeRange time.Duration
+}
+
+// CostLimitError tells the limit exceeded by a query, it matches ErrQueryCostExceeded.
+type CostLimitError struct {
+	// Tenant is the tenant of the query, empty without multi-tenancy.
+	Tenant string `json:"tenant,omitempty"`
+	// Limit is the name of the limit exceeded, i.e. maxSpans, maxSubQueries or maxTimeRange.
+	Limit string `json:"limit"`
+	// Max is the limit and Value the cost of the query.
+	Max   string `json:"max"`
+	Value string `json:"value"`
+
+	description string
+}
+
+func (e *CostLimitError) Error() string {
+	return fmt.Sprintf("%s: the query %s, above the limit of %s", ErrQueryCostExceeded, e.description, e.Max)
+}
+
+// Is makes the error match ErrQueryCostExceeded.
+func (*CostLimitError) Is(target error) bool {
+	return target == ErrQueryCostExceeded
+}
+
+// CostLimiter rejects the queries exceeding the cost limits of their tenant, see pkg/tenancy.
+type CostLimiter struct {
+	defaults CostLimits
+	tenants  map[string]CostLimits
+}
+
+// NewCostLimiter creates a CostLimiter with the default limits and their overrides by tenant. The non-zero
+// limits of a tenant replace the default ones, a negative limit making the queries of the tenant unbounded.
+func NewCostLimiter(defaults CostLimits, tenants map[string]CostLimits) *CostLimiter {
+	return &CostLimiter{defaults: defaults, tenants: tenants}
+}
+
+// Limits returns the cost limits of the tenant.
+func (l *CostLimiter) Limits(tenant string) CostLimits {
+	limits := l.defaults
+	overrides, ok := l.tenants[tenant]
+	if !ok {
+		return limits
+	}
+	if overrides.MaxSpans != 0 {
+		limits.MaxSpans = overrides.MaxSpans
+	}
+	if overrides.MaxSubQueries != 0 {
+		limits.MaxSubQueries = overrides.MaxSubQueries
+	}
+	if overrides.MaxTimeRange != 0 {
+		limits.MaxTimeRange = overrides.MaxTimeRange
+	}
+	return limits
+}
+
+// Check returns a CostLimitError if the cost exceeds one of the limits of the tenant of the context.
+func (l *CostLimiter) Check(ctx context.Context, cost QueryCost) error {
+	tenant := tenancy.GetTenant(ctx)
+	limits := l.Limits(tenant)
+	switch {
+	case limits.MaxTimeRange > 0 && cost.TimeRange > limits.MaxTimeRange:
+		return &CostLimitError{
+			Tenant:      tenant,
+			Limit:       "maxTimeRange",
+			Max:         limits.MaxTimeRange.String(),
+			Value:       cost.TimeRange.String(),
+			description: "searches a time range of " + cost.TimeRange.String(),
+		}
+	case limits.MaxSubQueries > 0 && cost.SubQueries > limits.MaxSubQueries:
+		return &CostLimitError{
+			Tenant:      tenant,
+			Limit:       "maxSubQueries",
+			Max:         strconv.Itoa(limits.MaxSubQueries),
+			Value:       strconv.Itoa(cost.SubQueries),
+			description: fmt.Sprintf("needs %d storage queries", cost.SubQueries),
+		}
+	case limits.MaxSpans > 0 && cost.Spans > limits.MaxSpans:
+		return &CostLimitError{
+			Tenant:      tenant,
+			Limit:       "maxSpans",
+			Max:         strconv.Itoa(limits.MaxSpans),
+			Value:       strconv.Itoa(cost.Spans),
+			description: fmt.Sprintf("returns %d spans", cost.Spans),
+		}
+	}
+	return nil
+}
+
+// tenantCostLimits are the limits of a tenant in the overrides file.
+type tenantCostLimits struct {
+	MaxSpans      int    `json:"maxSpans,omitempty"`
+	MaxSubQueries int    `json:"maxSubQueries,omitempty"`
+	MaxTimeRange  string `json:"maxTimeRange,omitempty"`
+}
+
+type costLimitsFile struct {
+	Tenants map[string]tenantCostLimits `json:"tenants"`
+}
+
+// LoadTenantCostLimits reads the overrides of the cost limits by tenant from a JSON file of the form
+// {"tenants": {"<tenant>": {"maxSpans": 10000, "maxSubQueries": 100, "maxTimeRange": "24h"}}}.
+func LoadTenantCostLimits(filename string) (map[string]CostLimits, error) {
+	data, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tenant cost limits: %w", err)
+	}
+	var file costLimitsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse the tenant cost limits: %w", err)
+	}
+	tenants := make(map[string]CostLimits, len(file.Tenants))
+	for tenant, overrides := range file.Tenants {
+		limits := CostLimits{MaxSpans: overrides.MaxSpans, MaxSubQueries: overrides.MaxSubQueries}
+		if overrides.MaxTimeRange != "" {
+			limits.MaxTimeRange, err = time.ParseDuration(overrides.MaxTimeRange)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time range limit of the tenant %q: %w", tenant, err)
+			}
+		}
+		tenants[tenant] = limits
+	}
+	return tenants, nil
+}
+
+// traceQuerySubQueries estimates the storage queries of a trace search, see QueryCost.
+func traceQuerySubQueries(query *spanstore.TraceQueryParameters) int {
+	indexSearches := len(query.Tags) + len(query.LogFields) + len(query.TagFilters)
+	if query.OperationName != "" {
+		indexSearches++
+	}
+	if query.DurationMin != 0 || query.DurationMax != 0 {
+		indexSearches++
+	}
+	return max(indexSearches, 1) + query.NumTraces
+}
+
+func countSpans(traces []*model.Trace) int {
+	var spans int
+	for _, trace := range traces {
+		spans += len(trace.Spans)
+	}
+	return spans
+}
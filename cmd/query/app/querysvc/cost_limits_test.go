@@ -0,0 +1,217 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var testCostLimiter = NewCostLimiter(
+	CostLimits{MaxSpans: 3, MaxSubQueries: 10, MaxTimeRange: time.Hour},
+	map[string]CostLimits{
+		"acme":    {MaxSpans: 100, MaxTimeRange: 24 * time.Hour},
+		"initech": {MaxSubQueries: -1},
+	},
+)
+
+func TestCostLimiterLimits(t *testing.T) {
+	assert.Equal(t, CostLimits{MaxSpans: 3, MaxSubQueries: 10, MaxTimeRange: time.Hour}, testCostLimiter.Limits(""))
+	assert.Equal(t, CostLimits{MaxSpans: 100, MaxSubQueries: 10, MaxTimeRange: 24 * time.Hour}, testCostLimiter.Limits("acme"))
+	assert.Equal(t, CostLimits{MaxSpans: 3, MaxSubQueries: -1, MaxTimeRange: time.Hour}, testCostLimiter.Limits("initech"))
+}
+
+func TestCostLimiterCheck(t *testing.T) {
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	initech := tenancy.WithTenant(context.Background(), "initech")
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		cost  QueryCost
+		limit string
+		err   string
+	}{
+		{name: "within the limits", ctx: context.Background(), cost: QueryCost{Spans: 3, SubQueries: 10, TimeRange: time.Hour}},
+		{
+			name:  "spans",
+			ctx:   context.Background(),
+			cost:  QueryCost{Spans: 4},
+			limit: "maxSpans",
+			err:   "query cost limit exceeded: the query returns 4 spans, above the limit of 3",
+		},
+		{
+			name:  "sub-queries",
+			ctx:   context.Background(),
+			cost:  QueryCost{SubQueries: 11},
+			limit: "maxSubQueries",
+			err:   "query cost limit exceeded: the query needs 11 storage queries, above the limit of 10",
+		},
+		{
+			name:  "time range",
+			ctx:   context.Background(),
+			cost:  QueryCost{TimeRange: 2 * time.Hour},
+			limit: "maxTimeRange",
+			err:   "query cost limit exceeded: the query searches a time range of 2h0m0s, above the limit of 1h0m0s",
+		},
+		{name: "tenant overrides", ctx: acme, cost: QueryCost{Spans: 100, TimeRange: 2 * time.Hour}},
+		{
+			name:  "tenant limit",
+			ctx:   acme,
+			cost:  QueryCost{TimeRange: 48 * time.Hour},
+			limit: "maxTimeRange",
+			err:   "query cost limit exceeded: the query searches a time range of 48h0m0s, above the limit of 24h0m0s",
+		},
+		{name: "tenant unbounded", ctx: initech, cost: QueryCost{SubQueries: 1000}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := testCostLimiter.Check(test.ctx, test.cost)
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, test.err)
+			require.ErrorIs(t, err, ErrQueryCostExceeded)
+			var costErr *CostLimitError
+			require.ErrorAs(t, err, &costErr)
+			assert.Equal(t, test.limit, costErr.Limit)
+			assert.Equal(t, tenancy.GetTenant(test.ctx), costErr.Tenant)
+		})
+	}
+}
+
+func TestCostLimitErrorJSON(t *testing.T) {
+	err := testCostLimiter.Check(tenancy.WithTenant(context.Background(), "acme"), QueryCost{Spans: 150})
+	var costErr *CostLimitError
+	require.ErrorAs(t, err, &costErr)
+	data, err := json.Marshal(costErr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tenant": "acme", "limit": "maxSpans", "max": "100", "value": "150"}`, string(data))
+}
+
+func TestLoadTenantCostLimits(t *testing.T) {
+	filename := writeRulesFile(t, `{"tenants": {
+		"acme": {"maxSpans": 100, "maxTimeRange": "24h"},
+		"initech": {"maxSubQueries": -1}
+	}}`)
+	tenants, err := LoadTenantCostLimits(filename)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]CostLimits{
+		"acme":    {MaxSpans: 100, MaxTimeRange: 24 * time.Hour},
+		"initech": {MaxSubQueries: -1},
+	}, tenants)
+
+	_, err = LoadTenantCostLimits(writeRulesFile(t, `{`))
+	require.ErrorContains(t, err, "failed to parse the tenant cost limits")
+	_, err = LoadTenantCostLimits(writeRulesFile(t, `{"tenants": {"acme": {"maxTimeRange": "1 day"}}}`))
+	require.ErrorContains(t, err, `invalid time range limit of the tenant "acme"`)
+	_, err = LoadTenantCostLimits(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "failed to read the tenant cost limits")
+}
+
+func TestTraceQuerySubQueries(t *testing.T) {
+	assert.Equal(t, 21, traceQuerySubQueries(&spanstore.TraceQueryParameters{ServiceName: "service", NumTraces: 20}))
+	assert.Equal(t, 25, traceQuerySubQueries(&spanstore.TraceQueryParameters{
+		ServiceName:   "service",
+		OperationName: "operation",
+		Tags:          map[string]string{"http.status_code": "500"},
+		LogFields:     map[string]string{"event": "error"},
+		TagFilters:    []spanstore.TagFilter{{Key: "error", Operator: spanstore.TagFilterExists}},
+		DurationMin:   time.Second,
+		NumTraces:     20,
+	}))
+}
+
+func TestFindTracesCostLimits(t *testing.T) {
+	now := time.Now()
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraceIDs", mock.Anything, mock.Anything).Return([]model.TraceID{mockTraceID}, nil)
+	reader.On("GetTrace", mock.Anything, mock.Anything).Return(mockTrace, nil)
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{CostLimiter: testCostLimiter})
+
+	query := &spanstore.TraceQueryParameters{ServiceName: "service", StartTimeMin: now.Add(-time.Hour), StartTimeMax: now, NumTraces: 5}
+	traces, err := qs.FindTraces(context.Background(), query)
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+
+	// the storage is not queried if the query is known to exceed the limits
+	query.StartTimeMin = now.Add(-2 * time.Hour)
+	_, err = qs.FindTraces(context.Background(), query)
+	require.ErrorIs(t, err, ErrQueryCostExceeded)
+	query.StartTimeMin, query.NumTraces = now.Add(-time.Hour), 10
+	_, err = qs.FindTraces(context.Background(), query)
+	require.ErrorIs(t, err, ErrQueryCostExceeded)
+	reader.AssertNumberOfCalls(t, "FindTraceIDs", 1)
+
+	// the traces are no longer read once their spans exceed the limit
+	reader.ExpectedCalls = nil
+	reader.Calls = nil
+	reader.On("FindTraceIDs", mock.Anything, mock.Anything).Return([]model.TraceID{mockTraceID, mockTraceID, mockTraceID}, nil)
+	reader.On("GetTrace", mock.Anything, mock.Anything).Return(mockTrace, nil)
+	query.NumTraces = 5
+	_, err = qs.FindTraces(context.Background(), query)
+	require.EqualError(t, err, "query cost limit exceeded: the query returns 4 spans, above the limit of 3")
+	reader.AssertNumberOfCalls(t, "GetTrace", 2)
+	traces, err = qs.FindTraces(tenancy.WithTenant(context.Background(), "acme"), query)
+	require.NoError(t, err)
+	assert.Len(t, traces, 3)
+	reader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+
+	// the traces are found at once if the spans are not limited
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{mockTrace, mockTrace}, nil)
+	qs = NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{
+		CostLimiter: NewCostLimiter(CostLimits{MaxTimeRange: time.Hour}, nil),
+	})
+	traces, err = qs.FindTraces(context.Background(), query)
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+}
+
+func TestFindTracesByIDPrefixCostLimits(t *testing.T) {
+	now := time.Now()
+	qs := NewQueryService(&prefixSpanReader{}, &depsmocks.Reader{}, QueryServiceOptions{CostLimiter: testCostLimiter})
+	_, err := qs.FindTracesByIDPrefix(context.Background(), &spanstore.TraceIDPrefixQueryParameters{
+		Prefix:       "abcd",
+		StartTimeMin: now.Add(-time.Hour),
+		StartTimeMax: now,
+		NumTraces:    10,
+	})
+	require.EqualError(t, err, "query cost limit exceeded: the query needs 11 storage queries, above the limit of 10")
+}
+
+// prefixSpanReader is a span reader looking up the trace IDs by prefix.
+type prefixSpanReader struct {
+	spanstoremocks.Reader
+}
+
+func (*prefixSpanReader) FindTraceIDsByPrefix(context.Context, *spanstore.TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	return nil, nil
+}
+
+func TestGetDependenciesCostLimits(t *testing.T) {
+	reader := &fakeLatencyReader{}
+	qs := NewQueryService(&spanstoremocks.Reader{}, reader, QueryServiceOptions{CostLimiter: testCostLimiter})
+	_, err := qs.GetDependencies(context.Background(), time.Now(), 2*time.Hour)
+	require.ErrorIs(t, err, ErrQueryCostExceeded)
+	_, err = qs.GetDependencyLatencies(context.Background(), time.Now(), 2*time.Hour)
+	require.ErrorIs(t, err, ErrQueryCostExceeded)
+	reader.AssertNotCalled(t, "GetDependencies", mock.Anything, mock.Anything, mock.Anything)
+
+	reader.On("GetDependencies", mock.Anything, mock.Anything, time.Hour).Return([]model.DependencyLink{}, nil)
+	_, err = qs.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+}
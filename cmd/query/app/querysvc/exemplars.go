@@ -0,0 +1,119 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// maxExemplarTraces is the number of traces of the time window searched for the candidates of an exemplar.
+const maxExemplarTraces = 100
+
+// ExemplarQuery describes an exemplar of a metric, e.g. of a Prometheus latency histogram,
+// whose traces were not recorded with it.
+type ExemplarQuery struct {
+	ServiceName   string
+	OperationName string
+	// Timestamp is the time at which the exemplar was observed.
+	Timestamp time.Time
+	// Latency is the observed value of the exemplar, the candidates are only ranked
+	// by their time offset if zero.
+	Latency time.Duration
+	// Window is the maximum time offset of the candidates from the timestamp.
+	Window time.Duration
+	// Limit is the maximum number of candidates returned, all of them are returned if zero.
+	Limit int
+}
+
+// ExemplarCandidate is a trace that may have produced an exemplar, with the span matching its service and operation.
+type ExemplarCandidate struct {
+	Trace *model.Trace
+	Span  *model.Span
+	// TimeOffset is the distance between the timestamp of the exemplar and the span,
+	// zero if the exemplar was observed while the span was running.
+	TimeOffset time.Duration
+	// LatencyDelta is the absolute difference between the duration of the span and the latency of the exemplar.
+	LatencyDelta time.Duration
+}
+
+// FindExemplarTraces returns the traces that may have produced an exemplar, ranked by the closeness
+// of the duration of their span of the service/operation to the latency of the exemplar,
+// then by the closeness of the span to the timestamp of the exemplar.
+func (qs QueryService) FindExemplarTraces(ctx context.Context, query *ExemplarQuery) ([]ExemplarCandidate, error) {
+	traces, err := qs.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:   query.ServiceName,
+		OperationName: query.OperationName,
+		// the exemplar is usually observed at the end of the span
+		StartTimeMin: query.Timestamp.Add(-query.Window - query.Latency),
+		StartTimeMax: query.Timestamp.Add(query.Window),
+		NumTraces:    maxExemplarTraces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []ExemplarCandidate
+	for _, trace := range traces {
+		var best *ExemplarCandidate
+		for _, span := range trace.Spans {
+			if span.Process.GetServiceName() != query.ServiceName ||
+				(query.OperationName != "" && span.OperationName != query.OperationName) {
+				continue
+			}
+			candidate := ExemplarCandidate{
+				Trace:      trace,
+				Span:       span,
+				TimeOffset: timeOffset(span, query.Timestamp),
+			}
+			if candidate.TimeOffset > query.Window {
+				continue
+			}
+			if query.Latency > 0 {
+				candidate.LatencyDelta = absDuration(span.Duration - query.Latency)
+			}
+			if best == nil || candidate.closerThan(best) {
+				best = &candidate
+			}
+		}
+		if best != nil {
+			candidates = append(candidates, *best)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].closerThan(&candidates[j])
+	})
+	if query.Limit > 0 && len(candidates) > query.Limit {
+		candidates = candidates[:query.Limit]
+	}
+	return candidates, nil
+}
+
+func (c *ExemplarCandidate) closerThan(other *ExemplarCandidate) bool {
+	if c.LatencyDelta != other.LatencyDelta {
+		return c.LatencyDelta < other.LatencyDelta
+	}
+	return c.TimeOffset < other.TimeOffset
+}
+
+// timeOffset returns the distance between the timestamp and the time range of the span.
+func timeOffset(span *model.Span, timestamp time.Time) time.Duration {
+	if timestamp.Before(span.StartTime) {
+		return span.StartTime.Sub(timestamp)
+	}
+	if end := span.StartTime.Add(span.Duration); timestamp.After(end) {
+		return timestamp.Sub(end)
+	}
+	return 0
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func TestFindExemplarTraces(t *testing.T) {
+	store := memory.NewStore()
+	timestamp := time.Now()
+	writeSpan := func(traceID uint64, operation string, start, duration time.Duration) {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID:       model.NewTraceID(0, traceID),
+			SpanID:        model.NewSpanID(traceID),
+			OperationName: operation,
+			StartTime:     timestamp.Add(start),
+			Duration:      duration,
+			Process:       &model.Process{ServiceName: "frontend"},
+		}))
+	}
+	// ended at the timestamp, with a duration 20ms off the latency
+	writeSpan(1, "GET", -120*time.Millisecond, 120*time.Millisecond)
+	// ended 1s before the timestamp, with the exact latency
+	writeSpan(2, "GET", -1100*time.Millisecond, 100*time.Millisecond)
+	// ended 500ms before the timestamp, with the exact latency
+	writeSpan(3, "GET", -600*time.Millisecond, 100*time.Millisecond)
+	// out of the window
+	writeSpan(4, "GET", -time.Hour, 100*time.Millisecond)
+	// another operation
+	writeSpan(5, "POST", -100*time.Millisecond, 100*time.Millisecond)
+
+	qs := NewQueryService(store, &depsmocks.Reader{}, QueryServiceOptions{})
+	query := &ExemplarQuery{
+		ServiceName:   "frontend",
+		OperationName: "GET",
+		Timestamp:     timestamp,
+		Latency:       100 * time.Millisecond,
+		Window:        10 * time.Second,
+	}
+	candidates, err := qs.FindExemplarTraces(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, candidates, 3)
+	assert.Equal(t, model.NewSpanID(3), candidates[0].Span.SpanID)
+	assert.Equal(t, 500*time.Millisecond, candidates[0].TimeOffset)
+	assert.Equal(t, time.Duration(0), candidates[0].LatencyDelta)
+	assert.Equal(t, model.NewSpanID(2), candidates[1].Span.SpanID)
+	assert.Equal(t, model.NewSpanID(1), candidates[2].Span.SpanID)
+	assert.Equal(t, time.Duration(0), candidates[2].TimeOffset)
+	assert.Equal(t, 20*time.Millisecond, candidates[2].LatencyDelta)
+
+	// without a latency, the candidates are ranked by their time offset
+	query.Latency = 0
+	query.Limit = 2
+	candidates, err = qs.FindExemplarTraces(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, model.NewSpanID(1), candidates[0].Span.SpanID)
+	assert.Equal(t, model.NewSpanID(3), candidates[1].Span.SpanID)
+}
+
+func TestFindExemplarTracesError(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error")).Once()
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{})
+	_, err := qs.FindExemplarTraces(context.Background(), &ExemplarQuery{ServiceName: "frontend"})
+	require.EqualError(t, err, "storage error")
+
+	qs = NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{Authorizer: NewServiceAuthorizer(testAuthorizationRules)})
+	_, err = qs.FindExemplarTraces(context.Background(), &ExemplarQuery{ServiceName: "payment-api"})
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+}
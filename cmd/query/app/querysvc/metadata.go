@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+)
+
+// ErrMetadataStorageNotConfigured is returned when the service metadata is accessed but not stored.
+var ErrMetadataStorageNotConfigured = errors.New("service metadata storage was not configured")
+
+// InitMetadataStorage tries to initialize the service metadata storage if the storage factory supports it.
+func (opts *QueryServiceOptions) InitMetadataStorage(storageFactory storage.Factory, logger *zap.Logger) bool {
+	metadataFactory, ok := storageFactory.(storage.MetadataStoreFactory)
+	if !ok {
+		logger.Info("Service metadata storage not supported by the factory")
+		return false
+	}
+	store, err := metadataFactory.CreateMetadataStore()
+	if errors.Is(err, storage.ErrMetadataStorageNotSupported) {
+		logger.Info("Service metadata storage not created", zap.String("reason", err.Error()))
+		return false
+	}
+	if err != nil {
+		logger.Error("Cannot init service metadata storage", zap.Error(err))
+		return false
+	}
+	opts.MetadataStore = store
+	return true
+}
+
+// GetServiceMetadata returns the metadata of a service.
+func (qs QueryService) GetServiceMetadata(ctx context.Context, service string) (*metadatastore.ServiceMetadata, error) {
+	if qs.options.MetadataStore == nil {
+		return nil, ErrMetadataStorageNotConfigured
+	}
+	if err := qs.authorizeService(ctx, service); err != nil {
+		return nil, err
+	}
+	return qs.options.MetadataStore.GetServiceMetadata(ctx, service)
+}
+
+// FindServiceMetadata returns the metadata of the services the caller is allowed to query.
+func (qs QueryService) FindServiceMetadata(ctx context.Context) ([]*metadatastore.ServiceMetadata, error) {
+	if qs.options.MetadataStore == nil {
+		return nil, ErrMetadataStorageNotConfigured
+	}
+	found, err := qs.options.MetadataStore.FindServiceMetadata(ctx)
+	if err != nil || qs.options.Authorizer == nil {
+		return found, err
+	}
+	allowed := make([]*metadatastore.ServiceMetadata, 0, len(found))
+	for _, metadata := range found {
+		if qs.IsServiceAllowed(ctx, metadata.ServiceName) {
+			allowed = append(allowed, metadata)
+		}
+	}
+	return allowed, nil
+}
+
+// WriteServiceMetadata creates or replaces the metadata of a service.
+func (qs QueryService) WriteServiceMetadata(ctx context.Context, metadata *metadatastore.ServiceMetadata) error {
+	if qs.options.MetadataStore == nil {
+		return ErrMetadataStorageNotConfigured
+	}
+	if err := qs.authorizeService(ctx, metadata.ServiceName); err != nil {
+		return err
+	}
+	return qs.options.MetadataStore.WriteServiceMetadata(ctx, metadata)
+}
+
+// DeleteServiceMetadata deletes the metadata of a service.
+func (qs QueryService) DeleteServiceMetadata(ctx context.Context, service string) error {
+	if qs.options.MetadataStore == nil {
+		return ErrMetadataStorageNotConfigured
+	}
+	if err := qs.authorizeService(ctx, service); err != nil {
+		return err
+	}
+	return qs.options.MetadataStore.DeleteServiceMetadata(ctx, service)
+}
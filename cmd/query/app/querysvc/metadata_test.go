@@ -0,0 +1,116 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+)
+
+type fakeMetadataStorageFactory struct {
+	fakeStorageFactory1
+	store metadatastore.Store
+	err   error
+}
+
+func (f *fakeMetadataStorageFactory) CreateMetadataStore() (metadatastore.Store, error) {
+	return f.store, f.err
+}
+
+var _ storage.MetadataStoreFactory = new(fakeMetadataStorageFactory)
+
+func withMetadataStore() testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MetadataStore = memory.NewMetadataStore()
+	}
+}
+
+func TestInitMetadataStorage(t *testing.T) {
+	store := memory.NewMetadataStore()
+	tests := []struct {
+		name    string
+		factory storage.Factory
+		ok      bool
+	}{
+		{name: "not a metadata factory", factory: new(fakeStorageFactory1)},
+		{name: "not supported", factory: &fakeMetadataStorageFactory{err: storage.ErrMetadataStorageNotSupported}},
+		{name: "error", factory: &fakeMetadataStorageFactory{err: errors.New("storage error")}},
+		{name: "success", factory: &fakeMetadataStorageFactory{store: store}, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := &QueryServiceOptions{}
+			assert.Equal(t, test.ok, opts.InitMetadataStorage(test.factory, zap.NewNop()))
+			if test.ok {
+				assert.Same(t, store, opts.MetadataStore)
+			} else {
+				assert.Nil(t, opts.MetadataStore)
+			}
+		})
+	}
+}
+
+func TestServiceMetadata(t *testing.T) {
+	tqs := initializeTestService(withMetadataStore())
+	ctx := context.Background()
+	assert.True(t, tqs.queryService.GetCapabilities().ServiceMetadata)
+
+	frontend := &metadatastore.ServiceMetadata{ServiceName: "frontend", Team: "web"}
+	require.NoError(t, tqs.queryService.WriteServiceMetadata(ctx, frontend))
+	metadata, err := tqs.queryService.GetServiceMetadata(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, frontend, metadata)
+
+	found, err := tqs.queryService.FindServiceMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*metadatastore.ServiceMetadata{frontend}, found)
+
+	require.NoError(t, tqs.queryService.DeleteServiceMetadata(ctx, "frontend"))
+	_, err = tqs.queryService.GetServiceMetadata(ctx, "frontend")
+	require.ErrorIs(t, err, metadatastore.ErrServiceMetadataNotFound)
+}
+
+func TestServiceMetadataAuthorized(t *testing.T) {
+	tqs := initializeTestService(withMetadataStore(), withAuthorizer())
+	for _, service := range []string{"frontend", "payment-api"} {
+		require.NoError(t, tqs.queryService.WriteServiceMetadata(paymentsCaller(), &metadatastore.ServiceMetadata{ServiceName: service}))
+	}
+	ctx := context.Background()
+
+	found, err := tqs.queryService.FindServiceMetadata(ctx)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "frontend", found[0].ServiceName)
+	found, err = tqs.queryService.FindServiceMetadata(paymentsCaller())
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	_, err = tqs.queryService.GetServiceMetadata(ctx, "payment-api")
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+	err = tqs.queryService.WriteServiceMetadata(ctx, &metadatastore.ServiceMetadata{ServiceName: "payment-api"})
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+	require.ErrorIs(t, tqs.queryService.DeleteServiceMetadata(ctx, "payment-api"), ErrServiceNotAllowed)
+}
+
+func TestServiceMetadataNotConfigured(t *testing.T) {
+	tqs := initializeTestService()
+	ctx := context.Background()
+	assert.False(t, tqs.queryService.GetCapabilities().ServiceMetadata)
+	_, err := tqs.queryService.GetServiceMetadata(ctx, "frontend")
+	require.ErrorIs(t, err, ErrMetadataStorageNotConfigured)
+	_, err = tqs.queryService.FindServiceMetadata(ctx)
+	require.ErrorIs(t, err, ErrMetadataStorageNotConfigured)
+	err = tqs.queryService.WriteServiceMetadata(ctx, &metadatastore.ServiceMetadata{ServiceName: "frontend"})
+	require.ErrorIs(t, err, ErrMetadataStorageNotConfigured)
+	require.ErrorIs(t, tqs.queryService.DeleteServiceMetadata(ctx, "frontend"), ErrMetadataStorageNotConfigured)
+}
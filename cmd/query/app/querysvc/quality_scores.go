@@ -0,0 +1,203 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// maxQualityScoreTraces is the number of traces of the time range a service is scored from.
+const maxQualityScoreTraces = 200
+
+// QualityCheck is a check of the instrumentation of the spans of a service.
+type QualityCheck string
+
+const (
+	// QualityCheckSpanKind checks that the spans have a known span.kind tag.
+	QualityCheckSpanKind QualityCheck = "span-kind"
+	// QualityCheckHTTPAttributes checks that the HTTP spans, those with http.* tags, have the
+	// method and the response status code, of the semantic conventions before or after v1.20.
+	QualityCheckHTTPAttributes QualityCheck = "http-attributes"
+	// QualityCheckErrorRecording checks that the spans whose HTTP or gRPC status code signals an
+	// error record it with the error tag or otel.status_code.
+	QualityCheckErrorRecording QualityCheck = "error-recording"
+	// QualityCheckClockSkew checks that the spans are not adjusted for the clock skew of their host.
+	QualityCheckClockSkew QualityCheck = "clock-skew"
+)
+
+// QualityChecks lists the checks of the quality scores, in the order of QualityScore.Checks.
+var QualityChecks = []QualityCheck{
+	QualityCheckSpanKind,
+	QualityCheckHTTPAttributes,
+	QualityCheckErrorRecording,
+	QualityCheckClockSkew,
+}
+
+var (
+	httpMethodTags     = []string{"http.method", "http.request.method"}
+	httpStatusCodeTags = []string{"http.status_code", "http.response.status_code"}
+)
+
+// QualityScoreQuery selects the spans of the time range scored, of a service or of all of them.
+type QualityScoreQuery struct {
+	ServiceName  string
+	StartTimeMin time.Time
+	StartTimeMax time.Time
+}
+
+// QualityCheckResult is the outcome of a check over the spans of a service.
+type QualityCheckResult struct {
+	Check QualityCheck
+	// Checked is the number of spans the check applies to.
+	Checked int64
+	// Failed is the number of checked spans failing the check.
+	Failed int64
+}
+
+// Score returns the ratio of the checked spans passing the check, 1 if none is checked.
+func (r QualityCheckResult) Score() float64 {
+	if r.Checked == 0 {
+		return 1
+	}
+	return 1 - float64(r.Failed)/float64(r.Checked)
+}
+
+// QualityScore is the instrumentation quality of a service over a time range.
+type QualityScore struct {
+	ServiceName string
+	// SpanCount and TraceCount are the numbers of spans of the service scored and of their traces.
+	SpanCount  int64
+	TraceCount int64
+	Checks     []QualityCheckResult
+}
+
+// Score returns the mean score of the checks applying to spans of the service, between 0 and 1.
+func (s QualityScore) Score() float64 {
+	var sum float64
+	var count int
+	for _, check := range s.Checks {
+		if check.Checked > 0 {
+			sum += check.Score()
+			count++
+		}
+	}
+	if count == 0 {
+		return 1
+	}
+	return sum / float64(count)
+}
+
+// FindQualityScores scores the instrumentation of the service of the query, or of all the services
+// the caller may query, from the spans of up to maxQualityScoreTraces traces of the time range.
+// The services without spans in the time range are left out.
+func (qs QueryService) FindQualityScores(ctx context.Context, query *QualityScoreQuery) ([]QualityScore, error) {
+	services := []string{query.ServiceName}
+	if query.ServiceName == "" {
+		var err error
+		if services, err = qs.GetServices(ctx); err != nil {
+			return nil, err
+		}
+	}
+	scores := make([]QualityScore, 0, len(services))
+	for _, service := range services {
+		score, err := qs.findQualityScore(ctx, service, query)
+		if err != nil {
+			return nil, err
+		}
+		if score.SpanCount > 0 {
+			scores = append(scores, score)
+		}
+	}
+	return scores, nil
+}
+
+func (qs QueryService) findQualityScore(ctx context.Context, service string, query *QualityScoreQuery) (QualityScore, error) {
+	traces, err := qs.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:  service,
+		StartTimeMin: query.StartTimeMin,
+		StartTimeMax: query.StartTimeMax,
+		NumTraces:    maxQualityScoreTraces,
+	})
+	if err != nil {
+		return QualityScore{}, err
+	}
+	score := QualityScore{ServiceName: service, Checks: make([]QualityCheckResult, len(QualityChecks))}
+	for i, check := range QualityChecks {
+		score.Checks[i].Check = check
+	}
+	record := func(check QualityCheck, failed bool) {
+		for i := range score.Checks {
+			if score.Checks[i].Check == check {
+				score.Checks[i].Checked++
+				if failed {
+					score.Checks[i].Failed++
+				}
+			}
+		}
+	}
+	for _, trace := range traces {
+		var spans []*model.Span
+		for _, span := range trace.Spans {
+			if span.Process.GetServiceName() == service {
+				spans = append(spans, span)
+			}
+		}
+		if len(spans) == 0 {
+			continue
+		}
+		score.TraceCount++
+		score.SpanCount += int64(len(spans))
+		// the error signaling is checked before the adjusters, which may normalize it
+		for _, span := range spans {
+			_, hasKind := span.GetSpanKind()
+			record(QualityCheckSpanKind, !hasKind)
+			if isHTTPSpan(span) {
+				record(QualityCheckHTTPAttributes, !hasAnyTag(span, httpMethodTags) || !hasAnyTag(span, httpStatusCodeTags))
+			}
+			if adjuster.HasErrorStatusCode(span) {
+				record(QualityCheckErrorRecording, adjuster.UnrecordedError(span))
+			}
+		}
+		// the warnings of the failing adjusters are recorded as well
+		_, _ = qs.Adjust(trace)
+		qs.RecordWarnings(ctx, trace)
+		for _, span := range spans {
+			record(QualityCheckClockSkew, hasWarningType(span, adjuster.WarningTypeClockSkew))
+		}
+	}
+	return score, nil
+}
+
+func isHTTPSpan(span *model.Span) bool {
+	for _, tag := range span.Tags {
+		if strings.HasPrefix(tag.Key, "http.") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(span *model.Span, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := model.KeyValues(span.Tags).FindByKey(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasWarningType(span *model.Span, warningType string) bool {
+	for _, warning := range span.Warnings {
+		if adjuster.WarningType(warning) == warningType {
+			return true
+		}
+	}
+	return false
+}
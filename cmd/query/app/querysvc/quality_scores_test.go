@@ -0,0 +1,106 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func TestFindQualityScores(t *testing.T) {
+	store := memory.NewStore()
+	start := time.Now().Add(-time.Minute)
+	newSpan := func(traceID, spanID, parentID uint64, service string, offset time.Duration, tags ...model.KeyValue) *model.Span {
+		span := newCallPathSpan(traceID, spanID, parentID, service, "op", 10*time.Millisecond, start.Add(offset))
+		span.Tags = tags
+		return span
+	}
+	spans := []*model.Span{
+		newSpan(1, 1, 0, "frontend", 0,
+			model.String("span.kind", "server"),
+			model.String("http.method", "GET"),
+			model.Int64("http.status_code", 500),
+			model.Bool("error", true)),
+		// the HTTP client span misses the status code and does not record its gRPC error
+		newSpan(1, 2, 1, "frontend", 0,
+			model.String("span.kind", "client"),
+			model.String("http.method", "GET"),
+			model.Int64("rpc.grpc.status_code", 14)),
+		// the span of the other service starts before its parent, and misses the span kind
+		newSpan(1, 3, 2, "checkout", -time.Second),
+		newSpan(2, 1, 0, "frontend", 0, model.String("span.kind", "unknown")),
+	}
+	for _, span := range spans {
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	qs := NewQueryService(store, &depsmocks.Reader{}, QueryServiceOptions{})
+	query := &QualityScoreQuery{
+		ServiceName:  "frontend",
+		StartTimeMin: start.Add(-time.Minute),
+		StartTimeMax: start.Add(time.Minute),
+	}
+	scores, err := qs.FindQualityScores(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, QualityScore{
+		ServiceName: "frontend",
+		SpanCount:   3,
+		TraceCount:  2,
+		Checks: []QualityCheckResult{
+			{Check: QualityCheckSpanKind, Checked: 3, Failed: 1},
+			{Check: QualityCheckHTTPAttributes, Checked: 2, Failed: 1},
+			{Check: QualityCheckErrorRecording, Checked: 2, Failed: 1},
+			{Check: QualityCheckClockSkew, Checked: 3, Failed: 0},
+		},
+	}, scores[0])
+	assert.InDelta(t, (2.0/3+0.5+0.5+1)/4, scores[0].Score(), 1e-9)
+
+	// all the services
+	query.ServiceName = ""
+	scores, err = qs.FindQualityScores(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	var checkout QualityScore
+	for _, score := range scores {
+		if score.ServiceName == "checkout" {
+			checkout = score
+		}
+	}
+	assert.Equal(t, int64(1), checkout.SpanCount)
+	assert.Equal(t, []QualityCheckResult{
+		{Check: QualityCheckSpanKind, Checked: 1, Failed: 1},
+		{Check: QualityCheckHTTPAttributes},
+		{Check: QualityCheckErrorRecording},
+		{Check: QualityCheckClockSkew, Checked: 1, Failed: 1},
+	}, checkout.Checks)
+	assert.InDelta(t, 0.0, checkout.Score(), 1e-9, "the checks without checked spans are left out")
+}
+
+func TestQualityScoreWithoutChecks(t *testing.T) {
+	score := QualityScore{Checks: []QualityCheckResult{{Check: QualityCheckSpanKind}}}
+	assert.InDelta(t, 1.0, score.Score(), 1e-9)
+	assert.InDelta(t, 1.0, score.Checks[0].Score(), 1e-9)
+}
+
+func TestFindQualityScoresErrors(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("GetServices", mock.Anything).Return(nil, errors.New("services error")).Once()
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error")).Once()
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{})
+	_, err := qs.FindQualityScores(context.Background(), &QualityScoreQuery{})
+	require.EqualError(t, err, "services error")
+	_, err = qs.FindQualityScores(context.Background(), &QualityScoreQuery{ServiceName: "frontend"})
+	require.EqualError(t, err, "storage error")
+}
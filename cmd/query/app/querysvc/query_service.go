@@ -17,21 +17,32 @@ package querysvc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
 )
 
 var errNoArchiveSpanStorage = errors.New("archive span storage was not configured")
 
 const (
 	defaultMaxClockSkewAdjust = time.Second
+
+	// maxLatencyTraces is the number of traces sampled to compute latency distributions
+	// when the span storage cannot aggregate span durations natively.
+	maxLatencyTraces = 1000
 )
 
 // QueryServiceOptions has optional members of QueryService
@@ -39,13 +50,41 @@ type QueryServiceOptions struct {
 	ArchiveSpanReader spanstore.Reader
 	ArchiveSpanWriter spanstore.Writer
 	Adjuster          adjuster.Adjuster
+	// Authorizer restricts the services the callers may query, if not nil.
+	Authorizer *ServiceAuthorizer
+	// WarningStore finds the warnings of the adjusted traces, if not nil.
+	WarningStore warningstore.Store
+	// WarningRecorder records the warnings of the adjusted traces, if not nil.
+	WarningRecorder *WarningRecorder
+	// MetadataStore stores the metadata of the services, if not nil.
+	MetadataStore metadatastore.Store
+	// StrategyStore stores the sampling strategies of the services, if not nil.
+	StrategyStore samplingstore.StrategyStore
+	// SavedSearchStore stores the trace searches saved by the users, if not nil.
+	SavedSearchStore savedsearchstore.Store
+	// StorageCapabilities are the features of the span storage, if known.
+	StorageCapabilities *storage.Capabilities
+	// RecentTraces buffers the most recent traces of the collector of the same process, if not nil.
+	RecentTraces *tracestream.RecentTraces
+	// CostLimiter rejects the queries exceeding the cost limits of their tenant, if not nil.
+	CostLimiter *CostLimiter
 }
 
 // StorageCapabilities is a feature flag for query service
 type StorageCapabilities struct {
-	ArchiveStorage bool `json:"archiveStorage"`
+	ArchiveStorage  bool `json:"archiveStorage"`
+	ServiceMetadata bool `json:"serviceMetadata"`
+	SavedSearches   bool `json:"savedSearches"`
+	// SamplingStrategies reports whether the sampling strategies can be edited with the API.
+	SamplingStrategies bool `json:"samplingStrategies"`
+	TraceSearch        bool `json:"traceSearch"`
+	TagSearch          bool `json:"tagSearch"`
+	TagFilters         bool `json:"tagFilters"`
+	OperationSpanKind  bool `json:"operationSpanKind"`
+	Dependencies       bool `json:"dependencies"`
+	// DependencyLatencies reports whether the dependency storage computes the latencies of the dependency links.
+	DependencyLatencies bool `json:"dependencyLatencies"`
 	// SupportRegex     bool
-	// SupportTagFilter bool
 }
 
 // QueryService contains span utils required by the query-service.
@@ -78,12 +117,26 @@ func (qs QueryService) GetTrace(ctx context.Context, traceID model.TraceID) (*mo
 		}
 		trace, err = qs.options.ArchiveSpanReader.GetTrace(ctx, traceID)
 	}
+	// the trace is reported as not found rather than disclose its services
+	if err == nil && qs.options.Authorizer != nil && !qs.options.Authorizer.isTraceAllowed(ctx, trace) {
+		return nil, spanstore.ErrTraceNotFound
+	}
 	return trace, err
 }
 
 // GetServices is the queryService implementation of spanstore.Reader.GetServices
 func (qs QueryService) GetServices(ctx context.Context) ([]string, error) {
-	return qs.spanReader.GetServices(ctx)
+	services, err := qs.spanReader.GetServices(ctx)
+	if err != nil || qs.options.Authorizer == nil {
+		return services, err
+	}
+	allowed := make([]string, 0, len(services))
+	for _, service := range services {
+		if qs.options.Authorizer.IsAllowed(ctx, service) {
+			allowed = append(allowed, service)
+		}
+	}
+	return allowed, nil
 }
 
 // GetOperations is the queryService implementation of spanstore.Reader.GetOperations
@@ -91,12 +144,221 @@ func (qs QueryService) GetOperations(
 	ctx context.Context,
 	query spanstore.OperationQueryParameters,
 ) ([]spanstore.Operation, error) {
+	if err := qs.authorizeService(ctx, query.ServiceName); err != nil {
+		return nil, err
+	}
 	return qs.spanReader.GetOperations(ctx, query)
 }
 
 // FindTraces is the queryService implementation of spanstore.Reader.FindTraces
+// The traces spanning services the caller is not allowed to query are left out. It returns
+// spanstore.ErrTagFiltersNotSupported if the query has TagFilters the span storage cannot search by,
+// and a CostLimitError if the query exceeds the cost limits of the caller.
 func (qs QueryService) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
-	return qs.spanReader.FindTraces(ctx, query)
+	return qs.findTraces(ctx, query, qs.traceFinder(ctx))
+}
+
+// FindTracesPage is FindTraces returning the token of the next page of the traces, if the span
+// storage supports the pagination, see spanstore.PaginatedReader. Otherwise the page token of
+// the query is ignored and the traces are returned without a next page.
+func (qs QueryService) FindTracesPage(ctx context.Context, query *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	var nextPageToken string
+	traces, err := qs.findTraces(ctx, query, func(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+		paginatedReader, ok := qs.spanReader.(spanstore.PaginatedReader)
+		if !ok {
+			return qs.traceFinder(ctx)(ctx, query)
+		}
+		page, err := paginatedReader.FindTracesPage(ctx, query)
+		if errors.Is(err, spanstore.ErrPaginationNotSupported) {
+			return qs.traceFinder(ctx)(ctx, query)
+		}
+		if err != nil {
+			return nil, err
+		}
+		nextPageToken = page.NextPageToken
+		return page.Traces, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &spanstore.TracesPage{Traces: traces, NextPageToken: nextPageToken}, nil
+}
+
+// traceFinder returns the function finding the traces of a query. If the spans of the searches of
+// the caller are limited, the traces are read one by one after their IDs are found, to stop as
+// soon as their spans exceed the limit rather than load them all.
+func (qs QueryService) traceFinder(ctx context.Context) func(context.Context, *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	if qs.options.CostLimiter == nil || qs.options.CostLimiter.Limits(tenancy.GetTenant(ctx)).MaxSpans <= 0 {
+		return qs.spanReader.FindTraces
+	}
+	return func(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+		traceIDs, err := qs.spanReader.FindTraceIDs(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return qs.readTraces(ctx, traceIDs)
+	}
+}
+
+// readTraces reads the traces by their IDs, leaving out the ones not found or the caller is not
+// allowed to query, and stops with a CostLimitError as soon as their spans exceed the cost limits.
+func (qs QueryService) readTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	traces := make([]*model.Trace, 0, len(traceIDs))
+	var spans int
+	for _, traceID := range traceIDs {
+		trace, err := qs.GetTrace(ctx, traceID)
+		if errors.Is(err, spanstore.ErrTraceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		spans += len(trace.Spans)
+		if err := qs.CheckCost(ctx, QueryCost{Spans: spans}); err != nil {
+			return nil, err
+		}
+		traces = append(traces, trace)
+	}
+	return traces, nil
+}
+
+// findTraces checks the query and the cost of its traces found by find, and leaves out the
+// traces the caller is not allowed to query.
+func (qs QueryService) findTraces(
+	ctx context.Context,
+	query *spanstore.TraceQueryParameters,
+	find func(context.Context, *spanstore.TraceQueryParameters) ([]*model.Trace, error),
+) ([]*model.Trace, error) {
+	if err := qs.authorizeService(ctx, query.ServiceName); err != nil {
+		return nil, err
+	}
+	if len(query.TagFilters) > 0 && !qs.GetCapabilities().TagFilters {
+		return nil, spanstore.ErrTagFiltersNotSupported
+	}
+	if err := qs.CheckCost(ctx, QueryCost{
+		SubQueries: traceQuerySubQueries(query),
+		TimeRange:  query.StartTimeMax.Sub(query.StartTimeMin),
+	}); err != nil {
+		return nil, err
+	}
+	traces, err := find(ctx, query)
+	if err != nil {
+		return traces, err
+	}
+	if qs.options.Authorizer != nil {
+		allowed := make([]*model.Trace, 0, len(traces))
+		for _, trace := range traces {
+			if qs.options.Authorizer.isTraceAllowed(ctx, trace) {
+				allowed = append(allowed, trace)
+			}
+		}
+		traces = allowed
+	}
+	if err := qs.CheckCost(ctx, QueryCost{Spans: countSpans(traces)}); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// CheckCost returns a CostLimitError if the cost of a query exceeds the cost limits of the caller.
+func (qs QueryService) CheckCost(ctx context.Context, cost QueryCost) error {
+	if qs.options.CostLimiter == nil {
+		return nil
+	}
+	return qs.options.CostLimiter.Check(ctx, cost)
+}
+
+// IsServiceAllowed checks that the caller of the context may query the service.
+func (qs QueryService) IsServiceAllowed(ctx context.Context, service string) bool {
+	return qs.options.Authorizer == nil || qs.options.Authorizer.IsAllowed(ctx, service)
+}
+
+// authorizeService checks that the caller may query the service, if given.
+func (qs QueryService) authorizeService(ctx context.Context, service string) error {
+	if service == "" || qs.IsServiceAllowed(ctx, service) {
+		return nil
+	}
+	return fmt.Errorf("%w %q", ErrServiceNotAllowed, service)
+}
+
+// GetLatencyDistribution returns the latency histogram and percentiles of spans of a service/operation.
+// It uses the span storage aggregations if available, otherwise it computes them from a sample of stored traces.
+func (qs QueryService) GetLatencyDistribution(
+	ctx context.Context,
+	query *spanstore.LatencyQueryParameters,
+) (*spanstore.LatencyDistribution, error) {
+	if err := qs.authorizeService(ctx, query.ServiceName); err != nil {
+		return nil, err
+	}
+	if latencyReader, ok := qs.spanReader.(spanstore.LatencyReader); ok {
+		dist, err := latencyReader.GetLatencyDistribution(ctx, query)
+		if !errors.Is(err, spanstore.ErrLatencyDistributionNotSupported) {
+			return dist, err
+		}
+	}
+	traces, err := qs.spanReader.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:   query.ServiceName,
+		OperationName: query.OperationName,
+		Tags:          query.Tags,
+		StartTimeMin:  query.StartTimeMin,
+		StartTimeMax:  query.StartTimeMax,
+		NumTraces:     maxLatencyTraces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var durations []time.Duration
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			if span.Process.GetServiceName() != query.ServiceName ||
+				(query.OperationName != "" && span.OperationName != query.OperationName) ||
+				span.StartTime.Before(query.StartTimeMin) || span.StartTime.After(query.StartTimeMax) ||
+				!hasTags(span, query.Tags) {
+				continue
+			}
+			durations = append(durations, span.Duration)
+		}
+	}
+	return spanstore.ComputeLatencyDistribution(durations, query.GetBucketBounds()), nil
+}
+
+// hasTags checks that the span or its process has all the given tags.
+func hasTags(span *model.Span, tags map[string]string) bool {
+	for k, v := range tags {
+		tag, ok := model.KeyValues(span.Tags).FindByKey(k)
+		if !ok && span.Process != nil {
+			tag, ok = model.KeyValues(span.Process.Tags).FindByKey(k)
+		}
+		if !ok || tag.AsString() != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FindTracesByIDPrefix returns the most recent traces with an ID starting with the prefix of the query,
+// or spanstore.ErrTraceIDPrefixNotSupported if the span storage cannot look them up.
+// The traces the caller is not allowed to query are left out. Its cost is one storage query
+// for the trace IDs and one for each trace read.
+func (qs QueryService) FindTracesByIDPrefix(
+	ctx context.Context,
+	query *spanstore.TraceIDPrefixQueryParameters,
+) ([]*model.Trace, error) {
+	prefixReader, ok := qs.spanReader.(spanstore.TraceIDPrefixReader)
+	if !ok {
+		return nil, spanstore.ErrTraceIDPrefixNotSupported
+	}
+	if err := qs.CheckCost(ctx, QueryCost{
+		SubQueries: 1 + query.NumTraces,
+		TimeRange:  query.StartTimeMax.Sub(query.StartTimeMin),
+	}); err != nil {
+		return nil, err
+	}
+	traceIDs, err := prefixReader.FindTraceIDsByPrefix(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return qs.readTraces(ctx, traceIDs)
 }
 
 // ArchiveTrace is the queryService utility to archive traces.
@@ -125,15 +387,77 @@ func (qs QueryService) Adjust(trace *model.Trace) (*model.Trace, error) {
 }
 
 // GetDependencies implements dependencystore.Reader.GetDependencies
+// The links are limited to the services the caller is allowed to query.
 func (qs QueryService) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
-	return qs.dependencyReader.GetDependencies(ctx, endTs, lookback)
+	if err := qs.CheckCost(ctx, QueryCost{TimeRange: lookback}); err != nil {
+		return nil, err
+	}
+	links, err := qs.dependencyReader.GetDependencies(ctx, endTs, lookback)
+	if err != nil || qs.options.Authorizer == nil {
+		return links, err
+	}
+	allowed := make([]model.DependencyLink, 0, len(links))
+	for _, link := range links {
+		if qs.options.Authorizer.IsAllowed(ctx, link.Parent) && qs.options.Authorizer.IsAllowed(ctx, link.Child) {
+			allowed = append(allowed, link)
+		}
+	}
+	return allowed, nil
+}
+
+// GetDependencyLatencies returns the dependency links with the latencies of their calls, or
+// dependencystore.ErrDependencyLatenciesNotSupported if the dependency storage cannot compute them.
+func (qs QueryService) GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLatency, error) {
+	latencyReader, ok := qs.dependencyReader.(dependencystore.LatencyReader)
+	if !ok {
+		return nil, dependencystore.ErrDependencyLatenciesNotSupported
+	}
+	if err := qs.CheckCost(ctx, QueryCost{TimeRange: lookback}); err != nil {
+		return nil, err
+	}
+	latencies, err := latencyReader.GetDependencyLatencies(ctx, endTs, lookback)
+	if err != nil || qs.options.Authorizer == nil {
+		return latencies, err
+	}
+	allowed := make([]dependencystore.DependencyLatency, 0, len(latencies))
+	for _, latency := range latencies {
+		if qs.options.Authorizer.IsAllowed(ctx, latency.Parent) && qs.options.Authorizer.IsAllowed(ctx, latency.Child) {
+			allowed = append(allowed, latency)
+		}
+	}
+	return allowed, nil
+}
+
+// WithoutAuthorization returns a copy of the query service not restricting the services,
+// for the background jobs which do not query on behalf of a caller.
+func (qs QueryService) WithoutAuthorization() *QueryService {
+	qs.options.Authorizer = nil
+	return &qs
 }
 
 // GetCapabilities returns the features supported by the query service.
+// The features of a storage whose capabilities are unknown are assumed to be supported.
 func (qs QueryService) GetCapabilities() StorageCapabilities {
-	return StorageCapabilities{
-		ArchiveStorage: qs.options.hasArchiveStorage(),
+	capabilities := StorageCapabilities{
+		ArchiveStorage:     qs.options.hasArchiveStorage(),
+		ServiceMetadata:    qs.options.MetadataStore != nil,
+		SavedSearches:      qs.options.SavedSearchStore != nil,
+		SamplingStrategies: qs.options.StrategyStore != nil,
+		TraceSearch:        true,
+		TagSearch:          true,
+		TagFilters:         true,
+		OperationSpanKind:  true,
+		Dependencies:       true,
+	}
+	_, capabilities.DependencyLatencies = qs.dependencyReader.(dependencystore.LatencyReader)
+	if storageCapabilities := qs.options.StorageCapabilities; storageCapabilities != nil {
+		capabilities.TraceSearch = storageCapabilities.TraceSearch
+		capabilities.TagSearch = storageCapabilities.TagSearch
+		capabilities.TagFilters = storageCapabilities.TagFilters
+		capabilities.OperationSpanKind = storageCapabilities.OperationSpanKind
+		capabilities.Dependencies = storageCapabilities.Dependencies
 	}
+	return capabilities
 }
 
 // InitArchiveStorage tries to initialize archive storage reader/writer if storage factory supports them.
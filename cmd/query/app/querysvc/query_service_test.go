@@ -29,6 +29,7 @@ import (
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
@@ -207,6 +208,178 @@ func TestFindTraces(t *testing.T) {
 	assert.Len(t, traces, 1)
 }
 
+func TestFindTracesByTagFilters(t *testing.T) {
+	params := &spanstore.TraceQueryParameters{
+		ServiceName: "service",
+		TagFilters:  []spanstore.TagFilter{{Key: "error", Operator: spanstore.TagFilterExists}},
+	}
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, params).Return([]*model.Trace{mockTrace}, nil).Once()
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{
+		StorageCapabilities: &storage.Capabilities{TagFilters: true},
+	})
+	traces, err := qs.FindTraces(context.Background(), params)
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+
+	qs = NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{
+		StorageCapabilities: &storage.Capabilities{TagSearch: true},
+	})
+	_, err = qs.FindTraces(context.Background(), params)
+	require.ErrorIs(t, err, spanstore.ErrTagFiltersNotSupported)
+}
+
+type paginatedSpanReader struct {
+	*spanstoremocks.Reader
+	*spanstoremocks.PaginatedReader
+}
+
+func TestFindTracesPage(t *testing.T) {
+	params := &spanstore.TraceQueryParameters{ServiceName: "service", PageToken: spanstore.EncodePageToken(2)}
+	nextPageToken := spanstore.EncodePageToken(4)
+	pageReader := &spanstoremocks.PaginatedReader{}
+	pageReader.On("FindTracesPage", mock.Anything, params).
+		Return(&spanstore.TracesPage{Traces: []*model.Trace{mockTrace}, NextPageToken: nextPageToken}, nil).Once()
+	qs := NewQueryService(&paginatedSpanReader{Reader: &spanstoremocks.Reader{}, PaginatedReader: pageReader}, &depsmocks.Reader{}, QueryServiceOptions{})
+	page, err := qs.FindTracesPage(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, &spanstore.TracesPage{Traces: []*model.Trace{mockTrace}, NextPageToken: nextPageToken}, page)
+
+	// the traces of the readers not supporting the pagination have no next page
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, params).Return([]*model.Trace{mockTrace}, nil).Twice()
+	qs = NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{})
+	page, err = qs.FindTracesPage(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, &spanstore.TracesPage{Traces: []*model.Trace{mockTrace}}, page)
+
+	// nor the ones of the decorators of such readers
+	qs = NewQueryService(spanstore.NewTimeoutReader(reader, time.Minute), &depsmocks.Reader{}, QueryServiceOptions{})
+	page, err = qs.FindTracesPage(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, &spanstore.TracesPage{Traces: []*model.Trace{mockTrace}}, page)
+	reader.AssertExpectations(t)
+
+	pageReader.On("FindTracesPage", mock.Anything, params).Return(nil, spanstore.ErrInvalidPageToken).Once()
+	qs = NewQueryService(&paginatedSpanReader{Reader: &spanstoremocks.Reader{}, PaginatedReader: pageReader}, &depsmocks.Reader{}, QueryServiceOptions{})
+	_, err = qs.FindTracesPage(context.Background(), params)
+	require.ErrorIs(t, err, spanstore.ErrInvalidPageToken)
+}
+
+type latencySpanReader struct {
+	spanstoremocks.Reader
+	dist *spanstore.LatencyDistribution
+	err  error
+}
+
+func (r *latencySpanReader) GetLatencyDistribution(context.Context, *spanstore.LatencyQueryParameters) (*spanstore.LatencyDistribution, error) {
+	return r.dist, r.err
+}
+
+// Test QueryService.GetLatencyDistribution() with native storage aggregations.
+func TestGetLatencyDistributionFromStorage(t *testing.T) {
+	dist := &spanstore.LatencyDistribution{Count: 10}
+	qs := NewQueryService(&latencySpanReader{dist: dist}, &depsmocks.Reader{}, QueryServiceOptions{})
+
+	actual, err := qs.GetLatencyDistribution(context.Background(), &spanstore.LatencyQueryParameters{ServiceName: "service"})
+	require.NoError(t, err)
+	assert.Equal(t, dist, actual)
+}
+
+func TestFindTracesByIDPrefix(t *testing.T) {
+	store := memory.NewStore()
+	now := time.Now()
+	for i, service := range []string{"frontend", "payment-api", "frontend"} {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID:   model.NewTraceID(0, uint64(0xabcd0001+i)),
+			SpanID:    model.NewSpanID(1),
+			StartTime: now.Add(time.Duration(i) * time.Millisecond),
+			Process:   &model.Process{ServiceName: service},
+		}))
+	}
+	query := &spanstore.TraceIDPrefixQueryParameters{
+		Prefix:       "00000000abcd",
+		StartTimeMin: now.Add(-time.Minute),
+		StartTimeMax: now.Add(time.Minute),
+	}
+
+	qs := NewQueryService(store, &depsmocks.Reader{}, QueryServiceOptions{})
+	traces, err := qs.FindTracesByIDPrefix(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, traces, 3)
+	assert.Equal(t, model.NewTraceID(0, 0xabcd0003), traces[0].Spans[0].TraceID)
+
+	// the traces of the services the caller is not allowed to query are left out
+	qs = NewQueryService(store, &depsmocks.Reader{}, QueryServiceOptions{Authorizer: NewServiceAuthorizer(testAuthorizationRules)})
+	traces, err = qs.FindTracesByIDPrefix(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+
+	qs = NewQueryService(&spanstoremocks.Reader{}, &depsmocks.Reader{}, QueryServiceOptions{})
+	_, err = qs.FindTracesByIDPrefix(context.Background(), query)
+	require.ErrorIs(t, err, spanstore.ErrTraceIDPrefixNotSupported)
+}
+
+// Test QueryService.GetLatencyDistribution() computed from stored traces.
+func TestGetLatencyDistributionFromTraces(t *testing.T) {
+	startTime := time.Now()
+	span := func(service, operation string, start time.Time, duration time.Duration) *model.Span {
+		return &model.Span{
+			OperationName: operation,
+			Process:       &model.Process{ServiceName: service},
+			StartTime:     start,
+			Duration:      duration,
+		}
+	}
+	reader := &latencySpanReader{err: spanstore.ErrLatencyDistributionNotSupported}
+	reader.On("FindTraces", mock.Anything, &spanstore.TraceQueryParameters{
+		ServiceName:   "service",
+		OperationName: "operation",
+		StartTimeMin:  startTime,
+		StartTimeMax:  startTime.Add(time.Minute),
+		NumTraces:     maxLatencyTraces,
+	}).Return([]*model.Trace{
+		{Spans: []*model.Span{
+			span("service", "operation", startTime, 2*time.Millisecond),
+			span("service", "other-operation", startTime, 3*time.Millisecond),
+			span("other-service", "operation", startTime, 4*time.Millisecond),
+		}},
+		{Spans: []*model.Span{
+			span("service", "operation", startTime.Add(time.Second), 20*time.Millisecond),
+			span("service", "operation", startTime.Add(-time.Second), 30*time.Millisecond),
+		}},
+	}, nil).Once()
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{})
+
+	bounds := []time.Duration{10 * time.Millisecond}
+	dist, err := qs.GetLatencyDistribution(context.Background(), &spanstore.LatencyQueryParameters{
+		ServiceName:   "service",
+		OperationName: "operation",
+		StartTimeMin:  startTime,
+		StartTimeMax:  startTime.Add(time.Minute),
+		BucketBounds:  bounds,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &spanstore.LatencyDistribution{
+		Count:        2,
+		P50:          2 * time.Millisecond,
+		P95:          20 * time.Millisecond,
+		P99:          20 * time.Millisecond,
+		BucketBounds: bounds,
+		BucketCounts: []int64{1, 1},
+	}, dist)
+}
+
+// Test QueryService.GetLatencyDistribution() when stored traces cannot be read.
+func TestGetLatencyDistributionError(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).
+		Return(nil, errors.New("storage error")).Once()
+
+	_, err := tqs.queryService.GetLatencyDistribution(context.Background(), &spanstore.LatencyQueryParameters{ServiceName: "service"})
+	require.EqualError(t, err, "storage error")
+}
+
 // Test QueryService.ArchiveTrace() with no ArchiveSpanWriter.
 func TestArchiveTraceNoOptions(t *testing.T) {
 	tqs := initializeTestService()
@@ -291,11 +464,54 @@ func TestGetDependencies(t *testing.T) {
 	assert.Equal(t, expectedDependencies, actualDependencies)
 }
 
+// fakeLatencyReader is a dependency reader computing the latencies of the dependency links.
+type fakeLatencyReader struct {
+	depsmocks.Reader
+	latencies []dependencystore.DependencyLatency
+	err       error
+}
+
+func (r *fakeLatencyReader) GetDependencyLatencies(context.Context, time.Time, time.Duration) ([]dependencystore.DependencyLatency, error) {
+	return r.latencies, r.err
+}
+
+func TestGetDependencyLatencies(t *testing.T) {
+	allowed := dependencystore.DependencyLatency{Parent: "frontend", Child: "payment-api", CallCount: 1}
+	reader := &fakeLatencyReader{latencies: []dependencystore.DependencyLatency{
+		allowed,
+		{Parent: "frontend", Child: "inventory", CallCount: 2},
+	}}
+	qs := NewQueryService(&spanstoremocks.Reader{}, reader, QueryServiceOptions{})
+	latencies, err := qs.GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, reader.latencies, latencies)
+	assert.True(t, qs.GetCapabilities().DependencyLatencies)
+
+	qs = NewQueryService(&spanstoremocks.Reader{}, reader, QueryServiceOptions{Authorizer: NewServiceAuthorizer(testAuthorizationRules)})
+	latencies, err = qs.GetDependencyLatencies(paymentsCaller(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []dependencystore.DependencyLatency{allowed}, latencies)
+
+	reader.err = errors.New("storage error")
+	_, err = qs.GetDependencyLatencies(paymentsCaller(), time.Now(), time.Hour)
+	require.EqualError(t, err, "storage error")
+
+	tqs := initializeTestService()
+	_, err = tqs.queryService.GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+	require.ErrorIs(t, err, dependencystore.ErrDependencyLatenciesNotSupported)
+	assert.False(t, tqs.queryService.GetCapabilities().DependencyLatencies)
+}
+
 // Test QueryService.GetCapacities()
 func TestGetCapabilities(t *testing.T) {
 	tqs := initializeTestService()
 	expectedStorageCapabilities := StorageCapabilities{
-		ArchiveStorage: false,
+		ArchiveStorage:    false,
+		TraceSearch:       true,
+		TagSearch:         true,
+		TagFilters:        true,
+		OperationSpanKind: true,
+		Dependencies:      true,
 	}
 	assert.Equal(t, expectedStorageCapabilities, tqs.queryService.GetCapabilities())
 }
@@ -304,11 +520,32 @@ func TestGetCapabilitiesWithSupportsArchive(t *testing.T) {
 	tqs := initializeTestService(withArchiveSpanReader(), withArchiveSpanWriter())
 
 	expectedStorageCapabilities := StorageCapabilities{
-		ArchiveStorage: true,
+		ArchiveStorage:    true,
+		TraceSearch:       true,
+		TagSearch:         true,
+		TagFilters:        true,
+		OperationSpanKind: true,
+		Dependencies:      true,
 	}
 	assert.Equal(t, expectedStorageCapabilities, tqs.queryService.GetCapabilities())
 }
 
+func TestGetCapabilitiesWithStorageCapabilities(t *testing.T) {
+	queryService := NewQueryService(&spanstoremocks.Reader{}, &depsmocks.Reader{}, QueryServiceOptions{
+		StorageCapabilities: &storage.Capabilities{
+			// the archive storage is only enabled by the archive span reader and writer
+			ArchiveStorage: true,
+			TraceSearch:    true,
+			Dependencies:   true,
+		},
+	})
+	expectedStorageCapabilities := StorageCapabilities{
+		TraceSearch:  true,
+		Dependencies: true,
+	}
+	assert.Equal(t, expectedStorageCapabilities, queryService.GetCapabilities())
+}
+
 type fakeStorageFactory1 struct{}
 
 type fakeStorageFactory2 struct {
@@ -325,6 +562,7 @@ func (*fakeStorageFactory1) Initialize(metrics.Factory, *zap.Logger) error {
 func (*fakeStorageFactory1) CreateSpanReader() (spanstore.Reader, error)             { return nil, nil }
 func (*fakeStorageFactory1) CreateSpanWriter() (spanstore.Writer, error)             { return nil, nil }
 func (*fakeStorageFactory1) CreateDependencyReader() (dependencystore.Reader, error) { return nil, nil }
+func (*fakeStorageFactory1) Capabilities() storage.Capabilities                      { return storage.Capabilities{} }
 
 func (f *fakeStorageFactory2) CreateArchiveSpanReader() (spanstore.Reader, error) { return f.r, f.rErr }
 func (f *fakeStorageFactory2) CreateArchiveSpanWriter() (spanstore.Writer, error) { return f.w, f.wErr }
@@ -373,3 +611,14 @@ func TestInitArchiveStorage(t *testing.T) {
 func TestMain(m *testing.M) {
 	testutils.VerifyGoLeaks(m)
 }
+
+func TestHasTags(t *testing.T) {
+	span := &model.Span{
+		Tags:    []model.KeyValue{model.Bool("error", true)},
+		Process: &model.Process{Tags: []model.KeyValue{model.String("region", "eu")}},
+	}
+	assert.True(t, hasTags(span, nil))
+	assert.True(t, hasTags(span, map[string]string{"error": "true", "region": "eu"}))
+	assert.False(t, hasTags(span, map[string]string{"error": "false"}))
+	assert.False(t, hasTags(span, map[string]string{"zone": "a"}))
+}
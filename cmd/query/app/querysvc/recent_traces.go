@@ -0,0 +1,43 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// ErrRecentTracesNotConfigured is returned when the recent traces are requested but not buffered.
+var ErrRecentTracesNotConfigured = errors.New("the buffer of the recent traces was not configured")
+
+// GetRecentTraces returns the most recent traces processed by the collector of the same process,
+// without querying the span storage, the most recently updated first. They are restricted to the
+// traces with a span of the service, if not empty, and to the limit, if positive. The traces
+// spanning services the caller is not allowed to query are left out.
+func (qs QueryService) GetRecentTraces(ctx context.Context, service string, limit int) ([]*model.Trace, error) {
+	if qs.options.RecentTraces == nil {
+		return nil, ErrRecentTracesNotConfigured
+	}
+	if err := qs.authorizeService(ctx, service); err != nil {
+		return nil, err
+	}
+	return qs.options.RecentTraces.Get(tenancy.GetTenant(ctx), limit, func(trace *model.Trace) bool {
+		if service != "" && !hasService(trace, service) {
+			return false
+		}
+		return qs.options.Authorizer == nil || qs.options.Authorizer.isTraceAllowed(ctx, trace)
+	})
+}
+
+func hasService(trace *model.Trace, service string) bool {
+	for _, span := range trace.Spans {
+		if span.Process.GetServiceName() == service {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/tracestream"
+)
+
+func withRecentTraces(recentTraces *tracestream.RecentTraces) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.RecentTraces = recentTraces
+	}
+}
+
+func recentSpan(traceID uint64, service string) *model.Span {
+	return &model.Span{
+		TraceID: model.NewTraceID(0, traceID),
+		SpanID:  model.NewSpanID(traceID),
+		Process: &model.Process{ServiceName: service},
+	}
+}
+
+func TestGetRecentTraces(t *testing.T) {
+	recentTraces := tracestream.NewRecentTraces(10)
+	recentTraces.Add(recentSpan(1, "frontend"), "")
+	recentTraces.Add(recentSpan(2, "payment-api"), "")
+	recentTraces.Add(recentSpan(3, "acme-api"), "acme")
+	qs := initializeTestService(withRecentTraces(recentTraces)).queryService
+
+	traces, err := qs.GetRecentTraces(context.Background(), "", 0)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+	assert.Equal(t, model.NewTraceID(0, 2), traces[0].Spans[0].TraceID)
+
+	traces, err = qs.GetRecentTraces(context.Background(), "frontend", 0)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, model.NewTraceID(0, 1), traces[0].Spans[0].TraceID)
+
+	traces, err = qs.GetRecentTraces(context.Background(), "", 1)
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+
+	traces, err = qs.GetRecentTraces(tenancy.WithTenant(context.Background(), "acme"), "", 0)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, model.NewTraceID(0, 3), traces[0].Spans[0].TraceID)
+}
+
+func TestGetRecentTracesAuthorization(t *testing.T) {
+	recentTraces := tracestream.NewRecentTraces(10)
+	recentTraces.Add(recentSpan(1, "frontend"), "")
+	recentTraces.Add(recentSpan(2, "payment-api"), "")
+	qs := initializeTestService(withRecentTraces(recentTraces), withAuthorizer()).queryService
+
+	traces, err := qs.GetRecentTraces(context.Background(), "", 0)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, "frontend", traces[0].Spans[0].Process.ServiceName)
+
+	_, err = qs.GetRecentTraces(context.Background(), "payment-api", 0)
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+
+	traces, err = qs.GetRecentTraces(paymentsCaller(), "payment-api", 0)
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+}
+
+func TestGetRecentTracesNotConfigured(t *testing.T) {
+	qs := initializeTestService().queryService
+	_, err := qs.GetRecentTraces(context.Background(), "", 0)
+	require.ErrorIs(t, err, ErrRecentTracesNotConfigured)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+// ErrSamplingStrategyStorageNotConfigured is returned when the sampling strategies are accessed but not stored.
+var ErrSamplingStrategyStorageNotConfigured = errors.New("sampling strategies storage was not configured")
+
+// ErrInvalidSamplingStrategy is returned when a sampling strategy cannot be served to the SDKs.
+var ErrInvalidSamplingStrategy = errors.New("invalid sampling strategy")
+
+// InitSamplingStrategyStorage tries to initialize the sampling strategies storage if the storage factory supports it.
+func (opts *QueryServiceOptions) InitSamplingStrategyStorage(storageFactory storage.Factory, logger *zap.Logger) bool {
+	strategyFactory, ok := storageFactory.(storage.SamplingStrategyStoreFactory)
+	if !ok {
+		logger.Info("Sampling strategies storage not supported by the factory")
+		return false
+	}
+	store, err := strategyFactory.CreateSamplingStrategyStore()
+	if errors.Is(err, storage.ErrSamplingStrategyStorageNotSupported) {
+		logger.Info("Sampling strategies storage not created", zap.String("reason", err.Error()))
+		return false
+	}
+	if err != nil {
+		logger.Error("Cannot init sampling strategies storage", zap.Error(err))
+		return false
+	}
+	opts.StrategyStore = store
+	return true
+}
+
+// GetSamplingStrategy returns the stored sampling strategy of a service.
+func (qs QueryService) GetSamplingStrategy(ctx context.Context, service string) (*api_v2.SamplingStrategyResponse, error) {
+	if qs.options.StrategyStore == nil {
+		return nil, ErrSamplingStrategyStorageNotConfigured
+	}
+	return qs.options.StrategyStore.GetStrategy(ctx, service)
+}
+
+// FindSamplingStrategies returns the stored sampling strategies of all the services, by service name.
+func (qs QueryService) FindSamplingStrategies(ctx context.Context) (map[string]*api_v2.SamplingStrategyResponse, error) {
+	if qs.options.StrategyStore == nil {
+		return nil, ErrSamplingStrategyStorageNotConfigured
+	}
+	return qs.options.StrategyStore.FindStrategies(ctx)
+}
+
+// WriteSamplingStrategy validates and stores the sampling strategy of a service, replacing
+// the strategy the collectors have for it once they refresh the stored strategies.
+func (qs QueryService) WriteSamplingStrategy(ctx context.Context, service string, strategy *api_v2.SamplingStrategyResponse) error {
+	if qs.options.StrategyStore == nil {
+		return ErrSamplingStrategyStorageNotConfigured
+	}
+	if err := validateSamplingStrategy(strategy); err != nil {
+		return err
+	}
+	return qs.options.StrategyStore.WriteStrategy(ctx, service, strategy)
+}
+
+// DeleteSamplingStrategy deletes the stored sampling strategy of a service, so that the
+// collectors serve the strategy they have for it again.
+func (qs QueryService) DeleteSamplingStrategy(ctx context.Context, service string) error {
+	if qs.options.StrategyStore == nil {
+		return ErrSamplingStrategyStorageNotConfigured
+	}
+	return qs.options.StrategyStore.DeleteStrategy(ctx, service)
+}
+
+func invalidStrategy(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSamplingStrategy, fmt.Sprintf(format, args...))
+}
+
+// validateSamplingStrategy checks that the strategy has the parameters of its type, and that
+// its probabilities and rates are in range.
+func validateSamplingStrategy(strategy *api_v2.SamplingStrategyResponse) error {
+	switch {
+	case strategy.OperationSampling != nil:
+		operations := strategy.OperationSampling
+		if err := validateProbability(operations.DefaultSamplingProbability, "default sampling probability"); err != nil {
+			return err
+		}
+		if operations.DefaultLowerBoundTracesPerSecond < 0 {
+			return invalidStrategy("the default lower bound of traces per second %v is negative", operations.DefaultLowerBoundTracesPerSecond)
+		}
+		for _, operation := range operations.PerOperationStrategies {
+			if operation.ProbabilisticSampling == nil {
+				return invalidStrategy("the strategy of the operation %q has no probabilistic sampling", operation.Operation)
+			}
+			if err := validateProbability(operation.ProbabilisticSampling.SamplingRate, fmt.Sprintf("sampling rate of the operation %q", operation.Operation)); err != nil {
+				return err
+			}
+			if _, ok := model.SpanKindFromString(operation.SpanKind); operation.SpanKind != "" && !ok {
+				return invalidStrategy("the span kind %q of the operation %q is unknown", operation.SpanKind, operation.Operation)
+			}
+		}
+	case strategy.StrategyType == api_v2.SamplingStrategyType_PROBABILISTIC:
+		if strategy.ProbabilisticSampling == nil {
+			return invalidStrategy("a probabilistic strategy requires probabilisticSampling")
+		}
+		if err := validateProbability(strategy.ProbabilisticSampling.SamplingRate, "sampling rate"); err != nil {
+			return err
+		}
+	case strategy.StrategyType == api_v2.SamplingStrategyType_RATE_LIMITING:
+		if strategy.RateLimitingSampling == nil {
+			return invalidStrategy("a rate limiting strategy requires rateLimitingSampling")
+		}
+		if strategy.RateLimitingSampling.MaxTracesPerSecond < 0 {
+			return invalidStrategy("the maximum traces per second %d is negative", strategy.RateLimitingSampling.MaxTracesPerSecond)
+		}
+	default:
+		return invalidStrategy("unknown strategy type %v", strategy.StrategyType)
+	}
+	return nil
+}
+
+func validateProbability(probability float64, name string) error {
+	if probability < 0 || probability > 1 {
+		return invalidStrategy("the %s must be between 0 and 1, got %v", name, probability)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+type fakeStrategyStorageFactory struct {
+	fakeStorageFactory1
+	store samplingstore.StrategyStore
+	err   error
+}
+
+func (f *fakeStrategyStorageFactory) CreateSamplingStrategyStore() (samplingstore.StrategyStore, error) {
+	return f.store, f.err
+}
+
+var _ storage.SamplingStrategyStoreFactory = new(fakeStrategyStorageFactory)
+
+func withStrategyStore() testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.StrategyStore = memory.NewStrategyStore()
+	}
+}
+
+func probabilistic(rate float64) *api_v2.SamplingStrategyResponse {
+	return &api_v2.SamplingStrategyResponse{
+		StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: rate},
+	}
+}
+
+func TestInitSamplingStrategyStorage(t *testing.T) {
+	store := memory.NewStrategyStore()
+	tests := []struct {
+		name    string
+		factory storage.Factory
+		ok      bool
+	}{
+		{name: "not a sampling strategies factory", factory: new(fakeStorageFactory1)},
+		{name: "not supported", factory: &fakeStrategyStorageFactory{err: storage.ErrSamplingStrategyStorageNotSupported}},
+		{name: "error", factory: &fakeStrategyStorageFactory{err: errors.New("storage error")}},
+		{name: "success", factory: &fakeStrategyStorageFactory{store: store}, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := &QueryServiceOptions{}
+			assert.Equal(t, test.ok, opts.InitSamplingStrategyStorage(test.factory, zap.NewNop()))
+			if test.ok {
+				assert.Same(t, store, opts.StrategyStore)
+			} else {
+				assert.Nil(t, opts.StrategyStore)
+			}
+		})
+	}
+}
+
+func TestSamplingStrategies(t *testing.T) {
+	tqs := initializeTestService(withStrategyStore())
+	ctx := context.Background()
+	assert.True(t, tqs.queryService.GetCapabilities().SamplingStrategies)
+
+	require.NoError(t, tqs.queryService.WriteSamplingStrategy(ctx, "frontend", probabilistic(0.5)))
+	strategy, err := tqs.queryService.GetSamplingStrategy(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.5), strategy)
+
+	found, err := tqs.queryService.FindSamplingStrategies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*api_v2.SamplingStrategyResponse{"frontend": probabilistic(0.5)}, found)
+
+	err = tqs.queryService.WriteSamplingStrategy(ctx, "frontend", probabilistic(2))
+	require.ErrorIs(t, err, ErrInvalidSamplingStrategy)
+
+	require.NoError(t, tqs.queryService.DeleteSamplingStrategy(ctx, "frontend"))
+	_, err = tqs.queryService.GetSamplingStrategy(ctx, "frontend")
+	require.ErrorIs(t, err, samplingstore.ErrStrategyNotFound)
+}
+
+func TestSamplingStrategiesNotConfigured(t *testing.T) {
+	tqs := initializeTestService()
+	ctx := context.Background()
+	assert.False(t, tqs.queryService.GetCapabilities().SamplingStrategies)
+	_, err := tqs.queryService.GetSamplingStrategy(ctx, "frontend")
+	require.ErrorIs(t, err, ErrSamplingStrategyStorageNotConfigured)
+	_, err = tqs.queryService.FindSamplingStrategies(ctx)
+	require.ErrorIs(t, err, ErrSamplingStrategyStorageNotConfigured)
+	err = tqs.queryService.WriteSamplingStrategy(ctx, "frontend", probabilistic(0.5))
+	require.ErrorIs(t, err, ErrSamplingStrategyStorageNotConfigured)
+	require.ErrorIs(t, tqs.queryService.DeleteSamplingStrategy(ctx, "frontend"), ErrSamplingStrategyStorageNotConfigured)
+}
+
+func TestValidateSamplingStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy *api_v2.SamplingStrategyResponse
+		err      string
+	}{
+		{name: "probabilistic", strategy: probabilistic(1)},
+		{
+			name: "rate limiting",
+			strategy: &api_v2.SamplingStrategyResponse{
+				StrategyType:         api_v2.SamplingStrategyType_RATE_LIMITING,
+				RateLimitingSampling: &api_v2.RateLimitingSamplingStrategy{MaxTracesPerSecond: 10},
+			},
+		},
+		{
+			name: "per operation",
+			strategy: &api_v2.SamplingStrategyResponse{
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{
+					DefaultSamplingProbability: 0.1,
+					PerOperationStrategies: []*api_v2.OperationSamplingStrategy{
+						{Operation: "GET /", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 1}},
+						{Operation: "GET /", SpanKind: "server", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.1}},
+					},
+				},
+			},
+		},
+		{name: "negative probability", strategy: probabilistic(-0.1), err: "invalid sampling strategy: the sampling rate must be between 0 and 1, got -0.1"},
+		{
+			name:     "missing probabilistic sampling",
+			strategy: &api_v2.SamplingStrategyResponse{},
+			err:      "invalid sampling strategy: a probabilistic strategy requires probabilisticSampling",
+		},
+		{
+			name:     "missing rate limiting sampling",
+			strategy: &api_v2.SamplingStrategyResponse{StrategyType: api_v2.SamplingStrategyType_RATE_LIMITING},
+			err:      "invalid sampling strategy: a rate limiting strategy requires rateLimitingSampling",
+		},
+		{
+			name: "negative rate",
+			strategy: &api_v2.SamplingStrategyResponse{
+				StrategyType:         api_v2.SamplingStrategyType_RATE_LIMITING,
+				RateLimitingSampling: &api_v2.RateLimitingSamplingStrategy{MaxTracesPerSecond: -1},
+			},
+			err: "invalid sampling strategy: the maximum traces per second -1 is negative",
+		},
+		{
+			name:     "unknown type",
+			strategy: &api_v2.SamplingStrategyResponse{StrategyType: 5},
+			err:      "invalid sampling strategy: unknown strategy type 5",
+		},
+		{
+			name: "invalid default probability",
+			strategy: &api_v2.SamplingStrategyResponse{
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{DefaultSamplingProbability: 1.5},
+			},
+			err: "invalid sampling strategy: the default sampling probability must be between 0 and 1, got 1.5",
+		},
+		{
+			name: "negative lower bound",
+			strategy: &api_v2.SamplingStrategyResponse{
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{DefaultLowerBoundTracesPerSecond: -1},
+			},
+			err: "invalid sampling strategy: the default lower bound of traces per second -1 is negative",
+		},
+		{
+			name: "operation without probabilistic sampling",
+			strategy: &api_v2.SamplingStrategyResponse{
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{
+					PerOperationStrategies: []*api_v2.OperationSamplingStrategy{{Operation: "GET /"}},
+				},
+			},
+			err: `invalid sampling strategy: the strategy of the operation "GET /" has no probabilistic sampling`,
+		},
+		{
+			name: "invalid operation probability",
+			strategy: &api_v2.SamplingStrategyResponse{
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{
+					PerOperationStrategies: []*api_v2.OperationSamplingStrategy{
+						{Operation: "GET /", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 2}},
+					},
+				},
+			},
+			err: `invalid sampling strategy: the sampling rate of the operation "GET /" must be between 0 and 1, got 2`,
+		},
+		{
+			name: "unknown operation span kind",
+			strategy: &api_v2.SamplingStrategyResponse{
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{
+					PerOperationStrategies: []*api_v2.OperationSamplingStrategy{
+						{Operation: "orders", SpanKind: "worker", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 1}},
+					},
+				},
+			},
+			err: `invalid sampling strategy: the span kind "worker" of the operation "orders" is unknown`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSamplingStrategy(test.strategy)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
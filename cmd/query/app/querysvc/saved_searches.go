@@ -0,0 +1,108 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
+)
+
+// ErrSavedSearchStorageNotConfigured is returned when the saved searches are accessed but not stored.
+var ErrSavedSearchStorageNotConfigured = errors.New("saved searches storage was not configured")
+
+// InitSavedSearchStorage tries to initialize the saved searches storage if the storage factory supports it.
+func (opts *QueryServiceOptions) InitSavedSearchStorage(storageFactory storage.Factory, logger *zap.Logger) bool {
+	searchFactory, ok := storageFactory.(storage.SavedSearchStoreFactory)
+	if !ok {
+		logger.Info("Saved searches storage not supported by the factory")
+		return false
+	}
+	store, err := searchFactory.CreateSavedSearchStore()
+	if errors.Is(err, storage.ErrSavedSearchStorageNotSupported) {
+		logger.Info("Saved searches storage not created", zap.String("reason", err.Error()))
+		return false
+	}
+	if err != nil {
+		logger.Error("Cannot init saved searches storage", zap.Error(err))
+		return false
+	}
+	opts.SavedSearchStore = store
+	return true
+}
+
+// savedSearchOwner returns the user the searches are saved for, the subject of the JWT bearer
+// token of the request. The callers without one share the searches of the anonymous user.
+func savedSearchOwner(ctx context.Context) string {
+	subject, _ := ClaimsFromContext(ctx)["sub"].(string)
+	return subject
+}
+
+// GetSavedSearch returns a search saved by the owner, or by the caller if the owner is empty.
+// The searches of the other users are only returned if they are shared.
+func (qs QueryService) GetSavedSearch(ctx context.Context, owner, name string) (*savedsearchstore.SavedSearch, error) {
+	if qs.options.SavedSearchStore == nil {
+		return nil, ErrSavedSearchStorageNotConfigured
+	}
+	caller := savedSearchOwner(ctx)
+	if owner == "" {
+		owner = caller
+	}
+	search, err := qs.options.SavedSearchStore.GetSavedSearch(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if owner != caller && !search.Shared {
+		return nil, savedsearchstore.ErrSavedSearchNotFound
+	}
+	if err := qs.authorizeService(ctx, search.ServiceName); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// FindSavedSearches returns the searches saved by the caller and the ones shared by the other
+// users, for the services the caller is allowed to query.
+func (qs QueryService) FindSavedSearches(ctx context.Context) ([]*savedsearchstore.SavedSearch, error) {
+	if qs.options.SavedSearchStore == nil {
+		return nil, ErrSavedSearchStorageNotConfigured
+	}
+	found, err := qs.options.SavedSearchStore.FindSavedSearches(ctx, savedSearchOwner(ctx))
+	if err != nil || qs.options.Authorizer == nil {
+		return found, err
+	}
+	allowed := make([]*savedsearchstore.SavedSearch, 0, len(found))
+	for _, search := range found {
+		if qs.IsServiceAllowed(ctx, search.ServiceName) {
+			allowed = append(allowed, search)
+		}
+	}
+	return allowed, nil
+}
+
+// WriteSavedSearch creates or replaces a search of the caller, setting its owner and update time.
+func (qs QueryService) WriteSavedSearch(ctx context.Context, search *savedsearchstore.SavedSearch) error {
+	if qs.options.SavedSearchStore == nil {
+		return ErrSavedSearchStorageNotConfigured
+	}
+	if err := qs.authorizeService(ctx, search.ServiceName); err != nil {
+		return err
+	}
+	search.Owner = savedSearchOwner(ctx)
+	search.UpdatedAt = time.Now().UTC()
+	return qs.options.SavedSearchStore.WriteSavedSearch(ctx, search)
+}
+
+// DeleteSavedSearch deletes a search of the caller.
+func (qs QueryService) DeleteSavedSearch(ctx context.Context, name string) error {
+	if qs.options.SavedSearchStore == nil {
+		return ErrSavedSearchStorageNotConfigured
+	}
+	return qs.options.SavedSearchStore.DeleteSavedSearch(ctx, savedSearchOwner(ctx), name)
+}
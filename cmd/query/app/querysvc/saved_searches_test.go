@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
+)
+
+type fakeSavedSearchStorageFactory struct {
+	fakeStorageFactory1
+	store savedsearchstore.Store
+	err   error
+}
+
+func (f *fakeSavedSearchStorageFactory) CreateSavedSearchStore() (savedsearchstore.Store, error) {
+	return f.store, f.err
+}
+
+var _ storage.SavedSearchStoreFactory = new(fakeSavedSearchStorageFactory)
+
+func withSavedSearchStore() testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.SavedSearchStore = memory.NewSavedSearchStore()
+	}
+}
+
+func userCaller(subject string) context.Context {
+	return ContextWithClaims(context.Background(), map[string]any{"sub": subject})
+}
+
+func TestInitSavedSearchStorage(t *testing.T) {
+	store := memory.NewSavedSearchStore()
+	tests := []struct {
+		name    string
+		factory storage.Factory
+		ok      bool
+	}{
+		{name: "not a saved search factory", factory: new(fakeStorageFactory1)},
+		{name: "not supported", factory: &fakeSavedSearchStorageFactory{err: storage.ErrSavedSearchStorageNotSupported}},
+		{name: "error", factory: &fakeSavedSearchStorageFactory{err: errors.New("storage error")}},
+		{name: "success", factory: &fakeSavedSearchStorageFactory{store: store}, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := &QueryServiceOptions{}
+			assert.Equal(t, test.ok, opts.InitSavedSearchStorage(test.factory, zap.NewNop()))
+			if test.ok {
+				assert.Same(t, store, opts.SavedSearchStore)
+			} else {
+				assert.Nil(t, opts.SavedSearchStore)
+			}
+		})
+	}
+}
+
+func TestSavedSearches(t *testing.T) {
+	tqs := initializeTestService(withSavedSearchStore())
+	alice, bob := userCaller("alice"), userCaller("bob")
+	assert.True(t, tqs.queryService.GetCapabilities().SavedSearches)
+
+	slow := &savedsearchstore.SavedSearch{Name: "slow", ServiceName: "frontend", Owner: "bob"}
+	require.NoError(t, tqs.queryService.WriteSavedSearch(alice, slow))
+	assert.Equal(t, "alice", slow.Owner)
+	assert.False(t, slow.UpdatedAt.IsZero())
+	search, err := tqs.queryService.GetSavedSearch(alice, "", "slow")
+	require.NoError(t, err)
+	assert.Equal(t, slow, search)
+
+	// the searches of the other users are only visible when shared
+	_, err = tqs.queryService.GetSavedSearch(bob, "", "slow")
+	require.ErrorIs(t, err, savedsearchstore.ErrSavedSearchNotFound)
+	_, err = tqs.queryService.GetSavedSearch(bob, "alice", "slow")
+	require.ErrorIs(t, err, savedsearchstore.ErrSavedSearchNotFound)
+	found, err := tqs.queryService.FindSavedSearches(bob)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	slow.Shared = true
+	require.NoError(t, tqs.queryService.WriteSavedSearch(alice, slow))
+	search, err = tqs.queryService.GetSavedSearch(bob, "alice", "slow")
+	require.NoError(t, err)
+	assert.Equal(t, slow, search)
+	found, err = tqs.queryService.FindSavedSearches(bob)
+	require.NoError(t, err)
+	assert.Equal(t, []*savedsearchstore.SavedSearch{slow}, found)
+
+	require.ErrorIs(t, tqs.queryService.DeleteSavedSearch(bob, "slow"), savedsearchstore.ErrSavedSearchNotFound)
+	require.NoError(t, tqs.queryService.DeleteSavedSearch(alice, "slow"))
+	_, err = tqs.queryService.GetSavedSearch(alice, "", "slow")
+	require.ErrorIs(t, err, savedsearchstore.ErrSavedSearchNotFound)
+}
+
+func TestSavedSearchesAuthorized(t *testing.T) {
+	tqs := initializeTestService(withSavedSearchStore(), withAuthorizer())
+	for _, service := range []string{"frontend", "payment-api"} {
+		search := &savedsearchstore.SavedSearch{Name: service, ServiceName: service, Shared: true}
+		require.NoError(t, tqs.queryService.WriteSavedSearch(paymentsCaller(), search))
+	}
+	ctx := context.Background()
+
+	found, err := tqs.queryService.FindSavedSearches(ctx)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "frontend", found[0].Name)
+	found, err = tqs.queryService.FindSavedSearches(paymentsCaller())
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	_, err = tqs.queryService.GetSavedSearch(ctx, "", "payment-api")
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+	err = tqs.queryService.WriteSavedSearch(ctx, &savedsearchstore.SavedSearch{Name: "payments", ServiceName: "payment-api"})
+	require.ErrorIs(t, err, ErrServiceNotAllowed)
+}
+
+func TestSavedSearchesNotConfigured(t *testing.T) {
+	tqs := initializeTestService()
+	ctx := context.Background()
+	assert.False(t, tqs.queryService.GetCapabilities().SavedSearches)
+	_, err := tqs.queryService.GetSavedSearch(ctx, "", "slow")
+	require.ErrorIs(t, err, ErrSavedSearchStorageNotConfigured)
+	_, err = tqs.queryService.FindSavedSearches(ctx)
+	require.ErrorIs(t, err, ErrSavedSearchStorageNotConfigured)
+	err = tqs.queryService.WriteSavedSearch(ctx, &savedsearchstore.SavedSearch{Name: "slow", ServiceName: "frontend"})
+	require.ErrorIs(t, err, ErrSavedSearchStorageNotConfigured)
+	require.ErrorIs(t, tqs.queryService.DeleteSavedSearch(ctx, "slow"), ErrSavedSearchStorageNotConfigured)
+}
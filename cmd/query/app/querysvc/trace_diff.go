@@ -0,0 +1,173 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// SpanDiffType describes how a span differs between two compared traces.
+type SpanDiffType string
+
+const (
+	// SpanDiffMatched is a span with the same service and operation in both traces.
+	SpanDiffMatched SpanDiffType = "matched"
+	// SpanDiffRenamed is a span of the same service at the same position in both traces, but with another operation.
+	SpanDiffRenamed SpanDiffType = "renamed"
+	// SpanDiffAdded is a span that exists only in the other trace.
+	SpanDiffAdded SpanDiffType = "added"
+	// SpanDiffRemoved is a span that exists only in the base trace.
+	SpanDiffRemoved SpanDiffType = "removed"
+)
+
+// SpanDiff compares a span of the base trace with its counterpart in the other trace.
+// Base is nil for added spans and Other is nil for removed spans.
+type SpanDiff struct {
+	Type  SpanDiffType
+	Base  *model.Span
+	Other *model.Span
+	// DurationDelta is the duration of Other minus the duration of Base, for matched and renamed spans.
+	DurationDelta time.Duration
+}
+
+// TraceDiff is the structural difference between two traces.
+type TraceDiff struct {
+	BaseTraceID  model.TraceID
+	OtherTraceID model.TraceID
+	// Spans are ordered depth-first, following the span tree of the base trace.
+	Spans []SpanDiff
+}
+
+// CompareTraces retrieves two traces and computes their structural difference.
+// Spans are matched by service and operation name among the children of matching parents,
+// in the order of their start time.
+func (qs QueryService) CompareTraces(ctx context.Context, baseTraceID, otherTraceID model.TraceID) (*TraceDiff, error) {
+	base, err := qs.GetTrace(ctx, baseTraceID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve base trace %s: %w", baseTraceID, err)
+	}
+	other, err := qs.GetTrace(ctx, otherTraceID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve other trace %s: %w", otherTraceID, err)
+	}
+	diff := &TraceDiff{
+		BaseTraceID:  baseTraceID,
+		OtherTraceID: otherTraceID,
+	}
+	diff.compareSpans(buildSpanTree(base), buildSpanTree(other))
+	return diff, nil
+}
+
+type spanNode struct {
+	span     *model.Span
+	children []*spanNode
+}
+
+// buildSpanTree returns the root spans of the trace, with children sorted by start time.
+// Spans whose parent is missing from the trace are considered roots.
+func buildSpanTree(trace *model.Trace) []*spanNode {
+	nodes := make(map[model.SpanID]*spanNode, len(trace.Spans))
+	for _, span := range trace.Spans {
+		nodes[span.SpanID] = &spanNode{span: span}
+	}
+	var roots []*spanNode
+	for _, span := range trace.Spans {
+		node := nodes[span.SpanID]
+		if node.span != span {
+			// duplicate span ID, only the last span is kept
+			continue
+		}
+		if parent, ok := nodes[span.ParentSpanID()]; ok && parent != node {
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sortSpanNodes(roots)
+	for _, node := range nodes {
+		sortSpanNodes(node.children)
+	}
+	return roots
+}
+
+func sortSpanNodes(nodes []*spanNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].span.StartTime.Before(nodes[j].span.StartTime)
+	})
+}
+
+// compareSpans matches sibling spans of both traces and recursively compares their children.
+func (d *TraceDiff) compareSpans(base, other []*spanNode) {
+	matches := make([]int, len(base))
+	otherMatched := make([]bool, len(other))
+	for i := range matches {
+		matches[i] = -1
+	}
+	match := func(same func(b, o *model.Span) bool) {
+		for i, b := range base {
+			if matches[i] >= 0 {
+				continue
+			}
+			for j, o := range other {
+				if !otherMatched[j] && same(b.span, o.span) {
+					matches[i] = j
+					otherMatched[j] = true
+					break
+				}
+			}
+		}
+	}
+	match(func(b, o *model.Span) bool {
+		return sameService(b, o) && b.OperationName == o.OperationName
+	})
+	// the spans left at the same position are considered renamed
+	match(sameService)
+
+	for i, b := range base {
+		j := matches[i]
+		if j < 0 {
+			d.addSubtree(SpanDiffRemoved, b)
+			continue
+		}
+		o := other[j]
+		diffType := SpanDiffMatched
+		if b.span.OperationName != o.span.OperationName {
+			diffType = SpanDiffRenamed
+		}
+		d.Spans = append(d.Spans, SpanDiff{
+			Type:          diffType,
+			Base:          b.span,
+			Other:         o.span,
+			DurationDelta: o.span.Duration - b.span.Duration,
+		})
+		d.compareSpans(b.children, o.children)
+	}
+	for j, o := range other {
+		if !otherMatched[j] {
+			d.addSubtree(SpanDiffAdded, o)
+		}
+	}
+}
+
+func (d *TraceDiff) addSubtree(diffType SpanDiffType, node *spanNode) {
+	spanDiff := SpanDiff{Type: diffType}
+	if diffType == SpanDiffAdded {
+		spanDiff.Other = node.span
+	} else {
+		spanDiff.Base = node.span
+	}
+	d.Spans = append(d.Spans, spanDiff)
+	for _, child := range node.children {
+		d.addSubtree(diffType, child)
+	}
+}
+
+func sameService(b, o *model.Span) bool {
+	return b.Process.GetServiceName() == o.Process.GetServiceName()
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func newDiffSpan(traceID model.TraceID, id, parent uint64, service, operation string, start, duration time.Duration) *model.Span {
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(id),
+		OperationName: operation,
+		Process:       &model.Process{ServiceName: service},
+		StartTime:     time.Unix(0, 0).Add(start),
+		Duration:      duration,
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parent))}
+	}
+	return span
+}
+
+func TestCompareTraces(t *testing.T) {
+	baseID := model.NewTraceID(0, 1)
+	otherID := model.NewTraceID(0, 2)
+	baseTrace := &model.Trace{Spans: []*model.Span{
+		newDiffSpan(baseID, 1, 0, "frontend", "GET /dispatch", 0, 100*time.Millisecond),
+		newDiffSpan(baseID, 3, 1, "redis", "GetDriver", 20*time.Millisecond, 10*time.Millisecond),
+		newDiffSpan(baseID, 2, 1, "customer", "GET /customer", 10*time.Millisecond, 5*time.Millisecond),
+		newDiffSpan(baseID, 4, 1, "route", "FindRoute", 30*time.Millisecond, 10*time.Millisecond),
+		newDiffSpan(baseID, 5, 4, "route", "compute", 31*time.Millisecond, 5*time.Millisecond),
+	}}
+	otherTrace := &model.Trace{Spans: []*model.Span{
+		newDiffSpan(otherID, 11, 0, "frontend", "GET /dispatch", 0, 150*time.Millisecond),
+		newDiffSpan(otherID, 12, 11, "customer", "GET /customer", 10*time.Millisecond, 45*time.Millisecond),
+		newDiffSpan(otherID, 13, 11, "redis", "FindDriverIDs", 60*time.Millisecond, 10*time.Millisecond),
+		newDiffSpan(otherID, 14, 11, "mysql", "SQL SELECT", 80*time.Millisecond, 10*time.Millisecond),
+	}}
+
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetTrace", mock.Anything, baseID).Return(baseTrace, nil).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, otherID).Return(otherTrace, nil).Once()
+
+	diff, err := tqs.queryService.CompareTraces(context.Background(), baseID, otherID)
+	require.NoError(t, err)
+	assert.Equal(t, baseID, diff.BaseTraceID)
+	assert.Equal(t, otherID, diff.OtherTraceID)
+
+	type spanDiff struct {
+		diffType SpanDiffType
+		base     uint64
+		other    uint64
+		delta    time.Duration
+	}
+	expected := []spanDiff{
+		{SpanDiffMatched, 1, 11, 50 * time.Millisecond},
+		{SpanDiffMatched, 2, 12, 40 * time.Millisecond},
+		{SpanDiffRenamed, 3, 13, 0},
+		{SpanDiffRemoved, 4, 0, 0},
+		{SpanDiffRemoved, 5, 0, 0},
+		{SpanDiffAdded, 0, 14, 0},
+	}
+	actual := make([]spanDiff, len(diff.Spans))
+	for i, s := range diff.Spans {
+		actual[i] = spanDiff{diffType: s.Type, delta: s.DurationDelta}
+		if s.Base != nil {
+			actual[i].base = uint64(s.Base.SpanID)
+		}
+		if s.Other != nil {
+			actual[i].other = uint64(s.Other.SpanID)
+		}
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestCompareTracesNotFound(t *testing.T) {
+	baseID := model.NewTraceID(0, 1)
+	otherID := model.NewTraceID(0, 2)
+
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetTrace", mock.Anything, baseID).Return(nil, spanstore.ErrTraceNotFound).Once()
+	_, err := tqs.queryService.CompareTraces(context.Background(), baseID, otherID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+	tqs.spanReader.On("GetTrace", mock.Anything, baseID).Return(mockTrace, nil).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, otherID).Return(nil, spanstore.ErrTraceNotFound).Once()
+	_, err = tqs.queryService.CompareTraces(context.Background(), baseID, otherID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+func TestBuildSpanTree(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	duplicate := newDiffSpan(traceID, 2, 1, "svc", "duplicate", 0, 0)
+	roots := buildSpanTree(&model.Trace{Spans: []*model.Span{
+		newDiffSpan(traceID, 3, 99, "svc", "orphan", 2, 0),
+		newDiffSpan(traceID, 1, 0, "svc", "root", 1, 0),
+		newDiffSpan(traceID, 2, 1, "svc", "child", 0, 0),
+		duplicate,
+	}})
+	require.Len(t, roots, 2)
+	assert.Equal(t, "root", roots[0].span.OperationName)
+	assert.Equal(t, "orphan", roots[1].span.OperationName)
+	require.Len(t, roots[0].children, 1)
+	assert.Same(t, duplicate, roots[0].children[0].span)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
+)
+
+// ErrWarningStorageNotConfigured is returned when the warnings are queried but not recorded.
+var ErrWarningStorageNotConfigured = errors.New("warnings storage was not configured")
+
+// maxPendingWarningWrites bounds the writes of the warnings in progress, the warnings of the
+// traces adjusted beyond it being dropped rather than delaying the reads of the traces.
+const maxPendingWarningWrites = 100
+
+// WarningRecorder writes the warnings of the adjusted traces in the background.
+type WarningRecorder struct {
+	store   warningstore.Writer
+	logger  *zap.Logger
+	pending chan struct{}
+}
+
+// NewWarningRecorder creates a WarningRecorder writing the warnings to the store.
+func NewWarningRecorder(store warningstore.Writer, logger *zap.Logger) *WarningRecorder {
+	return &WarningRecorder{
+		store:   store,
+		logger:  logger,
+		pending: make(chan struct{}, maxPendingWarningWrites),
+	}
+}
+
+// Record writes the warnings of the spans of an adjusted trace, if any, without waiting for the
+// write, which is not bound to ctx but to its tenant.
+func (r *WarningRecorder) Record(ctx context.Context, trace *model.Trace) {
+	if len(trace.Spans) == 0 {
+		return
+	}
+	warnings := traceWarnings(trace)
+	if len(warnings.Warnings) == 0 {
+		return
+	}
+	select {
+	case r.pending <- struct{}{}:
+	default:
+		r.logger.Debug("Dropping the warnings of the trace, too many writes in progress", zap.Stringer("trace_id", warnings.TraceID))
+		return
+	}
+	writeCtx := tenancy.WithTenant(context.Background(), tenancy.GetTenant(ctx))
+	go func() {
+		defer func() { <-r.pending }()
+		if err := r.store.WriteTraceWarnings(writeCtx, warnings); err != nil {
+			r.logger.Error("Failed to record the warnings of the trace", zap.Stringer("trace_id", warnings.TraceID), zap.Error(err))
+		}
+	}()
+}
+
+// InitWarningStorage tries to initialize the warnings storage if the storage factory supports it.
+func (opts *QueryServiceOptions) InitWarningStorage(storageFactory storage.Factory, logger *zap.Logger) bool {
+	warningFactory, ok := storageFactory.(storage.WarningStoreFactory)
+	if !ok {
+		logger.Info("Warnings storage not supported by the factory")
+		return false
+	}
+	store, err := warningFactory.CreateWarningStore()
+	if errors.Is(err, storage.ErrWarningStorageNotSupported) {
+		logger.Info("Warnings storage not created", zap.String("reason", err.Error()))
+		return false
+	}
+	if err != nil {
+		logger.Error("Cannot init warnings storage", zap.Error(err))
+		return false
+	}
+	opts.WarningStore = store
+	opts.WarningRecorder = NewWarningRecorder(store, logger)
+	return true
+}
+
+// RecordWarnings records the warnings of the spans of an adjusted trace in the background, if the
+// warnings storage is configured.
+func (qs QueryService) RecordWarnings(ctx context.Context, trace *model.Trace) {
+	if qs.options.WarningRecorder != nil {
+		qs.options.WarningRecorder.Record(ctx, trace)
+	}
+}
+
+// FindTraceWarnings finds the recorded warnings of the traces.
+// The traces spanning services the caller is not allowed to query are left out.
+func (qs QueryService) FindTraceWarnings(ctx context.Context, query *warningstore.Query) ([]*warningstore.TraceWarnings, error) {
+	if qs.options.WarningStore == nil {
+		return nil, ErrWarningStorageNotConfigured
+	}
+	found, err := qs.options.WarningStore.FindTraceWarnings(ctx, query)
+	if err != nil || qs.options.Authorizer == nil {
+		return found, err
+	}
+	allowed := make([]*warningstore.TraceWarnings, 0, len(found))
+	for _, warnings := range found {
+		if qs.areServicesAllowed(ctx, warnings.Services) {
+			allowed = append(allowed, warnings)
+		}
+	}
+	return allowed, nil
+}
+
+func (qs QueryService) areServicesAllowed(ctx context.Context, services []string) bool {
+	for _, service := range services {
+		if !qs.options.Authorizer.IsAllowed(ctx, service) {
+			return false
+		}
+	}
+	return true
+}
+
+func traceWarnings(trace *model.Trace) *warningstore.TraceWarnings {
+	warnings := &warningstore.TraceWarnings{
+		TraceID:   trace.Spans[0].TraceID,
+		StartTime: trace.Spans[0].StartTime,
+	}
+	services := make(map[string]struct{})
+	for _, span := range trace.Spans {
+		if span.StartTime.Before(warnings.StartTime) {
+			warnings.StartTime = span.StartTime
+		}
+		if _, ok := services[span.Process.GetServiceName()]; !ok {
+			services[span.Process.GetServiceName()] = struct{}{}
+			warnings.Services = append(warnings.Services, span.Process.GetServiceName())
+		}
+		for _, warning := range span.Warnings {
+			warnings.Warnings = append(warnings.Warnings, warningstore.SpanWarning{
+				SpanID:  span.SpanID,
+				Type:    adjuster.WarningType(warning),
+				Message: warning,
+			})
+		}
+	}
+	sort.Strings(warnings.Services)
+	return warnings
+}
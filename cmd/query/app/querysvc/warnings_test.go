@@ -0,0 +1,190 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
+)
+
+type fakeWarningStorageFactory struct {
+	fakeStorageFactory1
+	store warningstore.Store
+	err   error
+}
+
+func (f *fakeWarningStorageFactory) CreateWarningStore() (warningstore.Store, error) {
+	return f.store, f.err
+}
+
+var _ storage.WarningStoreFactory = new(fakeWarningStorageFactory)
+
+func withWarningStore() testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		store := memory.NewWarningStore(0)
+		options.WarningStore = store
+		options.WarningRecorder = NewWarningRecorder(store, zap.NewNop())
+	}
+}
+
+func newWarningsTrace(traceID model.TraceID, start time.Time, services ...string) *model.Trace {
+	trace := &model.Trace{}
+	for i, service := range services {
+		trace.Spans = append(trace.Spans, &model.Span{
+			TraceID:   traceID,
+			SpanID:    model.NewSpanID(uint64(i + 1)),
+			StartTime: start.Add(time.Duration(len(services)-i) * time.Millisecond),
+			Process:   &model.Process{ServiceName: service},
+		})
+	}
+	return trace
+}
+
+func TestInitWarningStorage(t *testing.T) {
+	store := memory.NewWarningStore(0)
+	tests := []struct {
+		name    string
+		factory storage.Factory
+		ok      bool
+	}{
+		{name: "not a warnings factory", factory: new(fakeStorageFactory1)},
+		{name: "not supported", factory: &fakeWarningStorageFactory{err: storage.ErrWarningStorageNotSupported}},
+		{name: "error", factory: &fakeWarningStorageFactory{err: errors.New("storage error")}},
+		{name: "success", factory: &fakeWarningStorageFactory{store: store}, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := &QueryServiceOptions{}
+			assert.Equal(t, test.ok, opts.InitWarningStorage(test.factory, zap.NewNop()))
+			if test.ok {
+				assert.Same(t, store, opts.WarningStore)
+				assert.NotNil(t, opts.WarningRecorder)
+			} else {
+				assert.Nil(t, opts.WarningStore)
+				assert.Nil(t, opts.WarningRecorder)
+			}
+		})
+	}
+}
+
+// findTraceWarnings waits for the warnings of n traces to be recorded and returns the ones the
+// caller of ctx is allowed to find.
+func findTraceWarnings(ctx context.Context, t *testing.T, qs *QueryService, n int) []*warningstore.TraceWarnings {
+	var found []*warningstore.TraceWarnings
+	require.Eventually(t, func() bool {
+		var err error
+		found, err = qs.WithoutAuthorization().FindTraceWarnings(ctx, &warningstore.Query{})
+		return err == nil && len(found) == n
+	}, time.Second, time.Millisecond)
+	found, err := qs.FindTraceWarnings(ctx, &warningstore.Query{})
+	require.NoError(t, err)
+	return found
+}
+
+func TestRecordWarnings(t *testing.T) {
+	tqs := initializeTestService(withWarningStore())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	clean := newWarningsTrace(model.NewTraceID(0, 1), start, "frontend")
+	tqs.queryService.RecordWarnings(context.Background(), clean)
+
+	skewed := newWarningsTrace(model.NewTraceID(0, 2), start, "payment-api", "frontend")
+	skewed.Spans[1].Warnings = []string{"This span's timestamps were adjusted by 1s", "reported by the SDK"}
+	tqs.queryService.RecordWarnings(context.Background(), skewed)
+
+	found := findTraceWarnings(context.Background(), t, tqs.queryService, 1)
+	assert.Equal(t, []*warningstore.TraceWarnings{{
+		TraceID:   skewed.Spans[0].TraceID,
+		StartTime: skewed.Spans[1].StartTime,
+		Services:  []string{"frontend", "payment-api"},
+		Warnings: []warningstore.SpanWarning{
+			{SpanID: model.NewSpanID(2), Type: adjuster.WarningTypeClockSkew, Message: skewed.Spans[1].Warnings[0]},
+			{SpanID: model.NewSpanID(2), Type: adjuster.WarningTypeOther, Message: skewed.Spans[1].Warnings[1]},
+		},
+	}}, found)
+
+	// the warnings are written for the tenant of the trace read
+	tenantCtx := tenancy.WithTenant(context.Background(), "acme")
+	tqs.queryService.RecordWarnings(tenantCtx, skewed)
+	findTraceWarnings(tenantCtx, t, tqs.queryService, 1)
+}
+
+// blockingWarningStore blocks the writes until unblocked.
+type blockingWarningStore struct {
+	warningstore.Store
+	unblock chan struct{}
+	written chan *warningstore.TraceWarnings
+}
+
+func (s *blockingWarningStore) WriteTraceWarnings(_ context.Context, warnings *warningstore.TraceWarnings) error {
+	<-s.unblock
+	s.written <- warnings
+	return errors.New("storage error")
+}
+
+func TestWarningRecorderDropsWhenBusy(t *testing.T) {
+	store := &blockingWarningStore{
+		unblock: make(chan struct{}),
+		written: make(chan *warningstore.TraceWarnings, maxPendingWarningWrites+1),
+	}
+	recorder := NewWarningRecorder(store, zap.NewNop())
+	for i := 0; i <= maxPendingWarningWrites; i++ {
+		trace := newWarningsTrace(model.NewTraceID(0, uint64(i+1)), time.Now(), "frontend")
+		trace.Spans[0].Warnings = []string{"reported by the SDK"}
+		// the reads of the traces are not delayed by the writes in progress
+		recorder.Record(context.Background(), trace)
+	}
+	close(store.unblock)
+	for i := 0; i < maxPendingWarningWrites; i++ {
+		<-store.written
+	}
+	// the warnings of the trace adjusted beyond the writes in progress are dropped
+	assert.Eventually(t, func() bool {
+		return len(recorder.pending) == 0
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, store.written)
+}
+
+func TestRecordWarningsNotConfigured(t *testing.T) {
+	tqs := initializeTestService()
+	trace := newWarningsTrace(model.NewTraceID(0, 1), time.Now(), "frontend")
+	trace.Spans[0].Warnings = []string{"reported by the SDK"}
+	tqs.queryService.RecordWarnings(context.Background(), trace)
+
+	_, err := tqs.queryService.FindTraceWarnings(context.Background(), &warningstore.Query{})
+	require.ErrorIs(t, err, ErrWarningStorageNotConfigured)
+}
+
+func TestFindTraceWarningsAuthorized(t *testing.T) {
+	tqs := initializeTestService(withWarningStore(), withAuthorizer())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, services := range [][]string{{"frontend"}, {"frontend", "payment-api"}, {"frontend", "billing"}} {
+		trace := newWarningsTrace(model.NewTraceID(0, uint64(i+1)), start.Add(time.Duration(i)*time.Second), services...)
+		trace.Spans[0].Warnings = []string{"reported by the SDK"}
+		tqs.queryService.RecordWarnings(context.Background(), trace)
+	}
+
+	found := findTraceWarnings(context.Background(), t, tqs.queryService, 3)
+	require.Len(t, found, 1)
+	assert.Equal(t, model.NewTraceID(0, 1), found[0].TraceID)
+
+	found, err := tqs.queryService.FindTraceWarnings(paymentsCaller(), &warningstore.Query{})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, model.NewTraceID(0, 2), found[0].TraceID)
+	assert.Equal(t, model.NewTraceID(0, 1), found[1].TraceID)
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// errorTags selects the spans counted as errors.
+var errorTags = map[string]string{"error": "true"}
+
+type annotationKey struct {
+	service   string
+	operation string
+	kind      Kind
+}
+
+// Detector periodically compares the latency and error rate of each operation
+// against a trailing baseline and records the regressions in a Store.
+type Detector struct {
+	options  Options
+	querySvc *querysvc.QueryService
+	store    *Store
+	logger   *zap.Logger
+	timeNow  func() time.Time
+
+	// lastDetected avoids reporting the same regression on every run, it is only used by the detection loop.
+	lastDetected map[annotationKey]time.Time
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	done      sync.WaitGroup
+}
+
+// NewDetector creates a Detector using the query service to compute latency distributions.
+func NewDetector(options Options, querySvc *querysvc.QueryService, logger *zap.Logger) *Detector {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Detector{
+		options:      options,
+		querySvc:     querySvc,
+		store:        NewStore(options.MaxAnnotations),
+		logger:       logger,
+		timeNow:      time.Now,
+		lastDetected: make(map[annotationKey]time.Time),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Store returns the store of detected regressions.
+func (d *Detector) Store() *Store {
+	return d.store
+}
+
+// Start runs the detection periodically until Close is called.
+func (d *Detector) Start() {
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+		ticker := time.NewTicker(d.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.detect(d.ctx)
+			case <-d.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the detection and waits for the current run to finish.
+func (d *Detector) Close() error {
+	d.closeOnce.Do(d.cancel)
+	d.done.Wait()
+	return nil
+}
+
+// detect compares every operation of every service against its baseline.
+func (d *Detector) detect(ctx context.Context) {
+	now := d.timeNow()
+	services, err := d.querySvc.GetServices(ctx)
+	if err != nil {
+		d.logger.Error("Failed to get services for regression detection", zap.Error(err))
+		return
+	}
+	for _, service := range services {
+		operations, err := d.querySvc.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: service})
+		if err != nil {
+			d.logger.Error("Failed to get operations for regression detection", zap.String("service", service), zap.Error(err))
+			continue
+		}
+		seen := make(map[string]struct{}, len(operations))
+		for _, operation := range operations {
+			if _, ok := seen[operation.Name]; ok {
+				continue
+			}
+			seen[operation.Name] = struct{}{}
+			if err := d.detectOperation(ctx, service, operation.Name, now); err != nil {
+				d.logger.Error("Failed to detect regressions",
+					zap.String("service", service), zap.String("operation", operation.Name), zap.Error(err))
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+func (d *Detector) detectOperation(ctx context.Context, service, operation string, now time.Time) error {
+	windowStart := now.Add(-d.options.Window)
+	current := &spanstore.LatencyQueryParameters{
+		ServiceName:   service,
+		OperationName: operation,
+		StartTimeMin:  windowStart,
+		StartTimeMax:  now,
+	}
+	baseline := &spanstore.LatencyQueryParameters{
+		ServiceName:   service,
+		OperationName: operation,
+		StartTimeMin:  windowStart.Add(-d.options.BaselineWindow),
+		StartTimeMax:  windowStart,
+	}
+	currentDist, err := d.querySvc.GetLatencyDistribution(ctx, current)
+	if err != nil {
+		return err
+	}
+	baselineDist, err := d.querySvc.GetLatencyDistribution(ctx, baseline)
+	if err != nil {
+		return err
+	}
+	if currentDist.Count < d.options.MinSpans || baselineDist.Count < d.options.MinSpans {
+		return nil
+	}
+	newAnnotation := func(kind Kind, baselineValue, currentValue float64) Annotation {
+		return Annotation{
+			ServiceName:   service,
+			OperationName: operation,
+			Kind:          kind,
+			DetectedAt:    now,
+			WindowStart:   windowStart,
+			WindowEnd:     now,
+			Baseline:      baselineValue,
+			Current:       currentValue,
+		}
+	}
+
+	currentP95 := float64(currentDist.P95.Microseconds())
+	baselineP95 := float64(baselineDist.P95.Microseconds())
+	if currentP95 > baselineP95*(1+d.options.LatencyThreshold) {
+		d.report(newAnnotation(KindLatency, baselineP95, currentP95))
+	}
+
+	current.Tags = errorTags
+	baseline.Tags = errorTags
+	currentErrors, err := d.querySvc.GetLatencyDistribution(ctx, current)
+	if err != nil {
+		return err
+	}
+	baselineErrors, err := d.querySvc.GetLatencyDistribution(ctx, baseline)
+	if err != nil {
+		return err
+	}
+	currentRate := float64(currentErrors.Count) / float64(currentDist.Count)
+	baselineRate := float64(baselineErrors.Count) / float64(baselineDist.Count)
+	if currentRate-baselineRate > d.options.ErrorRateThreshold {
+		d.report(newAnnotation(KindErrorRate, baselineRate, currentRate))
+	}
+	return nil
+}
+
+// report records the annotation unless the same regression was reported within the current window.
+func (d *Detector) report(annotation Annotation) {
+	key := annotationKey{service: annotation.ServiceName, operation: annotation.OperationName, kind: annotation.Kind}
+	if last, ok := d.lastDetected[key]; ok && last.After(annotation.WindowStart) {
+		return
+	}
+	d.lastDetected[key] = annotation.DetectedAt
+	d.logger.Info("Regression detected",
+		zap.String("service", annotation.ServiceName),
+		zap.String("operation", annotation.OperationName),
+		zap.String("kind", string(annotation.Kind)),
+		zap.Float64("baseline", annotation.Baseline),
+		zap.Float64("current", annotation.Current))
+	d.store.Add(annotation)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+type distributions struct {
+	current, baseline             *spanstore.LatencyDistribution
+	currentErrors, baselineErrors *spanstore.LatencyDistribution
+}
+
+type latencyReader struct {
+	spanstoremocks.Reader
+	operations map[string]distributions
+	err        error
+}
+
+func (r *latencyReader) GetLatencyDistribution(_ context.Context, query *spanstore.LatencyQueryParameters) (*spanstore.LatencyDistribution, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	dists := r.operations[query.OperationName]
+	isCurrent := query.StartTimeMax.Equal(testNow)
+	switch {
+	case isCurrent && query.Tags == nil:
+		return dists.current, nil
+	case isCurrent:
+		return dists.currentErrors, nil
+	case query.Tags == nil:
+		return dists.baseline, nil
+	default:
+		return dists.baselineErrors, nil
+	}
+}
+
+func newTestDetector(t *testing.T, reader *latencyReader) *Detector {
+	reader.On("GetServices", mock.Anything).Return([]string{"svc"}, nil)
+	var operations []spanstore.Operation
+	for name := range reader.operations {
+		operations = append(operations,
+			spanstore.Operation{Name: name, SpanKind: "server"},
+			spanstore.Operation{Name: name, SpanKind: "client"})
+	}
+	reader.On("GetOperations", mock.Anything, spanstore.OperationQueryParameters{ServiceName: "svc"}).Return(operations, nil)
+	qs := querysvc.NewQueryService(reader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	d := NewDetector(Options{
+		Interval:           time.Minute,
+		Window:             15 * time.Minute,
+		BaselineWindow:     time.Hour,
+		LatencyThreshold:   0.5,
+		ErrorRateThreshold: 0.05,
+		MinSpans:           10,
+		MaxAnnotations:     10,
+	}, qs, zap.NewNop())
+	d.timeNow = func() time.Time { return testNow }
+	t.Cleanup(func() { require.NoError(t, d.Close()) })
+	return d
+}
+
+func dist(count int64, p95 time.Duration) *spanstore.LatencyDistribution {
+	return &spanstore.LatencyDistribution{Count: count, P95: p95}
+}
+
+func TestDetectRegressions(t *testing.T) {
+	d := newTestDetector(t, &latencyReader{operations: map[string]distributions{
+		"slower": {
+			current:        dist(100, 300*time.Millisecond),
+			baseline:       dist(100, 100*time.Millisecond),
+			currentErrors:  dist(1, 0),
+			baselineErrors: dist(1, 0),
+		},
+		"failing": {
+			current:        dist(100, 100*time.Millisecond),
+			baseline:       dist(200, 100*time.Millisecond),
+			currentErrors:  dist(20, 0),
+			baselineErrors: dist(2, 0),
+		},
+		"stable": {
+			current:        dist(100, 120*time.Millisecond),
+			baseline:       dist(100, 100*time.Millisecond),
+			currentErrors:  dist(2, 0),
+			baselineErrors: dist(1, 0),
+		},
+		"rare": {
+			current:        dist(5, time.Second),
+			baseline:       dist(100, 100*time.Millisecond),
+			currentErrors:  dist(5, 0),
+			baselineErrors: dist(0, 0),
+		},
+	}})
+
+	d.detect(context.Background())
+	annotations := d.Store().Find(Query{})
+	assert.ElementsMatch(t, []Annotation{
+		{
+			ServiceName:   "svc",
+			OperationName: "slower",
+			Kind:          KindLatency,
+			DetectedAt:    testNow,
+			WindowStart:   testNow.Add(-15 * time.Minute),
+			WindowEnd:     testNow,
+			Baseline:      100000,
+			Current:       300000,
+		},
+		{
+			ServiceName:   "svc",
+			OperationName: "failing",
+			Kind:          KindErrorRate,
+			DetectedAt:    testNow,
+			WindowStart:   testNow.Add(-15 * time.Minute),
+			WindowEnd:     testNow,
+			Baseline:      0.01,
+			Current:       0.2,
+		},
+	}, annotations)
+
+	// the same regressions are not reported again within the window
+	d.timeNow = func() time.Time { return testNow.Add(5 * time.Minute) }
+	d.detect(context.Background())
+	assert.Len(t, d.Store().Find(Query{}), 2)
+}
+
+func TestDetectErrors(t *testing.T) {
+	logger, logBuffer := testutils.NewLogger()
+	reader := &latencyReader{
+		operations: map[string]distributions{"op": {}},
+		err:        errors.New("storage error"),
+	}
+	d := newTestDetector(t, reader)
+	d.logger = logger
+
+	d.detect(context.Background())
+	assert.Empty(t, d.Store().Find(Query{}))
+	assert.Contains(t, logBuffer.String(), "Failed to detect regressions")
+
+	failingReader := &spanstoremocks.Reader{}
+	failingReader.On("GetServices", mock.Anything).Return(nil, errors.New("storage error"))
+	d.querySvc = querysvc.NewQueryService(failingReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	d.detect(context.Background())
+	assert.Contains(t, logBuffer.String(), "Failed to get services for regression detection")
+
+	failingReader = &spanstoremocks.Reader{}
+	failingReader.On("GetServices", mock.Anything).Return([]string{"svc"}, nil)
+	failingReader.On("GetOperations", mock.Anything, mock.Anything).Return(nil, errors.New("storage error"))
+	d.querySvc = querysvc.NewQueryService(failingReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	d.detect(context.Background())
+	assert.Contains(t, logBuffer.String(), "Failed to get operations for regression detection")
+}
+
+func TestDetectorStartClose(t *testing.T) {
+	d := newTestDetector(t, &latencyReader{operations: map[string]distributions{
+		"slower": {
+			current:        dist(100, 300*time.Millisecond),
+			baseline:       dist(100, 100*time.Millisecond),
+			currentErrors:  dist(0, 0),
+			baselineErrors: dist(0, 0),
+		},
+	}})
+	d.options.Interval = time.Millisecond
+	d.Start()
+	assert.Eventually(t, func() bool {
+		return len(d.Store().Find(Query{})) == 1
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, d.Close())
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPrefix             = "query.regression-detection"
+	flagEnabled            = flagPrefix + ".enabled"
+	flagInterval           = flagPrefix + ".interval"
+	flagWindow             = flagPrefix + ".window"
+	flagBaselineWindow     = flagPrefix + ".baseline-window"
+	flagLatencyThreshold   = flagPrefix + ".latency-threshold"
+	flagErrorRateThreshold = flagPrefix + ".error-rate-threshold"
+	flagMinSpans           = flagPrefix + ".min-spans"
+	flagMaxAnnotations     = flagPrefix + ".max-annotations"
+
+	defaultInterval           = 5 * time.Minute
+	defaultWindow             = 15 * time.Minute
+	defaultBaselineWindow     = 24 * time.Hour
+	defaultLatencyThreshold   = 0.5
+	defaultErrorRateThreshold = 0.05
+	defaultMinSpans           = 20
+	defaultMaxAnnotations     = 1000
+)
+
+// Options holds configuration for the regression detection job.
+type Options struct {
+	// Enabled runs the regression detection job in the query service.
+	Enabled bool
+	// Interval is the time between two detection runs.
+	Interval time.Duration
+	// Window is the recent time range compared against the baseline.
+	Window time.Duration
+	// BaselineWindow is the time range preceding Window used as the baseline.
+	BaselineWindow time.Duration
+	// LatencyThreshold is the relative increase of the p95 latency reported as a regression.
+	LatencyThreshold float64
+	// ErrorRateThreshold is the absolute increase of the error rate reported as a regression.
+	ErrorRateThreshold float64
+	// MinSpans is the number of spans required in both windows to compare an operation.
+	MinSpans int64
+	// MaxAnnotations is the number of most recent regression annotations kept in memory.
+	MaxAnnotations int
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Periodically compare the latency and error rate of each operation against a trailing baseline and report regressions via /api/regressions")
+	flagSet.Duration(flagInterval, defaultInterval, "The interval between two regression detection runs")
+	flagSet.Duration(flagWindow, defaultWindow, "The recent time range compared against the baseline by the regression detection")
+	flagSet.Duration(flagBaselineWindow, defaultBaselineWindow, "The time range preceding the regression detection window used as the baseline")
+	flagSet.Float64(flagLatencyThreshold, defaultLatencyThreshold, "The relative increase of the p95 latency of an operation reported as a regression, e.g. 0.5 for +50%")
+	flagSet.Float64(flagErrorRateThreshold, defaultErrorRateThreshold, "The absolute increase of the error rate of an operation reported as a regression, e.g. 0.05 for +5 percentage points")
+	flagSet.Int64(flagMinSpans, defaultMinSpans, "The minimum number of spans of an operation in both windows for the regression detection to compare them")
+	flagSet.Int(flagMaxAnnotations, defaultMaxAnnotations, "The number of most recent regression annotations kept in memory")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.Interval = v.GetDuration(flagInterval)
+	o.Window = v.GetDuration(flagWindow)
+	o.BaselineWindow = v.GetDuration(flagBaselineWindow)
+	o.LatencyThreshold = v.GetFloat64(flagLatencyThreshold)
+	o.ErrorRateThreshold = v.GetFloat64(flagErrorRateThreshold)
+	o.MinSpans = v.GetInt64(flagMinSpans)
+	o.MaxAnnotations = v.GetInt(flagMaxAnnotations)
+	return o
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.regression-detection.enabled=true",
+		"--query.regression-detection.interval=1m",
+		"--query.regression-detection.window=10m",
+		"--query.regression-detection.baseline-window=2h",
+		"--query.regression-detection.latency-threshold=0.25",
+		"--query.regression-detection.error-rate-threshold=0.1",
+		"--query.regression-detection.min-spans=5",
+		"--query.regression-detection.max-annotations=50",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Enabled:            true,
+		Interval:           time.Minute,
+		Window:             10 * time.Minute,
+		BaselineWindow:     2 * time.Hour,
+		LatencyThreshold:   0.25,
+		ErrorRateThreshold: 0.1,
+		MinSpans:           5,
+		MaxAnnotations:     50,
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Equal(t, defaultInterval, opts.Interval)
+	assert.Equal(t, defaultWindow, opts.Window)
+	assert.Equal(t, defaultBaselineWindow, opts.BaselineWindow)
+	assert.Equal(t, int64(defaultMinSpans), opts.MinSpans)
+	assert.Equal(t, defaultMaxAnnotations, opts.MaxAnnotations)
+}
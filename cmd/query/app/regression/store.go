@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"sync"
+	"time"
+)
+
+// Kind is the metric in which a regression was detected.
+type Kind string
+
+const (
+	// KindLatency is an increase of the p95 latency of an operation.
+	KindLatency Kind = "latency"
+	// KindErrorRate is an increase of the ratio of spans with the error tag.
+	KindErrorRate Kind = "error_rate"
+)
+
+// Annotation records a regression of an operation against its baseline.
+type Annotation struct {
+	ServiceName   string    `json:"serviceName"`
+	OperationName string    `json:"operationName"`
+	Kind          Kind      `json:"kind"`
+	DetectedAt    time.Time `json:"detectedAt"`
+	WindowStart   time.Time `json:"windowStart"`
+	WindowEnd     time.Time `json:"windowEnd"`
+	// Baseline and Current are p95 latencies in microseconds for KindLatency,
+	// and error rates between 0 and 1 for KindErrorRate.
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+// Query contains parameters to find annotations, empty fields match all annotations.
+type Query struct {
+	ServiceName   string
+	OperationName string
+	StartTime     time.Time
+	EndTime       time.Time
+}
+
+// Store keeps the most recent annotations in memory.
+type Store struct {
+	sync.RWMutex
+
+	annotations []Annotation
+	maxSize     int
+}
+
+// NewStore creates a Store keeping up to maxSize annotations.
+func NewStore(maxSize int) *Store {
+	return &Store{maxSize: maxSize}
+}
+
+// Add records an annotation, evicting the oldest one if the store is full.
+func (s *Store) Add(annotation Annotation) {
+	s.Lock()
+	defer s.Unlock()
+	s.annotations = append(s.annotations, annotation)
+	if over := len(s.annotations) - s.maxSize; over > 0 {
+		s.annotations = append(s.annotations[:0], s.annotations[over:]...)
+	}
+}
+
+// Find returns the annotations matching the query, most recent first.
+func (s *Store) Find(query Query) []Annotation {
+	s.RLock()
+	defer s.RUnlock()
+	result := []Annotation{}
+	for i := len(s.annotations) - 1; i >= 0; i-- {
+		a := s.annotations[i]
+		if query.ServiceName != "" && a.ServiceName != query.ServiceName {
+			continue
+		}
+		if query.OperationName != "" && a.OperationName != query.OperationName {
+			continue
+		}
+		if !query.StartTime.IsZero() && a.DetectedAt.Before(query.StartTime) {
+			continue
+		}
+		if !query.EndTime.IsZero() && a.DetectedAt.After(query.EndTime) {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
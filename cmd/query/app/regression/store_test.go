@@ -0,0 +1,39 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(3)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, op := range []string{"a", "b", "c", "d"} {
+		s.Add(Annotation{
+			ServiceName:   "svc",
+			OperationName: op,
+			Kind:          KindLatency,
+			DetectedAt:    base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	operations := func(annotations []Annotation) []string {
+		var ops []string
+		for _, a := range annotations {
+			ops = append(ops, a.OperationName)
+		}
+		return ops
+	}
+
+	assert.Equal(t, []string{"d", "c", "b"}, operations(s.Find(Query{})))
+	assert.Equal(t, []string{"c"}, operations(s.Find(Query{ServiceName: "svc", OperationName: "c"})))
+	assert.Empty(t, s.Find(Query{ServiceName: "other"}))
+	assert.Equal(t, []string{"c", "b"}, operations(s.Find(Query{
+		StartTime: base.Add(time.Minute),
+		EndTime:   base.Add(2 * time.Minute),
+	})))
+}
@@ -24,7 +24,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gorilla/handlers"
 	"github.com/soheilhy/cmux"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -34,11 +33,21 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/alerting"
 	"github.com/jaegertracing/jaeger/cmd/query/app/apiv3"
+	"github.com/jaegertracing/jaeger/cmd/query/app/audit"
+	"github.com/jaegertracing/jaeger/cmd/query/app/export"
+	"github.com/jaegertracing/jaeger/cmd/query/app/graphqlapi"
 	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
+	"github.com/jaegertracing/jaeger/cmd/query/app/livetail"
+	"github.com/jaegertracing/jaeger/cmd/query/app/logs"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
+	"github.com/jaegertracing/jaeger/cmd/query/app/sharing"
+	"github.com/jaegertracing/jaeger/cmd/query/app/slo"
 	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/http3server"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
@@ -62,17 +71,22 @@ type Server struct {
 	cmuxServer    cmux.CMux
 	grpcServer    *grpc.Server
 	httpServer    *httpServer
+	http3Server   *http3server.Server
 	separatePorts bool
 	bgFinished    sync.WaitGroup
+	detector      *regression.Detector
+	alerting      *alerting.Manager
+	exports       *export.Scheduler
+	auditLogger   *audit.Logger
 }
 
 // NewServer creates and initializes Server
 func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, options *QueryOptions, tm *tenancy.Manager, tracer *jtracer.JTracer) (*Server, error) {
-	_, httpPort, err := net.SplitHostPort(options.HTTPHostPort)
+	httpPort, err := listenPort(options.HTTPHostPort)
 	if err != nil {
 		return nil, fmt.Errorf("invalid HTTP server host:port: %w", err)
 	}
-	_, grpcPort, err := net.SplitHostPort(options.GRPCHostPort)
+	grpcPort, err := listenPort(options.GRPCHostPort)
 	if err != nil {
 		return nil, fmt.Errorf("invalid gRPC server host:port: %w", err)
 	}
@@ -81,12 +95,90 @@ func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, querySv
 		return nil, errors.New("server with TLS enabled can not use same host ports for gRPC and HTTP.  Use dedicated HTTP and gRPC host ports instead")
 	}
 
-	grpcServer, err := createGRPCServer(querySvc, metricsQuerySvc, options, tm, logger, tracer)
+	var auditLogger *audit.Logger
+	if options.Audit.Enabled {
+		var tenancyHeader string
+		if tm.Enabled {
+			tenancyHeader = tm.Header
+		}
+		auditLogger, err = audit.NewLogger(options.Audit, tenancyHeader, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the audit log: %w", err)
+		}
+	}
+
+	var traceSharing *sharing.Signer
+	if options.TraceSharing.Enabled {
+		traceSharing, err = sharing.NewSigner(options.TraceSharing, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the trace share tokens signer: %w", err)
+		}
+	}
+
+	var logCorrelator *logs.Correlator
+	if options.Logs.Backend != "" {
+		logCorrelator, err = logs.NewCorrelator(options.Logs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the log correlator: %w", err)
+		}
+	}
+
+	var exporter *export.Exporter
+	if options.Export.Enabled {
+		exporter, err = export.NewExporter(options.Export, querySvc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the trace exporter: %w", err)
+		}
+	}
+
+	claims, err := newClaimsReader(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the JWT verifier: %w", err)
+	}
+
+	grpcServer, err := createGRPCServer(querySvc, metricsQuerySvc, auditLogger, claims, options, tm, logger, tracer)
 	if err != nil {
 		return nil, err
 	}
 
-	httpServer, err := createHTTPServer(querySvc, metricsQuerySvc, options, tm, tracer, logger)
+	var detector *regression.Detector
+	if options.RegressionDetection.Enabled {
+		// the detector runs for all the callers, its annotations are filtered when queried
+		detector = regression.NewDetector(options.RegressionDetection, querySvc.WithoutAuthorization(), logger)
+	}
+
+	var alertingManager *alerting.Manager
+	if options.Alerting.Enabled {
+		// the rules are evaluated for all the callers, they are filtered when queried
+		alertingManager, err = alerting.NewManager(options.Alerting, querySvc.WithoutAuthorization(), metricsQuerySvc, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the alerting rules manager: %w", err)
+		}
+	}
+
+	var sloTracker *slo.Tracker
+	if options.SLOs.Enabled {
+		// the SLOs are computed on the requests, for the services of the callers
+		sloTracker, err = slo.NewTracker(options.SLOs, querySvc, metricsQuerySvc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the SLO tracker: %w", err)
+		}
+	}
+
+	var exportScheduler *export.Scheduler
+	if options.Export.Schedule.Enabled {
+		exportScheduler, err = export.NewScheduler(options.Export.Schedule, querySvc.WithoutAuthorization(), logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the scheduled trace exports: %w", err)
+		}
+	}
+
+	var liveTailer *livetail.Tailer
+	if options.LiveTail.Enabled {
+		liveTailer = livetail.NewTailer(options.LiveTail, querySvc, options.TraceBroadcaster, logger)
+	}
+
+	httpServer, err := createHTTPServer(querySvc, metricsQuerySvc, detector, alertingManager, sloTracker, auditLogger, traceSharing, logCorrelator, exporter, liveTailer, claims, options, tm, tracer, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -100,10 +192,14 @@ func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, querySv
 		grpcServer:    grpcServer,
 		httpServer:    httpServer,
 		separatePorts: grpcPort != httpPort,
+		detector:      detector,
+		alerting:      alertingManager,
+		exports:       exportScheduler,
+		auditLogger:   auditLogger,
 	}, nil
 }
 
-func createGRPCServer(querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, options *QueryOptions, tm *tenancy.Manager, logger *zap.Logger, tracer *jtracer.JTracer) (*grpc.Server, error) {
+func createGRPCServer(querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, auditLogger *audit.Logger, claims *claimsReader, options *QueryOptions, tm *tenancy.Manager, logger *zap.Logger, tracer *jtracer.JTracer) (*grpc.Server, error) {
 	var grpcOpts []grpc.ServerOption
 
 	if options.TLSGRPC.Enabled {
@@ -116,12 +212,26 @@ func createGRPCServer(querySvc *querysvc.QueryService, metricsQuerySvc querysvc.
 
 		grpcOpts = append(grpcOpts, grpc.Creds(creds))
 	}
+	// the claims are read first, the audit log recording the subjects of the verified claims
+	unaryInterceptors := []grpc.UnaryServerInterceptor{claims.unaryInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{claims.streamInterceptor()}
+	if auditLogger != nil {
+		// record the calls rejected by the tenancy guard too
+		unaryInterceptors = append(unaryInterceptors, auditLogger.NewUnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, auditLogger.NewStreamInterceptor())
+	}
 	if tm.Enabled {
-		grpcOpts = append(grpcOpts,
-			grpc.StreamInterceptor(tenancy.NewGuardingStreamInterceptor(tm)),
-			grpc.UnaryInterceptor(tenancy.NewGuardingUnaryInterceptor(tm)),
-		)
+		unaryInterceptors = append(unaryInterceptors, tenancy.NewGuardingUnaryInterceptor(tm))
+		streamInterceptors = append(streamInterceptors, tenancy.NewGuardingStreamInterceptor(tm))
+	}
+	if options.BearerTokenPropagation {
+		unaryInterceptors = append(unaryInterceptors, bearertoken.NewUnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, bearertoken.NewStreamServerInterceptor())
 	}
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
 
 	server := grpc.NewServer(grpcOpts...)
 	reflection.Register(server)
@@ -154,6 +264,15 @@ var _ io.Closer = (*httpServer)(nil)
 func createHTTPServer(
 	querySvc *querysvc.QueryService,
 	metricsQuerySvc querysvc.MetricsQueryService,
+	detector *regression.Detector,
+	alertingManager *alerting.Manager,
+	sloTracker *slo.Tracker,
+	auditLogger *audit.Logger,
+	traceSharing *sharing.Signer,
+	logCorrelator *logs.Correlator,
+	exporter *export.Exporter,
+	liveTailer *livetail.Tailer,
+	claims *claimsReader,
 	queryOpts *QueryOptions,
 	tm *tenancy.Manager,
 	tracer *jtracer.JTracer,
@@ -164,6 +283,30 @@ func createHTTPServer(
 		HandlerOptions.Tracer(tracer),
 		HandlerOptions.MetricsQueryService(metricsQuerySvc),
 	}
+	if detector != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.Regressions(detector.Store()))
+	}
+	if alertingManager != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.AlertingRules(alertingManager))
+	}
+	if sloTracker != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.SLOs(sloTracker))
+	}
+	if traceSharing != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.TraceSharing(traceSharing))
+	}
+	if logCorrelator != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.LogCorrelator(logCorrelator))
+	}
+	if exporter != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.TraceExporter(exporter))
+	}
+	if liveTailer != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.LiveTail(liveTailer))
+	}
+	if queryOpts.SamplingAdminToken != "" {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.SamplingAdminToken(queryOpts.SamplingAdminToken))
+	}
 
 	apiHandler := NewAPIHandler(
 		querySvc,
@@ -173,6 +316,9 @@ func createHTTPServer(
 	if queryOpts.BasePath != "/" {
 		r = r.PathPrefix(queryOpts.BasePath).Subrouter()
 	}
+	if auditLogger != nil {
+		r.Use(auditLogger.Middleware)
+	}
 
 	(&apiv3.HTTPGateway{
 		QueryService: querySvc,
@@ -182,12 +328,30 @@ func createHTTPServer(
 	}).RegisterRoutes(r)
 
 	apiHandler.RegisterRoutes(r)
+	if queryOpts.GraphQL.Enabled {
+		graphqlHandler, err := graphqlapi.NewHandler(queryOpts.GraphQL, querySvc, tm, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the GraphQL API: %w", err)
+		}
+		graphqlHandler.RegisterRoutes(r)
+	}
 	var handler http.Handler = r
 	handler = additionalHeadersHandler(handler, queryOpts.AdditionalHeaders)
 	if queryOpts.BearerTokenPropagation {
 		handler = bearertoken.PropagationHandler(logger, handler)
 	}
-	handler = handlers.CompressHandler(handler)
+	// the claims identify the callers authorized by the rules, and the owners of the saved searches
+	handler = claims.handler(handler)
+	if err := validateCompressionEncodings(queryOpts.Compression); err != nil {
+		return nil, err
+	}
+	handler = compressionHandler(handler, queryOpts.Compression)
+	handler = allowedHostsHandler(handler, queryOpts.AllowedHosts)
+	trustedProxies, err := parseTrustedProxies(queryOpts.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	handler = forwardedHeadersHandler(handler, trustedProxies)
 	recoveryHandler := recoveryhandler.NewRecoveryHandler(logger, true)
 
 	errorLog, _ := zap.NewStdLogAt(logger, zapcore.ErrorLevel)
@@ -219,16 +383,26 @@ func (hS httpServer) Close() error {
 	return errors.Join(errs...)
 }
 
+// listenPort returns the port of the host:port address, or the unix:// address of a Unix domain
+// socket; the HTTP and gRPC servers share a listener if they have the same.
+func listenPort(address string) (string, error) {
+	if _, ok := netutils.UnixSocketPath(address); ok {
+		return address, nil
+	}
+	_, port, err := net.SplitHostPort(address)
+	return port, err
+}
+
 // initListener initialises listeners of the server
 func (s *Server) initListener() (cmux.CMux, error) {
 	if s.separatePorts { // use separate ports and listeners each for gRPC and HTTP requests
 		var err error
-		s.grpcConn, err = net.Listen("tcp", s.queryOptions.GRPCHostPort)
+		s.grpcConn, err = netutils.Listen(s.queryOptions.GRPCNetwork, s.queryOptions.GRPCHostPort, s.queryOptions.GRPCSocketPermissions)
 		if err != nil {
 			return nil, err
 		}
 
-		s.httpConn, err = net.Listen("tcp", s.queryOptions.HTTPHostPort)
+		s.httpConn, err = netutils.Listen(s.queryOptions.HTTPNetwork, s.queryOptions.HTTPHostPort, s.queryOptions.HTTPSocketPermissions)
 		if err != nil {
 			return nil, err
 		}
@@ -241,7 +415,7 @@ func (s *Server) initListener() (cmux.CMux, error) {
 	}
 
 	//  old behavior using cmux
-	conn, err := net.Listen("tcp", s.queryOptions.HTTPHostPort)
+	conn, err := netutils.Listen(s.queryOptions.HTTPNetwork, s.queryOptions.HTTPHostPort, s.queryOptions.HTTPSocketPermissions)
 	if err != nil {
 		return nil, err
 	}
@@ -278,6 +452,14 @@ func (s *Server) Start() error {
 	}
 	s.cmuxServer = cmuxServer
 
+	if s.queryOptions.HTTP3HostPort != "" {
+		s.http3Server, err = http3server.Listen(s.queryOptions.HTTPNetwork, s.queryOptions.HTTP3HostPort, s.httpServer.TLSConfig, s.httpServer.Handler)
+		if err != nil {
+			return fmt.Errorf("query server failed to listen on the HTTP/3 port: %w", err)
+		}
+		s.httpServer.Handler = s.http3Server.AltSvcHandler(s.httpServer.Handler)
+	}
+
 	var tcpPort int
 	if !s.separatePorts {
 		if port, err := netutils.GetPort(s.conn.Addr()); err == nil {
@@ -312,6 +494,18 @@ func (s *Server) Start() error {
 		s.bgFinished.Done()
 	}()
 
+	if s.http3Server != nil {
+		s.bgFinished.Add(1)
+		go func() {
+			s.logger.Info("Starting HTTP/3 server", zap.Stringer("addr", s.http3Server.Addr()))
+			if err := s.http3Server.Serve(); err != nil {
+				s.logger.Error("Could not start HTTP/3 server", zap.Error(err))
+			}
+			s.logger.Info("HTTP/3 server stopped", zap.Stringer("addr", s.http3Server.Addr()))
+			s.bgFinished.Done()
+		}()
+	}
+
 	// Start GRPC server concurrently
 	s.bgFinished.Add(1)
 	go func() {
@@ -326,6 +520,21 @@ func (s *Server) Start() error {
 		s.bgFinished.Done()
 	}()
 
+	if s.detector != nil {
+		s.logger.Info("Starting regression detection", zap.Duration("interval", s.queryOptions.RegressionDetection.Interval))
+		s.detector.Start()
+	}
+
+	if s.alerting != nil {
+		s.logger.Info("Starting alerting rules evaluation", zap.Duration("interval", s.queryOptions.Alerting.Interval))
+		s.alerting.Start()
+	}
+
+	if s.exports != nil {
+		s.logger.Info("Starting scheduled trace exports", zap.Duration("interval", s.queryOptions.Export.Schedule.Interval))
+		s.exports.Start()
+	}
+
 	// Start cmux server concurrently.
 	if !s.separatePorts {
 		s.bgFinished.Add(1)
@@ -353,11 +562,33 @@ func (s *Server) Close() error {
 		s.queryOptions.TLSHTTP.Close(),
 	}
 
+	if s.detector != nil {
+		s.logger.Info("Stopping regression detection")
+		errs = append(errs, s.detector.Close())
+	}
+
+	if s.alerting != nil {
+		s.logger.Info("Stopping alerting rules evaluation")
+		errs = append(errs, s.alerting.Close())
+	}
+
+	if s.exports != nil {
+		s.logger.Info("Stopping scheduled trace exports")
+		errs = append(errs, s.exports.Close())
+	}
+
 	s.logger.Info("Closing HTTP server")
 	if err := s.httpServer.Close(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to close HTTP server: %w", err))
 	}
 
+	if s.http3Server != nil {
+		s.logger.Info("Closing HTTP/3 server")
+		if err := s.http3Server.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close HTTP/3 server: %w", err))
+		}
+	}
+
 	s.logger.Info("Stopping gRPC server")
 	s.grpcServer.Stop()
 
@@ -367,6 +598,10 @@ func (s *Server) Close() error {
 	}
 
 	s.bgFinished.Wait()
+	if s.auditLogger != nil {
+		s.logger.Info("Closing audit log")
+		errs = append(errs, s.auditLogger.Close())
+	}
 	s.logger.Info("Server stopped")
 	return errors.Join(errs...)
 }
@@ -17,12 +17,18 @@ package app
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -34,7 +40,15 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
+	"github.com/jaegertracing/jaeger/cmd/query/app/alerting"
+	"github.com/jaegertracing/jaeger/cmd/query/app/audit"
+	"github.com/jaegertracing/jaeger/cmd/query/app/export"
+	"github.com/jaegertracing/jaeger/cmd/query/app/graphqlapi"
+	"github.com/jaegertracing/jaeger/cmd/query/app/logs"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/cmd/query/app/regression"
+	"github.com/jaegertracing/jaeger/cmd/query/app/sharing"
+	"github.com/jaegertracing/jaeger/cmd/query/app/slo"
 	"github.com/jaegertracing/jaeger/internal/grpctest"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
@@ -561,6 +575,23 @@ func TestServerBadHostPort(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestServerBadReverseProxyOptions(t *testing.T) {
+	for _, base := range []QueryOptionsBase{
+		{TrustedProxies: []string{"proxy"}},
+		{Compression: []string{"zstd"}},
+	} {
+		_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+			&QueryOptions{
+				HTTPHostPort:     ":8080",
+				GRPCHostPort:     ":8081",
+				QueryOptionsBase: base,
+			},
+			tenancy.NewManager(&tenancy.Options{}),
+			jtracer.NoOp())
+		require.Error(t, err)
+	}
+}
+
 func TestServerInUseHostPort(t *testing.T) {
 	const availableHostPort = "127.0.0.1:0"
 	conn, err := net.Listen("tcp", availableHostPort)
@@ -634,6 +665,100 @@ func TestServerSinglePort(t *testing.T) {
 	assert.Equal(t, querySvc.expectedServices, res.Services)
 }
 
+func TestServerUnixSockets(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dir := t.TempDir()
+	httpSocket := filepath.Join(dir, "query-http.sock")
+	grpcSocket := filepath.Join(dir, "query-grpc.sock")
+	querySvc := makeQuerySvc()
+	server, err := NewServer(logger, healthcheck.New(), querySvc.qs, nil,
+		&QueryOptions{
+			HTTPHostPort:          "unix://" + httpSocket,
+			HTTPSocketPermissions: 0o660,
+			GRPCHostPort:          "unix://" + grpcSocket,
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	info, err := os.Stat(httpSocket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	client := newGRPCClient(t, "unix://"+grpcSocket)
+	t.Cleanup(func() {
+		require.NoError(t, client.conn.Close())
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, querySvc.expectedServices, res.Services)
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", httpSocket)
+			},
+		},
+	}
+	resp, err := httpClient.Get("http://localhost/api/services")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServerHTTP3(t *testing.T) {
+	querySvc := makeQuerySvc()
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc.qs, nil,
+		&QueryOptions{
+			HTTPHostPort:  "127.0.0.1:0",
+			GRPCHostPort:  "unix://" + filepath.Join(t.TempDir(), "query-grpc.sock"),
+			HTTPNetwork:   "tcp4",
+			HTTP3HostPort: "127.0.0.1:0",
+			TLSHTTP: tlscfg.Options{
+				Enabled:  true,
+				CertPath: testCertKeyLocation + "/example-server-cert.pem",
+				KeyPath:  testCertKeyLocation + "/example-server-key.pem",
+			},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	clientTLS := tlscfg.Options{
+		Enabled:    true,
+		CAPath:     testCertKeyLocation + "/example-CA-cert.pem",
+		ServerName: "example.com",
+	}
+	tlsCfg, err := clientTLS.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer clientTLS.Close()
+
+	httpsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+	resp, err := httpsClient.Get("https://" + server.httpConn.Addr().String() + "/api/services")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Alt-Svc"), "h3=")
+
+	roundTripper := &http3.RoundTripper{TLSClientConfig: tlsCfg}
+	defer roundTripper.Close()
+	resp, err = (&http.Client{Transport: roundTripper}).Get("https://" + server.http3Server.Addr().String() + "/api/services")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "HTTP/3.0", resp.Proto)
+}
+
 func TestServerGracefulExit(t *testing.T) {
 	flagsSvc := flags.NewService(ports.QueryAdminHTTP)
 
@@ -704,6 +829,213 @@ func TestServerHandlesPortZero(t *testing.T) {
 	}.Execute(t)
 }
 
+func TestServerRegressionDetection(t *testing.T) {
+	zapCore, logs := observer.New(zap.InfoLevel)
+	server, err := NewServer(zap.New(zapCore), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			RegressionDetection: regression.Options{
+				Enabled:        true,
+				Interval:       time.Hour,
+				MaxAnnotations: 10,
+			},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NotNil(t, server.detector)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	assert.Equal(t, 1, logs.FilterMessage("Starting regression detection").Len())
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/regressions", server.httpConn.Addr().String()))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServerAuditLog(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	querySvc := makeQuerySvc()
+	querySvc.spanReader.On("GetServices", mock.Anything).Return([]string{"frontend"}, nil).Once()
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc.qs, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Audit: audit.Options{
+				Enabled:       true,
+				Exporter:      audit.FileExporter,
+				FilePath:      auditPath,
+				FileMaxSizeMB: 1,
+			},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/services", server.httpConn.Addr().String()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.NoError(t, server.Close())
+
+	content, err := os.ReadFile(auditPath)
+	require.NoError(t, err)
+	var event audit.Event
+	require.NoError(t, json.Unmarshal(content, &event))
+	assert.Equal(t, "GET /api/services", event.Endpoint)
+	assert.Equal(t, "200", event.Status)
+}
+
+func TestServerGraphQL(t *testing.T) {
+	querySvc := makeQuerySvc()
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc.qs, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			GraphQL:      graphqlapi.Options{Enabled: true, MaxTraces: 10},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	resp, err := http.Post(fmt.Sprintf("http://%s/api/graphql", server.httpConn.Addr().String()),
+		"application/graphql", strings.NewReader("{ services { name } }"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"services": [{"name": "test"}]}}`, string(body))
+}
+
+func TestServerAuditLogError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Audit:        audit.Options{Enabled: true, Exporter: audit.FileExporter},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the audit log")
+}
+
+func TestServerTraceSharingError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			TraceSharing: sharing.Options{Enabled: true, KeyFile: filepath.Join(t.TempDir(), "missing.key")},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the trace share tokens signer")
+}
+
+func TestServerLogCorrelatorError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Logs:         logs.Options{Backend: logs.BackendLoki},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the log correlator")
+}
+
+func TestServerTraceExporterError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Export:       export.Options{Enabled: true},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the trace exporter")
+}
+
+func TestServerExportSchedulerError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Export:       export.Options{Schedule: export.ScheduleOptions{Enabled: true}},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the scheduled trace exports")
+}
+
+func TestServerAlerting(t *testing.T) {
+	querySvc := makeQuerySvc()
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc.qs, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Alerting:     alerting.Options{Enabled: true, Interval: time.Minute, Source: alerting.SourceSpans},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/alerting/rules", server.httpConn.Addr().String()))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServerAlertingError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			Alerting:     alerting.Options{Enabled: true, Interval: time.Minute, Source: "logs"},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the alerting rules manager")
+}
+
+func TestServerSLOs(t *testing.T) {
+	querySvc := makeQuerySvc()
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc.qs, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			SLOs:         slo.Options{Enabled: true, Source: slo.SourceSpans},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/slos", server.httpConn.Addr().String()))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServerSLOsError(t *testing.T) {
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+		&QueryOptions{
+			GRPCHostPort: "127.0.0.1:0",
+			HTTPHostPort: "127.0.0.1:0",
+			SLOs:         slo.Options{Enabled: true, Source: "logs"},
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.ErrorContains(t, err, "failed to create the SLO tracker")
+}
+
 func TestServerHTTPTenancy(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -0,0 +1,52 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sharing
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPrefix     = "query.trace-sharing"
+	flagEnabled    = flagPrefix + ".enabled"
+	flagKeyFile    = flagPrefix + ".key-file"
+	flagDefaultTTL = flagPrefix + ".default-ttl"
+	flagMaxTTL     = flagPrefix + ".max-ttl"
+
+	defaultDefaultTTL = 24 * time.Hour
+	defaultMaxTTL     = 7 * 24 * time.Hour
+)
+
+// Options holds configuration for the share tokens of single traces.
+type Options struct {
+	// Enabled registers the API minting the share tokens and the route reading the shared traces.
+	Enabled bool
+	// KeyFile is the path to the secret key signing the tokens. A random key is
+	// generated if empty, the tokens are then only valid until the query service restarts.
+	KeyFile string
+	// DefaultTTL is the validity of the tokens minted without an explicit TTL.
+	DefaultTTL time.Duration
+	// MaxTTL is the longest validity of the tokens.
+	MaxTTL time.Duration
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Allow minting signed, expiring tokens granting read access to a single trace via /api/shared-traces/{token}")
+	flagSet.String(flagKeyFile, "", "The path to the secret key, of at least 32 bytes, signing the trace share tokens; a random key valid until restart is generated if empty")
+	flagSet.Duration(flagDefaultTTL, defaultDefaultTTL, "The validity of the trace share tokens minted without an explicit TTL")
+	flagSet.Duration(flagMaxTTL, defaultMaxTTL, "The longest validity of the trace share tokens")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.KeyFile = v.GetString(flagKeyFile)
+	o.DefaultTTL = v.GetDuration(flagDefaultTTL)
+	o.MaxTTL = v.GetDuration(flagMaxTTL)
+	return o
+}
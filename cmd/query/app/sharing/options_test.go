@@ -0,0 +1,41 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sharing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	err := command.ParseFlags([]string{
+		"--query.trace-sharing.enabled=true",
+		"--query.trace-sharing.key-file=/etc/jaeger/sharing.key",
+		"--query.trace-sharing.default-ttl=1h",
+		"--query.trace-sharing.max-ttl=48h",
+	})
+	require.NoError(t, err)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Enabled:    true,
+		KeyFile:    "/etc/jaeger/sharing.key",
+		DefaultTTL: time.Hour,
+		MaxTTL:     48 * time.Hour,
+	}, opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled)
+	assert.Empty(t, opts.KeyFile)
+	assert.Equal(t, defaultDefaultTTL, opts.DefaultTTL)
+	assert.Equal(t, defaultMaxTTL, opts.MaxTTL)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sharing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const minKeySize = 32
+
+var (
+	// ErrInvalidToken is returned for the tokens which were not minted by the Signer.
+	ErrInvalidToken = errors.New("invalid trace share token")
+	// ErrTokenExpired is returned for the tokens past their expiration.
+	ErrTokenExpired = errors.New("the trace share token has expired")
+)
+
+// Grant is the access to a single trace granted by a share token.
+type Grant struct {
+	TraceID model.TraceID
+	// Tenant is the tenant of the trace, empty without tenancy.
+	Tenant    string
+	ExpiresAt time.Time
+}
+
+type tokenPayload struct {
+	TraceID   string `json:"trace"`
+	Tenant    string `json:"tenant,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer mints and verifies the share tokens, made of a payload naming the trace
+// and the expiration, and of its HMAC-SHA256 signature.
+type Signer struct {
+	key        []byte
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	timeNow    func() time.Time
+}
+
+// NewSigner creates a Signer with the key read from the options' key file, or
+// else a random key.
+func NewSigner(options Options, logger *zap.Logger) (*Signer, error) {
+	var key []byte
+	if options.KeyFile != "" {
+		data, err := os.ReadFile(options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the trace sharing key: %w", err)
+		}
+		key = bytes.TrimSpace(data)
+		if len(key) < minKeySize {
+			return nil, fmt.Errorf("the trace sharing key must have at least %d bytes", minKeySize)
+		}
+	} else {
+		logger.Warn("No trace sharing key file, the share tokens will be invalid after a restart and across replicas")
+		key = make([]byte, minKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate the trace sharing key: %w", err)
+		}
+	}
+	if options.DefaultTTL <= 0 || options.MaxTTL < options.DefaultTTL {
+		return nil, errors.New("the default TTL of the trace share tokens must be positive and not exceed the max TTL")
+	}
+	return &Signer{
+		key:        key,
+		defaultTTL: options.DefaultTTL,
+		maxTTL:     options.MaxTTL,
+		timeNow:    time.Now,
+	}, nil
+}
+
+// Sign mints a token granting access to the trace for the ttl, or the default TTL if zero.
+func (s *Signer) Sign(traceID model.TraceID, tenant string, ttl time.Duration) (string, Grant, error) {
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return "", Grant{}, fmt.Errorf("the TTL of the trace share token must be positive and at most %v", s.maxTTL)
+	}
+	grant := Grant{
+		TraceID:   traceID,
+		Tenant:    tenant,
+		ExpiresAt: s.timeNow().Add(ttl).Truncate(time.Second),
+	}
+	payload, err := json.Marshal(tokenPayload{
+		TraceID:   traceID.String(),
+		Tenant:    tenant,
+		ExpiresAt: grant.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", Grant{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), grant, nil
+}
+
+// Verify checks the signature and the expiration of the token and returns its grant.
+func (s *Signer) Verify(token string) (Grant, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Grant{}, ErrInvalidToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, s.sign(encoded)) {
+		return Grant{}, ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Grant{}, ErrInvalidToken
+	}
+	var payload tokenPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return Grant{}, ErrInvalidToken
+	}
+	traceID, err := model.TraceIDFromString(payload.TraceID)
+	if err != nil {
+		return Grant{}, ErrInvalidToken
+	}
+	grant := Grant{
+		TraceID:   traceID,
+		Tenant:    payload.Tenant,
+		ExpiresAt: time.Unix(payload.ExpiresAt, 0),
+	}
+	if !s.timeNow().Before(grant.ExpiresAt) {
+		return Grant{}, ErrTokenExpired
+	}
+	return grant, nil
+}
+
+func (s *Signer) sign(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
+
+type grantContextKey struct{}
+
+// ContextWithGrant returns a context with the grant of the verified share token of the request.
+func ContextWithGrant(ctx context.Context, grant Grant) context.Context {
+	return context.WithValue(ctx, grantContextKey{}, grant)
+}
+
+// GrantFromContext returns the grant of the verified share token of the request, if any.
+func GrantFromContext(ctx context.Context) (Grant, bool) {
+	grant, ok := ctx.Value(grantContextKey{}).(Grant)
+	return grant, ok
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sharing
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var testOptions = Options{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour}
+
+func newTestSigner(t *testing.T, now time.Time) *Signer {
+	signer, err := NewSigner(testOptions, zap.NewNop())
+	require.NoError(t, err)
+	signer.timeNow = func() time.Time { return now }
+	return signer
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := newTestSigner(t, now)
+	traceID := model.NewTraceID(1, 2)
+
+	token, grant, err := signer.Sign(traceID, "acme", 0)
+	require.NoError(t, err)
+	assert.Equal(t, Grant{TraceID: traceID, Tenant: "acme", ExpiresAt: now.Add(time.Hour)}, grant)
+
+	verified, err := signer.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, grant, verified)
+
+	signer.timeNow = func() time.Time { return now.Add(time.Hour) }
+	_, err = signer.Verify(token)
+	require.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestSignTTL(t *testing.T) {
+	signer := newTestSigner(t, time.Unix(1700000000, 0))
+	_, grant, err := signer.Sign(model.NewTraceID(0, 1), "", 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).Add(2*time.Hour), grant.ExpiresAt)
+
+	for _, ttl := range []time.Duration{-time.Second, 25 * time.Hour} {
+		_, _, err := signer.Sign(model.NewTraceID(0, 1), "", ttl)
+		require.ErrorContains(t, err, "must be positive and at most 24h0m0s")
+	}
+}
+
+func TestVerifyInvalidTokens(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := newTestSigner(t, now)
+	token, _, err := signer.Sign(model.NewTraceID(0, 1), "", 0)
+	require.NoError(t, err)
+	payload, signature, _ := strings.Cut(token, ".")
+
+	otherTrace, _, err := signer.Sign(model.NewTraceID(0, 2), "", 0)
+	require.NoError(t, err)
+	otherPayload, _, _ := strings.Cut(otherTrace, ".")
+	otherKey, _, err := newTestSigner(t, now).Sign(model.NewTraceID(0, 1), "", 0)
+	require.NoError(t, err)
+	signed := func(payload string) string {
+		return payload + "." + base64.RawURLEncoding.EncodeToString(signer.sign(payload))
+	}
+
+	for _, token := range []string{
+		"",
+		payload,
+		payload + ".!!!",
+		otherPayload + "." + signature,
+		otherKey,
+		signed("!!!"),
+		signed(base64.RawURLEncoding.EncodeToString([]byte("not json"))),
+		signed(base64.RawURLEncoding.EncodeToString([]byte(`{"trace":"xyz","exp":1800000000}`))),
+	} {
+		_, err := signer.Verify(token)
+		require.ErrorIs(t, err, ErrInvalidToken, token)
+	}
+}
+
+func TestNewSignerKeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "sharing.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(strings.Repeat("k", minKeySize)+"\n"), 0o600))
+	options := testOptions
+	options.KeyFile = keyFile
+
+	signer1, err := NewSigner(options, zap.NewNop())
+	require.NoError(t, err)
+	signer2, err := NewSigner(options, zap.NewNop())
+	require.NoError(t, err)
+	token, _, err := signer1.Sign(model.NewTraceID(0, 1), "", 0)
+	require.NoError(t, err)
+	_, err = signer2.Verify(token)
+	require.NoError(t, err, "the signers with the same key accept the tokens of each other")
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	shortKeyFile := filepath.Join(t.TempDir(), "short.key")
+	require.NoError(t, os.WriteFile(shortKeyFile, []byte("short"), 0o600))
+	tests := []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{name: "missing key file", options: Options{KeyFile: filepath.Join(t.TempDir(), "missing"), DefaultTTL: time.Hour, MaxTTL: time.Hour}, err: "failed to read the trace sharing key"},
+		{name: "short key", options: Options{KeyFile: shortKeyFile, DefaultTTL: time.Hour, MaxTTL: time.Hour}, err: "at least 32 bytes"},
+		{name: "no default TTL", options: Options{MaxTTL: time.Hour}, err: "must be positive"},
+		{name: "default TTL above max", options: Options{DefaultTTL: 2 * time.Hour, MaxTTL: time.Hour}, err: "not exceed the max TTL"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewSigner(test.options, zap.NewNop())
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestGrantContext(t *testing.T) {
+	_, ok := GrantFromContext(context.Background())
+	assert.False(t, ok)
+	grant := Grant{TraceID: model.NewTraceID(0, 1)}
+	actual, ok := GrantFromContext(ContextWithGrant(context.Background(), grant))
+	assert.True(t, ok)
+	assert.Equal(t, grant, actual)
+}
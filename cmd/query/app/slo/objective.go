@@ -0,0 +1,126 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrInvalidObjective is wrapped by the errors of the validation of the SLOs.
+var ErrInvalidObjective = errors.New("invalid SLO")
+
+// objectiveName restricts the names of the SLOs, which are part of their URLs.
+var objectiveName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Type is the kind of the events counted as bad by an SLO.
+type Type string
+
+const (
+	// TypeAvailability counts the spans with an error as bad events.
+	TypeAvailability Type = "availability"
+	// TypeLatency counts the spans not faster than the latency threshold as bad events.
+	TypeLatency Type = "latency"
+)
+
+const defaultPeriod = 30 * 24 * time.Hour
+
+// defaultBurnRateWindows are the windows of the fast and slow burn rates of the multiwindow alerts,
+// see https://sre.google/workbook/alerting-on-slos/.
+var defaultBurnRateWindows = []string{"1h", "6h", "72h"}
+
+// SLO is a Service Level Objective of a service, optionally restricted to one of its operations,
+// e.g. 99.9% of the spans of the operation checkout of the service frontend faster than 300ms over 30 days.
+type SLO struct {
+	Name      string `json:"name"`
+	Service   string `json:"service"`
+	Operation string `json:"operation,omitempty"`
+	Type      Type   `json:"type"`
+	// Objective is the target ratio of good events, between 0 and 1 exclusive, e.g. 0.999.
+	Objective float64 `json:"objective"`
+	// LatencyThreshold is the duration in milliseconds under which the spans are good events,
+	// for the latency SLOs.
+	LatencyThreshold float64 `json:"latencyThreshold,omitempty"`
+	// Period is the rolling time range of the compliance and the error budget, 720h if empty.
+	Period string `json:"period,omitempty"`
+	// BurnRateWindows are the time ranges of the burn rates, 1h, 6h and 72h if empty.
+	BurnRateWindows []string `json:"burnRateWindows,omitempty"`
+
+	period          time.Duration
+	burnRateWindows []time.Duration
+}
+
+// init validates the SLO, sets its defaults and parses its durations.
+func (s *SLO) init() error {
+	if err := s.validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidObjective, err)
+	}
+	return nil
+}
+
+func (s *SLO) validate() error {
+	if !objectiveName.MatchString(s.Name) {
+		return fmt.Errorf("the name %q must only contain letters, digits, '_', '.' and '-'", s.Name)
+	}
+	if s.Service == "" {
+		return errors.New("the service is required")
+	}
+	switch s.Type {
+	case TypeAvailability:
+		if s.LatencyThreshold != 0 {
+			return fmt.Errorf("the latency threshold only applies to the %s SLOs", TypeLatency)
+		}
+	case TypeLatency:
+		if s.LatencyThreshold <= 0 {
+			return fmt.Errorf("the latency threshold of the %s SLOs must be positive", TypeLatency)
+		}
+	default:
+		return fmt.Errorf("unknown type %q, must be %s or %s", s.Type, TypeAvailability, TypeLatency)
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("the objective %v must be between 0 and 1 exclusive", s.Objective)
+	}
+	s.period = defaultPeriod
+	if s.Period != "" {
+		period, err := parsePositiveDuration(s.Period)
+		if err != nil {
+			return fmt.Errorf("invalid period: %w", err)
+		}
+		s.period = period
+	}
+	windows := s.BurnRateWindows
+	if len(windows) == 0 {
+		windows = defaultBurnRateWindows
+	}
+	s.burnRateWindows = make([]time.Duration, len(windows))
+	for i, w := range windows {
+		window, err := parsePositiveDuration(w)
+		if err != nil {
+			return fmt.Errorf("invalid burn rate window #%d: %w", i, err)
+		}
+		if window > s.period {
+			return fmt.Errorf("the burn rate window %v is longer than the period %v", window, s.period)
+		}
+		s.burnRateWindows[i] = window
+	}
+	return nil
+}
+
+func parsePositiveDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("the duration %q must be positive", s)
+	}
+	return d, nil
+}
+
+// errorBudget is the ratio of bad events allowed by the objective.
+func (s *SLO) errorBudget() float64 {
+	return 1 - s.Objective
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectiveInit(t *testing.T) {
+	slo := SLO{Name: "checkout-latency", Service: "frontend", Type: TypeLatency, Objective: 0.99, LatencyThreshold: 300}
+	require.NoError(t, slo.init())
+	assert.Equal(t, defaultPeriod, slo.period)
+	assert.Equal(t, []time.Duration{time.Hour, 6 * time.Hour, 72 * time.Hour}, slo.burnRateWindows)
+	assert.InDelta(t, 0.01, slo.errorBudget(), 1e-9)
+
+	slo = SLO{Name: "availability", Service: "frontend", Type: TypeAvailability, Objective: 0.999, Period: "168h", BurnRateWindows: []string{"5m", "1h"}}
+	require.NoError(t, slo.init())
+	assert.Equal(t, 168*time.Hour, slo.period)
+	assert.Equal(t, []time.Duration{5 * time.Minute, time.Hour}, slo.burnRateWindows)
+}
+
+func TestObjectiveInitErrors(t *testing.T) {
+	valid := SLO{Name: "availability", Service: "frontend", Type: TypeAvailability, Objective: 0.999}
+	tests := []struct {
+		name   string
+		modify func(*SLO)
+		err    string
+	}{
+		{name: "name", modify: func(s *SLO) { s.Name = "not valid" }, err: "the name"},
+		{name: "service", modify: func(s *SLO) { s.Service = "" }, err: "the service is required"},
+		{name: "type", modify: func(s *SLO) { s.Type = "throughput" }, err: `unknown type "throughput"`},
+		{name: "threshold of availability", modify: func(s *SLO) { s.LatencyThreshold = 100 }, err: "the latency threshold only applies"},
+		{name: "missing threshold", modify: func(s *SLO) { s.Type = TypeLatency }, err: "the latency threshold of the latency SLOs must be positive"},
+		{name: "objective", modify: func(s *SLO) { s.Objective = 1 }, err: "the objective 1 must be between 0 and 1"},
+		{name: "zero objective", modify: func(s *SLO) { s.Objective = 0 }, err: "the objective 0 must be between 0 and 1"},
+		{name: "period", modify: func(s *SLO) { s.Period = "30d" }, err: "invalid period"},
+		{name: "negative period", modify: func(s *SLO) { s.Period = "-1h" }, err: "must be positive"},
+		{name: "window", modify: func(s *SLO) { s.BurnRateWindows = []string{"1h", "soon"} }, err: "invalid burn rate window #1"},
+		{name: "long window", modify: func(s *SLO) { s.Period = "1h"; s.BurnRateWindows = []string{"6h"} }, err: "the burn rate window 6h0m0s is longer than the period 1h0m0s"},
+		{name: "default windows longer than the period", modify: func(s *SLO) { s.Period = "24h" }, err: "is longer than the period"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slo := valid
+			test.modify(&slo)
+			err := slo.init()
+			require.ErrorIs(t, err, ErrInvalidObjective)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slo
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPrefix          = "query.slo"
+	flagEnabled         = flagPrefix + ".enabled"
+	flagSource          = flagPrefix + ".source"
+	flagDefinitionsFile = flagPrefix + ".definitions-file"
+
+	defaultSource = SourceSpans
+)
+
+// Options holds configuration for the Service Level Objectives.
+type Options struct {
+	// Enabled tracks the SLOs in the query service and registers their API.
+	Enabled bool
+	// Source is where the ratios of bad events are computed from, SourceSpans or SourceMetrics.
+	Source string
+	// DefinitionsFile is the path to the JSON file persisting the SLOs, they are only kept
+	// in memory if empty.
+	DefinitionsFile string
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(flagEnabled, false, "Track the availability and latency Service Level Objectives of the services, managed via /api/slos, with their compliance, error budget and burn rates")
+	flagSet.String(flagSource, defaultSource, "Where the SLOs are computed from: spans, from the span storage, or metrics, from the metrics storage of the Service Performance Monitoring, which only supports the availability SLOs")
+	flagSet.String(flagDefinitionsFile, "", "The path to the JSON file persisting the SLOs; they are only kept in memory if empty")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Enabled = v.GetBool(flagEnabled)
+	o.Source = v.GetString(flagSource)
+	o.DefinitionsFile = v.GetString(flagDefinitionsFile)
+	return o
+}
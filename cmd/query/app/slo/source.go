@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// SourceSpans computes the ratios of bad events from the spans of the span storage.
+	SourceSpans = "spans"
+	// SourceMetrics reads the error rates from the metrics storage of the Service Performance Monitoring.
+	SourceMetrics = "metrics"
+
+	operationLabel = "operation"
+)
+
+// errorTags selects the spans counted as errors.
+var errorTags = map[string]string{"error": "true"}
+
+// source computes the ratio of the bad events of an SLO between start and end.
+// It returns false if there is no data to compute it from, i.e. no events.
+type source interface {
+	// validate checks that the source can compute the bad events of the SLO.
+	validate(slo *SLO) error
+	badRatio(ctx context.Context, slo *SLO, start, end time.Time) (float64, bool, error)
+}
+
+// spanSource counts the events with the latency distributions of the spans.
+type spanSource struct {
+	querySvc *querysvc.QueryService
+}
+
+func (*spanSource) validate(*SLO) error {
+	return nil
+}
+
+func (s *spanSource) badRatio(ctx context.Context, slo *SLO, start, end time.Time) (float64, bool, error) {
+	query := &spanstore.LatencyQueryParameters{
+		ServiceName:   slo.Service,
+		OperationName: slo.Operation,
+		StartTimeMin:  start,
+		StartTimeMax:  end,
+	}
+	if slo.Type == TypeLatency {
+		// the single bucket counts the spans faster than the threshold
+		query.BucketBounds = []time.Duration{time.Duration(slo.LatencyThreshold * float64(time.Millisecond))}
+	}
+	dist, err := s.querySvc.GetLatencyDistribution(ctx, query)
+	if err != nil {
+		return 0, false, err
+	}
+	if dist.Count == 0 {
+		return 0, false, nil
+	}
+	if slo.Type == TypeLatency {
+		return float64(dist.Count-dist.BucketCounts[0]) / float64(dist.Count), true, nil
+	}
+	query.Tags = errorTags
+	errorDist, err := s.querySvc.GetLatencyDistribution(ctx, query)
+	if err != nil {
+		return 0, false, err
+	}
+	return float64(errorDist.Count) / float64(dist.Count), true, nil
+}
+
+// metricSource reads the error rates from the metrics storage, with a single step covering the time range.
+type metricSource struct {
+	reader metricsstore.Reader
+}
+
+func (*metricSource) validate(slo *SLO) error {
+	if slo.Type != TypeAvailability {
+		return fmt.Errorf("the %s source only computes the %s SLOs", SourceMetrics, TypeAvailability)
+	}
+	return nil
+}
+
+func (s *metricSource) badRatio(ctx context.Context, slo *SLO, start, end time.Time) (float64, bool, error) {
+	window := end.Sub(start)
+	family, err := s.reader.GetErrorRates(ctx, &metricsstore.ErrorRateQueryParameters{
+		BaseQueryParameters: metricsstore.BaseQueryParameters{
+			ServiceNames:     []string{slo.Service},
+			GroupByOperation: slo.Operation != "",
+			EndTime:          &end,
+			Lookback:         &window,
+			Step:             &window,
+			RatePer:          &window,
+		},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	for _, metric := range family.GetMetrics() {
+		if slo.Operation != "" && labelValue(metric, operationLabel) != slo.Operation {
+			continue
+		}
+		points := metric.GetMetricPoints()
+		if len(points) == 0 {
+			continue
+		}
+		return points[len(points)-1].GetGaugeValue().GetDoubleValue(), true, nil
+	}
+	return 0, false, nil
+}
+
+func labelValue(metric *metrics.Metric, name string) string {
+	for _, label := range metric.GetLabels() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
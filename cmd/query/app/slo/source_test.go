@@ -0,0 +1,159 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestQueryService stores four checkout spans of frontend lasting 100ms to 400ms, the first one
+// an error, and one span of another operation lasting 50ms.
+func newTestQueryService(t *testing.T) *querysvc.QueryService {
+	store := memory.NewStore()
+	frontend := model.NewProcess("frontend", nil)
+	for i := 1; i <= 5; i++ {
+		span := &model.Span{
+			TraceID:       model.NewTraceID(0, uint64(i)),
+			SpanID:        model.SpanID(i),
+			OperationName: "checkout",
+			StartTime:     testNow.Add(-time.Duration(i) * time.Minute),
+			Duration:      time.Duration(i) * 100 * time.Millisecond,
+			Process:       frontend,
+		}
+		if i == 1 {
+			span.Tags = []model.KeyValue{model.Bool("error", true)}
+		}
+		if i == 5 {
+			span.OperationName = "health"
+			span.Duration = 50 * time.Millisecond
+		}
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+	}
+	return querysvc.NewQueryService(store, store, querysvc.QueryServiceOptions{})
+}
+
+func initObjective(t *testing.T, slo SLO) *SLO {
+	slo.Name = "test"
+	if slo.Objective == 0 {
+		slo.Objective = 0.99
+	}
+	require.NoError(t, slo.init())
+	return &slo
+}
+
+func TestSpanSource(t *testing.T) {
+	src := &spanSource{querySvc: newTestQueryService(t)}
+	tests := []struct {
+		name   string
+		slo    SLO
+		window time.Duration
+		ratio  float64
+		ok     bool
+	}{
+		{name: "availability", slo: SLO{Service: "frontend", Operation: "checkout", Type: TypeAvailability}, window: time.Hour, ratio: 0.25, ok: true},
+		{name: "availability of the service", slo: SLO{Service: "frontend", Type: TypeAvailability}, window: time.Hour, ratio: 0.2, ok: true},
+		{name: "availability over a window", slo: SLO{Service: "frontend", Type: TypeAvailability}, window: 90 * time.Second, ratio: 1, ok: true},
+		{name: "latency", slo: SLO{Service: "frontend", Operation: "checkout", Type: TypeLatency, LatencyThreshold: 300}, window: time.Hour, ratio: 0.5, ok: true},
+		{name: "latency of the service", slo: SLO{Service: "frontend", Type: TypeLatency, LatencyThreshold: 250}, window: time.Hour, ratio: 0.4, ok: true},
+		{name: "no availability", slo: SLO{Service: "backend", Type: TypeAvailability}, window: time.Hour},
+		{name: "no latency", slo: SLO{Service: "backend", Type: TypeLatency, LatencyThreshold: 100}, window: time.Hour},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slo := initObjective(t, test.slo)
+			require.NoError(t, src.validate(slo))
+			ratio, ok, err := src.badRatio(context.Background(), slo, testNow.Add(-test.window), testNow)
+			require.NoError(t, err)
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.ratio, ratio, 1e-9)
+		})
+	}
+}
+
+func TestSpanSourceError(t *testing.T) {
+	qs := querysvc.NewQueryService(memory.NewStore(), memory.NewStore(), querysvc.QueryServiceOptions{
+		Authorizer: querysvc.NewServiceAuthorizer([]querysvc.AuthorizationRule{}),
+	})
+	src := &spanSource{querySvc: qs}
+	_, _, err := src.badRatio(context.Background(), initObjective(t, SLO{Service: "frontend", Type: TypeAvailability}), testNow.Add(-time.Hour), testNow)
+	require.ErrorIs(t, err, querysvc.ErrServiceNotAllowed)
+}
+
+type fakeMetricsReader struct {
+	metricsstore.Reader
+	params *metricsstore.ErrorRateQueryParameters
+	family *metrics.MetricFamily
+	err    error
+}
+
+func (r *fakeMetricsReader) GetErrorRates(_ context.Context, params *metricsstore.ErrorRateQueryParameters) (*metrics.MetricFamily, error) {
+	r.params = params
+	return r.family, r.err
+}
+
+func newMetric(operation string, values ...float64) *metrics.Metric {
+	metric := &metrics.Metric{Labels: []*metrics.Label{{Name: "service_name", Value: "frontend"}}}
+	if operation != "" {
+		metric.Labels = append(metric.Labels, &metrics.Label{Name: operationLabel, Value: operation})
+	}
+	for _, v := range values {
+		metric.MetricPoints = append(metric.MetricPoints, &metrics.MetricPoint{
+			Value: &metrics.MetricPoint_GaugeValue{GaugeValue: &metrics.GaugeValue{Value: &metrics.GaugeValue_DoubleValue{DoubleValue: v}}},
+		})
+	}
+	return metric
+}
+
+func TestMetricSource(t *testing.T) {
+	reader := &fakeMetricsReader{family: &metrics.MetricFamily{Metrics: []*metrics.Metric{
+		newMetric("health"),
+		newMetric("health", 0.5),
+		newMetric("checkout", 0.1, 0.02),
+	}}}
+	src := &metricSource{reader: reader}
+
+	slo := initObjective(t, SLO{Service: "frontend", Operation: "checkout", Type: TypeAvailability})
+	require.NoError(t, src.validate(slo))
+	ratio, ok, err := src.badRatio(context.Background(), slo, testNow.Add(-time.Hour), testNow)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.02, ratio, 1e-9)
+	assert.Equal(t, []string{"frontend"}, reader.params.ServiceNames)
+	assert.True(t, reader.params.GroupByOperation)
+	assert.Equal(t, testNow, *reader.params.EndTime)
+	assert.Equal(t, time.Hour, *reader.params.Lookback)
+	assert.Equal(t, time.Hour, *reader.params.Step)
+	assert.Equal(t, time.Hour, *reader.params.RatePer)
+
+	ratio, ok, err = src.badRatio(context.Background(), initObjective(t, SLO{Service: "frontend", Type: TypeAvailability}), testNow.Add(-time.Hour), testNow)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, ratio, 1e-9)
+	assert.False(t, reader.params.GroupByOperation)
+
+	_, ok, err = src.badRatio(context.Background(), initObjective(t, SLO{Service: "frontend", Operation: "pay", Type: TypeAvailability}), testNow.Add(-time.Hour), testNow)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	reader.err = errors.New("storage error")
+	_, _, err = src.badRatio(context.Background(), slo, testNow.Add(-time.Hour), testNow)
+	require.ErrorContains(t, err, "storage error")
+
+	err = src.validate(initObjective(t, SLO{Service: "frontend", Type: TypeLatency, LatencyThreshold: 100}))
+	require.ErrorContains(t, err, "the metrics source only computes the availability SLOs")
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package slo tracks the Service Level Objectives of the services from their spans: the compliance
+// of the availability or latency SLOs over their period, the remaining error budget, and the burn
+// rates over shorter windows, at which the error budget is being consumed.
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// ErrObjectiveNotFound is returned when the tenant has no SLO of the name.
+var ErrObjectiveNotFound = errors.New("SLO not found")
+
+// Status is the compliance of an SLO at a point in time.
+type Status struct {
+	Name      string    `json:"name"`
+	Objective float64   `json:"objective"`
+	Period    string    `json:"period"`
+	End       time.Time `json:"end"`
+	// Compliance is the ratio of good events over the period, nil if there were no events.
+	Compliance  *float64     `json:"compliance,omitempty"`
+	ErrorBudget *ErrorBudget `json:"errorBudget,omitempty"`
+	BurnRates   []BurnRate   `json:"burnRates"`
+}
+
+// ErrorBudget is the ratio of bad events allowed over the period, and how much of it was consumed.
+type ErrorBudget struct {
+	Total float64 `json:"total"`
+	// Consumed is the part of the budget consumed by the bad events, above 1 once exhausted.
+	Consumed float64 `json:"consumed"`
+	// Remaining is 1 minus Consumed, negative once the budget is exhausted.
+	Remaining float64 `json:"remaining"`
+}
+
+// BurnRate is the rate of consumption of the error budget over a window, 1 consuming exactly the
+// budget over the period.
+type BurnRate struct {
+	Window string `json:"window"`
+	// ErrorRatio is the ratio of bad events over the window, nil with BurnRate if there were no events.
+	ErrorRatio *float64 `json:"errorRatio,omitempty"`
+	BurnRate   *float64 `json:"burnRate,omitempty"`
+}
+
+// definitionsFile is the content of the file persisting the SLOs.
+type definitionsFile struct {
+	SLOs []persistedObjective `json:"slos"`
+}
+
+type persistedObjective struct {
+	Tenant string `json:"tenant,omitempty"`
+	SLO    SLO    `json:"slo"`
+}
+
+// Tracker stores the SLOs of the tenants and computes their status on demand.
+type Tracker struct {
+	options Options
+	source  source
+	timeNow func() time.Time
+
+	// lock guards slos and the writes of the definitions file.
+	lock sync.Mutex
+	slos map[string]map[string]SLO
+}
+
+// NewTracker creates a Tracker computing the SLOs with the query service or the metrics query service,
+// depending on the source of the options, and loading the SLOs of the definitions file if it exists.
+func NewTracker(options Options, querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService) (*Tracker, error) {
+	var src source
+	switch options.Source {
+	case SourceSpans:
+		src = &spanSource{querySvc: querySvc}
+	case SourceMetrics:
+		src = &metricSource{reader: metricsQuerySvc}
+	default:
+		return nil, fmt.Errorf("unknown SLO source %q, must be %s or %s", options.Source, SourceSpans, SourceMetrics)
+	}
+	t := newTracker(options, src)
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func newTracker(options Options, src source) *Tracker {
+	return &Tracker{
+		options: options,
+		source:  src,
+		timeNow: time.Now,
+		slos:    make(map[string]map[string]SLO),
+	}
+}
+
+// load reads the SLOs from the definitions file, if any.
+func (t *Tracker) load() error {
+	if t.options.DefinitionsFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(t.options.DefinitionsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the SLO definitions file: %w", err)
+	}
+	var file definitionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse the SLO definitions file: %w", err)
+	}
+	for i := range file.SLOs {
+		persisted := &file.SLOs[i]
+		if err := t.check(&persisted.SLO); err != nil {
+			return fmt.Errorf("invalid SLO #%d of the definitions file: %w", i, err)
+		}
+		t.tenantObjectives(persisted.Tenant)[persisted.SLO.Name] = persisted.SLO
+	}
+	return nil
+}
+
+// save writes the SLOs to the definitions file, replacing it atomically.
+// It must be called with the lock held.
+func (t *Tracker) save() error {
+	if t.options.DefinitionsFile == "" {
+		return nil
+	}
+	file := definitionsFile{SLOs: []persistedObjective{}}
+	for tenant, slos := range t.slos {
+		for _, slo := range slos {
+			file.SLOs = append(file.SLOs, persistedObjective{Tenant: tenant, SLO: slo})
+		}
+	}
+	sort.Slice(file.SLOs, func(i, j int) bool {
+		if file.SLOs[i].Tenant != file.SLOs[j].Tenant {
+			return file.SLOs[i].Tenant < file.SLOs[j].Tenant
+		}
+		return file.SLOs[i].SLO.Name < file.SLOs[j].SLO.Name
+	})
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.options.DefinitionsFile), filepath.Base(t.options.DefinitionsFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write the SLO definitions file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), t.options.DefinitionsFile)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the SLO definitions file: %w", err)
+	}
+	return nil
+}
+
+func (t *Tracker) tenantObjectives(tenant string) map[string]SLO {
+	slos, ok := t.slos[tenant]
+	if !ok {
+		slos = make(map[string]SLO)
+		t.slos[tenant] = slos
+	}
+	return slos
+}
+
+// check validates the SLO and that the source can compute it.
+func (t *Tracker) check(slo *SLO) error {
+	if err := slo.init(); err != nil {
+		return err
+	}
+	if err := t.source.validate(slo); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidObjective, err)
+	}
+	return nil
+}
+
+// Objectives returns the SLOs of the tenant of the context, ordered by name.
+func (t *Tracker) Objectives(ctx context.Context) []SLO {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	slos := t.slos[tenancy.GetTenant(ctx)]
+	found := make([]SLO, 0, len(slos))
+	for _, slo := range slos {
+		found = append(found, slo)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// GetObjective returns an SLO of the tenant of the context, or ErrObjectiveNotFound.
+func (t *Tracker) GetObjective(ctx context.Context, name string) (SLO, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	slo, ok := t.slos[tenancy.GetTenant(ctx)][name]
+	if !ok {
+		return SLO{}, ErrObjectiveNotFound
+	}
+	return slo, nil
+}
+
+// PutObjective creates or replaces an SLO of the tenant of the context.
+func (t *Tracker) PutObjective(ctx context.Context, slo SLO) (SLO, error) {
+	if err := t.check(&slo); err != nil {
+		return SLO{}, err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.tenantObjectives(tenancy.GetTenant(ctx))[slo.Name] = slo
+	return slo, t.save()
+}
+
+// DeleteObjective deletes an SLO of the tenant of the context, returning ErrObjectiveNotFound if there is none.
+func (t *Tracker) DeleteObjective(ctx context.Context, name string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	slos := t.slos[tenancy.GetTenant(ctx)]
+	if _, ok := slos[name]; !ok {
+		return ErrObjectiveNotFound
+	}
+	delete(slos, name)
+	return t.save()
+}
+
+// GetStatus computes the compliance, error budget and burn rates of an SLO of the tenant of the context,
+// over the period and the windows ending now.
+func (t *Tracker) GetStatus(ctx context.Context, name string) (*Status, error) {
+	slo, err := t.GetObjective(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	end := t.timeNow()
+	status := &Status{
+		Name:      slo.Name,
+		Objective: slo.Objective,
+		Period:    slo.period.String(),
+		End:       end,
+		BurnRates: make([]BurnRate, len(slo.burnRateWindows)),
+	}
+	budget := slo.errorBudget()
+	badRatio, ok, err := t.source.badRatio(ctx, &slo, end.Add(-slo.period), end)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		compliance := 1 - badRatio
+		consumed := badRatio / budget
+		status.Compliance = &compliance
+		status.ErrorBudget = &ErrorBudget{Total: budget, Consumed: consumed, Remaining: 1 - consumed}
+	}
+	for i, window := range slo.burnRateWindows {
+		burnRate := &status.BurnRates[i]
+		burnRate.Window = window.String()
+		ratio, ok, err := t.source.badRatio(ctx, &slo, end.Add(-window), end)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rate := ratio / budget
+			burnRate.ErrorRatio = &ratio
+			burnRate.BurnRate = &rate
+		}
+	}
+	return status, nil
+}
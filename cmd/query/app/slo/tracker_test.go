@@ -0,0 +1,230 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// fakeSource returns the ratios set for the durations of the time ranges.
+type fakeSource struct {
+	ratios map[time.Duration]float64
+	err    error
+}
+
+func (*fakeSource) validate(slo *SLO) error {
+	if slo.Operation == "unsupported" {
+		return errors.New("unsupported operation")
+	}
+	return nil
+}
+
+func (s *fakeSource) badRatio(_ context.Context, _ *SLO, start, end time.Time) (float64, bool, error) {
+	ratio, ok := s.ratios[end.Sub(start)]
+	return ratio, ok, s.err
+}
+
+func newTestTracker(options Options) (*Tracker, *fakeSource) {
+	src := &fakeSource{ratios: make(map[time.Duration]float64)}
+	t := newTracker(options, src)
+	t.timeNow = func() time.Time { return testNow }
+	return t, src
+}
+
+func TestNewTracker(t *testing.T) {
+	tracker, err := NewTracker(Options{Source: SourceSpans}, newTestQueryService(t), nil)
+	require.NoError(t, err)
+	assert.IsType(t, &spanSource{}, tracker.source)
+	tracker, err = NewTracker(Options{Source: SourceMetrics}, nil, &fakeMetricsReader{})
+	require.NoError(t, err)
+	assert.IsType(t, &metricSource{}, tracker.source)
+	// the definitions file is created by the first change
+	tracker, err = NewTracker(Options{Source: SourceSpans, DefinitionsFile: filepath.Join(t.TempDir(), "missing.json")}, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, tracker.Objectives(context.Background()))
+}
+
+func TestNewTrackerErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+		return filename
+	}
+	tests := []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{name: "source", options: Options{Source: "logs"}, err: `unknown SLO source "logs"`},
+		{name: "unreadable definitions file", options: Options{Source: SourceSpans, DefinitionsFile: dir}, err: "failed to read the SLO definitions file"},
+		{name: "malformed definitions file", options: Options{Source: SourceSpans, DefinitionsFile: writeFile("malformed.json", "{")}, err: "failed to parse the SLO definitions file"},
+		{
+			name:    "invalid SLO",
+			options: Options{Source: SourceSpans, DefinitionsFile: writeFile("invalid.json", `{"slos": [{"slo": {"name": "availability"}}]}`)},
+			err:     "invalid SLO #0 of the definitions file",
+		},
+		{
+			name:    "unsupported SLO",
+			options: Options{Source: SourceMetrics, DefinitionsFile: writeFile("latency.json", `{"slos": [{"slo": {"name": "latency", "service": "frontend", "type": "latency", "objective": 0.99, "latencyThreshold": 100}}]}`)},
+			err:     "the metrics source only computes the availability SLOs",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewTracker(test.options, nil, nil)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestTrackerObjectives(t *testing.T) {
+	tracker, _ := newTestTracker(Options{})
+	ctx := context.Background()
+	acme := tenancy.WithTenant(ctx, "acme")
+
+	slo, err := tracker.PutObjective(ctx, SLO{Name: "latency", Service: "frontend", Type: TypeLatency, Objective: 0.99, LatencyThreshold: 300})
+	require.NoError(t, err)
+	assert.Equal(t, defaultPeriod, slo.period)
+	_, err = tracker.PutObjective(ctx, SLO{Name: "availability", Service: "frontend", Type: TypeAvailability, Objective: 0.999})
+	require.NoError(t, err)
+	_, err = tracker.PutObjective(acme, SLO{Name: "availability", Service: "billing", Type: TypeAvailability, Objective: 0.99})
+	require.NoError(t, err)
+
+	slos := tracker.Objectives(ctx)
+	require.Len(t, slos, 2)
+	assert.Equal(t, "availability", slos[0].Name)
+	assert.Equal(t, "latency", slos[1].Name)
+	slo, err = tracker.GetObjective(acme, "availability")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", slo.Service)
+	_, err = tracker.GetObjective(acme, "latency")
+	require.ErrorIs(t, err, ErrObjectiveNotFound)
+
+	_, err = tracker.PutObjective(ctx, SLO{Name: "availability", Service: "frontend"})
+	require.ErrorIs(t, err, ErrInvalidObjective)
+	_, err = tracker.PutObjective(ctx, SLO{Name: "availability", Service: "frontend", Operation: "unsupported", Type: TypeAvailability, Objective: 0.99})
+	require.ErrorIs(t, err, ErrInvalidObjective)
+	require.ErrorContains(t, err, "unsupported operation")
+
+	require.NoError(t, tracker.DeleteObjective(ctx, "availability"))
+	require.ErrorIs(t, tracker.DeleteObjective(ctx, "availability"), ErrObjectiveNotFound)
+	assert.Len(t, tracker.Objectives(ctx), 1)
+	assert.Len(t, tracker.Objectives(acme), 1)
+}
+
+func TestTrackerStatus(t *testing.T) {
+	tracker, src := newTestTracker(Options{})
+	ctx := context.Background()
+	_, err := tracker.PutObjective(ctx, SLO{
+		Name:            "availability",
+		Service:         "frontend",
+		Type:            TypeAvailability,
+		Objective:       0.99,
+		Period:          "168h",
+		BurnRateWindows: []string{"1h", "6h"},
+	})
+	require.NoError(t, err)
+
+	src.ratios[168*time.Hour] = 0.005
+	src.ratios[time.Hour] = 0.1
+	status, err := tracker.GetStatus(ctx, "availability")
+	require.NoError(t, err)
+	assert.Equal(t, "availability", status.Name)
+	assert.Equal(t, 0.99, status.Objective)
+	assert.Equal(t, "168h0m0s", status.Period)
+	assert.Equal(t, testNow, status.End)
+	assert.InDelta(t, 0.995, *status.Compliance, 1e-9)
+	assert.InDelta(t, 0.01, status.ErrorBudget.Total, 1e-9)
+	assert.InDelta(t, 0.5, status.ErrorBudget.Consumed, 1e-9)
+	assert.InDelta(t, 0.5, status.ErrorBudget.Remaining, 1e-9)
+	require.Len(t, status.BurnRates, 2)
+	assert.Equal(t, "1h0m0s", status.BurnRates[0].Window)
+	assert.InDelta(t, 0.1, *status.BurnRates[0].ErrorRatio, 1e-9)
+	assert.InDelta(t, 10, *status.BurnRates[0].BurnRate, 1e-9)
+	// no events over the window
+	assert.Equal(t, "6h0m0s", status.BurnRates[1].Window)
+	assert.Nil(t, status.BurnRates[1].ErrorRatio)
+	assert.Nil(t, status.BurnRates[1].BurnRate)
+
+	// the budget is exhausted
+	src.ratios[168*time.Hour] = 0.02
+	status, err = tracker.GetStatus(ctx, "availability")
+	require.NoError(t, err)
+	assert.InDelta(t, 2, status.ErrorBudget.Consumed, 1e-9)
+	assert.InDelta(t, -1, status.ErrorBudget.Remaining, 1e-9)
+
+	// no events over the period
+	src.ratios = map[time.Duration]float64{}
+	status, err = tracker.GetStatus(ctx, "availability")
+	require.NoError(t, err)
+	assert.Nil(t, status.Compliance)
+	assert.Nil(t, status.ErrorBudget)
+
+	src.err = errors.New("storage error")
+	_, err = tracker.GetStatus(ctx, "availability")
+	require.ErrorContains(t, err, "storage error")
+	_, err = tracker.GetStatus(ctx, "latency")
+	require.ErrorIs(t, err, ErrObjectiveNotFound)
+}
+
+func TestTrackerStatusBurnRateError(t *testing.T) {
+	tracker, src := newTestTracker(Options{})
+	_, err := tracker.PutObjective(context.Background(), SLO{Name: "availability", Service: "frontend", Type: TypeAvailability, Objective: 0.99})
+	require.NoError(t, err)
+	src.ratios[defaultPeriod] = 0.005
+	tracker.source = &failingWindowSource{fakeSource: src, period: defaultPeriod}
+	_, err = tracker.GetStatus(context.Background(), "availability")
+	require.ErrorContains(t, err, "window error")
+}
+
+// failingWindowSource fails for the time ranges other than the period.
+type failingWindowSource struct {
+	*fakeSource
+	period time.Duration
+}
+
+func (s *failingWindowSource) badRatio(ctx context.Context, slo *SLO, start, end time.Time) (float64, bool, error) {
+	if end.Sub(start) != s.period {
+		return 0, false, errors.New("window error")
+	}
+	return s.fakeSource.badRatio(ctx, slo, start, end)
+}
+
+func TestTrackerDefinitionsFile(t *testing.T) {
+	definitionsFile := filepath.Join(t.TempDir(), "slos.json")
+	tracker, _ := newTestTracker(Options{DefinitionsFile: definitionsFile})
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	_, err := tracker.PutObjective(acme, SLO{Name: "availability", Service: "frontend", Type: TypeAvailability, Objective: 0.999, Period: "168h"})
+	require.NoError(t, err)
+	_, err = tracker.PutObjective(context.Background(), SLO{Name: "latency", Service: "frontend", Type: TypeLatency, Objective: 0.99, LatencyThreshold: 300})
+	require.NoError(t, err)
+
+	reloaded, err := NewTracker(Options{Source: SourceSpans, DefinitionsFile: definitionsFile}, nil, nil)
+	require.NoError(t, err)
+	slo, err := reloaded.GetObjective(acme, "availability")
+	require.NoError(t, err)
+	assert.Equal(t, 168*time.Hour, slo.period)
+	assert.Len(t, reloaded.Objectives(context.Background()), 1)
+
+	require.NoError(t, tracker.DeleteObjective(acme, "availability"))
+	reloaded, err = NewTracker(Options{Source: SourceSpans, DefinitionsFile: definitionsFile}, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.Objectives(acme))
+
+	// the failure to persist a change is returned
+	tracker.options.DefinitionsFile = filepath.Join(t.TempDir(), "missing", "slos.json")
+	_, err = tracker.PutObjective(context.Background(), SLO{Name: "latency", Service: "frontend", Type: TypeLatency, Objective: 0.99, LatencyThreshold: 200})
+	require.ErrorContains(t, err, "failed to write the SLO definitions file")
+}
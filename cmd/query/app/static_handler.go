@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync/atomic"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/cmd/query/app/ui"
 	"github.com/jaegertracing/jaeger/pkg/fswatcher"
+	"github.com/jaegertracing/jaeger/pkg/httpfs"
 	"github.com/jaegertracing/jaeger/pkg/version"
 )
 
@@ -43,17 +45,28 @@ var (
 	versionPattern     = regexp.MustCompile("JAEGER_VERSION *= *DEFAULT_VERSION;")
 	compabilityPattern = regexp.MustCompile("JAEGER_STORAGE_CAPABILITIES *= *DEFAULT_STORAGE_CAPABILITIES;")
 	basePathPattern    = regexp.MustCompile(`<base href="/"`) // Note: tag is not closed
+	headEndPattern     = regexp.MustCompile(`(?i)</head>`)
 )
 
+// customCSSPath is the path of the stylesheet of the static assets overlay linked by the index.html,
+// e.g. to override the CSS variables or the logo of the UI.
+const customCSSPath = "static/custom.css"
+
 // RegisterStaticHandler adds handler for static assets to the router.
 func RegisterStaticHandler(r *mux.Router, logger *zap.Logger, qOpts *QueryOptions, qCapabilities querysvc.StorageCapabilities) io.Closer {
-	staticHandler, err := NewStaticAssetsHandler(qOpts.StaticAssets.Path, StaticAssetsHandlerOptions{
+	options := StaticAssetsHandlerOptions{
 		BasePath:            qOpts.BasePath,
 		UIConfigPath:        qOpts.UIConfig,
+		TenantUIConfigPaths: qOpts.TenantUIConfigs,
 		StorageCapabilities: qCapabilities,
 		Logger:              logger,
 		LogAccess:           qOpts.StaticAssets.LogAccess,
-	})
+		OverlayPath:         qOpts.StaticAssets.OverlayPath,
+	}
+	if qOpts.Tenancy.Enabled {
+		options.TenancyHeader = qOpts.Tenancy.Header
+	}
+	staticHandler, err := NewStaticAssetsHandler(qOpts.StaticAssets.Path, options)
 	if err != nil {
 		logger.Panic("Could not create static assets handler", zap.Error(err))
 	}
@@ -65,10 +78,11 @@ func RegisterStaticHandler(r *mux.Router, logger *zap.Logger, qOpts *QueryOption
 
 // StaticAssetsHandler handles static assets
 type StaticAssetsHandler struct {
-	options   StaticAssetsHandlerOptions
-	indexHTML atomic.Value // stores []byte
-	assetsFS  http.FileSystem
-	watcher   *fswatcher.FSWatcher
+	options         StaticAssetsHandlerOptions
+	indexHTML       atomic.Value // stores []byte
+	tenantIndexHTML atomic.Value // stores map[string][]byte
+	assetsFS        http.FileSystem
+	watcher         *fswatcher.FSWatcher
 }
 
 // StaticAssetsHandlerOptions defines options for NewStaticAssetsHandler
@@ -78,6 +92,15 @@ type StaticAssetsHandlerOptions struct {
 	LogAccess           bool
 	StorageCapabilities querysvc.StorageCapabilities
 	Logger              *zap.Logger
+	// TenantUIConfigPaths are the paths of the UI configuration files of the tenants, by tenant,
+	// replacing UIConfigPath for the requests of these tenants
+	TenantUIConfigPaths map[string]string
+	// TenancyHeader is the header of the tenant of the requests, the tenant UI configurations are
+	// ignored if empty
+	TenancyHeader string
+	// OverlayPath is the directory of the custom static assets replacing or extending those of
+	// the UI, e.g. for branding; its static/custom.css stylesheet, if any, is linked by index.html
+	OverlayPath string
 }
 
 type loadedConfig struct {
@@ -91,6 +114,14 @@ func NewStaticAssetsHandler(staticAssetsRoot string, options StaticAssetsHandler
 	if staticAssetsRoot != "" {
 		assetsFS = http.Dir(staticAssetsRoot)
 	}
+	if options.OverlayPath != "" {
+		if stat, err := os.Stat(options.OverlayPath); err != nil {
+			return nil, fmt.Errorf("cannot open the static assets overlay: %w", err)
+		} else if !stat.IsDir() {
+			return nil, fmt.Errorf("the static assets overlay %s is not a directory", options.OverlayPath)
+		}
+		assetsFS = httpfs.OverlayFS(http.Dir(options.OverlayPath), assetsFS)
+	}
 
 	if options.Logger == nil {
 		options.Logger = zap.NewNop()
@@ -101,34 +132,77 @@ func NewStaticAssetsHandler(staticAssetsRoot string, options StaticAssetsHandler
 		assetsFS: assetsFS,
 	}
 
-	indexHTML, err := h.loadAndEnrichIndexHTML(assetsFS.Open)
+	indexHTML, err := h.loadAndEnrichIndexHTML(assetsFS.Open, options.UIConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	tenantIndexHTML, err := h.loadTenantIndexHTML(assetsFS.Open)
 	if err != nil {
 		return nil, err
 	}
 
 	options.Logger.Info("Using UI configuration", zap.String("path", options.UIConfigPath))
-	watcher, err := fswatcher.New([]string{options.UIConfigPath}, h.reloadUIConfig, h.options.Logger)
+	paths := []string{options.UIConfigPath}
+	for _, tenant := range h.tenants() {
+		options.Logger.Info("Using tenant UI configuration",
+			zap.String("tenant", tenant), zap.String("path", options.TenantUIConfigPaths[tenant]))
+		paths = append(paths, options.TenantUIConfigPaths[tenant])
+	}
+	watcher, err := fswatcher.New(paths, h.reloadUIConfig, h.options.Logger)
 	if err != nil {
 		return nil, err
 	}
 	h.watcher = watcher
 
 	h.indexHTML.Store(indexHTML)
+	h.tenantIndexHTML.Store(tenantIndexHTML)
 
 	return h, nil
 }
 
-func (sH *StaticAssetsHandler) loadAndEnrichIndexHTML(open func(string) (http.File, error)) ([]byte, error) {
+// tenants returns the sorted tenants with a UI configuration, none without a tenancy header.
+func (sH *StaticAssetsHandler) tenants() []string {
+	if sH.options.TenancyHeader == "" {
+		return nil
+	}
+	tenants := make([]string, 0, len(sH.options.TenantUIConfigPaths))
+	for tenant := range sH.options.TenantUIConfigPaths {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+func (sH *StaticAssetsHandler) loadTenantIndexHTML(open func(string) (http.File, error)) (map[string][]byte, error) {
+	tenantIndexHTML := make(map[string][]byte)
+	for _, tenant := range sH.tenants() {
+		indexHTML, err := sH.loadAndEnrichIndexHTML(open, sH.options.TenantUIConfigPaths[tenant])
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the UI config of the tenant %q: %w", tenant, err)
+		}
+		tenantIndexHTML[tenant] = indexHTML
+	}
+	return tenantIndexHTML, nil
+}
+
+func (sH *StaticAssetsHandler) loadAndEnrichIndexHTML(open func(string) (http.File, error), uiConfigPath string) ([]byte, error) {
 	indexBytes, err := loadIndexHTML(open)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load index.html: %w", err)
 	}
 	// replace UI config
-	if configObject, err := loadUIConfig(sH.options.UIConfigPath); err != nil {
+	if configObject, err := loadUIConfig(uiConfigPath); err != nil {
 		return nil, err
 	} else if configObject != nil {
 		indexBytes = configObject.regexp.ReplaceAll(indexBytes, configObject.config)
 	}
+	// link the custom stylesheet of the overlay
+	if sH.options.OverlayPath != "" {
+		if _, err := os.Stat(filepath.Join(sH.options.OverlayPath, filepath.FromSlash(customCSSPath))); err == nil {
+			link := fmt.Sprintf(`<link rel="stylesheet" href="%s">$0`, customCSSPath)
+			indexBytes = headEndPattern.ReplaceAll(indexBytes, []byte(link))
+		}
+	}
 	// replace storage capabilities
 	capabilitiesJSON, _ := json.Marshal(sH.options.StorageCapabilities)
 	capabilitiesString := fmt.Sprintf("JAEGER_STORAGE_CAPABILITIES = %s;", string(capabilitiesJSON))
@@ -151,13 +225,22 @@ func (sH *StaticAssetsHandler) loadAndEnrichIndexHTML(open func(string) (http.Fi
 	return indexBytes, nil
 }
 
+// reloadUIConfig reloads the UI configurations, keeping the previous pages if one of them is invalid,
+// e.g. while it is being edited.
 func (sH *StaticAssetsHandler) reloadUIConfig() {
 	sH.options.Logger.Info("reloading UI config", zap.String("filename", sH.options.UIConfigPath))
-	content, err := sH.loadAndEnrichIndexHTML(sH.assetsFS.Open)
+	content, err := sH.loadAndEnrichIndexHTML(sH.assetsFS.Open, sH.options.UIConfigPath)
+	if err != nil {
+		sH.options.Logger.Error("error while reloading the UI config", zap.Error(err))
+		return
+	}
+	tenantContent, err := sH.loadTenantIndexHTML(sH.assetsFS.Open)
 	if err != nil {
 		sH.options.Logger.Error("error while reloading the UI config", zap.Error(err))
+		return
 	}
 	sH.indexHTML.Store(content)
+	sH.tenantIndexHTML.Store(tenantContent)
 	sH.options.Logger.Info("reloaded UI config", zap.String("filename", sH.options.UIConfigPath))
 }
 
@@ -235,8 +318,16 @@ func (sH *StaticAssetsHandler) RegisterRoutes(router *mux.Router) {
 	router.NotFoundHandler = sH.loggingHandler(http.HandlerFunc(sH.notFound))
 }
 
-func (sH *StaticAssetsHandler) notFound(w http.ResponseWriter, _ *http.Request) {
+func (sH *StaticAssetsHandler) notFound(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tenantIndexHTML := sH.tenantIndexHTML.Load().(map[string][]byte)
+	if len(tenantIndexHTML) > 0 {
+		w.Header().Add("Vary", sH.options.TenancyHeader)
+		if indexHTML, ok := tenantIndexHTML[r.Header.Get(sH.options.TenancyHeader)]; ok {
+			w.Write(indexHTML)
+			return
+		}
+	}
 	w.Write(sH.indexHTML.Load().([]byte))
 }
 
@@ -87,7 +87,7 @@ func TestRegisterStaticHandler(t *testing.T) {
 			logAccess:                   true,
 			UIConfigPath:                "",
 			expectedUIConfig:            "JAEGER_CONFIG=DEFAULT_CONFIG;",
-			expectedStorageCapabilities: `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":false};`,
+			expectedStorageCapabilities: `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":false,"serviceMetadata":false,"savedSearches":false,"samplingStrategies":false,"traceSearch":false,"tagSearch":false,"tagFilters":false,"operationSpanKind":false,"dependencies":false,"dependencyLatencies":false};`,
 		},
 		{
 			basePath:                    "/",
@@ -96,7 +96,7 @@ func TestRegisterStaticHandler(t *testing.T) {
 			expectedBaseHTML:            `<base href="/"`,
 			UIConfigPath:                "fixture/ui-config.json",
 			expectedUIConfig:            `JAEGER_CONFIG = {"x":"y"};`,
-			expectedStorageCapabilities: `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":false};`,
+			expectedStorageCapabilities: `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":false,"serviceMetadata":false,"savedSearches":false,"samplingStrategies":false,"traceSearch":false,"tagSearch":false,"tagFilters":false,"operationSpanKind":false,"dependencies":false,"dependencyLatencies":false};`,
 		},
 		{
 			basePath:                    "/jaeger",
@@ -106,7 +106,7 @@ func TestRegisterStaticHandler(t *testing.T) {
 			archiveStorage:              true,
 			UIConfigPath:                "fixture/ui-config.js",
 			expectedUIConfig:            "function UIConfig(){",
-			expectedStorageCapabilities: `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":true};`,
+			expectedStorageCapabilities: `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":true,"serviceMetadata":false,"savedSearches":false,"samplingStrategies":false,"traceSearch":false,"tagSearch":false,"tagFilters":false,"operationSpanKind":false,"dependencies":false,"dependencyLatencies":false};`,
 		},
 	}
 	httpClient = &http.Client{
@@ -219,6 +219,119 @@ func TestHotReloadUIConfig(t *testing.T) {
 	assert.Contains(t, i, "About a new Jaeger", logObserver.All())
 }
 
+func TestTenantUIConfig(t *testing.T) {
+	options := StaticAssetsHandlerOptions{
+		UIConfigPath:        "fixture/ui-config.json",
+		TenantUIConfigPaths: map[string]string{"acme": "fixture/ui-config-menu.json"},
+	}
+	get := func(h *StaticAssetsHandler, tenant string) *httptest.ResponseRecorder {
+		r := mux.NewRouter()
+		h.RegisterRoutes(r)
+		req := httptest.NewRequest(http.MethodGet, "/search", nil)
+		if tenant != "" {
+			req.Header.Set("x-tenant", tenant)
+		}
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	h, err := NewStaticAssetsHandler("fixture", options)
+	require.NoError(t, err)
+	defer h.Close()
+	assert.NotContains(t, get(h, "acme").Body.String(), "GitHub", "the tenant UI configs require a tenancy header")
+
+	options.TenancyHeader = "x-tenant"
+	h, err = NewStaticAssetsHandler("fixture", options)
+	require.NoError(t, err)
+	defer h.Close()
+	resp := get(h, "acme")
+	assert.Contains(t, resp.Body.String(), `JAEGER_CONFIG = {"menu":[{"label":"GitHub"`)
+	assert.Equal(t, "x-tenant", resp.Header().Get("Vary"))
+	for _, tenant := range []string{"", "globex"} {
+		body := get(h, tenant).Body.String()
+		assert.Contains(t, body, `JAEGER_CONFIG = {"x":"y"};`)
+		assert.NotContains(t, body, "GitHub")
+	}
+
+	options.TenantUIConfigPaths["globex"] = "fixture/ui-config-malformed.json"
+	_, err = NewStaticAssetsHandler("fixture", options)
+	require.ErrorContains(t, err, `cannot load the UI config of the tenant "globex"`)
+}
+
+func TestStaticAssetsOverlay(t *testing.T) {
+	get := func(h *StaticAssetsHandler, path string) string {
+		r := mux.NewRouter()
+		h.RegisterRoutes(r)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp.Body.String()
+	}
+
+	h, err := NewStaticAssetsHandler("fixture", StaticAssetsHandlerOptions{OverlayPath: t.TempDir()})
+	require.NoError(t, err)
+	defer h.Close()
+	assert.Equal(t, "some asset\n", get(h, "/static/asset.txt"))
+	assert.NotContains(t, get(h, "/search"), "custom.css")
+
+	h, err = NewStaticAssetsHandler("fixture", StaticAssetsHandlerOptions{OverlayPath: "fixture/overlay"})
+	require.NoError(t, err)
+	defer h.Close()
+	assert.Equal(t, "branded asset\n", get(h, "/static/asset.txt"))
+	assert.Contains(t, get(h, "/static/custom.css"), "--jaeger-logo")
+	index := get(h, "/search")
+	assert.Contains(t, index, "Branded Test Page")
+	assert.Contains(t, index, `<link rel="stylesheet" href="static/custom.css"></head>`)
+
+	_, err = NewStaticAssetsHandler("fixture", StaticAssetsHandlerOptions{OverlayPath: "fixture/missing"})
+	require.ErrorContains(t, err, "cannot open the static assets overlay")
+	_, err = NewStaticAssetsHandler("fixture", StaticAssetsHandlerOptions{OverlayPath: "fixture/index.html"})
+	require.EqualError(t, err, "the static assets overlay fixture/index.html is not a directory")
+}
+
+func TestHotReloadTenantUIConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfgFile, err := os.CreateTemp(dir, "*.json")
+	require.NoError(t, err)
+	defer cfgFile.Close()
+	cfgFileName := cfgFile.Name()
+
+	tmpFile, err := os.CreateTemp(dir, "*.json")
+	require.NoError(t, err)
+	defer tmpFile.Close()
+
+	content, err := os.ReadFile("fixture/ui-config-hotreload.json")
+	require.NoError(t, err)
+	require.NoError(t, syncWrite(cfgFile, tmpFile, content))
+
+	zcore, logObserver := observer.New(zapcore.InfoLevel)
+	h, err := NewStaticAssetsHandler("fixture", StaticAssetsHandlerOptions{
+		TenantUIConfigPaths: map[string]string{"acme": cfgFileName},
+		TenancyHeader:       "x-tenant",
+		Logger:              zap.New(zcore),
+	})
+	require.NoError(t, err)
+	defer h.Close()
+	tenantIndexHTML := func() string {
+		return string(h.tenantIndexHTML.Load().(map[string][]byte)["acme"])
+	}
+	assert.Contains(t, tenantIndexHTML(), "About Jaeger")
+
+	require.NoError(t, syncWrite(cfgFile, tmpFile, []byte("{")))
+	waitUntil(t, func() bool {
+		return logObserver.FilterMessage("error while reloading the UI config").Len() > 0
+	}, 100, 10*time.Millisecond, "timed out waiting for the hot reload to kick in")
+	assert.Contains(t, tenantIndexHTML(), "About Jaeger", "the previous page is kept on errors")
+
+	newContent := strings.Replace(string(content), "About Jaeger", "About a new Jaeger", 1)
+	require.NoError(t, syncWrite(cfgFile, tmpFile, []byte(newContent)))
+	waitUntil(t, func() bool {
+		return logObserver.FilterMessage("reloaded UI config").Len() > 0
+	}, 100, 10*time.Millisecond, "timed out waiting for the hot reload to kick in")
+	assert.Contains(t, tenantIndexHTML(), "About a new Jaeger", logObserver.All())
+}
+
 func TestLoadUIConfig(t *testing.T) {
 	type testCase struct {
 		configFile    string
@@ -15,6 +15,7 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -25,6 +26,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
@@ -35,6 +39,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/plugin/storage/es"
 	"github.com/jaegertracing/jaeger/ports"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
 const (
@@ -144,3 +149,24 @@ func TestBearerTokenPropagation(t *testing.T) {
 		})
 	}
 }
+
+func TestBearerTokenPropagationGRPC(t *testing.T) {
+	esSrv := runMockElasticsearchServer(t)
+	defer esSrv.Close()
+
+	querySrv := runQueryService(t, esSrv.URL)
+	defer querySrv.Close()
+
+	conn, err := grpc.NewClient(querySrv.grpcConn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := api_v2.NewQueryServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", bearerHeader)
+	_, err = client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+
+	// without a token the storage rejects the request
+	_, err = client.GetServices(context.Background(), &api_v2.GetServicesRequest{})
+	require.Error(t, err)
+}
@@ -43,7 +43,6 @@ import (
 	"github.com/jaegertracing/jaeger/plugin/storage"
 	"github.com/jaegertracing/jaeger/ports"
 	metricsstoreMetrics "github.com/jaegertracing/jaeger/storage/metricsstore/metrics"
-	spanstoreMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
 
 func main() {
@@ -70,10 +69,6 @@ func main() {
 				return err
 			}
 			logger := svc.Logger // shortcut
-			baseFactory := svc.MetricsFactory.Namespace(metrics.NSOptions{Name: "jaeger"})
-			metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "query"})
-			version.NewInfoMetrics(metricsFactory)
-
 			queryOpts, err := new(app.QueryOptions).InitFromViper(v, logger)
 			if err != nil {
 				logger.Fatal("Failed to configure query service", zap.Error(err))
@@ -86,6 +81,13 @@ func main() {
 					logger.Fatal("Failed to create tracer", zap.Error(err))
 				}
 			}
+			telset, err := svc.TelemetrySetting("jaeger-query", jt)
+			if err != nil {
+				logger.Fatal("Failed to create telemetry", zap.Error(err))
+			}
+			baseFactory := telset.Metrics
+			metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "query"})
+			version.NewInfoMetrics(metricsFactory)
 
 			// TODO: Need to figure out set enable/disable propagation on storage plugins.
 			v.Set(bearertoken.StoragePropagationKey, queryOpts.BearerTokenPropagation)
@@ -93,11 +95,12 @@ func main() {
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			svc.HC().AddProbe("storage", storageFactory.CheckHealth)
+			svc.Admin.Handle("/storage/capabilities", storageFactory.CapabilitiesHandler())
 			spanReader, err := storageFactory.CreateSpanReader()
 			if err != nil {
 				logger.Fatal("Failed to create span reader", zap.Error(err))
 			}
-			spanReader = spanstoreMetrics.NewReadMetricsDecorator(spanReader, metricsFactory)
 			dependencyReader, err := storageFactory.CreateDependencyReader()
 			if err != nil {
 				logger.Fatal("Failed to create dependency reader", zap.Error(err))
@@ -127,6 +130,9 @@ func main() {
 				if err := storageFactory.Close(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
+				if err := telset.Close(context.Background()); err != nil {
+					logger.Error("Failed to close telemetry", zap.Error(err))
+				}
 				if err = jt.Close(context.Background()); err != nil {
 					logger.Fatal("Error shutting down tracer provider", zap.Error(err))
 				}
@@ -27,7 +27,8 @@ import (
 )
 
 const (
-	flagGRPCHostPort = "grpc.host-port"
+	flagGRPCHostPort     = "grpc.host-port"
+	flagTokenPropagation = "grpc.bearer-token-propagation"
 )
 
 var tlsGRPCFlagsConfig = tlscfg.ServerFlagsConfig{
@@ -42,11 +43,14 @@ type Options struct {
 	TLSGRPC tlscfg.Options
 	// Tenancy configuration
 	Tenancy tenancy.Options
+	// BearerTokenPropagation activate/deactivate bearer token propagation to storage
+	BearerTokenPropagation bool
 }
 
 // AddFlags adds flags to flag set.
 func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.String(flagGRPCHostPort, ports.PortToHostPort(ports.RemoteStorageGRPC), "The host:port (e.g. 127.0.0.1:17271 or :17271) of the gRPC server")
+	flagSet.Bool(flagTokenPropagation, false, "Allow propagation of the bearer token of the gRPC requests to the storage backend")
 	tlsGRPCFlagsConfig.AddFlags(flagSet)
 	tenancy.AddFlags(flagSet)
 }
@@ -60,5 +64,6 @@ func (o *Options) InitFromViper(v *viper.Viper, _ *zap.Logger) (*Options, error)
 	}
 	o.TLSGRPC = tlsGrpc
 	o.Tenancy = tenancy.InitFromViper(v)
+	o.BearerTokenPropagation = v.GetBool(flagTokenPropagation)
 	return o, nil
 }
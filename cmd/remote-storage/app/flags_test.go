@@ -28,10 +28,12 @@ func TestFlags(t *testing.T) {
 	v, command := config.Viperize(AddFlags)
 	command.ParseFlags([]string{
 		"--grpc.host-port=127.0.0.1:8081",
+		"--grpc.bearer-token-propagation=true",
 	})
 	qOpts, err := new(Options).InitFromViper(v, zap.NewNop())
 	require.NoError(t, err)
 	assert.Equal(t, "127.0.0.1:8081", qOpts.GRPCHostPort)
+	assert.True(t, qOpts.BearerTokenPropagation)
 }
 
 func TestFailedTLSFlags(t *testing.T) {
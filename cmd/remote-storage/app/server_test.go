@@ -31,9 +31,11 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
 	"github.com/jaegertracing/jaeger/internal/grpctest"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/storage/grpc/shared"
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/proto-gen/storage_v1"
 	depStoreMocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
@@ -405,6 +407,34 @@ func TestServerHandlesPortZero(t *testing.T) {
 	assert.Equal(t, healthcheck.Unavailable, flagsSvc.HC().Get())
 }
 
+func TestServerBearerTokenPropagation(t *testing.T) {
+	storageMocks := newStorageMocks()
+	storageMocks.reader.On("GetServices", mock.MatchedBy(func(ctx context.Context) bool {
+		token, _ := bearertoken.GetBearerToken(ctx)
+		return token == "blah"
+	})).Return([]string{"frontend"}, nil)
+
+	server, err := NewServer(
+		&Options{GRPCHostPort: "127.0.0.1:0", BearerTokenPropagation: true},
+		storageMocks.factory,
+		tenancy.NewManager(&tenancy.Options{}),
+		zap.NewNop(),
+		healthcheck.New(),
+	)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	conn, err := grpc.NewClient(server.grpcConn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := bearertoken.ContextWithBearerToken(context.Background(), "blah")
+	services, err := shared.NewGRPCClient(conn).GetServices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, services)
+}
+
 func validateGRPCServer(t *testing.T, hostPort string, server *grpc.Server) {
 	grpctest.ReflectionServiceValidator{
 		HostPort: hostPort,
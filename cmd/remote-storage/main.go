@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/internal/printconfig"
 	"github.com/jaegertracing/jaeger/cmd/internal/status"
 	"github.com/jaegertracing/jaeger/cmd/remote-storage/app"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -62,7 +64,11 @@ func main() {
 				return err
 			}
 			logger := svc.Logger // shortcut
-			baseFactory := svc.MetricsFactory.Namespace(metrics.NSOptions{Name: "jaeger"})
+			telset, err := svc.TelemetrySetting(serviceName, nil)
+			if err != nil {
+				logger.Fatal("Failed to create telemetry", zap.Error(err))
+			}
+			baseFactory := telset.Metrics
 			metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "remote-storage"})
 			version.NewInfoMetrics(metricsFactory)
 
@@ -71,10 +77,12 @@ func main() {
 				logger.Fatal("Failed to parse options", zap.Error(err))
 			}
 
+			v.Set(bearertoken.StoragePropagationKey, opts.BearerTokenPropagation)
 			storageFactory.InitFromViper(v, logger)
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			svc.HC().AddProbe("storage", storageFactory.CheckHealth)
 
 			tm := tenancy.NewManager(&opts.Tenancy)
 			server, err := app.NewServer(opts, storageFactory, tm, svc.Logger, svc.HC())
@@ -91,6 +99,9 @@ func main() {
 				if err := storageFactory.Close(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
+				if err := telset.Close(context.Background()); err != nil {
+					logger.Error("Failed to close telemetry", zap.Error(err))
+				}
 			})
 			return nil
 		},
@@ -58,24 +58,50 @@ func main() {
 	otel.SetTextMapPropagator(propagation.TraceContext{})
 	jaegerclientenv2otel.MapJaegerToOtelEnvVars(logger)
 
-	tracers, shutdown := createTracers(cfg, logger)
+	if cfg.Topology != "" {
+		topology, err := tracegen.LoadTopology(cfg.Topology)
+		if err != nil {
+			logger.Fatal("cannot load topology", zap.Error(err))
+		}
+		services := topology.ServiceNames()
+		tracers, shutdown := createTracers(cfg, services, logger)
+		defer shutdown(context.Background())
+
+		tracersByService := make(map[string]trace.Tracer, len(services))
+		for i, svc := range services {
+			tracersByService[svc] = tracers[i]
+		}
+		if err := tracegen.RunTopology(cfg, topology, tracersByService, logger); err != nil {
+			logger.Error("error running topology", zap.Error(err))
+		}
+		return
+	}
+
+	tracers, shutdown := createTracers(cfg, serviceNames(cfg), logger)
 	defer shutdown(context.Background())
 
 	tracegen.Run(cfg, tracers, logger)
 }
 
-func createTracers(cfg *tracegen.Config, logger *zap.Logger) ([]trace.Tracer, func(context.Context) error) {
+func serviceNames(cfg *tracegen.Config) []string {
 	if cfg.Services < 1 {
 		cfg.Services = 1
 	}
-	var shutdown []func(context.Context) error
-	var tracers []trace.Tracer
+	var services []string
 	for s := 0; s < cfg.Services; s++ {
 		svc := cfg.Service
 		if cfg.Services > 1 {
 			svc = fmt.Sprintf("%s-%02d", svc, s)
 		}
+		services = append(services, svc)
+	}
+	return services
+}
 
+func createTracers(cfg *tracegen.Config, services []string, logger *zap.Logger) ([]trace.Tracer, func(context.Context) error) {
+	var shutdown []func(context.Context) error
+	var tracers []trace.Tracer
+	for _, svc := range services {
 		exp, err := createOtelExporter(cfg.TraceExporter)
 		if err != nil {
 			logger.Sugar().Fatalf("cannot create trace exporter %s: %s", cfg.TraceExporter, err)
@@ -94,10 +120,20 @@ func createTracers(cfg *tracegen.Config, logger *zap.Logger) ([]trace.Tracer, fu
 			logger.Sugar().Fatalf("resource creation failed: %s", err)
 		}
 
-		tp := sdktrace.NewTracerProvider(
+		opts := []sdktrace.TracerProviderOption{
 			sdktrace.WithBatcher(exp, sdktrace.WithBlocking()),
 			sdktrace.WithResource(res),
-		)
+		}
+		if cfg.SamplingEndpoint != "" {
+			sampler := tracegen.NewRemoteSampler(cfg.SamplingEndpoint, svc, cfg.SamplingRefreshInterval, logger)
+			opts = append(opts, sdktrace.WithSampler(sdktrace.ParentBased(sampler)))
+			shutdown = append(shutdown, func(context.Context) error {
+				sampler.Close()
+				return nil
+			})
+		}
+
+		tp := sdktrace.NewTracerProvider(opts...)
 		tracers = append(tracers, tp.Tracer(cfg.Service))
 		shutdown = append(shutdown, tp.Shutdown)
 	}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Options represent configurable parameters for jaeger-wal-replay
+type Options struct {
+	Dir            string
+	KeepReplayed   bool
+	SpansPerSecond float64
+}
+
+const (
+	dirFlag            = "wal.dir"
+	keepReplayedFlag   = "keep-replayed"
+	spansPerSecondFlag = "spans-per-second"
+)
+
+// AddFlags adds flags for wal-replay main program
+func (o *Options) AddFlags(command *cobra.Command) {
+	command.Flags().StringVar(
+		&o.Dir,
+		dirFlag,
+		"",
+		"The directory of the write-ahead log, as set with --collector.wal.dir on the collectors (required)")
+	command.Flags().BoolVar(
+		&o.KeepReplayed,
+		keepReplayedFlag,
+		false,
+		"Renames the replayed segments with the suffix .replayed instead of deleting them")
+	command.Flags().Float64Var(
+		&o.SpansPerSecond,
+		spansPerSecondFlag,
+		0,
+		"The maximum number of spans written per second, to avoid overloading a recovering storage backend; 0 means no limit")
+}
+
+// Validate checks that the combination of options is valid.
+func (o *Options) Validate() error {
+	if o.Dir == "" {
+		return fmt.Errorf("--%s is required", dirFlag)
+	}
+	if o.SpansPerSecond < 0 {
+		return fmt.Errorf("--%s must not be negative", spansPerSecondFlag)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsWithDefaultFlags(t *testing.T) {
+	o := Options{}
+	c := cobra.Command{}
+	o.AddFlags(&c)
+
+	assert.Empty(t, o.Dir)
+	assert.False(t, o.KeepReplayed)
+	assert.Zero(t, o.SpansPerSecond)
+	require.ErrorContains(t, o.Validate(), "--wal.dir is required")
+}
+
+func TestOptionsWithFlags(t *testing.T) {
+	o := Options{}
+	c := cobra.Command{}
+	o.AddFlags(&c)
+	require.NoError(t, c.ParseFlags([]string{
+		"--wal.dir=/var/lib/jaeger/wal",
+		"--keep-replayed",
+		"--spans-per-second=500",
+	}))
+
+	assert.Equal(t, "/var/lib/jaeger/wal", o.Dir)
+	assert.True(t, o.KeepReplayed)
+	assert.InDelta(t, 500, o.SpansPerSecond, 0)
+	require.NoError(t, o.Validate())
+}
+
+func TestOptionsValidate(t *testing.T) {
+	o := Options{Dir: "/var/lib/jaeger/wal", SpansPerSecond: -1}
+	require.ErrorContains(t, o.Validate(), "--spans-per-second must not be negative")
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// replayedSuffix is appended to the name of the replayed segments kept with --keep-replayed.
+const replayedSuffix = ".replayed"
+
+// replayBatchSize is the maximum number of spans written at once to a spanstore.BatchWriter.
+const replayBatchSize = 1000
+
+// Stats summarizes a replay.
+type Stats struct {
+	Segments int64
+	Spans    int64
+}
+
+// Replayer writes the spans recorded in the write-ahead log of the collectors to the span storage.
+//
+// The closed segments are replayed oldest first and removed once all their spans are written,
+// so that the segments left by an interrupted replay are replayed again, including the spans
+// of the interrupted segment already written. The segments still written by a collector are
+// not replayed, the collectors close them periodically.
+//
+// The spans are written in batches when the writer is a spanstore.BatchWriter, whose writes
+// complete before WriteBatch returns, unlike WriteSpan of the writers buffering the spans,
+// e.g. in the bulk processor of Elasticsearch, so that no segment is removed before its spans
+// are stored.
+type Replayer struct {
+	writer  spanstore.Writer
+	options Options
+	limiter *rate.Limiter
+	logger  *zap.Logger
+}
+
+// NewReplayer creates a Replayer.
+func NewReplayer(writer spanstore.Writer, options Options, logger *zap.Logger) *Replayer {
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if options.SpansPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(options.SpansPerSecond), int(math.Max(1, options.SpansPerSecond)))
+	}
+	return &Replayer{
+		writer:  writer,
+		options: options,
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// Run replays the segments, stopping at the first error.
+func (r *Replayer) Run(ctx context.Context) (Stats, error) {
+	var stats Stats
+	segments, err := wal.Segments(r.options.Dir)
+	if err != nil {
+		return stats, err
+	}
+	for _, segment := range segments {
+		spans, err := r.replaySegment(ctx, segment)
+		stats.Spans += spans
+		if err != nil {
+			return stats, fmt.Errorf("failed to replay the segment %s: %w", segment, err)
+		}
+		if err := r.remove(segment); err != nil {
+			return stats, err
+		}
+		stats.Segments++
+		r.logger.Info("Replayed a segment", zap.String("segment", segment), zap.Int64("spans", spans))
+	}
+	return stats, nil
+}
+
+func (r *Replayer) replaySegment(ctx context.Context, segment string) (int64, error) {
+	batchWriter, batching := r.writer.(spanstore.BatchWriter)
+	var spans int64
+	// the batch holds the spans of a single tenant
+	var batch []*model.Span
+	var batchTenant string
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := batchWriter.WriteBatch(tenantContext(ctx, batchTenant), batch); err != nil {
+			return fmt.Errorf("failed to write spans: %w", err)
+		}
+		spans += int64(len(batch))
+		batch = nil
+		return nil
+	}
+	err := wal.ReadSegment(segment, func(span *model.Span, tenant string) error {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if !batching {
+			if err := r.writer.WriteSpan(tenantContext(ctx, tenant), span); err != nil {
+				return fmt.Errorf("failed to write span: %w", err)
+			}
+			spans++
+			return nil
+		}
+		if tenant != batchTenant {
+			if err := flush(); err != nil {
+				return err
+			}
+			batchTenant = tenant
+		}
+		batch = append(batch, span)
+		if len(batch) == replayBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	return spans, err
+}
+
+// tenantContext returns the context of the writes of the spans of the tenant.
+func tenantContext(ctx context.Context, tenant string) context.Context {
+	if tenant == "" {
+		return ctx
+	}
+	return tenancy.WithTenant(ctx, tenant)
+}
+
+func (r *Replayer) remove(segment string) error {
+	if r.options.KeepReplayed {
+		return os.Rename(segment, segment+replayedSuffix)
+	}
+	return os.Remove(segment)
+}
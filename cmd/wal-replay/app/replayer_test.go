@@ -0,0 +1,179 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/wal"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// recordingWriter records the spans and their tenant, failing after failAfter spans if it is positive.
+type recordingWriter struct {
+	mu        sync.Mutex
+	spans     []string
+	failAfter int
+}
+
+func (w *recordingWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failAfter > 0 && len(w.spans) == w.failAfter {
+		return errors.New("storage unavailable")
+	}
+	w.spans = append(w.spans, span.OperationName+"@"+tenancy.GetTenant(ctx))
+	return nil
+}
+
+// recordingBatchWriter records the batches of spans and their tenant, failing if err is set.
+// Its WriteSpan buffers the spans like the Elasticsearch writer, it must not be used.
+type recordingBatchWriter struct {
+	recordingWriter
+	batches [][]string
+	err     error
+}
+
+func (w *recordingBatchWriter) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	if w.err != nil {
+		return w.err
+	}
+	var batch []string
+	for _, span := range spans {
+		batch = append(batch, span.OperationName+"@"+tenancy.GetTenant(ctx))
+	}
+	w.batches = append(w.batches, batch)
+	return nil
+}
+
+// writeWAL records the spans in a segment of the WAL per tenant.
+func writeWAL(t *testing.T, dir string, tenants ...string) {
+	for _, tenant := range tenants {
+		w, err := wal.NewWriter(wal.Options{Dir: dir, MaxSegmentSize: 1 << 20}, metrics.NullFactory, zap.NewNop())
+		require.NoError(t, err)
+		for _, operation := range []string{"op1", "op2"} {
+			span := &model.Span{OperationName: operation, Process: &model.Process{ServiceName: "svc"}}
+			require.NoError(t, w.Write(span, tenant))
+		}
+		require.NoError(t, w.Close())
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "", "acme")
+	writer := &recordingWriter{}
+
+	stats, err := NewReplayer(writer, Options{Dir: dir}, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Segments: 2, Spans: 4}, stats)
+	assert.Equal(t, []string{"op1@", "op2@", "op1@acme", "op2@acme"}, writer.spans)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestReplayerBatches(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "", "acme")
+	writer := &recordingBatchWriter{}
+
+	stats, err := NewReplayer(writer, Options{Dir: dir}, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Segments: 2, Spans: 4}, stats)
+	assert.Equal(t, [][]string{{"op1@", "op2@"}, {"op1@acme", "op2@acme"}}, writer.batches)
+	assert.Empty(t, writer.spans)
+	segments, err := wal.Segments(dir)
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+}
+
+func TestReplayerBatchFailureKeepsSegment(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "")
+
+	stats, err := NewReplayer(&recordingBatchWriter{err: errors.New("bulk request failed")}, Options{Dir: dir}, zap.NewNop()).Run(context.Background())
+	require.ErrorContains(t, err, "bulk request failed")
+	assert.Equal(t, Stats{}, stats)
+	segments, err := wal.Segments(dir)
+	require.NoError(t, err)
+	assert.Len(t, segments, 1)
+}
+
+func TestReplayerKeepReplayed(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "")
+
+	stats, err := NewReplayer(&recordingWriter{}, Options{Dir: dir, KeepReplayed: true}, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Segments: 1, Spans: 2}, stats)
+	replayed, err := filepath.Glob(filepath.Join(dir, "*"+wal.SegmentSuffix+replayedSuffix))
+	require.NoError(t, err)
+	assert.Len(t, replayed, 1)
+
+	// the kept segments are not replayed again
+	stats, err = NewReplayer(&recordingWriter{}, Options{Dir: dir, KeepReplayed: true}, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, stats)
+}
+
+func TestReplayerResumes(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "first", "second")
+
+	_, err := NewReplayer(&recordingWriter{failAfter: 3}, Options{Dir: dir}, zap.NewNop()).Run(context.Background())
+	require.ErrorContains(t, err, "storage unavailable")
+	segments, err := wal.Segments(dir)
+	require.NoError(t, err)
+	assert.Len(t, segments, 1)
+
+	// the spans of the interrupted segment are written again
+	writer := &recordingWriter{}
+	stats, err := NewReplayer(writer, Options{Dir: dir}, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Segments: 1, Spans: 2}, stats)
+	assert.Equal(t, []string{"op1@second", "op2@second"}, writer.spans)
+}
+
+func TestReplayerRateLimit(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "")
+
+	start := time.Now()
+	stats, err := NewReplayer(&recordingWriter{}, Options{Dir: dir, SpansPerSecond: 1}, zap.NewNop()).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Segments: 1, Spans: 2}, stats)
+	// the first span is written at once, the second one a second later
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestReplayerCanceled(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewReplayer(&recordingWriter{}, Options{Dir: dir}, zap.NewNop()).Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	segments, err := wal.Segments(dir)
+	require.NoError(t, err)
+	assert.Len(t, segments, 1)
+}
+
+func TestReplayerMissingDir(t *testing.T) {
+	_, err := NewReplayer(&recordingWriter{}, Options{Dir: filepath.Join(t.TempDir(), "missing")}, zap.NewNop()).Run(context.Background())
+	require.ErrorContains(t, err, "failed to list the WAL segments")
+}
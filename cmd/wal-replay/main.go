@@ -0,0 +1,89 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/wal-replay/app"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+)
+
+var logger, _ = zap.NewDevelopment()
+
+func main() {
+	options := app.Options{}
+	v := viper.New()
+
+	storageFactory, err := storage.NewFactory(storage.FactoryConfigFromEnvAndCLI(os.Args, os.Stderr))
+	if err != nil {
+		log.Fatalf("Cannot initialize storage factory: %v", err)
+	}
+
+	command := &cobra.Command{
+		Use:   "jaeger-wal-replay",
+		Short: "Jaeger WAL replay writes the spans recorded in the write-ahead log of the collectors to the storage",
+		Long: `Jaeger WAL replay writes the spans which the collectors failed to write to the storage, recorded in their
+write-ahead log enabled with --collector.wal.dir, to the storage backend configured via SPAN_STORAGE_TYPE and the
+storage flags. The replayed segments are removed, it can be run while the collectors are writing to the write-ahead log.`,
+		Run: func(_ *cobra.Command, _ /* args */ []string) {
+			if err := options.Validate(); err != nil {
+				logger.Fatal("invalid options", zap.Error(err))
+			}
+			if err := replay(v, storageFactory, &options); err != nil {
+				logger.Fatal("Replay failed, run it again to replay the remaining segments", zap.Error(err))
+			}
+		},
+	}
+
+	options.AddFlags(command)
+	config.AddFlags(
+		v,
+		command,
+		storageFactory.AddFlags,
+	)
+
+	command.AddCommand(version.Command())
+
+	if err := command.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func replay(v *viper.Viper, storageFactory *storage.Factory, options *app.Options) error {
+	storageFactory.InitFromViper(v, logger)
+	if err := storageFactory.Initialize(metrics.NullFactory, logger); err != nil {
+		return fmt.Errorf("failed to init storage factory: %w", err)
+	}
+	// closing the factory flushes the spans buffered by its writer
+	defer func() {
+		if err := storageFactory.Close(); err != nil {
+			logger.Error("Failed to close storage factory", zap.Error(err))
+		}
+	}()
+
+	writer, err := storageFactory.CreateSpanWriter()
+	if err != nil {
+		return fmt.Errorf("failed to create span writer: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stats, err := app.NewReplayer(writer, *options, logger).Run(ctx)
+	logger.Info("Replayed the write-ahead log", zap.Int64("segments", stats.Segments), zap.Int64("spans", stats.Spans))
+	return err
+}
@@ -22,6 +22,8 @@ import (
 
 var (
 	otelExporter string // otlp, stdout
+	otlpEndpoint string
+	otlpProtocol string // grpc, http/protobuf
 	verbose      bool
 
 	fixDBConnDelay         time.Duration
@@ -40,6 +42,8 @@ var (
 // used by root command
 func addFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVarP(&otelExporter, "otel-exporter", "x", "otlp", "OpenTelemetry exporter (otlp|stdout)")
+	cmd.PersistentFlags().StringVar(&otlpEndpoint, "otel-exporter-otlp-endpoint", "", "OTLP endpoint, e.g. http://localhost:4318 (overrides OTEL_EXPORTER_OTLP_ENDPOINT)")
+	cmd.PersistentFlags().StringVar(&otlpProtocol, "otel-exporter-otlp-protocol", "", "OTLP protocol (http/protobuf|grpc), defaults to http/protobuf (overrides OTEL_EXPORTER_OTLP_PROTOCOL)")
 
 	cmd.PersistentFlags().DurationVarP(&fixDBConnDelay, "fix-db-query-delay", "D", 300*time.Millisecond, "Average latency of MySQL DB query")
 	cmd.PersistentFlags().BoolVarP(&fixDBConnDisableMutex, "fix-disable-db-conn-mutex", "M", false, "Disables the mutex guarding db connection")
@@ -70,6 +70,16 @@ func onInitialize() {
 	logger, _ = zap.NewDevelopment(zapOptions...)
 	metricsFactory = prometheus.New().Namespace(metrics.NSOptions{Name: "hotrod", Tags: nil})
 
+	// the OTEL SDK is configured via environment variables, so the flags are mapped to them
+	if otlpEndpoint != "" {
+		logger.Info("using OTLP endpoint", zap.String("endpoint", otlpEndpoint))
+		os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", otlpEndpoint)
+	}
+	if otlpProtocol != "" {
+		logger.Info("using OTLP protocol", zap.String("protocol", otlpProtocol))
+		os.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", otlpProtocol)
+	}
+
 	if config.MySQLGetDelay != fixDBConnDelay {
 		logger.Info("fix: overriding MySQL query delay", zap.Duration("old", config.MySQLGetDelay), zap.Duration("new", fixDBConnDelay))
 		config.MySQLGetDelay = fixDBConnDelay
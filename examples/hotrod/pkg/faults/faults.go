@@ -0,0 +1,201 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package faults
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/httperr"
+)
+
+// ErrInjected is returned when a fault is injected into a request.
+var ErrInjected = errors.New("injected fault")
+
+// Default is the injector shared by all services running in the same process.
+var Default = NewInjector()
+
+// Settings describes the faults injected into the requests of a service.
+type Settings struct {
+	// ErrorRate is the probability in [0, 1] that a request fails.
+	ErrorRate float64
+	// Latency is added to each request.
+	Latency time.Duration
+}
+
+// Injector holds fault injection settings per service, which can be changed at runtime.
+type Injector struct {
+	lock     sync.RWMutex
+	settings map[string]Settings
+}
+
+// NewInjector creates an Injector without any faults.
+func NewInjector() *Injector {
+	return &Injector{
+		settings: make(map[string]Settings),
+	}
+}
+
+// Set replaces the fault injection settings of the service.
+func (i *Injector) Set(service string, settings Settings) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.settings[service] = settings
+}
+
+// Reset removes the fault injection settings of the service.
+func (i *Injector) Reset(service string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	delete(i.settings, service)
+}
+
+// Get returns the fault injection settings of the service.
+func (i *Injector) Get(service string) Settings {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.settings[service]
+}
+
+// Inject applies the faults configured for the service: it blocks for the added latency,
+// and returns ErrInjected if the request should fail. Injected faults are recorded as
+// events on the current span.
+func (i *Injector) Inject(ctx context.Context, service string) error {
+	settings := i.Get(service)
+	span := trace.SpanFromContext(ctx)
+	if settings.Latency > 0 {
+		span.AddEvent("fault injection: added latency", trace.WithAttributes(
+			attribute.String("latency", settings.Latency.String()),
+		))
+		select {
+		case <-time.After(settings.Latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if settings.ErrorRate > 0 && rand.Float64() < settings.ErrorRate {
+		span.AddEvent("fault injection: error", trace.WithAttributes(
+			attribute.Float64("error_rate", settings.ErrorRate),
+		))
+		return fmt.Errorf("%w in service %s", ErrInjected, service)
+	}
+	return nil
+}
+
+// HTTPMiddleware injects the faults configured for the service into HTTP requests,
+// failing requests with status code 500.
+func (i *Injector) HTTPMiddleware(service string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := i.Inject(r.Context(), service); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryServerInterceptor injects the faults configured for the service into gRPC requests,
+// failing requests with status code Unavailable.
+func (i *Injector) UnaryServerInterceptor(service string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := i.Inject(ctx, service); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Handler returns the admin endpoint that controls the fault injection:
+//
+//	GET    /faults                                           lists the faults of all services
+//	POST   /faults?service=customer&error_rate=0.1&latency=100ms sets the faults of a service
+//	DELETE /faults?service=customer                          removes the faults of a service
+func (i *Injector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost, http.MethodPut:
+			service, settings, err := parseSettings(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			i.Set(service, settings)
+		case http.MethodDelete:
+			service := r.FormValue("service")
+			if service == "" {
+				http.Error(w, "Missing required 'service' parameter", http.StatusBadRequest)
+				return
+			}
+			i.Reset(service)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		data, err := json.Marshal(i.list())
+		if httperr.HandleError(w, err, http.StatusInternalServerError) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+type serviceSettings struct {
+	Service   string  `json:"service"`
+	ErrorRate float64 `json:"error_rate"`
+	Latency   string  `json:"latency"`
+}
+
+func (i *Injector) list() []serviceSettings {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	out := make([]serviceSettings, 0, len(i.settings))
+	for service, s := range i.settings {
+		out = append(out, serviceSettings{
+			Service:   service,
+			ErrorRate: s.ErrorRate,
+			Latency:   s.Latency.String(),
+		})
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Service < out[b].Service })
+	return out
+}
+
+func parseSettings(r *http.Request) (string, Settings, error) {
+	var settings Settings
+	service := r.FormValue("service")
+	if service == "" {
+		return "", settings, errors.New("missing required 'service' parameter")
+	}
+	if v := r.FormValue("error_rate"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return "", settings, errors.New("parameter 'error_rate' must be a number in [0, 1]")
+		}
+		settings.ErrorRate = rate
+	}
+	if v := r.FormValue("latency"); v != "" {
+		latency, err := time.ParseDuration(v)
+		if err != nil || latency < 0 {
+			return "", settings, errors.New("parameter 'latency' must be a non-negative duration, e.g. 100ms")
+		}
+		settings.Latency = latency
+	}
+	return service, settings, nil
+}
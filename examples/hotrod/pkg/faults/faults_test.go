@@ -0,0 +1,257 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package faults
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		service  string
+		settings Settings
+		err      string
+	}{
+		{
+			name:    "service only",
+			query:   "service=customer",
+			service: "customer",
+		},
+		{
+			name:     "all settings",
+			query:    "service=customer&error_rate=0.25&latency=100ms",
+			service:  "customer",
+			settings: Settings{ErrorRate: 0.25, Latency: 100 * time.Millisecond},
+		},
+		{
+			name:  "missing service",
+			query: "error_rate=0.5",
+			err:   "missing required 'service' parameter",
+		},
+		{
+			name:  "invalid error rate",
+			query: "service=customer&error_rate=often",
+			err:   "parameter 'error_rate' must be a number in [0, 1]",
+		},
+		{
+			name:  "error rate above 1",
+			query: "service=customer&error_rate=1.5",
+			err:   "parameter 'error_rate' must be a number in [0, 1]",
+		},
+		{
+			name:  "negative error rate",
+			query: "service=customer&error_rate=-0.1",
+			err:   "parameter 'error_rate' must be a number in [0, 1]",
+		},
+		{
+			name:  "invalid latency",
+			query: "service=customer&latency=slow",
+			err:   "parameter 'latency' must be a non-negative duration, e.g. 100ms",
+		},
+		{
+			name:  "negative latency",
+			query: "service=customer&latency=-1s",
+			err:   "parameter 'latency' must be a non-negative duration, e.g. 100ms",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/faults?"+test.query, nil)
+			service, settings, err := parseSettings(r)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.service, service)
+			assert.Equal(t, test.settings, settings)
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	injector := NewInjector()
+	injector.Set("route", Settings{Latency: time.Second})
+	handler := injector.Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		body   string
+	}{
+		{
+			name:   "list",
+			method: http.MethodGet,
+			target: "/faults",
+			status: http.StatusOK,
+			body:   `[{"service":"route","error_rate":0,"latency":"1s"}]`,
+		},
+		{
+			name:   "set",
+			method: http.MethodPost,
+			target: "/faults?service=customer&error_rate=0.1&latency=100ms",
+			status: http.StatusOK,
+			body:   `[{"service":"customer","error_rate":0.1,"latency":"100ms"},{"service":"route","error_rate":0,"latency":"1s"}]`,
+		},
+		{
+			name:   "set invalid",
+			method: http.MethodPut,
+			target: "/faults?service=customer&error_rate=2",
+			status: http.StatusBadRequest,
+			body:   "parameter 'error_rate' must be a number in [0, 1]",
+		},
+		{
+			name:   "reset",
+			method: http.MethodDelete,
+			target: "/faults?service=route",
+			status: http.StatusOK,
+			body:   `[{"service":"customer","error_rate":0.1,"latency":"100ms"}]`,
+		},
+		{
+			name:   "reset without service",
+			method: http.MethodDelete,
+			target: "/faults",
+			status: http.StatusBadRequest,
+			body:   "Missing required 'service' parameter",
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodPatch,
+			target: "/faults",
+			status: http.StatusMethodNotAllowed,
+			body:   "Method not allowed",
+		},
+	}
+	// the cases run in order, each one seeing the faults set by the previous ones
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(test.method, test.target, nil))
+			assert.Equal(t, test.status, w.Code)
+			assert.Equal(t, test.body, strings.TrimSpace(w.Body.String()))
+			if test.status == http.StatusOK {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestInject(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		settings Settings
+		err      error
+		events   []string
+	}{
+		{
+			name: "no faults",
+			ctx:  context.Background(),
+		},
+		{
+			name:     "latency",
+			ctx:      context.Background(),
+			settings: Settings{Latency: time.Millisecond},
+			events:   []string{"fault injection: added latency"},
+		},
+		{
+			name:     "latency canceled",
+			ctx:      canceled,
+			settings: Settings{Latency: time.Hour},
+			err:      context.Canceled,
+			events:   []string{"fault injection: added latency"},
+		},
+		{
+			name:     "error",
+			ctx:      context.Background(),
+			settings: Settings{ErrorRate: 1},
+			err:      ErrInjected,
+			events:   []string{"fault injection: error"},
+		},
+		{
+			name:     "latency and error",
+			ctx:      context.Background(),
+			settings: Settings{ErrorRate: 1, Latency: time.Millisecond},
+			err:      ErrInjected,
+			events:   []string{"fault injection: added latency", "fault injection: error"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			injector := NewInjector()
+			injector.Set("customer", test.settings)
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			ctx, span := tp.Tracer("test").Start(test.ctx, "request")
+
+			err := injector.Inject(ctx, "customer")
+			span.End()
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			var events []string
+			for _, event := range recorder.Ended()[0].Events() {
+				events = append(events, event.Name)
+			}
+			assert.Equal(t, test.events, events)
+			// the faults of the other services are not injected
+			require.NoError(t, injector.Inject(context.Background(), "route"))
+		})
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	injector := NewInjector()
+	handler := injector.HTTPMiddleware("customer", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customer", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	injector.Set("customer", Settings{ErrorRate: 1})
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customer", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "injected fault in service customer")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	injector := NewInjector()
+	interceptor := injector.UnaryServerInterceptor("driver")
+	handler := func(context.Context, any) (any, error) {
+		return "response", nil
+	}
+
+	resp, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "response", resp)
+
+	injector.Set("driver", Settings{ErrorRate: 1})
+	_, err = interceptor(context.Background(), "request", &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
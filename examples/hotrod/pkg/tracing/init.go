@@ -26,6 +26,7 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
@@ -81,6 +82,17 @@ func InitOTEL(serviceName string, exporterType string, metricsFactory metrics.Fa
 	return tp
 }
 
+// otlpProtocol returns the OTLP protocol configured via the standard environment variables.
+func otlpProtocol() string {
+	if p := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"); p != "" {
+		return p
+	}
+	if p := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); p != "" {
+		return p
+	}
+	return "http/protobuf"
+}
+
 // withSecure instructs the client to use HTTPS scheme, instead of hotrod's desired default HTTP
 func withSecure() bool {
 	return strings.HasPrefix(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "https://") ||
@@ -94,14 +106,24 @@ func createOtelExporter(exporterType string) (sdktrace.SpanExporter, error) {
 	case "jaeger":
 		return nil, errors.New("jaeger exporter is no longer supported, please use otlp")
 	case "otlp":
-		var opts []otlptracehttp.Option
-		if !withSecure() {
-			opts = []otlptracehttp.Option{otlptracehttp.WithInsecure()}
+		var client otlptrace.Client
+		switch protocol := otlpProtocol(); protocol {
+		case "grpc":
+			var opts []otlptracegrpc.Option
+			if !withSecure() {
+				opts = []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+			}
+			client = otlptracegrpc.NewClient(opts...)
+		case "http/protobuf":
+			var opts []otlptracehttp.Option
+			if !withSecure() {
+				opts = []otlptracehttp.Option{otlptracehttp.WithInsecure()}
+			}
+			client = otlptracehttp.NewClient(opts...)
+		default:
+			return nil, fmt.Errorf("unsupported OTLP protocol %s, must be grpc or http/protobuf", protocol)
 		}
-		exporter, err = otlptrace.New(
-			context.Background(),
-			otlptracehttp.NewClient(opts...),
-		)
+		exporter, err = otlptrace.New(context.Background(), client)
 	case "stdout":
 		exporter, err = stdouttrace.New()
 	default:
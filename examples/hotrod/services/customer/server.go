@@ -24,6 +24,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/faults"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/httperr"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/log"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/tracing"
@@ -65,7 +66,8 @@ func (s *Server) Run() error {
 
 func (s *Server) createServeMux() http.Handler {
 	mux := tracing.NewServeMux(false, s.tracer, s.logger)
-	mux.Handle("/customer", http.HandlerFunc(s.customer))
+	mux.Handle("/customer", faults.Default.HTTPMiddleware("customer", http.HandlerFunc(s.customer)))
+	mux.Handle("/faults", faults.Default.Handler())
 	return mux
 }
 
@@ -24,6 +24,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
+	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/faults"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/log"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/tracing"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
@@ -44,6 +45,7 @@ func NewServer(hostPort string, otelExporter string, metricsFactory metrics.Fact
 	tracerProvider := tracing.InitOTEL("driver", otelExporter, metricsFactory, logger)
 	server := grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tracerProvider))),
+		grpc.UnaryInterceptor(faults.Default.UnaryServerInterceptor("driver")),
 	)
 	return &Server{
 		hostPort: hostPort,
@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/faults"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/httperr"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/log"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/tracing"
@@ -88,7 +89,8 @@ func (s *Server) createServeMux() http.Handler {
 	mux := tracing.NewServeMux(true, s.tracer, s.logger)
 	p := path.Join("/", s.basepath)
 	mux.Handle(p, http.StripPrefix(p, http.FileServer(s.assetFS)))
-	mux.Handle(path.Join(p, "/dispatch"), http.HandlerFunc(s.dispatch))
+	mux.Handle(path.Join(p, "/dispatch"), faults.Default.HTTPMiddleware("frontend", http.HandlerFunc(s.dispatch)))
+	mux.Handle(path.Join(p, "/faults"), faults.Default.Handler())
 	mux.Handle(path.Join(p, "/config"), http.HandlerFunc(s.config))
 	mux.Handle(path.Join(p, "/debug/vars"), expvar.Handler()) // expvar
 	mux.Handle(path.Join(p, "/metrics"), promhttp.Handler())  // Prometheus
@@ -27,6 +27,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/delay"
+	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/faults"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/httperr"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/log"
 	"github.com/jaegertracing/jaeger/examples/hotrod/pkg/tracing"
@@ -63,7 +64,8 @@ func (s *Server) Run() error {
 
 func (s *Server) createServeMux() http.Handler {
 	mux := tracing.NewServeMux(false, s.tracer, s.logger)
-	mux.Handle("/route", http.HandlerFunc(s.route))
+	mux.Handle("/route", faults.Default.HTTPMiddleware("route", http.HandlerFunc(s.route)))
+	mux.Handle("/faults", faults.Default.Handler())
 	mux.Handle("/debug/vars", http.HandlerFunc(movedToFrontend))
 	mux.Handle("/metrics", http.HandlerFunc(movedToFrontend))
 	return mux
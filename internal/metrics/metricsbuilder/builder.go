@@ -69,7 +69,8 @@ func (b *Builder) InitFromViper(v *viper.Viper) *Builder {
 func (b *Builder) CreateMetricsFactory(namespace string) (metrics.Factory, error) {
 	if b.Backend == "prometheus" {
 		metricsFactory := jprom.New().Namespace(metrics.NSOptions{Name: namespace, Tags: nil})
-		b.handler = promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{DisableCompression: true})
+		// the exemplars of the histograms are only exposed in the OpenMetrics format
+		b.handler = promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{DisableCompression: true, EnableOpenMetrics: true})
 		return metricsFactory, nil
 	}
 	if b.Backend == "none" || b.Backend == "" {
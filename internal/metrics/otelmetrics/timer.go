@@ -19,3 +19,8 @@ type otelTimer struct {
 func (t *otelTimer) Record(d time.Duration) {
 	t.histogram.Record(t.fixedCtx, d.Seconds(), t.option)
 }
+
+// RecordWithContext implements metrics.ContextTimer, the SDK taking the exemplars from the context.
+func (t *otelTimer) RecordWithContext(ctx context.Context, d time.Duration) {
+	t.histogram.Record(ctx, d.Seconds(), t.option)
+}
@@ -15,11 +15,13 @@
 package prometheus
 
 import (
+	"context"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 )
@@ -247,6 +249,22 @@ func (t *timer) Record(v time.Duration) {
 	t.histogram.Observe(float64(v.Nanoseconds()) / float64(time.Second/time.Nanosecond))
 }
 
+// RecordWithContext implements metrics.ContextTimer, the sampled trace of the context being the
+// exemplar of the observation.
+func (t *timer) RecordWithContext(ctx context.Context, v time.Duration) {
+	value := float64(v.Nanoseconds()) / float64(time.Second/time.Nanosecond)
+	spanContext := trace.SpanContextFromContext(ctx)
+	exemplarObserver, ok := t.histogram.(prometheus.ExemplarObserver)
+	if !ok || !spanContext.IsSampled() {
+		t.histogram.Observe(value)
+		return
+	}
+	exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	})
+}
+
 type histogram struct {
 	histogram observer
 }
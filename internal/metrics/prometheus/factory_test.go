@@ -15,6 +15,7 @@
 package prometheus_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	promModel "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	promMetrics "github.com/jaegertracing/jaeger/internal/metrics/prometheus"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
@@ -243,6 +245,45 @@ func TestTimerDefaultHelp(t *testing.T) {
 	assert.EqualValues(t, "rodriguez", snapshot[0].GetHelp())
 }
 
+func TestTimerWithExemplar(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	f1 := promMetrics.New(promMetrics.WithRegisterer(registry))
+	t1 := f1.Timer(metrics.TimerOptions{
+		Name: "rodriguez",
+		Tags: map[string]string{"x": "y"},
+	})
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	metrics.RecordWithContext(trace.ContextWithSpanContext(context.Background(), spanContext), t1, time.Second)
+	// the traces not sampled are not exemplars
+	metrics.RecordWithContext(context.Background(), t1, 2*time.Second)
+
+	snapshot, err := registry.Gather()
+	require.NoError(t, err)
+
+	m1 := findMetric(t, snapshot, "rodriguez", map[string]string{"x": "y"})
+	assert.EqualValues(t, 2, m1.GetHistogram().GetSampleCount(), "%+v", m1)
+	var exemplars []*promModel.Exemplar
+	for _, bucket := range m1.GetHistogram().GetBucket() {
+		if bucket.GetExemplar() != nil {
+			exemplars = append(exemplars, bucket.GetExemplar())
+		}
+	}
+	require.Len(t, exemplars, 1)
+	assert.EqualValues(t, 1, exemplars[0].GetValue())
+	labels := make(map[string]string)
+	for _, label := range exemplars[0].GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	assert.Equal(t, map[string]string{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}, labels)
+}
+
 func TestTimerCustomBuckets(t *testing.T) {
 	registry := prometheus.NewPedanticRegistry()
 	f1 := promMetrics.New(promMetrics.WithRegisterer(registry), promMetrics.WithBuckets([]float64{1.5}))
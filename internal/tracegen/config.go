@@ -17,6 +17,7 @@ package tracegen
 import (
 	"flag"
 	"fmt"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -41,6 +42,10 @@ type Config struct {
 	Duration      time.Duration
 	Service       string
 	TraceExporter string
+	Topology      string
+
+	SamplingEndpoint        string
+	SamplingRefreshInterval time.Duration
 }
 
 // Flags registers config flags.
@@ -53,15 +58,38 @@ func (c *Config) Flags(fs *flag.FlagSet) {
 	fs.IntVar(&c.AttrValues, "attr-values", 1000, "Number of distinct values to allow for each attribute")
 	fs.BoolVar(&c.Debug, "debug", false, "Whether to set DEBUG flag on the spans to force sampling")
 	fs.BoolVar(&c.Firehose, "firehose", false, "Whether to set FIREHOSE flag on the spans to skip indexing")
-	fs.DurationVar(&c.Pause, "pause", time.Microsecond, "How long to sleep before finishing each span. If set to 0s then a fake 123µs duration is used.")
+	fs.DurationVar(&c.Pause, "pause", time.Microsecond, "How long to sleep before finishing each span, or between the traces of a -topology. If set to 0s then a fake 123µs duration is used.")
 	fs.DurationVar(&c.Duration, "duration", 0, "For how long to run the test if greater than 0s (overrides -traces).")
 	fs.StringVar(&c.Service, "service", "tracegen", "Service name prefix to use")
 	fs.IntVar(&c.Services, "services", 1, "Number of unique suffixes to add to service name when generating traces, e.g. tracegen-01 (but only one service per trace)")
 	fs.StringVar(&c.TraceExporter, "trace-exporter", "otlp-http", "Trace exporter (otlp/otlp-http|otlp-grpc|stdout). Exporters can be additionally configured via environment variables, see https://github.com/jaegertracing/jaeger/blob/main/cmd/tracegen/README.md")
+	fs.StringVar(&c.SamplingEndpoint, "sampling-endpoint", "", "If set, enables the adaptive sampling test mode: the sampling strategies are polled from this Jaeger remote sampling endpoint (e.g. http://localhost:14268/api/sampling) and applied to root spans like SDKs do, and the effective sampling rates are logged. Overrides -debug")
+	fs.DurationVar(&c.SamplingRefreshInterval, "sampling-refresh-interval", time.Minute, "How often to poll the remote sampling endpoint and log the effective sampling rates")
+	fs.StringVar(&c.Topology, "topology", "", "Path to a YAML file describing a multi-service topology to simulate (overrides -service, -services, -spans and -attrs), see https://github.com/jaegertracing/jaeger/blob/main/cmd/tracegen/README.md")
 }
 
 // Run executes the test scenario.
 func Run(c *Config, tracers []trace.Tracer, logger *zap.Logger) error {
+	return run(c, logger, func(w *worker) {
+		w.tracers = tracers
+	})
+}
+
+// RunTopology executes the test scenario simulating traces of the given topology.
+// The tracers must contain a tracer for each service of the topology.
+func RunTopology(c *Config, topology *Topology, tracers map[string]trace.Tracer, logger *zap.Logger) error {
+	for _, svc := range topology.ServiceNames() {
+		if _, ok := tracers[svc]; !ok {
+			return fmt.Errorf("no tracer for service %s", svc)
+		}
+	}
+	return run(c, logger, func(w *worker) {
+		w.topology = topology
+		w.serviceTracers = tracers
+	})
+}
+
+func run(c *Config, logger *zap.Logger, initWorker func(w *worker)) error {
 	if c.Duration > 0 {
 		c.Traces = 0
 	} else if c.Traces <= 0 {
@@ -74,12 +102,13 @@ func Run(c *Config, tracers []trace.Tracer, logger *zap.Logger) error {
 		wg.Add(1)
 		w := worker{
 			id:      i,
-			tracers: tracers,
 			Config:  *c,
 			running: &running,
 			wg:      &wg,
 			logger:  logger.With(zap.Int("worker", i)),
+			rand:    rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))),
 		}
+		initWorker(&w)
 
 		go w.simulateTraces()
 	}
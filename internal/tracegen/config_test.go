@@ -7,6 +7,7 @@ import (
 	"errors"
 	"flag"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -76,6 +77,8 @@ func Test_Flags(t *testing.T) {
 		Service:       "tracegen",
 		Services:      1,
 		TraceExporter: "otlp-http",
+
+		SamplingRefreshInterval: time.Minute,
 	}
 
 	config.Flags(fs)
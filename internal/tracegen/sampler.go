@@ -0,0 +1,321 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracegen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	p2json "github.com/jaegertracing/jaeger/model/converter/json"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+const (
+	// defaultSamplingProbability is used until a strategy is retrieved, same as Jaeger SDKs.
+	defaultSamplingProbability = 0.001
+
+	samplerTypeProbabilistic = "probabilistic"
+	samplerTypeRateLimiting  = "ratelimiting"
+	samplerTypeLowerBound    = "lowerbound"
+
+	samplerTypeKey  = attribute.Key("sampler.type")
+	samplerParamKey = attribute.Key("sampler.param")
+)
+
+var _ sdktrace.Sampler = (*RemoteSampler)(nil)
+
+// RemoteSampler is a root span sampler that periodically polls the Jaeger remote sampling
+// endpoint for the strategy of a service and applies it the same way Jaeger SDKs do,
+// including per-operation probabilities and lower bound rates. It records the sampler.type
+// and sampler.param attributes on sampled spans, which are required by adaptive sampling,
+// and periodically logs the effective sampling rate of each operation.
+type RemoteSampler struct {
+	endpoint string
+	service  string
+	client   *http.Client
+	logger   *zap.Logger
+
+	lock     sync.Mutex
+	strategy compiledStrategy
+	stats    map[string]*operationStats
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type compiledStrategy struct {
+	defaultProbability float64
+	// rateLimiter is only set for rate limiting strategies
+	rateLimiter *rateLimiter
+	// defaultLowerBound is the lower bound rate for operations without explicit strategy
+	defaultLowerBound float64
+	operations        map[operationKey]*operationStrategy
+}
+
+// operationKey identifies the strategy of an operation for the spans of a kind, or of
+// all the kinds if spanKind is empty.
+type operationKey struct {
+	operation string
+	spanKind  string
+}
+
+type operationStrategy struct {
+	probability float64
+	lowerBound  *rateLimiter
+}
+
+type operationStats struct {
+	total   int
+	sampled int
+}
+
+// NewRemoteSampler creates a RemoteSampler for the service and retrieves the initial strategy.
+// The returned sampler must be closed to stop polling.
+func NewRemoteSampler(endpoint, service string, refreshInterval time.Duration, logger *zap.Logger) *RemoteSampler {
+	s := &RemoteSampler{
+		endpoint: endpoint,
+		service:  service,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		logger:   logger.With(zap.String("service", service)),
+		strategy: compiledStrategy{defaultProbability: defaultSamplingProbability},
+		stats:    make(map[string]*operationStats),
+		done:     make(chan struct{}),
+	}
+	s.refresh()
+	s.wg.Add(1)
+	go s.poll(refreshInterval)
+	return s
+}
+
+func (s *RemoteSampler) poll(refreshInterval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.logEffectiveRates()
+			s.refresh()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Close stops polling and logs the effective sampling rates since the last report.
+func (s *RemoteSampler) Close() {
+	close(s.done)
+	s.wg.Wait()
+	s.logEffectiveRates()
+}
+
+func (s *RemoteSampler) refresh() {
+	resp, err := s.fetch()
+	if err != nil {
+		s.logger.Warn("failed to retrieve sampling strategy", zap.Error(err))
+		return
+	}
+	strategy := compileStrategy(resp)
+	s.lock.Lock()
+	s.strategy = strategy
+	s.lock.Unlock()
+	s.logger.Info("retrieved sampling strategy", zap.String("strategy", resp.String()))
+}
+
+func (s *RemoteSampler) fetch() (*api_v2.SamplingStrategyResponse, error) {
+	u := s.endpoint + "?service=" + url.QueryEscape(s.service)
+	resp, err := s.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, u, body)
+	}
+	return p2json.SamplingStrategyResponseFromJSON(body)
+}
+
+func compileStrategy(resp *api_v2.SamplingStrategyResponse) compiledStrategy {
+	strategy := compiledStrategy{defaultProbability: defaultSamplingProbability}
+	if ops := resp.GetOperationSampling(); ops != nil {
+		strategy.defaultProbability = ops.GetDefaultSamplingProbability()
+		strategy.defaultLowerBound = ops.GetDefaultLowerBoundTracesPerSecond()
+		strategy.operations = make(map[operationKey]*operationStrategy, len(ops.GetPerOperationStrategies()))
+		for _, op := range ops.GetPerOperationStrategies() {
+			strategy.operations[operationKey{op.GetOperation(), op.GetSpanKind()}] = &operationStrategy{
+				probability: op.GetProbabilisticSampling().GetSamplingRate(),
+				lowerBound:  newRateLimiter(strategy.defaultLowerBound),
+			}
+		}
+		return strategy
+	}
+	switch resp.GetStrategyType() {
+	case api_v2.SamplingStrategyType_PROBABILISTIC:
+		strategy.defaultProbability = resp.GetProbabilisticSampling().GetSamplingRate()
+	case api_v2.SamplingStrategyType_RATE_LIMITING:
+		strategy.rateLimiter = newRateLimiter(float64(resp.GetRateLimitingSampling().GetMaxTracesPerSecond()))
+	}
+	return strategy
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *RemoteSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	sampled, samplerType, samplerParam := s.decide(p.Name, p.Kind, p.TraceID)
+
+	result := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	if sampled {
+		result.Decision = sdktrace.RecordAndSample
+		result.Attributes = []attribute.KeyValue{
+			samplerTypeKey.String(samplerType),
+			samplerParamKey.Float64(samplerParam),
+		}
+	}
+	return result
+}
+
+func (s *RemoteSampler) decide(operation string, spanKind trace.SpanKind, traceID trace.TraceID) (bool, string, float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats, ok := s.stats[operation]
+	if !ok {
+		stats = &operationStats{}
+		s.stats[operation] = stats
+	}
+	stats.total++
+
+	sampled, samplerType, samplerParam := s.strategy.decide(operation, spanKind, traceID)
+	if sampled {
+		stats.sampled++
+	}
+	return sampled, samplerType, samplerParam
+}
+
+func (c *compiledStrategy) decide(operation string, spanKind trace.SpanKind, traceID trace.TraceID) (bool, string, float64) {
+	if c.rateLimiter != nil {
+		return c.rateLimiter.allow(), samplerTypeRateLimiting, c.rateLimiter.creditsPerSecond
+	}
+	if c.operations == nil {
+		return sampleByTraceID(traceID, c.defaultProbability), samplerTypeProbabilistic, c.defaultProbability
+	}
+	// the strategy of the span kind takes precedence over the strategy of all the span kinds
+	op, ok := c.operations[operationKey{operation, spanKind.String()}]
+	if !ok {
+		op, ok = c.operations[operationKey{operation: operation}]
+	}
+	if !ok {
+		// same as SDKs, new operations are sampled with the default strategy
+		op = &operationStrategy{
+			probability: c.defaultProbability,
+			lowerBound:  newRateLimiter(c.defaultLowerBound),
+		}
+		c.operations[operationKey{operation: operation}] = op
+	}
+	if sampleByTraceID(traceID, op.probability) {
+		op.lowerBound.allow() // consume credits so that the lower bound is not exceeded
+		return true, samplerTypeProbabilistic, op.probability
+	}
+	if op.lowerBound.allow() {
+		return true, samplerTypeLowerBound, op.lowerBound.creditsPerSecond
+	}
+	return false, "", 0
+}
+
+// sampleByTraceID makes a deterministic decision using the lower 63 bits of the trace ID.
+func sampleByTraceID(traceID trace.TraceID, probability float64) bool {
+	if probability >= 1 {
+		return true
+	}
+	boundary := uint64(probability * math.MaxInt64)
+	return binary.BigEndian.Uint64(traceID[8:16])&math.MaxInt64 < boundary
+}
+
+func (s *RemoteSampler) logEffectiveRates() {
+	s.lock.Lock()
+	stats := s.stats
+	s.stats = make(map[string]*operationStats)
+	configured := make(map[string]float64, len(stats))
+	for op := range stats {
+		configured[op] = s.strategy.defaultProbability
+		if o, ok := s.strategy.operations[operationKey{operation: op}]; ok {
+			configured[op] = o.probability
+		}
+	}
+	s.lock.Unlock()
+
+	operations := make([]string, 0, len(stats))
+	for op := range stats {
+		operations = append(operations, op)
+	}
+	sort.Strings(operations)
+	for _, op := range operations {
+		st := stats[op]
+		s.logger.Info("effective sampling rate",
+			zap.String("operation", op),
+			zap.Int("traces", st.total),
+			zap.Int("sampled", st.sampled),
+			zap.Float64("effective_rate", float64(st.sampled)/float64(st.total)),
+			zap.Float64("configured_probability", configured[op]),
+		)
+	}
+}
+
+// Description implements sdktrace.Sampler.
+func (s *RemoteSampler) Description() string {
+	return fmt.Sprintf("RemoteSampler{endpoint=%s,service=%s}", s.endpoint, s.service)
+}
+
+// rateLimiter is a token bucket that allows creditsPerSecond decisions per second,
+// with a maximum balance of max(1, creditsPerSecond). It is not thread-safe.
+type rateLimiter struct {
+	creditsPerSecond float64
+	balance          float64
+	maxBalance       float64
+	lastTick         time.Time
+	now              func() time.Time
+}
+
+func newRateLimiter(creditsPerSecond float64) *rateLimiter {
+	maxBalance := math.Max(1, creditsPerSecond)
+	return &rateLimiter{
+		creditsPerSecond: creditsPerSecond,
+		balance:          maxBalance,
+		maxBalance:       maxBalance,
+		lastTick:         time.Now(),
+		now:              time.Now,
+	}
+}
+
+func (r *rateLimiter) allow() bool {
+	if r.creditsPerSecond <= 0 {
+		return false
+	}
+	now := r.now()
+	r.balance = math.Min(r.maxBalance, r.balance+now.Sub(r.lastTick).Seconds()*r.creditsPerSecond)
+	r.lastTick = now
+	if r.balance >= 1 {
+		r.balance--
+		return true
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracegen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	p2json "github.com/jaegertracing/jaeger/model/converter/json"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+const perOperationStrategy = `{
+	"strategyType": "PROBABILISTIC",
+	"operationSampling": {
+		"defaultSamplingProbability": 0.5,
+		"defaultLowerBoundTracesPerSecond": 0,
+		"perOperationStrategies": [
+			{"operation": "always", "probabilisticSampling": {"samplingRate": 1}},
+			{"operation": "never", "probabilisticSampling": {"samplingRate": 0}}
+		]
+	}
+}`
+
+func newStrategyServer(t *testing.T, strategy string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "svc", r.URL.Query().Get("service"))
+		w.Write([]byte(strategy))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sample(s sdktrace.Sampler, operation string, traceID trace.TraceID) sdktrace.SamplingResult {
+	return s.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       traceID,
+		Name:          operation,
+	})
+}
+
+func TestRemoteSampler_PerOperation(t *testing.T) {
+	server := newStrategyServer(t, perOperationStrategy)
+	logger, buf := testutils.NewLogger()
+	s := NewRemoteSampler(server.URL, "svc", time.Hour, logger)
+
+	traceID := trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	result := sample(s, "always", traceID)
+	assert.Equal(t, sdktrace.RecordAndSample, result.Decision)
+	assert.Contains(t, result.Attributes, samplerTypeKey.String(samplerTypeProbabilistic))
+	assert.Contains(t, result.Attributes, samplerParamKey.Float64(1))
+
+	assert.Equal(t, sdktrace.Drop, sample(s, "never", traceID).Decision)
+
+	// unknown operations use the default probability
+	assert.Equal(t, sdktrace.Drop, sample(s, "other", traceID).Decision)
+	assert.Equal(t, sdktrace.RecordAndSample, sample(s, "other", trace.TraceID{15: 1}).Decision)
+
+	s.Close()
+	assert.Contains(t, buf.String(), "retrieved sampling strategy")
+	assert.Contains(t, buf.String(), `"operation":"other","traces":2,"sampled":1,"effective_rate":0.5,"configured_probability":0.5`)
+	assert.Contains(t, buf.String(), `"operation":"always","traces":1,"sampled":1,"effective_rate":1,"configured_probability":1`)
+}
+
+func TestRemoteSampler_LowerBound(t *testing.T) {
+	strategy := compileStrategy(mustParseStrategy(t, `{
+		"operationSampling": {
+			"defaultSamplingProbability": 0,
+			"defaultLowerBoundTracesPerSecond": 1
+		}
+	}`))
+	sampled, samplerType, param := strategy.decide("op", trace.SpanKindInternal, trace.TraceID{})
+	assert.True(t, sampled)
+	assert.Equal(t, samplerTypeLowerBound, samplerType)
+	assert.InDelta(t, 1.0, param, 0.01)
+
+	sampled, _, _ = strategy.decide("op", trace.SpanKindInternal, trace.TraceID{})
+	assert.False(t, sampled)
+}
+
+func TestRemoteSampler_SpanKind(t *testing.T) {
+	strategy := compileStrategy(mustParseStrategy(t, `{
+		"operationSampling": {
+			"defaultSamplingProbability": 0,
+			"perOperationStrategies": [
+				{"operation": "orders", "spanKind": "consumer", "probabilisticSampling": {"samplingRate": 0}},
+				{"operation": "orders", "probabilisticSampling": {"samplingRate": 1}}
+			]
+		}
+	}`))
+	sampled, _, _ := strategy.decide("orders", trace.SpanKindConsumer, trace.TraceID{1})
+	assert.False(t, sampled)
+	sampled, samplerType, param := strategy.decide("orders", trace.SpanKindServer, trace.TraceID{1})
+	assert.True(t, sampled)
+	assert.Equal(t, samplerTypeProbabilistic, samplerType)
+	assert.InDelta(t, 1.0, param, 0.01)
+}
+
+func TestRemoteSampler_RateLimiting(t *testing.T) {
+	strategy := compileStrategy(mustParseStrategy(t, `{
+		"strategyType": "RATE_LIMITING",
+		"rateLimitingSampling": {"maxTracesPerSecond": 2}
+	}`))
+	now := time.Now()
+	strategy.rateLimiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		sampled, samplerType, _ := strategy.decide("op", trace.SpanKindInternal, trace.TraceID{})
+		assert.True(t, sampled)
+		assert.Equal(t, samplerTypeRateLimiting, samplerType)
+	}
+	sampled, _, _ := strategy.decide("op", trace.SpanKindInternal, trace.TraceID{})
+	assert.False(t, sampled)
+
+	now = now.Add(time.Second)
+	sampled, _, _ = strategy.decide("op", trace.SpanKindInternal, trace.TraceID{})
+	assert.True(t, sampled)
+}
+
+func TestRemoteSampler_Probabilistic(t *testing.T) {
+	strategy := compileStrategy(mustParseStrategy(t, `{
+		"strategyType": "PROBABILISTIC",
+		"probabilisticSampling": {"samplingRate": 1}
+	}`))
+	sampled, samplerType, param := strategy.decide("op", trace.SpanKindInternal, trace.TraceID{1})
+	assert.True(t, sampled)
+	assert.Equal(t, samplerTypeProbabilistic, samplerType)
+	assert.InDelta(t, 1.0, param, 0.01)
+}
+
+func TestRemoteSampler_FetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no strategy", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	logger, buf := testutils.NewLogger()
+	s := NewRemoteSampler(server.URL, "svc", time.Hour, logger)
+	defer s.Close()
+
+	assert.Contains(t, buf.String(), "failed to retrieve sampling strategy")
+	assert.InDelta(t, defaultSamplingProbability, s.strategy.defaultProbability, 0.0001)
+	assert.Contains(t, s.Description(), "service=svc")
+}
+
+func TestRemoteSampler_Refresh(t *testing.T) {
+	server := newStrategyServer(t, perOperationStrategy)
+	s := NewRemoteSampler("http://localhost:1", "svc", 10*time.Millisecond, zap.NewNop())
+	defer s.Close()
+	s.endpoint = server.URL
+	assert.Eventually(t, func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return s.strategy.operations != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func mustParseStrategy(t *testing.T, strategy string) *api_v2.SamplingStrategyResponse {
+	resp, err := p2json.SamplingStrategyResponseFromJSON([]byte(strategy))
+	require.NoError(t, err)
+	return resp
+}
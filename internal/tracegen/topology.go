@@ -0,0 +1,258 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracegen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	latencyNormal      = "normal"
+	latencyUniform     = "uniform"
+	latencyExponential = "exponential"
+
+	minSpanDuration = time.Microsecond
+)
+
+// Topology describes a multi-service application whose traces are simulated.
+//
+// Example:
+//
+//	services:
+//	  - name: frontend
+//	    operations:
+//	      - name: HTTP GET /dispatch
+//	        latency: {mean: 20ms, stddev: 5ms}
+//	        calls:
+//	          - {service: customer, operation: HTTP GET /customer}
+//	          - {service: driver, operation: FindNearest, probability: 0.9}
+//	  - name: customer
+//	    operations:
+//	      - name: HTTP GET /customer
+//	        latency: {distribution: exponential, mean: 10ms}
+//	        error_rate: 0.05
+type Topology struct {
+	Services []TopologyService `yaml:"services"`
+	// Entrypoints are the operations that start traces. If empty, all operations
+	// that are not called by any other operation are used.
+	Entrypoints []OperationRef `yaml:"entrypoints"`
+}
+
+// TopologyService is a service of the simulated application.
+type TopologyService struct {
+	Name       string              `yaml:"name"`
+	Operations []TopologyOperation `yaml:"operations"`
+}
+
+// TopologyOperation is an endpoint exposed by a service.
+type TopologyOperation struct {
+	Name string `yaml:"name"`
+	// Latency of the operation itself, excluding the time spent in downstream calls.
+	Latency Latency `yaml:"latency"`
+	// ErrorRate is the probability in [0, 1] that the operation fails.
+	ErrorRate float64 `yaml:"error_rate"`
+	// Calls are executed sequentially each time the operation is invoked.
+	Calls []Call `yaml:"calls"`
+}
+
+// OperationRef identifies an operation of a service.
+type OperationRef struct {
+	Service   string `yaml:"service"`
+	Operation string `yaml:"operation"`
+}
+
+// Call is a downstream call made by an operation.
+type Call struct {
+	OperationRef `yaml:",inline"`
+	// Probability in (0, 1] that the call is made, defaults to 1.
+	Probability float64 `yaml:"probability"`
+}
+
+// Latency describes the distribution of an operation's duration.
+type Latency struct {
+	// Distribution is one of normal (default), uniform or exponential.
+	Distribution string        `yaml:"distribution"`
+	Mean         time.Duration `yaml:"mean"`
+	StdDev       time.Duration `yaml:"stddev"`
+	Min          time.Duration `yaml:"min"`
+	Max          time.Duration `yaml:"max"`
+}
+
+func (l Latency) sample(r *rand.Rand) time.Duration {
+	var d float64
+	switch l.Distribution {
+	case latencyUniform:
+		d = float64(l.Min) + r.Float64()*float64(l.Max-l.Min)
+	case latencyExponential:
+		d = r.ExpFloat64() * float64(l.Mean)
+	default:
+		d = r.NormFloat64()*float64(l.StdDev) + float64(l.Mean)
+	}
+	return time.Duration(math.Max(d, float64(minSpanDuration)))
+}
+
+func (l Latency) validate() error {
+	switch l.Distribution {
+	case "", latencyNormal, latencyExponential:
+		if l.Mean < 0 || l.StdDev < 0 {
+			return errors.New("latency mean and stddev must not be negative")
+		}
+	case latencyUniform:
+		if l.Min < 0 || l.Max < l.Min {
+			return errors.New("uniform latency requires 0 <= min <= max")
+		}
+	default:
+		return fmt.Errorf("unknown latency distribution %q", l.Distribution)
+	}
+	return nil
+}
+
+// LoadTopology reads and validates the topology from a YAML file.
+func LoadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read topology file: %w", err)
+	}
+	topology := &Topology{}
+	if err := yaml.Unmarshal(data, topology); err != nil {
+		return nil, fmt.Errorf("cannot parse topology file: %w", err)
+	}
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid topology: %w", err)
+	}
+	return topology, nil
+}
+
+// ServiceNames returns the names of all services in the topology.
+func (t *Topology) ServiceNames() []string {
+	names := make([]string, 0, len(t.Services))
+	for _, svc := range t.Services {
+		names = append(names, svc.Name)
+	}
+	return names
+}
+
+// Validate checks that all references are resolvable and that the call graph is acyclic.
+func (t *Topology) Validate() error {
+	if len(t.Services) == 0 {
+		return errors.New("no services defined")
+	}
+	ops := make(map[OperationRef]*TopologyOperation)
+	for i := range t.Services {
+		svc := &t.Services[i]
+		if svc.Name == "" {
+			return errors.New("service name must not be empty")
+		}
+		if len(svc.Operations) == 0 {
+			return fmt.Errorf("service %s has no operations", svc.Name)
+		}
+		for j := range svc.Operations {
+			op := &svc.Operations[j]
+			ref := OperationRef{Service: svc.Name, Operation: op.Name}
+			if _, ok := ops[ref]; ok {
+				return fmt.Errorf("duplicate operation %s", ref)
+			}
+			if err := op.Latency.validate(); err != nil {
+				return fmt.Errorf("operation %s: %w", ref, err)
+			}
+			if op.ErrorRate < 0 || op.ErrorRate > 1 {
+				return fmt.Errorf("operation %s: error_rate must be in [0, 1]", ref)
+			}
+			for k := range op.Calls {
+				if op.Calls[k].Probability == 0 {
+					op.Calls[k].Probability = 1
+				}
+				if op.Calls[k].Probability < 0 || op.Calls[k].Probability > 1 {
+					return fmt.Errorf("operation %s: call probability must be in (0, 1]", ref)
+				}
+			}
+			ops[ref] = op
+		}
+	}
+
+	called := make(map[OperationRef]bool)
+	for ref, op := range ops {
+		for _, call := range op.Calls {
+			if _, ok := ops[call.OperationRef]; !ok {
+				return fmt.Errorf("operation %s calls unknown operation %s", ref, call.OperationRef)
+			}
+			called[call.OperationRef] = true
+		}
+	}
+	for _, ref := range t.Entrypoints {
+		if _, ok := ops[ref]; !ok {
+			return fmt.Errorf("unknown entrypoint %s", ref)
+		}
+	}
+	if len(t.Entrypoints) == 0 {
+		for ref := range ops {
+			if !called[ref] {
+				t.Entrypoints = append(t.Entrypoints, ref)
+			}
+		}
+		if len(t.Entrypoints) == 0 {
+			return errors.New("no entrypoints: every operation is called by another operation")
+		}
+		sort.Slice(t.Entrypoints, func(i, j int) bool {
+			return t.Entrypoints[i].String() < t.Entrypoints[j].String()
+		})
+	}
+
+	// detect cycles with a depth-first search
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[OperationRef]int)
+	var visit func(ref OperationRef) error
+	visit = func(ref OperationRef) error {
+		switch state[ref] {
+		case visiting:
+			return fmt.Errorf("call graph contains a cycle through %s", ref)
+		case visited:
+			return nil
+		}
+		state[ref] = visiting
+		for _, call := range ops[ref].Calls {
+			if err := visit(call.OperationRef); err != nil {
+				return err
+			}
+		}
+		state[ref] = visited
+		return nil
+	}
+	for ref := range ops {
+		if err := visit(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Topology) operation(ref OperationRef) *TopologyOperation {
+	for i := range t.Services {
+		if t.Services[i].Name != ref.Service {
+			continue
+		}
+		for j := range t.Services[i].Operations {
+			if t.Services[i].Operations[j].Name == ref.Operation {
+				return &t.Services[i].Operations[j]
+			}
+		}
+	}
+	return nil
+}
+
+func (r OperationRef) String() string {
+	return r.Service + "/" + r.Operation
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracegen
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadTopology(t *testing.T) {
+	topology, err := LoadTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "customer", "driver", "redis"}, topology.ServiceNames())
+	assert.Equal(t, []OperationRef{{Service: "frontend", Operation: "HTTP GET /dispatch"}}, topology.Entrypoints)
+
+	op := topology.operation(OperationRef{Service: "frontend", Operation: "HTTP GET /dispatch"})
+	require.NotNil(t, op)
+	assert.Equal(t, 20*time.Millisecond, op.Latency.Mean)
+	assert.Equal(t, 5*time.Millisecond, op.Latency.StdDev)
+	assert.InDelta(t, 1.0, op.Calls[0].Probability, 0.001)
+}
+
+func TestLoadTopologyErrors(t *testing.T) {
+	_, err := LoadTopology("testdata/does-not-exist.yaml")
+	require.ErrorContains(t, err, "cannot read topology file")
+}
+
+func TestTopologyValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{
+			name: "no services",
+			yaml: `services: []`,
+			err:  "no services defined",
+		},
+		{
+			name: "no operations",
+			yaml: `services: [{name: a}]`,
+			err:  "service a has no operations",
+		},
+		{
+			name: "unknown call",
+			yaml: `services: [{name: a, operations: [{name: x, calls: [{service: b, operation: y}]}]}]`,
+			err:  "operation a/x calls unknown operation b/y",
+		},
+		{
+			name: "unknown entrypoint",
+			yaml: `{services: [{name: a, operations: [{name: x}]}], entrypoints: [{service: a, operation: z}]}`,
+			err:  "unknown entrypoint a/z",
+		},
+		{
+			name: "cycle",
+			yaml: `{services: [{name: a, operations: [{name: x, calls: [{service: a, operation: y}]}, {name: y, calls: [{service: a, operation: x}]}, {name: root, calls: [{service: a, operation: x}]}]}]}`,
+			err:  "call graph contains a cycle",
+		},
+		{
+			name: "bad error rate",
+			yaml: `services: [{name: a, operations: [{name: x, error_rate: 2}]}]`,
+			err:  "error_rate must be in [0, 1]",
+		},
+		{
+			name: "bad distribution",
+			yaml: `services: [{name: a, operations: [{name: x, latency: {distribution: pareto}}]}]`,
+			err:  `unknown latency distribution "pareto"`,
+		},
+		{
+			name: "bad uniform",
+			yaml: `services: [{name: a, operations: [{name: x, latency: {distribution: uniform, min: 2ms, max: 1ms}}]}]`,
+			err:  "uniform latency requires 0 <= min <= max",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			topology := &Topology{}
+			require.NoError(t, yaml.Unmarshal([]byte(test.yaml), topology))
+			require.ErrorContains(t, topology.Validate(), test.err)
+		})
+	}
+}
+
+func TestRunTopology(t *testing.T) {
+	topology, err := LoadTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+
+	exporters := make(map[string]*tracetest.InMemoryExporter)
+	tracers := make(map[string]trace.Tracer)
+	for _, svc := range topology.ServiceNames() {
+		exporters[svc] = tracetest.NewInMemoryExporter()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporters[svc]))
+		tracers[svc] = tp.Tracer(svc)
+	}
+
+	err = RunTopology(&Config{Workers: 1, Traces: 3}, topology, tracers, zap.NewNop())
+	require.NoError(t, err)
+
+	// frontend: server span and two client spans per trace
+	frontend := exporters["frontend"].GetSpans()
+	require.Len(t, frontend, 9)
+	customer := exporters["customer"].GetSpans()
+	require.Len(t, customer, 3)
+	for _, span := range customer {
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, codes.Error, span.Status.Code)
+		assert.True(t, span.Parent.IsValid())
+	}
+	assert.Len(t, exporters["driver"].GetSpans(), 6)
+	assert.Len(t, exporters["redis"].GetSpans(), 3)
+
+	for _, span := range frontend {
+		if span.SpanKind == trace.SpanKindServer {
+			assert.False(t, span.Parent.IsValid())
+			assert.True(t, span.EndTime.After(span.StartTime))
+		}
+		if span.Name == "HTTP GET /customer" {
+			assert.Equal(t, codes.Error, span.Status.Code)
+		}
+	}
+}
+
+func TestRunTopologyPause(t *testing.T) {
+	topology, err := LoadTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+	tracers := make(map[string]trace.Tracer)
+	for _, svc := range topology.ServiceNames() {
+		tracers[svc] = sdktrace.NewTracerProvider().Tracer(svc)
+	}
+
+	start := time.Now()
+	err = RunTopology(&Config{Workers: 1, Traces: 3, Pause: 20 * time.Millisecond}, topology, tracers, zap.NewNop())
+	require.NoError(t, err)
+	// the worker pauses between the traces, not after the last one
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestRunTopologyMissingTracer(t *testing.T) {
+	topology, err := LoadTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+	err = RunTopology(&Config{Traces: 1}, topology, map[string]trace.Tracer{}, zap.NewNop())
+	require.ErrorContains(t, err, "no tracer for service")
+}
+
+func TestLatencySample(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	uniform := Latency{Distribution: latencyUniform, Min: time.Millisecond, Max: 2 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		d := uniform.sample(r)
+		assert.GreaterOrEqual(t, d, time.Millisecond)
+		assert.LessOrEqual(t, d, 2*time.Millisecond)
+	}
+	// negative samples of the normal distribution are clamped
+	normal := Latency{Mean: 0, StdDev: time.Second}
+	for i := 0; i < 100; i++ {
+		assert.GreaterOrEqual(t, normal.sample(r), minSpanDuration)
+	}
+	assert.Equal(t, minSpanDuration, Latency{}.sample(r))
+}
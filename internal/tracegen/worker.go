@@ -17,11 +17,13 @@ package tracegen
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
@@ -33,6 +35,11 @@ type worker struct {
 	Config
 	wg     *sync.WaitGroup // notify when done
 	logger *zap.Logger
+	rand   *rand.Rand
+
+	// only set when simulating a topology
+	topology       *Topology
+	serviceTracers map[string]trace.Tracer
 
 	// internal counters
 	traceNo   int
@@ -42,35 +49,52 @@ type worker struct {
 
 const (
 	fakeSpanDuration = 123 * time.Microsecond
+	// networkLatency is added on each side of a simulated downstream call.
+	networkLatency = 50 * time.Microsecond
 )
 
 func (w *worker) simulateTraces() {
 	for atomic.LoadUint32(w.running) == 1 {
-		svcNo := w.traceNo % len(w.tracers)
-		w.simulateOneTrace(w.tracers[svcNo])
+		if w.topology != nil {
+			w.simulateTopologyTrace()
+		} else {
+			svcNo := w.traceNo % len(w.tracers)
+			w.simulateOneTrace(w.tracers[svcNo])
+		}
 		w.traceNo++
 		if w.Traces != 0 {
 			if w.traceNo >= w.Traces {
 				break
 			}
 		}
+		// the spans of a topology have synthetic timestamps, the classic traces already
+		// pausing after each child span
+		if w.topology != nil && w.Pause != 0 {
+			time.Sleep(w.Pause)
+		}
 	}
 	w.logger.Info(fmt.Sprintf("Worker %d generated %d traces", w.id, w.traceNo))
 	w.wg.Done()
 }
 
-func (w *worker) simulateOneTrace(tracer trace.Tracer) {
-	ctx := context.Background()
-	attrs := []attribute.KeyValue{
-		attribute.String("peer.service", "tracegen-server"),
-		attribute.String("peer.host.ipv4", "1.1.1.1"),
-	}
+func (w *worker) flagAttributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
 	if w.Debug {
 		attrs = append(attrs, attribute.Bool("jaeger.debug", true))
 	}
 	if w.Firehose {
 		attrs = append(attrs, attribute.Bool("jaeger.firehose", true))
 	}
+	return attrs
+}
+
+func (w *worker) simulateOneTrace(tracer trace.Tracer) {
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{
+		attribute.String("peer.service", "tracegen-server"),
+		attribute.String("peer.host.ipv4", "1.1.1.1"),
+	}
+	attrs = append(attrs, w.flagAttributes()...)
 	start := time.Now()
 	ctx, parent := tracer.Start(
 		ctx,
@@ -124,3 +148,56 @@ func (w *worker) simulateChildSpans(ctx context.Context, start time.Time, tracer
 		}
 	}
 }
+
+// simulateTopologyTrace generates a trace starting at one of the topology entrypoints,
+// which are used in a round-robin fashion. Span timestamps are synthetic and derived
+// from the latency distributions of the operations.
+func (w *worker) simulateTopologyTrace() {
+	entrypoint := w.topology.Entrypoints[w.traceNo%len(w.topology.Entrypoints)]
+	w.simulateOperation(context.Background(), entrypoint, time.Now(), w.flagAttributes())
+}
+
+// simulateOperation emits the server span of the operation, together with the client
+// spans of its downstream calls and, recursively, the spans of the called operations.
+// It returns the end time of the operation and whether it failed.
+func (w *worker) simulateOperation(ctx context.Context, ref OperationRef, start time.Time, attrs []attribute.KeyValue) (time.Time, bool) {
+	op := w.topology.operation(ref)
+	tracer := w.serviceTracers[ref.Service]
+	ctx, span := tracer.Start(
+		ctx,
+		ref.Operation,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+		trace.WithTimestamp(start),
+	)
+
+	// half of the operation's own latency is spent before the downstream calls
+	own := op.Latency.sample(w.rand)
+	t := start.Add(own / 2)
+	for _, call := range op.Calls {
+		if w.rand.Float64() >= call.Probability {
+			continue
+		}
+		callCtx, client := tracer.Start(
+			ctx,
+			call.Operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("peer.service", call.Service)),
+			trace.WithTimestamp(t),
+		)
+		end, failed := w.simulateOperation(callCtx, call.OperationRef, t.Add(networkLatency), nil)
+		t = end.Add(networkLatency)
+		if failed {
+			client.SetStatus(codes.Error, "downstream call failed")
+		}
+		client.End(trace.WithTimestamp(t))
+	}
+	end := t.Add(own - own/2)
+
+	failed := w.rand.Float64() < op.ErrorRate
+	if failed {
+		span.SetStatus(codes.Error, "simulated error")
+	}
+	span.End(trace.WithTimestamp(end))
+	return end, failed
+}
@@ -4,11 +4,13 @@
 package tracegen
 
 import (
+	"math/rand"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 
@@ -43,3 +45,37 @@ func Test_SimulateTraces(t *testing.T) {
 	worker.simulateTraces()
 	assert.Equal(t, expectedOutput, buf.String())
 }
+
+func Test_SimulateTopologyTraces(t *testing.T) {
+	topology, err := LoadTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+	tracers := make(map[string]trace.Tracer)
+	for _, svc := range topology.ServiceNames() {
+		tracers[svc] = sdktrace.NewTracerProvider().Tracer(svc)
+	}
+	logger, buf := testutils.NewLogger()
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	var running uint32 = 1
+
+	worker := &worker{
+		logger:         logger,
+		wg:             &wg,
+		id:             3,
+		running:        &running,
+		rand:           rand.New(rand.NewSource(1)),
+		topology:       topology,
+		serviceTracers: tracers,
+		Config: Config{
+			Traces: 3,
+			Pause:  20 * time.Millisecond,
+		},
+	}
+	expectedOutput := `{"level":"info","msg":"Worker 3 generated 3 traces"}` + "\n"
+
+	start := time.Now()
+	worker.simulateTraces()
+	assert.Equal(t, expectedOutput, buf.String())
+	// the spans of a topology have synthetic timestamps, the worker pausing between the traces instead
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 )
 
+const warningFormatInvalidReference = "Invalid span reference removed %+v"
+
 // SpanReferences creates an adjuster that removes invalid span references, e.g. with traceID==0
 func SpanReferences() Adjuster {
 	return Func(func(trace *model.Trace) (*model.Trace, error) {
@@ -48,7 +50,7 @@ func (s spanReferenceAdjuster) adjust(span *model.Span) *model.Span {
 	references := make([]model.SpanRef, 0, len(span.References)-1)
 	for i := range span.References {
 		if !s.valid(&span.References[i]) {
-			span.Warnings = append(span.Warnings, fmt.Sprintf("Invalid span reference removed %+v", span.References[i]))
+			span.Warnings = append(span.Warnings, fmt.Sprintf(warningFormatInvalidReference, span.References[i]))
 			continue
 		}
 		references = append(references, span.References[i])
@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -29,6 +30,12 @@ import (
 // clock skew on different servers. The main condition that it checks is that
 // child spans do not start before or end after their parent spans.
 //
+// When the process of a span has a ClockOffsetTagKey tag, e.g. reported by an agent
+// from its NTP offset, the span and the spans of the same host under it are corrected
+// by this offset instead of the delta inferred from the parent span.
+//
+// The adjustments are bounded by maxDelta, 0 disabling them.
+//
 // The algorithm assumes that all spans have unique IDs, so the trace may need
 // to go through another adjuster first, such as SpanIDDeduper.
 //
@@ -50,11 +57,17 @@ func ClockSkew(maxDelta time.Duration) Adjuster {
 	})
 }
 
+// ClockOffsetTagKey is the process tag with the offset in milliseconds of the clock of
+// the host relative to the true time, e.g. 250 for a clock ahead by 250ms.
+const ClockOffsetTagKey = "clock.offset_ms"
+
 const (
 	warningDuplicateSpanID       = "duplicate span IDs; skipping clock skew adjustment"
 	warningFormatInvalidParentID = "invalid parent span IDs=%s; skipping clock skew adjustment"
 	warningMaxDeltaExceeded      = "max clock skew adjustment delta of %v exceeded; not applying calculated delta of %v"
 	warningSkewAdjustDisabled    = "clock skew adjustment disabled; not applying calculated delta of %v"
+	warningFormatAdjusted        = "This span's timestamps were adjusted by %v"
+	warningFormatOffsetAdjusted  = "This span's timestamps were adjusted by %v from the clock offset of its host"
 )
 
 type clockSkewAdjuster struct {
@@ -67,12 +80,17 @@ type clockSkewAdjuster struct {
 type clockSkew struct {
 	delta   time.Duration
 	hostKey string
+	// fromClockOffset is true when the delta is the clock offset reported for the host
+	fromClockOffset bool
 }
 
 type node struct {
 	span     *model.Span
 	children []*node
 	hostKey  string
+	// clockOffset is the clock offset of the host of the span, if reported
+	clockOffset    time.Duration
+	hasClockOffset bool
 }
 
 // hostKey returns a string representation of the host identity that can be used
@@ -99,6 +117,30 @@ func hostKey(span *model.Span) string {
 	return ""
 }
 
+// clockOffset returns the clock offset of the host of the span from the ClockOffsetTagKey process tag.
+func clockOffset(span *model.Span) (time.Duration, bool) {
+	tag, ok := model.KeyValues(span.Process.Tags).FindByKey(ClockOffsetTagKey)
+	if !ok {
+		return 0, false
+	}
+	var millis float64
+	switch tag.VType {
+	case model.Int64Type:
+		millis = float64(tag.Int64())
+	case model.Float64Type:
+		millis = tag.Float64()
+	case model.StringType:
+		v, err := strconv.ParseFloat(tag.VStr, 64)
+		if err != nil {
+			return 0, false
+		}
+		millis = v
+	default:
+		return 0, false
+	}
+	return time.Duration(millis * float64(time.Millisecond)), true
+}
+
 // buildNodesMap builds a map of span IDs -> node{}.
 func (a *clockSkewAdjuster) buildNodesMap() {
 	a.spans = make(map[model.SpanID]*node)
@@ -106,10 +148,12 @@ func (a *clockSkewAdjuster) buildNodesMap() {
 		if _, ok := a.spans[span.SpanID]; ok {
 			span.Warnings = append(span.Warnings, warningDuplicateSpanID)
 		} else {
-			a.spans[span.SpanID] = &node{
+			n := &node{
 				span:    span,
 				hostKey: hostKey(span),
 			}
+			n.clockOffset, n.hasClockOffset = clockOffset(span)
+			a.spans[span.SpanID] = n
 		}
 	}
 }
@@ -135,7 +179,14 @@ func (a *clockSkewAdjuster) buildSubGraphs() {
 }
 
 func (a *clockSkewAdjuster) adjustNode(n *node, parent *node, skew clockSkew) {
-	if (n.hostKey != skew.hostKey || n.hostKey == "") && parent != nil {
+	if n.hasClockOffset {
+		// The reported offset of the host is more accurate than the inferred skew.
+		skew = clockSkew{
+			hostKey:         n.hostKey,
+			delta:           -n.clockOffset,
+			fromClockOffset: true,
+		}
+	} else if (n.hostKey != skew.hostKey || n.hostKey == "") && parent != nil {
 		// Node n is from a different host. The parent has already been adjusted,
 		// so we can compare this node's timestamps against the parent.
 		skew = clockSkew{
@@ -191,7 +242,11 @@ func (a *clockSkewAdjuster) adjustTimestamps(n *node, skew clockSkew) {
 	}
 
 	n.span.StartTime = n.span.StartTime.Add(skew.delta)
-	n.span.Warnings = append(n.span.Warnings, fmt.Sprintf("This span's timestamps were adjusted by %v", skew.delta))
+	if skew.fromClockOffset {
+		n.span.Warnings = append(n.span.Warnings, fmt.Sprintf(warningFormatOffsetAdjusted, skew.delta))
+	} else {
+		n.span.Warnings = append(n.span.Warnings, fmt.Sprintf(warningFormatAdjusted, skew.delta))
+	}
 
 	for i := range n.span.Logs {
 		n.span.Logs[i].Timestamp = n.span.Logs[i].Timestamp.Add(skew.delta)
@@ -32,8 +32,9 @@ func TestClockSkewAdjuster(t *testing.T) {
 		id, parent, startTime, duration int
 		logs                            []int // timestamps for logs
 		host                            string
-		adjusted                        int   // start time after adjustment
-		adjustedLogs                    []int // adjusted log timestamps
+		clockOffset                     string // value of the clock offset process tag, if set
+		adjusted                        int    // start time after adjustment
+		adjustedLogs                    []int  // adjusted log timestamps
 	}
 
 	toTime := func(t int) time.Time {
@@ -69,6 +70,9 @@ func TestClockSkewAdjuster(t *testing.T) {
 					},
 				},
 			}
+			if spanProto.clockOffset != "" {
+				span.Process.Tags = append(span.Process.Tags, model.String(ClockOffsetTagKey, spanProto.clockOffset))
+			}
 			trace.Spans = append(trace.Spans, span)
 		}
 		return trace
@@ -192,6 +196,42 @@ func TestClockSkewAdjuster(t *testing.T) {
 			},
 			maxAdjust: time.Second,
 		},
+		{
+			description: "adjust span from the clock offset of its host",
+			trace: []spanProto{
+				{id: 1, parent: 0, startTime: 10, duration: 100, host: "a", adjusted: 10},
+				// the clock of host 'b' is 30ms behind
+				{id: 2, parent: 1, startTime: 0, duration: 50, host: "b", clockOffset: "-30", adjusted: 30},
+				// same host 'b', so same delta = 30
+				{id: 3, parent: 2, startTime: 5, duration: 20, host: "b", adjusted: 35},
+			},
+			maxAdjust: time.Second,
+		},
+		{
+			description: "adjust child fitting inside parent from the clock offset of its host",
+			trace: []spanProto{
+				{id: 1, parent: 0, startTime: 10, duration: 100, host: "a", adjusted: 10},
+				{id: 2, parent: 1, startTime: 20, duration: 50, host: "b", clockOffset: "5", adjusted: 15},
+			},
+			maxAdjust: time.Second,
+		},
+		{
+			description: "adjust root span from the clock offset of its host",
+			trace: []spanProto{
+				{id: 1, parent: 0, startTime: 10, duration: 100, host: "a", clockOffset: "10", adjusted: 0},
+				{id: 2, parent: 1, startTime: 20, duration: 50, host: "a", adjusted: 10},
+			},
+			maxAdjust: time.Second,
+		},
+		{
+			description: "do not apply clock offset due to max skew adjustment",
+			trace: []spanProto{
+				{id: 1, parent: 0, startTime: 10, duration: 100, host: "a", adjusted: 10},
+				{id: 2, parent: 1, startTime: 20, duration: 50, host: "b", clockOffset: "100", adjusted: 20},
+			},
+			maxAdjust: 10 * time.Millisecond,
+			err:       "max clock skew adjustment delta of 10ms exceeded; not applying calculated delta of -100ms",
+		},
 	}
 
 	for _, tt := range testCases {
@@ -235,6 +275,36 @@ func TestClockSkewAdjuster(t *testing.T) {
 	}
 }
 
+func TestClockOffset(t *testing.T) {
+	testCases := []struct {
+		tag    model.KeyValue
+		offset time.Duration
+		ok     bool
+	}{
+		{tag: model.Int64(ClockOffsetTagKey, -250), offset: -250 * time.Millisecond, ok: true},
+		{tag: model.Float64(ClockOffsetTagKey, 1.5), offset: 1500 * time.Microsecond, ok: true},
+		{tag: model.String(ClockOffsetTagKey, "12"), offset: 12 * time.Millisecond, ok: true},
+		{tag: model.String(ClockOffsetTagKey, "invalid")},
+		{tag: model.Bool(ClockOffsetTagKey, true)},
+		{tag: model.Int64("offset", 12)},
+	}
+
+	for _, tt := range testCases {
+		testCase := tt // capture loop var
+		t.Run(fmt.Sprintf("%+v", testCase.tag), func(t *testing.T) {
+			span := &model.Span{
+				Process: &model.Process{
+					ServiceName: "some service",
+					Tags:        []model.KeyValue{testCase.tag},
+				},
+			}
+			offset, ok := clockOffset(span)
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.offset, offset)
+		})
+	}
+}
+
 func TestHostKey(t *testing.T) {
 	testCases := []struct {
 		tag     model.KeyValue
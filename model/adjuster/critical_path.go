@@ -0,0 +1,127 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// CriticalPathTagKey is the span tag set by the CriticalPath adjuster. Its value is
+// the time in microseconds during which the span was on the critical path of the trace.
+const CriticalPathTagKey = "critical_path.duration_us"
+
+// CriticalPath returns an Adjuster that annotates each span with its contribution to the
+// critical path of the trace, i.e. the time during which the span itself, rather than one
+// of its children, was holding up the completion of the root span.
+//
+// The critical path is computed backwards from the end of the root span: at every step
+// the last child finishing before the current point in time is followed, and the time
+// not covered by any child is attributed to the current span. Children are clipped to the
+// time window of their parent, those entirely outside of it are ignored.
+func CriticalPath() Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		contributions := computeCriticalPath(trace)
+		for _, span := range trace.Spans {
+			d := contributions[span.SpanID]
+			span.Tags = append(span.Tags, model.Int64(CriticalPathTagKey, d.Microseconds()))
+		}
+		return trace, nil
+	})
+}
+
+type criticalPathNode struct {
+	span       *model.Span
+	start, end time.Time // clipped to the parent's window
+	children   []*criticalPathNode
+	visited    bool
+}
+
+func computeCriticalPath(trace *model.Trace) map[model.SpanID]time.Duration {
+	nodes := make(map[model.SpanID]*criticalPathNode, len(trace.Spans))
+	for _, span := range trace.Spans {
+		nodes[span.SpanID] = &criticalPathNode{
+			span:  span,
+			start: span.StartTime,
+			end:   span.StartTime.Add(span.Duration),
+		}
+	}
+	var root *criticalPathNode
+	for _, span := range trace.Spans {
+		node := nodes[span.SpanID]
+		if node.span != span {
+			// duplicate span ID, only the last span is kept
+			continue
+		}
+		parent, ok := nodes[node.span.ParentSpanID()]
+		if ok && parent != node {
+			parent.children = append(parent.children, node)
+			continue
+		}
+		if root == nil || node.start.Before(root.start) ||
+			(node.start.Equal(root.start) && node.end.After(root.end)) {
+			root = node
+		}
+	}
+	contributions := make(map[model.SpanID]time.Duration)
+	if root == nil {
+		return contributions
+	}
+	clipChildren(root)
+
+	var stack []*criticalPathNode
+	current, cursor := root, root.end
+	for {
+		// last child finishing before the cursor
+		var next *criticalPathNode
+		for _, child := range current.children {
+			if child.visited || child.end.After(cursor) {
+				continue
+			}
+			if next == nil || child.end.After(next.end) {
+				next = child
+			}
+		}
+		if next != nil {
+			contributions[current.span.SpanID] += cursor.Sub(next.end)
+			next.visited = true
+			stack = append(stack, current)
+			current, cursor = next, next.end
+			continue
+		}
+		contributions[current.span.SpanID] += cursor.Sub(current.start)
+		if len(stack) == 0 {
+			return contributions
+		}
+		cursor = current.start
+		current = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+	}
+}
+
+// clipChildren restricts the children of each span to the window of their parent,
+// dropping the children entirely outside of it.
+func clipChildren(root *criticalPathNode) {
+	queue := []*criticalPathNode{root}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		children := parent.children[:0]
+		for _, child := range parent.children {
+			if child.start.After(parent.end) || child.end.Before(parent.start) {
+				continue
+			}
+			if child.start.Before(parent.start) {
+				child.start = parent.start
+			}
+			if child.end.After(parent.end) {
+				child.end = parent.end
+			}
+			children = append(children, child)
+			queue = append(queue, child)
+		}
+		parent.children = children
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestCriticalPath(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	span := func(id, parent uint64, start, duration time.Duration) *model.Span {
+		s := &model.Span{
+			TraceID:   traceID,
+			SpanID:    model.NewSpanID(id),
+			StartTime: time.Unix(0, 0).Add(start),
+			Duration:  duration,
+		}
+		if parent != 0 {
+			s.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parent))}
+		}
+		return s
+	}
+	ms := time.Millisecond
+
+	testCases := []struct {
+		name     string
+		spans    []*model.Span
+		expected map[uint64]int64
+	}{
+		{
+			name:     "single span",
+			spans:    []*model.Span{span(1, 0, 0, 10*ms)},
+			expected: map[uint64]int64{1: 10000},
+		},
+		{
+			// root [0, 100] with sequential children [10, 40] and [50, 90],
+			// and a child [20, 30] of the first one.
+			name: "sequential children",
+			spans: []*model.Span{
+				span(1, 0, 0, 100*ms),
+				span(2, 1, 10*ms, 30*ms),
+				span(3, 1, 50*ms, 40*ms),
+				span(4, 2, 20*ms, 10*ms),
+			},
+			expected: map[uint64]int64{1: 30000, 2: 20000, 3: 40000, 4: 10000},
+		},
+		{
+			// the child [10, 50] overlaps with the last finishing child [30, 80]
+			// and is not on the critical path.
+			name: "parallel children",
+			spans: []*model.Span{
+				span(1, 0, 0, 100*ms),
+				span(2, 1, 10*ms, 40*ms),
+				span(3, 1, 30*ms, 50*ms),
+				span(4, 1, 5*ms, 20*ms),
+			},
+			expected: map[uint64]int64{1: 30000, 2: 0, 3: 50000, 4: 20000},
+		},
+		{
+			// the child [80, 130] is clipped to [80, 100], the child [120, 130] is ignored.
+			name: "children outside of parent",
+			spans: []*model.Span{
+				span(1, 0, 0, 100*ms),
+				span(2, 1, 80*ms, 50*ms),
+				span(3, 1, 120*ms, 10*ms),
+			},
+			expected: map[uint64]int64{1: 80000, 2: 20000, 3: 0},
+		},
+		{
+			name: "zero duration child",
+			spans: []*model.Span{
+				span(1, 0, 0, 10*ms),
+				span(2, 1, 10*ms, 0),
+			},
+			expected: map[uint64]int64{1: 10000, 2: 0},
+		},
+		{
+			// only the earliest root is analyzed
+			name: "orphan span",
+			spans: []*model.Span{
+				span(2, 99, 5*ms, 10*ms),
+				span(1, 0, 0, 10*ms),
+			},
+			expected: map[uint64]int64{1: 10000, 2: 0},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			trace := &model.Trace{Spans: testCase.spans}
+			trace, err := CriticalPath().Adjust(trace)
+			require.NoError(t, err)
+			actual := make(map[uint64]int64)
+			for _, s := range trace.Spans {
+				tag, ok := model.KeyValues(s.Tags).FindByKey(CriticalPathTagKey)
+				require.True(t, ok)
+				actual[uint64(s.SpanID)] = tag.Int64()
+			}
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestCriticalPathEmptyTrace(t *testing.T) {
+	trace, err := CriticalPath().Adjust(&model.Trace{})
+	require.NoError(t, err)
+	assert.Empty(t, trace.Spans)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// ErrorTag is the canonical tag of the spans in error, always a boolean true once normalized.
+	ErrorTag = "error"
+	// StatusCodeTag is the canonical status of the spans: StatusCodeError, StatusCodeOK or StatusCodeUnset.
+	StatusCodeTag = "status.code"
+
+	StatusCodeError = "ERROR"
+	StatusCodeOK    = "OK"
+	StatusCodeUnset = "UNSET"
+
+	otelStatusCodeTag = "otel.status_code"
+	grpcStatusCodeTag = "rpc.grpc.status_code"
+)
+
+// httpStatusCodeTags are the tags of the HTTP response status code, of the semantic conventions
+// before and after v1.20.
+var httpStatusCodeTags = []string{"http.status_code", "http.response.status_code"}
+
+// grpcServerErrorCodes are the gRPC status codes signaling an error of the server spans, the other
+// ones being errors of the client, see the semantic conventions of the gRPC spans.
+var grpcServerErrorCodes = map[int64]struct{}{
+	2:  {}, // UNKNOWN
+	4:  {}, // DEADLINE_EXCEEDED
+	12: {}, // UNIMPLEMENTED
+	13: {}, // INTERNAL
+	14: {}, // UNAVAILABLE
+	15: {}, // DATA_LOSS
+}
+
+// ErrorStatus returns an adjuster that normalizes the error signaling of the spans across the SDKs,
+// so that the searches of the errors behave the same regardless of the SDK. A span is in error if
+// its otel.status_code is ERROR, its error tag is true, its HTTP status code is 5xx, or its gRPC
+// status code is not OK, for the client spans, or a server error, for the server spans; unless
+// its otel.status_code is OK, which is final in OpenTelemetry. The error tags of the span are
+// replaced by a single boolean error=true tag if it is in error, and its status.code tag is set.
+func ErrorStatus() Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		for _, span := range trace.Spans {
+			NormalizeErrorStatus(span)
+		}
+		return trace, nil
+	})
+}
+
+// NormalizeErrorStatus normalizes the error signaling of the span in place, see ErrorStatus.
+func NormalizeErrorStatus(span *model.Span) {
+	status := errorStatus(span)
+	tags := span.Tags[:0]
+	for _, tag := range span.Tags {
+		if tag.Key != ErrorTag && tag.Key != StatusCodeTag {
+			tags = append(tags, tag)
+		}
+	}
+	if status == StatusCodeError {
+		tags = append(tags, model.Bool(ErrorTag, true))
+	}
+	span.Tags = append(tags, model.String(StatusCodeTag, status))
+}
+
+// UnrecordedError reports whether the HTTP or gRPC status code of the span signals an error which
+// the span does not record with its otel.status_code or error tag, see ErrorStatus.
+func UnrecordedError(span *model.Span) bool {
+	_, recorded := recordedStatus(span)
+	return !recorded && HasErrorStatusCode(span)
+}
+
+func errorStatus(span *model.Span) string {
+	if status, ok := recordedStatus(span); ok {
+		return status
+	}
+	if HasErrorStatusCode(span) {
+		return StatusCodeError
+	}
+	return StatusCodeUnset
+}
+
+// recordedStatus returns the status recorded by the otel.status_code or error tag of the span, if any.
+func recordedStatus(span *model.Span) (string, bool) {
+	tags := model.KeyValues(span.Tags)
+	if tag, ok := tags.FindByKey(otelStatusCodeTag); ok {
+		switch strings.ToUpper(tag.AsString()) {
+		case StatusCodeOK:
+			return StatusCodeOK, true
+		case StatusCodeError:
+			return StatusCodeError, true
+		}
+	}
+	for _, tag := range tags {
+		if tag.Key == ErrorTag && isTrue(tag) {
+			return StatusCodeError, true
+		}
+	}
+	return "", false
+}
+
+// HasErrorStatusCode reports whether the HTTP or gRPC status code of the span signals an error,
+// a 5xx HTTP status code or a gRPC status code which is not OK, see ErrorStatus.
+func HasErrorStatusCode(span *model.Span) bool {
+	tags := model.KeyValues(span.Tags)
+	for _, key := range httpStatusCodeTags {
+		if tag, ok := tags.FindByKey(key); ok {
+			if code, ok := intValue(tag); ok && code >= 500 {
+				return true
+			}
+		}
+	}
+	if tag, ok := tags.FindByKey(grpcStatusCodeTag); ok {
+		if code, ok := intValue(tag); ok && code != 0 {
+			_, serverError := grpcServerErrorCodes[code]
+			return serverError || !span.HasSpanKind(trace.SpanKindServer)
+		}
+	}
+	return false
+}
+
+func isTrue(tag model.KeyValue) bool {
+	if tag.GetVType() == model.BoolType {
+		return tag.Bool()
+	}
+	value, err := strconv.ParseBool(tag.AsString())
+	return err == nil && value
+}
+
+func intValue(tag model.KeyValue) (int64, bool) {
+	switch tag.GetVType() {
+	case model.Int64Type:
+		return tag.Int64(), true
+	case model.Float64Type:
+		return int64(tag.Float64()), true
+	case model.StringType:
+		code, err := strconv.ParseInt(tag.AsString(), 10, 64)
+		return code, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestErrorStatus(t *testing.T) {
+	testCases := []struct {
+		description string
+		tags        model.KeyValues
+		expected    model.KeyValues
+	}{
+		{
+			description: "no error signal",
+			tags:        model.KeyValues{model.String("key", "value")},
+			expected:    model.KeyValues{model.String("key", "value"), model.String(StatusCodeTag, StatusCodeUnset)},
+		},
+		{
+			description: "otel status error",
+			tags:        model.KeyValues{model.String(otelStatusCodeTag, "ERROR")},
+			expected: model.KeyValues{
+				model.String(otelStatusCodeTag, "ERROR"),
+				model.Bool(ErrorTag, true),
+				model.String(StatusCodeTag, StatusCodeError),
+			},
+		},
+		{
+			description: "otel status ok overrides the error tag",
+			tags:        model.KeyValues{model.Bool(ErrorTag, true), model.String(otelStatusCodeTag, "OK")},
+			expected:    model.KeyValues{model.String(otelStatusCodeTag, "OK"), model.String(StatusCodeTag, StatusCodeOK)},
+		},
+		{
+			description: "string error tag",
+			tags:        model.KeyValues{model.String(ErrorTag, "true")},
+			expected:    model.KeyValues{model.Bool(ErrorTag, true), model.String(StatusCodeTag, StatusCodeError)},
+		},
+		{
+			description: "duplicate error tags",
+			tags:        model.KeyValues{model.String(ErrorTag, "false"), model.Bool(ErrorTag, true)},
+			expected:    model.KeyValues{model.Bool(ErrorTag, true), model.String(StatusCodeTag, StatusCodeError)},
+		},
+		{
+			description: "false error tag",
+			tags:        model.KeyValues{model.Bool(ErrorTag, false)},
+			expected:    model.KeyValues{model.String(StatusCodeTag, StatusCodeUnset)},
+		},
+		{
+			description: "http server error",
+			tags:        model.KeyValues{model.Int64("http.status_code", 503)},
+			expected: model.KeyValues{
+				model.Int64("http.status_code", 503),
+				model.Bool(ErrorTag, true),
+				model.String(StatusCodeTag, StatusCodeError),
+			},
+		},
+		{
+			description: "http string status code of the newer conventions",
+			tags:        model.KeyValues{model.String("http.response.status_code", "500")},
+			expected: model.KeyValues{
+				model.String("http.response.status_code", "500"),
+				model.Bool(ErrorTag, true),
+				model.String(StatusCodeTag, StatusCodeError),
+			},
+		},
+		{
+			description: "http client error",
+			tags:        model.KeyValues{model.Int64("http.status_code", 404)},
+			expected:    model.KeyValues{model.Int64("http.status_code", 404), model.String(StatusCodeTag, StatusCodeUnset)},
+		},
+		{
+			description: "grpc client span with a non ok code",
+			tags:        model.KeyValues{model.String("span.kind", "client"), model.Int64(grpcStatusCodeTag, 5)},
+			expected: model.KeyValues{
+				model.String("span.kind", "client"),
+				model.Int64(grpcStatusCodeTag, 5),
+				model.Bool(ErrorTag, true),
+				model.String(StatusCodeTag, StatusCodeError),
+			},
+		},
+		{
+			description: "grpc server span with a client error code",
+			tags:        model.KeyValues{model.String("span.kind", "server"), model.Int64(grpcStatusCodeTag, 5)},
+			expected: model.KeyValues{
+				model.String("span.kind", "server"),
+				model.Int64(grpcStatusCodeTag, 5),
+				model.String(StatusCodeTag, StatusCodeUnset),
+			},
+		},
+		{
+			description: "grpc server span with a server error code",
+			tags:        model.KeyValues{model.String("span.kind", "server"), model.Float64(grpcStatusCodeTag, 14)},
+			expected: model.KeyValues{
+				model.String("span.kind", "server"),
+				model.Float64(grpcStatusCodeTag, 14),
+				model.Bool(ErrorTag, true),
+				model.String(StatusCodeTag, StatusCodeError),
+			},
+		},
+		{
+			description: "grpc ok code",
+			tags:        model.KeyValues{model.Int64(grpcStatusCodeTag, 0), model.String(StatusCodeTag, "stale")},
+			expected:    model.KeyValues{model.Int64(grpcStatusCodeTag, 0), model.String(StatusCodeTag, StatusCodeUnset)},
+		},
+		{
+			description: "unparsable status codes",
+			tags:        model.KeyValues{model.String("http.status_code", "oops"), model.Bool(grpcStatusCodeTag, true)},
+			expected: model.KeyValues{
+				model.String("http.status_code", "oops"),
+				model.Bool(grpcStatusCodeTag, true),
+				model.String(StatusCodeTag, StatusCodeUnset),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			trace := &model.Trace{Spans: []*model.Span{{Tags: testCase.tags}}}
+			trace, err := ErrorStatus().Adjust(trace)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, model.KeyValues(trace.Spans[0].Tags))
+		})
+	}
+}
+
+func TestUnrecordedError(t *testing.T) {
+	testCases := []struct {
+		description string
+		tags        model.KeyValues
+		expected    bool
+	}{
+		{
+			description: "no error signal",
+			tags:        model.KeyValues{model.Int64("http.status_code", 200)},
+		},
+		{
+			description: "http server error without the error tag",
+			tags:        model.KeyValues{model.Int64("http.status_code", 503)},
+			expected:    true,
+		},
+		{
+			description: "http server error with the error tag",
+			tags:        model.KeyValues{model.Int64("http.status_code", 503), model.Bool(ErrorTag, true)},
+		},
+		{
+			description: "grpc error with the otel status",
+			tags:        model.KeyValues{model.Int64(grpcStatusCodeTag, 14), model.String(otelStatusCodeTag, "ERROR")},
+		},
+		{
+			description: "grpc error with an ok otel status",
+			tags:        model.KeyValues{model.Int64(grpcStatusCodeTag, 14), model.String(otelStatusCodeTag, "OK")},
+		},
+		{
+			description: "grpc client error without the error tag",
+			tags:        model.KeyValues{model.String("span.kind", "client"), model.Int64(grpcStatusCodeTag, 5)},
+			expected:    true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, UnrecordedError(&model.Span{Tags: testCase.tags}))
+		})
+	}
+}
@@ -18,6 +18,7 @@ package adjuster
 import (
 	"bytes"
 	"encoding/binary"
+	"net"
 	"strconv"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -26,24 +27,31 @@ import (
 var ipTagsToCorrect = map[string]struct{}{
 	"ip":        {},
 	"peer.ipv4": {},
+	"peer.ipv6": {},
 }
 
 // IPTagAdjuster returns an adjuster that replaces numeric "ip" tags,
 // which usually contain IPv4 packed into uint32, with their string
-// representation (e.g. "8.8.8.8"").
+// representation (e.g. "8.8.8.8""), as well as the binary ones, which
+// contain IPv4 or IPv6 packed into 4 or 16 bytes (e.g. "2001:db8::1").
 func IPTagAdjuster() Adjuster {
 	adjustTags := func(tags model.KeyValues) {
 		for i, tag := range tags {
+			if _, ok := ipTagsToCorrect[tag.Key]; !ok {
+				continue
+			}
 			var value uint32
 			switch tag.VType {
 			case model.Int64Type:
 				value = uint32(tag.Int64())
 			case model.Float64Type:
 				value = uint32(tag.Float64())
-			default:
+			case model.BinaryType:
+				if l := len(tag.Binary()); l == net.IPv4len || l == net.IPv6len {
+					tags[i] = model.String(tag.Key, net.IP(tag.Binary()).String())
+				}
 				continue
-			}
-			if _, ok := ipTagsToCorrect[tag.Key]; !ok {
+			default:
 				continue
 			}
 			var buf [4]byte
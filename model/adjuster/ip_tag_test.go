@@ -36,6 +36,10 @@ func TestIPTagAdjuster(t *testing.T) {
 					model.String("peer.ipv4", "not integer"),
 					model.Int64("peer.ipv4", 1<<24|2<<16|3<<8|4),
 					model.Float64("peer.ipv4", 1<<24|2<<16|3<<8|4),
+					model.Binary("peer.ipv6", []byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}),
+					model.Binary("peer.ipv6", []byte{1, 2}),
+					model.Binary("ip", []byte{1, 2, 3, 4}),
+					model.Binary("a", []byte{1, 2, 3, 4}),
 				},
 				Process: &model.Process{
 					Tags: model.KeyValues{
@@ -58,6 +62,10 @@ func TestIPTagAdjuster(t *testing.T) {
 		model.String("peer.ipv4", "not integer"),
 		model.String("peer.ipv4", "1.2.3.4"),
 		model.String("peer.ipv4", "1.2.3.4"),
+		model.String("peer.ipv6", "2001:db8::1"),
+		model.Binary("peer.ipv6", []byte{1, 2}),
+		model.String("ip", "1.2.3.4"),
+		model.Binary("a", []byte{1, 2, 3, 4}),
 	}
 	assert.Equal(t, expectedSpanTags, model.KeyValues(trace.Spans[0].Tags))
 
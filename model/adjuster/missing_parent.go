@@ -0,0 +1,92 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// MissingSpanOperationName is the operation name of the placeholder spans.
+	MissingSpanOperationName = "missing span"
+	// MissingSpanServiceName is the service name of the placeholder spans.
+	MissingSpanServiceName = "missing-service"
+	// MissingSpanTagKey is the tag set to true on the placeholder spans.
+	MissingSpanTagKey = "jaeger.missing_span"
+
+	warningFormatMissingSpan = "placeholder for the span referenced by %d spans but missing from the trace; its timing is inferred from its children"
+)
+
+// MissingParentSpans returns an adjuster that adds placeholder spans for the parent
+// spans referenced but missing from the trace, e.g. not yet received or dropped by the
+// pipeline, so that the trace renders as a tree instead of a forest of orphan spans.
+// A placeholder spans the time range of its children, and it is a child of the root
+// span when the trace has a single one, since all the spans of a trace descend from it.
+//
+// The algorithm assumes that all spans have unique IDs, so the trace may need
+// to go through another adjuster first, such as SpanIDDeduper.
+//
+// This adjuster never returns any errors.
+func MissingParentSpans() Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		spanIDs := make(map[model.SpanID]struct{}, len(trace.Spans))
+		for _, span := range trace.Spans {
+			spanIDs[span.SpanID] = struct{}{}
+		}
+		var roots []*model.Span
+		orphans := make(map[model.SpanID][]*model.Span)
+		for _, span := range trace.Spans {
+			parentID := span.ParentSpanID()
+			if parentID == 0 {
+				roots = append(roots, span)
+				continue
+			}
+			if _, ok := spanIDs[parentID]; !ok {
+				orphans[parentID] = append(orphans[parentID], span)
+			}
+		}
+		if len(orphans) == 0 {
+			return trace, nil
+		}
+		missingIDs := make([]model.SpanID, 0, len(orphans))
+		for id := range orphans {
+			missingIDs = append(missingIDs, id)
+		}
+		// deterministic order of the placeholders in the trace
+		sort.Slice(missingIDs, func(i, j int) bool { return missingIDs[i] < missingIDs[j] })
+		for _, id := range missingIDs {
+			span := newMissingSpan(id, orphans[id])
+			if len(roots) == 1 {
+				span.References = []model.SpanRef{model.NewChildOfRef(roots[0].TraceID, roots[0].SpanID)}
+			}
+			trace.Spans = append(trace.Spans, span)
+		}
+		return trace, nil
+	})
+}
+
+func newMissingSpan(spanID model.SpanID, children []*model.Span) *model.Span {
+	start, end := children[0].StartTime, children[0].StartTime.Add(children[0].Duration)
+	for _, child := range children[1:] {
+		if child.StartTime.Before(start) {
+			start = child.StartTime
+		}
+		if childEnd := child.StartTime.Add(child.Duration); childEnd.After(end) {
+			end = childEnd
+		}
+	}
+	return &model.Span{
+		TraceID:       children[0].TraceID,
+		SpanID:        spanID,
+		OperationName: MissingSpanOperationName,
+		StartTime:     start,
+		Duration:      end.Sub(start),
+		Tags:          []model.KeyValue{model.Bool(MissingSpanTagKey, true)},
+		Process:       &model.Process{ServiceName: MissingSpanServiceName},
+		Warnings:      []string{fmt.Sprintf(warningFormatMissingSpan, len(children))},
+	}
+}
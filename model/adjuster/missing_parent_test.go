@@ -0,0 +1,83 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestMissingParentSpans(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newSpan := func(spanID, parentID model.SpanID, start, duration time.Duration) *model.Span {
+		span := &model.Span{
+			TraceID:   traceID,
+			SpanID:    spanID,
+			StartTime: now.Add(start),
+			Duration:  duration,
+			Process:   &model.Process{ServiceName: "svc"},
+		}
+		if parentID != 0 {
+			span.References = []model.SpanRef{model.NewChildOfRef(traceID, parentID)}
+		}
+		return span
+	}
+	missingSpan := func(spanID model.SpanID, start, duration time.Duration, children int) *model.Span {
+		return &model.Span{
+			TraceID:       traceID,
+			SpanID:        spanID,
+			OperationName: MissingSpanOperationName,
+			StartTime:     now.Add(start),
+			Duration:      duration,
+			Tags:          []model.KeyValue{model.Bool(MissingSpanTagKey, true)},
+			Process:       &model.Process{ServiceName: MissingSpanServiceName},
+			Warnings:      []string{fmt.Sprintf(warningFormatMissingSpan, children)},
+		}
+	}
+
+	t.Run("complete trace", func(t *testing.T) {
+		trace := &model.Trace{Spans: []*model.Span{
+			newSpan(1, 0, 0, time.Second),
+			newSpan(2, 1, 0, time.Second),
+		}}
+		adjusted, err := MissingParentSpans().Adjust(trace)
+		require.NoError(t, err)
+		assert.Len(t, adjusted.Spans, 2)
+	})
+
+	t.Run("missing root", func(t *testing.T) {
+		trace := &model.Trace{Spans: []*model.Span{
+			newSpan(2, 1, 2*time.Second, time.Second),
+			newSpan(3, 1, time.Second, time.Second),
+		}}
+		adjusted, err := MissingParentSpans().Adjust(trace)
+		require.NoError(t, err)
+		require.Len(t, adjusted.Spans, 3)
+		assert.Equal(t, missingSpan(1, time.Second, 2*time.Second, 2), adjusted.Spans[2])
+	})
+
+	t.Run("missing intermediate spans", func(t *testing.T) {
+		trace := &model.Trace{Spans: []*model.Span{
+			newSpan(1, 0, 0, 10*time.Second),
+			newSpan(3, 2, time.Second, time.Second),
+			newSpan(5, 4, 2*time.Second, time.Second),
+		}}
+		adjusted, err := MissingParentSpans().Adjust(trace)
+		require.NoError(t, err)
+		require.Len(t, adjusted.Spans, 5)
+		for i, id := range []model.SpanID{2, 4} {
+			span := adjusted.Spans[3+i]
+			assert.Equal(t, id, span.SpanID)
+			// the placeholders are attached to the single root
+			assert.Equal(t, model.SpanID(1), span.ParentSpanID())
+		}
+	})
+}
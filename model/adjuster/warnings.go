@@ -0,0 +1,61 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"strings"
+)
+
+// The types of the warnings recorded in Span.Warnings by the adjusters.
+const (
+	WarningTypeClockSkew        = "clock-skew"
+	WarningTypeDuplicateSpanID  = "duplicate-span-id"
+	WarningTypeInvalidParent    = "invalid-parent"
+	WarningTypeInvalidReference = "invalid-reference"
+	WarningTypeMissingSpan      = "missing-span"
+	// WarningTypeOther is the type of the warnings not recorded by the adjusters,
+	// e.g. by the SDKs or the collector.
+	WarningTypeOther = "other"
+)
+
+// WarningTypes lists the types of the warnings, see WarningType.
+var WarningTypes = []string{
+	WarningTypeClockSkew,
+	WarningTypeDuplicateSpanID,
+	WarningTypeInvalidParent,
+	WarningTypeInvalidReference,
+	WarningTypeMissingSpan,
+	WarningTypeOther,
+}
+
+var warningPrefixes = []struct {
+	prefix      string
+	warningType string
+}{
+	{prefix: warningPrefix(warningFormatAdjusted), warningType: WarningTypeClockSkew},
+	{prefix: warningPrefix(warningMaxDeltaExceeded), warningType: WarningTypeClockSkew},
+	{prefix: warningPrefix(warningSkewAdjustDisabled), warningType: WarningTypeClockSkew},
+	{prefix: warningDuplicateSpanID, warningType: WarningTypeDuplicateSpanID},
+	{prefix: warningTooManySpans, warningType: WarningTypeDuplicateSpanID},
+	{prefix: warningPrefix(warningFormatInvalidParentID), warningType: WarningTypeInvalidParent},
+	{prefix: warningPrefix(warningFormatInvalidReference), warningType: WarningTypeInvalidReference},
+	{prefix: warningPrefix(warningFormatMissingSpan), warningType: WarningTypeMissingSpan},
+}
+
+// warningPrefix returns the constant part of a warning format.
+func warningPrefix(format string) string {
+	prefix, _, _ := strings.Cut(format, "%")
+	return prefix
+}
+
+// WarningType returns the type of a warning of a span, WarningTypeOther when it is
+// not recorded by one of the adjusters.
+func WarningType(warning string) string {
+	for _, p := range warningPrefixes {
+		if strings.HasPrefix(warning, p.prefix) {
+			return p.warningType
+		}
+	}
+	return WarningTypeOther
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestWarningType(t *testing.T) {
+	testCases := []struct {
+		warning     string
+		warningType string
+	}{
+		{warning: fmt.Sprintf(warningFormatAdjusted, time.Second), warningType: WarningTypeClockSkew},
+		{warning: fmt.Sprintf(warningFormatOffsetAdjusted, time.Second), warningType: WarningTypeClockSkew},
+		{warning: fmt.Sprintf(warningMaxDeltaExceeded, time.Second, time.Minute), warningType: WarningTypeClockSkew},
+		{warning: fmt.Sprintf(warningSkewAdjustDisabled, time.Second), warningType: WarningTypeClockSkew},
+		{warning: warningDuplicateSpanID, warningType: WarningTypeDuplicateSpanID},
+		{warning: warningTooManySpans, warningType: WarningTypeDuplicateSpanID},
+		{warning: fmt.Sprintf(warningFormatInvalidParentID, model.NewSpanID(1)), warningType: WarningTypeInvalidParent},
+		{warning: fmt.Sprintf(warningFormatInvalidReference, model.SpanRef{}), warningType: WarningTypeInvalidReference},
+		{warning: fmt.Sprintf(warningFormatMissingSpan, 2), warningType: WarningTypeMissingSpan},
+		{warning: "reported by the SDK", warningType: WarningTypeOther},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.warning, func(t *testing.T) {
+			assert.Equal(t, testCase.warningType, WarningType(testCase.warning))
+		})
+	}
+}
@@ -44,6 +44,8 @@ func SamplingStrategyResponseToJSON(protoObj *api_v2.SamplingStrategyResponse) (
 	str = strings.ReplaceAll(str, `"probabilisticSampling":null,`, "")
 	str = strings.ReplaceAll(str, `,"rateLimitingSampling":null`, "")
 	str = strings.ReplaceAll(str, `,"operationSampling":null`, "")
+	// The operation strategies of all the span kinds are rendered the same as before span kinds were supported.
+	str = strings.ReplaceAll(str, `,"spanKind":""`, "")
 
 	return str, nil
 }
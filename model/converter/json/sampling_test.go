@@ -110,3 +110,35 @@ func TestSamplingStrategyResponseFromJSON(t *testing.T) {
 	assert.Equal(t, s1.GetStrategyType(), s2.GetStrategyType())
 	assert.EqualValues(t, s1.GetProbabilisticSampling(), s2.GetProbabilisticSampling())
 }
+
+func TestSamplingStrategyResponseSpanKind(t *testing.T) {
+	s1 := &api_v2.SamplingStrategyResponse{
+		OperationSampling: &api_v2.PerOperationSamplingStrategies{
+			DefaultSamplingProbability: 0.1,
+			PerOperationStrategies: []*api_v2.OperationSamplingStrategy{
+				{
+					Operation:             "orders",
+					SpanKind:              "consumer",
+					ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.01},
+				},
+				{
+					Operation:             "orders",
+					ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.5},
+				},
+			},
+		},
+	}
+	json, err := SamplingStrategyResponseToJSON(s1)
+	require.NoError(t, err)
+	assert.Contains(t, json, `"spanKind":"consumer"`)
+
+	s2, err := SamplingStrategyResponseFromJSON([]byte(json))
+	require.NoError(t, err)
+	assert.Equal(t, s1.OperationSampling.PerOperationStrategies, s2.OperationSampling.PerOperationStrategies)
+
+	data, err := s1.Marshal()
+	require.NoError(t, err)
+	s3 := &api_v2.SamplingStrategyResponse{}
+	require.NoError(t, s3.Unmarshal(data))
+	assert.Equal(t, s1.OperationSampling.PerOperationStrategies, s3.OperationSampling.PerOperationStrategies)
+}
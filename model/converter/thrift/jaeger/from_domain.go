@@ -42,6 +42,17 @@ func FromDomainSpan(span *model.Span) *jaeger.Span {
 	return dToJ.transformSpan(span)
 }
 
+// FromDomainProcess takes a model.Process and converts it into a jaeger.Process.
+func FromDomainProcess(process *model.Process) *jaeger.Process {
+	if process == nil {
+		return nil
+	}
+	return &jaeger.Process{
+		ServiceName: process.ServiceName,
+		Tags:        domainToJaegerTransformer{}.convertKeyValuesToTags(process.Tags),
+	}
+}
+
 type domainToJaegerTransformer struct{}
 
 func (domainToJaegerTransformer) keyValueToTag(kv *model.KeyValue) *jaeger.Tag {
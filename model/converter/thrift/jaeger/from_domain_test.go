@@ -57,6 +57,14 @@ func TestFromDomain(t *testing.T) {
 	assert.Equal(t, modelSpans, newModelSpans)
 }
 
+func TestFromDomainProcess(t *testing.T) {
+	jaegerBatch := loadBatch(t, "fixtures/thrift_batch_01.json")
+	modelProcess := ToDomainProcess(jaegerBatch.Process)
+
+	assert.Equal(t, modelProcess, ToDomainProcess(FromDomainProcess(modelProcess)))
+	assert.Nil(t, FromDomainProcess(nil))
+}
+
 func TestKeyValueToTag(t *testing.T) {
 	dToJ := domainToJaegerTransformer{}
 	jaegerTag := dToJ.keyValueToTag(&model.KeyValue{
@@ -70,9 +70,13 @@ func convertPerOperationFromDomain(s *api_v2.PerOperationSamplingStrategies) *sa
 
 	perOp := s.GetPerOperationStrategies()
 	// Default to empty array so that json.Marshal returns [] instead of null (Issue #3891).
-	r.PerOperationStrategies = make([]*sampling.OperationSamplingStrategy, len(perOp))
-	for i, k := range perOp {
-		r.PerOperationStrategies[i] = convertOperationFromDomain(k)
+	r.PerOperationStrategies = make([]*sampling.OperationSamplingStrategy, 0, len(perOp))
+	for _, k := range perOp {
+		// Thrift has no span kind, the strategies of a single span kind would apply to all the spans of the operation.
+		if k.GetSpanKind() != "" {
+			continue
+		}
+		r.PerOperationStrategies = append(r.PerOperationStrategies, convertOperationFromDomain(k))
 	}
 	return r
 }
@@ -123,6 +123,16 @@ func TestConvertPerOperationStrategyFromDomain(t *testing.T) {
 				PerOperationStrategies: []*sampling.OperationSamplingStrategy{},
 			},
 		},
+		{
+			in: &api_v2.PerOperationSamplingStrategies{
+				DefaultSamplingProbability: 15.2, DefaultUpperBoundTracesPerSecond: a, DefaultLowerBoundTracesPerSecond: 2,
+				PerOperationStrategies: []*api_v2.OperationSamplingStrategy{{Operation: "fao", SpanKind: "consumer"}, {Operation: "fao"}},
+			},
+			expected: &sampling.PerOperationSamplingStrategies{
+				DefaultSamplingProbability: 15.2, DefaultUpperBoundTracesPerSecond: &a, DefaultLowerBoundTracesPerSecond: 2,
+				PerOperationStrategies: []*sampling.OperationSamplingStrategy{{Operation: "fao"}},
+			},
+		},
 	}
 	for _, test := range tests {
 		o := convertPerOperationFromDomain(test.in)
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 
 	"go.opentelemetry.io/otel/trace"
 
@@ -228,41 +229,46 @@ func (td toDomain) generateProcess(zSpan *zipkincore.Span) (*model.Process, erro
 	for i, tag := range tags {
 		tags[i].Key = processTagAnnotations[tag.Key]
 	}
-	serviceName, ipv4, err := td.findServiceNameAndIP(zSpan)
-	if ipv4 != 0 {
+	serviceName, host, err := td.findServiceNameAndHost(zSpan)
+	if host != nil {
 		// If the ip process tag already exists, don't add it again
-		tags = append(tags, model.Int64(IPTagName, int64(uint64(ipv4))))
+		if host.Ipv4 != 0 {
+			tags = append(tags, model.Int64(IPTagName, int64(uint64(host.Ipv4))))
+		} else if len(host.Ipv6) == net.IPv6len {
+			tags = append(tags, model.String(IPTagName, net.IP(host.Ipv6).String()))
+		}
 	}
 	return model.NewProcess(serviceName, tags), err
 }
 
-func (td toDomain) findServiceNameAndIP(zSpan *zipkincore.Span) (string, int32, error) {
+// findServiceNameAndHost returns the service name and the endpoint of the host of the span.
+func (td toDomain) findServiceNameAndHost(zSpan *zipkincore.Span) (string, *zipkincore.Endpoint, error) {
 	for _, a := range zSpan.Annotations {
 		if td.isCoreAnnotation(a) && a.Host != nil && a.Host.ServiceName != "" {
-			return a.Host.ServiceName, a.Host.Ipv4, nil
+			return a.Host.ServiceName, a.Host, nil
 		}
 	}
 	for _, a := range zSpan.BinaryAnnotations {
 		if a.Key == zipkincore.LOCAL_COMPONENT && a.Host != nil && a.Host.ServiceName != "" {
-			return a.Host.ServiceName, a.Host.Ipv4, nil
+			return a.Host.ServiceName, a.Host, nil
 		}
 	}
 	// If no core annotations exist, use the service name from any annotation
 	for _, a := range zSpan.Annotations {
 		if a.Host != nil && a.Host.ServiceName != "" {
-			return a.Host.ServiceName, a.Host.Ipv4, nil
+			return a.Host.ServiceName, a.Host, nil
 		}
 	}
 	// Tracer can also report a span with just binary annotation/s
 	for _, a := range zSpan.BinaryAnnotations {
 		if a.Host != nil && a.Host.ServiceName != "" {
-			return a.Host.ServiceName, a.Host.Ipv4, nil
+			return a.Host.ServiceName, a.Host, nil
 		}
 	}
 	err := fmt.Errorf(
 		"cannot find service name in Zipkin span [traceID=%x, spanID=%x]",
 		uint64(zSpan.TraceID), uint64(zSpan.ID))
-	return UnknownServiceName, 0, err
+	return UnknownServiceName, nil, err
 }
 
 func (toDomain) isCoreAnnotation(annotation *zipkincore.Annotation) bool {
@@ -409,7 +415,11 @@ func (toDomain) getPeerTags(endpoint *zipkincore.Endpoint, tags []model.KeyValue
 	if endpoint.Ipv6 != nil {
 		// Zipkin defines Ipv6 field as: "IPv6 host address packed into 16 bytes. Ex Inet6Address.getBytes()".
 		// https://github.com/openzipkin/zipkin-api/blob/master/thrift/zipkinCore.thrift#L305
-		tags = append(tags, model.Binary(peerHostIPv6, endpoint.Ipv6))
+		if len(endpoint.Ipv6) == net.IPv6len {
+			tags = append(tags, model.String(peerHostIPv6, net.IP(endpoint.Ipv6).String()))
+		} else {
+			tags = append(tags, model.Binary(peerHostIPv6, endpoint.Ipv6))
+		}
 	}
 	if endpoint.Port != 0 {
 		port := int64(uint16(endpoint.Port))
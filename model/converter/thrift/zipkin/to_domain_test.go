@@ -91,6 +91,21 @@ func TestToDomainServiceNameInBinAnnotation(t *testing.T) {
 	assert.Equal(t, "bar", trace.Spans[0].Process.ServiceName)
 }
 
+func TestToDomainIPv6(t *testing.T) {
+	zSpans := getZipkinSpans(t, `[{ "trace_id": -1, "id": 31,
+	"annotations": [{"value": "sr", "timestamp": 1, "host": {"service_name": "bar", "ipv6": "IAENuAAAAAAAAAAAAAAAAQ=="}}],
+	"binary_annotations": [{"key": "ca", "host": {"service_name": "foo", "ipv6": "IAENuAAAAAAAAAAAAAAAAg=="}}] }]`)
+	trace, err := ToDomain(zSpans)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 1)
+	ip, ok := model.KeyValues(trace.Spans[0].Process.Tags).FindByKey(IPTagName)
+	require.True(t, ok)
+	assert.Equal(t, "2001:db8::1", ip.AsString())
+	peer, ok := model.KeyValues(trace.Spans[0].Tags).FindByKey(peerHostIPv6)
+	require.True(t, ok)
+	assert.Equal(t, model.String(peerHostIPv6, "2001:db8::2"), peer)
+}
+
 func TestToDomainWithDurationFromServerAnnotations(t *testing.T) {
 	zSpans := getZipkinSpans(t, `[{ "trace_id": -1, "id": 31, "annotations": [
 	{"value": "sr", "timestamp": 1, "host": {"service_name": "bar", "ipv4": 23456}},
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gogo/protobuf/jsonpb"
 )
@@ -54,6 +55,24 @@ func (t TraceID) String() string {
 	return fmt.Sprintf("%016x%016x", t.High, t.Low)
 }
 
+// HexString returns the 32 hexadecimal characters of the trace ID, with the high bits zero-padded
+// even if they are zero, as in the W3C Trace Context and OTLP, unlike String.
+func (t TraceID) HexString() string {
+	return fmt.Sprintf("%016x%016x", t.High, t.Low)
+}
+
+// ParseTraceID creates a TraceID from any of its common forms: the up to 16 hexadecimal characters
+// of a 64bit trace ID, the up to 32 hexadecimal characters of a 128bit trace ID, zero-padded or not,
+// in lower or upper case, or a W3C traceparent header, see ParseTraceParent.
+func ParseTraceID(s string) (TraceID, error) {
+	s = strings.TrimSpace(s)
+	if strings.Count(s, "-") >= 3 {
+		traceID, _, _, err := ParseTraceParent(s)
+		return traceID, err
+	}
+	return TraceIDFromString(s)
+}
+
 // TraceIDFromString creates a TraceID from a hexadecimal string
 func TraceIDFromString(s string) (TraceID, error) {
 	var hi, lo uint64
@@ -128,3 +128,36 @@ func TestTraceIDFromBytes(t *testing.T) {
 		assert.Equal(t, test.expected, traceID)
 	}
 }
+
+func TestTraceIDHexString(t *testing.T) {
+	assert.Equal(t, "0000000000000000000000000000002a", model.NewTraceID(0, 42).HexString())
+	assert.Equal(t, "0000000000000001000000000000002a", model.NewTraceID(1, 42).HexString())
+	assert.Equal(t, "000000000000002a", model.NewTraceID(0, 42).String(), "String omits the zero high bits")
+}
+
+func TestParseTraceID(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected model.TraceID
+	}{
+		{input: "2a", expected: model.NewTraceID(0, 42)},
+		{input: "000000000000002a", expected: model.NewTraceID(0, 42)},
+		{input: "0000000000000000000000000000002a", expected: model.NewTraceID(0, 42)},
+		{input: "0000000000000001000000000000002a", expected: model.NewTraceID(1, 42)},
+		{input: "1000000000000002a", expected: model.NewTraceID(1, 42)},
+		{input: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", expected: model.NewTraceID(^uint64(0), ^uint64(0))},
+		{input: " 2a\n", expected: model.NewTraceID(0, 42)},
+		{input: "00-0000000000000001000000000000002a-00f067aa0ba902b7-01", expected: model.NewTraceID(1, 42)},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			traceID, err := model.ParseTraceID(testCase.input)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, traceID)
+		})
+	}
+	for _, input := range []string{"", "xyz", "+2a", "0x2a", "000000000000000000000000000000002a", "00-2a-00f067aa0ba902b7-01"} {
+		_, err := model.ParseTraceID(input)
+		require.Error(t, err, input)
+	}
+}
@@ -110,8 +110,90 @@ type DependencyLink struct {
 	CallCount uint64 `json:"callCount"`
 }
 
+// DependencyLatency is a DependencyLink with the distribution of the durations of its calls
+type DependencyLatency struct {
+	Parent    string              `json:"parent"`
+	Child     string              `json:"child"`
+	CallCount uint64              `json:"callCount"`
+	Latency   LatencyDistribution `json:"latency"`
+}
+
 // Operation defines the data in the operation response when query operation by service and span kind
 type Operation struct {
 	Name     string `json:"name"`
 	SpanKind string `json:"spanKind"`
 }
+
+// LatencyDistribution shows the percentiles and histogram of span durations, in microseconds
+type LatencyDistribution struct {
+	Count        int64    `json:"count"`
+	P50          uint64   `json:"p50"`
+	P95          uint64   `json:"p95"`
+	P99          uint64   `json:"p99"`
+	BucketBounds []uint64 `json:"bucketBounds"`
+	BucketCounts []int64  `json:"bucketCounts"`
+}
+
+// CallPath is a chain of calls between services, from the root span of the traces to a service calling no other one
+type CallPath struct {
+	Hops       []CallHop `json:"hops"`
+	Count      int64     `json:"count"`
+	TraceCount int64     `json:"traceCount"`
+}
+
+// CallHop is a call to a service in a CallPath, durations of the spans entering the service are in microseconds
+type CallHop struct {
+	ServiceName   string `json:"serviceName"`
+	OperationName string `json:"operationName"`
+	P50           uint64 `json:"p50"`
+	P95           uint64 `json:"p95"`
+	P99           uint64 `json:"p99"`
+}
+
+// QualityScore shows the instrumentation quality of a service, the scores are between 0 and 1
+type QualityScore struct {
+	ServiceName string               `json:"serviceName"`
+	SpanCount   int64                `json:"spanCount"`
+	TraceCount  int64                `json:"traceCount"`
+	Score       float64              `json:"score"`
+	Checks      []QualityCheckResult `json:"checks"`
+}
+
+// QualityCheckResult shows the number of spans a check of a QualityScore applies to and of those failing it
+type QualityCheckResult struct {
+	Check   string  `json:"check"`
+	Checked int64   `json:"checked"`
+	Failed  int64   `json:"failed"`
+	Score   float64 `json:"score"`
+}
+
+// TraceDiff shows the structural difference between two traces
+type TraceDiff struct {
+	BaseTraceID  TraceID    `json:"baseTraceID"`
+	OtherTraceID TraceID    `json:"otherTraceID"`
+	Spans        []SpanDiff `json:"spans"`
+}
+
+// SpanDiff shows how a span differs between two traces, durations are in microseconds
+type SpanDiff struct {
+	Type               string `json:"type"`
+	ServiceName        string `json:"serviceName"`
+	BaseSpanID         SpanID `json:"baseSpanID,omitempty"`
+	OtherSpanID        SpanID `json:"otherSpanID,omitempty"`
+	BaseOperationName  string `json:"baseOperationName,omitempty"`
+	OtherOperationName string `json:"otherOperationName,omitempty"`
+	BaseDuration       uint64 `json:"baseDuration,omitempty"`
+	OtherDuration      uint64 `json:"otherDuration,omitempty"`
+	DurationDelta      int64  `json:"durationDelta"`
+}
+
+// ExemplarCandidate is a trace that may have produced a metric exemplar, times are in microseconds
+type ExemplarCandidate struct {
+	TraceID       TraceID `json:"traceID"`
+	SpanID        SpanID  `json:"spanID"`
+	OperationName string  `json:"operationName"`
+	StartTime     uint64  `json:"startTime"`
+	Duration      uint64  `json:"duration"`
+	TimeOffset    uint64  `json:"timeOffset"`
+	LatencyDelta  uint64  `json:"latencyDelta"`
+}
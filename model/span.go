@@ -18,6 +18,7 @@ package model
 import (
 	"encoding/gob"
 	"io"
+	"math"
 	"strconv"
 
 	"go.opentelemetry.io/otel/trace"
@@ -40,6 +41,10 @@ const (
 	// FirehoseFlag is the bit in Flags in order to define a span as a firehose span
 	FirehoseFlag = Flags(8)
 
+	// AdjustedCountTag is the tag of the number of spans of the traffic represented by a sampled
+	// span, the inverse of its sampling probability
+	AdjustedCountTag = "sampling.adjusted_count"
+
 	keySamplerType  = "sampler.type"
 	keySpanKind     = "span.kind"
 	keySamplerParam = "sampler.param"
@@ -100,9 +105,15 @@ func (s *Span) HasSpanKind(kind trace.SpanKind) bool {
 // GetSpanKind returns value of `span.kind` tag and whether the tag can be found
 func (s *Span) GetSpanKind() (spanKind trace.SpanKind, found bool) {
 	if tag, ok := KeyValues(s.Tags).FindByKey(keySpanKind); ok {
-		if kind, ok := toSpanKind[tag.AsString()]; ok {
-			return kind, true
-		}
+		return SpanKindFromString(tag.AsString())
+	}
+	return trace.SpanKindUnspecified, false
+}
+
+// SpanKindFromString returns the span kind of its `span.kind` tag value, e.g. "server", and whether it is known.
+func SpanKindFromString(kind string) (trace.SpanKind, bool) {
+	if spanKind, ok := toSpanKind[kind]; ok {
+		return spanKind, true
 	}
 	return trace.SpanKindUnspecified, false
 }
@@ -194,6 +205,20 @@ func (s *Span) GetSamplerParams(logger *zap.Logger) (SamplerType, float64) {
 	return samplerType, samplerParam
 }
 
+// GetAdjustedCount returns the number of spans of the traffic represented by the span, the value
+// of its sampling.adjusted_count tag, or 1 if it has no valid adjusted count.
+func (s *Span) GetAdjustedCount() float64 {
+	tag, ok := KeyValues(s.Tags).FindByKey(AdjustedCountTag)
+	if !ok {
+		return 1
+	}
+	count, err := samplerParamToFloat(tag)
+	if err != nil || count < 0 || math.IsInf(count, 0) || math.IsNaN(count) {
+		return 1
+	}
+	return count
+}
+
 // ------- Flags -------
 
 // SetSampled sets the Flags as sampled
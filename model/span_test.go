@@ -232,6 +232,16 @@ func TestGetSpanKind(t *testing.T) {
 	assert.True(t, found)
 }
 
+func TestSpanKindFromString(t *testing.T) {
+	spanKind, found := model.SpanKindFromString("consumer")
+	assert.Equal(t, trace.SpanKindConsumer, spanKind)
+	assert.True(t, found)
+
+	spanKind, found = model.SpanKindFromString("worker")
+	assert.Equal(t, trace.SpanKindUnspecified, spanKind)
+	assert.False(t, found)
+}
+
 func TestSamplerType(t *testing.T) {
 	span := makeSpan(model.String("sampler.type", "lowerbound"))
 	assert.Equal(t, model.SamplerTypeLowerBound, span.GetSamplerType())
@@ -509,3 +519,26 @@ func TestGetSamplerParams(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAdjustedCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     model.KeyValues
+		expected float64
+	}{
+		{name: "no tag", expected: 1},
+		{name: "float", tags: model.KeyValues{model.Float64("sampling.adjusted_count", 2.5)}, expected: 2.5},
+		{name: "int", tags: model.KeyValues{model.Int64("sampling.adjusted_count", 16)}, expected: 16},
+		{name: "string", tags: model.KeyValues{model.String("sampling.adjusted_count", "4")}, expected: 4},
+		{name: "zero", tags: model.KeyValues{model.Float64("sampling.adjusted_count", 0)}, expected: 0},
+		{name: "not a number", tags: model.KeyValues{model.String("sampling.adjusted_count", "many")}, expected: 1},
+		{name: "negative", tags: model.KeyValues{model.Float64("sampling.adjusted_count", -2)}, expected: 1},
+		{name: "infinite", tags: model.KeyValues{model.String("sampling.adjusted_count", "+Inf")}, expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			span := &model.Span{Tags: test.tags}
+			assert.Equal(t, test.expected, span.GetAdjustedCount())
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// traceParentVersion is the version of the W3C traceparent headers generated by FormatTraceParent.
+	traceParentVersion = "00"
+	// traceParentLen is the length of the W3C traceparent headers of version 00.
+	traceParentLen = 55
+	// traceParentSampled is the bit of the sampled flag of the W3C traceparent headers.
+	traceParentSampled = 0x01
+)
+
+// FormatTraceParent returns the W3C traceparent header of the span of the trace, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func FormatTraceParent(traceID TraceID, spanID SpanID, sampled bool) string {
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	return traceParentVersion + "-" + traceID.HexString() + "-" + spanID.String() + "-" + flags
+}
+
+// ParseTraceParent parses a W3C traceparent header into the trace ID, the parent span ID and the
+// sampled flag. It validates the header as specified by the W3C Trace Context: the IDs are lower
+// case hexadecimal and not zero, and the headers of the future versions may have more fields.
+func ParseTraceParent(header string) (TraceID, SpanID, bool, error) {
+	invalid := func(reason string) (TraceID, SpanID, bool, error) {
+		return TraceID{}, 0, false, fmt.Errorf("invalid traceparent %q: %s", header, reason)
+	}
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return invalid("must be version-traceid-parentid-flags")
+	}
+	version, traceIDHex, spanIDHex, flagsHex := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" {
+		return invalid("the version must be 2 lower case hexadecimal characters other than ff")
+	}
+	if version == traceParentVersion && (len(parts) != 4 || len(header) != traceParentLen) {
+		return invalid("the version 00 must have exactly 4 fields")
+	}
+	if len(traceIDHex) != 32 || !isLowerHex(traceIDHex) {
+		return invalid("the trace ID must be 32 lower case hexadecimal characters")
+	}
+	if len(spanIDHex) != 16 || !isLowerHex(spanIDHex) {
+		return invalid("the parent ID must be 16 lower case hexadecimal characters")
+	}
+	if len(flagsHex) != 2 || !isLowerHex(flagsHex) {
+		return invalid("the flags must be 2 lower case hexadecimal characters")
+	}
+	traceID, err := TraceIDFromString(traceIDHex)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	spanID, err := SpanIDFromString(spanIDHex)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if traceID == (TraceID{}) {
+		return invalid("the trace ID cannot be zero")
+	}
+	if spanID == 0 {
+		return invalid("the parent ID cannot be zero")
+	}
+	flags, err := strconv.ParseUint(flagsHex, 16, 8)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	return traceID, spanID, flags&traceParentSampled != 0, nil
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9') && !('a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRandomTraceID returns a random 128bit trace ID, never zero so that it is valid in the W3C
+// Trace Context.
+func NewRandomTraceID() TraceID {
+	var b [16]byte
+	for {
+		randomBytes(b[:])
+		if traceID, _ := TraceIDFromBytes(b[:]); traceID != (TraceID{}) {
+			return traceID
+		}
+	}
+}
+
+// NewRandomSpanID returns a random span ID, never zero so that it is valid in the W3C Trace Context.
+func NewRandomSpanID() SpanID {
+	var b [8]byte
+	for {
+		randomBytes(b[:])
+		if spanID := SpanID(binary.BigEndian.Uint64(b[:])); spanID != 0 {
+			return spanID
+		}
+	}
+}
+
+func randomBytes(b []byte) {
+	// crypto/rand.Read only fails if the randomness of the OS is unavailable, which is unrecoverable
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("cannot generate a random ID: %v", err))
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestFormatTraceParent(t *testing.T) {
+	traceID := model.NewTraceID(0x4bf92f3577b34da6, 0xa3ce929d0e0e4736)
+	spanID := model.NewSpanID(0x00f067aa0ba902b7)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", model.FormatTraceParent(traceID, spanID, true))
+	assert.Equal(t, "00-0000000000000000000000000000002a-0000000000000001-00",
+		model.FormatTraceParent(model.NewTraceID(0, 42), model.NewSpanID(1), false))
+}
+
+func TestParseTraceParent(t *testing.T) {
+	traceID, spanID, sampled, err := model.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, model.NewTraceID(0x4bf92f3577b34da6, 0xa3ce929d0e0e4736), traceID)
+	assert.Equal(t, model.NewSpanID(0x00f067aa0ba902b7), spanID)
+	assert.True(t, sampled)
+
+	traceID, _, sampled, err = model.ParseTraceParent("00-0000000000000000000000000000002a-0000000000000001-02")
+	require.NoError(t, err)
+	assert.Equal(t, model.NewTraceID(0, 42), traceID)
+	assert.False(t, sampled)
+
+	// the headers of the future versions may have more fields
+	_, _, sampled, err = model.ParseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds")
+	require.NoError(t, err)
+	assert.True(t, sampled)
+
+	for header, reason := range map[string]string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7":       "must be version-traceid-parentid-flags",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":    "the version must be 2 lower case hexadecimal characters other than ff",
+		"0-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":     "the version must be 2 lower case hexadecimal characters other than ff",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-00": "the version 00 must have exactly 4 fields",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":    "the trace ID must be 32 lower case hexadecimal characters",
+		"01-a3ce929d0e0e4736-00f067aa0ba902b7-01":                    "the trace ID must be 32 lower case hexadecimal characters",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-f067aa0ba902b7-01":      "the parent ID must be 16 lower case hexadecimal characters",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1":     "the flags must be 2 lower case hexadecimal characters",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":    "the trace ID cannot be zero",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":    "the parent ID cannot be zero",
+	} {
+		_, _, _, err := model.ParseTraceParent(header)
+		require.EqualError(t, err, `invalid traceparent "`+header+`": `+reason)
+	}
+}
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	traceID, spanID := model.NewRandomTraceID(), model.NewRandomSpanID()
+	parsedTraceID, parsedSpanID, sampled, err := model.ParseTraceParent(model.FormatTraceParent(traceID, spanID, true))
+	require.NoError(t, err)
+	assert.Equal(t, traceID, parsedTraceID)
+	assert.Equal(t, spanID, parsedSpanID)
+	assert.True(t, sampled)
+}
+
+func TestNewRandomIDs(t *testing.T) {
+	traceIDs := make(map[model.TraceID]struct{})
+	spanIDs := make(map[model.SpanID]struct{})
+	for i := 0; i < 100; i++ {
+		traceIDs[model.NewRandomTraceID()] = struct{}{}
+		spanIDs[model.NewRandomSpanID()] = struct{}{}
+	}
+	assert.Len(t, traceIDs, 100)
+	assert.Len(t, spanIDs, 100)
+	assert.NotContains(t, traceIDs, model.TraceID{})
+	assert.NotContains(t, spanIDs, model.SpanID(0))
+}
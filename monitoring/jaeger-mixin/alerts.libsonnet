@@ -99,7 +99,7 @@ local percentErrsWithTotal(metric_errs, metric_total) = '100 * sum(rate(%(metric
           },
         }, {
           alert: 'JaegerQueryReqsFailing',
-          expr: percentErrs('jaeger_query_requests_total', 'result="err"') + '> 1',
+          expr: percentErrsWithTotal('jaeger_storage_requests_total{operation!~"write_.*",result="err"}', 'jaeger_storage_requests_total{operation!~"write_.*"}') + '> 1',
           'for': '15m',
           labels: {
             severity: 'warning',
@@ -89,12 +89,12 @@ local g = (import 'grafana-builder/grafana.libsonnet') + {
         g.row('Query')
         .addPanel(
           g.panel('qps') +
-          g.qpsPanelErrTotal('jaeger_query_requests_total{result="err"}', 'jaeger_query_requests_total') +
+          g.qpsPanelErrTotal('jaeger_storage_requests_total{operation!~"write_.*",result="err"}', 'jaeger_storage_requests_total{operation!~"write_.*"}') +
           g.stack
         )
         .addPanel(
           g.panel('latency - 99 percentile') +
-          g.queryPanel('histogram_quantile(0.99, sum(rate(jaeger_query_latency_bucket[1m])) by (le, instance))', '{{instance}}') +
+          g.queryPanel('histogram_quantile(0.99, sum(rate(jaeger_storage_latency_bucket{operation!~"write_.*"}[1m])) by (le, instance))', '{{instance}}') +
           g.stack
         )
       ),
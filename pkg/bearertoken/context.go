@@ -23,6 +23,10 @@ const contextKey = contextKeyType(iota)
 // StoragePropagationKey is a key for viper configuration to pass this option to storage plugins.
 const StoragePropagationKey = "storage.propagate.token"
 
+// PropagatedTokenKey is the name of the gRPC metadata entry and of the Cassandra
+// custom payload entry carrying the bearer token propagated to the storage backends.
+const PropagatedTokenKey = "bearer.token"
+
 // ContextWithBearerToken set bearer token in context.
 func ContextWithBearerToken(ctx context.Context, token string) context.Context {
 	if token == "" {
@@ -0,0 +1,63 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package bearertoken
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type tokenServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tokenServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// contextWithTokenFromMetadata attaches to the context the bearer token of the incoming
+// metadata, read from the PropagatedTokenKey entry set by the clients of the gRPC storage,
+// or else from the Authorization header.
+func contextWithTokenFromMetadata(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if values := md.Get(PropagatedTokenKey); len(values) > 0 {
+		return ContextWithBearerToken(ctx, values[0])
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		headerValue := strings.Split(values[0], " ")
+		switch {
+		case len(headerValue) == 2 && headerValue[0] == "Bearer":
+			return ContextWithBearerToken(ctx, headerValue[1])
+		case len(headerValue) == 1:
+			return ContextWithBearerToken(ctx, values[0])
+		}
+	}
+	return ctx
+}
+
+// NewUnaryServerInterceptor creates a gRPC interceptor attaching the bearer token of the
+// incoming unary calls to their context, where GetBearerToken retrieves it.
+func NewUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(contextWithTokenFromMetadata(ctx), req)
+	}
+}
+
+// NewStreamServerInterceptor creates a gRPC interceptor attaching the bearer token of the
+// incoming streaming calls to their context, where GetBearerToken retrieves it.
+func NewStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &tokenServerStream{
+			ServerStream: ss,
+			ctx:          contextWithTokenFromMetadata(ss.Context()),
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package bearertoken
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestContextWithTokenFromMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		md    metadata.MD
+		token string
+	}{
+		{name: "propagated token", md: metadata.Pairs(PropagatedTokenKey, "blah", "authorization", "Bearer other"), token: "blah"},
+		{name: "bearer authorization", md: metadata.Pairs("authorization", "Bearer blah"), token: "blah"},
+		{name: "raw authorization", md: metadata.Pairs("authorization", "blah"), token: "blah"},
+		{name: "basic authorization", md: metadata.Pairs("authorization", "Basic dXNlcjpwYXNz")},
+		{name: "no token", md: metadata.Pairs("x-tenant", "acme")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := contextWithTokenFromMetadata(metadata.NewIncomingContext(context.Background(), test.md))
+			token, ok := GetBearerToken(ctx)
+			assert.Equal(t, test.token, token)
+			assert.Equal(t, test.token != "", ok)
+		})
+	}
+
+	_, ok := GetBearerToken(contextWithTokenFromMetadata(context.Background()))
+	assert.False(t, ok)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PropagatedTokenKey, "blah"))
+	_, err := NewUnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		token, _ := GetBearerToken(ctx)
+		assert.Equal(t, "blah", token)
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	stream := &fakeServerStream{
+		ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer blah")),
+	}
+	err := NewStreamServerInterceptor()(nil, stream, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+		token, _ := GetBearerToken(ss.Context())
+		assert.Equal(t, "blah", token)
+		return nil
+	})
+	require.NoError(t, err)
+}
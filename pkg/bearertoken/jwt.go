@@ -0,0 +1,35 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package bearertoken
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errNotJWT = errors.New("the bearer token is not a JWT")
+
+// ParseClaims returns the claims of the payload of a JWT, with or without the "Bearer "
+// prefix of the Authorization header. The signature of the token is not verified, so the
+// claims must not authorize anything unless the token was verified by a trusted proxy,
+// see ClaimsVerifier otherwise.
+func ParseClaims(token string) (map[string]any, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errNotJWT
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the JWT payload: %w", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode the JWT payload: %w", err)
+	}
+	return claims, nil
+}
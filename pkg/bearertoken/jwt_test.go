@@ -0,0 +1,39 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package bearertoken
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice","groups":["dev","ops"]}`))
+	token := "eyJhbGciOiJIUzI1NiJ9." + payload + ".signature"
+
+	for _, value := range []string{token, "Bearer " + token} {
+		claims, err := ParseClaims(value)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"sub": "alice", "groups": []any{"dev", "ops"}}, claims)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		err   string
+	}{
+		{name: "opaque token", token: "opaque", err: "the bearer token is not a JWT"},
+		{name: "invalid base64", token: "a.!!!.c", err: "failed to decode the JWT payload"},
+		{name: "invalid JSON", token: "a." + base64.RawURLEncoding.EncodeToString([]byte("[1]")) + ".c", err: "failed to decode the JWT payload"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseClaims(test.token)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package bearertoken
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksRefreshInterval is the minimum interval between two fetches of the JWKS, which is
+	// fetched again when a token is signed by an unknown key, e.g. after a key rotation.
+	jwksRefreshInterval = time.Minute
+	jwksFetchTimeout    = 10 * time.Second
+	maxJWKSSize         = 1 << 20
+)
+
+// signingMethods are the asymmetric algorithms of the tokens verified, the keys of the issuers
+// being public. The symmetric ones and "none" are rejected.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// VerifierOptions configures the verification of the JWTs by a ClaimsVerifier.
+type VerifierOptions struct {
+	// JWKSURL is the URL of the JSON Web Key Set of the issuer of the tokens.
+	JWKSURL string
+	// KeyFile is the path to the PEM public key or certificate of the issuer, if JWKSURL is empty.
+	KeyFile string
+	// Issuer and Audience are the iss and aud claims the tokens must have, if not empty.
+	Issuer   string
+	Audience string
+}
+
+// Enabled returns whether the options configure the keys of the issuer.
+func (o VerifierOptions) Enabled() bool {
+	return o.JWKSURL != "" || o.KeyFile != ""
+}
+
+// ClaimsVerifier returns the claims of the JWTs signed by the keys of their issuer
+// and valid for the time being.
+type ClaimsVerifier struct {
+	parser *jwt.Parser
+	key    jwt.Keyfunc
+}
+
+// NewClaimsVerifier creates a ClaimsVerifier with the keys of the JWKS URL, fetched by the client,
+// or of the key file of the options.
+func NewClaimsVerifier(opts VerifierOptions, client *http.Client) (*ClaimsVerifier, error) {
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(signingMethods)}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	v := &ClaimsVerifier{parser: jwt.NewParser(parserOpts...)}
+	switch {
+	case opts.JWKSURL != "" && opts.KeyFile != "":
+		return nil, errors.New("the JWKS URL and the key file of the JWTs are mutually exclusive")
+	case opts.JWKSURL != "":
+		keys := &jwks{url: opts.JWKSURL, client: client, now: time.Now}
+		if err := keys.fetch(); err != nil {
+			return nil, err
+		}
+		v.key = keys.key
+	case opts.KeyFile != "":
+		key, err := loadPublicKey(opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.key = func(*jwt.Token) (any, error) { return key, nil }
+	default:
+		return nil, errors.New("either the JWKS URL or the key file of the JWTs is required")
+	}
+	return v, nil
+}
+
+// VerifyClaims returns the claims of a JWT, with or without the "Bearer " prefix of the Authorization
+// header, if it is signed by the issuer and neither expired nor used before its nbf claim.
+func (v *ClaimsVerifier) VerifyClaims(token string) (map[string]any, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(strings.TrimPrefix(token, "Bearer "), claims, v.key); err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	return claims, nil
+}
+
+func loadPublicKey(filename string) (any, error) {
+	data, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the JWT key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("the JWT key file %s is not PEM encoded", filename)
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the JWT public key: %w", err)
+		}
+		return key, nil
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the JWT public key: %w", err)
+		}
+		return key, nil
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the JWT certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in the JWT key file, a public key or a certificate is expected", block.Type)
+	}
+}
+
+// jwks holds the public keys of a JSON Web Key Set by key ID.
+type jwks struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+}
+
+// key returns the key of the kid header of the token, fetching the keys again if it is unknown.
+func (s *jwks) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	if s.now().Sub(s.fetched) >= jwksRefreshInterval {
+		if err := s.fetchLocked(); err != nil {
+			return nil, err
+		}
+		if key, ok := s.lookup(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown JWT key %q", kid)
+}
+
+// lookup returns the key of the kid, or the only key of the set if the token has no kid.
+func (s *jwks) lookup(kid string) (any, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *jwks) fetch() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetchLocked()
+}
+
+func (s *jwks) fetchLocked() error {
+	s.fetched = s.now()
+	client := s.client
+	if client == nil {
+		client = &http.Client{Timeout: jwksFetchTimeout}
+	}
+	resp, err := client.Get(s.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch the JWKS: %s", resp.Status)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, maxJWKSSize)).Decode(&set); err != nil {
+		return fmt.Errorf("failed to parse the JWKS: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return fmt.Errorf("invalid key %q of the JWKS: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
+
+// jsonWebKey is a public key of a JWKS, see RFC 7517.
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	// N and E are the modulus and exponent of the RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// X and Y are the coordinates of the EC keys, X being the Ed25519 key of the OKP keys.
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("the RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("the EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
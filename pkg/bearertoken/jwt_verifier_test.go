@@ -0,0 +1,265 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package bearertoken
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func writeKeyFile(t *testing.T, blockType string, der []byte) string {
+	filename := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return filename
+}
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestClaimsVerifierKeyFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	verifier, err := NewClaimsVerifier(VerifierOptions{KeyFile: writeKeyFile(t, "PUBLIC KEY", der), Issuer: "https://idp"}, nil)
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "alice", "iss": "https://idp", "exp": float64(time.Now().Add(time.Hour).Unix())}
+	token := signToken(t, jwt.SigningMethodRS256, key, "", claims)
+	for _, value := range []string{token, "Bearer " + token} {
+		verified, err := verifier.VerifyClaims(value)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any(claims), verified)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	payload, err := json.Marshal(map[string]any{"sub": "mallory", "iss": "https://idp"})
+	require.NoError(t, err)
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "forged claims", token: forgeClaims(token, payload)},
+		{name: "unsigned", token: signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", jwt.MapClaims{"sub": "mallory", "iss": "https://idp"})},
+		{name: "signed by another key", token: signToken(t, jwt.SigningMethodRS256, otherKey, "", jwt.MapClaims{"sub": "mallory", "iss": "https://idp"})},
+		{name: "symmetric with the public key", token: signToken(t, jwt.SigningMethodHS256, der, "", jwt.MapClaims{"sub": "mallory", "iss": "https://idp"})},
+		{name: "expired", token: signToken(t, jwt.SigningMethodRS256, key, "", jwt.MapClaims{"sub": "alice", "iss": "https://idp", "exp": float64(time.Now().Add(-time.Hour).Unix())})},
+		{name: "other issuer", token: signToken(t, jwt.SigningMethodRS256, key, "", jwt.MapClaims{"sub": "alice", "iss": "https://other"})},
+		{name: "truncated signature", token: token[:len(token)-8]},
+		{name: "opaque", token: "opaque"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := verifier.VerifyClaims(test.token)
+			require.ErrorContains(t, err, "invalid JWT")
+		})
+	}
+}
+
+// forgeClaims replaces the payload of the token, keeping its header and signature.
+func forgeClaims(token string, payload []byte) string {
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+}
+
+func TestClaimsVerifierKeyFileTypes(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+	require.NoError(t, err)
+
+	verifier, err := NewClaimsVerifier(VerifierOptions{KeyFile: writeKeyFile(t, "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))}, nil)
+	require.NoError(t, err)
+	_, err = verifier.VerifyClaims(signToken(t, jwt.SigningMethodPS256, rsaKey, "", jwt.MapClaims{"sub": "alice"}))
+	require.NoError(t, err)
+
+	verifier, err = NewClaimsVerifier(VerifierOptions{KeyFile: writeKeyFile(t, "CERTIFICATE", cert), Audience: "jaeger"}, nil)
+	require.NoError(t, err)
+	_, err = verifier.VerifyClaims(signToken(t, jwt.SigningMethodES256, ecKey, "", jwt.MapClaims{"sub": "alice", "aud": "jaeger"}))
+	require.NoError(t, err)
+	_, err = verifier.VerifyClaims(signToken(t, jwt.SigningMethodES256, ecKey, "", jwt.MapClaims{"sub": "alice", "aud": "other"}))
+	require.ErrorContains(t, err, "invalid JWT")
+}
+
+func TestNewClaimsVerifierErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts VerifierOptions
+		err  string
+	}{
+		{name: "no keys", opts: VerifierOptions{}, err: "either the JWKS URL or the key file of the JWTs is required"},
+		{name: "both keys", opts: VerifierOptions{JWKSURL: "http://idp", KeyFile: "key.pem"}, err: "mutually exclusive"},
+		{name: "missing key file", opts: VerifierOptions{KeyFile: filepath.Join(t.TempDir(), "missing.pem")}, err: "failed to read the JWT key file"},
+		{name: "not PEM", opts: VerifierOptions{KeyFile: writeRaw(t, "key")}, err: "is not PEM encoded"},
+		{name: "private key", opts: VerifierOptions{KeyFile: writeKeyFile(t, "PRIVATE KEY", []byte("key"))}, err: `unsupported PEM block "PRIVATE KEY"`},
+		{name: "invalid public key", opts: VerifierOptions{KeyFile: writeKeyFile(t, "PUBLIC KEY", []byte("key"))}, err: "failed to parse the JWT public key"},
+		{name: "invalid RSA public key", opts: VerifierOptions{KeyFile: writeKeyFile(t, "RSA PUBLIC KEY", []byte("key"))}, err: "failed to parse the JWT public key"},
+		{name: "invalid certificate", opts: VerifierOptions{KeyFile: writeKeyFile(t, "CERTIFICATE", []byte("key"))}, err: "failed to parse the JWT certificate"},
+		{name: "unreachable JWKS", opts: VerifierOptions{JWKSURL: "http://127.0.0.1:0/jwks"}, err: "failed to fetch the JWKS"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.opts.JWKSURL != "" || test.opts.KeyFile != "", test.opts.Enabled())
+			_, err := NewClaimsVerifier(test.opts, nil)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func writeRaw(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "raw")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	return filename
+}
+
+func TestClaimsVerifierJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys := []map[string]string{
+		{"kid": "rsa", "kty": "RSA", "use": "sig", "n": encodeInt(rsaKey.N), "e": encodeInt(big.NewInt(int64(rsaKey.E)))},
+		{"kid": "ec", "kty": "EC", "crv": "P-384", "x": encodeInt(ecKey.X), "y": encodeInt(ecKey.Y)},
+		{"kid": "ed", "kty": "OKP", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(edPublic)},
+		{"kid": "enc", "kty": "RSA", "use": "enc", "n": "invalid"},
+	}
+	var rotated atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		set := keys
+		if rotated.Load() {
+			set = append(set, map[string]string{"kid": "rotated", "kty": "RSA", "n": encodeInt(rotatedKey.N), "e": encodeInt(big.NewInt(int64(rotatedKey.E)))})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": set})
+	}))
+	defer server.Close()
+
+	verifier, err := NewClaimsVerifier(VerifierOptions{JWKSURL: server.URL}, server.Client())
+	require.NoError(t, err)
+	for kid, token := range map[string]string{
+		"rsa": signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", jwt.MapClaims{"sub": "alice"}),
+		"ec":  signToken(t, jwt.SigningMethodES384, ecKey, "ec", jwt.MapClaims{"sub": "alice"}),
+		"ed":  signToken(t, jwt.SigningMethodEdDSA, edKey, "ed", jwt.MapClaims{"sub": "alice"}),
+	} {
+		claims, err := verifier.VerifyClaims(token)
+		require.NoError(t, err, kid)
+		assert.Equal(t, "alice", claims["sub"])
+	}
+	// a key of a kid is not used for the tokens of another kid
+	_, err = verifier.VerifyClaims(signToken(t, jwt.SigningMethodRS256, rsaKey, "ec", jwt.MapClaims{"sub": "alice"}))
+	require.ErrorContains(t, err, "invalid JWT")
+	// the JWKS is not fetched again for the unknown keys within the refresh interval
+	rotatedToken := signToken(t, jwt.SigningMethodRS256, rotatedKey, "rotated", jwt.MapClaims{"sub": "alice"})
+	_, err = verifier.VerifyClaims(rotatedToken)
+	require.ErrorContains(t, err, `unknown JWT key "rotated"`)
+	assert.EqualValues(t, 1, fetches.Load())
+
+	rotated.Store(true)
+	verifier, err = NewClaimsVerifier(VerifierOptions{JWKSURL: server.URL}, server.Client())
+	require.NoError(t, err)
+	_, err = verifier.VerifyClaims(rotatedToken)
+	require.NoError(t, err)
+}
+
+func TestJWKSRefresh(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var published atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		set := []map[string]string{}
+		if published.Load() {
+			set = append(set, map[string]string{"kid": "new", "kty": "RSA", "n": encodeInt(key.N), "e": encodeInt(big.NewInt(int64(key.E)))})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": set})
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keys := &jwks{url: server.URL, client: server.Client(), now: func() time.Time { return now }}
+	require.NoError(t, keys.fetch())
+	token, err := jwt.Parse(signToken(t, jwt.SigningMethodRS256, key, "new", jwt.MapClaims{}), nil)
+	require.Error(t, err)
+
+	published.Store(true)
+	_, err = keys.key(token)
+	require.ErrorContains(t, err, `unknown JWT key "new"`)
+	now = now.Add(jwksRefreshInterval)
+	found, err := keys.key(token)
+	require.NoError(t, err)
+	assert.Equal(t, &key.PublicKey, found)
+}
+
+func TestJWKSErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		err    string
+	}{
+		{name: "status", status: http.StatusInternalServerError, err: "failed to fetch the JWKS: 500"},
+		{name: "invalid JSON", status: http.StatusOK, body: `{`, err: "failed to parse the JWKS"},
+		{name: "unsupported key type", status: http.StatusOK, body: `{"keys": [{"kid": "k", "kty": "oct"}]}`, err: `invalid key "k" of the JWKS: unsupported key type "oct"`},
+		{name: "invalid modulus", status: http.StatusOK, body: `{"keys": [{"kty": "RSA", "n": "!", "e": "AQAB"}]}`, err: "invalid base64url integer"},
+		{name: "invalid exponent", status: http.StatusOK, body: `{"keys": [{"kty": "RSA", "n": "AQAB", "e": ""}]}`, err: "invalid base64url integer"},
+		{name: "large exponent", status: http.StatusOK, body: `{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAAAAAAAAAAAQ"}]}`, err: "the RSA exponent is too large"},
+		{name: "unsupported curve", status: http.StatusOK, body: `{"keys": [{"kty": "EC", "crv": "P-192"}]}`, err: `unsupported curve "P-192"`},
+		{name: "invalid x", status: http.StatusOK, body: `{"keys": [{"kty": "EC", "crv": "P-256", "x": "", "y": "AQ"}]}`, err: "invalid base64url integer"},
+		{name: "invalid y", status: http.StatusOK, body: `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": ""}]}`, err: "invalid base64url integer"},
+		{name: "point not on curve", status: http.StatusOK, body: `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`, err: "the EC point is not on the curve"},
+		{name: "unsupported OKP curve", status: http.StatusOK, body: `{"keys": [{"kty": "OKP", "crv": "X25519"}]}`, err: `unsupported curve "X25519"`},
+		{name: "invalid Ed25519 key", status: http.StatusOK, body: `{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "AQ"}]}`, err: "invalid Ed25519 key"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			_, err := NewClaimsVerifier(VerifierOptions{JWKSURL: server.URL}, nil)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -46,6 +46,10 @@ type Configuration struct {
 	Authenticator        Authenticator  `mapstructure:",squash"`
 	DisableAutoDiscovery bool           `mapstructure:"-"`
 	TLS                  tlscfg.Options `mapstructure:"tls"`
+	// AllowTokenFromContext attaches the bearer token of the queries to their custom payload
+	AllowTokenFromContext bool `mapstructure:"-"`
+	// ReadConcurrency is the number of traces found by a query that are read at the same time
+	ReadConcurrency int `mapstructure:"read_concurrency"`
 }
 
 func DefaultConfiguration() Configuration {
@@ -57,6 +61,7 @@ func DefaultConfiguration() Configuration {
 		ProtoVersion:       4,
 		ConnectionsPerHost: 2,
 		ReconnectInterval:  60 * time.Second,
+		ReadConcurrency:    10,
 	}
 }
 
@@ -99,6 +104,9 @@ func (c *Configuration) ApplyDefaults(source *Configuration) {
 	if c.SocketKeepAlive == 0 {
 		c.SocketKeepAlive = source.SocketKeepAlive
 	}
+	if c.ReadConcurrency == 0 {
+		c.ReadConcurrency = source.ReadConcurrency
+	}
 }
 
 // SessionBuilder creates new cassandra.Session
@@ -16,6 +16,8 @@
 package gocql
 
 import (
+	"context"
+
 	"github.com/gocql/gocql"
 
 	"github.com/jaegertracing/jaeger/pkg/cassandra"
@@ -88,6 +90,16 @@ func (q CQLQuery) PageSize(n int) cassandra.Query {
 	return WrapCQLQuery(q.query.PageSize(n))
 }
 
+// CustomPayload delegates to gocql.Query#CustomPayload and wraps the result as Query.
+func (q CQLQuery) CustomPayload(payload map[string][]byte) cassandra.Query {
+	return WrapCQLQuery(q.query.CustomPayload(payload))
+}
+
+// WithContext delegates to gocql.Query#WithContext and wraps the result as Query.
+func (q CQLQuery) WithContext(ctx context.Context) cassandra.Query {
+	return WrapCQLQuery(q.query.WithContext(ctx))
+}
+
 // ---
 
 // CQLIterator is a wrapper around gocql.Iter.
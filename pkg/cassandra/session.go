@@ -15,6 +15,8 @@
 
 package cassandra
 
+import "context"
+
 // Consistency is Cassandra's consistency level for queries.
 type Consistency uint16
 
@@ -64,6 +66,11 @@ type Query interface {
 	Bind(v ...any) Query
 	Consistency(level Consistency) Query
 	PageSize(int) Query
+	// CustomPayload sets the custom payload of the query, which the Cassandra nodes
+	// pass to their custom query handlers.
+	CustomPayload(payload map[string][]byte) Query
+	// WithContext binds the query to the context, the query being cancelled when the context is done.
+	WithContext(ctx context.Context) Query
 }
 
 // Iterator is an abstraction of gocql.Iter
@@ -0,0 +1,286 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outlierdetection implements a round robin gRPC load balancer ejecting the endpoints
+// with elevated error rates, so that the requests are not sent to a failing collector until
+// its connection breaks.
+//
+// The endpoints are the ready connections, the connections reported not serving by the gRPC
+// health checks being excluded too when a healthCheckConfig is set in the service config.
+// Every Interval, the endpoints which failed at least FailureRateThreshold of at least
+// MinimumRequests requests are ejected for EjectionTime, multiplied by the number of their
+// consecutive ejections, up to MaxEjectionPercent of the endpoints. The requests are sent to
+// the ejected endpoints if all the endpoints are ejected.
+package outlierdetection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	_ "google.golang.org/grpc/health" // registers the client side health checks
+	"google.golang.org/grpc/serviceconfig"
+	"google.golang.org/grpc/status"
+)
+
+// Name is the name of the load balancing policy in the gRPC service config.
+const Name = "jaeger_outlier_detection_round_robin"
+
+var logger = grpclog.Component("outlier-detection")
+
+func init() {
+	balancer.Register(builder{})
+}
+
+// Config is the load balancing config of the policy.
+type Config struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	// FailureRateThreshold is the ratio of failed requests over which an endpoint is ejected
+	FailureRateThreshold float64 `json:"failureRateThreshold"`
+	// MinimumRequests is the number of requests of an endpoint during an interval under which it is not ejected
+	MinimumRequests int `json:"minimumRequests"`
+	// Interval is how often the failure rates of the endpoints are evaluated
+	Interval time.Duration `json:"interval"`
+	// EjectionTime is how long an endpoint is ejected the first time
+	EjectionTime time.Duration `json:"ejectionTime"`
+	// MaxEjectionPercent is the maximum percentage of the endpoints ejected at the same time
+	MaxEjectionPercent int `json:"maxEjectionPercent"`
+}
+
+func (c *Config) validate() error {
+	switch {
+	case c.FailureRateThreshold <= 0 || c.FailureRateThreshold > 1:
+		return fmt.Errorf("the failure rate threshold must be in (0, 1], got %v", c.FailureRateThreshold)
+	case c.MinimumRequests < 1:
+		return fmt.Errorf("the minimum number of requests must be positive, got %d", c.MinimumRequests)
+	case c.Interval <= 0:
+		return fmt.Errorf("the interval must be positive, got %v", c.Interval)
+	case c.EjectionTime <= 0:
+		return fmt.Errorf("the ejection time must be positive, got %v", c.EjectionTime)
+	case c.MaxEjectionPercent < 0 || c.MaxEjectionPercent > 100:
+		return fmt.Errorf("the maximum ejection percent must be in [0, 100], got %d", c.MaxEjectionPercent)
+	}
+	return nil
+}
+
+// ServiceConfig returns the gRPC service config using the policy with config, and the gRPC health
+// checks of healthCheckService if it is not empty.
+func ServiceConfig(config Config, healthCheckService string) (string, error) {
+	if err := config.validate(); err != nil {
+		return "", err
+	}
+	serviceConfig := map[string]any{
+		"loadBalancingConfig": []map[string]any{{Name: config}},
+	}
+	if healthCheckService != "" {
+		serviceConfig["healthCheckConfig"] = map[string]string{"serviceName": healthCheckService}
+	}
+	js, err := json.Marshal(serviceConfig)
+	return string(js), err
+}
+
+type builder struct{}
+
+func (builder) Name() string {
+	return Name
+}
+
+func (builder) ParseConfig(js json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	config := &Config{}
+	if err := json.Unmarshal(js, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the outlier detection config: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	b := &outlierBalancer{detector: newDetector()}
+	b.Balancer = base.NewBalancerBuilder(Name, b, base.Config{HealthCheck: true}).Build(cc, opts)
+	return b
+}
+
+type outlierBalancer struct {
+	// Embeds balancer.Balancer to intercept UpdateClientConnState and learn about the config.
+	balancer.Balancer
+
+	detector *detector
+}
+
+func (b *outlierBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	config, ok := s.BalancerConfig.(*Config)
+	if !ok {
+		logger.Errorf("received config with unexpected type %T: %v", s.BalancerConfig, s.BalancerConfig)
+		return balancer.ErrBadResolverState
+	}
+	b.detector.setConfig(*config)
+	return b.Balancer.UpdateClientConnState(s)
+}
+
+// ExitIdle implements balancer.ExitIdler, hidden by the embedded interface.
+func (b *outlierBalancer) ExitIdle() {
+	if exitIdler, ok := b.Balancer.(balancer.ExitIdler); ok {
+		exitIdler.ExitIdle()
+	}
+}
+
+// Build implements base.PickerBuilder.
+func (b *outlierBalancer) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{detector: b.detector}
+	for subConn, subConnInfo := range info.ReadySCs {
+		p.subConns = append(p.subConns, subConn)
+		p.addresses = append(p.addresses, subConnInfo.Address.Addr)
+	}
+	return p
+}
+
+type picker struct {
+	detector  *detector
+	subConns  []balancer.SubConn
+	addresses []string
+	next      atomic.Uint32
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	i := p.detector.pick(p.addresses, int(p.next.Add(1)))
+	address := p.addresses[i]
+	return balancer.PickResult{
+		SubConn: p.subConns[i],
+		Done: func(info balancer.DoneInfo) {
+			p.detector.record(address, info.Err)
+		},
+	}, nil
+}
+
+type endpoint struct {
+	requests  int
+	failures  int
+	ejections int
+	// ejectedUntil is zero if the endpoint is not ejected
+	ejectedUntil time.Time
+}
+
+type detector struct {
+	now func() time.Time
+
+	mu             sync.Mutex
+	config         Config
+	lastEvaluation time.Time
+	endpoints      map[string]*endpoint
+}
+
+func newDetector() *detector {
+	return &detector{
+		now:       time.Now,
+		endpoints: make(map[string]*endpoint),
+	}
+}
+
+func (d *detector) setConfig(config Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.config = config
+}
+
+// pick returns the index of the first address not ejected from start, round robin,
+// or of the address at start if all the addresses are ejected.
+func (d *detector) pick(addresses []string, start int) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	if now.Sub(d.lastEvaluation) >= d.config.Interval {
+		d.evaluate(addresses, now)
+	}
+	for n := 0; n < len(addresses); n++ {
+		i := (start + n) % len(addresses)
+		if e, ok := d.endpoints[addresses[i]]; !ok || !now.Before(e.ejectedUntil) {
+			return i
+		}
+	}
+	return start % len(addresses)
+}
+
+func (d *detector) evaluate(addresses []string, now time.Time) {
+	d.lastEvaluation = now
+	ejected := 0
+	current := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		current[address] = struct{}{}
+		if e, ok := d.endpoints[address]; ok && now.Before(e.ejectedUntil) {
+			ejected++
+		}
+	}
+	// forget the endpoints removed by the resolver
+	for address := range d.endpoints {
+		if _, ok := current[address]; !ok {
+			delete(d.endpoints, address)
+		}
+	}
+	for _, address := range addresses {
+		e, ok := d.endpoints[address]
+		if !ok {
+			continue
+		}
+		failing := e.requests >= d.config.MinimumRequests &&
+			float64(e.failures) >= d.config.FailureRateThreshold*float64(e.requests)
+		switch {
+		case now.Before(e.ejectedUntil):
+			// the requests sent while all the endpoints are ejected do not extend the ejection
+		case failing && (ejected+1)*100 <= d.config.MaxEjectionPercent*len(addresses):
+			e.ejections++
+			e.ejectedUntil = now.Add(d.config.EjectionTime * time.Duration(e.ejections))
+			ejected++
+			logger.Warningf("ejecting %s for %v after %d failures of %d requests", address, e.ejectedUntil.Sub(now), e.failures, e.requests)
+		case !failing && e.requests >= d.config.MinimumRequests && e.ejections > 0:
+			// an endpoint is forgiven an ejection for every interval it served enough requests
+			e.ejections--
+		}
+		e.requests, e.failures = 0, 0
+	}
+}
+
+func (d *detector) record(address string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.endpoints[address]
+	if !ok {
+		e = &endpoint{}
+		d.endpoints[address] = e
+	}
+	e.requests++
+	if isFailure(err) {
+		e.failures++
+	}
+}
+
+// isFailure returns whether the error is caused by the endpoint, the requests canceled or
+// rejected because of their content not being failures.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package outlierdetection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+)
+
+var testConfig = Config{
+	FailureRateThreshold: 0.5,
+	MinimumRequests:      4,
+	Interval:             time.Second,
+	EjectionTime:         10 * time.Second,
+	MaxEjectionPercent:   50,
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(*Config)
+		err    string
+	}{
+		{name: "valid", update: func(*Config) {}},
+		{name: "failure rate", update: func(c *Config) { c.FailureRateThreshold = 1.5 }, err: "the failure rate threshold must be in (0, 1], got 1.5"},
+		{name: "minimum requests", update: func(c *Config) { c.MinimumRequests = 0 }, err: "the minimum number of requests must be positive, got 0"},
+		{name: "interval", update: func(c *Config) { c.Interval = 0 }, err: "the interval must be positive, got 0s"},
+		{name: "ejection time", update: func(c *Config) { c.EjectionTime = -time.Second }, err: "the ejection time must be positive, got -1s"},
+		{name: "max ejection percent", update: func(c *Config) { c.MaxEjectionPercent = 101 }, err: "the maximum ejection percent must be in [0, 100], got 101"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testConfig
+			test.update(&config)
+			_, err := ServiceConfig(config, "")
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestServiceConfig(t *testing.T) {
+	js, err := ServiceConfig(testConfig, "jaeger.api_v2.CollectorService")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"loadBalancingConfig": [{"jaeger_outlier_detection_round_robin": {
+			"failureRateThreshold": 0.5, "minimumRequests": 4, "interval": 1000000000,
+			"ejectionTime": 10000000000, "maxEjectionPercent": 50
+		}}],
+		"healthCheckConfig": {"serviceName": "jaeger.api_v2.CollectorService"}
+	}`, js)
+
+	js, err = ServiceConfig(testConfig, "")
+	require.NoError(t, err)
+	assert.NotContains(t, js, "healthCheckConfig")
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := builder{}.ParseConfig([]byte(`{"failureRateThreshold": 0.5, "minimumRequests": 4, "interval": 1000000000, "ejectionTime": 10000000000, "maxEjectionPercent": 50}`))
+	require.NoError(t, err)
+	assert.Equal(t, &testConfig, config)
+
+	_, err = builder{}.ParseConfig([]byte(`{"failureRateThreshold": 2}`))
+	require.ErrorContains(t, err, "the failure rate threshold must be in (0, 1]")
+
+	_, err = builder{}.ParseConfig([]byte(`[]`))
+	require.ErrorContains(t, err, "failed to unmarshal the outlier detection config")
+}
+
+func newTestDetector(now *time.Time) *detector {
+	d := newDetector()
+	d.now = func() time.Time { return *now }
+	d.setConfig(testConfig)
+	return d
+}
+
+func recordRequests(d *detector, address string, requests, failures int) {
+	for i := 0; i < requests; i++ {
+		var err error
+		if i < failures {
+			err = status.Error(codes.Unavailable, "unavailable")
+		}
+		d.record(address, err)
+	}
+}
+
+func TestDetectorEjection(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := newTestDetector(&now)
+	addresses := []string{"a", "b", "c", "d"}
+	assert.Equal(t, 1, d.pick(addresses, 1))
+
+	recordRequests(d, "a", 4, 2)
+	recordRequests(d, "b", 3, 3) // under the minimum number of requests
+	recordRequests(d, "c", 10, 1)
+	now = now.Add(time.Second)
+	// a is ejected, the next address is picked
+	assert.Equal(t, 1, d.pick(addresses, 0))
+	assert.Equal(t, 1, d.pick(addresses, 4))
+	assert.Equal(t, 2, d.pick(addresses, 2))
+
+	// a is back after the ejection time
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 0, d.pick(addresses, 0))
+
+	// the ejection time grows with the consecutive ejections
+	recordRequests(d, "a", 4, 4)
+	now = now.Add(time.Second)
+	assert.Equal(t, 1, d.pick(addresses, 0))
+	now = now.Add(19 * time.Second)
+	assert.Equal(t, 1, d.pick(addresses, 0))
+	now = now.Add(time.Second)
+	assert.Equal(t, 0, d.pick(addresses, 0))
+}
+
+func TestDetectorMaxEjectionPercent(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := newTestDetector(&now)
+	addresses := []string{"a", "b", "c", "d"}
+	for _, address := range addresses {
+		recordRequests(d, address, 4, 4)
+	}
+	now = now.Add(time.Second)
+	d.pick(addresses, 0)
+	ejected := 0
+	for _, e := range d.endpoints {
+		if now.Before(e.ejectedUntil) {
+			ejected++
+		}
+	}
+	assert.Equal(t, 2, ejected)
+}
+
+func TestDetectorAllEjected(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := newTestDetector(&now)
+	d.config.MaxEjectionPercent = 100
+	addresses := []string{"a", "b"}
+	recordRequests(d, "a", 4, 4)
+	recordRequests(d, "b", 4, 4)
+	now = now.Add(time.Second)
+	// the requests are sent to the ejected endpoints rather than failing
+	assert.Equal(t, 1, d.pick(addresses, 1))
+	assert.Equal(t, 0, d.pick(addresses, 2))
+}
+
+func TestIsFailure(t *testing.T) {
+	assert.False(t, isFailure(nil))
+	assert.False(t, isFailure(context.Canceled))
+	assert.False(t, isFailure(fmt.Errorf("wrapped: %w", context.Canceled)))
+	assert.False(t, isFailure(status.Error(codes.InvalidArgument, "invalid")))
+	assert.False(t, isFailure(status.Error(codes.PermissionDenied, "denied")))
+	assert.True(t, isFailure(status.Error(codes.Unavailable, "unavailable")))
+	assert.True(t, isFailure(status.Error(codes.DeadlineExceeded, "deadline")))
+	assert.True(t, isFailure(errors.New("broken")))
+}
+
+type testServer struct {
+	address  string
+	requests atomic.Int64
+	fail     atomic.Bool
+	server   *grpc.Server
+}
+
+func startTestServer(t *testing.T) *testServer {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	s := &testServer{address: listener.Addr().String()}
+	s.server = grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		s.requests.Add(1)
+		if s.fail.Load() {
+			return nil, status.Error(codes.Unavailable, "overloaded")
+		}
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(s.server, health.NewServer())
+	go s.server.Serve(listener)
+	t.Cleanup(s.server.Stop)
+	return s
+}
+
+func TestOutlierDetection(t *testing.T) {
+	healthy, failing := startTestServer(t), startTestServer(t)
+	failing.fail.Store(true)
+
+	config := testConfig
+	config.Interval = 10 * time.Millisecond
+	config.EjectionTime = time.Minute
+	serviceConfig, err := ServiceConfig(config, "")
+	require.NoError(t, err)
+	r := manual.NewBuilderWithScheme("outlier-detection-test")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: healthy.address}, {Addr: failing.address}}})
+	conn, err := grpc.NewClient(r.Scheme()+":///test",
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	assert.Eventually(t, func() bool {
+		failing.requests.Store(0)
+		for i := 0; i < 10; i++ {
+			client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		}
+		return failing.requests.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+	assert.Positive(t, healthy.requests.Load())
+}
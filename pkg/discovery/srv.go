@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SRVDiscoverer yields the host:port instances of the DNS SRV records of a name, e.g.
+// _grpc._tcp.jaeger-collector.observability.svc.cluster.local, and notifies its observers
+// when they change.
+type SRVDiscoverer struct {
+	Dispatcher
+
+	name      string
+	logger    *zap.Logger
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewSRVDiscoverer creates an SRVDiscoverer of the SRV records of name.
+func NewSRVDiscoverer(name string, logger *zap.Logger) *SRVDiscoverer {
+	return &SRVDiscoverer{
+		name:      name,
+		logger:    logger,
+		lookupSRV: net.DefaultResolver.LookupSRV,
+	}
+}
+
+// Instances implements Discoverer.
+func (d *SRVDiscoverer) Instances() ([]string, error) {
+	_, records, err := d.lookupSRV(context.Background(), "", "", d.name)
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		instances = append(instances, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+	}
+	slices.Sort(instances)
+	return instances, nil
+}
+
+// Watch looks the SRV records up every interval, and notifies the observers when the instances
+// change, until ctx is done. The previous instances are kept when the lookup fails.
+func (d *SRVDiscoverer) Watch(ctx context.Context, interval time.Duration) {
+	// the first lookup notifies the observers, which may have failed to look the records up
+	var instances []string
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			latest, err := d.Instances()
+			if err != nil {
+				d.logger.Warn("Failed to look up the SRV records, keeping the previous instances", zap.String("name", d.name), zap.Error(err))
+				continue
+			}
+			if !slices.Equal(latest, instances) {
+				instances = latest
+				d.Notify(instances)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSRVRecords struct {
+	mu      sync.Mutex
+	records []*net.SRV
+	err     error
+}
+
+func (f *fakeSRVRecords) set(err error, records ...*net.SRV) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records, f.err = records, err
+}
+
+func (f *fakeSRVRecords) lookupSRV(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return name, f.records, f.err
+}
+
+func newTestSRVDiscoverer(records *fakeSRVRecords) *SRVDiscoverer {
+	d := NewSRVDiscoverer("_grpc._tcp.collector.example.com", zap.NewNop())
+	d.lookupSRV = records.lookupSRV
+	return d
+}
+
+func TestSRVDiscovererInstances(t *testing.T) {
+	records := &fakeSRVRecords{}
+	records.set(nil,
+		&net.SRV{Target: "collector-2.example.com.", Port: 14250},
+		&net.SRV{Target: "collector-1.example.com.", Port: 14250},
+	)
+	instances, err := newTestSRVDiscoverer(records).Instances()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"collector-1.example.com:14250", "collector-2.example.com:14250"}, instances)
+
+	records.set(errors.New("no such host"))
+	_, err = newTestSRVDiscoverer(records).Instances()
+	require.EqualError(t, err, "no such host")
+}
+
+func TestSRVDiscovererWatch(t *testing.T) {
+	records := &fakeSRVRecords{}
+	records.set(nil, &net.SRV{Target: "collector-1.example.com.", Port: 14250})
+	d := newTestSRVDiscoverer(records)
+	ch := make(chan []string, 10)
+	d.Register(ch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.Watch(ctx, time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	select {
+	case instances := <-ch:
+		assert.Equal(t, []string{"collector-1.example.com:14250"}, instances)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the instances were not notified")
+	}
+
+	// the lookup failures keep the previous instances
+	records.set(errors.New("no such host"))
+	time.Sleep(10 * time.Millisecond)
+	records.set(nil,
+		&net.SRV{Target: "collector-1.example.com.", Port: 14250},
+		&net.SRV{Target: "collector-2.example.com.", Port: 14250},
+	)
+	select {
+	case instances := <-ch:
+		assert.Equal(t, []string{"collector-1.example.com:14250", "collector-2.example.com:14250"}, instances)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new instances were not notified")
+	}
+}
@@ -18,6 +18,7 @@ package es
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/olivere/elastic"
 )
@@ -28,11 +29,23 @@ type Client interface {
 	CreateIndex(index string) IndicesCreateService
 	CreateTemplate(id string) TemplateCreateService
 	Index() IndexService
+	// Bulk returns a bulk request sent at once by its Do, unlike the documents added to the bulk
+	// processor by IndexService.
+	Bulk() BulkService
 	Search(indices ...string) SearchService
 	MultiSearch() MultiSearchService
+	// AsyncSearch searches the indices with the async search API of Elasticsearch 7.7+.
+	AsyncSearch(indices ...string) AsyncSearchService
+	// OpenPointInTime opens a point in time of the indices, kept alive for keepAlive between the
+	// searches using it, and returns its ID. It requires Elasticsearch 7.10+.
+	OpenPointInTime(ctx context.Context, keepAlive string, indices ...string) (string, error)
+	// ClosePointInTime releases a point in time opened by OpenPointInTime.
+	ClosePointInTime(ctx context.Context, id string) error
 	DeleteIndex(index string) IndicesDeleteService
 	io.Closer
 	GetVersion() uint
+	// Ping returns an error if the cluster cannot be reached.
+	Ping(ctx context.Context) error
 }
 
 // IndicesExistsService is an abstraction for elastic.IndicesExistsService
@@ -63,15 +76,23 @@ type IndexService interface {
 	Type(typ string) IndexService
 	Id(id string) IndexService
 	BodyJson(body any) IndexService
+	Routing(routing string) IndexService
 	Add()
 }
 
+// BulkService is an abstraction for elastic.BulkService
+type BulkService interface {
+	Add(requests ...*elastic.BulkIndexRequest) BulkService
+	Do(ctx context.Context) (*elastic.BulkResponse, error)
+}
+
 // SearchService is an abstraction for elastic.SearchService
 type SearchService interface {
 	Size(size int) SearchService
 	Aggregation(name string, aggregation elastic.Aggregation) SearchService
 	IgnoreUnavailable(ignoreUnavailable bool) SearchService
 	Query(query elastic.Query) SearchService
+	Routing(routings ...string) SearchService
 	Do(ctx context.Context) (*elastic.SearchResult, error)
 }
 
@@ -81,3 +102,12 @@ type MultiSearchService interface {
 	Index(indices ...string) MultiSearchService
 	Do(ctx context.Context) (*elastic.MultiSearchResult, error)
 }
+
+// AsyncSearchService is an abstraction for the async search API. Do submits the search and
+// polls it until it completes, waiting for up to the WaitForCompletionTimeout at each request.
+type AsyncSearchService interface {
+	Source(source *elastic.SearchSource) AsyncSearchService
+	Routing(routings ...string) AsyncSearchService
+	WaitForCompletionTimeout(timeout time.Duration) AsyncSearchService
+	Do(ctx context.Context) (*elastic.SearchResult, error)
+}
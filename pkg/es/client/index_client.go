@@ -32,6 +32,9 @@ type Index struct {
 	CreationTime time.Time
 	// Aliases
 	Aliases map[string]bool
+	// Total store size in bytes, including replicas.
+	// It is only populated from the result of GetIndicesStoreSize.
+	StoreSize int64
 }
 
 // Alias represents ES alias.
@@ -102,6 +105,40 @@ func (i *IndicesClient) GetJaegerIndices(prefix string) ([]Index, error) {
 	return indices, nil
 }
 
+// GetIndicesStoreSize queries the total store size in bytes, including replicas,
+// of all Jaeger indices and returns it keyed by index name.
+// Indices for which Elasticsearch does not report a size (e.g. closed indices) are omitted.
+func (i *IndicesClient) GetIndicesStoreSize(prefix string) (map[string]int64, error) {
+	prefix += "jaeger-*"
+
+	body, err := i.request(elasticRequest{
+		endpoint: fmt.Sprintf("_cat/indices/%s?format=json&bytes=b&h=index,store.size", prefix),
+		method:   http.MethodGet,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indices store size: %w", err)
+	}
+
+	type indexStats struct {
+		Index     string `json:"index"`
+		StoreSize string `json:"store.size"`
+	}
+	var stats []indexStats
+	if err = json.Unmarshal(body, &stats); err != nil {
+		return nil, fmt.Errorf("failed to query indices store size and unmarshall response body: %q: %w", body, err)
+	}
+
+	sizes := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		size, err := strconv.ParseInt(s.StoreSize, 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[s.Index] = size
+	}
+	return sizes, nil
+}
+
 // execute delete request
 func (i *IndicesClient) indexDeleteRequest(concatIndices string) error {
 	_, err := i.request(elasticRequest{
@@ -175,6 +175,63 @@ func TestClientGetIndices(t *testing.T) {
 	}
 }
 
+func TestClientGetIndicesStoreSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		response     string
+		errContains  string
+		sizes        map[string]int64
+	}{
+		{
+			name:         "no error",
+			responseCode: http.StatusOK,
+			response:     `[{"index":"jaeger-span-000001","store.size":"1024"},{"index":"jaeger-span-000002","store.size":null}]`,
+			sizes:        map[string]int64{"jaeger-span-000001": 1024},
+		},
+		{
+			name:         "client error",
+			responseCode: http.StatusBadRequest,
+			response:     esErrResponse,
+			errContains:  "failed to query indices store size: request failed, status code: 400",
+		},
+		{
+			name:         "unmarshall error",
+			responseCode: http.StatusOK,
+			response:     "AAA",
+			errContains:  `failed to query indices store size and unmarshall response body: "AAA"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/_cat/indices/foo-jaeger-*", req.URL.Path)
+				assert.Equal(t, "b", req.URL.Query().Get("bytes"))
+				res.WriteHeader(test.responseCode)
+				res.Write([]byte(test.response))
+			}))
+			defer testServer.Close()
+
+			c := &IndicesClient{
+				Client: Client{
+					Client:   testServer.Client(),
+					Endpoint: testServer.URL,
+				},
+			}
+
+			sizes, err := c.GetIndicesStoreSize("foo-")
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				assert.Nil(t, sizes)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.sizes, sizes)
+			}
+		})
+	}
+}
+
 func getIndicesList(size int) []Index {
 	indicesList := []Index{}
 	for count := 1; count <= size/2; count++ {
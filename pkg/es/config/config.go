@@ -19,6 +19,7 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/asaskevich/govalidator"
 	esV8 "github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
 	"github.com/olivere/elastic"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -44,6 +46,15 @@ import (
 	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
 
+const (
+	// ClientOlivere sends the requests with the olivere/elastic client, supporting all the versions
+	// of Elasticsearch and OpenSearch.
+	ClientOlivere = "olivere"
+	// ClientGoElasticsearch sends the requests with the official go-elasticsearch client, which only
+	// supports Elasticsearch 7.14+.
+	ClientGoElasticsearch = "go-elasticsearch"
+)
+
 // Configuration describes the configuration properties needed to connect to an ElasticSearch cluster
 type Configuration struct {
 	Servers                        []string       `mapstructure:"server_urls" valid:"required,url"`
@@ -81,11 +92,21 @@ type Configuration struct {
 	Enabled                        bool           `mapstructure:"-"`
 	TLS                            tlscfg.Options `mapstructure:"tls"`
 	UseReadWriteAliases            bool           `mapstructure:"use_aliases"`
+	RouteByService                 bool           `mapstructure:"route_by_service"`
+	IdempotentWrites               bool           `mapstructure:"idempotent_writes"`
+	UsePointInTime                 bool           `mapstructure:"use_point_in_time"`
+	AsyncSearchTimeout             time.Duration  `mapstructure:"async_search_timeout"`
 	CreateIndexTemplates           bool           `mapstructure:"create_mappings"`
 	UseILM                         bool           `mapstructure:"use_ilm"`
 	Version                        uint           `mapstructure:"version"`
 	LogLevel                       string         `mapstructure:"log_level"`
 	SendGetBodyAs                  string         `mapstructure:"send_get_body_as"`
+	// Client is the library sending the requests, ClientOlivere or ClientGoElasticsearch. The
+	// following options only apply to the official client.
+	Client              string `mapstructure:"client" valid:"in(olivere|go-elasticsearch)"`
+	CompressRequests    bool   `mapstructure:"compress_requests"`
+	CompatibilityHeader bool   `mapstructure:"compatibility_header"`
+	MaxRetries          int    `mapstructure:"max_retries"`
 }
 
 // TagsAsFields holds configuration for tag schema.
@@ -107,6 +128,9 @@ func NewClient(c *Configuration, logger *zap.Logger, metricsFactory metrics.Fact
 	if len(c.Servers) < 1 {
 		return nil, errors.New("no servers specified")
 	}
+	if c.Client == ClientGoElasticsearch {
+		return newClientV8(c, logger, metricsFactory)
+	}
 	options, err := c.getConfigOptions(logger)
 	if err != nil {
 		return nil, err
@@ -174,23 +198,9 @@ func NewClient(c *Configuration, logger *zap.Logger, metricsFactory metrics.Fact
 		if err != nil {
 			return nil, err
 		}
-		esVersion, err := strconv.Atoi(string(pingResult.Version.Number[0]))
-		if err != nil {
+		if c.Version, err = majorVersion(pingResult.Version.Number, pingResult.TagLine, logger); err != nil {
 			return nil, err
 		}
-		// OpenSearch is based on ES 7.x
-		if strings.Contains(pingResult.TagLine, "OpenSearch") {
-			if pingResult.Version.Number[0] == '1' {
-				logger.Info("OpenSearch 1.x detected, using ES 7.x index mappings")
-				esVersion = 7
-			}
-			if pingResult.Version.Number[0] == '2' {
-				logger.Info("OpenSearch 2.x detected, using ES 7.x index mappings")
-				esVersion = 7
-			}
-		}
-		logger.Info("Elasticsearch detected", zap.Int("version", esVersion))
-		c.Version = uint(esVersion)
 	}
 
 	var rawClientV8 *esV8.Client
@@ -204,12 +214,46 @@ func NewClient(c *Configuration, logger *zap.Logger, metricsFactory metrics.Fact
 	return eswrapper.WrapESClient(rawClient, bulkProc, c.Version, rawClientV8), nil
 }
 
+// majorVersion returns the major version of the index mappings of the cluster of the version number
+// and tag line returned by its root endpoint.
+func majorVersion(number, tagLine string, logger *zap.Logger) (uint, error) {
+	if number == "" {
+		return 0, errors.New("the version of Elasticsearch is unknown")
+	}
+	esVersion, err := strconv.Atoi(string(number[0]))
+	if err != nil {
+		return 0, err
+	}
+	// OpenSearch is based on ES 7.x
+	if strings.Contains(tagLine, "OpenSearch") {
+		if number[0] == '1' {
+			logger.Info("OpenSearch 1.x detected, using ES 7.x index mappings")
+			esVersion = 7
+		}
+		if number[0] == '2' {
+			logger.Info("OpenSearch 2.x detected, using ES 7.x index mappings")
+			esVersion = 7
+		}
+	}
+	logger.Info("Elasticsearch detected", zap.Int("version", esVersion))
+	return uint(esVersion), nil
+}
+
 func newElasticsearchV8(c *Configuration, logger *zap.Logger) (*esV8.Client, error) {
 	var options esV8.Config
 	options.Addresses = c.Servers
 	options.Username = c.Username
 	options.Password = c.Password
 	options.DiscoverNodesOnStart = c.Sniffer
+	options.CompressRequestBody = c.CompressRequests
+	options.EnableCompatibilityMode = c.CompatibilityHeader
+	if c.MaxRetries > 0 {
+		options.MaxRetries = c.MaxRetries
+		options.RetryOnStatus = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+		options.RetryBackoff = retryBackoff
+	} else {
+		options.DisableRetry = true
+	}
 	transport, err := GetHTTPRoundTripper(c, logger)
 	if err != nil {
 		return nil, err
@@ -218,6 +262,90 @@ func newElasticsearchV8(c *Configuration, logger *zap.Logger) (*esV8.Client, err
 	return esV8.NewClient(options)
 }
 
+// retryBackoff doubles the delay of the retries from 100ms, up to 5s.
+func retryBackoff(attempt int) time.Duration {
+	backoff := 100 * time.Millisecond << (attempt - 1)
+	if attempt > 6 || backoff > 5*time.Second {
+		return 5 * time.Second
+	}
+	return backoff
+}
+
+// bulkFlush is the flush of the bulk indexer of the official client, in the context of the flush.
+type bulkFlush struct {
+	start time.Time
+	err   error
+}
+
+type bulkFlushKey struct{}
+
+// newClientV8 creates an es.Client sending the requests with the official client. The spans are
+// indexed by its bulk indexer, which flushes them by size or interval but not by count of actions.
+func newClientV8(c *Configuration, logger *zap.Logger, metricsFactory metrics.Factory) (es.Client, error) {
+	if err := c.loadPasswordFromFile(); err != nil {
+		return nil, err
+	}
+	rawClient, err := newElasticsearchV8(c, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error creating v8 client: %w", err)
+	}
+
+	if c.Version == 0 {
+		res, err := rawClient.Info()
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			return nil, fmt.Errorf("failed to get the version of Elasticsearch: %s", res)
+		}
+		var info struct {
+			Version struct {
+				Number string `json:"number"`
+			} `json:"version"`
+			TagLine string `json:"tagline"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+			return nil, fmt.Errorf("failed to decode the version of Elasticsearch: %w", err)
+		}
+		if c.Version, err = majorVersion(info.Version.Number, info.TagLine, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	sm := storageMetrics.NewWriteMetrics(metricsFactory, "bulk_index")
+	bulkIndexer, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:        rawClient,
+		NumWorkers:    c.BulkWorkers,
+		FlushBytes:    c.BulkSize,
+		FlushInterval: c.BulkFlushInterval,
+		OnFlushStart: func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, bulkFlushKey{}, &bulkFlush{start: time.Now()})
+		},
+		OnError: func(ctx context.Context, err error) {
+			if flush, ok := ctx.Value(bulkFlushKey{}).(*bulkFlush); ok {
+				flush.err = err
+			}
+			logger.Error("Elasticsearch could not process bulk request", zap.Error(err))
+		},
+		OnFlushEnd: func(ctx context.Context) {
+			if flush, ok := ctx.Value(bulkFlushKey{}).(*bulkFlush); ok {
+				sm.Emit(flush.err, time.Since(flush.start))
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	onFailure := func(_ context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+		logger.Error("Elasticsearch part of bulk request failed",
+			zap.String("index", item.Index),
+			zap.Reflect("response", res),
+			zap.Error(err))
+	}
+	return eswrapper.WrapESClientV8(rawClient, bulkIndexer, c.Version, onFailure)
+}
+
 // ApplyDefaults copies settings from source unless its own value is non-zero.
 func (c *Configuration) ApplyDefaults(source *Configuration) {
 	if len(c.RemoteReadClusters) == 0 {
@@ -289,6 +417,12 @@ func (c *Configuration) ApplyDefaults(source *Configuration) {
 	if c.SendGetBodyAs == "" {
 		c.SendGetBodyAs = source.SendGetBodyAs
 	}
+	if c.Client == "" {
+		c.Client = source.Client
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = source.MaxRetries
+	}
 }
 
 // GetIndexRolloverFrequencySpansDuration returns jaeger-span index rollover frequency duration
@@ -362,15 +496,8 @@ func (c *Configuration) getConfigOptions(logger *zap.Logger) ([]elastic.ClientOp
 	}
 	options = append(options, elastic.SetHttpClient(httpClient))
 
-	if c.Password != "" && c.PasswordFilePath != "" {
-		return nil, fmt.Errorf("both Password and PasswordFilePath are set")
-	}
-	if c.PasswordFilePath != "" {
-		passwordFromFile, err := loadTokenFromFile(c.PasswordFilePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load password from file: %w", err)
-		}
-		c.Password = passwordFromFile
+	if err := c.loadPasswordFromFile(); err != nil {
+		return nil, err
 	}
 	options = append(options, elastic.SetBasicAuth(c.Username, c.Password))
 
@@ -391,6 +518,21 @@ func (c *Configuration) getConfigOptions(logger *zap.Logger) ([]elastic.ClientOp
 	return options, nil
 }
 
+// loadPasswordFromFile sets the password to the content of PasswordFilePath if set.
+func (c *Configuration) loadPasswordFromFile() error {
+	if c.Password != "" && c.PasswordFilePath != "" {
+		return fmt.Errorf("both Password and PasswordFilePath are set")
+	}
+	if c.PasswordFilePath != "" {
+		passwordFromFile, err := loadTokenFromFile(c.PasswordFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to load password from file: %w", err)
+		}
+		c.Password = passwordFromFile
+	}
+	return nil
+}
+
 func addLoggerOptions(options []elastic.ClientOptionFunc, logLevel string, logger *zap.Logger) ([]elastic.ClientOptionFunc, error) {
 	// Decouple ES logger from the log-level assigned to the parent application's log-level; otherwise, the least
 	// permissive log-level will dominate.
@@ -0,0 +1,287 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func TestMajorVersion(t *testing.T) {
+	tests := []struct {
+		number   string
+		tagLine  string
+		expected uint
+		err      string
+	}{
+		{number: "6.8.23", tagLine: "You Know, for Search", expected: 6},
+		{number: "7.17.9", tagLine: "You Know, for Search", expected: 7},
+		{number: "8.14.1", tagLine: "You Know, for Search", expected: 8},
+		{number: "1.3.14", tagLine: "The OpenSearch Project: https://opensearch.org/", expected: 7},
+		{number: "2.11.0", tagLine: "The OpenSearch Project: https://opensearch.org/", expected: 7},
+		{number: "", err: "the version of Elasticsearch is unknown"},
+		{number: "v8", err: "invalid syntax"},
+	}
+	for _, test := range tests {
+		t.Run(test.number, func(t *testing.T) {
+			version, err := majorVersion(test.number, test.tagLine, zap.NewNop())
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: 100 * time.Millisecond},
+		{attempt: 2, expected: 200 * time.Millisecond},
+		{attempt: 3, expected: 400 * time.Millisecond},
+		{attempt: 6, expected: 3200 * time.Millisecond},
+		{attempt: 7, expected: 5 * time.Second},
+		{attempt: 64, expected: 5 * time.Second},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, retryBackoff(test.attempt), "attempt %d", test.attempt)
+	}
+}
+
+// newTestServer returns the URL of an Elasticsearch cluster answering the requests with handler.
+func newTestServer(t *testing.T, handler http.HandlerFunc) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the official client checks that it is connected to Elasticsearch
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	// the clients without TLS nor token use the default transport
+	t.Cleanup(http.DefaultTransport.(*http.Transport).CloseIdleConnections)
+	return srv.URL
+}
+
+func infoHandler(requests *atomic.Int32, number, tagLine string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/" {
+			requests.Add(1)
+			_, _ = io.WriteString(w, `{"version":{"number":"`+number+`"},"tagline":"`+tagLine+`"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"errors":false,"items":[{"index":{"status":201}}]}`)
+	}
+}
+
+func TestNewClientV8Version(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		tagLine  string
+		version  uint
+		expected uint
+	}{
+		{name: "Elasticsearch 7", number: "7.17.9", tagLine: "You Know, for Search", expected: 7},
+		{name: "Elasticsearch 8", number: "8.14.1", tagLine: "You Know, for Search", expected: 8},
+		{name: "OpenSearch 2", number: "2.11.0", tagLine: "The OpenSearch Project: https://opensearch.org/", expected: 7},
+		{name: "configured version", number: "8.14.1", version: 7, expected: 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			cfg := &Configuration{
+				Servers: []string{newTestServer(t, infoHandler(&requests, test.number, test.tagLine))},
+				Client:  ClientGoElasticsearch,
+				Version: test.version,
+			}
+			client, err := NewClient(cfg, zap.NewNop(), metrics.NullFactory)
+			require.NoError(t, err)
+			defer client.Close()
+			assert.Equal(t, test.expected, client.GetVersion())
+			assert.Equal(t, test.expected, cfg.Version)
+			if test.version != 0 {
+				assert.Zero(t, requests.Load(), "the version is not requested when configured")
+			} else {
+				assert.EqualValues(t, 1, requests.Load())
+			}
+		})
+	}
+}
+
+func TestNewClientV8Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Configuration
+		handler http.HandlerFunc
+		err     string
+	}{
+		{
+			name: "no servers",
+			cfg:  Configuration{},
+			err:  "no servers specified",
+		},
+		{
+			name: "missing password file",
+			cfg:  Configuration{Servers: []string{"http://127.0.0.1:9200"}, PasswordFilePath: filepath.Join(t.TempDir(), "missing")},
+			err:  "failed to load password from file",
+		},
+		{
+			name: "info error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = io.WriteString(w, `{"error":{"type":"security_exception"},"status":401}`)
+			},
+			err: "failed to get the version of Elasticsearch: [401 Unauthorized]",
+		},
+		{
+			name: "invalid info",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, `{"version":`)
+			},
+			err: "failed to decode the version of Elasticsearch",
+		},
+		{
+			name: "unknown version",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, `{"tagline":"You Know, for Search"}`)
+			},
+			err: "the version of Elasticsearch is unknown",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := test.cfg
+			cfg.Client = ClientGoElasticsearch
+			if test.handler != nil {
+				cfg.Servers = []string{newTestServer(t, test.handler)}
+			}
+			_, err := NewClient(&cfg, zap.NewNop(), metrics.NullFactory)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestNewClientV8Retries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		failures   int32
+		requests   int32
+		err        string
+	}{
+		{name: "retried", maxRetries: 2, failures: 2, requests: 3},
+		{name: "retries exhausted", maxRetries: 1, failures: 2, requests: 2, err: "503 Service Unavailable"},
+		{name: "retries disabled", failures: 1, requests: 1, err: "503 Service Unavailable"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			url := newTestServer(t, func(w http.ResponseWriter, _ *http.Request) {
+				if requests.Add(1) <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = io.WriteString(w, `{"version":{"number":"8.14.1"}}`)
+			})
+			cfg := &Configuration{
+				Servers:    []string{url},
+				Client:     ClientGoElasticsearch,
+				MaxRetries: test.maxRetries,
+			}
+			start := time.Now()
+			client, err := NewClient(cfg, zap.NewNop(), metrics.NullFactory)
+			assert.Equal(t, test.requests, requests.Load())
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			defer client.Close()
+			// the retries are delayed by 100ms then 200ms
+			assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+		})
+	}
+}
+
+func TestNewClientV8BulkMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected []metricstest.ExpectedMetric
+	}{
+		{
+			name:   "flushed",
+			status: http.StatusOK,
+			expected: []metricstest.ExpectedMetric{
+				{Name: "bulk_index.attempts", Value: 1},
+				{Name: "bulk_index.inserts", Value: 1},
+				{Name: "bulk_index.errors", Value: 0},
+			},
+		},
+		{
+			name:   "failed",
+			status: http.StatusInternalServerError,
+			expected: []metricstest.ExpectedMetric{
+				{Name: "bulk_index.attempts", Value: 1},
+				{Name: "bulk_index.inserts", Value: 0},
+				{Name: "bulk_index.errors", Value: 1},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var bulkRequests atomic.Int32
+			url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/_bulk" {
+					bulkRequests.Add(1)
+					w.WriteHeader(test.status)
+				}
+				_, _ = io.WriteString(w, `{"errors":false,"items":[{"index":{"status":201}}]}`)
+			})
+			metricsFactory := metricstest.NewFactory(0)
+			defer metricsFactory.Stop()
+			cfg := &Configuration{
+				Servers:           []string{url},
+				Client:            ClientGoElasticsearch,
+				Version:           8,
+				BulkWorkers:       1,
+				BulkFlushInterval: time.Hour,
+			}
+			client, err := NewClient(cfg, zap.NewNop(), metricsFactory)
+			require.NoError(t, err)
+			client.Index().Index("jaeger-span").BodyJson(map[string]string{"traceID": "1"}).Add()
+			require.NoError(t, client.Close())
+
+			assert.EqualValues(t, 1, bulkRequests.Load())
+			metricsFactory.AssertCounterMetrics(t, test.expected...)
+		})
+	}
+}
+
+func TestNewClientV8Ping(t *testing.T) {
+	url := newTestServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{}`)
+	})
+	client, err := NewClient(&Configuration{Servers: []string{url}, Client: ClientGoElasticsearch, Version: 8}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.Ping(context.Background()))
+}
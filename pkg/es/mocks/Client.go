@@ -8,6 +8,8 @@
 package mocks
 
 import (
+	context "context"
+
 	es "github.com/jaegertracing/jaeger/pkg/es"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -17,6 +19,52 @@ type Client struct {
 	mock.Mock
 }
 
+// AsyncSearch provides a mock function with given fields: indices
+func (_m *Client) AsyncSearch(indices ...string) es.AsyncSearchService {
+	_va := make([]interface{}, len(indices))
+	for _i := range indices {
+		_va[_i] = indices[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AsyncSearch")
+	}
+
+	var r0 es.AsyncSearchService
+	if rf, ok := ret.Get(0).(func(...string) es.AsyncSearchService); ok {
+		r0 = rf(indices...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.AsyncSearchService)
+		}
+	}
+
+	return r0
+}
+
+// Bulk provides a mock function with given fields:
+func (_m *Client) Bulk() es.BulkService {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Bulk")
+	}
+
+	var r0 es.BulkService
+	if rf, ok := ret.Get(0).(func() es.BulkService); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.BulkService)
+		}
+	}
+
+	return r0
+}
+
 // Close provides a mock function with given fields:
 func (_m *Client) Close() error {
 	ret := _m.Called()
@@ -35,6 +83,24 @@ func (_m *Client) Close() error {
 	return r0
 }
 
+// ClosePointInTime provides a mock function with given fields: ctx, id
+func (_m *Client) ClosePointInTime(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClosePointInTime")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateIndex provides a mock function with given fields: index
 func (_m *Client) CreateIndex(index string) es.IndicesCreateService {
 	ret := _m.Called(index)
@@ -173,6 +239,59 @@ func (_m *Client) MultiSearch() es.MultiSearchService {
 	return r0
 }
 
+// OpenPointInTime provides a mock function with given fields: ctx, keepAlive, indices
+func (_m *Client) OpenPointInTime(ctx context.Context, keepAlive string, indices ...string) (string, error) {
+	_va := make([]interface{}, len(indices))
+	for _i := range indices {
+		_va[_i] = indices[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, keepAlive)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenPointInTime")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (string, error)); ok {
+		return rf(ctx, keepAlive, indices...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) string); ok {
+		r0 = rf(ctx, keepAlive, indices...)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, keepAlive, indices...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ping provides a mock function with given fields: ctx
+func (_m *Client) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: indices
 func (_m *Client) Search(indices ...string) es.SearchService {
 	_va := make([]interface{}, len(indices))
@@ -111,6 +111,32 @@ func (_m *SearchService) Query(query elastic.Query) es.SearchService {
 	return r0
 }
 
+// Routing provides a mock function with given fields: routings
+func (_m *SearchService) Routing(routings ...string) es.SearchService {
+	_va := make([]interface{}, len(routings))
+	for _i := range routings {
+		_va[_i] = routings[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Routing")
+	}
+
+	var r0 es.SearchService
+	if rf, ok := ret.Get(0).(func(...string) es.SearchService); ok {
+		r0 = rf(routings...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.SearchService)
+		}
+	}
+
+	return r0
+}
+
 // Size provides a mock function with given fields: size
 func (_m *SearchService) Size(size int) es.SearchService {
 	ret := _m.Called(size)
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package eswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/olivere/elastic"
+
+	"github.com/jaegertracing/jaeger/pkg/es"
+)
+
+// performFunc performs a request of the APIs that are implemented alike for both client libraries,
+// returning the body of the response. The error responses are returned as *elastic.Error.
+type performFunc func(ctx context.Context, method, path string, params url.Values, body any) (json.RawMessage, error)
+
+const (
+	// asyncSearchKeepAlive is how long a running async search is kept without being polled.
+	asyncSearchKeepAlive = "5m"
+	// defaultAsyncSearchWait is how long each request waits for the completion of the search
+	// when the service does not set it.
+	defaultAsyncSearchWait = time.Second
+)
+
+func openPointInTime(ctx context.Context, perform performFunc, keepAlive string, indices []string) (string, error) {
+	body, err := perform(ctx, http.MethodPost, indicesPath(indices)+"/_pit",
+		url.Values{"keep_alive": []string{keepAlive}, "ignore_unavailable": []string{"true"}}, nil)
+	if err != nil {
+		return "", err
+	}
+	var pit struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &pit); err != nil {
+		return "", fmt.Errorf("failed to decode the point in time: %w", err)
+	}
+	return pit.ID, nil
+}
+
+func closePointInTime(ctx context.Context, perform performFunc, id string) error {
+	_, err := perform(ctx, http.MethodDelete, "/_pit", nil, map[string]string{"id": id})
+	return err
+}
+
+func indicesPath(indices []string) string {
+	escaped := make([]string, len(indices))
+	for i, index := range indices {
+		escaped[i] = url.PathEscape(index)
+	}
+	return "/" + strings.Join(escaped, ",")
+}
+
+// AsyncSearchServiceWrapper implements es.AsyncSearchService with the async search API,
+// which the elastic client does not support.
+type AsyncSearchServiceWrapper struct {
+	perform           performFunc
+	indices           []string
+	source            *elastic.SearchSource
+	routing           string
+	waitForCompletion time.Duration
+}
+
+type asyncSearchResponse struct {
+	ID        string                `json:"id"`
+	IsRunning bool                  `json:"is_running"`
+	Response  *elastic.SearchResult `json:"response"`
+	Error     *elastic.ErrorDetails `json:"error"`
+}
+
+// Source sets the body of the search.
+func (s AsyncSearchServiceWrapper) Source(source *elastic.SearchSource) es.AsyncSearchService {
+	s.source = source
+	return s
+}
+
+// Routing sets the routing of the search.
+func (s AsyncSearchServiceWrapper) Routing(routings ...string) es.AsyncSearchService {
+	s.routing = strings.Join(routings, ",")
+	return s
+}
+
+// WaitForCompletionTimeout sets how long each request waits for the search to complete.
+func (s AsyncSearchServiceWrapper) WaitForCompletionTimeout(timeout time.Duration) es.AsyncSearchService {
+	s.waitForCompletion = timeout
+	return s
+}
+
+// Do submits the search and polls it until it completes, deleting it if ctx is done before.
+func (s AsyncSearchServiceWrapper) Do(ctx context.Context) (*elastic.SearchResult, error) {
+	body := any(map[string]any{})
+	if s.source != nil {
+		source, err := s.source.Source()
+		if err != nil {
+			return nil, err
+		}
+		body = source
+	}
+	waitForCompletion := s.waitForCompletion
+	if waitForCompletion <= 0 {
+		waitForCompletion = defaultAsyncSearchWait
+	}
+	params := url.Values{
+		"keep_alive":                  []string{asyncSearchKeepAlive},
+		"wait_for_completion_timeout": []string{fmt.Sprintf("%dms", waitForCompletion.Milliseconds())},
+		"ignore_unavailable":          []string{"true"},
+	}
+	if s.routing != "" {
+		params.Set("routing", s.routing)
+	}
+	res, err := s.request(ctx, http.MethodPost, indicesPath(s.indices)+"/_async_search", params, body)
+	if err != nil {
+		return nil, err
+	}
+	if !res.IsRunning {
+		// the searches completing before the timeout are not stored
+		return res.result()
+	}
+	defer s.delete(ctx, res.ID)
+	params.Del("ignore_unavailable")
+	params.Del("routing")
+	for res.IsRunning {
+		if res, err = s.request(ctx, http.MethodGet, "/_async_search/"+url.PathEscape(res.ID), params, nil); err != nil {
+			return nil, err
+		}
+	}
+	return res.result()
+}
+
+func (s AsyncSearchServiceWrapper) request(ctx context.Context, method, path string, params url.Values, body any) (*asyncSearchResponse, error) {
+	resBody, err := s.perform(ctx, method, path, params, body)
+	if err != nil {
+		return nil, err
+	}
+	var res asyncSearchResponse
+	if err := json.Unmarshal(resBody, &res); err != nil {
+		return nil, fmt.Errorf("failed to decode the async search response: %w", err)
+	}
+	return &res, nil
+}
+
+// delete removes the search stored by Elasticsearch, even if ctx is done.
+func (s AsyncSearchServiceWrapper) delete(ctx context.Context, id string) {
+	_, _ = s.perform(context.WithoutCancel(ctx), http.MethodDelete, "/_async_search/"+url.PathEscape(id), nil, nil)
+}
+
+func (r *asyncSearchResponse) result() (*elastic.SearchResult, error) {
+	if r.Error != nil {
+		return nil, &elastic.Error{Details: r.Error}
+	}
+	if r.Response == nil {
+		return nil, errors.New("the async search has no response")
+	}
+	return r.Response, nil
+}
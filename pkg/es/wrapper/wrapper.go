@@ -17,7 +17,10 @@ package eswrapper
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
 	esV8 "github.com/elastic/go-elasticsearch/v8"
@@ -42,6 +45,12 @@ func (c ClientWrapper) GetVersion() uint {
 	return c.esVersion
 }
 
+// Ping requests the root endpoint of the cluster.
+func (c ClientWrapper) Ping(ctx context.Context) error {
+	_, err := c.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: http.MethodHead, Path: "/"})
+	return err
+}
+
 // WrapESClient creates a ESClient out of *elastic.Client.
 func WrapESClient(client *elastic.Client, s *elastic.BulkProcessor, esVersion uint, clientV8 *esV8.Client) ClientWrapper {
 	return ClientWrapper{
@@ -84,6 +93,11 @@ func (c ClientWrapper) Index() es.IndexService {
 	return WrapESIndexService(r, c.bulkService, c.esVersion)
 }
 
+// Bulk calls this function to internal client.
+func (c ClientWrapper) Bulk() es.BulkService {
+	return WrapESBulkService(c.client.Bulk(), c.esVersion >= 7)
+}
+
 // Search calls this function to internal client.
 func (c ClientWrapper) Search(indices ...string) es.SearchService {
 	searchService := c.client.Search(indices...)
@@ -102,6 +116,34 @@ func (c ClientWrapper) MultiSearch() es.MultiSearchService {
 	return WrapESMultiSearchService(multiSearchService)
 }
 
+// AsyncSearch returns a service submitting an async search of the indices.
+func (c ClientWrapper) AsyncSearch(indices ...string) es.AsyncSearchService {
+	return AsyncSearchServiceWrapper{perform: c.perform, indices: indices}
+}
+
+// OpenPointInTime opens a point in time of the indices, the missing ones being ignored.
+func (c ClientWrapper) OpenPointInTime(ctx context.Context, keepAlive string, indices ...string) (string, error) {
+	return openPointInTime(ctx, c.perform, keepAlive, indices)
+}
+
+// ClosePointInTime deletes the point in time.
+func (c ClientWrapper) ClosePointInTime(ctx context.Context, id string) error {
+	return closePointInTime(ctx, c.perform, id)
+}
+
+func (c ClientWrapper) perform(ctx context.Context, method, path string, params url.Values, body any) (json.RawMessage, error) {
+	res, err := c.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: method,
+		Path:   path,
+		Params: params,
+		Body:   body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
 // Close closes ESClient and flushes all data to the storage.
 func (c ClientWrapper) Close() error {
 	c.client.Stop()
@@ -189,6 +231,8 @@ type TemplateCreatorWrapperV8 struct {
 	indicesV8       *esV8api.Indices
 	templateName    string
 	templateMapping string
+	// legacy creates a legacy template, for the mappings of Elasticsearch 7.
+	legacy bool
 }
 
 // Body adds mapping to the future request.
@@ -200,10 +244,17 @@ func (c TemplateCreatorWrapperV8) Body(mapping string) es.TemplateCreateService
 
 // Do executes Put Template command.
 func (c TemplateCreatorWrapperV8) Do(context.Context) (*elastic.IndicesPutTemplateResponse, error) {
-	resp, err := c.indicesV8.PutIndexTemplate(c.templateName, strings.NewReader(c.templateMapping))
+	var resp *esV8api.Response
+	var err error
+	if c.legacy {
+		resp, err = c.indicesV8.PutTemplate(c.templateName, strings.NewReader(c.templateMapping))
+	} else {
+		resp, err = c.indicesV8.PutIndexTemplate(c.templateName, strings.NewReader(c.templateMapping))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error creating index template %s: %w", c.templateName, err)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("error creating index template %s: %s", c.templateName, resp)
 	}
@@ -238,6 +289,11 @@ func (i IndexServiceWrapper) Type(typ string) es.IndexService {
 	return WrapESIndexService(i.bulkIndexReq.Type(typ), i.bulkService, i.esVersion)
 }
 
+// Routing calls this function to internal service.
+func (i IndexServiceWrapper) Routing(routing string) es.IndexService {
+	return WrapESIndexService(i.bulkIndexReq.Routing(routing), i.bulkService, i.esVersion)
+}
+
 // Add adds the request to bulk service
 func (i IndexServiceWrapper) Add() {
 	i.bulkService.Add(i.bulkIndexReq)
@@ -245,6 +301,36 @@ func (i IndexServiceWrapper) Add() {
 
 // ---
 
+// BulkServiceWrapper is a wrapper around elastic.BulkService
+type BulkServiceWrapper struct {
+	bulkService *elastic.BulkService
+	// typeless removes the types of the requests, which Elasticsearch 7+ does not support.
+	typeless bool
+}
+
+// WrapESBulkService creates an ESBulkService out of *elastic.BulkService.
+func WrapESBulkService(bulkService *elastic.BulkService, typeless bool) BulkServiceWrapper {
+	return BulkServiceWrapper{bulkService: bulkService, typeless: typeless}
+}
+
+// Add calls this function to internal service.
+func (b BulkServiceWrapper) Add(requests ...*elastic.BulkIndexRequest) es.BulkService {
+	for _, r := range requests {
+		if b.typeless {
+			r.Type("")
+		}
+		b.bulkService.Add(r)
+	}
+	return b
+}
+
+// Do calls this function to internal service.
+func (b BulkServiceWrapper) Do(ctx context.Context) (*elastic.BulkResponse, error) {
+	return b.bulkService.Do(ctx)
+}
+
+// ---
+
 // SearchServiceWrapper is a wrapper around elastic.ESSearchService
 type SearchServiceWrapper struct {
 	searchService *elastic.SearchService
@@ -275,6 +361,11 @@ func (s SearchServiceWrapper) Query(query elastic.Query) es.SearchService {
 	return WrapESSearchService(s.searchService.Query(query))
 }
 
+// Routing calls this function to internal service.
+func (s SearchServiceWrapper) Routing(routings ...string) es.SearchService {
+	return WrapESSearchService(s.searchService.Routing(routings...))
+}
+
 // Do calls this function to internal service.
 func (s SearchServiceWrapper) Do(ctx context.Context) (*elastic.SearchResult, error) {
 	return s.searchService.Do(ctx)
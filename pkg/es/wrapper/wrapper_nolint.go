@@ -28,3 +28,15 @@ func (i IndexServiceWrapper) Id(id string) es.IndexService {
 func (i IndexServiceWrapper) BodyJson(body any) es.IndexService {
 	return WrapESIndexService(i.bulkIndexReq.Doc(body), i.bulkService, i.esVersion)
 }
+
+// Id sets the ID of the document.
+func (i IndexServiceWrapperV8) Id(id string) es.IndexService {
+	i.id = id
+	return i
+}
+
+// BodyJson sets the document, encoded to JSON when added to the bulk indexer.
+func (i IndexServiceWrapperV8) BodyJson(body any) es.IndexService {
+	i.body = body
+	return i
+}
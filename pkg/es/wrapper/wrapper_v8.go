@@ -0,0 +1,374 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package eswrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	esV8 "github.com/elastic/go-elasticsearch/v8"
+	esV8api "github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/olivere/elastic"
+
+	"github.com/jaegertracing/jaeger/pkg/es"
+)
+
+// BulkFailureFunc is called for the spans that the bulk indexer of ClientWrapperV8 fails to index.
+type BulkFailureFunc func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error)
+
+// ClientWrapperV8 implements es.Client with the official Elasticsearch client, the queries and the
+// responses being the types of the elastic client like for ClientWrapper.
+type ClientWrapperV8 struct {
+	client      *esV8.Client
+	bulkIndexer esutil.BulkIndexer
+	onFailure   BulkFailureFunc
+	esVersion   uint
+	// multiSearchClient encodes the multi searches, whose requests do not expose their headers,
+	// and the bulk requests, and sends them with client.
+	multiSearchClient *elastic.Client
+}
+
+// WrapESClientV8 creates an es.Client out of *esV8.Client, indexing the spans with the bulk indexer.
+func WrapESClientV8(client *esV8.Client, bulkIndexer esutil.BulkIndexer, esVersion uint, onFailure BulkFailureFunc) (ClientWrapperV8, error) {
+	multiSearchClient, err := elastic.NewSimpleClient(elastic.SetHttpClient(&http.Client{Transport: transportV8{client: client}}))
+	if err != nil {
+		return ClientWrapperV8{}, err
+	}
+	return ClientWrapperV8{
+		client:            client,
+		bulkIndexer:       bulkIndexer,
+		onFailure:         onFailure,
+		esVersion:         esVersion,
+		multiSearchClient: multiSearchClient,
+	}, nil
+}
+
+// transportV8 sends the requests of the elastic client with the official client, which replaces
+// their scheme and host by those of the node it selects.
+type transportV8 struct {
+	client *esV8.Client
+}
+
+func (t transportV8) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.client.Perform(req.Clone(req.Context()))
+}
+
+// GetVersion returns the ElasticSearch Version
+func (c ClientWrapperV8) GetVersion() uint {
+	return c.esVersion
+}
+
+// Ping requests the root endpoint of the cluster.
+func (c ClientWrapperV8) Ping(ctx context.Context) error {
+	res, err := esV8api.PingRequest{}.Do(ctx, c.client)
+	return decodeResponse(res, err, nil)
+}
+
+// IndexExists returns a service checking whether the index exists.
+func (c ClientWrapperV8) IndexExists(index string) es.IndicesExistsService {
+	return IndicesExistsServiceWrapperV8{client: c.client, index: index}
+}
+
+// CreateIndex returns a service creating the index.
+func (c ClientWrapperV8) CreateIndex(index string) es.IndicesCreateService {
+	return IndicesCreateServiceWrapperV8{client: c.client, index: index}
+}
+
+// DeleteIndex returns a service deleting the index.
+func (c ClientWrapperV8) DeleteIndex(index string) es.IndicesDeleteService {
+	return IndicesDeleteServiceWrapperV8{client: c.client, index: index}
+}
+
+// CreateTemplate returns a service creating the index template, a legacy one before Elasticsearch 8.
+func (c ClientWrapperV8) CreateTemplate(ttype string) es.TemplateCreateService {
+	return TemplateCreatorWrapperV8{
+		indicesV8:    c.client.Indices,
+		templateName: ttype,
+		legacy:       c.esVersion < 8,
+	}
+}
+
+// Index returns a service adding a document to the bulk indexer.
+func (c ClientWrapperV8) Index() es.IndexService {
+	return IndexServiceWrapperV8{bulkIndexer: c.bulkIndexer, onFailure: c.onFailure}
+}
+
+// Bulk returns a bulk request, sent at once unlike the documents added to the bulk indexer.
+func (c ClientWrapperV8) Bulk() es.BulkService {
+	return WrapESBulkService(c.multiSearchClient.Bulk(), true)
+}
+
+// Search returns a search of the indices.
+func (c ClientWrapperV8) Search(indices ...string) es.SearchService {
+	return SearchServiceWrapperV8{
+		client:             c.client,
+		indices:            indices,
+		source:             elastic.NewSearchSource(),
+		restTotalHitsAsInt: c.esVersion >= 7,
+	}
+}
+
+// MultiSearch returns a multi search.
+func (c ClientWrapperV8) MultiSearch() es.MultiSearchService {
+	multiSearchService := c.multiSearchClient.MultiSearch()
+	if c.esVersion >= 7 {
+		multiSearchService = multiSearchService.RestTotalHitsAsInt(true)
+	}
+	return WrapESMultiSearchService(multiSearchService)
+}
+
+// AsyncSearch returns a service submitting an async search of the indices.
+func (c ClientWrapperV8) AsyncSearch(indices ...string) es.AsyncSearchService {
+	return AsyncSearchServiceWrapper{perform: c.perform, indices: indices}
+}
+
+// OpenPointInTime opens a point in time of the indices, the missing ones being ignored.
+func (c ClientWrapperV8) OpenPointInTime(ctx context.Context, keepAlive string, indices ...string) (string, error) {
+	return openPointInTime(ctx, c.perform, keepAlive, indices)
+}
+
+// ClosePointInTime deletes the point in time.
+func (c ClientWrapperV8) ClosePointInTime(ctx context.Context, id string) error {
+	return closePointInTime(ctx, c.perform, id)
+}
+
+// Close flushes the bulk indexer.
+func (c ClientWrapperV8) Close() error {
+	return c.bulkIndexer.Close(context.Background())
+}
+
+func (c ClientWrapperV8) perform(ctx context.Context, method, path string, params url.Values, body any) (json.RawMessage, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = params.Encode()
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.client.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+	var raw json.RawMessage
+	if err := decodeResponse(&esV8api.Response{StatusCode: res.StatusCode, Body: res.Body, Header: res.Header}, nil, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// decodeResponse decodes the body of the response into v, if not nil, and returns the error
+// responses as *elastic.Error like the elastic client.
+func decodeResponse(res *esV8api.Response, err error, v any) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		e := &elastic.Error{Status: res.StatusCode}
+		// the body of the error is not JSON for some statuses, e.g. of HEAD requests
+		_ = json.Unmarshal(body, e)
+		return e
+	}
+	if v == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
+// ---
+
+// IndicesExistsServiceWrapperV8 implements es.IndicesExistsService.
+type IndicesExistsServiceWrapperV8 struct {
+	client *esV8.Client
+	index  string
+}
+
+// Do checks whether the index exists.
+func (s IndicesExistsServiceWrapperV8) Do(ctx context.Context) (bool, error) {
+	res, err := esV8api.IndicesExistsRequest{Index: []string{s.index}}.Do(ctx, s.client)
+	if err == nil && res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return false, nil
+	}
+	if err := decodeResponse(res, err, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IndicesCreateServiceWrapperV8 implements es.IndicesCreateService.
+type IndicesCreateServiceWrapperV8 struct {
+	client  *esV8.Client
+	index   string
+	mapping string
+}
+
+// Body sets the mapping of the index.
+func (s IndicesCreateServiceWrapperV8) Body(mapping string) es.IndicesCreateService {
+	s.mapping = mapping
+	return s
+}
+
+// Do creates the index.
+func (s IndicesCreateServiceWrapperV8) Do(ctx context.Context) (*elastic.IndicesCreateResult, error) {
+	req := esV8api.IndicesCreateRequest{Index: s.index}
+	if s.mapping != "" {
+		req.Body = strings.NewReader(s.mapping)
+	}
+	res, err := req.Do(ctx, s.client)
+	var result elastic.IndicesCreateResult
+	if err := decodeResponse(res, err, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IndicesDeleteServiceWrapperV8 implements es.IndicesDeleteService.
+type IndicesDeleteServiceWrapperV8 struct {
+	client *esV8.Client
+	index  string
+}
+
+// Do deletes the index.
+func (s IndicesDeleteServiceWrapperV8) Do(ctx context.Context) (*elastic.IndicesDeleteResponse, error) {
+	res, err := esV8api.IndicesDeleteRequest{Index: []string{s.index}}.Do(ctx, s.client)
+	var result elastic.IndicesDeleteResponse
+	if err := decodeResponse(res, err, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IndexServiceWrapperV8 implements es.IndexService with the bulk indexer.
+// See wrapper_nolint.go for more functions.
+type IndexServiceWrapperV8 struct {
+	bulkIndexer esutil.BulkIndexer
+	onFailure   BulkFailureFunc
+	index       string
+	id          string
+	routing     string
+	body        any
+}
+
+// Index sets the index of the document.
+func (i IndexServiceWrapperV8) Index(index string) es.IndexService {
+	i.index = index
+	return i
+}
+
+// Type is ignored, the official client supporting only the typeless indices of Elasticsearch 7+.
+func (i IndexServiceWrapperV8) Type(string) es.IndexService {
+	return i
+}
+
+// Routing sets the routing of the document.
+func (i IndexServiceWrapperV8) Routing(routing string) es.IndexService {
+	i.routing = routing
+	return i
+}
+
+// Add adds the document to the bulk indexer.
+func (i IndexServiceWrapperV8) Add() {
+	item := esutil.BulkIndexerItem{
+		Index:      i.index,
+		Action:     "index",
+		DocumentID: i.id,
+		Routing:    i.routing,
+		OnFailure:  i.onFailure,
+	}
+	body, err := json.Marshal(i.body)
+	if err == nil {
+		item.Body = bytes.NewReader(body)
+		err = i.bulkIndexer.Add(context.Background(), item)
+	}
+	if err != nil && i.onFailure != nil {
+		i.onFailure(context.Background(), item, esutil.BulkIndexerResponseItem{}, err)
+	}
+}
+
+// SearchServiceWrapperV8 implements es.SearchService.
+type SearchServiceWrapperV8 struct {
+	client             *esV8.Client
+	indices            []string
+	source             *elastic.SearchSource
+	routing            []string
+	ignoreUnavailable  *bool
+	restTotalHitsAsInt bool
+}
+
+// Size sets the number of hits returned.
+func (s SearchServiceWrapperV8) Size(size int) es.SearchService {
+	s.source = s.source.Size(size)
+	return s
+}
+
+// Aggregation adds an aggregation to the search.
+func (s SearchServiceWrapperV8) Aggregation(name string, aggregation elastic.Aggregation) es.SearchService {
+	s.source = s.source.Aggregation(name, aggregation)
+	return s
+}
+
+// IgnoreUnavailable sets whether the missing indices are ignored.
+func (s SearchServiceWrapperV8) IgnoreUnavailable(ignoreUnavailable bool) es.SearchService {
+	s.ignoreUnavailable = &ignoreUnavailable
+	return s
+}
+
+// Query sets the query of the search.
+func (s SearchServiceWrapperV8) Query(query elastic.Query) es.SearchService {
+	s.source = s.source.Query(query)
+	return s
+}
+
+// Routing sets the routing of the search.
+func (s SearchServiceWrapperV8) Routing(routings ...string) es.SearchService {
+	s.routing = routings
+	return s
+}
+
+// Do runs the search.
+func (s SearchServiceWrapperV8) Do(ctx context.Context) (*elastic.SearchResult, error) {
+	source, err := s.source.Source()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+	req := esV8api.SearchRequest{
+		Index:             s.indices,
+		Body:              bytes.NewReader(body),
+		Routing:           s.routing,
+		IgnoreUnavailable: s.ignoreUnavailable,
+	}
+	if s.restTotalHitsAsInt {
+		req.RestTotalHitsAsInt = &s.restTotalHitsAsInt
+	}
+	res, err := req.Do(ctx, s.client)
+	var result elastic.SearchResult
+	if err := decodeResponse(res, err, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
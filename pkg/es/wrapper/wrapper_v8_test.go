@@ -0,0 +1,500 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package eswrapper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	esV8 "github.com/elastic/go-elasticsearch/v8"
+	esV8api "github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/olivere/elastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is a request received by the test server.
+type recordedRequest struct {
+	method string
+	path   string
+	query  map[string]string
+	body   string
+}
+
+// testServer is an Elasticsearch cluster answering the requests with the handler.
+type testServer struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	// close closes the client once, flushing its bulk indexer.
+	close func() error
+}
+
+func (s *testServer) recorded() []recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest(nil), s.requests...)
+}
+
+func (s *testServer) last(t *testing.T) recordedRequest {
+	requests := s.recorded()
+	require.NotEmpty(t, requests)
+	return requests[len(requests)-1]
+}
+
+// newTestClientV8 returns a ClientWrapperV8 of the esVersion sending its requests to a server
+// answering them with handler, and the server.
+func newTestClientV8(t *testing.T, esVersion uint, onFailure BulkFailureFunc, handler http.HandlerFunc) (ClientWrapperV8, *testServer) {
+	ts := &testServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		query := map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		ts.mu.Lock()
+		ts.requests = append(ts.requests, recordedRequest{method: r.Method, path: r.URL.Path, query: query, body: string(body)})
+		ts.mu.Unlock()
+		// the official client checks that it is connected to Elasticsearch
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	transport := &http.Transport{}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	client, err := esV8.NewClient(esV8.Config{
+		Addresses:    []string{srv.URL},
+		DisableRetry: true,
+		Transport:    transport,
+	})
+	require.NoError(t, err)
+	bulkIndexer, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:        client,
+		NumWorkers:    1,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	c, err := WrapESClientV8(client, bulkIndexer, esVersion, onFailure)
+	require.NoError(t, err)
+	ts.close = sync.OnceValue(c.Close)
+	t.Cleanup(func() {
+		require.NoError(t, ts.close())
+	})
+	return c, ts
+}
+
+func respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+const errorBody = `{"error":{"type":"index_not_found_exception","reason":"no such index [jaeger-span]"},"status":404}`
+
+func requireElasticError(t *testing.T, err error, status int, errType string) {
+	var e *elastic.Error
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, status, e.Status)
+	if errType == "" {
+		assert.Nil(t, e.Details)
+		return
+	}
+	require.NotNil(t, e.Details)
+	assert.Equal(t, errType, e.Details.Type)
+}
+
+func TestClientWrapperV8Ping(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK, ""))
+	require.NoError(t, c.Ping(context.Background()))
+	assert.Equal(t, http.MethodHead, ts.last(t).method)
+	assert.Equal(t, "/", ts.last(t).path)
+	assert.EqualValues(t, 8, c.GetVersion())
+
+	c, _ = newTestClientV8(t, 8, nil, respond(http.StatusServiceUnavailable, ""))
+	requireElasticError(t, c.Ping(context.Background()), http.StatusServiceUnavailable, "")
+}
+
+func TestIndicesExistsServiceWrapperV8(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		exists bool
+	}{
+		{name: "exists", status: http.StatusOK, exists: true},
+		{name: "missing", status: http.StatusNotFound},
+		// the responses of the HEAD requests have no body
+		{name: "forbidden", status: http.StatusForbidden},
+		{name: "error", status: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, ts := newTestClientV8(t, 8, nil, respond(test.status, ""))
+			exists, err := c.IndexExists("jaeger-span-2024-01-02").Do(context.Background())
+			assert.Equal(t, http.MethodHead, ts.last(t).method)
+			assert.Equal(t, "/jaeger-span-2024-01-02", ts.last(t).path)
+			if test.status != http.StatusOK && test.status != http.StatusNotFound {
+				requireElasticError(t, err, test.status, "")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exists, exists)
+		})
+	}
+}
+
+func TestIndicesCreateServiceWrapperV8(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK, `{"acknowledged":true,"shards_acknowledged":true,"index":"jaeger-span"}`))
+	result, err := c.CreateIndex("jaeger-span").Body(`{"mappings":{}}`).Do(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Acknowledged)
+	assert.Equal(t, "jaeger-span", result.Index)
+	req := ts.last(t)
+	assert.Equal(t, http.MethodPut, req.method)
+	assert.Equal(t, "/jaeger-span", req.path)
+	assert.Equal(t, `{"mappings":{}}`, req.body)
+
+	c, ts = newTestClientV8(t, 8, nil, respond(http.StatusOK, `{"acknowledged":true}`))
+	_, err = c.CreateIndex("jaeger-span").Do(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, ts.last(t).body)
+
+	c, _ = newTestClientV8(t, 8, nil, respond(http.StatusBadRequest,
+		`{"error":{"type":"resource_already_exists_exception","reason":"index [jaeger-span] already exists"},"status":400}`))
+	_, err = c.CreateIndex("jaeger-span").Do(context.Background())
+	requireElasticError(t, err, http.StatusBadRequest, "resource_already_exists_exception")
+}
+
+func TestIndicesDeleteServiceWrapperV8(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK, `{"acknowledged":true}`))
+	result, err := c.DeleteIndex("jaeger-span").Do(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Acknowledged)
+	assert.Equal(t, http.MethodDelete, ts.last(t).method)
+	assert.Equal(t, "/jaeger-span", ts.last(t).path)
+
+	c, _ = newTestClientV8(t, 8, nil, respond(http.StatusNotFound, errorBody))
+	_, err = c.DeleteIndex("jaeger-span").Do(context.Background())
+	requireElasticError(t, err, http.StatusNotFound, "index_not_found_exception")
+}
+
+func TestTemplateCreatorWrapperV8(t *testing.T) {
+	tests := []struct {
+		esVersion uint
+		path      string
+	}{
+		{esVersion: 7, path: "/_template/jaeger-span"},
+		{esVersion: 8, path: "/_index_template/jaeger-span"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			c, ts := newTestClientV8(t, test.esVersion, nil, respond(http.StatusOK, `{"acknowledged":true}`))
+			_, err := c.CreateTemplate("jaeger-span").Body(`{"index_patterns":["*jaeger-span-*"]}`).Do(context.Background())
+			require.NoError(t, err)
+			req := ts.last(t)
+			assert.Equal(t, http.MethodPut, req.method)
+			assert.Equal(t, test.path, req.path)
+			assert.Equal(t, `{"index_patterns":["*jaeger-span-*"]}`, req.body)
+		})
+	}
+
+	c, _ := newTestClientV8(t, 8, nil, respond(http.StatusBadRequest, `{"error":{"type":"illegal_argument_exception"}}`))
+	_, err := c.CreateTemplate("jaeger-span").Body("{}").Do(context.Background())
+	require.ErrorContains(t, err, "error creating index template jaeger-span")
+}
+
+const searchResponse = `{"hits":{"total":2,"hits":[{"_index":"jaeger-span","_id":"1","_source":{"traceID":"1"}},{"_index":"jaeger-span","_id":"2","_source":{"traceID":"2"}}]},` +
+	`"aggregations":{"traceIDs":{"buckets":[{"key":"1","doc_count":1}]}}}`
+
+func TestSearchServiceWrapperV8(t *testing.T) {
+	tests := []struct {
+		esVersion          uint
+		restTotalHitsAsInt string
+	}{
+		{esVersion: 6},
+		{esVersion: 7, restTotalHitsAsInt: "true"},
+		{esVersion: 8, restTotalHitsAsInt: "true"},
+	}
+	for _, test := range tests {
+		t.Run(test.restTotalHitsAsInt, func(t *testing.T) {
+			c, ts := newTestClientV8(t, test.esVersion, nil, respond(http.StatusOK, searchResponse))
+			result, err := c.Search("jaeger-span-1", "jaeger-span-2").
+				Size(0).
+				Query(elastic.NewTermQuery("traceID", "1")).
+				Aggregation("traceIDs", elastic.NewTermsAggregation().Field("traceID")).
+				IgnoreUnavailable(true).
+				Routing("1", "2").
+				Do(context.Background())
+			require.NoError(t, err)
+			assert.EqualValues(t, 2, result.TotalHits())
+			require.Len(t, result.Hits.Hits, 2)
+			assert.Equal(t, "2", result.Hits.Hits[1].Id)
+			agg, found := result.Aggregations.Terms("traceIDs")
+			require.True(t, found)
+			require.Len(t, agg.Buckets, 1)
+
+			req := ts.last(t)
+			assert.Equal(t, "/jaeger-span-1,jaeger-span-2/_search", req.path)
+			assert.Equal(t, "true", req.query["ignore_unavailable"])
+			assert.Equal(t, "1,2", req.query["routing"])
+			assert.Equal(t, test.restTotalHitsAsInt, req.query["rest_total_hits_as_int"])
+			assert.JSONEq(t, `{"size":0,"query":{"term":{"traceID":"1"}},"aggregations":{"traceIDs":{"terms":{"field":"traceID"}}}}`, req.body)
+		})
+	}
+
+	c, _ := newTestClientV8(t, 8, nil, respond(http.StatusNotFound, errorBody))
+	_, err := c.Search("jaeger-span").Do(context.Background())
+	requireElasticError(t, err, http.StatusNotFound, "index_not_found_exception")
+
+	c, _ = newTestClientV8(t, 8, nil, respond(http.StatusOK, `{"hits":`))
+	_, err = c.Search("jaeger-span").Do(context.Background())
+	require.Error(t, err)
+}
+
+func TestMultiSearchServiceWrapperV8(t *testing.T) {
+	tests := []struct {
+		esVersion          uint
+		restTotalHitsAsInt string
+	}{
+		{esVersion: 6},
+		{esVersion: 7, restTotalHitsAsInt: "true"},
+	}
+	for _, test := range tests {
+		t.Run(test.restTotalHitsAsInt, func(t *testing.T) {
+			c, ts := newTestClientV8(t, test.esVersion, nil, respond(http.StatusOK,
+				`{"responses":[{"hits":{"total":1,"hits":[{"_id":"1"}]}},{"hits":{"total":0,"hits":[]}}]}`))
+			result, err := c.MultiSearch().
+				Add(elastic.NewSearchRequest().Index("jaeger-span-1").Source(elastic.NewSearchSource().Size(1)),
+					elastic.NewSearchRequest().Index("jaeger-span-2")).
+				Index("jaeger-span-1", "jaeger-span-2").
+				Do(context.Background())
+			require.NoError(t, err)
+			require.Len(t, result.Responses, 2)
+			assert.EqualValues(t, 1, result.Responses[0].TotalHits())
+			assert.EqualValues(t, 0, result.Responses[1].TotalHits())
+
+			req := ts.last(t)
+			assert.Equal(t, "/_msearch", req.path)
+			assert.Equal(t, test.restTotalHitsAsInt, req.query["rest_total_hits_as_int"])
+			lines := strings.Split(strings.TrimSuffix(req.body, "\n"), "\n")
+			require.Len(t, lines, 4)
+			assert.JSONEq(t, `{"index":"jaeger-span-1"}`, lines[0])
+			assert.JSONEq(t, `{"size":1}`, lines[1])
+			assert.JSONEq(t, `{"index":"jaeger-span-2"}`, lines[2])
+		})
+	}
+
+	c, _ := newTestClientV8(t, 8, nil, respond(http.StatusBadRequest, `{"error":{"type":"parsing_exception"},"status":400}`))
+	_, err := c.MultiSearch().Add(elastic.NewSearchRequest()).Do(context.Background())
+	requireElasticError(t, err, http.StatusBadRequest, "parsing_exception")
+}
+
+// bulkLines returns the JSON lines of the bulk requests.
+func bulkLines(t *testing.T, ts *testServer) []map[string]any {
+	var lines []map[string]any
+	for _, req := range ts.recorded() {
+		if req.path != "/_bulk" {
+			continue
+		}
+		scanner := bufio.NewScanner(strings.NewReader(req.body))
+		for scanner.Scan() {
+			var line map[string]any
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestIndexServiceWrapperV8(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK,
+		`{"errors":false,"items":[{"index":{"_index":"jaeger-span","_id":"1","status":201}},{"index":{"_index":"jaeger-service","status":201}}]}`))
+	c.Index().Index("jaeger-span").Type("span").Id("1").Routing("trace").BodyJson(map[string]string{"traceID": "1"}).Add()
+	c.Index().Index("jaeger-service").BodyJson(map[string]string{"serviceName": "frontend"}).Add()
+	require.NoError(t, ts.close())
+
+	assert.Equal(t, []map[string]any{
+		{"index": map[string]any{"_index": "jaeger-span", "_id": "1", "routing": "trace"}},
+		{"traceID": "1"},
+		{"index": map[string]any{"_index": "jaeger-service"}},
+		{"serviceName": "frontend"},
+	}, bulkLines(t, ts))
+}
+
+// bulkFailures records the failures of the bulk indexer.
+type bulkFailures struct {
+	mu       sync.Mutex
+	items    []esutil.BulkIndexerItem
+	response []esutil.BulkIndexerResponseItem
+	errs     []error
+}
+
+func (f *bulkFailures) onFailure(_ context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items = append(f.items, item)
+	f.response = append(f.response, res)
+	f.errs = append(f.errs, err)
+}
+
+func TestIndexServiceWrapperV8Failure(t *testing.T) {
+	failures := &bulkFailures{}
+	c, ts := newTestClientV8(t, 8, failures.onFailure, respond(http.StatusOK,
+		`{"errors":true,"items":[{"index":{"_index":"jaeger-span","status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+	c.Index().Index("jaeger-span").BodyJson(map[string]string{"traceID": "1"}).Add()
+	require.NoError(t, ts.close())
+
+	failures.mu.Lock()
+	defer failures.mu.Unlock()
+	require.Len(t, failures.items, 1)
+	assert.Equal(t, "jaeger-span", failures.items[0].Index)
+	assert.Equal(t, http.StatusBadRequest, failures.response[0].Status)
+	assert.Equal(t, "mapper_parsing_exception", failures.response[0].Error.Type)
+	assert.NoError(t, failures.errs[0])
+}
+
+func TestIndexServiceWrapperV8InvalidBody(t *testing.T) {
+	failures := &bulkFailures{}
+	c, ts := newTestClientV8(t, 8, failures.onFailure, respond(http.StatusOK, `{"errors":false,"items":[]}`))
+	c.Index().Index("jaeger-span").BodyJson(make(chan int)).Add()
+	require.NoError(t, ts.close())
+
+	failures.mu.Lock()
+	defer failures.mu.Unlock()
+	require.Len(t, failures.errs, 1)
+	var jsonErr *json.UnsupportedTypeError
+	require.ErrorAs(t, failures.errs[0], &jsonErr)
+	assert.Empty(t, bulkLines(t, ts))
+}
+
+func TestBulkServiceWrapperV8(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK,
+		`{"errors":true,"items":[{"index":{"_index":"jaeger-span","_id":"1","status":201}},{"index":{"_index":"jaeger-span","status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+	res, err := c.Bulk().
+		Add(elastic.NewBulkIndexRequest().Index("jaeger-span").Type("span").Id("1").Routing("frontend").Doc(map[string]string{"traceID": "1"})).
+		Add(elastic.NewBulkIndexRequest().Index("jaeger-span").Doc(map[string]string{"traceID": "2"})).
+		Do(context.Background())
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	failed := res.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "mapper_parsing_exception", failed[0].Error.Type)
+
+	// the bulk request is sent at once, the types being removed
+	assert.Equal(t, []map[string]any{
+		{"index": map[string]any{"_index": "jaeger-span", "_id": "1", "routing": "frontend"}},
+		{"traceID": "1"},
+		{"index": map[string]any{"_index": "jaeger-span"}},
+		{"traceID": "2"},
+	}, bulkLines(t, ts))
+
+	c, _ = newTestClientV8(t, 8, nil, respond(http.StatusNotFound, errorBody))
+	_, err = c.Bulk().Add(elastic.NewBulkIndexRequest().Index("jaeger-span").Doc(map[string]string{})).Do(context.Background())
+	requireElasticError(t, err, http.StatusNotFound, "index_not_found_exception")
+}
+
+func TestClientWrapperV8PointInTime(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK, `{"id":"pit-1"}`))
+	id, err := c.OpenPointInTime(context.Background(), "1m", "jaeger-span-1", "jaeger-span-2")
+	require.NoError(t, err)
+	assert.Equal(t, "pit-1", id)
+	req := ts.last(t)
+	assert.Equal(t, http.MethodPost, req.method)
+	assert.Equal(t, "/jaeger-span-1,jaeger-span-2/_pit", req.path)
+	assert.Equal(t, map[string]string{"keep_alive": "1m", "ignore_unavailable": "true"}, req.query)
+
+	require.NoError(t, c.ClosePointInTime(context.Background(), "pit-1"))
+	req = ts.last(t)
+	assert.Equal(t, http.MethodDelete, req.method)
+	assert.Equal(t, "/_pit", req.path)
+	assert.JSONEq(t, `{"id":"pit-1"}`, req.body)
+
+	c, _ = newTestClientV8(t, 8, nil, respond(http.StatusNotFound, errorBody))
+	_, err = c.OpenPointInTime(context.Background(), "1m", "jaeger-span")
+	requireElasticError(t, err, http.StatusNotFound, "index_not_found_exception")
+}
+
+func TestClientWrapperV8AsyncSearch(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK,
+		`{"id":"search-1","is_running":false,"response":{"hits":{"total":1,"hits":[{"_id":"1"}]}}}`))
+	result, err := c.AsyncSearch("jaeger-span").
+		Source(elastic.NewSearchSource().Size(1)).
+		Routing("1").
+		WaitForCompletionTimeout(2 * time.Second).
+		Do(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.TotalHits())
+	req := ts.last(t)
+	assert.Equal(t, "/jaeger-span/_async_search", req.path)
+	assert.Equal(t, "2000ms", req.query["wait_for_completion_timeout"])
+	assert.Equal(t, "1", req.query["routing"])
+	assert.JSONEq(t, `{"size":1}`, req.body)
+}
+
+func TestDecodeResponse(t *testing.T) {
+	response := func(status int, body string) *esV8api.Response {
+		return &esV8api.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+	}
+	requestErr := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		res      *esV8api.Response
+		err      error
+		expected map[string]any
+		status   int
+		errType  string
+		errMsg   string
+	}{
+		{name: "request error", err: requestErr, errMsg: requestErr.Error()},
+		{name: "body", res: response(http.StatusOK, `{"acknowledged":true}`), expected: map[string]any{"acknowledged": true}},
+		{name: "empty body", res: response(http.StatusOK, ""), expected: map[string]any{}},
+		{name: "invalid body", res: response(http.StatusOK, `{"acknowledged"`), errMsg: "unexpected end of JSON input"},
+		{name: "error body", res: response(http.StatusNotFound, errorBody), status: http.StatusNotFound, errType: "index_not_found_exception"},
+		{name: "error without JSON body", res: response(http.StatusBadGateway, "<html>Bad Gateway</html>"), status: http.StatusBadGateway},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := map[string]any{}
+			err := decodeResponse(test.res, test.err, &v)
+			switch {
+			case test.errMsg != "":
+				require.ErrorContains(t, err, test.errMsg)
+			case test.status != 0:
+				requireElasticError(t, err, test.status, test.errType)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, v)
+			}
+		})
+	}
+
+	// the body is only read if v is nil
+	require.NoError(t, decodeResponse(response(http.StatusOK, `not JSON`), nil, nil))
+}
+
+func TestTransportV8(t *testing.T) {
+	c, ts := newTestClientV8(t, 8, nil, respond(http.StatusOK, `{"ok":true}`))
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:9200/_cluster/health", bytes.NewReader(nil))
+	require.NoError(t, err)
+	res, err := transportV8{client: c.client}.RoundTrip(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	// the request is sent to the node of the official client
+	assert.Equal(t, "/_cluster/health", ts.last(t).path)
+	assert.Equal(t, "http://127.0.0.1:9200/_cluster/health", req.URL.String())
+}
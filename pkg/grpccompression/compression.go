@@ -0,0 +1,103 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grpccompression selects the compression of the messages sent over the gRPC
+// connections and reports the size of the messages before and after compression.
+package grpccompression
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	// the compressors must be registered on both ends: the servers decompress the messages
+	// with the compressor named by the client and compress the responses with it.
+	_ "github.com/mostynb/go-grpc-compression/nonclobbering/snappy"
+	_ "github.com/mostynb/go-grpc-compression/nonclobbering/zstd"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const (
+	// None disables the compression.
+	None = "none"
+	// Gzip compresses the messages with gzip.
+	Gzip = "gzip"
+	// Snappy compresses the messages with snappy.
+	Snappy = "snappy"
+	// Zstd compresses the messages with zstd.
+	Zstd = "zstd"
+)
+
+// Compressors lists the supported compressors.
+var Compressors = []string{None, Gzip, Snappy, Zstd}
+
+// Validate returns an error if the compressor is not supported.
+func Validate(compressor string) error {
+	if compressor != "" && !slices.Contains(Compressors, compressor) {
+		return fmt.Errorf("unsupported gRPC compression %q, expected one of %s", compressor, strings.Join(Compressors, ", "))
+	}
+	return nil
+}
+
+// DialOption returns the option of a client connection compressing its messages with the compressor.
+func DialOption(compressor string) grpc.DialOption {
+	if compressor == "" || compressor == None {
+		return grpc.EmptyDialOption{}
+	}
+	return grpc.WithDefaultCallOptions(grpc.UseCompressor(compressor))
+}
+
+type payloadMetrics struct {
+	// Size of the received messages once decompressed
+	ReceivedBytes metrics.Counter `metric:"grpc.payload_bytes" tags:"direction=received,encoding=uncompressed"`
+	// Size of the received messages as sent over the wire
+	ReceivedCompressedBytes metrics.Counter `metric:"grpc.payload_bytes" tags:"direction=received,encoding=compressed"`
+	// Size of the sent messages before compression
+	SentBytes metrics.Counter `metric:"grpc.payload_bytes" tags:"direction=sent,encoding=uncompressed"`
+	// Size of the sent messages as sent over the wire
+	SentCompressedBytes metrics.Counter `metric:"grpc.payload_bytes" tags:"direction=sent,encoding=compressed"`
+}
+
+// statsHandler is a stats.Handler counting the bytes of the messages before and after compression.
+type statsHandler struct {
+	metrics payloadMetrics
+}
+
+// NewStatsHandler creates a stats.Handler reporting the size of the messages of the
+// connections before and after compression, to be installed with grpc.WithStatsHandler
+// on clients and grpc.StatsHandler on servers.
+func NewStatsHandler(mFactory metrics.Factory) stats.Handler {
+	h := &statsHandler{}
+	metrics.MustInit(&h.metrics, mFactory, nil)
+	return h
+}
+
+// TagRPC implements stats.Handler.
+func (*statsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler.
+func (h *statsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch payload := s.(type) {
+	case *stats.InPayload:
+		h.metrics.ReceivedBytes.Inc(int64(payload.Length))
+		h.metrics.ReceivedCompressedBytes.Inc(int64(payload.CompressedLength))
+	case *stats.OutPayload:
+		h.metrics.SentBytes.Inc(int64(payload.Length))
+		h.metrics.SentCompressedBytes.Inc(int64(payload.CompressedLength))
+	}
+}
+
+// TagConn implements stats.Handler.
+func (*statsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler.
+func (*statsHandler) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,54 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpccompression
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+)
+
+func TestValidate(t *testing.T) {
+	for _, compressor := range append(Compressors, "") {
+		require.NoError(t, Validate(compressor))
+	}
+	require.EqualError(t, Validate("brotli"), `unsupported gRPC compression "brotli", expected one of none, gzip, snappy, zstd`)
+}
+
+func TestCompressorsRegistered(t *testing.T) {
+	for _, compressor := range Compressors[1:] {
+		assert.NotNil(t, encoding.GetCompressor(compressor), compressor)
+	}
+}
+
+func TestDialOption(t *testing.T) {
+	assert.Equal(t, grpc.EmptyDialOption{}, DialOption(""))
+	assert.Equal(t, grpc.EmptyDialOption{}, DialOption(None))
+	assert.NotEqual(t, grpc.EmptyDialOption{}, DialOption(Zstd))
+}
+
+func TestStatsHandler(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	h := NewStatsHandler(metricsFactory)
+	ctx := h.TagRPC(context.Background(), &stats.RPCTagInfo{})
+	ctx = h.TagConn(ctx, &stats.ConnTagInfo{})
+	h.HandleConn(ctx, &stats.ConnBegin{})
+	h.HandleRPC(ctx, &stats.InPayload{Length: 100, CompressedLength: 40})
+	h.HandleRPC(ctx, &stats.OutPayload{Length: 10, CompressedLength: 12})
+	h.HandleRPC(ctx, &stats.End{})
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "grpc.payload_bytes", Tags: map[string]string{"direction": "received", "encoding": "uncompressed"}, Value: 100},
+		metricstest.ExpectedMetric{Name: "grpc.payload_bytes", Tags: map[string]string{"direction": "received", "encoding": "compressed"}, Value: 40},
+		metricstest.ExpectedMetric{Name: "grpc.payload_bytes", Tags: map[string]string{"direction": "sent", "encoding": "uncompressed"}, Value: 10},
+		metricstest.ExpectedMetric{Name: "grpc.payload_bytes", Tags: map[string]string{"direction": "sent", "encoding": "compressed"}, Value: 12},
+	)
+}
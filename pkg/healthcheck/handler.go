@@ -67,6 +67,7 @@ type HealthCheck struct {
 	state     atomic.Value // stores state struct
 	logger    *zap.Logger
 	responses map[Status]healthCheckResponse
+	probes    probes
 }
 
 // New creates a HealthCheck with the specified initial state.
@@ -83,6 +84,10 @@ func New() *HealthCheck {
 				StatusMsg:  "Server available",
 			},
 		},
+		probes: probes{
+			probes: make(map[string]Probe),
+			stopCh: make(chan struct{}),
+		},
 	}
 	hc.state.Store(state{status: Unavailable})
 	return hc
@@ -0,0 +1,174 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Probe checks a dependency of the service, like its storage backend,
+// returning an error when the dependency is not available.
+type Probe func(ctx context.Context) error
+
+type probes struct {
+	mu     sync.RWMutex
+	probes map[string]Probe
+	errors map[string]error // the errors of the failing probes at their last check
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	stopWG   sync.WaitGroup
+}
+
+type readinessResponse struct {
+	StatusMsg string            `json:"status"`
+	Probes    map[string]string `json:"probes,omitempty"`
+}
+
+// AddProbe registers a probe of a dependency named name. The service is not
+// ready while one of its probes fails.
+func (hc *HealthCheck) AddProbe(name string, probe Probe) {
+	hc.probes.mu.Lock()
+	defer hc.probes.mu.Unlock()
+	hc.probes.probes[name] = probe
+}
+
+// StartProbing checks the probes now and then every interval until StopProbing,
+// each probe failing unless it succeeds within the timeout.
+func (hc *HealthCheck) StartProbing(interval time.Duration, timeout time.Duration) {
+	hc.probes.stopWG.Add(1)
+	go func() {
+		defer hc.probes.stopWG.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			hc.checkProbes(timeout)
+			select {
+			case <-ticker.C:
+			case <-hc.probes.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// StopProbing stops checking the probes.
+func (hc *HealthCheck) StopProbing() {
+	hc.probes.stopOnce.Do(func() {
+		close(hc.probes.stopCh)
+	})
+	hc.probes.stopWG.Wait()
+}
+
+func (hc *HealthCheck) checkProbes(timeout time.Duration) {
+	hc.probes.mu.RLock()
+	probes := make(map[string]Probe, len(hc.probes.probes))
+	for name, probe := range hc.probes.probes {
+		probes[name] = probe
+	}
+	hc.probes.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[string]error)
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, probe Probe) {
+			defer wg.Done()
+			if err := runProbe(probe, timeout); err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name, probe)
+	}
+	wg.Wait()
+
+	hc.probes.mu.Lock()
+	defer hc.probes.mu.Unlock()
+	for name := range probes {
+		previous, next := hc.probes.errors[name], errs[name]
+		if next != nil && previous == nil {
+			hc.logger.Warn("Health check probe failed, the service is not ready", zap.String("probe", name), zap.Error(next))
+		} else if next == nil && previous != nil {
+			hc.logger.Info("Health check probe recovered", zap.String("probe", name))
+		}
+	}
+	hc.probes.errors = errs
+}
+
+// runProbe runs the probe, giving up on it after the timeout even if it does not
+// respect the cancellation of its context.
+func runProbe(probe Probe, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- probe(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsReady returns whether the service is Ready and all its probes succeed.
+func (hc *HealthCheck) IsReady() bool {
+	hc.probes.mu.RLock()
+	defer hc.probes.mu.RUnlock()
+	return hc.Get() == Ready && len(hc.probes.errors) == 0
+}
+
+// LivenessHandler creates an HTTP handler responding whether the service is alive,
+// i.e. it is not Broken, whether it is ready or not.
+func (hc *HealthCheck) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status := hc.Get()
+		statusCode := http.StatusOK
+		if status == Broken {
+			statusCode = http.StatusServiceUnavailable
+		}
+		writeJSON(w, statusCode, readinessResponse{StatusMsg: status.String()})
+	})
+}
+
+// ReadinessHandler creates an HTTP handler responding whether the service is ready,
+// with the result of each probe.
+func (hc *HealthCheck) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		resp := readinessResponse{StatusMsg: hc.Get().String()}
+		hc.probes.mu.RLock()
+		if len(hc.probes.probes) > 0 {
+			resp.Probes = make(map[string]string, len(hc.probes.probes))
+			for name := range hc.probes.probes {
+				resp.Probes[name] = "ok"
+				if err := hc.probes.errors[name]; err != nil {
+					resp.Probes[name] = err.Error()
+				}
+			}
+		}
+		hc.probes.mu.RUnlock()
+
+		statusCode := http.StatusOK
+		if !hc.IsReady() {
+			statusCode = http.StatusServiceUnavailable
+		}
+		writeJSON(w, statusCode, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, resp readinessResponse) {
+	body, _ := json.Marshal(resp)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getStatus(t *testing.T, handler http.Handler) (int, readinessResponse) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var resp readinessResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestReadinessProbes(t *testing.T) {
+	hc := New()
+	hc.Ready()
+	var storageErr atomic.Pointer[error]
+	hc.AddProbe("storage", func(context.Context) error {
+		if err := storageErr.Load(); err != nil {
+			return *err
+		}
+		return nil
+	})
+
+	hc.checkProbes(time.Second)
+	code, resp := getStatus(t, hc.ReadinessHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, readinessResponse{StatusMsg: "ready", Probes: map[string]string{"storage": "ok"}}, resp)
+
+	err := errors.New("connection refused")
+	storageErr.Store(&err)
+	hc.checkProbes(time.Second)
+	assert.False(t, hc.IsReady())
+	code, resp = getStatus(t, hc.ReadinessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, map[string]string{"storage": "connection refused"}, resp.Probes)
+
+	// the service is still alive while its storage is unreachable
+	code, resp = getStatus(t, hc.LivenessHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", resp.StatusMsg)
+	// and the legacy health check does not depend on the probes
+	rec := httptest.NewRecorder()
+	hc.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	storageErr.Store(nil)
+	hc.checkProbes(time.Second)
+	assert.True(t, hc.IsReady())
+}
+
+func TestReadinessNotReady(t *testing.T) {
+	hc := New()
+	code, resp := getStatus(t, hc.ReadinessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, readinessResponse{StatusMsg: "unavailable"}, resp)
+
+	hc.Set(Broken)
+	code, _ = getStatus(t, hc.LivenessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestProbeTimeout(t *testing.T) {
+	hc := New()
+	hc.Ready()
+	release := make(chan struct{})
+	defer close(release)
+	// the probe ignores the cancellation of its context
+	hc.AddProbe("storage", func(context.Context) error {
+		<-release
+		return nil
+	})
+	hc.checkProbes(time.Millisecond)
+	assert.False(t, hc.IsReady())
+	_, resp := getStatus(t, hc.ReadinessHandler())
+	assert.Equal(t, map[string]string{"storage": context.DeadlineExceeded.Error()}, resp.Probes)
+}
+
+func TestStartProbing(t *testing.T) {
+	hc := New()
+	hc.Ready()
+	var checks atomic.Int32
+	hc.AddProbe("storage", func(context.Context) error {
+		checks.Add(1)
+		return errors.New("connection refused")
+	})
+	hc.StartProbing(time.Millisecond, time.Second)
+	assert.Eventually(t, func() bool { return checks.Load() > 1 }, time.Second, time.Millisecond)
+	hc.StopProbing()
+	hc.StopProbing()
+	assert.False(t, hc.IsReady())
+}
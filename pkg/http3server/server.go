@@ -0,0 +1,72 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package http3server serves HTTP/3 (QUIC) next to the HTTP/1 and HTTP/2 servers, for the SDKs
+// of the mobile and edge devices on lossy networks, where QUIC delivers the requests better.
+package http3server
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Server serves the HTTP/3 requests of a handler on a UDP socket.
+type Server struct {
+	server *http3.Server
+	conn   net.PacketConn
+}
+
+// Listen announces on the UDP host:port address and returns the HTTP/3 server of the handler,
+// to be started with Serve. The network is the Go TCP network of the HTTP server it complements,
+// e.g. tcp4, the socket having the same IP family. HTTP/3 is always secure, so the tlsConfig is
+// required.
+func Listen(network, hostPort string, tlsConfig *tls.Config, handler http.Handler) (*Server, error) {
+	if tlsConfig == nil {
+		return nil, errors.New("HTTP/3 requires TLS")
+	}
+	conn, err := net.ListenPacket("udp"+strings.TrimPrefix(network, "tcp"), hostPort)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		server: &http3.Server{
+			TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+			Handler:   handler,
+		},
+		conn: conn,
+	}, nil
+}
+
+// Addr returns the address of the UDP socket of the server.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Serve serves the requests until the server is closed, when it returns nil.
+func (s *Server) Serve() error {
+	err := s.server.Serve(s.conn)
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// AltSvcHandler returns the handler of the HTTP/1 and HTTP/2 server announcing the server in the
+// Alt-Svc header of its responses, for the clients to upgrade to HTTP/3.
+func (s *Server) AltSvcHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// there is nothing to announce before the server serves
+		_ = s.server.SetQUICHeaders(w.Header())
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Close stops the server and closes its UDP socket, which the QUIC listener does not own.
+func (s *Server) Close() error {
+	return errors.Join(s.server.Close(), s.conn.Close())
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package http3server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCertKeyLocation = "../config/tlscfg/testdata"
+
+func serverTLSConfig(t *testing.T) *tls.Config {
+	cert, err := tls.LoadX509KeyPair(testCertKeyLocation+"/example-server-cert.pem", testCertKeyLocation+"/example-server-key.pem")
+	require.NoError(t, err)
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS13}
+}
+
+func clientTLSConfig(t *testing.T) *tls.Config {
+	ca, err := os.ReadFile(testCertKeyLocation + "/example-CA-cert.pem")
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(ca))
+	return &tls.Config{RootCAs: pool, ServerName: "example.com", MinVersion: tls.VersionTLS13}
+}
+
+func TestServer(t *testing.T) {
+	server, err := Listen("tcp4", "127.0.0.1:0", serverTLSConfig(t), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "udp", server.Addr().Network())
+	done := make(chan error)
+	go func() { done <- server.Serve() }()
+
+	roundTripper := &http3.RoundTripper{TLSClientConfig: clientTLSConfig(t)}
+	defer roundTripper.Close()
+	response, err := (&http.Client{Transport: roundTripper}).Get("https://" + server.Addr().String())
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	assert.Equal(t, "HTTP/3.0", string(body))
+
+	recorder := httptest.NewRecorder()
+	server.AltSvcHandler(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, recorder.Header().Get("Alt-Svc"), "h3=")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	require.NoError(t, server.Close())
+	require.NoError(t, <-done)
+}
+
+func TestListenErrors(t *testing.T) {
+	_, err := Listen("tcp", ":0", nil, http.NotFoundHandler())
+	require.EqualError(t, err, "HTTP/3 requires TLS")
+
+	_, err = Listen("tcp4", "[::1]:0", serverTLSConfig(t), http.NotFoundHandler())
+	require.Error(t, err)
+}
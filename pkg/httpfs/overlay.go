@@ -0,0 +1,42 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+)
+
+// OverlayFS returns a FileSystem that serves the files of the overlay fs, falling back to the
+// underlying fs for the files missing from the overlay. The directories are always those of
+// the underlying fs if it has them, so that only the files of the overlay replace or extend it.
+func OverlayFS(overlay, fs http.FileSystem) http.FileSystem {
+	return &overlayFS{
+		overlay: overlay,
+		fs:      fs,
+	}
+}
+
+type overlayFS struct {
+	overlay http.FileSystem
+	fs      http.FileSystem
+}
+
+func (o *overlayFS) Open(name string) (http.File, error) {
+	file, err := o.overlay.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return o.fs.Open(name)
+		}
+		return nil, err
+	}
+	if stat, err := file.Stat(); err == nil && stat.IsDir() {
+		if baseFile, err := o.fs.Open(name); err == nil {
+			file.Close()
+			return baseFile, nil
+		}
+	}
+	return file, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingFS struct{}
+
+func (failingFS) Open(string) (http.File, error) {
+	return nil, errors.New("permission denied")
+}
+
+func TestOverlayFS(t *testing.T) {
+	fs := OverlayFS(http.Dir("test_overlay"), PrefixedFS("test_assets", http.FS(assetFS)))
+	tests := []struct {
+		file     string
+		isDir    bool
+		contents string
+	}{
+		{file: "/", isDir: true},
+		{file: "/somefile.txt", contents: "overlay\n"},
+		{file: "/newfile.txt", contents: "new\n"},
+		{file: "/sub", isDir: true},
+		{file: "/sub/file.txt", contents: "sub\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file, err := fs.Open(tt.file)
+			require.NoError(t, err)
+			defer file.Close()
+			stat, err := file.Stat()
+			require.NoError(t, err)
+			require.Equal(t, tt.isDir, stat.IsDir())
+			if !tt.isDir {
+				contents, err := io.ReadAll(file)
+				require.NoError(t, err)
+				assert.Equal(t, tt.contents, string(contents))
+			}
+		})
+	}
+}
+
+func TestOverlayFSErrors(t *testing.T) {
+	overlay := OverlayFS(http.Dir("test_overlay"), PrefixedFS("test_assets", http.FS(assetFS)))
+	_, err := overlay.Open("/missing.txt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	overlay = OverlayFS(failingFS{}, PrefixedFS("test_assets", http.FS(assetFS)))
+	_, err = overlay.Open("/somefile.txt")
+	require.EqualError(t, err, "permission denied")
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jtracer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanCapture records the spans ended by a tracer provider while a capture is in progress.
+// It is a span processor, and a sampler which only samples the spans during a capture,
+// so that a tracer provider without exporter does not create spans the rest of the time.
+type SpanCapture struct {
+	active atomic.Int32
+
+	mu    sync.Mutex
+	sinks map[*spanSink]struct{}
+}
+
+type spanSink struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+var (
+	_ sdktrace.SpanProcessor = (*SpanCapture)(nil)
+	_ sdktrace.Sampler       = (*SpanCapture)(nil)
+)
+
+// NewSpanCapture creates a SpanCapture.
+func NewSpanCapture() *SpanCapture {
+	return &SpanCapture{
+		sinks: make(map[*spanSink]struct{}),
+	}
+}
+
+// Capture returns the spans ended during the duration d, or until the context is done.
+// Concurrent captures all receive the spans they overlap with.
+func (c *SpanCapture) Capture(ctx context.Context, d time.Duration) []sdktrace.ReadOnlySpan {
+	sink := &spanSink{}
+	c.mu.Lock()
+	c.sinks[sink] = struct{}{}
+	c.mu.Unlock()
+	c.active.Add(1)
+
+	timer := time.NewTimer(d)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+
+	c.active.Add(-1)
+	c.mu.Lock()
+	delete(c.sinks, sink)
+	c.mu.Unlock()
+	return sink.spans
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (*SpanCapture) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (c *SpanCapture) OnEnd(span sdktrace.ReadOnlySpan) {
+	if c.active.Load() == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for sink := range c.sinks {
+		sink.spans = append(sink.spans, span)
+	}
+}
+
+// Shutdown implements sdktrace.SpanProcessor.
+func (*SpanCapture) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (*SpanCapture) ForceFlush(context.Context) error {
+	return nil
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (c *SpanCapture) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	if c.active.Load() > 0 {
+		result.Decision = sdktrace.RecordAndSample
+	}
+	return result
+}
+
+// Description implements sdktrace.Sampler.
+func (*SpanCapture) Description() string {
+	return "SpanCapture"
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jtracer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
+	ui "github.com/jaegertracing/jaeger/model/json"
+)
+
+const (
+	defaultCaptureSeconds = 5
+	maxCaptureSeconds     = 60
+)
+
+// capturedTraces has the shape of the responses of the query service HTTP API.
+type capturedTraces struct {
+	Data []*ui.Trace `json:"data"`
+}
+
+// NewCaptureHandler creates an HTTP handler capturing the spans of the tracer for the number of
+// seconds given by the "seconds" query parameter, like /debug/pprof/profile, and returning them
+// as Jaeger JSON traces.
+func NewCaptureHandler(capture *SpanCapture, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seconds := defaultCaptureSeconds
+		if s := r.URL.Query().Get("seconds"); s != "" {
+			var err error
+			seconds, err = strconv.Atoi(s)
+			if err != nil || seconds <= 0 || seconds > maxCaptureSeconds {
+				http.Error(w, fmt.Sprintf("seconds must be an integer between 1 and %d", maxCaptureSeconds), http.StatusBadRequest)
+				return
+			}
+		}
+		spans := capture.Capture(r.Context(), time.Duration(seconds)*time.Second)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(capturedTraces{Data: toUITraces(spans)}); err != nil {
+			logger.Error("Failed to write captured traces", zap.Error(err))
+		}
+	})
+}
+
+// toUITraces groups the spans by trace, in the order of their start time.
+func toUITraces(spans []sdktrace.ReadOnlySpan) []*ui.Trace {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].StartTime().Before(spans[j].StartTime())
+	})
+	traces := make(map[model.TraceID]*model.Trace)
+	var traceIDs []model.TraceID
+	for _, span := range spans {
+		s := toDomainSpan(span)
+		t, ok := traces[s.TraceID]
+		if !ok {
+			t = &model.Trace{}
+			traces[s.TraceID] = t
+			traceIDs = append(traceIDs, s.TraceID)
+		}
+		t.Spans = append(t.Spans, s)
+	}
+	result := make([]*ui.Trace, 0, len(traceIDs))
+	for _, traceID := range traceIDs {
+		result = append(result, uiconv.FromDomain(traces[traceID]))
+	}
+	return result
+}
+
+func toDomainSpan(span sdktrace.ReadOnlySpan) *model.Span {
+	traceID := toDomainTraceID(span.SpanContext().TraceID())
+	var refs []model.SpanRef
+	if span.Parent().IsValid() {
+		refs = append(refs, model.NewChildOfRef(traceID, toDomainSpanID(span.Parent().SpanID())))
+	}
+	for _, link := range span.Links() {
+		refs = append(refs, model.NewFollowsFromRef(toDomainTraceID(link.SpanContext.TraceID()), toDomainSpanID(link.SpanContext.SpanID())))
+	}
+
+	tags := toDomainTags(span.Attributes())
+	if kind := span.SpanKind(); kind != trace.SpanKindInternal && kind != trace.SpanKindUnspecified {
+		tags = append(tags, model.String("span.kind", kind.String()))
+	}
+	if scope := span.InstrumentationScope().Name; scope != "" {
+		tags = append(tags, model.String("otel.scope.name", scope))
+	}
+	if span.Status().Code == codes.Error {
+		tags = append(tags, model.Bool("error", true))
+		if span.Status().Description != "" {
+			tags = append(tags, model.String("otel.status_description", span.Status().Description))
+		}
+	}
+
+	logs := make([]model.Log, 0, len(span.Events()))
+	for _, event := range span.Events() {
+		logs = append(logs, model.Log{
+			Timestamp: event.Time,
+			Fields:    append([]model.KeyValue{model.String("event", event.Name)}, toDomainTags(event.Attributes)...),
+		})
+	}
+
+	process := &model.Process{}
+	for _, kv := range span.Resource().Attributes() {
+		if kv.Key == semconv.ServiceNameKey {
+			process.ServiceName = kv.Value.AsString()
+		} else {
+			process.Tags = append(process.Tags, toDomainTag(kv))
+		}
+	}
+
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        toDomainSpanID(span.SpanContext().SpanID()),
+		OperationName: span.Name(),
+		References:    refs,
+		Flags:         model.SampledFlag,
+		StartTime:     span.StartTime(),
+		Duration:      span.EndTime().Sub(span.StartTime()),
+		Tags:          tags,
+		Logs:          logs,
+		Process:       process,
+	}
+}
+
+func toDomainTraceID(traceID trace.TraceID) model.TraceID {
+	return model.NewTraceID(binary.BigEndian.Uint64(traceID[:8]), binary.BigEndian.Uint64(traceID[8:]))
+}
+
+func toDomainSpanID(spanID trace.SpanID) model.SpanID {
+	return model.NewSpanID(binary.BigEndian.Uint64(spanID[:]))
+}
+
+func toDomainTags(attributes []attribute.KeyValue) []model.KeyValue {
+	tags := make([]model.KeyValue, 0, len(attributes))
+	for _, kv := range attributes {
+		tags = append(tags, toDomainTag(kv))
+	}
+	return tags
+}
+
+func toDomainTag(kv attribute.KeyValue) model.KeyValue {
+	key := string(kv.Key)
+	switch kv.Value.Type() {
+	case attribute.BOOL:
+		return model.Bool(key, kv.Value.AsBool())
+	case attribute.INT64:
+		return model.Int64(key, kv.Value.AsInt64())
+	case attribute.FLOAT64:
+		return model.Float64(key, kv.Value.AsFloat64())
+	default:
+		return model.String(key, kv.Value.Emit())
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jtracer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	ui "github.com/jaegertracing/jaeger/model/json"
+)
+
+func newCaptureTracer(t *testing.T) *JTracer {
+	jt, err := NewCaptureOnly("jaeger-collector")
+	require.NoError(t, err)
+	t.Cleanup(func() { jt.Close(context.Background()) })
+	return jt
+}
+
+// createSpans creates spans with the tracer once the capture of the SpanCapture has started.
+func createSpans(jt *JTracer) {
+	for jt.Capture.active.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	tracer := jt.OTEL.Tracer("github.com/jaegertracing/jaeger/test")
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child", trace.WithAttributes(
+		attribute.String("db", "elasticsearch"),
+		attribute.Int("spans", 2),
+		attribute.Bool("retried", false),
+		attribute.Float64("ratio", 0.5),
+		attribute.StringSlice("indices", []string{"a", "b"}),
+	))
+	child.AddEvent("written", trace.WithAttributes(attribute.String("index", "a")))
+	child.SetStatus(codes.Error, "timeout")
+	child.End()
+	parent.End()
+}
+
+func TestSpanCapture(t *testing.T) {
+	jt := newCaptureTracer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		createSpans(jt)
+		cancel()
+	}()
+	spans := jt.Capture.Capture(ctx, time.Minute)
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, "parent", spans[1].Name())
+
+	// the spans are dropped once the capture is over
+	_, span := jt.OTEL.Tracer("test").Start(context.Background(), "op")
+	assert.False(t, span.SpanContext().IsSampled())
+	span.End()
+}
+
+func TestSpanCaptureTimeout(t *testing.T) {
+	jt := newCaptureTracer(t)
+	assert.Empty(t, jt.Capture.Capture(context.Background(), time.Millisecond))
+}
+
+func TestCaptureHandler(t *testing.T) {
+	jt := newCaptureTracer(t)
+	handler := NewCaptureHandler(jt.Capture, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		createSpans(jt)
+		cancel()
+	}()
+	req := httptest.NewRequest(http.MethodGet, "/debug/trace?seconds=60", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var res struct {
+		Data []ui.Trace `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 1)
+	trace := res.Data[0]
+	require.Len(t, trace.Spans, 2)
+	parent, child := trace.Spans[0], trace.Spans[1]
+	assert.Equal(t, "parent", parent.OperationName)
+	assert.Empty(t, parent.References)
+	assert.Contains(t, parent.Tags, ui.KeyValue{Key: "span.kind", Type: ui.StringType, Value: "server"})
+
+	assert.Equal(t, "child", child.OperationName)
+	require.Len(t, child.References, 1)
+	assert.Equal(t, ui.ChildOf, child.References[0].RefType)
+	assert.Equal(t, parent.SpanID, child.References[0].SpanID)
+	assert.Equal(t, parent.TraceID, child.TraceID)
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "db", Type: ui.StringType, Value: "elasticsearch"})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "spans", Type: ui.Int64Type, Value: float64(2)})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "retried", Type: ui.BoolType, Value: false})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "ratio", Type: ui.Float64Type, Value: 0.5})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "indices", Type: ui.StringType, Value: `["a","b"]`})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "error", Type: ui.BoolType, Value: true})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "otel.status_description", Type: ui.StringType, Value: "timeout"})
+	assert.Contains(t, child.Tags, ui.KeyValue{Key: "otel.scope.name", Type: ui.StringType, Value: "github.com/jaegertracing/jaeger/test"})
+	require.Len(t, child.Logs, 1)
+	assert.Equal(t, []ui.KeyValue{
+		{Key: "event", Type: ui.StringType, Value: "written"},
+		{Key: "index", Type: ui.StringType, Value: "a"},
+	}, child.Logs[0].Fields)
+
+	require.Len(t, trace.Processes, 1)
+	for _, process := range trace.Processes {
+		assert.Equal(t, "jaeger-collector", process.ServiceName)
+	}
+}
+
+func TestCaptureHandlerInvalidSeconds(t *testing.T) {
+	handler := NewCaptureHandler(NewSpanCapture(), zap.NewNop())
+	for _, seconds := range []string{"abc", "0", "61"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/trace?seconds="+seconds, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, seconds)
+	}
+}
@@ -24,14 +24,17 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 	"go.opentelemetry.io/otel/trace"
 	nooptrace "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 )
 
 type JTracer struct {
-	OTEL   trace.TracerProvider
-	closer func(ctx context.Context) error
+	OTEL trace.TracerProvider
+	// Capture records the spans of OTEL on demand, it is nil for NoOp.
+	Capture *SpanCapture
+	closer  func(ctx context.Context) error
 }
 
 var once sync.Once
@@ -40,18 +43,26 @@ func New(serviceName string) (*JTracer, error) {
 	return newHelper(serviceName, initOTEL)
 }
 
+// NewCaptureOnly creates a tracer without exporter, whose spans are only sampled
+// and recorded while its Capture is in progress.
+func NewCaptureOnly(serviceName string) (*JTracer, error) {
+	return newHelper(serviceName, initCaptureOnly)
+}
+
 func newHelper(
 	serviceName string,
-	tracerProvider func(ctx context.Context, svc string) (*sdktrace.TracerProvider, error),
+	tracerProvider func(ctx context.Context, svc string, capture *SpanCapture) (*sdktrace.TracerProvider, error),
 ) (*JTracer, error) {
 	ctx := context.Background()
-	provider, err := tracerProvider(ctx, serviceName)
+	capture := NewSpanCapture()
+	provider, err := tracerProvider(ctx, serviceName, capture)
 	if err != nil {
 		return nil, err
 	}
 
 	return &JTracer{
-		OTEL: provider,
+		OTEL:    provider,
+		Capture: capture,
 		closer: func(ctx context.Context) error {
 			return provider.Shutdown(ctx)
 		},
@@ -65,8 +76,22 @@ func NoOp() *JTracer {
 }
 
 // initOTEL initializes OTEL Tracer
-func initOTEL(ctx context.Context, svc string) (*sdktrace.TracerProvider, error) {
-	return initHelper(ctx, svc, otelExporter, otelResource)
+func initOTEL(ctx context.Context, svc string, capture *SpanCapture) (*sdktrace.TracerProvider, error) {
+	return initHelper(ctx, svc, otelExporter, otelResource, sdktrace.WithSpanProcessor(capture))
+}
+
+// initCaptureOnly initializes an OTEL Tracer sampling the spans for the capture only.
+// It is not registered as the global tracer provider.
+func initCaptureOnly(ctx context.Context, svc string, capture *SpanCapture) (*sdktrace.TracerProvider, error) {
+	res, err := otelResource(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(capture),
+		sdktrace.WithSpanProcessor(capture),
+		sdktrace.WithResource(res),
+	), nil
 }
 
 func initHelper(
@@ -74,6 +99,7 @@ func initHelper(
 	svc string,
 	otelExporter func(_ context.Context) (sdktrace.SpanExporter, error),
 	otelResource func(_ context.Context, _ /* svc */ string) (*resource.Resource, error),
+	opts ...sdktrace.TracerProviderOption,
 ) (*sdktrace.TracerProvider, error) {
 	res, err := otelResource(ctx, svc)
 	if err != nil {
@@ -89,10 +115,10 @@ func initHelper(
 	// span processor to aggregate spans before export.
 	bsp := sdktrace.NewBatchSpanProcessor(traceExporter)
 
-	tracerProvider := sdktrace.NewTracerProvider(
+	tracerProvider := sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{
 		sdktrace.WithSpanProcessor(bsp),
 		sdktrace.WithResource(res),
-	)
+	}, opts...)...)
 
 	once.Do(func() {
 		otel.SetTextMapPropagator(
@@ -108,15 +134,7 @@ func initHelper(
 }
 
 func otelResource(ctx context.Context, svc string) (*resource.Resource, error) {
-	return resource.New(
-		ctx,
-		resource.WithSchemaURL(semconv.SchemaURL),
-		resource.WithAttributes(semconv.ServiceNameKey.String(svc)),
-		resource.WithTelemetrySDK(),
-		resource.WithHost(),
-		resource.WithOSType(),
-		resource.WithFromEnv(),
-	)
+	return telemetery.NewResource(ctx, svc)
 }
 
 func otelExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
@@ -35,6 +35,17 @@ func TestNew(t *testing.T) {
 	jt.Close(context.Background())
 }
 
+func TestNewCaptureOnly(t *testing.T) {
+	jt, err := NewCaptureOnly("serviceName")
+	require.NoError(t, err)
+	require.NotNil(t, jt.Capture)
+	_, span := jt.OTEL.Tracer("test").Start(context.Background(), "op")
+	require.False(t, span.SpanContext().IsSampled(), "spans are not sampled outside of a capture")
+	span.End()
+
+	jt.Close(context.Background())
+}
+
 func TestNoOp(t *testing.T) {
 	jt := NoOp()
 	require.NotNil(t, jt.OTEL)
@@ -45,7 +56,7 @@ func TestNewHelperProviderError(t *testing.T) {
 	fakeErr := errors.New("fakeProviderError")
 	_, err := newHelper(
 		"svc",
-		func(_ context.Context, _ /* svc */ string) (*sdktrace.TracerProvider, error) {
+		func(_ context.Context, _ /* svc */ string, _ *SpanCapture) (*sdktrace.TracerProvider, error) {
 			return nil, fakeErr
 		})
 	require.Error(t, err)
@@ -26,6 +26,7 @@ import (
 // Builder builds a new kafka producer
 type Builder interface {
 	NewProducer(logger *zap.Logger) (sarama.AsyncProducer, error)
+	NewClient(logger *zap.Logger) (sarama.Client, error)
 }
 
 // Configuration describes the configuration properties needed to create a Kafka producer
@@ -45,6 +46,23 @@ type Configuration struct {
 
 // NewProducer creates a new asynchronous kafka producer
 func (c *Configuration) NewProducer(logger *zap.Logger) (sarama.AsyncProducer, error) {
+	saramaConfig, err := c.saramaConfig(logger)
+	if err != nil {
+		return nil, err
+	}
+	return sarama.NewAsyncProducer(c.Brokers, saramaConfig)
+}
+
+// NewClient creates a Kafka client, which connects to the brokers to fetch the metadata of the cluster.
+func (c *Configuration) NewClient(logger *zap.Logger) (sarama.Client, error) {
+	saramaConfig, err := c.saramaConfig(logger)
+	if err != nil {
+		return nil, err
+	}
+	return sarama.NewClient(c.Brokers, saramaConfig)
+}
+
+func (c *Configuration) saramaConfig(logger *zap.Logger) (*sarama.Config, error) {
 	saramaConfig := sarama.NewConfig()
 	saramaConfig.Producer.RequiredAcks = c.RequiredAcks
 	saramaConfig.Producer.Compression = c.Compression
@@ -65,5 +83,5 @@ func (c *Configuration) NewProducer(logger *zap.Logger) (sarama.AsyncProducer, e
 	if err := c.AuthenticationConfig.SetConfiguration(saramaConfig, logger); err != nil {
 		return nil, err
 	}
-	return sarama.NewAsyncProducer(c.Brokers, saramaConfig)
+	return saramaConfig, nil
 }
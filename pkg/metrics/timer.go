@@ -16,6 +16,7 @@
 package metrics
 
 import (
+	"context"
 	"time"
 )
 
@@ -32,3 +33,19 @@ var NullTimer Timer = nullTimer{}
 type nullTimer struct{}
 
 func (nullTimer) Record(time.Duration) {}
+
+// ContextTimer is implemented by the timers that record the trace of the context, if any,
+// as an exemplar of the time.
+type ContextTimer interface {
+	RecordWithContext(ctx context.Context, d time.Duration)
+}
+
+// RecordWithContext records the time with the timer, with the trace of the context as an exemplar
+// if the timer supports it.
+func RecordWithContext(ctx context.Context, t Timer, d time.Duration) {
+	if ct, ok := t.(ContextTimer); ok {
+		ct.RecordWithContext(ctx, d)
+		return
+	}
+	t.Record(d)
+}
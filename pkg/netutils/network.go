@@ -0,0 +1,68 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package netutils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// NetworkDual is the IP family of the listeners accepting both the IPv4 and IPv6 connections,
+// provided their host is empty, a wildcard or a host name resolving to both families.
+const NetworkDual = "dual"
+
+// TCPNetwork returns the Go network of the TCP listeners of the IP family: "tcp" for NetworkDual
+// or an empty family, "tcp4" or "tcp6".
+func TCPNetwork(family string) (string, error) {
+	return network("tcp", family)
+}
+
+// UDPNetwork returns the Go network of the UDP listeners of the IP family: "udp" for NetworkDual
+// or an empty family, "udp4" or "udp6".
+func UDPNetwork(family string) (string, error) {
+	return network("udp", family)
+}
+
+func network(base, family string) (string, error) {
+	switch family {
+	case "", NetworkDual:
+		return base, nil
+	case base + "4", base + "6":
+		return family, nil
+	default:
+		return "", fmt.Errorf("invalid IP family %q, must be %s, %s4 or %s6", family, NetworkDual, base, base)
+	}
+}
+
+// Listen announces on the host:port address with the Go TCP network, "tcp" if empty, or on the
+// Unix domain socket of a unix:// address with the socket permissions, see ListenUnix.
+func Listen(network, address string, socketPermissions os.FileMode) (net.Listener, error) {
+	if path, ok := UnixSocketPath(address); ok {
+		return ListenUnix(path, socketPermissions)
+	}
+	if network == "" {
+		network = "tcp"
+	}
+	return net.Listen(network, address)
+}
+
+// WildcardHostPort returns the host:port address with the wildcard address of the family of the
+// Go network as host, if the host is empty, for the servers which cannot be given a network but
+// only bind the address they are given.
+func WildcardHostPort(network, hostPort string) string {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil || host != "" {
+		return hostPort
+	}
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return net.JoinHostPort(net.IPv4zero.String(), port)
+	case strings.HasSuffix(network, "6"):
+		return net.JoinHostPort(net.IPv6zero.String(), port)
+	default:
+		return hostPort
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package netutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork(t *testing.T) {
+	for family, expected := range map[string]string{"": "tcp", NetworkDual: "tcp", "tcp4": "tcp4", "tcp6": "tcp6"} {
+		network, err := TCPNetwork(family)
+		require.NoError(t, err)
+		assert.Equal(t, expected, network)
+	}
+	for family, expected := range map[string]string{"": "udp", NetworkDual: "udp", "udp4": "udp4", "udp6": "udp6"} {
+		network, err := UDPNetwork(family)
+		require.NoError(t, err)
+		assert.Equal(t, expected, network)
+	}
+	_, err := TCPNetwork("udp4")
+	require.EqualError(t, err, `invalid IP family "udp4", must be dual, tcp4 or tcp6`)
+	_, err = UDPNetwork("ipv6")
+	require.EqualError(t, err, `invalid IP family "ipv6", must be dual, udp4 or udp6`)
+}
+
+func TestListen(t *testing.T) {
+	l, err := Listen("", "localhost:0", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", l.Addr().Network())
+	require.NoError(t, l.Close())
+
+	l, err = Listen("tcp4", ":0", 0)
+	require.NoError(t, err)
+	assert.Contains(t, l.Addr().String(), "0.0.0.0:")
+	require.NoError(t, l.Close())
+
+	_, err = Listen("tcp4", "[::1]:0", 0)
+	require.Error(t, err)
+}
+
+func TestWildcardHostPort(t *testing.T) {
+	assert.Equal(t, "0.0.0.0:4318", WildcardHostPort("tcp4", ":4318"))
+	assert.Equal(t, "[::]:4318", WildcardHostPort("tcp6", ":4318"))
+	assert.Equal(t, ":4318", WildcardHostPort("tcp", ":4318"))
+	assert.Equal(t, "localhost:4318", WildcardHostPort("tcp4", "localhost:4318"))
+	assert.Equal(t, "4318", WildcardHostPort("tcp4", "4318"))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package netutils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// UnixScheme is the prefix of the addresses of the Unix domain sockets, e.g. unix:///var/run/jaeger/collector.sock.
+const UnixScheme = "unix://"
+
+// UnixSocketPath returns the path of the Unix domain socket of a unix:// address, and false
+// if the address is not one.
+func UnixSocketPath(address string) (string, bool) {
+	if !strings.HasPrefix(address, UnixScheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(address, UnixScheme), true
+}
+
+// ParseSocketPermissions parses the octal permissions of a Unix domain socket, e.g. 0660,
+// zero if empty to keep the permissions given by the umask.
+func ParseSocketPermissions(permissions string) (os.FileMode, error) {
+	if permissions == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(permissions, 8, 32)
+	if err != nil || mode > uint64(os.ModePerm) {
+		return 0, fmt.Errorf("invalid socket permissions %q, must be octal permissions such as 0660", permissions)
+	}
+	return os.FileMode(mode), nil
+}
+
+// ListenUnix announces on the Unix domain socket at path, removing a socket left behind at the
+// path by a previous run, and sets the permissions of the socket if not zero. The socket is
+// removed when the listener is closed.
+func ListenUnix(path string, permissions os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("cannot remove stale socket %s: %w", path, err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if permissions != 0 {
+		if err := os.Chmod(path, permissions); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("cannot set the permissions of socket %s: %w", path, err)
+		}
+	}
+	return listener, nil
+}
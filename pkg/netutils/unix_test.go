@@ -0,0 +1,62 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package netutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	path, ok := UnixSocketPath("unix:///var/run/jaeger/query.sock")
+	assert.True(t, ok)
+	assert.Equal(t, "/var/run/jaeger/query.sock", path)
+
+	_, ok = UnixSocketPath(":16686")
+	assert.False(t, ok)
+}
+
+func TestParseSocketPermissions(t *testing.T) {
+	for permissions, expected := range map[string]os.FileMode{"": 0, "0660": 0o660, "777": 0o777} {
+		mode, err := ParseSocketPermissions(permissions)
+		require.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+	for _, permissions := range []string{"0888", "1777", "rw"} {
+		_, err := ParseSocketPermissions(permissions)
+		require.EqualError(t, err, `invalid socket permissions "`+permissions+`", must be octal permissions such as 0660`)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jaeger.sock")
+
+	stale, err := ListenUnix(path, 0)
+	require.NoError(t, err)
+	stale.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	_, err = os.Lstat(path)
+	require.NoError(t, err, "the socket is left behind")
+
+	l, err := Listen("tcp4", UnixScheme+path, 0o600)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", l.Addr().Network())
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	require.NoError(t, l.Close())
+	_, err = os.Lstat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestListenUnixNotSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jaeger.sock")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	_, err := ListenUnix(path, 0)
+	require.Error(t, err, "a regular file is not removed")
+}
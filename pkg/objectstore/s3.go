@@ -0,0 +1,131 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// ErrObjectNotFound is returned by Store.GetObject for the keys that do not exist.
+var ErrObjectNotFound = errors.New("no such key")
+
+// Store reads and writes the objects of a bucket.
+type Store interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// ListObjects returns the keys starting with the prefix, in lexicographic order.
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	// DeleteObject deletes the key, if it exists.
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// S3Config is the bucket of an S3 compatible object storage.
+type S3Config struct {
+	Bucket string
+	// Region is the region of the bucket, the default region of the AWS configuration is used if empty.
+	Region string
+	// Endpoint overrides the endpoint of S3, e.g. to use MinIO or https://storage.googleapis.com.
+	Endpoint string
+	// ForcePathStyle puts the bucket in the path of the URLs instead of the host name.
+	ForcePathStyle bool
+}
+
+// S3Store is the Store of a bucket of an S3 compatible object storage.
+type S3Store struct {
+	client s3iface.S3API
+	bucket string
+}
+
+var _ Store = (*S3Store)(nil)
+
+// NewS3Store creates an S3Store with the credentials of the default AWS configuration.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	awsConfig := aws.NewConfig().WithS3ForcePathStyle(config.ForcePathStyle)
+	if config.Region != "" {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	}
+	if config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.Endpoint)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create AWS session: %w", err)
+	}
+	return &S3Store{client: s3.New(sess), bucket: config.Bucket}, nil
+}
+
+// PutObject implements Store.
+func (s *S3Store) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s to the bucket %s: %w", key, s.bucket, err)
+	}
+	return nil
+}
+
+// GetObject implements Store.
+func (s *S3Store) GetObject(ctx context.Context, key string) ([]byte, error) {
+	output, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && (awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == "NotFound") {
+			err = ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to read %s from the bucket %s: %w", key, s.bucket, err)
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+// ListObjects implements Store.
+func (s *S3Store) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in the bucket %s: %w", prefix, s.bucket, err)
+	}
+	return keys, nil
+}
+
+// DeleteObject implements Store.
+func (s *S3Store) DeleteObject(ctx context.Context, key string) error {
+	// the objects are deleted one by one, some S3 compatible object storages not supporting DeleteObjects
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s from the bucket %s: %w", key, s.bucket, err)
+	}
+	return nil
+}
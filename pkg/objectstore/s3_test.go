@@ -0,0 +1,105 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startS3 starts a fake S3 keeping the objects in memory, the requests to the bucket forbidden failing.
+func startS3(t *testing.T) *httptest.Server {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	var lock sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path+"/", "/forbidden/"):
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPut:
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			objects[r.URL.Path] = body
+		case r.Method == http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Query().Get("list-type") == "2":
+			prefix := r.URL.Path + "/" + r.URL.Query().Get("prefix")
+			var keys []string
+			for key := range objects {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, fmt.Sprintf("<Contents><Key>%s</Key></Contents>", strings.TrimPrefix(key, r.URL.Path+"/")))
+				}
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(w, "<ListBucketResult><IsTruncated>false</IsTruncated>%s</ListBucketResult>", strings.Join(keys, ""))
+		default:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestS3Store(t *testing.T, bucket, endpoint string) *S3Store {
+	store, err := NewS3Store(S3Config{Bucket: bucket, Region: "us-east-1", Endpoint: endpoint, ForcePathStyle: true})
+	require.NoError(t, err)
+	return store
+}
+
+func TestS3Store(t *testing.T) {
+	server := startS3(t)
+	store := newTestS3Store(t, "traces", server.URL)
+	ctx := context.Background()
+
+	require.NoError(t, store.PutObject(ctx, "jaeger/frontend/manifest.json", []byte("{}"), "application/json"))
+	require.NoError(t, store.PutObject(ctx, "jaeger/billing/manifest.json", []byte(`{"n":1}`), "application/json"))
+	require.NoError(t, store.PutObject(ctx, "other/manifest.json", []byte("{}"), "application/json"))
+
+	body, err := store.GetObject(ctx, "jaeger/billing/manifest.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"n":1}`, string(body))
+
+	keys, err := store.ListObjects(ctx, "jaeger/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jaeger/billing/manifest.json", "jaeger/frontend/manifest.json"}, keys)
+
+	require.NoError(t, store.DeleteObject(ctx, "jaeger/billing/manifest.json"))
+	_, err = store.GetObject(ctx, "jaeger/billing/manifest.json")
+	require.ErrorIs(t, err, ErrObjectNotFound)
+	require.ErrorContains(t, err, "failed to read jaeger/billing/manifest.json from the bucket traces")
+}
+
+func TestS3StoreErrors(t *testing.T) {
+	server := startS3(t)
+	store := newTestS3Store(t, "forbidden", server.URL)
+	ctx := context.Background()
+
+	require.ErrorContains(t, store.PutObject(ctx, "key", []byte("{}"), "application/json"), "failed to write key to the bucket forbidden")
+	_, err := store.GetObject(ctx, "key")
+	require.ErrorContains(t, err, "failed to read key from the bucket forbidden")
+	require.NotErrorIs(t, err, ErrObjectNotFound)
+	_, err = store.ListObjects(ctx, "jaeger/")
+	require.ErrorContains(t, err, "failed to list jaeger/ in the bucket forbidden")
+	require.ErrorContains(t, store.DeleteObject(ctx, "key"), "failed to delete key from the bucket forbidden")
+}
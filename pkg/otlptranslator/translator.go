@@ -0,0 +1,286 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlptranslator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	jaegertranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.9.0"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// LinksTagKey is the tag of the spans holding their OTLP links, in the OTLP/JSON encoding, when
+// the links have attributes, a trace state, flags or dropped attributes which the span references
+// of the Jaeger model cannot hold.
+const LinksTagKey = "otel.links"
+
+// link is the OTLP/JSON encoding of a span link.
+type link struct {
+	TraceID                string     `json:"traceId"`
+	SpanID                 string     `json:"spanId"`
+	TraceState             string     `json:"traceState,omitempty"`
+	Attributes             []keyValue `json:"attributes,omitempty"`
+	DroppedAttributesCount uint32     `json:"droppedAttributesCount,omitempty"`
+	Flags                  uint32     `json:"flags,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+	IntValue    *int64       `json:"intValue,omitempty,string"`
+	DoubleValue *float64     `json:"doubleValue,omitempty"`
+	BytesValue  *[]byte      `json:"bytesValue,omitempty"`
+	ArrayValue  *arrayValue  `json:"arrayValue,omitempty"`
+	KvlistValue *kvlistValue `json:"kvlistValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+type kvlistValue struct {
+	Values []keyValue `json:"values"`
+}
+
+type spanKey struct {
+	traceID model.TraceID
+	spanID  model.SpanID
+}
+
+// ProtoFromTraces translates the OTLP traces into Jaeger batches like the OpenTelemetry translator,
+// which turns the span links into FOLLOWS_FROM references, and also records the links in the
+// LinksTagKey tag of the spans if the references lose some of their fields.
+func ProtoFromTraces(td ptrace.Traces) ([]*model.Batch, error) {
+	batches, err := jaegertranslator.ProtoFromTraces(td)
+	if err != nil {
+		return batches, err
+	}
+	links := make(map[spanKey]string)
+	forEachSpan(td, func(span ptrace.Span) {
+		if _, ok := span.Attributes().Get(LinksTagKey); ok || !hasLinkFields(span.Links()) {
+			return
+		}
+		encoded, err := json.Marshal(fromLinks(span.Links()))
+		if err != nil {
+			return
+		}
+		links[spanKey{traceID: toTraceID(span.TraceID()), spanID: toSpanID(span.SpanID())}] = string(encoded)
+	})
+	if len(links) == 0 {
+		return batches, nil
+	}
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if encoded, ok := links[spanKey{traceID: span.TraceID, spanID: span.SpanID}]; ok {
+				span.Tags = append(span.Tags, model.String(LinksTagKey, encoded))
+			}
+		}
+	}
+	return batches, nil
+}
+
+// ProtoToTraces translates the Jaeger batches into OTLP traces like the OpenTelemetry translator,
+// and restores the span links recorded in the LinksTagKey tag of the spans by ProtoFromTraces.
+func ProtoToTraces(batches []*model.Batch) (ptrace.Traces, error) {
+	td, err := jaegertranslator.ProtoToTraces(batches)
+	if err != nil {
+		return td, err
+	}
+	forEachSpan(td, func(span ptrace.Span) {
+		value, ok := span.Attributes().Get(LinksTagKey)
+		if !ok {
+			return
+		}
+		var recorded []link
+		if err := json.Unmarshal([]byte(value.AsString()), &recorded); err != nil {
+			// the attribute was not recorded by ProtoFromTraces, it is kept as is
+			return
+		}
+		span.Attributes().Remove(LinksTagKey)
+		restoreLinks(span.Links(), recorded)
+	})
+	return td, nil
+}
+
+func forEachSpan(td ptrace.Traces, f func(span ptrace.Span)) {
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		scopeSpans := td.ResourceSpans().At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				f(spans.At(k))
+			}
+		}
+	}
+}
+
+// hasLinkFields reports whether one of the links has a field that the span references do not hold,
+// the opentracing.ref_type attribute being the type of the reference.
+func hasLinkFields(links ptrace.SpanLinkSlice) bool {
+	for i := 0; i < links.Len(); i++ {
+		l := links.At(i)
+		attributes := l.Attributes().Len()
+		if _, ok := l.Attributes().Get(conventions.AttributeOpentracingRefType); ok {
+			attributes--
+		}
+		if attributes > 0 || l.TraceState().AsRaw() != "" || l.DroppedAttributesCount() > 0 || l.Flags() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreLinks replaces the links translated from the span references by the recorded ones, in
+// their original order, followed by the links of the references not recorded.
+func restoreLinks(links ptrace.SpanLinkSlice, recorded []link) {
+	restored := ptrace.NewSpanLinkSlice()
+	pending := make(map[spanKey]int, len(recorded))
+	for _, l := range recorded {
+		traceID, spanID, ok := l.ids()
+		if !ok {
+			continue
+		}
+		dest := restored.AppendEmpty()
+		dest.SetTraceID(traceID)
+		dest.SetSpanID(spanID)
+		dest.TraceState().FromRaw(l.TraceState)
+		dest.SetDroppedAttributesCount(l.DroppedAttributesCount)
+		dest.SetFlags(l.Flags)
+		toMap(l.Attributes, dest.Attributes())
+		pending[spanKey{traceID: toTraceID(traceID), spanID: toSpanID(spanID)}]++
+	}
+	for i := 0; i < links.Len(); i++ {
+		key := spanKey{traceID: toTraceID(links.At(i).TraceID()), spanID: toSpanID(links.At(i).SpanID())}
+		if pending[key] > 0 {
+			pending[key]--
+			continue
+		}
+		links.At(i).CopyTo(restored.AppendEmpty())
+	}
+	restored.CopyTo(links)
+}
+
+func (l link) ids() (pcommon.TraceID, pcommon.SpanID, bool) {
+	var traceID pcommon.TraceID
+	var spanID pcommon.SpanID
+	if n, err := hex.Decode(traceID[:], []byte(l.TraceID)); err != nil || n != len(traceID) {
+		return traceID, spanID, false
+	}
+	if n, err := hex.Decode(spanID[:], []byte(l.SpanID)); err != nil || n != len(spanID) {
+		return traceID, spanID, false
+	}
+	return traceID, spanID, true
+}
+
+func fromLinks(links ptrace.SpanLinkSlice) []link {
+	encoded := make([]link, links.Len())
+	for i := range encoded {
+		l := links.At(i)
+		encoded[i] = link{
+			TraceID:                l.TraceID().String(),
+			SpanID:                 l.SpanID().String(),
+			TraceState:             l.TraceState().AsRaw(),
+			Attributes:             fromMap(l.Attributes()),
+			DroppedAttributesCount: l.DroppedAttributesCount(),
+			Flags:                  l.Flags(),
+		}
+	}
+	return encoded
+}
+
+func fromMap(m pcommon.Map) []keyValue {
+	if m.Len() == 0 {
+		return nil
+	}
+	kvs := make([]keyValue, 0, m.Len())
+	m.Range(func(k string, v pcommon.Value) bool {
+		kvs = append(kvs, keyValue{Key: k, Value: fromValue(v)})
+		return true
+	})
+	return kvs
+}
+
+func fromValue(v pcommon.Value) anyValue {
+	var encoded anyValue
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		s := v.Str()
+		encoded.StringValue = &s
+	case pcommon.ValueTypeBool:
+		b := v.Bool()
+		encoded.BoolValue = &b
+	case pcommon.ValueTypeInt:
+		i := v.Int()
+		encoded.IntValue = &i
+	case pcommon.ValueTypeDouble:
+		d := v.Double()
+		encoded.DoubleValue = &d
+	case pcommon.ValueTypeBytes:
+		b := v.Bytes().AsRaw()
+		encoded.BytesValue = &b
+	case pcommon.ValueTypeSlice:
+		values := make([]anyValue, v.Slice().Len())
+		for i := range values {
+			values[i] = fromValue(v.Slice().At(i))
+		}
+		encoded.ArrayValue = &arrayValue{Values: values}
+	case pcommon.ValueTypeMap:
+		encoded.KvlistValue = &kvlistValue{Values: fromMap(v.Map())}
+	}
+	return encoded
+}
+
+func toMap(kvs []keyValue, dest pcommon.Map) {
+	if len(kvs) == 0 {
+		return
+	}
+	dest.EnsureCapacity(len(kvs))
+	for _, kv := range kvs {
+		toValue(kv.Value, dest.PutEmpty(kv.Key))
+	}
+}
+
+func toValue(v anyValue, dest pcommon.Value) {
+	switch {
+	case v.StringValue != nil:
+		dest.SetStr(*v.StringValue)
+	case v.BoolValue != nil:
+		dest.SetBool(*v.BoolValue)
+	case v.IntValue != nil:
+		dest.SetInt(*v.IntValue)
+	case v.DoubleValue != nil:
+		dest.SetDouble(*v.DoubleValue)
+	case v.BytesValue != nil:
+		dest.SetEmptyBytes().FromRaw(*v.BytesValue)
+	case v.ArrayValue != nil:
+		slice := dest.SetEmptySlice()
+		slice.EnsureCapacity(len(v.ArrayValue.Values))
+		for _, value := range v.ArrayValue.Values {
+			toValue(value, slice.AppendEmpty())
+		}
+	case v.KvlistValue != nil:
+		toMap(v.KvlistValue.Values, dest.SetEmptyMap())
+	}
+}
+
+func toTraceID(traceID pcommon.TraceID) model.TraceID {
+	// the length of the bytes is always valid
+	id, _ := model.TraceIDFromBytes(traceID[:])
+	return id
+}
+
+func toSpanID(spanID pcommon.SpanID) model.SpanID {
+	id, _ := model.SpanIDFromBytes(spanID[:])
+	return id
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlptranslator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var (
+	traceID       = pcommon.TraceID([16]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1})
+	linkedTraceID = pcommon.TraceID([16]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2})
+)
+
+func newTraces() (ptrace.Traces, ptrace.Span) {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "frontend")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetTraceID(traceID)
+	span.SetSpanID(pcommon.SpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 1}))
+	span.SetName("op")
+	return td, span
+}
+
+func linksTag(t *testing.T, span *model.Span) (string, bool) {
+	t.Helper()
+	tag, ok := model.KeyValues(span.Tags).FindByKey(LinksTagKey)
+	return tag.AsString(), ok
+}
+
+func TestLinksRoundTrip(t *testing.T) {
+	td, span := newTraces()
+	first := span.Links().AppendEmpty()
+	first.SetTraceID(linkedTraceID)
+	first.SetSpanID(pcommon.SpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 2}))
+	first.TraceState().FromRaw("vendor=value")
+	first.SetDroppedAttributesCount(1)
+	first.SetFlags(1)
+	attributes := first.Attributes()
+	attributes.PutStr("string", "value")
+	attributes.PutBool("bool", true)
+	attributes.PutInt("int", 42)
+	attributes.PutDouble("double", 1.5)
+	attributes.PutEmptyBytes("bytes").FromRaw([]byte{1, 2})
+	slice := attributes.PutEmptySlice("slice")
+	slice.AppendEmpty().SetStr("a")
+	slice.AppendEmpty().SetInt(1)
+	attributes.PutEmptyMap("map").PutStr("key", "value")
+	second := span.Links().AppendEmpty()
+	second.SetTraceID(linkedTraceID)
+	second.SetSpanID(pcommon.SpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 3}))
+
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0].Spans, 1)
+	jaegerSpan := batches[0].Spans[0]
+	assert.Equal(t, []model.SpanRef{
+		model.NewFollowsFromRef(model.NewTraceID(0, 2), model.NewSpanID(2)),
+		model.NewFollowsFromRef(model.NewTraceID(0, 2), model.NewSpanID(3)),
+	}, jaegerSpan.References)
+	tag, ok := linksTag(t, jaegerSpan)
+	require.True(t, ok)
+	assert.JSONEq(t, `[
+		{
+			"traceId": "00000000000000000000000000000002",
+			"spanId": "0000000000000002",
+			"traceState": "vendor=value",
+			"attributes": [
+				{"key": "string", "value": {"stringValue": "value"}},
+				{"key": "bool", "value": {"boolValue": true}},
+				{"key": "int", "value": {"intValue": "42"}},
+				{"key": "double", "value": {"doubleValue": 1.5}},
+				{"key": "bytes", "value": {"bytesValue": "AQI="}},
+				{"key": "slice", "value": {"arrayValue": {"values": [{"stringValue": "a"}, {"intValue": "1"}]}}},
+				{"key": "map", "value": {"kvlistValue": {"values": [{"key": "key", "value": {"stringValue": "value"}}]}}}
+			],
+			"droppedAttributesCount": 1,
+			"flags": 1
+		},
+		{"traceId": "00000000000000000000000000000002", "spanId": "0000000000000003"}
+	]`, tag)
+
+	restored, err := ProtoToTraces(batches)
+	require.NoError(t, err)
+	restoredSpan := restored.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	_, ok = restoredSpan.Attributes().Get(LinksTagKey)
+	assert.False(t, ok, "the links tag is removed")
+	assertLinksEqual(t, span.Links(), restoredSpan.Links())
+}
+
+func assertLinksEqual(t *testing.T, expected, actual ptrace.SpanLinkSlice) {
+	require.Equal(t, expected.Len(), actual.Len())
+	for i := 0; i < expected.Len(); i++ {
+		e, a := expected.At(i), actual.At(i)
+		assert.Equal(t, e.TraceID(), a.TraceID())
+		assert.Equal(t, e.SpanID(), a.SpanID())
+		assert.Equal(t, e.TraceState().AsRaw(), a.TraceState().AsRaw())
+		assert.Equal(t, e.Attributes().AsRaw(), a.Attributes().AsRaw())
+		assert.Equal(t, e.DroppedAttributesCount(), a.DroppedAttributesCount())
+		assert.Equal(t, e.Flags(), a.Flags())
+	}
+}
+
+func TestLinksWithoutFields(t *testing.T) {
+	td, span := newTraces()
+	link := span.Links().AppendEmpty()
+	link.SetTraceID(linkedTraceID)
+	link.SetSpanID(pcommon.SpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 2}))
+	link.Attributes().PutStr("opentracing.ref_type", "child_of")
+
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	_, ok := linksTag(t, batches[0].Spans[0])
+	assert.False(t, ok, "the references hold the links without other fields")
+	assert.Equal(t, []model.SpanRef{model.NewChildOfRef(model.NewTraceID(0, 2), model.NewSpanID(2))}, batches[0].Spans[0].References)
+}
+
+func TestLinksTagAlreadyRecorded(t *testing.T) {
+	td, span := newTraces()
+	span.Attributes().PutStr(LinksTagKey, "[]")
+	link := span.Links().AppendEmpty()
+	link.SetTraceID(linkedTraceID)
+	link.Attributes().PutStr("key", "value")
+
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	var tags []string
+	for _, tag := range batches[0].Spans[0].Tags {
+		if tag.Key == LinksTagKey {
+			tags = append(tags, tag.AsString())
+		}
+	}
+	assert.Equal(t, []string{"[]"}, tags)
+}
+
+func TestRestoreLinks(t *testing.T) {
+	span := &model.Span{
+		TraceID:       model.NewTraceID(0, 1),
+		SpanID:        model.NewSpanID(1),
+		OperationName: "op",
+		References: []model.SpanRef{
+			model.NewFollowsFromRef(model.NewTraceID(0, 2), model.NewSpanID(2)),
+			// the reference added after the links were recorded is kept
+			model.NewFollowsFromRef(model.NewTraceID(0, 2), model.NewSpanID(4)),
+		},
+		Tags: []model.KeyValue{model.String(LinksTagKey, `[
+			{"traceId": "00000000000000000000000000000002", "spanId": "0000000000000002", "attributes": [{"key": "k", "value": {"stringValue": "v"}}]},
+			{"traceId": "invalid", "spanId": "0000000000000003"},
+			{"traceId": "00000000000000000000000000000002", "spanId": "invalid"}
+		]`)},
+		Process: &model.Process{ServiceName: "frontend"},
+	}
+	td, err := ProtoToTraces([]*model.Batch{{Spans: []*model.Span{span}}})
+	require.NoError(t, err)
+	links := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Links()
+	require.Equal(t, 2, links.Len())
+	assert.Equal(t, map[string]any{"k": "v"}, links.At(0).Attributes().AsRaw())
+	assert.Equal(t, pcommon.SpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 4}), links.At(1).SpanID())
+	assert.Equal(t, map[string]any{"opentracing.ref_type": "follows_from"}, links.At(1).Attributes().AsRaw())
+}
+
+func TestLinksTagNotRecorded(t *testing.T) {
+	span := &model.Span{
+		TraceID: model.NewTraceID(0, 1),
+		SpanID:  model.NewSpanID(1),
+		Tags:    []model.KeyValue{model.String(LinksTagKey, "not json")},
+		Process: &model.Process{ServiceName: "frontend"},
+	}
+	td, err := ProtoToTraces([]*model.Batch{{Spans: []*model.Span{span}}})
+	require.NoError(t, err)
+	value, ok := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().Get(LinksTagKey)
+	require.True(t, ok)
+	assert.Equal(t, "not json", value.Str())
+}
+
+func TestLinksTagRoundTrip(t *testing.T) {
+	// the links tag written through the OTLP pipelines, e.g. by the storage integration tests, is unchanged
+	tag := `[{"traceId":"00000000000000000000000000000026","spanId":"0000000000000001","traceState":"vendor=value",` +
+		`"attributes":[{"key":"link.kind","value":{"stringValue":"batch"}},{"key":"link.index","value":{"intValue":"1"}}]}]`
+	span := &model.Span{
+		TraceID:    model.NewTraceID(0, 0x25),
+		SpanID:     model.NewSpanID(1),
+		References: []model.SpanRef{model.NewFollowsFromRef(model.NewTraceID(0, 0x26), model.NewSpanID(1))},
+		Tags:       []model.KeyValue{model.String(LinksTagKey, tag)},
+		Process:    &model.Process{ServiceName: "frontend"},
+	}
+	td, err := ProtoToTraces([]*model.Batch{{Spans: []*model.Span{span}}})
+	require.NoError(t, err)
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	actual, ok := linksTag(t, batches[0].Spans[0])
+	require.True(t, ok)
+	assert.Equal(t, tag, actual)
+	assert.Equal(t, span.References, batches[0].Spans[0].References)
+}
@@ -32,4 +32,7 @@ type Configuration struct {
 	LatencyUnit       string
 	NormalizeCalls    bool
 	NormalizeDuration bool
+	// AdjustedCountLabel is the label of the calls metric holding the adjusted count of the
+	// sampled spans, the call rates and error rates are not scaled if empty
+	AdjustedCountLabel string
 }
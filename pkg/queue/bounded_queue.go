@@ -64,6 +64,8 @@ func NewBoundedQueue(capacity int, onDroppedItem func(item any)) *BoundedQueue {
 func (q *BoundedQueue) StartConsumersWithFactory(num int, factory func() Consumer) {
 	q.workers = num
 	q.factory = factory
+	// read before starting the consumers, as the fields change when the queue is resized
+	queue := *q.items
 	var startWG sync.WaitGroup
 	for i := 0; i < q.workers; i++ {
 		q.stopWG.Add(1)
@@ -71,8 +73,7 @@ func (q *BoundedQueue) StartConsumersWithFactory(num int, factory func() Consume
 		go func() {
 			startWG.Done()
 			defer q.stopWG.Done()
-			consumer := q.factory()
-			queue := *q.items
+			consumer := factory()
 			for {
 				select {
 				case item, ok := <-queue:
@@ -179,7 +180,13 @@ func (q *BoundedQueue) StartLengthReporting(reportPeriod time.Duration, gauge me
 
 // Resize changes the capacity of the queue, returning whether the action was successful
 func (q *BoundedQueue) Resize(capacity int) bool {
-	if capacity == q.Capacity() {
+	return q.ResizeWithWorkers(capacity, q.workers)
+}
+
+// ResizeWithWorkers changes the capacity of the queue and the number of its consumers,
+// returning whether the action was successful
+func (q *BoundedQueue) ResizeWithWorkers(capacity int, workers int) bool {
+	if capacity == q.Capacity() && workers == q.workers {
 		// noop
 		return false
 	}
@@ -192,7 +199,7 @@ func (q *BoundedQueue) Resize(capacity int) bool {
 	swapped := atomic.CompareAndSwapPointer((*unsafe.Pointer)(unsafe.Pointer(&q.items)), unsafe.Pointer(q.items), unsafe.Pointer(&queue))
 	if swapped {
 		// start a new set of consumers, based on the information given previously
-		q.StartConsumersWithFactory(q.workers, q.factory)
+		q.StartConsumersWithFactory(workers, q.factory)
 
 		// gracefully drain the existing queue
 		close(previous)
@@ -268,6 +268,36 @@ func TestResizeDown(t *testing.T) {
 	releaseConsumers.Done()
 }
 
+func TestResizeWithWorkers(t *testing.T) {
+	q := NewBoundedQueue(4, func(item any) {
+		fmt.Printf("dropped: %v\n", item)
+	})
+
+	var consuming sync.WaitGroup
+	release := make(chan struct{})
+	q.StartConsumers(1, func( /* item */ any) {
+		consuming.Done()
+		<-release
+	})
+	defer q.Stop()
+
+	assert.False(t, q.ResizeWithWorkers(4, 1))
+
+	consuming.Add(1)
+	assert.True(t, q.Produce("a")) // in process by the only worker
+	consuming.Wait()
+
+	assert.True(t, q.ResizeWithWorkers(4, 2))
+	assert.EqualValues(t, 4, q.Capacity())
+
+	// the two new workers consume while the previous one is still busy
+	consuming.Add(2)
+	assert.True(t, q.Produce("b"))
+	assert.True(t, q.Produce("c"))
+	consuming.Wait()
+	close(release)
+}
+
 func TestResizeOldQueueIsDrained(t *testing.T) {
 	q := NewBoundedQueue(2, func(item any) {
 		fmt.Printf("dropped: %v\n", item)
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package selfmonitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyWindow records the latest durations of an operation to compute their percentiles.
+type LatencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+	errors  uint64
+}
+
+// LatencyPercentiles are the percentiles of the durations in a LatencyWindow, in milliseconds.
+type LatencyPercentiles struct {
+	// Samples is the number of durations the percentiles are computed from.
+	Samples int     `json:"samples"`
+	Errors  uint64  `json:"errors"`
+	P50     float64 `json:"p50_ms"`
+	P95     float64 `json:"p95_ms"`
+	P99     float64 `json:"p99_ms"`
+	Max     float64 `json:"max_ms"`
+}
+
+// NewLatencyWindow creates a LatencyWindow keeping the last size durations.
+func NewLatencyWindow(size int) *LatencyWindow {
+	return &LatencyWindow{samples: make([]time.Duration, size)}
+}
+
+// Record adds the duration of an operation, counting it as an error if err is not nil.
+func (w *LatencyWindow) Record(duration time.Duration, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.errors++
+	}
+	w.samples[w.next] = duration
+	w.next = (w.next + 1) % len(w.samples)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// Percentiles computes the percentiles of the recorded durations.
+func (w *LatencyWindow) Percentiles() LatencyPercentiles {
+	w.mu.Lock()
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+	samples := make([]time.Duration, n)
+	copy(samples, w.samples[:n])
+	p := LatencyPercentiles{Samples: n, Errors: w.errors}
+	w.mu.Unlock()
+
+	if n == 0 {
+		return p
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	percentile := func(q float64) float64 {
+		// nearest-rank method
+		i := int(q*float64(n)+0.5) - 1
+		i = max(0, min(n-1, i))
+		return milliseconds(samples[i])
+	}
+	p.P50, p.P95, p.P99 = percentile(0.5), percentile(0.95), percentile(0.99)
+	p.Max = milliseconds(samples[n-1])
+	return p
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package selfmonitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyWindowEmpty(t *testing.T) {
+	assert.Equal(t, LatencyPercentiles{}, NewLatencyWindow(10).Percentiles())
+}
+
+func TestLatencyWindowPercentiles(t *testing.T) {
+	w := NewLatencyWindow(100)
+	for i := 100; i > 0; i-- {
+		w.Record(time.Duration(i)*time.Millisecond, nil)
+	}
+	w.Record(time.Second, errors.New("timeout"))
+
+	// the first duration, 100ms, was replaced by the last one
+	assert.Equal(t, LatencyPercentiles{
+		Samples: 100,
+		Errors:  1,
+		P50:     50,
+		P95:     95,
+		P99:     99,
+		Max:     1000,
+	}, w.Percentiles())
+}
+
+func TestLatencyWindowPartial(t *testing.T) {
+	w := NewLatencyWindow(100)
+	w.Record(2*time.Millisecond, nil)
+	w.Record(4*time.Millisecond, nil)
+
+	assert.Equal(t, LatencyPercentiles{Samples: 2, P50: 2, P95: 4, P99: 4, Max: 4}, w.Percentiles())
+}
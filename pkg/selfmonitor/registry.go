@@ -0,0 +1,68 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package selfmonitor collects a snapshot of the internal health of a component, like
+// the depth of its queue or the latency of its storage, served in JSON on the admin
+// server for a status page that does not need to scrape the metrics.
+package selfmonitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Source returns the current status of a part of the component, serialized in JSON.
+type Source func() any
+
+// Snapshot is the status of all the sources at a point in time.
+type Snapshot struct {
+	Time       time.Time      `json:"time"`
+	Components map[string]any `json:"components"`
+}
+
+// Registry holds the sources of the snapshot of a component.
+type Registry struct {
+	mu      sync.RWMutex
+	sources map[string]Source
+	timeNow func() time.Time
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		sources: make(map[string]Source),
+		timeNow: time.Now,
+	}
+}
+
+// Register adds the source under the name, replacing the source previously registered with it.
+func (r *Registry) Register(name string, source Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[name] = source
+}
+
+// Snapshot calls all the sources.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snapshot := Snapshot{
+		Time:       r.timeNow(),
+		Components: make(map[string]any, len(r.sources)),
+	}
+	for name, source := range r.sources {
+		snapshot.Components[name] = source()
+	}
+	return snapshot
+}
+
+// Handler returns a http.Handler serving the Snapshot in JSON.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(r.Snapshot())
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package selfmonitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryHandler(t *testing.T) {
+	r := NewRegistry()
+	r.timeNow = func() time.Time {
+		return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+	r.Register("queue", func() any {
+		return map[string]int{"length": 1}
+	})
+	r.Register("queue", func() any {
+		return map[string]int{"length": 2}
+	})
+	r.Register("consumer", func() any {
+		return map[string]int{"total_lag": 10}
+	})
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status/snapshot", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"time": "2024-06-01T12:00:00Z",
+		"components": {
+			"consumer": {"total_lag": 10},
+			"queue": {"length": 2}
+		}
+	}`, w.Body.String())
+}
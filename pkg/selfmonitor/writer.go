@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package selfmonitor
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// latencySamples is the number of the latest writes whose latency is reported.
+const latencySamples = 1024
+
+// StorageStatus is the status of the span writer of a component.
+type StorageStatus struct {
+	WriteLatency LatencyPercentiles `json:"write_latency"`
+}
+
+// InstrumentSpanWriter decorates the writer of the component to report the latency of
+// its latest writes under the "storage" source.
+func (r *Registry) InstrumentSpanWriter(writer spanstore.Writer) spanstore.Writer {
+	window := NewLatencyWindow(latencySamples)
+	r.Register("storage", func() any {
+		return StorageStatus{WriteLatency: window.Percentiles()}
+	})
+	return NewLatencyWriter(writer, window)
+}
+
+type latencyWriter struct {
+	spanstore.Writer
+	window *LatencyWindow
+}
+
+type latencyBatchWriter struct {
+	latencyWriter
+	batchWriter spanstore.BatchWriter
+}
+
+// NewLatencyWriter decorates the writer to record the latency of its writes in the window.
+// It implements spanstore.BatchWriter if the writer does.
+func NewLatencyWriter(writer spanstore.Writer, window *LatencyWindow) spanstore.Writer {
+	w := latencyWriter{Writer: writer, window: window}
+	if batchWriter, ok := writer.(spanstore.BatchWriter); ok {
+		return &latencyBatchWriter{latencyWriter: w, batchWriter: batchWriter}
+	}
+	return &w
+}
+
+// WriteSpan implements spanstore.Writer.
+func (w *latencyWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	start := time.Now()
+	err := w.Writer.WriteSpan(ctx, span)
+	w.window.Record(time.Since(start), err)
+	return err
+}
+
+// Close closes the writer if it implements io.Closer.
+func (w *latencyWriter) Close() error {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// WriteBatch implements spanstore.BatchWriter.
+func (w *latencyBatchWriter) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	start := time.Now()
+	err := w.batchWriter.WriteBatch(ctx, spans)
+	w.window.Record(time.Since(start), err)
+	return err
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package selfmonitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+type batchWriter struct {
+	spanstoremocks.Writer
+	batches int
+}
+
+func (w *batchWriter) WriteBatch(context.Context, []*model.Span) error {
+	w.batches++
+	return nil
+}
+
+type closableWriter struct {
+	spanstoremocks.Writer
+	closed bool
+}
+
+func (w *closableWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestInstrumentSpanWriter(t *testing.T) {
+	writer := &spanstoremocks.Writer{}
+	writer.On("WriteSpan", mock.Anything, mock.Anything).Return(nil).Once()
+	writer.On("WriteSpan", mock.Anything, mock.Anything).Return(errors.New("timeout")).Once()
+	r := NewRegistry()
+	instrumented := r.InstrumentSpanWriter(writer)
+
+	require.NoError(t, instrumented.WriteSpan(context.Background(), &model.Span{}))
+	require.EqualError(t, instrumented.WriteSpan(context.Background(), &model.Span{}), "timeout")
+
+	status := r.Snapshot().Components["storage"].(StorageStatus)
+	assert.Equal(t, 2, status.WriteLatency.Samples)
+	assert.EqualValues(t, 1, status.WriteLatency.Errors)
+	_, ok := instrumented.(spanstore.BatchWriter)
+	assert.False(t, ok)
+	require.NoError(t, instrumented.(io.Closer).Close())
+}
+
+func TestLatencyWriterBatchWriter(t *testing.T) {
+	writer := &batchWriter{}
+	window := NewLatencyWindow(10)
+	instrumented, ok := NewLatencyWriter(writer, window).(spanstore.BatchWriter)
+	require.True(t, ok)
+
+	require.NoError(t, instrumented.WriteBatch(context.Background(), []*model.Span{{}, {}}))
+	assert.Equal(t, 1, writer.batches)
+	assert.Equal(t, 1, window.Percentiles().Samples)
+}
+
+func TestLatencyWriterClose(t *testing.T) {
+	writer := &closableWriter{}
+	require.NoError(t, NewLatencyWriter(writer, NewLatencyWindow(10)).(io.Closer).Close())
+	assert.True(t, writer.closed)
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Command creates the command printing the metrics of the DefaultRegistry.
+func Command() *cobra.Command {
+	var format string
+	command := &cobra.Command{
+		Use:   "metrics-schema",
+		Short: "Print the documented metrics.",
+		Long:  `Print the names, types and labels of the documented metrics exposed on the /metrics endpoint of the admin server.`,
+		RunE: func(cmd *cobra.Command, _ /* args */ []string) error {
+			switch format {
+			case "markdown":
+				return defaultRegistry.WriteMarkdown(cmd.OutOrStdout())
+			case "json":
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(defaultRegistry.Metrics())
+			default:
+				return fmt.Errorf("unknown format %q, expected markdown or json", format)
+			}
+		},
+	}
+	command.Flags().StringVar(&format, "format", "markdown", "The output format, markdown or json")
+	return command
+}
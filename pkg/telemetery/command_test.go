@@ -0,0 +1,41 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCommand(t *testing.T, args ...string) (string, error) {
+	cmd := Command()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestCommand(t *testing.T) {
+	Register(testCounter)
+
+	out, err := runCommand(t)
+	require.NoError(t, err)
+	assert.Contains(t, out, "| `jaeger_test_requests_total` | counter |")
+
+	out, err = runCommand(t, "--format=json")
+	require.NoError(t, err)
+	var metrics []Metric
+	require.NoError(t, json.Unmarshal([]byte(out), &metrics))
+	assert.Contains(t, metrics, testCounter)
+
+	_, err = runCommand(t, "--format=yaml")
+	require.EqualError(t, err, `unknown format "yaml", expected markdown or json`)
+}
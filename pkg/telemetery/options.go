@@ -0,0 +1,58 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	metricsExporter       = "telemetry.metrics.exporter"
+	metricsExportInterval = "telemetry.metrics.export-interval"
+
+	// ExporterNone disables the export of the OTEL metrics.
+	ExporterNone = "none"
+	// ExporterOTLP exports the OTEL metrics over OTLP/gRPC, to the endpoint configured
+	// with the OTEL_EXPORTER_OTLP_* environment variables.
+	ExporterOTLP = "otlp"
+
+	defaultMetricsExportInterval = time.Minute
+)
+
+// Options configure the export of the telemetry of the components.
+type Options struct {
+	// MetricsExporter is ExporterNone or ExporterOTLP.
+	MetricsExporter       string
+	MetricsExportInterval time.Duration
+}
+
+// AddFlags adds the flags of Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		metricsExporter,
+		ExporterNone,
+		fmt.Sprintf("The exporter of the OTEL metrics of the components, like the receivers of the collector: %s or %s. "+
+			"The OTLP endpoint is configured with the OTEL_EXPORTER_OTLP_* environment variables", ExporterNone, ExporterOTLP))
+	flagSet.Duration(
+		metricsExportInterval,
+		defaultMetricsExportInterval,
+		"The interval between two exports of the OTEL metrics")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) (*Options, error) {
+	o.MetricsExporter = v.GetString(metricsExporter)
+	o.MetricsExportInterval = v.GetDuration(metricsExportInterval)
+	if o.MetricsExporter != ExporterNone && o.MetricsExporter != ExporterOTLP {
+		return o, fmt.Errorf("unknown metrics exporter %q, expected %s or %s", o.MetricsExporter, ExporterNone, ExporterOTLP)
+	}
+	if o.MetricsExportInterval <= 0 {
+		return o, fmt.Errorf("%s must be positive", metricsExportInterval)
+	}
+	return o, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFromViper(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	opts, err := new(Options).InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, &Options{MetricsExporter: ExporterNone, MetricsExportInterval: time.Minute}, opts)
+
+	require.NoError(t, command.ParseFlags([]string{
+		"--telemetry.metrics.exporter=otlp",
+		"--telemetry.metrics.export-interval=10s",
+	}))
+	opts, err = new(Options).InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, &Options{MetricsExporter: ExporterOTLP, MetricsExportInterval: 10 * time.Second}, opts)
+}
+
+func TestOptionsFromViperErrors(t *testing.T) {
+	tests := []struct {
+		flag string
+		err  string
+	}{
+		{flag: "--telemetry.metrics.exporter=prometheus", err: `unknown metrics exporter "prometheus", expected none or otlp`},
+		{flag: "--telemetry.metrics.export-interval=0s", err: "telemetry.metrics.export-interval must be positive"},
+	}
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			v, command := config.Viperize(AddFlags)
+			require.NoError(t, command.ParseFlags([]string{test.flag}))
+			_, err := new(Options).InitFromViper(v)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
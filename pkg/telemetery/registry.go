@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package telemetery documents the metrics exposed by the Jaeger components on the
+// /metrics endpoint of their admin server, so that dashboards can be built ahead
+// of a deployment from their names and labels.
+package telemetery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a metric in the Prometheus exposition format.
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// Label is a label of a metric.
+type Label struct {
+	Name string `json:"name"`
+	// Values are the possible values of the label, empty if unbounded like service names.
+	Values []string `json:"values,omitempty"`
+}
+
+// Metric documents a metric exposed on the /metrics endpoint.
+type Metric struct {
+	// Name is the name of the Prometheus metric, including the _total suffix of the counters.
+	Name   string  `json:"name"`
+	Type   Type    `json:"type"`
+	Help   string  `json:"help"`
+	Labels []Label `json:"labels,omitempty"`
+}
+
+// Registry holds the definitions of the metrics of the packages instrumenting the components.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]Metric
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]Metric)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the registry the instrumented packages register their metrics with.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register registers the metrics with the DefaultRegistry.
+func Register(metrics ...Metric) {
+	defaultRegistry.Register(metrics...)
+}
+
+// Register adds the metrics to the registry. Like prometheus.MustRegister, it panics
+// if a metric is registered twice with different definitions.
+func (r *Registry) Register(metrics ...Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, metric := range metrics {
+		if existing, ok := r.metrics[metric.Name]; ok && !reflect.DeepEqual(existing, metric) {
+			panic(fmt.Sprintf("metric %s registered twice with different definitions", metric.Name))
+		}
+		r.metrics[metric.Name] = metric
+	}
+}
+
+// Metrics returns the registered metrics sorted by name.
+func (r *Registry) Metrics() []Metric {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	metrics := make([]Metric, 0, len(r.metrics))
+	for _, metric := range r.metrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics
+}
+
+// Handler returns a http.Handler serving the registered metrics in JSON.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Metrics []Metric `json:"metrics"`
+		}{r.Metrics()})
+	})
+}
+
+// WriteMarkdown writes the registered metrics as a Markdown table.
+func (r *Registry) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("| Name | Type | Labels | Description |\n")
+	sb.WriteString("|------|------|--------|-------------|\n")
+	for _, metric := range r.Metrics() {
+		labels := make([]string, len(metric.Labels))
+		for i, label := range metric.Labels {
+			labels[i] = "`" + label.Name + "`"
+			if len(label.Values) > 0 {
+				labels[i] += " (" + strings.Join(label.Values, ", ") + ")"
+			}
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s |\n", metric.Name, metric.Type, strings.Join(labels, ", "), metric.Help)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testCounter = Metric{
+		Name:   "jaeger_test_requests_total",
+		Type:   Counter,
+		Help:   "Requests by result",
+		Labels: []Label{{Name: "svc"}, {Name: "result", Values: []string{"ok", "err"}}},
+	}
+	testGauge = Metric{Name: "jaeger_test_queue_length", Type: Gauge, Help: "Queue length"}
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register(testGauge, testCounter)
+	r.Register(testCounter)
+	assert.Equal(t, []Metric{testGauge, testCounter}, r.Metrics())
+
+	assert.PanicsWithValue(t, "metric jaeger_test_queue_length registered twice with different definitions", func() {
+		r.Register(Metric{Name: testGauge.Name, Type: Counter})
+	})
+}
+
+func TestRegistryHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Register(testCounter)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics/schema", nil))
+
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var body struct {
+		Metrics []Metric `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []Metric{testCounter}, body.Metrics)
+}
+
+func TestRegistryWriteMarkdown(t *testing.T) {
+	r := NewRegistry()
+	r.Register(testCounter, testGauge)
+	var sb strings.Builder
+	require.NoError(t, r.WriteMarkdown(&sb))
+	assert.Equal(t, `| Name | Type | Labels | Description |
+|------|------|--------|-------------|
+| `+"`jaeger_test_queue_length`"+` | gauge |  | Queue length |
+| `+"`jaeger_test_requests_total` | counter | `svc`, `result` (ok, err)"+` | Requests by result |
+`, sb.String())
+}
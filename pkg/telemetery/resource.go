@@ -0,0 +1,30 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+
+	"github.com/jaegertracing/jaeger/pkg/version"
+)
+
+// NewResource detects the resource of a component: its service.name and service.version,
+// the host and OS it runs on, and the attributes of the OTEL_RESOURCE_ATTRIBUTES variable.
+func NewResource(ctx context.Context, serviceName string) (*resource.Resource, error) {
+	return resource.New(
+		ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version.Get().GitVersion),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithOSType(),
+		resource.WithFromEnv(),
+	)
+}
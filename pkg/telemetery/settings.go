@@ -0,0 +1,112 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	noopmetric "go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// Setting is the telemetry of a component, passed to the builders of its parts.
+type Setting struct {
+	Logger *zap.Logger
+	// Metrics is the factory of the metrics exposed on the /metrics endpoint, in the jaeger namespace.
+	Metrics metrics.Factory
+	// TracerProvider and MeterProvider are given to the OTEL collector components, see ToOtelComponent.
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Resource       *resource.Resource
+
+	closers []func(context.Context) error
+}
+
+// Params are the parts of the Setting configured by the components themselves.
+type Params struct {
+	ServiceName string
+	Logger      *zap.Logger
+	Metrics     metrics.Factory
+	// TracerProvider is the provider of the jtracer of the component, no-op if nil.
+	TracerProvider trace.TracerProvider
+}
+
+// NoopSettings returns a Setting discarding all the telemetry, for the tests.
+func NoopSettings() Setting {
+	return Setting{
+		Logger:         zap.NewNop(),
+		Metrics:        metrics.NullFactory,
+		TracerProvider: nooptrace.NewTracerProvider(),
+		MeterProvider:  noopmetric.NewMeterProvider(),
+		Resource:       resource.Empty(),
+	}
+}
+
+// New creates the Setting of a component, with a MeterProvider exporting over OTLP
+// when enabled by the options.
+func New(ctx context.Context, params Params, options Options) (Setting, error) {
+	res, err := NewResource(ctx, params.ServiceName)
+	if err != nil {
+		return Setting{}, fmt.Errorf("failed to detect the telemetry resource: %w", err)
+	}
+	set := NoopSettings()
+	set.Logger = params.Logger
+	set.Metrics = params.Metrics
+	set.Resource = res
+	if params.TracerProvider != nil {
+		set.TracerProvider = params.TracerProvider
+	}
+	if options.MetricsExporter == ExporterOTLP {
+		exporter, err := otlpmetricgrpc.New(ctx)
+		if err != nil {
+			return Setting{}, fmt.Errorf("failed to create the OTLP metrics exporter: %w", err)
+		}
+		meterProvider := sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(options.MetricsExportInterval))),
+		)
+		set.MeterProvider = meterProvider
+		set.closers = append(set.closers, meterProvider.Shutdown)
+	}
+	return set, nil
+}
+
+// ToOtelComponent returns the telemetry settings of the OTEL collector components.
+func (s Setting) ToOtelComponent() component.TelemetrySettings {
+	return component.TelemetrySettings{
+		Logger:         s.Logger,
+		TracerProvider: s.TracerProvider,
+		MeterProvider:  s.MeterProvider,
+		Resource:       s.otelResource(),
+	}
+}
+
+func (s Setting) otelResource() pcommon.Resource {
+	res := pcommon.NewResource()
+	for _, attr := range s.Resource.Attributes() {
+		res.Attributes().PutStr(string(attr.Key), attr.Value.Emit())
+	}
+	return res
+}
+
+// Close flushes the telemetry not exported yet and shuts down the exporters.
+func (s Setting) Close(ctx context.Context) error {
+	var errs []error
+	for _, closer := range s.closers {
+		errs = append(errs, closer(ctx))
+	}
+	return errors.Join(errs...)
+}
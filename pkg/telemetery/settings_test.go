@@ -0,0 +1,85 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	noopmetric "go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/version"
+)
+
+func TestNewResource(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=test")
+	res, err := NewResource(context.Background(), "jaeger-test")
+	require.NoError(t, err)
+	attrs := make(map[string]string)
+	for _, attr := range res.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "jaeger-test", attrs["service.name"])
+	assert.Equal(t, version.Get().GitVersion, attrs["service.version"])
+	assert.Equal(t, "test", attrs["deployment.environment"])
+	assert.NotEmpty(t, attrs["host.name"])
+}
+
+func TestNewWithoutExporter(t *testing.T) {
+	logger := zap.NewNop()
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tracerProvider := sdktrace.NewTracerProvider()
+	defer tracerProvider.Shutdown(context.Background())
+
+	set, err := New(context.Background(), Params{
+		ServiceName:    "jaeger-test",
+		Logger:         logger,
+		Metrics:        metricsFactory,
+		TracerProvider: tracerProvider,
+	}, Options{MetricsExporter: ExporterNone})
+	require.NoError(t, err)
+	assert.Same(t, logger, set.Logger)
+	assert.Same(t, metricsFactory, set.Metrics)
+	assert.Same(t, tracerProvider, set.TracerProvider)
+	assert.Equal(t, noopmetric.NewMeterProvider(), set.MeterProvider)
+	require.NoError(t, set.Close(context.Background()))
+}
+
+func TestNewWithOTLPExporter(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+	set, err := New(context.Background(), Params{ServiceName: "jaeger-test", Logger: zap.NewNop()},
+		Options{MetricsExporter: ExporterOTLP, MetricsExportInterval: time.Hour})
+	require.NoError(t, err)
+	assert.IsType(t, &sdkmetric.MeterProvider{}, set.MeterProvider)
+	assert.Equal(t, nooptrace.NewTracerProvider(), set.TracerProvider)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// the shutdown flushes the metrics to the endpoint
+	require.ErrorContains(t, set.Close(ctx), "failed to upload metrics")
+}
+
+func TestToOtelComponent(t *testing.T) {
+	set := NoopSettings()
+	res, err := NewResource(context.Background(), "jaeger-test")
+	require.NoError(t, err)
+	set.Resource = res
+
+	telset := set.ToOtelComponent()
+	assert.Same(t, set.Logger, telset.Logger)
+	assert.Equal(t, set.TracerProvider, telset.TracerProvider)
+	assert.Equal(t, set.MeterProvider, telset.MeterProvider)
+	serviceName, ok := telset.Resource.Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "jaeger-test", serviceName.Str())
+}
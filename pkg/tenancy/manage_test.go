@@ -89,3 +89,18 @@ func TestTenancyValidity(t *testing.T) {
 		})
 	}
 }
+
+func TestTenancySetTenants(t *testing.T) {
+	tc := NewManager(&Options{Enabled: true, Tenants: []string{"acme"}})
+	tc.SetTenants([]string{"country-store"})
+	assert.False(t, tc.Valid("acme"))
+	assert.True(t, tc.Valid("country-store"))
+
+	tc.SetTenants(nil)
+	assert.True(t, tc.Valid("acme"))
+
+	// the tenants are ignored while tenancy is disabled
+	tc = NewManager(&Options{})
+	tc.SetTenants([]string{"acme"})
+	assert.True(t, tc.Valid("country-store"))
+}
@@ -14,6 +14,8 @@
 
 package tenancy
 
+import "sync"
+
 // Options describes the configuration properties for multitenancy
 type Options struct {
 	Enabled bool
@@ -25,7 +27,9 @@ type Options struct {
 type Manager struct {
 	Enabled bool
 	Header  string
-	guard   guard
+
+	mu    sync.RWMutex
+	guard guard
 }
 
 // Guard verifies a valid tenant when tenancy is enabled
@@ -48,9 +52,19 @@ func NewManager(options *Options) *Manager {
 }
 
 func (tc *Manager) Valid(tenant string) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return tc.guard.Valid(tenant)
 }
 
+// SetTenants replaces the list of the valid tenants, any tenant being valid if it is empty.
+func (tc *Manager) SetTenants(tenants []string) {
+	guard := tenancyGuardFactory(&Options{Enabled: tc.Enabled, Tenants: tenants})
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.guard = guard
+}
+
 type tenantDontCare bool
 
 func (tenantDontCare) Valid(string /* candidate */) bool {
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracestream
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Event is the saving of a span of a trace by the collector.
+type Event struct {
+	TraceID model.TraceID
+	Tenant  string
+}
+
+// Broadcaster broadcasts the events of the collector to the subscribers of the query service
+// running in the same process. The events are never blocked on the subscribers: those not
+// keeping up miss them.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives the events of a Broadcaster until it is closed.
+type Subscription struct {
+	broadcaster *Broadcaster
+	events      chan Event
+	dropped     atomic.Uint64
+	closeOnce   sync.Once
+}
+
+// NewBroadcaster creates a Broadcaster without subscribers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Publish sends the event to the subscribers whose buffer is not full.
+func (b *Broadcaster) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for subscription := range b.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe creates a Subscription buffering up to bufferSize events.
+func (b *Broadcaster) Subscribe(bufferSize int) *Subscription {
+	subscription := &Subscription{
+		broadcaster: b,
+		events:      make(chan Event, bufferSize),
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Events returns the channel of the events, closed once the Subscription is.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of the events missed because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes from the Broadcaster.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.broadcaster.mu.Lock()
+		defer s.broadcaster.mu.Unlock()
+		delete(s.broadcaster.subscribers, s)
+		close(s.events)
+	})
+}
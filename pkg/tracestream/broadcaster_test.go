@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracestream
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestBroadcaster(t *testing.T) {
+	b := NewBroadcaster()
+	b.Publish(Event{TraceID: model.NewTraceID(0, 1)})
+
+	s1 := b.Subscribe(2)
+	s2 := b.Subscribe(1)
+	b.Publish(Event{TraceID: model.NewTraceID(0, 2), Tenant: "acme"})
+	b.Publish(Event{TraceID: model.NewTraceID(0, 3)})
+
+	assert.Equal(t, Event{TraceID: model.NewTraceID(0, 2), Tenant: "acme"}, <-s1.Events())
+	assert.Equal(t, Event{TraceID: model.NewTraceID(0, 3)}, <-s1.Events())
+	assert.Zero(t, s1.Dropped())
+	assert.Equal(t, Event{TraceID: model.NewTraceID(0, 2), Tenant: "acme"}, <-s2.Events())
+	assert.Equal(t, uint64(1), s2.Dropped())
+
+	s1.Close()
+	s1.Close()
+	_, ok := <-s1.Events()
+	assert.False(t, ok)
+	b.Publish(Event{TraceID: model.NewTraceID(0, 4)})
+	assert.Equal(t, Event{TraceID: model.NewTraceID(0, 4)}, <-s2.Events())
+	s2.Close()
+}
+
+func TestBroadcasterConcurrentClose(t *testing.T) {
+	b := NewBroadcaster()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			b.Publish(Event{TraceID: model.NewTraceID(0, uint64(i))})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		b.Subscribe(1).Close()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracestream
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const flagRecentTracesMaxTraces = "recent-traces.max-traces"
+
+// RecentTracesOptions holds configuration for RecentTraces.
+type RecentTracesOptions struct {
+	// MaxTraces is the number of the most recent traces buffered per tenant, the buffer is disabled if zero.
+	MaxTraces int
+}
+
+// AddFlags adds flags for RecentTracesOptions.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Int(flagRecentTracesMaxTraces, 0, "The number of the most recent traces of each tenant buffered in memory by the collector and served by /api/traces/recent without querying the span storage; disabled if zero")
+}
+
+// InitFromViper initializes RecentTracesOptions with properties from viper.
+func (o *RecentTracesOptions) InitFromViper(v *viper.Viper) *RecentTracesOptions {
+	o.MaxTraces = v.GetInt(flagRecentTracesMaxTraces)
+	return o
+}
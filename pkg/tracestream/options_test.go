@@ -0,0 +1,21 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracestream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestRecentTracesOptions(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	assert.Zero(t, new(RecentTracesOptions).InitFromViper(v).MaxTraces)
+
+	require.NoError(t, command.ParseFlags([]string{"--recent-traces.max-traces=100"}))
+	assert.Equal(t, &RecentTracesOptions{MaxTraces: 100}, new(RecentTracesOptions).InitFromViper(v))
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracestream
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// RecentTraces buffers the spans of the most recent traces processed by the collector, by tenant,
+// for the query service running in the same process to show them without a round trip to the span
+// storage. Beyond the maximum number of traces of a tenant, the least recently updated one is evicted.
+type RecentTraces struct {
+	maxTraces int
+
+	mu      sync.Mutex
+	tenants map[string]*recentTenant
+}
+
+type recentTenant struct {
+	// order holds the *model.Trace, the most recently updated first.
+	order  *list.List
+	traces map[model.TraceID]*list.Element
+}
+
+// NewRecentTraces creates RecentTraces buffering up to maxTraces traces per tenant.
+func NewRecentTraces(maxTraces int) *RecentTraces {
+	return &RecentTraces{
+		maxTraces: maxTraces,
+		tenants:   make(map[string]*recentTenant),
+	}
+}
+
+// Add buffers the span of the tenant, making its trace the most recent one.
+func (r *RecentTraces) Add(span *model.Span, tenant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tenants[tenant]
+	if !ok {
+		t = &recentTenant{order: list.New(), traces: make(map[model.TraceID]*list.Element)}
+		r.tenants[tenant] = t
+	}
+	if element, ok := t.traces[span.TraceID]; ok {
+		trace := element.Value.(*model.Trace)
+		trace.Spans = append(trace.Spans, span)
+		t.order.MoveToFront(element)
+		return
+	}
+	t.traces[span.TraceID] = t.order.PushFront(&model.Trace{Spans: []*model.Span{span}})
+	for t.order.Len() > r.maxTraces {
+		oldest := t.order.Remove(t.order.Back()).(*model.Trace)
+		delete(t.traces, oldest.Spans[0].TraceID)
+	}
+}
+
+// Get returns copies of the most recent traces of the tenant, the most recently updated first,
+// for which keep returns true, up to limit traces if positive.
+func (r *RecentTraces) Get(tenant string, limit int, keep func(*model.Trace) bool) ([]*model.Trace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tenants[tenant]
+	if !ok {
+		return nil, nil
+	}
+	var traces []*model.Trace
+	for element := t.order.Front(); element != nil && (limit <= 0 || len(traces) < limit); element = element.Next() {
+		trace := element.Value.(*model.Trace)
+		if !keep(trace) {
+			continue
+		}
+		// the spans of the traces are added after they are returned, and adjusted by the callers
+		copied := &model.Trace{}
+		bytes, err := proto.Marshal(trace)
+		if err != nil {
+			return nil, err
+		}
+		if err := proto.Unmarshal(bytes, copied); err != nil {
+			return nil, err
+		}
+		traces = append(traces, copied)
+	}
+	return traces, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracestream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func testSpan(traceID, spanID uint64, service string) *model.Span {
+	return &model.Span{
+		TraceID: model.NewTraceID(0, traceID),
+		SpanID:  model.NewSpanID(spanID),
+		Process: &model.Process{ServiceName: service},
+	}
+}
+
+func traceIDs(traces []*model.Trace) []model.TraceID {
+	ids := make([]model.TraceID, len(traces))
+	for i, trace := range traces {
+		ids[i] = trace.Spans[0].TraceID
+	}
+	return ids
+}
+
+func TestRecentTraces(t *testing.T) {
+	all := func(*model.Trace) bool { return true }
+	r := NewRecentTraces(2)
+	traces, err := r.Get("", 0, all)
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+
+	r.Add(testSpan(1, 1, "frontend"), "")
+	r.Add(testSpan(2, 2, "backend"), "")
+	r.Add(testSpan(1, 3, "backend"), "")
+	r.Add(testSpan(3, 4, "frontend"), "acme")
+
+	traces, err = r.Get("", 0, all)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}, traceIDs(traces))
+	assert.Len(t, traces[0].Spans, 2)
+
+	r.Add(testSpan(4, 5, "frontend"), "")
+	traces, err = r.Get("", 0, all)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 4), model.NewTraceID(0, 1)}, traceIDs(traces), "the least recently updated trace is evicted")
+
+	traces, err = r.Get("", 1, all)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 4)}, traceIDs(traces))
+
+	traces, err = r.Get("", 0, func(trace *model.Trace) bool { return len(trace.Spans) > 1 })
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs(traces))
+
+	traces, err = r.Get("acme", 0, all)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 3)}, traceIDs(traces))
+
+	traces[0].Spans[0].OperationName = "changed"
+	traces, err = r.Get("acme", 0, all)
+	require.NoError(t, err)
+	assert.Empty(t, traces[0].Spans[0].OperationName, "the traces are copied")
+}
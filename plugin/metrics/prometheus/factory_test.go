@@ -90,11 +90,13 @@ func TestWithConfiguration(t *testing.T) {
 		err := command.ParseFlags([]string{
 			"--prometheus.query.namespace=mynamespace",
 			"--prometheus.query.duration-unit=ms",
+			"--prometheus.query.adjusted-count-label=sampling_adjusted_count",
 		})
 		require.NoError(t, err)
 		f.InitFromViper(v, zap.NewNop())
 		assert.Equal(t, "mynamespace", f.options.Primary.MetricNamespace)
 		assert.Equal(t, "ms", f.options.Primary.LatencyUnit)
+		assert.Equal(t, "sampling_adjusted_count", f.options.Primary.AdjustedCountLabel)
 	})
 	t.Run("with invalid prometheus.query.duration-unit", func(t *testing.T) {
 		defer func() {
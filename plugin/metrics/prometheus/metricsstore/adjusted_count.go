@@ -0,0 +1,110 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metricsstore
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+)
+
+// scaleByAdjustedCount multiplies the values of the series by the adjusted count held in their
+// label, 1 if they have no valid adjusted count, and sums up the series which only differ by it.
+// The values which are not a matrix are returned unchanged.
+func scaleByAdjustedCount(mv model.Value, label model.LabelName) model.Value {
+	matrix, ok := mv.(model.Matrix)
+	if !ok {
+		return mv
+	}
+	series := make(map[model.Fingerprint]*scaledSeries)
+	fingerprints := make([]model.Fingerprint, 0, len(matrix))
+	for _, stream := range matrix {
+		adjustedCount := parseAdjustedCount(stream.Metric[label])
+		metric := stream.Metric.Clone()
+		delete(metric, label)
+		fingerprint := metric.Fingerprint()
+		s, ok := series[fingerprint]
+		if !ok {
+			s = &scaledSeries{metric: metric, values: make(map[model.Time]float64)}
+			series[fingerprint] = s
+			fingerprints = append(fingerprints, fingerprint)
+		}
+		for _, pair := range stream.Values {
+			s.values[pair.Timestamp] += adjustedCount * float64(pair.Value)
+		}
+	}
+	scaled := make(model.Matrix, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		scaled = append(scaled, series[fingerprint].sampleStream())
+	}
+	return scaled
+}
+
+// divideMatrices divides the values of the series of dividends by the values of the series of
+// divisors with the same labels at the same timestamps, like the PromQL division operator.
+// The values which are not matrices are returned unchanged.
+func divideMatrices(dividends model.Value, divisors model.Value) model.Value {
+	dividendMatrix, ok := dividends.(model.Matrix)
+	if !ok {
+		return dividends
+	}
+	divisorMatrix, ok := divisors.(model.Matrix)
+	if !ok {
+		return divisors
+	}
+	divisorValues := make(map[model.Fingerprint]map[model.Time]model.SampleValue, len(divisorMatrix))
+	for _, stream := range divisorMatrix {
+		values := make(map[model.Time]model.SampleValue, len(stream.Values))
+		for _, pair := range stream.Values {
+			values[pair.Timestamp] = pair.Value
+		}
+		divisorValues[stream.Metric.Fingerprint()] = values
+	}
+	quotients := make(model.Matrix, 0, len(dividendMatrix))
+	for _, stream := range dividendMatrix {
+		values, ok := divisorValues[stream.Metric.Fingerprint()]
+		if !ok {
+			continue
+		}
+		quotient := &model.SampleStream{Metric: stream.Metric}
+		for _, pair := range stream.Values {
+			if divisor, ok := values[pair.Timestamp]; ok {
+				quotient.Values = append(quotient.Values, model.SamplePair{Timestamp: pair.Timestamp, Value: pair.Value / divisor})
+			}
+		}
+		if len(quotient.Values) > 0 {
+			quotients = append(quotients, quotient)
+		}
+	}
+	return quotients
+}
+
+// parseAdjustedCount returns the adjusted count of the label value, or 1 if it is not a valid
+// adjusted count, like model.Span.GetAdjustedCount.
+func parseAdjustedCount(value model.LabelValue) float64 {
+	if value == "" {
+		return 1
+	}
+	adjustedCount, err := strconv.ParseFloat(string(value), 64)
+	if err != nil || adjustedCount < 0 || math.IsInf(adjustedCount, 0) || math.IsNaN(adjustedCount) {
+		return 1
+	}
+	return adjustedCount
+}
+
+type scaledSeries struct {
+	metric model.Metric
+	values map[model.Time]float64
+}
+
+func (s *scaledSeries) sampleStream() *model.SampleStream {
+	stream := &model.SampleStream{Metric: s.metric, Values: make([]model.SamplePair, 0, len(s.values))}
+	for timestamp, value := range s.values {
+		stream.Values = append(stream.Values, model.SamplePair{Timestamp: timestamp, Value: model.SampleValue(value)})
+	}
+	sort.Slice(stream.Values, func(i, j int) bool { return stream.Values[i].Timestamp < stream.Values[j].Timestamp })
+	return stream
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metricsstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+)
+
+func stream(metric model.Metric, values ...float64) *model.SampleStream {
+	s := &model.SampleStream{Metric: metric}
+	for i, v := range values {
+		s.Values = append(s.Values, model.SamplePair{Timestamp: model.Time(i * 1000), Value: model.SampleValue(v)})
+	}
+	return s
+}
+
+func TestScaleByAdjustedCount(t *testing.T) {
+	matrix := model.Matrix{
+		stream(model.Metric{"service_name": "frontend"}, 1, 2),
+		stream(model.Metric{"service_name": "frontend", "sampling_adjusted_count": "4"}, 1, 1),
+		stream(model.Metric{"service_name": "backend", "sampling_adjusted_count": "2.5"}, 2),
+		stream(model.Metric{"service_name": "backend", "sampling_adjusted_count": "invalid"}, 1),
+		stream(model.Metric{"service_name": "db", "sampling_adjusted_count": "-1"}, 3),
+	}
+	assert.Equal(t, model.Matrix{
+		stream(model.Metric{"service_name": "frontend"}, 5, 6),
+		stream(model.Metric{"service_name": "backend"}, 6),
+		stream(model.Metric{"service_name": "db"}, 3),
+	}, scaleByAdjustedCount(matrix, "sampling_adjusted_count"))
+
+	vector := model.Vector{}
+	assert.Equal(t, vector, scaleByAdjustedCount(vector, "sampling_adjusted_count"))
+}
+
+func TestDivideMatrices(t *testing.T) {
+	dividends := model.Matrix{
+		stream(model.Metric{"service_name": "frontend"}, 1, 3),
+		stream(model.Metric{"service_name": "backend"}, 1),
+	}
+	divisors := model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"service_name": "frontend"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 4}},
+		},
+	}
+	assert.Equal(t, model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"service_name": "frontend"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 0.75}},
+		},
+	}, divideMatrices(dividends, divisors))
+
+	vector := model.Vector{}
+	assert.Equal(t, vector, divideMatrices(vector, divisors))
+	assert.Equal(t, vector, divideMatrices(dividends, vector))
+}
+
+// startAdjustedCountPrometheusServer responds to the queries with the series of their results.
+func startAdjustedCountPrometheusServer(t *testing.T, results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		defer r.Body.Close()
+		q, err := url.ParseQuery(string(body))
+		require.NoError(t, err)
+		result, ok := results[q.Get("query")]
+		assert.True(t, ok, "unexpected query %s", q.Get("query"))
+		fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "matrix", "result": [%s]}}`, result)
+	}))
+}
+
+func newAdjustedCountReader(t *testing.T, server *httptest.Server) *MetricsReader {
+	tracer, _, closer := tracerProvider(t)
+	t.Cleanup(closer)
+	cfg := defaultConfig
+	cfg.ServerURL = server.URL
+	cfg.ConnectTimeout = defaultTimeout
+	cfg.AdjustedCountLabel = "sampling_adjusted_count"
+	reader, err := NewMetricsReader(cfg, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	return reader
+}
+
+func metricValue(t *testing.T, m *metrics.MetricFamily) float64 {
+	require.Len(t, m.Metrics, 1)
+	require.Len(t, m.Metrics[0].MetricPoints, 1)
+	return m.Metrics[0].MetricPoints[0].Value.(*metrics.MetricPoint_GaugeValue).GaugeValue.Value.(*metrics.GaugeValue_DoubleValue).DoubleValue
+}
+
+func TestGetCallRatesAdjustedCount(t *testing.T) {
+	server := startAdjustedCountPrometheusServer(t, map[string]string{
+		`sum(rate(calls{service_name =~ "emailservice", span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,sampling_adjusted_count)`: `
+			{"metric": {"service_name": "emailservice"}, "values": [[1620351786, "1"]]},
+			{"metric": {"service_name": "emailservice", "sampling_adjusted_count": "10"}, "values": [[1620351786, "2"]]}`,
+	})
+	defer server.Close()
+	reader := newAdjustedCountReader(t, server)
+
+	m, err := reader.GetCallRates(context.Background(), &metricsstore.CallRateQueryParameters{
+		BaseQueryParameters: buildTestBaseQueryParametersFrom(metricsTestCase{
+			serviceNames: []string{"emailservice"},
+			spanKinds:    []string{"SPAN_KIND_SERVER"},
+		}),
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 21, metricValue(t, m), 1e-9)
+	assert.Equal(t, []*metrics.Label{{Name: "service_name", Value: "emailservice"}}, m.Metrics[0].Labels)
+}
+
+func TestGetErrorRatesAdjustedCount(t *testing.T) {
+	server := startAdjustedCountPrometheusServer(t, map[string]string{
+		`sum(rate(calls{service_name =~ "emailservice", status_code = "STATUS_CODE_ERROR", span_kind =~ "SPAN_KIND_SERVER"}[10m])) ` +
+			`by (service_name,sampling_adjusted_count)`: `
+			{"metric": {"service_name": "emailservice", "sampling_adjusted_count": "10"}, "values": [[1620351786, "1"]]}`,
+		`sum(rate(calls{service_name =~ "emailservice", span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,sampling_adjusted_count)`: `
+			{"metric": {"service_name": "emailservice"}, "values": [[1620351786, "20"]]},
+			{"metric": {"service_name": "emailservice", "sampling_adjusted_count": "10"}, "values": [[1620351786, "2"]]}`,
+	})
+	defer server.Close()
+	reader := newAdjustedCountReader(t, server)
+
+	m, err := reader.GetErrorRates(context.Background(), &metricsstore.ErrorRateQueryParameters{
+		BaseQueryParameters: buildTestBaseQueryParametersFrom(metricsTestCase{
+			serviceNames: []string{"emailservice"},
+			spanKinds:    []string{"SPAN_KIND_SERVER"},
+		}),
+	})
+	require.NoError(t, err)
+	// 10 errors out of 40 calls, instead of 1 error out of 22 sampled calls
+	assert.InDelta(t, 0.25, metricValue(t, m), 1e-9)
+	assert.Equal(t, "service_error_rate", m.Name)
+}
+
+func TestGetErrorRatesAdjustedCountDivisorError(t *testing.T) {
+	errorsQuery := `sum(rate(calls{service_name =~ "emailservice", status_code = "STATUS_CODE_ERROR", span_kind =~ "SPAN_KIND_SERVER"}[10m])) ` +
+		`by (service_name,sampling_adjusted_count)`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		q, _ := url.ParseQuery(string(body))
+		if q.Get("query") != errorsQuery {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"status": "success", "data": {"resultType": "matrix", "result": []}}`)
+	}))
+	defer server.Close()
+	reader := newAdjustedCountReader(t, server)
+
+	_, err := reader.GetErrorRates(context.Background(), &metricsstore.ErrorRateQueryParameters{
+		BaseQueryParameters: buildTestBaseQueryParametersFrom(metricsTestCase{
+			serviceNames: []string{"emailservice"},
+			spanKinds:    []string{"SPAN_KIND_SERVER"},
+		}),
+	})
+	require.ErrorContains(t, err, "failed getting error metrics: failed executing metrics query")
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/prometheus/client_golang/api"
 	promapi "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
@@ -56,6 +57,8 @@ type (
 		latencyMetricName string
 		callsMetricName   string
 		operationLabel    string // name of the attribute that contains span name / operation
+		// adjustedCountLabel is the label of the calls metric holding the adjusted count of the sampled spans
+		adjustedCountLabel string
 	}
 
 	promQueryParams struct {
@@ -71,6 +74,11 @@ type (
 		metricName        string
 		metricDesc        string
 		buildPromQuery    func(p promQueryParams) string
+		// adjustedCounts scales the results by the adjusted count label of their series, if configured
+		adjustedCounts bool
+		// buildDivisorPromQuery builds the query of the series the results are divided by, after
+		// both are scaled by the adjusted counts
+		buildDivisorPromQuery func(p promQueryParams) string
 	}
 )
 
@@ -101,6 +109,8 @@ func NewMetricsReader(cfg config.Configuration, logger *zap.Logger, tracer trace
 		callsMetricName:   buildFullCallsMetricName(cfg),
 		latencyMetricName: buildFullLatencyMetricName(cfg),
 		operationLabel:    operationLabel,
+
+		adjustedCountLabel: cfg.AdjustedCountLabel,
 	}
 
 	logger.Info("Prometheus reader initialized", zap.String("addr", cfg.ServerURL))
@@ -157,21 +167,24 @@ func (m MetricsReader) GetCallRates(ctx context.Context, requestParams *metricss
 		BaseQueryParameters: requestParams.BaseQueryParameters,
 		metricName:          "service_call_rate",
 		metricDesc:          "calls/sec, grouped by service",
-		buildPromQuery: func(p promQueryParams) string {
-			return fmt.Sprintf(
-				// Note: p.spanKindFilter can be ""; trailing commas are okay within a timeseries selection.
-				`sum(rate(%s{service_name =~ "%s", %s}[%s])) by (%s)`,
-				m.callsMetricName,
-				p.serviceFilter,
-				p.spanKindFilter,
-				p.rate,
-				p.groupBy,
-			)
-		},
+		buildPromQuery:      m.buildCallRatePromQuery,
+		adjustedCounts:      true,
 	}
 	return m.executeQuery(ctx, metricsParams)
 }
 
+func (m MetricsReader) buildCallRatePromQuery(p promQueryParams) string {
+	return fmt.Sprintf(
+		// Note: p.spanKindFilter can be ""; trailing commas are okay within a timeseries selection.
+		`sum(rate(%s{service_name =~ "%s", %s}[%s])) by (%s)`,
+		m.callsMetricName,
+		p.serviceFilter,
+		p.spanKindFilter,
+		p.rate,
+		p.groupBy,
+	)
+}
+
 func buildFullCallsMetricName(cfg config.Configuration) string {
 	metricName := "calls"
 	if cfg.MetricNamespace != "" {
@@ -200,6 +213,17 @@ func (m MetricsReader) GetErrorRates(ctx context.Context, requestParams *metrics
 			)
 		},
 	}
+	if m.adjustedCountLabel != "" {
+		// the errors and the calls are scaled by their adjusted counts before being divided
+		metricsParams.buildPromQuery = func(p promQueryParams) string {
+			return fmt.Sprintf(
+				`sum(rate(%s{service_name =~ "%s", status_code = "STATUS_CODE_ERROR", %s}[%s])) by (%s)`,
+				m.callsMetricName, p.serviceFilter, p.spanKindFilter, p.rate, p.groupBy,
+			)
+		}
+		metricsParams.buildDivisorPromQuery = m.buildCallRatePromQuery
+		metricsParams.adjustedCounts = true
+	}
 	errorMetrics, err := m.executeQuery(ctx, metricsParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting error metrics: %w", err)
@@ -245,8 +269,27 @@ func (m MetricsReader) executeQuery(ctx context.Context, p metricsQueryParams) (
 		p.metricName = strings.Replace(p.metricName, "service", "service_operation", 1)
 		p.metricDesc += " & operation"
 	}
-	promQuery := m.buildPromQuery(p)
+	mv, err := m.queryRange(ctx, p, m.buildPromQuery(p, p.buildPromQuery))
+	if err != nil {
+		return &metrics.MetricFamily{}, err
+	}
+	if p.buildDivisorPromQuery != nil {
+		divisors, err := m.queryRange(ctx, p, m.buildPromQuery(p, p.buildDivisorPromQuery))
+		if err != nil {
+			return &metrics.MetricFamily{}, err
+		}
+		mv = divideMatrices(mv, divisors)
+	}
 
+	return m.metricsTranslator.ToDomainMetricsFamily(
+		p.metricName,
+		p.metricDesc,
+		mv,
+	)
+}
+
+// queryRange executes a query over the time range of the parameters, scaling its results by the adjusted counts if requested.
+func (m MetricsReader) queryRange(ctx context.Context, p metricsQueryParams, promQuery string) (model.Value, error) {
 	ctx, span := startSpanForQuery(ctx, p.metricName, promQuery, m.tracer)
 	defer span.End()
 
@@ -260,7 +303,7 @@ func (m MetricsReader) executeQuery(ctx context.Context, p metricsQueryParams) (
 	if err != nil {
 		err = fmt.Errorf("failed executing metrics query: %w", err)
 		logErrorToSpan(span, err)
-		return &metrics.MetricFamily{}, err
+		return nil, err
 	}
 	if len(warnings) > 0 {
 		m.logger.Warn("Warnings detected on Prometheus query", zap.Any("warnings", warnings), zap.String("query", promQuery), zap.Any("range", queryRange))
@@ -268,14 +311,17 @@ func (m MetricsReader) executeQuery(ctx context.Context, p metricsQueryParams) (
 
 	m.logger.Debug("Prometheus query results", zap.String("results", mv.String()), zap.String("query", promQuery), zap.Any("range", queryRange))
 
-	return m.metricsTranslator.ToDomainMetricsFamily(
-		p.metricName,
-		p.metricDesc,
-		mv,
-	)
+	if m.scalesByAdjustedCount(p) {
+		mv = scaleByAdjustedCount(mv, model.LabelName(m.adjustedCountLabel))
+	}
+	return mv, nil
 }
 
-func (m MetricsReader) buildPromQuery(metricsParams metricsQueryParams) string {
+func (m MetricsReader) scalesByAdjustedCount(p metricsQueryParams) bool {
+	return p.adjustedCounts && m.adjustedCountLabel != ""
+}
+
+func (m MetricsReader) buildPromQuery(metricsParams metricsQueryParams, buildPromQuery func(p promQueryParams) string) string {
 	groupBy := []string{"service_name"}
 	if metricsParams.GroupByOperation {
 		groupBy = append(groupBy, m.operationLabel)
@@ -284,6 +330,10 @@ func (m MetricsReader) buildPromQuery(metricsParams metricsQueryParams) string {
 		// Group by the bucket value ("le" => "less than or equal to").
 		groupBy = append(groupBy, "le")
 	}
+	if m.scalesByAdjustedCount(metricsParams) {
+		// the series are summed up after being scaled by their adjusted counts
+		groupBy = append(groupBy, m.adjustedCountLabel)
+	}
 
 	spanKindFilter := ""
 	if len(metricsParams.SpanKinds) > 0 {
@@ -295,7 +345,7 @@ func (m MetricsReader) buildPromQuery(metricsParams metricsQueryParams) string {
 		rate:           promqlDurationString(metricsParams.RatePer),
 		groupBy:        strings.Join(groupBy, ","),
 	}
-	return metricsParams.buildPromQuery(promParams)
+	return buildPromQuery(promParams)
 }
 
 // promqlDurationString formats the duration string to be promQL-compliant.
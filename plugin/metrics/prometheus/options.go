@@ -32,10 +32,11 @@ const (
 	suffixTokenFilePath       = ".token-file"
 	suffixOverrideFromContext = ".token-override-from-context"
 
-	suffixMetricNamespace   = ".query.namespace"
-	suffixLatencyUnit       = ".query.duration-unit"
-	suffixNormalizeCalls    = ".query.normalize-calls"
-	suffixNormalizeDuration = ".query.normalize-duration"
+	suffixMetricNamespace    = ".query.namespace"
+	suffixLatencyUnit        = ".query.duration-unit"
+	suffixNormalizeCalls     = ".query.normalize-calls"
+	suffixNormalizeDuration  = ".query.normalize-duration"
+	suffixAdjustedCountLabel = ".query.adjusted-count-label"
 
 	defaultServerURL      = "http://localhost:9090"
 	defaultConnectTimeout = 30 * time.Second
@@ -46,6 +47,7 @@ const (
 	defaultLatencyUnit                 = "ms"
 	defaultNormalizeCalls              = false
 	defaultNormalizeDuration           = false
+	defaultAdjustedCountLabel          = ""
 )
 
 type namespaceConfig struct {
@@ -107,6 +109,10 @@ func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 			`https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/main/pkg/translator/prometheus/README.md. `+
 			`For example: `+
 			`"duration_bucket" (not normalized) -> "duration_milliseconds_bucket (normalized)"`)
+	flagSet.String(nsConfig.namespace+suffixAdjustedCountLabel, defaultAdjustedCountLabel,
+		`The label of the "calls" metric holding the adjusted count of the sampled spans, e.g. "sampling_adjusted_count" `+
+			`when the spanmetrics connector has the "sampling.adjusted_count" dimension. `+
+			`If set, the call rates and error rates are scaled by the adjusted counts to reflect the actual traffic.`)
 
 	nsConfig.getTLSFlagsConfig().AddFlags(flagSet)
 }
@@ -122,6 +128,7 @@ func (opt *Options) InitFromViper(v *viper.Viper) error {
 	cfg.LatencyUnit = v.GetString(cfg.namespace + suffixLatencyUnit)
 	cfg.NormalizeCalls = v.GetBool(cfg.namespace + suffixNormalizeCalls)
 	cfg.NormalizeDuration = v.GetBool(cfg.namespace + suffixNormalizeDuration)
+	cfg.AdjustedCountLabel = v.GetString(cfg.namespace + suffixAdjustedCountLabel)
 	cfg.TokenOverrideFromContext = v.GetBool(cfg.namespace + suffixOverrideFromContext)
 
 	isValidUnit := map[string]bool{"ms": true, "s": true}
@@ -94,7 +94,7 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, ssFactory storage.S
 
 // CreateStrategyProvider implements samplingstrategy.Factory
 func (f *Factory) CreateStrategyProvider() (samplingstrategy.Provider, samplingstrategy.Aggregator, error) {
-	s := NewProvider(*f.options, f.logger, f.participant, f.store)
+	s := NewProvider(*f.options, f.logger, f.metricsFactory, f.participant, f.store)
 	a, err := NewAggregator(*f.options, f.logger, f.metricsFactory, f.participant, f.store)
 	if err != nil {
 		return nil, nil, err
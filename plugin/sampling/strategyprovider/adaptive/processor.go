@@ -41,6 +41,9 @@ const (
 	serviceCacheSize = 25
 
 	defaultResourceName = "sampling_store_leader"
+
+	probabilityIncrease = "increase"
+	probabilityDecrease = "decrease"
 )
 
 var (
@@ -103,6 +106,8 @@ type PostAggregator struct {
 
 	operationsCalculatedGauge     metrics.Gauge
 	calculateProbabilitiesLatency metrics.Timer
+	probabilityIncreases          metrics.Counter
+	probabilityDecreases          metrics.Counter
 	lastCheckedTime               time.Time
 }
 
@@ -122,6 +127,9 @@ func newPostAggregator(
 		return nil, errBucketsForCalculation
 	}
 	metricsFactory = metricsFactory.Namespace(metrics.NSOptions{Name: "adaptive_sampling_processor"})
+	probabilityChanges := func(direction string) metrics.Counter {
+		return metricsFactory.Counter(metrics.Options{Name: "probability_changes", Tags: map[string]string{"direction": direction}})
+	}
 	return &PostAggregator{
 		Options:             opts,
 		storage:             storage,
@@ -136,6 +144,8 @@ func newPostAggregator(
 		serviceCache:                  []SamplingCache{},
 		operationsCalculatedGauge:     metricsFactory.Gauge(metrics.Options{Name: "operations_calculated"}),
 		calculateProbabilitiesLatency: metricsFactory.Timer(metrics.TimerOptions{Name: "calculate_probabilities"}),
+		probabilityIncreases:          probabilityChanges(probabilityIncrease),
+		probabilityDecreases:          probabilityChanges(probabilityDecrease),
 		shutdown:                      make(chan struct{}),
 	}, nil
 }
@@ -164,11 +174,13 @@ func (p *Provider) loadProbabilities() {
 	probabilities, err := p.storage.GetLatestProbabilities()
 	if err != nil {
 		p.logger.Warn("failed to initialize probabilities", zap.Error(err))
+		p.metrics.loadFailures.Inc(1)
 		return
 	}
 	p.Lock()
 	defer p.Unlock()
 	p.probabilities = probabilities
+	p.metrics.lastUpdate.Update(time.Now().Unix())
 }
 
 // runUpdateProbabilitiesLoop is a loop that reads probabilities from storage.
@@ -425,7 +437,14 @@ func (p *PostAggregator) calculateProbability(service, operation string, qps flo
 	} else {
 		newProbability = p.probabilityCalculator.Calculate(p.TargetSamplesPerSecond, qps, oldProbability)
 	}
-	return math.Min(maxSamplingProbability, math.Max(p.MinSamplingProbability, newProbability))
+	newProbability = math.Min(maxSamplingProbability, math.Max(p.MinSamplingProbability, newProbability))
+	switch {
+	case newProbability > oldProbability:
+		p.probabilityIncreases.Inc(1)
+	case newProbability < oldProbability:
+		p.probabilityDecreases.Inc(1)
+	}
+	return newProbability
 }
 
 // is actual value within p.DeltaTolerance percentage of expected value.
@@ -494,6 +513,7 @@ func (p *Provider) generateStrategyResponses() {
 	p.Lock()
 	defer p.Unlock()
 	p.strategyResponses = strategies
+	p.metrics.services.Update(int64(len(strategies)))
 }
 
 func (p *Provider) generateDefaultSamplingStrategyResponse() *api_v2.SamplingStrategyResponse {
@@ -268,12 +268,15 @@ func TestCalculateProbability(t *testing.T) {
 		InitialSamplingProbability: 0.001,
 		MinSamplingProbability:     0.00001,
 	}
+	mets := metricstest.NewFactory(0)
 	p := &PostAggregator{
 		Options:               cfg,
 		probabilities:         probabilities,
 		probabilityCalculator: testCalculator(),
 		throughputs:           throughputs,
 		serviceCache:          []SamplingCache{{"svcA": {}, "svcB": {}}},
+		probabilityIncreases:  mets.Counter(metrics.Options{Name: "increases"}),
+		probabilityDecreases:  mets.Counter(metrics.Options{Name: "decreases"}),
 	}
 	tests := []struct {
 		service             string
@@ -293,6 +296,10 @@ func TestCalculateProbability(t *testing.T) {
 		probability := p.calculateProbability(test.service, test.operation, test.qps)
 		assert.Equal(t, test.expectedProbability, probability, test.errMsg)
 	}
+	mets.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "increases", Value: 2},
+		metricstest.ExpectedMetric{Name: "decreases", Value: 3},
+	)
 }
 
 func TestCalculateProbabilitiesAndQPS(t *testing.T) {
@@ -318,6 +325,8 @@ func TestCalculateProbabilitiesAndQPS(t *testing.T) {
 		throughputs: testThroughputBuckets(), probabilities: prevProbabilities, qps: qps,
 		weightVectorCache: NewWeightVectorCache(), probabilityCalculator: testCalculator(),
 		operationsCalculatedGauge: mets.Gauge(metrics.Options{Name: "test"}),
+		probabilityIncreases:      mets.Counter(metrics.Options{Name: "increases"}),
+		probabilityDecreases:      mets.Counter(metrics.Options{Name: "decreases"}),
 	}
 	probabilities, qps := p.calculateProbabilitiesAndQPS()
 
@@ -420,12 +429,34 @@ func TestLoadProbabilities(t *testing.T) {
 	mockStorage := &smocks.Store{}
 	mockStorage.On("GetLatestProbabilities").Return(make(model.ServiceOperationProbabilities), nil)
 
-	p := &Provider{storage: mockStorage}
+	p := &Provider{storage: mockStorage, metrics: newProviderMetrics(metrics.NullFactory)}
 	require.Nil(t, p.probabilities)
 	p.loadProbabilities()
 	require.NotNil(t, p.probabilities)
 }
 
+func TestLoadProbabilitiesMetrics(t *testing.T) {
+	mockStorage := &smocks.Store{}
+	mockStorage.On("GetLatestProbabilities").Return(model.ServiceOperationProbabilities{}, errTestStorage()).Once()
+	mockStorage.On("GetLatestProbabilities").Return(model.ServiceOperationProbabilities{
+		"svcA": map[string]float64{"GET": 0.5},
+	}, nil).Once()
+
+	mets := metricstest.NewFactory(0)
+	defer mets.Stop()
+	p := &Provider{storage: mockStorage, logger: zap.NewNop(), metrics: newProviderMetrics(mets)}
+	p.loadProbabilities()
+	mets.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "adaptive_sampling_provider.load_failures", Value: 1})
+	_, gauges := mets.Snapshot()
+	assert.Zero(t, gauges["adaptive_sampling_provider.last_update_timestamp_seconds"])
+
+	p.loadProbabilities()
+	p.generateStrategyResponses()
+	_, gauges = mets.Snapshot()
+	assert.NotZero(t, gauges["adaptive_sampling_provider.last_update_timestamp_seconds"])
+	assert.EqualValues(t, 1, gauges["adaptive_sampling_provider.services"])
+}
+
 func TestRunUpdateProbabilitiesLoop(t *testing.T) {
 	mockStorage := &smocks.Store{}
 	mockStorage.On("GetLatestProbabilities").Return(make(model.ServiceOperationProbabilities), nil)
@@ -439,6 +470,7 @@ func TestRunUpdateProbabilitiesLoop(t *testing.T) {
 		shutdown:                make(chan struct{}),
 		followerRefreshInterval: time.Millisecond,
 		electionParticipant:     mockEP,
+		metrics:                 newProviderMetrics(metrics.NullFactory),
 	}
 	defer close(p.shutdown)
 	require.Nil(t, p.probabilities)
@@ -488,7 +520,7 @@ func TestRealisticRunCalculationLoop(t *testing.T) {
 		AggregationBuckets:         1,
 		Delay:                      time.Second * 10,
 	}
-	s := NewProvider(cfg, logger, mockEP, mockStorage)
+	s := NewProvider(cfg, logger, metrics.NullFactory, mockEP, mockStorage)
 	s.Start()
 
 	for i := 0; i < 100; i++ {
@@ -566,6 +598,7 @@ func TestGenerateStrategyResponses(t *testing.T) {
 	}
 	p := &Provider{
 		probabilities: probabilities,
+		metrics:       newProviderMetrics(metrics.NullFactory),
 		Options: Options{
 			InitialSamplingProbability: 0.001,
 			MinSamplesPerSecond:        0.0001,
@@ -656,6 +689,8 @@ func TestCalculateProbabilitiesAndQPSMultiple(t *testing.T) {
 		probabilityCalculator:     calculationstrategy.NewPercentageIncreaseCappedCalculator(1.0),
 		serviceCache:              []SamplingCache{},
 		operationsCalculatedGauge: metrics.NullFactory.Gauge(metrics.Options{}),
+		probabilityIncreases:      metrics.NullCounter,
+		probabilityDecreases:      metrics.NullCounter,
 	}
 
 	probabilities, qps := p.calculateProbabilitiesAndQPS()
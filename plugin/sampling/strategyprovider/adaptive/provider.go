@@ -21,6 +21,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/plugin/sampling/leaderelection"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
@@ -28,6 +30,52 @@ import (
 
 const defaultFollowerProbabilityInterval = 20 * time.Second
 
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_adaptive_sampling_provider_last_update_timestamp_seconds",
+			Type: telemetery.Gauge,
+			Help: "Unix time of the last load of the adaptive sampling probabilities from the storage, to compute the age of the served strategies",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_adaptive_sampling_provider_load_failures_total",
+			Type: telemetery.Counter,
+			Help: "Failed loads of the adaptive sampling probabilities from the storage",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_adaptive_sampling_provider_services",
+			Type: telemetery.Gauge,
+			Help: "Number of services with adaptive sampling strategies",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_adaptive_sampling_processor_operations_calculated",
+			Type: telemetery.Gauge,
+			Help: "Number of operations whose sampling probability was calculated by the leader",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_adaptive_sampling_processor_calculate_probabilities",
+			Type: telemetery.Histogram,
+			Help: "Duration in seconds of the calculation of the sampling probabilities by the leader",
+		},
+		telemetery.Metric{
+			Name:   "jaeger_collector_adaptive_sampling_processor_probability_changes_total",
+			Type:   telemetery.Counter,
+			Help:   "Sampling probabilities of operations changed by the leader, by direction",
+			Labels: []telemetery.Label{{Name: "direction", Values: []string{probabilityIncrease, probabilityDecrease}}},
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_sampling_operations_total",
+			Type: telemetery.Counter,
+			Help: "Operations whose throughput was aggregated",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_sampling_services_total",
+			Type: telemetery.Counter,
+			Help: "Services whose throughput was aggregated",
+		},
+	)
+}
+
 type Provider struct {
 	sync.RWMutex
 	Options
@@ -49,10 +97,33 @@ type Provider struct {
 
 	shutdown   chan struct{}
 	bgFinished sync.WaitGroup
+
+	metrics providerMetrics
+}
+
+type providerMetrics struct {
+	lastUpdate   metrics.Gauge
+	loadFailures metrics.Counter
+	services     metrics.Gauge
+}
+
+func newProviderMetrics(metricsFactory metrics.Factory) providerMetrics {
+	metricsFactory = metricsFactory.Namespace(metrics.NSOptions{Name: "adaptive_sampling_provider"})
+	return providerMetrics{
+		lastUpdate:   metricsFactory.Gauge(metrics.Options{Name: "last_update_timestamp_seconds"}),
+		loadFailures: metricsFactory.Counter(metrics.Options{Name: "load_failures"}),
+		services:     metricsFactory.Gauge(metrics.Options{Name: "services"}),
+	}
 }
 
 // NewProvider creates a strategy store that holds adaptive sampling strategies.
-func NewProvider(options Options, logger *zap.Logger, participant leaderelection.ElectionParticipant, store samplingstore.Store) *Provider {
+func NewProvider(
+	options Options,
+	logger *zap.Logger,
+	metricsFactory metrics.Factory,
+	participant leaderelection.ElectionParticipant,
+	store samplingstore.Store,
+) *Provider {
 	return &Provider{
 		Options:                 options,
 		storage:                 store,
@@ -62,6 +133,7 @@ func NewProvider(options Options, logger *zap.Logger, participant leaderelection
 		electionParticipant:     participant,
 		followerRefreshInterval: defaultFollowerProbabilityInterval,
 		shutdown:                make(chan struct{}),
+		metrics:                 newProviderMetrics(metricsFactory),
 	}
 }
 
@@ -0,0 +1,65 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+var _ plugin.Configurable = (*Factory)(nil)
+
+// Factory implements samplingstrategy.Factory for a strategy store watching a Kubernetes ConfigMap.
+type Factory struct {
+	options        *Options
+	logger         *zap.Logger
+	metricsFactory metrics.Factory
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{
+		options:        &Options{},
+		logger:         zap.NewNop(),
+		metricsFactory: metrics.NullFactory,
+	}
+}
+
+// AddFlags implements plugin.Configurable
+func (*Factory) AddFlags(flagSet *flag.FlagSet) {
+	AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, _ *zap.Logger) {
+	f.options.InitFromViper(v)
+}
+
+// Initialize implements samplingstrategy.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, _ storage.SamplingStoreFactory, logger *zap.Logger) error {
+	f.logger = logger
+	f.metricsFactory = metricsFactory
+	return nil
+}
+
+// CreateStrategyProvider implements samplingstrategy.Factory
+func (f *Factory) CreateStrategyProvider() (samplingstrategy.Provider, samplingstrategy.Aggregator, error) {
+	p, err := NewProvider(*f.options, f.logger, f.metricsFactory)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, nil, nil
+}
+
+// Close closes the factory.
+func (*Factory) Close() error {
+	return nil
+}
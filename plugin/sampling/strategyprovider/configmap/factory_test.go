@@ -0,0 +1,55 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+)
+
+var (
+	_ ss.Factory          = new(Factory)
+	_ plugin.Configurable = new(Factory)
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{
+		"--sampling.configmap.name=sampling",
+		"--sampling.configmap.namespace=tracing",
+		"--sampling.configmap.key=custom.json",
+		"--sampling.configmap.kubeconfig=/does/not/exist",
+		"--sampling.configmap.sync-timeout=10s",
+	})
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, Options{
+		Name:        "sampling",
+		Namespace:   "tracing",
+		Key:         "custom.json",
+		Kubeconfig:  "/does/not/exist",
+		SyncTimeout: 10 * time.Second,
+	}, *f.options)
+
+	require.NoError(t, f.Initialize(metrics.NullFactory, nil, zap.NewNop()))
+	_, _, err := f.CreateStrategyProvider()
+	require.ErrorContains(t, err, "failed to load the Kubernetes configuration")
+	require.NoError(t, f.Close())
+}
+
+func TestDefaultOptions(t *testing.T) {
+	f := NewFactory()
+	v, _ := config.Viperize(f.AddFlags)
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, Options{Key: "strategies.json", SyncTimeout: time.Minute}, *f.options)
+}
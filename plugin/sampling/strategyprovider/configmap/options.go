@@ -0,0 +1,55 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	samplingConfigMapName        = "sampling.configmap.name"
+	samplingConfigMapNamespace   = "sampling.configmap.namespace"
+	samplingConfigMapKey         = "sampling.configmap.key"
+	samplingConfigMapKubeconfig  = "sampling.configmap.kubeconfig"
+	samplingConfigMapSyncTimeout = "sampling.configmap.sync-timeout"
+
+	defaultKey         = "strategies.json"
+	defaultSyncTimeout = time.Minute
+)
+
+// Options holds configuration for the ConfigMap sampling strategy provider.
+type Options struct {
+	// Name is the name of the ConfigMap holding the sampling strategies
+	Name string
+	// Namespace is the namespace of the ConfigMap, the namespace of the collector if empty
+	Namespace string
+	// Key is the key of the ConfigMap holding the sampling strategies in JSON format
+	Key string
+	// Kubeconfig is the path of the kubeconfig file, the in-cluster configuration being used if empty
+	Kubeconfig string
+	// SyncTimeout bounds the time waiting for the ConfigMap to be read when starting
+	SyncTimeout time.Duration
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(samplingConfigMapName, "", "The name of the Kubernetes ConfigMap holding the sampling strategies, which are updated as soon as the ConfigMap changes")
+	flagSet.String(samplingConfigMapNamespace, "", "The namespace of the sampling strategies ConfigMap, the namespace of the collector if empty")
+	flagSet.String(samplingConfigMapKey, defaultKey, "The key of the sampling strategies ConfigMap holding the strategies, in the format of the sampling strategies file")
+	flagSet.String(samplingConfigMapKubeconfig, "", "The path of the kubeconfig file used to watch the sampling strategies ConfigMap, the service account of the collector pod being used if empty")
+	flagSet.Duration(samplingConfigMapSyncTimeout, defaultSyncTimeout, "The maximum time waiting for the sampling strategies ConfigMap to be read when starting")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opts *Options) InitFromViper(v *viper.Viper) *Options {
+	opts.Name = v.GetString(samplingConfigMapName)
+	opts.Namespace = v.GetString(samplingConfigMapNamespace)
+	opts.Key = v.GetString(samplingConfigMapKey)
+	opts.Kubeconfig = v.GetString(samplingConfigMapKubeconfig)
+	opts.SyncTimeout = v.GetDuration(samplingConfigMapSyncTimeout)
+	return opts
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package configmap serves the sampling strategies held by a Kubernetes ConfigMap, in the format
+// of the sampling strategies file, and updates them as soon as the ConfigMap changes. The
+// strategies can then be managed like the other resources of the cluster, e.g. by GitOps,
+// without mounting the ConfigMap as a file and waiting for the kubelet to refresh it.
+package configmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/static"
+)
+
+type provider struct {
+	static.UpdatableProvider
+
+	options   Options
+	factory   informers.SharedInformerFactory
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	logger    *zap.Logger
+}
+
+// NewProvider creates a strategy store serving the sampling strategies of the ConfigMap of
+// options. It connects to the Kubernetes API, watches the ConfigMap and waits for it to be read.
+// The default strategies are served while the ConfigMap does not exist.
+func NewProvider(options Options, logger *zap.Logger, metricsFactory metrics.Factory) (ss.Provider, error) {
+	if options.Name == "" {
+		return nil, errors.New("the name of the sampling strategies ConfigMap is required")
+	}
+	client, namespace, err := newClient(options.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if options.Namespace == "" {
+		options.Namespace = namespace
+	}
+	// the Kubernetes client logs the failures to watch the ConfigMap with klog
+	klog.SetLogger(zapr.NewLogger(logger))
+	return newProvider(client, options, logger, metricsFactory)
+}
+
+// newClient returns a client of the Kubernetes API configured by the kubeconfig file at path, or
+// by the service account of the pod if path is empty, and the namespace of this configuration.
+func newClient(path string) (kubernetes.Interface, string, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: path}, &clientcmd.ConfigOverrides{})
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load the Kubernetes configuration: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find the namespace of the Kubernetes configuration: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, namespace, nil
+}
+
+func newProvider(client kubernetes.Interface, options Options, logger *zap.Logger, metricsFactory metrics.Factory) (*provider, error) {
+	if options.Key == "" {
+		options.Key = defaultKey
+	}
+	p := &provider{
+		// the new behavior of https://github.com/jaegertracing/jaeger/issues/5270, as there is no
+		// existing ConfigMap relying on the former one
+		UpdatableProvider: static.NewUpdatableProvider(static.Options{IncludeDefaultOpStrategies: true}, logger, metricsFactory),
+		options:           options,
+		factory: informers.NewSharedInformerFactoryWithOptions(client, 0,
+			informers.WithNamespace(options.Namespace),
+			informers.WithTweakListOptions(func(listOptions *metav1.ListOptions) {
+				listOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", options.Name).String()
+			}),
+		),
+		stopCh: make(chan struct{}),
+		logger: logger.With(zap.String("configmap", options.Namespace+"/"+options.Name)),
+	}
+
+	informer := p.factory.Core().V1().ConfigMaps().Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.update,
+		UpdateFunc: func(_, obj any) { p.update(obj) },
+		DeleteFunc: p.delete,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.factory.Start(p.stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.SyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		_ = p.Close()
+		return nil, fmt.Errorf("failed to read the sampling strategies ConfigMap within %v, check the permissions of the collector to list and watch the ConfigMaps", options.SyncTimeout)
+	}
+	if len(informer.GetStore().ListKeys()) == 0 {
+		p.logger.Warn("The sampling strategies ConfigMap does not exist, using the default strategies until it is created")
+	}
+	return p, nil
+}
+
+func (p *provider) update(obj any) {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok || configMap.Name != p.options.Name {
+		return
+	}
+	strategies, ok := configMap.Data[p.options.Key]
+	if !ok {
+		p.logger.Warn("The sampling strategies ConfigMap has no strategies, using the default strategies", zap.String("key", p.options.Key))
+	}
+	if err := p.Update([]byte(strategies)); err != nil {
+		p.logger.Error("Failed to update the sampling strategies from the ConfigMap, keeping the previous strategies", zap.Error(err))
+		return
+	}
+	p.logger.Info("Updated the sampling strategies from the ConfigMap", zap.String("resourceVersion", configMap.ResourceVersion))
+}
+
+func (p *provider) delete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if configMap, ok := obj.(*corev1.ConfigMap); !ok || configMap.Name != p.options.Name {
+		return
+	}
+	p.logger.Warn("The sampling strategies ConfigMap was deleted, using the default strategies")
+	_ = p.Update(nil)
+}
+
+// Close stops watching the ConfigMap.
+func (p *provider) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stopCh)
+		p.factory.Shutdown()
+	})
+	return p.UpdatableProvider.Close()
+}
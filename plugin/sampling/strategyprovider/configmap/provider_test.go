@@ -0,0 +1,162 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package configmap
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+func strategies(probability string) string {
+	return `{"default_strategy": {"type": "probabilistic", "param": 0.5},
+		"service_strategies": [{"service": "foo", "type": "probabilistic", "param": ` + probability + `}]}`
+}
+
+func newConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tracing"},
+		Data:       data,
+	}
+}
+
+func newTestProvider(t *testing.T, metricsFactory metrics.Factory, objects ...runtime.Object) (*provider, *fake.Clientset) {
+	client := fake.NewSimpleClientset(objects...)
+	p, err := newProvider(client, Options{Name: "sampling", Namespace: "tracing", SyncTimeout: 5 * time.Second}, zap.NewNop(), metricsFactory)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+	return p, client
+}
+
+func samplingRate(t *testing.T, p *provider, service string) float64 {
+	s, err := p.GetSamplingStrategy(context.Background(), service)
+	require.NoError(t, err)
+	require.Equal(t, api_v2.SamplingStrategyType_PROBABILISTIC, s.StrategyType)
+	return s.ProbabilisticSampling.SamplingRate
+}
+
+func TestProvider(t *testing.T) {
+	p, _ := newTestProvider(t, metrics.NullFactory,
+		newConfigMap("sampling", map[string]string{"strategies.json": strategies("0.8")}),
+		newConfigMap("other", map[string]string{"strategies.json": strategies("0.1")}),
+	)
+	assert.InDelta(t, 0.8, samplingRate(t, p, "foo"), 1e-9)
+	assert.InDelta(t, 0.5, samplingRate(t, p, "bar"), 1e-9)
+}
+
+func TestProviderWatchesConfigMap(t *testing.T) {
+	p, client := newTestProvider(t, metrics.NullFactory)
+	// the default strategies are served until the ConfigMap is created
+	assert.InDelta(t, 0.001, samplingRate(t, p, "foo"), 1e-9)
+
+	configMaps := client.CoreV1().ConfigMaps("tracing")
+	_, err := configMaps.Create(context.Background(), newConfigMap("sampling", map[string]string{"strategies.json": strategies("0.8")}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return samplingRate(t, p, "foo") == 0.8
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = configMaps.Update(context.Background(), newConfigMap("sampling", map[string]string{"strategies.json": strategies("0.2")}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return samplingRate(t, p, "foo") == 0.2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the other ConfigMaps are ignored
+	_, err = configMaps.Create(context.Background(), newConfigMap("other", map[string]string{"strategies.json": strategies("0.1")}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, configMaps.Delete(context.Background(), "other", metav1.DeleteOptions{}))
+
+	require.NoError(t, configMaps.Delete(context.Background(), "sampling", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		return samplingRate(t, p, "foo") == 0.001
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProviderInvalidStrategies(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	p, client := newTestProvider(t, mb, newConfigMap("sampling", map[string]string{"strategies.json": strategies("0.8")}))
+
+	// the previous strategies are kept when the ConfigMap is invalid
+	_, err := client.CoreV1().ConfigMaps("tracing").Update(context.Background(),
+		newConfigMap("sampling", map[string]string{"strategies.json": "{"}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		counters, _ := mb.Snapshot()
+		return counters["static_sampling.reloads|result=failed"] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.InDelta(t, 0.8, samplingRate(t, p, "foo"), 1e-9)
+
+	// the default strategies are used when the ConfigMap has no strategies
+	_, err = client.CoreV1().ConfigMaps("tracing").Update(context.Background(),
+		newConfigMap("sampling", map[string]string{"other.json": strategies("0.1")}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return samplingRate(t, p, "foo") == 0.001
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProviderCustomKey(t *testing.T) {
+	client := fake.NewSimpleClientset(newConfigMap("sampling", map[string]string{"custom.json": strategies("0.3")}))
+	p, err := newProvider(client, Options{Name: "sampling", Namespace: "tracing", Key: "custom.json", SyncTimeout: 5 * time.Second}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	defer p.Close()
+	assert.InDelta(t, 0.3, samplingRate(t, p, "foo"), 1e-9)
+}
+
+func TestNewProviderSyncTimeout(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("configmaps is forbidden")
+	})
+	_, err := newProvider(client, Options{Name: "sampling", SyncTimeout: 100 * time.Millisecond}, zap.NewNop(), metrics.NullFactory)
+	require.ErrorContains(t, err, "failed to read the sampling strategies ConfigMap within 100ms")
+}
+
+func TestNewProviderErrors(t *testing.T) {
+	_, err := NewProvider(Options{}, zap.NewNop(), metrics.NullFactory)
+	require.ErrorContains(t, err, "the name of the sampling strategies ConfigMap is required")
+
+	_, err = NewProvider(Options{Name: "sampling", Kubeconfig: "/does/not/exist"}, zap.NewNop(), metrics.NullFactory)
+	require.ErrorContains(t, err, "failed to load the Kubernetes configuration")
+}
+
+func TestNewProviderNamespaceFromKubeconfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: http://127.0.0.1:1
+contexts:
+- name: test
+  context:
+    cluster: test
+    namespace: tracing
+current-context: test
+`), 0o600))
+	client, namespace, err := newClient(kubeconfig)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+	assert.Equal(t, "tracing", namespace)
+}
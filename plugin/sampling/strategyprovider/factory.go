@@ -26,6 +26,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/plugin"
 	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/adaptive"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/configmap"
 	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/static"
 	"github.com/jaegertracing/jaeger/storage"
 )
@@ -34,12 +35,13 @@ import (
 type Kind string
 
 const (
-	samplingTypeAdaptive = "adaptive"
-	samplingTypeFile     = "file"
+	samplingTypeAdaptive  = "adaptive"
+	samplingTypeFile      = "file"
+	samplingTypeConfigMap = "configmap"
 )
 
 // AllSamplingTypes lists all types of sampling factories.
-var AllSamplingTypes = []string{samplingTypeFile, samplingTypeAdaptive}
+var AllSamplingTypes = []string{samplingTypeFile, samplingTypeAdaptive, samplingTypeConfigMap}
 
 var (
 	_ plugin.Configurable      = (*Factory)(nil)
@@ -76,6 +78,8 @@ func (*Factory) getFactoryOfType(factoryType Kind) (samplingstrategy.Factory, er
 		return static.NewFactory(), nil
 	case samplingTypeAdaptive:
 		return adaptive.NewFactory(), nil
+	case samplingTypeConfigMap:
+		return configmap.NewFactory(), nil
 	default:
 		return nil, fmt.Errorf("unknown sampling strategy store type %s. Valid types are %v", factoryType, AllSamplingTypes)
 	}
@@ -32,10 +32,12 @@ type FactoryConfig struct {
 // FactoryConfigFromEnv reads the desired sampling type from the SAMPLING_CONFIG_TYPE environment variable. Allowed values:
 // * `file` - built-in
 // * `adaptive` - built-in
+// * `configmap` - built-in
 func FactoryConfigFromEnv() (*FactoryConfig, error) {
 	strategyStoreType := getStrategyStoreTypeFromEnv()
 	if strategyStoreType != samplingTypeAdaptive &&
-		strategyStoreType != samplingTypeFile {
+		strategyStoreType != samplingTypeFile &&
+		strategyStoreType != samplingTypeConfigMap {
 		return nil, fmt.Errorf("invalid sampling type: %s. Valid types are %v", strategyStoreType, AllSamplingTypes)
 	}
 
@@ -48,6 +48,11 @@ func TestFactoryConfigFromEnv(t *testing.T) {
 			env:          "adaptive",
 			expectedType: Kind("adaptive"),
 		},
+		{
+			name:         "configmap on SamplingTypeEnvVar",
+			env:          "configmap",
+			expectedType: Kind("configmap"),
+		},
 		{
 			name:         "unexpected string on SamplingTypeEnvVar",
 			env:          "??",
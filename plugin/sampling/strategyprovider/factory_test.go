@@ -49,6 +49,9 @@ func TestNewFactory(t *testing.T) {
 		{
 			strategyStoreType: "adaptive",
 		},
+		{
+			strategyStoreType: "configmap",
+		},
 		{
 			// expliclitly test that the deprecated value is refused in NewFactory(). it should be translated correctly in factory_config.go
 			// and no other code should need to be aware of the old name.
@@ -30,15 +30,17 @@ var _ plugin.Configurable = (*Factory)(nil)
 
 // Factory implements samplingstrategy.Factory for a static strategy store.
 type Factory struct {
-	options *Options
-	logger  *zap.Logger
+	options        *Options
+	logger         *zap.Logger
+	metricsFactory metrics.Factory
 }
 
 // NewFactory creates a new Factory.
 func NewFactory() *Factory {
 	return &Factory{
-		options: &Options{},
-		logger:  zap.NewNop(),
+		options:        &Options{},
+		logger:         zap.NewNop(),
+		metricsFactory: metrics.NullFactory,
 	}
 }
 
@@ -53,14 +55,15 @@ func (f *Factory) InitFromViper(v *viper.Viper, _ *zap.Logger) {
 }
 
 // Initialize implements samplingstrategy.Factory
-func (f *Factory) Initialize(_ metrics.Factory, _ storage.SamplingStoreFactory, logger *zap.Logger) error {
+func (f *Factory) Initialize(metricsFactory metrics.Factory, _ storage.SamplingStoreFactory, logger *zap.Logger) error {
 	f.logger = logger
+	f.metricsFactory = metricsFactory
 	return nil
 }
 
 // CreateStrategyStore implements samplingstrategy.Factory
 func (f *Factory) CreateStrategyProvider() (samplingstrategy.Provider, samplingstrategy.Aggregator, error) {
-	s, err := NewProvider(*f.options, f.logger)
+	s, err := NewProvider(*f.options, f.logger, f.metricsFactory)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -24,15 +24,46 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
 	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
+const (
+	reloadUpdated   = "updated"
+	reloadUnchanged = "unchanged"
+	reloadFailed    = "failed"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_static_sampling_last_update_timestamp_seconds",
+			Type: telemetery.Gauge,
+			Help: "Unix time of the last update of the static sampling strategies, to compute the age of the served strategies",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_static_sampling_services",
+			Type: telemetery.Gauge,
+			Help: "Number of services with their own static sampling strategy",
+		},
+		telemetery.Metric{
+			Name:   "jaeger_collector_static_sampling_reloads_total",
+			Type:   telemetery.Counter,
+			Help:   "Periodic reloads or pushed updates of the static sampling strategies, by result",
+			Labels: []telemetery.Label{{Name: "result", Values: []string{reloadUpdated, reloadUnchanged, reloadFailed}}},
+		},
+	)
+}
+
 // null represents "null" JSON value and
 // it un-marshals to nil pointer.
 var nullJSON = []byte("null")
@@ -45,6 +76,29 @@ type samplingProvider struct {
 	cancelFunc context.CancelFunc
 
 	options Options
+	metrics providerMetrics
+}
+
+type providerMetrics struct {
+	lastUpdate       metrics.Gauge
+	services         metrics.Gauge
+	reloadsUpdated   metrics.Counter
+	reloadsUnchanged metrics.Counter
+	reloadsFailed    metrics.Counter
+}
+
+func newProviderMetrics(metricsFactory metrics.Factory) providerMetrics {
+	metricsFactory = metricsFactory.Namespace(metrics.NSOptions{Name: "static_sampling"})
+	reloads := func(result string) metrics.Counter {
+		return metricsFactory.Counter(metrics.Options{Name: "reloads", Tags: map[string]string{"result": result}})
+	}
+	return providerMetrics{
+		lastUpdate:       metricsFactory.Gauge(metrics.Options{Name: "last_update_timestamp_seconds"}),
+		services:         metricsFactory.Gauge(metrics.Options{Name: "services"}),
+		reloadsUpdated:   reloads(reloadUpdated),
+		reloadsUnchanged: reloads(reloadUnchanged),
+		reloadsFailed:    reloads(reloadFailed),
+	}
 }
 
 type storedStrategies struct {
@@ -55,14 +109,15 @@ type storedStrategies struct {
 type strategyLoader func() ([]byte, error)
 
 // NewProvider creates a strategy store that holds static sampling strategies.
-func NewProvider(options Options, logger *zap.Logger) (ss.Provider, error) {
+func NewProvider(options Options, logger *zap.Logger, metricsFactory metrics.Factory) (ss.Provider, error) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	h := &samplingProvider{
 		logger:     logger,
 		cancelFunc: cancelFunc,
 		options:    options,
+		metrics:    newProviderMetrics(metricsFactory),
 	}
-	h.storedStrategies.Store(defaultStrategies())
+	h.storeStrategies(defaultStrategies())
 
 	if options.StrategiesFile == "" {
 		h.logger.Info("No sampling strategies source provided, using defaults")
@@ -93,6 +148,49 @@ func NewProvider(options Options, logger *zap.Logger) (ss.Provider, error) {
 	return h, nil
 }
 
+// UpdatableProvider is a strategy store whose static sampling strategies are pushed by their
+// source, e.g. a watcher of a Kubernetes ConfigMap, rather than loaded from a file or a URL.
+type UpdatableProvider interface {
+	ss.Provider
+	// Update replaces the strategies by the strategies of their JSON representation, keeping
+	// the current strategies if it is invalid. Empty strategies restore the default strategies.
+	Update(strategies []byte) error
+}
+
+// NewUpdatableProvider creates a strategy store serving the default sampling strategies until it is updated.
+func NewUpdatableProvider(options Options, logger *zap.Logger, metricsFactory metrics.Factory) UpdatableProvider {
+	h := &samplingProvider{
+		logger:     logger,
+		cancelFunc: func() {},
+		options:    options,
+		metrics:    newProviderMetrics(metricsFactory),
+	}
+	h.storeStrategies(defaultStrategies())
+	return h
+}
+
+// Update implements UpdatableProvider#Update.
+func (h *samplingProvider) Update(data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = nullJSON
+	}
+	strategies, err := loadStrategies(func() ([]byte, error) { return data, nil })
+	if err != nil {
+		h.metrics.reloadsFailed.Inc(1)
+		return err
+	}
+	switch {
+	case strategies == nil:
+		h.storeStrategies(defaultStrategies())
+	case h.options.IncludeDefaultOpStrategies:
+		h.parseStrategies(strategies)
+	default:
+		h.parseStrategies_deprecated(strategies)
+	}
+	h.metrics.reloadsUpdated.Inc(1)
+	return nil
+}
+
 // GetSamplingStrategy implements StrategyStore#GetSamplingStrategy.
 func (h *samplingProvider) GetSamplingStrategy(_ context.Context, serviceName string) (*api_v2.SamplingStrategyResponse, error) {
 	ss := h.storedStrategies.Load().(*storedStrategies)
@@ -184,15 +282,19 @@ func (h *samplingProvider) reloadSamplingStrategy(loadFn strategyLoader, lastVal
 	newValue, err := loadFn()
 	if err != nil {
 		h.logger.Error("failed to re-load sampling strategies", zap.Error(err))
+		h.metrics.reloadsFailed.Inc(1)
 		return lastValue
 	}
 	if lastValue == string(newValue) {
+		h.metrics.reloadsUnchanged.Inc(1)
 		return lastValue
 	}
 	if err := h.updateSamplingStrategy(newValue); err != nil {
 		h.logger.Error("failed to update sampling strategies", zap.Error(err))
+		h.metrics.reloadsFailed.Inc(1)
 		return lastValue
 	}
+	h.metrics.reloadsUpdated.Inc(1)
 	return string(newValue)
 }
 
@@ -256,7 +358,7 @@ func (h *samplingProvider) parseStrategies_deprecated(strategies *strategies) {
 				newStore.defaultStrategy.OperationSampling.PerOperationStrategies)
 		}
 	}
-	h.storedStrategies.Store(newStore)
+	h.storeStrategies(newStore)
 }
 
 func (h *samplingProvider) parseStrategies(strategies *strategies) {
@@ -294,24 +396,45 @@ func (h *samplingProvider) parseStrategies(strategies *strategies) {
 			opS.PerOperationStrategies,
 			newStore.defaultStrategy.OperationSampling.PerOperationStrategies)
 	}
-	h.storedStrategies.Store(newStore)
+	h.storeStrategies(newStore)
+}
+
+func (h *samplingProvider) storeStrategies(strategies *storedStrategies) {
+	h.storedStrategies.Store(strategies)
+	h.metrics.lastUpdate.Update(time.Now().Unix())
+	h.metrics.services.Update(int64(len(strategies.serviceStrategies)))
 }
 
-// mergePerOperationSamplingStrategies merges two operation strategies a and b, where a takes precedence over b.
+// mergePerOperationSamplingStrategies merges two operation strategies a and b, where a takes precedence over b
+// for the same operation and span kind.
 func mergePerOperationSamplingStrategies(
 	a, b []*api_v2.OperationSamplingStrategy,
 ) []*api_v2.OperationSamplingStrategy {
-	m := make(map[string]bool)
+	type operationKey struct {
+		operation string
+		spanKind  string
+	}
+	m := make(map[operationKey]bool)
 	for _, aOp := range a {
-		m[aOp.Operation] = true
+		m[operationKey{aOp.Operation, aOp.SpanKind}] = true
 	}
 	for _, bOp := range b {
-		if m[bOp.Operation] {
+		if m[operationKey{bOp.Operation, bOp.SpanKind}] {
 			continue
 		}
 		a = append(a, bOp)
 	}
-	return a
+	return spanKindsFirst(a)
+}
+
+// spanKindsFirst moves the strategies of a span kind before the strategies of all the span kinds,
+// keeping their order otherwise. The SDKs which do not support span kinds use the last strategy
+// of an operation, which is then the strategy of all its span kinds if there is one.
+func spanKindsFirst(operations []*api_v2.OperationSamplingStrategy) []*api_v2.OperationSamplingStrategy {
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].SpanKind != "" && operations[j].SpanKind == ""
+	})
+	return operations
 }
 
 func (h *samplingProvider) parseServiceStrategies(strategy *serviceStrategy) *api_v2.SamplingStrategyResponse {
@@ -335,8 +458,10 @@ func (h *samplingProvider) parseServiceStrategies(strategy *serviceStrategy) *ap
 			&api_v2.OperationSamplingStrategy{
 				Operation:             operationStrategy.Operation,
 				ProbabilisticSampling: s.ProbabilisticSampling,
+				SpanKind:              operationStrategy.SpanKind,
 			})
 	}
+	opS.PerOperationStrategies = spanKindsFirst(opS.PerOperationStrategies)
 	resp.OperationSampling = opS
 	return resp
 }
@@ -345,6 +470,11 @@ func (h *samplingProvider) parseOperationStrategy(
 	strategy *operationStrategy,
 	parent *api_v2.PerOperationSamplingStrategies,
 ) (s *api_v2.SamplingStrategyResponse, ok bool) {
+	if _, known := model.SpanKindFromString(strategy.SpanKind); strategy.SpanKind != "" && !known {
+		h.logger.Warn("Unknown span kind of operation strategy, ignoring the strategy",
+			zap.String("operation", strategy.Operation), zap.String("span_kind", strategy.SpanKind))
+		return nil, false
+	}
 	s = h.parseStrategy(&strategy.strategy)
 	if s.StrategyType == api_v2.SamplingStrategyType_RATE_LIMITING {
 		// TODO OperationSamplingStrategy only supports probabilistic sampling
@@ -32,6 +32,8 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
@@ -105,16 +107,16 @@ func mockStrategyServer(t *testing.T) (*httptest.Server, *atomic.Pointer[string]
 }
 
 func TestStrategyStoreWithFile(t *testing.T) {
-	_, err := NewProvider(Options{StrategiesFile: "fileNotFound.json"}, zap.NewNop())
+	_, err := NewProvider(Options{StrategiesFile: "fileNotFound.json"}, zap.NewNop(), metrics.NullFactory)
 	assert.Contains(t, err.Error(), "failed to read strategies file fileNotFound.json")
 
-	_, err = NewProvider(Options{StrategiesFile: "fixtures/bad_strategies.json"}, zap.NewNop())
+	_, err = NewProvider(Options{StrategiesFile: "fixtures/bad_strategies.json"}, zap.NewNop(), metrics.NullFactory)
 	require.EqualError(t, err,
 		"failed to unmarshal strategies: json: cannot unmarshal string into Go value of type static.strategies")
 
 	// Test default strategy
 	logger, buf := testutils.NewLogger()
-	provider, err := NewProvider(Options{}, logger)
+	provider, err := NewProvider(Options{}, logger, metrics.NullFactory)
 	require.NoError(t, err)
 	assert.Contains(t, buf.String(), "No sampling strategies source provided, using defaults")
 	s, err := provider.GetSamplingStrategy(context.Background(), "foo")
@@ -122,7 +124,7 @@ func TestStrategyStoreWithFile(t *testing.T) {
 	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, 0.001), *s)
 
 	// Test reading strategies from a file
-	provider, err = NewProvider(Options{StrategiesFile: "fixtures/strategies.json"}, logger)
+	provider, err = NewProvider(Options{StrategiesFile: "fixtures/strategies.json"}, logger, metrics.NullFactory)
 	require.NoError(t, err)
 	s, err = provider.GetSamplingStrategy(context.Background(), "foo")
 	require.NoError(t, err)
@@ -141,7 +143,7 @@ func TestStrategyStoreWithURL(t *testing.T) {
 	// Test default strategy when URL is temporarily unavailable.
 	logger, buf := testutils.NewLogger()
 	mockServer, _ := mockStrategyServer(t)
-	provider, err := NewProvider(Options{StrategiesFile: mockServer.URL + "/service-unavailable"}, logger)
+	provider, err := NewProvider(Options{StrategiesFile: mockServer.URL + "/service-unavailable"}, logger, metrics.NullFactory)
 	require.NoError(t, err)
 	assert.Contains(t, buf.String(), "No sampling strategies found or URL is unavailable, using defaults")
 	s, err := provider.GetSamplingStrategy(context.Background(), "foo")
@@ -149,7 +151,7 @@ func TestStrategyStoreWithURL(t *testing.T) {
 	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, 0.001), *s)
 
 	// Test downloading strategies from a URL.
-	provider, err = NewProvider(Options{StrategiesFile: mockServer.URL}, logger)
+	provider, err = NewProvider(Options{StrategiesFile: mockServer.URL}, logger, metrics.NullFactory)
 	require.NoError(t, err)
 
 	s, err = provider.GetSamplingStrategy(context.Background(), "foo")
@@ -174,7 +176,7 @@ func TestPerOperationSamplingStrategies(t *testing.T) {
 
 	for _, tc := range tests {
 		logger, buf := testutils.NewLogger()
-		provider, err := NewProvider(tc.options, logger)
+		provider, err := NewProvider(tc.options, logger, metrics.NullFactory)
 		assert.Contains(t, buf.String(), "Operation strategies only supports probabilistic sampling at the moment,"+
 			"'op2' defaulting to probabilistic sampling with probability 0.8")
 		assert.Contains(t, buf.String(), "Operation strategies only supports probabilistic sampling at the moment,"+
@@ -254,9 +256,33 @@ func TestPerOperationSamplingStrategies(t *testing.T) {
 	}
 }
 
+func TestPerOperationSamplingStrategiesBySpanKind(t *testing.T) {
+	logger, buf := testutils.NewLogger()
+	provider, err := NewProvider(Options{StrategiesFile: "fixtures/operation_strategies_span_kind.json"}, logger, metrics.NullFactory)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Unknown span kind of operation strategy, ignoring the strategy")
+
+	operationStrategy := func(spanKind string, rate float64) *api_v2.OperationSamplingStrategy {
+		return &api_v2.OperationSamplingStrategy{
+			Operation:             "orders",
+			SpanKind:              spanKind,
+			ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: rate},
+		}
+	}
+	s, err := provider.GetSamplingStrategy(context.Background(), "foo")
+	require.NoError(t, err)
+	require.NotNil(t, s.OperationSampling)
+	// the strategy of all the span kinds is last for the SDKs which do not support span kinds
+	assert.Equal(t, []*api_v2.OperationSamplingStrategy{
+		operationStrategy("server", 0.2),
+		operationStrategy("consumer", 0.01),
+		operationStrategy("", 0.5),
+	}, s.OperationSampling.PerOperationStrategies)
+}
+
 func TestMissingServiceSamplingStrategyTypes(t *testing.T) {
 	logger, buf := testutils.NewLogger()
-	provider, err := NewProvider(Options{StrategiesFile: "fixtures/missing-service-types.json"}, logger)
+	provider, err := NewProvider(Options{StrategiesFile: "fixtures/missing-service-types.json"}, logger, metrics.NullFactory)
 	assert.Contains(t, buf.String(), "Failed to parse sampling strategy")
 	require.NoError(t, err)
 
@@ -316,7 +342,7 @@ func TestParseStrategy(t *testing.T) {
 		},
 	}
 	logger, buf := testutils.NewLogger()
-	provider := &samplingProvider{logger: logger}
+	provider := &samplingProvider{logger: logger, metrics: newProviderMetrics(metrics.NullFactory)}
 	for _, test := range tests {
 		tt := test
 		t.Run("", func(t *testing.T) {
@@ -374,7 +400,7 @@ func TestAutoUpdateStrategyWithFile(t *testing.T) {
 	ss, err := NewProvider(Options{
 		StrategiesFile: dstFile,
 		ReloadInterval: time.Millisecond * 10,
-	}, zap.NewNop())
+	}, zap.NewNop(), metrics.NullFactory)
 	require.NoError(t, err)
 	provider := ss.(*samplingProvider)
 	defer provider.Close()
@@ -409,7 +435,7 @@ func TestAutoUpdateStrategyWithURL(t *testing.T) {
 	ss, err := NewProvider(Options{
 		StrategiesFile: mockServer.URL,
 		ReloadInterval: 10 * time.Millisecond,
-	}, zap.NewNop())
+	}, zap.NewNop(), metrics.NullFactory)
 	require.NoError(t, err)
 	provider := ss.(*samplingProvider)
 	defer provider.Close()
@@ -457,7 +483,7 @@ func TestAutoUpdateStrategyErrors(t *testing.T) {
 	s, err := NewProvider(Options{
 		StrategiesFile: "fixtures/strategies.json",
 		ReloadInterval: time.Hour,
-	}, logger)
+	}, logger, metrics.NullFactory)
 	require.NoError(t, err)
 	provider := s.(*samplingProvider)
 	defer provider.Close()
@@ -485,13 +511,91 @@ func TestAutoUpdateStrategyErrors(t *testing.T) {
 	assert.Len(t, logs.FilterMessage("failed to update sampling strategies").All(), 2)
 }
 
+func TestReloadMetrics(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	s, err := NewProvider(Options{
+		StrategiesFile: "fixtures/strategies.json",
+		ReloadInterval: time.Hour,
+	}, zap.NewNop(), metricsFactory)
+	require.NoError(t, err)
+	provider := s.(*samplingProvider)
+	defer provider.Close()
+
+	_, gauges := metricsFactory.Snapshot()
+	assert.EqualValues(t, 2, gauges["static_sampling.services"])
+	assert.NotZero(t, gauges["static_sampling.last_update_timestamp_seconds"])
+
+	loader := provider.samplingStrategyLoader("fixtures/strategies.json")
+	content, err := loader()
+	require.NoError(t, err)
+	provider.reloadSamplingStrategy(loader, string(content))
+	provider.reloadSamplingStrategy(loader, "old value")
+	provider.reloadSamplingStrategy(provider.samplingStrategyLoader("fixtures/missing.json"), "old value")
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "static_sampling.reloads", Tags: map[string]string{"result": "unchanged"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "static_sampling.reloads", Tags: map[string]string{"result": "updated"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "static_sampling.reloads", Tags: map[string]string{"result": "failed"}, Value: 1},
+	)
+}
+
+func TestUpdatableProvider(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	provider := NewUpdatableProvider(Options{IncludeDefaultOpStrategies: true}, zap.NewNop(), metricsFactory)
+	defer provider.Close()
+
+	s, err := provider.GetSamplingStrategy(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, 0.001), *s)
+
+	require.NoError(t, provider.Update([]byte(strategiesJSON(0.8))))
+	s, err = provider.GetSamplingStrategy(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, 0.8), *s)
+
+	// the strategies are kept when the update is invalid
+	require.ErrorContains(t, provider.Update([]byte("bad-content")), "failed to unmarshal strategies")
+	s, err = provider.GetSamplingStrategy(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, 0.8), *s)
+
+	// the default strategies are restored by empty strategies
+	require.NoError(t, provider.Update([]byte(" ")))
+	s, err = provider.GetSamplingStrategy(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, 0.001), *s)
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "static_sampling.reloads", Tags: map[string]string{"result": "updated"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "static_sampling.reloads", Tags: map[string]string{"result": "failed"}, Value: 1},
+	)
+}
+
+func TestUpdatableProviderDeprecatedBehavior(t *testing.T) {
+	provider := NewUpdatableProvider(Options{}, zap.NewNop(), metrics.NullFactory)
+	defer provider.Close()
+	bytes, err := os.ReadFile("fixtures/service_no_per_operation.json")
+	require.NoError(t, err)
+	require.NoError(t, provider.Update(bytes))
+
+	strategy, err := provider.GetSamplingStrategy(context.Background(), "ServiceA")
+	require.NoError(t, err)
+	strategyJson, err := json.MarshalIndent(strategy, "", "  ")
+	require.NoError(t, err)
+	expectedServiceResponse, err := os.ReadFile("fixtures/TestServiceNoPerOperationStrategiesDeprecatedBehavior_ServiceA.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(expectedServiceResponse), string(strategyJson))
+}
+
 func TestServiceNoPerOperationStrategies(t *testing.T) {
 	// given setup of strategy provider with no specific per operation sampling strategies
 	// and option "sampling.strategies.bugfix-5270=true"
 	provider, err := NewProvider(Options{
 		StrategiesFile:             "fixtures/service_no_per_operation.json",
 		IncludeDefaultOpStrategies: true,
-	}, zap.NewNop())
+	}, zap.NewNop(), metrics.NullFactory)
 	require.NoError(t, err)
 
 	for _, service := range []string{"ServiceA", "ServiceB"} {
@@ -523,7 +627,7 @@ func TestServiceNoPerOperationStrategiesDeprecatedBehavior(t *testing.T) {
 	// given setup of strategy provider with no specific per operation sampling strategies
 	provider, err := NewProvider(Options{
 		StrategiesFile: "fixtures/service_no_per_operation.json",
-	}, zap.NewNop())
+	}, zap.NewNop(), metrics.NullFactory)
 	require.NoError(t, err)
 
 	for _, service := range []string{"ServiceA", "ServiceB"} {
@@ -549,7 +653,7 @@ func TestServiceNoPerOperationStrategiesDeprecatedBehavior(t *testing.T) {
 }
 
 func TestSamplingStrategyLoader(t *testing.T) {
-	provider := &samplingProvider{logger: zap.NewNop()}
+	provider := &samplingProvider{logger: zap.NewNop(), metrics: newProviderMetrics(metrics.NullFactory)}
 	// invalid file path
 	loader := provider.samplingStrategyLoader("not-exists")
 	_, err := loader()
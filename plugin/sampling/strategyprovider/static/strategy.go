@@ -21,9 +21,11 @@ type strategy struct {
 	Param float64 `json:"param"`
 }
 
-// operationStrategy defines an operation specific sampling strategy.
+// operationStrategy defines an operation specific sampling strategy. SpanKind optionally restricts
+// the strategy to the spans of a kind, e.g. "consumer", the operation having a strategy by span kind.
 type operationStrategy struct {
 	Operation string `json:"operation"`
+	SpanKind  string `json:"span_kind,omitempty"`
 	strategy
 }
 
@@ -0,0 +1,35 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package stored
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const samplingStoredStrategiesRefreshInterval = "sampling.stored-strategies.refresh-interval"
+
+// Options holds configuration for the sampling strategies stored with the query service API.
+type Options struct {
+	// RefreshInterval is how often the stored strategies are read from the storage, 0 disabling them
+	RefreshInterval time.Duration
+}
+
+// Enabled returns whether the stored strategies are served.
+func (o Options) Enabled() bool {
+	return o.RefreshInterval > 0
+}
+
+// AddFlags adds flags for Options.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Duration(samplingStoredStrategiesRefreshInterval, 0, "How often the sampling strategies set with the /api/sampling-strategies endpoints of the query service are read from the storage, to be served ahead of the other strategies; 0 disables them. Only supported by the memory and sqlite storages")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.RefreshInterval = v.GetDuration(samplingStoredStrategiesRefreshInterval)
+	return o
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package stored
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	opts := new(Options).InitFromViper(v)
+	assert.False(t, opts.Enabled())
+
+	require.NoError(t, command.ParseFlags([]string{"--sampling.stored-strategies.refresh-interval=30s"}))
+	opts = new(Options).InitFromViper(v)
+	assert.Equal(t, 30*time.Second, opts.RefreshInterval)
+	assert.True(t, opts.Enabled())
+}
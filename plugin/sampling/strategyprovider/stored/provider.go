@@ -0,0 +1,124 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package stored serves the sampling strategies of the services set with the query service API,
+// and read from the storage, ahead of the strategies of another provider, e.g. of the strategies
+// file. The operators can then adjust the sampling of a service without redeploying the collectors,
+// the other services keeping the strategies of the file.
+package stored
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/telemetery"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+func init() {
+	telemetery.Register(
+		telemetery.Metric{
+			Name: "jaeger_collector_stored_sampling_services",
+			Type: telemetery.Gauge,
+			Help: "Number of services with a sampling strategy set with the query service API",
+		},
+		telemetery.Metric{
+			Name: "jaeger_collector_stored_sampling_refreshes_total",
+			Type: telemetery.Counter,
+			Help: "Reads of the sampling strategies set with the query service API, the previous strategies are kept when it fails",
+			Labels: []telemetery.Label{
+				{Name: "result", Values: []string{"ok", "err"}},
+			},
+		},
+	)
+}
+
+type providerMetrics struct {
+	// Services is the number of services with a stored strategy
+	Services metrics.Gauge `metric:"stored_sampling.services"`
+	// Refreshes counts the reads of the stored strategies
+	Refreshes metrics.Counter `metric:"stored_sampling.refreshes" tags:"result=ok"`
+	// RefreshFailures counts the reads of the stored strategies which failed, leaving the previous strategies in place
+	RefreshFailures metrics.Counter `metric:"stored_sampling.refreshes" tags:"result=err"`
+}
+
+type provider struct {
+	ss.Provider
+
+	store      samplingstore.StrategyStore
+	strategies atomic.Pointer[map[string]*api_v2.SamplingStrategyResponse]
+	logger     *zap.Logger
+	metrics    providerMetrics
+
+	stop      chan struct{}
+	done      sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewProvider creates a strategy store serving the strategies of the store, refreshed every
+// options.RefreshInterval, and the strategies of fallback for the other services.
+func NewProvider(fallback ss.Provider, store samplingstore.StrategyStore, options Options, logger *zap.Logger, metricsFactory metrics.Factory) ss.Provider {
+	p := &provider{
+		Provider: fallback,
+		store:    store,
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+	metrics.MustInit(&p.metrics, metricsFactory, nil)
+	p.strategies.Store(&map[string]*api_v2.SamplingStrategyResponse{})
+	// the collector starts with the fallback strategies if the storage is not available yet
+	p.refresh()
+	p.done.Add(1)
+	go p.refreshPeriodically(options.RefreshInterval)
+	return p
+}
+
+// GetSamplingStrategy returns the stored strategy of the service, or the strategy of the fallback provider.
+func (p *provider) GetSamplingStrategy(ctx context.Context, serviceName string) (*api_v2.SamplingStrategyResponse, error) {
+	if strategy, ok := (*p.strategies.Load())[serviceName]; ok {
+		return strategy, nil
+	}
+	return p.Provider.GetSamplingStrategy(ctx, serviceName)
+}
+
+func (p *provider) refreshPeriodically(interval time.Duration) {
+	defer p.done.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.refresh()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *provider) refresh() {
+	strategies, err := p.store.FindStrategies(context.Background())
+	if err != nil {
+		p.metrics.RefreshFailures.Inc(1)
+		p.logger.Error("Failed to read the stored sampling strategies, keeping the previous strategies", zap.Error(err))
+		return
+	}
+	p.strategies.Store(&strategies)
+	p.metrics.Refreshes.Inc(1)
+	p.metrics.Services.Update(int64(len(strategies)))
+}
+
+// Close stops refreshing the stored strategies and closes the fallback provider.
+func (p *provider) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		p.done.Wait()
+	})
+	return p.Provider.Close()
+}
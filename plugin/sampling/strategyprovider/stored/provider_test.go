@@ -0,0 +1,111 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package stored
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+func probabilistic(rate float64) *api_v2.SamplingStrategyResponse {
+	return &api_v2.SamplingStrategyResponse{
+		StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: rate},
+	}
+}
+
+type fakeProvider struct {
+	closed atomic.Bool
+}
+
+func (*fakeProvider) GetSamplingStrategy(context.Context, string) (*api_v2.SamplingStrategyResponse, error) {
+	return probabilistic(0.001), nil
+}
+
+func (p *fakeProvider) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+type failingStore struct {
+	samplingstore.StrategyStore
+	fail atomic.Bool
+}
+
+func (s *failingStore) FindStrategies(ctx context.Context) (map[string]*api_v2.SamplingStrategyResponse, error) {
+	if s.fail.Load() {
+		return nil, errors.New("storage error")
+	}
+	return s.StrategyStore.FindStrategies(ctx)
+}
+
+func samplingRate(t *testing.T, p ss.Provider, service string) float64 {
+	s, err := p.GetSamplingStrategy(context.Background(), service)
+	require.NoError(t, err)
+	return s.ProbabilisticSampling.SamplingRate
+}
+
+func TestProvider(t *testing.T) {
+	store := memory.NewStrategyStore()
+	ctx := context.Background()
+	require.NoError(t, store.WriteStrategy(ctx, "frontend", probabilistic(0.5)))
+	fallback := &fakeProvider{}
+	p := NewProvider(fallback, store, Options{RefreshInterval: 10 * time.Millisecond}, zap.NewNop(), metrics.NullFactory)
+
+	// the stored strategies are read when the provider is created
+	assert.InDelta(t, 0.5, samplingRate(t, p, "frontend"), 1e-9)
+	assert.InDelta(t, 0.001, samplingRate(t, p, "billing"), 1e-9)
+
+	require.NoError(t, store.WriteStrategy(ctx, "billing", probabilistic(1)))
+	require.NoError(t, store.DeleteStrategy(ctx, "frontend"))
+	assert.Eventually(t, func() bool {
+		return samplingRate(t, p, "billing") == 1 && samplingRate(t, p, "frontend") == 0.001
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, p.Close())
+	require.NoError(t, p.Close())
+	assert.True(t, fallback.closed.Load())
+}
+
+func TestProviderRefreshFailure(t *testing.T) {
+	mb := metricstest.NewFactory(0)
+	defer mb.Backend.Stop()
+	store := &failingStore{StrategyStore: memory.NewStrategyStore()}
+	require.NoError(t, store.WriteStrategy(context.Background(), "frontend", probabilistic(0.5)))
+	p := NewProvider(&fakeProvider{}, store, Options{RefreshInterval: 10 * time.Millisecond}, zap.NewNop(), mb)
+	defer p.Close()
+	_, gauges := mb.Snapshot()
+	assert.Equal(t, int64(1), gauges["stored_sampling.services"])
+
+	// the previous strategies are kept when the storage fails
+	store.fail.Store(true)
+	assert.Eventually(t, func() bool {
+		counters, _ := mb.Snapshot()
+		return counters["stored_sampling.refreshes|result=err"] > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.InDelta(t, 0.5, samplingRate(t, p, "frontend"), 1e-9)
+}
+
+func TestProviderStorageUnavailable(t *testing.T) {
+	store := &failingStore{StrategyStore: memory.NewStrategyStore()}
+	store.fail.Store(true)
+	p := NewProvider(&fakeProvider{}, store, Options{RefreshInterval: time.Hour}, zap.NewNop(), metrics.NullFactory)
+	defer p.Close()
+	// the fallback strategies are served until the storage is available
+	assert.InDelta(t, 0.001, samplingRate(t, p, "frontend"), 1e-9)
+}
@@ -16,9 +16,11 @@ package dependencystore
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -37,6 +39,8 @@ func NewDependencyStore(store spanstore.Reader) *DependencyStore {
 // GetDependencies returns all interservice dependencies, implements DependencyReader
 func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
 	deps := map[string]*model.DependencyLink{}
+	// the calls are weighted by the adjusted counts of the sampled spans, to count the actual traffic
+	callCounts := map[string]float64{}
 
 	params := &spanstore.TraceQueryParameters{
 		StartTimeMin: endTs.Add(-1 * lookback),
@@ -52,23 +56,40 @@ func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time,
 		return nil, err
 	}
 	for _, tr := range traces {
-		processTrace(deps, tr)
+		processTrace(deps, callCounts, tr)
 	}
 
-	return depMapToSlice(deps), err
+	return depMapToSlice(deps, callCounts), err
+}
+
+// GetDependencyLatencies returns all interservice dependencies with the latencies of their calls, implements dependencystore.LatencyReader
+func (s *DependencyStore) GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLatency, error) {
+	traces, err := s.reader.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		StartTimeMin: endTs.Add(-1 * lookback),
+		StartTimeMax: endTs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	aggregator := dependencystore.NewLatencyAggregator(nil)
+	for _, tr := range traces {
+		aggregator.AddTrace(tr)
+	}
+	return aggregator.Latencies(), nil
 }
 
 // depMapToSlice modifies the spans to DependencyLink in the same way as the memory storage plugin
-func depMapToSlice(deps map[string]*model.DependencyLink) []model.DependencyLink {
+func depMapToSlice(deps map[string]*model.DependencyLink, callCounts map[string]float64) []model.DependencyLink {
 	retMe := make([]model.DependencyLink, 0, len(deps))
-	for _, dep := range deps {
+	for depKey, dep := range deps {
+		dep.CallCount = uint64(math.Round(callCounts[depKey]))
 		retMe = append(retMe, *dep)
 	}
 	return retMe
 }
 
 // processTrace is copy from the memory storage plugin
-func processTrace(deps map[string]*model.DependencyLink, trace *model.Trace) {
+func processTrace(deps map[string]*model.DependencyLink, callCounts map[string]float64, trace *model.Trace) {
 	for _, s := range trace.Spans {
 		parentSpan := seekToSpan(trace, s.ParentSpanID())
 		if parentSpan != nil {
@@ -78,13 +99,11 @@ func processTrace(deps map[string]*model.DependencyLink, trace *model.Trace) {
 			depKey := parentSpan.Process.ServiceName + "&&&" + s.Process.ServiceName
 			if _, ok := deps[depKey]; !ok {
 				deps[depKey] = &model.DependencyLink{
-					Parent:    parentSpan.Process.ServiceName,
-					Child:     s.Process.ServiceName,
-					CallCount: 1,
+					Parent: parentSpan.Process.ServiceName,
+					Child:  s.Process.ServiceName,
 				}
-			} else {
-				deps[depKey].CallCount++
 			}
+			callCounts[depKey] += s.GetAdjustedCount()
 		}
 	}
 }
@@ -17,6 +17,7 @@ package dependencystore_test
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -86,6 +87,10 @@ func TestDependencyReader(t *testing.T) {
 				if j > 0 {
 					s.References = []model.SpanRef{model.NewChildOfRef(s.TraceID, model.SpanID(j-1))}
 				}
+				if j > 1 {
+					// the calls of the sampled spans are counted by their adjusted count
+					s.Tags = model.KeyValues{model.Float64(model.AdjustedCountTag, 4)}
+				}
 				err := sw.WriteSpan(context.Background(), &s)
 				require.NoError(t, err)
 			}
@@ -93,7 +98,18 @@ func TestDependencyReader(t *testing.T) {
 		links, err = dr.GetDependencies(context.Background(), time.Now(), time.Hour)
 		require.NoError(t, err)
 		assert.NotEmpty(t, links)
-		assert.Len(t, links, spans-1)                       // First span does not create a dependency
-		assert.Equal(t, uint64(traces), links[0].CallCount) // Each trace calls the same services
+		assert.Len(t, links, spans-1) // First span does not create a dependency
+		sort.Slice(links, func(i, j int) bool { return links[i].Parent < links[j].Parent })
+		assert.Equal(t, uint64(traces), links[0].CallCount)   // Each trace calls the same services
+		assert.Equal(t, uint64(4*traces), links[1].CallCount) // The calls of the last service are sampled at 25%
+
+		latencies, err := dr.(dependencystore.LatencyReader).GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+		require.NoError(t, err)
+		require.Len(t, latencies, spans-1)
+		assert.Equal(t, "service-0", latencies[0].Parent)
+		assert.Equal(t, "service-1", latencies[0].Child)
+		assert.Equal(t, uint64(traces), latencies[0].CallCount)
+		assert.Equal(t, int64(traces), latencies[0].Latency.Count)
+		assert.Equal(t, time.Duration(traces), latencies[0].Latency.P99) // The durations of the calls are 1 to traces
 	})
 }
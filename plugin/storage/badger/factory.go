@@ -34,10 +34,12 @@ import (
 	depStore "github.com/jaegertracing/jaeger/plugin/storage/badger/dependencystore"
 	badgerSampling "github.com/jaegertracing/jaeger/plugin/storage/badger/samplingstore"
 	badgerStore "github.com/jaegertracing/jaeger/plugin/storage/badger/spanstore"
+	badgerWarnings "github.com/jaegertracing/jaeger/plugin/storage/badger/warningstore"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
 )
 
 const (
@@ -57,6 +59,7 @@ var ( // interface comformance checks
 	// _ storage.ArchiveFactory       = (*Factory)(nil)
 
 	_ storage.SamplingStoreFactory = (*Factory)(nil)
+	_ storage.WarningStoreFactory  = (*Factory)(nil)
 )
 
 // Factory implements storage.Factory for Badger backend.
@@ -184,12 +187,12 @@ func initializeDir(path string) {
 
 // CreateSpanReader implements storage.Factory
 func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
-	return badgerStore.NewTraceReader(f.store, f.cache), nil
+	return badgerStore.NewTraceReader(f.store, f.cache, f.Options.Primary.TagIndex), nil
 }
 
 // CreateSpanWriter implements storage.Factory
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
-	return badgerStore.NewSpanWriter(f.store, f.cache, f.Options.Primary.SpanStoreTTL), nil
+	return badgerStore.NewSpanWriter(f.store, f.cache, f.Options.Primary.SpanStoreTTL, f.Options.Primary.TagIndex), nil
 }
 
 // CreateDependencyReader implements storage.Factory
@@ -198,11 +201,29 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return depStore.NewDependencyStore(sr), nil
 }
 
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{
+		TraceSearch: true,
+		TagSearch:   true,
+		// the span kind of the operations is not returned yet,
+		// see https://github.com/jaegertracing/jaeger/issues/1922
+		OperationSpanKind: false,
+		Dependencies:      true,
+		AdaptiveSampling:  true,
+	}
+}
+
 // CreateSamplingStore implements storage.SamplingStoreFactory
 func (f *Factory) CreateSamplingStore(int /* maxBuckets */) (samplingstore.Store, error) {
 	return badgerSampling.NewSamplingStore(f.store), nil
 }
 
+// CreateWarningStore implements storage.WarningStoreFactory, the warnings expiring with the spans.
+func (f *Factory) CreateWarningStore() (warningstore.Store, error) {
+	return badgerWarnings.NewWarningStore(f.store, f.Options.Primary.SpanStoreTTL), nil
+}
+
 // CreateLock implements storage.SamplingStoreFactory
 func (*Factory) CreateLock() (distributedlock.Lock, error) {
 	return &lock{}, nil
@@ -68,11 +68,16 @@ func TestForCodecov(t *testing.T) {
 
 	_, err = f.CreateDependencyReader()
 	require.NoError(t, err)
+	assert.False(t, f.Capabilities().OperationSpanKind)
 
 	lock, err := f.CreateLock()
 	require.NoError(t, err)
 	assert.NotNil(t, lock)
 
+	warnings, err := f.CreateWarningStore()
+	require.NoError(t, err)
+	assert.NotNil(t, warnings)
+
 	// Now, remove the badger directories
 	err = os.RemoveAll(f.tmpDir)
 	require.NoError(t, err)
@@ -18,10 +18,13 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+
+	badgerStore "github.com/jaegertracing/jaeger/plugin/storage/badger/spanstore"
 )
 
 // Options store storage plugin related configs
@@ -42,6 +45,8 @@ type NamespaceConfig struct {
 	MaintenanceInterval   time.Duration `mapstructure:"maintenance_interval"`
 	MetricsUpdateInterval time.Duration `mapstructure:"metrics_update_interval"`
 	ReadOnly              bool          `mapstructure:"read_only"`
+	// TagIndex selects the tags of the spans written to the tag index.
+	TagIndex badgerStore.TagIndex `mapstructure:"tag_index"`
 }
 
 const (
@@ -59,6 +64,8 @@ const (
 	suffixMaintenanceInterval = ".maintenance-interval"
 	suffixMetricsInterval     = ".metrics-update-interval" // Intended only for testing purposes
 	suffixReadOnly            = ".read-only"
+	suffixTagIndexDisabled    = ".tag-index.disabled"
+	suffixTagIndexAllowList   = ".tag-index.allow-list"
 	defaultDataDir            = string(os.PathSeparator) + "data"
 	defaultValueDir           = defaultDataDir + string(os.PathSeparator) + "values"
 	defaultKeysDir            = defaultDataDir + string(os.PathSeparator) + "keys"
@@ -141,6 +148,16 @@ func addFlags(flagSet *flag.FlagSet, nsConfig NamespaceConfig) {
 		nsConfig.ReadOnly,
 		"Allows to open badger database in read only mode. Multiple instances can open same database in read-only mode. Values still in the write-ahead-log must be replayed before opening.",
 	)
+	flagSet.Bool(
+		nsConfig.namespace+suffixTagIndexDisabled,
+		nsConfig.TagIndex.Disabled,
+		"Disables the writes of the span tags to the tag index. The traces queried by tags are then found by their service and operation, and matched against the tags once read.",
+	)
+	flagSet.String(
+		nsConfig.namespace+suffixTagIndexAllowList,
+		strings.Join(nsConfig.TagIndex.AllowList, ","),
+		"The comma-separated list of span tags written to the tag index, all of them if empty. The traces queried by other tags are matched against them once read.",
+	)
 }
 
 // InitFromViper initializes Options with properties from viper
@@ -157,6 +174,13 @@ func initFromViper(cfg *NamespaceConfig, v *viper.Viper, _ *zap.Logger) {
 	cfg.MaintenanceInterval = v.GetDuration(cfg.namespace + suffixMaintenanceInterval)
 	cfg.MetricsUpdateInterval = v.GetDuration(cfg.namespace + suffixMetricsInterval)
 	cfg.ReadOnly = v.GetBool(cfg.namespace + suffixReadOnly)
+	cfg.TagIndex.Disabled = v.GetBool(cfg.namespace + suffixTagIndexDisabled)
+	cfg.TagIndex.AllowList = nil
+	for _, key := range strings.Split(v.GetString(cfg.namespace+suffixTagIndexAllowList), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			cfg.TagIndex.AllowList = append(cfg.TagIndex.AllowList, key)
+		}
+	}
 }
 
 // GetPrimary returns the primary namespace configuration
@@ -33,6 +33,8 @@ func TestDefaultOptionsParsing(t *testing.T) {
 	assert.True(t, opts.GetPrimary().Ephemeral)
 	assert.False(t, opts.GetPrimary().SyncWrites)
 	assert.Equal(t, time.Duration(72*time.Hour), opts.GetPrimary().SpanStoreTTL)
+	assert.False(t, opts.GetPrimary().TagIndex.Disabled)
+	assert.Empty(t, opts.GetPrimary().TagIndex.AllowList)
 }
 
 func TestParseOptions(t *testing.T) {
@@ -44,6 +46,8 @@ func TestParseOptions(t *testing.T) {
 		"--badger.directory-key=/var/lib/badger",
 		"--badger.directory-value=/mnt/slow/badger",
 		"--badger.span-store-ttl=168h",
+		"--badger.tag-index.disabled=true",
+		"--badger.tag-index.allow-list=http.method, error,",
 	})
 	opts.InitFromViper(v, zap.NewNop())
 
@@ -53,6 +57,8 @@ func TestParseOptions(t *testing.T) {
 	assert.Equal(t, "/var/lib/badger", opts.GetPrimary().KeyDirectory)
 	assert.Equal(t, "/mnt/slow/badger", opts.GetPrimary().ValueDirectory)
 	assert.False(t, opts.GetPrimary().ReadOnly)
+	assert.True(t, opts.GetPrimary().TagIndex.Disabled)
+	assert.Equal(t, []string{"http.method", "error"}, opts.GetPrimary().TagIndex.AllowList)
 }
 
 func TestReadOnlyOptions(t *testing.T) {
@@ -126,6 +126,11 @@ func TestValidation(t *testing.T) {
 		params.Tags = map[string]string{"A": "B"}
 		_, err = sr.FindTraces(context.Background(), params)
 		require.EqualError(t, err, "service name must be set")
+
+		params.Tags = nil
+		params.ResourceAttributes = map[string]string{"deployment.environment": "prod"}
+		_, err = sr.FindTraces(context.Background(), params)
+		require.EqualError(t, err, "service name must be set")
 	})
 }
 
@@ -289,6 +294,93 @@ func TestIndexSeeks(t *testing.T) {
 	})
 }
 
+func TestFindTraceIDsByPrefix(t *testing.T) {
+	runFactoryTest(t, func(_ testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		startT := time.Now()
+		traceIDs := []model.TraceID{
+			model.NewTraceID(0xabcd000000000001, 1),
+			model.NewTraceID(0xabcd000000000002, 2),
+			model.NewTraceID(0xef00000000000001, 3),
+		}
+		for i, traceID := range traceIDs {
+			for j := 0; j < 2; j++ {
+				require.NoError(t, sw.WriteSpan(context.Background(), &model.Span{
+					TraceID:       traceID,
+					SpanID:        model.SpanID(j + 1),
+					OperationName: "operation",
+					Process:       &model.Process{ServiceName: "service"},
+					StartTime:     startT.Add(time.Duration(i) * time.Millisecond),
+				}))
+			}
+		}
+
+		reader, ok := sr.(spanstore.TraceIDPrefixReader)
+		require.True(t, ok)
+		query := &spanstore.TraceIDPrefixQueryParameters{
+			Prefix:       "abcd",
+			StartTimeMin: startT,
+			StartTimeMax: startT.Add(time.Second),
+		}
+		found, err := reader.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{traceIDs[1], traceIDs[0]}, found)
+
+		query.NumTraces = 1
+		found, err = reader.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{traceIDs[1]}, found)
+
+		query.StartTimeMin = startT.Add(time.Second)
+		query.StartTimeMax = startT.Add(2 * time.Second)
+		found, err = reader.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+
+		_, err = reader.FindTraceIDsByPrefix(context.Background(), &spanstore.TraceIDPrefixQueryParameters{Prefix: "abcd"})
+		require.EqualError(t, err, "start and end time must be set")
+	})
+}
+
+func TestFindTracesByLogFields(t *testing.T) {
+	runFactoryTest(t, func(_ testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		startT := time.Now()
+		logs := [][]model.Log{
+			{{Fields: model.KeyValues{model.String("event", "exception"), model.String("exception.type", "IOError")}}},
+			{{Fields: model.KeyValues{model.String("event", "exception")}}, {Fields: model.KeyValues{model.String("exception.type", "IOError")}}},
+		}
+		for i, spanLogs := range logs {
+			require.NoError(t, sw.WriteSpan(context.Background(), &model.Span{
+				TraceID:       model.NewTraceID(0, uint64(i+1)),
+				SpanID:        model.SpanID(1),
+				OperationName: "operation",
+				Process:       &model.Process{ServiceName: "service"},
+				StartTime:     startT.Add(time.Duration(i) * time.Millisecond),
+				Logs:          spanLogs,
+			}))
+		}
+
+		query := &spanstore.TraceQueryParameters{
+			ServiceName:  "service",
+			LogFields:    map[string]string{"event": "exception", "exception.type": "IOError"},
+			StartTimeMin: startT,
+			StartTimeMax: startT.Add(time.Second),
+		}
+		traces, err := sr.FindTraces(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, traces, 1)
+		assert.Equal(t, model.NewTraceID(0, 1), traces[0].Spans[0].TraceID)
+
+		query.LogFields = map[string]string{"event": "timeout"}
+		traceIDs, err := sr.FindTraceIDs(context.Background(), query)
+		require.NoError(t, err)
+		assert.Empty(t, traceIDs)
+
+		query.ServiceName = ""
+		_, err = sr.FindTraceIDs(context.Background(), query)
+		require.EqualError(t, err, "service name must be set")
+	})
+}
+
 func TestFindNothing(t *testing.T) {
 	runFactoryTest(t, func(_ testing.TB, _ spanstore.Writer, sr spanstore.Reader) {
 		startT := time.Now()
@@ -372,6 +464,13 @@ func TestMenuSeeks(t *testing.T) {
 
 		assert.Len(t, operations, spans)
 		assert.Len(t, serviceList, services)
+
+		operations, err = sr.GetOperations(
+			context.Background(),
+			spanstore.OperationQueryParameters{ServiceName: "service-1", NamePrefix: "operation-", Offset: 1, Limit: 1},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []spanstore.Operation{{Name: "operation-1"}}, operations)
 	})
 }
 
@@ -66,8 +66,9 @@ const (
 
 // TraceReader reads traces from the local badger store
 type TraceReader struct {
-	store *badger.DB
-	cache *CacheStore
+	store      *badger.DB
+	cache      *CacheStore
+	indexedTag func(key string) bool
 }
 
 // executionPlan is internal structure to track the index filtering
@@ -82,13 +83,23 @@ type executionPlan struct {
 
 	// hashOuter is the hashmap for hash-join of outer resultset
 	hashOuter map[model.TraceID]struct{}
+
+	// traceIDPrefix restricts the scanned traces to the IDs with the prefix
+	traceIDPrefix string
+
+	// filterTags are the tags of the query not in the tag index, matched against the traces found
+	filterTags  map[string]string
+	serviceName string
+	// logFields are matched against the logs of the traces found
+	logFields map[string]string
 }
 
-// NewTraceReader returns a TraceReader with cache
-func NewTraceReader(db *badger.DB, c *CacheStore) *TraceReader {
+// NewTraceReader returns a TraceReader with cache, searching the tags selected by tagIndex in the tag index
+func NewTraceReader(db *badger.DB, c *CacheStore, tagIndex TagIndex) *TraceReader {
 	return &TraceReader{
-		store: db,
-		cache: c,
+		store:      db,
+		cache:      c,
+		indexedTag: tagIndex.indexed(),
 	}
 }
 
@@ -110,7 +121,7 @@ func decodeValue(val []byte, encodeType byte) (*model.Span, error) {
 }
 
 // getTraces enriches TraceIDs to Traces
-func (r *TraceReader) getTraces(traceIDs []model.TraceID) ([]*model.Trace, error) {
+func (r *TraceReader) getTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
 	// Get by PK
 	traces := make([]*model.Trace, 0, len(traceIDs))
 	prefixes := make([][]byte, 0, len(traceIDs))
@@ -126,6 +137,9 @@ func (r *TraceReader) getTraces(traceIDs []model.TraceID) ([]*model.Trace, error
 
 		val := []byte{}
 		for _, prefix := range prefixes {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			spans := make([]*model.Span, 0, 32) // reduce reallocation requirements by defining some initial length
 
 			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
@@ -157,8 +171,8 @@ func (r *TraceReader) getTraces(traceIDs []model.TraceID) ([]*model.Trace, error
 }
 
 // GetTrace takes a traceID and returns a Trace associated with that traceID
-func (r *TraceReader) GetTrace(_ context.Context, traceID model.TraceID) (*model.Trace, error) {
-	traces, err := r.getTraces([]model.TraceID{traceID})
+func (r *TraceReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	traces, err := r.getTraces(ctx, []model.TraceID{traceID})
 	if err != nil {
 		return nil, err
 	}
@@ -172,8 +186,20 @@ func (r *TraceReader) GetTrace(_ context.Context, traceID model.TraceID) (*model
 	return nil, ErrInternalConsistencyError
 }
 
+// matchesTraceID returns whether a trace found by a scan is in the result set of the plan
+func (plan *executionPlan) matchesTraceID(traceID model.TraceID) bool {
+	if plan.traceIDPrefix != "" && !spanstore.TraceIDHasPrefix(traceID, plan.traceIDPrefix) {
+		return false
+	}
+	if plan.hashOuter != nil {
+		_, exists := plan.hashOuter[traceID]
+		return exists
+	}
+	return true
+}
+
 // scanTimeRange returns all the Traces found between startTs and endTs
-func (r *TraceReader) scanTimeRange(plan *executionPlan) ([]model.TraceID, error) {
+func (r *TraceReader) scanTimeRange(ctx context.Context, plan *executionPlan) ([]model.TraceID, error) {
 	// We need to do a full table scan
 	traceKeys := make([][]byte, 0)
 	err := r.store.View(func(txn *badger.Txn) error {
@@ -185,6 +211,9 @@ func (r *TraceReader) scanTimeRange(plan *executionPlan) ([]model.TraceID, error
 		startIndex := []byte{spanKeyPrefix}
 		prevTraceID := []byte{}
 		for it.Seek(startIndex); it.ValidForPrefix(startIndex); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			item := it.Item()
 
 			key := []byte{}
@@ -195,12 +224,7 @@ func (r *TraceReader) scanTimeRange(plan *executionPlan) ([]model.TraceID, error
 
 			if bytes.Compare(timestamp, plan.startTimeMin) >= 0 && bytes.Compare(timestamp, plan.startTimeMax) <= 0 {
 				if !bytes.Equal(traceID, prevTraceID) {
-					if plan.hashOuter != nil {
-						trID := bytesToTraceID(traceID)
-						if _, exists := plan.hashOuter[trID]; exists {
-							traceKeys = append(traceKeys, key)
-						}
-					} else {
+					if plan.matchesTraceID(bytesToTraceID(traceID)) {
 						traceKeys = append(traceKeys, key)
 					}
 					prevTraceID = traceID
@@ -250,7 +274,11 @@ func (r *TraceReader) GetOperations(
 	_ context.Context,
 	query spanstore.OperationQueryParameters,
 ) ([]spanstore.Operation, error) {
-	return r.cache.GetOperations(query.ServiceName)
+	operations, err := r.cache.GetOperations(query.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	return spanstore.PageOperations(operations, query), nil
 }
 
 // setQueryDefaults alters the query with defaults if certain parameters are not set
@@ -260,12 +288,21 @@ func setQueryDefaults(query *spanstore.TraceQueryParameters) {
 	}
 }
 
-// serviceQueries parses the query to index seeks which are unique index seeks
-func serviceQueries(query *spanstore.TraceQueryParameters, indexSeeks [][]byte) [][]byte {
+// serviceQueries parses the query to index seeks which are unique index seeks, the tags not
+// in the tag index being added to the filterTags of the plan
+func (r *TraceReader) serviceQueries(plan *executionPlan, query *spanstore.TraceQueryParameters, indexSeeks [][]byte) [][]byte {
 	if query.ServiceName != "" {
 		indexSearchKey := make([]byte, 0, 64) // 64 is a magic guess
 		tagQueryUsed := false
 		for k, v := range query.Tags {
+			if !r.indexedTag(k) {
+				if plan.filterTags == nil {
+					plan.filterTags = make(map[string]string)
+					plan.serviceName = query.ServiceName
+				}
+				plan.filterTags[k] = v
+				continue
+			}
 			tagSearch := []byte(query.ServiceName + k + v)
 			tagSearchKey := make([]byte, 0, len(tagSearch)+1)
 			tagSearchKey = append(tagSearchKey, tagIndexKey)
@@ -290,9 +327,9 @@ func serviceQueries(query *spanstore.TraceQueryParameters, indexSeeks [][]byte)
 }
 
 // indexSeeksToTraceIDs does the index scanning against badger based on the parsed index queries
-func (r *TraceReader) indexSeeksToTraceIDs(plan *executionPlan, indexSeeks [][]byte) ([]model.TraceID, error) {
+func (r *TraceReader) indexSeeksToTraceIDs(ctx context.Context, plan *executionPlan, indexSeeks [][]byte) ([]model.TraceID, error) {
 	for i := len(indexSeeks) - 1; i > 0; i-- {
-		indexResults, err := r.scanIndexKeys(indexSeeks[i], plan)
+		indexResults, err := r.scanIndexKeys(ctx, indexSeeks[i], plan)
 		if err != nil {
 			return nil, err
 		}
@@ -321,7 +358,7 @@ func (r *TraceReader) indexSeeksToTraceIDs(plan *executionPlan, indexSeeks [][]b
 	}
 
 	// Last scan should get us in correct timestamp order
-	ids, err := r.scanIndexKeys(indexSeeks[0], plan)
+	ids, err := r.scanIndexKeys(ctx, indexSeeks[0], plan)
 	if err != nil {
 		return nil, err
 	}
@@ -335,11 +372,10 @@ func (r *TraceReader) indexSeeksToTraceIDs(plan *executionPlan, indexSeeks [][]b
 		plan.hashOuter = buildHash(plan, ids)
 	}
 
-	traceIDs := filterIDs(plan, ids)
-	return traceIDs, nil
+	return r.filterIDs(ctx, plan, ids)
 }
 
-func filterIDs(plan *executionPlan, innerIDs [][]byte) []model.TraceID {
+func (r *TraceReader) filterIDs(ctx context.Context, plan *executionPlan, innerIDs [][]byte) ([]model.TraceID, error) {
 	traces := make([]model.TraceID, 0, plan.limit)
 
 	items := 0
@@ -347,17 +383,39 @@ func filterIDs(plan *executionPlan, innerIDs [][]byte) []model.TraceID {
 		trID := bytesToTraceID(innerIDs[i])
 
 		if _, found := plan.hashOuter[trID]; found {
-			traces = append(traces, trID)
 			delete(plan.hashOuter, trID) // Prevent duplicate add
-			items++
+			matches, err := r.matchesFilterTags(ctx, plan, trID)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				traces = append(traces, trID)
+				items++
+			}
 		}
 
 		if items == plan.limit {
-			return traces
+			return traces, nil
 		}
 	}
 
-	return traces
+	return traces, nil
+}
+
+// matchesFilterTags reads the trace to match it against the tags of the query not in the tag index
+// and the log fields of the query, which are not indexed
+func (r *TraceReader) matchesFilterTags(ctx context.Context, plan *executionPlan, traceID model.TraceID) (bool, error) {
+	if len(plan.filterTags) == 0 && len(plan.logFields) == 0 {
+		return true, nil
+	}
+	traces, err := r.getTraces(ctx, []model.TraceID{traceID})
+	if err != nil {
+		return false, err
+	}
+	if len(traces) != 1 || !hasTags(traces[0], plan.serviceName, plan.filterTags) {
+		return false, nil
+	}
+	return len(plan.logFields) == 0 || spanstore.TraceHasLogWithFields(traces[0], plan.logFields), nil
 }
 
 func bytesToTraceID(key []byte) model.TraceID {
@@ -388,7 +446,7 @@ func buildHash(plan *executionPlan, outerIDs [][]byte) map[model.TraceID]struct{
 }
 
 // durationQueries checks non unique index of durations and returns a map for further filtering purposes
-func (r *TraceReader) durationQueries(plan *executionPlan, query *spanstore.TraceQueryParameters) map[model.TraceID]struct{} {
+func (r *TraceReader) durationQueries(ctx context.Context, plan *executionPlan, query *spanstore.TraceQueryParameters) (map[model.TraceID]struct{}, error) {
 	durMax := uint64(model.DurationAsMicroseconds(query.DurationMax))
 	durMin := uint64(model.DurationAsMicroseconds(query.DurationMin))
 
@@ -406,7 +464,10 @@ func (r *TraceReader) durationQueries(plan *executionPlan, query *spanstore.Trac
 	binary.BigEndian.PutUint64(startKey[1:], durMin)
 
 	// This is not unique index result - same TraceID can be matched from multiple spans
-	indexResults, _ := r.scanRangeIndex(plan, startKey, endKey)
+	indexResults, err := r.scanRangeIndex(ctx, plan, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
 	hashFilter := make(map[model.TraceID]struct{})
 	var value struct{}
 	for _, k := range indexResults {
@@ -420,7 +481,7 @@ func (r *TraceReader) durationQueries(plan *executionPlan, query *spanstore.Trac
 		}
 	}
 
-	return hashFilter
+	return hashFilter, nil
 }
 
 func mergeJoinIds(left, right [][]byte) [][]byte {
@@ -460,21 +521,19 @@ func (r *TraceReader) FindTraces(ctx context.Context, query *spanstore.TraceQuer
 		return nil, err
 	}
 
-	return r.getTraces(keys)
+	return r.getTraces(ctx, keys)
 }
 
 // FindTraceIDs retrieves only the TraceIDs that match the traceQuery, but not the trace data
-func (r *TraceReader) FindTraceIDs(_ context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+func (r *TraceReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
 	// Validate and set query defaults which were not defined
 	if err := validateQuery(query); err != nil {
 		return nil, err
 	}
 
 	setQueryDefaults(query)
-
-	// Find matches using indexes that are using service as part of the key
-	indexSeeks := make([][]byte, 0, 1)
-	indexSeeks = serviceQueries(query, indexSeeks)
+	// the resource attributes are indexed as the other process tags
+	spanstore.ResourceAttributesAsTags(query)
 
 	startStampBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(startStampBytes, model.TimeAsEpochMicroseconds(query.StartTimeMin))
@@ -486,14 +545,23 @@ func (r *TraceReader) FindTraceIDs(_ context.Context, query *spanstore.TraceQuer
 		startTimeMin: startStampBytes,
 		startTimeMax: endStampBytes,
 		limit:        query.NumTraces,
+		logFields:    query.LogFields,
 	}
 
+	// Find matches using indexes that are using service as part of the key
+	indexSeeks := make([][]byte, 0, 1)
+	indexSeeks = r.serviceQueries(plan, query, indexSeeks)
+
 	if query.DurationMax != 0 || query.DurationMin != 0 {
-		plan.hashOuter = r.durationQueries(plan, query)
+		hashOuter, err := r.durationQueries(ctx, plan, query)
+		if err != nil {
+			return nil, err
+		}
+		plan.hashOuter = hashOuter
 	}
 
 	if len(indexSeeks) > 0 {
-		keys, err := r.indexSeeksToTraceIDs(plan, indexSeeks)
+		keys, err := r.indexSeeksToTraceIDs(ctx, plan, indexSeeks)
 		if err != nil {
 			return nil, err
 		}
@@ -501,7 +569,31 @@ func (r *TraceReader) FindTraceIDs(_ context.Context, query *spanstore.TraceQuer
 		return keys, nil
 	}
 
-	return r.scanTimeRange(plan)
+	return r.scanTimeRange(ctx, plan)
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix
+// with a scan of the keys of the spans.
+func (r *TraceReader) FindTraceIDsByPrefix(ctx context.Context, query *spanstore.TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	if query.StartTimeMin.IsZero() || query.StartTimeMax.IsZero() {
+		return nil, ErrStartAndEndTimeNotSet
+	}
+	startStampBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(startStampBytes, model.TimeAsEpochMicroseconds(query.StartTimeMin))
+
+	endStampBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(endStampBytes, model.TimeAsEpochMicroseconds(query.StartTimeMax))
+
+	limit := query.NumTraces
+	if limit <= 0 {
+		limit = defaultNumTraces
+	}
+	return r.scanTimeRange(ctx, &executionPlan{
+		startTimeMin:  startStampBytes,
+		startTimeMax:  endStampBytes,
+		limit:         limit,
+		traceIDPrefix: query.Prefix,
+	})
 }
 
 // validateQuery returns an error if certain restrictions are not met
@@ -509,7 +601,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
 		return ErrMalformedRequestObject
 	}
-	if p.ServiceName == "" && len(p.Tags) > 0 {
+	if p.ServiceName == "" && (len(p.Tags) > 0 || len(p.LogFields) > 0 || len(p.ResourceAttributes) > 0) {
 		return ErrServiceNameNotSet
 	}
 	if p.ServiceName == "" && p.OperationName != "" {
@@ -528,7 +620,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 }
 
 // scanIndexKeys scans the time range for index keys matching the given prefix.
-func (r *TraceReader) scanIndexKeys(indexKeyValue []byte, plan *executionPlan) ([][]byte, error) {
+func (r *TraceReader) scanIndexKeys(ctx context.Context, indexKeyValue []byte, plan *executionPlan) ([][]byte, error) {
 	indexResults := make([][]byte, 0)
 
 	err := r.store.View(func(txn *badger.Txn) error {
@@ -545,6 +637,9 @@ func (r *TraceReader) scanIndexKeys(indexKeyValue []byte, plan *executionPlan) (
 		copy(startIndex[len(indexKeyValue):], plan.startTimeMax)
 
 		for it.Seek(startIndex); scanFunction(it, indexKeyValue, plan.startTimeMin); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			item := it.Item()
 
 			// ScanFunction is a prefix scanning (since we could have for example service1 & service12)
@@ -583,7 +678,7 @@ func scanFunction(it *badger.Iterator, indexPrefix []byte, timeBytesEnd []byte)
 }
 
 // scanRangeIndex scans the time range for index keys matching the given prefix.
-func (r *TraceReader) scanRangeIndex(plan *executionPlan, indexStartValue []byte, indexEndValue []byte) ([][]byte, error) {
+func (r *TraceReader) scanRangeIndex(ctx context.Context, plan *executionPlan, indexStartValue []byte, indexEndValue []byte) ([][]byte, error) {
 	indexResults := make([][]byte, 0)
 
 	err := r.store.View(func(txn *badger.Txn) error {
@@ -598,6 +693,9 @@ func (r *TraceReader) scanRangeIndex(plan *executionPlan, indexStartValue []byte
 		copy(startIndex[len(indexStartValue):], plan.startTimeMin)
 
 		for it.Seek(startIndex); scanRangeFunction(it, indexEndValue); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			item := it.Item()
 
 			// ScanFunction is a prefix scanning (since we could have for example service1 & service12)
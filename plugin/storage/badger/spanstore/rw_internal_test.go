@@ -34,8 +34,8 @@ func TestEncodingTypes(t *testing.T) {
 		testSpan := createDummySpan()
 
 		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
-		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour))
-		rw := NewTraceReader(store, cache)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), TagIndex{})
+		rw := NewTraceReader(store, cache, TagIndex{})
 
 		sw.encodingType = jsonEncoding
 		err := sw.WriteSpan(context.Background(), &testSpan)
@@ -51,8 +51,8 @@ func TestEncodingTypes(t *testing.T) {
 		testSpan := createDummySpan()
 
 		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
-		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour))
-		// rw := NewTraceReader(store, cache)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), TagIndex{})
+		// rw := NewTraceReader(store, cache, TagIndex{})
 
 		sw.encodingType = 0x04
 		err := sw.WriteSpan(context.Background(), &testSpan)
@@ -64,8 +64,8 @@ func TestEncodingTypes(t *testing.T) {
 		testSpan := createDummySpan()
 
 		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
-		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour))
-		rw := NewTraceReader(store, cache)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), TagIndex{})
+		rw := NewTraceReader(store, cache, TagIndex{})
 
 		err := sw.WriteSpan(context.Background(), &testSpan)
 		require.NoError(t, err)
@@ -103,8 +103,8 @@ func TestDuplicateTraceIDDetection(t *testing.T) {
 	runWithBadger(t, func(store *badger.DB, t *testing.T) {
 		testSpan := createDummySpan()
 		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
-		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour))
-		rw := NewTraceReader(store, cache)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), TagIndex{})
+		rw := NewTraceReader(store, cache, TagIndex{})
 		origStartTime := testSpan.StartTime
 
 		traceCount := 128
@@ -199,3 +199,94 @@ func TestMergeJoin(t *testing.T) {
 	assert.Len(merged, 2)
 	assert.Equal(uint32(2), binary.BigEndian.Uint32(merged[1]))
 }
+
+func TestTagIndexAllowList(t *testing.T) {
+	write := func(t *testing.T, sw *SpanWriter, traceID uint64, service string, tags ...model.KeyValue) {
+		span := createDummySpan()
+		span.TraceID = model.TraceID{Low: traceID}
+		span.Process.ServiceName = service
+		span.Tags = tags
+		require.NoError(t, sw.WriteSpan(context.Background(), &span))
+	}
+	countTagIndexKeys := func(t *testing.T, store *badger.DB) int {
+		count := 0
+		err := store.View(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchValues = false
+			it := txn.NewIterator(opts)
+			defer it.Close()
+			for it.Seek([]byte{tagIndexKey}); it.ValidForPrefix([]byte{tagIndexKey}); it.Next() {
+				count++
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return count
+	}
+	findTraceIDs := func(t *testing.T, rw *TraceReader, numTraces int, tags map[string]string) []model.TraceID {
+		traceIDs, err := rw.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  "service",
+			Tags:         tags,
+			NumTraces:    numTraces,
+			StartTimeMin: time.Now().Add(-time.Hour),
+			StartTimeMax: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		return traceIDs
+	}
+
+	for _, tagIndex := range []TagIndex{{AllowList: []string{"http.method"}}, {Disabled: true}} {
+		runWithBadger(t, func(store *badger.DB, t *testing.T) {
+			cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+			sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), tagIndex)
+			rw := NewTraceReader(store, cache, tagIndex)
+
+			write(t, sw, 1, "service", model.String("http.method", "GET"), model.Bool("error", true))
+			write(t, sw, 2, "service", model.String("http.method", "GET"))
+			write(t, sw, 3, "other", model.Bool("error", true))
+			if tagIndex.Disabled {
+				assert.Equal(t, 0, countTagIndexKeys(t, store))
+			} else {
+				assert.Equal(t, 2, countTagIndexKeys(t, store))
+			}
+
+			assert.ElementsMatch(t, []model.TraceID{{Low: 1}, {Low: 2}}, findTraceIDs(t, rw, 10, map[string]string{"http.method": "GET"}))
+			// the tags not indexed are matched against the traces, only for the spans of the service
+			assert.Equal(t, []model.TraceID{{Low: 1}}, findTraceIDs(t, rw, 10, map[string]string{"error": "true"}))
+			assert.Equal(t, []model.TraceID{{Low: 1}}, findTraceIDs(t, rw, 10, map[string]string{"http.method": "GET", "error": "true"}))
+			// the limit applies to the traces matched
+			assert.Equal(t, []model.TraceID{{Low: 1}}, findTraceIDs(t, rw, 1, map[string]string{"error": "true"}))
+			assert.Empty(t, findTraceIDs(t, rw, 10, map[string]string{"error": "false"}))
+		})
+	}
+}
+
+func TestReadCanceled(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), TagIndex{})
+		rw := NewTraceReader(store, cache, TagIndex{})
+		span := createDummySpan()
+		require.NoError(t, sw.WriteSpan(context.Background(), &span))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := rw.GetTrace(ctx, span.TraceID)
+		require.ErrorIs(t, err, context.Canceled)
+		for _, query := range []*spanstore.TraceQueryParameters{
+			{ServiceName: "service"},
+			{ServiceName: "service", DurationMin: time.Millisecond},
+			{},
+		} {
+			query.StartTimeMin = time.Now().Add(-time.Hour)
+			query.StartTimeMax = time.Now().Add(time.Hour)
+			_, err = rw.FindTraceIDs(ctx, query)
+			require.ErrorIs(t, err, context.Canceled)
+		}
+		_, err = rw.FindTraceIDsByPrefix(ctx, &spanstore.TraceIDPrefixQueryParameters{
+			StartTimeMin: time.Now().Add(-time.Hour),
+			StartTimeMax: time.Now().Add(time.Hour),
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
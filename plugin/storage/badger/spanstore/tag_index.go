@@ -0,0 +1,72 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// TagIndex selects the tags of the spans written to the tag index. The traces queried by the tags
+// that are not indexed are found by the other indexes and then matched against these tags once read.
+// The tags added to the allow-list are only found in the tag index for the spans written afterwards.
+type TagIndex struct {
+	// Disabled is true if no tag is written to the tag index.
+	Disabled bool `mapstructure:"disabled"`
+	// AllowList holds the keys of the tags written to the tag index, all of them if it is empty.
+	AllowList []string `mapstructure:"allow_list"`
+}
+
+// indexed returns a function telling whether the tags of a key are written to the tag index.
+func (t TagIndex) indexed() func(key string) bool {
+	if t.Disabled {
+		return func(string) bool { return false }
+	}
+	if len(t.AllowList) == 0 {
+		return func(string) bool { return true }
+	}
+	allowed := make(map[string]struct{}, len(t.AllowList))
+	for _, key := range t.AllowList {
+		allowed[key] = struct{}{}
+	}
+	return func(key string) bool {
+		_, ok := allowed[key]
+		return ok
+	}
+}
+
+// hasTags returns true if the spans of the service have all the tags in their tags, their process
+// tags or their log fields, like the tag index.
+func hasTags(trace *model.Trace, service string, tags map[string]string) bool {
+	for k, v := range tags {
+		if !hasTag(trace, service, k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(trace *model.Trace, service, key, value string) bool {
+	matches := func(kvs []model.KeyValue) bool {
+		for _, kv := range kvs {
+			if kv.Key == key && kv.AsString() == value {
+				return true
+			}
+		}
+		return false
+	}
+	for _, span := range trace.Spans {
+		if span.Process.GetServiceName() != service {
+			continue
+		}
+		if matches(span.Tags) || matches(span.Process.GetTags()) {
+			return true
+		}
+		for _, log := range span.Logs {
+			if matches(log.Fields) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -52,15 +52,17 @@ type SpanWriter struct {
 	ttl          time.Duration
 	cache        *CacheStore
 	encodingType byte
+	indexedTag   func(key string) bool
 }
 
-// NewSpanWriter returns a SpawnWriter with cache
-func NewSpanWriter(db *badger.DB, c *CacheStore, ttl time.Duration) *SpanWriter {
+// NewSpanWriter returns a SpawnWriter with cache, writing the tags selected by tagIndex to the tag index
+func NewSpanWriter(db *badger.DB, c *CacheStore, ttl time.Duration, tagIndex TagIndex) *SpanWriter {
 	return &SpanWriter{
 		store:        db,
 		ttl:          ttl,
 		cache:        c,
 		encodingType: defaultEncoding, // TODO Make configurable
+		indexedTag:   tagIndex.indexed(),
 	}
 }
 
@@ -87,17 +89,26 @@ func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(durationIndexKey, durationValue, startTime, span.TraceID), nil, expireTime))
 
 	for _, kv := range span.Tags {
+		if !w.indexedTag(kv.Key) {
+			continue
+		}
 		// Convert everything to string since queries are done that way also
 		// KEY: it<serviceName><tagsKey><traceId> VALUE: <tagsValue>
 		entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID), nil, expireTime))
 	}
 
 	for _, kv := range span.Process.Tags {
+		if !w.indexedTag(kv.Key) {
+			continue
+		}
 		entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID), nil, expireTime))
 	}
 
 	for _, log := range span.Logs {
 		for _, kv := range log.Fields {
+			if !w.indexedTag(kv.Key) {
+				continue
+			}
 			entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID), nil, expireTime))
 		}
 	}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package warningstore
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
+)
+
+// warningsKeyPrefix is the prefix of the keys of the warnings, followed by the tenant, a zero byte and
+// the trace ID, so that the warnings of a trace written again replace the ones written before.
+const warningsKeyPrefix byte = 0x0a
+
+// WarningStore stores the warnings of the traces in Badger, expiring with the spans.
+type WarningStore struct {
+	store *badger.DB
+	ttl   time.Duration
+}
+
+// NewWarningStore creates a WarningStore whose warnings expire after ttl.
+func NewWarningStore(db *badger.DB, ttl time.Duration) *WarningStore {
+	return &WarningStore{
+		store: db,
+		ttl:   ttl,
+	}
+}
+
+// WriteTraceWarnings implements warningstore.Writer#WriteTraceWarnings.
+func (s *WarningStore) WriteTraceWarnings(ctx context.Context, warnings *warningstore.TraceWarnings) error {
+	value, err := json.Marshal(warnings)
+	if err != nil {
+		return err
+	}
+	key := append(tenantPrefix(ctx), warnings.TraceID.String()...)
+	return s.store.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry(key, value).WithTTL(s.ttl))
+	})
+}
+
+// FindTraceWarnings implements warningstore.Reader#FindTraceWarnings.
+func (s *WarningStore) FindTraceWarnings(ctx context.Context, query *warningstore.Query) ([]*warningstore.TraceWarnings, error) {
+	var found []*warningstore.TraceWarnings
+	prefix := tenantPrefix(ctx)
+	err := s.store.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var warnings warningstore.TraceWarnings
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &warnings)
+			}); err != nil {
+				return err
+			}
+			if query.Type != "" && !warnings.HasType(query.Type) {
+				continue
+			}
+			if !query.StartTimeMin.IsZero() && warnings.StartTime.Before(query.StartTimeMin) {
+				continue
+			}
+			if !query.StartTimeMax.IsZero() && warnings.StartTime.After(query.StartTimeMax) {
+				continue
+			}
+			found = append(found, &warnings)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].StartTime.After(found[j].StartTime)
+	})
+	if query.NumTraces > 0 && len(found) > query.NumTraces {
+		found = found[:query.NumTraces]
+	}
+	return found, nil
+}
+
+func tenantPrefix(ctx context.Context) []byte {
+	prefix := append([]byte{warningsKeyPrefix}, tenancy.GetTenant(ctx)...)
+	return append(prefix, 0)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package warningstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
+)
+
+func newTraceWarnings(traceID uint64, startTime time.Time, warningType string) *warningstore.TraceWarnings {
+	return &warningstore.TraceWarnings{
+		TraceID:   model.NewTraceID(0, traceID),
+		StartTime: startTime,
+		Services:  []string{"svc"},
+		Warnings:  []warningstore.SpanWarning{{SpanID: model.NewSpanID(1), Type: warningType, Message: "warning"}},
+	}
+}
+
+func TestWarningStoreFind(t *testing.T) {
+	runWithBadger(t, time.Hour, func(t *testing.T, ws *WarningStore) {
+		ctx := context.Background()
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		clockSkew := newTraceWarnings(1, now.Add(-time.Minute), "clock-skew")
+		missingSpan := newTraceWarnings(2, now, "missing-span")
+		old := newTraceWarnings(3, now.Add(-time.Hour), "clock-skew")
+		for _, warnings := range []*warningstore.TraceWarnings{clockSkew, missingSpan, old} {
+			require.NoError(t, ws.WriteTraceWarnings(ctx, warnings))
+		}
+
+		found, err := ws.FindTraceWarnings(ctx, &warningstore.Query{})
+		require.NoError(t, err)
+		assert.Equal(t, []*warningstore.TraceWarnings{missingSpan, clockSkew, old}, found)
+
+		found, err = ws.FindTraceWarnings(ctx, &warningstore.Query{Type: "clock-skew", StartTimeMin: now.Add(-2 * time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []*warningstore.TraceWarnings{clockSkew}, found)
+
+		found, err = ws.FindTraceWarnings(ctx, &warningstore.Query{StartTimeMax: now.Add(-time.Second), NumTraces: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []*warningstore.TraceWarnings{clockSkew}, found)
+
+		found, err = ws.FindTraceWarnings(tenancy.WithTenant(ctx, "acme"), &warningstore.Query{})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestWarningStoreWrite(t *testing.T) {
+	runWithBadger(t, time.Hour, func(t *testing.T, ws *WarningStore) {
+		ctx := tenancy.WithTenant(context.Background(), "acme")
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, ws.WriteTraceWarnings(ctx, newTraceWarnings(1, now, "clock-skew")))
+		// the warnings of the same trace are replaced
+		replaced := newTraceWarnings(1, now, "missing-span")
+		require.NoError(t, ws.WriteTraceWarnings(ctx, replaced))
+
+		found, err := ws.FindTraceWarnings(ctx, &warningstore.Query{})
+		require.NoError(t, err)
+		assert.Equal(t, []*warningstore.TraceWarnings{replaced}, found)
+
+		// the tenants whose name is a prefix of the others do not see their warnings
+		found, err = ws.FindTraceWarnings(tenancy.WithTenant(ctx, "acm"), &warningstore.Query{})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestWarningStoreExpiry(t *testing.T) {
+	runWithBadger(t, time.Second, func(t *testing.T, ws *WarningStore) {
+		ctx := context.Background()
+		require.NoError(t, ws.WriteTraceWarnings(ctx, newTraceWarnings(1, time.Now(), "clock-skew")))
+		// badger expires the entries at the granularity of the seconds
+		assert.Eventually(t, func() bool {
+			found, err := ws.FindTraceWarnings(ctx, &warningstore.Query{})
+			return err == nil && len(found) == 0
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+}
+
+func runWithBadger(t *testing.T, ttl time.Duration, test func(t *testing.T, ws *WarningStore)) {
+	opts := badger.DefaultOptions("")
+
+	opts.SyncWrites = false
+	dir := t.TempDir()
+	opts.Dir = dir
+	opts.ValueDir = dir
+	opts.Logger = nil
+
+	store, err := badger.Open(opts)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	test(t, NewWarningStore(store, ttl))
+}
+
+func TestMain(m *testing.M) {
+	testutils.VerifyGoLeaks(m)
+}
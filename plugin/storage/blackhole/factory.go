@@ -73,3 +73,8 @@ func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
 func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return f.store, nil
 }
+
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{ArchiveStorage: true}
+}
@@ -47,4 +47,5 @@ func TestStorageFactory(t *testing.T) {
 	depReader, err := f.CreateDependencyReader()
 	require.NoError(t, err)
 	assert.Equal(t, f.store, depReader)
+	assert.Equal(t, storage.Capabilities{ArchiveStorage: true}, f.Capabilities())
 }
@@ -19,11 +19,23 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/cassandra"
 )
 
+const (
+	depsTableV2 = "dependencies_v2"
+	depsTableV3 = "dependencies_v3"
+)
+
 // GetDependencyVersion attempts to determine the version of the dependencies table.
 // TODO: Remove this once we've migrated to V2 permanently. https://github.com/jaegertracing/jaeger/issues/1344
 func GetDependencyVersion(s cassandra.Session) Version {
-	if err := s.Query("SELECT ts from dependencies_v2 limit 1;").Exec(); err != nil {
-		return V1
+	if hasTable(s, depsTableV3) {
+		return V3
+	}
+	if hasTable(s, depsTableV2) {
+		return V2
 	}
-	return V2
+	return V1
+}
+
+func hasTable(s cassandra.Session, table string) bool {
+	return s.Query("SELECT ts from "+table+" limit 1;").Exec() == nil
 }
@@ -37,11 +37,24 @@ func TestGetDependencyVersionV1(t *testing.T) {
 }
 
 func TestGetDependencyVersionV2(t *testing.T) {
+	var (
+		session = &mocks.Session{}
+		queryV2 = &mocks.Query{}
+		queryV3 = &mocks.Query{}
+	)
+	session.On("Query", "SELECT ts from dependencies_v3 limit 1;", mock.Anything).Return(queryV3)
+	session.On("Query", "SELECT ts from dependencies_v2 limit 1;", mock.Anything).Return(queryV2)
+	queryV3.On("Exec").Return(errors.New("error"))
+	queryV2.On("Exec").Return(nil)
+	assert.Equal(t, V2, GetDependencyVersion(session))
+}
+
+func TestGetDependencyVersionV3(t *testing.T) {
 	var (
 		session = &mocks.Session{}
 		query   = &mocks.Query{}
 	)
 	session.On("Query", mock.AnythingOfType("string"), mock.Anything).Return(query)
 	query.On("Exec").Return(nil)
-	assert.Equal(t, V2, GetDependencyVersion(session))
+	assert.Equal(t, V3, GetDependencyVersion(session))
 }
@@ -17,8 +17,13 @@ package dependencystore
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gocql/gocql"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 // Dependency is the UDT representation of a Jaeger Dependency.
@@ -60,3 +65,127 @@ func (d *Dependency) UnmarshalUDT(name string, info gocql.TypeInfo, data []byte)
 		return fmt.Errorf("unknown column for position: %q", name)
 	}
 }
+
+// dependencyRow is a row of the dependencies_v3 table, a link with the latencies of its calls
+// if LatencyCount is not zero. The durations are in microseconds.
+type dependencyRow struct {
+	ts                  time.Time
+	Parent              string
+	Child               string
+	Source              string
+	CallCount           int64 // always unsigned, but we cannot explicitly read uint64 from Cassandra
+	LatencyCount        int64
+	LatencyP50          int64
+	LatencyP95          int64
+	LatencyP99          int64
+	LatencyBucketBounds []int64
+	LatencyBucketCounts []int64
+}
+
+func newLatencyRow(l *dependencystore.DependencyLatency) dependencyRow {
+	row := dependencyRow{
+		Parent:    l.Parent,
+		Child:     l.Child,
+		Source:    string(model.JaegerDependencyLinkSource),
+		CallCount: int64(l.CallCount),
+	}
+	if l.Latency != nil {
+		row.LatencyCount = l.Latency.Count
+		row.LatencyP50 = durationToMicros(l.Latency.P50)
+		row.LatencyP95 = durationToMicros(l.Latency.P95)
+		row.LatencyP99 = durationToMicros(l.Latency.P99)
+		row.LatencyBucketBounds = make([]int64, len(l.Latency.BucketBounds))
+		for i, bound := range l.Latency.BucketBounds {
+			row.LatencyBucketBounds[i] = durationToMicros(bound)
+		}
+		row.LatencyBucketCounts = l.Latency.BucketCounts
+	}
+	return row
+}
+
+type edge struct {
+	parent string
+	child  string
+}
+
+// mergeLatencies merges the latencies of the rows of each link, ordered by first occurrence.
+// The bucket counts are summed if the rows have the same bucket bounds, and dropped otherwise.
+func mergeLatencies(rows []dependencyRow) []dependencystore.DependencyLatency {
+	var latencies []dependencystore.DependencyLatency
+	// weighted sums of the percentiles of the latencies
+	var weightedPercentiles [][3]float64
+	indexes := make(map[edge]int)
+	for i := range rows {
+		row := &rows[i]
+		if row.LatencyCount == 0 {
+			continue
+		}
+		e := edge{parent: row.Parent, child: row.Child}
+		idx, ok := indexes[e]
+		if !ok {
+			idx = len(latencies)
+			indexes[e] = idx
+			latencies = append(latencies, dependencystore.DependencyLatency{
+				Parent:  row.Parent,
+				Child:   row.Child,
+				Latency: &spanstore.LatencyDistribution{BucketBounds: microsToDurations(row.LatencyBucketBounds)},
+			})
+			if len(row.LatencyBucketCounts) > 0 {
+				latencies[idx].Latency.BucketCounts = make([]int64, len(row.LatencyBucketCounts))
+			}
+			weightedPercentiles = append(weightedPercentiles, [3]float64{})
+		}
+		l := &latencies[idx]
+		l.CallCount += uint64(row.CallCount)
+		l.Latency.Count += row.LatencyCount
+		weight := float64(row.LatencyCount)
+		weightedPercentiles[idx][0] += weight * float64(row.LatencyP50)
+		weightedPercentiles[idx][1] += weight * float64(row.LatencyP95)
+		weightedPercentiles[idx][2] += weight * float64(row.LatencyP99)
+		if sameBounds(l.Latency.BucketBounds, row.LatencyBucketBounds) && len(l.Latency.BucketCounts) == len(row.LatencyBucketCounts) {
+			for j, count := range row.LatencyBucketCounts {
+				l.Latency.BucketCounts[j] += count
+			}
+		} else {
+			l.Latency.BucketBounds, l.Latency.BucketCounts = nil, nil
+		}
+	}
+	for i := range latencies {
+		count := float64(latencies[i].Latency.Count)
+		latencies[i].Latency.P50 = microsToDuration(weightedPercentiles[i][0] / count)
+		latencies[i].Latency.P95 = microsToDuration(weightedPercentiles[i][1] / count)
+		latencies[i].Latency.P99 = microsToDuration(weightedPercentiles[i][2] / count)
+	}
+	return latencies
+}
+
+func sameBounds(bounds []time.Duration, micros []int64) bool {
+	if len(bounds) != len(micros) {
+		return false
+	}
+	for i, bound := range bounds {
+		if durationToMicros(bound) != micros[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func durationToMicros(d time.Duration) int64 {
+	return int64(d / time.Microsecond)
+}
+
+func microsToDuration(micros float64) time.Duration {
+	return time.Duration(micros) * time.Microsecond
+}
+
+func microsToDurations(micros []int64) []time.Duration {
+	if len(micros) == 0 {
+		return nil
+	}
+	durations := make([]time.Duration, len(micros))
+	for i, m := range micros {
+		durations[i] = time.Duration(m) * time.Microsecond
+	}
+	return durations
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"time"
 
 	"go.uber.org/zap"
@@ -27,6 +28,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/cassandra"
 	casMetrics "github.com/jaegertracing/jaeger/pkg/cassandra/metrics"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 )
 
 // Version determines which version of the dependencies table to use.
@@ -43,15 +45,29 @@ const (
 
 	// V2 is used when the dependency table is NOT SASI indexed.
 	V2
+
+	// V3 is used when the dependency table stores a row per link in sharded hourly buckets.
+	V3
 	versionEnumEnd
 
 	depsInsertStmtV1 = "INSERT INTO dependencies(ts, ts_index, dependencies) VALUES (?, ?, ?)"
 	depsInsertStmtV2 = "INSERT INTO dependencies_v2(ts, ts_bucket, dependencies) VALUES (?, ?, ?)"
+	depsInsertStmtV3 = `INSERT INTO dependencies_v3(ts_bucket, shard, ts, parent, child, source, call_count,
+		latency_count, latency_p50, latency_p95, latency_p99, latency_bucket_bounds, latency_bucket_counts)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	depsSelectStmtV1 = "SELECT ts, dependencies FROM dependencies WHERE ts_index >= ? AND ts_index < ?"
 	depsSelectStmtV2 = "SELECT ts, dependencies FROM dependencies_v2 WHERE ts_bucket IN ? AND ts >= ? AND ts < ?"
+	depsSelectStmtV3 = `SELECT ts, parent, child, source, call_count,
+		latency_count, latency_p50, latency_p95, latency_p99, latency_bucket_bounds, latency_bucket_counts
+		FROM dependencies_v3 WHERE ts_bucket = ? AND shard IN ? AND ts >= ? AND ts < ?`
 
 	// TODO: Make this customizable.
 	tsBucket = 24 * time.Hour
+
+	// tsBucketV3 and shardsV3 split the links of a day in partitions small enough
+	// to be written frequently by large deployments.
+	tsBucketV3 = time.Hour
+	shardsV3   = 16
 )
 
 var errInvalidVersion = errors.New("invalid version")
@@ -62,6 +78,9 @@ type DependencyStore struct {
 	dependenciesTableMetrics *casMetrics.Table
 	logger                   *zap.Logger
 	version                  Version
+	// readV2 reads the links of the dependencies_v2 table for the time ranges without links
+	// in the dependencies_v3 table, while migrating online to V3.
+	readV2 bool
 }
 
 // NewDependencyStore returns a DependencyStore
@@ -79,11 +98,26 @@ func NewDependencyStore(
 		dependenciesTableMetrics: casMetrics.NewTable(metricsFactory, "dependencies"),
 		logger:                   logger,
 		version:                  version,
+		readV2:                   version == V3 && hasTable(session, depsTableV2),
 	}, nil
 }
 
 // WriteDependencies implements dependencystore.Writer#WriteDependencies.
 func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.DependencyLink) error {
+	if s.version == V3 {
+		for _, d := range dependencies {
+			row := dependencyRow{
+				Parent:    d.Parent,
+				Child:     d.Child,
+				CallCount: int64(d.CallCount),
+				Source:    string(d.ApplyDefaults().Source),
+			}
+			if err := s.writeRowV3(ts, &row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	deps := make([]Dependency, len(dependencies))
 	for i, d := range dependencies {
 		deps[i] = Dependency{
@@ -104,16 +138,60 @@ func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.D
 	return s.dependenciesTableMetrics.Exec(query, s.logger)
 }
 
+// WriteDependencyLatencies implements dependencystore.LatencyWriter#WriteDependencyLatencies,
+// the latencies are only stored by V3.
+func (s *DependencyStore) WriteDependencyLatencies(ts time.Time, latencies []dependencystore.DependencyLatency) error {
+	if s.version != V3 {
+		return dependencystore.ErrDependencyLatenciesNotSupported
+	}
+	for i := range latencies {
+		row := newLatencyRow(&latencies[i])
+		if err := s.writeRowV3(ts, &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DependencyStore) writeRowV3(ts time.Time, row *dependencyRow) error {
+	query := s.session.Query(depsInsertStmtV3, ts.Truncate(tsBucketV3), shard(row.Parent, row.Child), ts,
+		row.Parent, row.Child, row.Source, row.CallCount,
+		row.LatencyCount, row.LatencyP50, row.LatencyP95, row.LatencyP99, row.LatencyBucketBounds, row.LatencyBucketCounts)
+	return s.dependenciesTableMetrics.Exec(query, s.logger)
+}
+
 // GetDependencies returns all interservice dependencies
-func (s *DependencyStore) GetDependencies(_ context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
 	startTs := endTs.Add(-1 * lookback)
-	var query cassandra.Query
+	var (
+		mDependency []model.DependencyLink
+		err         error
+	)
 	switch s.version {
 	case V1:
-		query = s.session.Query(depsSelectStmtV1, startTs, endTs)
+		mDependency, err = readLinks(s.query(ctx, depsSelectStmtV1, startTs, endTs))
 	case V2:
-		query = s.session.Query(depsSelectStmtV2, getBuckets(startTs, endTs), startTs, endTs)
+		mDependency, err = readLinks(s.query(ctx, depsSelectStmtV2, getBuckets(startTs, endTs, tsBucket), startTs, endTs))
+	case V3:
+		mDependency, err = s.getDependenciesV3(ctx, startTs, endTs)
+	}
+	if err != nil {
+		s.logger.Error("Failure to read Dependencies", zap.Time("endTs", endTs), zap.Duration("lookback", lookback), zap.Error(err))
+		return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
 	}
+	return mDependency, nil
+}
+
+// query creates the query of a read, canceled with the context if it can be.
+func (s *DependencyStore) query(ctx context.Context, stmt string, values ...any) cassandra.Query {
+	query := s.session.Query(stmt, values...)
+	if ctx.Done() != nil {
+		query = query.WithContext(ctx)
+	}
+	return query
+}
+
+func readLinks(query cassandra.Query) ([]model.DependencyLink, error) {
 	iter := query.Consistency(cassandra.One).Iter()
 
 	var mDependency []model.DependencyLink
@@ -130,19 +208,102 @@ func (s *DependencyStore) GetDependencies(_ context.Context, endTs time.Time, lo
 			mDependency = append(mDependency, dl)
 		}
 	}
-
 	if err := iter.Close(); err != nil {
-		s.logger.Error("Failure to read Dependencies", zap.Time("endTs", endTs), zap.Duration("lookback", lookback), zap.Error(err))
-		return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
+		return nil, err
 	}
 	return mDependency, nil
 }
 
-func getBuckets(startTs time.Time, endTs time.Time) []time.Time {
+func (s *DependencyStore) getDependenciesV3(ctx context.Context, startTs, endTs time.Time) ([]model.DependencyLink, error) {
+	rows, earliest, err := s.readRowsV3(ctx, startTs, endTs)
+	if err != nil {
+		return nil, err
+	}
+	mDependency := make([]model.DependencyLink, 0, len(rows))
+	for _, row := range rows {
+		mDependency = append(mDependency, model.DependencyLink{
+			Parent:    row.Parent,
+			Child:     row.Child,
+			CallCount: uint64(row.CallCount),
+			Source:    row.Source,
+		}.ApplyDefaults())
+	}
+	if !s.readV2 {
+		return mDependency, nil
+	}
+	// the links of dependencies_v2 are only read before the first links of dependencies_v3,
+	// not to count the links twice while the dependencies jobs switch to the new table
+	v2EndTs := endTs
+	if len(rows) > 0 {
+		v2EndTs = earliest
+	}
+	if !startTs.Before(v2EndTs) {
+		return mDependency, nil
+	}
+	v2Dependencies, err := readLinks(s.query(ctx, depsSelectStmtV2, getBuckets(startTs, v2EndTs, tsBucket), startTs, v2EndTs))
+	if err != nil {
+		return nil, err
+	}
+	return append(mDependency, v2Dependencies...), nil
+}
+
+// readRowsV3 reads the rows of dependencies_v3 between startTs and endTs, along with the earliest of their timestamps.
+func (s *DependencyStore) readRowsV3(ctx context.Context, startTs, endTs time.Time) ([]dependencyRow, time.Time, error) {
+	shards := make([]int, shardsV3)
+	for i := range shards {
+		shards[i] = i
+	}
+	var (
+		rows     []dependencyRow
+		earliest time.Time
+	)
+	for _, bucket := range getBuckets(startTs, endTs, tsBucketV3) {
+		iter := s.query(ctx, depsSelectStmtV3, bucket, shards, startTs, endTs).Consistency(cassandra.One).Iter()
+		var row dependencyRow
+		for iter.Scan(&row.ts, &row.Parent, &row.Child, &row.Source, &row.CallCount,
+			&row.LatencyCount, &row.LatencyP50, &row.LatencyP95, &row.LatencyP99, &row.LatencyBucketBounds, &row.LatencyBucketCounts) {
+			if len(rows) == 0 || row.ts.Before(earliest) {
+				earliest = row.ts
+			}
+			rows = append(rows, row)
+			row = dependencyRow{}
+		}
+		if err := iter.Close(); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	return rows, earliest, nil
+}
+
+// GetDependencyLatencies implements dependencystore.LatencyReader#GetDependencyLatencies,
+// the latencies are only stored by V3. The latencies of a link stored at several timestamps
+// are merged, their percentiles being averaged by number of calls.
+func (s *DependencyStore) GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLatency, error) {
+	if s.version != V3 {
+		return nil, dependencystore.ErrDependencyLatenciesNotSupported
+	}
+	rows, _, err := s.readRowsV3(ctx, endTs.Add(-1*lookback), endTs)
+	if err != nil {
+		s.logger.Error("Failure to read Dependency latencies", zap.Time("endTs", endTs), zap.Duration("lookback", lookback), zap.Error(err))
+		return nil, fmt.Errorf("error reading dependency latencies from storage: %w", err)
+	}
+	return mergeLatencies(rows), nil
+}
+
+func getBuckets(startTs time.Time, endTs time.Time, bucket time.Duration) []time.Time {
 	// TODO: Preallocate the array using some maths and maybe use a pool? This endpoint probably isn't used enough to warrant this.
 	var tsBuckets []time.Time
-	for ts := startTs.Truncate(tsBucket); ts.Before(endTs); ts = ts.Add(tsBucket) {
+	for ts := startTs.Truncate(bucket); ts.Before(endTs); ts = ts.Add(bucket) {
 		tsBuckets = append(tsBuckets, ts)
 	}
 	return tsBuckets
 }
+
+// shard spreads the links of a bucket over its partitions.
+func shard(parent, child string) int {
+	h := fnv.New32a()
+	h.Write([]byte(parent))
+	h.Write([]byte{0})
+	h.Write([]byte(child))
+	return int(h.Sum32() % shardsV3)
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 type depStorageTest struct {
@@ -59,13 +60,83 @@ func withDepStore(version Version, fn func(s *depStorageTest)) {
 }
 
 var (
-	_ dependencystore.Reader = &DependencyStore{} // check API conformance
-	_ dependencystore.Writer = &DependencyStore{} // check API conformance
+	_ dependencystore.Reader        = &DependencyStore{} // check API conformance
+	_ dependencystore.Writer        = &DependencyStore{} // check API conformance
+	_ dependencystore.LatencyReader = &DependencyStore{} // check API conformance
+	_ dependencystore.LatencyWriter = &DependencyStore{} // check API conformance
 )
 
+// newV3DepStore creates a V3 DependencyStore, reading the links of dependencies_v2 if hasV2.
+func newV3DepStore(t *testing.T, hasV2 bool) (*DependencyStore, *mocks.Session, *testutils.Buffer) {
+	session := &mocks.Session{}
+	probe := &mocks.Query{}
+	if hasV2 {
+		probe.On("Exec").Return(nil)
+	} else {
+		probe.On("Exec").Return(errors.New("unconfigured table"))
+	}
+	session.On("Query", "SELECT ts from dependencies_v2 limit 1;", mock.Anything).Return(probe).Once()
+	logger, logBuffer := testutils.NewLogger()
+	store, err := NewDependencyStore(session, metrics.NullFactory, logger, V3)
+	require.NoError(t, err)
+	assert.Equal(t, hasV2, store.readV2)
+	return store, session, logBuffer
+}
+
+// scanRows returns an iterator scanning the rows of dependencies_v3.
+func scanRows(rows []dependencyRow, err error) *mocks.Iterator {
+	iter := &mocks.Iterator{}
+	iter.On("Scan", mock.MatchedBy(func(args []any) bool {
+		if len(rows) == 0 {
+			return false
+		}
+		row := rows[0]
+		rows = rows[1:]
+		*args[0].(*time.Time) = row.ts
+		*args[1].(*string) = row.Parent
+		*args[2].(*string) = row.Child
+		*args[3].(*string) = row.Source
+		*args[4].(*int64) = row.CallCount
+		*args[5].(*int64) = row.LatencyCount
+		*args[6].(*int64) = row.LatencyP50
+		*args[7].(*int64) = row.LatencyP95
+		*args[8].(*int64) = row.LatencyP99
+		*args[9].(*[]int64) = row.LatencyBucketBounds
+		*args[10].(*[]int64) = row.LatencyBucketCounts
+		return true
+	})).Return(true)
+	iter.On("Scan", matchEverything()).Return(false)
+	iter.On("Close").Return(err)
+	return iter
+}
+
+// scanLinks returns an iterator scanning the rows of dependencies_v2.
+func scanLinks(deps [][]Dependency) *mocks.Iterator {
+	iter := &mocks.Iterator{}
+	iter.On("Scan", mock.MatchedBy(func(args []any) bool {
+		if len(deps) == 0 {
+			return false
+		}
+		*args[1].(*[]Dependency) = deps[0]
+		deps = deps[1:]
+		return true
+	})).Return(true)
+	iter.On("Scan", matchEverything()).Return(false)
+	iter.On("Close").Return(nil)
+	return iter
+}
+
+func selectQuery(iter *mocks.Iterator) *mocks.Query {
+	query := &mocks.Query{}
+	query.On("Consistency", cassandra.One).Return(query)
+	query.On("Iter").Return(iter)
+	return query
+}
+
 func TestVersionIsValid(t *testing.T) {
 	assert.True(t, V1.IsValid())
 	assert.True(t, V2.IsValid())
+	assert.True(t, V3.IsValid())
 	assert.False(t, versionEnumEnd.IsValid())
 }
 
@@ -254,6 +325,19 @@ func TestDependencyStoreGetDependencies(t *testing.T) {
 	}
 }
 
+func TestDependencyStoreGetDependenciesWithContext(t *testing.T) {
+	store, session, _ := newV3DepStore(t, false)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	query := selectQuery(scanRows(nil, nil))
+	query.On("WithContext", ctx).Return(query)
+	session.On("Query", depsSelectStmtV3, mock.Anything).Return(query)
+
+	_, err := store.GetDependencies(ctx, time.Now(), time.Hour)
+	require.NoError(t, err)
+	query.AssertCalled(t, "WithContext", ctx)
+}
+
 func TestGetBuckets(t *testing.T) {
 	var (
 		start    = time.Date(2017, time.January, 24, 11, 15, 17, 12345, time.UTC)
@@ -264,7 +348,214 @@ func TestGetBuckets(t *testing.T) {
 			time.Date(2017, time.January, 26, 0, 0, 0, 0, time.UTC),
 		}
 	)
-	assert.Equal(t, expected, getBuckets(start, end))
+	assert.Equal(t, expected, getBuckets(start, end, tsBucket))
+}
+
+func TestDependencyStoreWriteV3(t *testing.T) {
+	store, session, _ := newV3DepStore(t, false)
+	var args [][]any
+	query := &mocks.Query{}
+	query.On("Exec").Return(nil)
+	session.On("Query", depsInsertStmtV3, mock.MatchedBy(func(v []any) bool {
+		args = append(args, v)
+		return true
+	})).Return(query)
+
+	ts := time.Date(2017, time.January, 24, 11, 15, 17, 12345, time.UTC)
+	bucket := time.Date(2017, time.January, 24, 11, 0, 0, 0, time.UTC)
+	err := store.WriteDependencies(ts, []model.DependencyLink{
+		{Parent: "a", Child: "b", CallCount: 42},
+		{Parent: "b", Child: "c", CallCount: 7, Source: "skywalking"},
+	})
+	require.NoError(t, err)
+	err = store.WriteDependencyLatencies(ts, []dependencystore.DependencyLatency{
+		{
+			Parent:    "a",
+			Child:     "b",
+			CallCount: 42,
+			Latency: &spanstore.LatencyDistribution{
+				Count:        40,
+				P50:          time.Millisecond,
+				P95:          2 * time.Millisecond,
+				P99:          3 * time.Millisecond,
+				BucketBounds: []time.Duration{time.Millisecond},
+				BucketCounts: []int64{20, 20},
+			},
+		},
+		{Parent: "b", Child: "c", CallCount: 7},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, args, 4)
+	assert.Equal(t, []any{bucket, shard("a", "b"), ts, "a", "b", "jaeger", int64(42), int64(0), int64(0), int64(0), int64(0), []int64(nil), []int64(nil)}, args[0])
+	assert.Equal(t, []any{bucket, shard("b", "c"), ts, "b", "c", "skywalking", int64(7), int64(0), int64(0), int64(0), int64(0), []int64(nil), []int64(nil)}, args[1])
+	assert.Equal(t, []any{bucket, shard("a", "b"), ts, "a", "b", "jaeger", int64(42), int64(40), int64(1000), int64(2000), int64(3000), []int64{1000}, []int64{20, 20}}, args[2])
+	assert.Equal(t, []any{bucket, shard("b", "c"), ts, "b", "c", "jaeger", int64(7), int64(0), int64(0), int64(0), int64(0), []int64(nil), []int64(nil)}, args[3])
+}
+
+func TestDependencyStoreWriteV3Failure(t *testing.T) {
+	store, session, _ := newV3DepStore(t, false)
+	query := &mocks.Query{}
+	query.On("Exec").Return(errors.New("write error"))
+	query.On("String").Return("INSERT")
+	session.On("Query", depsInsertStmtV3, matchEverything()).Return(query)
+
+	err := store.WriteDependencies(time.Now(), []model.DependencyLink{{Parent: "a", Child: "b", CallCount: 1}})
+	require.ErrorContains(t, err, "write error")
+	err = store.WriteDependencyLatencies(time.Now(), []dependencystore.DependencyLatency{{Parent: "a", Child: "b", CallCount: 1}})
+	require.ErrorContains(t, err, "write error")
+}
+
+func TestDependencyLatenciesNotSupported(t *testing.T) {
+	withDepStore(V2, func(s *depStorageTest) {
+		err := s.storage.WriteDependencyLatencies(time.Now(), nil)
+		require.ErrorIs(t, err, dependencystore.ErrDependencyLatenciesNotSupported)
+		_, err = s.storage.GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+		require.ErrorIs(t, err, dependencystore.ErrDependencyLatenciesNotSupported)
+	})
+}
+
+func TestDependencyStoreGetDependenciesV3(t *testing.T) {
+	endTs := time.Date(2017, time.January, 24, 11, 30, 0, 0, time.UTC)
+	earliest := endTs.Add(-20 * time.Minute)
+	rows := []dependencyRow{
+		{ts: endTs.Add(-10 * time.Minute), Parent: "a", Child: "b", Source: "jaeger", CallCount: 2},
+		{ts: earliest, Parent: "b", Child: "c", Source: "skywalking", CallCount: 3},
+	}
+	for _, tc := range []struct {
+		caption string
+		hasV2   bool
+		rows    []dependencyRow
+		v2End   time.Time
+	}{
+		{caption: "no links"},
+		{caption: "V3 links", rows: rows},
+		{caption: "V3 links before V2 links", hasV2: true, rows: rows, v2End: earliest},
+		{caption: "V2 links only", hasV2: true, v2End: endTs},
+	} {
+		t.Run(tc.caption, func(t *testing.T) {
+			store, session, _ := newV3DepStore(t, tc.hasV2)
+			var buckets []time.Time
+			session.On("Query", depsSelectStmtV3, mock.MatchedBy(func(v []any) bool {
+				buckets = append(buckets, v[0].(time.Time))
+				return true
+			})).Return(selectQuery(scanRows(tc.rows, nil)))
+			var v2Args []any
+			v2Iter := scanLinks([][]Dependency{{{Parent: "c", Child: "d", CallCount: 4}}})
+			session.On("Query", depsSelectStmtV2, mock.MatchedBy(func(v []any) bool {
+				v2Args = v
+				return true
+			})).Return(selectQuery(v2Iter))
+
+			deps, err := store.GetDependencies(context.Background(), endTs, 3*time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, []time.Time{
+				time.Date(2017, time.January, 24, 8, 0, 0, 0, time.UTC),
+				time.Date(2017, time.January, 24, 9, 0, 0, 0, time.UTC),
+				time.Date(2017, time.January, 24, 10, 0, 0, 0, time.UTC),
+				time.Date(2017, time.January, 24, 11, 0, 0, 0, time.UTC),
+			}, buckets)
+			var expected []model.DependencyLink
+			for _, row := range tc.rows {
+				expected = append(expected, model.DependencyLink{Parent: row.Parent, Child: row.Child, CallCount: uint64(row.CallCount), Source: row.Source})
+			}
+			if tc.hasV2 {
+				expected = append(expected, model.DependencyLink{Parent: "c", Child: "d", CallCount: 4, Source: "jaeger"})
+				require.Len(t, v2Args, 3)
+				assert.Equal(t, endTs.Add(-3*time.Hour), v2Args[1])
+				assert.Equal(t, tc.v2End, v2Args[2])
+			} else {
+				assert.Nil(t, v2Args)
+			}
+			if expected == nil {
+				assert.Empty(t, deps)
+			} else {
+				assert.Equal(t, expected, deps)
+			}
+		})
+	}
+}
+
+func TestDependencyStoreGetDependenciesV3Failures(t *testing.T) {
+	store, session, logBuffer := newV3DepStore(t, false)
+	session.On("Query", depsSelectStmtV3, matchEverything()).Return(selectQuery(scanRows(nil, errors.New("query error"))))
+	_, err := store.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.EqualError(t, err, "error reading dependencies from storage: query error")
+	_, err = store.GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+	require.EqualError(t, err, "error reading dependency latencies from storage: query error")
+	assert.Contains(t, logBuffer.String(), "Failure to read Dependency latencies")
+
+	store, session, _ = newV3DepStore(t, true)
+	session.On("Query", depsSelectStmtV3, matchEverything()).Return(selectQuery(scanRows(nil, nil)))
+	session.On("Query", depsSelectStmtV2, matchEverything()).Return(selectQuery(scanRows(nil, errors.New("v2 error"))))
+	_, err = store.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.EqualError(t, err, "error reading dependencies from storage: v2 error")
+}
+
+func TestDependencyStoreGetDependencyLatencies(t *testing.T) {
+	store, session, _ := newV3DepStore(t, false)
+	ts := time.Date(2017, time.January, 24, 11, 15, 0, 0, time.UTC)
+	session.On("Query", depsSelectStmtV3, matchEverything()).Return(selectQuery(scanRows([]dependencyRow{
+		{ts: ts, Parent: "a", Child: "b", Source: "jaeger", CallCount: 1},
+		{
+			ts: ts, Parent: "a", Child: "b", Source: "jaeger", CallCount: 10,
+			LatencyCount: 10, LatencyP50: 1000, LatencyP95: 2000, LatencyP99: 3000,
+			LatencyBucketBounds: []int64{1000}, LatencyBucketCounts: []int64{5, 5},
+		},
+		{
+			ts: ts, Parent: "b", Child: "c", Source: "jaeger", CallCount: 4,
+			LatencyCount: 4, LatencyP50: 500, LatencyP95: 500, LatencyP99: 500,
+		},
+		{
+			ts: ts.Add(-time.Minute), Parent: "a", Child: "b", Source: "jaeger", CallCount: 30,
+			LatencyCount: 30, LatencyP50: 5000, LatencyP95: 6000, LatencyP99: 7000,
+			LatencyBucketBounds: []int64{1000}, LatencyBucketCounts: []int64{0, 30},
+		},
+		{
+			ts: ts.Add(-2 * time.Minute), Parent: "b", Child: "c", Source: "jaeger", CallCount: 4,
+			LatencyCount: 4, LatencyP50: 1500, LatencyP95: 1500, LatencyP99: 1500,
+			LatencyBucketBounds: []int64{1000}, LatencyBucketCounts: []int64{0, 4},
+		},
+	}, nil)))
+
+	latencies, err := store.GetDependencyLatencies(context.Background(), ts.Add(time.Minute), 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []dependencystore.DependencyLatency{
+		{
+			Parent:    "a",
+			Child:     "b",
+			CallCount: 40,
+			Latency: &spanstore.LatencyDistribution{
+				Count:        40,
+				P50:          4000 * time.Microsecond,
+				P95:          5000 * time.Microsecond,
+				P99:          6000 * time.Microsecond,
+				BucketBounds: []time.Duration{time.Millisecond},
+				BucketCounts: []int64{5, 35},
+			},
+		},
+		{
+			Parent:    "b",
+			Child:     "c",
+			CallCount: 8,
+			Latency: &spanstore.LatencyDistribution{
+				Count: 8,
+				P50:   time.Millisecond,
+				P95:   time.Millisecond,
+				P99:   time.Millisecond,
+			},
+		},
+	}, latencies)
+}
+
+func TestShard(t *testing.T) {
+	assert.Equal(t, shard("a", "b"), shard("a", "b"))
+	assert.NotEqual(t, shard("ab", ""), shard("a", "b"))
+	for _, parent := range []string{"a", "b", "c", "frontend", "payment-api"} {
+		s := shard(parent, "child")
+		assert.GreaterOrEqual(t, s, 0)
+		assert.Less(t, s, shardsV3)
+	}
 }
 
 func matchEverything() any {
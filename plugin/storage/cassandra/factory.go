@@ -51,6 +51,7 @@ const (
 var ( // interface comformance checks
 	_ storage.Factory              = (*Factory)(nil)
 	_ storage.Purger               = (*Factory)(nil)
+	_ storage.HealthChecker        = (*Factory)(nil)
 	_ storage.ArchiveFactory       = (*Factory)(nil)
 	_ storage.SamplingStoreFactory = (*Factory)(nil)
 	_ io.Closer                    = (*Factory)(nil)
@@ -168,7 +169,7 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 
 // CreateSpanReader implements storage.Factory
 func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
-	return cSpanStore.NewSpanReader(f.primarySession, f.primaryMetricsFactory, f.logger, f.tracer.Tracer("cSpanStore.SpanReader")), nil
+	return cSpanStore.NewSpanReader(f.primarySession, f.primaryMetricsFactory, f.logger, f.tracer.Tracer("cSpanStore.SpanReader"), readerOptions(f.Options, f.Options.GetPrimary())...), nil
 }
 
 // CreateSpanWriter implements storage.Factory
@@ -186,12 +187,24 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return cDepStore.NewDependencyStore(f.primarySession, f.primaryMetricsFactory, f.logger, version)
 }
 
+// Capabilities implements storage.Factory
+func (f *Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{
+		ArchiveStorage:    f.archiveSession != nil,
+		TraceSearch:       true,
+		TagSearch:         true,
+		OperationSpanKind: true,
+		Dependencies:      true,
+		AdaptiveSampling:  true,
+	}
+}
+
 // CreateArchiveSpanReader implements storage.ArchiveFactory
 func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
 	if f.archiveSession == nil {
 		return nil, storage.ErrArchiveStorageNotConfigured
 	}
-	return cSpanStore.NewSpanReader(f.archiveSession, f.archiveMetricsFactory, f.logger, f.tracer.Tracer("cSpanStore.SpanReader")), nil
+	return cSpanStore.NewSpanReader(f.archiveSession, f.archiveMetricsFactory, f.logger, f.tracer.Tracer("cSpanStore.SpanReader"), readerOptions(f.Options, f.Options.Get(archiveStorageConfig))...), nil
 }
 
 // CreateArchiveSpanWriter implements storage.ArchiveFactory
@@ -222,6 +235,26 @@ func (f *Factory) CreateSamplingStore(int /* maxBuckets */) (samplingstore.Store
 	return cSamplingStore.New(f.primarySession, f.primaryMetricsFactory, f.logger), nil
 }
 
+func readerOptions(opts *Options, cfg *config.Configuration) []cSpanStore.ReaderOption {
+	var options []cSpanStore.ReaderOption
+	if cfg == nil {
+		return options
+	}
+	if tags := opts.IndexLowSelectivityTags(); len(tags) > 0 {
+		options = append(options, cSpanStore.LowSelectivityTags(tags...))
+	}
+	if opts.Index.AdaptiveSelection {
+		options = append(options, cSpanStore.AdaptiveIndexSelection())
+	}
+	if cfg.AllowTokenFromContext {
+		options = append(options, cSpanStore.PropagateBearerToken())
+	}
+	if cfg.ReadConcurrency > 0 {
+		options = append(options, cSpanStore.ReadConcurrency(cfg.ReadConcurrency))
+	}
+	return options
+}
+
 func writerOptions(opts *Options) ([]cSpanStore.Option, error) {
 	var tagFilters []dbmodel.TagFilter
 
@@ -273,3 +306,8 @@ func (f *Factory) Close() error {
 func (f *Factory) Purge(_ context.Context) error {
 	return f.primarySession.Query("TRUNCATE traces").Exec()
 }
+
+// CheckHealth implements storage.HealthChecker by querying the local node of the primary session.
+func (f *Factory) CheckHealth(_ context.Context) error {
+	return f.primarySession.Query("SELECT now() FROM system.local").Exec()
+}
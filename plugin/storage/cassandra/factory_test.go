@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/cassandra"
 	cassandraCfg "github.com/jaegertracing/jaeger/pkg/cassandra/config"
 	"github.com/jaegertracing/jaeger/pkg/cassandra/mocks"
@@ -75,6 +76,7 @@ func TestCassandraFactory(t *testing.T) {
 	f.archiveConfig = nil
 	require.NoError(t, f.Initialize(metrics.NullFactory, logger))
 	assert.Contains(t, logBuf.String(), "Cassandra archive storage configuration is empty, skipping")
+	assert.False(t, f.Capabilities().ArchiveStorage)
 
 	_, err := f.CreateSpanReader()
 	require.NoError(t, err)
@@ -93,6 +95,7 @@ func TestCassandraFactory(t *testing.T) {
 
 	f.archiveConfig = newMockSessionBuilder(session, nil)
 	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	assert.True(t, f.Capabilities().ArchiveStorage)
 
 	_, err = f.CreateArchiveSpanReader()
 	require.NoError(t, err)
@@ -191,6 +194,31 @@ func TestWriterOptions(t *testing.T) {
 	assert.Empty(t, options)
 }
 
+func TestReaderOptions(t *testing.T) {
+	opts := NewOptions("cassandra")
+	v, _ := config.Viperize(opts.AddFlags)
+	opts.InitFromViper(v)
+	assert.Equal(t, 10, opts.GetPrimary().ReadConcurrency)
+	assert.Len(t, readerOptions(opts, opts.GetPrimary()), 1)
+
+	v.Set(bearertoken.StoragePropagationKey, true)
+	opts.InitFromViper(v)
+	assert.True(t, opts.GetPrimary().AllowTokenFromContext)
+	assert.Len(t, readerOptions(opts, opts.GetPrimary()), 2)
+
+	v.Set("cassandra.read-concurrency", 0)
+	opts.InitFromViper(v)
+	assert.Len(t, readerOptions(opts, opts.GetPrimary()), 1)
+
+	v.Set("cassandra.index.low-selectivity-tags", "http.method, span.kind")
+	v.Set("cassandra.index.adaptive-selection", true)
+	opts.InitFromViper(v)
+	assert.Equal(t, []string{"http.method", "span.kind"}, opts.IndexLowSelectivityTags())
+	assert.Len(t, readerOptions(opts, opts.GetPrimary()), 3)
+
+	assert.Empty(t, readerOptions(opts, nil))
+}
+
 func TestConfigureFromOptions(t *testing.T) {
 	f := NewFactory()
 	o := NewOptions("foo", archiveStorageConfig)
@@ -265,3 +293,16 @@ func TestFactory_Purge(t *testing.T) {
 	session.AssertCalled(t, "Query", mock.AnythingOfType("string"), mock.Anything)
 	query.AssertCalled(t, "Exec")
 }
+
+func TestFactory_CheckHealth(t *testing.T) {
+	f := NewFactory()
+	var (
+		session = &mocks.Session{}
+		query   = &mocks.Query{}
+	)
+	session.On("Query", "SELECT now() FROM system.local", mock.Anything).Return(query)
+	query.On("Exec").Return(errors.New("no hosts available"))
+	f.primarySession = session
+
+	require.EqualError(t, f.CheckHealth(context.Background()), "no hosts available")
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/cassandra/config"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 )
@@ -46,6 +47,7 @@ const (
 	suffixUsername           = ".username"
 	suffixPassword           = ".password"
 	suffixAuth               = ".basic.allowed-authenticators"
+	suffixReadConcurrency    = ".read-concurrency"
 	// common storage settings
 	suffixSpanStoreWriteCacheTTL = ".span-store-write-cache-ttl"
 	suffixIndexTagsBlacklist     = ".index.tag-blacklist"
@@ -53,6 +55,8 @@ const (
 	suffixIndexLogs              = ".index.logs"
 	suffixIndexTags              = ".index.tags"
 	suffixIndexProcessTags       = ".index.process-tags"
+	suffixIndexLowSelectivity    = ".index.low-selectivity-tags"
+	suffixIndexAdaptive          = ".index.adaptive-selection"
 )
 
 // Options contains various type of Cassandra configs and provides the ability
@@ -73,6 +77,9 @@ type IndexConfig struct {
 	ProcessTags  bool   `mapstructure:"process_tags"`
 	TagBlackList string `mapstructure:"tag_blacklist"`
 	TagWhiteList string `mapstructure:"tag_whitelist"`
+	// LowSelectivityTags and AdaptiveSelection configure the indexes the reader searches.
+	LowSelectivityTags string `mapstructure:"low_selectivity_tags"`
+	AdaptiveSelection  bool   `mapstructure:"adaptive_selection"`
 }
 
 // the Servers field in config.Configuration is a list, which we cannot represent with flags.
@@ -133,6 +140,14 @@ func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 		opt.Primary.namespace+suffixIndexProcessTags,
 		!opt.Index.ProcessTags,
 		"Controls process tag indexing. Set to false to disable.")
+	flagSet.String(
+		opt.Primary.namespace+suffixIndexLowSelectivity,
+		opt.Index.LowSelectivityTags,
+		"The comma-separated list of span tags shared by too many spans to search the traces by their tag index. The traces are searched by the other conditions of the queries, then filtered by these tags.")
+	flagSet.Bool(
+		opt.Primary.namespace+suffixIndexAdaptive,
+		opt.Index.AdaptiveSelection,
+		"Learns the selectivity of the indexes from the searches of the traces, to search the most selective tags first and to skip the indexes matching too many traces when another index is searched.")
 }
 
 func addFlags(flagSet *flag.FlagSet, nsConfig NamespaceConfig) {
@@ -212,6 +227,10 @@ func addFlags(flagSet *flag.FlagSet, nsConfig NamespaceConfig) {
 			"If none are specified, there is a default 'approved' list that is used "+
 			"(https://github.com/gocql/gocql/blob/34fdeebefcbf183ed7f916f931aa0586fdaa1b40/conn.go#L27). "+
 			"If a non-empty list is provided, only specified authenticators are allowed.")
+	flagSet.Int(
+		nsConfig.namespace+suffixReadConcurrency,
+		nsConfig.ReadConcurrency,
+		"The number of traces found by a query that are read from Cassandra at the same time")
 }
 
 // InitFromViper initializes Options with properties from viper
@@ -226,6 +245,8 @@ func (opt *Options) InitFromViper(v *viper.Viper) {
 	opt.Index.Tags = v.GetBool(opt.Primary.namespace + suffixIndexTags)
 	opt.Index.Logs = v.GetBool(opt.Primary.namespace + suffixIndexLogs)
 	opt.Index.ProcessTags = v.GetBool(opt.Primary.namespace + suffixIndexProcessTags)
+	opt.Index.LowSelectivityTags = stripWhiteSpace(v.GetString(opt.Primary.namespace + suffixIndexLowSelectivity))
+	opt.Index.AdaptiveSelection = v.GetBool(opt.Primary.namespace + suffixIndexAdaptive)
 }
 
 func tlsFlagsConfig(namespace string) tlscfg.ClientFlagsConfig {
@@ -257,6 +278,8 @@ func (cfg *NamespaceConfig) initFromViper(v *viper.Viper) {
 	authentication := stripWhiteSpace(v.GetString(cfg.namespace + suffixAuth))
 	cfg.Authenticator.Basic.AllowedAuthenticators = strings.Split(authentication, ",")
 	cfg.DisableCompression = v.GetBool(cfg.namespace + suffixDisableCompression)
+	cfg.ReadConcurrency = v.GetInt(cfg.namespace + suffixReadConcurrency)
+	cfg.AllowTokenFromContext = v.GetBool(bearertoken.StoragePropagationKey)
 	var err error
 	cfg.TLS, err = tlsFlagsConfig.InitFromViper(v)
 	if err != nil {
@@ -305,6 +328,15 @@ func (opt *Options) TagIndexWhitelist() []string {
 	return nil
 }
 
+// IndexLowSelectivityTags returns the list of the tags of low selectivity
+func (opt *Options) IndexLowSelectivityTags() []string {
+	if len(opt.Index.LowSelectivityTags) > 0 {
+		return strings.Split(opt.Index.LowSelectivityTags, ",")
+	}
+
+	return nil
+}
+
 // stripWhiteSpace removes all whitespace characters from a string
 func stripWhiteSpace(str string) string {
 	return strings.ReplaceAll(str, " ", "")
@@ -43,6 +43,7 @@ func TestOptions(t *testing.T) {
 	assert.Equal(t, primary.Servers, aux.Servers)
 	assert.Equal(t, primary.ConnectionsPerHost, aux.ConnectionsPerHost)
 	assert.Equal(t, primary.ReconnectInterval, aux.ReconnectInterval)
+	assert.Equal(t, primary.ReadConcurrency, aux.ReadConcurrency)
 }
 
 func TestOptionsWithFlags(t *testing.T) {
@@ -60,10 +61,13 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--cas.consistency=ONE",
 		"--cas.proto-version=3",
 		"--cas.socket-keep-alive=42s",
+		"--cas.read-concurrency=42",
 		"--cas.index.tag-blacklist=blerg, blarg,blorg ",
 		"--cas.index.tag-whitelist=flerg, flarg,florg ",
 		"--cas.index.tags=true",
 		"--cas.index.process-tags=false",
+		"--cas.index.low-selectivity-tags=http.method, span.kind",
+		"--cas.index.adaptive-selection=true",
 		"--cas.basic.allowed-authenticators=org.apache.cassandra.auth.PasswordAuthenticator,com.datastax.bdp.cassandra.auth.DseAuthenticator",
 		"--cas.username=username",
 		"--cas.password=password",
@@ -83,11 +87,14 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, primary.Servers)
 	assert.Equal(t, []string{"org.apache.cassandra.auth.PasswordAuthenticator", "com.datastax.bdp.cassandra.auth.DseAuthenticator"}, primary.Authenticator.Basic.AllowedAuthenticators)
 	assert.Equal(t, "ONE", primary.Consistency)
+	assert.Equal(t, 42, primary.ReadConcurrency)
 	assert.Equal(t, []string{"blerg", "blarg", "blorg"}, opts.TagIndexBlacklist())
 	assert.Equal(t, []string{"flerg", "flarg", "florg"}, opts.TagIndexWhitelist())
 	assert.True(t, opts.Index.Tags)
 	assert.False(t, opts.Index.ProcessTags)
 	assert.True(t, opts.Index.Logs)
+	assert.Equal(t, []string{"http.method", "span.kind"}, opts.IndexLowSelectivityTags())
+	assert.True(t, opts.Index.AdaptiveSelection)
 
 	aux := opts.Get("cas-aux")
 	require.NotNil(t, aux)
@@ -102,6 +109,7 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, "", aux.Consistency, "aux storage does not inherit consistency from primary")
 	assert.Equal(t, 3, aux.ProtoVersion)
 	assert.Equal(t, 42*time.Second, aux.SocketKeepAlive)
+	assert.Equal(t, 42, aux.ReadConcurrency)
 }
 
 func TestDefaultTlsHostVerify(t *testing.T) {
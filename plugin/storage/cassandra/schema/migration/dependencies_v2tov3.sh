@@ -0,0 +1,126 @@
+#!/usr/bin/env bash
+
+# Create the dependencies_v3 table next to the dependencies_v2 table, without downtime.
+# Once the table exists, Jaeger reads the dependency links from both tables, the dependencies_v2
+# table only for the time ranges without links in the dependencies_v3 table, so that the
+# dependencies jobs can be switched to the new table at any time. The dependencies_v2 table
+# can be dropped once its data expired, or once its data is copied with COPY_DATA=true.
+# Sample usage: KEYSPACE=jaeger_v1 CQL_CMD='cqlsh host 9042 -u test_user -p test_password --request-timeout=3000' bash
+# ./dependencies_v2tov3.sh
+
+set -euo pipefail
+
+function usage {
+    >&2 echo "Error: $1"
+    >&2 echo ""
+    >&2 echo "Usage: KEYSPACE={keyspace} CQL_CMD={cql_cmd} $0"
+    >&2 echo ""
+    >&2 echo "The following parameters can be set via environment:"
+    >&2 echo "  KEYSPACE           - keyspace"
+    >&2 echo "  CQL_CMD            - cqlsh host port -u user -p password"
+    >&2 echo "  SHARDS             - number of shards of the buckets, must match Jaeger (default: 16)"
+    >&2 echo "  COPY_DATA          - copy the data of the dependencies_v2 table, true or false (default: false)"
+    >&2 echo ""
+    exit 1
+}
+
+confirm() {
+    read -r -p "${1:-Continue? [y/N]} " response
+    case "$response" in
+        [yY][eE][sS]|[yY])
+            true
+            ;;
+        *)
+            exit 1
+            ;;
+    esac
+}
+
+if [[ ${KEYSPACE} == "" ]]; then
+   usage "missing KEYSPACE parameter"
+fi
+
+if [[ ${KEYSPACE} =~ [^a-zA-Z0-9_] ]]; then
+    usage "invalid characters in KEYSPACE=$KEYSPACE parameter, please use letters, digits or underscores"
+fi
+
+keyspace=${KEYSPACE}
+old_table=dependencies_v2
+new_table=dependencies_v3
+shards=${SHARDS:-16}
+copy_data=${COPY_DATA:-false}
+cqlsh_cmd=${CQL_CMD:-cqlsh}
+
+echo "Using cql command: $cqlsh_cmd"
+
+ttl=$(${cqlsh_cmd} -e "select default_time_to_live from system_schema.tables WHERE keyspace_name='$keyspace' AND table_name='$old_table';"|head -4|tail -1|tr -d ' ')
+
+echo "Creating new table $new_table with ttl: $ttl"
+
+${cqlsh_cmd} -e "CREATE TABLE IF NOT EXISTS $keyspace.$new_table (
+    ts_bucket             timestamp,
+    shard                 int,
+    ts                    timestamp,
+    parent                text,
+    child                 text,
+    source                text,
+    call_count            bigint,
+    latency_count         bigint,
+    latency_p50           bigint,
+    latency_p95           bigint,
+    latency_p99           bigint,
+    latency_bucket_bounds frozen<list<bigint>>,
+    latency_bucket_counts frozen<list<bigint>>,
+    PRIMARY KEY ((ts_bucket, shard), ts, parent, child, source)
+) WITH CLUSTERING ORDER BY (ts DESC, parent ASC, child ASC, source ASC)
+    AND compaction = {
+        'min_threshold': '4',
+        'max_threshold': '32',
+        'class': 'org.apache.cassandra.db.compaction.SizeTieredCompactionStrategy'
+    }
+    AND default_time_to_live = $ttl;"
+
+if [[ ${copy_data} != "true" ]]; then
+    echo "Table $keyspace.$new_table is created, the links of $keyspace.$old_table are still read until it is dropped."
+    exit 0
+fi
+
+row_count=$(${cqlsh_cmd} -e "select count(*) from $keyspace.$old_table;"|head -4|tail -1| tr -d ' ')
+
+echo "About to copy the links of $row_count rows to new table..."
+
+confirm
+
+${cqlsh_cmd} -e "COPY $keyspace.$old_table (ts, dependencies) to '$old_table.csv' WITH DELIMITER='|';"
+
+if [[ ! -f ${old_table}.csv ]]; then
+    echo "Could not find $old_table.csv. Backup from cassandra was probably not successful"
+    exit 1
+fi
+
+echo "Generating data for new table..."
+# the links are exported as [{parent: 'a', child: 'b', call_count: 1, source: 'jaeger'}, ...],
+# each one is written in the hourly bucket of its timestamp, in the shard of its parent and child
+while IFS="|" read ts dependencies; do
+    bucket=$(date -u +"%Y-%m-%d %H:00:00+0000" -d "$ts")
+    echo "$dependencies" | grep -o "{[^}]*}" | while read -r link; do
+        parent=$(echo "$link" | sed -n "s/.*parent: '\([^']*\)'.*/\1/p")
+        child=$(echo "$link" | sed -n "s/.*child: '\([^']*\)'.*/\1/p")
+        call_count=$(echo "$link" | sed -n "s/.*call_count: \([0-9]*\).*/\1/p")
+        source=$(echo "$link" | sed -n "s/.*source: '\([^']*\)'.*/\1/p")
+        shard=$(( $(printf '%s\n%s' "$parent" "$child" | cksum | cut -f 1 -d ' ') % shards ))
+        echo "$bucket|$shard|$ts|$parent|$child|${source:-jaeger}|$call_count"
+    done
+done < ${old_table}.csv > ${new_table}.csv
+
+echo "Import data to new table: $keyspace.$new_table from $new_table.csv"
+
+${cqlsh_cmd} -e "COPY $keyspace.$new_table (ts_bucket, shard, ts, parent, child, source, call_count)
+    FROM '$new_table.csv'
+    WITH DELIMITER='|';"
+
+echo "Data from old table are successfully imported to new table!"
+
+echo "Before finish, do you want to delete old table: $keyspace.$old_table?"
+confirm
+${cqlsh_cmd} -e "DROP TABLE IF EXISTS $keyspace.$old_table;"
@@ -67,6 +67,9 @@ func (c converter) fromDomain(span *model.Span) *Span {
 	logs := c.toDBLogs(span.Logs)
 	refs := c.toDBRefs(span.References)
 	udtProcess := c.toDBProcess(span.Process)
+	// The hash is part of the PRIMARY KEY (trace_id, span_id, span_hash) of the traces table, see
+	// schema/v004.cql.tmpl. A span failing to be hashed gets a zero hash, so it is still written and
+	// its redeliveries still overwrite the same row, without the need to report the error.
 	spanHash, _ := model.HashCode(span)
 
 	tags = append(tags, warnings...)
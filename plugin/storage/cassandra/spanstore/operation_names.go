@@ -153,7 +153,11 @@ func (s *OperationNamesStorage) Write(operation dbmodel.Operation) error {
 func (s *OperationNamesStorage) GetOperations(
 	query spanstore.OperationQueryParameters,
 ) ([]spanstore.Operation, error) {
-	return s.table.getOperations(s, query)
+	operations, err := s.table.getOperations(s, query)
+	if err != nil {
+		return nil, err
+	}
+	return spanstore.PageOperations(operations, query), nil
 }
 
 func tableExist(session cassandra.Session, tableName string) bool {
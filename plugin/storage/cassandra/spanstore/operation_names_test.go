@@ -154,6 +154,7 @@ func TestOperationNamesStorageGetServices(t *testing.T) {
 	for _, test := range []struct {
 		name          string
 		schemaVersion schemaVersion
+		namePrefix    string
 		expErr        error
 		expRes        []spanstore.Operation
 	}{
@@ -167,6 +168,18 @@ func TestOperationNamesStorageGetServices(t *testing.T) {
 			schemaVersion: latestVersion,
 			expRes:        []spanstore.Operation{{SpanKind: "foo", Name: "bar"}},
 		},
+		{
+			name:          "test new schema with matching name prefix",
+			schemaVersion: latestVersion,
+			namePrefix:    "b",
+			expRes:        []spanstore.Operation{{SpanKind: "foo", Name: "bar"}},
+		},
+		{
+			name:          "test new schema with other name prefix",
+			schemaVersion: latestVersion,
+			namePrefix:    "foo",
+			expRes:        []spanstore.Operation{},
+		},
 		{name: "test old schema with scan error", schemaVersion: previousVersion, expErr: scanError},
 		{name: "test new schema with scan error", schemaVersion: latestVersion, expErr: scanError},
 	} {
@@ -203,6 +216,7 @@ func TestOperationNamesStorageGetServices(t *testing.T) {
 				s.session.On("Query", mock.AnythingOfType("string"), mock.Anything).Return(query)
 				services, err := s.storage.GetOperations(spanstore.OperationQueryParameters{
 					ServiceName: "service-a",
+					NamePrefix:  test.namePrefix,
 				})
 				if test.expErr == nil {
 					require.NoError(t, err)
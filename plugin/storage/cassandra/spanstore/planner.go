@@ -0,0 +1,344 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/spanstore/dbmodel"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// selectivityDecay is the weight of a search in the moving average of the saturation of an index.
+	selectivityDecay = 0.2
+	// minSelectivitySearches is the number of searches of an index before its selectivity is known.
+	minSelectivitySearches = 10
+	// lowSelectivitySaturation is the saturation above which an index is not selective enough to be searched.
+	lowSelectivitySaturation = 0.9
+	// selectivityProbeInterval is the number of skips of a low selectivity index between its searches,
+	// which keep its selectivity up to date.
+	selectivityProbeInterval = 20
+	// maxSelectivityTags is the number of tag keys whose selectivity is learned.
+	maxSelectivityTags = 1000
+)
+
+// indexPlanMetrics counts the indexes the trace IDs are searched by.
+type indexPlanMetrics struct {
+	Duration                metrics.Counter `metric:"index_plans" tags:"plan=duration"`
+	Service                 metrics.Counter `metric:"index_plans" tags:"plan=service"`
+	ServiceOperation        metrics.Counter `metric:"index_plans" tags:"plan=service_operation"`
+	Tags                    metrics.Counter `metric:"index_plans" tags:"plan=tags"`
+	ServiceOperationAndTags metrics.Counter `metric:"index_plans" tags:"plan=service_operation_and_tags"`
+	SkippedIndexes          metrics.Counter `metric:"skipped_indexes"`
+}
+
+// indexStats is the selectivity learned from the searches of an index.
+type indexStats struct {
+	// saturation is the moving average of the searches returning as many traces as the queries ask for.
+	saturation float64
+	searches   int
+	skips      int
+}
+
+// selectivity tells the indexes matching too many spans to be searched, which are the tag indexes
+// of the configured low selectivity tags and, when adaptive, the indexes whose searches return as
+// many traces as the queries ask for most of the time.
+type selectivity struct {
+	lowSelectivityTags map[string]struct{}
+	adaptive           bool
+
+	mu        sync.Mutex
+	operation indexStats
+	tags      map[string]*indexStats
+}
+
+func newSelectivity() *selectivity {
+	return &selectivity{
+		lowSelectivityTags: make(map[string]struct{}),
+		tags:               make(map[string]*indexStats),
+	}
+}
+
+// tagStats returns the stats of the tag index of the key, nil if they are not learned, creating
+// them if asked to unless too many tags are learned.
+func (s *selectivity) tagStats(key string, create bool) *indexStats {
+	stats, ok := s.tags[key]
+	if !ok && create && len(s.tags) < maxSelectivityTags {
+		stats = &indexStats{}
+		s.tags[key] = stats
+	}
+	return stats
+}
+
+// configuredLowTag returns whether the key is one of the configured low selectivity tags.
+func (s *selectivity) configuredLowTag(key string) bool {
+	_, ok := s.lowSelectivityTags[key]
+	return ok
+}
+
+// learnedLowTag returns whether the tag index of the key is learned to be of low selectivity.
+func (s *selectivity) learnedLowTag(key string) bool {
+	if !s.adaptive {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tagStats(key, false).skip()
+}
+
+// learnedLowOperation returns whether the service+operation index is learned to be of low selectivity.
+func (s *selectivity) learnedLowOperation() bool {
+	if !s.adaptive {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.operation.skip()
+}
+
+// tagSaturation returns the saturation of the tag index of the key, 0 if it is not learned.
+func (s *selectivity) tagSaturation(key string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats := s.tagStats(key, false); stats != nil {
+		return stats.saturation
+	}
+	return 0
+}
+
+func (s *selectivity) recordTag(key string, saturated bool) {
+	if !s.adaptive {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats := s.tagStats(key, true); stats != nil {
+		stats.record(saturated)
+	}
+}
+
+func (s *selectivity) recordOperation(saturated bool) {
+	if !s.adaptive {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operation.record(saturated)
+}
+
+// skip returns whether the index is of low selectivity, except for the searches probing it.
+func (st *indexStats) skip() bool {
+	if st == nil || st.searches < minSelectivitySearches || st.saturation < lowSelectivitySaturation {
+		return false
+	}
+	st.skips++
+	return st.skips%selectivityProbeInterval != 0
+}
+
+func (st *indexStats) record(saturated bool) {
+	var value float64
+	if saturated {
+		value = 1
+	}
+	if st.searches == 0 {
+		st.saturation = value
+	} else {
+		st.saturation += selectivityDecay * (value - st.saturation)
+	}
+	st.searches++
+}
+
+// tagCondition is a tag or a log field of a query, searched in the tag index.
+type tagCondition struct {
+	key      string
+	value    string
+	logField bool
+}
+
+// indexPlan is the way the trace IDs of a query are searched.
+type indexPlan struct {
+	duration bool
+	// operation is whether the service+operation index is searched.
+	operation bool
+	// tags are searched in the tag index one after the other, in the order of their selectivity.
+	tags []tagCondition
+	// filterOperation and filterTags are the conditions of the indexes not searched, which
+	// the traces are filtered by once read.
+	filterOperation bool
+	filterTags      map[string]string
+	// skipped is the number of indexes not searched.
+	skipped int
+}
+
+// planIndexes chooses the indexes the trace IDs of the query are searched by. The tag indexes of the
+// configured low selectivity tags are not searched, the service index being searched if no other index
+// is. The indexes learned to be of low selectivity are not searched as long as another index is.
+func (s *SpanReader) planIndexes(tq *spanstore.TraceQueryParameters) indexPlan {
+	plan := s.choosePlan(tq)
+	s.metrics.plans.SkippedIndexes.Inc(int64(plan.skipped))
+	switch {
+	case plan.duration:
+		s.metrics.plans.Duration.Inc(1)
+	case plan.operation && len(plan.tags) > 0:
+		s.metrics.plans.ServiceOperationAndTags.Inc(1)
+	case plan.operation:
+		s.metrics.plans.ServiceOperation.Inc(1)
+	case len(plan.tags) > 0:
+		s.metrics.plans.Tags.Inc(1)
+	default:
+		s.metrics.plans.Service.Inc(1)
+	}
+	return plan
+}
+
+func (s *SpanReader) choosePlan(tq *spanstore.TraceQueryParameters) indexPlan {
+	if tq.DurationMin != 0 || tq.DurationMax != 0 {
+		return indexPlan{duration: true}
+	}
+	var searched, learned, skipped []tagCondition
+	classify := func(tag tagCondition) {
+		switch {
+		case s.selectivity.configuredLowTag(tag.key):
+			skipped = append(skipped, tag)
+		case s.selectivity.learnedLowTag(tag.key):
+			learned = append(learned, tag)
+		default:
+			searched = append(searched, tag)
+		}
+	}
+	for k, v := range tq.Tags {
+		classify(tagCondition{key: k, value: v})
+	}
+	for k, v := range tq.LogFields {
+		classify(tagCondition{key: k, value: v, logField: true})
+	}
+	skipOperation := tq.OperationName != "" && s.selectivity.learnedLowOperation()
+	if len(searched) == 0 {
+		if tq.OperationName != "" {
+			skipOperation = false
+		} else {
+			searched, learned = learned, nil
+		}
+	}
+
+	plan := indexPlan{
+		operation:       tq.OperationName != "" && !skipOperation,
+		filterOperation: skipOperation,
+		tags:            searched,
+	}
+	if skipOperation {
+		plan.skipped++
+	}
+	// the traces are filtered by the log fields once read whatever the plan, see matches
+	for _, tag := range append(skipped, learned...) {
+		plan.skipped++
+		if tag.logField {
+			continue
+		}
+		if plan.filterTags == nil {
+			plan.filterTags = make(map[string]string)
+		}
+		plan.filterTags[tag.key] = tag.value
+	}
+	sort.Slice(plan.tags, func(i, j int) bool {
+		return plan.tags[i].key < plan.tags[j].key
+	})
+	saturation := make(map[string]float64, len(plan.tags))
+	for _, tag := range plan.tags {
+		saturation[tag.key] = s.selectivity.tagSaturation(tag.key)
+	}
+	sort.SliceStable(plan.tags, func(i, j int) bool {
+		return saturation[plan.tags[i].key] < saturation[plan.tags[j].key]
+	})
+	return plan
+}
+
+// findTraceIDsByPlan searches the trace IDs of the query by the indexes of the plan.
+func (s *SpanReader) findTraceIDsByPlan(ctx context.Context, plan indexPlan, tq *spanstore.TraceQueryParameters) ([]dbmodel.TraceID, error) {
+	if plan.duration {
+		return s.queryByDuration(ctx, tq)
+	}
+	if !plan.operation && len(plan.tags) == 0 {
+		return s.queryByService(ctx, tq)
+	}
+	var results [][]dbmodel.TraceID
+	if plan.operation {
+		traceIDs, err := s.queryByServiceNameAndOperation(ctx, tq)
+		if err != nil {
+			return nil, err
+		}
+		s.selectivity.recordOperation(len(traceIDs) >= tq.NumTraces)
+		if len(traceIDs) == 0 {
+			return nil, nil
+		}
+		results = append(results, traceIDs)
+	}
+	if len(plan.tags) > 0 {
+		traceIDs, err := s.queryByTagsAndLogs(ctx, tq, plan.tags)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, traceIDs)
+	}
+	return intersectTraceIDs(results), nil
+}
+
+// matches returns whether the trace matches the conditions of the query that the trace IDs are
+// not searched by.
+func (p indexPlan) matches(trace *model.Trace, tq *spanstore.TraceQueryParameters) bool {
+	// the tag index only tells that the log fields exist in a trace, not that they belong to the same log
+	if len(tq.LogFields) > 0 && !spanstore.TraceHasLogWithFields(trace, tq.LogFields) {
+		return false
+	}
+	if p.filterOperation && !hasOperation(trace, tq.ServiceName, tq.OperationName) {
+		return false
+	}
+	for k, v := range p.filterTags {
+		if !hasTag(trace, tq.ServiceName, k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasOperation(trace *model.Trace, service, operation string) bool {
+	for _, span := range trace.Spans {
+		if span.Process.GetServiceName() == service && span.OperationName == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag returns true if a span of the service has the tag in its tags, its process tags
+// or its log fields, like the tag index.
+func hasTag(trace *model.Trace, service, key, value string) bool {
+	matches := func(kvs []model.KeyValue) bool {
+		for _, kv := range kvs {
+			if kv.Key == key && kv.VType != model.BinaryType && kv.AsString() == value {
+				return true
+			}
+		}
+		return false
+	}
+	for _, span := range trace.Spans {
+		if span.Process.GetServiceName() != service {
+			continue
+		}
+		if matches(span.Tags) || matches(span.Process.GetTags()) {
+			return true
+		}
+		for _, log := range span.Logs {
+			if matches(log.Fields) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/cassandra"
+	"github.com/jaegertracing/jaeger/pkg/cassandra/mocks"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/spanstore/dbmodel"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func TestPlanIndexes(t *testing.T) {
+	testCases := []struct {
+		caption  string
+		query    spanstore.TraceQueryParameters
+		expected indexPlan
+		metric   string
+	}{
+		{
+			caption:  "service",
+			query:    spanstore.TraceQueryParameters{ServiceName: "svc"},
+			expected: indexPlan{},
+			metric:   "service",
+		},
+		{
+			caption:  "duration",
+			query:    spanstore.TraceQueryParameters{ServiceName: "svc", OperationName: "op", DurationMin: time.Second},
+			expected: indexPlan{duration: true},
+			metric:   "duration",
+		},
+		{
+			caption:  "operation",
+			query:    spanstore.TraceQueryParameters{ServiceName: "svc", OperationName: "op"},
+			expected: indexPlan{operation: true},
+			metric:   "service_operation",
+		},
+		{
+			caption: "tags without the low selectivity tags",
+			query: spanstore.TraceQueryParameters{
+				ServiceName: "svc",
+				Tags:        map[string]string{"http.method": "GET", "error": "true", "user": "x"},
+				LogFields:   map[string]string{"http.method": "POST"},
+			},
+			expected: indexPlan{
+				tags:       []tagCondition{{key: "error", value: "true"}, {key: "user", value: "x"}},
+				filterTags: map[string]string{"http.method": "GET"},
+				skipped:    2,
+			},
+			metric: "tags",
+		},
+		{
+			caption: "operation and tags",
+			query: spanstore.TraceQueryParameters{
+				ServiceName:   "svc",
+				OperationName: "op",
+				Tags:          map[string]string{"error": "true"},
+				LogFields:     map[string]string{"event": "exception"},
+			},
+			expected: indexPlan{
+				operation: true,
+				tags:      []tagCondition{{key: "error", value: "true"}, {key: "event", value: "exception", logField: true}},
+			},
+			metric: "service_operation_and_tags",
+		},
+		{
+			caption: "service without the low selectivity tags",
+			query: spanstore.TraceQueryParameters{
+				ServiceName: "svc",
+				Tags:        map[string]string{"http.method": "GET"},
+			},
+			expected: indexPlan{filterTags: map[string]string{"http.method": "GET"}, skipped: 1},
+			metric:   "service",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caption, func(t *testing.T) {
+			withSpanReader(t, func(r *spanReaderTest) {
+				LowSelectivityTags("http.method")(r.reader)
+				plan := r.reader.planIndexes(&tc.query)
+				assert.Equal(t, tc.expected, plan)
+				r.metricsFactory.AssertCounterMetrics(t,
+					metricstest.ExpectedMetric{Name: "read.index_plans", Tags: map[string]string{"plan": tc.metric}, Value: 1},
+					metricstest.ExpectedMetric{Name: "read.skipped_indexes", Value: tc.expected.skipped},
+				)
+			})
+		})
+	}
+}
+
+func TestAdaptiveIndexSelection(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		AdaptiveIndexSelection()(r.reader)
+		s := r.reader.selectivity
+		for i := 0; i < minSelectivitySearches; i++ {
+			s.recordTag("http.method", true)
+			s.recordTag("user", false)
+			s.recordOperation(true)
+		}
+		s.recordTag("error", true)
+
+		query := &spanstore.TraceQueryParameters{
+			ServiceName:   "svc",
+			OperationName: "op",
+			Tags:          map[string]string{"http.method": "GET", "error": "true", "user": "x"},
+		}
+		assert.Equal(t, indexPlan{
+			// the tags of unknown selectivity are searched, after the more selective ones
+			tags:            []tagCondition{{key: "user", value: "x"}, {key: "error", value: "true"}},
+			filterOperation: true,
+			filterTags:      map[string]string{"http.method": "GET"},
+			skipped:         2,
+		}, r.reader.planIndexes(query))
+
+		// the indexes of low selectivity are searched as no other index is
+		assert.Equal(t, indexPlan{operation: true}, r.reader.planIndexes(&spanstore.TraceQueryParameters{
+			ServiceName:   "svc",
+			OperationName: "op",
+		}))
+		assert.Equal(t, indexPlan{tags: []tagCondition{{key: "http.method", value: "GET"}}}, r.reader.planIndexes(&spanstore.TraceQueryParameters{
+			ServiceName: "svc",
+			Tags:        map[string]string{"http.method": "GET"},
+		}))
+
+		// the selectivity is learned again as the index is probed
+		probed := false
+		for i := 0; i < selectivityProbeInterval; i++ {
+			if !s.learnedLowTag("http.method") {
+				probed = true
+			}
+		}
+		assert.True(t, probed)
+		for i := 0; i < 3; i++ {
+			s.recordTag("http.method", false)
+		}
+		assert.False(t, s.learnedLowTag("http.method"))
+	})
+}
+
+func TestSelectivityNotAdaptive(t *testing.T) {
+	s := newSelectivity()
+	for i := 0; i < minSelectivitySearches; i++ {
+		s.recordTag("http.method", true)
+		s.recordOperation(true)
+	}
+	assert.False(t, s.learnedLowTag("http.method"))
+	assert.False(t, s.learnedLowOperation())
+	assert.Empty(t, s.tags)
+}
+
+func TestSelectivityMaxTags(t *testing.T) {
+	s := newSelectivity()
+	s.adaptive = true
+	for i := 0; i < maxSelectivityTags+1; i++ {
+		s.recordTag(string(rune('a'+i)), true)
+	}
+	assert.Len(t, s.tags, maxSelectivityTags)
+}
+
+func TestIndexPlanMatches(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				OperationName: "op",
+				Process:       model.NewProcess("svc", []model.KeyValue{model.String("host", "a")}),
+				Tags:          []model.KeyValue{model.String("http.method", "GET"), model.Binary("payload", []byte("x"))},
+				Logs:          []model.Log{{Fields: []model.KeyValue{model.String("event", "exception")}}},
+			},
+			{
+				OperationName: "other-op",
+				Process:       model.NewProcess("other", nil),
+				Tags:          []model.KeyValue{model.String("user", "x")},
+			},
+		},
+	}
+	query := &spanstore.TraceQueryParameters{ServiceName: "svc", OperationName: "op"}
+	testCases := []struct {
+		plan    indexPlan
+		matches bool
+	}{
+		{plan: indexPlan{}, matches: true},
+		{plan: indexPlan{filterOperation: true}, matches: true},
+		{plan: indexPlan{filterTags: map[string]string{"http.method": "GET", "host": "a", "event": "exception"}}, matches: true},
+		{plan: indexPlan{filterTags: map[string]string{"http.method": "POST"}}, matches: false},
+		{plan: indexPlan{filterTags: map[string]string{"payload": "x"}}, matches: false},
+		// the tags of the spans of other services are left out, like in the tag index
+		{plan: indexPlan{filterTags: map[string]string{"user": "x"}}, matches: false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.matches, tc.plan.matches(trace, query), "%+v", tc.plan)
+	}
+	query.OperationName = "other-op"
+	assert.False(t, indexPlan{filterOperation: true}.matches(trace, query))
+	query.LogFields = map[string]string{"event": "error"}
+	assert.False(t, indexPlan{}.matches(trace, query))
+}
+
+func TestSpanReaderFindTracesWithLowSelectivityTags(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		LowSelectivityTags("http.method")(r.reader)
+		traceIDs := []dbmodel.TraceID{
+			dbmodel.TraceIDFromDomain(model.NewTraceID(0, 1)),
+			dbmodel.TraceIDFromDomain(model.NewTraceID(0, 2)),
+		}
+		iter := &mocks.Iterator{}
+		for _, traceID := range traceIDs {
+			traceID := traceID
+			iter.On("Scan", matchOnceWithSideEffect(func(args []any) {
+				*args[0].(*dbmodel.TraceID) = traceID
+			})).Return(true)
+		}
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(nil)
+		tagQuery := &mocks.Query{}
+		tagQuery.On("PageSize", 0).Return(tagQuery)
+		tagQuery.On("Iter").Return(iter)
+		r.session.On("Query", queryByTag, mock.MatchedBy(func(values []any) bool {
+			return values[1] == "error" && values[2] == "true"
+		})).Return(tagQuery).Once()
+
+		// only the first trace has the low selectivity tag
+		r.session.On("Query", querySpanByTraceID, matchEverything()).Return(func(_ string, values ...any) cassandra.Query {
+			traceID := values[0].(dbmodel.TraceID)
+			iter := &mocks.Iterator{}
+			iter.On("Scan", matchOnceWithSideEffect(func(args []any) {
+				*args[0].(*dbmodel.TraceID) = traceID
+				*args[10].(*dbmodel.Process) = dbmodel.Process{ServiceName: "svc"}
+				if traceID == traceIDs[0] {
+					*args[7].(*[]dbmodel.KeyValue) = []dbmodel.KeyValue{{Key: "http.method", ValueType: "string", ValueString: "GET"}}
+				}
+			})).Return(true)
+			iter.On("Scan", matchEverything()).Return(false)
+			iter.On("Close").Return(nil)
+			query := &mocks.Query{}
+			query.On("Iter").Return(iter)
+			return query
+		})
+
+		traces, err := r.reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  "svc",
+			Tags:         map[string]string{"http.method": "GET", "error": "true"},
+			StartTimeMax: time.Now(),
+			StartTimeMin: time.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Len(t, traces, 1)
+		assert.Equal(t, traceIDs[0].ToDomain(), traces[0].Spans[0].TraceID)
+		r.session.AssertNumberOfCalls(t, "Query", 4)
+	})
+}
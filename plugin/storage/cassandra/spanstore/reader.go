@@ -19,6 +19,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -28,6 +30,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/cassandra"
 	casMetrics "github.com/jaegertracing/jaeger/pkg/cassandra/metrics"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
@@ -66,6 +69,11 @@ const (
 		LIMIT ?`
 
 	defaultNumTraces = 100
+	// maxTraceOffset is the maximum offset of the pages of trace IDs. Unlike the storages ordering the
+	// traces by their most recent span, the index tables do not return the trace IDs in an order that a
+	// page could resume after, so a page is a window into the trace IDs read up to its end, bounded
+	// like the result window of Elasticsearch.
+	maxTraceOffset = 10_000
 	// limitMultiple exists because many spans that are returned from indices can have the same trace, limitMultiple increases
 	// the number of responses from the index, so we can respect the user's limit value they provided.
 	limitMultiple = 3
@@ -96,12 +104,11 @@ type serviceNamesReader func() ([]string, error)
 type operationNamesReader func(query spanstore.OperationQueryParameters) ([]spanstore.Operation, error)
 
 type spanReaderMetrics struct {
-	readTraces                 *casMetrics.Table
-	queryTrace                 *casMetrics.Table
 	queryTagIndex              *casMetrics.Table
 	queryDurationIndex         *casMetrics.Table
 	queryServiceOperationIndex *casMetrics.Table
 	queryServiceNameIndex      *casMetrics.Table
+	plans                      indexPlanMetrics
 }
 
 // SpanReader can query for and load traces from Cassandra.
@@ -112,6 +119,53 @@ type SpanReader struct {
 	metrics              spanReaderMetrics
 	logger               *zap.Logger
 	tracer               trace.Tracer
+	propagateToken       bool
+	readConcurrency      int
+	selectivity          *selectivity
+}
+
+// ReaderOption is a function that sets some option on the reader.
+type ReaderOption func(r *SpanReader)
+
+// PropagateBearerToken can be provided to attach the bearer token of the context of the trace
+// queries, if any, to the custom payload of their Cassandra requests under the
+// bearertoken.PropagatedTokenKey entry, for custom query handlers of the Cassandra nodes
+// to authorize the requests per user.
+func PropagateBearerToken() ReaderOption {
+	return func(r *SpanReader) {
+		r.propagateToken = true
+	}
+}
+
+// ReadConcurrency sets the number of the traces found by FindTraces that are read at the same
+// time, which must be positive. The traces are read one at a time by default.
+func ReadConcurrency(n int) ReaderOption {
+	return func(r *SpanReader) {
+		r.readConcurrency = n
+	}
+}
+
+// LowSelectivityTags can be provided with the keys of the tags shared by too many spans for their
+// tag index to be searched, like the HTTP method, because the searches are slow and return as many
+// traces as the queries ask for without the other conditions. The trace IDs are searched by the other
+// conditions of the queries, or by service, and FindTraces filters the traces by the tags once read,
+// returning fewer traces than the queries ask for when the traces found do not have the tags.
+func LowSelectivityTags(keys ...string) ReaderOption {
+	return func(r *SpanReader) {
+		for _, key := range keys {
+			r.selectivity.lowSelectivityTags[key] = struct{}{}
+		}
+	}
+}
+
+// AdaptiveIndexSelection can be provided to learn the selectivity of the service+operation index and of
+// the tag indexes of the tag keys from their searches, searching the most selective tags first and not
+// searching the indexes returning as many traces as the queries ask for most of the time as long as
+// another index is searched, like the LowSelectivityTags.
+func AdaptiveIndexSelection() ReaderOption {
+	return func(r *SpanReader) {
+		r.selectivity.adaptive = true
+	}
 }
 
 // NewSpanReader returns a new SpanReader.
@@ -120,25 +174,45 @@ func NewSpanReader(
 	metricsFactory metrics.Factory,
 	logger *zap.Logger,
 	tracer trace.Tracer,
+	options ...ReaderOption,
 ) *SpanReader {
 	readFactory := metricsFactory.Namespace(metrics.NSOptions{Name: "read", Tags: nil})
 	serviceNamesStorage := NewServiceNamesStorage(session, 0, metricsFactory, logger)
 	operationNamesStorage := NewOperationNamesStorage(session, 0, metricsFactory, logger)
-	return &SpanReader{
+	reader := &SpanReader{
 		session:              session,
 		serviceNamesReader:   serviceNamesStorage.GetServices,
 		operationNamesReader: operationNamesStorage.GetOperations,
 		metrics: spanReaderMetrics{
-			readTraces:                 casMetrics.NewTable(readFactory, "read_traces"),
-			queryTrace:                 casMetrics.NewTable(readFactory, "query_traces"),
 			queryTagIndex:              casMetrics.NewTable(readFactory, "tag_index"),
 			queryDurationIndex:         casMetrics.NewTable(readFactory, "duration_index"),
 			queryServiceOperationIndex: casMetrics.NewTable(readFactory, "service_operation_index"),
 			queryServiceNameIndex:      casMetrics.NewTable(readFactory, "service_name_index"),
 		},
-		logger: logger,
-		tracer: tracer,
+		logger:          logger,
+		tracer:          tracer,
+		readConcurrency: 1,
+		selectivity:     newSelectivity(),
+	}
+	metrics.MustInit(&reader.metrics.plans, readFactory, nil)
+	for _, option := range options {
+		option(reader)
 	}
+	return reader
+}
+
+func (s *SpanReader) query(ctx context.Context, stmt string, values ...any) cassandra.Query {
+	query := s.session.Query(stmt, values...)
+	if ctx.Done() != nil {
+		query = query.WithContext(ctx)
+	}
+	if !s.propagateToken {
+		return query
+	}
+	if token, ok := bearertoken.GetBearerToken(ctx); ok {
+		query = query.CustomPayload(map[string][]byte{bearertoken.PropagatedTokenKey: []byte(token)})
+	}
+	return query
 }
 
 // GetServices returns all services traced by Jaeger
@@ -164,9 +238,8 @@ func (s *SpanReader) readTrace(ctx context.Context, traceID dbmodel.TraceID) (*m
 	return trace, err
 }
 
-func (s *SpanReader) readTraceInSpan(_ context.Context, traceID dbmodel.TraceID) (*model.Trace, error) {
-	start := time.Now()
-	q := s.session.Query(querySpanByTraceID, traceID)
+func (s *SpanReader) readTraceInSpan(ctx context.Context, traceID dbmodel.TraceID) (*model.Trace, error) {
+	q := s.query(ctx, querySpanByTraceID, traceID)
 	i := q.Iter()
 	var traceIDFromSpan dbmodel.TraceID
 	var startTime, spanID, duration, parentID int64
@@ -194,14 +267,12 @@ func (s *SpanReader) readTraceInSpan(_ context.Context, traceID dbmodel.TraceID)
 		}
 		span, err := dbmodel.ToDomain(&dbSpan)
 		if err != nil {
-			s.metrics.readTraces.Emit(err, time.Since(start))
 			return nil, err
 		}
 		retMe.Spans = append(retMe.Spans, span)
 	}
 
 	err := i.Close()
-	s.metrics.readTraces.Emit(err, time.Since(start))
 	if err != nil {
 		return nil, fmt.Errorf("error reading traces from storage: %w", err)
 	}
@@ -220,7 +291,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
 		return ErrMalformedRequestObject
 	}
-	if p.ServiceName == "" && len(p.Tags) > 0 {
+	if p.ServiceName == "" && (len(p.Tags) > 0 || len(p.LogFields) > 0 || len(p.ResourceAttributes) > 0) {
 		return ErrServiceNameNotSet
 	}
 	if p.StartTimeMin.IsZero() || p.StartTimeMax.IsZero() {
@@ -232,7 +303,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p.DurationMin != 0 && p.DurationMax != 0 && p.DurationMin > p.DurationMax {
 		return ErrDurationMinGreaterThanMax
 	}
-	if (p.DurationMin != 0 || p.DurationMax != 0) && len(p.Tags) > 0 {
+	if (p.DurationMin != 0 || p.DurationMax != 0) && (len(p.Tags) > 0 || len(p.LogFields) > 0) {
 		return ErrDurationAndTagQueryNotSupported
 	}
 	return nil
@@ -240,109 +311,186 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 
 // FindTraces retrieves traces that match the traceQuery
 func (s *SpanReader) FindTraces(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
-	uniqueTraceIDs, err := s.FindTraceIDs(ctx, traceQuery)
+	page, err := s.FindTracesPage(ctx, traceQuery)
 	if err != nil {
 		return nil, err
 	}
+	return page.Traces, nil
+}
+
+// FindTracesPage implements spanstore.PaginatedReader#FindTracesPage
+func (s *SpanReader) FindTracesPage(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	uniqueTraceIDs, plan, nextPageToken, err := s.findTraceIDsAndPlan(ctx, traceQuery)
+	if err != nil {
+		return nil, err
+	}
+	traces := s.loadTraces(ctx, uniqueTraceIDs, func(trace *model.Trace) bool {
+		return plan.matches(trace, traceQuery)
+	})
 	var retMe []*model.Trace
-	for _, traceID := range uniqueTraceIDs {
-		jTrace, err := s.GetTrace(ctx, traceID)
-		if err != nil {
-			s.logger.Error("Failure to read trace", zap.String("trace_id", traceID.String()), zap.Error(err))
-			continue
+	for _, jTrace := range traces {
+		if jTrace != nil {
+			retMe = append(retMe, jTrace)
 		}
-		retMe = append(retMe, jTrace)
 	}
-	return retMe, nil
+	return &spanstore.TracesPage{Traces: retMe, NextPageToken: nextPageToken}, nil
+}
+
+// loadTraces reads the traces of the IDs, up to readConcurrency at a time, in the order of the IDs,
+// the traces failing to load or not matching being left nil. When the context has a
+// deadline, each trace is given an even share of the time left for the rounds of reads left, so that
+// a slow trace cannot use up the time of the others, and the traces not read by then are left out.
+func (s *SpanReader) loadTraces(ctx context.Context, traceIDs []model.TraceID, matches func(*model.Trace) bool) []*model.Trace {
+	traces := make([]*model.Trace, len(traceIDs))
+	var next, skipped atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.readConcurrency, len(traceIDs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(traceIDs) {
+					return
+				}
+				if ctx.Err() != nil {
+					skipped.Add(1)
+					continue
+				}
+				traces[i] = s.loadTrace(ctx, traceIDs[i], len(traceIDs)-i, matches)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := skipped.Load(); n > 0 {
+		s.logger.Warn("Traces left out of the results of the query", zap.Int64("count", n), zap.Error(ctx.Err()))
+	}
+	return traces
+}
+
+// loadTrace reads a trace, pending being the number of traces left to read, itself included.
+func (s *SpanReader) loadTrace(ctx context.Context, traceID model.TraceID, pending int, matches func(*model.Trace) bool) *model.Trace {
+	if deadline, ok := ctx.Deadline(); ok {
+		rounds := (pending + s.readConcurrency - 1) / s.readConcurrency
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Until(deadline)/time.Duration(rounds))
+		defer cancel()
+	}
+	jTrace, err := s.GetTrace(ctx, traceID)
+	if err != nil {
+		s.logger.Error("Failure to read trace", zap.String("trace_id", traceID.String()), zap.Error(err))
+		return nil
+	}
+	if matches != nil && !matches(jTrace) {
+		return nil
+	}
+	return jTrace
 }
 
-// FindTraceIDs retrieve traceIDs that match the traceQuery
+// FindTraceIDs retrieve traceIDs that match the traceQuery. Log fields are only
+// required to exist in the trace, FindTraces also checks that they belong to the same log.
+// The conditions whose index is not searched, see LowSelectivityTags, are only checked by FindTraces.
 func (s *SpanReader) FindTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	traceIDs, _, _, err := s.findTraceIDsAndPlan(ctx, traceQuery)
+	return traceIDs, err
+}
+
+// findTraceIDsAndPlan returns the page of the trace IDs matching the traceQuery, the plan of the
+// index searches and the token of the next page.
+func (s *SpanReader) findTraceIDsAndPlan(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]model.TraceID, indexPlan, string, error) {
 	if err := validateQuery(traceQuery); err != nil {
-		return nil, err
+		return nil, indexPlan{}, "", err
 	}
+	// the resource attributes are indexed as the other process tags
+	spanstore.ResourceAttributesAsTags(traceQuery)
 	if traceQuery.NumTraces == 0 {
 		traceQuery.NumTraces = defaultNumTraces
 	}
+	offset, err := decodeTracePageToken(traceQuery.PageToken)
+	if err != nil {
+		return nil, indexPlan{}, "", err
+	}
 
-	dbTraceIDs, err := s.findTraceIDs(ctx, traceQuery)
+	// Index queries are limited by NumTraces and return trace IDs in the order of the
+	// index tables, so the trace IDs up to the end of the requested page are read, plus
+	// one to tell whether there is a next page.
+	pageQuery := *traceQuery
+	pageQuery.NumTraces = offset + traceQuery.NumTraces + 1
+	plan := s.planIndexes(&pageQuery)
+	dbTraceIDs, err := s.findTraceIDsByPlan(ctx, plan, &pageQuery)
 	if err != nil {
-		return nil, err
+		return nil, indexPlan{}, "", err
 	}
+	start, end, nextPageToken := spanstore.Paginate(len(dbTraceIDs), offset, traceQuery.NumTraces)
 
 	var traceIDs []model.TraceID
-	for t := range dbTraceIDs {
-		if len(traceIDs) >= traceQuery.NumTraces {
-			break
-		}
+	for _, t := range dbTraceIDs[start:end] {
 		traceIDs = append(traceIDs, t.ToDomain())
 	}
-	return traceIDs, nil
+	return traceIDs, plan, nextPageToken, nil
 }
 
-func (s *SpanReader) findTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
-	if traceQuery.DurationMin != 0 || traceQuery.DurationMax != 0 {
-		return s.queryByDuration(ctx, traceQuery)
-	}
-
-	if traceQuery.OperationName != "" {
-		traceIds, err := s.queryByServiceNameAndOperation(ctx, traceQuery)
-		if err != nil {
-			return nil, err
-		}
-		if len(traceQuery.Tags) > 0 {
-			tagTraceIds, err := s.queryByTagsAndLogs(ctx, traceQuery)
-			if err != nil {
-				return nil, err
-			}
-			return dbmodel.IntersectTraceIDs([]dbmodel.UniqueTraceIDs{
-				traceIds,
-				tagTraceIds,
-			}), nil
-		}
-		return traceIds, nil
+// decodeTracePageToken returns the offset encoded in the continuation token of a page of
+// trace IDs, rejecting the offsets above maxTraceOffset with an ErrInvalidPageToken.
+func decodeTracePageToken(token string) (int, error) {
+	offset, err := spanstore.DecodePageToken(token)
+	if err != nil {
+		return 0, err
 	}
-	if len(traceQuery.Tags) > 0 {
-		return s.queryByTagsAndLogs(ctx, traceQuery)
+	if offset > maxTraceOffset {
+		return 0, fmt.Errorf("%w: the offset %d exceeds the maximum of %d traces", spanstore.ErrInvalidPageToken, offset, maxTraceOffset)
 	}
-	return s.queryByService(ctx, traceQuery)
+	return offset, nil
 }
 
-func (s *SpanReader) queryByTagsAndLogs(ctx context.Context, tq *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
+// queryByTagsAndLogs searches the tags in the order of the plan, log fields being stored in
+// the tag index together with span and process tags.
+func (s *SpanReader) queryByTagsAndLogs(ctx context.Context, tq *spanstore.TraceQueryParameters, tags []tagCondition) ([]dbmodel.TraceID, error) {
 	ctx, span := s.startSpanForQuery(ctx, "queryByTagsAndLogs", queryByTag)
 	defer span.End()
 
-	results := make([]dbmodel.UniqueTraceIDs, 0, len(tq.Tags))
-	for k, v := range tq.Tags {
-		_, childSpan := s.tracer.Start(ctx, "queryByTag")
-		childSpan.SetAttributes(
-			attribute.Key("tag.key").String(k),
-			attribute.Key("tag.value").String(v),
-		)
-		query := s.session.Query(
-			queryByTag,
-			tq.ServiceName,
-			k,
-			v,
-			model.TimeAsEpochMicroseconds(tq.StartTimeMin),
-			model.TimeAsEpochMicroseconds(tq.StartTimeMax),
-			tq.NumTraces*limitMultiple,
-		).PageSize(0)
-		t, err := s.executeQuery(childSpan, query, s.metrics.queryTagIndex)
-		childSpan.End()
+	results := make([][]dbmodel.TraceID, 0, len(tags))
+	for _, tag := range tags {
+		t, err := s.queryByTag(ctx, tq, tag.key, tag.value)
 		if err != nil {
 			return nil, err
 		}
+		s.selectivity.recordTag(tag.key, len(t) >= tq.NumTraces)
+		// the other tags are not searched once no trace matches
+		if len(t) == 0 {
+			return nil, nil
+		}
 		results = append(results, t)
 	}
-	return dbmodel.IntersectTraceIDs(results), nil
+	return intersectTraceIDs(results), nil
 }
 
-func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
+func (s *SpanReader) queryByTag(ctx context.Context, tq *spanstore.TraceQueryParameters, k, v string) ([]dbmodel.TraceID, error) {
+	_, childSpan := s.tracer.Start(ctx, "queryByTag")
+	defer childSpan.End()
+	childSpan.SetAttributes(
+		attribute.Key("tag.key").String(k),
+		attribute.Key("tag.value").String(v),
+	)
+	query := s.query(
+		ctx,
+		queryByTag,
+		tq.ServiceName,
+		k,
+		v,
+		model.TimeAsEpochMicroseconds(tq.StartTimeMin),
+		model.TimeAsEpochMicroseconds(tq.StartTimeMax),
+		tq.NumTraces*limitMultiple,
+	).PageSize(0)
+	return s.executeQuery(childSpan, query, s.metrics.queryTagIndex)
+}
+
+func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]dbmodel.TraceID, error) {
 	ctx, span := s.startSpanForQuery(ctx, "queryByDuration", queryByDuration)
 	defer span.End()
 
-	results := dbmodel.UniqueTraceIDs{}
+	var results []dbmodel.TraceID
+	seen := dbmodel.UniqueTraceIDs{}
 
 	minDurationMicros := traceQuery.DurationMin.Nanoseconds() / int64(time.Microsecond/time.Nanosecond)
 	maxDurationMicros := (time.Hour * 24).Nanoseconds() / int64(time.Microsecond/time.Nanosecond)
@@ -358,7 +506,8 @@ func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.
 	for timeBucket := endTimeByHour; timeBucket.After(startTimeByHour) || timeBucket.Equal(startTimeByHour); timeBucket = timeBucket.Add(-1 * durationBucketSize) {
 		_, childSpan := s.tracer.Start(ctx, "queryForTimeBucket")
 		childSpan.SetAttributes(attribute.Key("timeBucket").String(timeBucket.String()))
-		query := s.session.Query(
+		query := s.query(
+			ctx,
 			queryByDuration,
 			timeBucket,
 			traceQuery.ServiceName,
@@ -372,8 +521,12 @@ func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.
 			return nil, err
 		}
 
-		for traceID := range t {
-			results.Add(traceID)
+		for _, traceID := range t {
+			if _, ok := seen[traceID]; ok {
+				continue
+			}
+			seen.Add(traceID)
+			results = append(results, traceID)
 			if len(results) == traceQuery.NumTraces {
 				break
 			}
@@ -382,10 +535,11 @@ func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.
 	return results, nil
 }
 
-func (s *SpanReader) queryByServiceNameAndOperation(ctx context.Context, tq *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
+func (s *SpanReader) queryByServiceNameAndOperation(ctx context.Context, tq *spanstore.TraceQueryParameters) ([]dbmodel.TraceID, error) {
 	_, span := s.startSpanForQuery(ctx, "queryByServiceNameAndOperation", queryByServiceAndOperationName)
 	defer span.End()
-	query := s.session.Query(
+	query := s.query(
+		ctx,
 		queryByServiceAndOperationName,
 		tq.ServiceName,
 		tq.OperationName,
@@ -396,10 +550,11 @@ func (s *SpanReader) queryByServiceNameAndOperation(ctx context.Context, tq *spa
 	return s.executeQuery(span, query, s.metrics.queryServiceOperationIndex)
 }
 
-func (s *SpanReader) queryByService(ctx context.Context, tq *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
+func (s *SpanReader) queryByService(ctx context.Context, tq *spanstore.TraceQueryParameters) ([]dbmodel.TraceID, error) {
 	_, span := s.startSpanForQuery(ctx, "queryByService", queryByServiceAndOperationName)
 	defer span.End()
-	query := s.session.Query(
+	query := s.query(
+		ctx,
 		queryByServiceName,
 		tq.ServiceName,
 		model.TimeAsEpochMicroseconds(tq.StartTimeMin),
@@ -409,13 +564,18 @@ func (s *SpanReader) queryByService(ctx context.Context, tq *spanstore.TraceQuer
 	return s.executeQuery(span, query, s.metrics.queryServiceNameIndex)
 }
 
-func (s *SpanReader) executeQuery(span trace.Span, query cassandra.Query, tableMetrics *casMetrics.Table) (dbmodel.UniqueTraceIDs, error) {
+// executeQuery returns the unique trace IDs in the order they are read from the index table.
+func (s *SpanReader) executeQuery(span trace.Span, query cassandra.Query, tableMetrics *casMetrics.Table) ([]dbmodel.TraceID, error) {
 	start := time.Now()
 	i := query.Iter()
-	retMe := dbmodel.UniqueTraceIDs{}
+	var retMe []dbmodel.TraceID
+	seen := dbmodel.UniqueTraceIDs{}
 	var traceID dbmodel.TraceID
 	for i.Scan(&traceID) {
-		retMe.Add(traceID)
+		if _, ok := seen[traceID]; !ok {
+			seen.Add(traceID)
+			retMe = append(retMe, traceID)
+		}
 	}
 	err := i.Close()
 	tableMetrics.Emit(err, time.Since(start))
@@ -427,6 +587,28 @@ func (s *SpanReader) executeQuery(span trace.Span, query cassandra.Query, tableM
 	return retMe, nil
 }
 
+// intersectTraceIDs returns the trace IDs present in all lists, in the order of the first list.
+func intersectTraceIDs(traceIDsList [][]dbmodel.TraceID) []dbmodel.TraceID {
+	others := make([]dbmodel.UniqueTraceIDs, 0, len(traceIDsList)-1)
+	for _, traceIDs := range traceIDsList[1:] {
+		others = append(others, dbmodel.UniqueTraceIDsFromList(traceIDs))
+	}
+	var retMe []dbmodel.TraceID
+	for _, traceID := range traceIDsList[0] {
+		inAll := true
+		for _, other := range others {
+			if _, ok := other[traceID]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			retMe = append(retMe, traceID)
+		}
+	}
+	return retMe
+}
+
 func (s *SpanReader) startSpanForQuery(ctx context.Context, name, query string) (context.Context, trace.Span) {
 	ctx, span := s.tracer.Start(ctx, name)
 	span.SetAttributes(
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/cassandra"
 	"github.com/jaegertracing/jaeger/pkg/cassandra/mocks"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
@@ -40,11 +42,12 @@ import (
 )
 
 type spanReaderTest struct {
-	session     *mocks.Session
-	logger      *zap.Logger
-	logBuffer   *testutils.Buffer
-	traceBuffer *tracetest.InMemoryExporter
-	reader      *SpanReader
+	session        *mocks.Session
+	logger         *zap.Logger
+	logBuffer      *testutils.Buffer
+	traceBuffer    *tracetest.InMemoryExporter
+	metricsFactory *metricstest.Factory
+	reader         *SpanReader
 }
 
 func tracerProvider(t *testing.T) (trace.TracerProvider, *tracetest.InMemoryExporter, func()) {
@@ -71,11 +74,12 @@ func withSpanReader(t *testing.T, fn func(r *spanReaderTest)) {
 	tracer, exp, closer := tracerProvider(t)
 	defer closer()
 	r := &spanReaderTest{
-		session:     session,
-		logger:      logger,
-		logBuffer:   logBuffer,
-		traceBuffer: exp,
-		reader:      NewSpanReader(session, metricsFactory, logger, tracer.Tracer("test")),
+		session:        session,
+		logger:         logger,
+		logBuffer:      logBuffer,
+		traceBuffer:    exp,
+		metricsFactory: metricsFactory,
+		reader:         NewSpanReader(session, metricsFactory, logger, tracer.Tracer("test")),
 	}
 	fn(r)
 }
@@ -186,6 +190,35 @@ func TestSpanReaderGetTrace_TraceNotFound(t *testing.T) {
 	})
 }
 
+func TestSpanReaderPropagateBearerToken(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		PropagateBearerToken()(r.reader)
+
+		iter := &mocks.Iterator{}
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(nil)
+
+		query := &mocks.Query{}
+		query.On("CustomPayload", map[string][]byte{bearertoken.PropagatedTokenKey: []byte("blah")}).Return(query)
+		query.On("Iter").Return(iter)
+		r.session.On("Query", mock.AnythingOfType("string"), matchEverything()).Return(query)
+
+		ctx := bearertoken.ContextWithBearerToken(context.Background(), "blah")
+		_, err := r.reader.GetTrace(ctx, model.TraceID{})
+		require.EqualError(t, err, "trace not found")
+		query.AssertCalled(t, "CustomPayload", mock.Anything)
+
+		// queries without a token have no custom payload
+		query = &mocks.Query{}
+		query.On("Iter").Return(iter)
+		r.session.ExpectedCalls = nil
+		r.session.On("Query", mock.AnythingOfType("string"), matchEverything()).Return(query)
+		_, err = r.reader.GetTrace(context.Background(), model.TraceID{})
+		require.EqualError(t, err, "trace not found")
+		query.AssertNotCalled(t, "CustomPayload", mock.Anything)
+	})
+}
+
 func TestSpanReaderFindTracesBadRequest(t *testing.T) {
 	withSpanReader(t, func(r *spanReaderTest) {
 		_, err := r.reader.FindTraces(context.Background(), nil)
@@ -422,6 +455,96 @@ func TestSpanReaderFindTraces(t *testing.T) {
 	}
 }
 
+// mockTraceQuery returns the queries of the traces, each with a span of its trace ID, the trace
+// of notFound having no spans. withQuery can set expectations preceding those of the queries.
+func mockTraceQuery(notFound dbmodel.TraceID, withQuery func(query *mocks.Query, iter *mocks.Iterator)) func(string, ...any) cassandra.Query {
+	return func(_ string, values ...any) cassandra.Query {
+		traceID := values[0].(dbmodel.TraceID)
+		iter := &mocks.Iterator{}
+		query := &mocks.Query{}
+		withQuery(query, iter)
+		if traceID != notFound {
+			iter.On("Scan", matchOnceWithSideEffect(func(args []any) {
+				*args[0].(*dbmodel.TraceID) = traceID
+			})).Return(true)
+		}
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(nil)
+		query.On("Iter").Return(iter)
+		return query
+	}
+}
+
+func TestSpanReaderLoadTracesConcurrently(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		ReadConcurrency(3)(r.reader)
+		var traceIDs []model.TraceID
+		for i := uint64(1); i <= 7; i++ {
+			traceIDs = append(traceIDs, model.NewTraceID(0, i))
+		}
+		var inFlight, maxInFlight atomic.Int32
+		notFound := dbmodel.TraceIDFromDomain(traceIDs[4])
+		r.session.On("Query", querySpanByTraceID, matchEverything()).Return(mockTraceQuery(notFound, func(query *mocks.Query, iter *mocks.Iterator) {
+			query.On("Iter").Return(iter).Run(func(mock.Arguments) {
+				n := inFlight.Add(1)
+				for m := maxInFlight.Load(); n > m && !maxInFlight.CompareAndSwap(m, n); m = maxInFlight.Load() {
+				}
+			})
+			iter.On("Close").Return(nil).Run(func(mock.Arguments) {
+				time.Sleep(10 * time.Millisecond)
+				inFlight.Add(-1)
+			})
+		}))
+
+		traces := r.reader.loadTraces(context.Background(), traceIDs, nil)
+		require.Len(t, traces, len(traceIDs))
+		for i, trace := range traces {
+			if i == 4 {
+				assert.Nil(t, trace, "the trace not found is left out")
+				continue
+			}
+			require.NotNil(t, trace)
+			assert.Equal(t, traceIDs[i], trace.Spans[0].TraceID, "the traces keep the order of their IDs")
+		}
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+		assert.Greater(t, maxInFlight.Load(), int32(1))
+		assert.Contains(t, r.logBuffer.String(), "Failure to read trace")
+	})
+}
+
+func TestSpanReaderLoadTracesDeadline(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		var deadlines []time.Time
+		r.session.On("Query", querySpanByTraceID, matchEverything()).Return(mockTraceQuery(dbmodel.TraceID{}, func(query *mocks.Query, _ *mocks.Iterator) {
+			query.On("WithContext", mock.Anything).Return(query).Run(func(args mock.Arguments) {
+				deadline, ok := args.Get(0).(context.Context).Deadline()
+				require.True(t, ok)
+				deadlines = append(deadlines, deadline)
+			})
+		}))
+		traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		traces := r.reader.loadTraces(ctx, traceIDs, nil)
+		require.Len(t, traces, 2)
+		assert.NotNil(t, traces[0])
+		assert.NotNil(t, traces[1])
+		require.Len(t, deadlines, 2)
+		// the first trace has half of the time left, the second one all of the time left
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), deadlines[0], time.Second)
+		assert.WithinDuration(t, deadline, deadlines[1], time.Second)
+
+		// no trace is read after the deadline
+		cancel()
+		traces = r.reader.loadTraces(ctx, traceIDs, nil)
+		assert.Equal(t, []*model.Trace{nil, nil}, traces)
+		assert.Len(t, deadlines, 2)
+		assert.Contains(t, r.logBuffer.String(), "Traces left out of the results of the query")
+	})
+}
+
 func TestTraceQueryParameterValidation(t *testing.T) {
 	tsp := &spanstore.TraceQueryParameters{
 		ServiceName: "",
@@ -432,6 +555,25 @@ func TestTraceQueryParameterValidation(t *testing.T) {
 	err := validateQuery(tsp)
 	require.EqualError(t, err, ErrServiceNameNotSet.Error())
 
+	err = validateQuery(&spanstore.TraceQueryParameters{
+		LogFields: map[string]string{"event": "exception"},
+	})
+	require.EqualError(t, err, ErrServiceNameNotSet.Error())
+
+	err = validateQuery(&spanstore.TraceQueryParameters{
+		ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+	})
+	require.EqualError(t, err, ErrServiceNameNotSet.Error())
+
+	err = validateQuery(&spanstore.TraceQueryParameters{
+		ServiceName:  "serviceName",
+		LogFields:    map[string]string{"event": "exception"},
+		StartTimeMin: time.Now().Add(-1 * time.Hour),
+		StartTimeMax: time.Now(),
+		DurationMin:  time.Minute,
+	})
+	require.EqualError(t, err, ErrDurationAndTagQueryNotSupported.Error())
+
 	tsp.ServiceName = "serviceName"
 	tsp.StartTimeMin = time.Now()
 	tsp.StartTimeMax = time.Now().Add(-1 * time.Hour)
@@ -454,3 +596,126 @@ func TestTraceQueryParameterValidation(t *testing.T) {
 	err = validateQuery(tsp)
 	require.EqualError(t, err, ErrStartAndEndTimeNotSet.Error())
 }
+
+func TestSpanReaderFindTraceIDsPagination(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockQuery := func() *mocks.Query {
+			traceIDs := []dbmodel.TraceID{
+				dbmodel.TraceIDFromDomain(model.NewTraceID(0, 3)),
+				dbmodel.TraceIDFromDomain(model.NewTraceID(0, 1)),
+				dbmodel.TraceIDFromDomain(model.NewTraceID(0, 3)),
+				dbmodel.TraceIDFromDomain(model.NewTraceID(0, 2)),
+			}
+			iter := &mocks.Iterator{}
+			iter.On("Scan", mock.MatchedBy(func(args []any) bool {
+				if len(traceIDs) == 0 {
+					return false
+				}
+				*args[0].(*dbmodel.TraceID) = traceIDs[0]
+				traceIDs = traceIDs[1:]
+				return true
+			})).Return(true)
+			iter.On("Scan", mock.Anything).Return(false)
+			iter.On("Close").Return(nil)
+
+			query := &mocks.Query{}
+			query.On("PageSize", 0).Return(query)
+			query.On("Iter").Return(iter)
+			return query
+		}
+		r.session.On("Query", stringMatcher(queryByServiceName), mock.Anything).Return(mockQuery()).Once()
+		r.session.On("Query", stringMatcher(queryByServiceName), mock.Anything).Return(mockQuery()).Once()
+
+		queryParams := &spanstore.TraceQueryParameters{
+			ServiceName:  "service-a",
+			NumTraces:    2,
+			StartTimeMax: time.Now(),
+			StartTimeMin: time.Now().Add(-1 * time.Minute * 30),
+		}
+		traceIDs, _, nextPageToken, err := r.reader.findTraceIDsAndPlan(context.Background(), queryParams)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 3), model.NewTraceID(0, 1)}, traceIDs)
+		assert.Equal(t, spanstore.EncodePageToken(2), nextPageToken)
+
+		queryParams.PageToken = nextPageToken
+		traceIDs, _, nextPageToken, err = r.reader.findTraceIDsAndPlan(context.Background(), queryParams)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2)}, traceIDs)
+		assert.Empty(t, nextPageToken)
+
+		queryParams.PageToken = "invalid token"
+		_, err = r.reader.FindTraceIDs(context.Background(), queryParams)
+		require.ErrorIs(t, err, spanstore.ErrInvalidPageToken)
+		// the offsets exceeding the maximum are rejected without querying the storage
+		queryParams.PageToken = spanstore.EncodePageToken(maxTraceOffset + 1)
+		_, err = r.reader.FindTraceIDs(context.Background(), queryParams)
+		require.ErrorIs(t, err, spanstore.ErrInvalidPageToken)
+	})
+}
+
+func TestIntersectTraceIDs(t *testing.T) {
+	id := func(low uint64) dbmodel.TraceID {
+		return dbmodel.TraceIDFromDomain(model.NewTraceID(0, low))
+	}
+	intersection := intersectTraceIDs([][]dbmodel.TraceID{
+		{id(4), id(1), id(3), id(2)},
+		{id(1), id(2), id(3)},
+		{id(2), id(3), id(5)},
+	})
+	assert.Equal(t, []dbmodel.TraceID{id(3), id(2)}, intersection)
+}
+
+func TestSpanReaderFindTraceIDsByLogFields(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		// each lookup finds a trace, so that the other lookups are not skipped
+		r.session.On("Query", stringMatcher(queryByTag), matchEverything()).Return(func(string, ...any) cassandra.Query {
+			iter := &mocks.Iterator{}
+			iter.On("Scan", matchOnce()).Return(true)
+			iter.On("Scan", mock.Anything).Return(false)
+			iter.On("Close").Return(nil)
+			query := &mocks.Query{}
+			query.On("PageSize", 0).Return(query)
+			query.On("Iter").Return(iter)
+			return query
+		})
+
+		callsBefore := len(r.session.Calls)
+		_, err := r.reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  "service-a",
+			LogFields:    map[string]string{"event": "exception", "exception.type": "TimeoutError"},
+			StartTimeMax: time.Now(),
+			StartTimeMin: time.Now().Add(-1 * time.Minute * 30),
+		})
+		require.NoError(t, err)
+		// one tag index lookup per log field
+		assert.Len(t, r.session.Calls, callsBefore+2)
+	})
+}
+
+func TestSpanReaderFindTraceIDsByResourceAttributes(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		// each lookup finds a trace, so that the other lookups are not skipped
+		r.session.On("Query", stringMatcher(queryByTag), matchEverything()).Return(func(string, ...any) cassandra.Query {
+			iter := &mocks.Iterator{}
+			iter.On("Scan", matchOnce()).Return(true)
+			iter.On("Scan", mock.Anything).Return(false)
+			iter.On("Close").Return(nil)
+			query := &mocks.Query{}
+			query.On("PageSize", 0).Return(query)
+			query.On("Iter").Return(iter)
+			return query
+		})
+
+		callsBefore := len(r.session.Calls)
+		_, err := r.reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:        "service-a",
+			Tags:               map[string]string{"http.method": "GET"},
+			ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+			StartTimeMax:       time.Now(),
+			StartTimeMin:       time.Now().Add(-1 * time.Minute * 30),
+		})
+		require.NoError(t, err)
+		// one tag index lookup per tag and resource attribute
+		assert.Len(t, r.session.Calls, callsBefore+2)
+	})
+}
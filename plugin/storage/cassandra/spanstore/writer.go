@@ -138,23 +138,33 @@ func (s *SpanWriter) Close() error {
 }
 
 // WriteSpan saves the span into Cassandra
-func (s *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
+func (s *SpanWriter) WriteSpan(ctx context.Context, span *model.Span) error {
 	ds := dbmodel.FromDomain(span)
 	if s.storageMode&storeFlag == storeFlag {
-		if err := s.writeSpan(span, ds); err != nil {
+		if err := s.writeSpan(ctx, span, ds); err != nil {
 			return err
 		}
 	}
 	if s.storageMode&indexFlag == indexFlag {
-		if err := s.writeIndexes(span, ds); err != nil {
+		if err := s.writeIndexes(ctx, span, ds); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *SpanWriter) writeSpan(_ *model.Span, ds *dbmodel.Span) error {
-	mainQuery := s.session.Query(
+// query creates the query of a write, canceled with the context if it can be.
+func (s *SpanWriter) query(ctx context.Context, stmt string, values ...any) cassandra.Query {
+	query := s.session.Query(stmt, values...)
+	if ctx.Done() != nil {
+		query = query.WithContext(ctx)
+	}
+	return query
+}
+
+func (s *SpanWriter) writeSpan(ctx context.Context, _ *model.Span, ds *dbmodel.Span) error {
+	mainQuery := s.query(
+		ctx,
 		insertSpan,
 		ds.TraceID,
 		ds.SpanID,
@@ -175,7 +185,7 @@ func (s *SpanWriter) writeSpan(_ *model.Span, ds *dbmodel.Span) error {
 	return nil
 }
 
-func (s *SpanWriter) writeIndexes(span *model.Span, ds *dbmodel.Span) error {
+func (s *SpanWriter) writeIndexes(ctx context.Context, span *model.Span, ds *dbmodel.Span) error {
 	spanKind, _ := span.GetSpanKind()
 	if err := s.saveServiceNameAndOperationName(dbmodel.Operation{
 		ServiceName:   ds.ServiceName,
@@ -187,13 +197,13 @@ func (s *SpanWriter) writeIndexes(span *model.Span, ds *dbmodel.Span) error {
 	}
 
 	if s.indexFilter(ds, dbmodel.ServiceIndex) {
-		if err := s.indexByService(ds); err != nil {
+		if err := s.indexByService(ctx, ds); err != nil {
 			return s.logError(ds, err, "Failed to index service name", s.logger)
 		}
 	}
 
 	if s.indexFilter(ds, dbmodel.OperationIndex) {
-		if err := s.indexByOperation(ds); err != nil {
+		if err := s.indexByOperation(ctx, ds); err != nil {
 			return s.logError(ds, err, "Failed to index operation name", s.logger)
 		}
 	}
@@ -202,24 +212,24 @@ func (s *SpanWriter) writeIndexes(span *model.Span, ds *dbmodel.Span) error {
 		return nil // skipping expensive indexing
 	}
 
-	if err := s.indexByTags(span, ds); err != nil {
+	if err := s.indexByTags(ctx, span, ds); err != nil {
 		return s.logError(ds, err, "Failed to index tags", s.logger)
 	}
 
 	if s.indexFilter(ds, dbmodel.DurationIndex) {
-		if err := s.indexByDuration(ds, span.StartTime); err != nil {
+		if err := s.indexByDuration(ctx, ds, span.StartTime); err != nil {
 			return s.logError(ds, err, "Failed to index duration", s.logger)
 		}
 	}
 	return nil
 }
 
-func (s *SpanWriter) indexByTags(span *model.Span, ds *dbmodel.Span) error {
+func (s *SpanWriter) indexByTags(ctx context.Context, span *model.Span, ds *dbmodel.Span) error {
 	for _, v := range dbmodel.GetAllUniqueTags(span, s.tagFilter) {
 		// we should introduce retries or just ignore failures imo, retrying each individual tag insertion might be better
 		// we should consider bucketing.
 		if s.shouldIndexTag(v) {
-			insertTagQuery := s.session.Query(tagIndex, ds.TraceID, ds.SpanID, v.ServiceName, ds.StartTime, v.TagKey, v.TagValue)
+			insertTagQuery := s.query(ctx, tagIndex, ds.TraceID, ds.SpanID, v.ServiceName, ds.StartTime, v.TagKey, v.TagValue)
 			if err := s.writerMetrics.tagIndex.Exec(insertTagQuery, s.logger); err != nil {
 				withTagInfo := s.logger.
 					With(zap.String("tag_key", v.TagKey)).
@@ -234,8 +244,8 @@ func (s *SpanWriter) indexByTags(span *model.Span, ds *dbmodel.Span) error {
 	return nil
 }
 
-func (s *SpanWriter) indexByDuration(span *dbmodel.Span, startTime time.Time) error {
-	query := s.session.Query(durationIndex)
+func (s *SpanWriter) indexByDuration(ctx context.Context, span *dbmodel.Span, startTime time.Time) error {
+	query := s.query(ctx, durationIndex)
 	timeBucket := startTime.Round(durationBucketSize)
 	var err error
 	indexByOperationName := func(operationName string) {
@@ -250,15 +260,15 @@ func (s *SpanWriter) indexByDuration(span *dbmodel.Span, startTime time.Time) er
 	return err
 }
 
-func (s *SpanWriter) indexByService(span *dbmodel.Span) error {
+func (s *SpanWriter) indexByService(ctx context.Context, span *dbmodel.Span) error {
 	bucketNo := uint64(span.SpanHash) % defaultNumBuckets
-	query := s.session.Query(serviceNameIndex)
+	query := s.query(ctx, serviceNameIndex)
 	q := query.Bind(span.Process.ServiceName, bucketNo, span.StartTime, span.TraceID)
 	return s.writerMetrics.serviceNameIndex.Exec(q, s.logger)
 }
 
-func (s *SpanWriter) indexByOperation(span *dbmodel.Span) error {
-	query := s.session.Query(serviceOperationIndex)
+func (s *SpanWriter) indexByOperation(ctx context.Context, span *dbmodel.Span) error {
+	query := s.query(ctx, serviceOperationIndex)
 	q := query.Bind(span.Process.ServiceName, span.OperationName, span.StartTime, span.TraceID)
 	return s.writerMetrics.serviceOperationIndex.Exec(q, s.logger)
 }
@@ -435,3 +435,23 @@ func TestStorageMode_StoreWithoutIndexing(t *testing.T) {
 		w.session.AssertNotCalled(t, "Query", stringMatcher(serviceNameIndex), matchEverything())
 	}, StoreWithoutIndexing())
 }
+
+func TestSpanWriterWithContext(t *testing.T) {
+	withSpanWriter(0, func(w *spanWriterTest) {
+		span := &model.Span{
+			TraceID: model.NewTraceID(0, 1),
+			Process: &model.Process{
+				ServiceName: "service-a",
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		spanQuery := &mocks.Query{}
+		spanQuery.On("WithContext", ctx).Return(spanQuery)
+		spanQuery.On("Exec").Return(nil)
+		w.session.On("Query", stringMatcher(insertSpan), matchEverything()).Return(spanQuery)
+
+		require.NoError(t, w.writer.WriteSpan(ctx, span))
+		spanQuery.AssertExpectations(t)
+	}, StoreWithoutIndexing())
+}
@@ -57,6 +57,7 @@ var ( // interface comformance checks
 	_ io.Closer              = (*Factory)(nil)
 	_ plugin.Configurable    = (*Factory)(nil)
 	_ storage.Purger         = (*Factory)(nil)
+	_ storage.HealthChecker  = (*Factory)(nil)
 )
 
 // Factory implements storage.Factory for Elasticsearch backend.
@@ -204,6 +205,23 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return createDependencyReader(f.getPrimaryClient, f.primaryConfig, f.logger)
 }
 
+// Capabilities implements storage.Factory
+func (f *Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{
+		ArchiveStorage: f.archiveConfig.Enabled,
+		// the spans are written by the bulk processor
+		StreamingWrites: true,
+		TraceSearch:     true,
+		TagSearch:       true,
+		TagFilters:      true,
+		// the span kind of the operations is not returned yet,
+		// see https://github.com/jaegertracing/jaeger/issues/1923
+		OperationSpanKind: false,
+		Dependencies:      true,
+		AdaptiveSampling:  true,
+	}
+}
+
 // CreateArchiveSpanReader implements storage.ArchiveFactory
 func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
 	if !f.archiveConfig.Enabled {
@@ -242,6 +260,9 @@ func createSpanReader(
 		ServiceIndexRolloverFrequency: cfg.GetIndexRolloverFrequencyServicesDuration(),
 		TagDotReplacement:             cfg.Tags.DotReplacement,
 		UseReadWriteAliases:           cfg.UseReadWriteAliases,
+		RouteByService:                cfg.RouteByService,
+		UsePointInTime:                cfg.UsePointInTime,
+		AsyncSearchTimeout:            cfg.AsyncSearchTimeout,
 		Archive:                       archive,
 		RemoteReadClusters:            cfg.RemoteReadClusters,
 		Logger:                        logger,
@@ -277,6 +298,8 @@ func createSpanWriter(
 		TagDotReplacement:      cfg.Tags.DotReplacement,
 		Archive:                archive,
 		UseReadWriteAliases:    cfg.UseReadWriteAliases,
+		RouteByService:         cfg.RouteByService,
+		IdempotentWrites:       cfg.IdempotentWrites,
 		Logger:                 logger,
 		MetricsFactory:         mFactory,
 		ServiceCacheTTL:        cfg.ServiceCacheTTL,
@@ -410,6 +433,11 @@ func (f *Factory) Purge(ctx context.Context) error {
 	return err
 }
 
+// CheckHealth implements storage.HealthChecker by pinging the primary cluster.
+func (f *Factory) CheckHealth(ctx context.Context) error {
+	return f.getPrimaryClient().Ping(ctx)
+}
+
 func loadTokenFromFile(path string) (string, error) {
 	b, err := os.ReadFile(filepath.Clean(path))
 	if err != nil {
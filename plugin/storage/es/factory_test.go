@@ -113,6 +113,15 @@ func TestElasticsearchFactory(t *testing.T) {
 	require.NoError(t, f.Close())
 }
 
+func TestElasticsearchFactoryCheckHealth(t *testing.T) {
+	c := &mocks.Client{}
+	c.On("Ping", mock.Anything).Return(errors.New("no Elasticsearch node available"))
+	var client es.Client = c
+	f := NewFactory()
+	f.primaryClient.Store(&client)
+	require.EqualError(t, f.CheckHealth(context.Background()), "no Elasticsearch node available")
+}
+
 func TestElasticsearchTagsFileDoNotExist(t *testing.T) {
 	f := NewFactory()
 	f.primaryConfig = &escfg.Configuration{
@@ -244,6 +253,7 @@ func TestArchiveDisabled(t *testing.T) {
 	r, err := f.CreateArchiveSpanReader()
 	assert.Nil(t, r)
 	require.NoError(t, err)
+	assert.False(t, f.Capabilities().ArchiveStorage)
 }
 
 func TestArchiveEnabled(t *testing.T) {
@@ -260,6 +270,7 @@ func TestArchiveEnabled(t *testing.T) {
 	r, err := f.CreateArchiveSpanReader()
 	require.NoError(t, err)
 	assert.NotNil(t, r)
+	assert.True(t, f.Capabilities().ArchiveStorage)
 }
 
 func TestConfigureFromOptions(t *testing.T) {
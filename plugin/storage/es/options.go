@@ -61,6 +61,10 @@ const (
 	suffixTagsFile                       = suffixTagsAsFields + ".config-file"
 	suffixTagDeDotChar                   = suffixTagsAsFields + ".dot-replacement"
 	suffixReadAlias                      = ".use-aliases"
+	suffixRouteByService                 = ".route-by-service"
+	suffixIdempotentWrites               = ".idempotent-writes"
+	suffixUsePointInTime                 = ".use-point-in-time"
+	suffixAsyncSearchTimeout             = ".async-search-timeout"
 	suffixUseILM                         = ".use-ilm"
 	suffixCreateIndexTemplate            = ".create-index-templates"
 	suffixEnabled                        = ".enabled"
@@ -68,6 +72,10 @@ const (
 	suffixMaxDocCount                    = ".max-doc-count"
 	suffixLogLevel                       = ".log-level"
 	suffixSendGetBodyAs                  = ".send-get-body-as"
+	suffixClient                         = ".client"
+	suffixCompressRequests               = ".compress-requests"
+	suffixCompatibilityHeader            = ".compatibility-header"
+	suffixMaxRetries                     = ".max-retries"
 	// default number of documents to return from a query (elasticsearch allowed limit)
 	// see search.max_buckets and index.max_result_window
 	defaultMaxDocCount        = 10_000
@@ -78,6 +86,7 @@ const (
 
 	defaultIndexRolloverFrequency = "day"
 	defaultSendGetBodyAs          = ""
+	defaultMaxRetries             = 3
 )
 
 // TODO this should be moved next to config.Configuration struct (maybe ./flags package)
@@ -254,6 +263,32 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 		"Use read and write aliases for indices. Use this option with Elasticsearch rollover "+
 			"API. It requires an external component to create aliases before startup and then performing its management. "+
 			"Note that es"+suffixMaxSpanAge+" will influence trace search window start times.")
+	flagSet.Bool(
+		nsConfig.namespace+suffixRouteByService,
+		nsConfig.RouteByService,
+		"Route the spans to the shards of their indices by service name, so that the searches for the traces of a service "+
+			"only query the shard holding the spans of the service. The spans written before the option is enabled "+
+			"are not found by these searches until their indices are removed.")
+	flagSet.Bool(
+		nsConfig.namespace+suffixIdempotentWrites,
+		nsConfig.IdempotentWrites,
+		"Derive the IDs of the span documents from the span hash, so that the spans written again, e.g. redelivered by Kafka "+
+			"to the ingester after a rebalance, overwrite their documents instead of being duplicated. It increases the indexing cost, "+
+			"Elasticsearch checking whether each document exists.")
+	flagSet.Bool(
+		nsConfig.namespace+suffixUsePointInTime,
+		nsConfig.UsePointInTime,
+		"Page through the spans of the traces read with a point in time and search_after, instead of searching the indices "+
+			"again for each page, so that the large traces are read from a consistent view of the indices without missing "+
+			"the spans starting at the same time as the last span of a page. The pages of the traces found by the api_v3 "+
+			"queries are also searched in a point in time, passed on by their continuation tokens. "+
+			"Supported only for elasticsearch version 7.10+.")
+	flagSet.Duration(
+		nsConfig.namespace+suffixAsyncSearchTimeout,
+		nsConfig.AsyncSearchTimeout,
+		"Search the trace IDs with async searches, waiting for up to this timeout at each poll of their completion, "+
+			"so that the searches over long time ranges are not bound by the timeout of the requests. "+
+			"Zero disables the async searches. Supported only for elasticsearch version 7.7+.")
 	flagSet.Bool(
 		nsConfig.namespace+suffixUseILM,
 		nsConfig.UseILM,
@@ -284,6 +319,27 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 		nsConfig.namespace+suffixSendGetBodyAs,
 		nsConfig.SendGetBodyAs,
 		"HTTP verb for requests that contain a body [GET, POST].")
+	flagSet.String(
+		nsConfig.namespace+suffixClient,
+		nsConfig.Client,
+		"The client library sending the requests to Elasticsearch [olivere, go-elasticsearch]. The official go-elasticsearch "+
+			"client supports only Elasticsearch 7.14+, not OpenSearch, and flushes the bulk requests by size or interval only, "+
+			"ignoring "+nsConfig.namespace+suffixBulkActions+".")
+	flagSet.Bool(
+		nsConfig.namespace+suffixCompressRequests,
+		nsConfig.CompressRequests,
+		"Compress the bodies of the requests with gzip. Supported only by the go-elasticsearch client.")
+	flagSet.Bool(
+		nsConfig.namespace+suffixCompatibilityHeader,
+		nsConfig.CompatibilityHeader,
+		"Send the compatibility headers, so that Elasticsearch answers the requests as its previous major version does. "+
+			"Supported only by the go-elasticsearch client.")
+	flagSet.Int(
+		nsConfig.namespace+suffixMaxRetries,
+		nsConfig.MaxRetries,
+		"The maximum number of retries of the requests failing with a 429, 502, 503 or 504 status or a connection error, "+
+			"with an exponential backoff. Zero disables the retries. Supported by the go-elasticsearch client, "+
+			"and by the index templates requests to Elasticsearch 8+.")
 	flagSet.Duration(
 		nsConfig.namespace+suffixAdaptiveSamplingLookback,
 		nsConfig.AdaptiveSamplingLookback,
@@ -339,11 +395,19 @@ func initFromViper(cfg *namespaceConfig, v *viper.Viper) {
 	cfg.Tags.File = v.GetString(cfg.namespace + suffixTagsFile)
 	cfg.Tags.DotReplacement = v.GetString(cfg.namespace + suffixTagDeDotChar)
 	cfg.UseReadWriteAliases = v.GetBool(cfg.namespace + suffixReadAlias)
+	cfg.RouteByService = v.GetBool(cfg.namespace + suffixRouteByService)
+	cfg.IdempotentWrites = v.GetBool(cfg.namespace + suffixIdempotentWrites)
+	cfg.UsePointInTime = v.GetBool(cfg.namespace + suffixUsePointInTime)
+	cfg.AsyncSearchTimeout = v.GetDuration(cfg.namespace + suffixAsyncSearchTimeout)
 	cfg.Enabled = v.GetBool(cfg.namespace + suffixEnabled)
 	cfg.CreateIndexTemplates = v.GetBool(cfg.namespace + suffixCreateIndexTemplate)
 	cfg.Version = uint(v.GetInt(cfg.namespace + suffixVersion))
 	cfg.LogLevel = v.GetString(cfg.namespace + suffixLogLevel)
 	cfg.SendGetBodyAs = v.GetString(cfg.namespace + suffixSendGetBodyAs)
+	cfg.Client = v.GetString(cfg.namespace + suffixClient)
+	cfg.CompressRequests = v.GetBool(cfg.namespace + suffixCompressRequests)
+	cfg.CompatibilityHeader = v.GetBool(cfg.namespace + suffixCompatibilityHeader)
+	cfg.MaxRetries = v.GetInt(cfg.namespace + suffixMaxRetries)
 
 	cfg.MaxDocCount = v.GetInt(cfg.namespace + suffixMaxDocCount)
 	cfg.UseILM = v.GetBool(cfg.namespace + suffixUseILM)
@@ -437,5 +501,7 @@ func DefaultConfig() config.Configuration {
 		MaxDocCount:          defaultMaxDocCount,
 		LogLevel:             "error",
 		SendGetBodyAs:        defaultSendGetBodyAs,
+		Client:               config.ClientOlivere,
+		MaxRetries:           defaultMaxRetries,
 	}
 }
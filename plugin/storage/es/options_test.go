@@ -77,6 +77,14 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--es.tags-as-fields.config-file=./file.txt",
 		"--es.tags-as-fields.dot-replacement=!",
 		"--es.use-ilm=true",
+		"--es.route-by-service=true",
+		"--es.idempotent-writes=true",
+		"--es.use-point-in-time=true",
+		"--es.async-search-timeout=2s",
+		"--es.client=go-elasticsearch",
+		"--es.compress-requests=true",
+		"--es.compatibility-header=true",
+		"--es.max-retries=5",
 		"--es.send-get-body-as=POST",
 	})
 	require.NoError(t, err)
@@ -115,6 +123,14 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, "2006.01.02", aux.IndexDateLayoutServices)
 	assert.Equal(t, "2006.01.02.15", aux.IndexDateLayoutSpans)
 	assert.True(t, primary.UseILM)
+	assert.True(t, primary.RouteByService)
+	assert.True(t, primary.IdempotentWrites)
+	assert.True(t, primary.UsePointInTime)
+	assert.Equal(t, 2*time.Second, primary.AsyncSearchTimeout)
+	assert.Equal(t, "go-elasticsearch", primary.Client)
+	assert.True(t, primary.CompressRequests)
+	assert.True(t, primary.CompatibilityHeader)
+	assert.Equal(t, 5, primary.MaxRetries)
 	assert.Equal(t, "POST", aux.SendGetBodyAs)
 }
 
@@ -125,9 +125,26 @@ func (fd FromDomain) convertProcess(process *model.Process) Process {
 		ServiceName: process.ServiceName,
 		Tags:        tags,
 		Tag:         tagsMap,
+		Resource:    convertResource(process.Tags),
 	}
 }
 
+// convertResource returns the searchable resource attributes of the process tags, if any.
+func convertResource(tags []model.KeyValue) map[string]string {
+	var resource map[string]string
+	for _, tag := range tags {
+		field, ok := ResourceFields[tag.Key]
+		if !ok {
+			continue
+		}
+		if resource == nil {
+			resource = make(map[string]string)
+		}
+		resource[field] = tag.AsString()
+	}
+	return resource
+}
+
 func convertKeyValue(kv model.KeyValue) KeyValue {
 	return KeyValue{
 		Key:   kv.Key,
@@ -108,6 +108,23 @@ func TestTagMap(t *testing.T) {
 	assert.Equal(t, tagsMap, dbSpan.Process.Tag)
 }
 
+func TestResource(t *testing.T) {
+	tags := []model.KeyValue{
+		model.String("k8s.namespace.name", "checkout"),
+		model.String("deployment.environment", "prod"),
+		model.String("hostname", "host1"),
+	}
+	span := model.Span{Process: &model.Process{Tags: tags}}
+	dbSpan := NewFromDomain(true, nil, ":").FromDomainEmbedProcess(&span)
+	assert.Equal(t, map[string]string{"k8sNamespace": "checkout", "deploymentEnvironment": "prod"}, dbSpan.Process.Resource)
+	// the resource attributes are kept in the tags
+	assert.Len(t, dbSpan.Process.Tag, 3)
+
+	span = model.Span{Process: &model.Process{Tags: tags[2:]}}
+	dbSpan = NewFromDomain(false, nil, ":").FromDomainEmbedProcess(&span)
+	assert.Nil(t, dbSpan.Process.Resource)
+}
+
 func TestConvertKeyValueValue(t *testing.T) {
 	longString := `Bender Bending Rodrigues Bender Bending Rodrigues Bender Bending Rodrigues Bender Bending Rodrigues
 	Bender Bending Rodrigues Bender Bending Rodrigues Bender Bending Rodrigues Bender Bending Rodrigues Bender Bending Rodrigues
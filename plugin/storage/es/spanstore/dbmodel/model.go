@@ -79,6 +79,17 @@ type Process struct {
 	Tags        []KeyValue `json:"tags"`
 	// Alternative representation of tags for better kibana support
 	Tag map[string]any `json:"tag,omitempty"`
+	// Resource holds the searchable resource attributes of the process, also kept in the tags,
+	// as dedicated fields named by ResourceFields.
+	Resource map[string]string `json:"resource,omitempty"`
+}
+
+// ResourceFields maps the searchable resource attributes to the fields of Process.Resource.
+var ResourceFields = map[string]string{
+	"k8s.namespace.name":     "k8sNamespace",
+	"k8s.pod.name":           "k8sPodName",
+	"deployment.environment": "deploymentEnvironment",
+	"service.version":        "serviceVersion",
 }
 
 // Log is a log emitted in a span
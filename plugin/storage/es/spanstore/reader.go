@@ -18,9 +18,13 @@ package spanstore
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/olivere/elastic"
@@ -43,6 +47,8 @@ const (
 	archiveReadIndexSuffix  = archiveIndexSuffix + "-read"
 	archiveWriteIndexSuffix = archiveIndexSuffix + "-write"
 	traceIDAggregation      = "traceIDs"
+	percentilesAggregation  = "durationPercentiles"
+	histogramAggregation    = "durationHistogram"
 	indexPrefixSeparator    = "-"
 
 	traceIDField           = "traceID"
@@ -55,6 +61,8 @@ const (
 	objectProcessTagsField = "process.tag"
 	nestedTagsField        = "tags"
 	nestedProcessTagsField = "process.tags"
+	processResourceField   = "process.resource"
+	nestedLogsField        = "logs"
 	nestedLogFieldsField   = "logs.fields"
 	tagKeyField            = "key"
 	tagValueField          = "value"
@@ -62,6 +70,16 @@ const (
 	defaultNumTraces = 100
 
 	rolloverMaxSpanAge = time.Hour * 24 * 365 * 50
+
+	// pointInTimeKeepAlive is how long a point in time is kept between the searches of the pages of the traces.
+	pointInTimeKeepAlive = "1m"
+
+	// tagFilterScript compares the numeric values of a field with the %s operator of a tag filter,
+	// the tags being indexed as keywords, the values that are not numbers never matching.
+	tagFilterScript = "if (!doc.containsKey(params.field)) { return false; } " +
+		"for (def v : doc[params.field]) { " +
+		"try { if (Double.parseDouble(v) %s params.value) { return true; } } catch (NumberFormatException e) {} " +
+		"} return false;"
 )
 
 var (
@@ -83,6 +101,9 @@ var (
 	// ErrUnableToFindTraceIDAggregation occurs when an aggregation query for TraceIDs fail.
 	ErrUnableToFindTraceIDAggregation = errors.New("could not find aggregation of traceIDs")
 
+	// ErrUnableToFindLatencyAggregation occurs when an aggregation query for span durations fail.
+	ErrUnableToFindLatencyAggregation = errors.New("could not find aggregation of span durations")
+
 	defaultMaxDuration = model.DurationAsMicroseconds(time.Hour * 24)
 
 	objectTagFieldList = []string{objectTagsField, objectProcessTagsField}
@@ -108,6 +129,9 @@ type SpanReader struct {
 	sourceFn                      sourceFn
 	maxDocCount                   int
 	useReadWriteAliases           bool
+	routeByService                bool
+	usePointInTime                bool
+	asyncSearchTimeout            time.Duration
 	logger                        *zap.Logger
 	tracer                        trace.Tracer
 }
@@ -125,10 +149,20 @@ type SpanReaderParams struct {
 	TagDotReplacement             string
 	Archive                       bool
 	UseReadWriteAliases           bool
-	RemoteReadClusters            []string
-	MetricsFactory                metrics.Factory
-	Logger                        *zap.Logger
-	Tracer                        trace.Tracer
+	// RouteByService makes the searches by service name only query the shard the spans
+	// of the service are routed to. Elasticsearch hashes the service name to pick the shard.
+	RouteByService bool
+	// UsePointInTime makes the reads of the traces page through their spans with a point in time
+	// of the indices and search_after, instead of searching the indices again for each page, and
+	// the pages of FindTracesPage be searched in a point in time passed on by their tokens.
+	UsePointInTime bool
+	// AsyncSearchTimeout, if positive, makes the searches of the trace IDs async searches, waiting
+	// for up to the timeout at each poll of their completion.
+	AsyncSearchTimeout time.Duration
+	RemoteReadClusters []string
+	MetricsFactory     metrics.Factory
+	Logger             *zap.Logger
+	Tracer             trace.Tracer
 }
 
 // NewSpanReader returns a new SpanReader with a metrics.
@@ -154,6 +188,9 @@ func NewSpanReader(p SpanReaderParams) *SpanReader {
 		sourceFn:                      getSourceFn(p.Archive, p.MaxDocCount),
 		maxDocCount:                   p.MaxDocCount,
 		useReadWriteAliases:           p.UseReadWriteAliases,
+		routeByService:                p.RouteByService,
+		usePointInTime:                p.UsePointInTime,
+		asyncSearchTimeout:            p.AsyncSearchTimeout,
 		logger:                        p.Logger,
 		tracer:                        p.Tracer,
 	}
@@ -302,7 +339,7 @@ func (s *SpanReader) GetOperations(
 	defer span.End()
 	currentTime := time.Now()
 	jaegerIndices := s.timeRangeIndices(s.serviceIndexPrefix, s.serviceIndexDateLayout, currentTime.Add(-s.maxSpanAge), currentTime, s.serviceIndexRolloverFrequency)
-	operations, err := s.serviceOperationStorage.getOperations(ctx, jaegerIndices, query.ServiceName, s.maxDocCount)
+	operations, err := s.serviceOperationStorage.getOperations(ctx, jaegerIndices, query.ServiceName, query.NamePrefix, s.maxDocCount)
 	if err != nil {
 		return nil, err
 	}
@@ -315,7 +352,7 @@ func (s *SpanReader) GetOperations(
 			Name: operation,
 		})
 	}
-	return result, err
+	return spanstore.PageOperations(result, query), err
 }
 
 func bucketToStringArray(buckets []*elastic.AggregationBucketKeyItem) ([]string, error) {
@@ -332,14 +369,31 @@ func bucketToStringArray(buckets []*elastic.AggregationBucketKeyItem) ([]string,
 
 // FindTraces retrieves traces that match the traceQuery
 func (s *SpanReader) FindTraces(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	page, err := s.findTracesPage(ctx, traceQuery, false)
+	if err != nil {
+		return nil, err
+	}
+	return page.Traces, nil
+}
+
+// FindTracesPage implements spanstore.PaginatedReader#FindTracesPage
+func (s *SpanReader) FindTracesPage(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	return s.findTracesPage(ctx, traceQuery, true)
+}
+
+func (s *SpanReader) findTracesPage(ctx context.Context, traceQuery *spanstore.TraceQueryParameters, paginate bool) (*spanstore.TracesPage, error) {
 	ctx, span := s.tracer.Start(ctx, "FindTraces")
 	defer span.End()
 
-	uniqueTraceIDs, err := s.FindTraceIDs(ctx, traceQuery)
+	uniqueTraceIDs, nextPageToken, err := s.findTraceIDsPage(ctx, traceQuery, paginate)
 	if err != nil {
 		return nil, es.DetailedError(err)
 	}
-	return s.multiRead(ctx, uniqueTraceIDs, traceQuery.StartTimeMin, traceQuery.StartTimeMax)
+	traces, err := s.multiRead(ctx, uniqueTraceIDs, traceQuery.StartTimeMin, traceQuery.StartTimeMax)
+	if err != nil {
+		return nil, err
+	}
+	return &spanstore.TracesPage{Traces: traces, NextPageToken: nextPageToken}, nil
 }
 
 // FindTraceIDs retrieves traces IDs that match the traceQuery
@@ -347,21 +401,354 @@ func (s *SpanReader) FindTraceIDs(ctx context.Context, traceQuery *spanstore.Tra
 	ctx, span := s.tracer.Start(ctx, "FindTraceIDs")
 	defer span.End()
 
+	traceIDs, _, err := s.findTraceIDsPage(ctx, traceQuery, false)
+	return traceIDs, err
+}
+
+// findTraceIDsPage returns the page of the trace IDs matching the traceQuery and, if paginate, the token of
+// the next page. With usePointInTime, the pages are searched in a point in time of the indices opened by the
+// first page, which the token of the next page passes on, and which is closed once it is not passed on.
+func (s *SpanReader) findTraceIDsPage(ctx context.Context, traceQuery *spanstore.TraceQueryParameters, paginate bool) (_ []model.TraceID, nextPageToken string, err error) {
 	if err := validateQuery(traceQuery); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if traceQuery.NumTraces == 0 {
 		traceQuery.NumTraces = defaultNumTraces
 	}
+	pitID, cursor, err := decodeTracesPageToken(traceQuery.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	if pitID == "" && paginate && s.usePointInTime {
+		jaegerIndices := s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, traceQuery.StartTimeMin, traceQuery.StartTimeMax, s.spanIndexRolloverFrequency)
+		pitID, err = s.client().OpenPointInTime(ctx, pointInTimeKeepAlive, jaegerIndices...)
+		if err != nil {
+			return nil, "", fmt.Errorf("open point in time failed: %w", es.DetailedError(err))
+		}
+	}
+	if pitID != "" {
+		defer func() {
+			if nextPageToken != "" {
+				return
+			}
+			if err := s.client().ClosePointInTime(context.WithoutCancel(ctx), pitID); err != nil {
+				s.logger.Warn("failed to close the point in time", zap.Error(es.DetailedError(err)))
+			}
+		}()
+	}
+
+	// trace IDs are ordered by the most recent span, then by trace ID, so the traces after the cursor
+	// have no span after it: the spans up to the cursor are searched, and the traces of the previous
+	// pages having older spans are left out. While they leave the page incomplete, more buckets are
+	// searched, one extra bucket telling whether there is a next page.
+	pageQuery := *traceQuery
+	if cursor != nil && cursor.StartTime.Before(pageQuery.StartTimeMax) {
+		pageQuery.StartTimeMax = cursor.StartTime
+	}
+	var buckets []*elastic.AggregationBucketKeyItem
+	for size := traceQuery.NumTraces + 1; ; size *= 2 {
+		found, err := s.findTraceIDs(ctx, &pageQuery, size, pitID)
+		if err != nil {
+			return nil, "", err
+		}
+		buckets, err = s.bucketsAfterCursor(ctx, traceQuery, cursor, found, pitID)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(buckets) > traceQuery.NumTraces || len(found) < size {
+			break
+		}
+	}
+
+	if len(buckets) > traceQuery.NumTraces {
+		buckets = buckets[:traceQuery.NumTraces]
+		if paginate {
+			nextPageToken, err = encodeTracesPageToken(pitID, buckets[len(buckets)-1])
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	esTraceIDs, err := bucketToStringArray(buckets)
+	if err != nil {
+		return nil, "", err
+	}
+	traceIDs, err := convertTraceIDsStringsToModels(esTraceIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	return traceIDs, nextPageToken, nil
+}
+
+// tracesPageToken is the content of the continuation token of a page of traces.
+type tracesPageToken struct {
+	// PointInTime is the ID of the point in time the pages are searched in, if any.
+	PointInTime string `json:"pit,omitempty"`
+	// SearchAfter holds the sort values of the last trace of the previous page: the start
+	// time of its most recent span in microseconds, and its trace ID.
+	SearchAfter []any `json:"search_after"`
+}
+
+// encodeTracesPageToken returns the continuation token of the page after the trace of the bucket.
+func encodeTracesPageToken(pitID string, bucket *elastic.AggregationBucketKeyItem) (string, error) {
+	startTime, err := bucketStartTime(bucket)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(tracesPageToken{
+		PointInTime: pitID,
+		SearchAfter: []any{model.TimeAsEpochMicroseconds(startTime), bucket.Key},
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeTracesPageToken returns the point in time and the cursor of the continuation token,
+// which are empty for the first page.
+func decodeTracesPageToken(token string) (string, *spanstore.TraceCursor, error) {
+	if token == "" {
+		return "", nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", nil, spanstore.ErrInvalidPageToken
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var t tracesPageToken
+	if err := decoder.Decode(&t); err != nil || len(t.SearchAfter) != 2 {
+		return "", nil, spanstore.ErrInvalidPageToken
+	}
+	startTime, ok := t.SearchAfter[0].(json.Number)
+	if !ok {
+		return "", nil, spanstore.ErrInvalidPageToken
+	}
+	micros, err := strconv.ParseUint(startTime.String(), 10, 64)
+	if err != nil {
+		return "", nil, spanstore.ErrInvalidPageToken
+	}
+	key, ok := t.SearchAfter[1].(string)
+	if !ok {
+		return "", nil, spanstore.ErrInvalidPageToken
+	}
+	traceID, err := model.TraceIDFromString(key)
+	if err != nil {
+		return "", nil, spanstore.ErrInvalidPageToken
+	}
+	return t.PointInTime, &spanstore.TraceCursor{StartTime: model.EpochMicrosecondsAsTime(micros), TraceID: traceID}, nil
+}
+
+// bucketsAfterCursor returns the buckets of the traces after the cursor among the buckets of the traces
+// found up to its start time, leaving out the traces of the previous pages: those ordered before the
+// cursor at its start time, and those having spans matching the query after it.
+func (s *SpanReader) bucketsAfterCursor(
+	ctx context.Context,
+	traceQuery *spanstore.TraceQueryParameters,
+	cursor *spanstore.TraceCursor,
+	buckets []*elastic.AggregationBucketKeyItem,
+	pitID string,
+) ([]*elastic.AggregationBucketKeyItem, error) {
+	if cursor == nil || len(buckets) == 0 {
+		return buckets, nil
+	}
+	candidates := make([]*elastic.AggregationBucketKeyItem, 0, len(buckets))
+	keys := make([]any, 0, len(buckets))
+	for _, bucket := range buckets {
+		startTime, err := bucketStartTime(bucket)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := bucket.Key.(string)
+		if !ok {
+			return nil, errors.New("non-string key found in aggregation")
+		}
+		traceID, err := model.TraceIDFromString(key)
+		if err != nil {
+			return nil, fmt.Errorf("making traceID from string '%s' failed: %w", key, err)
+		}
+		// the trace IDs with leading zeros are ordered like their values
+		if startTime.Equal(cursor.StartTime) && (traceID.High < cursor.TraceID.High ||
+			traceID.High == cursor.TraceID.High && traceID.Low <= cursor.TraceID.Low) {
+			continue
+		}
+		candidates = append(candidates, bucket)
+		keys = append(keys, key)
+	}
+	if len(candidates) == 0 || !cursor.StartTime.Before(traceQuery.StartTimeMax) {
+		return candidates, nil
+	}
 
-	esTraceIDs, err := s.findTraceIDs(ctx, traceQuery)
+	laterQuery := *traceQuery
+	laterQuery.StartTimeMin = cursor.StartTime.Add(time.Microsecond)
+	boolQuery := elastic.NewBoolQuery().Must(
+		s.buildFindTraceIDsQuery(&laterQuery),
+		elastic.NewTermsQuery(traceIDField, keys...),
+	)
+	jaegerIndices := s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, laterQuery.StartTimeMin, laterQuery.StartTimeMax, s.spanIndexRolloverFrequency)
+	aggregation := elastic.NewTermsAggregation().Field(traceIDField).Size(len(keys))
+	searchResult, err := s.searchTraceIDs(ctx, jaegerIndices, traceQuery.ServiceName, pitID, boolQuery, aggregation)
+	if err != nil {
+		return nil, fmt.Errorf("search the traces of the previous pages failed: %w", es.DetailedError(err))
+	}
+	if searchResult.Aggregations == nil {
+		return candidates, nil
+	}
+	bucket, found := searchResult.Aggregations.Terms(traceIDAggregation)
+	if !found {
+		return nil, ErrUnableToFindTraceIDAggregation
+	}
+	previous, err := bucketToStringArray(bucket.Buckets)
 	if err != nil {
 		return nil, err
 	}
+	return slices.DeleteFunc(candidates, func(b *elastic.AggregationBucketKeyItem) bool {
+		return slices.Contains(previous, b.Key.(string))
+	}), nil
+}
+
+// bucketStartTime returns the start time of the most recent span of the trace of a bucket of the trace ID aggregation.
+func bucketStartTime(bucket *elastic.AggregationBucketKeyItem) (time.Time, error) {
+	maxStartTime, found := bucket.Max(startTimeField)
+	if !found || maxStartTime.Value == nil {
+		return time.Time{}, ErrUnableToFindTraceIDAggregation
+	}
+	return model.EpochMicrosecondsAsTime(uint64(*maxStartTime.Value)), nil
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader using a wildcard query on the trace IDs.
+func (s *SpanReader) FindTraceIDsByPrefix(ctx context.Context, query *spanstore.TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	ctx, span := s.tracer.Start(ctx, "FindTraceIDsByPrefix")
+	defer span.End()
+
+	if query.StartTimeMin.IsZero() || query.StartTimeMax.IsZero() {
+		return nil, ErrStartAndEndTimeNotSet
+	}
+	numTraces := query.NumTraces
+	if numTraces <= 0 {
+		numTraces = defaultNumTraces
+	}
+	boolQuery := elastic.NewBoolQuery().Must(
+		s.buildStartTimeQuery(query.StartTimeMin, query.StartTimeMax),
+		s.buildTraceIDPrefixQuery(query.Prefix),
+	)
+	jaegerIndices := s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, query.StartTimeMin, query.StartTimeMax, s.spanIndexRolloverFrequency)
 
+	searchResult, err := s.searchService(jaegerIndices, "").
+		Size(0). // set to 0 because we don't want actual documents.
+		Aggregation(traceIDAggregation, s.buildTraceIDAggregation(numTraces)).
+		IgnoreUnavailable(true).
+		Query(boolQuery).
+		Do(ctx)
+	if err != nil {
+		err = es.DetailedError(err)
+		logErrorToSpan(span, err)
+		return nil, fmt.Errorf("search trace IDs by prefix failed: %w", err)
+	}
+	if searchResult.Aggregations == nil {
+		return []model.TraceID{}, nil
+	}
+	bucket, found := searchResult.Aggregations.Terms(traceIDAggregation)
+	if !found {
+		return nil, ErrUnableToFindTraceIDAggregation
+	}
+	esTraceIDs, err := bucketToStringArray(bucket.Buckets)
+	if err != nil {
+		return nil, err
+	}
 	return convertTraceIDsStringsToModels(esTraceIDs)
 }
 
+func (*SpanReader) buildTraceIDPrefixQuery(prefix string) elastic.Query {
+	// the prefix is validated as hexadecimal, without wildcard characters
+	return elastic.NewWildcardQuery(traceIDField, prefix+"*")
+}
+
+// GetLatencyDistribution implements spanstore.LatencyReader using percentiles and range aggregations of span durations.
+func (s *SpanReader) GetLatencyDistribution(
+	ctx context.Context,
+	query *spanstore.LatencyQueryParameters,
+) (*spanstore.LatencyDistribution, error) {
+	ctx, span := s.tracer.Start(ctx, "GetLatencyDistribution")
+	defer span.End()
+
+	if query.ServiceName == "" {
+		return nil, ErrServiceNameNotSet
+	}
+	boolQuery := elastic.NewBoolQuery().Must(
+		s.buildServiceNameQuery(query.ServiceName),
+		s.buildStartTimeQuery(query.StartTimeMin, query.StartTimeMax),
+	)
+	if query.OperationName != "" {
+		boolQuery.Must(s.buildOperationNameQuery(query.OperationName))
+	}
+	for k, v := range query.Tags {
+		boolQuery.Must(s.buildTagQuery(k, v))
+	}
+	bounds := query.GetBucketBounds()
+	jaegerIndices := s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, query.StartTimeMin, query.StartTimeMax, s.spanIndexRolloverFrequency)
+
+	searchResult, err := s.searchService(jaegerIndices, query.ServiceName).
+		Size(0). // set to 0 because we don't want actual documents.
+		Aggregation(percentilesAggregation, s.buildPercentilesAggregation()).
+		Aggregation(histogramAggregation, s.buildHistogramAggregation(bounds)).
+		IgnoreUnavailable(true).
+		Query(boolQuery).
+		Do(ctx)
+	if err != nil {
+		err = es.DetailedError(err)
+		logErrorToSpan(span, err)
+		return nil, fmt.Errorf("search span durations failed: %w", err)
+	}
+	if searchResult.Aggregations == nil {
+		return nil, ErrUnableToFindLatencyAggregation
+	}
+	percentiles, found := searchResult.Aggregations.Percentiles(percentilesAggregation)
+	if !found {
+		return nil, ErrUnableToFindLatencyAggregation
+	}
+	histogram, found := searchResult.Aggregations.Range(histogramAggregation)
+	if !found || len(histogram.Buckets) != len(bounds)+1 {
+		return nil, ErrUnableToFindLatencyAggregation
+	}
+	dist := &spanstore.LatencyDistribution{
+		P50:          percentileToDuration(percentiles, 50),
+		P95:          percentileToDuration(percentiles, 95),
+		P99:          percentileToDuration(percentiles, 99),
+		BucketBounds: bounds,
+		BucketCounts: make([]int64, len(histogram.Buckets)),
+	}
+	for i, bucket := range histogram.Buckets {
+		dist.BucketCounts[i] = bucket.DocCount
+		dist.Count += bucket.DocCount
+	}
+	return dist, nil
+}
+
+func (*SpanReader) buildPercentilesAggregation() elastic.Aggregation {
+	return elastic.NewPercentilesAggregation().
+		Field(durationField).
+		Percentiles(50, 95, 99)
+}
+
+func (*SpanReader) buildHistogramAggregation(bounds []time.Duration) elastic.Aggregation {
+	aggregation := elastic.NewRangeAggregation().Field(durationField)
+	var from any
+	for _, bound := range bounds {
+		to := bound.Microseconds()
+		aggregation.AddRange(from, to)
+		from = to
+	}
+	return aggregation.AddUnboundedTo(from)
+}
+
+// percentileToDuration converts a percentile of span durations in microseconds to time.Duration.
+func percentileToDuration(percentiles *elastic.AggregationPercentilesMetric, p float64) time.Duration {
+	micros := percentiles.Values[strconv.FormatFloat(p, 'f', 1, 64)]
+	return time.Duration(micros * float64(time.Microsecond))
+}
+
 func (s *SpanReader) multiRead(ctx context.Context, traceIDs []model.TraceID, startTime, endTime time.Time) ([]*model.Trace, error) {
 	ctx, childSpan := s.tracer.Start(ctx, "multiRead")
 	defer childSpan.End()
@@ -381,6 +768,9 @@ func (s *SpanReader) multiRead(ctx context.Context, traceIDs []model.TraceID, st
 	// Add an hour in both directions so that traces that straddle two indexes are retrieved.
 	// i.e starts in one and ends in another.
 	indices := s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, startTime.Add(-time.Hour), endTime.Add(time.Hour), s.spanIndexRolloverFrequency)
+	if s.usePointInTime {
+		return s.multiReadWithPointInTime(ctx, childSpan, traceIDs, indices, startTime, endTime)
+	}
 	nextTime := model.TimeAsEpochMicroseconds(startTime.Add(-time.Hour))
 	searchAfterTime := make(map[model.TraceID]uint64)
 	totalDocumentsFetched := make(map[model.TraceID]int)
@@ -391,14 +781,7 @@ func (s *SpanReader) multiRead(ctx context.Context, traceIDs []model.TraceID, st
 		}
 		searchRequests := make([]*elastic.SearchRequest, len(traceIDs))
 		for i, traceID := range traceIDs {
-			traceQuery := buildTraceByIDQuery(traceID)
-			query := elastic.NewBoolQuery().
-				Must(traceQuery)
-			if s.useReadWriteAliases {
-				startTimeRangeQuery := s.buildStartTimeQuery(startTime.Add(-time.Hour*24), endTime.Add(time.Hour*24))
-				query = query.Must(startTimeRangeQuery)
-			}
-
+			query := s.buildMultiReadQuery(traceID, startTime, endTime)
 			if val, ok := searchAfterTime[traceID]; ok {
 				nextTime = val
 			}
@@ -454,6 +837,101 @@ func (s *SpanReader) multiRead(ctx context.Context, traceIDs []model.TraceID, st
 	return traces, nil
 }
 
+// multiReadWithPointInTime reads the traces like multiRead from a point in time of the indices,
+// the pages of the spans of each trace resuming after the sort values of the last span read, which
+// Elasticsearch breaks the ties of with the position of the span document in the point in time.
+func (s *SpanReader) multiReadWithPointInTime(ctx context.Context, span trace.Span, traceIDs []model.TraceID, indices []string, startTime, endTime time.Time) ([]*model.Trace, error) {
+	pitID, err := s.client().OpenPointInTime(ctx, pointInTimeKeepAlive, indices...)
+	if err != nil {
+		err = es.DetailedError(err)
+		logErrorToSpan(span, err)
+		return nil, fmt.Errorf("open point in time failed: %w", err)
+	}
+	defer func() {
+		if err := s.client().ClosePointInTime(context.WithoutCancel(ctx), pitID); err != nil {
+			s.logger.Warn("failed to close the point in time", zap.Error(es.DetailedError(err)))
+		}
+	}()
+
+	searchAfter := make(map[model.TraceID][]any)
+	tracesMap := make(map[model.TraceID]*model.Trace)
+	for len(traceIDs) > 0 {
+		searchRequests := make([]*elastic.SearchRequest, len(traceIDs))
+		for i, traceID := range traceIDs {
+			source, err := s.pointInTimeSource(s.buildMultiReadQuery(traceID, startTime, endTime), pitID, searchAfter[traceID])
+			if err != nil {
+				return nil, err
+			}
+			// the indices and their options are those of the point in time
+			searchRequests[i] = elastic.NewSearchRequest().Source(source)
+		}
+		traceIDs = nil
+		results, err := s.client().MultiSearch().Add(searchRequests...).Do(ctx)
+		if err != nil {
+			err = es.DetailedError(err)
+			logErrorToSpan(span, err)
+			return nil, err
+		}
+		for _, result := range results.Responses {
+			if result.Hits == nil || len(result.Hits.Hits) == 0 {
+				continue
+			}
+			spans, err := s.collectSpans(result.Hits.Hits)
+			if err != nil {
+				err = es.DetailedError(err)
+				logErrorToSpan(span, err)
+				return nil, err
+			}
+			traceID := spans[len(spans)-1].TraceID
+			if traceSpan, ok := tracesMap[traceID]; ok {
+				traceSpan.Spans = append(traceSpan.Spans, spans...)
+			} else {
+				tracesMap[traceID] = &model.Trace{Spans: spans}
+			}
+			// a full page may be followed by other spans
+			if len(result.Hits.Hits) == s.maxDocCount {
+				traceIDs = append(traceIDs, traceID)
+				searchAfter[traceID] = result.Hits.Hits[len(result.Hits.Hits)-1].Sort
+			}
+		}
+	}
+
+	traces := make([]*model.Trace, 0, len(tracesMap))
+	for _, t := range tracesMap {
+		traces = append(traces, t)
+	}
+	return traces, nil
+}
+
+// pointInTimeSource returns the body of a search of the spans sorted by start time in the point in time,
+// after the sort values of the previous page if any.
+func (s *SpanReader) pointInTimeSource(query elastic.Query, pitID string, searchAfter []any) (map[string]any, error) {
+	src, err := elastic.NewSearchSource().
+		Query(query).
+		Size(s.maxDocCount).
+		Sort("startTime", true).
+		Source()
+	if err != nil {
+		return nil, err
+	}
+	source := src.(map[string]any)
+	source["pit"] = map[string]any{"id": pitID, "keep_alive": pointInTimeKeepAlive}
+	if len(searchAfter) > 0 {
+		source["search_after"] = searchAfter
+	}
+	return source, nil
+}
+
+func (s *SpanReader) buildMultiReadQuery(traceID model.TraceID, startTime, endTime time.Time) elastic.Query {
+	query := elastic.NewBoolQuery().
+		Must(buildTraceByIDQuery(traceID))
+	if s.useReadWriteAliases {
+		startTimeRangeQuery := s.buildStartTimeQuery(startTime.Add(-time.Hour*24), endTime.Add(time.Hour*24))
+		query = query.Must(startTimeRangeQuery)
+	}
+	return query
+}
+
 func buildTraceByIDQuery(traceID model.TraceID) elastic.Query {
 	traceIDStr := traceID.String()
 	if traceIDStr[0] != '0' {
@@ -499,7 +977,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
 		return ErrMalformedRequestObject
 	}
-	if p.ServiceName == "" && len(p.Tags) > 0 {
+	if p.ServiceName == "" && (len(p.Tags) > 0 || len(p.TagFilters) > 0) {
 		return ErrServiceNameNotSet
 	}
 	if p.StartTimeMin.IsZero() || p.StartTimeMax.IsZero() {
@@ -511,10 +989,15 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p.DurationMin != 0 && p.DurationMax != 0 && p.DurationMin > p.DurationMax {
 		return ErrDurationMinGreaterThanMax
 	}
+	for _, filter := range p.TagFilters {
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *SpanReader) findTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]string, error) {
+func (s *SpanReader) findTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters, numOfTraces int, pitID string) ([]*elastic.AggregationBucketKeyItem, error) {
 	ctx, childSpan := s.tracer.Start(ctx, "findTraceIDs")
 	defer childSpan.End()
 	//  Below is the JSON body to our HTTP GET request to ElasticSearch. This function creates this.
@@ -570,32 +1053,69 @@ func (s *SpanReader) findTraceIDs(ctx context.Context, traceQuery *spanstore.Tra
 	//      },
 	//      "aggs": { "traceIDs" : { "terms" : {"size": 100,"field": "traceID" }}}
 	//  }
-	aggregation := s.buildTraceIDAggregation(traceQuery.NumTraces)
+	aggregation := s.buildTraceIDAggregation(numOfTraces)
 	boolQuery := s.buildFindTraceIDsQuery(traceQuery)
 	jaegerIndices := s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, traceQuery.StartTimeMin, traceQuery.StartTimeMax, s.spanIndexRolloverFrequency)
 
-	searchService := s.client().Search(jaegerIndices...).
-		Size(0). // set to 0 because we don't want actual documents.
-		Aggregation(traceIDAggregation, aggregation).
-		IgnoreUnavailable(true).
-		Query(boolQuery)
-
-	searchResult, err := searchService.Do(ctx)
+	searchResult, err := s.searchTraceIDs(ctx, jaegerIndices, traceQuery.ServiceName, pitID, boolQuery, aggregation)
 	if err != nil {
 		err = es.DetailedError(err)
 		s.logger.Info("es search services failed", zap.Any("traceQuery", traceQuery), zap.Error(err))
 		return nil, fmt.Errorf("search services failed: %w", err)
 	}
 	if searchResult.Aggregations == nil {
-		return []string{}, nil
+		return []*elastic.AggregationBucketKeyItem{}, nil
 	}
 	bucket, found := searchResult.Aggregations.Terms(traceIDAggregation)
 	if !found {
 		return nil, ErrUnableToFindTraceIDAggregation
 	}
+	return bucket.Buckets, nil
+}
 
-	traceIDBuckets := bucket.Buckets
-	return bucketToStringArray(traceIDBuckets)
+// searchTraceIDs searches the spans matching the query with the trace ID aggregation, in the point in time
+// if any, whose indices and options apply, or else in the indices, with an async search if configured.
+func (s *SpanReader) searchTraceIDs(
+	ctx context.Context,
+	indices []string,
+	serviceName string,
+	pitID string,
+	query elastic.Query,
+	aggregation elastic.Aggregation,
+) (*elastic.SearchResult, error) {
+	searchSource := elastic.NewSearchSource().
+		Size(0). // set to 0 because we don't want actual documents.
+		Aggregation(traceIDAggregation, aggregation).
+		Query(query)
+	switch {
+	case pitID != "":
+		src, err := searchSource.Source()
+		if err != nil {
+			return nil, err
+		}
+		source := src.(map[string]any)
+		source["pit"] = map[string]any{"id": pitID, "keep_alive": pointInTimeKeepAlive}
+		results, err := s.client().MultiSearch().Add(elastic.NewSearchRequest().Source(source)).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(results.Responses) == 0 {
+			return &elastic.SearchResult{}, nil
+		}
+		if result := results.Responses[0]; result.Error != nil {
+			return nil, fmt.Errorf("%s: %s", result.Error.Type, result.Error.Reason)
+		}
+		return results.Responses[0], nil
+	case s.asyncSearchTimeout > 0:
+		return s.asyncSearch(indices, serviceName).Source(searchSource).Do(ctx)
+	default:
+		return s.searchService(indices, serviceName).
+			Size(0). // set to 0 because we don't want actual documents.
+			Aggregation(traceIDAggregation, aggregation).
+			IgnoreUnavailable(true).
+			Query(query).
+			Do(ctx)
+	}
 }
 
 func (s *SpanReader) buildTraceIDAggregation(numOfTraces int) elastic.Aggregation {
@@ -603,6 +1123,7 @@ func (s *SpanReader) buildTraceIDAggregation(numOfTraces int) elastic.Aggregatio
 		Size(numOfTraces).
 		Field(traceIDField).
 		Order(startTimeField, false).
+		OrderByKeyAsc().
 		SubAggregation(startTimeField, s.buildTraceIDSubAggregation())
 }
 
@@ -640,9 +1161,38 @@ func (s *SpanReader) buildFindTraceIDsQuery(traceQuery *spanstore.TraceQueryPara
 		tagQuery := s.buildTagQuery(k, v)
 		boolQuery.Must(tagQuery)
 	}
+
+	for _, filter := range traceQuery.TagFilters {
+		boolQuery.Must(s.buildTagFilterQuery(filter))
+	}
+
+	// add queries of the resource attributes, in a deterministic order
+	resourceKeys := make([]string, 0, len(traceQuery.ResourceAttributes))
+	for k := range traceQuery.ResourceAttributes {
+		resourceKeys = append(resourceKeys, k)
+	}
+	sort.Strings(resourceKeys)
+	for _, k := range resourceKeys {
+		boolQuery.Must(s.buildResourceQuery(k, traceQuery.ResourceAttributes[k]))
+	}
+
+	// add query for fields of a single log
+	if len(traceQuery.LogFields) > 0 {
+		boolQuery.Must(s.buildLogFieldsQuery(traceQuery.LogFields))
+	}
 	return boolQuery
 }
 
+// buildResourceQuery matches the dedicated field of a searchable resource attribute,
+// or the process tags for the other attributes.
+func (s *SpanReader) buildResourceQuery(key string, value string) elastic.Query {
+	field, ok := dbmodel.ResourceFields[key]
+	if !ok {
+		return s.buildTagQuery(key, value)
+	}
+	return elastic.NewTermQuery(processResourceField+"."+field, value)
+}
+
 func (*SpanReader) buildDurationQuery(durationMin time.Duration, durationMax time.Duration) elastic.Query {
 	minDurationMicros := model.DurationAsMicroseconds(durationMin)
 	maxDurationMicros := defaultMaxDuration
@@ -661,6 +1211,24 @@ func (*SpanReader) buildStartTimeQuery(startTimeMin time.Time, startTimeMax time
 	return elastic.NewRangeQuery(startTimeMillisField).Gte(minStartTimeMicros / 1000).Lte(maxStartTimeMicros / 1000)
 }
 
+// searchService returns a search of the indices, routed to the shard of the service when enabled.
+func (s *SpanReader) searchService(indices []string, serviceName string) es.SearchService {
+	searchService := s.client().Search(indices...)
+	if s.routeByService && serviceName != "" {
+		searchService = searchService.Routing(serviceName)
+	}
+	return searchService
+}
+
+// asyncSearch returns an async search of the indices like searchService, the missing indices being ignored.
+func (s *SpanReader) asyncSearch(indices []string, serviceName string) es.AsyncSearchService {
+	asyncSearch := s.client().AsyncSearch(indices...).WaitForCompletionTimeout(s.asyncSearchTimeout)
+	if s.routeByService && serviceName != "" {
+		asyncSearch = asyncSearch.Routing(serviceName)
+	}
+	return asyncSearch
+}
+
 func (*SpanReader) buildServiceNameQuery(serviceName string) elastic.Query {
 	return elastic.NewMatchQuery(serviceNameField, serviceName)
 }
@@ -684,6 +1252,56 @@ func (s *SpanReader) buildTagQuery(k string, v string) elastic.Query {
 	return elastic.NewBoolQuery().Should(queries...)
 }
 
+// buildTagFilterQuery matches the spans with a tag, a process tag or a log field matching the filter,
+// in the object or nested fields like buildTagQuery.
+func (s *SpanReader) buildTagFilterQuery(filter spanstore.TagFilter) elastic.Query {
+	objectTagListLen := len(objectTagFieldList)
+	queries := make([]elastic.Query, len(nestedTagFieldList)+objectTagListLen)
+	kd := s.spanConverter.ReplaceDot(filter.Key)
+	for i := range objectTagFieldList {
+		queries[i] = s.buildValueFilterQuery(fmt.Sprintf("%s.%s", objectTagFieldList[i], kd), filter)
+	}
+	for i, field := range nestedTagFieldList {
+		keyQuery := elastic.NewMatchQuery(fmt.Sprintf("%s.%s", field, tagKeyField), filter.Key)
+		tagBoolQuery := elastic.NewBoolQuery().Must(keyQuery)
+		if filter.Operator != spanstore.TagFilterExists {
+			tagBoolQuery.Must(s.buildValueFilterQuery(fmt.Sprintf("%s.%s", field, tagValueField), filter))
+		}
+		queries[i+objectTagListLen] = elastic.NewNestedQuery(field, tagBoolQuery)
+	}
+	return elastic.NewBoolQuery().Should(queries...)
+}
+
+// buildValueFilterQuery matches the values of the field with the operator of the filter.
+func (*SpanReader) buildValueFilterQuery(field string, filter spanstore.TagFilter) elastic.Query {
+	switch filter.Operator {
+	case spanstore.TagFilterExists:
+		return elastic.NewExistsQuery(field)
+	case spanstore.TagFilterNotEqual:
+		return elastic.NewBoolQuery().Must(elastic.NewExistsQuery(field)).MustNot(elastic.NewTermQuery(field, filter.Value))
+	default:
+		// the filter is validated by validateQuery
+		value, _ := strconv.ParseFloat(filter.Value, 64)
+		script := elastic.NewScript(fmt.Sprintf(tagFilterScript, filter.Operator)).
+			Params(map[string]any{"field": field, "value": value})
+		return elastic.NewScriptQuery(script)
+	}
+}
+
+// buildLogFieldsQuery matches spans with a log that contains all given fields.
+func (s *SpanReader) buildLogFieldsQuery(fields map[string]string) elastic.Query {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fieldQueries := make([]elastic.Query, len(keys))
+	for i, k := range keys {
+		fieldQueries[i] = s.buildNestedQuery(nestedLogFieldsField, k, fields[k])
+	}
+	return elastic.NewNestedQuery(nestedLogsField, elastic.NewBoolQuery().Must(fieldQueries...))
+}
+
 func (*SpanReader) buildNestedQuery(field string, k string, v string) elastic.Query {
 	keyField := fmt.Sprintf("%s.%s", field, tagKeyField)
 	valueField := fmt.Sprintf("%s.%s", field, tagValueField)
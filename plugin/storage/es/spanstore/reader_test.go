@@ -17,11 +17,13 @@ package spanstore
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -423,6 +425,79 @@ func TestSpanReader_multiRead_followUp_query(t *testing.T) {
 	})
 }
 
+func TestSpanReader_multiReadWithPointInTime(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.reader.maxDocCount = 2
+		date := time.Date(2019, 10, 10, 5, 0, 0, 0, time.UTC)
+		startTime := model.TimeAsEpochMicroseconds(date)
+		hit := func(spanID string, shardDoc int) *elastic.SearchHit {
+			span, err := json.Marshal(dbmodel.Span{SpanID: dbmodel.SpanID(spanID), TraceID: "1", StartTime: startTime})
+			require.NoError(t, err)
+			return &elastic.SearchHit{Source: (*json.RawMessage)(&span), Sort: []any{float64(startTime), float64(shardDoc)}}
+		}
+		searchBody := func(searchAfter string) func(*elastic.SearchRequest) bool {
+			return func(request *elastic.SearchRequest) bool {
+				body, err := request.Body()
+				return err == nil && strings.Contains(body, `"pit":{"id":"pit-id","keep_alive":"1m"}`) &&
+					strings.Contains(body, `"search_after"`) == (searchAfter != "") &&
+					strings.Contains(body, searchAfter)
+			}
+		}
+
+		r.client.On("OpenPointInTime", mock.Anything, pointInTimeKeepAlive, mock.AnythingOfType("string")).Return("pit-id", nil)
+		r.client.On("ClosePointInTime", mock.Anything, "pit-id").Return(nil)
+		multiSearchService := &mocks.MultiSearchService{}
+		firstMultiSearch := &mocks.MultiSearchService{}
+		secondMultiSearch := &mocks.MultiSearchService{}
+		// the searches of the point in time have no indices
+		multiSearchService.On("Add", mock.MatchedBy(searchBody(""))).Return(firstMultiSearch)
+		multiSearchService.On("Add", mock.MatchedBy(searchBody(fmt.Sprintf(`"search_after":[%d,2]`, startTime)))).Return(secondMultiSearch)
+		r.client.On("MultiSearch").Return(multiSearchService)
+		// the spans starting at the same time are all read, the full page being followed by another one
+		firstMultiSearch.On("Do", mock.Anything).Return(&elastic.MultiSearchResult{
+			Responses: []*elastic.SearchResult{{Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{hit("1", 1), hit("2", 2)}}}},
+		}, nil)
+		secondMultiSearch.On("Do", mock.Anything).Return(&elastic.MultiSearchResult{
+			Responses: []*elastic.SearchResult{{Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{hit("3", 3)}}}},
+		}, nil)
+
+		traces, err := r.reader.multiRead(context.Background(), []model.TraceID{model.NewTraceID(0, 1)}, date, date)
+		require.NoError(t, err)
+		require.Len(t, traces, 1)
+		var spanIDs []model.SpanID
+		for _, span := range traces[0].Spans {
+			spanIDs = append(spanIDs, span.SpanID)
+		}
+		assert.Equal(t, []model.SpanID{1, 2, 3}, spanIDs)
+		r.client.AssertCalled(t, "ClosePointInTime", mock.Anything, "pit-id")
+	})
+}
+
+func TestSpanReader_multiReadWithPointInTimeErrors(t *testing.T) {
+	date := time.Date(2019, 10, 10, 5, 0, 0, 0, time.UTC)
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.client.On("OpenPointInTime", mock.Anything, pointInTimeKeepAlive, mock.AnythingOfType("string")).Return("", errors.New("pit error"))
+
+		_, err := r.reader.multiRead(context.Background(), []model.TraceID{model.NewTraceID(0, 1)}, date, date)
+		require.EqualError(t, err, "open point in time failed: pit error")
+	})
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.client.On("OpenPointInTime", mock.Anything, pointInTimeKeepAlive, mock.AnythingOfType("string")).Return("pit-id", nil)
+		r.client.On("ClosePointInTime", mock.Anything, "pit-id").Return(errors.New("close error"))
+		multiSearchService := &mocks.MultiSearchService{}
+		multiSearchService.On("Add", mock.Anything).Return(multiSearchService)
+		multiSearchService.On("Do", mock.Anything).Return(nil, errors.New("search error"))
+		r.client.On("MultiSearch").Return(multiSearchService)
+
+		_, err := r.reader.multiRead(context.Background(), []model.TraceID{model.NewTraceID(0, 1)}, date, date)
+		require.EqualError(t, err, "search error")
+		r.client.AssertCalled(t, "ClosePointInTime", mock.Anything, "pit-id")
+	})
+}
+
 func TestSpanReader_SearchAfter(t *testing.T) {
 	withSpanReader(t, func(r *spanReaderTest) {
 		var hits []*elastic.SearchHit
@@ -696,7 +771,11 @@ func returnSearchFunc(typ string, r *spanReaderTest) (any, error) {
 			spanstore.OperationQueryParameters{ServiceName: "someService"},
 		)
 	case traceIDAggregation:
-		return r.reader.findTraceIDs(context.Background(), &spanstore.TraceQueryParameters{})
+		buckets, err := r.reader.findTraceIDs(context.Background(), &spanstore.TraceQueryParameters{}, defaultNumTraces, "")
+		if err != nil {
+			return nil, err
+		}
+		return bucketToStringArray(buckets)
 	}
 	return nil, errors.New("Specify services, operations, traceIDs only")
 }
@@ -729,7 +808,8 @@ func TestSpanReader_bucketToStringArrayError(t *testing.T) {
 
 func TestSpanReader_FindTraces(t *testing.T) {
 	goodAggregations := make(map[string]*json.RawMessage)
-	rawMessage := []byte(`{"buckets": [{"key": "1","doc_count": 16},{"key": "2","doc_count": 16},{"key": "3","doc_count": 16}]}`)
+	rawMessage := []byte(`{"buckets": [{"key": "1","doc_count": 16,"startTime": {"value": 1485467191639875}},` +
+		`{"key": "2","doc_count": 16,"startTime": {"value": 1485467191639874}},{"key": "3","doc_count": 16,"startTime": {"value": 1485467191639873}}]}`)
 	goodAggregations[traceIDAggregation] = (*json.RawMessage)(&rawMessage)
 
 	hits := make([]*elastic.SearchHit, 1)
@@ -969,6 +1049,315 @@ func TestFindTraceIDs(t *testing.T) {
 	}
 }
 
+// traceIDBucket returns a bucket of the trace ID aggregation, the trace ID having leading zeros.
+func traceIDBucket(traceID string, startTime time.Time) string {
+	return fmt.Sprintf(`{"key": "%016s","doc_count": 16,"startTime": {"value": %d}}`, traceID, model.TimeAsEpochMicroseconds(startTime))
+}
+
+func traceIDAggregations(buckets ...string) *elastic.SearchResult {
+	rawMessage := json.RawMessage(`{"buckets": [` + strings.Join(buckets, ",") + `]}`)
+	return &elastic.SearchResult{Aggregations: elastic.Aggregations{traceIDAggregation: &rawMessage}}
+}
+
+func tracesPageTokenOf(t *testing.T, pitID string, startTime time.Time, traceID string) string {
+	data, err := json.Marshal(tracesPageToken{
+		PointInTime: pitID,
+		SearchAfter: []any{model.TimeAsEpochMicroseconds(startTime), fmt.Sprintf("%016s", traceID)},
+	})
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestSpanReader_FindTraceIDsPagination(t *testing.T) {
+	// the traces 1 to 3 started a second apart, the most recent first
+	startTime := time.Now().Truncate(time.Second)
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockSearchService(r).
+			Return(traceIDAggregations(
+				traceIDBucket("1", startTime),
+				traceIDBucket("2", startTime.Add(-time.Second)),
+				traceIDBucket("3", startTime.Add(-2*time.Second)),
+			), nil)
+
+		traceQuery := &spanstore.TraceQueryParameters{
+			ServiceName:  serviceName,
+			StartTimeMin: startTime.Add(-1 * time.Hour),
+			StartTimeMax: startTime,
+			NumTraces:    2,
+		}
+		traceIDs, nextPageToken, err := r.reader.findTraceIDsPage(context.Background(), traceQuery, true)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}, traceIDs)
+		assert.Equal(t, tracesPageTokenOf(t, "", startTime.Add(-time.Second), "2"), nextPageToken)
+
+		// the token of the next page is only returned by FindTracesPage
+		_, nextPageToken, err = r.reader.findTraceIDsPage(context.Background(), traceQuery, false)
+		require.NoError(t, err)
+		assert.Empty(t, nextPageToken)
+	})
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		// the spans up to the cursor also find the trace 1 of the previous page, which has a span after it, the
+		// trace 2 of the cursor and the trace 0 ordered before it, and the new traces 4 and 5
+		searchService := mockSearchService(r).
+			Return(traceIDAggregations(
+				traceIDBucket("0", startTime.Add(-time.Second)),
+				traceIDBucket("2", startTime.Add(-time.Second)),
+				traceIDBucket("5", startTime.Add(-time.Second)),
+				traceIDBucket("1", startTime.Add(-2*time.Second)),
+				traceIDBucket("3", startTime.Add(-2*time.Second)),
+				traceIDBucket("4", startTime.Add(-3*time.Second)),
+			), nil).Once().Parent
+		searchService.On("Do", mock.Anything).Return(traceIDAggregations(traceIDBucket("1", startTime)), nil).Once()
+
+		traceQuery := &spanstore.TraceQueryParameters{
+			ServiceName:  serviceName,
+			StartTimeMin: startTime.Add(-1 * time.Hour),
+			StartTimeMax: startTime,
+			NumTraces:    2,
+			PageToken:    tracesPageTokenOf(t, "", startTime.Add(-time.Second), "2"),
+		}
+		traceIDs, nextPageToken, err := r.reader.findTraceIDsPage(context.Background(), traceQuery, true)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 5), model.NewTraceID(0, 3)}, traceIDs)
+		assert.Equal(t, tracesPageTokenOf(t, "", startTime.Add(-2*time.Second), "3"), nextPageToken)
+	})
+}
+
+func TestSpanReader_FindTraceIDsPageTokenErrors(t *testing.T) {
+	encode := func(token string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(token))
+	}
+	for _, token := range []string{
+		"invalid token",
+		encode("not json"),
+		// the offset tokens are not cursors
+		spanstore.EncodePageToken(2),
+		encode(`{"search_after":[1]}`),
+		encode(`{"search_after":["1","0000000000000001"]}`),
+		encode(`{"search_after":[-1,"0000000000000001"]}`),
+		encode(`{"search_after":[1,1]}`),
+		encode(`{"search_after":[1,"xyz"]}`),
+	} {
+		withSpanReader(t, func(r *spanReaderTest) {
+			_, err := r.reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+				StartTimeMin: time.Now().Add(-1 * time.Hour),
+				StartTimeMax: time.Now(),
+				PageToken:    token,
+			})
+			require.ErrorIs(t, err, spanstore.ErrInvalidPageToken, token)
+		})
+	}
+}
+
+func TestSpanReader_FindTraceIDsPaginationWithPointInTime(t *testing.T) {
+	startTime := time.Date(2019, 10, 10, 5, 0, 0, 0, time.UTC)
+	traceQuery := func(pageToken string) *spanstore.TraceQueryParameters {
+		return &spanstore.TraceQueryParameters{
+			ServiceName:  serviceName,
+			StartTimeMin: startTime.Add(-1 * time.Hour),
+			StartTimeMax: startTime,
+			NumTraces:    1,
+			PageToken:    pageToken,
+		}
+	}
+	mockPointInTimeSearch := func(r *spanReaderTest, results ...*elastic.SearchResult) {
+		multiSearchService := &mocks.MultiSearchService{}
+		// the searches of the point in time have no indices
+		multiSearchService.On("Add", mock.MatchedBy(func(request *elastic.SearchRequest) bool {
+			body, err := request.Body()
+			return err == nil && strings.Contains(body, `"pit":{"id":"pit-id","keep_alive":"1m"}`)
+		})).Return(multiSearchService)
+		for _, result := range results {
+			multiSearchService.On("Do", mock.Anything).Return(&elastic.MultiSearchResult{
+				Responses: []*elastic.SearchResult{result},
+			}, nil).Once()
+		}
+		r.client.On("MultiSearch").Return(multiSearchService)
+	}
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.client.On("OpenPointInTime", mock.Anything, pointInTimeKeepAlive, mock.AnythingOfType("string")).Return("pit-id", nil)
+		mockPointInTimeSearch(r, traceIDAggregations(
+			traceIDBucket("1", startTime),
+			traceIDBucket("2", startTime.Add(-time.Second)),
+		))
+
+		// the point in time is passed on to the next page
+		traceIDs, nextPageToken, err := r.reader.findTraceIDsPage(context.Background(), traceQuery(""), true)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+		assert.Equal(t, tracesPageTokenOf(t, "pit-id", startTime, "1"), nextPageToken)
+		r.client.AssertNotCalled(t, "ClosePointInTime", mock.Anything, mock.Anything)
+	})
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.client.On("ClosePointInTime", mock.Anything, "pit-id").Return(nil)
+		// the trace of the cursor leaves the page incomplete, so that more buckets are searched
+		page := traceIDAggregations(traceIDBucket("1", startTime), traceIDBucket("2", startTime.Add(-time.Second)))
+		mockPointInTimeSearch(r, page, page)
+
+		// the last page is searched in the point in time of the token, which is then closed
+		traceIDs, nextPageToken, err := r.reader.findTraceIDsPage(context.Background(), traceQuery(tracesPageTokenOf(t, "pit-id", startTime, "1")), true)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2)}, traceIDs)
+		assert.Empty(t, nextPageToken)
+		r.client.AssertNotCalled(t, "OpenPointInTime", mock.Anything, mock.Anything, mock.Anything)
+		r.client.AssertCalled(t, "ClosePointInTime", mock.Anything, "pit-id")
+	})
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		// FindTraceIDs does not open a point in time that no page would use
+		r.reader.usePointInTime = true
+		mockSearchService(r).Return(traceIDAggregations(traceIDBucket("1", startTime), traceIDBucket("2", startTime)), nil)
+
+		traceIDs, err := r.reader.FindTraceIDs(context.Background(), traceQuery(""))
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+		r.client.AssertNotCalled(t, "OpenPointInTime", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSpanReader_FindTraceIDsPaginationWithPointInTimeErrors(t *testing.T) {
+	query := &spanstore.TraceQueryParameters{
+		StartTimeMin: time.Now().Add(-1 * time.Hour),
+		StartTimeMax: time.Now(),
+	}
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.client.On("OpenPointInTime", mock.Anything, pointInTimeKeepAlive, mock.AnythingOfType("string")).Return("", errors.New("pit error"))
+
+		_, err := r.reader.FindTracesPage(context.Background(), query)
+		require.EqualError(t, err, "open point in time failed: pit error")
+	})
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.usePointInTime = true
+		r.client.On("OpenPointInTime", mock.Anything, pointInTimeKeepAlive, mock.AnythingOfType("string")).Return("pit-id", nil)
+		r.client.On("ClosePointInTime", mock.Anything, "pit-id").Return(errors.New("close error"))
+		multiSearchService := &mocks.MultiSearchService{}
+		multiSearchService.On("Add", mock.Anything).Return(multiSearchService)
+		multiSearchService.On("Do", mock.Anything).Return(&elastic.MultiSearchResult{
+			Responses: []*elastic.SearchResult{{Error: &elastic.ErrorDetails{Type: "search_phase_execution_exception", Reason: "expired"}}},
+		}, nil)
+		r.client.On("MultiSearch").Return(multiSearchService)
+
+		// the point in time is closed after an error
+		_, err := r.reader.FindTracesPage(context.Background(), query)
+		require.ErrorContains(t, err, "search_phase_execution_exception: expired")
+		r.client.AssertCalled(t, "ClosePointInTime", mock.Anything, "pit-id")
+	})
+}
+
+func TestSpanReader_FindTraceIDsByPrefix(t *testing.T) {
+	aggregations := make(map[string]*json.RawMessage)
+	rawMessage := []byte(`{"buckets": [{"key": "abcd0001","doc_count": 16},{"key": "abcd0002","doc_count": 16}]}`)
+	aggregations[traceIDAggregation] = (*json.RawMessage)(&rawMessage)
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockSearchService(r).
+			Return(&elastic.SearchResult{Aggregations: elastic.Aggregations(aggregations)}, nil)
+
+		query := &spanstore.TraceIDPrefixQueryParameters{
+			Prefix:       "abcd",
+			StartTimeMin: time.Now().Add(-1 * time.Hour),
+			StartTimeMax: time.Now(),
+		}
+		traceIDs, err := r.reader.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 0xabcd0001), model.NewTraceID(0, 0xabcd0002)}, traceIDs)
+
+		_, err = r.reader.FindTraceIDsByPrefix(context.Background(), &spanstore.TraceIDPrefixQueryParameters{Prefix: "abcd"})
+		require.ErrorIs(t, err, ErrStartAndEndTimeNotSet)
+	})
+}
+
+func TestSpanReader_FindTraceIDsByPrefixError(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockSearchService(r).Return(nil, errors.New("search failure"))
+
+		_, err := r.reader.FindTraceIDsByPrefix(context.Background(), &spanstore.TraceIDPrefixQueryParameters{
+			Prefix:       "abcd",
+			StartTimeMin: time.Now().Add(-1 * time.Hour),
+			StartTimeMax: time.Now(),
+		})
+		require.ErrorContains(t, err, "search trace IDs by prefix failed")
+	})
+}
+
+func TestSpanReader_buildTraceIDPrefixQuery(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		actual, err := r.reader.buildTraceIDPrefixQuery("abcd").Source()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"wildcard": map[string]any{"traceID": map[string]any{"wildcard": "abcd*"}}}, actual)
+	})
+}
+
+func TestSpanReader_FindTraceIDsRouteByService(t *testing.T) {
+	aggregations := make(map[string]*json.RawMessage)
+	rawMessage := []byte(`{"buckets": [{"key": "1","doc_count": 16}]}`)
+	aggregations[traceIDAggregation] = (*json.RawMessage)(&rawMessage)
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.routeByService = true
+		searchService := &mocks.SearchService{}
+		searchService.On("Routing", serviceName).Return(searchService)
+		searchService.On("Query", mock.Anything).Return(searchService)
+		searchService.On("IgnoreUnavailable", mock.AnythingOfType("bool")).Return(searchService)
+		searchService.On("Size", 0).Return(searchService)
+		searchService.On("Aggregation", stringMatcher(traceIDAggregation), mock.AnythingOfType("*elastic.TermsAggregation")).Return(searchService)
+		searchService.On("Do", mock.Anything).Return(&elastic.SearchResult{Aggregations: elastic.Aggregations(aggregations)}, nil)
+		r.client.On("Search", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(searchService)
+
+		traceIDs, err := r.reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  serviceName,
+			StartTimeMin: time.Now().Add(-1 * time.Hour),
+			StartTimeMax: time.Now(),
+			NumTraces:    2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+		searchService.AssertCalled(t, "Routing", serviceName)
+	})
+}
+
+func TestSpanReader_FindTraceIDsAsyncSearch(t *testing.T) {
+	aggregations := make(map[string]*json.RawMessage)
+	rawMessage := []byte(`{"buckets": [{"key": "1","doc_count": 16}]}`)
+	aggregations[traceIDAggregation] = (*json.RawMessage)(&rawMessage)
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		r.reader.asyncSearchTimeout = time.Second
+		r.reader.routeByService = true
+		asyncSearch := &mocks.AsyncSearchService{}
+		asyncSearch.On("WaitForCompletionTimeout", time.Second).Return(asyncSearch)
+		asyncSearch.On("Routing", serviceName).Return(asyncSearch)
+		asyncSearch.On("Source", mock.MatchedBy(func(source *elastic.SearchSource) bool {
+			src, err := source.Source()
+			if err != nil {
+				return false
+			}
+			body := src.(map[string]any)
+			_, hasAggregations := body["aggregations"]
+			return body["size"] == 0 && hasAggregations
+		})).Return(asyncSearch)
+		asyncSearch.On("Do", mock.Anything).Return(&elastic.SearchResult{Aggregations: elastic.Aggregations(aggregations)}, nil)
+		r.client.On("AsyncSearch", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(asyncSearch)
+
+		traceIDs, err := r.reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  serviceName,
+			StartTimeMin: time.Now().Add(-1 * time.Hour),
+			StartTimeMax: time.Now(),
+			NumTraces:    2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+		r.client.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
+
 func TestTraceIDsStringsToModelsConversion(t *testing.T) {
 	traceIDs, err := convertTraceIDsStringsToModels([]string{"1", "2", "3"})
 	require.NoError(t, err)
@@ -1054,15 +1443,26 @@ func TestTraceQueryParameterValidation(t *testing.T) {
 	tqp.DurationMax = time.Minute
 	err = validateQuery(tqp)
 	require.EqualError(t, err, ErrDurationMinGreaterThanMax.Error())
+
+	tqp.DurationMin = 0
+	tqp.TagFilters = []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagFilterGreater, Value: "5xx"}}
+	err = validateQuery(tqp)
+	require.EqualError(t, err, "the tag filter http.status_code > 5xx must compare with a number")
+
+	tqp.ServiceName = ""
+	tqp.Tags = nil
+	err = validateQuery(tqp)
+	require.EqualError(t, err, ErrServiceNameNotSet.Error())
 }
 
 func TestSpanReader_buildTraceIDAggregation(t *testing.T) {
 	expectedStr := `{ "terms":{
             "field":"traceID",
             "size":123,
-            "order":{
-               "startTime":"desc"
-            }
+            "order":[
+               {"startTime":"desc"},
+               {"_key":"asc"}
+            ]
          },
          "aggregations": {
             "startTime" : { "max": {"field": "startTime"}}
@@ -1075,7 +1475,7 @@ func TestSpanReader_buildTraceIDAggregation(t *testing.T) {
 		expected := make(map[string]any)
 		json.Unmarshal([]byte(expectedStr), &expected)
 		expected["terms"].(map[string]any)["size"] = 123
-		expected["terms"].(map[string]any)["order"] = []any{map[string]string{"startTime": "desc"}}
+		expected["terms"].(map[string]any)["order"] = []any{map[string]string{"startTime": "desc"}, map[string]string{"_key": "asc"}}
 		assert.EqualValues(t, expected, actual)
 	})
 }
@@ -1092,6 +1492,14 @@ func TestSpanReader_buildFindTraceIDsQuery(t *testing.T) {
 			Tags: map[string]string{
 				"hello": "world",
 			},
+			LogFields: map[string]string{
+				"event": "exception",
+			},
+			ResourceAttributes: map[string]string{
+				"k8s.namespace.name":     "checkout",
+				"deployment.environment": "prod",
+			},
+			TagFilters: []spanstore.TagFilter{{Key: "error", Operator: spanstore.TagFilterExists}},
 		}
 
 		actualQuery := r.reader.buildFindTraceIDsQuery(traceQuery)
@@ -1104,6 +1512,10 @@ func TestSpanReader_buildFindTraceIDsQuery(t *testing.T) {
 				r.reader.buildServiceNameQuery("s"),
 				r.reader.buildOperationNameQuery("o"),
 				r.reader.buildTagQuery("hello", "world"),
+				r.reader.buildTagFilterQuery(spanstore.TagFilter{Key: "error", Operator: spanstore.TagFilterExists}),
+				r.reader.buildResourceQuery("deployment.environment", "prod"),
+				r.reader.buildResourceQuery("k8s.namespace.name", "checkout"),
+				r.reader.buildLogFieldsQuery(map[string]string{"event": "exception"}),
 			)
 		expected, err := expectedQuery.Source()
 		require.NoError(t, err)
@@ -1175,6 +1587,26 @@ func TestSpanReader_buildServiceNameQuery(t *testing.T) {
 	})
 }
 
+func TestSpanReader_buildResourceQuery(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		actual, err := r.reader.buildResourceQuery("deployment.environment", "prod").Source()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"term": map[string]any{"process.resource.deploymentEnvironment": "prod"}}, actual)
+
+		actual, err = r.reader.buildResourceQuery("cloud.region", "eu").Source()
+		require.NoError(t, err)
+		expected, err := r.reader.buildTagQuery("cloud.region", "eu").Source()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestResourceFields(t *testing.T) {
+	for _, attribute := range spanstore.SearchableResourceAttributes {
+		assert.Contains(t, dbmodel.ResourceFields, attribute)
+	}
+}
+
 func TestSpanReader_buildOperationNameQuery(t *testing.T) {
 	expectedStr := `{ "match": { "operationName": { "query": "spook" }}}`
 	withSpanReader(t, func(r *spanReaderTest) {
@@ -1204,6 +1636,70 @@ func TestSpanReader_buildTagQuery(t *testing.T) {
 	})
 }
 
+func TestSpanReader_buildLogFieldsQuery(t *testing.T) {
+	inStr, err := os.ReadFile("fixtures/query_04.json")
+	require.NoError(t, err)
+	withSpanReader(t, func(r *spanReaderTest) {
+		logFieldsQuery := r.reader.buildLogFieldsQuery(map[string]string{
+			"exception.type": "TimeoutError",
+			"event":          "exception",
+		})
+		actual, err := logFieldsQuery.Source()
+		require.NoError(t, err)
+
+		expected := make(map[string]any)
+		json.Unmarshal(inStr, &expected)
+
+		assert.EqualValues(t, expected, actual)
+	})
+}
+
+func TestSpanReader_buildTagFilterQuery(t *testing.T) {
+	inStr, err := os.ReadFile("fixtures/query_05.json")
+	require.NoError(t, err)
+	withSpanReader(t, func(r *spanReaderTest) {
+		tagFilterQuery := r.reader.buildTagFilterQuery(spanstore.TagFilter{
+			Key:      "http.status_code",
+			Operator: spanstore.TagFilterGreaterOrEqual,
+			Value:    "500",
+		})
+		source, err := tagFilterQuery.Source()
+		require.NoError(t, err)
+		// the source of the scripts is raw JSON
+		actual, err := json.Marshal(source)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, string(inStr), string(actual))
+	})
+}
+
+func TestSpanReader_buildTagFilterQueryExists(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		exists := spanstore.TagFilter{Key: "error", Operator: spanstore.TagFilterExists}
+		actual, err := r.reader.buildTagFilterQuery(exists).Source()
+		require.NoError(t, err)
+		expected, err := elastic.NewBoolQuery().Should(
+			elastic.NewExistsQuery("tag.error"),
+			elastic.NewExistsQuery("process.tag.error"),
+			elastic.NewNestedQuery("tags", elastic.NewBoolQuery().Must(elastic.NewMatchQuery("tags.key", "error"))),
+			elastic.NewNestedQuery("process.tags", elastic.NewBoolQuery().Must(elastic.NewMatchQuery("process.tags.key", "error"))),
+			elastic.NewNestedQuery("logs.fields", elastic.NewBoolQuery().Must(elastic.NewMatchQuery("logs.fields.key", "error"))),
+		).Source()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		notEqual := spanstore.TagFilter{Key: "http.method", Operator: spanstore.TagFilterNotEqual, Value: "GET"}
+		actual, err = r.reader.buildValueFilterQuery("tag.http@method", notEqual).Source()
+		require.NoError(t, err)
+		expected, err = elastic.NewBoolQuery().
+			Must(elastic.NewExistsQuery("tag.http@method")).
+			MustNot(elastic.NewTermQuery("tag.http@method", "GET")).
+			Source()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
 func TestSpanReader_buildTagRegexQuery(t *testing.T) {
 	inStr, err := os.ReadFile("fixtures/query_02.json")
 	require.NoError(t, err)
@@ -1359,3 +1855,104 @@ func TestTerminateAfterNotSet(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, 99, size)
 }
+
+func mockLatencySearchService(r *spanReaderTest) *mock.Call {
+	searchService := &mocks.SearchService{}
+	searchService.On("Query", mock.Anything).Return(searchService)
+	searchService.On("IgnoreUnavailable", true).Return(searchService)
+	searchService.On("Size", 0).Return(searchService)
+	searchService.On("Aggregation", percentilesAggregation, mock.AnythingOfType("*elastic.PercentilesAggregation")).Return(searchService)
+	searchService.On("Aggregation", histogramAggregation, mock.AnythingOfType("*elastic.RangeAggregation")).Return(searchService)
+	r.client.On("Search", mock.AnythingOfType("string")).Return(searchService)
+	return searchService.On("Do", mock.Anything)
+}
+
+func TestSpanReader_GetLatencyDistribution(t *testing.T) {
+	query := &spanstore.LatencyQueryParameters{
+		ServiceName:   serviceName,
+		OperationName: "op",
+		StartTimeMin:  time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		StartTimeMax:  time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+		BucketBounds:  []time.Duration{time.Millisecond, time.Second},
+	}
+	percentiles := []byte(`{"values": {"50.0": 800, "95.0": 25000.5, "99.0": 1500000}}`)
+	histogram := []byte(`{"buckets": [{"to": 1000, "doc_count": 60}, {"from": 1000, "to": 1000000, "doc_count": 38}, {"from": 1000000, "doc_count": 2}]}`)
+	badHistogram := []byte(`{"buckets": [{"to": 1000, "doc_count": 60}]}`)
+
+	testCases := []struct {
+		name         string
+		aggregations map[string]*json.RawMessage
+		searchErr    error
+		expected     *spanstore.LatencyDistribution
+		expectedErr  string
+	}{
+		{
+			name: "aggregations",
+			aggregations: map[string]*json.RawMessage{
+				percentilesAggregation: (*json.RawMessage)(&percentiles),
+				histogramAggregation:   (*json.RawMessage)(&histogram),
+			},
+			expected: &spanstore.LatencyDistribution{
+				Count:        100,
+				P50:          800 * time.Microsecond,
+				P95:          25000500 * time.Nanosecond,
+				P99:          1500 * time.Millisecond,
+				BucketBounds: query.BucketBounds,
+				BucketCounts: []int64{60, 38, 2},
+			},
+		},
+		{
+			name:        "search error",
+			searchErr:   errors.New("search failure"),
+			expectedErr: "search span durations failed: search failure",
+		},
+		{
+			name:        "no aggregations",
+			expectedErr: ErrUnableToFindLatencyAggregation.Error(),
+		},
+		{
+			name: "mismatched buckets",
+			aggregations: map[string]*json.RawMessage{
+				percentilesAggregation: (*json.RawMessage)(&percentiles),
+				histogramAggregation:   (*json.RawMessage)(&badHistogram),
+			},
+			expectedErr: ErrUnableToFindLatencyAggregation.Error(),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			withSpanReader(t, func(r *spanReaderTest) {
+				mockLatencySearchService(r).
+					Return(&elastic.SearchResult{Aggregations: elastic.Aggregations(testCase.aggregations)}, testCase.searchErr)
+
+				dist, err := r.reader.GetLatencyDistribution(context.Background(), query)
+				if testCase.expectedErr != "" {
+					require.EqualError(t, err, testCase.expectedErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expected, dist)
+			})
+		})
+	}
+}
+
+func TestSpanReader_GetLatencyDistributionNoService(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		_, err := r.reader.GetLatencyDistribution(context.Background(), &spanstore.LatencyQueryParameters{})
+		require.ErrorIs(t, err, ErrServiceNameNotSet)
+	})
+}
+
+func TestSpanReader_buildHistogramAggregation(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		aggregation := r.reader.buildHistogramAggregation([]time.Duration{time.Millisecond, time.Second})
+		actual, err := aggregation.Source()
+		require.NoError(t, err)
+		actualJSON, err := json.Marshal(actual)
+		require.NoError(t, err)
+		assert.JSONEq(t,
+			`{"range": {"field": "duration", "ranges": [{"to": 1000}, {"from": 1000, "to": 1000000}, {"from": 1000000}]}}`,
+			string(actualJSON))
+	})
+}
@@ -106,8 +106,12 @@ func getServicesAggregation(maxDocCount int) elastic.Query {
 		Size(maxDocCount) // ES deprecated size omission for aggregating all. https://github.com/elastic/elasticsearch/issues/18838
 }
 
-func (s *ServiceOperationStorage) getOperations(context context.Context, indices []string, service string, maxDocCount int) ([]string, error) {
-	serviceQuery := elastic.NewTermQuery(serviceName, service)
+func (s *ServiceOperationStorage) getOperations(context context.Context, indices []string, service, namePrefix string, maxDocCount int) ([]string, error) {
+	var serviceQuery elastic.Query = elastic.NewTermQuery(serviceName, service)
+	if namePrefix != "" {
+		// filter in the storage so that the aggregation is not truncated by maxDocCount
+		serviceQuery = elastic.NewBoolQuery().Must(serviceQuery, elastic.NewPrefixQuery(operationNameField, namePrefix))
+	}
 	serviceFilter := getOperationsAggregation(maxDocCount)
 
 	searchService := s.client().Search(indices...).
@@ -17,6 +17,7 @@ package spanstore
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/olivere/elastic"
@@ -100,6 +101,34 @@ func TestSpanReader_GetOperations(t *testing.T) {
 	testGet(operationsAggregation, t)
 }
 
+func TestSpanReader_GetOperationsByPrefix(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		rawMessage := []byte(`{"buckets": [{"key": "GET /b","doc_count": 16}, {"key": "GET /a","doc_count": 8}]}`)
+		aggregations := map[string]*json.RawMessage{operationsAggregation: (*json.RawMessage)(&rawMessage)}
+		call := mockSearchService(r).
+			Return(&elastic.SearchResult{Aggregations: elastic.Aggregations(aggregations)}, nil)
+		operations, err := r.reader.GetOperations(
+			context.Background(),
+			spanstore.OperationQueryParameters{ServiceName: "foo", NamePrefix: "GET /", Limit: 1},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []spanstore.Operation{{Name: "GET /a"}}, operations)
+
+		var query any
+		for _, c := range call.Parent.Calls {
+			if c.Method == "Query" {
+				query = c.Arguments.Get(0)
+			}
+		}
+		source, err := query.(elastic.Query).Source()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"bool": map[string]any{"must": []any{
+			map[string]any{"term": map[string]any{serviceName: "foo"}},
+			map[string]any{"prefix": map[string]any{operationNameField: "GET /"}},
+		}}}, source)
+	})
+}
+
 func TestSpanReader_GetServicesEmptyIndex(t *testing.T) {
 	withSpanReader(t, func(r *spanReaderTest) {
 		mockSearchService(r).
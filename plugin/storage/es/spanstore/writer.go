@@ -17,10 +17,12 @@ package spanstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/olivere/elastic"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -28,6 +30,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/es"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
 
@@ -53,6 +56,8 @@ type SpanWriter struct {
 	serviceWriter    serviceWriter
 	spanConverter    dbmodel.FromDomain
 	spanServiceIndex spanAndServiceIndexFn
+	routeByService   bool
+	idempotentWrites bool
 }
 
 // SpanWriterParams holds constructor parameters for NewSpanWriter
@@ -69,6 +74,11 @@ type SpanWriterParams struct {
 	Archive                bool
 	UseReadWriteAliases    bool
 	ServiceCacheTTL        time.Duration
+	// RouteByService routes the spans by service name, see SpanReaderParams.RouteByService.
+	RouteByService bool
+	// IdempotentWrites derives the IDs of the span documents from the spans, so that writing a span
+	// again overwrites its document instead of creating a duplicate.
+	IdempotentWrites bool
 }
 
 // NewSpanWriter creates a new SpanWriter for use
@@ -88,6 +98,8 @@ func NewSpanWriter(p SpanWriterParams) *SpanWriter {
 		serviceWriter:    serviceOperationStorage.Write,
 		spanConverter:    dbmodel.NewFromDomain(p.AllTagsAsFields, p.TagKeysAsFields, p.TagDotReplacement),
 		spanServiceIndex: getSpanAndServiceIndexFn(p.Archive, p.UseReadWriteAliases, p.IndexPrefix, p.SpanIndexDateLayout, p.ServiceIndexDateLayout),
+		routeByService:   p.RouteByService,
+		idempotentWrites: p.IdempotentWrites,
 	}
 }
 
@@ -142,10 +154,55 @@ func (s *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	if serviceIndexName != "" {
 		s.writeService(serviceIndexName, jsonSpan)
 	}
-	s.writeSpan(spanIndexName, jsonSpan)
+	s.writeSpan(spanIndexName, s.documentID(span), jsonSpan)
 	return nil
 }
 
+// WriteBatch writes the spans and their corresponding service:operation in ElasticSearch. Unlike
+// WriteSpan, which adds the span to the bulk processor, it sends the spans in a single bulk request
+// and returns a spanstore.BatchWriteError with the spans that failed to be indexed.
+func (s *SpanWriter) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	bulk := s.client().Bulk()
+	for _, span := range spans {
+		spanIndexName, serviceIndexName := s.spanServiceIndex(span.StartTime)
+		jsonSpan := s.spanConverter.FromDomainEmbedProcess(span)
+		if serviceIndexName != "" {
+			s.writeService(serviceIndexName, jsonSpan)
+		}
+		bulk = bulk.Add(s.spanIndexRequest(spanIndexName, s.documentID(span), jsonSpan))
+	}
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to write the spans: %w", err)
+	}
+	var failed []*model.Span
+	var errs []error
+	// the items of the response are in the order of the requests, one per span
+	for i, item := range res.Items {
+		for _, result := range item {
+			if i >= len(spans) || (result.Status >= 200 && result.Status <= 299) {
+				continue
+			}
+			failed = append(failed, spans[i])
+			errs = append(errs, fmt.Errorf("failed to index span %s of trace %s: %w", spans[i].SpanID, spans[i].TraceID, bulkItemError(result)))
+		}
+	}
+	if len(failed) > 0 {
+		return &spanstore.BatchWriteError{Failed: failed, Err: errors.Join(errs...)}
+	}
+	return nil
+}
+
+func bulkItemError(result *elastic.BulkResponseItem) error {
+	if result.Error != nil {
+		return fmt.Errorf("%s: %s", result.Error.Type, result.Error.Reason)
+	}
+	return fmt.Errorf("status %d", result.Status)
+}
+
 // Close closes SpanWriter
 func (s *SpanWriter) Close() error {
 	return s.client().Close()
@@ -163,6 +220,41 @@ func (s *SpanWriter) writeService(indexName string, jsonSpan *dbmodel.Span) {
 	s.serviceWriter(indexName, jsonSpan)
 }
 
-func (s *SpanWriter) writeSpan(indexName string, jsonSpan *dbmodel.Span) {
-	s.client().Index().Index(indexName).Type(spanType).BodyJson(&jsonSpan).Add()
+func (s *SpanWriter) writeSpan(indexName string, documentID string, jsonSpan *dbmodel.Span) {
+	indexService := s.client().Index().Index(indexName).Type(spanType)
+	if documentID != "" {
+		indexService = indexService.Id(documentID)
+	}
+	if s.routeByService {
+		indexService = indexService.Routing(jsonSpan.Process.ServiceName)
+	}
+	indexService.BodyJson(&jsonSpan).Add()
+}
+
+// documentID returns the ID of the document of the span, empty to let Elasticsearch generate it
+// unless the writes are idempotent. The ID is derived from the span hash, as the primary key of
+// the spans in Cassandra, so that the spans redelivered by Kafka to the ingester after a rebalance
+// overwrite their documents. If the span cannot be hashed, its ID is generated by Elasticsearch.
+func (s *SpanWriter) documentID(span *model.Span) string {
+	if !s.idempotentWrites {
+		return ""
+	}
+	spanHash, err := model.HashCode(span)
+	if err != nil {
+		s.logger.Warn("Failed to hash the span, the ID of its document is generated",
+			zap.Stringer("trace_id", span.TraceID), zap.Stringer("span_id", span.SpanID), zap.Error(err))
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%016x", span.TraceID, span.SpanID, spanHash)
+}
+
+func (s *SpanWriter) spanIndexRequest(indexName string, documentID string, jsonSpan *dbmodel.Span) *elastic.BulkIndexRequest {
+	request := elastic.NewBulkIndexRequest().Index(indexName).Type(spanType)
+	if documentID != "" {
+		request = request.Id(documentID)
+	}
+	if s.routeByService {
+		request = request.Routing(jsonSpan.Process.ServiceName)
+	}
+	return request.Doc(jsonSpan)
 }
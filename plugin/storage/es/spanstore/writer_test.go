@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/olivere/elastic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -56,7 +57,7 @@ func withSpanWriter(fn func(w *spanWriterTest)) {
 	fn(w)
 }
 
-var _ spanstore.Writer = &SpanWriter{} // check API conformance
+var _ spanstore.BatchWriter = &SpanWriter{} // check API conformance
 
 func TestSpanWriterIndices(t *testing.T) {
 	client := &mocks.Client{}
@@ -217,6 +218,121 @@ func TestSpanWriter_WriteSpan(t *testing.T) {
 	}
 }
 
+func TestSpanWriter_WriteBatch(t *testing.T) {
+	date, err := time.Parse(time.RFC3339, "1995-04-21T22:08:41+00:00")
+	require.NoError(t, err)
+	spans := []*model.Span{
+		{
+			TraceID:       model.NewTraceID(0, 1),
+			SpanID:        model.NewSpanID(1),
+			OperationName: "operation",
+			Process:       &model.Process{ServiceName: "service"},
+			StartTime:     date,
+		},
+		{
+			TraceID:       model.NewTraceID(0, 2),
+			SpanID:        model.NewSpanID(2),
+			OperationName: "operation",
+			Process:       &model.Process{ServiceName: "service"},
+			StartTime:     date.Add(24 * time.Hour),
+		},
+	}
+	indexed := func(status int) map[string]*elastic.BulkResponseItem {
+		return map[string]*elastic.BulkResponseItem{"index": {Status: status}}
+	}
+	testCases := []struct {
+		name     string
+		response *elastic.BulkResponse
+		doErr    error
+		err      string
+		failed   []*model.Span
+	}{
+		{
+			name:     "all spans indexed",
+			response: &elastic.BulkResponse{Items: []map[string]*elastic.BulkResponseItem{indexed(201), indexed(201)}},
+		},
+		{
+			name: "span failed",
+			response: &elastic.BulkResponse{Errors: true, Items: []map[string]*elastic.BulkResponseItem{
+				indexed(201),
+				{"index": {Status: 400, Error: &elastic.ErrorDetails{Type: "mapper_parsing_exception", Reason: "failed to parse"}}},
+			}},
+			err:    "failed to write 1 spans of the batch: failed to index span 0000000000000002 of trace 0000000000000002: mapper_parsing_exception: failed to parse",
+			failed: spans[1:],
+		},
+		{
+			name: "span rejected",
+			response: &elastic.BulkResponse{Errors: true, Items: []map[string]*elastic.BulkResponseItem{
+				indexed(429),
+				indexed(201),
+			}},
+			err:    "failed to write 1 spans of the batch: failed to index span 0000000000000001 of trace 0000000000000001: status 429",
+			failed: spans[:1],
+		},
+		{
+			name:  "bulk request failed",
+			doErr: errors.New("connection refused"),
+			err:   "failed to write the spans: connection refused",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withSpanWriter(func(w *spanWriterTest) {
+				indexService := &mocks.IndexService{}
+				indexServicePut := &mocks.IndexService{}
+				indexService.On("Index", mock.AnythingOfType("string")).Return(indexService)
+				indexService.On("Type", stringMatcher(serviceType)).Return(indexServicePut)
+				indexServicePut.On("Id", mock.AnythingOfType("string")).Return(indexServicePut)
+				indexServicePut.On("BodyJson", mock.AnythingOfType("dbmodel.Service")).Return(indexServicePut)
+				indexServicePut.On("Add")
+				w.client.On("Index").Return(indexService)
+
+				var requests []*elastic.BulkIndexRequest
+				bulk := &mocks.BulkService{}
+				bulk.On("Add", mock.AnythingOfType("*elastic.BulkIndexRequest")).
+					Run(func(args mock.Arguments) {
+						requests = append(requests, args.Get(0).(*elastic.BulkIndexRequest))
+					}).
+					Return(bulk)
+				bulk.On("Do", mock.Anything).Return(tc.response, tc.doErr)
+				w.client.On("Bulk").Return(bulk)
+
+				err := w.writer.WriteBatch(context.Background(), spans)
+				if tc.err == "" {
+					require.NoError(t, err)
+				} else {
+					require.EqualError(t, err, tc.err)
+				}
+				var batchErr *spanstore.BatchWriteError
+				if tc.failed != nil {
+					require.ErrorAs(t, err, &batchErr)
+					assert.Equal(t, tc.failed, batchErr.Failed)
+				} else {
+					assert.False(t, errors.As(err, &batchErr))
+				}
+
+				// the spans are sent in a single bulk request
+				bulk.AssertNumberOfCalls(t, "Do", 1)
+				require.Len(t, requests, 2)
+				for i, index := range []string{"jaeger-span-1995-04-21", "jaeger-span-1995-04-22"} {
+					source, err := requests[i].Source()
+					require.NoError(t, err)
+					assert.JSONEq(t, `{"index":{"_index":"`+index+`","_type":"span"}}`, source[0])
+				}
+				// the service:operation pair is cached after its first write
+				indexServicePut.AssertNumberOfCalls(t, "Add", 1)
+			})
+		})
+	}
+}
+
+func TestSpanWriter_WriteBatchEmpty(t *testing.T) {
+	withSpanWriter(func(w *spanWriterTest) {
+		require.NoError(t, w.writer.WriteBatch(context.Background(), nil))
+		w.client.AssertNotCalled(t, "Bulk")
+	})
+}
+
 func TestCreateTemplates(t *testing.T) {
 	tests := []struct {
 		err                    string
@@ -327,12 +443,66 @@ func TestWriteSpanInternal(t *testing.T) {
 
 		jsonSpan := &dbmodel.Span{}
 
-		w.writer.writeSpan(indexName, jsonSpan)
+		w.writer.writeSpan(indexName, "", jsonSpan)
 		indexService.AssertNumberOfCalls(t, "Add", 1)
 		assert.Equal(t, "", w.logBuffer.String())
 	})
 }
 
+func TestWriteSpanInternalRouteByService(t *testing.T) {
+	withSpanWriter(func(w *spanWriterTest) {
+		w.writer.routeByService = true
+		indexService := &mocks.IndexService{}
+
+		indexName := "jaeger-1995-04-21"
+		indexService.On("Index", stringMatcher(indexName)).Return(indexService)
+		indexService.On("Type", stringMatcher(spanType)).Return(indexService)
+		indexService.On("Routing", "service").Return(indexService)
+		indexService.On("BodyJson", mock.AnythingOfType("**dbmodel.Span")).Return(indexService)
+		indexService.On("Add")
+
+		w.client.On("Index").Return(indexService)
+
+		jsonSpan := &dbmodel.Span{Process: dbmodel.Process{ServiceName: "service"}}
+
+		w.writer.writeSpan(indexName, "", jsonSpan)
+		indexService.AssertCalled(t, "Routing", "service")
+		indexService.AssertNumberOfCalls(t, "Add", 1)
+	})
+}
+
+func TestWriteSpanIdempotentWrites(t *testing.T) {
+	withSpanWriter(func(w *spanWriterTest) {
+		span := &model.Span{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(2), OperationName: "op"}
+		assert.Empty(t, w.writer.documentID(span))
+
+		w.writer.idempotentWrites = true
+		documentID := w.writer.documentID(span)
+		assert.True(t, strings.HasPrefix(documentID, "0000000000000001-0000000000000002-"), documentID)
+		assert.Equal(t, documentID, w.writer.documentID(&model.Span{TraceID: span.TraceID, SpanID: span.SpanID, OperationName: "op"}))
+		assert.NotEqual(t, documentID, w.writer.documentID(&model.Span{TraceID: span.TraceID, SpanID: span.SpanID, OperationName: "other-op"}))
+
+		// the span cannot be hashed with a zone offset of -1 minute
+		unhashable := &model.Span{TraceID: span.TraceID, SpanID: span.SpanID, StartTime: time.Now().In(time.FixedZone("", -60))}
+		assert.Empty(t, w.writer.documentID(unhashable))
+		assert.Contains(t, w.logBuffer.String(), "Failed to hash the span")
+
+		indexService := &mocks.IndexService{}
+		indexName := "jaeger-1995-04-21"
+		indexService.On("Index", stringMatcher(indexName)).Return(indexService)
+		indexService.On("Type", stringMatcher(spanType)).Return(indexService)
+		indexService.On("Id", documentID).Return(indexService)
+		indexService.On("BodyJson", mock.AnythingOfType("**dbmodel.Span")).Return(indexService)
+		indexService.On("Add")
+
+		w.client.On("Index").Return(indexService)
+
+		w.writer.writeSpan(indexName, documentID, &dbmodel.Span{})
+		indexService.AssertCalled(t, "Id", documentID)
+		indexService.AssertNumberOfCalls(t, "Add", 1)
+	})
+}
+
 func TestWriteSpanInternalError(t *testing.T) {
 	withSpanWriter(func(w *spanWriterTest) {
 		indexService := &mocks.IndexService{}
@@ -350,7 +520,7 @@ func TestWriteSpanInternalError(t *testing.T) {
 			SpanID:  dbmodel.SpanID("0"),
 		}
 
-		w.writer.writeSpan(indexName, jsonSpan)
+		w.writer.writeSpan(indexName, "", jsonSpan)
 		indexService.AssertNumberOfCalls(t, "Add", 1)
 	})
 }
@@ -16,10 +16,14 @@
 package storage
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -31,12 +35,22 @@ import (
 	"github.com/jaegertracing/jaeger/plugin/storage/blackhole"
 	"github.com/jaegertracing/jaeger/plugin/storage/cassandra"
 	"github.com/jaegertracing/jaeger/plugin/storage/es"
+	"github.com/jaegertracing/jaeger/plugin/storage/forwarder"
 	"github.com/jaegertracing/jaeger/plugin/storage/grpc"
 	"github.com/jaegertracing/jaeger/plugin/storage/kafka"
 	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/plugin/storage/parquet"
+	"github.com/jaegertracing/jaeger/plugin/storage/postgres"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	dependencyStoreMetrics "github.com/jaegertracing/jaeger/storage/dependencystore/metrics"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanStoreMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
 )
 
 const (
@@ -49,11 +63,37 @@ const (
 	grpcPluginDeprecated     = "grpc-plugin"
 	badgerStorageType        = "badger"
 	blackholeStorageType     = "blackhole"
+	forwarderStorageType     = "forwarder"
+	parquetStorageType       = "parquet"
+	postgresStorageType      = "postgres"
+	sqliteStorageType        = "sqlite"
 
 	downsamplingRatio    = "downsampling.ratio"
 	downsamplingHashSalt = "downsampling.hashsalt"
 	spanStorageType      = "span-storage-type"
 
+	fanoutPrefix             = "span-storage.fanout."
+	fanoutQueueSize          = ".queue-size"
+	fanoutWorkers            = ".workers"
+	fanoutMaxRetries         = ".max-retries"
+	fanoutRetryInterval      = ".retry-interval"
+	defaultFanoutQueueSize   = 10000
+	defaultFanoutWorkers     = 10
+	defaultFanoutMaxRetries  = 3
+	defaultFanoutRetryPeriod = 100 * time.Millisecond
+	federationPrefix         = "span-reader.federation."
+	federationMinAge         = ".min-age"
+	federationMaxAge         = ".max-age"
+	readTimeout              = "span-storage.read-timeout"
+	writeTimeout             = "span-storage.write-timeout"
+
+	// primaryRole and archiveRole tag the storage metrics of the primary and archive storages.
+	primaryRole = "primary"
+	archiveRole = "archive"
+
+	// fanoutDrainTimeout is how long Close waits for the queued spans of a secondary backend to be written.
+	fanoutDrainTimeout = 5 * time.Second
+
 	// defaultDownsamplingRatio is the default downsampling ratio.
 	defaultDownsamplingRatio = 1.0
 	// defaultDownsamplingHashSalt is the default downsampling hashsalt.
@@ -70,6 +110,10 @@ var AllStorageTypes = []string{
 	badgerStorageType,
 	blackholeStorageType,
 	grpcStorageType,
+	forwarderStorageType,
+	parquetStorageType,
+	postgresStorageType,
+	sqliteStorageType,
 }
 
 // AllSamplingStorageTypes returns all storage backends that implement adaptive sampling
@@ -86,18 +130,36 @@ func AllSamplingStorageTypes() []string {
 }
 
 var ( // interface comformance checks
-	_ storage.Factory        = (*Factory)(nil)
-	_ storage.ArchiveFactory = (*Factory)(nil)
-	_ io.Closer              = (*Factory)(nil)
-	_ plugin.Configurable    = (*Factory)(nil)
+	_ storage.Factory                      = (*Factory)(nil)
+	_ storage.ArchiveFactory               = (*Factory)(nil)
+	_ storage.WarningStoreFactory          = (*Factory)(nil)
+	_ storage.MetadataStoreFactory         = (*Factory)(nil)
+	_ storage.SamplingStrategyStoreFactory = (*Factory)(nil)
+	_ storage.SavedSearchStoreFactory      = (*Factory)(nil)
+	_ storage.HealthChecker                = (*Factory)(nil)
+	_ io.Closer                            = (*Factory)(nil)
+	_ plugin.Configurable                  = (*Factory)(nil)
 )
 
 // Factory implements storage.Factory interface as a meta-factory for storage components.
 type Factory struct {
 	FactoryConfig
 	metricsFactory         metrics.Factory
+	logger                 *zap.Logger
 	factories              map[string]storage.Factory
 	downsamplingFlagsAdded bool
+
+	// fanout holds the async write options of the secondary span writer types
+	fanout       map[string]spanstore.AsyncWriterOptions
+	asyncWriters []*spanstore.AsyncWriter
+
+	// federation holds the time ranges of the federated span reader types
+	federation map[string]spanstore.FederatedBackend
+
+	// readTimeout and writeTimeout bound the time of each read and write of the storage backends,
+	// 0 leaving them bounded by the deadlines of their contexts only.
+	readTimeout  time.Duration
+	writeTimeout time.Duration
 }
 
 // NewFactory creates the meta-factory.
@@ -110,6 +172,9 @@ func NewFactory(config FactoryConfig) (*Factory, error) {
 	for _, storageType := range f.SpanWriterTypes {
 		uniqueTypes[storageType] = struct{}{}
 	}
+	for _, storageType := range f.FederatedSpanReaderTypes {
+		uniqueTypes[storageType] = struct{}{}
+	}
 	// skip SamplingStorageType if it is empty. See CreateSamplingStoreFactory for details
 	if f.SamplingStorageType != "" {
 		uniqueTypes[f.SamplingStorageType] = struct{}{}
@@ -141,6 +206,14 @@ func (*Factory) getFactoryOfType(factoryType string) (storage.Factory, error) {
 		return grpc.NewFactory(), nil
 	case blackholeStorageType:
 		return blackhole.NewFactory(), nil
+	case forwarderStorageType:
+		return forwarder.NewFactory(), nil
+	case parquetStorageType:
+		return parquet.NewFactory(), nil
+	case postgresStorageType:
+		return postgres.NewFactory(), nil
+	case sqliteStorageType:
+		return sqlite.NewFactory(), nil
 	default:
 		return nil, fmt.Errorf("unknown storage type %s. Valid types are %v", factoryType, AllStorageTypes)
 	}
@@ -148,7 +221,7 @@ func (*Factory) getFactoryOfType(factoryType string) (storage.Factory, error) {
 
 // Initialize implements storage.Factory.
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
-	f.metricsFactory = metricsFactory
+	f.metricsFactory, f.logger = metricsFactory, logger
 	for _, factory := range f.factories {
 		if err := factory.Initialize(metricsFactory, logger); err != nil {
 			return err
@@ -160,18 +233,63 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 }
 
 // CreateSpanReader implements storage.Factory.
+// When federated span reader types are configured, the spans of all the span reader
+// types are merged, each backend being searched only for the time range it holds.
 func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
-	factory, ok := f.factories[f.SpanReaderType]
+	if len(f.FederatedSpanReaderTypes) == 0 {
+		return f.createSpanReaderOfType(f.SpanReaderType)
+	}
+	var backends []spanstore.FederatedBackend
+	for _, storageType := range append([]string{f.SpanReaderType}, f.FederatedSpanReaderTypes...) {
+		reader, err := f.createSpanReaderOfType(storageType)
+		if err != nil {
+			return nil, err
+		}
+		backend := f.federation[storageType]
+		backend.Name, backend.Reader = storageType, reader
+		backends = append(backends, backend)
+	}
+	return spanstore.NewFederatedReader(f.logger, backends...), nil
+}
+
+func (f *Factory) createSpanReaderOfType(storageType string) (spanstore.Reader, error) {
+	factory, ok := f.factories[storageType]
 	if !ok {
-		return nil, fmt.Errorf("no %s backend registered for span store", f.SpanReaderType)
+		return nil, fmt.Errorf("no %s backend registered for span store", storageType)
+	}
+	reader, err := factory.CreateSpanReader()
+	if err != nil {
+		return nil, err
+	}
+	reader = spanstore.NewTimeoutReader(reader, f.readTimeout)
+	return spanStoreMetrics.NewReadMetricsDecorator(reader, f.storageMetricsFactory(storageType, primaryRole)), nil
+}
+
+// storageMetricsFactory returns the metrics factory of the decorators of the readers and writers
+// of the storage type, which report the same metrics whatever the backend.
+func (f *Factory) storageMetricsFactory(storageType, role string) metrics.Factory {
+	return f.metricsFactoryOrNull().Namespace(metrics.NSOptions{
+		Name: "storage",
+		Tags: map[string]string{"backend": storageType, "role": role},
+	})
+}
+
+// metricsFactoryOrNull returns the metrics factory given to Initialize, or metrics.NullFactory
+// if the factory is not initialized.
+func (f *Factory) metricsFactoryOrNull() metrics.Factory {
+	if f.metricsFactory == nil {
+		return metrics.NullFactory
 	}
-	return factory.CreateSpanReader()
+	return f.metricsFactory
 }
 
 // CreateSpanWriter implements storage.Factory.
+// When multiple span writer types are configured, the first one is written synchronously
+// and the others are written through their own queue, if enabled, so that a slow or
+// failing secondary backend does not affect the primary one.
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
 	var writers []spanstore.Writer
-	for _, storageType := range f.SpanWriterTypes {
+	for i, storageType := range f.SpanWriterTypes {
 		factory, ok := f.factories[storageType]
 		if !ok {
 			return nil, fmt.Errorf("no %s backend registered for span store", storageType)
@@ -180,6 +298,21 @@ func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
 		if err != nil {
 			return nil, err
 		}
+		writer = spanstore.NewTimeoutWriter(writer, f.writeTimeout)
+		writer = spanStoreMetrics.NewWriteMetricsDecorator(writer, f.storageMetricsFactory(storageType, primaryRole))
+		if opts, ok := f.fanout[storageType]; ok && i > 0 && opts.QueueSize > 0 {
+			opts.MetricsFactory = f.metricsFactoryOrNull().Namespace(metrics.NSOptions{
+				Name: "fanout_writer",
+				Tags: map[string]string{"backend": storageType},
+			})
+			opts.Logger = zap.NewNop()
+			if f.logger != nil {
+				opts.Logger = f.logger.With(zap.String("backend", storageType))
+			}
+			asyncWriter := spanstore.NewAsyncWriter(writer, opts)
+			f.asyncWriters = append(f.asyncWriters, asyncWriter)
+			writer = asyncWriter
+		}
 		writers = append(writers, writer)
 	}
 	var spanWriter spanstore.Writer
@@ -233,7 +366,12 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	if !ok {
 		return nil, fmt.Errorf("no %s backend registered for span store", f.DependenciesStorageType)
 	}
-	return factory.CreateDependencyReader()
+	reader, err := factory.CreateDependencyReader()
+	if err != nil {
+		return nil, err
+	}
+	reader = dependencystore.NewTimeoutReader(reader, f.readTimeout)
+	return dependencyStoreMetrics.NewReadMetricsDecorator(reader, f.storageMetricsFactory(f.DependenciesStorageType, primaryRole)), nil
 }
 
 // AddFlags implements plugin.Configurable
@@ -243,14 +381,56 @@ func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
 			conf.AddFlags(flagSet)
 		}
 	}
+	f.addFederationFlags(flagSet)
+	f.addTimeoutFlags(flagSet)
+}
+
+// addTimeoutFlags adds flags for the timeouts of the reads and writes of the storage backends
+func (*Factory) addTimeoutFlags(flagSet *flag.FlagSet) {
+	flagSet.Duration(
+		readTimeout,
+		0,
+		"The maximum duration of each read of the span and dependency storage, so that a slow backend query cannot hold a request indefinitely; 0 means no timeout other than the deadline of the request.",
+	)
+	flagSet.Duration(
+		writeTimeout,
+		0,
+		"The maximum duration of each write to the span storage; 0 means no timeout other than the deadline of the write.",
+	)
+}
+
+// addFederationFlags add flags for the time ranges held by the federated span reader types
+func (f *Factory) addFederationFlags(flagSet *flag.FlagSet) {
+	if len(f.FederatedSpanReaderTypes) == 0 {
+		return
+	}
+	f.federation = make(map[string]spanstore.FederatedBackend)
+	for _, storageType := range append([]string{f.SpanReaderType}, f.FederatedSpanReaderTypes...) {
+		if _, ok := f.federation[storageType]; ok {
+			continue
+		}
+		f.federation[storageType] = spanstore.FederatedBackend{}
+		prefix := federationPrefix + storageType
+		flagSet.Duration(
+			prefix+federationMinAge,
+			0,
+			fmt.Sprintf("The age of the most recent spans held by the %s span storage, e.g. when it is an archive; trace searches for more recent spans skip it.", storageType),
+		)
+		flagSet.Duration(
+			prefix+federationMaxAge,
+			0,
+			fmt.Sprintf("The age of the oldest spans held by the %s span storage, e.g. its retention; trace searches for older spans skip it. 0 means unbounded.", storageType),
+		)
+	}
 }
 
 // AddPipelineFlags adds all the standard flags as well as the downsampling
-// flags. This is intended to be used in Jaeger pipeline services such as
+// and fan-out flags. This is intended to be used in Jaeger pipeline services such as
 // the collector or ingester.
 func (f *Factory) AddPipelineFlags(flagSet *flag.FlagSet) {
 	f.AddFlags(flagSet)
 	f.addDownsamplingFlags(flagSet)
+	f.addFanoutFlags(flagSet)
 }
 
 // addDownsamplingFlags add flags for Downsampling params
@@ -268,6 +448,52 @@ func (f *Factory) addDownsamplingFlags(flagSet *flag.FlagSet) {
 	)
 }
 
+// secondaryWriterTypes returns the span writer types other than the primary one, without duplicates.
+func (f *Factory) secondaryWriterTypes() []string {
+	var types []string
+	seen := map[string]bool{}
+	for i, storageType := range f.SpanWriterTypes {
+		if i == 0 {
+			seen[storageType] = true
+			continue
+		}
+		if !seen[storageType] {
+			seen[storageType] = true
+			types = append(types, storageType)
+		}
+	}
+	return types
+}
+
+// addFanoutFlags add flags for the async write queues of the secondary span writer types
+func (f *Factory) addFanoutFlags(flagSet *flag.FlagSet) {
+	f.fanout = make(map[string]spanstore.AsyncWriterOptions)
+	for _, storageType := range f.secondaryWriterTypes() {
+		f.fanout[storageType] = spanstore.AsyncWriterOptions{}
+		prefix := fanoutPrefix + storageType
+		flagSet.Int(
+			prefix+fanoutQueueSize,
+			defaultFanoutQueueSize,
+			fmt.Sprintf("The number of spans queued for the secondary %s span storage before spans are dropped; 0 writes spans synchronously.", storageType),
+		)
+		flagSet.Int(
+			prefix+fanoutWorkers,
+			defaultFanoutWorkers,
+			fmt.Sprintf("The number of workers writing queued spans to the secondary %s span storage.", storageType),
+		)
+		flagSet.Int(
+			prefix+fanoutMaxRetries,
+			defaultFanoutMaxRetries,
+			fmt.Sprintf("The number of times a failed write to the secondary %s span storage is retried before the span is dropped.", storageType),
+		)
+		flagSet.Duration(
+			prefix+fanoutRetryInterval,
+			defaultFanoutRetryPeriod,
+			fmt.Sprintf("The delay between retries of a failed write to the secondary %s span storage.", storageType),
+		)
+	}
+}
+
 // InitFromViper implements plugin.Configurable
 func (f *Factory) InitFromViper(v *viper.Viper, logger *zap.Logger) {
 	for _, factory := range f.factories {
@@ -276,6 +502,34 @@ func (f *Factory) InitFromViper(v *viper.Viper, logger *zap.Logger) {
 		}
 	}
 	f.initDownsamplingFromViper(v)
+	f.initFanoutFromViper(v)
+	f.initFederationFromViper(v)
+	f.readTimeout = v.GetDuration(readTimeout)
+	f.writeTimeout = v.GetDuration(writeTimeout)
+}
+
+func (f *Factory) initFederationFromViper(v *viper.Viper) {
+	for storageType := range f.federation {
+		prefix := federationPrefix + storageType
+		f.federation[storageType] = spanstore.FederatedBackend{
+			MinAge: v.GetDuration(prefix + federationMinAge),
+			MaxAge: v.GetDuration(prefix + federationMaxAge),
+		}
+	}
+}
+
+func (f *Factory) initFanoutFromViper(v *viper.Viper) {
+	// without the fan-out flags the secondary span writers are written synchronously
+	for storageType := range f.fanout {
+		prefix := fanoutPrefix + storageType
+		f.fanout[storageType] = spanstore.AsyncWriterOptions{
+			QueueSize:     v.GetInt(prefix + fanoutQueueSize),
+			Workers:       v.GetInt(prefix + fanoutWorkers),
+			MaxRetries:    v.GetInt(prefix + fanoutMaxRetries),
+			RetryInterval: v.GetDuration(prefix + fanoutRetryInterval),
+			DrainTimeout:  fanoutDrainTimeout,
+		}
+	}
 }
 
 func (f *Factory) initDownsamplingFromViper(v *viper.Viper) {
@@ -305,7 +559,12 @@ func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
 	if !ok {
 		return nil, storage.ErrArchiveStorageNotSupported
 	}
-	return archive.CreateArchiveSpanReader()
+	reader, err := archive.CreateArchiveSpanReader()
+	if err != nil {
+		return nil, err
+	}
+	reader = spanstore.NewTimeoutReader(reader, f.readTimeout)
+	return spanStoreMetrics.NewReadMetricsDecorator(reader, f.storageMetricsFactory(f.SpanReaderType, archiveRole)), nil
 }
 
 // CreateArchiveSpanWriter implements storage.ArchiveFactory
@@ -318,7 +577,73 @@ func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
 	if !ok {
 		return nil, storage.ErrArchiveStorageNotSupported
 	}
-	return archive.CreateArchiveSpanWriter()
+	writer, err := archive.CreateArchiveSpanWriter()
+	if err != nil {
+		return nil, err
+	}
+	writer = spanstore.NewTimeoutWriter(writer, f.writeTimeout)
+	return spanStoreMetrics.NewWriteMetricsDecorator(writer, f.storageMetricsFactory(f.SpanWriterTypes[0], archiveRole)), nil
+}
+
+// CreateWarningStore implements storage.WarningStoreFactory.
+// The warnings are stored in the backend the spans are read from, since the query service records them.
+func (f *Factory) CreateWarningStore() (warningstore.Store, error) {
+	factory, ok := f.factories[f.SpanReaderType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.SpanReaderType)
+	}
+	warnings, ok := factory.(storage.WarningStoreFactory)
+	if !ok {
+		return nil, storage.ErrWarningStorageNotSupported
+	}
+	return warnings.CreateWarningStore()
+}
+
+// CreateMetadataStore implements storage.MetadataStoreFactory.
+// The metadata is stored in the backend the spans are read from, since the query service serves it.
+func (f *Factory) CreateMetadataStore() (metadatastore.Store, error) {
+	factory, ok := f.factories[f.SpanReaderType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.SpanReaderType)
+	}
+	metadata, ok := factory.(storage.MetadataStoreFactory)
+	if !ok {
+		return nil, storage.ErrMetadataStorageNotSupported
+	}
+	return metadata.CreateMetadataStore()
+}
+
+// CreateSamplingStrategyStore implements storage.SamplingStrategyStoreFactory.
+// The strategies are stored in the sampling storage if one is specified, otherwise in the backend
+// the spans are read from, so that the query service and the collector use the same backend.
+func (f *Factory) CreateSamplingStrategyStore() (samplingstore.StrategyStore, error) {
+	storageType := f.SamplingStorageType
+	if storageType == "" {
+		storageType = f.SpanReaderType
+	}
+	factory, ok := f.factories[storageType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for sampling strategies store", storageType)
+	}
+	strategies, ok := factory.(storage.SamplingStrategyStoreFactory)
+	if !ok {
+		return nil, storage.ErrSamplingStrategyStorageNotSupported
+	}
+	return strategies.CreateSamplingStrategyStore()
+}
+
+// CreateSavedSearchStore implements storage.SavedSearchStoreFactory.
+// The searches are stored in the backend the spans are read from, since the query service serves them.
+func (f *Factory) CreateSavedSearchStore() (savedsearchstore.Store, error) {
+	factory, ok := f.factories[f.SpanReaderType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.SpanReaderType)
+	}
+	searches, ok := factory.(storage.SavedSearchStoreFactory)
+	if !ok {
+		return nil, storage.ErrSavedSearchStorageNotSupported
+	}
+	return searches.CreateSavedSearchStore()
 }
 
 var _ io.Closer = (*Factory)(nil)
@@ -326,6 +651,10 @@ var _ io.Closer = (*Factory)(nil)
 // Close closes the resources held by the factory
 func (f *Factory) Close() error {
 	var errs []error
+	// the queued spans are written before the backends are closed
+	for _, w := range f.asyncWriters {
+		errs = append(errs, w.Close())
+	}
 	for _, storageType := range f.SpanWriterTypes {
 		if factory, ok := f.factories[storageType]; ok {
 			if closer, ok := factory.(io.Closer); ok {
@@ -339,6 +668,83 @@ func (f *Factory) Close() error {
 	return errors.Join(errs...)
 }
 
+// CheckHealth implements storage.HealthChecker by checking the backends able to check their connectivity.
+func (f *Factory) CheckHealth(ctx context.Context) error {
+	var errs []error
+	for storageType, factory := range f.factories {
+		if checker, ok := factory.(storage.HealthChecker); ok {
+			if err := checker.CheckHealth(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", storageType, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Capabilities implements storage.Factory by combining the capabilities of the backends used
+// for each feature: the search features must be supported by every span reader type, the archive
+// storage by both the span reader type and the primary span writer type.
+func (f *Factory) Capabilities() storage.Capabilities {
+	var capabilities storage.Capabilities
+	if reader, ok := f.factories[f.SpanReaderType]; ok {
+		readerCapabilities := reader.Capabilities()
+		capabilities.TraceSearch = readerCapabilities.TraceSearch
+		capabilities.TagSearch = readerCapabilities.TagSearch
+		capabilities.TagFilters = readerCapabilities.TagFilters
+		capabilities.OperationSpanKind = readerCapabilities.OperationSpanKind
+		capabilities.ArchiveStorage = readerCapabilities.ArchiveStorage
+	}
+	for _, storageType := range f.FederatedSpanReaderTypes {
+		if reader, ok := f.factories[storageType]; ok {
+			readerCapabilities := reader.Capabilities()
+			capabilities.TraceSearch = capabilities.TraceSearch && readerCapabilities.TraceSearch
+			capabilities.TagSearch = capabilities.TagSearch && readerCapabilities.TagSearch
+			capabilities.TagFilters = capabilities.TagFilters && readerCapabilities.TagFilters
+			capabilities.OperationSpanKind = capabilities.OperationSpanKind && readerCapabilities.OperationSpanKind
+		}
+	}
+	if len(f.SpanWriterTypes) > 0 {
+		if writer, ok := f.factories[f.SpanWriterTypes[0]]; ok {
+			writerCapabilities := writer.Capabilities()
+			capabilities.StreamingWrites = writerCapabilities.StreamingWrites
+			capabilities.ArchiveStorage = capabilities.ArchiveStorage && writerCapabilities.ArchiveStorage
+		} else {
+			capabilities.ArchiveStorage = false
+		}
+	}
+	if dependencies, ok := f.factories[f.DependenciesStorageType]; ok {
+		capabilities.Dependencies = dependencies.Capabilities().Dependencies
+	}
+	if samplingStoreFactory, err := f.CreateSamplingStoreFactory(); err == nil && samplingStoreFactory != nil {
+		capabilities.AdaptiveSampling = true
+	}
+	return capabilities
+}
+
+// capabilitiesResponse is the body returned by the CapabilitiesHandler.
+type capabilitiesResponse struct {
+	Capabilities storage.Capabilities            `json:"capabilities"`
+	Backends     map[string]storage.Capabilities `json:"backends"`
+}
+
+// CapabilitiesHandler returns the handler of the admin endpoint serving, as JSON, the combined
+// capabilities of the storage and the capabilities of each of its backends.
+func (f *Factory) CapabilitiesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		response := capabilitiesResponse{
+			Capabilities: f.Capabilities(),
+			Backends:     make(map[string]storage.Capabilities, len(f.factories)),
+		}
+		for storageType, factory := range f.factories {
+			response.Backends[storageType] = factory.Capabilities()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			f.logger.Error("Failed to write the storage capabilities", zap.Error(err))
+		}
+	})
+}
+
 func (f *Factory) publishOpts() {
 	safeexpvar.SetInt(downsamplingRatio, int64(f.FactoryConfig.DownsamplingRatio))
 	safeexpvar.SetInt(spanStorageType+"-"+f.FactoryConfig.SpanReaderType, 1)
@@ -32,17 +32,22 @@ const (
 	// SamplingStorageTypeEnvVar is the name of the env var that defines the type of backend used for sampling data storage when using adaptive sampling.
 	SamplingStorageTypeEnvVar = "SAMPLING_STORAGE_TYPE"
 
+	// SpanReaderFederationEnvVar is the name of the env var that defines the types of additional backends
+	// queried for spans, together with the primary span storage backend.
+	SpanReaderFederationEnvVar = "SPAN_READER_FEDERATION"
+
 	spanStorageFlag = "--span-storage.type"
 )
 
 // FactoryConfig tells the Factory which types of backends it needs to create for different storage types.
 type FactoryConfig struct {
-	SpanWriterTypes         []string
-	SpanReaderType          string
-	SamplingStorageType     string
-	DependenciesStorageType string
-	DownsamplingRatio       float64
-	DownsamplingHashSalt    string
+	SpanWriterTypes          []string
+	SpanReaderType           string
+	FederatedSpanReaderTypes []string
+	SamplingStorageType      string
+	DependenciesStorageType  string
+	DownsamplingRatio        float64
+	DownsamplingHashSalt     string
 }
 
 // FactoryConfigFromEnvAndCLI reads the desired types of storage backends from SPAN_STORAGE_TYPE and
@@ -54,6 +59,13 @@ type FactoryConfig struct {
 // * `kafka` - built-in
 // * `blackhole` - built-in
 // * `grpc` - build-in
+// * `forwarder` - built-in
+// * `parquet` - built-in
+// * `postgres` - built-in
+// * `sqlite` - built-in
+//
+// The SPAN_READER_FEDERATION environment variable lists additional backends, e.g. a cold archive,
+// whose spans are merged with those of the primary span storage by the span reader.
 //
 // For backwards compatibility it also parses the args looking for deprecated --span-storage.type flag.
 // If found, it writes a deprecation warning to the log.
@@ -87,11 +99,16 @@ func FactoryConfigFromEnvAndCLI(args []string, log io.Writer) FactoryConfig {
 	}
 	samplingStorageType := os.Getenv(SamplingStorageTypeEnvVar)
 	// TODO support explicit configuration for readers
+	var federatedSpanReaderTypes []string
+	if federation := os.Getenv(SpanReaderFederationEnvVar); federation != "" {
+		federatedSpanReaderTypes = strings.Split(federation, ",")
+	}
 	return FactoryConfig{
-		SpanWriterTypes:         spanWriterTypes,
-		SpanReaderType:          spanWriterTypes[0],
-		DependenciesStorageType: depStorageType,
-		SamplingStorageType:     samplingStorageType,
+		SpanWriterTypes:          spanWriterTypes,
+		SpanReaderType:           spanWriterTypes[0],
+		FederatedSpanReaderTypes: federatedSpanReaderTypes,
+		DependenciesStorageType:  depStorageType,
+		SamplingStorageType:      samplingStorageType,
 	}
 }
 
@@ -47,6 +47,14 @@ func TestFactoryConfigFromEnv(t *testing.T) {
 	assert.Len(t, f.SpanWriterTypes, 2)
 	assert.Equal(t, []string{elasticsearchStorageType, kafkaStorageType}, f.SpanWriterTypes)
 	assert.Equal(t, elasticsearchStorageType, f.SpanReaderType)
+	assert.Empty(t, f.FederatedSpanReaderTypes)
+
+	t.Setenv(SpanReaderFederationEnvVar, cassandraStorageType+","+badgerStorageType)
+
+	f = FactoryConfigFromEnvAndCLI(nil, &bytes.Buffer{})
+	assert.Equal(t, elasticsearchStorageType, f.SpanReaderType)
+	assert.Equal(t, []string{cassandraStorageType, badgerStorageType}, f.FederatedSpanReaderTypes)
+	t.Setenv(SpanReaderFederationEnvVar, "")
 
 	t.Setenv(SpanStorageTypeEnvVar, badgerStorageType)
 
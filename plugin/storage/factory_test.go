@@ -16,27 +16,37 @@
 package storage
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"expvar"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	depStoreMocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
 	"github.com/jaegertracing/jaeger/storage/mocks"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanStoreMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 	spanStoreMocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
 
@@ -94,6 +104,95 @@ func TestClose(t *testing.T) {
 	require.EqualError(t, f.Close(), err.Error())
 }
 
+func TestCheckHealth(t *testing.T) {
+	f := Factory{
+		factories: map[string]storage.Factory{
+			"foo": &errorFactory{healthErr: errors.New("connection refused")},
+			"bar": &errorFactory{},
+		},
+	}
+	require.EqualError(t, f.CheckHealth(context.Background()), "foo: connection refused")
+}
+
+func newCapabilitiesFactory(capabilities storage.Capabilities) *mocks.Factory {
+	f := new(mocks.Factory)
+	f.On("Capabilities").Return(capabilities)
+	return f
+}
+
+func newCapabilitiesTestFactory() *Factory {
+	return &Factory{
+		FactoryConfig: FactoryConfig{
+			SpanReaderType:           "reader",
+			FederatedSpanReaderTypes: []string{"federated"},
+			SpanWriterTypes:          []string{"writer", "reader"},
+			DependenciesStorageType:  "dependencies",
+			SamplingStorageType:      memoryStorageType,
+		},
+		factories: map[string]storage.Factory{
+			"reader": newCapabilitiesFactory(storage.Capabilities{
+				ArchiveStorage:    true,
+				TraceSearch:       true,
+				TagSearch:         true,
+				TagFilters:        true,
+				OperationSpanKind: true,
+				Dependencies:      true,
+			}),
+			"federated": newCapabilitiesFactory(storage.Capabilities{
+				TraceSearch: true,
+				TagSearch:   true,
+			}),
+			"writer": newCapabilitiesFactory(storage.Capabilities{
+				ArchiveStorage:  true,
+				StreamingWrites: true,
+			}),
+			"dependencies":    newCapabilitiesFactory(storage.Capabilities{Dependencies: true}),
+			memoryStorageType: memory.NewFactory(),
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	f := newCapabilitiesTestFactory()
+	assert.Equal(t, storage.Capabilities{
+		ArchiveStorage:   true,
+		StreamingWrites:  true,
+		TraceSearch:      true,
+		TagSearch:        true,
+		Dependencies:     true,
+		AdaptiveSampling: true,
+	}, f.Capabilities())
+
+	// the tag filters must be supported by the federated span reader types as well
+	f.factories["federated"] = newCapabilitiesFactory(storage.Capabilities{TraceSearch: true, TagSearch: true, TagFilters: true})
+	assert.True(t, f.Capabilities().TagFilters)
+
+	// the archive span writer is created by the primary span writer type
+	f.factories["writer"] = newCapabilitiesFactory(storage.Capabilities{})
+	assert.False(t, f.Capabilities().ArchiveStorage)
+	delete(f.factories, "writer")
+	assert.False(t, f.Capabilities().ArchiveStorage)
+
+	f = &Factory{factories: map[string]storage.Factory{}}
+	assert.Equal(t, storage.Capabilities{}, f.Capabilities())
+}
+
+func TestCapabilitiesHandler(t *testing.T) {
+	f := newCapabilitiesTestFactory()
+	w := httptest.NewRecorder()
+	f.CapabilitiesHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/storage/capabilities", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response capabilitiesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, f.Capabilities(), response.Capabilities)
+	assert.Len(t, response.Backends, len(f.factories))
+	assert.Equal(t, storage.Capabilities{Dependencies: true}, response.Backends["dependencies"])
+	assert.Equal(t, memory.NewFactory().Capabilities(), response.Backends[memoryStorageType])
+}
+
 func TestInitialize(t *testing.T) {
 	f, err := NewFactory(defaultCfg())
 	require.NoError(t, err)
@@ -132,7 +231,7 @@ func TestCreate(t *testing.T) {
 	mock.On("CreateDependencyReader").Return(depReader, errors.New("dep-reader-error"))
 
 	r, err := f.CreateSpanReader()
-	assert.Equal(t, spanReader, r)
+	assert.Nil(t, r)
 	require.EqualError(t, err, "span-reader-error")
 
 	w, err := f.CreateSpanWriter()
@@ -140,7 +239,7 @@ func TestCreate(t *testing.T) {
 	require.EqualError(t, err, "span-writer-error")
 
 	d, err := f.CreateDependencyReader()
-	assert.Equal(t, depReader, d)
+	assert.Nil(t, d)
 	require.EqualError(t, err, "dep-reader-error")
 
 	_, err = f.CreateArchiveSpanReader()
@@ -156,7 +255,7 @@ func TestCreate(t *testing.T) {
 	f.Initialize(m, l)
 	w, err = f.CreateSpanWriter()
 	require.NoError(t, err)
-	assert.Equal(t, spanWriter, w)
+	assert.Equal(t, spanStoreMetrics.NewWriteMetricsDecorator(spanWriter, m), w)
 }
 
 func TestCreateDownsamplingWriter(t *testing.T) {
@@ -177,7 +276,7 @@ func TestCreateDownsamplingWriter(t *testing.T) {
 		writerType string
 	}{
 		{0.5, "*spanstore.DownsamplingWriter"},
-		{1.0, "*mocks.Writer"},
+		{1.0, "*metrics.WriteMetricsDecorator"},
 	}
 
 	for _, param := range testParams {
@@ -222,7 +321,179 @@ func TestCreateMulti(t *testing.T) {
 	f.Initialize(m, l)
 	w, err = f.CreateSpanWriter()
 	require.NoError(t, err)
-	assert.Equal(t, spanstore.NewCompositeWriter(spanWriter, spanWriter2), w)
+	assert.Equal(t, spanstore.NewCompositeWriter(
+		spanStoreMetrics.NewWriteMetricsDecorator(spanWriter, m),
+		spanStoreMetrics.NewWriteMetricsDecorator(spanWriter2, m),
+	), w)
+}
+
+// blockingWriter records the written spans, waiting for unblock before each write.
+type blockingWriter struct {
+	unblock chan struct{}
+	written chan *model.Span
+}
+
+func (w *blockingWriter) WriteSpan(_ context.Context, span *model.Span) error {
+	<-w.unblock
+	w.written <- span
+	return nil
+}
+
+func TestCreateMultiFanout(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.SpanWriterTypes = append(cfg.SpanWriterTypes, kafkaStorageType)
+	f, err := NewFactory(cfg)
+	require.NoError(t, err)
+
+	mock := new(mocks.Factory)
+	mock2 := new(mocks.Factory)
+	f.factories[cassandraStorageType] = mock
+	f.factories[kafkaStorageType] = mock2
+
+	v, command := config.Viperize(f.AddPipelineFlags)
+	// fan-out flags are only defined for the secondary backends
+	require.Error(t, command.ParseFlags([]string{"--span-storage.fanout.cassandra.queue-size=5"}))
+	require.NoError(t, command.ParseFlags([]string{
+		"--span-storage.fanout.kafka.queue-size=5",
+		"--span-storage.fanout.kafka.max-retries=1",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, spanstore.AsyncWriterOptions{
+		QueueSize:     5,
+		Workers:       defaultFanoutWorkers,
+		MaxRetries:    1,
+		RetryInterval: defaultFanoutRetryPeriod,
+		DrainTimeout:  fanoutDrainTimeout,
+	}, f.fanout[kafkaStorageType])
+
+	primary := &blockingWriter{unblock: make(chan struct{}), written: make(chan *model.Span, 1)}
+	close(primary.unblock)
+	secondary := &blockingWriter{unblock: make(chan struct{}), written: make(chan *model.Span, 1)}
+	mock.On("CreateSpanWriter").Return(primary, nil)
+	mock2.On("CreateSpanWriter").Return(secondary, nil)
+	m := metrics.NullFactory
+	l := zap.NewNop()
+	mock.On("Initialize", m, l).Return(nil)
+	mock2.On("Initialize", m, l).Return(nil)
+	require.NoError(t, f.Initialize(m, l))
+
+	w, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	span := &model.Span{SpanID: 1}
+	// the blocked secondary backend does not block the primary one
+	require.NoError(t, w.WriteSpan(context.Background(), span))
+	assert.Same(t, span, <-primary.written)
+
+	close(secondary.unblock)
+	require.NoError(t, f.Close())
+	assert.Same(t, span, <-secondary.written)
+}
+
+func TestCreateMultiFanoutWithoutInitialize(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.SpanWriterTypes = append(cfg.SpanWriterTypes, kafkaStorageType)
+	f, err := NewFactory(cfg)
+	require.NoError(t, err)
+
+	mock := new(mocks.Factory)
+	mock2 := new(mocks.Factory)
+	f.factories[cassandraStorageType] = mock
+	f.factories[kafkaStorageType] = mock2
+
+	v, command := config.Viperize(f.AddPipelineFlags)
+	require.NoError(t, command.ParseFlags([]string{"--span-storage.fanout.kafka.queue-size=5"}))
+	f.InitFromViper(v, zap.NewNop())
+
+	mock.On("CreateSpanWriter").Return(new(spanStoreMocks.Writer), nil)
+	mock2.On("CreateSpanWriter").Return(new(spanStoreMocks.Writer), nil)
+	_, err = f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestCreateMultiFanoutDisabled(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.SpanWriterTypes = append(cfg.SpanWriterTypes, kafkaStorageType)
+	f, err := NewFactory(cfg)
+	require.NoError(t, err)
+
+	mock := new(mocks.Factory)
+	mock2 := new(mocks.Factory)
+	f.factories[cassandraStorageType] = mock
+	f.factories[kafkaStorageType] = mock2
+
+	v, command := config.Viperize(f.AddPipelineFlags)
+	require.NoError(t, command.ParseFlags([]string{"--span-storage.fanout.kafka.queue-size=0"}))
+	f.InitFromViper(v, zap.NewNop())
+
+	spanWriter := new(spanStoreMocks.Writer)
+	spanWriter2 := new(spanStoreMocks.Writer)
+	mock.On("CreateSpanWriter").Return(spanWriter, nil)
+	mock2.On("CreateSpanWriter").Return(spanWriter2, nil)
+	m := metrics.NullFactory
+	l := zap.NewNop()
+	mock.On("Initialize", m, l).Return(nil)
+	mock2.On("Initialize", m, l).Return(nil)
+	require.NoError(t, f.Initialize(m, l))
+
+	w, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	assert.Equal(t, spanstore.NewCompositeWriter(
+		spanStoreMetrics.NewWriteMetricsDecorator(spanWriter, m),
+		spanStoreMetrics.NewWriteMetricsDecorator(spanWriter2, m),
+	), w)
+	assert.Empty(t, f.asyncWriters)
+}
+
+func TestCreateFederatedReader(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.FederatedSpanReaderTypes = []string{elasticsearchStorageType}
+	f, err := NewFactory(cfg)
+	require.NoError(t, err)
+	assert.NotEmpty(t, f.factories[elasticsearchStorageType])
+
+	factory := new(mocks.Factory)
+	factory2 := new(mocks.Factory)
+	f.factories[cassandraStorageType] = factory
+	f.factories[elasticsearchStorageType] = factory2
+
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--span-reader.federation.cassandra.max-age=48h",
+		"--span-reader.federation.elasticsearch.min-age=24h",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+
+	m := metrics.NullFactory
+	l := zap.NewNop()
+	factory.On("Initialize", m, l).Return(nil)
+	factory2.On("Initialize", m, l).Return(nil)
+	require.NoError(t, f.Initialize(m, l))
+
+	spanReader := new(spanStoreMocks.Reader)
+	spanReader2 := new(spanStoreMocks.Reader)
+	factory.On("CreateSpanReader").Return(spanReader, nil)
+	factory2.On("CreateSpanReader").Once().Return(nil, errors.New("span-reader-error"))
+
+	_, err = f.CreateSpanReader()
+	require.EqualError(t, err, "span-reader-error")
+
+	factory2.On("CreateSpanReader").Return(spanReader2, nil)
+	r, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	assert.IsType(t, &spanstore.FederatedReader{}, r)
+
+	// only the archive holds spans older than the retention of the primary storage
+	spanReader.On("FindTraceIDs", mock.Anything, mock.Anything).Return(nil, nil)
+	spanReader2.On("FindTraceIDs", mock.Anything, mock.Anything).Return([]model.TraceID{{Low: 1}}, nil)
+	old := time.Now().Add(-72 * time.Hour)
+	traceIDs, err := r.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+		StartTimeMin: old.Add(-time.Hour),
+		StartTimeMax: old,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{{Low: 1}}, traceIDs)
+	spanReader.AssertNotCalled(t, "FindTraceIDs", mock.Anything, mock.Anything)
 }
 
 func TestCreateArchive(t *testing.T) {
@@ -243,14 +514,197 @@ func TestCreateArchive(t *testing.T) {
 	mock.ArchiveFactory.On("CreateArchiveSpanWriter").Return(archiveSpanWriter, errors.New("archive-span-writer-error"))
 
 	ar, err := f.CreateArchiveSpanReader()
-	assert.Equal(t, archiveSpanReader, ar)
+	assert.Nil(t, ar)
 	require.EqualError(t, err, "archive-span-reader-error")
 
 	aw, err := f.CreateArchiveSpanWriter()
-	assert.Equal(t, archiveSpanWriter, aw)
+	assert.Nil(t, aw)
 	require.EqualError(t, err, "archive-span-writer-error")
 }
 
+func TestCreateStorageMetrics(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{
+		SpanWriterTypes:         []string{memoryStorageType},
+		SpanReaderType:          memoryStorageType,
+		DependenciesStorageType: memoryStorageType,
+	})
+	require.NoError(t, err)
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	f.InitFromViper(v, zap.NewNop())
+	metricsFactory := metricstest.NewFactory(0)
+	require.NoError(t, f.Initialize(metricsFactory, zap.NewNop()))
+
+	w, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	r, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	d, err := f.CreateDependencyReader()
+	require.NoError(t, err)
+	// the decorators keep the optional interfaces of the backend
+	_, ok := d.(dependencystore.LatencyReader)
+	assert.True(t, ok)
+
+	span := &model.Span{TraceID: model.NewTraceID(0, 1), SpanID: 1, Process: &model.Process{ServiceName: "service"}}
+	require.NoError(t, w.WriteSpan(context.Background(), span))
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 2))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	_, err = d.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "storage.requests", Tags: map[string]string{"backend": "memory", "role": "primary", "operation": "write_span", "result": "ok"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage.requests", Tags: map[string]string{"backend": "memory", "role": "primary", "operation": "get_trace", "result": "err"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage.errors", Tags: map[string]string{"backend": "memory", "role": "primary", "operation": "get_trace", "error": "not_found"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage.requests", Tags: map[string]string{"backend": "memory", "role": "primary", "operation": "get_dependencies", "result": "ok"}, Value: 1},
+	)
+}
+
+func TestCreateWithTimeouts(t *testing.T) {
+	f, err := NewFactory(defaultCfg())
+	require.NoError(t, err)
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--span-storage.read-timeout=1m",
+		"--span-storage.write-timeout=2m",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, time.Minute, f.readTimeout)
+	assert.Equal(t, 2*time.Minute, f.writeTimeout)
+
+	mockFactory := new(mocks.Factory)
+	f.factories[cassandraStorageType] = mockFactory
+	withDeadline := func(timeout time.Duration) any {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			deadline, ok := ctx.Deadline()
+			return ok && time.Until(deadline) <= timeout && time.Until(deadline) > timeout-time.Minute/2
+		})
+	}
+	spanReader := new(spanStoreMocks.Reader)
+	spanReader.On("GetServices", withDeadline(time.Minute)).Return([]string{}, nil)
+	spanWriter := new(spanStoreMocks.Writer)
+	spanWriter.On("WriteSpan", withDeadline(2*time.Minute), &model.Span{}).Return(nil)
+	depReader := new(depStoreMocks.Reader)
+	depReader.On("GetDependencies", withDeadline(time.Minute), mock.Anything, time.Hour).Return(nil, nil)
+	mockFactory.On("CreateSpanReader").Return(spanReader, nil)
+	mockFactory.On("CreateSpanWriter").Return(spanWriter, nil)
+	mockFactory.On("CreateDependencyReader").Return(depReader, nil)
+	mockFactory.On("Initialize", metrics.NullFactory, zap.NewNop()).Return(nil)
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+	r, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	_, err = r.GetServices(context.Background())
+	require.NoError(t, err)
+	w, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSpan(context.Background(), &model.Span{}))
+	d, err := f.CreateDependencyReader()
+	require.NoError(t, err)
+	_, err = d.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	spanReader.AssertExpectations(t)
+	spanWriter.AssertExpectations(t)
+	depReader.AssertExpectations(t)
+}
+
+func TestCreateWarningStore(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{
+		SpanWriterTypes:         []string{memoryStorageType},
+		SpanReaderType:          memoryStorageType,
+		DependenciesStorageType: memoryStorageType,
+	})
+	require.NoError(t, err)
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	store, err := f.CreateWarningStore()
+	require.NoError(t, err)
+	assert.NotNil(t, store)
+
+	f.factories[memoryStorageType] = &mocks.Factory{}
+	_, err = f.CreateWarningStore()
+	require.ErrorIs(t, err, storage.ErrWarningStorageNotSupported)
+
+	delete(f.factories, memoryStorageType)
+	_, err = f.CreateWarningStore()
+	require.EqualError(t, err, "no memory backend registered for span store")
+}
+
+func TestCreateMetadataStore(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{
+		SpanWriterTypes:         []string{memoryStorageType},
+		SpanReaderType:          memoryStorageType,
+		DependenciesStorageType: memoryStorageType,
+	})
+	require.NoError(t, err)
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	store, err := f.CreateMetadataStore()
+	require.NoError(t, err)
+	assert.NotNil(t, store)
+
+	f.factories[memoryStorageType] = &mocks.Factory{}
+	_, err = f.CreateMetadataStore()
+	require.ErrorIs(t, err, storage.ErrMetadataStorageNotSupported)
+
+	delete(f.factories, memoryStorageType)
+	_, err = f.CreateMetadataStore()
+	require.EqualError(t, err, "no memory backend registered for span store")
+}
+
+func TestCreateSamplingStrategyStore(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{
+		SpanWriterTypes:         []string{memoryStorageType},
+		SpanReaderType:          memoryStorageType,
+		DependenciesStorageType: memoryStorageType,
+	})
+	require.NoError(t, err)
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	store, err := f.CreateSamplingStrategyStore()
+	require.NoError(t, err)
+	assert.NotNil(t, store)
+
+	f.factories[memoryStorageType] = &mocks.Factory{}
+	_, err = f.CreateSamplingStrategyStore()
+	require.ErrorIs(t, err, storage.ErrSamplingStrategyStorageNotSupported)
+
+	// the sampling storage takes precedence over the span storage
+	f.SamplingStorageType = badgerStorageType
+	_, err = f.CreateSamplingStrategyStore()
+	require.EqualError(t, err, "no badger backend registered for sampling strategies store")
+}
+
+func TestCreateSavedSearchStore(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{
+		SpanWriterTypes:         []string{memoryStorageType},
+		SpanReaderType:          memoryStorageType,
+		DependenciesStorageType: memoryStorageType,
+	})
+	require.NoError(t, err)
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	store, err := f.CreateSavedSearchStore()
+	require.NoError(t, err)
+	assert.NotNil(t, store)
+
+	f.factories[memoryStorageType] = &mocks.Factory{}
+	_, err = f.CreateSavedSearchStore()
+	require.ErrorIs(t, err, storage.ErrSavedSearchStorageNotSupported)
+
+	delete(f.factories, memoryStorageType)
+	_, err = f.CreateSavedSearchStore()
+	require.EqualError(t, err, "no memory backend registered for span store")
+}
+
 func TestCreateError(t *testing.T) {
 	f, err := NewFactory(defaultCfg())
 	require.NoError(t, err)
@@ -292,7 +746,7 @@ func TestCreateError(t *testing.T) {
 }
 
 func TestAllSamplingStorageTypes(t *testing.T) {
-	assert.Equal(t, []string{"cassandra", "memory", "badger"}, AllSamplingStorageTypes())
+	assert.Equal(t, []string{"cassandra", "memory", "badger", "sqlite"}, AllSamplingStorageTypes())
 }
 
 func TestCreateSamplingStoreFactory(t *testing.T) {
@@ -414,12 +868,14 @@ func TestPublishOpts(t *testing.T) {
 }
 
 type errorFactory struct {
-	closeErr error
+	closeErr  error
+	healthErr error
 }
 
 var (
-	_ storage.Factory = (*errorFactory)(nil)
-	_ io.Closer       = (*errorFactory)(nil)
+	_ storage.Factory       = (*errorFactory)(nil)
+	_ storage.HealthChecker = (*errorFactory)(nil)
+	_ io.Closer             = (*errorFactory)(nil)
 )
 
 func (errorFactory) Initialize(metrics.Factory, *zap.Logger) error {
@@ -438,6 +894,14 @@ func (errorFactory) CreateDependencyReader() (dependencystore.Reader, error) {
 	panic("implement me")
 }
 
+func (errorFactory) Capabilities() storage.Capabilities {
+	panic("implement me")
+}
+
 func (e errorFactory) Close() error {
 	return e.closeErr
 }
+
+func (e errorFactory) CheckHealth(context.Context) error {
+	return e.healthErr
+}
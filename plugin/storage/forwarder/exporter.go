@@ -0,0 +1,54 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+
+	"github.com/jaegertracing/jaeger/model"
+	model2otel "github.com/jaegertracing/jaeger/pkg/otlptranslator"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+// exporter sends a batch of spans to another collector.
+type exporter interface {
+	export(ctx context.Context, spans []*model.Span) error
+}
+
+// jaegerExporter uses the jaeger-proto gRPC collector service.
+type jaegerExporter struct {
+	client api_v2.CollectorServiceClient
+}
+
+func newJaegerExporter(conn *grpc.ClientConn) *jaegerExporter {
+	return &jaegerExporter{client: api_v2.NewCollectorServiceClient(conn)}
+}
+
+func (e *jaegerExporter) export(ctx context.Context, spans []*model.Span) error {
+	_, err := e.client.PostSpans(ctx, &api_v2.PostSpansRequest{
+		Batch: model.Batch{Spans: spans},
+	})
+	return err
+}
+
+// otlpExporter uses the OTLP gRPC trace service.
+type otlpExporter struct {
+	client ptraceotlp.GRPCClient
+}
+
+func newOTLPExporter(conn *grpc.ClientConn) *otlpExporter {
+	return &otlpExporter{client: ptraceotlp.NewGRPCClient(conn)}
+}
+
+func (e *otlpExporter) export(ctx context.Context, spans []*model.Span) error {
+	td, err := model2otel.ProtoToTraces([]*model.Batch{{Spans: spans}})
+	if err != nil {
+		return err
+	}
+	_, err = e.client.Export(ctx, ptraceotlp.NewExportRequestFromTraces(td))
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory     = (*Factory)(nil)
+	_ io.Closer           = (*Factory)(nil)
+	_ plugin.Configurable = (*Factory)(nil)
+)
+
+var errWriteOnly = errors.New("forwarder storage is write-only")
+
+// Factory implements storage.Factory and creates write-only storage components
+// that forward spans to another collector.
+type Factory struct {
+	options Options
+
+	metricsFactory metrics.Factory
+	logger         *zap.Logger
+
+	conn     *grpc.ClientConn
+	exporter exporter
+	writer   *SpanWriter
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, logger *zap.Logger) {
+	if err := f.options.InitFromViper(v); err != nil {
+		logger.Fatal("unable to initialize forwarder storage factory", zap.Error(err))
+	}
+}
+
+// configureFromOptions initializes factory from options.
+func (f *Factory) configureFromOptions(o Options) {
+	f.options = o
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.metricsFactory, f.logger = metricsFactory, logger
+	logger.Info("Forwarder factory",
+		zap.String("endpoint", f.options.Endpoint),
+		zap.String("protocol", f.options.Protocol))
+
+	creds := insecure.NewCredentials()
+	if f.options.TLS.Enabled {
+		tlsCfg, err := f.options.TLS.Config(logger)
+		if err != nil {
+			return err
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+	conn, err := grpc.NewClient(f.options.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("cannot create forwarder client: %w", err)
+	}
+	switch f.options.Protocol {
+	case ProtocolOTLP:
+		f.exporter = newOTLPExporter(conn)
+	case ProtocolJaeger:
+		f.exporter = newJaegerExporter(conn)
+	default:
+		return errors.Join(
+			fmt.Errorf("forwarder protocol '%s' is not one of '%s' or '%s'", f.options.Protocol, ProtocolOTLP, ProtocolJaeger),
+			conn.Close())
+	}
+	f.conn = conn
+	return nil
+}
+
+// CreateSpanReader implements storage.Factory
+func (*Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return nil, errWriteOnly
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	if f.writer == nil {
+		f.writer = newSpanWriter(f.exporter, f.options, f.metricsFactory, f.logger)
+	}
+	return f.writer, nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (*Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return nil, errWriteOnly
+}
+
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{StreamingWrites: true}
+}
+
+// Close forwards the buffered spans and closes the connection
+func (f *Factory) Close() error {
+	var errs []error
+	if f.writer != nil {
+		errs = append(errs, f.writer.Close())
+	}
+	if f.conn != nil {
+		errs = append(errs, f.conn.Close())
+	}
+	errs = append(errs, f.options.TLS.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+// collector receives the forwarded spans, recording the received span IDs.
+type collector struct {
+	ptraceotlp.UnimplementedGRPCServer
+	api_v2.UnimplementedCollectorServiceServer
+
+	sync.Mutex
+	spanIDs []uint64
+}
+
+func (c *collector) Export(_ context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+	rss := req.Traces().ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				id := spans.At(k).SpanID()
+				var spanID uint64
+				for _, b := range id {
+					spanID = spanID<<8 | uint64(b)
+				}
+				c.spanIDs = append(c.spanIDs, spanID)
+			}
+		}
+	}
+	return ptraceotlp.NewExportResponse(), nil
+}
+
+func (c *collector) PostSpans(_ context.Context, req *api_v2.PostSpansRequest) (*api_v2.PostSpansResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+	for _, span := range req.Batch.Spans {
+		c.spanIDs = append(c.spanIDs, uint64(span.SpanID))
+	}
+	return &api_v2.PostSpansResponse{}, nil
+}
+
+func (c *collector) received() []uint64 {
+	c.Lock()
+	defer c.Unlock()
+	return append([]uint64(nil), c.spanIDs...)
+}
+
+func startCollector(t *testing.T) (*collector, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &collector{}
+	server := grpc.NewServer()
+	ptraceotlp.RegisterGRPCServer(server, c)
+	api_v2.RegisterCollectorServiceServer(server, c)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return c, lis.Addr().String()
+}
+
+func TestForwarderFactory(t *testing.T) {
+	for _, protocol := range allProtocols {
+		t.Run(protocol, func(t *testing.T) {
+			c, endpoint := startCollector(t)
+			f := NewFactory()
+			opts := testOptions()
+			opts.Endpoint = endpoint
+			opts.Protocol = protocol
+			f.configureFromOptions(opts)
+			require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+			_, err := f.CreateSpanReader()
+			require.ErrorIs(t, err, errWriteOnly)
+			_, err = f.CreateDependencyReader()
+			require.ErrorIs(t, err, errWriteOnly)
+			assert.Equal(t, storage.Capabilities{StreamingWrites: true}, f.Capabilities())
+
+			w, err := f.CreateSpanWriter()
+			require.NoError(t, err)
+			w2, err := f.CreateSpanWriter()
+			require.NoError(t, err)
+			assert.Same(t, w, w2)
+			for i := uint64(1); i <= 3; i++ {
+				require.NoError(t, w.WriteSpan(context.Background(), span(i)))
+			}
+			assert.Eventually(t, func() bool {
+				return len(c.received()) == 2
+			}, 5*time.Second, time.Millisecond)
+			require.NoError(t, f.Close())
+			assert.ElementsMatch(t, []uint64{1, 2, 3}, c.received())
+		})
+	}
+}
+
+func TestForwarderFactoryInitFromViper(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--forwarder.endpoint=central:4317"}))
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, "central:4317", f.options.Endpoint)
+}
+
+func TestForwarderFactoryInitializeErrors(t *testing.T) {
+	f := NewFactory()
+	f.configureFromOptions(Options{Endpoint: "127.0.0.1:4317", Protocol: "zipkin"})
+	require.ErrorContains(t, f.Initialize(metrics.NullFactory, zap.NewNop()), "forwarder protocol 'zipkin' is not one of")
+	require.NoError(t, f.Close())
+
+	f = NewFactory()
+	f.configureFromOptions(Options{
+		Endpoint: "127.0.0.1:4317",
+		Protocol: ProtocolOTLP,
+		TLS:      tlscfg.Options{Enabled: true, CAPath: "/does/not/exist"},
+	})
+	require.Error(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	require.NoError(t, f.Close())
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+const (
+	// ProtocolOTLP forwards spans with the OTLP gRPC trace service.
+	ProtocolOTLP = "otlp"
+	// ProtocolJaeger forwards spans with the jaeger-proto gRPC collector service.
+	ProtocolJaeger = "jaeger"
+
+	configPrefix               = "forwarder"
+	suffixEndpoint             = ".endpoint"
+	suffixProtocol             = ".protocol"
+	suffixTimeout              = ".timeout"
+	suffixQueueSize            = ".queue-size"
+	suffixWorkers              = ".workers"
+	suffixBatchSize            = ".batch-size"
+	suffixFlushInterval        = ".flush-interval"
+	suffixMaxRetries           = ".retry.max-retries"
+	suffixRetryInitialInterval = ".retry.initial-interval"
+	suffixRetryMaxInterval     = ".retry.max-interval"
+
+	defaultEndpoint             = "127.0.0.1:4317"
+	defaultProtocol             = ProtocolOTLP
+	defaultTimeout              = 5 * time.Second
+	defaultQueueSize            = 100
+	defaultWorkers              = 4
+	defaultBatchSize            = 500
+	defaultFlushInterval        = time.Second
+	defaultMaxRetries           = 5
+	defaultRetryInitialInterval = 100 * time.Millisecond
+	defaultRetryMaxInterval     = 5 * time.Second
+)
+
+// allProtocols lists the supported forwarding protocols
+var allProtocols = []string{ProtocolOTLP, ProtocolJaeger}
+
+// Options stores the configuration of the forwarder
+type Options struct {
+	// Endpoint is the host:port of the collector spans are forwarded to.
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol is either ProtocolOTLP or ProtocolJaeger.
+	Protocol string         `mapstructure:"protocol"`
+	TLS      tlscfg.Options `mapstructure:"tls"`
+	// Timeout applies to each export request.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// QueueSize is the number of batches waiting to be exported, batches are dropped when the queue is full.
+	QueueSize int `mapstructure:"queue_size"`
+	// Workers is the number of batches exported concurrently.
+	Workers int `mapstructure:"workers"`
+	// BatchSize is the maximum number of spans exported in one request.
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushInterval is the maximum time a span waits for its batch to fill.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	Retry         RetryOptions  `mapstructure:"retry"`
+}
+
+// RetryOptions configures the exponential backoff of failed exports
+type RetryOptions struct {
+	MaxRetries      int           `mapstructure:"max_retries"`
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	MaxInterval     time.Duration `mapstructure:"max_interval"`
+}
+
+func tlsFlagsConfig() tlscfg.ClientFlagsConfig {
+	return tlscfg.ClientFlagsConfig{
+		Prefix: configPrefix,
+	}
+}
+
+// AddFlags adds flags for Options
+func (*Options) AddFlags(flagSet *flag.FlagSet) {
+	tlsFlagsConfig().AddFlags(flagSet)
+	flagSet.String(
+		configPrefix+suffixEndpoint,
+		defaultEndpoint,
+		"The host:port of the collector to forward spans to")
+	flagSet.String(
+		configPrefix+suffixProtocol,
+		defaultProtocol,
+		fmt.Sprintf(`The gRPC protocol used to forward spans ("%s")`, strings.Join(allProtocols, `", "`)))
+	flagSet.Duration(
+		configPrefix+suffixTimeout,
+		defaultTimeout,
+		"The timeout of each request forwarding spans")
+	flagSet.Int(
+		configPrefix+suffixQueueSize,
+		defaultQueueSize,
+		"The maximum number of span batches waiting to be forwarded, new batches are dropped when the queue is full")
+	flagSet.Int(
+		configPrefix+suffixWorkers,
+		defaultWorkers,
+		"The number of span batches forwarded concurrently")
+	flagSet.Int(
+		configPrefix+suffixBatchSize,
+		defaultBatchSize,
+		"The maximum number of spans forwarded in one request")
+	flagSet.Duration(
+		configPrefix+suffixFlushInterval,
+		defaultFlushInterval,
+		"The maximum time spans are buffered before being forwarded")
+	flagSet.Int(
+		configPrefix+suffixMaxRetries,
+		defaultMaxRetries,
+		"The maximum number of times a failed request is retried before its spans are dropped")
+	flagSet.Duration(
+		configPrefix+suffixRetryInitialInterval,
+		defaultRetryInitialInterval,
+		"The time to wait before the first retry, doubled on each retry")
+	flagSet.Duration(
+		configPrefix+suffixRetryMaxInterval,
+		defaultRetryMaxInterval,
+		"The maximum time to wait between retries")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (o *Options) InitFromViper(v *viper.Viper) error {
+	o.Endpoint = v.GetString(configPrefix + suffixEndpoint)
+	o.Protocol = v.GetString(configPrefix + suffixProtocol)
+	o.Timeout = v.GetDuration(configPrefix + suffixTimeout)
+	o.QueueSize = v.GetInt(configPrefix + suffixQueueSize)
+	o.Workers = v.GetInt(configPrefix + suffixWorkers)
+	o.BatchSize = v.GetInt(configPrefix + suffixBatchSize)
+	o.FlushInterval = v.GetDuration(configPrefix + suffixFlushInterval)
+	o.Retry.MaxRetries = v.GetInt(configPrefix + suffixMaxRetries)
+	o.Retry.InitialInterval = v.GetDuration(configPrefix + suffixRetryInitialInterval)
+	o.Retry.MaxInterval = v.GetDuration(configPrefix + suffixRetryMaxInterval)
+	var err error
+	o.TLS, err = tlsFlagsConfig().InitFromViper(v)
+	if err != nil {
+		return fmt.Errorf("failed to parse forwarder TLS options: %w", err)
+	}
+	return nil
+}
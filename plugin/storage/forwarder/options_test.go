@@ -0,0 +1,77 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	err := command.ParseFlags([]string{
+		"--forwarder.endpoint=central:14250",
+		"--forwarder.protocol=jaeger",
+		"--forwarder.timeout=2s",
+		"--forwarder.queue-size=10",
+		"--forwarder.workers=2",
+		"--forwarder.batch-size=50",
+		"--forwarder.flush-interval=100ms",
+		"--forwarder.retry.max-retries=3",
+		"--forwarder.retry.initial-interval=10ms",
+		"--forwarder.retry.max-interval=1s",
+		"--forwarder.tls.enabled=true",
+		"--forwarder.tls.server-name=central",
+	})
+	require.NoError(t, err)
+	require.NoError(t, opts.InitFromViper(v))
+
+	assert.Equal(t, "central:14250", opts.Endpoint)
+	assert.Equal(t, ProtocolJaeger, opts.Protocol)
+	assert.Equal(t, 2*time.Second, opts.Timeout)
+	assert.Equal(t, 10, opts.QueueSize)
+	assert.Equal(t, 2, opts.Workers)
+	assert.Equal(t, 50, opts.BatchSize)
+	assert.Equal(t, 100*time.Millisecond, opts.FlushInterval)
+	assert.Equal(t, RetryOptions{MaxRetries: 3, InitialInterval: 10 * time.Millisecond, MaxInterval: time.Second}, opts.Retry)
+	assert.True(t, opts.TLS.Enabled)
+	assert.Equal(t, "central", opts.TLS.ServerName)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, _ := config.Viperize(opts.AddFlags)
+	require.NoError(t, opts.InitFromViper(v))
+
+	assert.Equal(t, defaultEndpoint, opts.Endpoint)
+	assert.Equal(t, defaultProtocol, opts.Protocol)
+	assert.Equal(t, defaultTimeout, opts.Timeout)
+	assert.Equal(t, defaultQueueSize, opts.QueueSize)
+	assert.Equal(t, defaultWorkers, opts.Workers)
+	assert.Equal(t, defaultBatchSize, opts.BatchSize)
+	assert.Equal(t, defaultFlushInterval, opts.FlushInterval)
+	assert.Equal(t, RetryOptions{
+		MaxRetries:      defaultMaxRetries,
+		InitialInterval: defaultRetryInitialInterval,
+		MaxInterval:     defaultRetryMaxInterval,
+	}, opts.Retry)
+	assert.False(t, opts.TLS.Enabled)
+}
+
+func TestOptionsInvalidTLS(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	err := command.ParseFlags([]string{
+		"--forwarder.tls.enabled=false",
+		"--forwarder.tls.cert=/some/cert",
+	})
+	require.NoError(t, err)
+	require.ErrorContains(t, opts.InitFromViper(v), "failed to parse forwarder TLS options")
+}
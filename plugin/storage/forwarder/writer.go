@@ -0,0 +1,184 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/queue"
+)
+
+// drainCheckInterval is how often Close checks whether the queue is empty.
+const drainCheckInterval = 10 * time.Millisecond
+
+// retryableCodes are the gRPC status codes for which exports are retried, as recommended by the OTLP specification.
+var retryableCodes = map[codes.Code]struct{}{
+	codes.Canceled:          {},
+	codes.DeadlineExceeded:  {},
+	codes.ResourceExhausted: {},
+	codes.Aborted:           {},
+	codes.OutOfRange:        {},
+	codes.Unavailable:       {},
+	codes.DataLoss:          {},
+}
+
+type spanWriterMetrics struct {
+	SpansWrittenSuccess metrics.Counter
+	SpansWrittenFailure metrics.Counter
+	SpansDropped        metrics.Counter
+	Retries             metrics.Counter
+	QueueLength         metrics.Gauge
+}
+
+// SpanWriter buffers spans into batches and forwards them to another collector. Implements spanstore.Writer
+type SpanWriter struct {
+	exporter exporter
+	options  Options
+	metrics  spanWriterMetrics
+	logger   *zap.Logger
+	queue    *queue.BoundedQueue
+
+	batchLock sync.Mutex
+	batch     []*model.Span
+
+	// ctx is canceled on Close to abort pending retries
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	flushDone chan struct{}
+}
+
+func newSpanWriter(exporter exporter, options Options, factory metrics.Factory, logger *zap.Logger) *SpanWriter {
+	writeMetrics := spanWriterMetrics{
+		SpansWrittenSuccess: factory.Counter(metrics.Options{Name: "forwarder_spans_written", Tags: map[string]string{"status": "success"}}),
+		SpansWrittenFailure: factory.Counter(metrics.Options{Name: "forwarder_spans_written", Tags: map[string]string{"status": "failure"}}),
+		SpansDropped:        factory.Counter(metrics.Options{Name: "forwarder_spans_dropped"}),
+		Retries:             factory.Counter(metrics.Options{Name: "forwarder_retries"}),
+		QueueLength:         factory.Gauge(metrics.Options{Name: "forwarder_queue_length"}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &SpanWriter{
+		exporter:  exporter,
+		options:   options,
+		metrics:   writeMetrics,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+		flushDone: make(chan struct{}),
+	}
+	w.queue = queue.NewBoundedQueue(options.QueueSize, func(item any) {
+		w.metrics.SpansDropped.Inc(int64(len(item.([]*model.Span))))
+	})
+	w.queue.StartConsumers(options.Workers, func(item any) {
+		w.export(item.([]*model.Span))
+	})
+	w.queue.StartLengthReporting(time.Second, w.metrics.QueueLength)
+	go w.flushPeriodically()
+	return w
+}
+
+// WriteSpan adds the span to the current batch, which is queued for forwarding when it is full.
+func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
+	w.batchLock.Lock()
+	w.batch = append(w.batch, span)
+	var batch []*model.Span
+	if len(w.batch) >= w.options.BatchSize {
+		batch, w.batch = w.batch, nil
+	}
+	w.batchLock.Unlock()
+	if batch != nil {
+		w.queue.Produce(batch)
+	}
+	return nil
+}
+
+// Close forwards the buffered spans, waiting up to the export timeout, and stops the workers.
+func (w *SpanWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.flushDone)
+		w.flush()
+		deadline := time.Now().Add(w.options.Timeout)
+		stopped := make(chan struct{})
+		go func() {
+			for w.queue.Size() > 0 && time.Now().Before(deadline) {
+				time.Sleep(drainCheckInterval)
+			}
+			// waits for the exports in progress
+			w.queue.Stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Until(deadline)):
+		}
+		w.cancel()
+		<-stopped
+	})
+	return nil
+}
+
+func (w *SpanWriter) flushPeriodically() {
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.flushDone:
+			return
+		}
+	}
+}
+
+// flush queues the current batch, even if it is not full.
+func (w *SpanWriter) flush() {
+	w.batchLock.Lock()
+	batch := w.batch
+	w.batch = nil
+	w.batchLock.Unlock()
+	if len(batch) > 0 {
+		w.queue.Produce(batch)
+	}
+}
+
+// export sends the batch, retrying with an exponential backoff on transient errors.
+func (w *SpanWriter) export(batch []*model.Span) {
+	interval := w.options.Retry.InitialInterval
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(w.ctx, w.options.Timeout)
+		err := w.exporter.export(ctx, batch)
+		cancel()
+		if err == nil {
+			w.metrics.SpansWrittenSuccess.Inc(int64(len(batch)))
+			return
+		}
+		if !isRetryable(err) || attempt >= w.options.Retry.MaxRetries {
+			w.logger.Error("Failed to forward spans", zap.Int("spans", len(batch)), zap.Int("attempts", attempt+1), zap.Error(err))
+			w.metrics.SpansWrittenFailure.Inc(int64(len(batch)))
+			return
+		}
+		w.metrics.Retries.Inc(1)
+		select {
+		case <-time.After(interval):
+		case <-w.ctx.Done():
+			w.logger.Error("Dropping spans that failed to be forwarded before shutdown", zap.Int("spans", len(batch)), zap.Error(err))
+			w.metrics.SpansWrittenFailure.Inc(int64(len(batch)))
+			return
+		}
+		interval = min(2*interval, w.options.Retry.MaxInterval)
+	}
+}
+
+func isRetryable(err error) bool {
+	_, ok := retryableCodes[status.Code(err)]
+	return ok
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// fakeExporter records the exported batches, failing with the given errors first.
+type fakeExporter struct {
+	sync.Mutex
+	errs    []error
+	batches [][]*model.Span
+	calls   int
+}
+
+func (e *fakeExporter) export(_ context.Context, spans []*model.Span) error {
+	e.Lock()
+	defer e.Unlock()
+	e.calls++
+	if len(e.errs) > 0 {
+		err := e.errs[0]
+		e.errs = e.errs[1:]
+		return err
+	}
+	e.batches = append(e.batches, spans)
+	return nil
+}
+
+func (e *fakeExporter) exported() [][]*model.Span {
+	e.Lock()
+	defer e.Unlock()
+	return append([][]*model.Span(nil), e.batches...)
+}
+
+func testOptions() Options {
+	return Options{
+		Timeout:       time.Second,
+		QueueSize:     10,
+		Workers:       1,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Retry: RetryOptions{
+			MaxRetries:      2,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+		},
+	}
+}
+
+func span(id uint64) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, 1),
+		SpanID:        model.NewSpanID(id),
+		OperationName: "op",
+		Process:       &model.Process{ServiceName: "svc"},
+	}
+}
+
+func TestSpanWriterBatches(t *testing.T) {
+	exp := &fakeExporter{}
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	w := newSpanWriter(exp, testOptions(), metricsFactory, zap.NewNop())
+
+	for i := uint64(1); i <= 3; i++ {
+		require.NoError(t, w.WriteSpan(context.Background(), span(i)))
+	}
+	assert.Eventually(t, func() bool {
+		return len(exp.exported()) == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []*model.Span{span(1), span(2)}, exp.exported()[0])
+
+	// the incomplete batch is flushed on close
+	require.NoError(t, w.Close())
+	require.Len(t, exp.exported(), 2)
+	assert.Equal(t, []*model.Span{span(3)}, exp.exported()[1])
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "forwarder_spans_written", Tags: map[string]string{"status": "success"}, Value: 3,
+	})
+}
+
+func TestSpanWriterFlushInterval(t *testing.T) {
+	exp := &fakeExporter{}
+	opts := testOptions()
+	opts.FlushInterval = time.Millisecond
+	w := newSpanWriter(exp, opts, metricstest.NewFactory(0), zap.NewNop())
+	defer w.Close()
+
+	require.NoError(t, w.WriteSpan(context.Background(), span(1)))
+	assert.Eventually(t, func() bool {
+		return len(exp.exported()) == 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestSpanWriterRetries(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		success       int
+		failure       int
+	}{
+		{
+			name:          "retried until success",
+			errs:          []error{unavailable, unavailable},
+			expectedCalls: 3,
+			success:       2,
+		},
+		{
+			name:          "max retries exceeded",
+			errs:          []error{unavailable, unavailable, unavailable},
+			expectedCalls: 3,
+			failure:       2,
+		},
+		{
+			name:          "non retryable error",
+			errs:          []error{status.Error(codes.InvalidArgument, "invalid")},
+			expectedCalls: 1,
+			failure:       2,
+		},
+		{
+			name:          "conversion error",
+			errs:          []error{errors.New("cannot convert")},
+			expectedCalls: 1,
+			failure:       2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exp := &fakeExporter{errs: test.errs}
+			metricsFactory := metricstest.NewFactory(0)
+			defer metricsFactory.Stop()
+			w := newSpanWriter(exp, testOptions(), metricsFactory, zap.NewNop())
+			require.NoError(t, w.WriteSpan(context.Background(), span(1)))
+			require.NoError(t, w.WriteSpan(context.Background(), span(2)))
+			require.NoError(t, w.Close())
+
+			assert.Equal(t, test.expectedCalls, exp.calls)
+			metricsFactory.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "forwarder_spans_written", Tags: map[string]string{"status": "success"}, Value: test.success},
+				metricstest.ExpectedMetric{Name: "forwarder_spans_written", Tags: map[string]string{"status": "failure"}, Value: test.failure},
+			)
+		})
+	}
+}
+
+func TestSpanWriterCloseAbortsRetries(t *testing.T) {
+	exp := &fakeExporter{errs: []error{status.Error(codes.Unavailable, "unavailable")}}
+	opts := testOptions()
+	opts.Timeout = 10 * time.Millisecond
+	opts.Retry.InitialInterval = time.Hour
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	w := newSpanWriter(exp, opts, metricsFactory, zap.NewNop())
+	require.NoError(t, w.WriteSpan(context.Background(), span(1)))
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "forwarder_spans_written", Tags: map[string]string{"status": "failure"}, Value: 1,
+	})
+}
+
+func TestSpanWriterDropsWhenQueueIsFull(t *testing.T) {
+	opts := testOptions()
+	opts.QueueSize = 0
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	w := newSpanWriter(&fakeExporter{}, opts, metricsFactory, zap.NewNop())
+	require.NoError(t, w.WriteSpan(context.Background(), span(1)))
+	require.NoError(t, w.WriteSpan(context.Background(), span(2)))
+	require.NoError(t, w.Close())
+
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "forwarder_spans_dropped", Value: 2})
+}
@@ -21,6 +21,7 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configcompression"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -38,6 +39,7 @@ type Configuration struct {
 	RemoteServerAddr     string `yaml:"server" mapstructure:"server"`
 	RemoteTLS            tlscfg.Options
 	RemoteConnectTimeout time.Duration `yaml:"connection-timeout" mapstructure:"connection-timeout"`
+	RemoteCompression    string        `yaml:"compression" mapstructure:"compression"`
 	TenancyOpts          tenancy.Options
 }
 
@@ -58,8 +60,9 @@ func DefaultConfigV2() ConfigV2 {
 func (c *Configuration) TranslateToConfigV2() *ConfigV2 {
 	return &ConfigV2{
 		ClientConfig: configgrpc.ClientConfig{
-			Endpoint:   c.RemoteServerAddr,
-			TLSSetting: c.RemoteTLS.ToOtelClientConfig(),
+			Endpoint:    c.RemoteServerAddr,
+			TLSSetting:  c.RemoteTLS.ToOtelClientConfig(),
+			Compression: configcompression.Type(c.RemoteCompression),
 		},
 		TimeoutSettings: exporterhelper.TimeoutSettings{
 			Timeout: c.RemoteConnectTimeout,
@@ -19,6 +19,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel"
@@ -51,6 +52,8 @@ type Factory struct {
 	configV2 *ConfigV2
 
 	services *ClientPluginServices
+	// spanWriters are the span writers created, closed with the factory
+	spanWriters []io.Closer
 }
 
 // NewFactory creates a new Factory.
@@ -107,14 +110,19 @@ func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
 	return f.services.Store.SpanReader(), nil
 }
 
-// CreateSpanWriter implements storage.Factory
+// CreateSpanWriter implements storage.Factory.
+// The span writer is closed, flushing the writes of the plugin, when the factory is closed.
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	writer := f.services.Store.SpanWriter()
 	if f.services.Capabilities != nil && f.services.StreamingSpanWriter != nil {
 		if capabilities, err := f.services.Capabilities.Capabilities(); err == nil && capabilities.StreamingSpanWriter {
-			return f.services.StreamingSpanWriter.StreamingSpanWriter(), nil
+			writer = f.services.StreamingSpanWriter.StreamingSpanWriter()
 		}
 	}
-	return f.services.Store.SpanWriter(), nil
+	if closer, ok := writer.(io.Closer); ok && !slices.Contains(f.spanWriters, closer) {
+		f.spanWriters = append(f.spanWriters, closer)
+	}
+	return writer, nil
 }
 
 // CreateDependencyReader implements storage.Factory
@@ -122,6 +130,26 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return f.services.Store.DependencyReader(), nil
 }
 
+// Capabilities implements storage.Factory, with the capabilities of the plugin
+func (f *Factory) Capabilities() storage.Capabilities {
+	capabilities := storage.Capabilities{
+		TraceSearch:       true,
+		TagSearch:         true,
+		OperationSpanKind: true,
+		Dependencies:      true,
+	}
+	if f.services == nil || f.services.Capabilities == nil {
+		return capabilities
+	}
+	pluginCapabilities, err := f.services.Capabilities.Capabilities()
+	if err != nil || pluginCapabilities == nil {
+		return capabilities
+	}
+	capabilities.ArchiveStorage = pluginCapabilities.ArchiveSpanReader && pluginCapabilities.ArchiveSpanWriter
+	capabilities.StreamingWrites = pluginCapabilities.StreamingSpanWriter && f.services.StreamingSpanWriter != nil
+	return capabilities
+}
+
 // CreateArchiveSpanReader implements storage.ArchiveFactory
 func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
 	if f.services.Capabilities == nil {
@@ -155,6 +183,11 @@ func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
 // Close closes the resources held by the factory
 func (f *Factory) Close() error {
 	var errs []error
+	// the span writers are closed first, while the connection to the plugin is open
+	for _, writer := range f.spanWriters {
+		errs = append(errs, writer.Close())
+	}
+	f.spanWriters = nil
 	if f.services != nil {
 		errs = append(errs, f.services.Close())
 	}
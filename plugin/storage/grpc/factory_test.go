@@ -252,6 +252,36 @@ func TestGRPCStorageFactory_CapabilitiesNil(t *testing.T) {
 	assert.NotNil(t, writer, "regular span writer is available")
 }
 
+func TestGRPCStorageFactory_StorageCapabilities(t *testing.T) {
+	f := makeFactory(t)
+
+	all := &shared.Capabilities{
+		ArchiveSpanReader:   true,
+		ArchiveSpanWriter:   true,
+		StreamingSpanWriter: true,
+	}
+	capabilities := f.services.Capabilities.(*mocks.PluginCapabilities)
+	capabilities.
+		On("Capabilities").Return(nil, errors.New("made-up error")).Once().
+		On("Capabilities").Return(&shared.Capabilities{ArchiveSpanReader: true}, nil).Once().
+		On("Capabilities").Return(all, nil).Once()
+
+	expected := storage.Capabilities{
+		TraceSearch:       true,
+		TagSearch:         true,
+		OperationSpanKind: true,
+		Dependencies:      true,
+	}
+	assert.Equal(t, expected, f.Capabilities())
+	assert.Equal(t, expected, f.Capabilities(), "the archive storage needs both a reader and a writer")
+	expected.ArchiveStorage = true
+	expected.StreamingWrites = true
+	assert.Equal(t, expected, f.Capabilities())
+
+	f.services.Capabilities = nil
+	assert.False(t, f.Capabilities().ArchiveStorage)
+}
+
 func TestWithCLIFlags(t *testing.T) {
 	f := NewFactory()
 	v, command := config.Viperize(f.AddFlags)
@@ -316,3 +346,42 @@ func TestStreamingSpanWriterFactory_Capabilities(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "I am streaming writer", "streaming writer when Capabilities return true")
 }
+
+// closingWriter counts the calls to Close.
+type closingWriter struct {
+	spanStoreMocks.Writer
+	closeErr error
+	closed   int
+}
+
+func (w *closingWriter) Close() error {
+	w.closed++
+	return w.closeErr
+}
+
+func TestGRPCStorageFactory_CloseSpanWriters(t *testing.T) {
+	f := makeFactory(t)
+
+	writer := &closingWriter{}
+	streamingWriter := &closingWriter{closeErr: errors.New("close error")}
+	f.services.Store = &store{writer: writer}
+	f.services.StreamingSpanWriter = &store{writer: streamingWriter}
+	capabilities := f.services.Capabilities.(*mocks.PluginCapabilities)
+	capabilities.
+		On("Capabilities").Return(&shared.Capabilities{}, nil).Twice().
+		On("Capabilities").Return(&shared.Capabilities{StreamingSpanWriter: true}, nil).Once()
+
+	for _, expected := range []spanstore.Writer{writer, writer, streamingWriter} {
+		w, err := f.CreateSpanWriter()
+		require.NoError(t, err)
+		assert.Same(t, expected, w)
+	}
+
+	require.EqualError(t, f.Close(), "close error")
+	assert.Equal(t, 1, writer.closed)
+	assert.Equal(t, 1, streamingWriter.closed)
+
+	// the span writers are closed once
+	require.NoError(t, f.Close())
+	assert.Equal(t, 1, writer.closed)
+}
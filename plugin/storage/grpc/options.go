@@ -17,11 +17,13 @@ package grpc
 import (
 	"flag"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 )
 
@@ -29,6 +31,7 @@ const (
 	remotePrefix             = "grpc-storage"
 	remoteServer             = remotePrefix + ".server"
 	remoteConnectionTimeout  = remotePrefix + ".connection-timeout"
+	remoteCompression        = remotePrefix + ".compression"
 	defaultConnectionTimeout = time.Duration(5 * time.Second)
 )
 
@@ -44,6 +47,7 @@ func v1AddFlags(flagSet *flag.FlagSet) {
 
 	flagSet.String(remoteServer, "", "The remote storage gRPC server address as host:port")
 	flagSet.Duration(remoteConnectionTimeout, defaultConnectionTimeout, "The remote storage gRPC server connection timeout")
+	flagSet.String(remoteCompression, grpccompression.None, "The compression of the messages sent to the remote storage gRPC server: "+strings.Join(grpccompression.Compressors, ", "))
 }
 
 func v1InitFromViper(cfg *Configuration, v *viper.Viper) error {
@@ -54,6 +58,10 @@ func v1InitFromViper(cfg *Configuration, v *viper.Viper) error {
 		return fmt.Errorf("failed to parse gRPC storage TLS options: %w", err)
 	}
 	cfg.RemoteConnectTimeout = v.GetDuration(remoteConnectionTimeout)
+	cfg.RemoteCompression = v.GetString(remoteCompression)
+	if err := grpccompression.Validate(cfg.RemoteCompression); err != nil {
+		return err
+	}
 	cfg.TenancyOpts = tenancy.InitFromViper(v)
 	return nil
 }
@@ -20,6 +20,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configcompression"
 
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -46,6 +47,7 @@ func TestRemoteOptionsWithFlags(t *testing.T) {
 		"--grpc-storage.server=localhost:2001",
 		"--grpc-storage.tls.enabled=true",
 		"--grpc-storage.connection-timeout=60s",
+		"--grpc-storage.compression=snappy",
 	})
 	require.NoError(t, err)
 	var cfg Configuration
@@ -54,6 +56,18 @@ func TestRemoteOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, "localhost:2001", cfg.RemoteServerAddr)
 	assert.True(t, cfg.RemoteTLS.Enabled)
 	assert.Equal(t, 60*time.Second, cfg.RemoteConnectTimeout)
+	assert.Equal(t, "snappy", cfg.RemoteCompression)
+	assert.Equal(t, configcompression.TypeSnappy, cfg.TranslateToConfigV2().Compression)
+}
+
+func TestRemoteOptionsInvalidCompression(t *testing.T) {
+	v, command := config.Viperize(v1AddFlags)
+	err := command.ParseFlags([]string{
+		"--grpc-storage.compression=brotli",
+	})
+	require.NoError(t, err)
+	var cfg Configuration
+	require.ErrorContains(t, v1InitFromViper(&cfg, v), `unsupported gRPC compression "brotli"`)
 }
 
 func TestRemoteOptionsNoTLSWithFlags(t *testing.T) {
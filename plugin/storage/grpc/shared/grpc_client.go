@@ -35,7 +35,7 @@ import (
 )
 
 // BearerTokenKey is the key name for the bearer token context value.
-const BearerTokenKey = "bearer.token"
+const BearerTokenKey = bearertoken.PropagatedTokenKey
 
 var (
 	_ StoragePlugin        = (*GRPCClient)(nil)
@@ -179,11 +179,18 @@ func (c *GRPCClient) GetOperations(
 			})
 		}
 	}
-	return operations, nil
+	// the plugin protocol does not carry the prefix and the page of the operations
+	return spanstore.PageOperations(operations, query), nil
 }
 
 // FindTraces retrieves traces that match the traceQuery
 func (c *GRPCClient) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	// the plugin protocol does not carry the log fields either, which cannot be ignored
+	if len(query.LogFields) > 0 {
+		return nil, spanstore.ErrLogFieldsNotSupported
+	}
+	// the plugin protocol does not carry the resource attributes, which are also process tags
+	spanstore.ResourceAttributesAsTags(query)
 	stream, err := c.readerClient.FindTraces(upgradeContext(ctx), &storage_v1.FindTracesRequest{
 		Query: &storage_v1.TraceQueryParameters{
 			ServiceName:   query.ServiceName,
@@ -222,6 +229,11 @@ func (c *GRPCClient) FindTraces(ctx context.Context, query *spanstore.TraceQuery
 
 // FindTraceIDs retrieves traceIDs that match the traceQuery
 func (c *GRPCClient) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	if len(query.LogFields) > 0 {
+		return nil, spanstore.ErrLogFieldsNotSupported
+	}
+	// the plugin protocol does not carry the resource attributes, which are also process tags
+	spanstore.ResourceAttributesAsTags(query)
 	resp, err := c.readerClient.FindTraceIDs(upgradeContext(ctx), &storage_v1.FindTraceIDsRequest{
 		Query: &storage_v1.TraceQueryParameters{
 			ServiceName:   query.ServiceName,
@@ -308,6 +308,41 @@ func TestGRPCClientFindTraceIDs(t *testing.T) {
 	})
 }
 
+func TestGRPCClientFindTracesLogFields(t *testing.T) {
+	withGRPCClient(func(r *grpcClientTest) {
+		query := &spanstore.TraceQueryParameters{
+			ServiceName: "frontend",
+			LogFields:   map[string]string{"event": "exception"},
+		}
+		_, err := r.client.FindTraces(context.Background(), query)
+		require.ErrorIs(t, err, spanstore.ErrLogFieldsNotSupported)
+		_, err = r.client.FindTraceIDs(context.Background(), query)
+		require.ErrorIs(t, err, spanstore.ErrLogFieldsNotSupported)
+		r.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+		r.spanReader.AssertNotCalled(t, "FindTraceIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGRPCClientFindTraceIDsResourceAttributes(t *testing.T) {
+	withGRPCClient(func(r *grpcClientTest) {
+		r.spanReader.On("FindTraceIDs", mock.Anything, &storage_v1.FindTraceIDsRequest{
+			Query: &storage_v1.TraceQueryParameters{
+				ServiceName: "frontend",
+				Tags:        map[string]string{"deployment.environment": "prod"},
+			},
+		}).Return(&storage_v1.FindTraceIDsResponse{
+			TraceIDs: []model.TraceID{mockTraceID},
+		}, nil)
+
+		s, err := r.client.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:        "frontend",
+			ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{mockTraceID}, s)
+	})
+}
+
 func TestGRPCClientWriteSpan(t *testing.T) {
 	withGRPCClient(func(r *grpcClientTest) {
 		r.spanWriter.On("WriteSpan", mock.Anything, &storage_v1.WriteSpanRequest{
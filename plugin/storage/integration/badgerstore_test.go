@@ -50,6 +50,7 @@ func (s *BadgerIntegrationStorage) initialize(t *testing.T) {
 
 	s.SamplingStore, err = s.factory.CreateSamplingStore(0)
 	require.NoError(t, err)
+	s.ApplyCapabilities(s.factory.Capabilities())
 }
 
 func (s *BadgerIntegrationStorage) cleanUp(t *testing.T) {
@@ -58,14 +59,7 @@ func (s *BadgerIntegrationStorage) cleanUp(t *testing.T) {
 
 func TestBadgerStorage(t *testing.T) {
 	SkipUnlessEnv(t, "badger")
-	s := &BadgerIntegrationStorage{
-		StorageIntegration: StorageIntegration{
-			SkipArchiveTest: true,
-
-			// TODO: remove this badger supports returning spanKind from GetOperations
-			GetOperationsMissingSpanKind: true,
-		},
-	}
+	s := &BadgerIntegrationStorage{}
 	s.CleanUp = s.cleanUp
 	s.initialize(t)
 	s.RunAll(t)
@@ -153,6 +153,7 @@ func (s *ESStorageIntegration) initSpanstore(t *testing.T, allTagsAsFields bool)
 
 	s.SamplingStore, err = f.CreateSamplingStore(1)
 	require.NoError(t, err)
+	s.ApplyCapabilities(f.Capabilities())
 }
 
 func healthCheck() error {
@@ -172,11 +173,7 @@ func testElasticsearchStorage(t *testing.T, allTagsAsFields bool) {
 	}
 	s := &ESStorageIntegration{
 		StorageIntegration: StorageIntegration{
-			Fixtures:        LoadAndParseQueryTestCases(t, "fixtures/queries_es.json"),
-			SkipArchiveTest: false,
-			// TODO: remove this flag after ES supports returning spanKind
-			//  Issue https://github.com/jaegertracing/jaeger/issues/1923
-			GetOperationsMissingSpanKind: true,
+			Fixtures: LoadAndParseQueryTestCases(t, "fixtures/queries_es.json"),
 		},
 	}
 	s.initializeES(t, allTagsAsFields)
@@ -35,6 +35,7 @@ import (
 
 	samplemodel "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -61,7 +62,7 @@ type StorageIntegration struct {
 	SamplingStore     samplingstore.Store
 	Fixtures          []*QueryFixtures
 
-	// TODO: remove this after all storage backends return spanKind from GetOperations
+	// Set by ApplyCapabilities for the backends not returning spanKind from GetOperations
 	GetOperationsMissingSpanKind bool
 
 	// TODO: remove this after all storage backends return Source column from GetDependencies
@@ -94,6 +95,12 @@ type QueryFixtures struct {
 	ExpectedFixtures []string
 }
 
+// ApplyCapabilities adjusts the tests to the features supported by the storage backend.
+func (s *StorageIntegration) ApplyCapabilities(capabilities storage.Capabilities) {
+	s.SkipArchiveTest = !capabilities.ArchiveStorage
+	s.GetOperationsMissingSpanKind = !capabilities.OperationSpanKind
+}
+
 func (s *StorageIntegration) cleanUp(t *testing.T) {
 	require.NotNil(t, s.CleanUp, "CleanUp function must be provided")
 	s.CleanUp(t)
@@ -539,6 +546,7 @@ func (s *StorageIntegration) RunSpanStoreTests(t *testing.T) {
 	t.Run("GetServices", s.testGetServices)
 	t.Run("GetOperations", s.testGetOperations)
 	t.Run("GetTrace", s.testGetTrace)
+	t.Run("GetTrace128BitIDs", s.testGetTrace128BitIDs)
 	t.Run("GetLargeSpans", s.testGetLargeSpan)
 	t.Run("FindTraces", s.testFindTraces)
 }
@@ -0,0 +1,60 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package integration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite"
+)
+
+type SQLiteStorageIntegration struct {
+	StorageIntegration
+	factory *sqlite.Factory
+}
+
+func (s *SQLiteStorageIntegration) initialize(t *testing.T) {
+	logger := zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller()))
+	s.factory = sqlite.NewFactory()
+	v, command := config.Viperize(s.factory.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--sqlite.path=" + filepath.Join(t.TempDir(), "jaeger.db"),
+	}))
+	s.factory.InitFromViper(v, logger)
+	require.NoError(t, s.factory.Initialize(metrics.NullFactory, logger))
+	t.Cleanup(func() {
+		require.NoError(t, s.factory.Close())
+	})
+
+	var err error
+	s.SpanWriter, err = s.factory.CreateSpanWriter()
+	require.NoError(t, err)
+	s.SpanReader, err = s.factory.CreateSpanReader()
+	require.NoError(t, err)
+	s.DependencyReader, err = s.factory.CreateDependencyReader()
+	require.NoError(t, err)
+	s.SamplingStore, err = s.factory.CreateSamplingStore(0)
+	require.NoError(t, err)
+	s.ApplyCapabilities(s.factory.Capabilities())
+}
+
+func (s *SQLiteStorageIntegration) cleanUp(t *testing.T) {
+	require.NoError(t, s.factory.Purge(context.Background()))
+}
+
+func TestSQLiteStorage(t *testing.T) {
+	SkipUnlessEnv(t, "sqlite")
+	s := &SQLiteStorageIntegration{}
+	s.CleanUp = s.cleanUp
+	s.initialize(t)
+	s.RunAll(t)
+}
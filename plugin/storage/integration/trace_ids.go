@@ -0,0 +1,99 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// traceIDsService is the service of the spans written by testGetTrace128BitIDs.
+const traceIDsService = "trace-ids-service"
+
+// traceIDs128Bit are trace IDs whose truncation to their low 64 bits or whose formatting without
+// the zero-padding of their bits collide or lose their high bits in the storage backends.
+var traceIDs128Bit = []model.TraceID{
+	model.NewTraceID(0, 0x2a),
+	model.NewTraceID(1, 0x2a),
+	model.NewTraceID(0x8000000000000001, 0x2a),
+	model.NewTraceID(^uint64(0), ^uint64(0)),
+}
+
+// testGetTrace128BitIDs checks that the traces whose IDs only differ by their high 64 bits round-trip
+// through the storage backend, by their ID and by the search of their service.
+func (s *StorageIntegration) testGetTrace128BitIDs(t *testing.T) {
+	s.skipIfNeeded(t)
+	defer s.cleanUp(t)
+
+	startTime := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	expected := make(map[model.TraceID]*model.Trace, len(traceIDs128Bit))
+	for i, traceID := range traceIDs128Bit {
+		trace := &model.Trace{Spans: []*model.Span{{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(uint64(i + 1)),
+			OperationName: fmt.Sprintf("trace-ids-operation-%d", i),
+			References:    []model.SpanRef{},
+			StartTime:     startTime.Add(time.Duration(i) * time.Millisecond),
+			Duration:      time.Millisecond,
+			Tags:          model.KeyValues{model.String("trace.id", traceID.HexString())},
+			Process:       model.NewProcess(traceIDsService, model.KeyValues{}),
+			Logs:          []model.Log{},
+		}}}
+		s.writeTrace(t, trace)
+		expected[traceID] = trace
+	}
+
+	for _, traceID := range traceIDs128Bit {
+		t.Run(traceID.HexString(), func(t *testing.T) {
+			var actual *model.Trace
+			found := s.waitForCondition(t, func(t *testing.T) bool {
+				var err error
+				actual, err = s.SpanReader.GetTrace(context.Background(), traceID)
+				if err != nil {
+					t.Log(err)
+				}
+				return err == nil && len(actual.Spans) == 1
+			})
+			require.True(t, found)
+			assert.Equal(t, traceID, actual.Spans[0].TraceID)
+			CompareTraces(t, expected[traceID], actual)
+		})
+	}
+
+	t.Run("FindTraces", func(t *testing.T) {
+		s.skipIfNeeded(t)
+		query := &spanstore.TraceQueryParameters{
+			ServiceName:  traceIDsService,
+			StartTimeMin: startTime.Add(-time.Minute),
+			StartTimeMax: startTime.Add(time.Minute),
+			NumTraces:    len(traceIDs128Bit),
+		}
+		traces := s.findTracesByQuery(t, query, mapValues(expected))
+		actual := make([]model.TraceID, 0, len(traces))
+		for _, trace := range traces {
+			actual = append(actual, trace.Spans[0].TraceID)
+		}
+		sort.Slice(actual, func(i, j int) bool {
+			return actual[i].High < actual[j].High || (actual[i].High == actual[j].High && actual[i].Low < actual[j].Low)
+		})
+		assert.Equal(t, traceIDs128Bit, actual)
+	})
+}
+
+func mapValues(traces map[model.TraceID]*model.Trace) []*model.Trace {
+	values := make([]*model.Trace, 0, len(traces))
+	for _, trace := range traces {
+		values = append(values, trace)
+	}
+	return values
+}
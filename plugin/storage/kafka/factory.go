@@ -15,6 +15,7 @@
 package kafka
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"io"
@@ -32,9 +33,10 @@ import (
 )
 
 var ( // interface comformance checks
-	_ storage.Factory     = (*Factory)(nil)
-	_ io.Closer           = (*Factory)(nil)
-	_ plugin.Configurable = (*Factory)(nil)
+	_ storage.Factory       = (*Factory)(nil)
+	_ storage.HealthChecker = (*Factory)(nil)
+	_ io.Closer             = (*Factory)(nil)
+	_ plugin.Configurable   = (*Factory)(nil)
 )
 
 // Factory implements storage.Factory and creates write-only storage components backed by kafka.
@@ -108,6 +110,20 @@ func (*Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return nil, errors.New("kafka storage is write-only")
 }
 
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{StreamingWrites: true}
+}
+
+// CheckHealth implements storage.HealthChecker by connecting to the brokers.
+func (f *Factory) CheckHealth(context.Context) error {
+	client, err := f.NewClient(f.logger)
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
 var _ io.Closer = (*Factory)(nil)
 
 // Close closes the resources held by the factory
@@ -16,6 +16,7 @@ package kafka
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/config"
 	kafkaConfig "github.com/jaegertracing/jaeger/pkg/kafka/producer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage"
 )
 
 type mockProducerBuilder struct {
@@ -44,6 +46,10 @@ func (m *mockProducerBuilder) NewProducer(*zap.Logger) (sarama.AsyncProducer, er
 	return nil, m.err
 }
 
+func (m *mockProducerBuilder) NewClient(*zap.Logger) (sarama.Client, error) {
+	return nil, m.err
+}
+
 func TestKafkaFactory(t *testing.T) {
 	f := NewFactory()
 	v, command := config.Viperize(f.AddFlags)
@@ -68,6 +74,7 @@ func TestKafkaFactory(t *testing.T) {
 
 	_, err = f.CreateDependencyReader()
 	require.Error(t, err)
+	assert.Equal(t, storage.Capabilities{StreamingWrites: true}, f.Capabilities())
 
 	require.NoError(t, f.Close())
 }
@@ -165,3 +172,9 @@ func TestConfigureFromOptions(t *testing.T) {
 	assert.Equal(t, o, f.options)
 	assert.Equal(t, &o.Config, f.Builder)
 }
+
+func TestKafkaFactoryCheckHealth(t *testing.T) {
+	f := NewFactory()
+	f.Builder = &mockProducerBuilder{err: sarama.ErrOutOfBrokers, t: t}
+	require.ErrorIs(t, f.CheckHealth(context.Background()), sarama.ErrOutOfBrokers)
+}
@@ -27,15 +27,22 @@ import (
 	"github.com/jaegertracing/jaeger/plugin"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
 )
 
 var ( // interface comformance checks
-	_ storage.Factory              = (*Factory)(nil)
-	_ storage.ArchiveFactory       = (*Factory)(nil)
-	_ storage.SamplingStoreFactory = (*Factory)(nil)
-	_ plugin.Configurable          = (*Factory)(nil)
+	_ storage.Factory                      = (*Factory)(nil)
+	_ storage.ArchiveFactory               = (*Factory)(nil)
+	_ storage.SamplingStoreFactory         = (*Factory)(nil)
+	_ storage.WarningStoreFactory          = (*Factory)(nil)
+	_ storage.MetadataStoreFactory         = (*Factory)(nil)
+	_ storage.SamplingStrategyStoreFactory = (*Factory)(nil)
+	_ storage.SavedSearchStoreFactory      = (*Factory)(nil)
+	_ plugin.Configurable                  = (*Factory)(nil)
 )
 
 // Factory implements storage.Factory and creates storage components backed by memory store.
@@ -44,6 +51,10 @@ type Factory struct {
 	metricsFactory metrics.Factory
 	logger         *zap.Logger
 	store          *Store
+	warningStore   *WarningStore
+	metadataStore  *MetadataStore
+	strategyStore  *StrategyStore
+	searchStore    *SavedSearchStore
 }
 
 // NewFactory creates a new Factory.
@@ -82,6 +93,10 @@ func (f *Factory) configureFromOptions(opts Options) {
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.metricsFactory, f.logger = metricsFactory, logger
 	f.store = WithConfiguration(f.options.Configuration)
+	f.warningStore = NewWarningStore(f.options.Configuration.MaxTraces)
+	f.metadataStore = NewMetadataStore()
+	f.strategyStore = NewStrategyStore()
+	f.searchStore = NewSavedSearchStore()
 	logger.Info("Memory storage initialized", zap.Any("configuration", f.store.defaultConfig))
 	f.publishOpts()
 
@@ -113,6 +128,19 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return f.store, nil
 }
 
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{
+		ArchiveStorage:    true,
+		TraceSearch:       true,
+		TagSearch:         true,
+		TagFilters:        true,
+		OperationSpanKind: true,
+		Dependencies:      true,
+		AdaptiveSampling:  true,
+	}
+}
+
 // CreateSamplingStore implements storage.SamplingStoreFactory
 func (*Factory) CreateSamplingStore(maxBuckets int) (samplingstore.Store, error) {
 	return NewSamplingStore(maxBuckets), nil
@@ -123,6 +151,26 @@ func (*Factory) CreateLock() (distributedlock.Lock, error) {
 	return &lock{}, nil
 }
 
+// CreateWarningStore implements storage.WarningStoreFactory
+func (f *Factory) CreateWarningStore() (warningstore.Store, error) {
+	return f.warningStore, nil
+}
+
+// CreateMetadataStore implements storage.MetadataStoreFactory
+func (f *Factory) CreateMetadataStore() (metadatastore.Store, error) {
+	return f.metadataStore, nil
+}
+
+// CreateSamplingStrategyStore implements storage.SamplingStrategyStoreFactory
+func (f *Factory) CreateSamplingStrategyStore() (samplingstore.StrategyStore, error) {
+	return f.strategyStore, nil
+}
+
+// CreateSavedSearchStore implements storage.SavedSearchStoreFactory
+func (f *Factory) CreateSavedSearchStore() (savedsearchstore.Store, error) {
+	return f.searchStore, nil
+}
+
 func (f *Factory) publishOpts() {
 	safeexpvar.SetInt("jaeger_storage_memory_max_traces", int64(f.options.Configuration.MaxTraces))
 }
@@ -43,12 +43,25 @@ func TestMemoryStorageFactory(t *testing.T) {
 	depReader, err := f.CreateDependencyReader()
 	require.NoError(t, err)
 	assert.Equal(t, f.store, depReader)
+	assert.True(t, f.Capabilities().ArchiveStorage)
 	samplingStore, err := f.CreateSamplingStore(2)
 	require.NoError(t, err)
 	assert.Equal(t, 2, samplingStore.(*SamplingStore).maxBuckets)
 	lock, err := f.CreateLock()
 	require.NoError(t, err)
 	assert.NotNil(t, lock)
+	warningStore, err := f.CreateWarningStore()
+	require.NoError(t, err)
+	assert.Equal(t, f.warningStore, warningStore)
+	metadataStore, err := f.CreateMetadataStore()
+	require.NoError(t, err)
+	assert.Equal(t, f.metadataStore, metadataStore)
+	strategyStore, err := f.CreateSamplingStrategyStore()
+	require.NoError(t, err)
+	assert.Equal(t, f.strategyStore, strategyStore)
+	searchStore, err := f.CreateSavedSearchStore()
+	require.NoError(t, err)
+	assert.Equal(t, f.searchStore, searchStore)
 }
 
 func TestWithConfiguration(t *testing.T) {
@@ -18,6 +18,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -99,6 +101,8 @@ func (st *Store) GetDependencies(ctx context.Context, endTs time.Time, lookback
 	m.Lock()
 	defer m.Unlock()
 	deps := map[string]*model.DependencyLink{}
+	// the calls are weighted by the adjusted counts of the sampled spans, to count the actual traffic
+	callCounts := map[string]float64{}
 	startTs := endTs.Add(-1 * lookback)
 	for _, orig := range m.traces {
 		// SpanIDDeduper never returns an err
@@ -113,24 +117,41 @@ func (st *Store) GetDependencies(ctx context.Context, endTs time.Time, lookback
 					depKey := parentSpan.Process.ServiceName + "&&&" + s.Process.ServiceName
 					if _, ok := deps[depKey]; !ok {
 						deps[depKey] = &model.DependencyLink{
-							Parent:    parentSpan.Process.ServiceName,
-							Child:     s.Process.ServiceName,
-							CallCount: 1,
+							Parent: parentSpan.Process.ServiceName,
+							Child:  s.Process.ServiceName,
 						}
-					} else {
-						deps[depKey].CallCount++
 					}
+					callCounts[depKey] += s.GetAdjustedCount()
 				}
 			}
 		}
 	}
 	retMe := make([]model.DependencyLink, 0, len(deps))
-	for _, dep := range deps {
+	for depKey, dep := range deps {
+		dep.CallCount = uint64(math.Round(callCounts[depKey]))
 		retMe = append(retMe, *dep)
 	}
 	return retMe, nil
 }
 
+// GetDependencyLatencies returns dependencies between services with the latencies of their calls
+func (st *Store) GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLatency, error) {
+	m := st.getTenant(tenancy.GetTenant(ctx))
+	// deduper used below can modify the spans, so we take an exclusive lock
+	m.Lock()
+	defer m.Unlock()
+	aggregator := dependencystore.NewLatencyAggregator(nil)
+	startTs := endTs.Add(-1 * lookback)
+	for _, orig := range m.traces {
+		// SpanIDDeduper never returns an err
+		trace, _ := m.deduper.Adjust(orig)
+		if traceIsBetweenStartAndEnd(startTs, endTs, trace) {
+			aggregator.AddTrace(trace)
+		}
+	}
+	return aggregator.Latencies(), nil
+}
+
 func findSpan(trace *model.Trace, spanID model.SpanID) *model.Span {
 	for _, s := range trace.Spans {
 		if s.SpanID == spanID {
@@ -244,7 +265,7 @@ func (st *Store) GetOperations(
 			}
 		}
 	}
-	return retMe, nil
+	return spanstore.PageOperations(retMe, query), nil
 }
 
 // FindTraces returns all traces in the query parameters are satisfied by a trace's span
@@ -281,6 +302,42 @@ func (*Store) FindTraceIDs(context.Context, *spanstore.TraceQueryParameters) ([]
 	return nil, errors.New("not implemented")
 }
 
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix.
+// It returns the IDs of the most recent traces first.
+func (st *Store) FindTraceIDsByPrefix(ctx context.Context, query *spanstore.TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	m := st.getTenant(tenancy.GetTenant(ctx))
+	m.RLock()
+	defer m.RUnlock()
+	var found []*model.Trace
+	for traceID, trace := range m.traces {
+		if !spanstore.TraceIDHasPrefix(traceID, query.Prefix) || !hasSpanInTimeRange(trace, query.StartTimeMin, query.StartTimeMax) {
+			continue
+		}
+		found = append(found, trace)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Spans[0].StartTime.After(found[j].Spans[0].StartTime)
+	})
+	if query.NumTraces > 0 && len(found) > query.NumTraces {
+		found = found[:query.NumTraces]
+	}
+	traceIDs := make([]model.TraceID, len(found))
+	for i, trace := range found {
+		traceIDs[i] = trace.Spans[0].TraceID
+	}
+	return traceIDs, nil
+}
+
+func hasSpanInTimeRange(trace *model.Trace, startTimeMin, startTimeMax time.Time) bool {
+	for _, span := range trace.Spans {
+		if (startTimeMin.IsZero() || !span.StartTime.Before(startTimeMin)) &&
+			(startTimeMax.IsZero() || !span.StartTime.After(startTimeMax)) {
+			return true
+		}
+	}
+	return false
+}
+
 func validTrace(trace *model.Trace, query *spanstore.TraceQueryParameters) bool {
 	for _, span := range trace.Spans {
 		if validSpan(span, query) {
@@ -325,6 +382,19 @@ func validSpan(span *model.Span, query *spanstore.TraceQueryParameters) bool {
 			return false
 		}
 	}
+	for queryK, queryV := range query.ResourceAttributes {
+		if _, ok := findKeyValueMatch(span.Process.Tags, queryK, queryV); !ok {
+			return false
+		}
+	}
+	for _, filter := range query.TagFilters {
+		if !filter.Matches(spanKVs) {
+			return false
+		}
+	}
+	if len(query.LogFields) > 0 && !spanstore.SpanHasLogWithFields(span, query.LogFields) {
+		return false
+	}
 	return true
 }
 
@@ -17,6 +17,7 @@ package memory
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -160,6 +161,47 @@ func TestStoreGetDependencies(t *testing.T) {
 	})
 }
 
+func TestStoreGetDependenciesAdjustedCount(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		sampled := func(span *model.Span, adjustedCount float64) *model.Span {
+			s := *span
+			s.Tags = append(model.KeyValues{model.Float64(model.AdjustedCountTag, adjustedCount)}, span.Tags...)
+			return &s
+		}
+		require.NoError(t, store.WriteSpan(context.Background(), testingSpan))
+		require.NoError(t, store.WriteSpan(context.Background(), sampled(childSpan1, 10)))
+		require.NoError(t, store.WriteSpan(context.Background(), sampled(childSpan2, 2.5)))
+		links, err := store.GetDependencies(context.Background(), time.Unix(0, 0).Add(time.Hour), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []model.DependencyLink{{
+			Parent:    "serviceName",
+			Child:     "childService",
+			CallCount: 13,
+		}}, links)
+	})
+}
+
+func TestStoreGetDependencyLatencies(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		require.NoError(t, store.WriteSpan(context.Background(), testingSpan))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan1))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan2))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan2_1))
+		latencies, err := store.GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+		require.NoError(t, err)
+		assert.Empty(t, latencies)
+
+		latencies, err = store.GetDependencyLatencies(context.Background(), time.Unix(0, 0).Add(time.Hour), time.Hour)
+		require.NoError(t, err)
+		require.Len(t, latencies, 1)
+		assert.Equal(t, "serviceName", latencies[0].Parent)
+		assert.Equal(t, "childService", latencies[0].Child)
+		assert.Equal(t, uint64(2), latencies[0].CallCount)
+		assert.Equal(t, int64(2), latencies[0].Latency.Count)
+		assert.Equal(t, childSpan2.Duration, latencies[0].Latency.P99)
+	})
+}
+
 func TestStoreWriteSpan(t *testing.T) {
 	withMemoryStore(func(store *Store) {
 		err := store.WriteSpan(context.Background(), testingSpan)
@@ -286,6 +328,36 @@ func TestStoreGetServerOperationsFound(t *testing.T) {
 	})
 }
 
+func TestStoreGetOperationsPage(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan1))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan2))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan2_1))
+		query := spanstore.OperationQueryParameters{ServiceName: childSpan1.Process.ServiceName}
+		all, err := store.GetOperations(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+
+		query.Limit = 2
+		firstPage, err := store.GetOperations(context.Background(), query)
+		require.NoError(t, err)
+		query.Offset = 2
+		lastPage, err := store.GetOperations(context.Background(), query)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, all, append(firstPage, lastPage...))
+
+		operations, err := store.GetOperations(context.Background(), spanstore.OperationQueryParameters{
+			ServiceName: childSpan1.Process.ServiceName,
+			NamePrefix:  childSpan1.OperationName,
+		})
+		require.NoError(t, err)
+		for _, operation := range operations {
+			assert.True(t, strings.HasPrefix(operation.Name, childSpan1.OperationName))
+		}
+		assert.NotEmpty(t, operations)
+	})
+}
+
 func TestStoreGetOperationsNotFound(t *testing.T) {
 	withPopulatedMemoryStore(func(store *Store) {
 		operations, err := store.GetOperations(
@@ -425,6 +497,15 @@ func TestStoreGetTrace(t *testing.T) {
 				},
 			}, false,
 		},
+		{
+			&spanstore.TraceQueryParameters{
+				ServiceName: testingSpan.Process.ServiceName,
+				// resource attributes only match the process tags
+				ResourceAttributes: map[string]string{
+					testingSpan.Tags[0].Key: testingSpan.Tags[0].VStr,
+				},
+			}, false,
+		},
 	}
 	for _, testS := range testStruct {
 		withPopulatedMemoryStore(func(store *Store) {
@@ -442,6 +523,114 @@ func TestStoreGetTrace(t *testing.T) {
 	}
 }
 
+func TestStoreFindTracesByResourceAttributes(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		span := makeTestingSpan(traceID, "")
+		span.Process.Tags = model.KeyValues{model.String("deployment.environment", "prod")}
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+
+		traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:        span.Process.ServiceName,
+			ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+			NumTraces:          10,
+		})
+		require.NoError(t, err)
+		assert.Len(t, traces, 1)
+
+		traces, err = store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:        span.Process.ServiceName,
+			ResourceAttributes: map[string]string{"deployment.environment": "staging"},
+			NumTraces:          10,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, traces)
+	})
+}
+
+func TestStoreFindTracesByTagFilters(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		span := makeTestingSpan(traceID, "")
+		span.Tags = model.KeyValues{model.Int64("http.status_code", 503)}
+		span.Process.Tags = model.KeyValues{model.String("host.name", "web-1")}
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+
+		for _, testCase := range []struct {
+			filters  []spanstore.TagFilter
+			expected int
+		}{
+			{filters: []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagFilterGreaterOrEqual, Value: "500"}}, expected: 1},
+			{filters: []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagFilterLess, Value: "500"}}},
+			{filters: []spanstore.TagFilter{
+				{Key: "host.name", Operator: spanstore.TagFilterNotEqual, Value: "web-2"},
+				{Key: "http.status_code", Operator: spanstore.TagFilterExists},
+			}, expected: 1},
+			{filters: []spanstore.TagFilter{{Key: "error", Operator: spanstore.TagFilterExists}}},
+		} {
+			traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+				ServiceName: span.Process.ServiceName,
+				TagFilters:  testCase.filters,
+				NumTraces:   10,
+			})
+			require.NoError(t, err)
+			assert.Len(t, traces, testCase.expected, testCase.filters)
+		}
+	})
+}
+
+func TestStoreFindTracesByLogFields(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		span := makeTestingSpan(traceID, "")
+		span.Logs = []model.Log{
+			{Fields: model.KeyValues{model.String("event", "retry")}},
+			{Fields: model.KeyValues{model.String("event", "exception"), model.String("exception.type", "IOError")}},
+		}
+		require.NoError(t, store.WriteSpan(context.Background(), span))
+
+		for _, testCase := range []struct {
+			fields   map[string]string
+			expected int
+		}{
+			{fields: map[string]string{"event": "exception", "exception.type": "IOError"}, expected: 1},
+			{fields: map[string]string{"event": "retry", "exception.type": "IOError"}},
+			{fields: map[string]string{"event": "timeout"}},
+		} {
+			traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+				ServiceName: span.Process.ServiceName,
+				LogFields:   testCase.fields,
+				NumTraces:   10,
+			})
+			require.NoError(t, err)
+			assert.Len(t, traces, testCase.expected, testCase.fields)
+		}
+	})
+}
+
+func TestStoreFindTraceIDsByPrefix(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		newer := makeTestingSpan(model.NewTraceID(1, 0xabcd), "")
+		newer.StartTime = newer.StartTime.Add(time.Second)
+		require.NoError(t, store.WriteSpan(context.Background(), newer))
+		other := makeTestingSpan(model.NewTraceID(0xff, 1), "")
+		require.NoError(t, store.WriteSpan(context.Background(), other))
+
+		query := &spanstore.TraceIDPrefixQueryParameters{Prefix: "0000000000000001"}
+		traceIDs, err := store.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{newer.TraceID, testingSpan.TraceID}, traceIDs)
+
+		query.NumTraces = 1
+		traceIDs, err = store.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{newer.TraceID}, traceIDs)
+
+		query.NumTraces = 0
+		query.StartTimeMax = testingSpan.StartTime.Add(-time.Second)
+		traceIDs, err = store.FindTraceIDsByPrefix(context.Background(), query)
+		require.NoError(t, err)
+		assert.Empty(t, traceIDs)
+	})
+}
+
 func TestStore_FindTraceIDs(t *testing.T) {
 	withMemoryStore(func(store *Store) {
 		traceIDs, err := store.FindTraceIDs(context.Background(), nil)
@@ -0,0 +1,79 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+)
+
+// MetadataStore is an in-memory store of the metadata of the services.
+type MetadataStore struct {
+	sync.RWMutex
+	perTenant map[string]map[string]metadatastore.ServiceMetadata
+}
+
+// NewMetadataStore creates an in-memory service metadata store.
+func NewMetadataStore() *MetadataStore {
+	return &MetadataStore{
+		perTenant: make(map[string]map[string]metadatastore.ServiceMetadata),
+	}
+}
+
+// WriteServiceMetadata implements metadatastore.Writer#WriteServiceMetadata.
+func (ms *MetadataStore) WriteServiceMetadata(ctx context.Context, metadata *metadatastore.ServiceMetadata) error {
+	ms.Lock()
+	defer ms.Unlock()
+	tenantID := tenancy.GetTenant(ctx)
+	services, ok := ms.perTenant[tenantID]
+	if !ok {
+		services = make(map[string]metadatastore.ServiceMetadata)
+		ms.perTenant[tenantID] = services
+	}
+	services[metadata.ServiceName] = *metadata
+	return nil
+}
+
+// DeleteServiceMetadata implements metadatastore.Writer#DeleteServiceMetadata.
+func (ms *MetadataStore) DeleteServiceMetadata(ctx context.Context, service string) error {
+	ms.Lock()
+	defer ms.Unlock()
+	services := ms.perTenant[tenancy.GetTenant(ctx)]
+	if _, ok := services[service]; !ok {
+		return metadatastore.ErrServiceMetadataNotFound
+	}
+	delete(services, service)
+	return nil
+}
+
+// GetServiceMetadata implements metadatastore.Reader#GetServiceMetadata.
+func (ms *MetadataStore) GetServiceMetadata(ctx context.Context, service string) (*metadatastore.ServiceMetadata, error) {
+	ms.RLock()
+	defer ms.RUnlock()
+	metadata, ok := ms.perTenant[tenancy.GetTenant(ctx)][service]
+	if !ok {
+		return nil, metadatastore.ErrServiceMetadataNotFound
+	}
+	return &metadata, nil
+}
+
+// FindServiceMetadata implements metadatastore.Reader#FindServiceMetadata.
+func (ms *MetadataStore) FindServiceMetadata(ctx context.Context) ([]*metadatastore.ServiceMetadata, error) {
+	ms.RLock()
+	defer ms.RUnlock()
+	services := ms.perTenant[tenancy.GetTenant(ctx)]
+	found := make([]*metadatastore.ServiceMetadata, 0, len(services))
+	for _, metadata := range services {
+		metadata := metadata
+		found = append(found, &metadata)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].ServiceName < found[j].ServiceName
+	})
+	return found, nil
+}
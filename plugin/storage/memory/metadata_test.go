@@ -0,0 +1,59 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
+)
+
+func TestMetadataStore(t *testing.T) {
+	ms := NewMetadataStore()
+	ctx := context.Background()
+	frontend := &metadatastore.ServiceMetadata{ServiceName: "frontend", Team: "web"}
+	billing := &metadatastore.ServiceMetadata{ServiceName: "billing", OnCallURL: "https://oncall/billing"}
+	require.NoError(t, ms.WriteServiceMetadata(ctx, frontend))
+	require.NoError(t, ms.WriteServiceMetadata(ctx, billing))
+
+	metadata, err := ms.GetServiceMetadata(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, frontend, metadata)
+	// the stored metadata is a copy
+	metadata.Team = "other"
+	metadata, err = ms.GetServiceMetadata(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, "web", metadata.Team)
+
+	found, err := ms.FindServiceMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*metadatastore.ServiceMetadata{billing, frontend}, found)
+
+	require.NoError(t, ms.DeleteServiceMetadata(ctx, "frontend"))
+	_, err = ms.GetServiceMetadata(ctx, "frontend")
+	require.ErrorIs(t, err, metadatastore.ErrServiceMetadataNotFound)
+	require.ErrorIs(t, ms.DeleteServiceMetadata(ctx, "frontend"), metadatastore.ErrServiceMetadataNotFound)
+}
+
+func TestMetadataStoreTenancy(t *testing.T) {
+	ms := NewMetadataStore()
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	require.NoError(t, ms.WriteServiceMetadata(acme, &metadatastore.ServiceMetadata{ServiceName: "frontend"}))
+
+	_, err := ms.GetServiceMetadata(context.Background(), "frontend")
+	require.ErrorIs(t, err, metadatastore.ErrServiceMetadataNotFound)
+	found, err := ms.FindServiceMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	require.ErrorIs(t, ms.DeleteServiceMetadata(context.Background(), "frontend"), metadatastore.ErrServiceMetadataNotFound)
+
+	found, err = ms.FindServiceMetadata(acme)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
+)
+
+type savedSearchKey struct {
+	owner string
+	name  string
+}
+
+// SavedSearchStore is an in-memory store of the trace searches saved by the users.
+type SavedSearchStore struct {
+	sync.RWMutex
+	perTenant map[string]map[savedSearchKey]savedsearchstore.SavedSearch
+}
+
+// NewSavedSearchStore creates an in-memory saved searches store.
+func NewSavedSearchStore() *SavedSearchStore {
+	return &SavedSearchStore{
+		perTenant: make(map[string]map[savedSearchKey]savedsearchstore.SavedSearch),
+	}
+}
+
+// WriteSavedSearch implements savedsearchstore.Writer#WriteSavedSearch.
+func (ss *SavedSearchStore) WriteSavedSearch(ctx context.Context, search *savedsearchstore.SavedSearch) error {
+	ss.Lock()
+	defer ss.Unlock()
+	tenantID := tenancy.GetTenant(ctx)
+	searches, ok := ss.perTenant[tenantID]
+	if !ok {
+		searches = make(map[savedSearchKey]savedsearchstore.SavedSearch)
+		ss.perTenant[tenantID] = searches
+	}
+	stored := *search
+	stored.Tags = copyTags(search.Tags)
+	searches[savedSearchKey{owner: search.Owner, name: search.Name}] = stored
+	return nil
+}
+
+// DeleteSavedSearch implements savedsearchstore.Writer#DeleteSavedSearch.
+func (ss *SavedSearchStore) DeleteSavedSearch(ctx context.Context, owner, name string) error {
+	ss.Lock()
+	defer ss.Unlock()
+	searches := ss.perTenant[tenancy.GetTenant(ctx)]
+	key := savedSearchKey{owner: owner, name: name}
+	if _, ok := searches[key]; !ok {
+		return savedsearchstore.ErrSavedSearchNotFound
+	}
+	delete(searches, key)
+	return nil
+}
+
+// GetSavedSearch implements savedsearchstore.Reader#GetSavedSearch.
+func (ss *SavedSearchStore) GetSavedSearch(ctx context.Context, owner, name string) (*savedsearchstore.SavedSearch, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+	search, ok := ss.perTenant[tenancy.GetTenant(ctx)][savedSearchKey{owner: owner, name: name}]
+	if !ok {
+		return nil, savedsearchstore.ErrSavedSearchNotFound
+	}
+	search.Tags = copyTags(search.Tags)
+	return &search, nil
+}
+
+// FindSavedSearches implements savedsearchstore.Reader#FindSavedSearches.
+func (ss *SavedSearchStore) FindSavedSearches(ctx context.Context, owner string) ([]*savedsearchstore.SavedSearch, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+	searches := ss.perTenant[tenancy.GetTenant(ctx)]
+	found := make([]*savedsearchstore.SavedSearch, 0, len(searches))
+	for key, search := range searches {
+		if key.owner != owner && !search.Shared {
+			continue
+		}
+		search := search
+		search.Tags = copyTags(search.Tags)
+		found = append(found, &search)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Owner != found[j].Owner {
+			return found[i].Owner < found[j].Owner
+		}
+		return found[i].Name < found[j].Name
+	})
+	return found, nil
+}
+
+func copyTags(tags map[string]string) map[string]string {
+	if tags == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	return copied
+}
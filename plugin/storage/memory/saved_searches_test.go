@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
+)
+
+func TestSavedSearchStore(t *testing.T) {
+	ss := NewSavedSearchStore()
+	ctx := context.Background()
+	slow := &savedsearchstore.SavedSearch{
+		Owner:       "alice",
+		Name:        "slow",
+		ServiceName: "frontend",
+		Tags:        map[string]string{"error": "true"},
+		DurationMin: time.Second,
+		Lookback:    time.Hour,
+		Limit:       20,
+	}
+	shared := &savedsearchstore.SavedSearch{Owner: "bob", Name: "errors", ServiceName: "billing", Shared: true}
+	private := &savedsearchstore.SavedSearch{Owner: "bob", Name: "private", ServiceName: "billing"}
+	for _, search := range []*savedsearchstore.SavedSearch{slow, shared, private} {
+		require.NoError(t, ss.WriteSavedSearch(ctx, search))
+	}
+
+	search, err := ss.GetSavedSearch(ctx, "alice", "slow")
+	require.NoError(t, err)
+	assert.Equal(t, slow, search)
+	// the stored search is a copy
+	search.Tags["error"] = "false"
+	search, err = ss.GetSavedSearch(ctx, "alice", "slow")
+	require.NoError(t, err)
+	assert.Equal(t, "true", search.Tags["error"])
+	_, err = ss.GetSavedSearch(ctx, "bob", "slow")
+	require.ErrorIs(t, err, savedsearchstore.ErrSavedSearchNotFound)
+
+	found, err := ss.FindSavedSearches(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []*savedsearchstore.SavedSearch{slow, shared}, found)
+	found, err = ss.FindSavedSearches(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []*savedsearchstore.SavedSearch{shared, private}, found)
+
+	require.NoError(t, ss.DeleteSavedSearch(ctx, "alice", "slow"))
+	_, err = ss.GetSavedSearch(ctx, "alice", "slow")
+	require.ErrorIs(t, err, savedsearchstore.ErrSavedSearchNotFound)
+	require.ErrorIs(t, ss.DeleteSavedSearch(ctx, "alice", "slow"), savedsearchstore.ErrSavedSearchNotFound)
+}
+
+func TestSavedSearchStoreTenancy(t *testing.T) {
+	ss := NewSavedSearchStore()
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	require.NoError(t, ss.WriteSavedSearch(acme, &savedsearchstore.SavedSearch{Name: "slow", ServiceName: "frontend", Shared: true}))
+
+	_, err := ss.GetSavedSearch(context.Background(), "", "slow")
+	require.ErrorIs(t, err, savedsearchstore.ErrSavedSearchNotFound)
+	found, err := ss.FindSavedSearches(context.Background(), "")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	require.ErrorIs(t, ss.DeleteSavedSearch(context.Background(), "", "slow"), savedsearchstore.ErrSavedSearchNotFound)
+
+	found, err = ss.FindSavedSearches(acme, "")
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+}
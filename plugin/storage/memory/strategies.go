@@ -0,0 +1,73 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+// StrategyStore is an in-memory store of the sampling strategies of the services.
+// The strategies are copied, so that the callers may modify them.
+type StrategyStore struct {
+	sync.RWMutex
+	strategies map[string]*api_v2.SamplingStrategyResponse
+}
+
+// NewStrategyStore creates an in-memory sampling strategies store.
+func NewStrategyStore() *StrategyStore {
+	return &StrategyStore{
+		strategies: make(map[string]*api_v2.SamplingStrategyResponse),
+	}
+}
+
+// GetStrategy implements samplingstore.StrategyStore#GetStrategy.
+func (ss *StrategyStore) GetStrategy(_ context.Context, service string) (*api_v2.SamplingStrategyResponse, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+	strategy, ok := ss.strategies[service]
+	if !ok {
+		return nil, samplingstore.ErrStrategyNotFound
+	}
+	return cloneStrategy(strategy), nil
+}
+
+// FindStrategies implements samplingstore.StrategyStore#FindStrategies.
+func (ss *StrategyStore) FindStrategies(context.Context) (map[string]*api_v2.SamplingStrategyResponse, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+	found := make(map[string]*api_v2.SamplingStrategyResponse, len(ss.strategies))
+	for service, strategy := range ss.strategies {
+		found[service] = cloneStrategy(strategy)
+	}
+	return found, nil
+}
+
+// WriteStrategy implements samplingstore.StrategyStore#WriteStrategy.
+func (ss *StrategyStore) WriteStrategy(_ context.Context, service string, strategy *api_v2.SamplingStrategyResponse) error {
+	ss.Lock()
+	defer ss.Unlock()
+	ss.strategies[service] = cloneStrategy(strategy)
+	return nil
+}
+
+// DeleteStrategy implements samplingstore.StrategyStore#DeleteStrategy.
+func (ss *StrategyStore) DeleteStrategy(_ context.Context, service string) error {
+	ss.Lock()
+	defer ss.Unlock()
+	if _, ok := ss.strategies[service]; !ok {
+		return samplingstore.ErrStrategyNotFound
+	}
+	delete(ss.strategies, service)
+	return nil
+}
+
+func cloneStrategy(strategy *api_v2.SamplingStrategyResponse) *api_v2.SamplingStrategyResponse {
+	return proto.Clone(strategy).(*api_v2.SamplingStrategyResponse)
+}
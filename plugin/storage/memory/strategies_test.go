@@ -0,0 +1,50 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+func probabilisticStrategy(rate float64) *api_v2.SamplingStrategyResponse {
+	return &api_v2.SamplingStrategyResponse{
+		StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: rate},
+	}
+}
+
+func TestStrategyStore(t *testing.T) {
+	ss := NewStrategyStore()
+	ctx := context.Background()
+	require.NoError(t, ss.WriteStrategy(ctx, "frontend", probabilisticStrategy(0.5)))
+	require.NoError(t, ss.WriteStrategy(ctx, "billing", probabilisticStrategy(1)))
+
+	strategy, err := ss.GetStrategy(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, probabilisticStrategy(0.5), strategy)
+	// the stored strategy is a copy
+	strategy.ProbabilisticSampling.SamplingRate = 0.1
+	strategy, err = ss.GetStrategy(ctx, "frontend")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, strategy.ProbabilisticSampling.SamplingRate, 1e-9)
+
+	found, err := ss.FindStrategies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*api_v2.SamplingStrategyResponse{
+		"frontend": probabilisticStrategy(0.5),
+		"billing":  probabilisticStrategy(1),
+	}, found)
+
+	require.NoError(t, ss.DeleteStrategy(ctx, "frontend"))
+	_, err = ss.GetStrategy(ctx, "frontend")
+	require.ErrorIs(t, err, samplingstore.ErrStrategyNotFound)
+	require.ErrorIs(t, ss.DeleteStrategy(ctx, "frontend"), samplingstore.ErrStrategyNotFound)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
+)
+
+// WarningStore is an in-memory store of the warnings of the traces.
+type WarningStore struct {
+	sync.RWMutex
+	// maxTraces bounds the number of traces with warnings stored per tenant, 0 meaning no limit.
+	maxTraces int
+	perTenant map[string]*tenantWarnings
+}
+
+type tenantWarnings struct {
+	traces map[model.TraceID]*warningstore.TraceWarnings
+	ids    []model.TraceID // in the order of insertion, to evict the oldest traces first
+}
+
+// NewWarningStore creates an in-memory warnings store.
+func NewWarningStore(maxTraces int) *WarningStore {
+	return &WarningStore{
+		maxTraces: maxTraces,
+		perTenant: make(map[string]*tenantWarnings),
+	}
+}
+
+// WriteTraceWarnings implements warningstore.Writer#WriteTraceWarnings.
+func (ws *WarningStore) WriteTraceWarnings(ctx context.Context, warnings *warningstore.TraceWarnings) error {
+	ws.Lock()
+	defer ws.Unlock()
+	tenantID := tenancy.GetTenant(ctx)
+	t, ok := ws.perTenant[tenantID]
+	if !ok {
+		t = &tenantWarnings{traces: make(map[model.TraceID]*warningstore.TraceWarnings)}
+		ws.perTenant[tenantID] = t
+	}
+	if _, ok := t.traces[warnings.TraceID]; !ok {
+		if ws.maxTraces > 0 && len(t.ids) >= ws.maxTraces {
+			delete(t.traces, t.ids[0])
+			t.ids = t.ids[1:]
+		}
+		t.ids = append(t.ids, warnings.TraceID)
+	}
+	t.traces[warnings.TraceID] = warnings
+	return nil
+}
+
+// FindTraceWarnings implements warningstore.Reader#FindTraceWarnings.
+func (ws *WarningStore) FindTraceWarnings(ctx context.Context, query *warningstore.Query) ([]*warningstore.TraceWarnings, error) {
+	ws.RLock()
+	defer ws.RUnlock()
+	t, ok := ws.perTenant[tenancy.GetTenant(ctx)]
+	if !ok {
+		return nil, nil
+	}
+	var found []*warningstore.TraceWarnings
+	for _, warnings := range t.traces {
+		if query.Type != "" && !warnings.HasType(query.Type) {
+			continue
+		}
+		if !query.StartTimeMin.IsZero() && warnings.StartTime.Before(query.StartTimeMin) {
+			continue
+		}
+		if !query.StartTimeMax.IsZero() && warnings.StartTime.After(query.StartTimeMax) {
+			continue
+		}
+		found = append(found, warnings)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].StartTime.After(found[j].StartTime)
+	})
+	if query.NumTraces > 0 && len(found) > query.NumTraces {
+		found = found[:query.NumTraces]
+	}
+	return found, nil
+}
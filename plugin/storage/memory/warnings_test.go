@@ -0,0 +1,73 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
+)
+
+func newTraceWarnings(traceID uint64, startTime time.Time, warningType string) *warningstore.TraceWarnings {
+	return &warningstore.TraceWarnings{
+		TraceID:   model.NewTraceID(0, traceID),
+		StartTime: startTime,
+		Services:  []string{"svc"},
+		Warnings:  []warningstore.SpanWarning{{SpanID: model.NewSpanID(1), Type: warningType, Message: "warning"}},
+	}
+}
+
+func TestWarningStoreFind(t *testing.T) {
+	ws := NewWarningStore(0)
+	ctx := context.Background()
+	now := time.Now()
+	clockSkew := newTraceWarnings(1, now.Add(-time.Minute), "clock-skew")
+	missingSpan := newTraceWarnings(2, now, "missing-span")
+	old := newTraceWarnings(3, now.Add(-time.Hour), "clock-skew")
+	for _, warnings := range []*warningstore.TraceWarnings{clockSkew, missingSpan, old} {
+		require.NoError(t, ws.WriteTraceWarnings(ctx, warnings))
+	}
+
+	found, err := ws.FindTraceWarnings(ctx, &warningstore.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []*warningstore.TraceWarnings{missingSpan, clockSkew, old}, found)
+
+	found, err = ws.FindTraceWarnings(ctx, &warningstore.Query{Type: "clock-skew", StartTimeMin: now.Add(-2 * time.Minute)})
+	require.NoError(t, err)
+	assert.Equal(t, []*warningstore.TraceWarnings{clockSkew}, found)
+
+	found, err = ws.FindTraceWarnings(ctx, &warningstore.Query{StartTimeMax: now.Add(-time.Second), NumTraces: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []*warningstore.TraceWarnings{clockSkew}, found)
+
+	found, err = ws.FindTraceWarnings(tenancy.WithTenant(ctx, "acme"), &warningstore.Query{})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestWarningStoreWrite(t *testing.T) {
+	ws := NewWarningStore(2)
+	ctx := context.Background()
+	now := time.Now()
+	for i := uint64(1); i <= 3; i++ {
+		require.NoError(t, ws.WriteTraceWarnings(ctx, newTraceWarnings(i, now, "clock-skew")))
+	}
+	// the warnings of the same trace are replaced
+	replaced := newTraceWarnings(3, now, "missing-span")
+	require.NoError(t, ws.WriteTraceWarnings(ctx, replaced))
+
+	found, err := ws.FindTraceWarnings(ctx, &warningstore.Query{})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	// the oldest trace is evicted
+	assert.Nil(t, ws.perTenant[""].traces[model.NewTraceID(0, 1)])
+	assert.Equal(t, replaced, ws.perTenant[""].traces[model.NewTraceID(0, 3)])
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (w *SpanWriter) compactPeriodically() {
+	defer w.flushWG.Done()
+	ticker := time.NewTicker(w.options.IndexCompactionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.compactIndex()
+		case <-w.flushDone:
+			return
+		}
+	}
+}
+
+// compactIndex merges the index objects of each partition of the shards into a single object,
+// so that a lookup reads at most an object per day. The objects merged are deleted once the
+// merged object is written, and the readers listing them before look the trace up again.
+func (w *SpanWriter) compactIndex() error {
+	var errs []error
+	for i := 0; i < indexShards; i++ {
+		shard := fmt.Sprintf("%x", i)
+		keys, err := w.listIndex(shard)
+		if err != nil {
+			w.logger.Error("Failed to list the index of Parquet files", zap.String("shard", shard), zap.Error(err))
+			w.metrics.IndexCompactionsFailure.Inc(1)
+			errs = append(errs, err)
+			continue
+		}
+		// the keys are sorted, the partitions are compacted by date
+		var partitions []string
+		objectsOf := make(map[string][]string)
+		for _, key := range keys {
+			partition := path.Dir(key)
+			if _, ok := objectsOf[partition]; !ok {
+				partitions = append(partitions, partition)
+			}
+			objectsOf[partition] = append(objectsOf[partition], key)
+		}
+		for _, partition := range partitions {
+			objects := objectsOf[partition]
+			if len(objects) < 2 {
+				continue
+			}
+			if err := w.compactPartition(shard, path.Base(partition), objects); err != nil {
+				w.logger.Error("Failed to compact the index of Parquet files",
+					zap.String("partition", partition), zap.Int("objects", len(objects)), zap.Error(err))
+				w.metrics.IndexCompactionsFailure.Inc(1)
+				errs = append(errs, err)
+				continue
+			}
+			w.metrics.IndexCompactionsSuccess.Inc(1)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *SpanWriter) listIndex(shard string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.options.UploadTimeout)
+	defer cancel()
+	return w.store.ListObjects(ctx, indexPrefix(w.options.KeyPrefix, shard))
+}
+
+// compactPartition writes the index object merging the objects of the partition, then deletes them.
+func (w *SpanWriter) compactPartition(shard, partition string, keys []string) error {
+	date, ok := strings.CutPrefix(partition, indexDatePrefix)
+	if !ok {
+		return fmt.Errorf("unexpected partition %s of the index", partition)
+	}
+	objects := make([]*indexObject, len(keys))
+	for i, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), w.options.UploadTimeout)
+		data, err := w.store.GetObject(ctx, key)
+		cancel()
+		if err != nil {
+			return err
+		}
+		objects[i] = &indexObject{}
+		if err := json.Unmarshal(data, objects[i]); err != nil {
+			return fmt.Errorf("failed to parse the index object %s: %w", key, err)
+		}
+	}
+	if err := w.uploadIndex(indexKey(w.options.KeyPrefix, shard, date, w.timeNow()), mergeIndex(objects)); err != nil {
+		return err
+	}
+	var errs []error
+	for _, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), w.options.UploadTimeout)
+		// the entries of the objects that fail to be deleted are merged again by the next compaction
+		errs = append(errs, w.store.DeleteObject(ctx, key))
+		cancel()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+)
+
+// indexKeys returns the keys of the index objects of the shard.
+func (s *fakeObjectStore) indexKeys(t *testing.T, shard string) []string {
+	keys, err := s.ListObjects(context.Background(), indexPrefix("jaeger", shard))
+	require.NoError(t, err)
+	return keys
+}
+
+// writeSpansAt writes the spans to the store at the time, in a file per span.
+func writeSpansAt(t *testing.T, store objectstore.Store, now time.Time, spans ...*model.Span) {
+	opts := testOptions()
+	opts.MaxSpansPerFile = 1
+	w := newSpanWriter(store, opts, metricstest.NewFactory(0), zap.NewNop())
+	w.timeNow = func() time.Time { return now }
+	for _, span := range spans {
+		require.NoError(t, w.WriteSpan(context.Background(), span))
+		now = now.Add(time.Second)
+	}
+	require.NoError(t, w.Close())
+}
+
+func TestSpanWriterCompactIndex(t *testing.T) {
+	store := &fakeObjectStore{}
+	day := time.Date(2024, 5, 6, 23, 0, 0, 0, time.UTC)
+	writeSpansAt(t, store, day,
+		spanOfTrace(1, "frontend", testStartTime),
+		spanOfTrace(1, "billing", testStartTime),
+		spanOfTrace(0x11, "frontend", testStartTime))
+	writeSpansAt(t, store, day.Add(2*time.Hour),
+		spanOfTrace(1, "frontend", testStartTime.Add(24*time.Hour)),
+		spanOfTrace(0x21, "frontend", testStartTime.Add(24*time.Hour)))
+	writeSpansAt(t, store, day, spanOfTrace(2, "frontend", testStartTime))
+	index := store.index(t)
+	require.Len(t, store.indexKeys(t, "1"), 5)
+
+	metricsFactory := metricstest.NewFactory(0)
+	w := newSpanWriter(store, testOptions(), metricsFactory, zap.NewNop())
+	w.timeNow = func() time.Time { return day.Add(3 * time.Hour) }
+	require.NoError(t, w.compactIndex())
+	require.NoError(t, w.Close())
+
+	// an object per day, in the partition of the objects merged
+	keys := store.indexKeys(t, "1")
+	require.Len(t, keys, 2)
+	assert.True(t, strings.HasPrefix(keys[0], "jaeger/_index/1/dt=2024-05-06/"), keys[0])
+	assert.True(t, strings.HasPrefix(keys[1], "jaeger/_index/1/dt=2024-05-07/"), keys[1])
+	assert.Len(t, store.indexKeys(t, "2"), 1)
+	assert.Equal(t, index, store.index(t))
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "parquet_index_compactions", Tags: map[string]string{"status": "success"}, Value: 2})
+
+	trace, err := newSpanReader(store, testReaderOptions()).GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 3)
+
+	// the compacted partitions are left as is
+	store.gets = 0
+	require.NoError(t, w.compactIndex())
+	assert.Zero(t, store.gets)
+	assert.Equal(t, keys, store.indexKeys(t, "1"))
+}
+
+// compactionFailingStore fails the deletions, the listings or the reads of the index objects.
+type compactionFailingStore struct {
+	fakeObjectStore
+	deleteErr error
+	listErr   error
+	corrupt   bool
+}
+
+func (s *compactionFailingStore) DeleteObject(ctx context.Context, key string) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	return s.fakeObjectStore.DeleteObject(ctx, key)
+}
+
+func (s *compactionFailingStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	return s.fakeObjectStore.ListObjects(ctx, prefix)
+}
+
+func (s *compactionFailingStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.fakeObjectStore.GetObject(ctx, key)
+	if s.corrupt && strings.HasSuffix(key, ".json") {
+		return []byte("corrupt"), err
+	}
+	return body, err
+}
+
+func TestSpanWriterCompactIndexErrors(t *testing.T) {
+	store := &compactionFailingStore{}
+	writeSpans(t, store, span("frontend", testStartTime), span("billing", testStartTime))
+	index := store.index(t)
+	metricsFactory := metricstest.NewFactory(0)
+	w := newSpanWriter(store, testOptions(), metricsFactory, zap.NewNop())
+	defer w.Close()
+
+	store.corrupt = true
+	require.ErrorContains(t, w.compactIndex(), "failed to parse the index object")
+	store.corrupt = false
+	assert.Len(t, store.indexKeys(t, "1"), 2)
+
+	store.listErr = errors.New("access denied")
+	require.ErrorContains(t, w.compactIndex(), "access denied")
+	store.listErr = nil
+
+	// the objects that are not deleted are merged again
+	store.deleteErr = errors.New("slow down")
+	require.ErrorContains(t, w.compactIndex(), "slow down")
+	assert.Len(t, store.indexKeys(t, "1"), 3)
+	assert.Equal(t, index, dedupIndex(store.index(t)))
+	store.deleteErr = nil
+	require.NoError(t, w.compactIndex())
+	assert.Len(t, store.indexKeys(t, "1"), 1)
+	assert.Equal(t, index, store.index(t))
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "parquet_index_compactions", Tags: map[string]string{"status": "success"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "parquet_index_compactions", Tags: map[string]string{"status": "failure"}, Value: 2 + indexShards})
+}
+
+// dedupIndex removes the duplicate files of the traces.
+func dedupIndex(index map[string][]string) map[string][]string {
+	deduped := make(map[string][]string, len(index))
+	for id, files := range index {
+		for i, file := range files {
+			if i == 0 || file != files[i-1] {
+				deduped[id] = append(deduped[id], file)
+			}
+		}
+	}
+	return deduped
+}
+
+func TestSpanWriterCompactionInterval(t *testing.T) {
+	store := &fakeObjectStore{}
+	writeSpans(t, store, span("frontend", testStartTime), span("billing", testStartTime))
+	opts := testOptions()
+	opts.IndexCompactionInterval = time.Millisecond
+	w := newSpanWriter(store, opts, metricstest.NewFactory(0), zap.NewNop())
+	defer w.Close()
+	assert.Eventually(t, func() bool {
+		return len(store.indexKeys(t, "1")) == 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+// compactingStore compacts the index after the first listing of its objects, before they are read.
+type compactingStore struct {
+	fakeObjectStore
+	once    sync.Once
+	compact func()
+	lists   int
+}
+
+func (s *compactingStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := s.fakeObjectStore.ListObjects(ctx, prefix)
+	s.lists++
+	s.once.Do(s.compact)
+	return keys, err
+}
+
+func TestSpanReaderGetTraceDuringCompaction(t *testing.T) {
+	store := &compactingStore{}
+	writeSpans(t, store, span("frontend", testStartTime), span("billing", testStartTime))
+	w := newSpanWriter(&store.fakeObjectStore, testOptions(), metricstest.NewFactory(0), zap.NewNop())
+	defer w.Close()
+	store.compact = func() {
+		assert.NoError(t, w.compactIndex())
+	}
+
+	trace, err := newSpanReader(store, testReaderOptions()).GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+	assert.Equal(t, 2, store.lists)
+}
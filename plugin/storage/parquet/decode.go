@@ -0,0 +1,165 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var errInvalidFile = errors.New("invalid parquet file")
+
+// readBatchSize is the number of rows read at once from a file.
+const readBatchSize = 1024
+
+// decodeSpans returns the spans of the trace held by a Parquet file with the columns of spanRow.
+// The types of the tags are inferred from their JSON values, binary tags are read as base64 strings.
+func decodeSpans(data []byte, traceID model.TraceID) ([]*model.Span, error) {
+	file, err := parquetgo.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidFile, err)
+	}
+	reader := parquetgo.NewGenericReader[spanRow](file)
+	defer reader.Close()
+	id := traceID.String()
+	var spans []*model.Span
+	rows := make([]spanRow, readBatchSize)
+	for {
+		n, err := reader.Read(rows)
+		for i := range rows[:n] {
+			if rows[i].TraceID != id {
+				continue
+			}
+			span, err := rows[i].span(traceID)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, span)
+		}
+		if errors.Is(err, io.EOF) {
+			return spans, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidFile, err)
+		}
+	}
+}
+
+func (row *spanRow) span(traceID model.TraceID) (*model.Span, error) {
+	span := &model.Span{
+		TraceID:       traceID,
+		OperationName: row.OperationName,
+		StartTime:     model.EpochMicrosecondsAsTime(uint64(row.StartTime)),
+		Duration:      model.MicrosecondsAsDuration(uint64(row.DurationUs)),
+		Flags:         model.Flags(uint32(row.Flags)),
+	}
+	var err error
+	if span.SpanID, err = model.SpanIDFromString(row.SpanID); err != nil {
+		return nil, err
+	}
+	if span.Tags, err = tagsFromJSON([]byte(row.Tags)); err != nil {
+		return nil, err
+	}
+	processTags, err := tagsFromJSON([]byte(row.ProcessTags))
+	if err != nil {
+		return nil, err
+	}
+	span.Process = model.NewProcess(row.ServiceName, processTags)
+	if span.Logs, err = logsFromJSON([]byte(row.Logs)); err != nil {
+		return nil, err
+	}
+	if span.References, err = referencesFromJSON([]byte(row.References)); err != nil {
+		return nil, err
+	}
+	return span, nil
+}
+
+func tagsFromJSON(data []byte) ([]model.KeyValue, error) {
+	var m map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: invalid tags: %w", errInvalidFile, err)
+	}
+	return tagsFromMap(m), nil
+}
+
+// tagsFromMap converts the values of tagsMap back to tags, sorted by key.
+func tagsFromMap(m map[string]any) []model.KeyValue {
+	if len(m) == 0 {
+		return nil
+	}
+	tags := make(model.KeyValues, 0, len(m))
+	for key, value := range m {
+		switch v := value.(type) {
+		case bool:
+			tags = append(tags, model.Bool(key, v))
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				tags = append(tags, model.Int64(key, i))
+			} else {
+				f, _ := v.Float64()
+				tags = append(tags, model.Float64(key, f))
+			}
+		case string:
+			tags = append(tags, model.String(key, v))
+		default:
+			raw, _ := json.Marshal(v)
+			tags = append(tags, model.String(key, string(raw)))
+		}
+	}
+	tags.Sort()
+	return tags
+}
+
+func logsFromJSON(data []byte) ([]model.Log, error) {
+	var logs []logJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&logs); err != nil {
+		return nil, fmt.Errorf("%w: invalid logs: %w", errInvalidFile, err)
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+	result := make([]model.Log, len(logs))
+	for i, log := range logs {
+		result[i] = model.Log{Timestamp: model.EpochMicrosecondsAsTime(log.Timestamp), Fields: tagsFromMap(log.Fields)}
+	}
+	return result, nil
+}
+
+func referencesFromJSON(data []byte) ([]model.SpanRef, error) {
+	var refs []referenceJSON
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("%w: invalid references: %w", errInvalidFile, err)
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	result := make([]model.SpanRef, len(refs))
+	for i, ref := range refs {
+		refType, ok := model.SpanRefType_value[ref.RefType]
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid reference type %q", errInvalidFile, ref.RefType)
+		}
+		traceID, err := model.TraceIDFromString(ref.TraceID)
+		if err != nil {
+			return nil, err
+		}
+		spanID, err := model.SpanIDFromString(ref.SpanID)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = model.SpanRef{RefType: model.SpanRefType(refType), TraceID: traceID, SpanID: spanID}
+	}
+	return result, nil
+}
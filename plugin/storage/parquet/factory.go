@@ -0,0 +1,117 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"errors"
+	"flag"
+	"io"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory     = (*Factory)(nil)
+	_ io.Closer           = (*Factory)(nil)
+	_ plugin.Configurable = (*Factory)(nil)
+)
+
+var errNoDependencies = errors.New("parquet storage does not support dependencies")
+
+// Factory implements storage.Factory and creates storage components that upload spans
+// as Parquet files to an S3 compatible object storage, and read them back by trace ID.
+type Factory struct {
+	options Options
+
+	metricsFactory metrics.Factory
+	logger         *zap.Logger
+
+	store  objectstore.Store
+	reader *SpanReader
+	writer *SpanWriter
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, _ *zap.Logger) {
+	f.options.InitFromViper(v)
+}
+
+// configureFromOptions initializes factory from options.
+func (f *Factory) configureFromOptions(o Options) {
+	f.options = o
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.metricsFactory, f.logger = metricsFactory, logger
+	logger.Info("Parquet factory",
+		zap.String("bucket", f.options.Bucket),
+		zap.String("prefix", f.options.KeyPrefix))
+
+	if f.options.Bucket == "" {
+		return errors.New("the bucket of the parquet storage must be set")
+	}
+	if f.options.MaxSpansPerFile <= 0 || f.options.FlushInterval <= 0 {
+		return errors.New("the maximum number of spans per file and the flush interval of the parquet storage must be positive")
+	}
+	store, err := objectstore.NewS3Store(f.options.s3Config())
+	if err != nil {
+		return err
+	}
+	f.store = store
+	return nil
+}
+
+// CreateSpanReader implements storage.Factory
+func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
+	if f.reader == nil {
+		f.reader = newSpanReader(f.store, f.options)
+	}
+	return f.reader, nil
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	if f.writer == nil {
+		f.writer = newSpanWriter(f.store, f.options, f.metricsFactory, f.logger)
+	}
+	return f.writer, nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (*Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return nil, errNoDependencies
+}
+
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	// the traces are only found by their ID
+	return storage.Capabilities{StreamingWrites: true}
+}
+
+// Close uploads the buffered spans
+func (f *Factory) Close() error {
+	if f.writer != nil {
+		return f.writer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+)
+
+// startS3 starts an S3 compatible endpoint keeping the objects in memory by path.
+// The objects of the bucket "forbidden" cannot be written.
+func startS3(t *testing.T) (*httptest.Server, func() map[string][]byte) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	var lock sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path+"/", "/forbidden/"):
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			objects[r.URL.Path] = body
+		case r.Method == http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Query().Get("list-type") == "2":
+			prefix := r.URL.Path + "/" + r.URL.Query().Get("prefix")
+			var keys []string
+			for key := range objects {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, fmt.Sprintf("<Contents><Key>%s</Key></Contents>", strings.TrimPrefix(key, r.URL.Path+"/")))
+				}
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(w, "<ListBucketResult><IsTruncated>false</IsTruncated>%s</ListBucketResult>", strings.Join(keys, ""))
+		default:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() map[string][]byte {
+		lock.Lock()
+		defer lock.Unlock()
+		return objects
+	}
+}
+
+func testS3Options(bucket, endpoint string) Options {
+	opts := testReaderOptions()
+	opts.Bucket = bucket
+	opts.Region = "us-east-1"
+	opts.Endpoint = endpoint
+	opts.ForcePathStyle = true
+	return opts
+}
+
+func TestParquetFactory(t *testing.T) {
+	server, objects := startS3(t)
+	f := NewFactory()
+	f.configureFromOptions(testS3Options("traces", server.URL))
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+	_, err := f.CreateDependencyReader()
+	require.ErrorIs(t, err, errNoDependencies)
+	assert.False(t, f.Capabilities().TraceSearch)
+
+	w, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	w2, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	assert.Same(t, w, w2)
+	require.NoError(t, w.WriteSpan(context.Background(), span("frontend", testStartTime)))
+	assert.Empty(t, objects())
+	require.NoError(t, f.Close())
+	var files []string
+	for path := range objects() {
+		if strings.HasSuffix(path, ".parquet") {
+			files = append(files, path)
+		}
+	}
+	require.Len(t, files, 1)
+	assert.True(t, strings.HasPrefix(files[0], "/traces/jaeger/dt=2024-05-06/service=frontend/"), files[0])
+	assert.Len(t, objects(), 2)
+
+	r, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	r2, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	assert.Same(t, r, r2)
+	trace, err := r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 1)
+	assert.Equal(t, "frontend", trace.Spans[0].Process.ServiceName)
+}
+
+func TestParquetFactoryS3Errors(t *testing.T) {
+	server, _ := startS3(t)
+	f := NewFactory()
+	f.configureFromOptions(testS3Options("forbidden", server.URL))
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	w, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSpan(context.Background(), span("frontend", testStartTime)))
+	require.ErrorContains(t, f.Close(), "failed to write")
+
+	r, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorContains(t, err, "failed to list jaeger/_index/1/ in the bucket forbidden")
+
+	store, err := objectstore.NewS3Store(testS3Options("traces", server.URL).s3Config())
+	require.NoError(t, err)
+	_, err = store.GetObject(context.Background(), "missing")
+	require.ErrorContains(t, err, "failed to read missing from the bucket traces")
+	require.ErrorIs(t, err, objectstore.ErrObjectNotFound)
+
+	forbidden, err := objectstore.NewS3Store(testS3Options("forbidden", server.URL).s3Config())
+	require.NoError(t, err)
+	require.ErrorContains(t, forbidden.DeleteObject(context.Background(), "key"), "failed to delete key from the bucket forbidden")
+}
+
+func TestParquetFactoryCompaction(t *testing.T) {
+	server, objects := startS3(t)
+	store, err := objectstore.NewS3Store(testS3Options("traces", server.URL).s3Config())
+	require.NoError(t, err)
+	writeSpans(t, store, span("frontend", testStartTime), span("billing", testStartTime))
+	indexObjects := func() int {
+		var n int
+		for key := range objects() {
+			if strings.HasPrefix(key, "/traces/jaeger/_index/1/") {
+				n++
+			}
+		}
+		return n
+	}
+	require.Equal(t, 2, indexObjects())
+
+	w := newSpanWriter(store, testS3Options("traces", server.URL), metrics.NullFactory, zap.NewNop())
+	require.NoError(t, w.compactIndex())
+	require.NoError(t, w.Close())
+	assert.Equal(t, 1, indexObjects())
+
+	trace, err := newSpanReader(store, testReaderOptions()).GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+}
+
+func TestParquetFactoryInitFromViper(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--parquet.s3.bucket=traces"}))
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, "traces", f.options.Bucket)
+}
+
+func TestParquetFactoryInitializeErrors(t *testing.T) {
+	f := NewFactory()
+	f.configureFromOptions(Options{MaxSpansPerFile: 1, FlushInterval: 1})
+	require.ErrorContains(t, f.Initialize(metrics.NullFactory, zap.NewNop()), "the bucket of the parquet storage must be set")
+	require.NoError(t, f.Close())
+
+	f = NewFactory()
+	f.configureFromOptions(Options{Bucket: "traces"})
+	require.ErrorContains(t, f.Initialize(metrics.NullFactory, zap.NewNop()), "must be positive")
+	require.NoError(t, f.Close())
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// spanRow is a row of the Parquet files, a row per span. The tags, logs and references are
+// JSON strings, so that the schema does not depend on the spans.
+type spanRow struct {
+	TraceID       string `parquet:"trace_id"`
+	SpanID        string `parquet:"span_id"`
+	ParentSpanID  string `parquet:"parent_span_id"`
+	ServiceName   string `parquet:"service_name"`
+	OperationName string `parquet:"operation_name"`
+	SpanKind      string `parquet:"span_kind"`
+	StartTime     int64  `parquet:"start_time,timestamp(microsecond)"`
+	DurationUs    int64  `parquet:"duration_us"`
+	Flags         int32  `parquet:"flags"`
+	Tags          string `parquet:"tags"`
+	ProcessTags   string `parquet:"process_tags"`
+	Logs          string `parquet:"logs"`
+	References    string `parquet:"references"`
+}
+
+func newSpanRow(span *model.Span) spanRow {
+	row := spanRow{
+		TraceID:       span.TraceID.String(),
+		SpanID:        span.SpanID.String(),
+		ServiceName:   span.Process.GetServiceName(),
+		OperationName: span.OperationName,
+		StartTime:     int64(model.TimeAsEpochMicroseconds(span.StartTime)),
+		DurationUs:    int64(model.DurationAsMicroseconds(span.Duration)),
+		Flags:         int32(span.Flags),
+		Tags:          toJSON(tagsMap(span.Tags)),
+		ProcessTags:   toJSON(tagsMap(span.Process.GetTags())),
+	}
+	if parent := span.ParentSpanID(); parent != 0 {
+		row.ParentSpanID = parent.String()
+	}
+	if kind, ok := span.GetSpanKind(); ok {
+		row.SpanKind = kind.String()
+	}
+	logs := make([]logJSON, len(span.Logs))
+	for i, log := range span.Logs {
+		logs[i] = logJSON{Timestamp: model.TimeAsEpochMicroseconds(log.Timestamp), Fields: tagsMap(log.Fields)}
+	}
+	row.Logs = toJSON(logs)
+	refs := make([]referenceJSON, len(span.References))
+	for i, ref := range span.References {
+		refs[i] = referenceJSON{RefType: ref.RefType.String(), TraceID: ref.TraceID.String(), SpanID: ref.SpanID.String()}
+	}
+	row.References = toJSON(refs)
+	return row
+}
+
+type logJSON struct {
+	Timestamp uint64         `json:"timestamp"`
+	Fields    map[string]any `json:"fields"`
+}
+
+type referenceJSON struct {
+	RefType string `json:"ref_type"`
+	TraceID string `json:"trace_id"`
+	SpanID  string `json:"span_id"`
+}
+
+// tagsMap returns the values of the tags by key, the last tag wins when a key is repeated.
+func tagsMap(tags []model.KeyValue) map[string]any {
+	m := make(map[string]any, len(tags))
+	for i := range tags {
+		kv := &tags[i]
+		switch kv.VType {
+		case model.BoolType:
+			m[kv.Key] = kv.Bool()
+		case model.Int64Type:
+			m[kv.Key] = kv.Int64()
+		case model.Float64Type:
+			if f := kv.Float64(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+				m[kv.Key] = f
+			} else {
+				m[kv.Key] = kv.AsString()
+			}
+		case model.BinaryType:
+			m[kv.Key] = kv.Binary()
+		default:
+			m[kv.Key] = kv.AsString()
+		}
+	}
+	return m
+}
+
+// toJSON marshals values that only hold strings, finite numbers, booleans and bytes, which cannot fail.
+func toJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// encodeFile returns a Parquet file holding a row per span, in a single GZIP compressed row group.
+func encodeFile(spans []*model.Span) ([]byte, error) {
+	rows := make([]spanRow, len(spans))
+	for i, span := range spans {
+		rows[i] = newSpanRow(span)
+	}
+	var file bytes.Buffer
+	w := parquetgo.NewGenericWriter[spanRow](&file, parquetgo.Compression(&parquetgo.Gzip), parquetgo.CreatedBy("jaeger", "", ""))
+	if _, err := w.Write(rows); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return file.Bytes(), nil
+}
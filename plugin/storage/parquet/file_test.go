@@ -0,0 +1,213 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var testStartTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func testSpans() []*model.Span {
+	traceID := model.NewTraceID(0, 0xab)
+	return []*model.Span{
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "GET /orders",
+			StartTime:     testStartTime,
+			Duration:      3 * time.Millisecond,
+			Flags:         1,
+			Tags:          []model.KeyValue{model.String("span.kind", "server"), model.Int64("http.status_code", 200)},
+			Process:       model.NewProcess("frontend", []model.KeyValue{model.String("host", "web-1")}),
+		},
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "SELECT",
+			References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+			StartTime:     testStartTime.Add(time.Millisecond),
+			Duration:      time.Millisecond,
+			Tags:          []model.KeyValue{model.Bool("error", true), model.Float64("ratio", 0.5)},
+			Logs: []model.Log{{
+				Timestamp: testStartTime.Add(2 * time.Millisecond),
+				Fields:    []model.KeyValue{model.String("event", "retry")},
+			}},
+			Process: model.NewProcess("frontend", nil),
+		},
+	}
+}
+
+// fileColumns are the names of the columns of the Parquet files, in order.
+var fileColumns = []string{
+	"trace_id", "span_id", "parent_span_id", "service_name", "operation_name", "span_kind",
+	"start_time", "duration_us", "flags", "tags", "process_tags", "logs", "references",
+}
+
+// readFile checks the metadata of a Parquet file and returns its number of rows and the values
+// of its columns by column name, reading it without its Go schema.
+func readFile(t *testing.T, data []byte) (int64, map[string][]any) {
+	file, err := parquetgo.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	metadata := file.Metadata()
+	require.Len(t, metadata.Schema, len(fileColumns)+1)
+	assert.Equal(t, len(fileColumns), int(metadata.Schema[0].NumChildren))
+	names := make([]string, len(fileColumns))
+	for i, element := range metadata.Schema[1:] {
+		names[i] = element.Name
+		assert.Equal(t, format.Required, *element.RepetitionType, element.Name)
+	}
+	assert.Equal(t, fileColumns, names)
+	require.NotNil(t, metadata.Schema[7].LogicalType.Timestamp)
+	assert.NotNil(t, metadata.Schema[7].LogicalType.Timestamp.Unit.Micros)
+
+	values := make(map[string][]any)
+	for _, rowGroup := range file.RowGroups() {
+		for _, chunk := range rowGroup.ColumnChunks() {
+			assert.Equal(t, format.Gzip, metadata.RowGroups[0].Columns[chunk.Column()].MetaData.Codec)
+		}
+		rows := rowGroup.Rows()
+		buf := make([]parquetgo.Row, 16)
+		for {
+			n, err := rows.ReadRows(buf)
+			for _, row := range buf[:n] {
+				for _, v := range row {
+					name := names[v.Column()]
+					switch v.Kind() {
+					case parquetgo.Int32:
+						values[name] = append(values[name], v.Int32())
+					case parquetgo.Int64:
+						values[name] = append(values[name], v.Int64())
+					default:
+						values[name] = append(values[name], string(v.ByteArray()))
+					}
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		require.NoError(t, rows.Close())
+	}
+	return file.NumRows(), values
+}
+
+func TestEncodeFile(t *testing.T) {
+	data, err := encodeFile(testSpans())
+	require.NoError(t, err)
+	numRows, values := readFile(t, data)
+	assert.Equal(t, int64(2), numRows)
+	assert.Equal(t, map[string][]any{
+		"trace_id":       {"00000000000000ab", "00000000000000ab"},
+		"span_id":        {"0000000000000001", "0000000000000002"},
+		"parent_span_id": {"", "0000000000000001"},
+		"service_name":   {"frontend", "frontend"},
+		"operation_name": {"GET /orders", "SELECT"},
+		"span_kind":      {"server", ""},
+		"start_time":     {testStartTime.UnixMicro(), testStartTime.UnixMicro() + 1000},
+		"duration_us":    {int64(3000), int64(1000)},
+		"flags":          {int32(1), int32(0)},
+		"tags":           {`{"http.status_code":200,"span.kind":"server"}`, `{"error":true,"ratio":0.5}`},
+		"process_tags":   {`{"host":"web-1"}`, `{}`},
+		"logs":           {`[]`, `[{"timestamp":` + strconv.FormatInt(testStartTime.UnixMicro()+2000, 10) + `,"fields":{"event":"retry"}}]`},
+		"references":     {`[]`, `[{"ref_type":"CHILD_OF","trace_id":"00000000000000ab","span_id":"0000000000000001"}]`},
+	}, values)
+}
+
+func TestEncodeEmptyFile(t *testing.T) {
+	data, err := encodeFile(nil)
+	require.NoError(t, err)
+	numRows, values := readFile(t, data)
+	assert.Equal(t, int64(0), numRows)
+	assert.Empty(t, values)
+}
+
+func TestTagsMapNotFinite(t *testing.T) {
+	assert.Equal(t, `{"ratio":"NaN"}`, toJSON(tagsMap([]model.KeyValue{model.Float64("ratio", math.NaN())})))
+	assert.Equal(t, `{"payload":"AQI="}`, toJSON(tagsMap([]model.KeyValue{model.Binary("payload", []byte{1, 2})})))
+}
+
+func TestDecodeSpans(t *testing.T) {
+	data, err := encodeFile(append(testSpans(), spanOfTrace(2, "billing", testStartTime)))
+	require.NoError(t, err)
+	spans, err := decodeSpans(data, model.NewTraceID(0, 0xab))
+	require.NoError(t, err)
+
+	traceID := model.NewTraceID(0, 0xab)
+	assert.Equal(t, []*model.Span{
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "GET /orders",
+			StartTime:     testStartTime,
+			Duration:      3 * time.Millisecond,
+			Flags:         1,
+			Tags:          []model.KeyValue{model.Int64("http.status_code", 200), model.String("span.kind", "server")},
+			Process:       model.NewProcess("frontend", []model.KeyValue{model.String("host", "web-1")}),
+		},
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "SELECT",
+			References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+			StartTime:     testStartTime.Add(time.Millisecond),
+			Duration:      time.Millisecond,
+			Tags:          []model.KeyValue{model.Bool("error", true), model.Float64("ratio", 0.5)},
+			Logs: []model.Log{{
+				Timestamp: testStartTime.Add(2 * time.Millisecond),
+				Fields:    []model.KeyValue{model.String("event", "retry")},
+			}},
+			Process: model.NewProcess("frontend", nil),
+		},
+	}, spans)
+
+	spans, err = decodeSpans(data, model.NewTraceID(0, 3))
+	require.NoError(t, err)
+	assert.Empty(t, spans)
+}
+
+func TestDecodeInvalidFile(t *testing.T) {
+	data, err := encodeFile(testSpans())
+	require.NoError(t, err)
+	for name, file := range map[string][]byte{
+		"empty":            nil,
+		"no magic":         append([]byte("PAR0"), data[4:]...),
+		"footer too large": append(append([]byte(nil), data[:len(data)-8]...), 0xff, 0xff, 0xff, 0, 'P', 'A', 'R', '1'),
+		"invalid footer":   append(append([]byte(nil), data[:len(data)-9]...), 0xff, 1, 0, 0, 0, 'P', 'A', 'R', '1'),
+	} {
+		_, err := decodeSpans(file, model.NewTraceID(0, 0xab))
+		require.ErrorIs(t, err, errInvalidFile, name)
+	}
+}
+
+func TestTagsFromJSON(t *testing.T) {
+	tags, err := tagsFromJSON([]byte(`{"big":1e300,"nested":{"a":[1]},"s":"x"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []model.KeyValue{model.Float64("big", 1e300), model.String("nested", `{"a":[1]}`), model.String("s", "x")}, tags)
+
+	_, err = tagsFromJSON([]byte(`[`))
+	require.ErrorIs(t, err, errInvalidFile)
+	_, err = logsFromJSON([]byte(`{`))
+	require.ErrorIs(t, err, errInvalidFile)
+	_, err = referencesFromJSON([]byte(`[{"ref_type":"PARENT_OF"}]`))
+	require.ErrorIs(t, err, errInvalidFile)
+	_, err = referencesFromJSON([]byte(`[{"ref_type":"CHILD_OF","trace_id":"x"}]`))
+	require.Error(t, err)
+	_, err = referencesFromJSON([]byte(`[{"ref_type":"CHILD_OF","trace_id":"1","span_id":"x"}]`))
+	require.Error(t, err)
+}
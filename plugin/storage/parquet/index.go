@@ -0,0 +1,114 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"fmt"
+	"math/rand"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// indexDir holds the index of the files by trace ID. Its leading underscore hides it
+	// from the Hive style tables, e.g. of Athena, defined on the files.
+	indexDir = "_index"
+	// indexDatePrefix is the prefix of the partitions of a shard of the index by the UTC date of the
+	// uploads, the index objects of a partition being merged by the compaction.
+	indexDatePrefix = "dt="
+	// indexShards is the number of shards of the index, a lookup reads the index objects of a single shard.
+	indexShards      = 16
+	indexContentType = "application/json"
+)
+
+// indexObject lists the trace IDs of a shard held by each file of an upload.
+type indexObject struct {
+	Files []indexedFile `json:"files"`
+}
+
+type indexedFile struct {
+	Key      string   `json:"key"`
+	TraceIDs []string `json:"traceIds"`
+}
+
+// indexShard returns the shard of the index holding the trace ID.
+func indexShard(traceID model.TraceID) string {
+	return fmt.Sprintf("%x", traceID.Low%indexShards)
+}
+
+// indexPrefix returns the prefix of the keys of the index objects of a shard.
+func indexPrefix(keyPrefix, shard string) string {
+	return path.Join(keyPrefix, indexDir, shard) + "/"
+}
+
+// indexPartition returns the prefix of the keys of the index objects of a shard uploaded at the date.
+func indexPartition(keyPrefix, shard, date string) string {
+	return indexPrefix(keyPrefix, shard) + indexDatePrefix + date + "/"
+}
+
+// indexKey returns a new key of an index object of the partition of the shard.
+func indexKey(keyPrefix, shard, date string, now time.Time) string {
+	return indexPartition(keyPrefix, shard, date) + fmt.Sprintf("%d-%08x.json", now.UnixNano(), rand.Uint32())
+}
+
+// buildIndex returns the index objects of the files by shard.
+func buildIndex(files map[string][]*model.Span) map[string]*indexObject {
+	index := make(map[string]*indexObject)
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		traceIDs := make(map[string]map[string]struct{})
+		for _, span := range files[key] {
+			shard := indexShard(span.TraceID)
+			if traceIDs[shard] == nil {
+				traceIDs[shard] = make(map[string]struct{})
+			}
+			traceIDs[shard][span.TraceID.String()] = struct{}{}
+		}
+		for shard, ids := range traceIDs {
+			file := indexedFile{Key: key, TraceIDs: make([]string, 0, len(ids))}
+			for id := range ids {
+				file.TraceIDs = append(file.TraceIDs, id)
+			}
+			sort.Strings(file.TraceIDs)
+			if index[shard] == nil {
+				index[shard] = &indexObject{}
+			}
+			index[shard].Files = append(index[shard].Files, file)
+		}
+	}
+	return index
+}
+
+// mergeIndex returns the index object listing the trace IDs of the files of all the objects.
+func mergeIndex(objects []*indexObject) *indexObject {
+	traceIDs := make(map[string]map[string]struct{})
+	for _, object := range objects {
+		for _, file := range object.Files {
+			if traceIDs[file.Key] == nil {
+				traceIDs[file.Key] = make(map[string]struct{})
+			}
+			for _, id := range file.TraceIDs {
+				traceIDs[file.Key][id] = struct{}{}
+			}
+		}
+	}
+	merged := &indexObject{Files: make([]indexedFile, 0, len(traceIDs))}
+	for key, ids := range traceIDs {
+		file := indexedFile{Key: key, TraceIDs: make([]string, 0, len(ids))}
+		for id := range ids {
+			file.TraceIDs = append(file.TraceIDs, id)
+		}
+		sort.Strings(file.TraceIDs)
+		merged.Files = append(merged.Files, file)
+	}
+	sort.Slice(merged.Files, func(i, j int) bool { return merged.Files[i].Key < merged.Files[j].Key })
+	return merged
+}
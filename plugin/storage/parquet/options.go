@@ -0,0 +1,135 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+)
+
+const (
+	configPrefix         = "parquet"
+	suffixBucket         = ".s3.bucket"
+	suffixKeyPrefix      = ".s3.prefix"
+	suffixRegion         = ".s3.region"
+	suffixEndpoint       = ".s3.endpoint"
+	suffixForcePathStyle = ".s3.force-path-style"
+	suffixMaxSpans       = ".max-spans-per-file"
+	suffixFlushInterval  = ".flush-interval"
+	suffixUploadTimeout  = ".upload-timeout"
+	suffixIndexCacheSize = ".reader.index-cache-size"
+	suffixReadWorkers    = ".reader.workers"
+	suffixCompaction     = ".index.compaction-interval"
+
+	defaultKeyPrefix      = "jaeger/spans"
+	defaultMaxSpans       = 100000
+	defaultFlushInterval  = 5 * time.Minute
+	defaultUploadTimeout  = time.Minute
+	defaultIndexCacheSize = 10000
+	defaultReadWorkers    = 8
+	defaultCompaction     = time.Hour
+)
+
+// Options stores the configuration of the Parquet span storage
+type Options struct {
+	// Bucket is the bucket of the S3 compatible object storage the files are uploaded to.
+	Bucket string `mapstructure:"bucket"`
+	// KeyPrefix is prepended to the keys of the files, before their date and service partitions.
+	KeyPrefix string `mapstructure:"prefix"`
+	Region    string `mapstructure:"region"`
+	// Endpoint overrides the S3 endpoint, e.g. to use MinIO or the interoperability API of GCS.
+	Endpoint       string `mapstructure:"endpoint"`
+	ForcePathStyle bool   `mapstructure:"force_path_style"`
+	// MaxSpansPerFile is the number of spans of a partition after which they are written to a file.
+	MaxSpansPerFile int `mapstructure:"max_spans_per_file"`
+	// FlushInterval is the maximum time a span is buffered before being written to a file.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// UploadTimeout applies to the upload of each file.
+	UploadTimeout time.Duration `mapstructure:"upload_timeout"`
+	// IndexCacheSize is the number of index objects kept in memory by the reader.
+	IndexCacheSize int `mapstructure:"index_cache_size"`
+	// ReadWorkers is the number of objects read concurrently by a lookup of a trace.
+	ReadWorkers int `mapstructure:"read_workers"`
+	// IndexCompactionInterval is the interval between the compactions of the index objects of each day
+	// by the writer, 0 disabling them.
+	IndexCompactionInterval time.Duration `mapstructure:"index_compaction_interval"`
+}
+
+// AddFlags adds flags for Options
+func (*Options) AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		configPrefix+suffixBucket,
+		"",
+		"The bucket of the S3 compatible object storage the Parquet files of spans are uploaded to")
+	flagSet.String(
+		configPrefix+suffixKeyPrefix,
+		defaultKeyPrefix,
+		"The prefix of the keys of the Parquet files, followed by their dt=YYYY-MM-DD/service=<name> partitions")
+	flagSet.String(
+		configPrefix+suffixRegion,
+		"",
+		"The region of the bucket, the default AWS configuration is used if not set")
+	flagSet.String(
+		configPrefix+suffixEndpoint,
+		"",
+		"The endpoint of the S3 compatible object storage, e.g. https://storage.googleapis.com for GCS, AWS S3 if not set")
+	flagSet.Bool(
+		configPrefix+suffixForcePathStyle,
+		false,
+		"Whether the bucket is part of the path of the URLs instead of the host name, as required by some S3 compatible object storages")
+	flagSet.Int(
+		configPrefix+suffixMaxSpans,
+		defaultMaxSpans,
+		"The number of spans of a date and service after which they are written to a Parquet file")
+	flagSet.Duration(
+		configPrefix+suffixFlushInterval,
+		defaultFlushInterval,
+		"The maximum time spans are buffered before being written to a Parquet file")
+	flagSet.Duration(
+		configPrefix+suffixUploadTimeout,
+		defaultUploadTimeout,
+		"The timeout of the upload of each Parquet file")
+	flagSet.Int(
+		configPrefix+suffixIndexCacheSize,
+		defaultIndexCacheSize,
+		"The number of objects of the trace ID index of the Parquet files cached in memory to speed up the reads of traces")
+	flagSet.Int(
+		configPrefix+suffixReadWorkers,
+		defaultReadWorkers,
+		"The number of index objects and Parquet files read concurrently when reading a trace")
+	flagSet.Duration(
+		configPrefix+suffixCompaction,
+		defaultCompaction,
+		"The interval between the compactions of the objects of the trace ID index uploaded each day into a single object, "+
+			"which bounds the objects read when reading a trace; 0 disables the compactions")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (o *Options) InitFromViper(v *viper.Viper) {
+	o.Bucket = v.GetString(configPrefix + suffixBucket)
+	o.KeyPrefix = v.GetString(configPrefix + suffixKeyPrefix)
+	o.Region = v.GetString(configPrefix + suffixRegion)
+	o.Endpoint = v.GetString(configPrefix + suffixEndpoint)
+	o.ForcePathStyle = v.GetBool(configPrefix + suffixForcePathStyle)
+	o.MaxSpansPerFile = v.GetInt(configPrefix + suffixMaxSpans)
+	o.FlushInterval = v.GetDuration(configPrefix + suffixFlushInterval)
+	o.UploadTimeout = v.GetDuration(configPrefix + suffixUploadTimeout)
+	o.IndexCacheSize = v.GetInt(configPrefix + suffixIndexCacheSize)
+	o.ReadWorkers = v.GetInt(configPrefix + suffixReadWorkers)
+	o.IndexCompactionInterval = v.GetDuration(configPrefix + suffixCompaction)
+}
+
+// s3Config returns the bucket the files are uploaded to.
+func (o Options) s3Config() objectstore.S3Config {
+	return objectstore.S3Config{
+		Bucket:         o.Bucket,
+		Region:         o.Region,
+		Endpoint:       o.Endpoint,
+		ForcePathStyle: o.ForcePathStyle,
+	}
+}
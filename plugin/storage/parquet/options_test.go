@@ -0,0 +1,66 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	err := command.ParseFlags([]string{
+		"--parquet.s3.bucket=traces",
+		"--parquet.s3.prefix=cold",
+		"--parquet.s3.region=eu-west-1",
+		"--parquet.s3.endpoint=https://storage.googleapis.com",
+		"--parquet.s3.force-path-style=true",
+		"--parquet.max-spans-per-file=10",
+		"--parquet.flush-interval=1m",
+		"--parquet.upload-timeout=10s",
+		"--parquet.reader.index-cache-size=5",
+		"--parquet.reader.workers=3",
+		"--parquet.index.compaction-interval=10m",
+	})
+	require.NoError(t, err)
+	opts.InitFromViper(v)
+
+	assert.Equal(t, Options{
+		Bucket:          "traces",
+		KeyPrefix:       "cold",
+		Region:          "eu-west-1",
+		Endpoint:        "https://storage.googleapis.com",
+		ForcePathStyle:  true,
+		MaxSpansPerFile: 10,
+		FlushInterval:   time.Minute,
+		UploadTimeout:   10 * time.Second,
+		IndexCacheSize:  5,
+		ReadWorkers:     3,
+
+		IndexCompactionInterval: 10 * time.Minute,
+	}, *opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, _ := config.Viperize(opts.AddFlags)
+	opts.InitFromViper(v)
+
+	assert.Equal(t, Options{
+		KeyPrefix:       defaultKeyPrefix,
+		MaxSpansPerFile: defaultMaxSpans,
+		FlushInterval:   defaultFlushInterval,
+		UploadTimeout:   defaultUploadTimeout,
+		IndexCacheSize:  defaultIndexCacheSize,
+		ReadWorkers:     defaultReadWorkers,
+
+		IndexCompactionInterval: defaultCompaction,
+	}, *opts)
+}
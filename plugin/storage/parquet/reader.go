@@ -0,0 +1,182 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/cache"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var _ spanstore.Reader = (*SpanReader)(nil)
+
+// indexLookupAttempts is the number of times a lookup lists the index objects of the trace.
+const indexLookupAttempts = 3
+
+// SpanReader reads the traces of the Parquet files with the index of their trace IDs. Implements spanstore.Reader
+//
+// Only GetTrace is supported, the trace searches return no results. A lookup lists the index objects
+// of the shard of the trace, reads those that are not cached, then the files holding the trace.
+// The objects of the index are compacted by the writer into an object per day and shard.
+type SpanReader struct {
+	store   objectstore.Store
+	options Options
+	// indexes caches the trace IDs of the index objects, which are never modified once written.
+	indexes *cache.LRU
+}
+
+// indexEntries are the keys of the files holding each trace of an index object.
+type indexEntries map[string][]string
+
+func newSpanReader(store objectstore.Store, options Options) *SpanReader {
+	return &SpanReader{
+		store:   store,
+		options: options,
+		indexes: cache.NewLRU(max(options.IndexCacheSize, 1)),
+	}
+}
+
+// GetTrace reads the spans of the trace from the files listed by the index.
+func (r *SpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	files, err := r.findFiles(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	spans := make([][]*model.Span, len(files))
+	err = r.forEach(len(files), func(i int) error {
+		data, err := r.store.GetObject(ctx, files[i])
+		if err != nil {
+			return err
+		}
+		if spans[i], err = decodeSpans(data, traceID); err != nil {
+			return fmt.Errorf("failed to read the Parquet file %s: %w", files[i], err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	trace := &model.Trace{}
+	for _, s := range spans {
+		trace.Spans = append(trace.Spans, s...)
+	}
+	if len(trace.Spans) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return trace, nil
+}
+
+// findFiles returns the keys of the files holding spans of the trace, in lexicographic order.
+// The lookup is retried if an index object listed is deleted by a compaction before being read,
+// the object merging it being written before.
+func (r *SpanReader) findFiles(ctx context.Context, traceID model.TraceID) ([]string, error) {
+	for attempt := 1; ; attempt++ {
+		files, err := r.lookupFiles(ctx, traceID)
+		if err == nil || attempt == indexLookupAttempts || !errors.Is(err, objectstore.ErrObjectNotFound) {
+			return files, err
+		}
+	}
+}
+
+func (r *SpanReader) lookupFiles(ctx context.Context, traceID model.TraceID) ([]string, error) {
+	keys, err := r.store.ListObjects(ctx, indexPrefix(r.options.KeyPrefix, indexShard(traceID)))
+	if err != nil {
+		return nil, err
+	}
+	id := traceID.String()
+	var lock sync.Mutex
+	found := make(map[string]struct{})
+	err = r.forEach(len(keys), func(i int) error {
+		entries, err := r.loadIndex(ctx, keys[i])
+		if err != nil {
+			return err
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		for _, file := range entries[id] {
+			found[file] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(found))
+	for file := range found {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadIndex returns the entries of an index object, reading it if it is not cached.
+func (r *SpanReader) loadIndex(ctx context.Context, key string) (indexEntries, error) {
+	if entries, ok := r.indexes.Get(key).(indexEntries); ok {
+		return entries, nil
+	}
+	data, err := r.store.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var index indexObject
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse the index object %s: %w", key, err)
+	}
+	entries := make(indexEntries)
+	for _, file := range index.Files {
+		for _, id := range file.TraceIDs {
+			entries[id] = append(entries[id], file.Key)
+		}
+	}
+	r.indexes.Put(key, entries)
+	return entries, nil
+}
+
+// forEach calls fn for the n items with up to Options.ReadWorkers concurrent calls, and returns their errors.
+func (r *SpanReader) forEach(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	slots := make(chan struct{}, max(r.options.ReadWorkers, 1))
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// GetServices returns no services, the files are not searchable.
+func (*SpanReader) GetServices(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// GetOperations returns no operations, the files are not searchable.
+func (*SpanReader) GetOperations(context.Context, spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	return nil, nil
+}
+
+// FindTraces returns no traces, the files are not searchable.
+func (*SpanReader) FindTraces(context.Context, *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	return nil, nil
+}
+
+// FindTraceIDs returns no trace IDs, the files are not searchable.
+func (*SpanReader) FindTraceIDs(context.Context, *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	return nil, nil
+}
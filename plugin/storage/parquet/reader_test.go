@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// writeSpans writes the spans to the store, in a file per span.
+func writeSpans(t *testing.T, store objectstore.Store, spans ...*model.Span) {
+	opts := testOptions()
+	opts.MaxSpansPerFile = 1
+	w := newSpanWriter(store, opts, metricstest.NewFactory(0), zap.NewNop())
+	for _, span := range spans {
+		require.NoError(t, w.WriteSpan(context.Background(), span))
+	}
+	require.NoError(t, w.Close())
+}
+
+func testReaderOptions() Options {
+	opts := testOptions()
+	opts.IndexCacheSize = 100
+	opts.ReadWorkers = 2
+	return opts
+}
+
+func TestSpanReaderGetTrace(t *testing.T) {
+	store := &fakeObjectStore{}
+	writeSpans(t, store,
+		spanOfTrace(1, "frontend", testStartTime),
+		spanOfTrace(1, "billing", testStartTime.Add(24*time.Hour)),
+		spanOfTrace(0x11, "frontend", testStartTime),
+		spanOfTrace(2, "frontend", testStartTime))
+	r := newSpanReader(store, testReaderOptions())
+
+	trace, err := r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 2)
+	services := []string{trace.Spans[0].Process.ServiceName, trace.Spans[1].Process.ServiceName}
+	assert.ElementsMatch(t, []string{"frontend", "billing"}, services)
+	for _, span := range trace.Spans {
+		assert.Equal(t, model.NewTraceID(0, 1), span.TraceID)
+	}
+
+	// the index objects are cached, only the files are read again
+	store.gets = 0
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.gets)
+
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 3))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+// failingReadStore fails the reads of the keys with a suffix.
+type failingReadStore struct {
+	fakeObjectStore
+	listErr error
+	corrupt string
+}
+
+func (s *failingReadStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	return s.fakeObjectStore.ListObjects(ctx, prefix)
+}
+
+func (s *failingReadStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.fakeObjectStore.GetObject(ctx, key)
+	if s.corrupt != "" && strings.HasSuffix(key, s.corrupt) {
+		return []byte("corrupt"), err
+	}
+	return body, err
+}
+
+func TestSpanReaderGetTraceErrors(t *testing.T) {
+	store := &failingReadStore{listErr: errors.New("access denied")}
+	writeSpans(t, store, span("frontend", testStartTime))
+	r := newSpanReader(store, testReaderOptions())
+	_, err := r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.EqualError(t, err, "access denied")
+
+	store.listErr = nil
+	store.corrupt = ".json"
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorContains(t, err, "failed to parse the index object")
+
+	store.corrupt = ".parquet"
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorIs(t, err, errInvalidFile)
+
+	// a file missing from the store
+	store.corrupt = ""
+	for key := range store.files {
+		if strings.HasSuffix(key, ".parquet") {
+			delete(store.files, key)
+		}
+	}
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorContains(t, err, "no such key")
+}
+
+func TestSpanReaderSearches(t *testing.T) {
+	r := newSpanReader(&fakeObjectStore{}, testReaderOptions())
+	ctx := context.Background()
+	services, err := r.GetServices(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, services)
+	operations, err := r.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+	assert.Empty(t, operations)
+	traces, err := r.FindTraces(ctx, &spanstore.TraceQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+	traceIDs, err := r.FindTraceIDs(ctx, &spanstore.TraceQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+	assert.Empty(t, traceIDs)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+)
+
+const (
+	dateFormat        = "2006-01-02"
+	parquetObjectType = "application/vnd.apache.parquet"
+)
+
+// partition groups the spans written to the same files, by the UTC date of their start time and their service.
+type partition struct {
+	date    string
+	service string
+}
+
+// key returns a new key of a file of the partition, with Hive style partitions
+// understood by Athena, BigQuery external tables or Spark.
+func (p partition) key(prefix string, now time.Time) string {
+	return path.Join(
+		prefix,
+		"dt="+p.date,
+		"service="+url.PathEscape(p.service),
+		fmt.Sprintf("%d-%08x.parquet", now.UnixNano(), rand.Uint32()))
+}
+
+type spanWriterMetrics struct {
+	SpansWrittenSuccess metrics.Counter
+	SpansWrittenFailure metrics.Counter
+	FilesWritten        metrics.Counter
+	IndexFailures       metrics.Counter
+	// IndexCompactions counts the partitions of the index whose objects are merged.
+	IndexCompactionsSuccess metrics.Counter
+	IndexCompactionsFailure metrics.Counter
+}
+
+// SpanWriter buffers spans by date and service and uploads them as Parquet files to an object storage,
+// along with an index of their trace IDs, whose objects it periodically compacts. Implements spanstore.Writer
+type SpanWriter struct {
+	store   objectstore.Store
+	options Options
+	metrics spanWriterMetrics
+	logger  *zap.Logger
+	timeNow func() time.Time
+
+	lock       sync.Mutex
+	partitions map[partition][]*model.Span
+
+	closeOnce sync.Once
+	flushDone chan struct{}
+	flushWG   sync.WaitGroup
+}
+
+func newSpanWriter(store objectstore.Store, options Options, factory metrics.Factory, logger *zap.Logger) *SpanWriter {
+	writeMetrics := spanWriterMetrics{
+		SpansWrittenSuccess:     factory.Counter(metrics.Options{Name: "parquet_spans_written", Tags: map[string]string{"status": "success"}}),
+		SpansWrittenFailure:     factory.Counter(metrics.Options{Name: "parquet_spans_written", Tags: map[string]string{"status": "failure"}}),
+		FilesWritten:            factory.Counter(metrics.Options{Name: "parquet_files_written"}),
+		IndexFailures:           factory.Counter(metrics.Options{Name: "parquet_index_failures"}),
+		IndexCompactionsSuccess: factory.Counter(metrics.Options{Name: "parquet_index_compactions", Tags: map[string]string{"status": "success"}}),
+		IndexCompactionsFailure: factory.Counter(metrics.Options{Name: "parquet_index_compactions", Tags: map[string]string{"status": "failure"}}),
+	}
+	w := &SpanWriter{
+		store:      store,
+		options:    options,
+		metrics:    writeMetrics,
+		logger:     logger,
+		timeNow:    time.Now,
+		partitions: make(map[partition][]*model.Span),
+		flushDone:  make(chan struct{}),
+	}
+	w.flushWG.Add(1)
+	go w.flushPeriodically()
+	if options.IndexCompactionInterval > 0 {
+		w.flushWG.Add(1)
+		go w.compactPeriodically()
+	}
+	return w
+}
+
+// WriteSpan adds the span to its partition, which is uploaded as a file when it is full.
+func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
+	p := partition{
+		date:    span.StartTime.UTC().Format(dateFormat),
+		service: span.Process.GetServiceName(),
+	}
+	w.lock.Lock()
+	spans := append(w.partitions[p], span)
+	if len(spans) >= w.options.MaxSpansPerFile {
+		delete(w.partitions, p)
+	} else {
+		w.partitions[p] = spans
+		spans = nil
+	}
+	w.lock.Unlock()
+	if spans != nil {
+		return w.upload(map[partition][]*model.Span{p: spans})
+	}
+	return nil
+}
+
+// Close uploads the buffered spans.
+func (w *SpanWriter) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.flushDone)
+		w.flushWG.Wait()
+		err = w.flush()
+	})
+	return err
+}
+
+func (w *SpanWriter) flushPeriodically() {
+	defer w.flushWG.Done()
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.flushDone:
+			return
+		}
+	}
+}
+
+// flush uploads all the partitions, even if they are not full.
+func (w *SpanWriter) flush() error {
+	w.lock.Lock()
+	partitions := w.partitions
+	w.partitions = make(map[partition][]*model.Span)
+	w.lock.Unlock()
+	return w.upload(partitions)
+}
+
+// upload writes the spans of each partition to a new file, then the index of the trace IDs
+// of the files written. The spans of the files that fail to be written are dropped.
+func (w *SpanWriter) upload(partitions map[partition][]*model.Span) error {
+	now := w.timeNow()
+	var errs []error
+	files := make(map[string][]*model.Span, len(partitions))
+	for p, spans := range partitions {
+		key := p.key(w.options.KeyPrefix, now)
+		if err := w.uploadFile(key, spans); err != nil {
+			w.logger.Error("Failed to write spans to a Parquet file",
+				zap.String("date", p.date), zap.String("service", p.service), zap.Int("spans", len(spans)), zap.Error(err))
+			w.metrics.SpansWrittenFailure.Inc(int64(len(spans)))
+			errs = append(errs, err)
+			continue
+		}
+		w.metrics.SpansWrittenSuccess.Inc(int64(len(spans)))
+		w.metrics.FilesWritten.Inc(1)
+		files[key] = spans
+	}
+	date := now.UTC().Format(dateFormat)
+	for shard, index := range buildIndex(files) {
+		if err := w.uploadIndex(indexKey(w.options.KeyPrefix, shard, date, now), index); err != nil {
+			// the spans are still in the files, but their traces cannot be read with GetTrace
+			w.logger.Error("Failed to write the index of Parquet files", zap.Int("files", len(index.Files)), zap.Error(err))
+			w.metrics.IndexFailures.Inc(1)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *SpanWriter) uploadFile(key string, spans []*model.Span) error {
+	file, err := encodeFile(spans)
+	if err != nil {
+		return fmt.Errorf("failed to encode the Parquet file: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.options.UploadTimeout)
+	defer cancel()
+	return w.store.PutObject(ctx, key, file, parquetObjectType)
+}
+
+func (w *SpanWriter) uploadIndex(key string, index *indexObject) error {
+	body, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.options.UploadTimeout)
+	defer cancel()
+	return w.store.PutObject(ctx, key, body, indexContentType)
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/objectstore"
+)
+
+// fakeObjectStore keeps the objects in memory, failing the writes with err if set.
+type fakeObjectStore struct {
+	sync.Mutex
+	err   error
+	files map[string][]byte
+	gets  int
+}
+
+func (s *fakeObjectStore) PutObject(_ context.Context, key string, body []byte, contentType string) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if strings.HasSuffix(key, ".parquet") != (contentType == parquetObjectType) {
+		return errors.New("unexpected content type " + contentType)
+	}
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+	s.files[key] = body
+	return nil
+}
+
+func (s *fakeObjectStore) GetObject(_ context.Context, key string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.gets++
+	body, ok := s.files[key]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", objectstore.ErrObjectNotFound, key)
+	}
+	return body, nil
+}
+
+func (s *fakeObjectStore) DeleteObject(_ context.Context, key string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.files, key)
+	return nil
+}
+
+func (s *fakeObjectStore) ListObjects(_ context.Context, prefix string) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	var keys []string
+	for key := range s.files {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// written returns the directories of the Parquet files written with their number of rows.
+func (s *fakeObjectStore) written(t *testing.T) map[string][]int64 {
+	s.Lock()
+	defer s.Unlock()
+	written := make(map[string][]int64)
+	for key, body := range s.files {
+		if strings.Contains(key, indexDir) {
+			continue
+		}
+		require.Regexp(t, `/\d+-[0-9a-f]{8}\.parquet$`, key)
+		numRows, _ := readFile(t, body)
+		dir := path.Dir(key)
+		written[dir] = append(written[dir], numRows)
+		sort.Slice(written[dir], func(i, j int) bool { return written[dir][i] < written[dir][j] })
+	}
+	return written
+}
+
+// index returns the files holding each trace ID according to the index objects written.
+func (s *fakeObjectStore) index(t *testing.T) map[string][]string {
+	s.Lock()
+	defer s.Unlock()
+	index := make(map[string][]string)
+	for key, body := range s.files {
+		if !strings.Contains(key, indexDir) {
+			continue
+		}
+		require.Regexp(t, `^jaeger/_index/[0-9a-f]/dt=\d{4}-\d{2}-\d{2}/\d+-[0-9a-f]{8}\.json$`, key)
+		var object indexObject
+		require.NoError(t, json.Unmarshal(body, &object))
+		for _, file := range object.Files {
+			require.Contains(t, s.files, file.Key)
+			for _, id := range file.TraceIDs {
+				traceID, err := model.TraceIDFromString(id)
+				require.NoError(t, err)
+				assert.Equal(t, indexShard(traceID), strings.Split(key, "/")[2])
+				index[id] = append(index[id], path.Dir(file.Key))
+			}
+		}
+	}
+	for _, files := range index {
+		sort.Strings(files)
+	}
+	return index
+}
+
+func testOptions() Options {
+	return Options{
+		KeyPrefix:       "jaeger",
+		MaxSpansPerFile: 2,
+		FlushInterval:   time.Hour,
+		UploadTimeout:   time.Second,
+	}
+}
+
+func span(service string, startTime time.Time) *model.Span {
+	return spanOfTrace(1, service, startTime)
+}
+
+func spanOfTrace(traceID uint64, service string, startTime time.Time) *model.Span {
+	return &model.Span{
+		TraceID:   model.NewTraceID(0, traceID),
+		SpanID:    model.NewSpanID(1),
+		StartTime: startTime,
+		Process:   model.NewProcess(service, nil),
+	}
+}
+
+func TestSpanWriterPartitions(t *testing.T) {
+	store := &fakeObjectStore{}
+	metricsFactory := metricstest.NewFactory(0)
+	w := newSpanWriter(store, testOptions(), metricsFactory, zap.NewNop())
+	w.timeNow = func() time.Time { return time.Unix(1700000000, 0) }
+	ctx := context.Background()
+	require.NoError(t, w.WriteSpan(ctx, span("frontend", testStartTime)))
+	require.NoError(t, w.WriteSpan(ctx, span("frontend", testStartTime.Add(24*time.Hour))))
+	require.NoError(t, w.WriteSpan(ctx, spanOfTrace(0x12, "billing/v2", testStartTime)))
+	assert.Empty(t, store.written(t))
+
+	// the partition is written once full
+	require.NoError(t, w.WriteSpan(ctx, span("frontend", testStartTime.Add(time.Hour))))
+	assert.Equal(t, map[string][]int64{"jaeger/dt=2024-05-06/service=frontend": {2}}, store.written(t))
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, map[string][]int64{
+		"jaeger/dt=2024-05-06/service=frontend":     {2},
+		"jaeger/dt=2024-05-07/service=frontend":     {1},
+		"jaeger/dt=2024-05-06/service=billing%2Fv2": {1},
+	}, store.written(t))
+	assert.Equal(t, map[string][]string{
+		"0000000000000001": {"jaeger/dt=2024-05-06/service=frontend", "jaeger/dt=2024-05-07/service=frontend"},
+		"0000000000000012": {"jaeger/dt=2024-05-06/service=billing%2Fv2"},
+	}, store.index(t))
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "parquet_spans_written", Tags: map[string]string{"status": "success"}, Value: 4},
+		metricstest.ExpectedMetric{Name: "parquet_files_written", Value: 3})
+}
+
+func TestSpanWriterFlushInterval(t *testing.T) {
+	store := &fakeObjectStore{}
+	opts := testOptions()
+	opts.FlushInterval = time.Millisecond
+	w := newSpanWriter(store, opts, metricstest.NewFactory(0), zap.NewNop())
+	defer w.Close()
+	require.NoError(t, w.WriteSpan(context.Background(), span("frontend", testStartTime)))
+	assert.Eventually(t, func() bool {
+		return len(store.written(t)) == 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestSpanWriterUploadError(t *testing.T) {
+	store := &fakeObjectStore{err: errors.New("access denied")}
+	metricsFactory := metricstest.NewFactory(0)
+	w := newSpanWriter(store, testOptions(), metricsFactory, zap.NewNop())
+	ctx := context.Background()
+	require.NoError(t, w.WriteSpan(ctx, span("frontend", testStartTime)))
+	require.EqualError(t, w.WriteSpan(ctx, span("frontend", testStartTime)), "access denied")
+	require.NoError(t, w.WriteSpan(ctx, span("frontend", testStartTime)))
+	require.EqualError(t, w.Close(), "access denied")
+	assert.Empty(t, store.files)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "parquet_spans_written", Tags: map[string]string{"status": "failure"}, Value: 3})
+}
+
+// indexFailingStore fails the writes of the index objects.
+type indexFailingStore struct {
+	fakeObjectStore
+}
+
+func (s *indexFailingStore) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	if contentType == indexContentType {
+		return errors.New("slow down")
+	}
+	return s.fakeObjectStore.PutObject(ctx, key, body, contentType)
+}
+
+func TestSpanWriterIndexError(t *testing.T) {
+	store := &indexFailingStore{}
+	metricsFactory := metricstest.NewFactory(0)
+	w := newSpanWriter(store, testOptions(), metricsFactory, zap.NewNop())
+	require.NoError(t, w.WriteSpan(context.Background(), span("frontend", testStartTime)))
+	require.EqualError(t, w.Close(), "slow down")
+	assert.Len(t, store.written(t), 1)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "parquet_spans_written", Tags: map[string]string{"status": "success"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "parquet_index_failures", Value: 1})
+}
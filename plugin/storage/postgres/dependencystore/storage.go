@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	insertDependencies = `
+		INSERT INTO dependency_links (ts, parent, child, call_count, source)
+		SELECT $1::TIMESTAMPTZ, *
+		FROM unnest($2::TEXT[], $3::TEXT[], $4::BIGINT[], $5::TEXT[])`
+	// the links written by several runs of the dependencies job over the lookback are summed up
+	queryDependencies = `
+		SELECT parent, child, SUM(call_count)::BIGINT, source FROM dependency_links
+		WHERE ts >= $1 AND ts <= $2
+		GROUP BY parent, child, source
+		ORDER BY parent, child, source`
+)
+
+// DB is the subset of the pgx connection pool used by the DependencyStore.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// DependencyStore stores the dependency links in PostgreSQL.
+type DependencyStore struct {
+	db DB
+}
+
+// NewDependencyStore creates a DependencyStore.
+func NewDependencyStore(db DB) *DependencyStore {
+	return &DependencyStore{db: db}
+}
+
+// WriteDependencies implements dependencystore.Writer#WriteDependencies.
+func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.DependencyLink) error {
+	if len(dependencies) == 0 {
+		return nil
+	}
+	parents := make([]string, len(dependencies))
+	children := make([]string, len(dependencies))
+	callCounts := make([]int64, len(dependencies))
+	sources := make([]string, len(dependencies))
+	for i, d := range dependencies {
+		d = d.ApplyDefaults()
+		parents[i] = d.Parent
+		children[i] = d.Child
+		callCounts[i] = int64(d.CallCount)
+		sources[i] = d.Source
+	}
+	_, err := s.db.Exec(context.Background(), insertDependencies, ts.UTC(), parents, children, callCounts, sources)
+	if err != nil {
+		return fmt.Errorf("failed to write the dependencies: %w", err)
+	}
+	return nil
+}
+
+// GetDependencies returns all interservice dependencies
+func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	rows, err := s.db.Query(ctx, queryDependencies, endTs.Add(-lookback).UTC(), endTs.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
+	}
+	dependencies, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.DependencyLink, error) {
+		var d model.DependencyLink
+		var callCount int64
+		if err := row.Scan(&d.Parent, &d.Child, &callCount, &d.Source); err != nil {
+			return d, err
+		}
+		d.CallCount = uint64(callCount)
+		return d.ApplyDefaults(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
+	}
+	return dependencies, nil
+}
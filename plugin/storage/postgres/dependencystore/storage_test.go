@@ -0,0 +1,106 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	row    int
+}
+
+func (r *fakeRows) Next() bool {
+	if r.row >= len(r.values) {
+		return false
+	}
+	r.row++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, v := range r.values[r.row-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (*fakeRows) Close() {}
+
+func (*fakeRows) Err() error {
+	return nil
+}
+
+type fakeDB struct {
+	args []any
+	rows *fakeRows
+	err  error
+}
+
+func (db *fakeDB) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	db.args = args
+	return pgconn.CommandTag{}, db.err
+}
+
+func (db *fakeDB) Query(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+	db.args = args
+	return db.rows, db.err
+}
+
+func TestWriteDependencies(t *testing.T) {
+	db := &fakeDB{}
+	store := NewDependencyStore(db)
+	ts := time.Unix(1700000000, 0)
+	require.NoError(t, store.WriteDependencies(ts, []model.DependencyLink{
+		{Parent: "hello", Child: "world", CallCount: 1},
+		{Parent: "world", Child: "hello", CallCount: 3, Source: "other"},
+	}))
+	assert.Equal(t, []any{
+		ts.UTC(),
+		[]string{"hello", "world"},
+		[]string{"world", "hello"},
+		[]int64{1, 3},
+		[]string{model.JaegerDependencyLinkSource, "other"},
+	}, db.args)
+
+	db = &fakeDB{}
+	require.NoError(t, NewDependencyStore(db).WriteDependencies(ts, nil))
+	assert.Nil(t, db.args)
+
+	db = &fakeDB{err: errors.New("db error")}
+	err := NewDependencyStore(db).WriteDependencies(ts, []model.DependencyLink{{Parent: "hello", Child: "world"}})
+	require.EqualError(t, err, "failed to write the dependencies: db error")
+}
+
+func TestGetDependencies(t *testing.T) {
+	db := &fakeDB{rows: &fakeRows{values: [][]any{
+		{"hello", "world", int64(1), ""},
+		{"world", "hello", int64(3), "other"},
+	}}}
+	endTs := time.Unix(1700000000, 0)
+	dependencies, err := NewDependencyStore(db).GetDependencies(context.Background(), endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{
+		{Parent: "hello", Child: "world", CallCount: 1, Source: model.JaegerDependencyLinkSource},
+		{Parent: "world", Child: "hello", CallCount: 3, Source: "other"},
+	}, dependencies)
+	assert.Equal(t, []any{endTs.Add(-time.Hour).UTC(), endTs.UTC()}, db.args)
+
+	db = &fakeDB{err: errors.New("db error")}
+	_, err = NewDependencyStore(db).GetDependencies(context.Background(), endTs, time.Hour)
+	require.EqualError(t, err, "error reading dependencies from storage: db error")
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	depStore "github.com/jaegertracing/jaeger/plugin/storage/postgres/dependencystore"
+	"github.com/jaegertracing/jaeger/plugin/storage/postgres/schema"
+	pgSpanstore "github.com/jaegertracing/jaeger/plugin/storage/postgres/spanstore"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory       = (*Factory)(nil)
+	_ storage.Purger        = (*Factory)(nil)
+	_ storage.HealthChecker = (*Factory)(nil)
+	_ io.Closer             = (*Factory)(nil)
+	_ plugin.Configurable   = (*Factory)(nil)
+)
+
+// truncateTables removes all the data of the storage, but not the applied migrations.
+const truncateTables = `TRUNCATE spans, span_tags, operations, services, dependency_links`
+
+// Factory implements storage.Factory for PostgreSQL, optionally with TimescaleDB hypertables.
+type Factory struct {
+	options Options
+
+	metricsFactory metrics.Factory
+	logger         *zap.Logger
+
+	pool *pgxpool.Pool
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, _ *zap.Logger) {
+	f.options.InitFromViper(v)
+}
+
+// configureFromOptions initializes factory from options.
+func (f *Factory) configureFromOptions(o Options) {
+	f.options = o
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.metricsFactory, f.logger = metricsFactory, logger
+
+	if f.options.MaxConnections <= 0 {
+		return errors.New("the maximum number of connections of the postgres storage must be positive")
+	}
+	if f.options.TimescaleDB && f.options.ChunkInterval <= 0 {
+		return errors.New("the chunk interval of the TimescaleDB hypertables must be positive")
+	}
+	config, err := pgxpool.ParseConfig(f.options.ConnectionString)
+	if err != nil {
+		return fmt.Errorf("invalid postgres connection string: %w", err)
+	}
+	config.MaxConns = int32(f.options.MaxConnections)
+	logger.Info("PostgreSQL factory",
+		zap.String("host", config.ConnConfig.Host),
+		zap.String("database", config.ConnConfig.Database),
+		zap.Bool("timescaledb", f.options.TimescaleDB))
+
+	ctx := context.Background()
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	if f.options.CreateSchema {
+		if err := f.createSchema(ctx, pool); err != nil {
+			pool.Close()
+			return err
+		}
+	}
+	f.pool = pool
+	return nil
+}
+
+func (f *Factory) createSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	if err := schema.Migrate(ctx, pool); err != nil {
+		return err
+	}
+	if f.options.TimescaleDB {
+		return schema.CreateHypertables(ctx, pool, f.options.ChunkInterval)
+	}
+	return nil
+}
+
+// CreateSpanReader implements storage.Factory
+func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return pgSpanstore.NewSpanReader(f.pool), nil
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	return pgSpanstore.NewSpanWriter(f.pool, f.logger), nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return depStore.NewDependencyStore(f.pool), nil
+}
+
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{
+		TraceSearch:       true,
+		TagSearch:         true,
+		OperationSpanKind: true,
+		Dependencies:      true,
+	}
+}
+
+// Purge removes all the spans and dependencies, only meant to be used by the integration tests.
+func (f *Factory) Purge(ctx context.Context) error {
+	_, err := f.pool.Exec(ctx, truncateTables)
+	return err
+}
+
+// CheckHealth implements storage.HealthChecker
+func (f *Factory) CheckHealth(ctx context.Context) error {
+	return f.pool.Ping(ctx)
+}
+
+// Close closes the connections to the database.
+func (f *Factory) Close() error {
+	if f.pool != nil {
+		f.pool.Close()
+	}
+	return nil
+}
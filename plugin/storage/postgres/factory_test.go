@@ -0,0 +1,83 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// unreachableDB is the connection string of a database nothing listens to,
+// the pool connects lazily so that the factory initializes without the schema creation.
+const unreachableDB = "postgres://jaeger@127.0.0.1:1/jaeger?connect_timeout=1"
+
+func TestPostgresFactory(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--postgres.connection-string=" + unreachableDB,
+		"--postgres.create-schema=false",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+	_, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	_, err = f.CreateSpanWriter()
+	require.NoError(t, err)
+	_, err = f.CreateDependencyReader()
+	require.NoError(t, err)
+	assert.True(t, f.Capabilities().TagSearch)
+
+	require.Error(t, f.CheckHealth(context.Background()))
+	require.Error(t, f.Purge(context.Background()))
+	require.NoError(t, f.Close())
+}
+
+func TestPostgresFactoryCreateSchemaError(t *testing.T) {
+	f := NewFactory()
+	f.configureFromOptions(Options{ConnectionString: unreachableDB, MaxConnections: 1, CreateSchema: true})
+	err := f.Initialize(metrics.NullFactory, zap.NewNop())
+	require.ErrorContains(t, err, "failed to create the schema migrations table")
+	assert.Nil(t, f.pool)
+	require.NoError(t, f.Close())
+}
+
+func TestPostgresFactoryInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:     "no connections",
+			options:  Options{ConnectionString: unreachableDB},
+			expected: "the maximum number of connections of the postgres storage must be positive",
+		},
+		{
+			name:     "no chunk interval",
+			options:  Options{ConnectionString: unreachableDB, MaxConnections: 1, TimescaleDB: true},
+			expected: "the chunk interval of the TimescaleDB hypertables must be positive",
+		},
+		{
+			name:     "invalid connection string",
+			options:  Options{ConnectionString: "postgres://:invalid", MaxConnections: 1},
+			expected: "invalid postgres connection string",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFactory()
+			f.configureFromOptions(tc.options)
+			require.ErrorContains(t, f.Initialize(metrics.NullFactory, zap.NewNop()), tc.expected)
+		})
+	}
+}
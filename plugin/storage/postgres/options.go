@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	configPrefix           = "postgres"
+	suffixConnectionString = ".connection-string"
+	suffixMaxConnections   = ".max-connections"
+	suffixCreateSchema     = ".create-schema"
+	suffixTimescaleDB      = ".timescaledb.enabled"
+	suffixChunkInterval    = ".timescaledb.chunk-interval"
+
+	defaultConnectionString = "postgres://postgres@localhost:5432/jaeger?sslmode=disable"
+	defaultMaxConnections   = 10
+	defaultChunkInterval    = 24 * time.Hour
+)
+
+// Options stores the configuration of the PostgreSQL storage
+type Options struct {
+	// ConnectionString is a libpq URL or key=value connection string.
+	ConnectionString string `mapstructure:"connection_string"`
+	MaxConnections   int    `mapstructure:"max_connections"`
+	// CreateSchema applies the migrations of the schema on start up.
+	CreateSchema bool `mapstructure:"create_schema"`
+	// TimescaleDB turns the tables of the spans into hypertables partitioned by start time.
+	TimescaleDB   bool          `mapstructure:"timescaledb"`
+	ChunkInterval time.Duration `mapstructure:"chunk_interval"`
+}
+
+// AddFlags adds flags for Options
+func (*Options) AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		configPrefix+suffixConnectionString,
+		defaultConnectionString,
+		"The connection string of the PostgreSQL database, as a URL or key=value pairs, the password may be set by the PGPASSWORD environment variable")
+	flagSet.Int(
+		configPrefix+suffixMaxConnections,
+		defaultMaxConnections,
+		"The maximum number of connections to the PostgreSQL database")
+	flagSet.Bool(
+		configPrefix+suffixCreateSchema,
+		true,
+		"Whether to create the tables of the PostgreSQL storage and apply their migrations on start up")
+	flagSet.Bool(
+		configPrefix+suffixTimescaleDB,
+		false,
+		"Whether to partition the tables of the spans into TimescaleDB hypertables by start time, requires the timescaledb extension")
+	flagSet.Duration(
+		configPrefix+suffixChunkInterval,
+		defaultChunkInterval,
+		"The time interval of the chunks of the TimescaleDB hypertables, only applies when they are created")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (o *Options) InitFromViper(v *viper.Viper) {
+	o.ConnectionString = v.GetString(configPrefix + suffixConnectionString)
+	o.MaxConnections = v.GetInt(configPrefix + suffixMaxConnections)
+	o.CreateSchema = v.GetBool(configPrefix + suffixCreateSchema)
+	o.TimescaleDB = v.GetBool(configPrefix + suffixTimescaleDB)
+	o.ChunkInterval = v.GetDuration(configPrefix + suffixChunkInterval)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	err := command.ParseFlags([]string{
+		"--postgres.connection-string=postgres://jaeger@db:5432/traces",
+		"--postgres.max-connections=20",
+		"--postgres.create-schema=false",
+		"--postgres.timescaledb.enabled=true",
+		"--postgres.timescaledb.chunk-interval=6h",
+	})
+	require.NoError(t, err)
+	opts.InitFromViper(v)
+
+	assert.Equal(t, Options{
+		ConnectionString: "postgres://jaeger@db:5432/traces",
+		MaxConnections:   20,
+		TimescaleDB:      true,
+		ChunkInterval:    6 * time.Hour,
+	}, *opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, _ := config.Viperize(opts.AddFlags)
+	opts.InitFromViper(v)
+
+	assert.Equal(t, Options{
+		ConnectionString: defaultConnectionString,
+		MaxConnections:   defaultMaxConnections,
+		CreateSchema:     true,
+		ChunkInterval:    defaultChunkInterval,
+	}, *opts)
+}
@@ -0,0 +1,57 @@
+-- Copyright (c) 2024 The Jaeger Authors.
+-- SPDX-License-Identifier: Apache-2.0
+
+CREATE TABLE IF NOT EXISTS services (
+    id   SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS operations (
+    id         SERIAL PRIMARY KEY,
+    service_id INTEGER NOT NULL REFERENCES services (id),
+    name       TEXT NOT NULL,
+    span_kind  TEXT NOT NULL,
+    UNIQUE (service_id, name, span_kind)
+);
+
+-- spans holds a row per span, with the columns searched by the trace queries
+-- and the protobuf encoded span returned by the reads.
+CREATE TABLE IF NOT EXISTS spans (
+    trace_id     BYTEA NOT NULL,
+    span_id      BIGINT NOT NULL,
+    service_id   INTEGER NOT NULL REFERENCES services (id),
+    operation_id INTEGER NOT NULL REFERENCES operations (id),
+    start_time   TIMESTAMPTZ NOT NULL,
+    -- in microseconds
+    duration     BIGINT NOT NULL,
+    span         BYTEA NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS spans_trace_id_idx ON spans (trace_id);
+CREATE INDEX IF NOT EXISTS spans_service_id_start_time_idx ON spans (service_id, start_time DESC);
+CREATE INDEX IF NOT EXISTS spans_operation_id_start_time_idx ON spans (operation_id, start_time DESC);
+
+-- span_tags holds a row per tag of a span, of the span itself (scope 0), of its process (scope 1)
+-- or of one of its logs (scope 2), identified by log_index.
+CREATE TABLE IF NOT EXISTS span_tags (
+    trace_id   BYTEA NOT NULL,
+    span_id    BIGINT NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    scope      SMALLINT NOT NULL,
+    log_index  INTEGER NOT NULL,
+    key        TEXT NOT NULL,
+    value      TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS span_tags_span_idx ON span_tags (trace_id, span_id, start_time);
+CREATE INDEX IF NOT EXISTS span_tags_key_value_idx ON span_tags (key, value, start_time DESC);
+
+CREATE TABLE IF NOT EXISTS dependency_links (
+    ts         TIMESTAMPTZ NOT NULL,
+    parent     TEXT NOT NULL,
+    child      TEXT NOT NULL,
+    call_count BIGINT NOT NULL,
+    source     TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS dependency_links_ts_idx ON dependency_links (ts DESC);
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	createMigrationsTable = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`
+	// lockMigrations serializes the migrations of the collectors and query services starting concurrently,
+	// the lock is released at the end of the transaction.
+	lockMigrations   = `SELECT pg_advisory_xact_lock(7460984517302813440)`
+	isApplied        = `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`
+	insertMigration  = `INSERT INTO schema_migrations (version) VALUES ($1)`
+	createExtension  = `CREATE EXTENSION IF NOT EXISTS timescaledb`
+	createHypertable = `SELECT create_hypertable($1, 'start_time', chunk_time_interval => $2::interval, if_not_exists => TRUE, migrate_data => TRUE)`
+)
+
+// hypertables are the tables partitioned by the start time of the spans when TimescaleDB is enabled.
+var hypertables = []string{"spans", "span_tags"}
+
+// DB is the subset of the pgx connection pool used to migrate the schema.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Migration is a versioned change of the schema.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations returns the migrations of the schema ordered by version, read from the
+// migrations/<version>_<name>.sql files.
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		version, name, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		v, err := strconv.Atoi(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of the migration file %s: %w", entry.Name(), err)
+		}
+		sql, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: v, Name: name, SQL: string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies the migrations not applied yet, each in its own transaction.
+func Migrate(ctx context.Context, db DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create the schema migrations table: %w", err)
+	}
+	for _, m := range migrations {
+		if err := apply(ctx, db, m); err != nil {
+			return fmt.Errorf("failed to apply the schema migration %d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db DB, m Migration) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// a no-op once the transaction is committed
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, lockMigrations); err != nil {
+		return err
+	}
+	var applied bool
+	if err := tx.QueryRow(ctx, isApplied, m.Version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertMigration, m.Version); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CreateHypertables converts the span tables to TimescaleDB hypertables partitioned by the start time
+// of the spans in chunks of the given interval. It is a no-op for the tables already converted.
+func CreateHypertables(ctx context.Context, db DB, chunkInterval time.Duration) error {
+	if _, err := db.Exec(ctx, createExtension); err != nil {
+		return fmt.Errorf("failed to create the timescaledb extension: %w", err)
+	}
+	interval := fmt.Sprintf("%d microseconds", chunkInterval.Microseconds())
+	for _, table := range hypertables {
+		if _, err := db.Exec(ctx, createHypertable, table, interval); err != nil {
+			return fmt.Errorf("failed to create the hypertable %s: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB records the statements executed, the applied versions are the ones of the schema_migrations table.
+type fakeDB struct {
+	applied    map[int]bool
+	statements []string
+	failOn     string
+	committed  []int
+}
+
+type fakeTx struct {
+	pgx.Tx
+	db      *fakeDB
+	version int
+}
+
+type fakeRow struct {
+	applied bool
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = r.applied
+	return nil
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	db.statements = append(db.statements, sql)
+	if db.failOn != "" && strings.Contains(sql, db.failOn) {
+		return pgconn.CommandTag{}, errors.New("exec error")
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{db: db}, nil
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if sql == insertMigration {
+		tx.version = args[0].(int)
+	}
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	return fakeRow{applied: tx.db.applied[args[0].(int)]}
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.db.committed = append(tx.db.committed, tx.version)
+	return nil
+}
+
+func (*fakeTx) Rollback(context.Context) error {
+	return nil
+}
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create_tables", migrations[0].Name)
+	assert.Contains(t, migrations[0].SQL, "CREATE TABLE IF NOT EXISTS spans")
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db := &fakeDB{}
+	require.NoError(t, Migrate(context.Background(), db))
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	assert.Equal(t, createMigrationsTable, db.statements[0])
+	assert.Equal(t, lockMigrations, db.statements[1])
+	assert.Equal(t, migrations[0].SQL, db.statements[2])
+	assert.Equal(t, []int{1}, db.committed[:1])
+	assert.Len(t, db.committed, len(migrations))
+}
+
+func TestMigrateApplied(t *testing.T) {
+	db := &fakeDB{applied: map[int]bool{1: true}}
+	require.NoError(t, Migrate(context.Background(), db))
+	assert.NotContains(t, db.statements, insertMigration)
+	assert.Empty(t, db.committed)
+}
+
+func TestMigrateErrors(t *testing.T) {
+	db := &fakeDB{failOn: "schema_migrations ("}
+	require.ErrorContains(t, Migrate(context.Background(), db), "failed to create the schema migrations table")
+
+	db = &fakeDB{failOn: "CREATE TABLE IF NOT EXISTS spans"}
+	require.ErrorContains(t, Migrate(context.Background(), db), "failed to apply the schema migration 1_create_tables: exec error")
+	assert.Empty(t, db.committed)
+}
+
+func TestCreateHypertables(t *testing.T) {
+	db := &fakeDB{}
+	require.NoError(t, CreateHypertables(context.Background(), db, time.Hour))
+	assert.Equal(t, []string{createExtension, createHypertable, createHypertable}, db.statements)
+
+	db = &fakeDB{failOn: "timescaledb"}
+	require.ErrorContains(t, CreateHypertables(context.Background(), db, time.Hour), "failed to create the timescaledb extension")
+
+	db = &fakeDB{failOn: "create_hypertable"}
+	require.ErrorContains(t, CreateHypertables(context.Background(), db, time.Hour), "failed to create the hypertable spans")
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// The scopes of the rows of span_tags.
+const (
+	scopeSpan    int16 = 0
+	scopeProcess int16 = 1
+	scopeLog     int16 = 2
+)
+
+// DB is the subset of the pgx connection pool used by the span reader and writer.
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// traceIDBytes returns the value of the trace_id columns, the 16 bytes of the trace ID in big endian order.
+func traceIDBytes(traceID model.TraceID) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, traceID.High)
+	binary.BigEndian.PutUint64(b[8:], traceID.Low)
+	return b
+}
+
+// spanTags are the columns of the rows of span_tags of a span.
+type spanTags struct {
+	scopes     []int16
+	logIndexes []int32
+	keys       []string
+	values     []string
+}
+
+func (t *spanTags) add(scope int16, logIndex int, tags []model.KeyValue) {
+	for i := range tags {
+		t.scopes = append(t.scopes, scope)
+		t.logIndexes = append(t.logIndexes, int32(logIndex))
+		t.keys = append(t.keys, tags[i].Key)
+		t.values = append(t.values, tags[i].AsString())
+	}
+}
+
+// tagsOf returns the tags of the span, its process and its logs, searchable by the trace queries.
+func tagsOf(span *model.Span) *spanTags {
+	t := &spanTags{}
+	t.add(scopeSpan, 0, span.Tags)
+	if span.Process != nil {
+		t.add(scopeProcess, 0, span.Process.Tags)
+	}
+	for i := range span.Logs {
+		t.add(scopeLog, i, span.Logs[i].Fields)
+	}
+	return t
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// queryBuilder builds the SQL query of the trace IDs matching the parameters of a trace query.
+// Like the other span storages, a trace matches if one of its spans matches all the conditions.
+type queryBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// arg adds an argument to the query and returns its placeholder.
+func (b *queryBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) where(format string, args ...any) {
+	placeholders := make([]any, len(args))
+	for i, arg := range args {
+		placeholders[i] = b.arg(arg)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(format, placeholders...))
+}
+
+// tag adds the condition of a span having a tag of the scopes.
+func (b *queryBuilder) tag(key, value string, scopes []int16) {
+	b.where(`EXISTS (SELECT 1 FROM span_tags t
+		WHERE t.trace_id = s.trace_id AND t.span_id = s.span_id AND t.start_time = s.start_time
+		AND t.key = %s AND t.value = %s AND t.scope = ANY(%s))`, key, value, scopes)
+}
+
+// logFields adds the condition of a span having a log holding all the fields.
+func (b *queryBuilder) logFields(fields map[string]string) {
+	keys := sortedKeys(fields)
+	scope := b.arg(scopeLog)
+	matches := make([]string, len(keys))
+	for i, key := range keys {
+		matches[i] = fmt.Sprintf("(t.key = %s AND t.value = %s)", b.arg(key), b.arg(fields[key]))
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM span_tags t
+		WHERE t.trace_id = s.trace_id AND t.span_id = s.span_id AND t.start_time = s.start_time
+		AND t.scope = %s AND (%s)
+		GROUP BY t.log_index HAVING COUNT(DISTINCT t.key) = %s)`,
+		scope, strings.Join(matches, " OR "), b.arg(len(keys))))
+}
+
+// buildFindTraceIDsQuery returns the query of the trace IDs matching the parameters and the start time of
+// their most recent matching span, the most recent first, after the cursor if any and limited to limit rows.
+func buildFindTraceIDsQuery(query *spanstore.TraceQueryParameters, cursor *spanstore.TraceCursor, limit int) (string, []any) {
+	b := &queryBuilder{}
+	if query.ServiceName != "" {
+		b.where("s.service_id = (SELECT id FROM services WHERE name = %s)", query.ServiceName)
+	}
+	if query.OperationName != "" {
+		b.where("s.operation_id IN (SELECT o.id FROM operations o JOIN services sv ON sv.id = o.service_id WHERE sv.name = %s AND o.name = %s)",
+			query.ServiceName, query.OperationName)
+	}
+	b.where("s.start_time >= %s AND s.start_time <= %s", query.StartTimeMin.UTC(), query.StartTimeMax.UTC())
+	if query.DurationMin != 0 {
+		b.where("s.duration >= %s", model.DurationAsMicroseconds(query.DurationMin))
+	}
+	if query.DurationMax != 0 {
+		b.where("s.duration <= %s", model.DurationAsMicroseconds(query.DurationMax))
+	}
+	// the tags of the query also match the process tags and the log fields
+	for _, key := range sortedKeys(query.Tags) {
+		b.tag(key, query.Tags[key], []int16{scopeSpan, scopeProcess, scopeLog})
+	}
+	for _, key := range sortedKeys(query.ResourceAttributes) {
+		b.tag(key, query.ResourceAttributes[key], []int16{scopeProcess})
+	}
+	if len(query.LogFields) > 0 {
+		b.logFields(query.LogFields)
+	}
+	var having string
+	if cursor != nil {
+		startTime := b.arg(cursor.StartTime.UTC())
+		having = fmt.Sprintf("HAVING MAX(s.start_time) < %[1]s OR (MAX(s.start_time) = %[1]s AND s.trace_id > %[2]s)",
+			startTime, b.arg(traceIDBytes(cursor.TraceID)))
+	}
+	sql := fmt.Sprintf(`SELECT s.trace_id, MAX(s.start_time) FROM spans s
+		WHERE %s
+		GROUP BY s.trace_id
+		%s
+		ORDER BY MAX(s.start_time) DESC, s.trace_id
+		LIMIT %s`, strings.Join(b.conditions, " AND "), having, b.arg(limit))
+	return sql, b.args
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// normalize collapses the white space of a query.
+func normalize(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+func TestBuildFindTraceIDsQuery(t *testing.T) {
+	start, end := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	sql, args := buildFindTraceIDsQuery(&spanstore.TraceQueryParameters{
+		StartTimeMin: start,
+		StartTimeMax: end,
+	}, nil, 21)
+	assert.Equal(t, "SELECT s.trace_id, MAX(s.start_time) FROM spans s WHERE s.start_time >= $1 AND s.start_time <= $2 "+
+		"GROUP BY s.trace_id ORDER BY MAX(s.start_time) DESC, s.trace_id LIMIT $3", normalize(sql))
+	assert.Equal(t, []any{start.UTC(), end.UTC(), 21}, args)
+}
+
+func TestBuildFindTraceIDsQueryAfterCursor(t *testing.T) {
+	start, end := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	cursor := &spanstore.TraceCursor{StartTime: time.Unix(1700001800, 0), TraceID: model.NewTraceID(1, 2)}
+	sql, args := buildFindTraceIDsQuery(&spanstore.TraceQueryParameters{
+		StartTimeMin: start,
+		StartTimeMax: end,
+	}, cursor, 21)
+	assert.Equal(t, "SELECT s.trace_id, MAX(s.start_time) FROM spans s WHERE s.start_time >= $1 AND s.start_time <= $2 "+
+		"GROUP BY s.trace_id HAVING MAX(s.start_time) < $3 OR (MAX(s.start_time) = $3 AND s.trace_id > $4) "+
+		"ORDER BY MAX(s.start_time) DESC, s.trace_id LIMIT $5", normalize(sql))
+	assert.Equal(t, []any{start.UTC(), end.UTC(), cursor.StartTime.UTC(), traceIDBytes(cursor.TraceID), 21}, args)
+}
+
+func TestBuildFindTraceIDsQueryConditions(t *testing.T) {
+	start, end := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	sql, args := buildFindTraceIDsQuery(&spanstore.TraceQueryParameters{
+		ServiceName:        "frontend",
+		OperationName:      "GET /",
+		StartTimeMin:       start,
+		StartTimeMax:       end,
+		DurationMin:        time.Millisecond,
+		DurationMax:        time.Second,
+		Tags:               map[string]string{"http.status_code": "500", "error": "true"},
+		ResourceAttributes: map[string]string{"hostname": "host1"},
+		LogFields:          map[string]string{"event": "retry", "attempt": "2"},
+	}, nil, 21)
+	sql = normalize(sql)
+	assert.Contains(t, sql, "s.service_id = (SELECT id FROM services WHERE name = $1)")
+	assert.Contains(t, sql, "sv.name = $2 AND o.name = $3")
+	assert.Contains(t, sql, "s.duration >= $6")
+	assert.Contains(t, sql, "s.duration <= $7")
+	// the tags in the order of their keys
+	assert.Contains(t, sql, "t.key = $8 AND t.value = $9 AND t.scope = ANY($10)")
+	assert.Contains(t, sql, "t.key = $11 AND t.value = $12 AND t.scope = ANY($13)")
+	assert.Contains(t, sql, "t.key = $14 AND t.value = $15 AND t.scope = ANY($16)")
+	assert.Contains(t, sql, "t.scope = $17 AND ((t.key = $18 AND t.value = $19) OR (t.key = $20 AND t.value = $21)) "+
+		"GROUP BY t.log_index HAVING COUNT(DISTINCT t.key) = $22")
+	assert.Contains(t, sql, "LIMIT $23")
+	assert.Equal(t, []any{
+		"frontend",
+		"frontend", "GET /",
+		start.UTC(), end.UTC(),
+		uint64(1000), uint64(1000000),
+		"error", "true", []int16{scopeSpan, scopeProcess, scopeLog},
+		"http.status_code", "500", []int16{scopeSpan, scopeProcess, scopeLog},
+		"hostname", "host1", []int16{scopeProcess},
+		scopeLog, "attempt", "2", "event", "retry", 2,
+		21,
+	}, args)
+}
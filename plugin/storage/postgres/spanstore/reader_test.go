@@ -0,0 +1,201 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func spanRows(t *testing.T, spans ...*model.Span) *fakeRows {
+	rows := &fakeRows{}
+	for _, span := range spans {
+		payload, err := span.Marshal()
+		require.NoError(t, err)
+		rows.values = append(rows.values, []any{payload})
+	}
+	return rows
+}
+
+// traceIDRows returns the rows of the trace IDs, their most recent spans starting a second apart.
+func traceIDRows(traceIDs ...model.TraceID) *fakeRows {
+	rows := &fakeRows{}
+	for i, traceID := range traceIDs {
+		rows.values = append(rows.values, []any{traceIDBytes(traceID), time.Unix(1700003600-int64(i), 0).UTC()})
+	}
+	return rows
+}
+
+func testQuery() *spanstore.TraceQueryParameters {
+	return &spanstore.TraceQueryParameters{
+		ServiceName:  "frontend",
+		StartTimeMin: time.Unix(1700000000, 0),
+		StartTimeMax: time.Unix(1700003600, 0),
+		NumTraces:    2,
+	}
+}
+
+func TestGetTrace(t *testing.T) {
+	span := testSpan()
+	db := &fakeDB{results: []*fakeRows{spanRows(t, span)}}
+	trace, err := NewSpanReader(db).GetTrace(context.Background(), span.TraceID)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 1)
+	assert.Equal(t, span.OperationName, trace.Spans[0].OperationName)
+	assert.Equal(t, []any{traceIDBytes(span.TraceID)}, db.queries[0].args)
+
+	_, err = NewSpanReader(&fakeDB{}).GetTrace(context.Background(), span.TraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+	db = &fakeDB{results: []*fakeRows{{values: [][]any{{[]byte("invalid")}}}}}
+	_, err = NewSpanReader(db).GetTrace(context.Background(), span.TraceID)
+	require.ErrorContains(t, err, "failed to unmarshal a span")
+
+	_, err = NewSpanReader(&fakeDB{err: errors.New("db error")}).GetTrace(context.Background(), span.TraceID)
+	require.EqualError(t, err, "db error")
+}
+
+func TestGetServices(t *testing.T) {
+	db := &fakeDB{results: []*fakeRows{{values: [][]any{{"backend"}, {"frontend"}}}}}
+	services, err := NewSpanReader(db).GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "frontend"}, services)
+}
+
+func TestGetOperations(t *testing.T) {
+	db := &fakeDB{results: []*fakeRows{{values: [][]any{{"GET /", "server"}, {"query", "client"}}}}}
+	operations, err := NewSpanReader(db).GetOperations(context.Background(), spanstore.OperationQueryParameters{
+		ServiceName: "frontend",
+		NamePrefix:  "G",
+		Offset:      1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "GET /", SpanKind: "server"}, {Name: "query", SpanKind: "client"}}, operations)
+	// no limit
+	assert.Equal(t, []any{"frontend", "", "G", 1, nil}, db.queries[0].args)
+
+	db = &fakeDB{}
+	_, err = NewSpanReader(db).GetOperations(context.Background(), spanstore.OperationQueryParameters{
+		ServiceName: "frontend",
+		SpanKind:    "server",
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"frontend", "server", "", 0, 10}, db.queries[0].args)
+}
+
+func TestFindTraces(t *testing.T) {
+	span1, span2, span3 := testSpan(), testSpan(), testSpan()
+	span2.TraceID = model.NewTraceID(0, 5)
+	span3.SpanID = model.NewSpanID(4)
+	db := &fakeDB{results: []*fakeRows{
+		traceIDRows(span2.TraceID, span1.TraceID),
+		spanRows(t, span1, span2, span3),
+	}}
+	page, err := NewSpanReader(db).FindTracesPage(context.Background(), testQuery())
+	require.NoError(t, err)
+	assert.Empty(t, page.NextPageToken)
+	traces := page.Traces
+	require.Len(t, traces, 2)
+	// in the order of the trace IDs
+	assert.Equal(t, span2.TraceID, traces[0].Spans[0].TraceID)
+	assert.Len(t, traces[0].Spans, 1)
+	assert.Equal(t, span1.TraceID, traces[1].Spans[0].TraceID)
+	assert.Len(t, traces[1].Spans, 2)
+	assert.Equal(t, []any{[][]byte{traceIDBytes(span2.TraceID), traceIDBytes(span1.TraceID)}}, db.queries[1].args)
+
+	db = &fakeDB{}
+	traces, err = NewSpanReader(db).FindTraces(context.Background(), testQuery())
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+	assert.Len(t, db.queries, 1)
+}
+
+func TestFindTraceIDsPagination(t *testing.T) {
+	traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3)}
+	db := &fakeDB{results: []*fakeRows{traceIDRows(traceIDs...)}}
+	query := testQuery()
+	found, nextPageToken, err := NewSpanReader(db).findTraceIDsPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, traceIDs[:2], found)
+	// the next page starts after the last trace of the page
+	cursor := spanstore.TraceCursor{StartTime: time.Unix(1700003599, 0).UTC(), TraceID: traceIDs[1]}
+	assert.Equal(t, spanstore.EncodeTraceCursor(cursor), nextPageToken)
+	args := db.queries[0].args
+	assert.Equal(t, []any{3}, args[len(args)-1:])
+
+	db = &fakeDB{results: []*fakeRows{traceIDRows(traceIDs[2])}}
+	query = testQuery()
+	query.PageToken = nextPageToken
+	found, nextPageToken, err = NewSpanReader(db).findTraceIDsPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, traceIDs[2:], found)
+	assert.Empty(t, nextPageToken)
+	args = db.queries[0].args
+	assert.Equal(t, []any{cursor.StartTime, traceIDBytes(cursor.TraceID), 3}, args[len(args)-3:])
+}
+
+func TestFindTraceIDsErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    func(q *spanstore.TraceQueryParameters)
+		expected error
+	}{
+		{
+			name:     "nil query",
+			expected: ErrMalformedRequestObject,
+		},
+		{
+			name:     "tags without service",
+			query:    func(q *spanstore.TraceQueryParameters) { q.ServiceName = ""; q.Tags = map[string]string{"k": "v"} },
+			expected: ErrServiceNameNotSet,
+		},
+		{
+			name:     "no start time",
+			query:    func(q *spanstore.TraceQueryParameters) { q.StartTimeMin = time.Time{} },
+			expected: ErrStartAndEndTimeNotSet,
+		},
+		{
+			name:     "start time min above max",
+			query:    func(q *spanstore.TraceQueryParameters) { q.StartTimeMin = q.StartTimeMax.Add(time.Second) },
+			expected: ErrStartTimeMinGreaterThanMax,
+		},
+		{
+			name:     "duration min above max",
+			query:    func(q *spanstore.TraceQueryParameters) { q.DurationMin = time.Second; q.DurationMax = time.Millisecond },
+			expected: ErrDurationMinGreaterThanMax,
+		},
+		{
+			name:     "invalid page token",
+			query:    func(q *spanstore.TraceQueryParameters) { q.PageToken = "invalid" },
+			expected: spanstore.ErrInvalidPageToken,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var query *spanstore.TraceQueryParameters
+			if tc.query != nil {
+				query = testQuery()
+				tc.query(query)
+			}
+			_, err := NewSpanReader(&fakeDB{}).FindTraceIDs(context.Background(), query)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+
+	db := &fakeDB{results: []*fakeRows{{values: [][]any{{[]byte{1, 2}}}}}}
+	_, err := NewSpanReader(db).FindTraceIDs(context.Background(), testQuery())
+	require.EqualError(t, err, "invalid trace ID of 2 bytes")
+
+	_, err = NewSpanReader(&fakeDB{err: errors.New("db error")}).FindTraceIDs(context.Background(), testQuery())
+	require.EqualError(t, err, "db error")
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/cache"
+)
+
+const (
+	// the no-op update makes RETURNING return the ID of an existing row
+	upsertService = `
+		INSERT INTO services (name) VALUES ($1)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`
+	upsertOperation = `
+		INSERT INTO operations (service_id, name, span_kind) VALUES ($1, $2, $3)
+		ON CONFLICT (service_id, name, span_kind) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`
+	insertSpan = `
+		INSERT INTO spans (trace_id, span_id, service_id, operation_id, start_time, duration, span)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	insertSpanTags = `
+		INSERT INTO span_tags (trace_id, span_id, start_time, scope, log_index, key, value)
+		SELECT $1::BYTEA, $2::BIGINT, $3::TIMESTAMPTZ, *
+		FROM unnest($4::SMALLINT[], $5::INTEGER[], $6::TEXT[], $7::TEXT[])`
+
+	// idCacheSize is the number of IDs of services and operations cached by the writer.
+	idCacheSize = 10000
+)
+
+// SpanWriter writes spans to PostgreSQL. Implements spanstore.Writer
+type SpanWriter struct {
+	db     DB
+	logger *zap.Logger
+	// ids caches the IDs of the services and operations, which are never modified once inserted.
+	ids *cache.LRU
+}
+
+// NewSpanWriter creates a SpanWriter.
+func NewSpanWriter(db DB, logger *zap.Logger) *SpanWriter {
+	return &SpanWriter{
+		db:     db,
+		logger: logger,
+		ids:    cache.NewLRU(idCacheSize),
+	}
+}
+
+// WriteSpan inserts the span and its tags in a single transaction.
+func (w *SpanWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	serviceName := span.Process.GetServiceName()
+	serviceID, err := w.id(ctx, "s:"+serviceName, upsertService, serviceName)
+	if err != nil {
+		return fmt.Errorf("failed to insert the service %s: %w", serviceName, err)
+	}
+	kind, _ := span.GetSpanKind()
+	operationID, err := w.id(ctx, fmt.Sprintf("o:%d:%s:%s", serviceID, kind, span.OperationName),
+		upsertOperation, serviceID, span.OperationName, kind.String())
+	if err != nil {
+		return fmt.Errorf("failed to insert the operation %s: %w", span.OperationName, err)
+	}
+	payload, err := span.Marshal()
+	if err != nil {
+		return err
+	}
+	traceID := traceIDBytes(span.TraceID)
+	spanID := int64(span.SpanID)
+	startTime := span.StartTime.UTC()
+	batch := &pgx.Batch{}
+	batch.Queue(insertSpan, traceID, spanID, serviceID, operationID, startTime, model.DurationAsMicroseconds(span.Duration), payload)
+	tags := tagsOf(span)
+	if len(tags.keys) > 0 {
+		batch.Queue(insertSpanTags, traceID, spanID, startTime, tags.scopes, tags.logIndexes, tags.keys, tags.values)
+	}
+	// the statements of a batch are executed in an implicit transaction
+	results := w.db.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("failed to insert the span: %w", err)
+		}
+	}
+	return results.Close()
+}
+
+// id returns the ID of a service or an operation, inserting it if needed.
+func (w *SpanWriter) id(ctx context.Context, cacheKey string, upsert string, args ...any) (int32, error) {
+	if id, ok := w.ids.Get(cacheKey).(int32); ok {
+		return id, nil
+	}
+	var id int32
+	if err := w.db.QueryRow(ctx, upsert, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+	w.ids.Put(cacheKey, id)
+	return id, nil
+}
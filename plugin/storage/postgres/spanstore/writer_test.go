@@ -0,0 +1,192 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// fakeRows returns the values of each row, scanned positionally into the destinations.
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	err    error
+	row    int
+}
+
+func (r *fakeRows) Next() bool {
+	if r.err != nil || r.row >= len(r.values) {
+		return false
+	}
+	r.row++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, v := range r.values[r.row-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (*fakeRows) Close() {}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+type query struct {
+	sql  string
+	args []any
+}
+
+// fakeDB records the queries and batches, returns the rows of the results queued for the queries
+// and sequential IDs for the upserts.
+type fakeDB struct {
+	queries []query
+	results []*fakeRows
+	batches []*pgx.Batch
+	nextID  int32
+	err     error
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.queries = append(db.queries, query{sql: sql, args: args})
+	if db.err != nil {
+		return nil, db.err
+	}
+	if len(db.results) == 0 {
+		return &fakeRows{}, nil
+	}
+	rows := db.results[0]
+	db.results = db.results[1:]
+	return rows, nil
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	db.queries = append(db.queries, query{sql: sql, args: args})
+	db.nextID++
+	return fakeRow{id: db.nextID, err: db.err}
+}
+
+func (db *fakeDB) SendBatch(_ context.Context, b *pgx.Batch) pgx.BatchResults {
+	db.batches = append(db.batches, b)
+	return &fakeBatchResults{err: db.err}
+}
+
+type fakeBatchResults struct {
+	pgx.BatchResults
+	err error
+}
+
+func (r *fakeBatchResults) Exec() (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, r.err
+}
+
+func (*fakeBatchResults) Close() error {
+	return nil
+}
+
+// fakeRow is the ID returned by the upsert of a service or an operation.
+type fakeRow struct {
+	id  int32
+	err error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int32) = r.id
+	return nil
+}
+
+// testSpan has tags, process tags and a log.
+func testSpan() *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(1, 2),
+		SpanID:        model.NewSpanID(3),
+		OperationName: "GET /",
+		StartTime:     time.Unix(1700000000, 0),
+		Duration:      time.Millisecond,
+		Tags: []model.KeyValue{
+			model.String("span.kind", "server"),
+			model.Int64("http.status_code", 200),
+		},
+		Process: model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "host1")}),
+		Logs: []model.Log{{
+			Timestamp: time.Unix(1700000000, 0),
+			Fields:    []model.KeyValue{model.String("event", "retry")},
+		}},
+	}
+}
+
+func TestWriteSpan(t *testing.T) {
+	db := &fakeDB{}
+	writer := NewSpanWriter(db, zap.NewNop())
+	span := testSpan()
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+
+	require.Len(t, db.queries, 2)
+	assert.Equal(t, []any{"frontend"}, db.queries[0].args)
+	assert.Equal(t, []any{int32(1), "GET /", "server"}, db.queries[1].args)
+
+	require.Len(t, db.batches, 1)
+	queued := db.batches[0].QueuedQueries
+	require.Len(t, queued, 2)
+	payload, err := span.Marshal()
+	require.NoError(t, err)
+	traceID := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}
+	startTime := span.StartTime.UTC()
+	assert.Equal(t, []any{traceID, int64(3), int32(1), int32(2), startTime, uint64(1000), payload}, queued[0].Arguments)
+	assert.Equal(t, []any{
+		traceID, int64(3), startTime,
+		[]int16{scopeSpan, scopeSpan, scopeProcess, scopeLog},
+		[]int32{0, 0, 0, 0},
+		[]string{"span.kind", "http.status_code", "hostname", "event"},
+		[]string{"server", "200", "host1", "retry"},
+	}, queued[1].Arguments)
+
+	// the IDs of the service and the operation are cached
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+	assert.Len(t, db.queries, 2)
+	assert.Len(t, db.batches, 2)
+}
+
+func TestWriteSpanWithoutTags(t *testing.T) {
+	db := &fakeDB{}
+	writer := NewSpanWriter(db, zap.NewNop())
+	span := &model.Span{Process: model.NewProcess("frontend", nil)}
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+	require.Len(t, db.batches, 1)
+	assert.Len(t, db.batches[0].QueuedQueries, 1)
+	assert.Equal(t, "unspecified", db.queries[1].args[2])
+}
+
+func TestWriteSpanErrors(t *testing.T) {
+	db := &fakeDB{err: errors.New("db error")}
+	writer := NewSpanWriter(db, zap.NewNop())
+	err := writer.WriteSpan(context.Background(), testSpan())
+	require.EqualError(t, err, "failed to insert the service frontend: db error")
+
+	// the IDs are cached, not the failure
+	db.err = nil
+	writer = NewSpanWriter(db, zap.NewNop())
+	require.NoError(t, writer.WriteSpan(context.Background(), testSpan()))
+	db.err = errors.New("db error")
+	err = writer.WriteSpan(context.Background(), testSpan())
+	require.EqualError(t, err, "failed to insert the span: db error")
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// queryDependencies counts the calls between the services of the spans started in the time range
+// and the services of their parent spans, like the memory and Badger storages. The calls are
+// weighted by the adjusted counts of the sampled spans, to count the actual traffic.
+const queryDependencies = `
+	SELECT p.service_name, c.service_name, CAST(ROUND(SUM(COALESCE(
+		(SELECT CAST(t.value AS REAL) FROM span_tags t WHERE t.span_ref = c.id AND t.scope = 0 AND t.key = ?), 1
+	))) AS INTEGER) FROM spans c
+	JOIN spans p ON p.trace_id = c.trace_id AND p.span_id = c.parent_span_id
+	WHERE c.start_time >= ? AND c.start_time <= ? AND c.parent_span_id != 0 AND p.service_name != c.service_name
+	GROUP BY p.service_name, c.service_name
+	ORDER BY p.service_name, c.service_name`
+
+// DependencyStore computes the dependencies between the services from the spans stored in SQLite.
+type DependencyStore struct {
+	db *sql.DB
+}
+
+// NewDependencyStore creates a DependencyStore.
+func NewDependencyStore(db *sql.DB) *DependencyStore {
+	return &DependencyStore{db: db}
+}
+
+// GetDependencies returns all interservice dependencies
+func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	rows, err := s.db.QueryContext(ctx, queryDependencies, model.AdjustedCountTag,
+		int64(model.TimeAsEpochMicroseconds(endTs.Add(-lookback))), int64(model.TimeAsEpochMicroseconds(endTs)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
+	}
+	defer rows.Close()
+	var dependencies []model.DependencyLink
+	for rows.Next() {
+		var d model.DependencyLink
+		if err := rows.Scan(&d.Parent, &d.Child, &d.CallCount); err != nil {
+			return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
+		}
+		dependencies = append(dependencies, d)
+	}
+	return dependencies, rows.Err()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite/schema"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite/spanstore"
+)
+
+func TestGetDependencies(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "jaeger.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, schema.Migrate(context.Background(), db))
+
+	now := time.Now()
+	traceID := model.NewTraceID(0, 1)
+	span := func(spanID, parentID uint64, service string, startTime time.Time) *model.Span {
+		s := &model.Span{
+			TraceID:   traceID,
+			SpanID:    model.NewSpanID(spanID),
+			StartTime: startTime,
+			Process:   model.NewProcess(service, nil),
+		}
+		if parentID != 0 {
+			s.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parentID))}
+		}
+		return s
+	}
+	sampled := func(s *model.Span, adjustedCount float64) *model.Span {
+		s.Tags = model.KeyValues{model.Float64(model.AdjustedCountTag, adjustedCount)}
+		return s
+	}
+	writer := spanstore.NewSpanWriter(db)
+	for _, s := range []*model.Span{
+		span(1, 0, "frontend", now),
+		span(2, 1, "backend", now),
+		span(3, 1, "backend", now),
+		// within the same service
+		span(4, 3, "backend", now),
+		span(5, 4, "db", now),
+		// sampled at 25%
+		sampled(span(7, 6, "db", now), 4),
+		span(6, 0, "cache", now),
+		// out of the lookback
+		span(8, 1, "cache", now.Add(-2*time.Hour)),
+	} {
+		require.NoError(t, writer.WriteSpan(context.Background(), s))
+	}
+
+	store := NewDependencyStore(db)
+	dependencies, err := store.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{
+		{Parent: "backend", Child: "db", CallCount: 1},
+		{Parent: "cache", Child: "db", CallCount: 4},
+		{Parent: "frontend", Child: "backend", CallCount: 2},
+	}, dependencies)
+
+	require.NoError(t, db.Close())
+	_, err = store.GetDependencies(context.Background(), now, time.Hour)
+	require.ErrorContains(t, err, "error reading dependencies from storage")
+}
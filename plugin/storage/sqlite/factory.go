@@ -0,0 +1,222 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	// registers the "sqlite" driver of database/sql
+	_ "modernc.org/sqlite"
+
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	depStore "github.com/jaegertracing/jaeger/plugin/storage/sqlite/dependencystore"
+	sqliteSampling "github.com/jaegertracing/jaeger/plugin/storage/sqlite/samplingstore"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite/schema"
+	sqliteStore "github.com/jaegertracing/jaeger/plugin/storage/sqlite/spanstore"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory                      = (*Factory)(nil)
+	_ storage.Purger                       = (*Factory)(nil)
+	_ storage.SamplingStoreFactory         = (*Factory)(nil)
+	_ storage.SamplingStrategyStoreFactory = (*Factory)(nil)
+	_ io.Closer                            = (*Factory)(nil)
+	_ plugin.Configurable                  = (*Factory)(nil)
+)
+
+const (
+	// the tags of the spans are deleted with them
+	deleteExpiredSpans = `DELETE FROM spans WHERE start_time < ?`
+	deleteOperations   = `
+		DELETE FROM operations WHERE NOT EXISTS (SELECT 1 FROM spans s
+			WHERE s.service_name = operations.service_name AND s.operation_name = operations.operation_name)`
+	deleteThroughput    = `DELETE FROM sampling_throughput WHERE ts < ?`
+	deleteProbabilities = `DELETE FROM sampling_probabilities WHERE ts < ?`
+
+	lastMaintenanceRunName = "sqlite_storage_maintenance_last_run"
+)
+
+// Factory implements storage.Factory for a SQLite database in a single file.
+type Factory struct {
+	options Options
+
+	logger *zap.Logger
+	db     *sql.DB
+
+	lastMaintenanceRun metrics.Gauge
+	maintenanceDone    chan struct{}
+	maintenanceWG      sync.WaitGroup
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{
+		maintenanceDone: make(chan struct{}),
+	}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, _ *zap.Logger) {
+	f.options.InitFromViper(v)
+}
+
+// configureFromOptions initializes factory from options.
+func (f *Factory) configureFromOptions(o Options) {
+	f.options = o
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.logger = logger
+	logger.Info("SQLite factory", zap.String("path", f.options.Path), zap.Duration("span-store-ttl", f.options.SpanStoreTTL))
+
+	if f.options.Path == "" {
+		return errors.New("the path of the sqlite storage must be set")
+	}
+	if f.options.SpanStoreTTL > 0 && f.options.MaintenanceInterval <= 0 {
+		return errors.New("the maintenance interval of the sqlite storage must be positive")
+	}
+	db, err := sql.Open("sqlite", dataSourceName(f.options))
+	if err != nil {
+		return err
+	}
+	if err := schema.Migrate(context.Background(), db); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create the schema of the sqlite storage: %w", err)
+	}
+	f.db = db
+
+	f.lastMaintenanceRun = metricsFactory.Gauge(metrics.Options{Name: lastMaintenanceRunName})
+	if f.options.SpanStoreTTL > 0 {
+		f.maintenanceWG.Add(1)
+		go f.maintenance()
+	}
+	return nil
+}
+
+// dataSourceName returns the name of the database with the pragmas applied to each connection.
+// The write transactions take the lock of the database when they begin, so that the concurrent
+// writes wait for each other instead of failing when upgrading their lock.
+func dataSourceName(o Options) string {
+	params := []string{
+		"_pragma=journal_mode(WAL)",
+		"_pragma=synchronous(NORMAL)",
+		"_pragma=foreign_keys(1)",
+		fmt.Sprintf("_pragma=busy_timeout(%d)", o.BusyTimeout.Milliseconds()),
+		"_txlock=immediate",
+	}
+	return "file:" + o.Path + "?" + strings.Join(params, "&")
+}
+
+// CreateSpanReader implements storage.Factory
+func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return sqliteStore.NewSpanReader(f.db), nil
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	return sqliteStore.NewSpanWriter(f.db), nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return depStore.NewDependencyStore(f.db), nil
+}
+
+// Capabilities implements storage.Factory
+func (*Factory) Capabilities() storage.Capabilities {
+	return storage.Capabilities{
+		TraceSearch:       true,
+		TagSearch:         true,
+		OperationSpanKind: true,
+		Dependencies:      true,
+		AdaptiveSampling:  true,
+	}
+}
+
+// CreateSamplingStore implements storage.SamplingStoreFactory
+func (f *Factory) CreateSamplingStore(int /* maxBuckets */) (samplingstore.Store, error) {
+	return sqliteSampling.NewSamplingStore(f.db), nil
+}
+
+// CreateSamplingStrategyStore implements storage.SamplingStrategyStoreFactory
+func (f *Factory) CreateSamplingStrategyStore() (samplingstore.StrategyStore, error) {
+	return sqliteSampling.NewStrategyStore(f.db), nil
+}
+
+// CreateLock implements storage.SamplingStoreFactory
+func (*Factory) CreateLock() (distributedlock.Lock, error) {
+	return &lock{}, nil
+}
+
+// Purge removes all the data of the database, only meant to be used by the integration tests.
+func (f *Factory) Purge(ctx context.Context) error {
+	for _, table := range []string{"spans", "operations", "sampling_throughput", "sampling_probabilities", "sampling_strategies"} {
+		if _, err := f.db.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the maintenance and closes the database.
+func (f *Factory) Close() error {
+	close(f.maintenanceDone)
+	f.maintenanceWG.Wait()
+	if f.db == nil {
+		return nil
+	}
+	return f.db.Close()
+}
+
+// maintenance periodically deletes the spans and the sampling data older than the span store TTL.
+func (f *Factory) maintenance() {
+	defer f.maintenanceWG.Done()
+	ticker := time.NewTicker(f.options.MaintenanceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.maintenanceDone:
+			return
+		case t := <-ticker.C:
+			if err := f.deleteExpired(t.Add(-f.options.SpanStoreTTL)); err != nil {
+				f.logger.Error("Failed to delete the expired spans", zap.Error(err))
+			}
+			f.lastMaintenanceRun.Update(t.UnixNano())
+		}
+	}
+}
+
+func (f *Factory) deleteExpired(before time.Time) error {
+	ctx := context.Background()
+	ts := before.UnixMicro()
+	for _, statement := range []string{deleteExpiredSpans, deleteThroughput, deleteProbabilities} {
+		if _, err := f.db.ExecContext(ctx, statement, ts); err != nil {
+			return err
+		}
+	}
+	_, err := f.db.ExecContext(ctx, deleteOperations)
+	return err
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func newTestFactory(t *testing.T, flags ...string) *Factory {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags(append([]string{
+		"--sqlite.path=" + filepath.Join(t.TempDir(), "jaeger.db"),
+	}, flags...)))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	return f
+}
+
+func testSpan(startTime time.Time) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, 1),
+		SpanID:        model.NewSpanID(1),
+		OperationName: "GET /",
+		StartTime:     startTime,
+		Tags:          []model.KeyValue{model.String("k", "v")},
+		Process:       model.NewProcess("frontend", nil),
+	}
+}
+
+func TestSQLiteFactory(t *testing.T) {
+	f := newTestFactory(t)
+	defer f.Close()
+
+	var journalMode string
+	require.NoError(t, f.db.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+	assert.Equal(t, "wal", journalMode)
+
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	span := testSpan(time.Now())
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+	trace, err := reader.GetTrace(context.Background(), span.TraceID)
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 1)
+
+	_, err = f.CreateDependencyReader()
+	require.NoError(t, err)
+	assert.True(t, f.Capabilities().AdaptiveSampling)
+	samplingStore, err := f.CreateSamplingStore(0)
+	require.NoError(t, err)
+	require.NoError(t, samplingStore.InsertThroughput(nil))
+	strategyStore, err := f.CreateSamplingStrategyStore()
+	require.NoError(t, err)
+	require.NoError(t, strategyStore.WriteStrategy(context.Background(), "frontend", &api_v2.SamplingStrategyResponse{}))
+	_, err = f.CreateLock()
+	require.NoError(t, err)
+
+	require.NoError(t, f.Purge(context.Background()))
+	_, err = reader.GetTrace(context.Background(), span.TraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	services, err := reader.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, services)
+	strategies, err := strategyStore.FindStrategies(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, strategies)
+}
+
+func TestSQLiteFactoryConcurrentWrites(t *testing.T) {
+	f := newTestFactory(t)
+	defer f.Close()
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+
+	// the writes wait for each other instead of failing with SQLITE_BUSY
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			span := testSpan(time.Now())
+			span.TraceID = model.NewTraceID(0, uint64(i))
+			assert.NoError(t, writer.WriteSpan(context.Background(), span))
+		}(i)
+	}
+	wg.Wait()
+	var spans int
+	require.NoError(t, f.db.QueryRow("SELECT COUNT(*) FROM spans").Scan(&spans))
+	assert.Equal(t, 10, spans)
+}
+
+func TestSQLiteFactoryReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jaeger.db")
+	f := newTestFactory(t, "--sqlite.path="+path)
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	span := testSpan(time.Now())
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+	require.NoError(t, f.Close())
+
+	// the spans are kept, the migrations are not applied again
+	f = newTestFactory(t, "--sqlite.path="+path)
+	defer f.Close()
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	_, err = reader.GetTrace(context.Background(), span.TraceID)
+	require.NoError(t, err)
+}
+
+func TestSQLiteFactoryMaintenance(t *testing.T) {
+	f := NewFactory()
+	f.configureFromOptions(Options{
+		Path:                filepath.Join(t.TempDir(), "jaeger.db"),
+		SpanStoreTTL:        time.Hour,
+		MaintenanceInterval: 10 * time.Millisecond,
+	})
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	require.NoError(t, f.Initialize(metricsFactory, zap.NewNop()))
+	defer f.Close()
+
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	expired := testSpan(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, writer.WriteSpan(context.Background(), expired))
+	kept := testSpan(time.Now())
+	kept.TraceID = model.NewTraceID(0, 2)
+	kept.OperationName = "POST /"
+	require.NoError(t, writer.WriteSpan(context.Background(), kept))
+
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, err := reader.GetTrace(context.Background(), expired.TraceID)
+		return errors.Is(err, spanstore.ErrTraceNotFound)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = reader.GetTrace(context.Background(), kept.TraceID)
+	require.NoError(t, err)
+	operations, err := reader.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "POST /", SpanKind: "unspecified"}}, operations)
+	var tags int
+	require.NoError(t, f.db.QueryRow("SELECT COUNT(*) FROM span_tags").Scan(&tags))
+	assert.Equal(t, 1, tags)
+
+	_, gauges := metricsFactory.Snapshot()
+	assert.Positive(t, gauges[lastMaintenanceRunName])
+}
+
+func TestSQLiteFactoryErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:     "no path",
+			expected: "the path of the sqlite storage must be set",
+		},
+		{
+			name:     "no maintenance interval",
+			options:  Options{Path: filepath.Join(t.TempDir(), "jaeger.db"), SpanStoreTTL: time.Hour},
+			expected: "the maintenance interval of the sqlite storage must be positive",
+		},
+		{
+			name:     "invalid path",
+			options:  Options{Path: filepath.Join(t.TempDir(), "missing", "jaeger.db")},
+			expected: "failed to create the schema of the sqlite storage",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFactory()
+			f.configureFromOptions(tc.options)
+			require.ErrorContains(t, f.Initialize(metrics.NullFactory, zap.NewNop()), tc.expected)
+			require.NoError(t, f.Close())
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	l := &lock{}
+	ok, err := l.Acquire("resource", time.Duration(1))
+	assert.True(t, ok)
+	require.NoError(t, err)
+}
+
+func TestForfeit(t *testing.T) {
+	l := &lock{}
+	ok, err := l.Forfeit("resource")
+	assert.True(t, ok)
+	require.NoError(t, err)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlite
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	configPrefix              = "sqlite"
+	suffixPath                = ".path"
+	suffixSpanStoreTTL        = ".span-store-ttl"
+	suffixMaintenanceInterval = ".maintenance-interval"
+	suffixBusyTimeout         = ".busy-timeout"
+
+	defaultPath                = "jaeger.db"
+	defaultSpanStoreTTL        = 72 * time.Hour
+	defaultMaintenanceInterval = 5 * time.Minute
+	defaultBusyTimeout         = 5 * time.Second
+)
+
+// Options stores the configuration of the SQLite storage
+type Options struct {
+	// Path is the file of the database, created if it does not exist.
+	Path string `mapstructure:"path"`
+	// SpanStoreTTL is how long the spans are kept, forever if not positive.
+	SpanStoreTTL time.Duration `mapstructure:"span_store_ttl"`
+	// MaintenanceInterval is the interval of the deletions of the expired spans.
+	MaintenanceInterval time.Duration `mapstructure:"maintenance_interval"`
+	// BusyTimeout is how long a write waits for the concurrent ones to finish.
+	BusyTimeout time.Duration `mapstructure:"busy_timeout"`
+}
+
+// AddFlags adds flags for Options
+func (*Options) AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		configPrefix+suffixPath,
+		defaultPath,
+		"The file of the SQLite database, created if it does not exist, its journal is written in WAL mode next to it")
+	flagSet.Duration(
+		configPrefix+suffixSpanStoreTTL,
+		defaultSpanStoreTTL,
+		"How long to keep the spans in the SQLite database, forever if 0")
+	flagSet.Duration(
+		configPrefix+suffixMaintenanceInterval,
+		defaultMaintenanceInterval,
+		"How often the spans older than the span store TTL are deleted from the SQLite database")
+	flagSet.Duration(
+		configPrefix+suffixBusyTimeout,
+		defaultBusyTimeout,
+		"How long a write to the SQLite database waits for the concurrent writes before failing")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (o *Options) InitFromViper(v *viper.Viper) {
+	o.Path = v.GetString(configPrefix + suffixPath)
+	o.SpanStoreTTL = v.GetDuration(configPrefix + suffixSpanStoreTTL)
+	o.MaintenanceInterval = v.GetDuration(configPrefix + suffixMaintenanceInterval)
+	o.BusyTimeout = v.GetDuration(configPrefix + suffixBusyTimeout)
+}
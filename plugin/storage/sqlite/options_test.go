@@ -0,0 +1,47 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	err := command.ParseFlags([]string{
+		"--sqlite.path=/var/lib/jaeger/traces.db",
+		"--sqlite.span-store-ttl=24h",
+		"--sqlite.maintenance-interval=1m",
+		"--sqlite.busy-timeout=1s",
+	})
+	require.NoError(t, err)
+	opts.InitFromViper(v)
+
+	assert.Equal(t, Options{
+		Path:                "/var/lib/jaeger/traces.db",
+		SpanStoreTTL:        24 * time.Hour,
+		MaintenanceInterval: time.Minute,
+		BusyTimeout:         time.Second,
+	}, *opts)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, _ := config.Viperize(opts.AddFlags)
+	opts.InitFromViper(v)
+
+	assert.Equal(t, Options{
+		Path:                defaultPath,
+		SpanStoreTTL:        defaultSpanStoreTTL,
+		MaintenanceInterval: defaultMaintenanceInterval,
+		BusyTimeout:         defaultBusyTimeout,
+	}, *opts)
+}
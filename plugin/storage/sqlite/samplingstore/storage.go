@@ -0,0 +1,107 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	jaegermodel "github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	insertThroughput    = `INSERT INTO sampling_throughput (ts, throughput) VALUES (?, ?)`
+	queryThroughput     = `SELECT throughput FROM sampling_throughput WHERE ts > ? AND ts <= ? ORDER BY ts`
+	insertProbabilities = `INSERT INTO sampling_probabilities (ts, hostname, probabilities, qps) VALUES (?, ?, ?, ?)`
+	queryProbabilities  = `SELECT probabilities FROM sampling_probabilities ORDER BY ts DESC, rowid DESC LIMIT 1`
+)
+
+// SamplingStore stores the throughput and the probabilities of the adaptive sampling in SQLite.
+// The values are JSON encoded, the times are in microseconds since the epoch.
+type SamplingStore struct {
+	db      *sql.DB
+	timeNow func() time.Time
+}
+
+// NewSamplingStore creates a SamplingStore.
+func NewSamplingStore(db *sql.DB) *SamplingStore {
+	return &SamplingStore{
+		db:      db,
+		timeNow: time.Now,
+	}
+}
+
+// InsertThroughput implements samplingstore.Store#InsertThroughput.
+func (s *SamplingStore) InsertThroughput(throughput []*model.Throughput) error {
+	value, err := json.Marshal(throughput)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(insertThroughput, s.now(), string(value))
+	return err
+}
+
+// GetThroughput implements samplingstore.Store#GetThroughput.
+func (s *SamplingStore) GetThroughput(start, end time.Time) ([]*model.Throughput, error) {
+	rows, err := s.db.Query(queryThroughput,
+		int64(jaegermodel.TimeAsEpochMicroseconds(start)), int64(jaegermodel.TimeAsEpochMicroseconds(end)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []*model.Throughput
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		var throughput []*model.Throughput
+		if err := json.Unmarshal([]byte(value), &throughput); err != nil {
+			return nil, err
+		}
+		result = append(result, throughput...)
+	}
+	return result, rows.Err()
+}
+
+// InsertProbabilitiesAndQPS implements samplingstore.Store#InsertProbabilitiesAndQPS.
+func (s *SamplingStore) InsertProbabilitiesAndQPS(hostname string,
+	probabilities model.ServiceOperationProbabilities,
+	qps model.ServiceOperationQPS,
+) error {
+	probabilitiesValue, err := json.Marshal(probabilities)
+	if err != nil {
+		return err
+	}
+	qpsValue, err := json.Marshal(qps)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(insertProbabilities, s.now(), hostname, string(probabilitiesValue), string(qpsValue))
+	return err
+}
+
+// GetLatestProbabilities implements samplingstore.Store#GetLatestProbabilities.
+func (s *SamplingStore) GetLatestProbabilities() (model.ServiceOperationProbabilities, error) {
+	var value string
+	err := s.db.QueryRow(queryProbabilities).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var probabilities model.ServiceOperationProbabilities
+	if err := json.Unmarshal([]byte(value), &probabilities); err != nil {
+		return nil, err
+	}
+	return probabilities, nil
+}
+
+func (s *SamplingStore) now() int64 {
+	return int64(jaegermodel.TimeAsEpochMicroseconds(s.timeNow()))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite/schema"
+)
+
+func newTestStore(t *testing.T) (*SamplingStore, *sql.DB) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "jaeger.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, schema.Migrate(context.Background(), db))
+	return NewSamplingStore(db), db
+}
+
+func TestThroughput(t *testing.T) {
+	store, _ := newTestStore(t)
+	start := time.Unix(1700000000, 0)
+	for i, service := range []string{"frontend", "backend", "db"} {
+		store.timeNow = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+		require.NoError(t, store.InsertThroughput([]*model.Throughput{{Service: service, Operation: "op", Count: int64(i)}}))
+	}
+
+	// the start of the range is exclusive and its end inclusive
+	throughput, err := store.GetThroughput(start, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Throughput{
+		{Service: "backend", Operation: "op", Count: 1},
+		{Service: "db", Operation: "op", Count: 2},
+	}, throughput)
+}
+
+func TestProbabilities(t *testing.T) {
+	store, _ := newTestStore(t)
+	probabilities, err := store.GetLatestProbabilities()
+	require.NoError(t, err)
+	assert.Nil(t, probabilities)
+
+	require.NoError(t, store.InsertProbabilitiesAndQPS("host1",
+		model.ServiceOperationProbabilities{"frontend": {"op": 0.5}},
+		model.ServiceOperationQPS{"frontend": {"op": 10}}))
+	require.NoError(t, store.InsertProbabilitiesAndQPS("host1",
+		model.ServiceOperationProbabilities{"frontend": {"op": 0.1}},
+		model.ServiceOperationQPS{"frontend": {"op": 20}}))
+
+	probabilities, err = store.GetLatestProbabilities()
+	require.NoError(t, err)
+	assert.Equal(t, model.ServiceOperationProbabilities{"frontend": {"op": 0.1}}, probabilities)
+}
+
+func TestErrors(t *testing.T) {
+	store, db := newTestStore(t)
+	_, err := db.Exec("INSERT INTO sampling_throughput (ts, throughput) VALUES (1, 'invalid')")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO sampling_probabilities (ts, hostname, probabilities, qps) VALUES (1, 'host1', 'invalid', '{}')")
+	require.NoError(t, err)
+	_, err = store.GetThroughput(time.Unix(0, 0), time.Unix(1, 0))
+	require.Error(t, err)
+	_, err = store.GetLatestProbabilities()
+	require.Error(t, err)
+
+	require.NoError(t, db.Close())
+	require.Error(t, store.InsertThroughput(nil))
+	require.Error(t, store.InsertProbabilitiesAndQPS("host1", nil, nil))
+	_, err = store.GetThroughput(time.Unix(0, 0), time.Unix(1, 0))
+	require.Error(t, err)
+	_, err = store.GetLatestProbabilities()
+	require.Error(t, err)
+}
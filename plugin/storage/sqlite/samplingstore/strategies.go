@@ -0,0 +1,94 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	p2json "github.com/jaegertracing/jaeger/model/converter/json"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+const (
+	queryStrategy   = `SELECT strategy FROM sampling_strategies WHERE service_name = ?`
+	queryStrategies = `SELECT service_name, strategy FROM sampling_strategies`
+	upsertStrategy  = `INSERT INTO sampling_strategies (service_name, strategy) VALUES (?, ?)
+		ON CONFLICT (service_name) DO UPDATE SET strategy = excluded.strategy`
+	deleteStrategy = `DELETE FROM sampling_strategies WHERE service_name = ?`
+)
+
+// StrategyStore stores the sampling strategies of the services in SQLite, in the JSON
+// format of the sampling endpoints.
+type StrategyStore struct {
+	db *sql.DB
+}
+
+// NewStrategyStore creates a StrategyStore.
+func NewStrategyStore(db *sql.DB) *StrategyStore {
+	return &StrategyStore{db: db}
+}
+
+// GetStrategy implements samplingstore.StrategyStore#GetStrategy.
+func (s *StrategyStore) GetStrategy(ctx context.Context, service string) (*api_v2.SamplingStrategyResponse, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, queryStrategy, service).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, samplingstore.ErrStrategyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p2json.SamplingStrategyResponseFromJSON([]byte(value))
+}
+
+// FindStrategies implements samplingstore.StrategyStore#FindStrategies.
+func (s *StrategyStore) FindStrategies(ctx context.Context) (map[string]*api_v2.SamplingStrategyResponse, error) {
+	rows, err := s.db.QueryContext(ctx, queryStrategies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := make(map[string]*api_v2.SamplingStrategyResponse)
+	for rows.Next() {
+		var service, value string
+		if err := rows.Scan(&service, &value); err != nil {
+			return nil, err
+		}
+		strategy, err := p2json.SamplingStrategyResponseFromJSON([]byte(value))
+		if err != nil {
+			return nil, err
+		}
+		found[service] = strategy
+	}
+	return found, rows.Err()
+}
+
+// WriteStrategy implements samplingstore.StrategyStore#WriteStrategy.
+func (s *StrategyStore) WriteStrategy(ctx context.Context, service string, strategy *api_v2.SamplingStrategyResponse) error {
+	value, err := p2json.SamplingStrategyResponseToJSON(strategy)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, upsertStrategy, service, value)
+	return err
+}
+
+// DeleteStrategy implements samplingstore.StrategyStore#DeleteStrategy.
+func (s *StrategyStore) DeleteStrategy(ctx context.Context, service string) error {
+	result, err := s.db.ExecContext(ctx, deleteStrategy, service)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return samplingstore.ErrStrategyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+func TestStrategies(t *testing.T) {
+	_, db := newTestStore(t)
+	store := NewStrategyStore(db)
+	ctx := context.Background()
+
+	_, err := store.GetStrategy(ctx, "frontend")
+	require.ErrorIs(t, err, samplingstore.ErrStrategyNotFound)
+	found, err := store.FindStrategies(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	frontend := &api_v2.SamplingStrategyResponse{
+		StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.5},
+	}
+	billing := &api_v2.SamplingStrategyResponse{
+		StrategyType:         api_v2.SamplingStrategyType_RATE_LIMITING,
+		RateLimitingSampling: &api_v2.RateLimitingSamplingStrategy{MaxTracesPerSecond: 10},
+	}
+	require.NoError(t, store.WriteStrategy(ctx, "frontend", billing))
+	// the strategy is replaced
+	require.NoError(t, store.WriteStrategy(ctx, "frontend", frontend))
+	require.NoError(t, store.WriteStrategy(ctx, "billing", billing))
+
+	strategy, err := store.GetStrategy(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, frontend, strategy)
+
+	found, err = store.FindStrategies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*api_v2.SamplingStrategyResponse{"frontend": frontend, "billing": billing}, found)
+
+	require.NoError(t, store.DeleteStrategy(ctx, "frontend"))
+	_, err = store.GetStrategy(ctx, "frontend")
+	require.ErrorIs(t, err, samplingstore.ErrStrategyNotFound)
+	require.ErrorIs(t, store.DeleteStrategy(ctx, "frontend"), samplingstore.ErrStrategyNotFound)
+}
+
+func TestStrategiesInvalidJSON(t *testing.T) {
+	_, db := newTestStore(t)
+	store := NewStrategyStore(db)
+	ctx := context.Background()
+	_, err := db.Exec(`INSERT INTO sampling_strategies (service_name, strategy) VALUES ('frontend', '{')`)
+	require.NoError(t, err)
+
+	_, err = store.GetStrategy(ctx, "frontend")
+	require.Error(t, err)
+	_, err = store.FindStrategies(ctx)
+	require.Error(t, err)
+}
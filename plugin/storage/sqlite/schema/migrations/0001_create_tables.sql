@@ -0,0 +1,52 @@
+-- The spans are stored as protobuf encoded model.Span, with the columns and the tags
+-- needed to search them. The times are in microseconds since the epoch.
+CREATE TABLE IF NOT EXISTS spans (
+    id             INTEGER PRIMARY KEY,
+    trace_id       BLOB NOT NULL,
+    span_id        INTEGER NOT NULL,
+    parent_span_id INTEGER NOT NULL,
+    service_name   TEXT NOT NULL,
+    operation_name TEXT NOT NULL,
+    start_time     INTEGER NOT NULL,
+    duration       INTEGER NOT NULL,
+    span           BLOB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS spans_trace_id_idx ON spans (trace_id, span_id);
+CREATE INDEX IF NOT EXISTS spans_service_name_idx ON spans (service_name, operation_name, start_time);
+CREATE INDEX IF NOT EXISTS spans_start_time_idx ON spans (start_time);
+
+CREATE TABLE IF NOT EXISTS operations (
+    service_name   TEXT NOT NULL,
+    operation_name TEXT NOT NULL,
+    span_kind      TEXT NOT NULL,
+    PRIMARY KEY (service_name, operation_name, span_kind)
+) WITHOUT ROWID;
+
+-- scope is 0 for the tags of the span, 1 for the tags of its process and 2 for the fields of its logs
+CREATE TABLE IF NOT EXISTS span_tags (
+    span_ref  INTEGER NOT NULL REFERENCES spans (id) ON DELETE CASCADE,
+    scope     INTEGER NOT NULL,
+    log_index INTEGER NOT NULL,
+    key       TEXT NOT NULL,
+    value     TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS span_tags_span_ref_idx ON span_tags (span_ref);
+CREATE INDEX IF NOT EXISTS span_tags_key_value_idx ON span_tags (key, value);
+
+CREATE TABLE IF NOT EXISTS sampling_throughput (
+    ts         INTEGER NOT NULL,
+    throughput TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sampling_throughput_ts_idx ON sampling_throughput (ts);
+
+CREATE TABLE IF NOT EXISTS sampling_probabilities (
+    ts            INTEGER NOT NULL,
+    hostname      TEXT NOT NULL,
+    probabilities TEXT NOT NULL,
+    qps           TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sampling_probabilities_ts_idx ON sampling_probabilities (ts);
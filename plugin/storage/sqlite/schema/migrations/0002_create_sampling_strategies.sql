@@ -0,0 +1,6 @@
+-- The sampling strategies of the services set with the query service API, stored as
+-- api_v2.SamplingStrategyResponse in the JSON format of the sampling endpoints.
+CREATE TABLE IF NOT EXISTS sampling_strategies (
+    service_name TEXT PRIMARY KEY,
+    strategy     TEXT NOT NULL
+) WITHOUT ROWID;
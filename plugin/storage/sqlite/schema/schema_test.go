@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create_tables", migrations[0].Name)
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "jaeger.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, Migrate(context.Background(), db))
+	// the migrations already applied are skipped
+	require.NoError(t, Migrate(context.Background(), db))
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	var version int
+	require.NoError(t, db.QueryRow("PRAGMA user_version").Scan(&version))
+	assert.Equal(t, migrations[len(migrations)-1].Version, version)
+
+	var tables int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables))
+	assert.Equal(t, 6, tables)
+}
+
+func TestMigrateError(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "jaeger.db"))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	require.ErrorContains(t, Migrate(context.Background(), db), "failed to apply the schema migration 1_create_tables")
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// queryBuilder builds the SQL query of the trace IDs matching the parameters of a trace query.
+// Like the other span storages, a trace matches if one of its spans matches all the conditions.
+type queryBuilder struct {
+	conditions []string
+	args       []any
+}
+
+func (b *queryBuilder) where(condition string, args ...any) {
+	b.conditions = append(b.conditions, condition)
+	b.args = append(b.args, args...)
+}
+
+// tag adds the condition of a span having a tag of the scopes.
+func (b *queryBuilder) tag(key, value string, scopes ...int) {
+	args := []any{key, value}
+	for _, scope := range scopes {
+		args = append(args, scope)
+	}
+	b.where(`EXISTS (SELECT 1 FROM span_tags t
+		WHERE t.span_ref = s.id AND t.key = ? AND t.value = ?
+		AND t.scope IN (?`+strings.Repeat(", ?", len(scopes)-1)+`))`, args...)
+}
+
+// logFields adds the condition of a span having a log holding all the fields.
+func (b *queryBuilder) logFields(fields map[string]string) {
+	keys := sortedKeys(fields)
+	matches := make([]string, len(keys))
+	args := []any{scopeLog}
+	for i, key := range keys {
+		matches[i] = "(t.key = ? AND t.value = ?)"
+		args = append(args, key, fields[key])
+	}
+	args = append(args, len(keys))
+	b.where(`EXISTS (SELECT 1 FROM span_tags t
+		WHERE t.span_ref = s.id AND t.scope = ? AND (`+strings.Join(matches, " OR ")+`)
+		GROUP BY t.log_index HAVING COUNT(DISTINCT t.key) = ?)`, args...)
+}
+
+// buildFindTraceIDsQuery returns the query of the trace IDs matching the parameters and the start time of
+// their most recent matching span, the most recent first, after the cursor if any and limited to limit rows.
+func buildFindTraceIDsQuery(query *spanstore.TraceQueryParameters, cursor *spanstore.TraceCursor, limit int) (string, []any) {
+	b := &queryBuilder{}
+	if query.ServiceName != "" {
+		b.where("s.service_name = ?", query.ServiceName)
+	}
+	if query.OperationName != "" {
+		b.where("s.operation_name = ?", query.OperationName)
+	}
+	b.where("s.start_time >= ? AND s.start_time <= ?",
+		int64(model.TimeAsEpochMicroseconds(query.StartTimeMin)), int64(model.TimeAsEpochMicroseconds(query.StartTimeMax)))
+	if query.DurationMin != 0 {
+		b.where("s.duration >= ?", int64(model.DurationAsMicroseconds(query.DurationMin)))
+	}
+	if query.DurationMax != 0 {
+		b.where("s.duration <= ?", int64(model.DurationAsMicroseconds(query.DurationMax)))
+	}
+	// the tags of the query also match the process tags and the log fields
+	for _, key := range sortedKeys(query.Tags) {
+		b.tag(key, query.Tags[key], scopeSpan, scopeProcess, scopeLog)
+	}
+	for _, key := range sortedKeys(query.ResourceAttributes) {
+		b.tag(key, query.ResourceAttributes[key], scopeProcess)
+	}
+	if len(query.LogFields) > 0 {
+		b.logFields(query.LogFields)
+	}
+	var having string
+	if cursor != nil {
+		startTime := int64(model.TimeAsEpochMicroseconds(cursor.StartTime))
+		having = "HAVING MAX(s.start_time) < ? OR (MAX(s.start_time) = ? AND s.trace_id > ?)"
+		b.args = append(b.args, startTime, startTime, traceIDBytes(cursor.TraceID))
+	}
+	sql := `SELECT s.trace_id, MAX(s.start_time) FROM spans s
+		WHERE ` + strings.Join(b.conditions, " AND ") + `
+		GROUP BY s.trace_id
+		` + having + `
+		ORDER BY MAX(s.start_time) DESC, s.trace_id
+		LIMIT ?`
+	return sql, append(b.args, limit)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	defaultNumTraces = 100
+
+	queryTrace      = `SELECT span FROM spans WHERE trace_id = ? ORDER BY start_time`
+	queryServices   = `SELECT DISTINCT service_name FROM operations ORDER BY service_name`
+	queryOperations = `
+		SELECT operation_name, span_kind FROM operations
+		WHERE service_name = ? AND (? = '' OR span_kind = ?) AND substr(operation_name, 1, length(?)) = ?
+		ORDER BY operation_name, span_kind
+		LIMIT ? OFFSET ?`
+)
+
+var (
+	// ErrServiceNameNotSet occurs when attempting to query with an empty service name
+	ErrServiceNameNotSet = errors.New("service Name must be set")
+
+	// ErrStartTimeMinGreaterThanMax occurs when start time min is above start time max
+	ErrStartTimeMinGreaterThanMax = errors.New("start Time Minimum is above Maximum")
+
+	// ErrDurationMinGreaterThanMax occurs when duration min is above duration max
+	ErrDurationMinGreaterThanMax = errors.New("duration Minimum is above Maximum")
+
+	// ErrMalformedRequestObject occurs when a request object is nil
+	ErrMalformedRequestObject = errors.New("malformed request object")
+
+	// ErrStartAndEndTimeNotSet occurs when start time and end time are not set
+	ErrStartAndEndTimeNotSet = errors.New("start and End Time must be set")
+)
+
+// SpanReader reads spans from SQLite. Implements spanstore.Reader
+type SpanReader struct {
+	db *sql.DB
+}
+
+// NewSpanReader creates a SpanReader.
+func NewSpanReader(db *sql.DB) *SpanReader {
+	return &SpanReader{db: db}
+}
+
+// GetTrace takes a traceID and returns a Trace associated with that traceID
+func (r *SpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	rows, err := r.db.QueryContext(ctx, queryTrace, traceIDBytes(traceID))
+	if err != nil {
+		return nil, err
+	}
+	spans, err := scanSpans(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return &model.Trace{Spans: spans}, nil
+}
+
+// GetServices returns all services traced by Jaeger
+func (r *SpanReader) GetServices(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, queryServices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var services []string
+	for rows.Next() {
+		var service string
+		if err := rows.Scan(&service); err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+	return services, rows.Err()
+}
+
+// GetOperations returns all operations for a specific service traced by Jaeger
+func (r *SpanReader) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	// a negative limit is no limit
+	limit := -1
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+	rows, err := r.db.QueryContext(ctx, queryOperations,
+		query.ServiceName, query.SpanKind, query.SpanKind, query.NamePrefix, query.NamePrefix, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	operations := []spanstore.Operation{}
+	for rows.Next() {
+		var operation spanstore.Operation
+		if err := rows.Scan(&operation.Name, &operation.SpanKind); err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	return operations, rows.Err()
+}
+
+// FindTraces retrieves traces that match the traceQuery
+func (r *SpanReader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	page, err := r.FindTracesPage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return page.Traces, nil
+}
+
+// FindTracesPage implements spanstore.PaginatedReader#FindTracesPage
+func (r *SpanReader) FindTracesPage(ctx context.Context, query *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	traceIDs, nextPageToken, err := r.findTraceIDsPage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(traceIDs) == 0 {
+		return &spanstore.TracesPage{}, nil
+	}
+	args := make([]any, len(traceIDs))
+	for i, traceID := range traceIDs {
+		args[i] = traceIDBytes(traceID)
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT span FROM spans WHERE trace_id IN (?`+strings.Repeat(", ?", len(args)-1)+`) ORDER BY start_time`, args...)
+	if err != nil {
+		return nil, err
+	}
+	spans, err := scanSpans(rows)
+	if err != nil {
+		return nil, err
+	}
+	traces := make(map[model.TraceID]*model.Trace, len(traceIDs))
+	for _, span := range spans {
+		trace, ok := traces[span.TraceID]
+		if !ok {
+			trace = &model.Trace{}
+			traces[span.TraceID] = trace
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	// the traces are returned in the order of their IDs, the most recent first
+	result := make([]*model.Trace, 0, len(traces))
+	for _, traceID := range traceIDs {
+		if trace, ok := traces[traceID]; ok {
+			result = append(result, trace)
+		}
+	}
+	return &spanstore.TracesPage{Traces: result, NextPageToken: nextPageToken}, nil
+}
+
+// FindTraceIDs retrieves the IDs of the traces that match the traceQuery
+func (r *SpanReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	traceIDs, _, err := r.findTraceIDsPage(ctx, query)
+	return traceIDs, err
+}
+
+// findTraceIDsPage returns the page of the trace IDs matching the query and the token of the next page.
+func (r *SpanReader) findTraceIDsPage(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, string, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, "", err
+	}
+	numTraces := query.NumTraces
+	if numTraces <= 0 {
+		numTraces = defaultNumTraces
+	}
+	cursor, err := spanstore.DecodeTraceCursor(query.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	// one more trace ID is read to know whether there is a next page
+	sql, args := buildFindTraceIDsQuery(query, cursor, numTraces+1)
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var cursors []spanstore.TraceCursor
+	for rows.Next() {
+		var id []byte
+		var startTime int64
+		if err := rows.Scan(&id, &startTime); err != nil {
+			return nil, "", err
+		}
+		traceID, err := traceIDFromBytes(id)
+		if err != nil {
+			return nil, "", err
+		}
+		cursors = append(cursors, spanstore.TraceCursor{
+			StartTime: model.EpochMicrosecondsAsTime(uint64(startTime)),
+			TraceID:   traceID,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	var nextPageToken string
+	if len(cursors) > numTraces {
+		cursors = cursors[:numTraces]
+		nextPageToken = spanstore.EncodeTraceCursor(cursors[numTraces-1])
+	}
+	traceIDs := make([]model.TraceID, len(cursors))
+	for i, c := range cursors {
+		traceIDs[i] = c.TraceID
+	}
+	return traceIDs, nextPageToken, nil
+}
+
+func validateQuery(p *spanstore.TraceQueryParameters) error {
+	if p == nil {
+		return ErrMalformedRequestObject
+	}
+	if p.ServiceName == "" && len(p.Tags) > 0 {
+		return ErrServiceNameNotSet
+	}
+	if p.StartTimeMin.IsZero() || p.StartTimeMax.IsZero() {
+		return ErrStartAndEndTimeNotSet
+	}
+	if p.StartTimeMax.Before(p.StartTimeMin) {
+		return ErrStartTimeMinGreaterThanMax
+	}
+	if p.DurationMin != 0 && p.DurationMax != 0 && p.DurationMin > p.DurationMax {
+		return ErrDurationMinGreaterThanMax
+	}
+	return nil
+}
+
+func scanSpans(rows *sql.Rows) ([]*model.Span, error) {
+	defer rows.Close()
+	var spans []*model.Span
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, err
+		}
+		span := &model.Span{}
+		if err := span.Unmarshal(payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal a span: %w", err)
+		}
+		spans = append(spans, span)
+	}
+	return spans, rows.Err()
+}
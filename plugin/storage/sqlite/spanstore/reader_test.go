@@ -0,0 +1,351 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var testStartTime = time.Unix(1700000000, 0)
+
+// writeTraces writes the traces 1 to 3, started a second apart, the trace 2 has two spans.
+func writeTraces(t *testing.T) *SpanReader {
+	db := newTestDB(t)
+	writer := NewSpanWriter(db)
+	for _, span := range []*model.Span{
+		testSpan(1, 1, testStartTime),
+		testSpan(2, 1, testStartTime.Add(time.Second)),
+		testSpan(2, 2, testStartTime.Add(2*time.Second)),
+		testSpan(3, 1, testStartTime.Add(3*time.Second)),
+	} {
+		require.NoError(t, writer.WriteSpan(context.Background(), span))
+	}
+	backend := testSpan(3, 2, testStartTime.Add(3*time.Second))
+	backend.Process = model.NewProcess("backend", []model.KeyValue{model.String("hostname", "host2")})
+	backend.OperationName = "query"
+	backend.Tags = []model.KeyValue{model.String("span.kind", "client"), model.Bool("error", true)}
+	backend.Duration = time.Second
+	require.NoError(t, writer.WriteSpan(context.Background(), backend))
+	return NewSpanReader(db)
+}
+
+func testQuery() *spanstore.TraceQueryParameters {
+	return &spanstore.TraceQueryParameters{
+		StartTimeMin: testStartTime,
+		StartTimeMax: testStartTime.Add(time.Hour),
+	}
+}
+
+func traceIDsOf(traces []*model.Trace) []uint64 {
+	var ids []uint64
+	for _, trace := range traces {
+		ids = append(ids, trace.Spans[0].TraceID.Low)
+	}
+	return ids
+}
+
+func TestGetTrace(t *testing.T) {
+	reader := writeTraces(t)
+	trace, err := reader.GetTrace(context.Background(), model.NewTraceID(0, 2))
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 2)
+	assert.Equal(t, model.NewSpanID(1), trace.Spans[0].SpanID)
+	assert.Equal(t, model.NewSpanID(2), trace.Spans[1].SpanID)
+	assert.Equal(t, "frontend", trace.Spans[0].Process.ServiceName)
+
+	_, err = reader.GetTrace(context.Background(), model.NewTraceID(0, 4))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+func TestGetServicesAndOperations(t *testing.T) {
+	reader := writeTraces(t)
+	services, err := reader.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "frontend"}, services)
+
+	testCases := []struct {
+		name     string
+		query    spanstore.OperationQueryParameters
+		expected []spanstore.Operation
+	}{
+		{
+			name:     "all",
+			query:    spanstore.OperationQueryParameters{ServiceName: "backend"},
+			expected: []spanstore.Operation{{Name: "query", SpanKind: "client"}},
+		},
+		{
+			name:     "span kind",
+			query:    spanstore.OperationQueryParameters{ServiceName: "backend", SpanKind: "server"},
+			expected: []spanstore.Operation{},
+		},
+		{
+			name:     "prefix",
+			query:    spanstore.OperationQueryParameters{ServiceName: "frontend", NamePrefix: "GET"},
+			expected: []spanstore.Operation{{Name: "GET /", SpanKind: "server"}},
+		},
+		{
+			name:     "offset",
+			query:    spanstore.OperationQueryParameters{ServiceName: "frontend", Offset: 1, Limit: 1},
+			expected: []spanstore.Operation{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			operations, err := reader.GetOperations(context.Background(), tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, operations)
+		})
+	}
+}
+
+func TestFindTraces(t *testing.T) {
+	reader := writeTraces(t)
+	testCases := []struct {
+		name     string
+		query    func(q *spanstore.TraceQueryParameters)
+		expected []uint64
+	}{
+		{
+			name:     "all",
+			expected: []uint64{3, 2, 1},
+		},
+		{
+			name:     "time range",
+			query:    func(q *spanstore.TraceQueryParameters) { q.StartTimeMax = testStartTime.Add(time.Second) },
+			expected: []uint64{2, 1},
+		},
+		{
+			name:     "service",
+			query:    func(q *spanstore.TraceQueryParameters) { q.ServiceName = "backend" },
+			expected: []uint64{3},
+		},
+		{
+			name:     "operation",
+			query:    func(q *spanstore.TraceQueryParameters) { q.ServiceName = "frontend"; q.OperationName = "query" },
+			expected: nil,
+		},
+		{
+			name: "duration",
+			query: func(q *spanstore.TraceQueryParameters) {
+				q.DurationMin = time.Millisecond
+				q.DurationMax = 10 * time.Millisecond
+			},
+			expected: []uint64{3, 2, 1},
+		},
+		{
+			name:     "min duration",
+			query:    func(q *spanstore.TraceQueryParameters) { q.DurationMin = 10 * time.Millisecond },
+			expected: []uint64{3},
+		},
+		{
+			name: "tags",
+			query: func(q *spanstore.TraceQueryParameters) {
+				q.ServiceName = "frontend"
+				q.Tags = map[string]string{"http.status_code": "200", "hostname": "host1", "event": "done"}
+			},
+			expected: []uint64{3, 2, 1},
+		},
+		{
+			name: "tags of different spans",
+			query: func(q *spanstore.TraceQueryParameters) {
+				q.ServiceName = "backend"
+				q.Tags = map[string]string{"error": "true", "http.status_code": "200"}
+			},
+			expected: nil,
+		},
+		{
+			name:     "resource attributes",
+			query:    func(q *spanstore.TraceQueryParameters) { q.ResourceAttributes = map[string]string{"hostname": "host2"} },
+			expected: []uint64{3},
+		},
+		{
+			name:     "tag as resource attribute",
+			query:    func(q *spanstore.TraceQueryParameters) { q.ResourceAttributes = map[string]string{"error": "true"} },
+			expected: nil,
+		},
+		{
+			name: "log fields",
+			query: func(q *spanstore.TraceQueryParameters) {
+				q.LogFields = map[string]string{"event": "retry", "attempt": "1"}
+			},
+			expected: []uint64{3, 2, 1},
+		},
+		{
+			name: "fields of different logs",
+			query: func(q *spanstore.TraceQueryParameters) {
+				q.LogFields = map[string]string{"event": "retry", "attempt": "2"}
+			},
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := testQuery()
+			if tc.query != nil {
+				tc.query(query)
+			}
+			traces, err := reader.FindTraces(context.Background(), query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, traceIDsOf(traces))
+		})
+	}
+}
+
+func TestFindTracesSpans(t *testing.T) {
+	reader := writeTraces(t)
+	query := testQuery()
+	query.StartTimeMin = testStartTime.Add(time.Second)
+	query.StartTimeMax = testStartTime.Add(time.Second)
+	traces, err := reader.FindTraces(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	// all the spans of the trace, not only the matching ones
+	assert.Len(t, traces[0].Spans, 2)
+}
+
+func TestFindTraceIDsPagination(t *testing.T) {
+	reader := writeTraces(t)
+	query := testQuery()
+	query.NumTraces = 2
+	traceIDs, nextPageToken, err := reader.findTraceIDsPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 3), model.NewTraceID(0, 2)}, traceIDs)
+	cursor := spanstore.TraceCursor{StartTime: testStartTime.Add(2 * time.Second).UTC(), TraceID: model.NewTraceID(0, 2)}
+	assert.Equal(t, spanstore.EncodeTraceCursor(cursor), nextPageToken)
+
+	query.PageToken = nextPageToken
+	traceIDs, nextPageToken, err = reader.findTraceIDsPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+	assert.Empty(t, nextPageToken)
+}
+
+func TestFindTraceIDsPaginationWithNewTraces(t *testing.T) {
+	reader := writeTraces(t)
+	query := testQuery()
+	query.NumTraces = 2
+	traceIDs, nextPageToken, err := reader.findTraceIDsPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 3), model.NewTraceID(0, 2)}, traceIDs)
+
+	// the traces written after the first page are in the next page only if they are older
+	// than its last trace, and the traces of the first page are not repeated
+	writer := NewSpanWriter(reader.db)
+	for _, span := range []*model.Span{
+		testSpan(4, 1, testStartTime.Add(4*time.Second)),
+		testSpan(5, 1, testStartTime.Add(2*time.Second)),
+		testSpan(1, 2, testStartTime.Add(time.Second)),
+		testSpan(2, 3, testStartTime.Add(-time.Second)),
+	} {
+		require.NoError(t, writer.WriteSpan(context.Background(), span))
+	}
+	query.PageToken = nextPageToken
+	traceIDs, nextPageToken, err = reader.findTraceIDsPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 5), model.NewTraceID(0, 1)}, traceIDs)
+	assert.Empty(t, nextPageToken)
+}
+
+func TestFindTracesPage(t *testing.T) {
+	reader := writeTraces(t)
+	query := testQuery()
+	query.NumTraces = 2
+	page, err := reader.FindTracesPage(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, page.Traces, 2)
+	assert.Equal(t, model.NewTraceID(0, 3), page.Traces[0].Spans[0].TraceID)
+	assert.NotEmpty(t, page.NextPageToken)
+
+	query.PageToken = page.NextPageToken
+	page, err = reader.FindTracesPage(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, page.Traces, 1)
+	assert.Equal(t, model.NewTraceID(0, 1), page.Traces[0].Spans[0].TraceID)
+	assert.Empty(t, page.NextPageToken)
+}
+
+func TestFindTraceIDsErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    func(q *spanstore.TraceQueryParameters)
+		expected error
+	}{
+		{
+			name:     "nil query",
+			expected: ErrMalformedRequestObject,
+		},
+		{
+			name:     "tags without service",
+			query:    func(q *spanstore.TraceQueryParameters) { q.Tags = map[string]string{"k": "v"} },
+			expected: ErrServiceNameNotSet,
+		},
+		{
+			name:     "no start time",
+			query:    func(q *spanstore.TraceQueryParameters) { q.StartTimeMin = time.Time{} },
+			expected: ErrStartAndEndTimeNotSet,
+		},
+		{
+			name:     "start time min above max",
+			query:    func(q *spanstore.TraceQueryParameters) { q.StartTimeMin = q.StartTimeMax.Add(time.Second) },
+			expected: ErrStartTimeMinGreaterThanMax,
+		},
+		{
+			name:     "duration min above max",
+			query:    func(q *spanstore.TraceQueryParameters) { q.DurationMin = time.Second; q.DurationMax = time.Millisecond },
+			expected: ErrDurationMinGreaterThanMax,
+		},
+		{
+			name:     "invalid page token",
+			query:    func(q *spanstore.TraceQueryParameters) { q.PageToken = "invalid" },
+			expected: spanstore.ErrInvalidPageToken,
+		},
+	}
+	reader := NewSpanReader(newTestDB(t))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var query *spanstore.TraceQueryParameters
+			if tc.query != nil {
+				query = testQuery()
+				tc.query(query)
+			}
+			_, err := reader.FindTraceIDs(context.Background(), query)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	db := newTestDB(t)
+	_, err := db.Exec("INSERT INTO spans (trace_id, span_id, parent_span_id, service_name, operation_name, start_time, duration, span) " +
+		"VALUES (x'0102', 1, 0, 'frontend', 'GET /', 1700000000000000, 1, x'ff')")
+	require.NoError(t, err)
+	reader := NewSpanReader(db)
+	_, err = reader.GetTrace(context.Background(), model.TraceID{})
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	_, err = reader.FindTraceIDs(context.Background(), testQuery())
+	require.EqualError(t, err, "invalid trace ID of 2 bytes")
+
+	_, err = db.Exec("UPDATE spans SET trace_id = ?", traceIDBytes(model.NewTraceID(0, 1)))
+	require.NoError(t, err)
+	_, err = reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorContains(t, err, "failed to unmarshal a span")
+
+	require.NoError(t, db.Close())
+	_, err = reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.Error(t, err)
+	_, err = reader.GetServices(context.Background())
+	require.Error(t, err)
+	_, err = reader.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.Error(t, err)
+	_, err = reader.FindTraces(context.Background(), testQuery())
+	require.Error(t, err)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// The scopes of the rows of span_tags.
+const (
+	scopeSpan    = 0
+	scopeProcess = 1
+	scopeLog     = 2
+)
+
+const (
+	insertOperation = `INSERT OR IGNORE INTO operations (service_name, operation_name, span_kind) VALUES (?, ?, ?)`
+	insertSpan      = `
+		INSERT INTO spans (trace_id, span_id, parent_span_id, service_name, operation_name, start_time, duration, span)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	insertSpanTag = `INSERT INTO span_tags (span_ref, scope, log_index, key, value) VALUES (?, ?, ?, ?, ?)`
+)
+
+// SpanWriter writes spans to SQLite. Implements spanstore.Writer
+type SpanWriter struct {
+	db *sql.DB
+}
+
+// NewSpanWriter creates a SpanWriter.
+func NewSpanWriter(db *sql.DB) *SpanWriter {
+	return &SpanWriter{db: db}
+}
+
+// WriteSpan inserts the span, its operation and its tags in a single transaction.
+func (w *SpanWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	payload, err := span.Marshal()
+	if err != nil {
+		return err
+	}
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to insert the span: %w", err)
+	}
+	// a no-op once the transaction is committed
+	defer tx.Rollback()
+	if err := insert(ctx, tx, span, payload); err != nil {
+		return fmt.Errorf("failed to insert the span: %w", err)
+	}
+	return tx.Commit()
+}
+
+func insert(ctx context.Context, tx *sql.Tx, span *model.Span, payload []byte) error {
+	serviceName := span.Process.GetServiceName()
+	kind, _ := span.GetSpanKind()
+	if _, err := tx.ExecContext(ctx, insertOperation, serviceName, span.OperationName, kind.String()); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, insertSpan,
+		traceIDBytes(span.TraceID),
+		int64(span.SpanID),
+		int64(span.ParentSpanID()),
+		serviceName,
+		span.OperationName,
+		int64(model.TimeAsEpochMicroseconds(span.StartTime)),
+		int64(model.DurationAsMicroseconds(span.Duration)),
+		payload)
+	if err != nil {
+		return err
+	}
+	spanRef, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, insertSpanTag)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	insertTags := func(scope, logIndex int, tags []model.KeyValue) error {
+		for i := range tags {
+			if _, err := stmt.ExecContext(ctx, spanRef, scope, logIndex, tags[i].Key, tags[i].AsString()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := insertTags(scopeSpan, 0, span.Tags); err != nil {
+		return err
+	}
+	if span.Process != nil {
+		if err := insertTags(scopeProcess, 0, span.Process.Tags); err != nil {
+			return err
+		}
+	}
+	for i := range span.Logs {
+		if err := insertTags(scopeLog, i, span.Logs[i].Fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceIDBytes returns the value of the trace_id column, the 16 bytes of the trace ID in big endian order.
+func traceIDBytes(traceID model.TraceID) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, traceID.High)
+	binary.BigEndian.PutUint64(b[8:], traceID.Low)
+	return b
+}
+
+func traceIDFromBytes(id []byte) (model.TraceID, error) {
+	if len(id) != 16 {
+		return model.TraceID{}, fmt.Errorf("invalid trace ID of %d bytes", len(id))
+	}
+	return model.NewTraceID(binary.BigEndian.Uint64(id), binary.BigEndian.Uint64(id[8:])), nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/sqlite/schema"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "jaeger.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, schema.Migrate(context.Background(), db))
+	return db
+}
+
+// testSpan has tags, process tags and logs.
+func testSpan(traceID, spanID uint64, startTime time.Time) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: "GET /",
+		StartTime:     startTime,
+		Duration:      time.Millisecond,
+		Tags: []model.KeyValue{
+			model.String("span.kind", "server"),
+			model.Int64("http.status_code", 200),
+		},
+		Process: model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "host1")}),
+		Logs: []model.Log{
+			{
+				Timestamp: startTime,
+				Fields:    []model.KeyValue{model.String("event", "retry"), model.Int64("attempt", 1)},
+			},
+			{
+				Timestamp: startTime,
+				Fields:    []model.KeyValue{model.String("event", "done"), model.Int64("attempt", 2)},
+			},
+		},
+	}
+}
+
+func count(t *testing.T, db *sql.DB, table string) int {
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&n))
+	return n
+}
+
+func TestWriteSpan(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewSpanWriter(db)
+	span := testSpan(1, 2, time.Unix(1700000000, 0))
+	span.References = []model.SpanRef{model.NewChildOfRef(span.TraceID, model.NewSpanID(1))}
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+	require.NoError(t, writer.WriteSpan(context.Background(), testSpan(2, 3, time.Unix(1700000000, 0))))
+
+	assert.Equal(t, 2, count(t, db, "spans"))
+	assert.Equal(t, 1, count(t, db, "operations"))
+	// 2 tags, 1 process tag and 4 log fields per span
+	assert.Equal(t, 14, count(t, db, "span_tags"))
+
+	var parentSpanID, startTime, duration int64
+	require.NoError(t, db.QueryRow("SELECT parent_span_id, start_time, duration FROM spans WHERE span_id = 2").
+		Scan(&parentSpanID, &startTime, &duration))
+	assert.Equal(t, int64(1), parentSpanID)
+	assert.Equal(t, int64(1700000000000000), startTime)
+	assert.Equal(t, int64(1000), duration)
+
+	var kind string
+	require.NoError(t, db.QueryRow("SELECT span_kind FROM operations").Scan(&kind))
+	assert.Equal(t, "server", kind)
+}
+
+func TestWriteSpanError(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, db.Close())
+	err := NewSpanWriter(db).WriteSpan(context.Background(), testSpan(1, 2, time.Now()))
+	require.ErrorContains(t, err, "failed to insert the span")
+}
@@ -159,9 +159,14 @@ func (m *RateLimitingSamplingStrategy) GetMaxTracesPerSecond() int32 {
 type OperationSamplingStrategy struct {
 	Operation             string                         `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
 	ProbabilisticSampling *ProbabilisticSamplingStrategy `protobuf:"bytes,2,opt,name=probabilisticSampling,proto3" json:"probabilisticSampling,omitempty"`
-	XXX_NoUnkeyedLiteral  struct{}                       `json:"-"`
-	XXX_unrecognized      []byte                         `json:"-"`
-	XXX_sizecache         int32                          `json:"-"`
+	// Optional span kind ("server", "client", "producer", "consumer" or "internal")
+	// of the spans the strategy applies to, the strategy applying to the spans of
+	// all kinds of the operation if empty. SDKs ignoring this field use the last
+	// strategy of the operation.
+	SpanKind             string   `protobuf:"bytes,3,opt,name=spanKind,proto3" json:"spanKind,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *OperationSamplingStrategy) Reset()         { *m = OperationSamplingStrategy{} }
@@ -211,6 +216,13 @@ func (m *OperationSamplingStrategy) GetProbabilisticSampling() *ProbabilisticSam
 	return nil
 }
 
+func (m *OperationSamplingStrategy) GetSpanKind() string {
+	if m != nil {
+		return m.SpanKind
+	}
+	return ""
+}
+
 // PerOperationSamplingStrategies is a combination of strategies for different endpoints
 // as well as some service-wide defaults. It is particularly useful for services whose
 // endpoints receive vastly different traffic, so that any single rate of sampling would
@@ -441,43 +453,44 @@ func init() {
 func init() { proto.RegisterFile("sampling.proto", fileDescriptor_79c798842d009798) }
 
 var fileDescriptor_79c798842d009798 = []byte{
-	// 569 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0x41, 0x6f, 0x12, 0x41,
-	0x14, 0x76, 0x40, 0x9b, 0xf4, 0xd1, 0xd6, 0x76, 0xac, 0xba, 0x12, 0x4a, 0xc8, 0xf6, 0x20, 0x56,
-	0x0b, 0xc9, 0x7a, 0x33, 0xa6, 0x49, 0x69, 0x0c, 0x59, 0x43, 0x29, 0x59, 0xf0, 0xa2, 0x07, 0x1c,
-	0xe0, 0xb9, 0x19, 0x03, 0x3b, 0x9b, 0x99, 0x29, 0xca, 0xd5, 0xc4, 0xab, 0x17, 0xcf, 0x5e, 0xfc,
-	0x35, 0x1e, 0x4d, 0xbc, 0x79, 0x32, 0xc4, 0x1f, 0x62, 0x76, 0x59, 0x5a, 0x58, 0x16, 0xb8, 0x79,
-	0xda, 0xcd, 0x7b, 0xdf, 0x7c, 0xdf, 0xf7, 0xde, 0xbc, 0x79, 0xb0, 0xa3, 0xd8, 0xc0, 0xef, 0x73,
-	0xcf, 0x2d, 0xf9, 0x52, 0x68, 0x41, 0xb7, 0xdf, 0x33, 0x74, 0x51, 0x96, 0x98, 0xcf, 0xdb, 0x43,
-	0x2b, 0xbb, 0xef, 0x0a, 0x57, 0x84, 0x99, 0x72, 0xf0, 0x37, 0x01, 0x65, 0x73, 0xae, 0x10, 0x6e,
-	0x1f, 0xcb, 0xcc, 0xe7, 0x65, 0xe6, 0x79, 0x42, 0x33, 0xcd, 0x85, 0xa7, 0x26, 0x59, 0xf3, 0x0c,
-	0x0e, 0x1a, 0x52, 0x74, 0x58, 0x87, 0xf7, 0xb9, 0xd2, 0xbc, 0xdb, 0x8c, 0x14, 0x9a, 0x5a, 0x32,
-	0x8d, 0xee, 0x88, 0x9a, 0xb0, 0x35, 0x55, 0x75, 0x98, 0x46, 0x83, 0x14, 0x48, 0x91, 0x38, 0x73,
-	0x31, 0xb3, 0x0e, 0xb9, 0xe0, 0x5b, 0xe3, 0x03, 0xae, 0x83, 0xb3, 0x71, 0x8e, 0x12, 0xd0, 0x01,
-	0xfb, 0xd8, 0x92, 0xac, 0x8b, 0xaa, 0x81, 0xb2, 0x89, 0x5d, 0xe1, 0xf5, 0x42, 0xa6, 0x5b, 0x4e,
-	0x42, 0xc6, 0xfc, 0x46, 0xe0, 0xc1, 0x85, 0x8f, 0x32, 0x74, 0xba, 0xc0, 0x96, 0x83, 0x4d, 0x31,
-	0x4d, 0x86, 0x24, 0x9b, 0xce, 0x75, 0x80, 0x76, 0xe0, 0xae, 0x9f, 0x54, 0x90, 0x91, 0x2a, 0x90,
-	0x62, 0xc6, 0x7a, 0x52, 0x9a, 0xeb, 0x59, 0x69, 0x65, 0xf1, 0x4e, 0x32, 0x95, 0xf9, 0x3b, 0x05,
-	0xf9, 0x06, 0xca, 0x65, 0x16, 0x39, 0x2a, 0x7a, 0x02, 0xd9, 0x1e, 0xbe, 0x63, 0x97, 0x7d, 0x3d,
-	0x4d, 0x5e, 0x29, 0xe9, 0x51, 0xd4, 0xc4, 0x15, 0x08, 0xfa, 0x12, 0x0a, 0x51, 0xb6, 0x26, 0x3e,
-	0xa0, 0xac, 0x88, 0x4b, 0xaf, 0x17, 0x6f, 0x60, 0x2a, 0x64, 0x59, 0x8b, 0xa3, 0x6f, 0xe1, 0x9e,
-	0x3f, 0xeb, 0xf6, 0xca, 0xa5, 0x91, 0x2e, 0xa4, 0x8b, 0x19, 0xab, 0x18, 0xeb, 0xc9, 0xd2, 0xd6,
-	0x3b, 0x4b, 0x78, 0x66, 0xdc, 0xbe, 0xf2, 0xfd, 0x25, 0x6e, 0x6f, 0xce, 0xb9, 0x5d, 0x8a, 0x33,
-	0x3f, 0xa7, 0xc1, 0x58, 0x10, 0x46, 0xe5, 0x0b, 0x4f, 0x21, 0xad, 0xc2, 0x96, 0x8a, 0x62, 0xad,
-	0x91, 0x3f, 0x99, 0xc6, 0x1d, 0xeb, 0x30, 0x56, 0x40, 0xfc, 0x78, 0x00, 0x75, 0xe6, 0x0e, 0xfe,
-	0x8f, 0x31, 0xa1, 0x6d, 0xd8, 0x97, 0x09, 0xcf, 0xc2, 0x48, 0x87, 0x12, 0x8f, 0x63, 0x12, 0xab,
-	0x5e, 0x90, 0x93, 0x48, 0x44, 0xdf, 0xc0, 0x9e, 0x88, 0xdf, 0x55, 0xd8, 0xe7, 0x8c, 0x75, 0x1c,
-	0x2f, 0x60, 0xe5, 0xb8, 0x3a, 0x8b, 0x3c, 0xe6, 0x09, 0x64, 0xe3, 0x36, 0x1a, 0x4c, 0xb2, 0x01,
-	0x6a, 0x94, 0x8a, 0x16, 0x20, 0xa3, 0x50, 0x0e, 0x79, 0x17, 0xeb, 0x6c, 0x80, 0xd1, 0x33, 0x9c,
-	0x0d, 0x1d, 0x3d, 0x87, 0xfd, 0xa4, 0x7b, 0xa0, 0x7b, 0xb0, 0xdd, 0x70, 0x2e, 0x2a, 0xa7, 0x15,
-	0xbb, 0x66, 0x37, 0x5b, 0xf6, 0xd9, 0xee, 0x8d, 0x20, 0xe4, 0x9c, 0xb6, 0x5e, 0xb4, 0x6b, 0xf6,
-	0xb9, 0xdd, 0xb2, 0xeb, 0xd5, 0x5d, 0x62, 0x7d, 0x27, 0x70, 0x7b, 0x7a, 0xfc, 0x9c, 0x79, 0xcc,
-	0x45, 0x49, 0xbf, 0x10, 0xb8, 0x53, 0x45, 0xbd, 0xb0, 0x10, 0x1e, 0xad, 0xb9, 0xfe, 0x6b, 0xdb,
-	0xd9, 0x87, 0x6b, 0xa0, 0xd3, 0x41, 0x33, 0x0f, 0x3f, 0xfd, 0xfa, 0xfb, 0x35, 0x75, 0x60, 0x1a,
-	0xe1, 0xde, 0x1c, 0x5a, 0x65, 0x15, 0x43, 0x3e, 0x23, 0x47, 0x95, 0xe3, 0x1f, 0xe3, 0x3c, 0xf9,
-	0x39, 0xce, 0x93, 0x3f, 0xe3, 0x3c, 0x81, 0xfb, 0x5c, 0x44, 0xec, 0x5a, 0xb2, 0x6e, 0xb0, 0xa5,
-	0x27, 0x22, 0xaf, 0x37, 0x26, 0xdf, 0xce, 0x46, 0xb8, 0x72, 0x9f, 0xfe, 0x0b, 0x00, 0x00, 0xff,
-	0xff, 0xf1, 0xcc, 0x49, 0x9b, 0xc7, 0x05, 0x00, 0x00,
+	// 585 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0x4f, 0x6f, 0x12, 0x41,
+	0x14, 0x77, 0x40, 0x1b, 0xfb, 0x68, 0x6b, 0x3b, 0x56, 0x5d, 0x37, 0x94, 0x90, 0xed, 0x41, 0xac,
+	0x16, 0x92, 0xf5, 0x66, 0x4c, 0x93, 0xd2, 0x18, 0xb2, 0x4a, 0x29, 0x59, 0xf0, 0xa2, 0x07, 0x1c,
+	0x60, 0xdc, 0x8c, 0x81, 0x9d, 0xc9, 0xcc, 0x14, 0xe5, 0x6a, 0xe2, 0xd5, 0x8b, 0xdf, 0xc0, 0x6f,
+	0xe1, 0x37, 0xf0, 0x68, 0xe2, 0xcd, 0x93, 0x21, 0x7e, 0x10, 0xb3, 0xcb, 0x42, 0x61, 0x59, 0xe0,
+	0xe6, 0x69, 0xe1, 0xbd, 0xdf, 0xfb, 0xbd, 0xdf, 0xfb, 0x33, 0x0f, 0x76, 0x14, 0xe9, 0x8b, 0x1e,
+	0xf3, 0xbd, 0xa2, 0x90, 0x5c, 0x73, 0xbc, 0xfd, 0x9e, 0x50, 0x8f, 0xca, 0x22, 0x11, 0xac, 0x35,
+	0xb0, 0xcd, 0x7d, 0x8f, 0x7b, 0x3c, 0xf4, 0x94, 0x82, 0x5f, 0x63, 0x90, 0x99, 0xf5, 0x38, 0xf7,
+	0x7a, 0xb4, 0x44, 0x04, 0x2b, 0x11, 0xdf, 0xe7, 0x9a, 0x68, 0xc6, 0x7d, 0x35, 0xf6, 0x5a, 0x67,
+	0x70, 0x50, 0x97, 0xbc, 0x4d, 0xda, 0xac, 0xc7, 0x94, 0x66, 0x9d, 0x46, 0x94, 0xa1, 0xa1, 0x25,
+	0xd1, 0xd4, 0x1b, 0x62, 0x0b, 0xb6, 0x26, 0x59, 0x5d, 0xa2, 0xa9, 0x81, 0xf2, 0xa8, 0x80, 0xdc,
+	0x39, 0x9b, 0x55, 0x83, 0x6c, 0xf0, 0xad, 0xb2, 0x3e, 0xd3, 0x41, 0x6c, 0x9c, 0xa3, 0x08, 0xb8,
+	0x4f, 0x3e, 0x36, 0x25, 0xe9, 0x50, 0x55, 0xa7, 0xb2, 0x41, 0x3b, 0xdc, 0xef, 0x86, 0x4c, 0x37,
+	0xdc, 0x04, 0x8f, 0xf5, 0x1d, 0xc1, 0xfd, 0x0b, 0x41, 0x65, 0xa8, 0x74, 0x81, 0x2d, 0x0b, 0x9b,
+	0x7c, 0xe2, 0x0c, 0x49, 0x36, 0xdd, 0x2b, 0x03, 0x6e, 0xc3, 0x1d, 0x91, 0x54, 0x90, 0x91, 0xca,
+	0xa3, 0x42, 0xc6, 0x7e, 0x5c, 0x9c, 0xeb, 0x59, 0x71, 0x65, 0xf1, 0x6e, 0x32, 0x15, 0x36, 0xe1,
+	0xa6, 0x12, 0xc4, 0x7f, 0xc9, 0xfc, 0xae, 0x91, 0x0e, 0x05, 0x4c, 0xff, 0x5b, 0xbf, 0x53, 0x90,
+	0xab, 0x53, 0xb9, 0x4c, 0x3e, 0xa3, 0x0a, 0x9f, 0x80, 0xd9, 0xa5, 0xef, 0xc8, 0x65, 0x4f, 0x4f,
+	0x9c, 0x53, 0x15, 0x7a, 0x18, 0x35, 0x78, 0x05, 0x02, 0xbf, 0x80, 0x7c, 0xe4, 0xad, 0xf2, 0x0f,
+	0x54, 0x96, 0xf9, 0xa5, 0xdf, 0x8d, 0x37, 0x37, 0x15, 0xb2, 0xac, 0xc5, 0xe1, 0xb7, 0x70, 0x57,
+	0xcc, 0xaa, 0x9d, 0xaa, 0x34, 0xd2, 0xf9, 0x74, 0x21, 0x63, 0x17, 0x62, 0xfd, 0x5a, 0x3a, 0x16,
+	0x77, 0x09, 0xcf, 0x8c, 0xda, 0x57, 0x42, 0x2c, 0x51, 0x7b, 0x7d, 0x4e, 0xed, 0x52, 0x9c, 0xf5,
+	0x39, 0x0d, 0xc6, 0x42, 0x62, 0xaa, 0x04, 0xf7, 0x15, 0xc5, 0x15, 0xd8, 0x52, 0x91, 0xad, 0x39,
+	0x14, 0xe3, 0x4d, 0xdd, 0xb1, 0x0f, 0x63, 0x05, 0xc4, 0xc3, 0x03, 0xa8, 0x3b, 0x17, 0xf8, 0x5f,
+	0x56, 0xa8, 0x05, 0xfb, 0x32, 0xe1, 0xc9, 0x84, 0xeb, 0x94, 0xb1, 0x1f, 0xc5, 0x52, 0xac, 0x7a,
+	0x5d, 0x6e, 0x22, 0x11, 0x7e, 0x03, 0x7b, 0x3c, 0x3e, 0xab, 0xb0, 0xcf, 0x19, 0xfb, 0x38, 0x5e,
+	0xc0, 0xca, 0x75, 0x75, 0x17, 0x79, 0xac, 0x13, 0x30, 0xe3, 0x32, 0xea, 0x44, 0x92, 0x3e, 0xd5,
+	0x54, 0x2a, 0x9c, 0x87, 0x8c, 0xa2, 0x72, 0xc0, 0x3a, 0xb4, 0x46, 0xfa, 0x34, 0x7a, 0xa2, 0xb3,
+	0xa6, 0xa3, 0x67, 0xb0, 0x9f, 0x34, 0x07, 0xbc, 0x07, 0xdb, 0x75, 0xf7, 0xa2, 0x7c, 0x5a, 0x76,
+	0xaa, 0x4e, 0xa3, 0xe9, 0x9c, 0xed, 0x5e, 0x0b, 0x4c, 0xee, 0x69, 0xf3, 0x79, 0xab, 0xea, 0x9c,
+	0x3b, 0x4d, 0xa7, 0x56, 0xd9, 0x45, 0xf6, 0x37, 0x04, 0xb7, 0x26, 0xe1, 0xe7, 0xc4, 0x27, 0x1e,
+	0x95, 0xf8, 0x0b, 0x82, 0xdb, 0x15, 0xaa, 0x17, 0x8e, 0xc5, 0xc3, 0x35, 0xe3, 0xbf, 0x92, 0x6d,
+	0x3e, 0x58, 0x03, 0x9d, 0x2c, 0x9a, 0x75, 0xf8, 0xe9, 0xd7, 0xdf, 0xaf, 0xa9, 0x03, 0xcb, 0x08,
+	0x6f, 0xea, 0xc0, 0x2e, 0xa9, 0x18, 0xf2, 0x29, 0x3a, 0x2a, 0x1f, 0xff, 0x18, 0xe5, 0xd0, 0xcf,
+	0x51, 0x0e, 0xfd, 0x19, 0xe5, 0x10, 0xdc, 0x63, 0x3c, 0x62, 0xd7, 0x92, 0x74, 0x82, 0x0b, 0x3e,
+	0x4e, 0xf2, 0x7a, 0x63, 0xfc, 0x6d, 0x6f, 0x84, 0xe7, 0xf8, 0xc9, 0xbf, 0x00, 0x00, 0x00, 0xff,
+	0xff, 0x0e, 0xa7, 0x82, 0xd7, 0xe3, 0x05, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -649,6 +662,13 @@ func (m *OperationSamplingStrategy) MarshalToSizedBuffer(dAtA []byte) (int, erro
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.SpanKind) > 0 {
+		i -= len(m.SpanKind)
+		copy(dAtA[i:], m.SpanKind)
+		i = encodeVarintSampling(dAtA, i, uint64(len(m.SpanKind)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if m.ProbabilisticSampling != nil {
 		{
 			size, err := m.ProbabilisticSampling.MarshalToSizedBuffer(dAtA[:i])
@@ -887,6 +907,10 @@ func (m *OperationSamplingStrategy) Size() (n int) {
 		l = m.ProbabilisticSampling.Size()
 		n += 1 + l + sovSampling(uint64(l))
 	}
+	l = len(m.SpanKind)
+	if l > 0 {
+		n += 1 + l + sovSampling(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1198,6 +1222,38 @@ func (m *OperationSamplingStrategy) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SpanKind", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSampling
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSampling
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthSampling
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SpanKind = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSampling(dAtA[iNdEx:])
@@ -0,0 +1,65 @@
+#!/bin/bash
+
+set -uxf -o pipefail
+
+usage() {
+  echo $"Usage: $0 <distribution>"
+  echo "  distribution: postgres or timescaledb"
+  exit 1
+}
+
+check_arg() {
+  if [ ! $# -eq 1 ]; then
+    echo "ERROR: need exactly one argument, <distribution>"
+    usage
+  fi
+}
+
+setup_postgres() {
+  local compose_file=$1
+  docker compose -f "$compose_file" up -d --wait
+  echo "docker_compose_file=${compose_file}" >> "${GITHUB_OUTPUT:-/dev/null}"
+}
+
+teardown_postgres() {
+  local compose_file=$1
+  docker compose -f "$compose_file" down
+  exit "${exit_status}"
+}
+
+run_integration_test() {
+  local distribution=$1
+  local compose_file="docker-compose/postgres/docker-compose.yaml"
+
+  case ${distribution} in
+    postgres)
+      export POSTGRES_IMAGE="postgres:16"
+      export POSTGRES_TIMESCALEDB="false"
+      ;;
+    timescaledb)
+      export POSTGRES_IMAGE="timescale/timescaledb:latest-pg16"
+      export POSTGRES_TIMESCALEDB="true"
+      ;;
+    *)
+      echo "Unknown distribution ${distribution}. Valid options are postgres or timescaledb"
+      usage
+      ;;
+  esac
+
+  setup_postgres "${compose_file}"
+
+  # shellcheck disable=SC2064
+  trap "teardown_postgres ${compose_file}" EXIT
+
+  STORAGE=postgres make storage-integration-test
+  exit_status=$?
+}
+
+main() {
+  check_arg "$@"
+
+  echo "Executing integration test for $1"
+  run_integration_test "$1"
+}
+
+main "$@"
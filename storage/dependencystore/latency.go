@@ -0,0 +1,104 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// ErrDependencyLatenciesNotSupported is returned by the query service if the dependency
+// storage does not implement LatencyReader.
+var ErrDependencyLatenciesNotSupported = errors.New("dependency latencies are not supported by dependency storage")
+
+// DependencyLatency is a model.DependencyLink with the distribution of the durations of its calls,
+// the spans of the child service whose parent span belongs to the parent service.
+type DependencyLatency struct {
+	Parent    string
+	Child     string
+	CallCount uint64
+	Latency   *spanstore.LatencyDistribution
+}
+
+// LatencyReader is an optional interface of the dependency readers that can compute the latencies
+// of the calls of the dependency links.
+type LatencyReader interface {
+	GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]DependencyLatency, error)
+}
+
+// LatencyWriter is an optional interface of the dependency writers that can store the latencies
+// of the calls of the dependency links.
+type LatencyWriter interface {
+	WriteDependencyLatencies(ts time.Time, latencies []DependencyLatency) error
+}
+
+type edge struct {
+	parent string
+	child  string
+}
+
+// LatencyAggregator computes the DependencyLatency of the calls of traces,
+// for the storage backends aggregating the dependency links from the spans.
+type LatencyAggregator struct {
+	bounds []time.Duration
+	// the calls are weighted by the adjusted counts of the sampled spans, to count the actual traffic
+	callCounts map[edge]float64
+	durations  map[edge][]time.Duration
+}
+
+// NewLatencyAggregator creates a LatencyAggregator with the histogram bucket bounds of the distributions,
+// spanstore.DefaultLatencyBucketBounds if empty.
+func NewLatencyAggregator(bounds []time.Duration) *LatencyAggregator {
+	if len(bounds) == 0 {
+		bounds = spanstore.DefaultLatencyBucketBounds
+	}
+	return &LatencyAggregator{
+		bounds:     bounds,
+		callCounts: make(map[edge]float64),
+		durations:  make(map[edge][]time.Duration),
+	}
+}
+
+// AddTrace adds the calls between the services of the trace.
+func (a *LatencyAggregator) AddTrace(trace *model.Trace) {
+	spans := make(map[model.SpanID]*model.Span, len(trace.Spans))
+	for _, s := range trace.Spans {
+		spans[s.SpanID] = s
+	}
+	for _, s := range trace.Spans {
+		parentSpan, ok := spans[s.ParentSpanID()]
+		if !ok || parentSpan.Process.ServiceName == s.Process.ServiceName {
+			continue
+		}
+		e := edge{parent: parentSpan.Process.ServiceName, child: s.Process.ServiceName}
+		a.callCounts[e] += s.GetAdjustedCount()
+		a.durations[e] = append(a.durations[e], s.Duration)
+	}
+}
+
+// Latencies returns the latencies of the dependency links of the added traces, ordered by parent and child.
+func (a *LatencyAggregator) Latencies() []DependencyLatency {
+	latencies := make([]DependencyLatency, 0, len(a.durations))
+	for e, durations := range a.durations {
+		latencies = append(latencies, DependencyLatency{
+			Parent:    e.parent,
+			Child:     e.child,
+			CallCount: uint64(math.Round(a.callCounts[e])),
+			Latency:   spanstore.ComputeLatencyDistribution(durations, a.bounds),
+		})
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		if latencies[i].Parent != latencies[j].Parent {
+			return latencies[i].Parent < latencies[j].Parent
+		}
+		return latencies[i].Child < latencies[j].Child
+	})
+	return latencies
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func newSpan(spanID, parentID uint64, service string, duration time.Duration, tags ...model.KeyValue) *model.Span {
+	traceID := model.NewTraceID(0, 1)
+	span := &model.Span{
+		TraceID:  traceID,
+		SpanID:   model.NewSpanID(spanID),
+		Duration: duration,
+		Process:  &model.Process{ServiceName: service},
+		Tags:     tags,
+	}
+	if parentID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parentID))}
+	}
+	return span
+}
+
+func TestLatencyAggregator(t *testing.T) {
+	bounds := []time.Duration{50 * time.Millisecond}
+	aggregator := NewLatencyAggregator(bounds)
+	aggregator.AddTrace(&model.Trace{Spans: []*model.Span{
+		newSpan(1, 0, "frontend", time.Second),
+		newSpan(2, 1, "frontend", 900*time.Millisecond),
+		newSpan(3, 2, "checkout", 100*time.Millisecond, model.Float64(model.AdjustedCountTag, 10)),
+		newSpan(4, 1, "inventory", 10*time.Millisecond),
+		// the parent is missing
+		newSpan(5, 9, "payment", 10*time.Millisecond),
+	}})
+	aggregator.AddTrace(&model.Trace{Spans: []*model.Span{
+		newSpan(1, 0, "frontend", time.Second),
+		newSpan(2, 1, "checkout", 20*time.Millisecond),
+	}})
+
+	latencies := aggregator.Latencies()
+	require.Len(t, latencies, 2)
+	assert.Equal(t, "frontend", latencies[0].Parent)
+	assert.Equal(t, "checkout", latencies[0].Child)
+	assert.Equal(t, uint64(11), latencies[0].CallCount)
+	assert.Equal(t, &spanstore.LatencyDistribution{
+		Count:        2,
+		P50:          20 * time.Millisecond,
+		P95:          100 * time.Millisecond,
+		P99:          100 * time.Millisecond,
+		BucketBounds: bounds,
+		BucketCounts: []int64{1, 1},
+	}, latencies[0].Latency)
+	assert.Equal(t, "inventory", latencies[1].Child)
+	assert.Equal(t, uint64(1), latencies[1].CallCount)
+}
+
+func TestLatencyAggregatorDefaultBounds(t *testing.T) {
+	aggregator := NewLatencyAggregator(nil)
+	aggregator.AddTrace(&model.Trace{Spans: []*model.Span{
+		newSpan(1, 0, "frontend", time.Second),
+		newSpan(2, 1, "checkout", 20*time.Millisecond),
+	}})
+	latencies := aggregator.Latencies()
+	require.Len(t, latencies, 1)
+	assert.Equal(t, spanstore.DefaultLatencyBucketBounds, latencies[0].Latency.BucketBounds)
+	assert.Empty(t, NewLatencyAggregator(nil).Latencies())
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
+)
+
+// ReadMetricsDecorator wraps a dependencystore.Reader and collects metrics around each operation.
+type ReadMetricsDecorator struct {
+	reader                   dependencystore.Reader
+	getDependenciesMetrics   *storageMetrics.QueryMetrics
+	getLatenciesMetrics      *storageMetrics.QueryMetrics
+	writeDependenciesMetrics *storageMetrics.QueryMetrics
+	writeLatenciesMetrics    *storageMetrics.QueryMetrics
+}
+
+// NewReadMetricsDecorator returns a new ReadMetricsDecorator, which implements the
+// dependencystore.LatencyReader, dependencystore.Writer and dependencystore.LatencyWriter
+// interfaces if the reader does, a dependencystore.LatencyWriter being only decorated
+// if it is also a dependencystore.Writer.
+func NewReadMetricsDecorator(reader dependencystore.Reader, metricsFactory metrics.Factory) dependencystore.Reader {
+	m := &ReadMetricsDecorator{
+		reader:                   reader,
+		getDependenciesMetrics:   storageMetrics.NewQueryMetrics("get_dependencies", metricsFactory),
+		getLatenciesMetrics:      storageMetrics.NewQueryMetrics("get_dependency_latencies", metricsFactory),
+		writeDependenciesMetrics: storageMetrics.NewQueryMetrics("write_dependencies", metricsFactory),
+		writeLatenciesMetrics:    storageMetrics.NewQueryMetrics("write_dependency_latencies", metricsFactory),
+	}
+	lr, isLatencyReader := reader.(dependencystore.LatencyReader)
+	w, isWriter := reader.(dependencystore.Writer)
+	lw, isLatencyWriter := reader.(dependencystore.LatencyWriter)
+	latencies, writes, latencyWrites := latencyReader{m, lr}, writer{m, w}, latencyWriter{m, lw}
+	switch {
+	case isLatencyReader && isWriter && isLatencyWriter:
+		return &struct {
+			*ReadMetricsDecorator
+			latencyReader
+			writer
+			latencyWriter
+		}{m, latencies, writes, latencyWrites}
+	case isLatencyReader && isWriter:
+		return &struct {
+			*ReadMetricsDecorator
+			latencyReader
+			writer
+		}{m, latencies, writes}
+	case isLatencyReader:
+		return &struct {
+			*ReadMetricsDecorator
+			latencyReader
+		}{m, latencies}
+	case isWriter && isLatencyWriter:
+		return &struct {
+			*ReadMetricsDecorator
+			writer
+			latencyWriter
+		}{m, writes, latencyWrites}
+	case isWriter:
+		return &struct {
+			*ReadMetricsDecorator
+			writer
+		}{m, writes}
+	default:
+		return m
+	}
+}
+
+// GetDependencies implements dependencystore.Reader#GetDependencies
+func (m *ReadMetricsDecorator) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	start := time.Now()
+	retMe, err := m.reader.GetDependencies(ctx, endTs, lookback)
+	m.getDependenciesMetrics.Emit(ctx, err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+type latencyReader struct {
+	m      *ReadMetricsDecorator
+	reader dependencystore.LatencyReader
+}
+
+// GetDependencyLatencies implements dependencystore.LatencyReader#GetDependencyLatencies
+func (d latencyReader) GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLatency, error) {
+	start := time.Now()
+	retMe, err := d.reader.GetDependencyLatencies(ctx, endTs, lookback)
+	d.m.getLatenciesMetrics.Emit(ctx, err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+type writer struct {
+	m      *ReadMetricsDecorator
+	writer dependencystore.Writer
+}
+
+// WriteDependencies implements dependencystore.Writer#WriteDependencies
+func (d writer) WriteDependencies(ts time.Time, dependencies []model.DependencyLink) error {
+	start := time.Now()
+	err := d.writer.WriteDependencies(ts, dependencies)
+	d.m.writeDependenciesMetrics.Emit(context.Background(), err, time.Since(start), len(dependencies))
+	return err
+}
+
+type latencyWriter struct {
+	m      *ReadMetricsDecorator
+	writer dependencystore.LatencyWriter
+}
+
+// WriteDependencyLatencies implements dependencystore.LatencyWriter#WriteDependencyLatencies
+func (d latencyWriter) WriteDependencyLatencies(ts time.Time, latencies []dependencystore.DependencyLatency) error {
+	start := time.Now()
+	err := d.writer.WriteDependencyLatencies(ts, latencies)
+	d.m.writeLatenciesMetrics.Emit(context.Background(), err, time.Since(start), len(latencies))
+	return err
+}
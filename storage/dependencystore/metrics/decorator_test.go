@@ -0,0 +1,123 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+)
+
+// fullStore implements all the optional interfaces of a dependency store.
+type fullStore struct {
+	mocks.Reader
+	latencies []dependencystore.DependencyLatency
+	written   []model.DependencyLink
+}
+
+func (s *fullStore) GetDependencyLatencies(context.Context, time.Time, time.Duration) ([]dependencystore.DependencyLatency, error) {
+	return s.latencies, nil
+}
+
+func (s *fullStore) WriteDependencies(_ time.Time, dependencies []model.DependencyLink) error {
+	s.written = append(s.written, dependencies...)
+	return nil
+}
+
+func (*fullStore) WriteDependencyLatencies(time.Time, []dependencystore.DependencyLatency) error {
+	return errors.New("Failure")
+}
+
+type latencyStore struct {
+	mocks.Reader
+}
+
+func (*latencyStore) GetDependencyLatencies(context.Context, time.Time, time.Duration) ([]dependencystore.DependencyLatency, error) {
+	return nil, nil
+}
+
+type writerStore struct {
+	mocks.Reader
+}
+
+func (*writerStore) WriteDependencies(time.Time, []model.DependencyLink) error {
+	return nil
+}
+
+func TestGetDependencies(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	endTs := time.Unix(0, 0)
+	links := []model.DependencyLink{{Parent: "a", Child: "b", CallCount: 1}}
+	reader := &mocks.Reader{}
+	reader.On("GetDependencies", context.Background(), endTs, time.Hour).Return(links, nil).Once()
+	reader.On("GetDependencies", context.Background(), endTs, time.Hour).Return(nil, context.DeadlineExceeded).Once()
+
+	d := NewReadMetricsDecorator(reader, mf)
+	actual, err := d.GetDependencies(context.Background(), endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, links, actual)
+	_, err = d.GetDependencies(context.Background(), endTs, time.Hour)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	counters, gauges := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_dependencies|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=get_dependencies|result=err"])
+	assert.EqualValues(t, 1, counters["errors|error=timeout|operation=get_dependencies"])
+	assert.Contains(t, gauges, "latency|operation=get_dependencies|result=ok.P50")
+}
+
+func TestOptionalInterfaces(t *testing.T) {
+	tests := []struct {
+		name          string
+		reader        dependencystore.Reader
+		latencyReader bool
+		writer        bool
+		latencyWriter bool
+	}{
+		{name: "reader", reader: &mocks.Reader{}},
+		{name: "latency reader", reader: &latencyStore{}, latencyReader: true},
+		{name: "writer", reader: &writerStore{}, writer: true},
+		{name: "all", reader: &fullStore{}, latencyReader: true, writer: true, latencyWriter: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewReadMetricsDecorator(test.reader, metricstest.NewFactory(0))
+			_, ok := d.(dependencystore.LatencyReader)
+			assert.Equal(t, test.latencyReader, ok)
+			_, ok = d.(dependencystore.Writer)
+			assert.Equal(t, test.writer, ok)
+			_, ok = d.(dependencystore.LatencyWriter)
+			assert.Equal(t, test.latencyWriter, ok)
+		})
+	}
+}
+
+func TestLatenciesAndWrites(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	store := &fullStore{latencies: []dependencystore.DependencyLatency{{Parent: "a", Child: "b"}}}
+	links := []model.DependencyLink{{Parent: "a", Child: "b", CallCount: 1}}
+
+	d := NewReadMetricsDecorator(store, mf)
+	latencies, err := d.(dependencystore.LatencyReader).GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, store.latencies, latencies)
+	require.NoError(t, d.(dependencystore.Writer).WriteDependencies(time.Now(), links))
+	assert.Equal(t, links, store.written)
+	require.Error(t, d.(dependencystore.LatencyWriter).WriteDependencyLatencies(time.Now(), latencies))
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_dependency_latencies|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=write_dependencies|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=write_dependency_latencies|result=err"])
+	assert.EqualValues(t, 1, counters["errors|error=other|operation=write_dependency_latencies"])
+}
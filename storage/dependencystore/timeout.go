@@ -0,0 +1,85 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// timeoutReader is a Reader bounding the time of each read by a timeout, the earlier deadline
+// of the context of the read applying if it has one.
+type timeoutReader struct {
+	reader  Reader
+	timeout time.Duration
+}
+
+type timeoutLatencyReader struct {
+	reader  LatencyReader
+	timeout time.Duration
+}
+
+// NewTimeoutReader returns the reader bounding the time of the reads by the timeout, or the reader itself
+// if the timeout is not positive. It implements the LatencyReader, Writer and LatencyWriter interfaces if
+// the reader does, a LatencyWriter being only kept if it is also a Writer. The writes are not bounded
+// since they have no context.
+func NewTimeoutReader(reader Reader, timeout time.Duration) Reader {
+	if timeout <= 0 {
+		return reader
+	}
+	r := &timeoutReader{reader: reader, timeout: timeout}
+	lr, isLatencyReader := reader.(LatencyReader)
+	w, isWriter := reader.(Writer)
+	lw, isLatencyWriter := reader.(LatencyWriter)
+	latencies := timeoutLatencyReader{reader: lr, timeout: timeout}
+	switch {
+	case isLatencyReader && isWriter && isLatencyWriter:
+		return &struct {
+			*timeoutReader
+			timeoutLatencyReader
+			Writer
+			LatencyWriter
+		}{r, latencies, w, lw}
+	case isLatencyReader && isWriter:
+		return &struct {
+			*timeoutReader
+			timeoutLatencyReader
+			Writer
+		}{r, latencies, w}
+	case isLatencyReader:
+		return &struct {
+			*timeoutReader
+			timeoutLatencyReader
+		}{r, latencies}
+	case isWriter && isLatencyWriter:
+		return &struct {
+			*timeoutReader
+			Writer
+			LatencyWriter
+		}{r, w, lw}
+	case isWriter:
+		return &struct {
+			*timeoutReader
+			Writer
+		}{r, w}
+	default:
+		return r
+	}
+}
+
+// GetDependencies implements Reader#GetDependencies
+func (r *timeoutReader) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.GetDependencies(ctx, endTs, lookback)
+}
+
+// GetDependencyLatencies implements LatencyReader#GetDependencyLatencies
+func (r timeoutLatencyReader) GetDependencyLatencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]DependencyLatency, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.GetDependencyLatencies(ctx, endTs, lookback)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type deadlineStore struct {
+	deadlines []time.Time
+	written   []model.DependencyLink
+}
+
+func (s *deadlineStore) GetDependencies(ctx context.Context, _ time.Time, _ time.Duration) ([]model.DependencyLink, error) {
+	deadline, _ := ctx.Deadline()
+	s.deadlines = append(s.deadlines, deadline)
+	return nil, nil
+}
+
+func (s *deadlineStore) GetDependencyLatencies(ctx context.Context, _ time.Time, _ time.Duration) ([]DependencyLatency, error) {
+	deadline, _ := ctx.Deadline()
+	s.deadlines = append(s.deadlines, deadline)
+	return nil, nil
+}
+
+func (s *deadlineStore) WriteDependencies(_ time.Time, dependencies []model.DependencyLink) error {
+	s.written = append(s.written, dependencies...)
+	return nil
+}
+
+type readOnlyStore struct {
+	deadlineStore
+}
+
+// WriteDependencies hides the one of deadlineStore, so that readOnlyStore is not a Writer.
+func (*readOnlyStore) WriteDependencies() {}
+
+func TestTimeoutReader(t *testing.T) {
+	store := &deadlineStore{}
+	assert.Same(t, store, NewTimeoutReader(store, 0))
+
+	r := NewTimeoutReader(store, time.Minute)
+	_, err := r.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	_, err = r.(LatencyReader).GetDependencyLatencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	require.Len(t, store.deadlines, 2)
+	for _, deadline := range store.deadlines {
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	}
+
+	links := []model.DependencyLink{{Parent: "a", Child: "b"}}
+	require.NoError(t, r.(Writer).WriteDependencies(time.Now(), links))
+	assert.Equal(t, links, store.written)
+	_, ok := r.(LatencyWriter)
+	assert.False(t, ok)
+}
+
+func TestTimeoutReaderKeepsInterfaces(t *testing.T) {
+	r := NewTimeoutReader(&readOnlyStore{}, time.Minute)
+	_, ok := r.(LatencyReader)
+	assert.True(t, ok)
+	_, ok = r.(Writer)
+	assert.False(t, ok)
+}
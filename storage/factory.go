@@ -24,9 +24,12 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/distributedlock"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/metadatastore"
 	"github.com/jaegertracing/jaeger/storage/metricsstore"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jaegertracing/jaeger/storage/savedsearchstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/warningstore"
 )
 
 // Factory defines an interface for a factory that can create implementations of different storage components.
@@ -48,6 +51,32 @@ type Factory interface {
 
 	// CreateDependencyReader creates a dependencystore.Reader.
 	CreateDependencyReader() (dependencystore.Reader, error)
+
+	// Capabilities returns the optional features supported by the storage, once initialized.
+	Capabilities() Capabilities
+}
+
+// Capabilities describes the optional features of a storage backend, so that its users
+// can enable them at runtime instead of assuming them for every backend.
+type Capabilities struct {
+	// ArchiveStorage is true if the archive span reader and writer are configured.
+	ArchiveStorage bool `json:"archiveStorage"`
+	// StreamingWrites is true if the span writer returns before the spans are stored, the spans being
+	// streamed or buffered to the backend, so that they are only readable after a delay.
+	StreamingWrites bool `json:"streamingWrites"`
+	// TraceSearch is true if the traces can be found by service, operation, time and duration.
+	TraceSearch bool `json:"traceSearch"`
+	// TagSearch is true if the traces can also be found by tags.
+	TagSearch bool `json:"tagSearch"`
+	// TagFilters is true if the traces can also be found by the TagFilters of the queries: the
+	// existence of the tags, their negation and the numeric comparisons of their values.
+	TagFilters bool `json:"tagFilters"`
+	// OperationSpanKind is true if the operations are returned with their span kind.
+	OperationSpanKind bool `json:"operationSpanKind"`
+	// Dependencies is true if the dependency reader returns the links between the services.
+	Dependencies bool `json:"dependencies"`
+	// AdaptiveSampling is true if the storage implements SamplingStoreFactory.
+	AdaptiveSampling bool `json:"adaptiveSampling"`
 }
 
 // Purger defines an interface that is capable of purging the storage.
@@ -57,6 +86,12 @@ type Purger interface {
 	Purge(context.Context) error
 }
 
+// HealthChecker defines an interface that is capable of checking the connectivity to the storage backend.
+type HealthChecker interface {
+	// CheckHealth returns an error if the storage backend cannot be reached.
+	CheckHealth(context.Context) error
+}
+
 // SamplingStoreFactory defines an interface that is capable of returning the necessary backends for
 // adaptive sampling.
 type SamplingStoreFactory interface {
@@ -83,6 +118,49 @@ type ArchiveFactory interface {
 	CreateArchiveSpanWriter() (spanstore.Writer, error)
 }
 
+// ErrWarningStorageNotSupported can be returned by the WarningStoreFactory when the warnings storage is not supported by the backend.
+var ErrWarningStorageNotSupported = errors.New("warnings storage not supported")
+
+// WarningStoreFactory is an additional interface that can be implemented by a factory to persist
+// the data-quality warnings of the traces along with the spans.
+type WarningStoreFactory interface {
+	// CreateWarningStore creates a warningstore.Store.
+	CreateWarningStore() (warningstore.Store, error)
+}
+
+// ErrMetadataStorageNotSupported can be returned by the MetadataStoreFactory when the service metadata
+// storage is not supported by the backend.
+var ErrMetadataStorageNotSupported = errors.New("service metadata storage not supported")
+
+// MetadataStoreFactory is an additional interface that can be implemented by a factory to store
+// the metadata of the services, such as their owning team.
+type MetadataStoreFactory interface {
+	// CreateMetadataStore creates a metadatastore.Store.
+	CreateMetadataStore() (metadatastore.Store, error)
+}
+
+// ErrSamplingStrategyStorageNotSupported can be returned by the SamplingStrategyStoreFactory when the
+// sampling strategies storage is not supported by the backend.
+var ErrSamplingStrategyStorageNotSupported = errors.New("sampling strategies storage not supported")
+
+// SamplingStrategyStoreFactory is an additional interface that can be implemented by a factory to store
+// the sampling strategies of the services edited with the query service API.
+type SamplingStrategyStoreFactory interface {
+	// CreateSamplingStrategyStore creates a samplingstore.StrategyStore.
+	CreateSamplingStrategyStore() (samplingstore.StrategyStore, error)
+}
+
+// ErrSavedSearchStorageNotSupported can be returned by the SavedSearchStoreFactory when the saved
+// searches storage is not supported by the backend.
+var ErrSavedSearchStorageNotSupported = errors.New("saved searches storage not supported")
+
+// SavedSearchStoreFactory is an additional interface that can be implemented by a factory to store
+// the trace searches saved by the users of the query service.
+type SavedSearchStoreFactory interface {
+	// CreateSavedSearchStore creates a savedsearchstore.Store.
+	CreateSavedSearchStore() (savedsearchstore.Store, error)
+}
+
 // MetricsFactory defines an interface for a factory that can create implementations of different metrics storage components.
 // Implementations are also encouraged to implement plugin.Configurable interface.
 //
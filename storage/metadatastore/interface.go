@@ -0,0 +1,62 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metadatastore
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrServiceMetadataNotFound is returned when no metadata is stored for the service.
+var ErrServiceMetadataNotFound = errors.New("service metadata not found")
+
+// ServiceMetadata is the ownership information of a service, e.g. shown by the UI.
+type ServiceMetadata struct {
+	ServiceName   string
+	Description   string
+	Team          string
+	RepositoryURL string
+	OnCallURL     string
+}
+
+// Merge fills the empty fields of the metadata with the ones of other.
+// It returns true if any field was changed.
+func (m *ServiceMetadata) Merge(other *ServiceMetadata) bool {
+	changed := false
+	for _, field := range []struct{ dst, src *string }{
+		{&m.Description, &other.Description},
+		{&m.Team, &other.Team},
+		{&m.RepositoryURL, &other.RepositoryURL},
+		{&m.OnCallURL, &other.OnCallURL},
+	} {
+		if *field.dst == "" && *field.src != "" {
+			*field.dst = *field.src
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Writer writes the metadata of the services.
+type Writer interface {
+	// WriteServiceMetadata creates or replaces the metadata of a service.
+	WriteServiceMetadata(ctx context.Context, metadata *ServiceMetadata) error
+	// DeleteServiceMetadata deletes the metadata of a service, returning
+	// ErrServiceMetadataNotFound if there is none.
+	DeleteServiceMetadata(ctx context.Context, service string) error
+}
+
+// Reader reads the metadata of the services.
+type Reader interface {
+	// GetServiceMetadata returns the metadata of a service, or ErrServiceMetadataNotFound.
+	GetServiceMetadata(ctx context.Context, service string) (*ServiceMetadata, error)
+	// FindServiceMetadata returns the metadata of all the services, ordered by service name.
+	FindServiceMetadata(ctx context.Context) ([]*ServiceMetadata, error)
+}
+
+// Store reads and writes the metadata of the services.
+type Store interface {
+	Writer
+	Reader
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metadatastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceMetadataMerge(t *testing.T) {
+	metadata := &ServiceMetadata{ServiceName: "frontend", Team: "web"}
+	assert.True(t, metadata.Merge(&ServiceMetadata{Team: "other", RepositoryURL: "https://git/frontend"}))
+	assert.Equal(t, &ServiceMetadata{ServiceName: "frontend", Team: "web", RepositoryURL: "https://git/frontend"}, metadata)
+	assert.False(t, metadata.Merge(&ServiceMetadata{Team: "other"}))
+}
@@ -15,6 +15,8 @@ import (
 
 	spanstore "github.com/jaegertracing/jaeger/storage/spanstore"
 
+	storage "github.com/jaegertracing/jaeger/storage"
+
 	zap "go.uber.org/zap"
 )
 
@@ -23,6 +25,24 @@ type Factory struct {
 	mock.Mock
 }
 
+// Capabilities provides a mock function with given fields:
+func (_m *Factory) Capabilities() storage.Capabilities {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Capabilities")
+	}
+
+	var r0 storage.Capabilities
+	if rf, ok := ret.Get(0).(func() storage.Capabilities); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(storage.Capabilities)
+	}
+
+	return r0
+}
+
 // CreateDependencyReader provides a mock function with given fields:
 func (_m *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	ret := _m.Called()
@@ -0,0 +1,28 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+// ErrStrategyNotFound is returned when no sampling strategy is stored for the service.
+var ErrStrategyNotFound = errors.New("sampling strategy not found")
+
+// StrategyStore stores the sampling strategies of the services set with the query service API,
+// which take precedence over the strategies of the collector, e.g. of its strategies file.
+type StrategyStore interface {
+	// GetStrategy returns the sampling strategy of a service, or ErrStrategyNotFound.
+	GetStrategy(ctx context.Context, service string) (*api_v2.SamplingStrategyResponse, error)
+	// FindStrategies returns the sampling strategies of all the services, by service name.
+	FindStrategies(ctx context.Context) (map[string]*api_v2.SamplingStrategyResponse, error)
+	// WriteStrategy creates or replaces the sampling strategy of a service.
+	WriteStrategy(ctx context.Context, service string, strategy *api_v2.SamplingStrategyResponse) error
+	// DeleteStrategy deletes the sampling strategy of a service, returning
+	// ErrStrategyNotFound if there is none.
+	DeleteStrategy(ctx context.Context, service string) error
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package savedsearchstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSavedSearchNotFound is returned when the user has no saved search of the name.
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch is a named set of the parameters of a trace search, saved by a user.
+type SavedSearch struct {
+	// Owner is the user who saved the search, empty for the anonymous users.
+	Owner string
+	Name  string
+	// Shared allows the other users to list and run the search.
+	Shared      bool
+	ServiceName string
+	// OperationName, Tags and the durations are empty when not filtered on.
+	OperationName string
+	Tags          map[string]string
+	DurationMin   time.Duration
+	DurationMax   time.Duration
+	// Lookback is the time range searched, ending when the search is run.
+	Lookback  time.Duration
+	Limit     int
+	UpdatedAt time.Time
+}
+
+// Writer writes the saved searches.
+type Writer interface {
+	// WriteSavedSearch creates or replaces the search of its owner with its name.
+	WriteSavedSearch(ctx context.Context, search *SavedSearch) error
+	// DeleteSavedSearch deletes a search of the owner, returning ErrSavedSearchNotFound if there is none.
+	DeleteSavedSearch(ctx context.Context, owner, name string) error
+}
+
+// Reader reads the saved searches.
+type Reader interface {
+	// GetSavedSearch returns a search of the owner, or ErrSavedSearchNotFound.
+	GetSavedSearch(ctx context.Context, owner, name string) (*SavedSearch, error)
+	// FindSavedSearches returns the searches of the owner and the ones shared by the
+	// other users, ordered by owner and name.
+	FindSavedSearches(ctx context.Context, owner string) ([]*SavedSearch, error)
+}
+
+// Store reads and writes the saved searches.
+type Store interface {
+	Writer
+	Reader
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/queue"
+)
+
+var _ io.Closer = (*AsyncWriter)(nil)
+
+// asyncDrainCheckInterval is how often Close checks whether the queue is empty.
+const asyncDrainCheckInterval = 10 * time.Millisecond
+
+// asyncWriterMetrics keeps track of the spans written through an AsyncWriter.
+type asyncWriterMetrics struct {
+	SpansWrittenSuccess metrics.Counter `metric:"spans_written" tags:"status=success"`
+	SpansWrittenFailure metrics.Counter `metric:"spans_written" tags:"status=failure"`
+	SpansDropped        metrics.Counter `metric:"spans_dropped"`
+	Retries             metrics.Counter `metric:"retries"`
+	QueueLength         metrics.Gauge   `metric:"queue_length"`
+}
+
+// AsyncWriterOptions contains the options for constructing an AsyncWriter.
+type AsyncWriterOptions struct {
+	// QueueSize is the number of spans buffered before new spans are dropped.
+	QueueSize int
+	// Workers is the number of goroutines writing spans to the wrapped writer.
+	Workers int
+	// MaxRetries is the number of times a failed write is retried before the span is dropped.
+	MaxRetries int
+	// RetryInterval is the delay between retries of a failed write.
+	RetryInterval time.Duration
+	// DrainTimeout is how long Close waits for the queued spans to be written.
+	DrainTimeout   time.Duration
+	MetricsFactory metrics.Factory
+	Logger         *zap.Logger
+}
+
+// AsyncWriter is a span Writer that queues spans and writes them to the wrapped
+// writer in the background, so that a slow or failing backend does not block the caller.
+type AsyncWriter struct {
+	spanWriter Writer
+	options    AsyncWriterOptions
+	metrics    asyncWriterMetrics
+	queue      *queue.BoundedQueue
+
+	// done is closed on Close to abort pending retries
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type asyncWriteRequest struct {
+	ctx  context.Context
+	span *model.Span
+}
+
+// NewAsyncWriter creates an AsyncWriter and starts its workers.
+func NewAsyncWriter(spanWriter Writer, options AsyncWriterOptions) *AsyncWriter {
+	writeMetrics := &asyncWriterMetrics{}
+	metrics.Init(writeMetrics, options.MetricsFactory, nil)
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	w := &AsyncWriter{
+		spanWriter: spanWriter,
+		options:    options,
+		metrics:    *writeMetrics,
+		done:       make(chan struct{}),
+	}
+	w.queue = queue.NewBoundedQueue(options.QueueSize, func(any) {
+		w.metrics.SpansDropped.Inc(1)
+	})
+	w.queue.StartConsumers(max(options.Workers, 1), func(item any) {
+		w.write(item.(asyncWriteRequest))
+	})
+	w.queue.StartLengthReporting(time.Second, w.metrics.QueueLength)
+	return w
+}
+
+// WriteSpan queues the span for writing. The span is dropped if the queue is full.
+func (w *AsyncWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	// the span is written after the caller returns, so only the context values, e.g. the tenant, are kept
+	w.queue.Produce(asyncWriteRequest{ctx: context.WithoutCancel(ctx), span: span})
+	return nil
+}
+
+// Close waits up to the drain timeout for the queued spans to be written, then stops the workers.
+// It does not close the wrapped writer.
+func (w *AsyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		deadline := time.Now().Add(w.options.DrainTimeout)
+		for w.queue.Size() > 0 && time.Now().Before(deadline) {
+			time.Sleep(asyncDrainCheckInterval)
+		}
+		close(w.done)
+		w.queue.Stop()
+	})
+	return nil
+}
+
+func (w *AsyncWriter) write(req asyncWriteRequest) {
+	for attempt := 0; ; attempt++ {
+		err := w.spanWriter.WriteSpan(req.ctx, req.span)
+		if err == nil {
+			w.metrics.SpansWrittenSuccess.Inc(1)
+			return
+		}
+		if attempt >= w.options.MaxRetries {
+			w.options.Logger.Error("Failed to write span", zap.Int("attempts", attempt+1), zap.Error(err))
+			w.metrics.SpansWrittenFailure.Inc(1)
+			return
+		}
+		w.metrics.Retries.Inc(1)
+		select {
+		case <-time.After(w.options.RetryInterval):
+		case <-w.done:
+			w.metrics.SpansWrittenFailure.Inc(1)
+			return
+		}
+	}
+}
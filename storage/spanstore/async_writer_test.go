@@ -0,0 +1,149 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type ctxKey struct{}
+
+// flakyWriteSpanStore fails the given number of writes, then records the written spans.
+type flakyWriteSpanStore struct {
+	sync.Mutex
+	failures int
+	block    chan struct{}
+	spans    []*model.Span
+	values   []any
+}
+
+func (s *flakyWriteSpanStore) WriteSpan(ctx context.Context, span *model.Span) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errIWillAlwaysFail
+	}
+	s.spans = append(s.spans, span)
+	s.values = append(s.values, ctx.Value(ctxKey{}))
+	return nil
+}
+
+func (s *flakyWriteSpanStore) written() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.spans)
+}
+
+func asyncOptions(factory *metricstest.Factory) AsyncWriterOptions {
+	return AsyncWriterOptions{
+		QueueSize:      10,
+		Workers:        1,
+		MaxRetries:     2,
+		RetryInterval:  time.Millisecond,
+		DrainTimeout:   time.Second,
+		MetricsFactory: factory,
+	}
+}
+
+func TestAsyncWriterWritesInBackground(t *testing.T) {
+	store := &flakyWriteSpanStore{}
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	w := NewAsyncWriter(store, asyncOptions(metricsFactory))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "tenant"))
+	require.NoError(t, w.WriteSpan(ctx, &model.Span{}))
+	// the write must not be affected by the caller's context being canceled
+	cancel()
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, 1, store.written())
+	assert.Equal(t, []any{"tenant"}, store.values)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "spans_written", Tags: map[string]string{"status": "success"}, Value: 1,
+	})
+}
+
+func TestAsyncWriterRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		success  int
+		failure  int
+		retries  int
+	}{
+		{name: "retried until success", failures: 2, success: 1, retries: 2},
+		{name: "max retries exceeded", failures: 3, failure: 1, retries: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &flakyWriteSpanStore{failures: test.failures}
+			metricsFactory := metricstest.NewFactory(0)
+			defer metricsFactory.Stop()
+			w := NewAsyncWriter(store, asyncOptions(metricsFactory))
+			require.NoError(t, w.WriteSpan(context.Background(), &model.Span{}))
+			require.NoError(t, w.Close())
+
+			metricsFactory.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "spans_written", Tags: map[string]string{"status": "success"}, Value: test.success},
+				metricstest.ExpectedMetric{Name: "spans_written", Tags: map[string]string{"status": "failure"}, Value: test.failure},
+				metricstest.ExpectedMetric{Name: "retries", Value: test.retries},
+			)
+		})
+	}
+}
+
+func TestAsyncWriterDropsWhenQueueIsFull(t *testing.T) {
+	store := &flakyWriteSpanStore{block: make(chan struct{})}
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	opts := asyncOptions(metricsFactory)
+	opts.QueueSize = 1
+	opts.DrainTimeout = time.Millisecond
+	w := NewAsyncWriter(store, opts)
+
+	// a blocked backend does not block the caller
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.WriteSpan(context.Background(), &model.Span{}))
+	}
+	close(store.block)
+	require.NoError(t, w.Close())
+
+	counters, _ := metricsFactory.Snapshot()
+	assert.GreaterOrEqual(t, counters["spans_dropped"], int64(3))
+}
+
+func TestAsyncWriterCloseAbortsRetries(t *testing.T) {
+	store := &flakyWriteSpanStore{failures: 1}
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	opts := asyncOptions(metricsFactory)
+	opts.RetryInterval = time.Hour
+	opts.DrainTimeout = 10 * time.Millisecond
+	w := NewAsyncWriter(store, opts)
+	require.NoError(t, w.WriteSpan(context.Background(), &model.Span{}))
+	assert.Eventually(t, func() bool {
+		counters, _ := metricsFactory.Snapshot()
+		return counters["retries"] == 1
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "spans_written", Tags: map[string]string{"status": "failure"}, Value: 1,
+	})
+}
@@ -0,0 +1,438 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// errNotSupportedByBackend is returned by the query of a backend not supporting an optional interface.
+var errNotSupportedByBackend = errors.New("not supported by the backend")
+
+// FederatedBackend is a span Reader queried by a FederatedReader.
+type FederatedBackend struct {
+	// Name identifies the backend in the logs.
+	Name   string
+	Reader Reader
+	// MinAge is the age of the most recent spans held by the backend, e.g. the delay
+	// after which spans are moved to an archive. Zero means the backend holds recent spans.
+	MinAge time.Duration
+	// MaxAge is the age of the oldest spans held by the backend, e.g. its retention period.
+	// Zero means the age is not bounded.
+	MaxAge time.Duration
+}
+
+// FederatedReader is a span Reader that queries several backends, e.g. a hot and
+// a cold storage, and merges their results. Trace searches are only sent to the
+// backends holding spans in the searched time range.
+//
+// A query fails only if it fails for all the queried backends; the errors of the
+// other backends are logged. Pagination is not supported across backends.
+//
+// The trace ID prefix lookups and the latency distributions are sent to the backends
+// supporting them, the FederatedReader implementing TraceIDPrefixReader and LatencyReader.
+type FederatedReader struct {
+	backends []FederatedBackend
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewFederatedReader creates a FederatedReader.
+func NewFederatedReader(logger *zap.Logger, backends ...FederatedBackend) *FederatedReader {
+	return &FederatedReader{
+		backends: backends,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// GetTrace merges the spans of the trace found in all the backends.
+func (r *FederatedReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	traces := make([]*model.Trace, len(r.backends))
+	err := r.query(r.backends, "GetTrace", func(i int, reader Reader) error {
+		trace, err := reader.GetTrace(ctx, traceID)
+		if errors.Is(err, ErrTraceNotFound) {
+			return nil
+		}
+		traces[i] = trace
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var merged *model.Trace
+	for _, trace := range traces {
+		merged = mergeTraces(merged, trace)
+	}
+	if merged == nil {
+		return nil, ErrTraceNotFound
+	}
+	return merged, nil
+}
+
+// GetServices returns the union of the services of all the backends.
+func (r *FederatedReader) GetServices(ctx context.Context) ([]string, error) {
+	results := make([][]string, len(r.backends))
+	err := r.query(r.backends, "GetServices", func(i int, reader Reader) error {
+		var err error
+		results[i], err = reader.GetServices(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	var services []string
+	for _, result := range results {
+		for _, service := range result {
+			if _, ok := seen[service]; !ok {
+				seen[service] = struct{}{}
+				services = append(services, service)
+			}
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// GetOperations returns the union of the operations of all the backends.
+func (r *FederatedReader) GetOperations(ctx context.Context, query OperationQueryParameters) ([]Operation, error) {
+	// the page of the union is within the first offset+limit operations of each backend
+	backendQuery := query
+	backendQuery.Offset = 0
+	if query.Limit > 0 {
+		backendQuery.Limit = max(query.Offset, 0) + query.Limit
+	}
+	results := make([][]Operation, len(r.backends))
+	err := r.query(r.backends, "GetOperations", func(i int, reader Reader) error {
+		var err error
+		results[i], err = reader.GetOperations(ctx, backendQuery)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[Operation]struct{})
+	var operations []Operation
+	for _, result := range results {
+		for _, operation := range result {
+			if _, ok := seen[operation]; !ok {
+				seen[operation] = struct{}{}
+				operations = append(operations, operation)
+			}
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].SpanKind < operations[j].SpanKind
+	})
+	start, end, _ := Paginate(len(operations), max(query.Offset, 0), query.Limit)
+	return operations[start:end], nil
+}
+
+// FindTraces searches the backends holding spans in the query time range and merges
+// the traces found in several of them, returning up to query.NumTraces traces, the most
+// recent first.
+func (r *FederatedReader) FindTraces(ctx context.Context, query *TraceQueryParameters) ([]*model.Trace, error) {
+	backends := r.backendsFor(query.StartTimeMin, query.StartTimeMax)
+	results := make([][]*model.Trace, len(backends))
+	err := r.query(backends, "FindTraces", func(i int, reader Reader) error {
+		var err error
+		results[i], err = reader.FindTraces(ctx, backendQuery(query))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var traces []*model.Trace
+	byID := make(map[model.TraceID]int)
+	for _, result := range results {
+		for _, trace := range result {
+			if len(trace.Spans) == 0 {
+				continue
+			}
+			traceID := trace.Spans[0].TraceID
+			if i, ok := byID[traceID]; ok {
+				traces[i] = mergeTraces(traces[i], trace)
+				continue
+			}
+			byID[traceID] = len(traces)
+			traces = append(traces, mergeTraces(nil, trace))
+		}
+	}
+	// the backends return their most recent traces, which are not the most recent ones overall
+	startTimes := make(map[*model.Trace]time.Time, len(traces))
+	for _, trace := range traces {
+		startTimes[trace] = traceStartTime(trace)
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i]].After(startTimes[traces[j]])
+	})
+	if query.NumTraces > 0 && len(traces) > query.NumTraces {
+		traces = traces[:query.NumTraces]
+	}
+	return traces, nil
+}
+
+// FindTraceIDs searches the backends holding spans in the query time range, returning
+// up to query.NumTraces distinct trace IDs.
+func (r *FederatedReader) FindTraceIDs(ctx context.Context, query *TraceQueryParameters) ([]model.TraceID, error) {
+	backends := r.backendsFor(query.StartTimeMin, query.StartTimeMax)
+	results := make([][]model.TraceID, len(backends))
+	err := r.query(backends, "FindTraceIDs", func(i int, reader Reader) error {
+		var err error
+		results[i], err = reader.FindTraceIDs(ctx, backendQuery(query))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeTraceIDs(results, query.NumTraces), nil
+}
+
+// FindTraceIDsByPrefix implements TraceIDPrefixReader#FindTraceIDsByPrefix, looking up the prefix
+// in the backends supporting it and holding spans in the query time range. It returns
+// ErrTraceIDPrefixNotSupported if none of them supports it or no backend holds the time range.
+func (r *FederatedReader) FindTraceIDsByPrefix(ctx context.Context, query *TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	backends := r.backendsFor(query.StartTimeMin, query.StartTimeMax)
+	if len(backends) == 0 {
+		return nil, ErrTraceIDPrefixNotSupported
+	}
+	results := make([][]model.TraceID, len(backends))
+	err := r.query(backends, "FindTraceIDsByPrefix", func(i int, reader Reader) error {
+		prefixReader, ok := reader.(TraceIDPrefixReader)
+		if !ok {
+			return errNotSupportedByBackend
+		}
+		var err error
+		results[i], err = prefixReader.FindTraceIDsByPrefix(ctx, query)
+		if errors.Is(err, ErrTraceIDPrefixNotSupported) {
+			return errNotSupportedByBackend
+		}
+		return err
+	})
+	if errors.Is(err, errNotSupportedByBackend) {
+		return nil, ErrTraceIDPrefixNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mergeTraceIDs(results, query.NumTraces), nil
+}
+
+// GetLatencyDistribution implements LatencyReader#GetLatencyDistribution, merging the distributions
+// of the backends supporting it and holding spans in the query time range. It returns
+// ErrLatencyDistributionNotSupported if none of them supports it or no backend holds the time range.
+//
+// The percentiles of a distribution merged from several backends are estimated from its buckets,
+// as the upper bound of the bucket holding the percentile.
+func (r *FederatedReader) GetLatencyDistribution(ctx context.Context, query *LatencyQueryParameters) (*LatencyDistribution, error) {
+	backends := r.backendsFor(query.StartTimeMin, query.StartTimeMax)
+	if len(backends) == 0 {
+		return nil, ErrLatencyDistributionNotSupported
+	}
+	results := make([]*LatencyDistribution, len(backends))
+	err := r.query(backends, "GetLatencyDistribution", func(i int, reader Reader) error {
+		latencyReader, ok := reader.(LatencyReader)
+		if !ok {
+			return errNotSupportedByBackend
+		}
+		var err error
+		results[i], err = latencyReader.GetLatencyDistribution(ctx, query)
+		if errors.Is(err, ErrLatencyDistributionNotSupported) {
+			return errNotSupportedByBackend
+		}
+		return err
+	})
+	if errors.Is(err, errNotSupportedByBackend) {
+		return nil, ErrLatencyDistributionNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mergeLatencyDistributions(results, query.GetBucketBounds()), nil
+}
+
+// backendsFor returns the backends holding spans in the time range.
+func (r *FederatedReader) backendsFor(startTimeMin, startTimeMax time.Time) []FederatedBackend {
+	now := r.now()
+	var backends []FederatedBackend
+	for _, backend := range r.backends {
+		if backend.MaxAge > 0 && !startTimeMax.IsZero() && startTimeMax.Before(now.Add(-backend.MaxAge)) {
+			continue
+		}
+		if backend.MinAge > 0 && !startTimeMin.IsZero() && startTimeMin.After(now.Add(-backend.MinAge)) {
+			continue
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// query calls fn for each backend concurrently. It fails only if all the backends supporting
+// the query fail, or with errNotSupportedByBackend if none of them supports it.
+func (r *FederatedReader) query(backends []FederatedBackend, method string, fn func(i int, reader Reader) error) error {
+	errs := make([]error, len(backends))
+	var wg sync.WaitGroup
+	for i, backend := range backends {
+		wg.Add(1)
+		go func(i int, backend FederatedBackend) {
+			defer wg.Done()
+			errs[i] = fn(i, backend.Reader)
+		}(i, backend)
+	}
+	wg.Wait()
+	failed, unsupported := 0, 0
+	for i, err := range errs {
+		switch {
+		case errors.Is(err, errNotSupportedByBackend):
+			unsupported++
+			errs[i] = nil
+		case err != nil:
+			failed++
+			r.logger.Error("Federated span reader query failed",
+				zap.String("backend", backends[i].Name), zap.String("method", method), zap.Error(err))
+		}
+	}
+	if unsupported > 0 && unsupported == len(backends) {
+		return errNotSupportedByBackend
+	}
+	if failed > 0 && failed == len(backends)-unsupported {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// backendQuery copies the query without its pagination, which is specific to each backend.
+func backendQuery(query *TraceQueryParameters) *TraceQueryParameters {
+	q := *query
+	q.PageToken = ""
+	return &q
+}
+
+// mergeTraceIDs returns up to numTraces distinct trace IDs of the results, in their order.
+func mergeTraceIDs(results [][]model.TraceID, numTraces int) []model.TraceID {
+	seen := make(map[model.TraceID]struct{})
+	var traceIDs []model.TraceID
+	for _, result := range results {
+		for _, traceID := range result {
+			if _, ok := seen[traceID]; !ok {
+				seen[traceID] = struct{}{}
+				traceIDs = append(traceIDs, traceID)
+			}
+		}
+	}
+	if numTraces > 0 && len(traceIDs) > numTraces {
+		traceIDs = traceIDs[:numTraces]
+	}
+	return traceIDs
+}
+
+// traceStartTime returns the start time of the earliest span of the trace.
+func traceStartTime(trace *model.Trace) time.Time {
+	var start time.Time
+	for _, span := range trace.Spans {
+		if start.IsZero() || span.StartTime.Before(start) {
+			start = span.StartTime
+		}
+	}
+	return start
+}
+
+// mergeLatencyDistributions sums the buckets of the distributions computed with the bounds,
+// a single distribution being returned as is.
+func mergeLatencyDistributions(distributions []*LatencyDistribution, bounds []time.Duration) *LatencyDistribution {
+	var found []*LatencyDistribution
+	for _, distribution := range distributions {
+		if distribution != nil {
+			found = append(found, distribution)
+		}
+	}
+	if len(found) == 1 {
+		return found[0]
+	}
+	merged := &LatencyDistribution{
+		BucketBounds: bounds,
+		BucketCounts: make([]int64, len(bounds)+1),
+	}
+	// the percentiles above the last bound are only known to be below the largest of the backends
+	var maxP50, maxP95, maxP99 time.Duration
+	for _, distribution := range found {
+		merged.Count += distribution.Count
+		for i, count := range distribution.BucketCounts {
+			if i < len(merged.BucketCounts) {
+				merged.BucketCounts[i] += count
+			}
+		}
+		maxP50 = max(maxP50, distribution.P50)
+		maxP95 = max(maxP95, distribution.P95)
+		maxP99 = max(maxP99, distribution.P99)
+	}
+	merged.P50 = bucketPercentile(merged, 50, maxP50)
+	merged.P95 = bucketPercentile(merged, 95, maxP95)
+	merged.P99 = bucketPercentile(merged, 99, maxP99)
+	return merged
+}
+
+// bucketPercentile returns the upper bound of the bucket holding the nearest-rank percentile p
+// of the distribution, or overflow if it is above the last bound.
+func bucketPercentile(distribution *LatencyDistribution, p float64, overflow time.Duration) time.Duration {
+	if distribution.Count == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(p/100*float64(distribution.Count))), 1)
+	var seen int64
+	for i, count := range distribution.BucketCounts {
+		seen += count
+		if seen >= rank {
+			if i < len(distribution.BucketBounds) {
+				return distribution.BucketBounds[i]
+			}
+			break
+		}
+	}
+	return overflow
+}
+
+// spanKey identifies a span stored in several backends. The start time is compared in nanoseconds,
+// as the backends may return it in different locations.
+type spanKey struct {
+	spanID    model.SpanID
+	startTime int64
+	operation string
+}
+
+// mergeTraces adds the spans and warnings of the trace to merged, skipping duplicate spans.
+func mergeTraces(merged, trace *model.Trace) *model.Trace {
+	if trace == nil {
+		return merged
+	}
+	if merged == nil {
+		merged = &model.Trace{}
+	}
+	seen := make(map[spanKey]struct{}, len(merged.Spans))
+	for _, span := range merged.Spans {
+		seen[spanKey{span.SpanID, span.StartTime.UnixNano(), span.OperationName}] = struct{}{}
+	}
+	for _, span := range trace.Spans {
+		key := spanKey{span.SpanID, span.StartTime.UnixNano(), span.OperationName}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			merged.Spans = append(merged.Spans, span)
+		}
+	}
+	merged.Warnings = append(merged.Warnings, trace.Warnings...)
+	return merged
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// fakeReader returns canned results, recording the trace queries it receives.
+type fakeReader struct {
+	sync.Mutex
+	traces     []*model.Trace
+	services   []string
+	operations []Operation
+	err        error
+	queries    []*TraceQueryParameters
+}
+
+func (r *fakeReader) GetTrace(_ context.Context, traceID model.TraceID) (*model.Trace, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, trace := range r.traces {
+		if trace.Spans[0].TraceID == traceID {
+			return trace, nil
+		}
+	}
+	return nil, ErrTraceNotFound
+}
+
+func (r *fakeReader) GetServices(context.Context) ([]string, error) {
+	return r.services, r.err
+}
+
+func (r *fakeReader) GetOperations(_ context.Context, query OperationQueryParameters) ([]Operation, error) {
+	return PageOperations(r.operations, query), r.err
+}
+
+func (r *fakeReader) FindTraces(_ context.Context, query *TraceQueryParameters) ([]*model.Trace, error) {
+	r.Lock()
+	r.queries = append(r.queries, query)
+	r.Unlock()
+	return r.traces, r.err
+}
+
+func (r *fakeReader) FindTraceIDs(_ context.Context, query *TraceQueryParameters) ([]model.TraceID, error) {
+	r.Lock()
+	r.queries = append(r.queries, query)
+	r.Unlock()
+	var traceIDs []model.TraceID
+	for _, trace := range r.traces {
+		if len(trace.Spans) > 0 {
+			traceIDs = append(traceIDs, trace.Spans[0].TraceID)
+		}
+	}
+	return traceIDs, r.err
+}
+
+func federatedTrace(traceID uint64, spanIDs ...uint64) *model.Trace {
+	trace := &model.Trace{}
+	for _, spanID := range spanIDs {
+		trace.Spans = append(trace.Spans, &model.Span{
+			TraceID:       model.NewTraceID(0, traceID),
+			SpanID:        model.NewSpanID(spanID),
+			OperationName: "op",
+		})
+	}
+	return trace
+}
+
+func spanIDs(trace *model.Trace) []model.SpanID {
+	var ids []model.SpanID
+	for _, span := range trace.Spans {
+		ids = append(ids, span.SpanID)
+	}
+	return ids
+}
+
+func TestFederatedReaderGetTrace(t *testing.T) {
+	hot := &fakeReader{traces: []*model.Trace{federatedTrace(1, 1, 2)}}
+	cold := &fakeReader{traces: []*model.Trace{federatedTrace(1, 2, 3), federatedTrace(2, 4)}}
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold})
+
+	trace, err := r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{1, 2, 3}, spanIDs(trace))
+
+	trace, err = r.GetTrace(context.Background(), model.NewTraceID(0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{4}, spanIDs(trace))
+
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 3))
+	require.ErrorIs(t, err, ErrTraceNotFound)
+
+	// the same span is returned by the backends with its start time in different locations
+	startTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	hot.traces[0].Spans[1].StartTime = startTime
+	cold.traces[0].Spans[0].StartTime = startTime.In(time.FixedZone("CEST", 2*60*60))
+	trace, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{1, 2, 3}, spanIDs(trace))
+}
+
+func TestFederatedReaderPartialFailure(t *testing.T) {
+	hot := &fakeReader{traces: []*model.Trace{federatedTrace(1, 1)}, services: []string{"a"}}
+	cold := &fakeReader{err: errors.New("cold storage unavailable")}
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold})
+
+	trace, err := r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{1}, spanIDs(trace))
+	services, err := r.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, services)
+
+	hot.err = errors.New("hot storage unavailable")
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorContains(t, err, "hot storage unavailable")
+	require.ErrorContains(t, err, "cold storage unavailable")
+	_, err = r.GetServices(context.Background())
+	require.Error(t, err)
+	_, err = r.GetOperations(context.Background(), OperationQueryParameters{})
+	require.Error(t, err)
+	_, err = r.FindTraces(context.Background(), &TraceQueryParameters{})
+	require.Error(t, err)
+	_, err = r.FindTraceIDs(context.Background(), &TraceQueryParameters{})
+	require.Error(t, err)
+}
+
+func TestFederatedReaderServicesAndOperations(t *testing.T) {
+	hot := &fakeReader{
+		services:   []string{"b", "a"},
+		operations: []Operation{{Name: "op", SpanKind: "server"}, {Name: "op", SpanKind: "client"}},
+	}
+	cold := &fakeReader{
+		services:   []string{"c", "a"},
+		operations: []Operation{{Name: "op", SpanKind: "server"}, {Name: "legacy"}},
+	}
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold})
+
+	services, err := r.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, services)
+
+	operations, err := r.GetOperations(context.Background(), OperationQueryParameters{ServiceName: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []Operation{{Name: "legacy"}, {Name: "op", SpanKind: "client"}, {Name: "op", SpanKind: "server"}}, operations)
+
+	operations, err = r.GetOperations(context.Background(), OperationQueryParameters{ServiceName: "a", Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []Operation{{Name: "op", SpanKind: "client"}}, operations)
+}
+
+func TestFederatedReaderFindTraces(t *testing.T) {
+	hot := &fakeReader{traces: []*model.Trace{federatedTrace(1, 1), federatedTrace(2, 2)}}
+	cold := &fakeReader{traces: []*model.Trace{federatedTrace(2, 3), federatedTrace(3, 4), {}}}
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold})
+
+	traces, err := r.FindTraces(context.Background(), &TraceQueryParameters{PageToken: "token"})
+	require.NoError(t, err)
+	require.Len(t, traces, 3)
+	assert.Equal(t, []model.SpanID{1}, spanIDs(traces[0]))
+	assert.Equal(t, []model.SpanID{2, 3}, spanIDs(traces[1]))
+	assert.Equal(t, []model.SpanID{4}, spanIDs(traces[2]))
+	// the page token of the federated query is not meaningful to the backends
+	assert.Empty(t, hot.queries[0].PageToken)
+
+	// the merged traces do not modify the traces of the backends
+	assert.Len(t, hot.traces[1].Spans, 1)
+
+	traces, err = r.FindTraces(context.Background(), &TraceQueryParameters{NumTraces: 2})
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+
+	traceIDs, err := r.FindTraceIDs(context.Background(), &TraceQueryParameters{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3)}, traceIDs)
+
+	traceIDs, err = r.FindTraceIDs(context.Background(), &TraceQueryParameters{NumTraces: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+}
+
+func TestFederatedReaderTimeRangeRouting(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		min, max time.Time
+		hot      bool
+		cold     bool
+	}{
+		{name: "unbounded", hot: true, cold: true},
+		{name: "recent", min: now.Add(-time.Hour), max: now, hot: true},
+		{name: "old", min: now.Add(-30 * 24 * time.Hour), max: now.Add(-10 * 24 * time.Hour), cold: true},
+		{name: "overlapping", min: now.Add(-3 * 24 * time.Hour), max: now, hot: true, cold: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hot, cold := &fakeReader{}, &fakeReader{}
+			r := NewFederatedReader(zap.NewNop(),
+				FederatedBackend{Name: "hot", Reader: hot, MaxAge: 2 * 24 * time.Hour},
+				FederatedBackend{Name: "cold", Reader: cold, MinAge: 24 * time.Hour})
+			r.now = func() time.Time { return now }
+
+			_, err := r.FindTraces(context.Background(), &TraceQueryParameters{StartTimeMin: test.min, StartTimeMax: test.max})
+			require.NoError(t, err)
+			assert.Equal(t, test.hot, len(hot.queries) == 1, "hot queried")
+			assert.Equal(t, test.cold, len(cold.queries) == 1, "cold queried")
+		})
+	}
+}
+
+func TestFederatedReaderFindTracesMostRecent(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	traceAt := func(traceID uint64, age time.Duration) *model.Trace {
+		trace := federatedTrace(traceID, traceID)
+		trace.Spans[0].StartTime = now.Add(-age)
+		return trace
+	}
+	hot := &fakeReader{traces: []*model.Trace{traceAt(1, time.Minute), traceAt(2, time.Hour)}}
+	cold := &fakeReader{traces: []*model.Trace{traceAt(3, 48*time.Hour), traceAt(4, 2*time.Minute)}}
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold})
+
+	traces, err := r.FindTraces(context.Background(), &TraceQueryParameters{NumTraces: 3})
+	require.NoError(t, err)
+	require.Len(t, traces, 3)
+	assert.Equal(t, []model.SpanID{1}, spanIDs(traces[0]))
+	assert.Equal(t, []model.SpanID{4}, spanIDs(traces[1]))
+	assert.Equal(t, []model.SpanID{2}, spanIDs(traces[2]))
+}
+
+// fakeOptionalReader is a fakeReader implementing the optional TraceIDPrefixReader and LatencyReader.
+type fakeOptionalReader struct {
+	fakeReader
+	traceIDs     []model.TraceID
+	distribution *LatencyDistribution
+	optionalErr  error
+}
+
+func (r *fakeOptionalReader) FindTraceIDsByPrefix(context.Context, *TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	return r.traceIDs, r.optionalErr
+}
+
+func (r *fakeOptionalReader) GetLatencyDistribution(context.Context, *LatencyQueryParameters) (*LatencyDistribution, error) {
+	return r.distribution, r.optionalErr
+}
+
+func TestFederatedReaderFindTraceIDsByPrefix(t *testing.T) {
+	hot := &fakeOptionalReader{traceIDs: []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}}
+	cold := &fakeOptionalReader{traceIDs: []model.TraceID{model.NewTraceID(0, 2), model.NewTraceID(0, 3)}}
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold},
+		FederatedBackend{Name: "legacy", Reader: &fakeReader{}})
+
+	traceIDs, err := r.FindTraceIDsByPrefix(context.Background(), &TraceIDPrefixQueryParameters{Prefix: "0000"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3)}, traceIDs)
+
+	traceIDs, err = r.FindTraceIDsByPrefix(context.Background(), &TraceIDPrefixQueryParameters{Prefix: "0000", NumTraces: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}, traceIDs)
+
+	// the failure of a backend supporting the lookup is not hidden by the backends not supporting it
+	hot.optionalErr = errors.New("hot storage unavailable")
+	cold.optionalErr = ErrTraceIDPrefixNotSupported
+	_, err = r.FindTraceIDsByPrefix(context.Background(), &TraceIDPrefixQueryParameters{Prefix: "0000"})
+	require.ErrorIs(t, err, hot.optionalErr)
+
+	r = NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "cold", Reader: cold},
+		FederatedBackend{Name: "legacy", Reader: &fakeReader{}})
+	_, err = r.FindTraceIDsByPrefix(context.Background(), &TraceIDPrefixQueryParameters{Prefix: "0000"})
+	require.ErrorIs(t, err, ErrTraceIDPrefixNotSupported)
+
+	// no backend holds spans of the time range
+	r = NewFederatedReader(zap.NewNop(), FederatedBackend{Name: "hot", Reader: hot, MaxAge: time.Hour})
+	_, err = r.FindTraceIDsByPrefix(context.Background(), &TraceIDPrefixQueryParameters{
+		Prefix:       "0000",
+		StartTimeMax: time.Now().Add(-2 * time.Hour),
+	})
+	require.ErrorIs(t, err, ErrTraceIDPrefixNotSupported)
+}
+
+func TestFederatedReaderGetLatencyDistribution(t *testing.T) {
+	bounds := []time.Duration{10 * time.Millisecond, 100 * time.Millisecond}
+	hotDistribution := ComputeLatencyDistribution(
+		[]time.Duration{time.Millisecond, 2 * time.Millisecond, 20 * time.Millisecond}, bounds)
+	coldDistribution := ComputeLatencyDistribution(
+		[]time.Duration{3 * time.Millisecond, 50 * time.Millisecond, time.Second}, bounds)
+	hot := &fakeOptionalReader{distribution: hotDistribution}
+	cold := &fakeOptionalReader{distribution: coldDistribution}
+	query := &LatencyQueryParameters{ServiceName: "svc", BucketBounds: bounds}
+
+	r := NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "legacy", Reader: &fakeReader{}})
+	distribution, err := r.GetLatencyDistribution(context.Background(), query)
+	require.NoError(t, err)
+	assert.Same(t, hotDistribution, distribution)
+
+	r = NewFederatedReader(zap.NewNop(),
+		FederatedBackend{Name: "hot", Reader: hot},
+		FederatedBackend{Name: "cold", Reader: cold})
+	distribution, err = r.GetLatencyDistribution(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, &LatencyDistribution{
+		Count:        6,
+		P50:          10 * time.Millisecond,
+		P95:          time.Second,
+		P99:          time.Second,
+		BucketBounds: bounds,
+		BucketCounts: []int64{3, 2, 1},
+	}, distribution)
+
+	r = NewFederatedReader(zap.NewNop(), FederatedBackend{Name: "legacy", Reader: &fakeReader{}})
+	_, err = r.GetLatencyDistribution(context.Background(), query)
+	require.ErrorIs(t, err, ErrLatencyDistributionNotSupported)
+
+	// no backend holds spans of the time range
+	r = NewFederatedReader(zap.NewNop(), FederatedBackend{Name: "hot", Reader: hot, MaxAge: time.Hour})
+	query.StartTimeMax = time.Now().Add(-2 * time.Hour)
+	_, err = r.GetLatencyDistribution(context.Background(), query)
+	require.ErrorIs(t, err, ErrLatencyDistributionNotSupported)
+}
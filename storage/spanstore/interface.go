@@ -18,6 +18,7 @@ package spanstore
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -31,6 +32,31 @@ type Writer interface {
 	WriteSpan(ctx context.Context, span *model.Span) error
 }
 
+// BatchWriter is a Writer that writes several spans at once more efficiently than one by one.
+// The collector pipeline batches spans for the writers implementing it.
+type BatchWriter interface {
+	Writer
+	// WriteBatch writes the spans, which may belong to different traces.
+	WriteBatch(ctx context.Context, spans []*model.Span) error
+}
+
+// BatchWriteError is returned by WriteBatch when some of the spans failed to be written, the
+// others having been written.
+type BatchWriteError struct {
+	// Failed are the spans which failed to be written.
+	Failed []*model.Span
+	// Err joins the errors of the failed spans.
+	Err error
+}
+
+func (e *BatchWriteError) Error() string {
+	return fmt.Sprintf("failed to write %d spans of the batch: %v", len(e.Failed), e.Err)
+}
+
+func (e *BatchWriteError) Unwrap() error {
+	return e.Err
+}
+
 // Reader finds and loads traces and other data from storage.
 type Reader interface {
 	// GetTrace retrieves the trace with a given id.
@@ -70,13 +96,35 @@ type TraceQueryParameters struct {
 	StartTimeMax  time.Time
 	DurationMin   time.Duration
 	DurationMax   time.Duration
-	NumTraces     int
+	// LogFields are matched against the fields of span logs (OTLP events): a trace
+	// matches if at least one span has a single log containing all of these fields.
+	// The name of an OTLP event is stored in the "event" field. The readers not supporting
+	// them return ErrLogFieldsNotSupported.
+	LogFields map[string]string
+	// ResourceAttributes are matched against the resource attributes of the processes of
+	// the spans, see SearchableResourceAttributes.
+	ResourceAttributes map[string]string
+	// TagFilters are matched against the tags like Tags, with the other comparisons than the
+	// equality. They are only supported by the span storage having the TagFilters capability.
+	TagFilters []TagFilter
+	// NumTraces is the maximum number of traces to return, i.e. the page size.
+	NumTraces int
+	// PageToken is the continuation token returned in TracesPage.NextPageToken by a previous
+	// query with the same parameters. If empty, the first page is returned. Readers that do
+	// not implement PaginatedReader ignore it.
+	PageToken string
 }
 
 // OperationQueryParameters contains parameters of query operations, empty spanKind means get operations for all kinds of span.
 type OperationQueryParameters struct {
 	ServiceName string
 	SpanKind    string
+	// NamePrefix restricts the operations to the ones whose name starts with it.
+	NamePrefix string
+	// Offset is the number of operations to skip, in the order of PageOperations.
+	Offset int
+	// Limit is the maximum number of operations to return, 0 meaning no limit.
+	Limit int
 }
 
 // Operation contains operation name and span kind
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrLatencyDistributionNotSupported is returned by LatencyReader if the underlying
+// storage cannot aggregate span durations natively.
+var ErrLatencyDistributionNotSupported = errors.New("latency distribution is not supported by span storage")
+
+// DefaultLatencyBucketBounds are the histogram bucket bounds used when none are requested.
+var DefaultLatencyBucketBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyReader is an optional interface of span readers that can compute
+// latency distributions using backend-native aggregations.
+type LatencyReader interface {
+	GetLatencyDistribution(ctx context.Context, query *LatencyQueryParameters) (*LatencyDistribution, error)
+}
+
+// LatencyQueryParameters contains parameters of a latency distribution query.
+type LatencyQueryParameters struct {
+	ServiceName   string
+	OperationName string
+	StartTimeMin  time.Time
+	StartTimeMax  time.Time
+	// Tags restricts the distribution to spans with all the given span or process tags.
+	Tags map[string]string
+	// BucketBounds are the ascending upper bounds of histogram buckets,
+	// DefaultLatencyBucketBounds are used if empty.
+	BucketBounds []time.Duration
+}
+
+// LatencyDistribution describes the durations of spans matching a LatencyQueryParameters.
+type LatencyDistribution struct {
+	Count int64
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	// BucketBounds are the upper bounds (exclusive) of histogram buckets.
+	BucketBounds []time.Duration
+	// BucketCounts has one more element than BucketBounds, the last one
+	// counting the spans with duration above the last bound.
+	BucketCounts []int64
+}
+
+// GetBucketBounds returns the requested bucket bounds or the default ones.
+func (q *LatencyQueryParameters) GetBucketBounds() []time.Duration {
+	if len(q.BucketBounds) == 0 {
+		return DefaultLatencyBucketBounds
+	}
+	return q.BucketBounds
+}
+
+// ComputeLatencyDistribution computes the latency distribution of given span durations,
+// for storage backends that cannot aggregate them natively.
+func ComputeLatencyDistribution(durations []time.Duration, bounds []time.Duration) *LatencyDistribution {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	counts := make([]int64, len(bounds)+1)
+	for _, d := range sorted {
+		i := sort.Search(len(bounds), func(i int) bool { return d < bounds[i] })
+		counts[i]++
+	}
+	return &LatencyDistribution{
+		Count:        int64(len(sorted)),
+		P50:          percentile(sorted, 50),
+		P95:          percentile(sorted, 95),
+		P99:          percentile(sorted, 99),
+		BucketBounds: bounds,
+		BucketCounts: counts,
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
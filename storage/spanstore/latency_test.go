@@ -0,0 +1,44 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeLatencyDistribution(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i > 0; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	bounds := []time.Duration{10 * time.Millisecond, 50 * time.Millisecond}
+
+	dist := ComputeLatencyDistribution(durations, bounds)
+	assert.Equal(t, &LatencyDistribution{
+		Count:        100,
+		P50:          50 * time.Millisecond,
+		P95:          95 * time.Millisecond,
+		P99:          99 * time.Millisecond,
+		BucketBounds: bounds,
+		BucketCounts: []int64{9, 40, 51},
+	}, dist)
+	assert.Equal(t, 100*time.Millisecond, durations[0], "input must not be reordered")
+}
+
+func TestComputeLatencyDistributionEmpty(t *testing.T) {
+	dist := ComputeLatencyDistribution(nil, DefaultLatencyBucketBounds)
+	assert.Equal(t, int64(0), dist.Count)
+	assert.Equal(t, time.Duration(0), dist.P99)
+	assert.Len(t, dist.BucketCounts, len(DefaultLatencyBucketBounds)+1)
+}
+
+func TestLatencyQueryParametersGetBucketBounds(t *testing.T) {
+	q := &LatencyQueryParameters{}
+	assert.Equal(t, DefaultLatencyBucketBounds, q.GetBucketBounds())
+	q.BucketBounds = []time.Duration{time.Second}
+	assert.Equal(t, []time.Duration{time.Second}, q.GetBucketBounds())
+}
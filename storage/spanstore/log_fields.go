@@ -0,0 +1,41 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"errors"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// ErrLogFieldsNotSupported is returned by the readers which cannot match the traces
+// against TraceQueryParameters.LogFields, rather than ignoring them.
+var ErrLogFieldsNotSupported = errors.New("searching by log fields is not supported by span storage")
+
+// SpanHasLogWithFields returns whether a single log of the span contains all the fields,
+// for the storage backends matching TraceQueryParameters.LogFields against the spans read.
+func SpanHasLogWithFields(span *model.Span, fields map[string]string) bool {
+	for _, log := range span.Logs {
+		matched := make(map[string]struct{}, len(fields))
+		for _, field := range log.Fields {
+			if v, ok := fields[field.Key]; ok && v == field.AsString() {
+				matched[field.Key] = struct{}{}
+			}
+		}
+		if len(matched) == len(fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// TraceHasLogWithFields returns whether a span of the trace has a log containing all the fields.
+func TraceHasLogWithFields(trace *model.Trace, fields map[string]string) bool {
+	for _, span := range trace.Spans {
+		if SpanHasLogWithFields(span, fields) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceHasLogWithFields(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				Logs: []model.Log{
+					{Fields: []model.KeyValue{model.String("event", "exception")}},
+				},
+			},
+			{
+				Logs: []model.Log{
+					{Fields: []model.KeyValue{model.String("event", "retry"), model.Int64("attempt", 2)}},
+					{Fields: []model.KeyValue{model.String("event", "exception"), model.String("exception.type", "TimeoutError")}},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		fields   map[string]string
+		expected bool
+	}{
+		{fields: map[string]string{"event": "exception"}, expected: true},
+		{fields: map[string]string{"event": "exception", "exception.type": "TimeoutError"}, expected: true},
+		{fields: map[string]string{"event": "retry", "attempt": "2"}, expected: true},
+		{fields: map[string]string{"event": "retry", "exception.type": "TimeoutError"}, expected: false},
+		{fields: map[string]string{"event": "exception", "exception.type": "IOError"}, expected: false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, TraceHasLogWithFields(trace, test.fields), test.fields)
+	}
+}
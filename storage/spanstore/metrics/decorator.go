@@ -17,8 +17,12 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -27,56 +31,105 @@ import (
 // ReadMetricsDecorator wraps a spanstore.Reader and collects metrics around each read operation.
 type ReadMetricsDecorator struct {
 	spanReader           spanstore.Reader
-	findTracesMetrics    *queryMetrics
-	findTraceIDsMetrics  *queryMetrics
-	getTraceMetrics      *queryMetrics
-	getServicesMetrics   *queryMetrics
-	getOperationsMetrics *queryMetrics
+	findTracesMetrics    *QueryMetrics
+	findTraceIDsMetrics  *QueryMetrics
+	getTraceMetrics      *QueryMetrics
+	getServicesMetrics   *QueryMetrics
+	getOperationsMetrics *QueryMetrics
+	getLatencyMetrics    *QueryMetrics
+	findByPrefixMetrics  *QueryMetrics
 }
 
-type queryMetrics struct {
+// QueryMetrics are the metrics of an operation of a storage, shared by the decorators of the
+// span and dependency storages so that all the backends report the same metrics.
+type QueryMetrics struct {
 	Errors     metrics.Counter `metric:"requests" tags:"result=err"`
 	Successes  metrics.Counter `metric:"requests" tags:"result=ok"`
 	Responses  metrics.Timer   `metric:"responses"` // used as a histogram, not necessary for GetTrace
 	ErrLatency metrics.Timer   `metric:"latency" tags:"result=err"`
 	OKLatency  metrics.Timer   `metric:"latency" tags:"result=ok"`
+	// Timeouts, NotFound and OtherErrors classify the errors.
+	Timeouts    metrics.Counter `metric:"errors" tags:"error=timeout"`
+	NotFound    metrics.Counter `metric:"errors" tags:"error=not_found"`
+	OtherErrors metrics.Counter `metric:"errors" tags:"error=other"`
 }
 
-func (q *queryMetrics) emit(err error, latency time.Duration, responses int) {
+// NewQueryMetrics creates the metrics of the operation, tagged with its name.
+func NewQueryMetrics(operation string, metricsFactory metrics.Factory) *QueryMetrics {
+	qMetrics := &QueryMetrics{}
+	scoped := metricsFactory.Namespace(metrics.NSOptions{Name: "", Tags: map[string]string{"operation": operation}})
+	metrics.Init(qMetrics, scoped, nil)
+	return qMetrics
+}
+
+// Emit records the result of a request, its latency having the trace of the context as exemplar.
+func (q *QueryMetrics) Emit(ctx context.Context, err error, latency time.Duration, responses int) {
 	if err != nil {
 		q.Errors.Inc(1)
-		q.ErrLatency.Record(latency)
+		metrics.RecordWithContext(ctx, q.ErrLatency, latency)
+		switch {
+		case isTimeout(err):
+			q.Timeouts.Inc(1)
+		case isNotFound(err):
+			q.NotFound.Inc(1)
+		default:
+			q.OtherErrors.Inc(1)
+		}
 	} else {
 		q.Successes.Inc(1)
-		q.OKLatency.Record(latency)
+		metrics.RecordWithContext(ctx, q.OKLatency, latency)
 		q.Responses.Record(time.Duration(responses))
 	}
 }
 
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, spanstore.ErrTraceNotFound) || status.Code(err) == codes.NotFound
+}
+
 // NewReadMetricsDecorator returns a new ReadMetricsDecorator.
 func NewReadMetricsDecorator(spanReader spanstore.Reader, metricsFactory metrics.Factory) *ReadMetricsDecorator {
 	return &ReadMetricsDecorator{
 		spanReader:           spanReader,
-		findTracesMetrics:    buildQueryMetrics("find_traces", metricsFactory),
-		findTraceIDsMetrics:  buildQueryMetrics("find_trace_ids", metricsFactory),
-		getTraceMetrics:      buildQueryMetrics("get_trace", metricsFactory),
-		getServicesMetrics:   buildQueryMetrics("get_services", metricsFactory),
-		getOperationsMetrics: buildQueryMetrics("get_operations", metricsFactory),
+		findTracesMetrics:    NewQueryMetrics("find_traces", metricsFactory),
+		findTraceIDsMetrics:  NewQueryMetrics("find_trace_ids", metricsFactory),
+		getTraceMetrics:      NewQueryMetrics("get_trace", metricsFactory),
+		getServicesMetrics:   NewQueryMetrics("get_services", metricsFactory),
+		getOperationsMetrics: NewQueryMetrics("get_operations", metricsFactory),
+		getLatencyMetrics:    NewQueryMetrics("get_latency_distribution", metricsFactory),
+		findByPrefixMetrics:  NewQueryMetrics("find_trace_ids_by_prefix", metricsFactory),
 	}
 }
 
-func buildQueryMetrics(operation string, metricsFactory metrics.Factory) *queryMetrics {
-	qMetrics := &queryMetrics{}
-	scoped := metricsFactory.Namespace(metrics.NSOptions{Name: "", Tags: map[string]string{"operation": operation}})
-	metrics.Init(qMetrics, scoped, nil)
-	return qMetrics
-}
-
 // FindTraces implements spanstore.Reader#FindTraces
 func (m *ReadMetricsDecorator) FindTraces(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
 	start := time.Now()
 	retMe, err := m.spanReader.FindTraces(ctx, traceQuery)
-	m.findTracesMetrics.emit(err, time.Since(start), len(retMe))
+	m.findTracesMetrics.Emit(ctx, err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+// FindTracesPage implements spanstore.PaginatedReader#FindTracesPage
+// if the underlying reader supports it, otherwise it returns spanstore.ErrPaginationNotSupported.
+func (m *ReadMetricsDecorator) FindTracesPage(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	paginatedReader, ok := m.spanReader.(spanstore.PaginatedReader)
+	if !ok {
+		return nil, spanstore.ErrPaginationNotSupported
+	}
+	start := time.Now()
+	retMe, err := paginatedReader.FindTracesPage(ctx, traceQuery)
+	var numTraces int
+	if retMe != nil {
+		numTraces = len(retMe.Traces)
+	}
+	m.findTracesMetrics.Emit(ctx, err, time.Since(start), numTraces)
 	return retMe, err
 }
 
@@ -84,7 +137,7 @@ func (m *ReadMetricsDecorator) FindTraces(ctx context.Context, traceQuery *spans
 func (m *ReadMetricsDecorator) FindTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
 	start := time.Now()
 	retMe, err := m.spanReader.FindTraceIDs(ctx, traceQuery)
-	m.findTraceIDsMetrics.emit(err, time.Since(start), len(retMe))
+	m.findTraceIDsMetrics.Emit(ctx, err, time.Since(start), len(retMe))
 	return retMe, err
 }
 
@@ -92,7 +145,7 @@ func (m *ReadMetricsDecorator) FindTraceIDs(ctx context.Context, traceQuery *spa
 func (m *ReadMetricsDecorator) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
 	start := time.Now()
 	retMe, err := m.spanReader.GetTrace(ctx, traceID)
-	m.getTraceMetrics.emit(err, time.Since(start), 1)
+	m.getTraceMetrics.Emit(ctx, err, time.Since(start), 1)
 	return retMe, err
 }
 
@@ -100,7 +153,7 @@ func (m *ReadMetricsDecorator) GetTrace(ctx context.Context, traceID model.Trace
 func (m *ReadMetricsDecorator) GetServices(ctx context.Context) ([]string, error) {
 	start := time.Now()
 	retMe, err := m.spanReader.GetServices(ctx)
-	m.getServicesMetrics.emit(err, time.Since(start), len(retMe))
+	m.getServicesMetrics.Emit(ctx, err, time.Since(start), len(retMe))
 	return retMe, err
 }
 
@@ -111,6 +164,38 @@ func (m *ReadMetricsDecorator) GetOperations(
 ) ([]spanstore.Operation, error) {
 	start := time.Now()
 	retMe, err := m.spanReader.GetOperations(ctx, query)
-	m.getOperationsMetrics.emit(err, time.Since(start), len(retMe))
+	m.getOperationsMetrics.Emit(ctx, err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+// GetLatencyDistribution implements spanstore.LatencyReader#GetLatencyDistribution
+// if the underlying reader supports it, otherwise it returns spanstore.ErrLatencyDistributionNotSupported.
+func (m *ReadMetricsDecorator) GetLatencyDistribution(
+	ctx context.Context,
+	query *spanstore.LatencyQueryParameters,
+) (*spanstore.LatencyDistribution, error) {
+	latencyReader, ok := m.spanReader.(spanstore.LatencyReader)
+	if !ok {
+		return nil, spanstore.ErrLatencyDistributionNotSupported
+	}
+	start := time.Now()
+	retMe, err := latencyReader.GetLatencyDistribution(ctx, query)
+	m.getLatencyMetrics.Emit(ctx, err, time.Since(start), 1)
+	return retMe, err
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix
+// if the underlying reader supports it, otherwise it returns spanstore.ErrTraceIDPrefixNotSupported.
+func (m *ReadMetricsDecorator) FindTraceIDsByPrefix(
+	ctx context.Context,
+	query *spanstore.TraceIDPrefixQueryParameters,
+) ([]model.TraceID, error) {
+	prefixReader, ok := m.spanReader.(spanstore.TraceIDPrefixReader)
+	if !ok {
+		return nil, spanstore.ErrTraceIDPrefixNotSupported
+	}
+	start := time.Now()
+	retMe, err := prefixReader.FindTraceIDsByPrefix(ctx, query)
+	m.findByPrefixMetrics.Emit(ctx, err, time.Since(start), len(retMe))
 	return retMe, err
 }
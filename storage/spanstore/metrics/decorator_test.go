@@ -18,9 +18,14 @@ package metrics_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
@@ -143,3 +148,119 @@ func TestFailingUnderlyingCalls(t *testing.T) {
 
 	checkExpectedExistingAndNonExistentCounters(t, counters, expecteds, gauges, existingKeys, nonExistentKeys)
 }
+
+type latencyReader struct {
+	mocks.Reader
+	dist *spanstore.LatencyDistribution
+}
+
+func (r *latencyReader) GetLatencyDistribution(context.Context, *spanstore.LatencyQueryParameters) (*spanstore.LatencyDistribution, error) {
+	return r.dist, nil
+}
+
+func TestGetLatencyDistribution(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	query := &spanstore.LatencyQueryParameters{ServiceName: "something"}
+
+	mrs := metrics.NewReadMetricsDecorator(&mocks.Reader{}, mf)
+	_, err := mrs.GetLatencyDistribution(context.Background(), query)
+	require.ErrorIs(t, err, spanstore.ErrLatencyDistributionNotSupported)
+
+	dist := &spanstore.LatencyDistribution{Count: 1}
+	mrs = metrics.NewReadMetricsDecorator(&latencyReader{dist: dist}, mf)
+	actual, err := mrs.GetLatencyDistribution(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, dist, actual)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_latency_distribution|result=ok"])
+}
+
+type prefixReader struct {
+	mocks.Reader
+	traceIDs []model.TraceID
+}
+
+func (r *prefixReader) FindTraceIDsByPrefix(context.Context, *spanstore.TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	return r.traceIDs, nil
+}
+
+func TestFindTraceIDsByPrefix(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	query := &spanstore.TraceIDPrefixQueryParameters{Prefix: "abcd"}
+
+	mrs := metrics.NewReadMetricsDecorator(&mocks.Reader{}, mf)
+	_, err := mrs.FindTraceIDsByPrefix(context.Background(), query)
+	require.ErrorIs(t, err, spanstore.ErrTraceIDPrefixNotSupported)
+
+	traceIDs := []model.TraceID{model.NewTraceID(0, 0xabcd)}
+	mrs = metrics.NewReadMetricsDecorator(&prefixReader{traceIDs: traceIDs}, mf)
+	actual, err := mrs.FindTraceIDsByPrefix(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, traceIDs, actual)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=find_trace_ids_by_prefix|result=ok"])
+}
+
+type paginatedReader struct {
+	mocks.Reader
+	page *spanstore.TracesPage
+}
+
+func (r *paginatedReader) FindTracesPage(context.Context, *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	return r.page, nil
+}
+
+func TestFindTracesPage(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	query := &spanstore.TraceQueryParameters{}
+
+	mrs := metrics.NewReadMetricsDecorator(&mocks.Reader{}, mf)
+	_, err := mrs.FindTracesPage(context.Background(), query)
+	require.ErrorIs(t, err, spanstore.ErrPaginationNotSupported)
+
+	page := &spanstore.TracesPage{Traces: []*model.Trace{{}, {}}, NextPageToken: spanstore.EncodePageToken(2)}
+	mrs = metrics.NewReadMetricsDecorator(&paginatedReader{page: page}, mf)
+	actual, err := mrs.FindTracesPage(context.Background(), query)
+	require.NoError(t, err)
+	assert.Same(t, page, actual)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=find_traces|result=ok"])
+}
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		class string
+	}{
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), class: "timeout"},
+		{name: "grpc deadline", err: status.Error(codes.DeadlineExceeded, "too slow"), class: "timeout"},
+		{name: "net timeout", err: &net.DNSError{IsTimeout: true}, class: "timeout"},
+		{name: "not found", err: spanstore.ErrTraceNotFound, class: "not_found"},
+		{name: "grpc not found", err: status.Error(codes.NotFound, "no trace"), class: "not_found"},
+		{name: "other", err: errors.New("Failure"), class: "other"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mf := metricstest.NewFactory(0)
+			mockReader := mocks.Reader{}
+			mrs := metrics.NewReadMetricsDecorator(&mockReader, mf)
+			mockReader.On("GetTrace", context.Background(), model.TraceID{}).Return(nil, test.err)
+			_, err := mrs.GetTrace(context.Background(), model.TraceID{})
+			require.ErrorIs(t, err, test.err)
+
+			counters, _ := mf.Snapshot()
+			for _, class := range []string{"timeout", "not_found", "other"} {
+				expected := 0
+				if class == test.class {
+					expected = 1
+				}
+				assert.EqualValues(t, expected, counters["errors|error="+class+"|operation=get_trace"], class)
+			}
+			assert.EqualValues(t, 1, counters["requests|operation=get_trace|result=err"])
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// WriteMetricsDecorator wraps a spanstore.Writer and collects metrics around each write.
+type WriteMetricsDecorator struct {
+	spanWriter        spanstore.Writer
+	writeSpanMetrics  *QueryMetrics
+	writeBatchMetrics *QueryMetrics
+}
+
+// batchWriteMetricsDecorator is the WriteMetricsDecorator of a spanstore.BatchWriter.
+type batchWriteMetricsDecorator struct {
+	*WriteMetricsDecorator
+	batchWriter spanstore.BatchWriter
+}
+
+// NewWriteMetricsDecorator returns a new WriteMetricsDecorator, which is a spanstore.BatchWriter
+// if the writer is one.
+func NewWriteMetricsDecorator(spanWriter spanstore.Writer, metricsFactory metrics.Factory) spanstore.Writer {
+	decorator := &WriteMetricsDecorator{
+		spanWriter:        spanWriter,
+		writeSpanMetrics:  NewQueryMetrics("write_span", metricsFactory),
+		writeBatchMetrics: NewQueryMetrics("write_batch", metricsFactory),
+	}
+	if batchWriter, ok := spanWriter.(spanstore.BatchWriter); ok {
+		return &batchWriteMetricsDecorator{WriteMetricsDecorator: decorator, batchWriter: batchWriter}
+	}
+	return decorator
+}
+
+// WriteSpan implements spanstore.Writer#WriteSpan
+func (m *WriteMetricsDecorator) WriteSpan(ctx context.Context, span *model.Span) error {
+	start := time.Now()
+	err := m.spanWriter.WriteSpan(ctx, span)
+	m.writeSpanMetrics.Emit(ctx, err, time.Since(start), 1)
+	return err
+}
+
+// WriteBatch implements spanstore.BatchWriter#WriteBatch
+func (m *batchWriteMetricsDecorator) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	start := time.Now()
+	err := m.batchWriter.WriteBatch(ctx, spans)
+	m.writeBatchMetrics.Emit(ctx, err, time.Since(start), len(spans))
+	return err
+}
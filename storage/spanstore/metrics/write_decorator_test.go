@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+type batchWriter struct {
+	mocks.Writer
+	batches [][]*model.Span
+}
+
+func (w *batchWriter) WriteBatch(_ context.Context, spans []*model.Span) error {
+	w.batches = append(w.batches, spans)
+	return nil
+}
+
+func TestWriteMetricsDecorator(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	span := &model.Span{SpanID: 1}
+	mockWriter := mocks.Writer{}
+	mockWriter.On("WriteSpan", context.Background(), span).Return(nil).Once()
+	mockWriter.On("WriteSpan", context.Background(), span).Return(context.DeadlineExceeded).Once()
+
+	w := metrics.NewWriteMetricsDecorator(&mockWriter, mf)
+	_, ok := w.(spanstore.BatchWriter)
+	assert.False(t, ok)
+	require.NoError(t, w.WriteSpan(context.Background(), span))
+	require.ErrorIs(t, w.WriteSpan(context.Background(), span), context.DeadlineExceeded)
+
+	counters, gauges := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=write_span|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=write_span|result=err"])
+	assert.EqualValues(t, 1, counters["errors|error=timeout|operation=write_span"])
+	assert.Contains(t, gauges, "latency|operation=write_span|result=ok.P50")
+}
+
+func TestWriteMetricsDecoratorBatch(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	writer := &batchWriter{}
+	spans := []*model.Span{{SpanID: 1}, {SpanID: 2}}
+
+	w := metrics.NewWriteMetricsDecorator(writer, mf)
+	bw, ok := w.(spanstore.BatchWriter)
+	require.True(t, ok)
+	require.NoError(t, bw.WriteBatch(context.Background(), spans))
+	assert.Equal(t, [][]*model.Span{spans}, writer.batches)
+
+	writer.On("WriteSpan", context.Background(), spans[0]).Return(errors.New("Failure"))
+	require.Error(t, bw.WriteSpan(context.Background(), spans[0]))
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=write_batch|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=write_span|result=err"])
+	assert.EqualValues(t, 1, counters["errors|error=other|operation=write_span"])
+}
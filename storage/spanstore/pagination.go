@@ -0,0 +1,151 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// ErrInvalidPageToken is returned when a continuation token cannot be decoded.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrPaginationNotSupported is returned by PaginatedReader if the underlying storage cannot
+// find the traces page by page.
+var ErrPaginationNotSupported = errors.New("pagination of the traces is not supported by span storage")
+
+// PaginatedReader is an optional interface of span readers that can find the traces page
+// by page, starting at the PageToken of the query.
+type PaginatedReader interface {
+	FindTracesPage(ctx context.Context, query *TraceQueryParameters) (*TracesPage, error)
+}
+
+// TracesPage is a page of the traces matching a query.
+type TracesPage struct {
+	Traces []*model.Trace
+	// NextPageToken is the PageToken of the query of the next page, empty if there is none.
+	NextPageToken string
+}
+
+// pageToken is the content of an opaque continuation token.
+type pageToken struct {
+	Offset int `json:"o"`
+}
+
+// EncodePageToken returns an opaque continuation token for the page starting
+// at the given offset in the ordered results of a query.
+func EncodePageToken(offset int) string {
+	data, _ := json.Marshal(pageToken{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePageToken returns the offset encoded in the continuation token.
+// An empty token refers to the first page.
+func DecodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	var t pageToken
+	if err := json.Unmarshal(data, &t); err != nil || t.Offset < 0 {
+		return 0, ErrInvalidPageToken
+	}
+	return t.Offset, nil
+}
+
+// TraceCursor is the position of the last trace of a page in the results of a query, which
+// are ordered by the start time of the most recent matching span of the traces, descending,
+// then by trace ID. The next page starts after the cursor, so that it neither shifts nor
+// repeats traces when new traces are written, however deep the page.
+type TraceCursor struct {
+	StartTime time.Time
+	TraceID   model.TraceID
+}
+
+// traceCursorToken is the content of an opaque continuation token of a page of traces.
+type traceCursorToken struct {
+	StartTime int64  `json:"t"`
+	TraceID   string `json:"i"`
+}
+
+// EncodeTraceCursor returns an opaque continuation token for the page of traces
+// following the cursor.
+func EncodeTraceCursor(cursor TraceCursor) string {
+	data, _ := json.Marshal(traceCursorToken{
+		StartTime: cursor.StartTime.UnixNano(),
+		TraceID:   cursor.TraceID.String(),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTraceCursor returns the cursor encoded in the continuation token of a page
+// of traces. An empty token refers to the first page and returns a nil cursor.
+func DecodeTraceCursor(token string) (*TraceCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var t traceCursorToken
+	if err := json.Unmarshal(data, &t); err != nil || t.TraceID == "" {
+		return nil, ErrInvalidPageToken
+	}
+	traceID, err := model.TraceIDFromString(t.TraceID)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &TraceCursor{StartTime: time.Unix(0, t.StartTime).UTC(), TraceID: traceID}, nil
+}
+
+// Paginate returns the bounds of the page of the given size starting at offset
+// within n ordered results, and the token of the next page, which is empty
+// if there are no more results. A non-positive page size returns all results.
+func Paginate(n, offset, pageSize int) (start, end int, nextPageToken string) {
+	start = min(offset, n)
+	if pageSize <= 0 {
+		return start, n, ""
+	}
+	end = min(start+pageSize, n)
+	if end < n {
+		nextPageToken = EncodePageToken(end)
+	}
+	return start, end, nextPageToken
+}
+
+// PageOperations returns the operations whose name starts with query.NamePrefix,
+// within the page selected by query.Offset and query.Limit. The operations are
+// sorted by name and span kind when filtered or paginated, so that pages are
+// stable across requests. It is used by the readers that cannot filter and
+// paginate the operations of a service in the storage itself.
+func PageOperations(operations []Operation, query OperationQueryParameters) []Operation {
+	if query.NamePrefix == "" && query.Offset <= 0 && query.Limit <= 0 {
+		return operations
+	}
+	filtered := make([]Operation, 0, len(operations))
+	for _, operation := range operations {
+		if strings.HasPrefix(operation.Name, query.NamePrefix) {
+			filtered = append(filtered, operation)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].Name != filtered[j].Name {
+			return filtered[i].Name < filtered[j].Name
+		}
+		return filtered[i].SpanKind < filtered[j].SpanKind
+	})
+	start, end, _ := Paginate(len(filtered), max(query.Offset, 0), query.Limit)
+	return filtered[start:end]
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 100, 12345} {
+		offsetDecoded, err := DecodePageToken(EncodePageToken(offset))
+		require.NoError(t, err)
+		assert.Equal(t, offset, offsetDecoded)
+	}
+}
+
+func TestDecodePageToken(t *testing.T) {
+	offset, err := DecodePageToken("")
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset)
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "eyJvIjotMX0"} {
+		_, err := DecodePageToken(token)
+		require.ErrorIs(t, err, ErrInvalidPageToken, token)
+	}
+}
+
+func TestTraceCursorRoundTrip(t *testing.T) {
+	cursor := TraceCursor{
+		StartTime: time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC),
+		TraceID:   model.NewTraceID(1, 2),
+	}
+	decoded, err := DecodeTraceCursor(EncodeTraceCursor(cursor))
+	require.NoError(t, err)
+	assert.Equal(t, &cursor, decoded)
+}
+
+func TestDecodeTraceCursor(t *testing.T) {
+	cursor, err := DecodeTraceCursor("")
+	require.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	// the offset tokens of the other pages are not trace cursors
+	for _, token := range []string{"not base64!", "bm90IGpzb24", EncodePageToken(2), "eyJ0IjoxLCJpIjoieHl6In0"} {
+		_, err := DecodeTraceCursor(token)
+		require.ErrorIs(t, err, ErrInvalidPageToken, token)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name          string
+		n             int
+		offset        int
+		pageSize      int
+		start, end    int
+		nextPageToken string
+	}{
+		{name: "all results", n: 5, pageSize: 0, start: 0, end: 5},
+		{name: "first page", n: 5, pageSize: 2, start: 0, end: 2, nextPageToken: EncodePageToken(2)},
+		{name: "middle page", n: 5, offset: 2, pageSize: 2, start: 2, end: 4, nextPageToken: EncodePageToken(4)},
+		{name: "last page", n: 5, offset: 4, pageSize: 2, start: 4, end: 5},
+		{name: "exact last page", n: 4, offset: 2, pageSize: 2, start: 2, end: 4},
+		{name: "offset beyond results", n: 3, offset: 10, pageSize: 2, start: 3, end: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, next := Paginate(test.n, test.offset, test.pageSize)
+			assert.Equal(t, test.start, start)
+			assert.Equal(t, test.end, end)
+			assert.Equal(t, test.nextPageToken, next)
+		})
+	}
+}
+
+func TestPageOperations(t *testing.T) {
+	operations := []Operation{
+		{Name: "GET /orders", SpanKind: "server"},
+		{Name: "SELECT"},
+		{Name: "GET /customers", SpanKind: "server"},
+		{Name: "GET /orders", SpanKind: "client"},
+	}
+	tests := []struct {
+		name     string
+		query    OperationQueryParameters
+		expected []Operation
+	}{
+		{name: "all operations", query: OperationQueryParameters{}, expected: operations},
+		{
+			name:  "prefix",
+			query: OperationQueryParameters{NamePrefix: "GET /"},
+			expected: []Operation{
+				{Name: "GET /customers", SpanKind: "server"},
+				{Name: "GET /orders", SpanKind: "client"},
+				{Name: "GET /orders", SpanKind: "server"},
+			},
+		},
+		{
+			name:  "page",
+			query: OperationQueryParameters{Offset: 1, Limit: 2},
+			expected: []Operation{
+				{Name: "GET /orders", SpanKind: "client"},
+				{Name: "GET /orders", SpanKind: "server"},
+			},
+		},
+		{
+			name:     "prefix and last page",
+			query:    OperationQueryParameters{NamePrefix: "GET /", Offset: 2, Limit: 2},
+			expected: []Operation{{Name: "GET /orders", SpanKind: "server"}},
+		},
+		{name: "no match", query: OperationQueryParameters{NamePrefix: "POST"}, expected: []Operation{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, PageOperations(operations, test.query))
+		})
+	}
+}
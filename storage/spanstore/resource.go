@@ -0,0 +1,33 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+// SearchableResourceAttributes are the OTLP resource attributes that can be searched with
+// TraceQueryParameters.ResourceAttributes, e.g. to find the traces of an environment.
+// They are reported as process tags, and stored as dedicated fields by the backends
+// supporting it.
+var SearchableResourceAttributes = []string{
+	"k8s.namespace.name",
+	"k8s.pod.name",
+	"deployment.environment",
+	"service.version",
+}
+
+// ResourceAttributesAsTags moves the resource attributes of the query to its tags,
+// for the readers that do not store them as dedicated fields, since the tags also
+// match the process tags.
+func ResourceAttributesAsTags(query *TraceQueryParameters) {
+	if len(query.ResourceAttributes) == 0 {
+		return
+	}
+	tags := make(map[string]string, len(query.Tags)+len(query.ResourceAttributes))
+	for k, v := range query.Tags {
+		tags[k] = v
+	}
+	for k, v := range query.ResourceAttributes {
+		tags[k] = v
+	}
+	query.Tags = tags
+	query.ResourceAttributes = nil
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceAttributesAsTags(t *testing.T) {
+	tags := map[string]string{"http.status_code": "500"}
+	query := &TraceQueryParameters{
+		Tags:               tags,
+		ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+	}
+	ResourceAttributesAsTags(query)
+	assert.Equal(t, map[string]string{"http.status_code": "500", "deployment.environment": "prod"}, query.Tags)
+	assert.Nil(t, query.ResourceAttributes)
+	assert.Len(t, tags, 1, "the tags of the caller are not modified")
+
+	query = &TraceQueryParameters{Tags: tags}
+	ResourceAttributesAsTags(query)
+	assert.Equal(t, tags, query.Tags)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// ErrTagFiltersNotSupported is returned when a query has TagFilters but the span storage
+// cannot search the traces by them, see storage.Capabilities.TagFilters.
+var ErrTagFiltersNotSupported = errors.New("tag filters are not supported by span storage")
+
+// TagFilterOperator is the comparison of a TagFilter.
+type TagFilterOperator string
+
+const (
+	// TagFilterExists matches the spans having the tag, whatever its value.
+	TagFilterExists TagFilterOperator = "exists"
+	// TagFilterNotEqual matches the spans having the tag with another value.
+	TagFilterNotEqual TagFilterOperator = "!="
+	// TagFilterGreater, TagFilterGreaterOrEqual, TagFilterLess and TagFilterLessOrEqual match
+	// the spans having the tag with a numeric value in the range, the non-numeric values never matching.
+	TagFilterGreater        TagFilterOperator = ">"
+	TagFilterGreaterOrEqual TagFilterOperator = ">="
+	TagFilterLess           TagFilterOperator = "<"
+	TagFilterLessOrEqual    TagFilterOperator = "<="
+)
+
+// TagFilterOperators lists the operators of the tag filters, the longest first so that
+// they can be searched for in that order in an expression.
+var TagFilterOperators = []TagFilterOperator{
+	TagFilterNotEqual,
+	TagFilterGreaterOrEqual,
+	TagFilterLessOrEqual,
+	TagFilterGreater,
+	TagFilterLess,
+}
+
+// TagFilter matches the spans by a tag with another comparison than the equality of the Tags of
+// TraceQueryParameters. Like the Tags, it matches the span tags, the process tags and the log fields.
+type TagFilter struct {
+	Key      string
+	Operator TagFilterOperator
+	// Value is the value compared, empty for TagFilterExists and a number for the numeric operators.
+	Value string
+}
+
+// IsNumeric returns whether the filter compares the tag values as numbers.
+func (f TagFilter) IsNumeric() bool {
+	switch f.Operator {
+	case TagFilterGreater, TagFilterGreaterOrEqual, TagFilterLess, TagFilterLessOrEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks that the filter has a key, a known operator and a value suited to it.
+func (f TagFilter) Validate() error {
+	if f.Key == "" {
+		return errors.New("the key of a tag filter cannot be empty")
+	}
+	switch {
+	case f.Operator == TagFilterExists:
+		if f.Value != "" {
+			return fmt.Errorf("the tag filter of %s exists cannot have a value", f.Key)
+		}
+	case f.Operator == TagFilterNotEqual:
+	case f.IsNumeric():
+		if _, err := strconv.ParseFloat(f.Value, 64); err != nil {
+			return fmt.Errorf("the tag filter %s %s %s must compare with a number", f.Key, f.Operator, f.Value)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q of the tag filter of %s", f.Operator, f.Key)
+	}
+	return nil
+}
+
+// Matches returns whether one of the tags matches the filter, for the storage backends that filter
+// the spans in memory. The filter must be valid.
+func (f TagFilter) Matches(kvs model.KeyValues) bool {
+	for _, kv := range kvs {
+		if kv.Key == f.Key && f.matchesValue(kv) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f TagFilter) matchesValue(kv model.KeyValue) bool {
+	switch f.Operator {
+	case TagFilterExists:
+		return true
+	case TagFilterNotEqual:
+		return kv.AsString() != f.Value
+	}
+	value, err := strconv.ParseFloat(kv.AsString(), 64)
+	if err != nil {
+		return false
+	}
+	// the filter is valid
+	filterValue, _ := strconv.ParseFloat(f.Value, 64)
+	switch f.Operator {
+	case TagFilterGreater:
+		return value > filterValue
+	case TagFilterGreaterOrEqual:
+		return value >= filterValue
+	case TagFilterLess:
+		return value < filterValue
+	case TagFilterLessOrEqual:
+		return value <= filterValue
+	default:
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTagFilterValidate(t *testing.T) {
+	for _, filter := range []TagFilter{
+		{Key: "error", Operator: TagFilterExists},
+		{Key: "http.method", Operator: TagFilterNotEqual, Value: "GET"},
+		{Key: "http.method", Operator: TagFilterNotEqual},
+		{Key: "http.status_code", Operator: TagFilterGreaterOrEqual, Value: "500"},
+		{Key: "db.rows", Operator: TagFilterLess, Value: "1.5e3"},
+	} {
+		require.NoError(t, filter.Validate(), filter)
+	}
+	for filter, expected := range map[TagFilter]string{
+		{Operator: TagFilterExists}:                                  "the key of a tag filter cannot be empty",
+		{Key: "error", Operator: TagFilterExists, Value: "true"}:     "the tag filter of error exists cannot have a value",
+		{Key: "error", Operator: "=="}:                               `unknown operator "==" of the tag filter of error`,
+		{Key: "http.method", Operator: TagFilterGreater, Value: "G"}: "the tag filter http.method > G must compare with a number",
+	} {
+		require.EqualError(t, filter.Validate(), expected)
+	}
+}
+
+func TestTagFilterMatches(t *testing.T) {
+	kvs := model.KeyValues{
+		model.Int64("http.status_code", 503),
+		model.String("http.method", "GET"),
+		model.Float64("ratio", 0.25),
+		model.String("retry", "1"),
+		model.String("retry", "2"),
+	}
+	testCases := []struct {
+		filter   TagFilter
+		expected bool
+	}{
+		{filter: TagFilter{Key: "http.method", Operator: TagFilterExists}, expected: true},
+		{filter: TagFilter{Key: "error", Operator: TagFilterExists}},
+		{filter: TagFilter{Key: "http.method", Operator: TagFilterNotEqual, Value: "POST"}, expected: true},
+		{filter: TagFilter{Key: "http.method", Operator: TagFilterNotEqual, Value: "GET"}},
+		{filter: TagFilter{Key: "error", Operator: TagFilterNotEqual, Value: "true"}},
+		{filter: TagFilter{Key: "http.status_code", Operator: TagFilterGreaterOrEqual, Value: "500"}, expected: true},
+		{filter: TagFilter{Key: "http.status_code", Operator: TagFilterGreater, Value: "503"}},
+		{filter: TagFilter{Key: "http.status_code", Operator: TagFilterLessOrEqual, Value: "503"}, expected: true},
+		{filter: TagFilter{Key: "ratio", Operator: TagFilterLess, Value: "0.5"}, expected: true},
+		{filter: TagFilter{Key: "http.method", Operator: TagFilterLess, Value: "0.5"}},
+		// any of the duplicate tags matches
+		{filter: TagFilter{Key: "retry", Operator: TagFilterGreater, Value: "1"}, expected: true},
+		{filter: TagFilter{Key: "retry", Operator: TagFilterNotEqual, Value: "1"}, expected: true},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, testCase.filter.Matches(kvs), testCase.filter)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// TimeoutReader is a Reader bounding the time of each read by a timeout, the earlier deadline
+// of the context of the read applying if it has one.
+type TimeoutReader struct {
+	reader  Reader
+	timeout time.Duration
+}
+
+// NewTimeoutReader returns the reader bounding the time of the reads by the timeout, or the reader
+// itself if the timeout is not positive.
+func NewTimeoutReader(reader Reader, timeout time.Duration) Reader {
+	if timeout <= 0 {
+		return reader
+	}
+	return &TimeoutReader{reader: reader, timeout: timeout}
+}
+
+// GetTrace implements Reader#GetTrace
+func (r *TimeoutReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.GetTrace(ctx, traceID)
+}
+
+// GetServices implements Reader#GetServices
+func (r *TimeoutReader) GetServices(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.GetServices(ctx)
+}
+
+// GetOperations implements Reader#GetOperations
+func (r *TimeoutReader) GetOperations(ctx context.Context, query OperationQueryParameters) ([]Operation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.GetOperations(ctx, query)
+}
+
+// FindTraces implements Reader#FindTraces
+func (r *TimeoutReader) FindTraces(ctx context.Context, query *TraceQueryParameters) ([]*model.Trace, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.FindTraces(ctx, query)
+}
+
+// FindTracesPage implements PaginatedReader#FindTracesPage if the reader supports it,
+// otherwise it returns ErrPaginationNotSupported.
+func (r *TimeoutReader) FindTracesPage(ctx context.Context, query *TraceQueryParameters) (*TracesPage, error) {
+	paginatedReader, ok := r.reader.(PaginatedReader)
+	if !ok {
+		return nil, ErrPaginationNotSupported
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return paginatedReader.FindTracesPage(ctx, query)
+}
+
+// FindTraceIDs implements Reader#FindTraceIDs
+func (r *TimeoutReader) FindTraceIDs(ctx context.Context, query *TraceQueryParameters) ([]model.TraceID, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.reader.FindTraceIDs(ctx, query)
+}
+
+// GetLatencyDistribution implements LatencyReader#GetLatencyDistribution if the reader supports it,
+// otherwise it returns ErrLatencyDistributionNotSupported.
+func (r *TimeoutReader) GetLatencyDistribution(ctx context.Context, query *LatencyQueryParameters) (*LatencyDistribution, error) {
+	latencyReader, ok := r.reader.(LatencyReader)
+	if !ok {
+		return nil, ErrLatencyDistributionNotSupported
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return latencyReader.GetLatencyDistribution(ctx, query)
+}
+
+// FindTraceIDsByPrefix implements TraceIDPrefixReader#FindTraceIDsByPrefix if the reader supports it,
+// otherwise it returns ErrTraceIDPrefixNotSupported.
+func (r *TimeoutReader) FindTraceIDsByPrefix(ctx context.Context, query *TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	prefixReader, ok := r.reader.(TraceIDPrefixReader)
+	if !ok {
+		return nil, ErrTraceIDPrefixNotSupported
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return prefixReader.FindTraceIDsByPrefix(ctx, query)
+}
+
+// TimeoutWriter is a Writer bounding the time of each write by a timeout, the earlier deadline
+// of the context of the write applying if it has one.
+type TimeoutWriter struct {
+	writer  Writer
+	timeout time.Duration
+}
+
+// timeoutBatchWriter is the TimeoutWriter of a BatchWriter.
+type timeoutBatchWriter struct {
+	*TimeoutWriter
+	batchWriter BatchWriter
+}
+
+// NewTimeoutWriter returns the writer bounding the time of the writes by the timeout, which is
+// a BatchWriter if the writer is one, or the writer itself if the timeout is not positive.
+func NewTimeoutWriter(writer Writer, timeout time.Duration) Writer {
+	if timeout <= 0 {
+		return writer
+	}
+	timeoutWriter := &TimeoutWriter{writer: writer, timeout: timeout}
+	if batchWriter, ok := writer.(BatchWriter); ok {
+		return &timeoutBatchWriter{TimeoutWriter: timeoutWriter, batchWriter: batchWriter}
+	}
+	return timeoutWriter
+}
+
+// WriteSpan implements Writer#WriteSpan
+func (w *TimeoutWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.writer.WriteSpan(ctx, span)
+}
+
+// WriteBatch implements BatchWriter#WriteBatch
+func (w *timeoutBatchWriter) WriteBatch(ctx context.Context, spans []*model.Span) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.batchWriter.WriteBatch(ctx, spans)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+// withDeadline matches the contexts whose deadline is at most the timeout away.
+func withDeadline(timeout time.Duration) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		deadline, ok := ctx.Deadline()
+		return ok && time.Until(deadline) <= timeout
+	})
+}
+
+type deadlineReader struct {
+	mocks.Reader
+	deadlines []time.Time
+}
+
+func (r *deadlineReader) GetLatencyDistribution(ctx context.Context, _ *spanstore.LatencyQueryParameters) (*spanstore.LatencyDistribution, error) {
+	deadline, _ := ctx.Deadline()
+	r.deadlines = append(r.deadlines, deadline)
+	return &spanstore.LatencyDistribution{}, nil
+}
+
+func (r *deadlineReader) FindTraceIDsByPrefix(ctx context.Context, _ *spanstore.TraceIDPrefixQueryParameters) ([]model.TraceID, error) {
+	deadline, _ := ctx.Deadline()
+	r.deadlines = append(r.deadlines, deadline)
+	return nil, nil
+}
+
+func (r *deadlineReader) FindTracesPage(ctx context.Context, _ *spanstore.TraceQueryParameters) (*spanstore.TracesPage, error) {
+	deadline, _ := ctx.Deadline()
+	r.deadlines = append(r.deadlines, deadline)
+	return &spanstore.TracesPage{}, nil
+}
+
+func TestTimeoutReader(t *testing.T) {
+	reader := &mocks.Reader{}
+	assert.Same(t, reader, spanstore.NewTimeoutReader(reader, 0))
+
+	timeout := time.Minute
+	query := &spanstore.TraceQueryParameters{}
+	reader.On("GetTrace", withDeadline(timeout), model.TraceID{}).Return(&model.Trace{}, nil)
+	reader.On("GetServices", withDeadline(timeout)).Return([]string{"service"}, nil)
+	reader.On("GetOperations", withDeadline(timeout), spanstore.OperationQueryParameters{}).Return(nil, nil)
+	reader.On("FindTraces", withDeadline(timeout), query).Return(nil, nil)
+	reader.On("FindTraceIDs", withDeadline(timeout), query).Return(nil, nil)
+
+	r := spanstore.NewTimeoutReader(reader, timeout)
+	ctx := context.Background()
+	_, err := r.GetTrace(ctx, model.TraceID{})
+	require.NoError(t, err)
+	services, err := r.GetServices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service"}, services)
+	_, err = r.GetOperations(ctx, spanstore.OperationQueryParameters{})
+	require.NoError(t, err)
+	_, err = r.FindTraces(ctx, query)
+	require.NoError(t, err)
+	_, err = r.FindTraceIDs(ctx, query)
+	require.NoError(t, err)
+	reader.AssertExpectations(t)
+
+	_, err = r.(spanstore.LatencyReader).GetLatencyDistribution(ctx, &spanstore.LatencyQueryParameters{})
+	require.ErrorIs(t, err, spanstore.ErrLatencyDistributionNotSupported)
+	_, err = r.(spanstore.TraceIDPrefixReader).FindTraceIDsByPrefix(ctx, &spanstore.TraceIDPrefixQueryParameters{})
+	require.ErrorIs(t, err, spanstore.ErrTraceIDPrefixNotSupported)
+	_, err = r.(spanstore.PaginatedReader).FindTracesPage(ctx, query)
+	require.ErrorIs(t, err, spanstore.ErrPaginationNotSupported)
+}
+
+func TestTimeoutReaderOptionalInterfaces(t *testing.T) {
+	reader := &deadlineReader{}
+	r := spanstore.NewTimeoutReader(reader, time.Minute)
+	// the earlier deadline of the context applies
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	_, err := r.(spanstore.LatencyReader).GetLatencyDistribution(ctx, &spanstore.LatencyQueryParameters{})
+	require.NoError(t, err)
+	_, err = r.(spanstore.TraceIDPrefixReader).FindTraceIDsByPrefix(ctx, &spanstore.TraceIDPrefixQueryParameters{})
+	require.NoError(t, err)
+	_, err = r.(spanstore.PaginatedReader).FindTracesPage(ctx, &spanstore.TraceQueryParameters{})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{deadline, deadline, deadline}, reader.deadlines)
+}
+
+type deadlineBatchWriter struct {
+	mocks.Writer
+	deadline time.Time
+}
+
+func (w *deadlineBatchWriter) WriteBatch(ctx context.Context, _ []*model.Span) error {
+	w.deadline, _ = ctx.Deadline()
+	return nil
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	writer := &mocks.Writer{}
+	assert.Same(t, writer, spanstore.NewTimeoutWriter(writer, 0))
+
+	span := &model.Span{}
+	writer.On("WriteSpan", withDeadline(time.Minute), span).Return(nil)
+	w := spanstore.NewTimeoutWriter(writer, time.Minute)
+	_, ok := w.(spanstore.BatchWriter)
+	assert.False(t, ok)
+	require.NoError(t, w.WriteSpan(context.Background(), span))
+	writer.AssertExpectations(t)
+
+	batchWriter := &deadlineBatchWriter{}
+	w = spanstore.NewTimeoutWriter(batchWriter, time.Minute)
+	bw, ok := w.(spanstore.BatchWriter)
+	require.True(t, ok)
+	require.NoError(t, bw.WriteBatch(context.Background(), []*model.Span{span}))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), batchWriter.deadline, time.Second)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// MinTraceIDPrefixLength is the minimum number of hex digits of a trace ID prefix,
+// so that a lookup does not match most of the stored traces.
+const MinTraceIDPrefixLength = 4
+
+// ErrTraceIDPrefixNotSupported is returned by TraceIDPrefixReader if the underlying
+// storage cannot look up traces by a prefix of their ID.
+var ErrTraceIDPrefixNotSupported = errors.New("trace ID prefix lookup is not supported by span storage")
+
+// TraceIDPrefixReader is an optional interface of span readers that can find
+// traces by a shortened trace ID, as often copied truncated from logs.
+type TraceIDPrefixReader interface {
+	FindTraceIDsByPrefix(ctx context.Context, query *TraceIDPrefixQueryParameters) ([]model.TraceID, error)
+}
+
+// TraceIDPrefixQueryParameters contains parameters of a trace ID prefix lookup.
+type TraceIDPrefixQueryParameters struct {
+	// Prefix is a prefix of the hex string of the trace IDs, see NormalizeTraceIDPrefix.
+	Prefix       string
+	StartTimeMin time.Time
+	StartTimeMax time.Time
+	NumTraces    int
+}
+
+// NormalizeTraceIDPrefix validates a trace ID prefix and returns it in lower case,
+// as the trace IDs are formatted by model.TraceID.String.
+func NormalizeTraceIDPrefix(prefix string) (string, error) {
+	if len(prefix) < MinTraceIDPrefixLength || len(prefix) > 32 {
+		return "", fmt.Errorf("trace ID prefix must have between %d and 32 hex digits, received: %s", MinTraceIDPrefixLength, prefix)
+	}
+	prefix = strings.ToLower(prefix)
+	if strings.Trim(prefix, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("trace ID prefix must be hexadecimal, received: %s", prefix)
+	}
+	return prefix, nil
+}
+
+// TraceIDHasPrefix returns whether the string of the trace ID starts with a normalized prefix,
+// for the storage backends that scan the trace IDs.
+func TraceIDHasPrefix(traceID model.TraceID, prefix string) bool {
+	return strings.HasPrefix(traceID.String(), prefix)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestNormalizeTraceIDPrefix(t *testing.T) {
+	prefix, err := NormalizeTraceIDPrefix("4BF92F")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f", prefix)
+
+	_, err = NormalizeTraceIDPrefix("4bf")
+	require.ErrorContains(t, err, "between 4 and 32 hex digits")
+
+	_, err = NormalizeTraceIDPrefix("4bf92f3577b34da6a3ce929d0e0e47360")
+	require.ErrorContains(t, err, "between 4 and 32 hex digits")
+
+	_, err = NormalizeTraceIDPrefix("4bf9-2f")
+	require.ErrorContains(t, err, "must be hexadecimal")
+}
+
+func TestTraceIDHasPrefix(t *testing.T) {
+	traceID := model.NewTraceID(0x4bf92f3577b34da6, 0xa3ce929d0e0e4736)
+	assert.True(t, TraceIDHasPrefix(traceID, "4bf92f"))
+	assert.True(t, TraceIDHasPrefix(traceID, "4bf92f3577b34da6a3ce929d0e0e4736"))
+	assert.False(t, TraceIDHasPrefix(traceID, "a3ce"))
+
+	// the high bits are not formatted when zero
+	assert.True(t, TraceIDHasPrefix(model.NewTraceID(0, 0xa3ce929d0e0e4736), "a3ce"))
+}
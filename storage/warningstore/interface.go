@@ -0,0 +1,64 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package warningstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// SpanWarning is a data-quality warning of a span, e.g. recorded by the adjusters.
+type SpanWarning struct {
+	SpanID model.SpanID
+	// Type is the type of the warning, see adjuster.WarningType.
+	Type    string
+	Message string
+}
+
+// TraceWarnings are the warnings of the spans of a trace.
+type TraceWarnings struct {
+	TraceID   model.TraceID
+	StartTime time.Time
+	// Services are the services of the spans of the trace.
+	Services []string
+	Warnings []SpanWarning
+}
+
+// HasType checks that one of the warnings has the given type.
+func (w *TraceWarnings) HasType(warningType string) bool {
+	for _, warning := range w.Warnings {
+		if warning.Type == warningType {
+			return true
+		}
+	}
+	return false
+}
+
+// Query selects the traces with warnings, the most recent first.
+type Query struct {
+	// Type selects the traces with a warning of this type, all the traces if empty.
+	Type         string
+	StartTimeMin time.Time
+	StartTimeMax time.Time
+	// NumTraces is the maximum number of traces returned, 0 meaning no limit.
+	NumTraces int
+}
+
+// Writer writes the warnings of the traces, replacing the ones written before for the same trace.
+type Writer interface {
+	WriteTraceWarnings(ctx context.Context, warnings *TraceWarnings) error
+}
+
+// Reader finds the traces with warnings.
+type Reader interface {
+	FindTraceWarnings(ctx context.Context, query *Query) ([]*TraceWarnings, error)
+}
+
+// Store writes and finds the warnings of the traces.
+type Store interface {
+	Writer
+	Reader
+}